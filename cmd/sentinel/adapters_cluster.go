@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net"
@@ -12,9 +13,12 @@ import (
 	"time"
 
 	"github.com/Will-Luck/Docker-Sentinel/internal/cluster"
+	clusterproto "github.com/Will-Luck/Docker-Sentinel/internal/cluster/proto"
 	clusterserver "github.com/Will-Luck/Docker-Sentinel/internal/cluster/server"
+	"github.com/Will-Luck/Docker-Sentinel/internal/config"
 	"github.com/Will-Luck/Docker-Sentinel/internal/engine"
 	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+	"github.com/Will-Luck/Docker-Sentinel/internal/hooks"
 	"github.com/Will-Luck/Docker-Sentinel/internal/npm"
 	"github.com/Will-Luck/Docker-Sentinel/internal/portainer"
 	"github.com/Will-Luck/Docker-Sentinel/internal/store"
@@ -42,12 +46,15 @@ func (a *clusterAdapter) AllHosts() []web.ClusterHost {
 				Connected:     hs.Connected,
 				EnrolledAt:    hs.Info.EnrolledAt,
 				LastSeen:      hs.Info.LastSeen,
+				LastReport:    hs.LastReport,
 				AgentVersion:  hs.Info.AgentVersion,
 				Containers:    len(hs.Containers),
 				DisconnectAt:  hs.DisconnectAt,
 				DisconnectErr: hs.DisconnectErr,
 				DisconnectCat: hs.DisconnectCat,
 				EngineID:      hs.Info.EngineID,
+				Outdated:      a.srv.AgentOutdated(hs.Info.AgentVersion),
+				Slow:          a.srv.SlowAgent(hs.Info.ID),
 			})
 		}
 	}
@@ -67,12 +74,15 @@ func (a *clusterAdapter) GetHost(id string) (web.ClusterHost, bool) {
 		Connected:     hs.Connected,
 		EnrolledAt:    hs.Info.EnrolledAt,
 		LastSeen:      hs.Info.LastSeen,
+		LastReport:    hs.LastReport,
 		AgentVersion:  hs.Info.AgentVersion,
 		Containers:    len(hs.Containers),
 		DisconnectAt:  hs.DisconnectAt,
 		DisconnectErr: hs.DisconnectErr,
 		DisconnectCat: hs.DisconnectCat,
 		EngineID:      hs.Info.EngineID,
+		Outdated:      a.srv.AgentOutdated(hs.Info.AgentVersion),
+		Slow:          a.srv.SlowAgent(hs.Info.ID),
 	}, true
 }
 
@@ -111,8 +121,40 @@ func (a *clusterAdapter) ConnectedHosts() []string {
 	return a.srv.ConnectedHosts()
 }
 
-func (a *clusterAdapter) GenerateEnrollToken() (string, string, error) {
-	return a.srv.GenerateEnrollToken(24 * time.Hour)
+func (a *clusterAdapter) GenerateEnrollToken(ttl time.Duration, createdBy string) (string, string, error) {
+	return a.srv.GenerateEnrollToken(ttl, createdBy)
+}
+
+func (a *clusterAdapter) ListEnrollTokens() ([]web.EnrollToken, error) {
+	tokens, err := a.srv.ListEnrollTokens()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]web.EnrollToken, len(tokens))
+	for i, t := range tokens {
+		result[i] = web.EnrollToken{
+			ID:         t.ID,
+			CreatedBy:  t.CreatedBy,
+			CreatedAt:  t.CreatedAt,
+			ExpiresAt:  t.ExpiresAt,
+			Used:       t.Used,
+			UsedAt:     t.UsedAt,
+			UsedByID:   t.UsedByID,
+			UsedByName: t.UsedByName,
+		}
+	}
+	return result, nil
+}
+
+func (a *clusterAdapter) RevokeEnrollToken(id string) error {
+	err := a.srv.RevokeEnrollToken(id)
+	switch {
+	case errors.Is(err, clusterserver.ErrEnrollTokenNotFound):
+		return web.ErrEnrollTokenNotFound
+	case errors.Is(err, clusterserver.ErrEnrollTokenUsed):
+		return web.ErrEnrollTokenUsed
+	}
+	return err
 }
 
 func (a *clusterAdapter) RemoveHost(id string) error {
@@ -127,18 +169,40 @@ func (a *clusterAdapter) PauseHost(id string) error {
 	return a.srv.PauseHost(id)
 }
 
-func (a *clusterAdapter) UpdateRemoteContainer(ctx context.Context, hostID, containerName, targetImage, targetDigest string) error {
+func (a *clusterAdapter) HostEvents(id string, limit int) ([]web.ClusterHostEvent, error) {
+	evts, err := a.store.ListClusterHostEvents(id, limit)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]web.ClusterHostEvent, len(evts))
+	for i, e := range evts {
+		result[i] = web.ClusterHostEvent{
+			Timestamp: e.Timestamp,
+			Type:      e.Type,
+			Container: e.Container,
+			Message:   e.Message,
+			Failed:    e.Failed,
+		}
+	}
+	return result, nil
+}
+
+func (a *clusterAdapter) UpdateRemoteContainer(ctx context.Context, hostID, containerName, targetImage, targetDigest string) ([]web.HookRun, error) {
 	ur, err := a.srv.UpdateContainerSync(ctx, hostID, containerName, targetImage, targetDigest)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if ur.Outcome != "success" {
+	runs := webHookRuns(storeHookRuns(ur.HookResults))
+	switch {
+	case ur.Outcome == "skipped":
+		return runs, hooks.ErrSkipUpdate
+	case ur.Outcome != "success":
 		if ur.Error != "" {
-			return fmt.Errorf("%s", ur.Error)
+			return runs, fmt.Errorf("%s", ur.Error)
 		}
-		return fmt.Errorf("update failed")
+		return runs, fmt.Errorf("update failed")
 	}
-	return nil
+	return runs, nil
 }
 
 func (a *clusterAdapter) RemoteContainerAction(ctx context.Context, hostID, containerName, action string) error {
@@ -192,6 +256,7 @@ type clusterManager struct {
 	bus     *events.Bus
 	log     *slog.Logger
 	updater *engine.Updater
+	cfg     *config.Config
 	ctrl    *web.ClusterController // stable pointer in Dependencies
 	dataDir string                 // CA/cert storage directory
 }
@@ -220,6 +285,18 @@ func (m *clusterManager) Start() error {
 		return fmt.Errorf("create cluster server: %w", err)
 	}
 	m.srv.SetHistoryRecorder(m.db)
+	m.srv.SetHookSource(&hookSourceAdapter{db: m.db, cfg: m.cfg})
+	m.srv.SetAgentPolicy(&agentPolicyAdapter{db: m.db})
+	m.srv.SetHostEventRecorder(m.db)
+	m.srv.SetOnTokenUsed(func(tok cluster.EnrollToken) {
+		msg := fmt.Sprintf("Enrollment token %s used to enroll host %s (%s)", tok.ID, tok.UsedByName, tok.UsedByID)
+		if tok.CreatedBy != "" {
+			msg += ", token created by " + tok.CreatedBy
+		}
+		if err := m.db.AppendLog(store.LogEntry{Type: "cluster", Message: msg}); err != nil {
+			m.log.Warn("failed to record enrollment token use", "id", tok.ID, "error", err)
+		}
+	})
 
 	// Read advertise addresses for TLS cert SANs. Inside Docker, the container
 	// only sees its bridge network IPs, but agents connect via the host's
@@ -737,3 +814,45 @@ func (a *npmAdapter) LastSync() time.Time {
 func (a *npmAdapter) LastError() error {
 	return a.resolver.LastError()
 }
+
+// hookSourceAdapter supplies the hooks sent with remote updates, honouring
+// the global hooks toggle.
+type hookSourceAdapter struct {
+	db  *store.Store
+	cfg *config.Config
+}
+
+func (a *hookSourceAdapter) HooksEnabled() bool { return a.cfg.HooksEnabled() }
+
+func (a *hookSourceAdapter) ListHooks(containerName string) ([]store.HookEntry, error) {
+	return a.db.ListHooks(containerName)
+}
+
+// agentPolicyAdapter reads the minimum agent version policy from settings.
+type agentPolicyAdapter struct {
+	db *store.Store
+}
+
+func (a *agentPolicyAdapter) MinAgentVersion() string {
+	if v, _ := a.db.LoadSetting(store.SettingClusterMinAgentVersion); v != "" {
+		return v
+	}
+	return cluster.MinAgentVersion
+}
+
+func (a *agentPolicyAdapter) RefuseOutdatedAgents() bool {
+	v, _ := a.db.LoadSetting(store.SettingClusterOutdatedAgents)
+	return v == "refuse"
+}
+
+// storeHookRuns converts the hook results an agent returned for history.
+func storeHookRuns(in []*clusterproto.HookResult) []store.HookRun {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make([]store.HookRun, len(in))
+	for i, hr := range in {
+		out[i] = store.HookRun{Phase: hr.Phase, ExitCode: int(hr.ExitCode), Output: hr.Output, Error: hr.Error}
+	}
+	return out
+}
@@ -29,12 +29,13 @@ func (a *dockerAdapter) ListContainers(ctx context.Context) ([]web.ContainerSumm
 	result := make([]web.ContainerSummary, len(containers))
 	for i, c := range containers {
 		result[i] = web.ContainerSummary{
-			ID:     c.ID,
-			Names:  c.Names,
-			Image:  c.Image,
-			Labels: c.Labels,
-			State:  string(c.State),
-			Ports:  convertPorts(c.Ports),
+			ID:          c.ID,
+			Names:       c.Names,
+			Image:       c.Image,
+			Labels:      c.Labels,
+			State:       string(c.State),
+			Ports:       convertPorts(c.Ports),
+			NetworkMode: c.HostConfig.NetworkMode,
 		}
 	}
 	return result, nil
@@ -48,12 +49,13 @@ func (a *dockerAdapter) ListAllContainers(ctx context.Context) ([]web.ContainerS
 	result := make([]web.ContainerSummary, len(containers))
 	for i, c := range containers {
 		result[i] = web.ContainerSummary{
-			ID:     c.ID,
-			Names:  c.Names,
-			Image:  c.Image,
-			Labels: c.Labels,
-			State:  string(c.State),
-			Ports:  convertPorts(c.Ports),
+			ID:          c.ID,
+			Names:       c.Names,
+			Image:       c.Image,
+			Labels:      c.Labels,
+			State:       string(c.State),
+			Ports:       convertPorts(c.Ports),
+			NetworkMode: c.HostConfig.NetworkMode,
 		}
 	}
 	return result, nil
@@ -131,6 +133,24 @@ func (a *stopAdapter) StopContainer(ctx context.Context, id string) error {
 	return a.c.StopContainer(ctx, id, 10)
 }
 
+// pauseAdapter bridges docker.Client to web.ContainerPauser.
+type pauseAdapter struct{ c *docker.Client }
+
+func (a *pauseAdapter) PauseContainer(ctx context.Context, id string) error {
+	return a.c.PauseContainer(ctx, id)
+}
+
+func (a *pauseAdapter) UnpauseContainer(ctx context.Context, id string) error {
+	return a.c.UnpauseContainer(ctx, id)
+}
+
+// killAdapter bridges docker.Client to web.ContainerKiller.
+type killAdapter struct{ c *docker.Client }
+
+func (a *killAdapter) KillContainer(ctx context.Context, id, signal string) error {
+	return a.c.KillContainer(ctx, id, signal)
+}
+
 // startAdapter bridges docker.Client to web.ContainerStarter.
 type startAdapter struct{ c *docker.Client }
 
@@ -176,6 +196,59 @@ func (a *imageAdapter) RemoveImageByID(ctx context.Context, id string) error {
 	return a.client.RemoveImageByID(ctx, id)
 }
 
+// resourceAdapter bridges docker.Client to web.ResourceManager.
+type resourceAdapter struct {
+	client *docker.Client
+}
+
+func (a *resourceAdapter) ListNetworks(ctx context.Context) ([]web.NetworkInfo, error) {
+	nets, err := a.client.ListNetworks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]web.NetworkInfo, len(nets))
+	for i, n := range nets {
+		result[i] = web.NetworkInfo{
+			ID:         n.ID,
+			Name:       n.Name,
+			Driver:     n.Driver,
+			Scope:      n.Scope,
+			Internal:   n.Internal,
+			Builtin:    n.Builtin,
+			Containers: n.Containers,
+			Orphan:     n.Orphan(),
+		}
+	}
+	return result, nil
+}
+
+func (a *resourceAdapter) ListVolumes(ctx context.Context) ([]web.VolumeInfo, error) {
+	vols, err := a.client.ListVolumes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]web.VolumeInfo, len(vols))
+	for i, v := range vols {
+		result[i] = web.VolumeInfo{
+			Name:       v.Name,
+			Driver:     v.Driver,
+			Mountpoint: v.Mountpoint,
+			CreatedAt:  v.CreatedAt,
+			Containers: v.Containers,
+			Orphan:     v.Orphan(),
+		}
+	}
+	return result, nil
+}
+
+func (a *resourceAdapter) RemoveNetwork(ctx context.Context, id string) error {
+	return a.client.RemoveNetwork(ctx, id)
+}
+
+func (a *resourceAdapter) RemoveVolume(ctx context.Context, name string) error {
+	return a.client.RemoveVolume(ctx, name)
+}
+
 // rollbackAdapter bridges engine.RollbackFromStore to web.ContainerRollback.
 type rollbackAdapter struct {
 	d   *docker.Client
@@ -372,6 +445,10 @@ func (a *swarmAdapter) UpdateService(ctx context.Context, id, name, targetImage
 	return a.updater.UpdateService(ctx, id, name, targetImage)
 }
 
+func (a *swarmAdapter) PreviewPlacement(ctx context.Context, id, targetImage string) (engine.ServicePlacement, error) {
+	return a.updater.PreviewServicePlacement(ctx, id, targetImage)
+}
+
 func (a *swarmAdapter) RollbackService(ctx context.Context, id, name string) error {
 	// Look up service by name if id is empty (rollback from UI).
 	if id == "" {
@@ -3,13 +3,19 @@ package main
 import (
 	"context"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	clusterserver "github.com/Will-Luck/Docker-Sentinel/internal/cluster/server"
+	"github.com/Will-Luck/Docker-Sentinel/internal/compose"
 	"github.com/Will-Luck/Docker-Sentinel/internal/docker"
 	"github.com/Will-Luck/Docker-Sentinel/internal/engine"
 	"github.com/Will-Luck/Docker-Sentinel/internal/logging"
 	"github.com/Will-Luck/Docker-Sentinel/internal/registry"
+	"github.com/Will-Luck/Docker-Sentinel/internal/report"
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+	"github.com/Will-Luck/Docker-Sentinel/internal/ticket"
 	"github.com/Will-Luck/Docker-Sentinel/internal/web"
 )
 
@@ -34,11 +40,17 @@ func (a *queueAdapter) Add(update web.PendingUpdate) {
 		RemoteDigest:           update.RemoteDigest,
 		DetectedAt:             update.DetectedAt,
 		NewerVersions:          update.NewerVersions,
+		Candidates:             update.Candidates,
 		ResolvedCurrentVersion: update.ResolvedCurrentVersion,
 		ResolvedTargetVersion:  update.ResolvedTargetVersion,
 		Type:                   update.Type,
 		HostID:                 update.HostID,
 		HostName:               update.HostName,
+		Order:                  update.Order,
+		ScanReport:             update.ScanReport,
+		ScanThreshold:          update.ScanThreshold,
+		ConfigDiff:             update.ConfigDiff,
+		Comment:                update.Comment,
 	})
 }
 
@@ -60,6 +72,10 @@ func (a *queueAdapter) Approve(name string) (web.PendingUpdate, bool) {
 
 func (a *queueAdapter) Remove(name string) { a.q.Remove(name) }
 
+func (a *queueAdapter) Reorder(keys []string) error { return a.q.Reorder(keys) }
+
+func (a *queueAdapter) SetComment(name, comment string) bool { return a.q.SetComment(name, comment) }
+
 func convertPendingUpdate(item engine.PendingUpdate) web.PendingUpdate {
 	return web.PendingUpdate{
 		ContainerID:            item.ContainerID,
@@ -69,11 +85,17 @@ func convertPendingUpdate(item engine.PendingUpdate) web.PendingUpdate {
 		RemoteDigest:           item.RemoteDigest,
 		DetectedAt:             item.DetectedAt,
 		NewerVersions:          item.NewerVersions,
+		Candidates:             item.Candidates,
 		ResolvedCurrentVersion: item.ResolvedCurrentVersion,
 		ResolvedTargetVersion:  item.ResolvedTargetVersion,
 		Type:                   item.Type,
 		HostID:                 item.HostID,
 		HostName:               item.HostName,
+		Order:                  item.Order,
+		ScanReport:             item.ScanReport,
+		ScanThreshold:          item.ScanThreshold,
+		ConfigDiff:             item.ConfigDiff,
+		Comment:                item.Comment,
 	}
 }
 
@@ -138,7 +160,11 @@ func (a *registryAdapter) VersionsWithScopeHint(ctx context.Context, imageRef, s
 
 // parseSemverScope converts a raw scope string to a docker.SemverScope. Empty and
 // "default" both map to ScopeDefault (relaxed); unrecognised values do too.
+// Semver ranges from the sentinel.semver label pass through unchanged.
 func parseSemverScope(s string) docker.SemverScope {
+	if scope := docker.SemverScope(s); scope.IsConstraint() {
+		return scope
+	}
 	switch s {
 	case string(docker.ScopeStrict):
 		return docker.ScopeStrict
@@ -173,6 +199,67 @@ func (a *tagListerAdapter) ListAllTags(ctx context.Context, imageRef string) ([]
 	return tagsResult.Tags, nil
 }
 
+// imageDatesTTL is how long registry image dates are reused. Manifest GETs
+// count towards Docker Hub pull limits, so each page view must not refetch.
+const imageDatesTTL = 6 * time.Hour
+
+type cachedImageDates struct {
+	dates   registry.ImageDates
+	fetched time.Time
+}
+
+// imageDatesAdapter bridges docker.Client and registry.FetchImageDates to
+// web.ImageDateProvider, caching registry lookups per image reference.
+type imageDatesAdapter struct {
+	client *docker.Client
+	mu     sync.Mutex
+	cache  map[string]cachedImageDates
+}
+
+func (a *imageDatesAdapter) LocalImageDates(ctx context.Context, containerID string) (registry.ImageDates, error) {
+	inspect, err := a.client.InspectContainer(ctx, containerID)
+	if err != nil {
+		return registry.ImageDates{}, err
+	}
+	created, repoDigest, err := a.client.ImageCreated(ctx, inspect.Image)
+	if err != nil {
+		return registry.ImageDates{}, err
+	}
+	dates := registry.ImageDates{Created: created}
+	if i := strings.Index(repoDigest, "@"); i >= 0 {
+		dates.Digest = repoDigest[i+1:]
+	}
+	return dates, nil
+}
+
+func (a *imageDatesAdapter) RemoteImageDates(ctx context.Context, imageRef string) (registry.ImageDates, error) {
+	a.mu.Lock()
+	if c, ok := a.cache[imageRef]; ok && time.Since(c.fetched) < imageDatesTTL {
+		a.mu.Unlock()
+		return c.dates, nil
+	}
+	a.mu.Unlock()
+
+	repo := registry.RepoPath(imageRef)
+	host := registry.RegistryHost(imageRef)
+	token, err := registry.FetchToken(ctx, repo, nil, host)
+	if err != nil {
+		return registry.ImageDates{}, fmt.Errorf("fetch token: %w", err)
+	}
+	dates, err := registry.FetchImageDates(ctx, imageRef, token, host, nil)
+	if err != nil {
+		return registry.ImageDates{}, err
+	}
+
+	a.mu.Lock()
+	if a.cache == nil {
+		a.cache = make(map[string]cachedImageDates)
+	}
+	a.cache[imageRef] = cachedImageDates{dates: dates, fetched: time.Now()}
+	a.mu.Unlock()
+	return dates, nil
+}
+
 // registryCheckerAdapter bridges registry.Checker to web.RegistryChecker.
 type registryCheckerAdapter struct {
 	checker *registry.Checker
@@ -246,6 +333,7 @@ func (a *clusterScannerAdapter) ListContainers(ctx context.Context, hostID strin
 			Image:       c.Image,
 			ImageDigest: c.ImageDigest,
 			State:       c.State,
+			Health:      c.Health,
 			Labels:      c.Labels,
 		}
 	}
@@ -270,5 +358,100 @@ func (a *clusterScannerAdapter) UpdateContainer(ctx context.Context, hostID, con
 		Outcome:       ur.Outcome,
 		Error:         ur.Error,
 		Duration:      dur,
+		Hooks:         storeHookRuns(ur.HookResults),
 	}, nil
 }
+
+// ticketDetails looks up a queued update for the ticketing integration.
+// Stack labels are only known for local containers.
+func ticketDetails(q *engine.Queue, c *docker.Client, sources func() []registry.ReleaseSource) ticket.DetailsFunc {
+	return func(ctx context.Context, key string) (ticket.Details, bool) {
+		pu, ok := q.Get(key)
+		if !ok {
+			return ticket.Details{}, false
+		}
+		d := ticket.Details{
+			HostName:       pu.HostName,
+			CurrentImage:   pu.CurrentImage,
+			CurrentDigest:  pu.CurrentDigest,
+			RemoteDigest:   pu.RemoteDigest,
+			CurrentVersion: pu.ResolvedCurrentVersion,
+			TargetVersion:  pu.ResolvedTargetVersion,
+			NewerVersions:  pu.NewerVersions,
+		}
+		if d.TargetVersion == "" && len(pu.NewerVersions) > 0 {
+			d.TargetVersion = pu.NewerVersions[0]
+		}
+		if pu.HostID == "" {
+			if inspect, err := c.InspectContainer(ctx, pu.ContainerID); err == nil && inspect.Config != nil {
+				d.Stack = inspect.Config.Labels[compose.ProjectLabel]
+			}
+		}
+		if d.TargetVersion != "" {
+			if info := registry.FetchReleaseNotesWithSources(ctx, pu.CurrentImage, d.TargetVersion, sources()); info != nil {
+				d.ReleaseURL = info.URL
+				d.ReleaseNotes = info.Body
+			}
+		}
+		return d, true
+	}
+}
+
+// reportSource supplies the weekly report with history, the approval queue
+// and cluster hosts that are enrolled but not connected.
+func reportSource(db *store.Store, q *engine.Queue, cluster *web.ClusterController) report.Source {
+	return report.Source{
+		History: db.ListHistorySince,
+		Pending: func() []report.Pending {
+			items := q.List()
+			pending := make([]report.Pending, len(items))
+			for i, item := range items {
+				target := item.ResolvedTargetVersion
+				if target == "" && len(item.NewerVersions) > 0 {
+					target = item.NewerVersions[0]
+				}
+				pending[i] = report.Pending{
+					Container:  item.ContainerName,
+					Host:       item.HostName,
+					Target:     target,
+					DetectedAt: item.DetectedAt,
+				}
+			}
+			return pending
+		},
+		OfflineHosts: func() []report.Host {
+			if !cluster.Enabled() {
+				return nil
+			}
+			var hosts []report.Host
+			for _, h := range cluster.AllHosts() {
+				if h.State != "active" || h.Connected {
+					continue
+				}
+				since := h.DisconnectAt
+				if since.IsZero() {
+					since = h.LastSeen
+				}
+				hosts = append(hosts, report.Host{Name: h.Name, Since: since, Detail: h.DisconnectErr})
+			}
+			return hosts
+		},
+	}
+}
+
+// stackAdapter bridges engine.StackUpdater to web.StackUpdater.
+type stackAdapter struct {
+	stacks *engine.StackUpdater
+}
+
+func (a *stackAdapter) UpdateStack(ctx context.Context, project string) (web.StackResult, error) {
+	res, err := a.stacks.Update(ctx, project)
+	return web.StackResult{
+		Project:    res.Project,
+		Order:      res.Order,
+		Updated:    res.Updated,
+		Unchanged:  res.Unchanged,
+		RolledBack: res.RolledBack,
+		Failed:     res.Failed,
+	}, err
+}
@@ -3,13 +3,18 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"log/slog"
+	"time"
 
 	"github.com/Will-Luck/Docker-Sentinel/internal/backup"
+	"github.com/Will-Luck/Docker-Sentinel/internal/docker"
 	"github.com/Will-Luck/Docker-Sentinel/internal/hooks"
 	"github.com/Will-Luck/Docker-Sentinel/internal/notify"
 	"github.com/Will-Luck/Docker-Sentinel/internal/registry"
 	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+	"github.com/Will-Luck/Docker-Sentinel/internal/verify"
 	"github.com/Will-Luck/Docker-Sentinel/internal/web"
 )
 
@@ -36,6 +41,12 @@ func (a *storeAdapter) ListHistory(limit int, before string) ([]web.UpdateRecord
 			Type:          r.Type,
 			HostID:        r.HostID,
 			HostName:      r.HostName,
+			FailureClass:  r.FailureClass,
+			Stages:        r.Stages,
+			Preconditions: webPreconditionResults(r.Preconditions),
+			Hooks:         webHookRuns(r.Hooks),
+			Comment:       r.Comment,
+			ResourceDrift: r.ResourceDrift,
 		}
 	}
 	return result, nil
@@ -61,11 +72,42 @@ func (a *storeAdapter) ListAllHistory() ([]web.UpdateRecord, error) {
 			Type:          r.Type,
 			HostID:        r.HostID,
 			HostName:      r.HostName,
+			FailureClass:  r.FailureClass,
+			Stages:        r.Stages,
+			Preconditions: webPreconditionResults(r.Preconditions),
+			Hooks:         webHookRuns(r.Hooks),
+			Comment:       r.Comment,
+			ResourceDrift: r.ResourceDrift,
 		}
 	}
 	return result, nil
 }
 
+func (a *storeAdapter) ForEachHistory(from, to time.Time, fn func(web.UpdateRecord) error) error {
+	return a.s.ForEachHistory(from, to, func(r store.UpdateRecord) error {
+		return fn(web.UpdateRecord{
+			Timestamp:     r.Timestamp,
+			ContainerName: r.ContainerName,
+			OldImage:      r.OldImage,
+			OldDigest:     r.OldDigest,
+			NewImage:      r.NewImage,
+			NewDigest:     r.NewDigest,
+			Outcome:       r.Outcome,
+			Duration:      r.Duration,
+			Error:         r.Error,
+			Type:          r.Type,
+			HostID:        r.HostID,
+			HostName:      r.HostName,
+			FailureClass:  r.FailureClass,
+			Stages:        r.Stages,
+			Preconditions: webPreconditionResults(r.Preconditions),
+			Hooks:         webHookRuns(r.Hooks),
+			Comment:       r.Comment,
+			ResourceDrift: r.ResourceDrift,
+		})
+	})
+}
+
 func (a *storeAdapter) ListHistoryByContainer(name string, limit int) ([]web.UpdateRecord, error) {
 	records, err := a.s.ListHistoryByContainer(name, limit)
 	if err != nil {
@@ -86,6 +128,12 @@ func (a *storeAdapter) ListHistoryByContainer(name string, limit int) ([]web.Upd
 			Type:          r.Type,
 			HostID:        r.HostID,
 			HostName:      r.HostName,
+			FailureClass:  r.FailureClass,
+			Stages:        r.Stages,
+			Preconditions: webPreconditionResults(r.Preconditions),
+			Hooks:         webHookRuns(r.Hooks),
+			Comment:       r.Comment,
+			ResourceDrift: r.ResourceDrift,
 		}
 	}
 	return result, nil
@@ -109,6 +157,10 @@ func (a *storeAdapter) RecordUpdate(rec web.UpdateRecord) error {
 		Type:          rec.Type,
 		HostID:        rec.HostID,
 		HostName:      rec.HostName,
+		FailureClass:  rec.FailureClass,
+		Stages:        rec.Stages,
+		Hooks:         storeHookRunsFromWeb(rec.Hooks),
+		Comment:       rec.Comment,
 	})
 }
 
@@ -264,7 +316,8 @@ func (a *portConfigStoreAdapter) AllPortConfigs() (map[string]*web.PortConfig, e
 
 // backupAdapter bridges backup.Manager to web.BackupProvider.
 type backupAdapter struct {
-	m *backup.Manager
+	m     *backup.Manager
+	sched *backup.Scheduler
 }
 
 func (a *backupAdapter) CreateBackup(ctx context.Context) (*web.BackupInfo, error) {
@@ -279,6 +332,8 @@ func (a *backupAdapter) CreateBackup(ctx context.Context) (*web.BackupInfo, erro
 	}, nil
 }
 
+func (a *backupAdapter) InProgress() bool { return a.m.InProgress() }
+
 func (a *backupAdapter) List() ([]web.BackupInfo, error) {
 	list, err := a.m.List()
 	if err != nil {
@@ -299,6 +354,42 @@ func (a *backupAdapter) FilePath(filename string) (string, error) {
 	return a.m.FilePath(filename)
 }
 
+func (a *backupAdapter) WriteSnapshot(w io.Writer) (int64, error) {
+	return a.m.WriteSnapshot(w)
+}
+
+func (a *backupAdapter) Config() web.BackupConfig {
+	return web.BackupConfig{
+		Schedule:  a.sched.Schedule(),
+		Dir:       a.m.Dir(),
+		Retention: a.m.Retention(),
+	}
+}
+
+func (a *backupAdapter) Configure(cfg web.BackupConfig) error {
+	if err := a.m.SetDir(cfg.Dir); err != nil {
+		return err
+	}
+	a.m.SetRetention(cfg.Retention)
+	return a.sched.SetSchedule(cfg.Schedule)
+}
+
+// restoreAdapter bridges store.Store to web.DatabaseRestorer.
+type restoreAdapter struct{ s *store.Store }
+
+func (a *restoreAdapter) ValidateBackup(path string) error { return store.ValidateBackup(path) }
+func (a *restoreAdapter) Restore(path string) error        { return a.s.Restore(path) }
+
+// maintenanceAdapter bridges store.Store to web.DatabaseMaintainer.
+type maintenanceAdapter struct{ s *store.Store }
+
+func (a *maintenanceAdapter) ApplyRetention() (web.RetentionReport, error) {
+	rep, err := a.s.ApplyRetention(time.Now())
+	return web.RetentionReport{History: rep.History, Logs: rep.Logs, Snapshots: rep.Snapshots}, err
+}
+
+func (a *maintenanceAdapter) Compact() (before, after int64, err error) { return a.s.Compact() }
+
 // notifyConfigAdapter bridges store.Store to web.NotificationConfigStore.
 type notifyConfigAdapter struct{ s *store.Store }
 
@@ -333,11 +424,11 @@ func (a *notifyStateAdapter) GetNotifyPref(name string) (*web.NotifyPref, error)
 	if err != nil || p == nil {
 		return nil, err
 	}
-	return &web.NotifyPref{Mode: p.Mode}, nil
+	return &web.NotifyPref{Mode: p.Mode, Channels: p.Channels, Source: p.Source}, nil
 }
 
 func (a *notifyStateAdapter) SetNotifyPref(name string, pref *web.NotifyPref) error {
-	return a.s.SetNotifyPref(name, &store.NotifyPref{Mode: pref.Mode})
+	return a.s.SetNotifyPref(name, &store.NotifyPref{Mode: pref.Mode, Channels: pref.Channels, Source: pref.Source})
 }
 
 func (a *notifyStateAdapter) DeleteNotifyPref(name string) error {
@@ -351,7 +442,7 @@ func (a *notifyStateAdapter) AllNotifyPrefs() (map[string]*web.NotifyPref, error
 	}
 	result := make(map[string]*web.NotifyPref, len(prefs))
 	for k, v := range prefs {
-		result[k] = &web.NotifyPref{Mode: v.Mode}
+		result[k] = &web.NotifyPref{Mode: v.Mode, Channels: v.Channels, Source: v.Source}
 	}
 	return result, nil
 }
@@ -367,11 +458,21 @@ func (a *notifyStateAdapter) AllNotifyStates() (map[string]*web.NotifyState, err
 			LastDigest:   v.LastDigest,
 			LastNotified: v.LastNotified,
 			FirstSeen:    v.FirstSeen,
+			SnoozedUntil: v.SnoozedUntil,
 		}
 	}
 	return result, nil
 }
 
+func (a *notifyStateAdapter) SetNotifyState(name string, state *web.NotifyState) error {
+	return a.s.SetNotifyState(name, &store.NotifyState{
+		LastDigest:   state.LastDigest,
+		LastNotified: state.LastNotified,
+		FirstSeen:    state.FirstSeen,
+		SnoozedUntil: state.SnoozedUntil,
+	})
+}
+
 func (a *notifyStateAdapter) ClearNotifyState(name string) error {
 	return a.s.ClearNotifyState(name)
 }
@@ -387,6 +488,10 @@ func (a *ignoredVersionAdapter) GetIgnoredVersions(containerName string) ([]stri
 	return a.s.GetIgnoredVersions(containerName)
 }
 
+func (a *ignoredVersionAdapter) AllIgnoredVersions() (map[string][]string, error) {
+	return a.s.AllIgnoredVersions()
+}
+
 func (a *ignoredVersionAdapter) ClearIgnoredVersions(containerName string) error {
 	return a.s.ClearIgnoredVersions(containerName)
 }
@@ -577,6 +682,209 @@ func (a *releaseSourceAdapter) SetReleaseSources(sources []web.ReleaseSource) er
 	return a.s.SetReleaseSources(regSrcs)
 }
 
+// unmanagedAdapter bridges store.Store to web.UnmanagedStore.
+type unmanagedAdapter struct{ s *store.Store }
+
+func (a *unmanagedAdapter) SetUnmanaged(key string, entry web.UnmanagedEntry) error {
+	return a.s.SetUnmanaged(key, store.UnmanagedEntry{Since: entry.Since, Hidden: entry.Hidden})
+}
+
+func (a *unmanagedAdapter) DeleteUnmanaged(key string) error {
+	return a.s.DeleteUnmanaged(key)
+}
+
+func (a *unmanagedAdapter) AllUnmanaged() map[string]web.UnmanagedEntry {
+	entries := a.s.AllUnmanaged()
+	result := make(map[string]web.UnmanagedEntry, len(entries))
+	for k, e := range entries {
+		result[k] = web.UnmanagedEntry{Since: e.Since, Hidden: e.Hidden}
+	}
+	return result
+}
+
+// validationAdapter bridges store.Store to web.ValidationStore.
+type validationAdapter struct{ s *store.Store }
+
+func (a *validationAdapter) GetValidationOverride(key string) (web.ValidationOverride, bool) {
+	o, ok := a.s.GetValidationOverride(key)
+	return web.ValidationOverride{
+		GracePeriod:   o.GracePeriod,
+		Strategy:      o.Strategy,
+		ProbeURL:      o.ProbeURL,
+		HealthTimeout: o.HealthTimeout,
+		ProbeStatus:   o.ProbeStatus,
+		ProbeBody:     o.ProbeBody,
+	}, ok
+}
+
+func (a *validationAdapter) SetValidationOverride(key string, o web.ValidationOverride) error {
+	return a.s.SetValidationOverride(key, store.ValidationOverride{
+		GracePeriod:   o.GracePeriod,
+		Strategy:      o.Strategy,
+		ProbeURL:      o.ProbeURL,
+		HealthTimeout: o.HealthTimeout,
+		ProbeStatus:   o.ProbeStatus,
+		ProbeBody:     o.ProbeBody,
+	})
+}
+
+func (a *validationAdapter) DeleteValidationOverride(key string) error {
+	return a.s.DeleteValidationOverride(key)
+}
+
+// preconditionAdapter bridges store.Store to web.PreconditionStore.
+type preconditionAdapter struct{ s *store.Store }
+
+func (a *preconditionAdapter) GetPreconditions(key string) (web.Preconditions, bool) {
+	p, ok := a.s.GetPreconditions(key)
+	return web.Preconditions{Conditions: p.Conditions, Action: p.Action}, ok
+}
+
+func (a *preconditionAdapter) SetPreconditions(key string, p web.Preconditions) error {
+	return a.s.SetPreconditions(key, store.Preconditions{Conditions: p.Conditions, Action: p.Action})
+}
+
+func (a *preconditionAdapter) DeletePreconditions(key string) error {
+	return a.s.DeletePreconditions(key)
+}
+
+// scheduleAdapter bridges store.Store to web.ScheduleStore.
+type scheduleAdapter struct{ s *store.Store }
+
+func (a *scheduleAdapter) GetContainerSchedule(key string) (string, bool) {
+	return a.s.GetContainerSchedule(key)
+}
+
+func (a *scheduleAdapter) SetContainerSchedule(key, expr string) error {
+	return a.s.SetContainerSchedule(key, expr)
+}
+
+func (a *scheduleAdapter) DeleteContainerSchedule(key string) error {
+	return a.s.DeleteContainerSchedule(key)
+}
+
+// stackWindowAdapter bridges store.Store to web.StackWindowStore.
+type stackWindowAdapter struct{ s *store.Store }
+
+func (a *stackWindowAdapter) GetStackWindow(project string) (string, bool) {
+	return a.s.GetStackWindow(project)
+}
+
+func (a *stackWindowAdapter) SetStackWindow(project, expr string) error {
+	return a.s.SetStackWindow(project, expr)
+}
+
+func (a *stackWindowAdapter) DeleteStackWindow(project string) error {
+	return a.s.DeleteStackWindow(project)
+}
+
+func (a *stackWindowAdapter) ListStackWindows() (map[string]string, error) {
+	return a.s.ListStackWindows()
+}
+
+// composePathAdapter bridges store.Store to web.ComposePathStore.
+type composePathAdapter struct{ s *store.Store }
+
+func (a *composePathAdapter) ListComposePaths() (map[string]string, error) {
+	return a.s.ListComposePaths()
+}
+
+func (a *composePathAdapter) SetComposePath(project, path string) error {
+	return a.s.SetComposePath(project, path)
+}
+
+func (a *composePathAdapter) DeleteComposePath(project string) error {
+	return a.s.DeleteComposePath(project)
+}
+
+// pinAdapter bridges store.Store to web.PinStore, resolving digests through
+// the local Docker client.
+type pinAdapter struct {
+	s *store.Store
+	d *docker.Client
+}
+
+func (a *pinAdapter) GetTimedPin(key string) (web.TimedPin, bool) {
+	p, ok := a.s.GetTimedPin(key)
+	return web.TimedPin(p), ok
+}
+
+func (a *pinAdapter) SetTimedPin(key string, p web.TimedPin) error {
+	return a.s.SetTimedPin(key, store.TimedPin(p))
+}
+
+func (a *pinAdapter) DeleteTimedPin(key string) error {
+	return a.s.DeleteTimedPin(key)
+}
+
+func (a *pinAdapter) ImageDigest(ctx context.Context, imageRef string) (string, error) {
+	return a.d.ImageDigest(ctx, imageRef)
+}
+
+// webHookRuns converts recorded hook results for the web layer.
+func webHookRuns(in []store.HookRun) []web.HookRun {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make([]web.HookRun, len(in))
+	for i, h := range in {
+		out[i] = web.HookRun{Phase: h.Phase, ExitCode: h.ExitCode, Output: h.Output, Error: h.Error}
+	}
+	return out
+}
+
+// storeHookRunsFromWeb converts hook results recorded by the web layer.
+func storeHookRunsFromWeb(in []web.HookRun) []store.HookRun {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make([]store.HookRun, len(in))
+	for i, h := range in {
+		out[i] = store.HookRun{Phase: h.Phase, ExitCode: h.ExitCode, Output: h.Output, Error: h.Error}
+	}
+	return out
+}
+
+// webPreconditionResults converts recorded wait-for results for the web layer.
+func webPreconditionResults(in []store.PreconditionResult) []web.PreconditionResult {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make([]web.PreconditionResult, len(in))
+	for i, p := range in {
+		out[i] = web.PreconditionResult{Condition: p.Condition, OK: p.OK, Detail: p.Detail}
+	}
+	return out
+}
+
+// retryAdapter bridges store.Store to web.RetryStore.
+type retryAdapter struct{ s *store.Store }
+
+func (a *retryAdapter) AllRetryStates() map[string]web.RetryState {
+	states := a.s.AllRetryStates()
+	result := make(map[string]web.RetryState, len(states))
+	for k, v := range states {
+		result[k] = web.RetryState{Attempts: v.Attempts, LastError: v.LastError, LastFailure: v.LastFailure, NextAttempt: v.NextAttempt, Failing: v.Failing}
+	}
+	return result
+}
+
+func (a *retryAdapter) DeleteRetryState(name string) error {
+	return a.s.DeleteRetryState(name)
+}
+
+// versionAdapter bridges store.Store to web.VersionStore.
+type versionAdapter struct{ s *store.Store }
+
+func (a *versionAdapter) AllVersionInfo() map[string]web.VersionInfo {
+	infos := a.s.AllVersionInfo()
+	result := make(map[string]web.VersionInfo, len(infos))
+	for k, v := range infos {
+		result[k] = web.VersionInfo{Tag: v.Tag, Current: v.Current, Candidate: v.Candidate, Source: v.Source, UpdatedAt: v.UpdatedAt}
+	}
+	return result
+}
+
 // webHookStoreAdapter converts store.Store to web.HookStore interface.
 type webHookStoreAdapter struct{ s *store.Store }
 
@@ -585,6 +893,18 @@ func (a *webHookStoreAdapter) ListHooks(containerName string) ([]web.HookEntry,
 	if err != nil {
 		return nil, err
 	}
+	return webHookEntries(entries), nil
+}
+
+func (a *webHookStoreAdapter) ListAllHooks() ([]web.HookEntry, error) {
+	entries, err := a.s.ListAllHooks()
+	if err != nil {
+		return nil, err
+	}
+	return webHookEntries(entries), nil
+}
+
+func webHookEntries(entries []store.HookEntry) []web.HookEntry {
 	result := make([]web.HookEntry, len(entries))
 	for i, e := range entries {
 		result[i] = web.HookEntry{
@@ -594,7 +914,7 @@ func (a *webHookStoreAdapter) ListHooks(containerName string) ([]web.HookEntry,
 			Timeout:       e.Timeout,
 		}
 	}
-	return result, nil
+	return result
 }
 
 func (a *webHookStoreAdapter) SaveHook(hook web.HookEntry) error {
@@ -609,3 +929,150 @@ func (a *webHookStoreAdapter) SaveHook(hook web.HookEntry) error {
 func (a *webHookStoreAdapter) DeleteHook(containerName, phase string) error {
 	return a.s.DeleteHook(containerName, phase)
 }
+
+// trustedKeyAdapter bridges store.Store to web.TrustedKeyStore.
+type trustedKeyAdapter struct{ s *store.Store }
+
+func (a *trustedKeyAdapter) ListTrustedKeys() ([]web.TrustedKey, error) {
+	keys, err := a.s.ListTrustedKeys()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]web.TrustedKey, len(keys))
+	for i, k := range keys {
+		result[i] = web.TrustedKey{Name: k.Name, PEM: k.PEM, Fingerprint: k.Fingerprint, AddedAt: k.AddedAt}
+	}
+	return result, nil
+}
+
+func (a *trustedKeyAdapter) SaveTrustedKey(k web.TrustedKey) error {
+	return a.s.SaveTrustedKey(store.TrustedKey{Name: k.Name, PEM: k.PEM, Fingerprint: k.Fingerprint, AddedAt: k.AddedAt})
+}
+
+func (a *trustedKeyAdapter) DeleteTrustedKey(name string) error {
+	return a.s.DeleteTrustedKey(name)
+}
+
+// verifyCacheAdapter bridges store.Store to verify.CacheStore.
+type verifyCacheAdapter struct{ s *store.Store }
+
+func (a *verifyCacheAdapter) GetVerifyCache(digest string) (verify.CacheEntry, bool, error) {
+	e, ok, err := a.s.GetVerifyCache(digest)
+	return verify.CacheEntry(e), ok, err
+}
+
+func (a *verifyCacheAdapter) SaveVerifyCache(e verify.CacheEntry) error {
+	return a.s.SaveVerifyCache(store.VerifyCacheEntry(e))
+}
+
+func (a *verifyCacheAdapter) ListVerifyCache() ([]verify.CacheEntry, error) {
+	entries, err := a.s.ListVerifyCache()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]verify.CacheEntry, len(entries))
+	for i, e := range entries {
+		result[i] = verify.CacheEntry(e)
+	}
+	return result, nil
+}
+
+func (a *verifyCacheAdapter) DeleteVerifyCache(digest string) (bool, error) {
+	return a.s.DeleteVerifyCache(digest)
+}
+
+func (a *verifyCacheAdapter) ClearVerifyCache() (int, error) {
+	return a.s.ClearVerifyCache()
+}
+
+// deployTokenAdapter bridges store.Store to web.DeployTokenStore.
+type deployTokenAdapter struct{ s *store.Store }
+
+func (a *deployTokenAdapter) ListDeployTokens() ([]web.DeployToken, error) {
+	tokens, err := a.s.ListDeployTokens()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]web.DeployToken, len(tokens))
+	for i, t := range tokens {
+		result[i] = web.DeployToken(t)
+	}
+	return result, nil
+}
+
+func (a *deployTokenAdapter) SaveDeployToken(t web.DeployToken) error {
+	return a.s.SaveDeployToken(store.DeployToken(t))
+}
+
+func (a *deployTokenAdapter) DeleteDeployToken(id string) error {
+	return a.s.DeleteDeployToken(id)
+}
+
+func (a *deployTokenAdapter) GetDeployRequest(tokenID, key string) (web.DeployRequest, bool, error) {
+	req, ok, err := a.s.GetDeployRequest(tokenID, key)
+	return web.DeployRequest(req), ok, err
+}
+
+func (a *deployTokenAdapter) ClaimDeployRequest(req web.DeployRequest) (web.DeployRequest, bool, error) {
+	held, claimed, err := a.s.ClaimDeployRequest(store.DeployRequest(req))
+	return web.DeployRequest(held), claimed, err
+}
+
+func (a *deployTokenAdapter) ReleaseDeployRequest(tokenID, key string) error {
+	return a.s.ReleaseDeployRequest(tokenID, key)
+}
+
+// timelineAdapter bridges store.Store to web.TimelineStore.
+type timelineAdapter struct{ s *store.Store }
+
+func (a *timelineAdapter) ListTimeline(q web.TimelineQuery) ([]web.TimelineEntry, string, error) {
+	entries, next, err := a.s.ListTimeline(store.TimelineQuery(q))
+	if errors.Is(err, store.ErrInvalidCursor) {
+		return nil, "", web.ErrInvalidTimelineCursor
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	result := make([]web.TimelineEntry, len(entries))
+	for i, e := range entries {
+		result[i] = web.TimelineEntry{
+			Timestamp: e.Timestamp,
+			Source:    e.Source,
+			Type:      e.Type,
+			Container: e.Container,
+			HostID:    e.HostID,
+			HostName:  e.HostName,
+			User:      e.User,
+			Message:   e.Message,
+			Failed:    e.Failed,
+			Cursor:    e.Cursor,
+		}
+	}
+	return result, next, nil
+}
+
+// auditAdapter bridges store.Store to web.AuditStore.
+type auditAdapter struct{ s *store.Store }
+
+func (a *auditAdapter) AppendAudit(e web.AuditEntry) error {
+	return a.s.AppendAudit(store.AuditEntry(e))
+}
+
+func (a *auditAdapter) ListAudit(q web.AuditQuery) ([]web.AuditEntry, string, error) {
+	entries, next, err := a.s.ListAudit(store.AuditQuery(q))
+	if errors.Is(err, store.ErrInvalidAuditCursor) {
+		return nil, "", web.ErrInvalidAuditCursor
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	result := make([]web.AuditEntry, len(entries))
+	for i, e := range entries {
+		result[i] = web.AuditEntry(e)
+	}
+	return result, next, nil
+}
+
+func (a *auditAdapter) PruneAudit(cutoff time.Time) (int, error) {
+	return a.s.PruneAudit(cutoff)
+}
@@ -31,12 +31,13 @@ func runAgent(ctx context.Context, cfg *config.Config, log *logging.Logger) {
 	fmt.Printf("SENTINEL_HOST_NAME=%s\n", cfg.HostName)
 	fmt.Printf("SENTINEL_CLUSTER_DIR=%s\n", cfg.ClusterDataDir)
 
-	db, err := store.Open(cfg.DBPath)
+	db, err := store.OpenDriver(cfg.DBDriver, cfg.DBPath)
 	if err != nil {
 		log.Error("failed to open database", "error", err)
 		os.Exit(1)
 	}
 	defer db.Close()
+	enableEncryption(db, cfg, log)
 
 	// Load Docker TLS settings for agent mode too.
 	var agentTLSCfg *docker.TLSConfig
@@ -174,6 +175,16 @@ func loadSettingBool(db *store.Store, key string) bool {
 	return loadSettingStr(db, key) == "true"
 }
 
+// loadSettingDuration loads a duration setting from the DB, returning 0 when
+// unset or invalid.
+func loadSettingDuration(db *store.Store, key string) time.Duration {
+	d, err := time.ParseDuration(loadSettingStr(db, key))
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
 // parseHeaders parses comma-separated "Key:Value" pairs into a map.
 func parseHeaders(s string) map[string]string {
 	if s == "" {
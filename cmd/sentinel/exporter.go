@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/config"
+	"github.com/Will-Luck/Docker-Sentinel/internal/logging"
+	"github.com/Will-Luck/Docker-Sentinel/internal/metrics"
+	"github.com/Will-Luck/Docker-Sentinel/internal/registry"
+	"github.com/Will-Luck/Docker-Sentinel/internal/web"
+)
+
+// runExporter starts Sentinel as a standalone registry rate limit exporter.
+// It probes the configured registries on a fixed interval and serves the
+// results as Prometheus metrics and JSON, without a database, a Docker
+// socket or the update engine, so it can run as a sidecar on CI runners.
+func runExporter(ctx context.Context, cfg *config.Config, log *logging.Logger) {
+	hosts := cfg.ExporterRegistryList()
+	fmt.Printf("SENTINEL_EXPORTER_REGISTRIES=%s\n", cfg.ExporterRegistries)
+	fmt.Printf("SENTINEL_EXPORTER_INTERVAL=%s\n", cfg.ExporterInterval)
+	fmt.Printf("SENTINEL_WEB_PORT=%s\n", cfg.WebPort)
+
+	creds := loadExporterCredentials(cfg.DockerConfig, log)
+
+	tracker := registry.NewRateLimitTracker()
+	for _, host := range hosts {
+		tracker.Discover(host, 0)
+	}
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		metrics.NewRateLimitCollector(tracker),
+		metrics.RegistryErrors,
+	)
+
+	srv := web.NewExporterServer(web.ExporterDeps{
+		RateTracker: &rateLimitAdapter{t: tracker},
+		Gatherer:    reg,
+		Log:         log.Logger,
+		Version:     versionString(),
+	})
+	go func() {
+		addr := net.JoinHostPort("", cfg.WebPort)
+		if err := srv.ListenAndServe(addr); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("exporter web server error", "error", err)
+			os.Exit(1)
+		}
+	}()
+	defer func() {
+		shutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutCtx)
+	}()
+
+	log.Info("starting exporter mode", "registries", hosts, "interval", cfg.ExporterInterval)
+	ticker := time.NewTicker(cfg.ExporterInterval)
+	defer ticker.Stop()
+	for {
+		probeRateLimits(ctx, tracker, hosts, creds, log)
+		select {
+		case <-ctx.Done():
+			log.Info("exporter shutdown complete")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// loadExporterCredentials reads registry logins from a Docker config.json.
+// A missing file is only worth a warning when the path was set explicitly.
+func loadExporterCredentials(path string, log *logging.Logger) []registry.RegistryCredential {
+	explicit := path != ""
+	if !explicit {
+		path = registry.DefaultDockerConfigPath()
+		if path == "" {
+			return nil
+		}
+	}
+	creds, err := registry.LoadDockerConfigCredentials(path)
+	if err != nil {
+		if explicit || !errors.Is(err, os.ErrNotExist) {
+			log.Warn("failed to load registry credentials, probing anonymously", "path", path, "error", err)
+		}
+		return nil
+	}
+	registries := make([]string, len(creds))
+	for i, c := range creds {
+		registries[i] = c.Registry
+	}
+	log.Info("loaded registry credentials", "path", path, "registries", registries)
+	return creds
+}
+
+// probeRateLimits probes each registry once and records what it reports.
+// Failures are logged and counted; the last known state is kept.
+func probeRateLimits(ctx context.Context, tracker *registry.RateLimitTracker, hosts []string, creds []registry.RegistryCredential, log *logging.Logger) {
+	for _, host := range hosts {
+		if ctx.Err() != nil {
+			return
+		}
+		cred := registry.FindByRegistry(creds, registry.NormaliseRegistryHost(host))
+		probeCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		headers, err := registry.ProbeRateLimit(probeCtx, host, cred)
+		cancel()
+		if err != nil {
+			log.Warn("rate limit probe failed", "registry", host, "error", err)
+			metrics.RegistryErrors.WithLabelValues(registry.NormaliseRegistryHost(host)).Inc()
+			continue
+		}
+		tracker.Record(host, headers)
+		tracker.SetAuth(host, cred != nil)
+	}
+}
@@ -11,42 +11,51 @@ import (
 	"os/signal"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/Will-Luck/Docker-Sentinel/internal/auth"
 	"github.com/Will-Luck/Docker-Sentinel/internal/backup"
 	"github.com/Will-Luck/Docker-Sentinel/internal/clock"
+	"github.com/Will-Luck/Docker-Sentinel/internal/cluster"
 	"github.com/Will-Luck/Docker-Sentinel/internal/config"
+	"github.com/Will-Luck/Docker-Sentinel/internal/ctl"
 	"github.com/Will-Luck/Docker-Sentinel/internal/docker"
 	"github.com/Will-Luck/Docker-Sentinel/internal/engine"
 	"github.com/Will-Luck/Docker-Sentinel/internal/events"
 	"github.com/Will-Luck/Docker-Sentinel/internal/hooks"
+	"github.com/Will-Luck/Docker-Sentinel/internal/lifecycle"
 	"github.com/Will-Luck/Docker-Sentinel/internal/logging"
 	"github.com/Will-Luck/Docker-Sentinel/internal/metrics"
 	"github.com/Will-Luck/Docker-Sentinel/internal/notify"
 	"github.com/Will-Luck/Docker-Sentinel/internal/npm"
 	portainerpkg "github.com/Will-Luck/Docker-Sentinel/internal/portainer"
 	"github.com/Will-Luck/Docker-Sentinel/internal/registry"
+	"github.com/Will-Luck/Docker-Sentinel/internal/report"
 	"github.com/Will-Luck/Docker-Sentinel/internal/scanner"
 	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+	"github.com/Will-Luck/Docker-Sentinel/internal/ticket"
 	"github.com/Will-Luck/Docker-Sentinel/internal/verify"
 	"github.com/Will-Luck/Docker-Sentinel/internal/web"
 )
 
-// version and commit are set at build time via ldflags:
+// version, commit and buildDate are set at build time via ldflags:
 //
-//	-X main.version=$(VERSION) -X main.commit=$(COMMIT)
+//	-X main.version=$(VERSION) -X main.commit=$(COMMIT) -X main.buildDate=$(BUILD_DATE)
 //
 // version defaults to "dev" for untagged local builds.
 // commit defaults to "unknown" when git info isn't available (e.g. Docker build
 // without --build-arg COMMIT=...).
+// buildDate is an RFC 3339 timestamp, or "unknown".
 var version = "dev"
 var commit = "unknown"
+var buildDate = "unknown"
 
 // versionString returns the formatted version for display, including the
 // short commit hash in parentheses when available.
@@ -58,18 +67,49 @@ func versionString() string {
 	return version
 }
 
+// enableEncryption turns on encryption at rest when a key is configured,
+// encrypting any plaintext secrets. A wrong or unreadable key is fatal:
+// carrying on would leave secrets unreadable or encrypted under two keys.
+func enableEncryption(db *store.Store, cfg *config.Config, log *logging.Logger) {
+	secret, err := cfg.EncryptionSecret()
+	if err != nil {
+		log.Error("failed to load encryption key", "error", err)
+		os.Exit(1)
+	}
+	if secret == nil {
+		return
+	}
+	migrated, err := db.EnableEncryption(store.EncryptionKey(secret))
+	if err != nil {
+		log.Error("failed to enable encryption at rest", "error", err)
+		os.Exit(1)
+	}
+	log.Info("encryption at rest enabled", "migrated", migrated)
+}
+
 func main() {
-	// Subcommand dispatch: "sentinel server" or "sentinel agent".
-	// Bare "sentinel" defaults to server mode for backwards compatibility.
+	// Subcommand dispatch: "sentinel server", "sentinel agent",
+	// "sentinel exporter", "sentinel ctl" or "sentinel migrate". Bare
+	// "sentinel" defaults to server mode for backwards compatibility.
 	mode := ""
 	if len(os.Args) > 1 {
 		switch os.Args[1] {
+		case "ctl":
+			ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+			code := ctl.Run(ctx, os.Args[2:], os.Stdout, os.Stderr, os.Getenv)
+			cancel()
+			os.Exit(code)
+		case "migrate":
+			os.Exit(runMigrate(os.Args[2:], os.Stdout, os.Stderr))
 		case "server":
 			mode = "server"
 			os.Args = append(os.Args[:1], os.Args[2:]...) // strip subcommand
 		case "agent":
 			mode = "agent"
 			os.Args = append(os.Args[:1], os.Args[2:]...) // strip subcommand
+		case "exporter":
+			mode = "exporter"
+			os.Args = append(os.Args[:1], os.Args[2:]...) // strip subcommand
 		}
 	}
 
@@ -90,6 +130,15 @@ func main() {
 		cfg.CookieSecure = cfg.TLSEnabled()
 	}
 	log := logging.New(cfg.LogJSON)
+	if lvl, err := logging.ParseLevel(cfg.LogLevel); err == nil {
+		log.Levels().SetBase(lvl)
+	}
+	// Browsers drop SameSite=None cookies without Secure; TLS is usually
+	// terminated by the proxy that makes None necessary.
+	if strings.EqualFold(cfg.CookieSameSite, "none") && !cfg.CookieSecure {
+		cfg.CookieSecure = true
+		log.Warn("SENTINEL_COOKIE_SAMESITE=none needs secure cookies, enabling SENTINEL_COOKIE_SECURE")
+	}
 
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
 	defer cancel()
@@ -106,10 +155,18 @@ func main() {
 		return
 	}
 
+	// Exporter mode only probes registry rate limits: no database, Docker
+	// socket or update engine.
+	if cfg.IsExporter() {
+		runExporter(ctx, cfg, log)
+		return
+	}
+
 	fmt.Printf("SENTINEL_POLL_INTERVAL=%s\n", cfg.PollInterval())
 	fmt.Printf("SENTINEL_GRACE_PERIOD=%s\n", cfg.GracePeriod())
 	fmt.Printf("SENTINEL_DEFAULT_POLICY=%s\n", cfg.DefaultPolicy())
 	fmt.Printf("SENTINEL_DB_PATH=%s\n", cfg.DBPath)
+	fmt.Printf("SENTINEL_DB_DRIVER=%s\n", cfg.DBDriver)
 	fmt.Printf("SENTINEL_WEB_ENABLED=%t\n", cfg.WebEnabled)
 	fmt.Printf("SENTINEL_WEB_PORT=%s\n", cfg.WebPort)
 	fmt.Printf("SENTINEL_TLS_CERT=%s\n", cfg.TLSCert)
@@ -118,13 +175,14 @@ func main() {
 	fmt.Printf("SENTINEL_WEBAUTHN_RPID=%s\n", cfg.WebAuthnRPID)
 
 	// Open DB first so we can load TLS settings before creating the Docker client.
-	db, err := store.Open(cfg.DBPath)
+	db, err := store.OpenDriver(cfg.DBDriver, cfg.DBPath)
 	if err != nil {
 		log.Error("failed to open database", "error", err)
 		os.Exit(1)
 	}
 	var dbClosed sync.Once
 	defer dbClosed.Do(func() { db.Close() })
+	enableEncryption(db, cfg, log)
 
 	// Load Docker TLS certificate paths from BoltDB.
 	var tlsCfg *docker.TLSConfig
@@ -153,12 +211,22 @@ func main() {
 		log.Error("failed to seed built-in roles", "error", err)
 		os.Exit(1)
 	}
+	if migrated, err := db.MigrateTokenPermissions(); err != nil {
+		log.Warn("failed to migrate API token permissions", "error", err)
+	} else if migrated {
+		log.Info("granted cluster and self-update permissions to settings.modify API tokens")
+	}
 
 	// Create auth service.
 	var webAuthnCreds auth.WebAuthnCredentialStore
 	if cfg.WebAuthnEnabled() {
 		webAuthnCreds = db
 	}
+	cookieSameSite, _ := auth.ParseSameSite(cfg.CookieSameSite) // checked by Validate
+	var allowedOrigins []string
+	for _, o := range cfg.AllowedOriginList() {
+		allowedOrigins = append(allowedOrigins, auth.NormaliseOrigin(o))
+	}
 	authSvc := auth.NewService(auth.ServiceConfig{
 		Users:          db,
 		Sessions:       db,
@@ -169,6 +237,9 @@ func main() {
 		PendingTOTP:    db,
 		Log:            log.Logger,
 		CookieSecure:   cfg.CookieSecure,
+		CookieDomain:   cfg.CookieDomain,
+		CookieSameSite: cookieSameSite,
+		AllowedOrigins: allowedOrigins,
 		SessionExpiry:  cfg.SessionExpiry,
 		AuthEnabledEnv: cfg.AuthEnabled,
 	})
@@ -347,9 +418,17 @@ func main() {
 		log.Info("loaded persisted dependency-aware setting", "enabled", saved == "true")
 	}
 
+	// Component loggers tag their records and can be made more verbose at
+	// runtime from /api/settings/logging.
+	engineLog := log.Component(logging.ComponentEngine)
+	registryLog := log.Component(logging.ComponentRegistry)
+	clusterLog := log.Component(logging.ComponentCluster)
+	webLog := log.Component(logging.ComponentWeb)
+	notifyLog := log.Component(logging.ComponentNotify)
+
 	// Build notification chain from persisted channels, with env var fallback.
 	var notifiers []notify.Notifier
-	notifiers = append(notifiers, notify.NewLogNotifier(log))
+	notifiers = append(notifiers, notify.NewLogNotifier(notifyLog))
 
 	channels, err := db.GetNotificationChannels()
 	if err != nil {
@@ -377,19 +456,32 @@ func main() {
 		}
 	}
 
+	built := notify.BuildChannels(channels, channels, func(ch notify.Channel, buildErr error) {
+		log.Warn("failed to build notifier", "channel", ch.Name, "error", buildErr)
+	})
+	if templates, err := db.GetAllNotifyTemplates(); err != nil {
+		log.Warn("failed to load notification templates", "error", err)
+	} else {
+		notify.ApplyTemplates(built, templates)
+	}
 	for _, ch := range channels {
-		if !ch.Enabled {
-			continue
+		if ch.Enabled {
+			log.Info("notification channel enabled", "name", ch.Name, "type", string(ch.Type))
 		}
-		n, buildErr := notify.BuildFilteredNotifier(ch)
-		if buildErr != nil {
-			log.Warn("failed to build notifier", "channel", ch.Name, "error", buildErr)
-			continue
-		}
-		notifiers = append(notifiers, n)
-		log.Info("notification channel enabled", "name", ch.Name, "type", string(ch.Type))
 	}
-	notifier := notify.NewMulti(log, notifiers...)
+	notifiers = append(notifiers, built...)
+	notifier := notify.NewMulti(notifyLog, notifiers...)
+	// Per-container channel targets from the container's notification preference.
+	notifier.SetRouter(func(name string) []string {
+		pref, err := db.GetNotifyPref(name)
+		if err != nil || pref == nil {
+			return nil
+		}
+		return pref.Channels
+	})
+
+	// Links back to the web UI in notifications need the URL users reach it at.
+	notifier.SetExternalURL(loadSettingStr(db, "external_url"))
 
 	// Load notification batch window from settings (default: 0 = disabled).
 	if bw := loadSettingStr(db, "notification_batch_window"); bw != "" && bw != "0" {
@@ -414,7 +506,17 @@ func main() {
 	}
 
 	clk := clock.Real{}
-	checker := registry.NewChecker(client, log)
+	checker := registry.NewChecker(client, registryLog)
+	if cfg.UpdateSources != "" {
+		srcs, srcErr := registry.ParseHTTPSources(cfg.UpdateSources)
+		if srcErr != nil {
+			log.Error("invalid SENTINEL_UPDATE_SOURCES, custom sources disabled", "error", srcErr)
+		}
+		for _, src := range srcs {
+			registry.RegisterSource(src)
+			log.Info("custom update source registered", "name", src.SourceName, "pattern", src.Pattern)
+		}
+	}
 	rateTracker := registry.NewRateLimitTracker()
 	if rlData, rlErr := db.LoadRateLimits(); rlErr == nil && rlData != nil {
 		if importErr := rateTracker.Import(rlData); importErr != nil {
@@ -423,6 +525,7 @@ func main() {
 			log.Info("loaded persisted rate limits")
 		}
 	}
+	prometheus.MustRegister(metrics.NewRateLimitCollector(rateTracker))
 	ghcrCache := registry.NewGHCRCache(24 * time.Hour)
 	if ghcrData, ghcrErr := db.LoadGHCRCache(); ghcrErr == nil && ghcrData != nil {
 		if importErr := ghcrCache.Import(ghcrData); importErr != nil {
@@ -438,17 +541,62 @@ func main() {
 		checker.SetDefaultScope(docker.ScopeStrict)
 	}
 	bus := events.New()
-	queue := engine.NewQueue(db, bus, log.Logger)
-	updater := engine.NewUpdater(client, checker, db, queue, cfg, log, clk, notifier, bus)
+	queue := engine.NewQueue(db, bus, engineLog.Logger)
+	updater := engine.NewUpdater(client, checker, db, queue, cfg, engineLog, clk, notifier, bus)
 	updater.SetSettingsReader(db)
 	updater.SetRateLimitTracker(rateTracker)
 	updater.SetRateLimitSaver(db.SaveRateLimits)
 	updater.SetGHCRCache(ghcrCache)
 	updater.SetGHCRSaver(db.SaveGHCRCache)
 
+	// Pre-flight permission probes: a socket proxy that forbids calls an
+	// update needs is reported now rather than after a container is removed.
+	updater.SetCapabilityProber(client)
+	go func() {
+		if reason := updater.Capabilities(ctx).Reason(docker.UpdateOperations...); reason != "" {
+			log.Warn("container updates will be refused", "reason", reason)
+		}
+	}()
+
+	// Config previews read default config files out of scratch containers;
+	// they only run when config_diff or sentinel.config-diff enables them.
+	updater.SetConfigFileReader(client)
+
+	// Machine event sink: every update state transition goes to one endpoint.
+	lifecycleSink := lifecycle.New(func() lifecycle.Config {
+		url, _ := db.LoadSetting("event_sink_url")
+		format, _ := db.LoadSetting("event_sink_format")
+		return lifecycle.Config{URL: url, Format: format}
+	}, log.Logger)
+	queue.SetLifecycleSink(lifecycleSink)
+	updater.SetLifecycleSink(lifecycleSink)
+	go lifecycleSink.Run(ctx)
+
+	// Ticketing: one issue per pending manual update, closed once applied.
+	releaseSources := func() []registry.ReleaseSource {
+		srcs, err := db.GetReleaseSources()
+		if err != nil {
+			return nil
+		}
+		return srcs
+	}
+	ticketMgr := ticket.New(func() ticket.Config {
+		var c ticket.Config
+		if raw, _ := db.LoadSetting(store.SettingTicketing); raw != "" {
+			if err := json.Unmarshal([]byte(raw), &c); err != nil {
+				log.Warn("invalid ticketing settings, integration disabled", "error", err)
+				return ticket.Config{}
+			}
+		}
+		return c
+	}, db, ticketDetails(queue, client, releaseSources), log.Logger)
+	lifecycleSink.Observe(ticketMgr.Observe)
+	go ticketMgr.Run(ctx)
+
 	// Create hook runner if hooks are enabled.
-	hookRunner := hooks.NewRunner(client, &hookStoreAdapter{db}, log.Logger)
+	hookRunner := hooks.NewRunner(client, &hookStoreAdapter{db}, engineLog.Logger)
 	updater.SetHookRunner(hookRunner)
+	updater.SetReleaseSources(releaseSources)
 
 	// Initialise vulnerability scanner (Trivy) if configured.
 	{
@@ -458,10 +606,14 @@ func main() {
 		}
 		var scanOpts []scanner.Option
 		scanOpts = append(scanOpts, scanner.WithTrivyPath(trivyPath))
+		trivyServer := loadSettingStr(db, store.SettingTrivyServer)
+		if trivyServer != "" {
+			scanOpts = append(scanOpts, scanner.WithServer(trivyServer))
+		}
 		imgScanner := scanner.New(log, scanOpts...)
 		if imgScanner.Available() {
 			updater.SetScanner(imgScanner)
-			log.Info("vulnerability scanner available", "path", trivyPath)
+			log.Info("vulnerability scanner available", "path", trivyPath, "server", trivyServer)
 		} else {
 			log.Info("trivy not found, vulnerability scanning disabled")
 		}
@@ -475,6 +627,7 @@ func main() {
 	}
 
 	// Initialise signature verifier (cosign) if configured.
+	var imgVerifier *verify.Verifier
 	{
 		cosignPath := loadSettingStr(db, store.SettingCosignPath)
 		if cosignPath == "" {
@@ -488,7 +641,34 @@ func main() {
 		if kp := loadSettingStr(db, store.SettingCosignKeyPath); kp != "" {
 			verifyOpts = append(verifyOpts, verify.WithKeyPath(kp))
 		}
-		imgVerifier := verify.New(log, verifyOpts...)
+		verifyOpts = append(verifyOpts,
+			verify.WithIdentity(loadSettingStr(db, store.SettingCosignIdentity), loadSettingStr(db, store.SettingCosignIssuer)),
+			verify.WithTrustedKeys(func() []verify.TrustedKey {
+				stored, err := db.ListTrustedKeys()
+				if err != nil {
+					log.Warn("failed to load trusted keys", "error", err)
+					return nil
+				}
+				keys := make([]verify.TrustedKey, len(stored))
+				for i, k := range stored {
+					keys[i] = verify.TrustedKey{Name: k.Name, PEM: k.PEM}
+				}
+				return keys
+			}),
+			// Verified digests are remembered across restarts so unchanged
+			// images are not re-verified on every update.
+			verify.WithCache(verify.NewCache(&verifyCacheAdapter{db}, func() time.Duration {
+				v := loadSettingStr(db, store.SettingVerifyCacheTTL)
+				if v == "0" {
+					return 0
+				}
+				if d, err := docker.ParseDurationWithDays(v); err == nil && d > 0 {
+					return d
+				}
+				return verify.DefaultCacheTTL
+			}, log)),
+		)
+		imgVerifier = verify.New(log, verifyOpts...)
 		if imgVerifier.Available() {
 			updater.SetVerifier(imgVerifier)
 			log.Info("signature verifier available", "path", cosignPath)
@@ -523,6 +703,7 @@ func main() {
 						} else {
 							updater.SetHADiscovery(ha)
 							defer ha.Close()
+							go updater.RunHAStateSync(ctx)
 							log.Info("home assistant MQTT discovery enabled", "broker", mqttSettings.Broker)
 						}
 					}
@@ -548,25 +729,53 @@ func main() {
 		log.Info("swarm mode detected — service monitoring enabled")
 	}
 
-	selfUpdater := engine.NewSelfUpdater(client, log)
-	scheduler := engine.NewScheduler(updater, cfg, log, clk)
+	// Reconcile updates cut off by the previous shutdown or a crash.
+	if n := updater.RecoverInterrupted(ctx); len(n) > 0 {
+		log.Warn("reconciled interrupted updates", "containers", n)
+	}
+
+	// A restart marker means the previous instance self-updated into this one.
+	var selfUpdated *web.SelfUpdateInfo
+	if m, ok, err := db.TakeRestartMarker(); err != nil {
+		log.Warn("failed to read restart marker", "error", err)
+	} else if ok {
+		selfUpdated = &web.SelfUpdateInfo{FromVersion: m.FromVersion, ToVersion: version, StartedAt: m.StartedAt, CompletedAt: time.Now().UTC()}
+		log.Info("self-update complete", "from", m.FromVersion, "to", version)
+	}
+
+	selfUpdater := engine.NewSelfUpdater(client, engineLog)
+	selfUpdater.SetRestartMarker(db, version)
+	scheduler := engine.NewScheduler(updater, cfg, engineLog, clk)
 	scheduler.SetSettingsReader(db)
+	scheduler.SetRunStore(db)
 	scheduler.SetSelfUpdater(selfUpdater)
 	scheduler.SetReadyGate(scanGate)
-	digestSched := engine.NewDigestScheduler(db, queue, notifier, bus, log, clk)
+
+	// Pick up containers started between scans from the Docker event stream.
+	discovery := engine.NewDiscovery(updater, client, engineLog, clk)
+	discovery.SetSettingsReader(db)
+	go discovery.Run(ctx)
+	digestSched := engine.NewDigestScheduler(db, queue, notifier, bus, engineLog, clk)
 	digestSched.SetSettingsReader(db)
+	digestSched.SetCatchUp(cfg.CatchUp)
+	digestSched.SetChannelSource(db.GetNotificationChannels)
+	digestSched.SetContainerInfo(updater.DigestContainerInfo)
 
 	// Cluster lifecycle — centralised start/stop via clusterManager.
 	clusterCtrl := web.NewClusterController()
 	cm := &clusterManager{
 		db:      db,
 		bus:     bus,
-		log:     log.Logger,
+		log:     clusterLog.Logger,
 		updater: updater,
+		cfg:     cfg,
 		ctrl:    clusterCtrl,
 		dataDir: cfg.ClusterDataDir,
 	}
 
+	// Weekly summary report, sent through the notification channels.
+	reportSched := report.NewScheduler(report.NewBuilder(reportSource(db, queue, clusterCtrl), clk), notifier, db, clk, log.Logger)
+
 	// Env var takes precedence; fall back to DB setting.
 	clusterEnabled := cfg.ClusterEnabled
 	if !clusterEnabled {
@@ -691,7 +900,7 @@ func main() {
 	if v := loadSettingStr(db, "backup_dir"); v != "" {
 		backupDir = v
 	}
-	backupMgr, backupErr := backup.NewManager(db.DB(), backupDir, log)
+	backupMgr, backupErr := backup.NewManager(db, backupDir, log)
 	if backupErr != nil {
 		log.Warn("backup manager init failed", "error", backupErr)
 	}
@@ -711,12 +920,40 @@ func main() {
 			}
 		}
 		if ret := loadSettingStr(db, "backup_retention"); ret != "" {
-			if n, err := strconv.Atoi(ret); err == nil && n > 0 {
+			if n, err := strconv.Atoi(ret); err == nil && n >= 0 {
 				backupMgr.SetRetention(n)
 			}
 		}
 	}
 
+	// Retention janitor: prune history, logs and snapshots past their
+	// configured retention, and expired deploy hook idempotency keys, at
+	// startup and then hourly.
+	go func() {
+		prune := func() {
+			rep, pruneErr := db.ApplyRetention(time.Now())
+			if pruneErr != nil {
+				log.Warn("retention prune failed", "error", pruneErr)
+			} else if rep.Total() > 0 {
+				log.Info("pruned old entries", "history", rep.History, "logs", rep.Logs, "snapshots", rep.Snapshots)
+			}
+			if _, pruneErr := db.PruneDeployRequests(time.Now().Add(-store.DeployRequestTTL)); pruneErr != nil {
+				log.Warn("failed to prune deploy requests", "error", pruneErr)
+			}
+		}
+		prune()
+		ticker := time.NewTicker(1 * time.Hour)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				prune()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
 	// Start backup scheduler if configured.
 	var backupSched *backup.Scheduler
 	if backupMgr != nil {
@@ -742,8 +979,9 @@ func main() {
 			Snapshots:           &snapshotAdapter{db},
 			Rollback:            &rollbackAdapter{d: client, s: db, log: log},
 			Restarter:           &restartAdapter{client},
-			Registry:            &registryAdapter{log: log},
-			TagLister:           &tagListerAdapter{log: log},
+			Registry:            &registryAdapter{log: registryLog},
+			TagLister:           &tagListerAdapter{log: registryLog},
+			ImageDates:          &imageDatesAdapter{client: client},
 			RegistryChecker:     &registryCheckerAdapter{checker: checker},
 			VersionScope:        &versionScopeAdapter{checker: checker},
 			Policy:              &policyStoreAdapter{db},
@@ -752,9 +990,22 @@ func main() {
 			SettingsStore:       &settingsStoreAdapter{db},
 			Stopper:             &stopAdapter{client},
 			Starter:             &startAdapter{client},
+			Pauser:              &pauseAdapter{client},
+			Killer:              &killAdapter{client},
 			LogViewer:           &dockerAdapter{client},
 			LogStreamer:         &dockerAdapter{client},
 			SelfUpdater:         &selfUpdateAdapter{updater: selfUpdater},
+			Stacks:              &stackAdapter{stacks: engine.NewStackUpdater(updater)},
+			Capabilities:        updater,
+			ComposeSync:         updater,
+			UpdateLocks:         updater,
+			Rollouts:            updater,
+			ComposePaths:        &composePathAdapter{db},
+			TrustedKeys:         &trustedKeyAdapter{db},
+			VerifyCache:         imgVerifier,
+			DeployTokens:        &deployTokenAdapter{db},
+			Timeline:            &timelineAdapter{db},
+			Audit:               &auditAdapter{db},
 			NotifyConfig:        &notifyConfigAdapter{db},
 			NotifyReconfigurer:  notifier,
 			NotifyState:         &notifyStateAdapter{db},
@@ -765,16 +1016,30 @@ func main() {
 			GHCRCache:           &ghcrCacheAdapter{c: ghcrCache},
 			HookStore:           &webHookStoreAdapter{db},
 			ReleaseSources:      &releaseSourceAdapter{db},
+			Unmanaged:           &unmanagedAdapter{db},
+			Versions:            &versionAdapter{db},
+			Validation:          &validationAdapter{db},
+			Preconditions:       &preconditionAdapter{db},
+			Schedules:           &scheduleAdapter{db},
+			StackWindows:        &stackWindowAdapter{db},
+			Pins:                &pinAdapter{s: db, d: client},
+			Retries:             &retryAdapter{db},
 			ImageManager:        &imageAdapter{client: client},
+			Resources:           &resourceAdapter{client: client},
 			Cluster:             clusterCtrl,
 			// Backup is set below if backupMgr is available.
-			MetricsEnabled: cfg.MetricsEnabled,
-			Digest:         digestSched,
-			Auth:           authSvc,
-			Version:        versionString(),
-			ClusterPort:    cfg.ClusterPort,
-			Commit:         commit,
-			Log:            log.Logger,
+			MetricsEnabled:  cfg.MetricsEnabled,
+			Digest:          digestSched,
+			Reports:         reportSched,
+			Auth:            authSvc,
+			Version:         versionString(),
+			ClusterPort:     cfg.ClusterPort,
+			Commit:          commit,
+			BuildDate:       buildDate,
+			SelfUpdated:     selfUpdated,
+			MinAgentVersion: cluster.MinAgentVersion,
+			LogLevels:       log.Levels(),
+			Log:             webLog.Logger,
 		}
 		if isSwarm {
 			webDeps.Swarm = &swarmAdapter{client: client, updater: updater}
@@ -785,8 +1050,10 @@ func main() {
 			webDeps.NPM = npmProvider
 		}
 		if backupMgr != nil {
-			webDeps.Backup = &backupAdapter{backupMgr}
+			webDeps.Backup = &backupAdapter{m: backupMgr, sched: backupSched}
 		}
+		webDeps.Restorer = &restoreAdapter{db}
+		webDeps.Maintainer = &maintenanceAdapter{db}
 		// Factory to create NPM provider on demand (e.g. after first-time UI config).
 		webDeps.NPMInitFunc = func(initCtx context.Context) (web.NPMProvider, error) {
 			u, _ := db.LoadSetting(store.SettingNPMURL)
@@ -807,6 +1074,13 @@ func main() {
 		webDeps.PortConfigs = &portConfigStoreAdapter{s: db}
 		srv := web.NewServer(webDeps)
 		srv.SetClusterLifecycle(cm)
+
+		// Telegram approve/reject buttons act on the queue through the server.
+		approvals := notify.NewTelegramReceiver(ctx, srv, notifyLog)
+		approvals.Reload(channels)
+		srv.SetApprovalReceiver(approvals)
+
+		srv.SetAPIRateLimit(cfg.APIRateLimit, cfg.APIRateBurst)
 		if freshSetup {
 			srv.SetScanGate(scanGate)
 		}
@@ -816,14 +1090,14 @@ func main() {
 			wa, waErr := webauthn.New(&webauthn.Config{
 				RPDisplayName: cfg.WebAuthnDisplayName,
 				RPID:          cfg.WebAuthnRPID,
-				RPOrigins:     cfg.WebAuthnOriginList(),
+				RPOrigins:     cfg.WebAuthnRPOrigins(),
 			})
 			if waErr != nil {
 				log.Error("failed to create WebAuthn instance", "error", waErr)
 				os.Exit(1)
 			}
 			srv.SetWebAuthn(wa)
-			log.Info("webauthn passkeys enabled", "rpid", cfg.WebAuthnRPID, "origins", cfg.WebAuthnOrigins)
+			log.Info("webauthn passkeys enabled", "rpid", cfg.WebAuthnRPID, "origins", wa.Config.RPOrigins)
 		}
 
 		// Configure OIDC provider if settings are present.
@@ -836,6 +1110,10 @@ func main() {
 		if raw := loadSettingStr(db, "oidc_group_mappings"); raw != "" {
 			_ = json.Unmarshal([]byte(raw), &oidcGroupMappings)
 		}
+		var oidcRoleRules []auth.RoleMappingRule
+		if raw := loadSettingStr(db, "oidc_role_rules"); raw != "" {
+			_ = json.Unmarshal([]byte(raw), &oidcRoleRules)
+		}
 
 		oidcCfg := auth.OIDCConfig{
 			Enabled:       loadSettingBool(db, "oidc_enabled"),
@@ -847,6 +1125,11 @@ func main() {
 			DefaultRole:   loadSettingStr(db, "oidc_default_role"),
 			GroupClaim:    oidcGroupClaim,
 			GroupMappings: oidcGroupMappings,
+			RoleRules:     oidcRoleRules,
+
+			PostLogoutRedirectURL: loadSettingStr(db, "oidc_post_logout_redirect_url"),
+			BackchannelLogout:     loadSettingBool(db, "oidc_backchannel_logout"),
+			RevalidateInterval:    loadSettingDuration(db, "oidc_revalidate_interval"),
 		}
 		oidcProvider, oidcErr := auth.NewOIDCProvider(context.Background(), oidcCfg)
 		if oidcErr != nil {
@@ -908,6 +1191,31 @@ func main() {
 				log.Error("digest scheduler error", "error", err)
 			}
 		}()
+		go reportSched.Run(ctx)
+
+		// OIDC revalidation goroutine — re-check SSO sessions with the IdP so
+		// users disabled there lose access without waiting for expiry.
+		go func() {
+			ticker := time.NewTicker(1 * time.Minute)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					provider := srv.OIDCProvider()
+					if provider == nil || provider.RevalidateInterval() <= 0 {
+						continue
+					}
+					n, revalErr := authSvc.RevalidateOIDCSessions(ctx, provider, provider.RevalidateInterval())
+					if revalErr != nil {
+						log.Warn("OIDC session revalidation failed", "error", revalErr)
+					} else if n > 0 {
+						log.Info("ended SSO sessions revoked by identity provider", "count", n)
+					}
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
 
 		// Session cleanup goroutine — purge expired sessions hourly.
 		go func() {
@@ -936,6 +1244,11 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Let in-flight updates reach a safe point before exiting.
+	if stuck := updater.Drain(cfg.DrainTimeout); len(stuck) > 0 {
+		log.Warn("exiting with updates still in flight", "containers", stuck)
+	}
+
 	// Flush any buffered notifications before exit.
 	notifier.Stop()
 
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+)
+
+// runMigrate executes "sentinel migrate", a one-shot copy of a BoltDB
+// database into a new SQLite database. Sentinel must be stopped while it
+// runs. It returns the process exit code.
+func runMigrate(args []string, stdout, stderr io.Writer) int {
+	from := os.Getenv("SENTINEL_DB_PATH")
+	if from == "" {
+		from = "/data/sentinel.db"
+	}
+	fs := flag.NewFlagSet("sentinel migrate", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&from, "from", from, "BoltDB database to read")
+	to := fs.String("to", "", "SQLite database to create (default: sentinel.sqlite next to -from)")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(stderr, "unexpected argument %q\n", fs.Arg(0))
+		return 2
+	}
+	if *to == "" {
+		*to = filepath.Join(filepath.Dir(from), "sentinel.sqlite")
+	}
+
+	stats, err := store.MigrateBoltToSQLite(from, *to)
+	if err != nil {
+		fmt.Fprintf(stderr, "migrate: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "copied %d buckets, %d keys from %s to %s\n", stats.Buckets, stats.Keys, from, *to)
+	fmt.Fprintf(stdout, "start Sentinel with SENTINEL_DB_DRIVER=sqlite SENTINEL_DB_PATH=%s\n", *to)
+	return 0
+}
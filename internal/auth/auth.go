@@ -20,6 +20,9 @@ const (
 	PermUsersManage        Permission = "users.manage"
 	PermLogsView           Permission = "logs.view"
 	PermHistoryView        Permission = "history.view"
+	PermSystemSelfUpdate   Permission = "system.selfupdate"
+	PermClusterView        Permission = "cluster.view"
+	PermClusterManage      Permission = "cluster.manage"
 )
 
 // AllPermissions returns every defined permission.
@@ -28,9 +31,17 @@ func AllPermissions() []Permission {
 		PermContainersView, PermContainersUpdate, PermContainersApprove,
 		PermContainersRollback, PermContainersManage, PermSettingsView,
 		PermSettingsModify, PermUsersManage, PermLogsView, PermHistoryView,
+		PermSystemSelfUpdate, PermClusterView, PermClusterManage,
 	}
 }
 
+// SettingsModifySplit lists the permissions that used to be covered by
+// settings.modify. Tokens scoped to settings.modify are granted them on
+// upgrade so they keep the access they had.
+func SettingsModifySplit() []Permission {
+	return []Permission{PermSystemSelfUpdate, PermClusterView, PermClusterManage}
+}
+
 // User represents an authenticated user.
 type User struct {
 	ID             string    `json:"id"`
@@ -46,6 +57,10 @@ type User struct {
 	TOTPSecret     string    `json:"totp_secret,omitempty"`      // base32-encoded TOTP secret
 	TOTPEnabled    bool      `json:"totp_enabled,omitempty"`     // whether 2FA is active
 	RecoveryCodes  []string  `json:"recovery_codes,omitempty"`   // one-time recovery codes
+
+	// ContainerScope limits the containers the user can see and act on;
+	// nil means all of them.
+	ContainerScope *ContainerScope `json:"container_scope,omitempty"`
 }
 
 // EnsureWebAuthnUserID generates a random WebAuthn user ID if one isn't set.
@@ -62,16 +77,40 @@ func (u *User) EnsureWebAuthnUserID() (bool, error) {
 	return true, nil
 }
 
+// Session sources record how a session was created.
+const (
+	SessionSourcePassword = "password"
+	SessionSourceTOTP     = "totp"          // password plus authenticator code
+	SessionSourceRecovery = "recovery_code" // password plus 2FA recovery code
+	SessionSourcePasskey  = "passkey"
+	SessionSourceOIDC     = "oidc"
+	SessionSourceSetup    = "setup" // first admin created by the setup wizard
+)
+
 // Session represents an active login session.
 type Session struct {
 	Token     string    `json:"token"` // 64-char hex token (also the bucket key)
 	UserID    string    `json:"user_id"`
 	IP        string    `json:"ip"`
 	UserAgent string    `json:"user_agent"`
+	Source    string    `json:"source,omitempty"` // how the session was created (SessionSource*); empty for older sessions
 	CreatedAt time.Time `json:"created_at"`
 	ExpiresAt time.Time `json:"expires_at"`
+
+	// OIDC fields are set only for sessions created by SSO login. They let
+	// Sentinel end the IdP session on logout (ID token hint), match
+	// back-channel logout requests (subject, sid) and re-check the session
+	// with the IdP (refresh token).
+	OIDCSubject      string    `json:"oidc_subject,omitempty"`
+	OIDCSessionID    string    `json:"oidc_sid,omitempty"`
+	OIDCIDToken      string    `json:"oidc_id_token,omitempty"`
+	OIDCRefreshToken string    `json:"oidc_refresh_token,omitempty"`
+	OIDCCheckedAt    time.Time `json:"oidc_checked_at,omitempty"`
 }
 
+// IsOIDC reports whether the session was created by an OIDC login.
+func (s Session) IsOIDC() bool { return s.OIDCSubject != "" }
+
 // Role defines a named set of permissions.
 type Role struct {
 	ID          string       `json:"id"`
@@ -98,6 +137,7 @@ type RequestContext struct {
 	Session     *Session
 	APIToken    *APIToken
 	Permissions []Permission
+	Scope       *ContainerScope // nil = every container
 	AuthEnabled bool
 }
 
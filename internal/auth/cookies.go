@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// CookieOptions sets the attributes of the session and CSRF cookies.
+type CookieOptions struct {
+	Secure   bool
+	Domain   string        // empty = host-only cookie
+	SameSite http.SameSite // zero = Lax
+}
+
+// ParseSameSite converts a SENTINEL_COOKIE_SAMESITE value ("lax", "strict"
+// or "none"; empty means lax) to an http.SameSite mode.
+func ParseSameSite(s string) (http.SameSite, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "lax":
+		return http.SameSiteLaxMode, nil
+	case "strict":
+		return http.SameSiteStrictMode, nil
+	case "none":
+		return http.SameSiteNoneMode, nil
+	}
+	return 0, fmt.Errorf("must be lax, strict or none, got %q", s)
+}
+
+// SameSiteName returns the lower-case name of a SameSite mode.
+func SameSiteName(m http.SameSite) string {
+	switch m {
+	case http.SameSiteStrictMode:
+		return "strict"
+	case http.SameSiteNoneMode:
+		return "none"
+	}
+	return "lax"
+}
+
+func (o CookieOptions) sameSite() http.SameSite {
+	if o.SameSite == 0 || o.SameSite == http.SameSiteDefaultMode {
+		return http.SameSiteLaxMode
+	}
+	return o.SameSite
+}
+
+// Cookie returns a cookie named name carrying these attributes.
+func (o CookieOptions) Cookie(name, value string) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		Domain:   o.Domain,
+		HttpOnly: true,
+		SameSite: o.sameSite(),
+		Secure:   o.Secure,
+	}
+}
+
+// SetSession sets the session cookie on the response.
+func (o CookieOptions) SetSession(w http.ResponseWriter, token string, expiry time.Time) {
+	c := o.Cookie(SessionCookieName, token)
+	c.Expires = expiry
+	http.SetCookie(w, c)
+}
+
+// ClearSession removes the session cookie.
+func (o CookieOptions) ClearSession(w http.ResponseWriter) {
+	c := o.Cookie(SessionCookieName, "")
+	c.MaxAge = -1
+	http.SetCookie(w, c)
+}
+
+// SetCSRF sets the CSRF double-submit cookie (readable by JS).
+func (o CookieOptions) SetCSRF(w http.ResponseWriter, token string) {
+	c := o.Cookie(CSRFCookieName, token)
+	c.HttpOnly = false // JS must read this to send in header
+	http.SetCookie(w, c)
+}
+
+// DomainMatches reports whether a cookie for domain is accepted by a
+// browser on host: an empty domain always is, otherwise host must be the
+// domain or a subdomain of it.
+func DomainMatches(domain, host string) bool {
+	domain = strings.TrimPrefix(strings.ToLower(domain), ".")
+	if domain == "" {
+		return true
+	}
+	host = strings.ToLower(hostOnly(host))
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// RequestOrigin returns the scheme://host origin the browser used to reach
+// r, honouring X-Forwarded-Proto and X-Forwarded-Host from a reverse proxy.
+func RequestOrigin(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if p := r.Header.Get("X-Forwarded-Proto"); p != "" {
+		scheme = strings.ToLower(strings.TrimSpace(strings.Split(p, ",")[0]))
+	}
+	host := r.Host
+	if h := r.Header.Get("X-Forwarded-Host"); h != "" {
+		host = strings.TrimSpace(strings.Split(h, ",")[0])
+	}
+	return scheme + "://" + strings.ToLower(host)
+}
+
+// NormaliseOrigin lower-cases an origin and drops any path and default
+// port, so "https://Sentinel.example.com:443/" matches the Origin header.
+// Returns "" if s is not an http(s) URL.
+func NormaliseOrigin(s string) string {
+	u, err := url.Parse(strings.TrimSpace(s))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ""
+	}
+	host := strings.ToLower(u.Host)
+	if (u.Scheme == "https" && strings.HasSuffix(host, ":443")) || (u.Scheme == "http" && strings.HasSuffix(host, ":80")) {
+		host = host[:strings.LastIndex(host, ":")]
+	}
+	return u.Scheme + "://" + host
+}
+
+// hostOnly strips a port from host, keeping IPv6 literals intact.
+func hostOnly(host string) string {
+	if strings.HasPrefix(host, "[") {
+		if i := strings.Index(host, "]"); i > 0 {
+			return host[1:i]
+		}
+	}
+	if i := strings.LastIndex(host, ":"); i >= 0 && !strings.Contains(host[:i], ":") {
+		return host[:i]
+	}
+	return host
+}
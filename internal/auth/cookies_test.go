@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCookieOptions(t *testing.T) {
+	w := httptest.NewRecorder()
+	CookieOptions{Secure: true, Domain: "example.com", SameSite: http.SameSiteNoneMode}.SetCSRF(w, "tok")
+	c := w.Result().Cookies()[0]
+	if c.Domain != "example.com" || !c.Secure || c.SameSite != http.SameSiteNoneMode || c.HttpOnly {
+		t.Errorf("CSRF cookie = %+v", c)
+	}
+
+	w = httptest.NewRecorder()
+	CookieOptions{}.ClearSession(w)
+	c = w.Result().Cookies()[0]
+	if c.SameSite != http.SameSiteLaxMode || !c.HttpOnly || c.MaxAge >= 0 || c.Domain != "" {
+		t.Errorf("cleared session cookie = %+v, want host-only, Lax, expired", c)
+	}
+}
+
+func TestParseSameSite(t *testing.T) {
+	for in, want := range map[string]http.SameSite{"": http.SameSiteLaxMode, "Strict": http.SameSiteStrictMode, "none": http.SameSiteNoneMode} {
+		got, err := ParseSameSite(in)
+		if err != nil || got != want {
+			t.Errorf("ParseSameSite(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := ParseSameSite("loose"); err == nil {
+		t.Error("ParseSameSite(loose) should fail")
+	}
+}
+
+func TestDomainMatches(t *testing.T) {
+	cases := []struct {
+		domain, host string
+		want         bool
+	}{
+		{"", "192.0.2.10:8080", true},
+		{"example.com", "sentinel.example.com", true},
+		{".example.com", "example.com:443", true},
+		{"example.com", "192.0.2.10", false},
+		{"example.com", "badexample.com", false},
+	}
+	for _, c := range cases {
+		if got := DomainMatches(c.domain, c.host); got != c.want {
+			t.Errorf("DomainMatches(%q, %q) = %v, want %v", c.domain, c.host, got, c.want)
+		}
+	}
+}
+
+func TestNormaliseOrigin(t *testing.T) {
+	cases := map[string]string{
+		"https://Sentinel.Example.com:443/": "https://sentinel.example.com",
+		"http://192.0.2.10:8080":            "http://192.0.2.10:8080",
+		"http://host:80/login":              "http://host",
+		"null":                              "",
+		"ftp://host":                        "",
+	}
+	for in, want := range cases {
+		if got := NormaliseOrigin(in); got != want {
+			t.Errorf("NormaliseOrigin(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestOriginMiddleware(t *testing.T) {
+	svc := newTestService(true)
+	handler := OriginMiddleware(svc)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	post := func(origin string, forwarded bool) int {
+		req := httptest.NewRequest(http.MethodPost, "http://192.0.2.10:8080/api/settings", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if forwarded {
+			req.Header.Set("X-Forwarded-Proto", "https")
+			req.Header.Set("X-Forwarded-Host", "sentinel.example.com")
+		}
+		req = req.WithContext(context.WithValue(req.Context(), ContextKey, &RequestContext{AuthEnabled: true}))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// No allowed origins configured: the check is off.
+	if code := post("https://evil.example", false); code != http.StatusOK {
+		t.Errorf("without allowed origins: status = %d, want 200", code)
+	}
+
+	svc.AllowedOrigins = []string{"https://sentinel.example.com"}
+	for _, c := range []struct {
+		origin    string
+		forwarded bool
+		want      int
+	}{
+		{"http://192.0.2.10:8080", false, http.StatusOK},       // same origin
+		{"https://sentinel.example.com", false, http.StatusOK}, // allowed, Host rewritten by proxy
+		{"https://sentinel.example.com", true, http.StatusOK},  // forwarded headers
+		{"", false, http.StatusOK},                             // no Origin: double-submit still applies
+		{"https://evil.example", false, http.StatusForbidden},
+		{"null", false, http.StatusForbidden},
+	} {
+		if code := post(c.origin, c.forwarded); code != c.want {
+			t.Errorf("origin %q (forwarded %v): status = %d, want %d", c.origin, c.forwarded, code, c.want)
+		}
+	}
+}
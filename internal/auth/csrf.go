@@ -22,16 +22,9 @@ func GenerateCSRFToken() (string, error) {
 	return hex.EncodeToString(b), nil
 }
 
-// SetCSRFCookie sets the CSRF double-submit cookie (readable by JS).
+// SetCSRFCookie sets a host-only CSRF double-submit cookie (readable by JS).
 func SetCSRFCookie(w http.ResponseWriter, token string, secure bool) {
-	http.SetCookie(w, &http.Cookie{
-		Name:     CSRFCookieName,
-		Value:    token,
-		Path:     "/",
-		HttpOnly: false, // JS must read this to send in header
-		SameSite: http.SameSiteLaxMode,
-		Secure:   secure,
-	})
+	CookieOptions{Secure: secure}.SetCSRF(w, token)
 }
 
 // ValidateCSRF checks that the CSRF header matches the CSRF cookie (double-submit pattern).
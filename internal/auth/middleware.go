@@ -38,7 +38,7 @@ func AuthMiddleware(svc *Service) func(http.Handler) http.Handler {
 					return
 				}
 				// Invalid bearer token.
-				writeJSONError(w, http.StatusUnauthorized, "invalid or expired token")
+				writeJSONError(w, http.StatusUnauthorized, "invalid_token", "invalid or expired token", nil)
 				return
 			}
 
@@ -48,18 +48,18 @@ func AuthMiddleware(svc *Service) func(http.Handler) http.Handler {
 				if rc != nil {
 					rc.AuthEnabled = true
 					// Ensure CSRF cookie is set for browser sessions.
-					ensureCSRFCookie(w, r, svc.CookieSecure)
+					ensureCSRFCookie(w, r, svc.Cookies())
 					ctx := context.WithValue(r.Context(), ContextKey, rc)
 					next.ServeHTTP(w, r.WithContext(ctx))
 					return
 				}
 				// Invalid/expired session — clear the stale cookie.
-				ClearSessionCookie(w, svc.CookieSecure)
+				svc.Cookies().ClearSession(w)
 			}
 
 			// Not authenticated.
 			if isAPIRequest(r) {
-				writeJSONError(w, http.StatusUnauthorized, "authentication required")
+				writeJSONError(w, http.StatusUnauthorized, "unauthorized", "authentication required", nil)
 			} else {
 				http.Redirect(w, r, "/login", http.StatusSeeOther)
 			}
@@ -93,7 +93,7 @@ func CSRFMiddleware(next http.Handler) http.Handler {
 		// Validate CSRF double-submit.
 		if !ValidateCSRF(r) {
 			if isAPIRequest(r) {
-				writeJSONError(w, http.StatusForbidden, "CSRF validation failed")
+				writeJSONError(w, http.StatusForbidden, "csrf_failed", "CSRF validation failed", nil)
 			} else {
 				http.Error(w, "CSRF validation failed", http.StatusForbidden)
 			}
@@ -104,18 +104,76 @@ func CSRFMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// OriginMiddleware rejects cookie-authenticated state-changing requests
+// whose Origin (or Referer) is neither the origin the request arrived on nor
+// one of svc.AllowedOrigins. It does nothing until AllowedOrigins is set, so
+// proxies that rewrite the Host header keep working by default.
+func OriginMiddleware(svc *Service) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(svc.AllowedOrigins) == 0 || r.Method == "GET" || r.Method == "HEAD" || r.Method == "OPTIONS" ||
+				ExtractBearerToken(r.Header.Get("Authorization")) != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if rc := GetRequestContext(r.Context()); rc != nil && !rc.AuthEnabled {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if origin, ok := svc.OriginAllowed(r); !ok {
+				msg := "request origin " + origin + " is not an allowed origin"
+				if isAPIRequest(r) {
+					writeJSONError(w, http.StatusForbidden, "csrf_origin", msg, map[string]string{"origin": origin})
+				} else {
+					http.Error(w, msg, http.StatusForbidden)
+				}
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// OriginAllowed reports whether the browser origin of r (from the Origin
+// header, else the Referer) is the origin the request arrived on or one of
+// svc.AllowedOrigins. Requests carrying neither header are allowed; the
+// CSRF double-submit still applies to them. The origin is returned for
+// error messages.
+func (s *Service) OriginAllowed(r *http.Request) (string, bool) {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		origin = r.Header.Get("Referer")
+	}
+	if origin == "" {
+		return "", true
+	}
+	norm := NormaliseOrigin(origin)
+	if norm == "" {
+		return origin, false // "null" or garbage
+	}
+	if norm == NormaliseOrigin(RequestOrigin(r)) {
+		return norm, true
+	}
+	for _, allowed := range s.AllowedOrigins {
+		if norm == allowed {
+			return norm, true
+		}
+	}
+	return norm, false
+}
+
 // RequirePermission returns middleware that checks for a specific permission.
 func RequirePermission(perm Permission) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			rc := GetRequestContext(r.Context())
 			if rc == nil {
-				writeJSONError(w, http.StatusUnauthorized, "authentication required")
+				writeJSONError(w, http.StatusUnauthorized, "unauthorized", "authentication required", nil)
 				return
 			}
 			if !rc.HasPermission(perm) {
 				if isAPIRequest(r) {
-					writeJSONError(w, http.StatusForbidden, "insufficient permissions")
+					writeJSONError(w, http.StatusForbidden, "forbidden", "insufficient permissions", map[string]string{"permission": string(perm)})
 				} else {
 					http.Error(w, "You don't have permission to access this page.", http.StatusForbidden)
 				}
@@ -142,19 +200,24 @@ func isAPIRequest(r *http.Request) bool {
 }
 
 // ensureCSRFCookie sets a CSRF cookie if one doesn't already exist.
-func ensureCSRFCookie(w http.ResponseWriter, r *http.Request, secure bool) {
+func ensureCSRFCookie(w http.ResponseWriter, r *http.Request, opts CookieOptions) {
 	if _, err := r.Cookie(CSRFCookieName); err != nil {
 		token, err := GenerateCSRFToken()
 		if err != nil {
 			return
 		}
-		SetCSRFCookie(w, token, secure)
+		opts.SetCSRF(w, token)
 	}
 }
 
-// writeJSONError writes a JSON error response with correct Content-Type.
-func writeJSONError(w http.ResponseWriter, code int, msg string) {
+// writeJSONError writes a JSON error response with correct Content-Type, in
+// the same structured shape as the web package's API errors.
+func writeJSONError(w http.ResponseWriter, status int, code, msg string, details any) {
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
+	w.WriteHeader(status)
+	body := map[string]any{"error": msg, "code": code, "message": msg, "retryable": false}
+	if details != nil {
+		body["details"] = details
+	}
+	_ = json.NewEncoder(w).Encode(body)
 }
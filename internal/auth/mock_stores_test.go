@@ -145,6 +145,16 @@ func (m *mockSessionStore) ListSessionsForUser(userID string) ([]Session, error)
 	return result, nil
 }
 
+func (m *mockSessionStore) ListSessions() ([]Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	result := make([]Session, 0, len(m.sessions))
+	for _, s := range m.sessions {
+		result = append(result, s)
+	}
+	return result, nil
+}
+
 func (m *mockSessionStore) DeleteExpiredSessions() (int, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
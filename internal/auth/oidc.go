@@ -8,7 +8,9 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
 	"sync"
 	"time"
 
@@ -27,8 +29,25 @@ type OIDCConfig struct {
 	DefaultRole   string            // role for auto-created users (default "viewer")
 	GroupClaim    string            // claim name in ID token (default "groups")
 	GroupMappings map[string]string // IdP group name -> Sentinel role ID
+	RoleRules     []RoleMappingRule // claim rules applied alongside GroupMappings
+
+	PostLogoutRedirectURL string        // where the IdP sends the browser after logout (default: IdP's choice)
+	BackchannelLogout     bool          // accept logout tokens pushed by the IdP
+	RevalidateInterval    time.Duration // re-check SSO sessions with the IdP this often (0 = never)
 }
 
+// ErrOIDCSessionRevoked is returned by Refresh when the IdP no longer
+// honours the session's refresh token (user disabled, IdP logout, etc.).
+var ErrOIDCSessionRevoked = errors.New("oidc session revoked by identity provider")
+
+// backchannelLogoutEvent is the event key a logout token must carry
+// (OpenID Connect Back-Channel Logout 1.0, section 2.4).
+const backchannelLogoutEvent = "http://schemas.openid.net/event/backchannel-logout"
+
+// logoutTokenMaxAge bounds how old a logout token's iat may be. Logout
+// tokens are delivered immediately, so anything older is a replay.
+const logoutTokenMaxAge = 5 * time.Minute
+
 // OIDCProvider wraps the OIDC discovery and OAuth2 flow.
 type OIDCProvider struct {
 	mu            sync.RWMutex
@@ -39,6 +58,13 @@ type OIDCProvider struct {
 	defaultRole   string
 	groupClaim    string            // ID token claim containing group list
 	groupMappings map[string]string // IdP group -> Sentinel role ID
+	roleRules     []RoleMappingRule
+
+	logoutVerifier     *oidc.IDTokenVerifier // exp is optional on logout tokens
+	endSessionURL      string                // IdP end_session_endpoint, "" if unsupported
+	postLogoutRedirect string
+	backchannel        bool
+	revalidateInterval time.Duration
 }
 
 // OIDCUserInfo represents the user info extracted from OIDC claims.
@@ -47,7 +73,12 @@ type OIDCUserInfo struct {
 	Email    string
 	Name     string
 	Username string
-	Groups   []string // groups from the ID token group claim
+	Groups   []string       // groups from the ID token group claim
+	Claims   map[string]any // every ID token claim, for role mapping rules
+
+	SessionID    string // IdP session ID ("sid" claim), if the IdP sends one
+	IDToken      string // raw ID token, used as id_token_hint on logout
+	RefreshToken string // empty when the IdP issues none
 }
 
 // NewOIDCProvider initialises the OIDC provider via discovery.
@@ -80,14 +111,27 @@ func NewOIDCProvider(ctx context.Context, cfg OIDCConfig) (*OIDCProvider, error)
 		groupClaim = "groups"
 	}
 
+	// RP-initiated logout is optional for IdPs; an empty endpoint just means
+	// Sentinel's logout stays local.
+	var discovery struct {
+		EndSessionEndpoint string `json:"end_session_endpoint"`
+	}
+	_ = provider.Claims(&discovery)
+
 	return &OIDCProvider{
-		provider:      provider,
-		verifier:      provider.Verifier(&oidc.Config{ClientID: cfg.ClientID}),
-		oauth2Cfg:     oauth2Cfg,
-		autoCreate:    cfg.AutoCreate,
-		defaultRole:   defaultRole,
-		groupClaim:    groupClaim,
-		groupMappings: cfg.GroupMappings,
+		provider:           provider,
+		verifier:           provider.Verifier(&oidc.Config{ClientID: cfg.ClientID}),
+		oauth2Cfg:          oauth2Cfg,
+		autoCreate:         cfg.AutoCreate,
+		defaultRole:        defaultRole,
+		groupClaim:         groupClaim,
+		groupMappings:      cfg.GroupMappings,
+		roleRules:          cfg.RoleRules,
+		logoutVerifier:     provider.Verifier(&oidc.Config{ClientID: cfg.ClientID, SkipExpiryCheck: true}),
+		endSessionURL:      discovery.EndSessionEndpoint,
+		postLogoutRedirect: cfg.PostLogoutRedirectURL,
+		backchannel:        cfg.BackchannelLogout,
+		revalidateInterval: cfg.RevalidateInterval,
 	}, nil
 }
 
@@ -144,10 +188,22 @@ func (p *OIDCProvider) Exchange(ctx context.Context, code, pkceVerifier, expecte
 		return nil, fmt.Errorf("id token nonce mismatch")
 	}
 
+	info, err := p.userInfo(idToken)
+	if err != nil {
+		return nil, err
+	}
+	info.IDToken = rawIDToken
+	info.RefreshToken = token.RefreshToken
+	return info, nil
+}
+
+// userInfo extracts the Sentinel view of a verified ID token.
+func (p *OIDCProvider) userInfo(idToken *oidc.IDToken) (*OIDCUserInfo, error) {
 	var claims struct {
 		Email             string `json:"email"`
 		Name              string `json:"name"`
 		PreferredUsername string `json:"preferred_username"`
+		SessionID         string `json:"sid"`
 	}
 	if err := idToken.Claims(&claims); err != nil {
 		return nil, fmt.Errorf("parse claims: %w", err)
@@ -167,6 +223,9 @@ func (p *OIDCProvider) Exchange(ctx context.Context, code, pkceVerifier, expecte
 	groupClaim := p.groupClaim
 	p.mu.RUnlock()
 
+	var all map[string]any
+	_ = idToken.Claims(&all)
+
 	var groups []string
 	if groupClaim != "" {
 		var rawClaims map[string]json.RawMessage
@@ -188,14 +247,130 @@ func (p *OIDCProvider) Exchange(ctx context.Context, code, pkceVerifier, expecte
 	}
 
 	return &OIDCUserInfo{
-		Subject:  idToken.Subject,
-		Email:    claims.Email,
-		Name:     claims.Name,
-		Username: username,
-		Groups:   groups,
+		Subject:   idToken.Subject,
+		Email:     claims.Email,
+		Name:      claims.Name,
+		Username:  username,
+		Groups:    groups,
+		Claims:    all,
+		SessionID: claims.SessionID,
 	}, nil
 }
 
+// Refresh re-checks a session with the IdP by redeeming its refresh token.
+// Returns ErrOIDCSessionRevoked when the IdP rejects the token. The returned
+// info carries the (possibly rotated) refresh token; claims are filled in
+// only when the IdP also returned a fresh ID token.
+func (p *OIDCProvider) Refresh(ctx context.Context, refreshToken string) (*OIDCUserInfo, error) {
+	p.mu.RLock()
+	cfg := p.oauth2Cfg
+	verifier := p.verifier
+	p.mu.RUnlock()
+
+	token, err := cfg.TokenSource(ctx, &oauth2.Token{RefreshToken: refreshToken}).Token()
+	if err != nil {
+		var re *oauth2.RetrieveError
+		if errors.As(err, &re) && re.ErrorCode == "invalid_grant" {
+			return nil, ErrOIDCSessionRevoked
+		}
+		return nil, fmt.Errorf("token refresh: %w", err)
+	}
+
+	info := &OIDCUserInfo{RefreshToken: token.RefreshToken}
+	if info.RefreshToken == "" {
+		info.RefreshToken = refreshToken
+	}
+	if rawIDToken, ok := token.Extra("id_token").(string); ok && rawIDToken != "" {
+		idToken, err := verifier.Verify(ctx, rawIDToken)
+		if err != nil {
+			return nil, fmt.Errorf("token verification: %w", err)
+		}
+		claims, err := p.userInfo(idToken)
+		if err != nil {
+			return nil, err
+		}
+		claims.IDToken = rawIDToken
+		claims.RefreshToken = info.RefreshToken
+		info = claims
+	}
+	return info, nil
+}
+
+// LogoutURL builds the IdP end-session URL for RP-initiated logout, or
+// returns "" when the IdP does not advertise an end_session_endpoint.
+func (p *OIDCProvider) LogoutURL(idTokenHint string) string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.endSessionURL == "" {
+		return ""
+	}
+	u, err := url.Parse(p.endSessionURL)
+	if err != nil {
+		return ""
+	}
+	q := u.Query()
+	q.Set("client_id", p.oauth2Cfg.ClientID)
+	if idTokenHint != "" {
+		q.Set("id_token_hint", idTokenHint)
+	}
+	if p.postLogoutRedirect != "" {
+		q.Set("post_logout_redirect_uri", p.postLogoutRedirect)
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// VerifyLogoutToken validates a back-channel logout token and returns the
+// subject and IdP session ID it targets (at least one is non-empty).
+func (p *OIDCProvider) VerifyLogoutToken(ctx context.Context, rawToken string) (subject, sid string, err error) {
+	p.mu.RLock()
+	verifier := p.logoutVerifier
+	p.mu.RUnlock()
+
+	token, err := verifier.Verify(ctx, rawToken)
+	if err != nil {
+		return "", "", fmt.Errorf("logout token verification: %w", err)
+	}
+	if time.Since(token.IssuedAt) > logoutTokenMaxAge || time.Until(token.IssuedAt) > time.Minute {
+		return "", "", fmt.Errorf("logout token issued at %s is outside the accepted window", token.IssuedAt)
+	}
+
+	var claims struct {
+		SessionID string                     `json:"sid"`
+		Events    map[string]json.RawMessage `json:"events"`
+		Nonce     *string                    `json:"nonce"`
+	}
+	if err := token.Claims(&claims); err != nil {
+		return "", "", fmt.Errorf("parse logout token claims: %w", err)
+	}
+	if _, ok := claims.Events[backchannelLogoutEvent]; !ok {
+		return "", "", fmt.Errorf("logout token missing back-channel logout event")
+	}
+	// A nonce marks an ID token, which must never be accepted as a logout token.
+	if claims.Nonce != nil {
+		return "", "", fmt.Errorf("logout token must not contain a nonce")
+	}
+	if token.Subject == "" && claims.SessionID == "" {
+		return "", "", fmt.Errorf("logout token has neither sub nor sid")
+	}
+	return token.Subject, claims.SessionID, nil
+}
+
+// BackchannelLogout reports whether the IdP may push logout tokens.
+func (p *OIDCProvider) BackchannelLogout() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.backchannel
+}
+
+// RevalidateInterval returns how often SSO sessions are re-checked with the
+// IdP; 0 disables revalidation.
+func (p *OIDCProvider) RevalidateInterval() time.Duration {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.revalidateInterval
+}
+
 // AutoCreate returns whether users should be auto-created.
 func (p *OIDCProvider) AutoCreate() bool {
 	p.mu.RLock()
@@ -224,6 +399,13 @@ func (p *OIDCProvider) GroupMappings() map[string]string {
 	return p.groupMappings
 }
 
+// RoleMapping returns the group mappings and claim rules that set user roles.
+func (p *OIDCProvider) RoleMapping() RoleMapping {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return RoleMapping{Groups: p.groupMappings, Rules: p.roleRules}
+}
+
 // ResolveRoleFromGroups determines the highest-privilege Sentinel role from
 // a user's IdP groups. Returns defaultRole if no mappings match.
 // Priority order: admin > operator > viewer.
@@ -232,12 +414,6 @@ func ResolveRoleFromGroups(groups []string, mappings map[string]string, defaultR
 		return defaultRole
 	}
 
-	rolePriority := map[string]int{
-		RoleAdminID:    3,
-		RoleOperatorID: 2,
-		RoleViewerID:   1,
-	}
-
 	bestRole := defaultRole
 	bestPri := rolePriority[defaultRole]
 
@@ -294,12 +470,12 @@ func PKCEChallengeFromVerifier(verifier string) string {
 }
 
 // LoginWithOIDC finds or creates a user from OIDC claims and creates a session.
-// When groupMappings is non-empty, the user's role is resolved from their IdP
-// groups on every login (both new and existing users), keeping Sentinel roles
-// in sync with the identity provider.
-func (s *Service) LoginWithOIDC(ctx context.Context, info *OIDCUserInfo, autoCreate bool, defaultRole string, groupMappings map[string]string, ip, userAgent string) (*Session, error) {
-	// Resolve the target role from groups (falls back to defaultRole).
-	targetRole := ResolveRoleFromGroups(info.Groups, groupMappings, defaultRole)
+// When a role mapping is configured, the user's role is resolved from their
+// IdP groups and claims on every login (both new and existing users), keeping
+// Sentinel roles in sync with the identity provider.
+func (s *Service) LoginWithOIDC(ctx context.Context, info *OIDCUserInfo, autoCreate bool, defaultRole string, mapping RoleMapping, ip, userAgent string) (*Session, error) {
+	// Resolve the target role from groups and claims (falls back to defaultRole).
+	targetRole := mapping.Resolve(info, defaultRole)
 
 	// Try to find existing user by username.
 	user, err := s.Users.GetUserByUsername(info.Username)
@@ -348,8 +524,8 @@ func (s *Service) LoginWithOIDC(ctx context.Context, info *OIDCUserInfo, autoCre
 		if err := s.Users.CreateUser(*user); err != nil {
 			return nil, fmt.Errorf("create OIDC user: %w", err)
 		}
-	} else if len(groupMappings) > 0 && user.RoleID != targetRole {
-		// Existing user with group mappings: sync role from IdP.
+	} else if mapping.Active() && user.RoleID != targetRole {
+		// Existing user with a role mapping: sync role from IdP.
 		user.RoleID = targetRole
 		user.UpdatedAt = time.Now().UTC()
 		_ = s.Users.UpdateUser(*user)
@@ -361,13 +537,20 @@ func (s *Service) LoginWithOIDC(ctx context.Context, info *OIDCUserInfo, autoCre
 		return nil, fmt.Errorf("generate session token: %w", err)
 	}
 
+	now := time.Now().UTC()
 	session := Session{
-		Token:     token,
-		UserID:    user.ID,
-		IP:        ip,
-		UserAgent: userAgent,
-		CreatedAt: time.Now().UTC(),
-		ExpiresAt: time.Now().UTC().Add(s.SessionExpiry),
+		Token:            token,
+		UserID:           user.ID,
+		IP:               ip,
+		UserAgent:        userAgent,
+		Source:           SessionSourceOIDC,
+		CreatedAt:        now,
+		ExpiresAt:        now.Add(s.SessionExpiry),
+		OIDCSubject:      info.Subject,
+		OIDCSessionID:    info.SessionID,
+		OIDCIDToken:      info.IDToken,
+		OIDCRefreshToken: info.RefreshToken,
+		OIDCCheckedAt:    now,
 	}
 
 	if err := s.Sessions.CreateSession(session); err != nil {
@@ -377,6 +560,106 @@ func (s *Service) LoginWithOIDC(ctx context.Context, info *OIDCUserInfo, autoCre
 	return &session, nil
 }
 
+// LogoutOIDCSessions ends every Sentinel session tied to an IdP session, as
+// requested by a back-channel logout token. A sid narrows the match to that
+// IdP session; otherwise all of the subject's SSO sessions are ended.
+func (s *Service) LogoutOIDCSessions(subject, sid string) (int, error) {
+	sessions, err := s.Sessions.ListSessions()
+	if err != nil {
+		return 0, fmt.Errorf("list sessions: %w", err)
+	}
+	ended := 0
+	for _, sess := range sessions {
+		if !sess.IsOIDC() {
+			continue
+		}
+		if sid != "" && sess.OIDCSessionID != sid {
+			continue
+		}
+		if subject != "" && sess.OIDCSubject != subject {
+			continue
+		}
+		if err := s.Sessions.DeleteSession(sess.Token); err != nil {
+			return ended, fmt.Errorf("delete session: %w", err)
+		}
+		ended++
+	}
+	return ended, nil
+}
+
+// OIDCSessionChecker re-checks SSO sessions with the IdP. *OIDCProvider
+// implements it.
+type OIDCSessionChecker interface {
+	Refresh(ctx context.Context, refreshToken string) (*OIDCUserInfo, error)
+	RoleMapping() RoleMapping
+	DefaultRole() string
+}
+
+// RevalidateOIDCSessions re-checks every SSO session last checked more than
+// maxAge ago. Sessions the IdP has revoked are ended; sessions whose ID token
+// carries new groups have the user's role re-synced. IdP outages leave
+// sessions alone. Sessions without a refresh token cannot be re-checked and
+// run to their normal expiry. Returns the number of sessions ended.
+func (s *Service) RevalidateOIDCSessions(ctx context.Context, checker OIDCSessionChecker, maxAge time.Duration) (int, error) {
+	sessions, err := s.Sessions.ListSessions()
+	if err != nil {
+		return 0, fmt.Errorf("list sessions: %w", err)
+	}
+	mapping := checker.RoleMapping()
+	ended := 0
+	for _, sess := range sessions {
+		if !sess.IsOIDC() || sess.OIDCRefreshToken == "" || time.Since(sess.OIDCCheckedAt) < maxAge {
+			continue
+		}
+		if time.Now().After(sess.ExpiresAt) {
+			continue // left for CleanupExpiredSessions
+		}
+
+		info, err := checker.Refresh(ctx, sess.OIDCRefreshToken)
+		if errors.Is(err, ErrOIDCSessionRevoked) || (err == nil && info.Subject != "" && info.Subject != sess.OIDCSubject) {
+			if delErr := s.Sessions.DeleteSession(sess.Token); delErr != nil {
+				return ended, fmt.Errorf("delete session: %w", delErr)
+			}
+			ended++
+			if s.Log != nil {
+				s.Log.Info("OIDC session ended after revalidation", "user_id", sess.UserID)
+			}
+			continue
+		}
+		if err != nil {
+			if s.Log != nil {
+				s.Log.Warn("OIDC session revalidation failed", "user_id", sess.UserID, "error", err)
+			}
+			continue
+		}
+
+		sess.OIDCRefreshToken = info.RefreshToken
+		sess.OIDCCheckedAt = time.Now().UTC()
+		if info.IDToken != "" {
+			sess.OIDCIDToken = info.IDToken
+		}
+		if err := s.Sessions.CreateSession(sess); err != nil {
+			return ended, fmt.Errorf("update session: %w", err)
+		}
+
+		if info.Subject != "" && mapping.Active() {
+			s.syncOIDCRole(sess.UserID, mapping.Resolve(info, checker.DefaultRole()))
+		}
+	}
+	return ended, nil
+}
+
+// syncOIDCRole applies a role resolved from IdP groups to a user.
+func (s *Service) syncOIDCRole(userID, roleID string) {
+	user, err := s.Users.GetUser(userID)
+	if err != nil || user == nil || user.RoleID == roleID {
+		return
+	}
+	user.RoleID = roleID
+	user.UpdatedAt = time.Now().UTC()
+	_ = s.Users.UpdateUser(*user)
+}
+
 // generateRandomPassword creates a 32-byte hex random string for OIDC-created users.
 func generateRandomPassword() (string, error) {
 	b := make([]byte, 32)
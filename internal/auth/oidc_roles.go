@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// rolePriority ranks the built-in roles; a higher value grants more.
+var rolePriority = map[string]int{
+	RoleAdminID:    3,
+	RoleOperatorID: 2,
+	RoleViewerID:   1,
+}
+
+// RoleMappingRule grants a Sentinel role to users whose ID token carries a
+// matching claim value.
+type RoleMappingRule struct {
+	Claim string `json:"claim"`           // dotted claims path, e.g. "realm_access.roles"; empty = the group claim
+	Match string `json:"match,omitempty"` // "exact" (default) or "regex"
+	Value string `json:"value"`           // value, or regular expression matched against the whole value
+	Role  string `json:"role"`
+}
+
+// Validate checks the rule names a built-in role and a usable matcher.
+func (r RoleMappingRule) Validate() error {
+	if _, ok := rolePriority[r.Role]; !ok {
+		return fmt.Errorf("invalid role %q", r.Role)
+	}
+	if r.Value == "" {
+		return fmt.Errorf("rule for role %s has no value to match", r.Role)
+	}
+	switch r.Match {
+	case "", "exact":
+	case "regex":
+		if _, err := regexp.Compile(r.Value); err != nil {
+			return fmt.Errorf("invalid regular expression %q: %w", r.Value, err)
+		}
+	default:
+		return fmt.Errorf("invalid match %q: want exact or regex", r.Match)
+	}
+	return nil
+}
+
+// matches reports whether any of the values satisfies the rule. Regular
+// expressions must match the whole value.
+func (r RoleMappingRule) matches(values []string) bool {
+	var re *regexp.Regexp
+	if r.Match == "regex" {
+		var err error
+		if re, err = regexp.Compile("^(?:" + r.Value + ")$"); err != nil {
+			return false
+		}
+	}
+	for _, v := range values {
+		if (re != nil && re.MatchString(v)) || (re == nil && v == r.Value) {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleMapping derives a user's role from their IdP claims: exact group
+// names from the group claim, plus rules matching any claim. The most
+// privileged match wins.
+type RoleMapping struct {
+	Groups map[string]string // IdP group -> Sentinel role ID
+	Rules  []RoleMappingRule
+}
+
+// Active reports whether any mapping is configured. Without one, roles are
+// left as they are in Sentinel.
+func (m RoleMapping) Active() bool {
+	return len(m.Groups) > 0 || len(m.Rules) > 0
+}
+
+// Resolve returns the role for a user, or defaultRole when nothing more
+// privileged matches.
+func (m RoleMapping) Resolve(info *OIDCUserInfo, defaultRole string) string {
+	best := ResolveRoleFromGroups(info.Groups, m.Groups, defaultRole)
+	for _, rule := range m.Rules {
+		values := info.Groups
+		if rule.Claim != "" {
+			values = claimValues(info.Claims, rule.Claim)
+		}
+		if rolePriority[rule.Role] > rolePriority[best] && rule.matches(values) {
+			best = rule.Role
+		}
+	}
+	return best
+}
+
+// claimValues returns the string values at a dotted path in the ID token
+// claims. A claim whose name itself contains dots (such as a namespaced
+// "https://example.com/roles") is matched before the path is split. Arrays
+// yield each element; numbers and booleans are formatted as strings.
+func claimValues(claims map[string]any, path string) []string {
+	v, ok := claims[path]
+	if !ok {
+		var cur any = claims
+		for _, part := range strings.Split(path, ".") {
+			obj, isObj := cur.(map[string]any)
+			if !isObj {
+				return nil
+			}
+			if cur, ok = obj[part]; !ok {
+				return nil
+			}
+		}
+		v = cur
+	}
+
+	var out []string
+	items, isList := v.([]any)
+	if !isList {
+		items = []any{v}
+	}
+	for _, item := range items {
+		switch x := item.(type) {
+		case string:
+			out = append(out, x)
+		case float64:
+			out = append(out, strconv.FormatFloat(x, 'f', -1, 64))
+		case bool:
+			out = append(out, strconv.FormatBool(x))
+		}
+	}
+	return out
+}
@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestClaimValues(t *testing.T) {
+	claims := map[string]any{
+		"realm_access":              map[string]any{"roles": []any{"ops", "dev"}},
+		"https://example.com/roles": []any{"admin"},
+		"email":                     "dev@example.com",
+		"level":                     float64(3),
+		"staff":                     true,
+	}
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"realm_access.roles", []string{"ops", "dev"}},
+		{"https://example.com/roles", []string{"admin"}},
+		{"email", []string{"dev@example.com"}},
+		{"level", []string{"3"}},
+		{"staff", []string{"true"}},
+		{"realm_access.missing", nil},
+		{"email.domain", nil},
+	}
+	for _, tt := range tests {
+		if got := claimValues(claims, tt.path); !slices.Equal(got, tt.want) {
+			t.Errorf("claimValues(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestRoleMappingResolve(t *testing.T) {
+	m := RoleMapping{
+		Groups: map[string]string{"readers": RoleViewerID},
+		Rules: []RoleMappingRule{
+			{Claim: "realm_access.roles", Value: "ops", Role: RoleOperatorID},
+			{Claim: "email", Match: "regex", Value: `.*@admins\.example\.com`, Role: RoleAdminID},
+			{Match: "regex", Value: "sentinel-.*", Role: RoleOperatorID},
+		},
+	}
+	tests := []struct {
+		name string
+		info OIDCUserInfo
+		want string
+	}{
+		{"no match", OIDCUserInfo{Claims: map[string]any{"email": "a@example.com"}}, RoleViewerID},
+		{"exact claim", OIDCUserInfo{Claims: map[string]any{"realm_access": map[string]any{"roles": []any{"ops"}}}}, RoleOperatorID},
+		{"exact is not a prefix", OIDCUserInfo{Claims: map[string]any{"realm_access": map[string]any{"roles": []any{"ops-team"}}}}, RoleViewerID},
+		{"regex anchored", OIDCUserInfo{Claims: map[string]any{"email": "a@admins.example.com.evil"}}, RoleViewerID},
+		{"highest wins", OIDCUserInfo{
+			Groups: []string{"readers"},
+			Claims: map[string]any{"email": "a@admins.example.com", "realm_access": map[string]any{"roles": []any{"ops"}}},
+		}, RoleAdminID},
+		{"empty claim uses groups", OIDCUserInfo{Groups: []string{"sentinel-ops"}}, RoleOperatorID},
+	}
+	for _, tt := range tests {
+		if got := m.Resolve(&tt.info, RoleViewerID); got != tt.want {
+			t.Errorf("%s: Resolve = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRoleMappingRuleValidate(t *testing.T) {
+	valid := []RoleMappingRule{
+		{Claim: "groups", Value: "admins", Role: RoleAdminID},
+		{Claim: "email", Match: "regex", Value: ".*@example\\.com", Role: RoleViewerID},
+	}
+	for _, r := range valid {
+		if err := r.Validate(); err != nil {
+			t.Errorf("%+v: %v", r, err)
+		}
+	}
+	invalid := []RoleMappingRule{
+		{Claim: "groups", Value: "admins", Role: "root"},
+		{Claim: "groups", Role: RoleAdminID},
+		{Claim: "email", Match: "regex", Value: "(", Role: RoleViewerID},
+		{Claim: "email", Match: "glob", Value: "*", Role: RoleViewerID},
+	}
+	for _, r := range invalid {
+		if err := r.Validate(); err == nil {
+			t.Errorf("%+v: want error", r)
+		}
+	}
+}
@@ -1,10 +1,20 @@
 package auth
 
 import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
+	"encoding/json"
+	"net/url"
 	"testing"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	jose "github.com/go-jose/go-jose/v4"
 )
 
 func TestResolveRoleFromGroups(t *testing.T) {
@@ -326,3 +336,171 @@ func TestPKCEChallengeFromVerifier(t *testing.T) {
 		}
 	})
 }
+
+func TestOIDCLogoutURL(t *testing.T) {
+	p := &OIDCProvider{
+		endSessionURL:      "https://idp.example.com/logout?tenant=a",
+		postLogoutRedirect: "https://sentinel.example.com/login",
+	}
+	p.oauth2Cfg.ClientID = "sentinel"
+
+	u, err := url.Parse(p.LogoutURL("id-token"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if u.Host != "idp.example.com" || q.Get("tenant") != "a" {
+		t.Errorf("endpoint not preserved: %s", u)
+	}
+	if q.Get("id_token_hint") != "id-token" || q.Get("client_id") != "sentinel" ||
+		q.Get("post_logout_redirect_uri") != "https://sentinel.example.com/login" {
+		t.Errorf("query = %v", q)
+	}
+
+	if got := (&OIDCProvider{}).LogoutURL("id-token"); got != "" {
+		t.Errorf("LogoutURL without end_session_endpoint = %q, want empty", got)
+	}
+}
+
+func TestVerifyLogoutToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: key}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const issuer = "https://idp.example.com"
+	p := &OIDCProvider{logoutVerifier: oidc.NewVerifier(issuer,
+		&oidc.StaticKeySet{PublicKeys: []crypto.PublicKey{&key.PublicKey}},
+		&oidc.Config{ClientID: "sentinel", SkipExpiryCheck: true})}
+
+	sign := func(claims map[string]any) string {
+		t.Helper()
+		base := map[string]any{
+			"iss":    issuer,
+			"aud":    "sentinel",
+			"iat":    time.Now().Unix(),
+			"jti":    "abc",
+			"events": map[string]any{backchannelLogoutEvent: map[string]any{}},
+		}
+		for k, v := range claims {
+			if v == nil {
+				delete(base, k)
+				continue
+			}
+			base[k] = v
+		}
+		payload, _ := json.Marshal(base)
+		jws, err := signer.Sign(payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw, err := jws.CompactSerialize()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return raw
+	}
+
+	sub, sid, err := p.VerifyLogoutToken(context.Background(), sign(map[string]any{"sub": "user-1", "sid": "sid-1"}))
+	if err != nil || sub != "user-1" || sid != "sid-1" {
+		t.Fatalf("valid token: sub=%q sid=%q err=%v", sub, sid, err)
+	}
+
+	bad := map[string]map[string]any{
+		"missing event":  {"sub": "user-1", "events": map[string]any{}},
+		"has nonce":      {"sub": "user-1", "nonce": "n"},
+		"no sub or sid":  {},
+		"wrong audience": {"sub": "user-1", "aud": "other"},
+		"stale iat":      {"sub": "user-1", "iat": time.Now().Add(-time.Hour).Unix()},
+	}
+	for name, claims := range bad {
+		if _, _, err := p.VerifyLogoutToken(context.Background(), sign(claims)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestLogoutOIDCSessions(t *testing.T) {
+	svc := newTestService(true)
+	for _, sess := range []Session{
+		{Token: "a", UserID: "u1", OIDCSubject: "sub-1", OIDCSessionID: "sid-1"},
+		{Token: "b", UserID: "u1", OIDCSubject: "sub-1", OIDCSessionID: "sid-2"},
+		{Token: "c", UserID: "u2", OIDCSubject: "sub-2", OIDCSessionID: "sid-3"},
+		{Token: "d", UserID: "u1"}, // password login
+	} {
+		_ = svc.Sessions.CreateSession(sess)
+	}
+
+	if n, err := svc.LogoutOIDCSessions("", "sid-1"); err != nil || n != 1 {
+		t.Fatalf("by sid: ended %d, err %v", n, err)
+	}
+	if n, err := svc.LogoutOIDCSessions("sub-1", ""); err != nil || n != 1 {
+		t.Fatalf("by subject: ended %d, err %v", n, err)
+	}
+	left, _ := svc.Sessions.ListSessions()
+	if len(left) != 2 {
+		t.Errorf("sessions left = %d, want 2 (other subject and password login)", len(left))
+	}
+}
+
+type fakeOIDCChecker struct {
+	results  map[string]*OIDCUserInfo
+	errs     map[string]error
+	mappings map[string]string
+}
+
+func (f *fakeOIDCChecker) Refresh(_ context.Context, refreshToken string) (*OIDCUserInfo, error) {
+	if err := f.errs[refreshToken]; err != nil {
+		return nil, err
+	}
+	return f.results[refreshToken], nil
+}
+
+func (f *fakeOIDCChecker) RoleMapping() RoleMapping { return RoleMapping{Groups: f.mappings} }
+func (f *fakeOIDCChecker) DefaultRole() string      { return RoleViewerID }
+
+func TestRevalidateOIDCSessions(t *testing.T) {
+	svc := newTestService(true)
+	_ = svc.Users.CreateUser(User{ID: "u1", Username: "alice", RoleID: RoleViewerID})
+	stale := time.Now().Add(-time.Hour)
+	expires := time.Now().Add(time.Hour)
+	for _, sess := range []Session{
+		{Token: "ok", UserID: "u1", OIDCSubject: "sub-1", OIDCRefreshToken: "rt-ok", OIDCCheckedAt: stale, ExpiresAt: expires},
+		{Token: "revoked", UserID: "u1", OIDCSubject: "sub-1", OIDCRefreshToken: "rt-revoked", OIDCCheckedAt: stale, ExpiresAt: expires},
+		{Token: "outage", UserID: "u1", OIDCSubject: "sub-1", OIDCRefreshToken: "rt-outage", OIDCCheckedAt: stale, ExpiresAt: expires},
+		{Token: "fresh", UserID: "u1", OIDCSubject: "sub-1", OIDCRefreshToken: "rt-revoked", OIDCCheckedAt: time.Now(), ExpiresAt: expires},
+	} {
+		_ = svc.Sessions.CreateSession(sess)
+	}
+	checker := &fakeOIDCChecker{
+		results: map[string]*OIDCUserInfo{
+			"rt-ok": {Subject: "sub-1", RefreshToken: "rt-rotated", Groups: []string{"ops"}},
+		},
+		errs: map[string]error{
+			"rt-revoked": ErrOIDCSessionRevoked,
+			"rt-outage":  context.DeadlineExceeded,
+		},
+		mappings: map[string]string{"ops": RoleOperatorID},
+	}
+
+	ended, err := svc.RevalidateOIDCSessions(context.Background(), checker, 15*time.Minute)
+	if err != nil || ended != 1 {
+		t.Fatalf("ended %d, err %v; want 1", ended, err)
+	}
+	for token, want := range map[string]bool{"ok": true, "revoked": false, "outage": true, "fresh": true} {
+		got, _ := svc.Sessions.GetSession(token)
+		if (got != nil) != want {
+			t.Errorf("session %q present = %v, want %v", token, got != nil, want)
+		}
+	}
+	ok, _ := svc.Sessions.GetSession("ok")
+	if ok.OIDCRefreshToken != "rt-rotated" || time.Since(ok.OIDCCheckedAt) > time.Minute {
+		t.Errorf("session not refreshed: %+v", ok)
+	}
+	if u, _ := svc.Users.GetUser("u1"); u.RoleID != RoleOperatorID {
+		t.Errorf("role = %q, want %q after group sync", u.RoleID, RoleOperatorID)
+	}
+}
@@ -22,7 +22,7 @@ func BuiltinRoles() []Role {
 			Permissions: []Permission{
 				PermContainersView, PermContainersUpdate, PermContainersApprove,
 				PermContainersRollback, PermContainersManage, PermSettingsView,
-				PermLogsView, PermHistoryView,
+				PermLogsView, PermHistoryView, PermClusterView,
 			},
 			BuiltIn: true,
 		},
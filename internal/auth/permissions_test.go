@@ -24,26 +24,38 @@ func TestBuiltinRoles(t *testing.T) {
 		return nil
 	}
 
-	t.Run("admin has 10 permissions", func(t *testing.T) {
+	t.Run("admin has 13 permissions", func(t *testing.T) {
 		admin := findRole(RoleAdminID)
-		if len(admin.Permissions) != 10 {
-			t.Errorf("expected admin to have 10 permissions, got %d", len(admin.Permissions))
+		if len(admin.Permissions) != 13 {
+			t.Errorf("expected admin to have 13 permissions, got %d", len(admin.Permissions))
 		}
 		if !admin.BuiltIn {
 			t.Error("admin role should be built-in")
 		}
 	})
 
-	t.Run("operator has 8 permissions", func(t *testing.T) {
+	t.Run("operator has 9 permissions", func(t *testing.T) {
 		op := findRole(RoleOperatorID)
-		if len(op.Permissions) != 8 {
-			t.Errorf("expected operator to have 8 permissions, got %d", len(op.Permissions))
+		if len(op.Permissions) != 9 {
+			t.Errorf("expected operator to have 9 permissions, got %d", len(op.Permissions))
 		}
 		if !op.BuiltIn {
 			t.Error("operator role should be built-in")
 		}
 	})
 
+	t.Run("operator can view but not manage the cluster", func(t *testing.T) {
+		rc := &RequestContext{Permissions: findRole(RoleOperatorID).Permissions}
+		if !rc.HasPermission(PermClusterView) {
+			t.Error("operator should have cluster.view")
+		}
+		for _, p := range []Permission{PermClusterManage, PermSystemSelfUpdate, PermSettingsModify} {
+			if rc.HasPermission(p) {
+				t.Errorf("operator should not have %s", p)
+			}
+		}
+	})
+
 	t.Run("viewer has 4 permissions", func(t *testing.T) {
 		viewer := findRole(RoleViewerID)
 		if len(viewer.Permissions) != 4 {
@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// composeProjectLabel is the label Docker Compose sets on every container
+// of a project.
+const composeProjectLabel = "com.docker.compose.project"
+
+// ContainerScope limits which containers a user can see and act on. A
+// container is in scope when it matches any one selector; a nil scope, or
+// one with no selectors, covers every container.
+type ContainerScope struct {
+	Names  []string `json:"names,omitempty"`  // name globs, e.g. "shop-*"
+	Stacks []string `json:"stacks,omitempty"` // Compose project names
+	Labels []string `json:"labels,omitempty"` // "key=value", or "key" to match any value
+}
+
+// Unrestricted reports whether the scope covers every container.
+func (s *ContainerScope) Unrestricted() bool {
+	return s == nil || (len(s.Names) == 0 && len(s.Stacks) == 0 && len(s.Labels) == 0)
+}
+
+// Matches reports whether a container with the given name and labels is in
+// scope.
+func (s *ContainerScope) Matches(name string, labels map[string]string) bool {
+	if s.Unrestricted() {
+		return true
+	}
+	for _, pattern := range s.Names {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	if s.AllowsStack(labels[composeProjectLabel]) {
+		return true
+	}
+	for _, sel := range s.Labels {
+		key, want, hasValue := strings.Cut(sel, "=")
+		got, ok := labels[key]
+		if ok && (!hasValue || got == want) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsStack reports whether the scope grants a whole Compose project.
+func (s *ContainerScope) AllowsStack(project string) bool {
+	if s.Unrestricted() {
+		return true
+	}
+	if project == "" {
+		return false
+	}
+	for _, p := range s.Stacks {
+		if p == project {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the name globs and label selectors are well formed.
+func (s *ContainerScope) Validate() error {
+	if s == nil {
+		return nil
+	}
+	for _, pattern := range s.Names {
+		if strings.TrimSpace(pattern) == "" {
+			return fmt.Errorf("empty name pattern")
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid name pattern %q: %w", pattern, err)
+		}
+	}
+	for _, p := range s.Stacks {
+		if strings.TrimSpace(p) == "" {
+			return fmt.Errorf("empty stack name")
+		}
+	}
+	for _, sel := range s.Labels {
+		if key, _, _ := strings.Cut(sel, "="); strings.TrimSpace(key) == "" {
+			return fmt.Errorf("invalid label selector %q: want key or key=value", sel)
+		}
+	}
+	return nil
+}
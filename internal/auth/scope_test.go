@@ -0,0 +1,64 @@
+package auth
+
+import "testing"
+
+func TestContainerScopeMatches(t *testing.T) {
+	scope := &ContainerScope{
+		Names:  []string{"shop-*"},
+		Stacks: []string{"billing"},
+		Labels: []string{"team=payments", "sentinel.app-team"},
+	}
+	tests := []struct {
+		name   string
+		cname  string
+		labels map[string]string
+		want   bool
+	}{
+		{"name glob", "shop-web", nil, true},
+		{"stack", "api", map[string]string{composeProjectLabel: "billing"}, true},
+		{"label value", "worker", map[string]string{"team": "payments"}, true},
+		{"label presence", "cron", map[string]string{"sentinel.app-team": ""}, true},
+		{"wrong label value", "worker", map[string]string{"team": "search"}, false},
+		{"other stack", "api", map[string]string{composeProjectLabel: "search"}, false},
+		{"no match", "postgres", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scope.Matches(tt.cname, tt.labels); got != tt.want {
+				t.Errorf("Matches(%q, %v) = %v, want %v", tt.cname, tt.labels, got, tt.want)
+			}
+		})
+	}
+
+	var none *ContainerScope
+	if !none.Matches("anything", nil) || !(&ContainerScope{}).Matches("anything", nil) {
+		t.Error("an empty scope should cover every container")
+	}
+	if !scope.AllowsStack("billing") || scope.AllowsStack("search") || scope.AllowsStack("") {
+		t.Error("AllowsStack mismatch")
+	}
+}
+
+func TestContainerScopeValidate(t *testing.T) {
+	valid := []*ContainerScope{
+		nil,
+		{},
+		{Names: []string{"web-?", "db"}, Stacks: []string{"shop"}, Labels: []string{"team=a", "owner"}},
+	}
+	for _, s := range valid {
+		if err := s.Validate(); err != nil {
+			t.Errorf("Validate(%+v) = %v, want nil", s, err)
+		}
+	}
+	invalid := []*ContainerScope{
+		{Names: []string{"web-["}},
+		{Names: []string{" "}},
+		{Stacks: []string{""}},
+		{Labels: []string{"=value"}},
+	}
+	for _, s := range invalid {
+		if err := s.Validate(); err == nil {
+			t.Errorf("Validate(%+v) = nil, want an error", s)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"time"
 
 	"github.com/pquerna/otp"
@@ -32,6 +33,7 @@ type SessionStore interface {
 	DeleteSession(token string) error
 	DeleteSessionsForUser(userID string) error
 	ListSessionsForUser(userID string) ([]Session, error)
+	ListSessions() ([]Session, error)
 	DeleteExpiredSessions() (int, error)
 }
 
@@ -77,6 +79,9 @@ type Service struct {
 	Log           *slog.Logger
 
 	CookieSecure   bool
+	CookieDomain   string        // empty = host-only cookies
+	CookieSameSite http.SameSite // zero = Lax
+	AllowedOrigins []string      // extra origins accepted on state-changing requests
 	SessionExpiry  time.Duration
 	AuthEnabledEnv *bool // nil = use DB setting; non-nil = override
 
@@ -95,6 +100,9 @@ func NewService(cfg ServiceConfig) *Service {
 		PendingTOTP:    cfg.PendingTOTP,
 		Log:            cfg.Log,
 		CookieSecure:   cfg.CookieSecure,
+		CookieDomain:   cfg.CookieDomain,
+		CookieSameSite: cfg.CookieSameSite,
+		AllowedOrigins: cfg.AllowedOrigins,
 		SessionExpiry:  cfg.SessionExpiry,
 		AuthEnabledEnv: cfg.AuthEnabledEnv,
 		rateLimiter:    NewRateLimiter(),
@@ -116,10 +124,18 @@ type ServiceConfig struct {
 	PendingTOTP    PendingTOTPStore
 	Log            *slog.Logger
 	CookieSecure   bool
+	CookieDomain   string
+	CookieSameSite http.SameSite
+	AllowedOrigins []string // normalised with NormaliseOrigin
 	SessionExpiry  time.Duration
 	AuthEnabledEnv *bool // env var override for auth_enabled
 }
 
+// Cookies returns the configured attributes for the session and CSRF cookies.
+func (s *Service) Cookies() CookieOptions {
+	return CookieOptions{Secure: s.CookieSecure, Domain: s.CookieDomain, SameSite: s.CookieSameSite}
+}
+
 // AuthEnabled returns whether auth is currently enabled.
 func (s *Service) AuthEnabled() bool {
 	// Env var override takes precedence.
@@ -222,6 +238,7 @@ func (s *Service) Login(ctx context.Context, username, password, ip, userAgent s
 		UserID:    user.ID,
 		IP:        ip,
 		UserAgent: userAgent,
+		Source:    SessionSourcePassword,
 		CreatedAt: time.Now().UTC(),
 		ExpiresAt: time.Now().UTC().Add(s.SessionExpiry),
 	}
@@ -278,6 +295,7 @@ func (s *Service) LoginWithWebAuthn(ctx context.Context, userID, ip, userAgent s
 		UserID:    user.ID,
 		IP:        ip,
 		UserAgent: userAgent,
+		Source:    SessionSourcePasskey,
 		CreatedAt: time.Now().UTC(),
 		ExpiresAt: time.Now().UTC().Add(s.SessionExpiry),
 	}
@@ -314,21 +332,33 @@ func (s *Service) ValidateSession(ctx context.Context, token string) *RequestCon
 		User:        user,
 		Session:     session,
 		Permissions: perms,
+		Scope:       user.ContainerScope,
 	}
 }
 
-// ValidateBearerToken checks a bearer token and returns a RequestContext if valid.
-func (s *Service) ValidateBearerToken(ctx context.Context, rawToken string) *RequestContext {
-	hash := HashToken(rawToken)
-	apiToken, err := s.Tokens.GetAPITokenByHash(hash)
+// LookupBearerToken returns the API token a raw bearer value belongs to, or
+// nil when it is unknown or expired. Unlike ValidateBearerToken it has no
+// side effects, so it is safe to call before the request is authenticated.
+func (s *Service) LookupBearerToken(rawToken string) *APIToken {
+	if s.Tokens == nil {
+		return nil
+	}
+	apiToken, err := s.Tokens.GetAPITokenByHash(HashToken(rawToken))
 	if err != nil || apiToken == nil {
 		return nil
 	}
-
-	// Check expiry.
 	if !apiToken.ExpiresAt.IsZero() && time.Now().After(apiToken.ExpiresAt) {
 		return nil
 	}
+	return apiToken
+}
+
+// ValidateBearerToken checks a bearer token and returns a RequestContext if valid.
+func (s *Service) ValidateBearerToken(ctx context.Context, rawToken string) *RequestContext {
+	apiToken := s.LookupBearerToken(rawToken)
+	if apiToken == nil {
+		return nil
+	}
 
 	user, err := s.Users.GetUser(apiToken.UserID)
 	if err != nil || user == nil {
@@ -347,6 +377,7 @@ func (s *Service) ValidateBearerToken(ctx context.Context, rawToken string) *Req
 		User:        user,
 		APIToken:    apiToken,
 		Permissions: perms,
+		Scope:       user.ContainerScope,
 	}
 }
 
@@ -355,6 +386,26 @@ func (s *Service) Logout(token string) error {
 	return s.Sessions.DeleteSession(token)
 }
 
+// RevokeOtherSessions ends every session of a user except keepToken (which
+// may be empty to end them all) and returns how many were ended.
+func (s *Service) RevokeOtherSessions(userID, keepToken string) (int, error) {
+	sessions, err := s.Sessions.ListSessionsForUser(userID)
+	if err != nil {
+		return 0, fmt.Errorf("list sessions: %w", err)
+	}
+	revoked := 0
+	for _, sess := range sessions {
+		if sess.Token == keepToken {
+			continue
+		}
+		if err := s.Sessions.DeleteSession(sess.Token); err != nil {
+			return revoked, fmt.Errorf("delete session: %w", err)
+		}
+		revoked++
+	}
+	return revoked, nil
+}
+
 // CleanupExpiredSessions removes expired sessions from the store.
 func (s *Service) CleanupExpiredSessions() (int, error) {
 	return s.Sessions.DeleteExpiredSessions()
@@ -405,12 +456,14 @@ func (s *Service) VerifyTOTP(ctx context.Context, pendingToken, code, ip, userAg
 
 	// Try TOTP code first.
 	valid := ValidateTOTPCode(user.TOTPSecret, code)
+	source := SessionSourceTOTP
 
 	// If TOTP didn't match, try recovery codes.
 	if !valid {
 		idx := ValidateRecoveryCode(code, user.RecoveryCodes)
 		if idx >= 0 {
 			valid = true
+			source = SessionSourceRecovery
 			// Remove the used recovery code.
 			user.RecoveryCodes = append(user.RecoveryCodes[:idx], user.RecoveryCodes[idx+1:]...)
 			user.UpdatedAt = time.Now().UTC()
@@ -438,6 +491,7 @@ func (s *Service) VerifyTOTP(ctx context.Context, pendingToken, code, ip, userAg
 		UserID:    user.ID,
 		IP:        ip,
 		UserAgent: userAgent,
+		Source:    source,
 		CreatedAt: time.Now().UTC(),
 		ExpiresAt: time.Now().UTC().Add(s.SessionExpiry),
 	}
@@ -21,30 +21,16 @@ func GenerateSessionToken() (string, error) {
 	return hex.EncodeToString(b), nil
 }
 
-// SetSessionCookie sets the session cookie on the response.
+// SetSessionCookie sets a host-only, SameSite=Lax session cookie on the
+// response. Use Service.Cookies().SetSession to apply the configured
+// cookie domain and SameSite mode.
 func SetSessionCookie(w http.ResponseWriter, token string, expiry time.Time, secure bool) {
-	http.SetCookie(w, &http.Cookie{
-		Name:     SessionCookieName,
-		Value:    token,
-		Path:     "/",
-		Expires:  expiry,
-		HttpOnly: true,
-		SameSite: http.SameSiteLaxMode,
-		Secure:   secure,
-	})
+	CookieOptions{Secure: secure}.SetSession(w, token, expiry)
 }
 
-// ClearSessionCookie removes the session cookie.
+// ClearSessionCookie removes a host-only session cookie.
 func ClearSessionCookie(w http.ResponseWriter, secure bool) {
-	http.SetCookie(w, &http.Cookie{
-		Name:     SessionCookieName,
-		Value:    "",
-		Path:     "/",
-		MaxAge:   -1,
-		HttpOnly: true,
-		SameSite: http.SameSiteLaxMode,
-		Secure:   secure,
-	})
+	CookieOptions{Secure: secure}.ClearSession(w)
 }
 
 // GetSessionToken extracts the session token from the request cookie.
@@ -3,12 +3,13 @@ package backup
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
-
-	bolt "go.etcd.io/bbolt"
 )
 
 // Logger is a minimal logging interface.
@@ -22,6 +23,14 @@ type S3Uploader interface {
 	Upload(ctx context.Context, localPath, objectName string) error
 }
 
+// Source is the database being backed up. store.Store satisfies it with
+// either storage driver, and its snapshots keep working after a restore
+// swaps the file.
+type Source interface {
+	// WriteTo writes a consistent copy of the live database to w.
+	WriteTo(w io.Writer) (int64, error)
+}
+
 // Info describes a single backup file.
 type Info struct {
 	Filename  string    `json:"filename"`
@@ -31,15 +40,17 @@ type Info struct {
 
 // Manager handles backup creation, listing, and cleanup.
 type Manager struct {
-	db       *bolt.DB
+	db       Source
+	mu       sync.RWMutex // guards dir and retain
 	dir      string
 	log      Logger
 	uploader S3Uploader // optional, nil if S3 not configured
 	retain   int        // number of local backups to keep (0 = unlimited)
+	running  atomic.Bool
 }
 
 // NewManager creates a backup manager. The dir is created if it doesn't exist.
-func NewManager(db *bolt.DB, dir string, log Logger) (*Manager, error) {
+func NewManager(db Source, dir string, log Logger) (*Manager, error) {
 	if err := os.MkdirAll(dir, 0o750); err != nil {
 		return nil, fmt.Errorf("create backup dir: %w", err)
 	}
@@ -56,7 +67,28 @@ func (m *Manager) SetRetention(n int) {
 	if n < 0 {
 		n = 0
 	}
+	m.mu.Lock()
 	m.retain = n
+	m.mu.Unlock()
+}
+
+// Retention returns how many local backups are kept (0 = unlimited).
+func (m *Manager) Retention() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.retain
+}
+
+// SetDir moves future backups to dir, creating it if needed. Existing
+// backups stay where they are.
+func (m *Manager) SetDir(dir string) error {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("create backup dir: %w", err)
+	}
+	m.mu.Lock()
+	m.dir = dir
+	m.mu.Unlock()
+	return nil
 }
 
 // SetUploader configures the optional S3 uploader.
@@ -64,23 +96,19 @@ func (m *Manager) SetUploader(u S3Uploader) {
 	m.uploader = u
 }
 
-// CreateBackup creates a hot backup of the BoltDB database.
+// CreateBackup creates a hot backup of the database.
 // Returns the Info of the created backup file.
 func (m *Manager) CreateBackup(ctx context.Context) (*Info, error) {
 	now := time.Now().UTC()
 	filename := fmt.Sprintf("sentinel-%s-%d.db", now.Format("20060102-150405"), now.UnixMilli()%1000)
-	path := filepath.Join(m.dir, filename)
+	path := filepath.Join(m.Dir(), filename)
 
 	f, err := os.Create(path)
 	if err != nil {
 		return nil, fmt.Errorf("create backup file: %w", err)
 	}
 
-	// BoltDB's Tx.WriteTo produces a consistent snapshot.
-	err = m.db.View(func(tx *bolt.Tx) error {
-		_, wErr := tx.WriteTo(f)
-		return wErr
-	})
+	_, err = m.WriteSnapshot(f)
 	closeErr := f.Close()
 	if err != nil {
 		os.Remove(path)
@@ -115,16 +143,32 @@ func (m *Manager) CreateBackup(ctx context.Context) (*Info, error) {
 	}
 
 	// Enforce retention.
-	if m.retain > 0 {
-		m.enforceRetention()
+	if retain := m.Retention(); retain > 0 {
+		m.enforceRetention(retain)
 	}
 
 	return info, nil
 }
 
+// WriteSnapshot writes a consistent copy of the database to w and returns
+// the number of bytes written. On BoltDB the copy holds a read transaction
+// for the duration, during which the file can't be remapped, so the web
+// layer goes read-only (see InProgress).
+func (m *Manager) WriteSnapshot(w io.Writer) (int64, error) {
+	m.running.Store(true)
+	defer m.running.Store(false)
+	return m.db.WriteTo(w)
+}
+
+// InProgress reports whether a hot backup is currently holding its snapshot
+// transaction open.
+func (m *Manager) InProgress() bool {
+	return m.running.Load()
+}
+
 // List returns all backup files sorted by creation time (newest first).
 func (m *Manager) List() ([]Info, error) {
-	entries, err := os.ReadDir(m.dir)
+	entries, err := os.ReadDir(m.Dir())
 	if err != nil {
 		return nil, fmt.Errorf("read backup dir: %w", err)
 	}
@@ -163,7 +207,7 @@ func (m *Manager) FilePath(filename string) (string, error) {
 	if clean != filename || clean == "." || clean == ".." {
 		return "", fmt.Errorf("invalid filename")
 	}
-	path := filepath.Join(m.dir, clean)
+	path := filepath.Join(m.Dir(), clean)
 	if _, err := os.Stat(path); err != nil {
 		return "", fmt.Errorf("backup not found: %s", clean)
 	}
@@ -172,5 +216,7 @@ func (m *Manager) FilePath(filename string) (string, error) {
 
 // Dir returns the backup directory path.
 func (m *Manager) Dir() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	return m.dir
 }
@@ -3,6 +3,7 @@ package backup
 import (
 	"context"
 	"fmt"
+	"io"
 	"path/filepath"
 	"testing"
 	"time"
@@ -31,7 +32,22 @@ func (m *mockUploader) Upload(_ context.Context, localPath, objectName string) e
 	return nil
 }
 
-func newTestDB(t *testing.T) *bolt.DB {
+// boltSource backs up a bare BoltDB file.
+type boltSource struct {
+	*bolt.DB
+}
+
+func (s boltSource) WriteTo(w io.Writer) (int64, error) {
+	var n int64
+	err := s.View(func(tx *bolt.Tx) error {
+		var err error
+		n, err = tx.WriteTo(w)
+		return err
+	})
+	return n, err
+}
+
+func newTestDB(t *testing.T) boltSource {
 	t.Helper()
 	path := filepath.Join(t.TempDir(), "test.db")
 	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 1 * time.Second})
@@ -44,7 +60,7 @@ func newTestDB(t *testing.T) *bolt.DB {
 		return b.Put([]byte("key"), []byte("value"))
 	})
 	t.Cleanup(func() { db.Close() })
-	return db
+	return boltSource{db}
 }
 
 func TestCreateBackup(t *testing.T) {
@@ -67,6 +83,9 @@ func TestCreateBackup(t *testing.T) {
 	if info.Filename == "" {
 		t.Error("backup filename is empty")
 	}
+	if mgr.InProgress() {
+		t.Error("InProgress() = true after backup completed")
+	}
 
 	// Verify the backup is a valid BoltDB file.
 	backupPath := filepath.Join(dir, info.Filename)
@@ -235,3 +254,42 @@ func TestFilePathSanitisation(t *testing.T) {
 		t.Error("expected error for non-existent file")
 	}
 }
+
+func TestSchedulerKeepsScheduleOnInvalidCron(t *testing.T) {
+	mgr, err := NewManager(newTestDB(t), t.TempDir(), &testLogger{})
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	s := NewScheduler(mgr, &testLogger{})
+
+	if err := s.SetSchedule("0 3 * * *"); err != nil {
+		t.Fatalf("SetSchedule: %v", err)
+	}
+	if err := s.SetSchedule("every night"); err == nil {
+		t.Fatal("expected an error for an invalid cron expression")
+	}
+	if got := s.Schedule(); got != "0 3 * * *" {
+		t.Errorf("Schedule() = %q, want the previous schedule kept", got)
+	}
+	if err := s.SetSchedule(""); err != nil || s.Schedule() != "" {
+		t.Errorf("disable: err = %v, schedule = %q", err, s.Schedule())
+	}
+}
+
+func TestSetDir(t *testing.T) {
+	mgr, err := NewManager(newTestDB(t), t.TempDir(), &testLogger{})
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	dir := filepath.Join(t.TempDir(), "nested", "backups")
+	if err := mgr.SetDir(dir); err != nil {
+		t.Fatalf("SetDir: %v", err)
+	}
+	info, err := mgr.CreateBackup(context.Background())
+	if err != nil {
+		t.Fatalf("CreateBackup: %v", err)
+	}
+	if path, err := mgr.FilePath(info.Filename); err != nil || filepath.Dir(path) != dir {
+		t.Errorf("backup written to %q (err %v), want %s", path, err, dir)
+	}
+}
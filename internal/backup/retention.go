@@ -7,8 +7,9 @@ import (
 )
 
 // enforceRetention deletes the oldest local backups beyond the retention limit.
-func (m *Manager) enforceRetention() {
-	entries, err := os.ReadDir(m.dir)
+func (m *Manager) enforceRetention(retain int) {
+	dir := m.Dir()
+	entries, err := os.ReadDir(dir)
 	if err != nil {
 		m.log.Error("retention: failed to list backups", "error", err)
 		return
@@ -31,7 +32,7 @@ func (m *Manager) enforceRetention() {
 		files = append(files, fileEntry{name: e.Name(), modTime: fi.ModTime().UnixNano()})
 	}
 
-	if len(files) <= m.retain {
+	if len(files) <= retain {
 		return
 	}
 
@@ -41,9 +42,9 @@ func (m *Manager) enforceRetention() {
 	})
 
 	// Delete the oldest files beyond the retention limit.
-	toDelete := len(files) - m.retain
+	toDelete := len(files) - retain
 	for i := 0; i < toDelete; i++ {
-		path := filepath.Join(m.dir, files[i].name)
+		path := filepath.Join(dir, files[i].name)
 		if err := os.Remove(path); err != nil {
 			m.log.Error("retention: failed to delete", "file", files[i].name, "error", err)
 		} else {
@@ -14,6 +14,7 @@ type Scheduler struct {
 	log     Logger
 	cron    *cron.Cron
 	entryID cron.EntryID
+	expr    string
 }
 
 // NewScheduler creates a backup scheduler. It does not start until SetSchedule is called.
@@ -26,7 +27,16 @@ func NewScheduler(mgr *Manager, log Logger) *Scheduler {
 }
 
 // SetSchedule configures the cron expression. Empty string disables scheduling.
+// An invalid expression is rejected and the current schedule kept.
 func (s *Scheduler) SetSchedule(expr string) error {
+	var sched cron.Schedule
+	if expr != "" {
+		var err error
+		if sched, err = cron.ParseStandard(expr); err != nil {
+			return err
+		}
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -35,26 +45,29 @@ func (s *Scheduler) SetSchedule(expr string) error {
 		s.cron.Remove(s.entryID)
 		s.entryID = 0
 	}
+	s.expr = expr
 
 	if expr == "" {
 		s.log.Info("backup schedule disabled")
 		return nil
 	}
 
-	id, err := s.cron.AddFunc(expr, func() {
+	s.entryID = s.cron.Schedule(sched, cron.FuncJob(func() {
 		if _, backupErr := s.mgr.CreateBackup(context.Background()); backupErr != nil {
 			s.log.Error("scheduled backup failed", "error", backupErr)
 		}
-	})
-	if err != nil {
-		return err
-	}
-
-	s.entryID = id
+	}))
 	s.log.Info("backup schedule configured", "cron", expr)
 	return nil
 }
 
+// Schedule returns the cron expression in effect, or "" when disabled.
+func (s *Scheduler) Schedule() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.expr
+}
+
 // Start begins the cron scheduler.
 func (s *Scheduler) Start() {
 	s.cron.Start()
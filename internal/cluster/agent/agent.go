@@ -53,6 +53,9 @@ type DockerAPI interface {
 	CreateContainer(ctx context.Context, name string, cfg *container.Config, hostCfg *container.HostConfig, netCfg *network.NetworkingConfig) (string, error)
 	StartContainer(ctx context.Context, id string) error
 	RestartContainer(ctx context.Context, id string) error
+	PauseContainer(ctx context.Context, id string) error
+	UnpauseContainer(ctx context.Context, id string) error
+	KillContainer(ctx context.Context, id, signal string) error
 	RenameContainer(ctx context.Context, id string, newName string) error
 	NetworkConnect(ctx context.Context, networkID string, containerID string) error
 	PullImage(ctx context.Context, refStr string) error
@@ -134,6 +134,8 @@ func (a *Agent) handleListContainers(ctx context.Context, stream proto.AgentServ
 
 // handleUpdateContainer executes the full update lifecycle for a container.
 // For regular containers: inspect -> pull -> stop -> remove -> create -> start.
+// Pre-update hooks run first and post-update hooks after a successful
+// update; their results travel back in the UpdateResult.
 // For self-containers (sentinel.self=true): uses rename-before-replace to
 // avoid killing the agent mid-update.
 func (a *Agent) handleUpdateContainer(ctx context.Context, stream proto.AgentService_ChannelClient, req *proto.UpdateContainerRequest, requestID string) error {
@@ -147,6 +149,24 @@ func (a *Agent) handleUpdateContainer(ctx context.Context, stream proto.AgentSer
 	}
 
 	start := time.Now()
+	hookDefs := a.updateHooks(ctx, name, req)
+	hookResults, skip := a.runUpdateHooks(ctx, name, "pre-update", hookDefs)
+	if skip {
+		a.log.Info("pre-update hook requested skip", "name", name)
+		return a.sendMsg(stream, &proto.AgentMessage{
+			Payload: &proto.AgentMessage_UpdateResult{
+				UpdateResult: &proto.UpdateResult{
+					RequestId:     requestID,
+					ContainerName: name,
+					NewImage:      targetImage,
+					Outcome:       "skipped",
+					Duration:      durationpb.New(time.Since(start)),
+					HookResults:   hookResults,
+				},
+			},
+		})
+	}
+
 	var oldImage, oldDigest, newDigest string
 	var oldContainerID string
 	var err error
@@ -158,6 +178,10 @@ func (a *Agent) handleUpdateContainer(ctx context.Context, stream proto.AgentSer
 	} else {
 		oldImage, oldDigest, newDigest, err = a.recreateContainer(ctx, name, targetImage)
 	}
+	if err == nil {
+		post, _ := a.runUpdateHooks(ctx, name, "post-update", hookDefs)
+		hookResults = append(hookResults, post...)
+	}
 	dur := time.Since(start)
 
 	result := &proto.UpdateResult{
@@ -168,6 +192,7 @@ func (a *Agent) handleUpdateContainer(ctx context.Context, stream proto.AgentSer
 		NewImage:      targetImage,
 		NewDigest:     newDigest,
 		Duration:      durationpb.New(dur),
+		HookResults:   hookResults,
 	}
 
 	if err != nil {
@@ -206,8 +231,8 @@ func (a *Agent) handleUpdateContainer(ctx context.Context, stream proto.AgentSer
 	return a.sendMsg(stream, msg)
 }
 
-// handleContainerAction executes a stop, start, or restart action on a
-// named container and sends the result back to the server.
+// handleContainerAction executes a stop, start, restart, pause, unpause or
+// kill action on a named container and sends the result back to the server.
 func (a *Agent) handleContainerAction(ctx context.Context, stream proto.AgentService_ChannelClient, req *proto.ContainerActionRequest, requestID string) error {
 	name := req.GetContainerName()
 	action := req.GetAction()
@@ -215,16 +240,7 @@ func (a *Agent) handleContainerAction(ctx context.Context, stream proto.AgentSer
 
 	cID, err := a.findContainerID(ctx, name)
 	if err == nil {
-		switch action {
-		case "stop":
-			err = a.docker.StopContainer(ctx, cID, 10)
-		case "start":
-			err = a.docker.StartContainer(ctx, cID)
-		case "restart":
-			err = a.docker.RestartContainer(ctx, cID)
-		default:
-			err = fmt.Errorf("unknown action: %s", action)
-		}
+		err = a.runContainerAction(ctx, cID, action)
 	}
 
 	result := &proto.ContainerActionResult{
@@ -252,6 +268,28 @@ func (a *Agent) handleContainerAction(ctx context.Context, stream proto.AgentSer
 	return a.handleListContainers(ctx, stream, "")
 }
 
+// runContainerAction applies one container action. Kill takes an optional
+// signal as "kill:SIGTERM"; a bare "kill" sends SIGKILL.
+func (a *Agent) runContainerAction(ctx context.Context, id, action string) error {
+	verb, signal, _ := strings.Cut(action, ":")
+	switch verb {
+	case "stop":
+		return a.docker.StopContainer(ctx, id, 10)
+	case "start":
+		return a.docker.StartContainer(ctx, id)
+	case "restart":
+		return a.docker.RestartContainer(ctx, id)
+	case "pause":
+		return a.docker.PauseContainer(ctx, id)
+	case "unpause":
+		return a.docker.UnpauseContainer(ctx, id)
+	case "kill":
+		return a.docker.KillContainer(ctx, id, signal)
+	default:
+		return fmt.Errorf("unknown action: %s", action)
+	}
+}
+
 // handleFetchLogs fetches the last N lines of a container's logs and sends
 // the result back to the server.
 func (a *Agent) handleFetchLogs(ctx context.Context, stream proto.AgentService_ChannelClient, req *proto.FetchLogsRequest, requestID string) error {
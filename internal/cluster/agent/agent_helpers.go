@@ -316,6 +316,27 @@ func (a *Agent) listLocalContainers(ctx context.Context) ([]*proto.ContainerInfo
 	return out, nil
 }
 
+// containerHealth returns the healthcheck status of a container, or "" when
+// it has none. Daemons that predate the Health summary field only report it
+// in the status text, e.g. "Up 2 hours (healthy)".
+func containerHealth(c *container.Summary) string {
+	if c.Health != nil {
+		if c.Health.Status == container.NoHealthcheck {
+			return ""
+		}
+		return string(c.Health.Status)
+	}
+	switch {
+	case strings.HasSuffix(c.Status, "(healthy)"):
+		return string(container.Healthy)
+	case strings.HasSuffix(c.Status, "(unhealthy)"):
+		return string(container.Unhealthy)
+	case strings.HasSuffix(c.Status, "(health: starting)"):
+		return string(container.Starting)
+	}
+	return ""
+}
+
 // containerInfoFromSummary converts a Docker container.Summary into a
 // proto ContainerInfo suitable for sending over the wire.
 func containerInfoFromSummary(c *container.Summary) *proto.ContainerInfo {
@@ -326,10 +347,11 @@ func containerInfoFromSummary(c *container.Summary) *proto.ContainerInfo {
 	}
 
 	info := &proto.ContainerInfo{
-		Id:    c.ID,
-		Name:  name,
-		Image: c.Image,
-		State: string(c.State),
+		Id:     c.ID,
+		Name:   name,
+		Image:  c.Image,
+		State:  string(c.State),
+		Health: containerHealth(c),
 	}
 
 	if len(c.Labels) > 0 {
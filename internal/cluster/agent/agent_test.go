@@ -8,6 +8,7 @@ import (
 	"net/netip"
 	"os"
 	"path/filepath"
+	"slices"
 	"sync"
 	"testing"
 	"time"
@@ -53,6 +54,10 @@ type mockDocker struct {
 	restartCalls []string
 	restartErr   map[string]error
 
+	pauseCalls   []string
+	unpauseCalls []string
+	killCalls    []string // "id:signal"
+
 	renameCalls []struct{ id, newName string }
 	renameErr   map[string]error
 
@@ -174,6 +179,27 @@ func (m *mockDocker) RestartContainer(_ context.Context, id string) error {
 	return nil
 }
 
+func (m *mockDocker) PauseContainer(_ context.Context, id string) error {
+	m.mu.Lock()
+	m.pauseCalls = append(m.pauseCalls, id)
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *mockDocker) UnpauseContainer(_ context.Context, id string) error {
+	m.mu.Lock()
+	m.unpauseCalls = append(m.unpauseCalls, id)
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *mockDocker) KillContainer(_ context.Context, id, signal string) error {
+	m.mu.Lock()
+	m.killCalls = append(m.killCalls, id+":"+signal)
+	m.mu.Unlock()
+	return nil
+}
+
 func (m *mockDocker) RenameContainer(_ context.Context, id string, newName string) error {
 	m.mu.Lock()
 	m.renameCalls = append(m.renameCalls, struct{ id, newName string }{id, newName})
@@ -770,6 +796,26 @@ func TestContainerInfoFromSummary(t *testing.T) {
 	}
 }
 
+func TestContainerInfoFromSummaryHealth(t *testing.T) {
+	tests := []struct {
+		health *container.HealthSummary
+		status string
+		want   string
+	}{
+		{&container.HealthSummary{Status: container.Healthy}, "Up 2 hours (healthy)", "healthy"},
+		{&container.HealthSummary{Status: container.NoHealthcheck}, "Up 2 hours", ""},
+		{nil, "Up 5 seconds (health: starting)", "starting"},
+		{nil, "Up 3 minutes (unhealthy)", "unhealthy"},
+		{nil, "Up 3 minutes", ""},
+	}
+	for _, tt := range tests {
+		s := container.Summary{ID: "abc", State: "running", Status: tt.status, Health: tt.health}
+		if got := containerInfoFromSummary(&s).Health; got != tt.want {
+			t.Errorf("Health for %q = %q, want %q", tt.status, got, tt.want)
+		}
+	}
+}
+
 func TestContainerInfoFromSummaryNoNames(t *testing.T) {
 	s := container.Summary{
 		ID:    "xyz",
@@ -1200,6 +1246,63 @@ func TestClampInt32(t *testing.T) {
 	}
 }
 
+func TestRunContainerAction(t *testing.T) {
+	mock := newMockDocker()
+	a := newTestAgent(t.TempDir(), mock)
+	ctx := context.Background()
+
+	for _, action := range []string{"pause", "unpause", "kill", "kill:SIGHUP"} {
+		if err := a.runContainerAction(ctx, "abc", action); err != nil {
+			t.Fatalf("%s: %v", action, err)
+		}
+	}
+	if len(mock.pauseCalls) != 1 || len(mock.unpauseCalls) != 1 {
+		t.Errorf("pause calls = %v, unpause calls = %v", mock.pauseCalls, mock.unpauseCalls)
+	}
+	if want := []string{"abc:", "abc:SIGHUP"}; !slices.Equal(mock.killCalls, want) {
+		t.Errorf("kill calls = %v, want %v", mock.killCalls, want)
+	}
+	if err := a.runContainerAction(ctx, "abc", "freeze"); err == nil {
+		t.Error("unknown action should fail")
+	}
+}
+
+func TestUpdateHooksAndRun(t *testing.T) {
+	mock := newMockDocker()
+	mock.containers = []container.Summary{{ID: "db1", Names: []string{"/db"}}}
+	mock.inspectResults["db1"] = container.InspectResponse{Config: &container.Config{Labels: map[string]string{
+		"sentinel.hook.pre-update":  "echo label-pre",
+		"sentinel.hook.post-update": "echo label-post",
+	}}}
+	a := newTestAgent(t.TempDir(), mock)
+	ctx := context.Background()
+
+	req := &proto.UpdateContainerRequest{
+		ContainerName: "db",
+		Hooks:         []*proto.HookDefinition{{Phase: "pre-update", Command: []string{"pg_dump"}}},
+	}
+	defs := a.updateHooks(ctx, "db", req)
+	if len(defs) != 2 || defs[0].GetCommand()[0] != "pg_dump" || defs[1].GetPhase() != "post-update" {
+		t.Fatalf("defs = %v, want the server pre hook plus the label post hook", defs)
+	}
+	req.SkipHooks = true
+	if got := a.updateHooks(ctx, "db", req); got != nil {
+		t.Errorf("skip_hooks should run nothing, got %v", got)
+	}
+
+	mock.execResults["db1"] = struct {
+		exitCode int
+		output   string
+	}{75, "maintenance window"}
+	results, skip := a.runUpdateHooks(ctx, "db", "pre-update", defs)
+	if !skip || len(results) != 1 || results[0].ExitCode != 75 || results[0].Output != "maintenance window" {
+		t.Errorf("pre-update: results = %v, skip = %v; want one exit 75 result and skip", results, skip)
+	}
+	if _, skip := a.runUpdateHooks(ctx, "db", "post-update", defs); skip {
+		t.Error("exit 75 only skips from a pre-update hook")
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Helpers
 // ---------------------------------------------------------------------------
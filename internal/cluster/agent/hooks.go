@@ -0,0 +1,99 @@
+package agent
+
+import (
+	"context"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/cluster/proto"
+	"github.com/Will-Luck/Docker-Sentinel/internal/hooks"
+)
+
+// hookExitSkip is the pre-update hook exit code that cancels the update,
+// matching hooks.ErrSkipUpdate on the server.
+const hookExitSkip = 75
+
+// updateHooks returns the hooks to run around an update. Hooks sent by the
+// server win; sentinel.hook.* labels on the container fill in any phase the
+// server left empty. Nothing runs when the server asked to skip hooks.
+func (a *Agent) updateHooks(ctx context.Context, name string, req *proto.UpdateContainerRequest) []*proto.HookDefinition {
+	if req.GetSkipHooks() {
+		return nil
+	}
+	defs := req.GetHooks()
+
+	id, err := a.findContainerID(ctx, name)
+	if err != nil {
+		return defs
+	}
+	inspect, err := a.docker.InspectContainer(ctx, id)
+	if err != nil || inspect.Config == nil {
+		return defs
+	}
+	for _, h := range hooks.ReadLabels(name, inspect.Config.Labels) {
+		if len(hooksForPhase(defs, h.Phase)) > 0 {
+			continue
+		}
+		defs = append(defs, &proto.HookDefinition{
+			Phase:          h.Phase,
+			Command:        h.Command,
+			TimeoutSeconds: clampInt32(h.Timeout),
+		})
+	}
+	return defs
+}
+
+// hooksForPhase filters defs to one phase, keeping their order.
+func hooksForPhase(defs []*proto.HookDefinition, phase string) []*proto.HookDefinition {
+	var out []*proto.HookDefinition
+	for _, d := range defs {
+		if d.GetPhase() == phase {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// runUpdateHooks executes the hooks for one phase inside the named
+// container, stopping at the first failure as the local hook runner does.
+// skip reports that a pre-update hook exited 75 to cancel the update.
+func (a *Agent) runUpdateHooks(ctx context.Context, name, phase string, defs []*proto.HookDefinition) (results []*proto.HookResult, skip bool) {
+	defs = hooksForPhase(defs, phase)
+	if len(defs) == 0 {
+		return nil, false
+	}
+	id, err := a.findContainerID(ctx, name)
+	if err != nil {
+		return []*proto.HookResult{{ContainerName: name, Phase: phase, ExitCode: -1, Error: err.Error()}}, false
+	}
+
+	for _, d := range defs {
+		timeout := int(d.GetTimeoutSeconds())
+		if timeout <= 0 {
+			timeout = 30
+		}
+		res := &proto.HookResult{ContainerName: name, Phase: phase}
+		if len(d.GetCommand()) == 0 {
+			res.ExitCode = -1
+			res.Error = "empty command"
+			return append(results, res), false
+		}
+
+		execCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+		exitCode, output, execErr := a.docker.ExecContainer(execCtx, id, d.GetCommand(), timeout)
+		cancel()
+
+		res.ExitCode = clampInt32(exitCode)
+		res.Output = output
+		if execErr != nil {
+			res.ExitCode = -1
+			res.Error = execErr.Error()
+		}
+		results = append(results, res)
+		a.log.Info(phase+" hook completed", "container", name, "exit_code", res.ExitCode, "error", res.Error)
+
+		if execErr != nil || exitCode != 0 {
+			return results, phase == "pre-update" && execErr == nil && exitCode == hookExitSkip
+		}
+	}
+	return results, false
+}
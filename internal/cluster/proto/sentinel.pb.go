@@ -719,6 +719,7 @@ type ContainerInfo struct {
 	Labels        map[string]string      `protobuf:"bytes,6,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	Created       *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created,proto3" json:"created,omitempty"`
 	Ports         []*PortMapping         `protobuf:"bytes,8,rep,name=ports,proto3" json:"ports,omitempty"`
+	Health        string                 `protobuf:"bytes,9,opt,name=health,proto3" json:"health,omitempty"` // healthcheck status: "healthy", "unhealthy", "starting"; empty without a healthcheck
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -809,6 +810,13 @@ func (x *ContainerInfo) GetPorts() []*PortMapping {
 	return nil
 }
 
+func (x *ContainerInfo) GetHealth() string {
+	if x != nil {
+		return x.Health
+	}
+	return ""
+}
+
 type ContainerList struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	RequestId     string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"` // echoes the server's request_id
@@ -903,6 +911,7 @@ type UpdateContainerRequest struct {
 	TargetImage   string                 `protobuf:"bytes,2,opt,name=target_image,json=targetImage,proto3" json:"target_image,omitempty"`    // image reference to update to
 	TargetDigest  string                 `protobuf:"bytes,3,opt,name=target_digest,json=targetDigest,proto3" json:"target_digest,omitempty"` // expected digest of the new image
 	SkipHooks     bool                   `protobuf:"varint,4,opt,name=skip_hooks,json=skipHooks,proto3" json:"skip_hooks,omitempty"`         // skip pre/post-update hooks
+	Hooks         []*HookDefinition      `protobuf:"bytes,5,rep,name=hooks,proto3" json:"hooks,omitempty"`                                   // server-configured hooks, run on the agent host
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -965,10 +974,17 @@ func (x *UpdateContainerRequest) GetSkipHooks() bool {
 	return false
 }
 
+func (x *UpdateContainerRequest) GetHooks() []*HookDefinition {
+	if x != nil {
+		return x.Hooks
+	}
+	return nil
+}
+
 type ContainerActionRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	ContainerName string                 `protobuf:"bytes,1,opt,name=container_name,json=containerName,proto3" json:"container_name,omitempty"`
-	Action        string                 `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"` // "stop", "start", "restart"
+	Action        string                 `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"` // "stop", "start", "restart", "pause", "unpause", "kill" or "kill:SIGNAL"
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -1232,6 +1248,7 @@ type UpdateResult struct {
 	Outcome       string                 `protobuf:"bytes,7,opt,name=outcome,proto3" json:"outcome,omitempty"` // "success", "rollback", "failed"
 	Error         string                 `protobuf:"bytes,8,opt,name=error,proto3" json:"error,omitempty"`
 	Duration      *durationpb.Duration   `protobuf:"bytes,9,opt,name=duration,proto3" json:"duration,omitempty"`
+	HookResults   []*HookResult          `protobuf:"bytes,10,rep,name=hook_results,json=hookResults,proto3" json:"hook_results,omitempty"` // hooks run during the update, in order
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -1329,6 +1346,13 @@ func (x *UpdateResult) GetDuration() *durationpb.Duration {
 	return nil
 }
 
+func (x *UpdateResult) GetHookResults() []*HookResult {
+	if x != nil {
+		return x.HookResults
+	}
+	return nil
+}
+
 type PullImageRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	ImageRef      string                 `protobuf:"bytes,1,opt,name=image_ref,json=imageRef,proto3" json:"image_ref,omitempty"` // image to pull (e.g. "nginx:1.26")
@@ -1525,6 +1549,68 @@ func (x *HookResult) GetError() string {
 	return ""
 }
 
+// HookDefinition is a lifecycle hook carried by UpdateContainerRequest and
+// executed inside the container on the agent host.
+type HookDefinition struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Phase          string                 `protobuf:"bytes,1,opt,name=phase,proto3" json:"phase,omitempty"`     // "pre-update" or "post-update"
+	Command        []string               `protobuf:"bytes,2,rep,name=command,proto3" json:"command,omitempty"` // exec argv, e.g. ["/bin/sh", "-c", "pg_dump ..."]
+	TimeoutSeconds int32                  `protobuf:"varint,3,opt,name=timeout_seconds,json=timeoutSeconds,proto3" json:"timeout_seconds,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *HookDefinition) Reset() {
+	*x = HookDefinition{}
+	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HookDefinition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HookDefinition) ProtoMessage() {}
+
+func (x *HookDefinition) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HookDefinition.ProtoReflect.Descriptor instead.
+func (*HookDefinition) Descriptor() ([]byte, []int) {
+	return file_internal_cluster_proto_sentinel_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *HookDefinition) GetPhase() string {
+	if x != nil {
+		return x.Phase
+	}
+	return ""
+}
+
+func (x *HookDefinition) GetCommand() []string {
+	if x != nil {
+		return x.Command
+	}
+	return nil
+}
+
+func (x *HookDefinition) GetTimeoutSeconds() int32 {
+	if x != nil {
+		return x.TimeoutSeconds
+	}
+	return 0
+}
+
 type RollbackRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	ContainerName string                 `protobuf:"bytes,1,opt,name=container_name,json=containerName,proto3" json:"container_name,omitempty"`
@@ -1534,7 +1620,7 @@ type RollbackRequest struct {
 
 func (x *RollbackRequest) Reset() {
 	*x = RollbackRequest{}
-	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[18]
+	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1546,7 +1632,7 @@ func (x *RollbackRequest) String() string {
 func (*RollbackRequest) ProtoMessage() {}
 
 func (x *RollbackRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[18]
+	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1559,7 +1645,7 @@ func (x *RollbackRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RollbackRequest.ProtoReflect.Descriptor instead.
 func (*RollbackRequest) Descriptor() ([]byte, []int) {
-	return file_internal_cluster_proto_sentinel_proto_rawDescGZIP(), []int{18}
+	return file_internal_cluster_proto_sentinel_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *RollbackRequest) GetContainerName() string {
@@ -1581,7 +1667,7 @@ type RollbackResult struct {
 
 func (x *RollbackResult) Reset() {
 	*x = RollbackResult{}
-	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[19]
+	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1593,7 +1679,7 @@ func (x *RollbackResult) String() string {
 func (*RollbackResult) ProtoMessage() {}
 
 func (x *RollbackResult) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[19]
+	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1606,7 +1692,7 @@ func (x *RollbackResult) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RollbackResult.ProtoReflect.Descriptor instead.
 func (*RollbackResult) Descriptor() ([]byte, []int) {
-	return file_internal_cluster_proto_sentinel_proto_rawDescGZIP(), []int{19}
+	return file_internal_cluster_proto_sentinel_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *RollbackResult) GetRequestId() string {
@@ -1651,7 +1737,7 @@ type StateReport struct {
 
 func (x *StateReport) Reset() {
 	*x = StateReport{}
-	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[20]
+	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1663,7 +1749,7 @@ func (x *StateReport) String() string {
 func (*StateReport) ProtoMessage() {}
 
 func (x *StateReport) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[20]
+	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1676,7 +1762,7 @@ func (x *StateReport) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StateReport.ProtoReflect.Descriptor instead.
 func (*StateReport) Descriptor() ([]byte, []int) {
-	return file_internal_cluster_proto_sentinel_proto_rawDescGZIP(), []int{20}
+	return file_internal_cluster_proto_sentinel_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *StateReport) GetHostId() string {
@@ -1731,7 +1817,7 @@ type StateAck struct {
 
 func (x *StateAck) Reset() {
 	*x = StateAck{}
-	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[21]
+	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[22]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1743,7 +1829,7 @@ func (x *StateAck) String() string {
 func (*StateAck) ProtoMessage() {}
 
 func (x *StateAck) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[21]
+	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[22]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1756,7 +1842,7 @@ func (x *StateAck) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StateAck.ProtoReflect.Descriptor instead.
 func (*StateAck) Descriptor() ([]byte, []int) {
-	return file_internal_cluster_proto_sentinel_proto_rawDescGZIP(), []int{21}
+	return file_internal_cluster_proto_sentinel_proto_rawDescGZIP(), []int{22}
 }
 
 func (x *StateAck) GetAccepted() bool {
@@ -1784,7 +1870,7 @@ type PolicySync struct {
 
 func (x *PolicySync) Reset() {
 	*x = PolicySync{}
-	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[22]
+	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[23]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1796,7 +1882,7 @@ func (x *PolicySync) String() string {
 func (*PolicySync) ProtoMessage() {}
 
 func (x *PolicySync) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[22]
+	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[23]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1809,7 +1895,7 @@ func (x *PolicySync) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PolicySync.ProtoReflect.Descriptor instead.
 func (*PolicySync) Descriptor() ([]byte, []int) {
-	return file_internal_cluster_proto_sentinel_proto_rawDescGZIP(), []int{22}
+	return file_internal_cluster_proto_sentinel_proto_rawDescGZIP(), []int{23}
 }
 
 func (x *PolicySync) GetPolicies() map[string]string {
@@ -1841,7 +1927,7 @@ type SettingsSync struct {
 
 func (x *SettingsSync) Reset() {
 	*x = SettingsSync{}
-	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[23]
+	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[24]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1853,7 +1939,7 @@ func (x *SettingsSync) String() string {
 func (*SettingsSync) ProtoMessage() {}
 
 func (x *SettingsSync) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[23]
+	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[24]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1866,7 +1952,7 @@ func (x *SettingsSync) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SettingsSync.ProtoReflect.Descriptor instead.
 func (*SettingsSync) Descriptor() ([]byte, []int) {
-	return file_internal_cluster_proto_sentinel_proto_rawDescGZIP(), []int{23}
+	return file_internal_cluster_proto_sentinel_proto_rawDescGZIP(), []int{24}
 }
 
 func (x *SettingsSync) GetPollInterval() *durationpb.Duration {
@@ -1927,7 +2013,7 @@ type OfflineJournal struct {
 
 func (x *OfflineJournal) Reset() {
 	*x = OfflineJournal{}
-	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[24]
+	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1939,7 +2025,7 @@ func (x *OfflineJournal) String() string {
 func (*OfflineJournal) ProtoMessage() {}
 
 func (x *OfflineJournal) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[24]
+	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1952,7 +2038,7 @@ func (x *OfflineJournal) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use OfflineJournal.ProtoReflect.Descriptor instead.
 func (*OfflineJournal) Descriptor() ([]byte, []int) {
-	return file_internal_cluster_proto_sentinel_proto_rawDescGZIP(), []int{24}
+	return file_internal_cluster_proto_sentinel_proto_rawDescGZIP(), []int{25}
 }
 
 func (x *OfflineJournal) GetEntries() []*JournalEntry {
@@ -1981,7 +2067,7 @@ type JournalEntry struct {
 
 func (x *JournalEntry) Reset() {
 	*x = JournalEntry{}
-	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[25]
+	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1993,7 +2079,7 @@ func (x *JournalEntry) String() string {
 func (*JournalEntry) ProtoMessage() {}
 
 func (x *JournalEntry) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[25]
+	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2006,7 +2092,7 @@ func (x *JournalEntry) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use JournalEntry.ProtoReflect.Descriptor instead.
 func (*JournalEntry) Descriptor() ([]byte, []int) {
-	return file_internal_cluster_proto_sentinel_proto_rawDescGZIP(), []int{25}
+	return file_internal_cluster_proto_sentinel_proto_rawDescGZIP(), []int{26}
 }
 
 func (x *JournalEntry) GetId() string {
@@ -2095,7 +2181,7 @@ type CertRenewalCSR struct {
 
 func (x *CertRenewalCSR) Reset() {
 	*x = CertRenewalCSR{}
-	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[26]
+	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2107,7 +2193,7 @@ func (x *CertRenewalCSR) String() string {
 func (*CertRenewalCSR) ProtoMessage() {}
 
 func (x *CertRenewalCSR) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[26]
+	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2120,7 +2206,7 @@ func (x *CertRenewalCSR) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CertRenewalCSR.ProtoReflect.Descriptor instead.
 func (*CertRenewalCSR) Descriptor() ([]byte, []int) {
-	return file_internal_cluster_proto_sentinel_proto_rawDescGZIP(), []int{26}
+	return file_internal_cluster_proto_sentinel_proto_rawDescGZIP(), []int{27}
 }
 
 func (x *CertRenewalCSR) GetCsr() []byte {
@@ -2139,7 +2225,7 @@ type CertRenewalResponse struct {
 
 func (x *CertRenewalResponse) Reset() {
 	*x = CertRenewalResponse{}
-	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[27]
+	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2151,7 +2237,7 @@ func (x *CertRenewalResponse) String() string {
 func (*CertRenewalResponse) ProtoMessage() {}
 
 func (x *CertRenewalResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[27]
+	mi := &file_internal_cluster_proto_sentinel_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2164,7 +2250,7 @@ func (x *CertRenewalResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CertRenewalResponse.ProtoReflect.Descriptor instead.
 func (*CertRenewalResponse) Descriptor() ([]byte, []int) {
-	return file_internal_cluster_proto_sentinel_proto_rawDescGZIP(), []int{27}
+	return file_internal_cluster_proto_sentinel_proto_rawDescGZIP(), []int{28}
 }
 
 func (x *CertRenewalResponse) GetAgentCert() []byte {
@@ -2228,7 +2314,7 @@ const file_internal_cluster_proto_sentinel_proto_rawDesc = "" +
 	"\ahost_ip\x18\x01 \x01(\tR\x06hostIp\x12\x1b\n" +
 	"\thost_port\x18\x02 \x01(\rR\bhostPort\x12%\n" +
 	"\x0econtainer_port\x18\x03 \x01(\rR\rcontainerPort\x12\x1a\n" +
-	"\bprotocol\x18\x04 \x01(\tR\bprotocol\"\xed\x02\n" +
+	"\bprotocol\x18\x04 \x01(\tR\bprotocol\"\x85\x03\n" +
 	"\rContainerInfo\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
@@ -2237,7 +2323,8 @@ const file_internal_cluster_proto_sentinel_proto_rawDesc = "" +
 	"\x05state\x18\x05 \x01(\tR\x05state\x12C\n" +
 	"\x06labels\x18\x06 \x03(\v2+.sentinel.cluster.ContainerInfo.LabelsEntryR\x06labels\x124\n" +
 	"\acreated\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\acreated\x123\n" +
-	"\x05ports\x18\b \x03(\v2\x1d.sentinel.cluster.PortMappingR\x05ports\x1a9\n" +
+	"\x05ports\x18\b \x03(\v2\x1d.sentinel.cluster.PortMappingR\x05ports\x12\x16\n" +
+	"\x06health\x18\t \x01(\tR\x06health\x1a9\n" +
 	"\vLabelsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"o\n" +
@@ -2247,13 +2334,14 @@ const file_internal_cluster_proto_sentinel_proto_rawDesc = "" +
 	"\n" +
 	"containers\x18\x02 \x03(\v2\x1f.sentinel.cluster.ContainerInfoR\n" +
 	"containers\"\x17\n" +
-	"\x15ListContainersRequest\"\xa6\x01\n" +
+	"\x15ListContainersRequest\"\xde\x01\n" +
 	"\x16UpdateContainerRequest\x12%\n" +
 	"\x0econtainer_name\x18\x01 \x01(\tR\rcontainerName\x12!\n" +
 	"\ftarget_image\x18\x02 \x01(\tR\vtargetImage\x12#\n" +
 	"\rtarget_digest\x18\x03 \x01(\tR\ftargetDigest\x12\x1d\n" +
 	"\n" +
-	"skip_hooks\x18\x04 \x01(\bR\tskipHooks\"W\n" +
+	"skip_hooks\x18\x04 \x01(\bR\tskipHooks\x126\n" +
+	"\x05hooks\x18\x05 \x03(\v2 .sentinel.cluster.HookDefinitionR\x05hooks\"W\n" +
 	"\x16ContainerActionRequest\x12%\n" +
 	"\x0econtainer_name\x18\x01 \x01(\tR\rcontainerName\x12\x16\n" +
 	"\x06action\x18\x02 \x01(\tR\x06action\"O\n" +
@@ -2273,7 +2361,7 @@ const file_internal_cluster_proto_sentinel_proto_rawDesc = "" +
 	"\x0econtainer_name\x18\x02 \x01(\tR\rcontainerName\x12\x16\n" +
 	"\x06action\x18\x03 \x01(\tR\x06action\x12\x18\n" +
 	"\aoutcome\x18\x04 \x01(\tR\aoutcome\x12\x14\n" +
-	"\x05error\x18\x05 \x01(\tR\x05error\"\xb3\x02\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\"\xf4\x02\n" +
 	"\fUpdateResult\x12\x1d\n" +
 	"\n" +
 	"request_id\x18\x01 \x01(\tR\trequestId\x12%\n" +
@@ -2286,7 +2374,9 @@ const file_internal_cluster_proto_sentinel_proto_rawDesc = "" +
 	"new_digest\x18\x06 \x01(\tR\tnewDigest\x12\x18\n" +
 	"\aoutcome\x18\a \x01(\tR\aoutcome\x12\x14\n" +
 	"\x05error\x18\b \x01(\tR\x05error\x125\n" +
-	"\bduration\x18\t \x01(\v2\x19.google.protobuf.DurationR\bduration\"/\n" +
+	"\bduration\x18\t \x01(\v2\x19.google.protobuf.DurationR\bduration\x12?\n" +
+	"\fhook_results\x18\n" +
+	" \x03(\v2\x1c.sentinel.cluster.HookResultR\vhookResults\"/\n" +
 	"\x10PullImageRequest\x12\x1b\n" +
 	"\timage_ref\x18\x01 \x01(\tR\bimageRef\"\x90\x01\n" +
 	"\x0eRunHookRequest\x12%\n" +
@@ -2302,7 +2392,11 @@ const file_internal_cluster_proto_sentinel_proto_rawDesc = "" +
 	"\x05phase\x18\x03 \x01(\tR\x05phase\x12\x1b\n" +
 	"\texit_code\x18\x04 \x01(\x05R\bexitCode\x12\x16\n" +
 	"\x06output\x18\x05 \x01(\tR\x06output\x12\x14\n" +
-	"\x05error\x18\x06 \x01(\tR\x05error\"8\n" +
+	"\x05error\x18\x06 \x01(\tR\x05error\"i\n" +
+	"\x0eHookDefinition\x12\x14\n" +
+	"\x05phase\x18\x01 \x01(\tR\x05phase\x12\x18\n" +
+	"\acommand\x18\x02 \x03(\tR\acommand\x12'\n" +
+	"\x0ftimeout_seconds\x18\x03 \x01(\x05R\x0etimeoutSeconds\"8\n" +
 	"\x0fRollbackRequest\x12%\n" +
 	"\x0econtainer_name\x18\x01 \x01(\tR\rcontainerName\"\x86\x01\n" +
 	"\x0eRollbackResult\x12\x1d\n" +
@@ -2378,7 +2472,7 @@ func file_internal_cluster_proto_sentinel_proto_rawDescGZIP() []byte {
 	return file_internal_cluster_proto_sentinel_proto_rawDescData
 }
 
-var file_internal_cluster_proto_sentinel_proto_msgTypes = make([]protoimpl.MessageInfo, 30)
+var file_internal_cluster_proto_sentinel_proto_msgTypes = make([]protoimpl.MessageInfo, 31)
 var file_internal_cluster_proto_sentinel_proto_goTypes = []any{
 	(*EnrollRequest)(nil),          // 0: sentinel.cluster.EnrollRequest
 	(*EnrollResponse)(nil),         // 1: sentinel.cluster.EnrollResponse
@@ -2398,29 +2492,30 @@ var file_internal_cluster_proto_sentinel_proto_goTypes = []any{
 	(*PullImageRequest)(nil),       // 15: sentinel.cluster.PullImageRequest
 	(*RunHookRequest)(nil),         // 16: sentinel.cluster.RunHookRequest
 	(*HookResult)(nil),             // 17: sentinel.cluster.HookResult
-	(*RollbackRequest)(nil),        // 18: sentinel.cluster.RollbackRequest
-	(*RollbackResult)(nil),         // 19: sentinel.cluster.RollbackResult
-	(*StateReport)(nil),            // 20: sentinel.cluster.StateReport
-	(*StateAck)(nil),               // 21: sentinel.cluster.StateAck
-	(*PolicySync)(nil),             // 22: sentinel.cluster.PolicySync
-	(*SettingsSync)(nil),           // 23: sentinel.cluster.SettingsSync
-	(*OfflineJournal)(nil),         // 24: sentinel.cluster.OfflineJournal
-	(*JournalEntry)(nil),           // 25: sentinel.cluster.JournalEntry
-	(*CertRenewalCSR)(nil),         // 26: sentinel.cluster.CertRenewalCSR
-	(*CertRenewalResponse)(nil),    // 27: sentinel.cluster.CertRenewalResponse
-	nil,                            // 28: sentinel.cluster.ContainerInfo.LabelsEntry
-	nil,                            // 29: sentinel.cluster.PolicySync.PoliciesEntry
-	(*timestamppb.Timestamp)(nil),  // 30: google.protobuf.Timestamp
-	(*durationpb.Duration)(nil),    // 31: google.protobuf.Duration
+	(*HookDefinition)(nil),         // 18: sentinel.cluster.HookDefinition
+	(*RollbackRequest)(nil),        // 19: sentinel.cluster.RollbackRequest
+	(*RollbackResult)(nil),         // 20: sentinel.cluster.RollbackResult
+	(*StateReport)(nil),            // 21: sentinel.cluster.StateReport
+	(*StateAck)(nil),               // 22: sentinel.cluster.StateAck
+	(*PolicySync)(nil),             // 23: sentinel.cluster.PolicySync
+	(*SettingsSync)(nil),           // 24: sentinel.cluster.SettingsSync
+	(*OfflineJournal)(nil),         // 25: sentinel.cluster.OfflineJournal
+	(*JournalEntry)(nil),           // 26: sentinel.cluster.JournalEntry
+	(*CertRenewalCSR)(nil),         // 27: sentinel.cluster.CertRenewalCSR
+	(*CertRenewalResponse)(nil),    // 28: sentinel.cluster.CertRenewalResponse
+	nil,                            // 29: sentinel.cluster.ContainerInfo.LabelsEntry
+	nil,                            // 30: sentinel.cluster.PolicySync.PoliciesEntry
+	(*timestamppb.Timestamp)(nil),  // 31: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),    // 32: google.protobuf.Duration
 }
 var file_internal_cluster_proto_sentinel_proto_depIdxs = []int32{
 	4,  // 0: sentinel.cluster.AgentMessage.heartbeat:type_name -> sentinel.cluster.Heartbeat
 	7,  // 1: sentinel.cluster.AgentMessage.container_list:type_name -> sentinel.cluster.ContainerList
 	14, // 2: sentinel.cluster.AgentMessage.update_result:type_name -> sentinel.cluster.UpdateResult
 	17, // 3: sentinel.cluster.AgentMessage.hook_result:type_name -> sentinel.cluster.HookResult
-	19, // 4: sentinel.cluster.AgentMessage.rollback_result:type_name -> sentinel.cluster.RollbackResult
-	24, // 5: sentinel.cluster.AgentMessage.offline_journal:type_name -> sentinel.cluster.OfflineJournal
-	26, // 6: sentinel.cluster.AgentMessage.cert_renewal:type_name -> sentinel.cluster.CertRenewalCSR
+	20, // 4: sentinel.cluster.AgentMessage.rollback_result:type_name -> sentinel.cluster.RollbackResult
+	25, // 5: sentinel.cluster.AgentMessage.offline_journal:type_name -> sentinel.cluster.OfflineJournal
+	27, // 6: sentinel.cluster.AgentMessage.cert_renewal:type_name -> sentinel.cluster.CertRenewalCSR
 	13, // 7: sentinel.cluster.AgentMessage.container_action_result:type_name -> sentinel.cluster.ContainerActionResult
 	12, // 8: sentinel.cluster.AgentMessage.fetch_logs_result:type_name -> sentinel.cluster.FetchLogsResult
 	4,  // 9: sentinel.cluster.ServerMessage.heartbeat:type_name -> sentinel.cluster.Heartbeat
@@ -2428,37 +2523,39 @@ var file_internal_cluster_proto_sentinel_proto_depIdxs = []int32{
 	9,  // 11: sentinel.cluster.ServerMessage.update_container:type_name -> sentinel.cluster.UpdateContainerRequest
 	15, // 12: sentinel.cluster.ServerMessage.pull_image:type_name -> sentinel.cluster.PullImageRequest
 	16, // 13: sentinel.cluster.ServerMessage.run_hook:type_name -> sentinel.cluster.RunHookRequest
-	18, // 14: sentinel.cluster.ServerMessage.rollback:type_name -> sentinel.cluster.RollbackRequest
-	22, // 15: sentinel.cluster.ServerMessage.policy_sync:type_name -> sentinel.cluster.PolicySync
-	23, // 16: sentinel.cluster.ServerMessage.settings_sync:type_name -> sentinel.cluster.SettingsSync
-	27, // 17: sentinel.cluster.ServerMessage.cert_renewal_response:type_name -> sentinel.cluster.CertRenewalResponse
+	19, // 14: sentinel.cluster.ServerMessage.rollback:type_name -> sentinel.cluster.RollbackRequest
+	23, // 15: sentinel.cluster.ServerMessage.policy_sync:type_name -> sentinel.cluster.PolicySync
+	24, // 16: sentinel.cluster.ServerMessage.settings_sync:type_name -> sentinel.cluster.SettingsSync
+	28, // 17: sentinel.cluster.ServerMessage.cert_renewal_response:type_name -> sentinel.cluster.CertRenewalResponse
 	10, // 18: sentinel.cluster.ServerMessage.container_action:type_name -> sentinel.cluster.ContainerActionRequest
 	11, // 19: sentinel.cluster.ServerMessage.fetch_logs:type_name -> sentinel.cluster.FetchLogsRequest
-	30, // 20: sentinel.cluster.Heartbeat.timestamp:type_name -> google.protobuf.Timestamp
-	28, // 21: sentinel.cluster.ContainerInfo.labels:type_name -> sentinel.cluster.ContainerInfo.LabelsEntry
-	30, // 22: sentinel.cluster.ContainerInfo.created:type_name -> google.protobuf.Timestamp
+	31, // 20: sentinel.cluster.Heartbeat.timestamp:type_name -> google.protobuf.Timestamp
+	29, // 21: sentinel.cluster.ContainerInfo.labels:type_name -> sentinel.cluster.ContainerInfo.LabelsEntry
+	31, // 22: sentinel.cluster.ContainerInfo.created:type_name -> google.protobuf.Timestamp
 	5,  // 23: sentinel.cluster.ContainerInfo.ports:type_name -> sentinel.cluster.PortMapping
 	6,  // 24: sentinel.cluster.ContainerList.containers:type_name -> sentinel.cluster.ContainerInfo
-	31, // 25: sentinel.cluster.UpdateResult.duration:type_name -> google.protobuf.Duration
-	6,  // 26: sentinel.cluster.StateReport.containers:type_name -> sentinel.cluster.ContainerInfo
-	30, // 27: sentinel.cluster.StateReport.timestamp:type_name -> google.protobuf.Timestamp
-	29, // 28: sentinel.cluster.PolicySync.policies:type_name -> sentinel.cluster.PolicySync.PoliciesEntry
-	31, // 29: sentinel.cluster.SettingsSync.poll_interval:type_name -> google.protobuf.Duration
-	31, // 30: sentinel.cluster.SettingsSync.grace_period:type_name -> google.protobuf.Duration
-	25, // 31: sentinel.cluster.OfflineJournal.entries:type_name -> sentinel.cluster.JournalEntry
-	30, // 32: sentinel.cluster.JournalEntry.timestamp:type_name -> google.protobuf.Timestamp
-	31, // 33: sentinel.cluster.JournalEntry.duration:type_name -> google.protobuf.Duration
-	0,  // 34: sentinel.cluster.EnrollmentService.Enroll:input_type -> sentinel.cluster.EnrollRequest
-	2,  // 35: sentinel.cluster.AgentService.Channel:input_type -> sentinel.cluster.AgentMessage
-	20, // 36: sentinel.cluster.AgentService.ReportState:input_type -> sentinel.cluster.StateReport
-	1,  // 37: sentinel.cluster.EnrollmentService.Enroll:output_type -> sentinel.cluster.EnrollResponse
-	3,  // 38: sentinel.cluster.AgentService.Channel:output_type -> sentinel.cluster.ServerMessage
-	21, // 39: sentinel.cluster.AgentService.ReportState:output_type -> sentinel.cluster.StateAck
-	37, // [37:40] is the sub-list for method output_type
-	34, // [34:37] is the sub-list for method input_type
-	34, // [34:34] is the sub-list for extension type_name
-	34, // [34:34] is the sub-list for extension extendee
-	0,  // [0:34] is the sub-list for field type_name
+	18, // 25: sentinel.cluster.UpdateContainerRequest.hooks:type_name -> sentinel.cluster.HookDefinition
+	32, // 26: sentinel.cluster.UpdateResult.duration:type_name -> google.protobuf.Duration
+	17, // 27: sentinel.cluster.UpdateResult.hook_results:type_name -> sentinel.cluster.HookResult
+	6,  // 28: sentinel.cluster.StateReport.containers:type_name -> sentinel.cluster.ContainerInfo
+	31, // 29: sentinel.cluster.StateReport.timestamp:type_name -> google.protobuf.Timestamp
+	30, // 30: sentinel.cluster.PolicySync.policies:type_name -> sentinel.cluster.PolicySync.PoliciesEntry
+	32, // 31: sentinel.cluster.SettingsSync.poll_interval:type_name -> google.protobuf.Duration
+	32, // 32: sentinel.cluster.SettingsSync.grace_period:type_name -> google.protobuf.Duration
+	26, // 33: sentinel.cluster.OfflineJournal.entries:type_name -> sentinel.cluster.JournalEntry
+	31, // 34: sentinel.cluster.JournalEntry.timestamp:type_name -> google.protobuf.Timestamp
+	32, // 35: sentinel.cluster.JournalEntry.duration:type_name -> google.protobuf.Duration
+	0,  // 36: sentinel.cluster.EnrollmentService.Enroll:input_type -> sentinel.cluster.EnrollRequest
+	2,  // 37: sentinel.cluster.AgentService.Channel:input_type -> sentinel.cluster.AgentMessage
+	21, // 38: sentinel.cluster.AgentService.ReportState:input_type -> sentinel.cluster.StateReport
+	1,  // 39: sentinel.cluster.EnrollmentService.Enroll:output_type -> sentinel.cluster.EnrollResponse
+	3,  // 40: sentinel.cluster.AgentService.Channel:output_type -> sentinel.cluster.ServerMessage
+	22, // 41: sentinel.cluster.AgentService.ReportState:output_type -> sentinel.cluster.StateAck
+	39, // [39:42] is the sub-list for method output_type
+	36, // [36:39] is the sub-list for method input_type
+	36, // [36:36] is the sub-list for extension type_name
+	36, // [36:36] is the sub-list for extension extendee
+	0,  // [0:36] is the sub-list for field type_name
 }
 
 func init() { file_internal_cluster_proto_sentinel_proto_init() }
@@ -2496,7 +2593,7 @@ func file_internal_cluster_proto_sentinel_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_cluster_proto_sentinel_proto_rawDesc), len(file_internal_cluster_proto_sentinel_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   30,
+			NumMessages:   31,
 			NumExtensions: 0,
 			NumServices:   2,
 		},
@@ -104,7 +104,9 @@ func (s *Server) updateAgentContainer(ctx context.Context, hostID, targetVersion
 	updateCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
 	defer cancel()
 
-	result, err := s.UpdateContainerSync(updateCtx, hostID, sentinelName, newImage, "")
+	// Bypass the agent version checks: updating is how an outdated agent
+	// gets back to a supported release.
+	result, err := s.updateContainerSync(updateCtx, hostID, sentinelName, newImage, "", nil, true)
 	if err != nil {
 		s.log.Error("auto-update: update failed",
 			"hostID", hostID,
@@ -0,0 +1,168 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/metrics"
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Command names used as the "command" metric label.
+const (
+	commandList   = "list"
+	commandUpdate = "update"
+	commandAction = "action"
+	commandLogs   = "logs"
+)
+
+// Command outcomes used as the "outcome" metric label.
+const (
+	outcomeOK        = "ok"
+	outcomeError     = "error"     // the agent responded with a failure, or the stream dropped
+	outcomeTimeout   = "timeout"   // no response before the caller's deadline
+	outcomeCancelled = "cancelled" // the caller gave up; says nothing about the agent
+	outcomeUnsent    = "unsent"    // the command never reached the agent's send buffer
+)
+
+// slowThresholds are the response times above which a command counts against
+// an agent. Updates pull images, so their duration says more about the image
+// than the agent: only update timeouts count.
+var slowThresholds = map[string]time.Duration{
+	commandList:   5 * time.Second,
+	commandLogs:   5 * time.Second,
+	commandAction: 30 * time.Second,
+}
+
+const (
+	// slowWindow is how many recent commands per host are considered.
+	slowWindow = 20
+	// slowMinSamples is how many commands a host needs before it is judged.
+	slowMinSamples = 5
+)
+
+// latencyWindow is a ring of recent slow/not-slow samples for one host.
+type latencyWindow struct {
+	samples [slowWindow]bool
+	n       int // samples held, up to slowWindow
+	next    int // ring index for the next sample
+	slow    bool
+}
+
+// add records a sample and returns how many held samples are slow.
+func (w *latencyWindow) add(slow bool) int {
+	w.samples[w.next] = slow
+	w.next = (w.next + 1) % slowWindow
+	if w.n < slowWindow {
+		w.n++
+	}
+	count := 0
+	for i := range w.n {
+		if w.samples[i] {
+			count++
+		}
+	}
+	return count
+}
+
+// commandOutcome classifies the error returned by a synchronous command.
+func commandOutcome(err error) string {
+	switch {
+	case err == nil:
+		return outcomeOK
+	case errors.Is(err, context.DeadlineExceeded):
+		return outcomeTimeout
+	case errors.Is(err, context.Canceled):
+		return outcomeCancelled
+	default:
+		return outcomeError
+	}
+}
+
+// commandUnsent counts a command that could not be queued for the agent.
+func commandUnsent(hostID, command string) {
+	metrics.ClusterCommandsTotal.WithLabelValues(hostID, command, outcomeUnsent).Inc()
+}
+
+// observeCommand records the outcome and latency of a command sent at
+// started, and updates the host's slow-agent flag.
+func (s *Server) observeCommand(hostID, command string, started time.Time, outcome string) {
+	metrics.ClusterCommandsTotal.WithLabelValues(hostID, command, outcome).Inc()
+	if outcome == outcomeCancelled {
+		return
+	}
+	elapsed := time.Since(started)
+	metrics.ClusterCommandDuration.WithLabelValues(hostID, command).Observe(elapsed.Seconds())
+
+	limit, judged := slowThresholds[command]
+	slow := outcome == outcomeTimeout || (judged && elapsed > limit)
+	if !judged && !slow {
+		return
+	}
+	s.recordLatency(hostID, slow)
+}
+
+// recordLatency adds a sample to the host's window. A host is flagged slow
+// once three quarters of its recent commands were slow, and cleared once no
+// more than a quarter were, so a borderline agent does not flap.
+func (s *Server) recordLatency(hostID string, slow bool) {
+	s.statsMu.Lock()
+	if s.cmdStats == nil {
+		s.cmdStats = make(map[string]*latencyWindow)
+	}
+	w, ok := s.cmdStats[hostID]
+	if !ok {
+		w = &latencyWindow{}
+		s.cmdStats[hostID] = w
+	}
+	count := w.add(slow)
+	n := w.n
+	changed := false
+	switch {
+	case !w.slow && n >= slowMinSamples && count*4 >= n*3:
+		w.slow, changed = true, true
+	case w.slow && count*4 <= n:
+		w.slow, changed = false, true
+	}
+	flagged := w.slow
+	s.statsMu.Unlock()
+
+	if !changed {
+		return
+	}
+	if flagged {
+		metrics.ClusterAgentSlow.WithLabelValues(hostID).Set(1)
+		msg := fmt.Sprintf("agent responding slowly: %d of the last %d commands were slow or timed out", count, n)
+		s.log.Warn("cluster agent flagged as slow", "hostID", hostID, "slow", count, "commands", n)
+		s.recordHostEvent(hostID, store.HostEventResult, "", msg, true)
+		return
+	}
+	metrics.ClusterAgentSlow.WithLabelValues(hostID).Set(0)
+	s.log.Info("cluster agent response times back to normal", "hostID", hostID)
+	s.recordHostEvent(hostID, store.HostEventResult, "", "agent response times back to normal", false)
+}
+
+// SlowAgent reports whether the host is flagged as consistently slow to
+// respond to commands.
+func (s *Server) SlowAgent(hostID string) bool {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	w, ok := s.cmdStats[hostID]
+	return ok && w.slow
+}
+
+// forgetCommandStats drops a removed host's latency window and metrics.
+func (s *Server) forgetCommandStats(hostID string) {
+	s.statsMu.Lock()
+	delete(s.cmdStats, hostID)
+	s.statsMu.Unlock()
+
+	labels := prometheus.Labels{"host": hostID}
+	metrics.ClusterCommandDuration.DeletePartialMatch(labels)
+	metrics.ClusterCommandsTotal.DeletePartialMatch(labels)
+	metrics.ClusterAgentSlow.DeletePartialMatch(labels)
+	metrics.ClusterAgentConnected.DeletePartialMatch(labels)
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/cluster/proto"
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+)
+
+type fakeHostEvents struct {
+	events []store.ClusterHostEvent
+}
+
+func (f *fakeHostEvents) AppendClusterHostEvent(e store.ClusterHostEvent) error {
+	f.events = append(f.events, e)
+	return nil
+}
+
+func (f *fakeHostEvents) DeleteClusterHostEvents(string) error { return nil }
+
+func TestCommandOutcome(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, outcomeOK},
+		{fmt.Errorf("await container list: %w", context.DeadlineExceeded), outcomeTimeout},
+		{fmt.Errorf("await container list: %w", context.Canceled), outcomeCancelled},
+		{errors.New("agent h1 disconnected"), outcomeError},
+	}
+	for _, tt := range tests {
+		if got := commandOutcome(tt.err); got != tt.want {
+			t.Errorf("commandOutcome(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestRecordLatency_FlagsConsistentlySlowAgent(t *testing.T) {
+	s := compatServer("2.6.0", nil)
+	ev := &fakeHostEvents{}
+	s.SetHostEventRecorder(ev)
+
+	for range slowMinSamples - 1 {
+		s.recordLatency("h1", true)
+	}
+	if s.SlowAgent("h1") {
+		t.Fatal("flagged before enough commands were seen")
+	}
+	s.recordLatency("h1", true)
+	if !s.SlowAgent("h1") {
+		t.Fatal("not flagged after consistently slow commands")
+	}
+	if len(ev.events) != 1 || !ev.events[0].Failed {
+		t.Errorf("events = %+v, want one failed event", ev.events)
+	}
+
+	// The flag holds until no more than a quarter of the window is slow.
+	for range slowWindow - slowMinSamples - 1 {
+		s.recordLatency("h1", false)
+	}
+	if !s.SlowAgent("h1") {
+		t.Fatal("cleared while a quarter of the window was still slow")
+	}
+	s.recordLatency("h1", false)
+	if s.SlowAgent("h1") {
+		t.Fatal("still flagged after response times recovered")
+	}
+	if len(ev.events) != 2 || ev.events[1].Failed {
+		t.Errorf("events = %+v, want a recovery event", ev.events)
+	}
+
+	s.forgetCommandStats("h1")
+	if s.SlowAgent("h1") {
+		t.Error("stats kept after the host was forgotten")
+	}
+}
+
+func TestObserveCommand_UpdateDurationNotJudged(t *testing.T) {
+	s := compatServer("2.6.0", nil)
+	long := time.Now().Add(-time.Hour)
+	for range slowWindow {
+		s.observeCommand("h1", commandUpdate, long, outcomeOK)
+	}
+	if s.SlowAgent("h1") {
+		t.Error("long image pulls flagged the agent as slow")
+	}
+	for range slowMinSamples {
+		s.observeCommand("h1", commandUpdate, long, outcomeTimeout)
+	}
+	if !s.SlowAgent("h1") {
+		t.Error("update timeouts did not flag the agent")
+	}
+	s.forgetCommandStats("h1")
+}
+
+func TestListContainersSync_TimeoutsFlagSlowAgent(t *testing.T) {
+	s := compatServer("2.6.0", []string{FeatureList})
+	s.streams["h1"].send = make(chan *proto.ServerMessage, slowMinSamples)
+
+	for range slowMinSamples {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+		_, err := s.ListContainersSync(ctx, "h1")
+		cancel()
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("err = %v, want DeadlineExceeded", err)
+		}
+	}
+	if !s.SlowAgent("h1") {
+		t.Error("repeated timeouts did not flag the agent")
+	}
+	s.forgetCommandStats("h1")
+}
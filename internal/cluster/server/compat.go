@@ -0,0 +1,86 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/registry"
+)
+
+// Features agents advertise in their heartbeats. A command is only sent to
+// an agent that advertises the feature it needs.
+const (
+	FeatureUpdate = "update"
+	FeatureHooks  = "hooks"
+	FeatureList   = "list"
+	FeatureLogs   = "logs"
+)
+
+// ErrAgentOutdated is returned for commands an agent is too old to run.
+var ErrAgentOutdated = errors.New("agent outdated")
+
+// AgentPolicy decides how the server treats agents older than the minimum
+// supported release. Implemented by the caller from persisted settings so
+// changes apply without a restart.
+type AgentPolicy interface {
+	// MinAgentVersion returns the oldest supported agent release.
+	MinAgentVersion() string
+	// RefuseOutdatedAgents reports whether outdated agents get no commands
+	// at all, rather than only those whose features they lack.
+	RefuseOutdatedAgents() bool
+}
+
+// SetAgentPolicy sets the minimum agent version policy. Without one, only
+// feature negotiation applies.
+func (s *Server) SetAgentPolicy(p AgentPolicy) {
+	s.policy = p
+}
+
+// AgentOutdated reports whether an agent version is older than the policy
+// minimum. Dev builds and unreported versions are never outdated.
+func (s *Server) AgentOutdated(version string) bool {
+	if s.policy == nil {
+		return false
+	}
+	return versionOlder(version, s.policy.MinAgentVersion())
+}
+
+// versionOlder reports whether version is older than minimum. Either being
+// unparseable (e.g. "dev") counts as not older.
+func versionOlder(version, minimum string) bool {
+	v, ok := registry.ParseSemVer(baseVersion(version))
+	if !ok {
+		return false
+	}
+	m, ok := registry.ParseSemVer(minimum)
+	if !ok {
+		return false
+	}
+	return v.LessThan(m)
+}
+
+// checkAgent returns an ErrAgentOutdated error when a connected agent cannot
+// run a command needing feature ("" for commands every agent supports).
+// Agents that have not sent a heartbeat yet are given the benefit of the
+// doubt; disconnected agents are left for SendCommand to report.
+func (s *Server) checkAgent(hostID, feature string) error {
+	s.mu.RLock()
+	as, ok := s.streams[hostID]
+	s.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	as.mu.RLock()
+	version, features := as.version, as.features
+	as.mu.RUnlock()
+
+	if s.policy != nil && s.policy.RefuseOutdatedAgents() && s.AgentOutdated(version) {
+		return fmt.Errorf("%w: %s runs %s, older than the minimum %s", ErrAgentOutdated, hostID, version, s.policy.MinAgentVersion())
+	}
+	if feature != "" && features != nil && !slices.Contains(features, feature) {
+		return fmt.Errorf("%w: %s (version %s) does not support %s", ErrAgentOutdated, hostID, version, feature)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/cluster/proto"
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+)
+
+type fakeAgentPolicy struct {
+	min    string
+	refuse bool
+}
+
+func (f *fakeAgentPolicy) MinAgentVersion() string    { return f.min }
+func (f *fakeAgentPolicy) RefuseOutdatedAgents() bool { return f.refuse }
+
+// compatServer has one connected agent, h1, running version with features.
+func compatServer(version string, features []string) *Server {
+	as := &agentStream{hostID: "h1", send: make(chan *proto.ServerMessage, 1), version: version, features: features}
+	return &Server{
+		log:     slog.Default(),
+		streams: map[string]*agentStream{"h1": as},
+		pending: make(map[string]chan *proto.AgentMessage),
+	}
+}
+
+func TestVersionOlder(t *testing.T) {
+	tests := []struct {
+		version, minimum string
+		want             bool
+	}{
+		{"2.4.9", "2.5.0", true},
+		{"v2.4.0 (abc1234)", "2.5.0", true},
+		{"2.5.0", "2.5.0", false},
+		{"2.10.0", "2.5.0", false},
+		{"dev", "2.5.0", false},
+		{"", "2.5.0", false},
+		{"2.0.0", "", false},
+	}
+	for _, tt := range tests {
+		if got := versionOlder(tt.version, tt.minimum); got != tt.want {
+			t.Errorf("versionOlder(%q, %q) = %v, want %v", tt.version, tt.minimum, got, tt.want)
+		}
+	}
+}
+
+func TestCheckAgent(t *testing.T) {
+	all := []string{FeatureUpdate, FeatureHooks, FeatureList, FeatureLogs}
+
+	// Feature negotiation applies without a policy.
+	s := compatServer("2.3.0", []string{FeatureUpdate, FeatureList})
+	if err := s.checkAgent("h1", FeatureUpdate); err != nil {
+		t.Errorf("advertised feature: %v", err)
+	}
+	if err := s.checkAgent("h1", FeatureLogs); !errors.Is(err, ErrAgentOutdated) {
+		t.Errorf("missing feature: err = %v, want ErrAgentOutdated", err)
+	}
+	if err := s.checkAgent("unknown", FeatureLogs); err != nil {
+		t.Errorf("disconnected host: %v, want nil for SendCommand to report", err)
+	}
+
+	// Before the first heartbeat nothing is known, so nothing is refused.
+	s = compatServer("", nil)
+	s.SetAgentPolicy(&fakeAgentPolicy{min: "2.5.0", refuse: true})
+	if err := s.checkAgent("h1", FeatureLogs); err != nil {
+		t.Errorf("no heartbeat yet: %v", err)
+	}
+
+	// Degrade keeps sending supported commands to an outdated agent.
+	policy := &fakeAgentPolicy{min: "2.5.0"}
+	s = compatServer("2.3.0", all)
+	s.SetAgentPolicy(policy)
+	if !s.AgentOutdated("2.3.0") {
+		t.Error("2.3.0 should be outdated against 2.5.0")
+	}
+	if err := s.checkAgent("h1", FeatureLogs); err != nil {
+		t.Errorf("degrade: %v", err)
+	}
+
+	// Refuse blocks everything, including feature-less commands.
+	policy.refuse = true
+	for _, feature := range []string{FeatureLogs, ""} {
+		if err := s.checkAgent("h1", feature); !errors.Is(err, ErrAgentOutdated) {
+			t.Errorf("refuse %q: err = %v, want ErrAgentOutdated", feature, err)
+		}
+	}
+}
+
+func TestUpdateContainerSync_NeedsHookSupport(t *testing.T) {
+	s := compatServer("2.3.0", []string{FeatureUpdate})
+	s.SetHookSource(&fakeHookSource{enabled: true, hooks: map[string][]store.HookEntry{
+		"h1::db": {{Phase: "pre-update", Command: []string{"pg_dump"}}},
+	}})
+
+	_, err := s.UpdateContainerSync(context.Background(), "h1", "db", "postgres:17", "")
+	if !errors.Is(err, ErrAgentOutdated) {
+		t.Fatalf("err = %v, want ErrAgentOutdated for an agent without hooks", err)
+	}
+	if len(s.streams["h1"].send) != 0 {
+		t.Error("the update was sent anyway")
+	}
+}
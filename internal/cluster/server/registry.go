@@ -262,6 +262,34 @@ func (r *Registry) UpdateEngineID(hostID, engineID string) error {
 	return nil
 }
 
+// UpdateAgentVersion stores the agent version reported in a heartbeat and
+// returns the previous one. changed is false when the version is the same,
+// in which case nothing is written.
+func (r *Registry) UpdateAgentVersion(hostID, version string) (prev string, changed bool, err error) {
+	r.mu.Lock()
+	hs, ok := r.hosts[hostID]
+	if !ok {
+		r.mu.Unlock()
+		return "", false, fmt.Errorf("host %s not found", hostID)
+	}
+	prev = hs.Info.AgentVersion
+	if prev == version {
+		r.mu.Unlock()
+		return prev, false, nil
+	}
+	hs.Info.AgentVersion = version
+	data, err := json.Marshal(hs.Info)
+	r.mu.Unlock()
+
+	if err != nil {
+		return prev, true, fmt.Errorf("marshal host info: %w", err)
+	}
+	if err := r.store.SaveClusterHost(hostID, data); err != nil {
+		return prev, true, fmt.Errorf("persist agent version: %w", err)
+	}
+	return prev, true, nil
+}
+
 // UpdateContainers replaces the known container list for a host.
 // Only stored in memory -- the server doesn't persist remote container lists
 // because the agent will re-report them on reconnection.
@@ -27,6 +27,20 @@ type HistoryRecorder interface {
 	RecordUpdate(rec store.UpdateRecord) error
 }
 
+// HostEventRecorder persists per-host activity timelines (connections,
+// commands, results, cert renewals and version changes).
+type HostEventRecorder interface {
+	AppendClusterHostEvent(e store.ClusterHostEvent) error
+	DeleteClusterHostEvents(hostID string) error
+}
+
+// HookSource supplies the lifecycle hooks sent with remote updates. Hooks
+// for remote containers are stored under store.ScopedKey(hostID, name).
+type HookSource interface {
+	HooksEnabled() bool
+	ListHooks(containerName string) ([]store.HookEntry, error)
+}
+
 // ClusterStore is the subset of store.Store needed by the cluster server.
 // Defined as an interface for dependency injection -- avoids importing the
 // store package directly, matching the pattern used by the web package.
@@ -37,6 +51,7 @@ type ClusterStore interface {
 	DeleteClusterHost(id string) error
 	SaveEnrollToken(id string, data []byte) error
 	GetEnrollToken(id string) ([]byte, error)
+	ListEnrollTokens() (map[string][]byte, error)
 	DeleteEnrollToken(id string) error
 	AddRevokedCert(serial string) error
 	IsRevokedCert(serial string) (bool, error)
@@ -59,6 +74,9 @@ type Server struct {
 	registry *Registry
 	store    ClusterStore
 	history  HistoryRecorder
+	events   HostEventRecorder
+	hooks    HookSource
+	policy   AgentPolicy
 	bus      *events.Bus
 	log      *slog.Logger
 	hmacKey  []byte // 32-byte random key for HMAC-SHA256 token signing
@@ -75,9 +93,18 @@ type Server struct {
 	// per host are supported.
 	pending map[string]chan *proto.AgentMessage
 
+	// statsMu protects cmdStats, the recent command latencies per host
+	// used to flag consistently slow agents.
+	statsMu  sync.Mutex
+	cmdStats map[string]*latencyWindow
+
 	// onEngineID is called when an agent reports its Docker Engine ID.
 	// Set by the web layer to trigger Portainer endpoint overlap checks.
 	onEngineID func(hostID, hostName, engineID string)
+
+	// onTokenUsed is called when an agent consumes an enrollment token.
+	// Set by the caller to record the consumption in the activity log.
+	onTokenUsed func(tok cluster.EnrollToken)
 }
 
 // SetOnEngineID registers a callback that fires when an agent reports
@@ -86,6 +113,12 @@ func (s *Server) SetOnEngineID(fn func(hostID, hostName, engineID string)) {
 	s.onEngineID = fn
 }
 
+// SetOnTokenUsed registers a callback that fires when an agent consumes an
+// enrollment token. The token passed to fn has its hash cleared.
+func (s *Server) SetOnTokenUsed(fn func(tok cluster.EnrollToken)) {
+	s.onTokenUsed = fn
+}
+
 // agentStream tracks an active bidirectional stream with an agent.
 // One per connected agent; removed on disconnect.
 type agentStream struct {
@@ -120,6 +153,7 @@ func New(ca *cluster.CA, store ClusterStore, bus *events.Bus, log *slog.Logger)
 		log:      log.With("component", "cluster-server"),
 		streams:  make(map[string]*agentStream),
 		pending:  make(map[string]chan *proto.AgentMessage),
+		cmdStats: make(map[string]*latencyWindow),
 	}, nil
 }
 
@@ -161,6 +195,38 @@ func (s *Server) SetHistoryRecorder(h HistoryRecorder) {
 	s.history = h
 }
 
+// SetHostEventRecorder wires in the store for per-host event timelines.
+// Read-only requests (container lists, logs) are not recorded. Without a
+// recorder no timeline is kept. Called after construction by main.go.
+func (s *Server) SetHostEventRecorder(r HostEventRecorder) {
+	s.events = r
+}
+
+// recordHostEvent appends an event to a host's timeline. Failures are
+// logged and otherwise ignored: the timeline is a diagnostic aid and must
+// not break the operation being recorded.
+func (s *Server) recordHostEvent(hostID, eventType, container, message string, failed bool) {
+	if s.events == nil {
+		return
+	}
+	if err := s.events.AppendClusterHostEvent(store.ClusterHostEvent{
+		HostID:    hostID,
+		Type:      eventType,
+		Container: container,
+		Message:   message,
+		Failed:    failed,
+	}); err != nil {
+		s.log.Warn("failed to record host event", "hostID", hostID, "type", eventType, "error", err)
+	}
+}
+
+// SetHookSource wires in the hook configuration sent with each
+// UpdateContainerRequest. Without one, agents skip hooks. Called after
+// construction by main.go.
+func (s *Server) SetHookSource(h HookSource) {
+	s.hooks = h
+}
+
 // Start starts the gRPC server with mTLS on the given address.
 // extraSANs are additional IPs or hostnames to include in the server
 // certificate (e.g. the Docker host's external IP that agents connect to).
@@ -295,7 +361,12 @@ func (s *Server) GetHost(id string) (*HostState, bool) {
 // RemoveHost deletes a host from the registry and disconnects its stream.
 func (s *Server) RemoveHost(id string) error {
 	s.disconnectAgent(id)
-	return s.registry.Remove(id)
+	if err := s.registry.Remove(id); err != nil {
+		return err
+	}
+	s.deleteHostEvents(id)
+	s.forgetCommandStats(id)
+	return nil
 }
 
 // PauseHost sets a host to "paused" state -- no new updates will be dispatched.
@@ -316,7 +387,22 @@ func (s *Server) RevokeHost(id string) error {
 	}
 
 	s.disconnectAgent(id)
-	return s.registry.Remove(id)
+	if err := s.registry.Remove(id); err != nil {
+		return err
+	}
+	s.deleteHostEvents(id)
+	s.forgetCommandStats(id)
+	return nil
+}
+
+// deleteHostEvents drops the timeline of a host that has left the cluster.
+func (s *Server) deleteHostEvents(id string) {
+	if s.events == nil {
+		return
+	}
+	if err := s.events.DeleteClusterHostEvents(id); err != nil {
+		s.log.Warn("failed to delete host events", "hostID", id, "error", err)
+	}
 }
 
 // disconnectAgent cancels the active stream for a host, if one exists.
@@ -5,16 +5,20 @@ import (
 	"crypto/hmac"
 	"crypto/rand"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/Will-Luck/Docker-Sentinel/internal/cluster"
 	"github.com/Will-Luck/Docker-Sentinel/internal/cluster/proto"
 	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+	"github.com/Will-Luck/Docker-Sentinel/internal/metrics"
 	"github.com/Will-Luck/Docker-Sentinel/internal/store"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -26,11 +30,17 @@ import (
 // EnrollmentService
 // ---------------------------------------------------------------------------
 
+// Enrollment token revocation errors.
+var (
+	ErrEnrollTokenNotFound = errors.New("enrollment token not found")
+	ErrEnrollTokenUsed     = errors.New("enrollment token already used")
+)
+
 // Enroll handles a new agent registration using a one-time token.
 //
 // Flow:
 // 1. Validate the enrollment token (HMAC comparison)
-// 2. Mark the token as used
+// 2. Mark the token as used, recording the consuming host
 // 3. Sign the agent's CSR with our CA
 // 4. Persist the new host record
 // 5. Return the host ID, CA cert, and signed agent cert
@@ -68,17 +78,25 @@ func (s *Server) Enroll(ctx context.Context, req *proto.EnrollRequest) (*proto.E
 		return nil, status.Error(codes.PermissionDenied, "invalid enrollment token")
 	}
 
+	// Generate a unique host ID.
+	hostID, err := generateHostID()
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to generate host ID")
+	}
+
 	// Mark token as used before issuing certs (prevent replay on error).
 	tok.Used = true
+	tok.UsedAt = time.Now()
+	tok.UsedByID = hostID
+	tok.UsedByName = req.HostName
 	if err := s.saveEnrollToken(tok); err != nil {
 		s.log.Error("failed to mark token used", "tokenID", tokenID, "error", err)
 		return nil, status.Error(codes.Internal, "failed to consume token")
 	}
-
-	// Generate a unique host ID.
-	hostID, err := generateHostID()
-	if err != nil {
-		return nil, status.Error(codes.Internal, "failed to generate host ID")
+	if s.onTokenUsed != nil {
+		used := *tok
+		used.Hash = nil
+		s.onTokenUsed(used)
 	}
 
 	// Sign the CSR. The CN is set to hostID by the CA (overrides whatever
@@ -113,6 +131,7 @@ func (s *Server) Enroll(ctx context.Context, req *proto.EnrollRequest) (*proto.E
 	})
 
 	s.log.Info("agent enrolled", "hostID", hostID, "name", req.HostName, "serial", serial)
+	s.recordHostEvent(hostID, store.HostEventEnrolled, "", fmt.Sprintf("enrolled as %s with token %s", req.HostName, tokenID), false)
 
 	return &proto.EnrollResponse{
 		HostId:    hostID,
@@ -121,10 +140,11 @@ func (s *Server) Enroll(ctx context.Context, req *proto.EnrollRequest) (*proto.E
 	}, nil
 }
 
-// GenerateEnrollToken creates a one-time enrollment token.
+// GenerateEnrollToken creates a one-time enrollment token that expires after
+// expiry. createdBy names the user who asked for it and may be empty.
 // The plaintext token is returned to the caller (shown to admin once); only
 // the HMAC hash is persisted. Token ID is the first 8 hex chars for lookup.
-func (s *Server) GenerateEnrollToken(expiry time.Duration) (token string, id string, err error) {
+func (s *Server) GenerateEnrollToken(expiry time.Duration, createdBy string) (token string, id string, err error) {
 	// 32 random bytes = 64 hex chars. Plenty of entropy.
 	raw := make([]byte, 32)
 	if _, err := rand.Read(raw); err != nil {
@@ -141,6 +161,7 @@ func (s *Server) GenerateEnrollToken(expiry time.Duration) (token string, id str
 		CreatedAt: now,
 		ExpiresAt: now.Add(expiry),
 		Used:      false,
+		CreatedBy: createdBy,
 	}
 
 	if err := s.saveEnrollToken(tok); err != nil {
@@ -151,6 +172,54 @@ func (s *Server) GenerateEnrollToken(expiry time.Duration) (token string, id str
 	return token, id, nil
 }
 
+// ListEnrollTokens returns all stored enrollment tokens, newest first, with
+// their hashes cleared.
+func (s *Server) ListEnrollTokens() ([]cluster.EnrollToken, error) {
+	all, err := s.store.ListEnrollTokens()
+	if err != nil {
+		return nil, fmt.Errorf("list tokens: %w", err)
+	}
+	tokens := make([]cluster.EnrollToken, 0, len(all))
+	for id, data := range all {
+		var tok cluster.EnrollToken
+		if err := json.Unmarshal(data, &tok); err != nil {
+			s.log.Warn("skipping unreadable enrollment token", "id", id, "error", err)
+			continue
+		}
+		tok.Hash = nil
+		tokens = append(tokens, tok)
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].CreatedAt.After(tokens[j].CreatedAt)
+	})
+	return tokens, nil
+}
+
+// RevokeEnrollToken deletes an unused enrollment token so it can no longer
+// enroll an agent. Used tokens are kept as an audit record and cannot be
+// revoked; revoke the host they enrolled instead.
+func (s *Server) RevokeEnrollToken(id string) error {
+	data, err := s.store.GetEnrollToken(id)
+	if err != nil {
+		return fmt.Errorf("load token: %w", err)
+	}
+	if data == nil {
+		return ErrEnrollTokenNotFound
+	}
+	var tok cluster.EnrollToken
+	if err := json.Unmarshal(data, &tok); err != nil {
+		return fmt.Errorf("unmarshal token: %w", err)
+	}
+	if tok.Used {
+		return ErrEnrollTokenUsed
+	}
+	if err := s.store.DeleteEnrollToken(id); err != nil {
+		return fmt.Errorf("delete token: %w", err)
+	}
+	s.log.Info("enrollment token revoked", "id", id)
+	return nil
+}
+
 // ---------------------------------------------------------------------------
 // AgentService
 // ---------------------------------------------------------------------------
@@ -183,9 +252,11 @@ func (s *Server) Channel(stream grpc.BidiStreamingServer[proto.AgentMessage, pro
 
 	// Capture the agent's IP from the gRPC peer address so the dashboard
 	// can link port chips to the correct host.
+	var peerIP string
 	if p, ok := peer.FromContext(stream.Context()); ok {
 		if tcpAddr, ok := p.Addr.(*net.TCPAddr); ok {
-			s.registry.UpdateAddress(hostID, tcpAddr.IP.String())
+			peerIP = tcpAddr.IP.String()
+			s.registry.UpdateAddress(hostID, peerIP)
 		}
 	}
 
@@ -211,8 +282,14 @@ func (s *Server) Channel(stream grpc.BidiStreamingServer[proto.AgentMessage, pro
 	s.mu.Unlock()
 
 	s.registry.SetConnected(hostID, true)
+	metrics.ClusterAgentConnected.WithLabelValues(hostID).Set(1)
 
 	s.log.Info("agent connected", "hostID", hostID)
+	connMsg := "agent connected"
+	if peerIP != "" {
+		connMsg += " from " + peerIP
+	}
+	s.recordHostEvent(hostID, store.HostEventConnected, "", connMsg, false)
 	s.bus.Publish(events.SSEEvent{
 		Type:      events.EventClusterHost,
 		HostName:  hostID,
@@ -224,10 +301,15 @@ func (s *Server) Channel(stream grpc.BidiStreamingServer[proto.AgentMessage, pro
 	defer func() {
 		s.mu.Lock()
 		// Only remove if it's still our stream (not replaced by a newer one).
+		current := false
 		if cur, ok := s.streams[hostID]; ok && cur == as {
 			delete(s.streams, hostID)
+			current = true
 		}
 		s.mu.Unlock()
+		if current {
+			metrics.ClusterAgentConnected.WithLabelValues(hostID).Set(0)
+		}
 
 		s.pendingMu.Lock()
 		prefix := hostID + ":"
@@ -245,8 +327,12 @@ func (s *Server) Channel(stream grpc.BidiStreamingServer[proto.AgentMessage, pro
 			s.log.Warn("failed to persist last seen on disconnect", "hostID", hostID, "error", err)
 		}
 
-		cat, _ := classifyDisconnect(streamErr)
+		cat, reason := classifyDisconnect(streamErr)
 		s.log.Info("agent disconnected", "hostID", hostID, "category", cat, "error", streamErr)
+		// A removed or revoked host has already had its timeline deleted.
+		if _, ok := s.registry.Get(hostID); ok {
+			s.recordHostEvent(hostID, store.HostEventDisconnected, "", "agent disconnected: "+reason, cat != "server")
+		}
 		s.bus.Publish(events.SSEEvent{
 			Type:      events.EventClusterHost,
 			HostName:  hostID,
@@ -384,13 +470,41 @@ func (s *Server) handleAgentMessage(hostID string, as *agentStream, msg *proto.A
 func (s *Server) handleHeartbeat(hostID string, as *agentStream, hb *proto.Heartbeat) {
 	// Cache the agent's version and features for the lifetime of this stream.
 	as.mu.Lock()
+	first := as.version == "" && hb.AgentVersion != ""
 	as.version = hb.AgentVersion
 	as.features = hb.SupportedFeatures
 	as.mu.Unlock()
 
+	// Warn once per connection rather than on every heartbeat.
+	if first && s.AgentOutdated(hb.AgentVersion) {
+		s.log.Warn("agent outdated",
+			"hostID", hostID,
+			"agent_version", hb.AgentVersion,
+			"min_version", s.policy.MinAgentVersion(),
+			"refused", s.policy.RefuseOutdatedAgents(),
+		)
+		s.recordHostEvent(hostID, store.HostEventVersion, "",
+			fmt.Sprintf("agent version %s is older than the minimum supported %s", hb.AgentVersion, s.policy.MinAgentVersion()), true)
+	}
+
 	if err := s.registry.UpdateLastSeen(hostID, time.Now()); err != nil {
 		s.log.Warn("failed to update last seen on heartbeat", "hostID", hostID, "error", err)
 	}
+
+	if hb.AgentVersion == "" {
+		return
+	}
+	prev, changed, err := s.registry.UpdateAgentVersion(hostID, hb.AgentVersion)
+	if err != nil {
+		s.log.Warn("failed to store agent version", "hostID", hostID, "error", err)
+	}
+	if changed {
+		msg := "agent version " + hb.AgentVersion
+		if prev != "" {
+			msg = fmt.Sprintf("agent version changed from %s to %s", prev, hb.AgentVersion)
+		}
+		s.recordHostEvent(hostID, store.HostEventVersion, "", msg, false)
+	}
 }
 
 func (s *Server) handleContainerList(hostID string, msg *proto.AgentMessage, cl *proto.ContainerList) {
@@ -422,6 +536,8 @@ func (s *Server) handleUpdateResult(hostID string, msg *proto.AgentMessage, ur *
 	if ur.Outcome != "success" && ur.Error != "" {
 		sseMsg = fmt.Sprintf("update %s failed: %s", ur.ContainerName, ur.Error)
 	}
+	s.recordHostEvent(hostID, store.HostEventResult, ur.ContainerName, sseMsg,
+		ur.Outcome != "success" && ur.Outcome != "skipped")
 	s.bus.Publish(events.SSEEvent{
 		Type:          events.EventContainerUpdate,
 		ContainerName: ur.ContainerName,
@@ -449,17 +565,30 @@ func (s *Server) handleContainerActionResult(hostID string, msg *proto.AgentMess
 	// the action immediately, without waiting for a full container list refresh.
 	if ar.Outcome == "success" {
 		var newState string
-		switch ar.Action {
+		switch verb, signal, _ := strings.Cut(ar.Action, ":"); verb {
 		case "stop":
 			newState = "exited"
-		case "start", "restart":
+		case "start", "restart", "unpause":
 			newState = "running"
+		case "pause":
+			newState = "paused"
+		case "kill":
+			// Other signals may be handled without the container exiting.
+			if signal == "" || signal == "SIGKILL" {
+				newState = "exited"
+			}
 		}
 		if newState != "" {
 			s.registry.UpdateContainerState(hostID, ar.ContainerName, newState)
 		}
 	}
 
+	actionMsg := fmt.Sprintf("%s %s: %s", ar.Action, ar.ContainerName, ar.Outcome)
+	if ar.Outcome != "success" && ar.Error != "" {
+		actionMsg += " (" + ar.Error + ")"
+	}
+	s.recordHostEvent(hostID, store.HostEventResult, ar.ContainerName, actionMsg, ar.Outcome != "success")
+
 	s.bus.Publish(events.SSEEvent{
 		Type:          events.EventContainerState,
 		ContainerName: ar.ContainerName,
@@ -502,6 +631,8 @@ func (s *Server) handleRollbackResult(hostID string, rr *proto.RollbackResult) {
 		"outcome", rr.Outcome,
 		"requestID", rr.RequestId,
 	)
+	s.recordHostEvent(hostID, store.HostEventResult, rr.ContainerName,
+		fmt.Sprintf("rollback %s: %s", rr.ContainerName, rr.Outcome), rr.Outcome != "success")
 
 	s.bus.Publish(events.SSEEvent{
 		Type:          events.EventContainerUpdate,
@@ -581,6 +712,8 @@ func (s *Server) handleOfflineJournal(hostID string, oj *proto.OfflineJournal) {
 		"hostID", hostID,
 		"count", len(oj.Entries),
 	)
+	s.recordHostEvent(hostID, store.HostEventResult, "",
+		fmt.Sprintf("replayed %d offline journal entries", len(oj.Entries)), false)
 }
 
 // handleCertRenewal processes a certificate renewal CSR from an agent whose
@@ -592,6 +725,7 @@ func (s *Server) handleCertRenewal(hostID string, as *agentStream, csr *proto.Ce
 	certPEM, serial, err := s.ca.SignCSR(csr.Csr, hostID)
 	if err != nil {
 		s.log.Error("cert renewal failed", "hostID", hostID, "error", err)
+		s.recordHostEvent(hostID, store.HostEventCertRenewal, "", "certificate renewal failed: "+err.Error(), true)
 		return
 	}
 
@@ -601,6 +735,7 @@ func (s *Server) handleCertRenewal(hostID string, as *agentStream, csr *proto.Ce
 		if rErr := s.store.AddRevokedCert(serial); rErr != nil {
 			s.log.Error("cert renewal: failed to revoke orphaned cert", "hostID", hostID, "serial", serial, "error", rErr)
 		}
+		s.recordHostEvent(hostID, store.HostEventCertRenewal, "", "certificate renewal failed: "+err.Error(), true)
 		return
 	}
 
@@ -616,8 +751,10 @@ func (s *Server) handleCertRenewal(hostID string, as *agentStream, csr *proto.Ce
 	case as.send <- msg:
 	default:
 		s.log.Error("cert renewal: send buffer full", "hostID", hostID)
+		s.recordHostEvent(hostID, store.HostEventCertRenewal, "", "certificate renewal failed: send buffer full", true)
 		return
 	}
 
 	s.log.Info("cert renewed", "hostID", hostID, "newSerial", serial)
+	s.recordHostEvent(hostID, store.HostEventCertRenewal, "", "certificate renewed, new serial "+serial, false)
 }
@@ -9,9 +9,11 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/Will-Luck/Docker-Sentinel/internal/cluster"
 	"github.com/Will-Luck/Docker-Sentinel/internal/cluster/proto"
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/peer"
 )
@@ -88,7 +90,10 @@ func (s *Server) awaitPending(ctx context.Context, hostID, requestID string, ch
 // ListContainersSync sends a ListContainersRequest to the agent and blocks
 // until the agent responds with a ContainerList or the context is cancelled.
 // The request_id on the ServerMessage is used for correlation.
-func (s *Server) ListContainersSync(ctx context.Context, hostID string) ([]cluster.ContainerInfo, error) {
+func (s *Server) ListContainersSync(ctx context.Context, hostID string) (_ []cluster.ContainerInfo, err error) {
+	if err := s.checkAgent(hostID, FeatureList); err != nil {
+		return nil, err
+	}
 	reqID := generateRequestID()
 
 	// Register the response channel BEFORE sending, so a fast agent
@@ -107,8 +112,11 @@ func (s *Server) ListContainersSync(ctx context.Context, hostID string) ([]clust
 
 	if err := s.SendCommand(hostID, msg); err != nil {
 		s.cancelPending(hostID, reqID, ch)
+		commandUnsent(hostID, commandList)
 		return nil, fmt.Errorf("send list containers: %w", err)
 	}
+	started := time.Now()
+	defer func() { s.observeCommand(hostID, commandList, started, commandOutcome(err)) }()
 
 	resp, err := s.awaitPending(ctx, hostID, reqID, ch)
 	if err != nil {
@@ -125,7 +133,24 @@ func (s *Server) ListContainersSync(ctx context.Context, hostID string) ([]clust
 
 // UpdateContainerSync sends an UpdateContainerRequest to the agent and blocks
 // until the agent responds with an UpdateResult or the context is cancelled.
+// The container's configured hooks travel with the request and run on the
+// agent host; an agent without hook support is not sent the update.
 func (s *Server) UpdateContainerSync(ctx context.Context, hostID, containerName, targetImage, targetDigest string) (*proto.UpdateResult, error) {
+	hooks, skipHooks := s.updateHooks(hostID, containerName)
+	err := s.checkAgent(hostID, FeatureUpdate)
+	if err == nil && len(hooks) > 0 {
+		err = s.checkAgent(hostID, FeatureHooks)
+	}
+	if err != nil {
+		s.recordHostEvent(hostID, store.HostEventCommand, containerName, fmt.Sprintf("update %s to %s not sent: %v", containerName, targetImage, err), true)
+		return nil, err
+	}
+	return s.updateContainerSync(ctx, hostID, containerName, targetImage, targetDigest, hooks, skipHooks)
+}
+
+// updateContainerSync is UpdateContainerSync without the agent version
+// checks, so an outdated agent can still be updated to a supported release.
+func (s *Server) updateContainerSync(ctx context.Context, hostID, containerName, targetImage, targetDigest string, hooks []*proto.HookDefinition, skipHooks bool) (result *proto.UpdateResult, err error) {
 	reqID := generateRequestID()
 
 	// Register the response channel BEFORE sending, so a fast agent
@@ -142,17 +167,32 @@ func (s *Server) UpdateContainerSync(ctx context.Context, hostID, containerName,
 				ContainerName: containerName,
 				TargetImage:   targetImage,
 				TargetDigest:  targetDigest,
+				SkipHooks:     skipHooks,
+				Hooks:         hooks,
 			},
 		},
 	}
 
+	cmd := fmt.Sprintf("update %s to %s", containerName, targetImage)
 	if err := s.SendCommand(hostID, msg); err != nil {
 		s.cancelPending(hostID, reqID, ch)
+		s.recordHostEvent(hostID, store.HostEventCommand, containerName, cmd+" not sent: "+err.Error(), true)
+		commandUnsent(hostID, commandUpdate)
 		return nil, fmt.Errorf("send update container: %w", err)
 	}
+	s.recordHostEvent(hostID, store.HostEventCommand, containerName, cmd, false)
+	started := time.Now()
+	defer func() {
+		outcome := commandOutcome(err)
+		if err == nil && result.Outcome != "success" {
+			outcome = outcomeError
+		}
+		s.observeCommand(hostID, commandUpdate, started, outcome)
+	}()
 
 	resp, err := s.awaitPending(ctx, hostID, reqID, ch)
 	if err != nil {
+		s.recordHostEvent(hostID, store.HostEventResult, containerName, "no update result: "+err.Error(), true)
 		return nil, fmt.Errorf("await update result: %w", err)
 	}
 
@@ -164,9 +204,35 @@ func (s *Server) UpdateContainerSync(ctx context.Context, hostID, containerName,
 	return ur.UpdateResult, nil
 }
 
+// updateHooks returns the hooks to send with an update, and whether the agent
+// should skip hooks altogether (no hook source, or hooks disabled).
+func (s *Server) updateHooks(hostID, containerName string) ([]*proto.HookDefinition, bool) {
+	if s.hooks == nil || !s.hooks.HooksEnabled() {
+		return nil, true
+	}
+	entries, err := s.hooks.ListHooks(store.ScopedKey(hostID, containerName))
+	if err != nil {
+		s.log.Warn("failed to load hooks for remote update", "hostID", hostID, "container", containerName, "error", err)
+		return nil, false
+	}
+	defs := make([]*proto.HookDefinition, 0, len(entries))
+	for _, e := range entries {
+		defs = append(defs, &proto.HookDefinition{
+			Phase:          e.Phase,
+			Command:        e.Command,
+			TimeoutSeconds: int32(e.Timeout), //nolint:gosec // hook timeouts are small second counts
+		})
+	}
+	return defs, false
+}
+
 // ContainerActionSync sends a ContainerActionRequest to the agent and blocks
 // until the agent responds with a ContainerActionResult or the context is cancelled.
-func (s *Server) ContainerActionSync(ctx context.Context, hostID, containerName, action string) error {
+func (s *Server) ContainerActionSync(ctx context.Context, hostID, containerName, action string) (err error) {
+	if err := s.checkAgent(hostID, ""); err != nil {
+		s.recordHostEvent(hostID, store.HostEventCommand, containerName, action+" "+containerName+" not sent: "+err.Error(), true)
+		return err
+	}
 	reqID := generateRequestID()
 	ch, err := s.registerPending(hostID, reqID)
 	if err != nil {
@@ -183,13 +249,20 @@ func (s *Server) ContainerActionSync(ctx context.Context, hostID, containerName,
 		},
 	}
 
+	cmd := action + " " + containerName
 	if err := s.SendCommand(hostID, msg); err != nil {
 		s.cancelPending(hostID, reqID, ch)
+		s.recordHostEvent(hostID, store.HostEventCommand, containerName, cmd+" not sent: "+err.Error(), true)
+		commandUnsent(hostID, commandAction)
 		return fmt.Errorf("send container action: %w", err)
 	}
+	s.recordHostEvent(hostID, store.HostEventCommand, containerName, cmd, false)
+	started := time.Now()
+	defer func() { s.observeCommand(hostID, commandAction, started, commandOutcome(err)) }()
 
 	resp, err := s.awaitPending(ctx, hostID, reqID, ch)
 	if err != nil {
+		s.recordHostEvent(hostID, store.HostEventResult, containerName, "no "+action+" result: "+err.Error(), true)
 		return fmt.Errorf("await container action result: %w", err)
 	}
 
@@ -205,7 +278,10 @@ func (s *Server) ContainerActionSync(ctx context.Context, hostID, containerName,
 
 // FetchLogsSync sends a FetchLogsRequest to the agent and blocks until the
 // agent responds with a FetchLogsResult or the context is cancelled.
-func (s *Server) FetchLogsSync(ctx context.Context, hostID, containerName string, lines int) (string, error) {
+func (s *Server) FetchLogsSync(ctx context.Context, hostID, containerName string, lines int) (_ string, err error) {
+	if err := s.checkAgent(hostID, FeatureLogs); err != nil {
+		return "", err
+	}
 	reqID := generateRequestID()
 	ch, err := s.registerPending(hostID, reqID)
 	if err != nil {
@@ -231,8 +307,11 @@ func (s *Server) FetchLogsSync(ctx context.Context, hostID, containerName string
 
 	if err := s.SendCommand(hostID, msg); err != nil {
 		s.cancelPending(hostID, reqID, ch)
+		commandUnsent(hostID, commandLogs)
 		return "", fmt.Errorf("send fetch logs: %w", err)
 	}
+	started := time.Now()
+	defer func() { s.observeCommand(hostID, commandLogs, started, commandOutcome(err)) }()
 
 	resp, err := s.awaitPending(ctx, hostID, reqID, ch)
 	if err != nil {
@@ -383,6 +462,7 @@ func protoToContainers(pcs []*proto.ContainerInfo) []cluster.ContainerInfo {
 			Image:       pc.Image,
 			ImageDigest: pc.ImageDigest,
 			State:       pc.State,
+			Health:      pc.Health,
 			Labels:      pc.Labels,
 		}
 		if pc.Created != nil {
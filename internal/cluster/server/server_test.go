@@ -14,6 +14,7 @@ import (
 	"log/slog"
 	"math/big"
 	"net"
+	"strings"
 	"testing"
 	"time"
 
@@ -220,7 +221,7 @@ func TestEnrollment(t *testing.T) {
 	srv, addr, st, _ := testServer(t)
 
 	// Generate enrollment token.
-	token, _, err := srv.GenerateEnrollToken(5 * time.Minute)
+	token, _, err := srv.GenerateEnrollToken(5*time.Minute, "")
 	if err != nil {
 		t.Fatalf("GenerateEnrollToken: %v", err)
 	}
@@ -353,7 +354,7 @@ func TestEnrollment_ExpiredToken(t *testing.T) {
 	// Generate a token with 0 expiry — ExpiresAt == time.Now() at creation.
 	// After a small sleep, the token will be in the past and the check
 	// time.Now().After(tok.ExpiresAt) will be true.
-	token, _, err := srv.GenerateEnrollToken(0)
+	token, _, err := srv.GenerateEnrollToken(0, "")
 	if err != nil {
 		t.Fatalf("GenerateEnrollToken: %v", err)
 	}
@@ -384,6 +385,62 @@ func TestEnrollment_ExpiredToken(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// TestEnrollTokens_ListRevokeAudit verifies token listing, revocation of
+// unused tokens, and the consumption record and callback.
+// ---------------------------------------------------------------------------
+
+func TestEnrollTokens_ListRevokeAudit(t *testing.T) {
+	srv, addr, _, _ := testServer(t)
+
+	var consumed []cluster.EnrollToken
+	srv.SetOnTokenUsed(func(tok cluster.EnrollToken) { consumed = append(consumed, tok) })
+
+	usedToken, usedID, err := srv.GenerateEnrollToken(time.Hour, "admin")
+	if err != nil {
+		t.Fatalf("GenerateEnrollToken: %v", err)
+	}
+	_, spareID, err := srv.GenerateEnrollToken(time.Hour, "")
+	if err != nil {
+		t.Fatalf("GenerateEnrollToken: %v", err)
+	}
+	hostID, _, _, _ := enrollAgent(t, addr, usedToken)
+
+	if len(consumed) != 1 || consumed[0].ID != usedID || consumed[0].UsedByID != hostID ||
+		consumed[0].UsedByName != "test-agent" || consumed[0].CreatedBy != "admin" || consumed[0].Hash != nil {
+		t.Fatalf("onTokenUsed calls = %+v", consumed)
+	}
+
+	tokens, err := srv.ListEnrollTokens()
+	if err != nil {
+		t.Fatalf("ListEnrollTokens: %v", err)
+	}
+	if len(tokens) != 2 {
+		t.Fatalf("ListEnrollTokens returned %d tokens, want 2", len(tokens))
+	}
+	for _, tok := range tokens {
+		if tok.Hash != nil {
+			t.Errorf("token %s listed with its hash", tok.ID)
+		}
+		if tok.ID == usedID && (!tok.Used || tok.UsedByID != hostID || tok.UsedAt.IsZero()) {
+			t.Errorf("used token = %+v", tok)
+		}
+	}
+
+	if err := srv.RevokeEnrollToken(usedID); !errors.Is(err, ErrEnrollTokenUsed) {
+		t.Errorf("RevokeEnrollToken(used) = %v, want ErrEnrollTokenUsed", err)
+	}
+	if err := srv.RevokeEnrollToken(spareID); err != nil {
+		t.Fatalf("RevokeEnrollToken(unused): %v", err)
+	}
+	if err := srv.RevokeEnrollToken(spareID); !errors.Is(err, ErrEnrollTokenNotFound) {
+		t.Errorf("RevokeEnrollToken(revoked) = %v, want ErrEnrollTokenNotFound", err)
+	}
+	if tokens, _ := srv.ListEnrollTokens(); len(tokens) != 1 || tokens[0].ID != usedID {
+		t.Errorf("after revoke, tokens = %+v", tokens)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // TestChannel_Heartbeat verifies that a heartbeat sent by the agent is
 // received by the server and updates the host's LastSeen timestamp.
@@ -393,7 +450,7 @@ func TestChannel_Heartbeat(t *testing.T) {
 	srv, addr, _, _ := testServer(t)
 
 	// Enroll an agent.
-	token, _, _ := srv.GenerateEnrollToken(5 * time.Minute)
+	token, _, _ := srv.GenerateEnrollToken(5*time.Minute, "")
 	hostID, certPEM, keyPEM, caPEM := enrollAgent(t, addr, token)
 
 	// Connect with mTLS.
@@ -468,7 +525,7 @@ func TestChannel_Heartbeat(t *testing.T) {
 func TestChannel_ListContainers(t *testing.T) {
 	srv, addr, _, _ := testServer(t)
 
-	token, _, _ := srv.GenerateEnrollToken(5 * time.Minute)
+	token, _, _ := srv.GenerateEnrollToken(5*time.Minute, "")
 	hostID, certPEM, keyPEM, caPEM := enrollAgent(t, addr, token)
 
 	conn := agentTLSConn(t, addr, certPEM, keyPEM, caPEM)
@@ -590,7 +647,7 @@ func TestChannel_ListContainers(t *testing.T) {
 func TestChannel_UpdateContainer(t *testing.T) {
 	srv, addr, _, bus := testServer(t)
 
-	token, _, _ := srv.GenerateEnrollToken(5 * time.Minute)
+	token, _, _ := srv.GenerateEnrollToken(5*time.Minute, "")
 	hostID, certPEM, keyPEM, caPEM := enrollAgent(t, addr, token)
 
 	conn := agentTLSConn(t, addr, certPEM, keyPEM, caPEM)
@@ -696,7 +753,7 @@ func TestChannel_UpdateContainer(t *testing.T) {
 func TestChannel_Disconnect(t *testing.T) {
 	srv, addr, _, bus := testServer(t)
 
-	token, _, _ := srv.GenerateEnrollToken(5 * time.Minute)
+	token, _, _ := srv.GenerateEnrollToken(5*time.Minute, "")
 	hostID, certPEM, keyPEM, caPEM := enrollAgent(t, addr, token)
 
 	conn := agentTLSConn(t, addr, certPEM, keyPEM, caPEM)
@@ -763,6 +820,105 @@ func TestChannel_Disconnect(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// TestHostEvents verifies the per-host timeline: enrollment, connection,
+// version changes, commands, results and disconnection are recorded in order.
+// ---------------------------------------------------------------------------
+
+func TestHostEvents(t *testing.T) {
+	srv, addr, st, _ := testServer(t)
+	srv.SetHostEventRecorder(st)
+
+	token, _, _ := srv.GenerateEnrollToken(5*time.Minute, "")
+	hostID, certPEM, keyPEM, caPEM := enrollAgent(t, addr, token)
+
+	conn := agentTLSConn(t, addr, certPEM, keyPEM, caPEM)
+	client := proto.NewAgentServiceClient(conn)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := client.Channel(ctx)
+	if err != nil {
+		t.Fatalf("Channel: %v", err)
+	}
+	waitFor(t, 3*time.Second, func() bool {
+		hs, ok := srv.Registry().Get(hostID)
+		return ok && hs.Connected
+	}, "host should be connected")
+
+	for _, v := range []string{"1.0.0", "1.0.0", "1.1.0"} {
+		if err := stream.Send(&proto.AgentMessage{
+			Payload: &proto.AgentMessage_Heartbeat{Heartbeat: &proto.Heartbeat{AgentVersion: v, HostId: hostID}},
+		}); err != nil {
+			t.Fatalf("send heartbeat: %v", err)
+		}
+	}
+	waitFor(t, 3*time.Second, func() bool {
+		hs, ok := srv.Registry().Get(hostID)
+		return ok && hs.Info.AgentVersion == "1.1.0"
+	}, "agent version should be stored")
+
+	// Agent: fail the restart it is sent.
+	go func() {
+		msg, err := stream.Recv()
+		if err != nil || msg.GetContainerAction() == nil {
+			return
+		}
+		_ = stream.Send(&proto.AgentMessage{
+			Payload: &proto.AgentMessage_ContainerActionResult{ContainerActionResult: &proto.ContainerActionResult{
+				RequestId:     msg.GetRequestId(),
+				ContainerName: "web",
+				Action:        "restart",
+				Outcome:       "failed",
+				Error:         "no such container",
+			}},
+		})
+	}()
+	actionCtx, actionCancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer actionCancel()
+	if err := srv.ContainerActionSync(actionCtx, hostID, "web", "restart"); err == nil {
+		t.Fatal("ContainerActionSync should report the failed restart")
+	}
+
+	cancel()
+	waitFor(t, 3*time.Second, func() bool {
+		hs, ok := srv.Registry().Get(hostID)
+		return ok && !hs.Connected
+	}, "host should disconnect")
+	waitFor(t, 3*time.Second, func() bool {
+		evts, _ := st.ListClusterHostEvents(hostID, 50)
+		return len(evts) > 0 && evts[0].Type == store.HostEventDisconnected
+	}, "disconnect should be recorded")
+
+	evts, err := st.ListClusterHostEvents(hostID, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var types []string
+	for i := len(evts) - 1; i >= 0; i-- {
+		types = append(types, evts[i].Type)
+	}
+	want := []string{
+		store.HostEventEnrolled, store.HostEventConnected, store.HostEventVersion, store.HostEventVersion,
+		store.HostEventCommand, store.HostEventResult, store.HostEventDisconnected,
+	}
+	if strings.Join(types, ",") != strings.Join(want, ",") {
+		t.Fatalf("event types = %v, want %v", types, want)
+	}
+	if msg := evts[3].Message; msg != "agent version changed from 1.0.0 to 1.1.0" {
+		t.Errorf("version event = %q", msg)
+	}
+	if res := evts[1]; !res.Failed || res.Container != "web" || !strings.Contains(res.Message, "no such container") {
+		t.Errorf("result event = %+v", res)
+	}
+
+	if err := srv.RemoveHost(hostID); err != nil {
+		t.Fatal(err)
+	}
+	if evts, _ := st.ListClusterHostEvents(hostID, 50); len(evts) != 0 {
+		t.Errorf("events kept after host removal: %+v", evts)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // TestChannel_Reconnect verifies that after disconnect, a reconnecting agent
 // re-establishes the stream and the host is marked connected again.
@@ -771,7 +927,7 @@ func TestChannel_Disconnect(t *testing.T) {
 func TestChannel_Reconnect(t *testing.T) {
 	srv, addr, _, _ := testServer(t)
 
-	token, _, _ := srv.GenerateEnrollToken(5 * time.Minute)
+	token, _, _ := srv.GenerateEnrollToken(5*time.Minute, "")
 	hostID, certPEM, keyPEM, caPEM := enrollAgent(t, addr, token)
 
 	conn := agentTLSConn(t, addr, certPEM, keyPEM, caPEM)
@@ -859,7 +1015,7 @@ func TestChannel_Reconnect(t *testing.T) {
 func TestReportState(t *testing.T) {
 	srv, addr, _, _ := testServer(t)
 
-	token, _, _ := srv.GenerateEnrollToken(5 * time.Minute)
+	token, _, _ := srv.GenerateEnrollToken(5*time.Minute, "")
 	hostID, certPEM, keyPEM, caPEM := enrollAgent(t, addr, token)
 
 	conn := agentTLSConn(t, addr, certPEM, keyPEM, caPEM)
@@ -932,7 +1088,7 @@ func TestReportState(t *testing.T) {
 func TestDuplicateRequestID(t *testing.T) {
 	srv, addr, _, _ := testServer(t)
 
-	token, _, _ := srv.GenerateEnrollToken(5 * time.Minute)
+	token, _, _ := srv.GenerateEnrollToken(5*time.Minute, "")
 	hostID, certPEM, keyPEM, caPEM := enrollAgent(t, addr, token)
 
 	conn := agentTLSConn(t, addr, certPEM, keyPEM, caPEM)
@@ -991,7 +1147,7 @@ func TestDuplicateRequestID(t *testing.T) {
 func TestCertRevocation(t *testing.T) {
 	srv, addr, st, _ := testServer(t)
 
-	token, _, _ := srv.GenerateEnrollToken(5 * time.Minute)
+	token, _, _ := srv.GenerateEnrollToken(5*time.Minute, "")
 	hostID, certPEM, keyPEM, caPEM := enrollAgent(t, addr, token)
 
 	// Look up the agent's cert serial from the registry so we can revoke it.
@@ -1150,7 +1306,7 @@ func TestSendCommand_DisconnectedAgent(t *testing.T) {
 func TestHandleCertRenewal_NoDataRace(t *testing.T) {
 	srv, addr, _, _ := testServer(t)
 
-	token, _, err := srv.GenerateEnrollToken(5 * time.Minute)
+	token, _, err := srv.GenerateEnrollToken(5*time.Minute, "")
 	if err != nil {
 		t.Fatalf("GenerateEnrollToken: %v", err)
 	}
@@ -1254,7 +1410,7 @@ func TestMultipleAgents(t *testing.T) {
 
 	// Enroll and connect each agent.
 	for i := 0; i < numAgents; i++ {
-		token, _, err := srv.GenerateEnrollToken(5 * time.Minute)
+		token, _, err := srv.GenerateEnrollToken(5*time.Minute, "")
 		if err != nil {
 			t.Fatalf("GenerateEnrollToken #%d: %v", i, err)
 		}
@@ -1349,6 +1505,7 @@ func (e *errStore) ListClusterHosts() (map[string][]byte, error) { return nil, n
 func (e *errStore) DeleteClusterHost(string) error               { return nil }
 func (e *errStore) SaveEnrollToken(string, []byte) error         { return nil }
 func (e *errStore) GetEnrollToken(string) ([]byte, error)        { return nil, nil }
+func (e *errStore) ListEnrollTokens() (map[string][]byte, error) { return nil, nil }
 func (e *errStore) DeleteEnrollToken(string) error               { return nil }
 func (e *errStore) AddRevokedCert(string) error                  { return nil }
 func (e *errStore) IsRevokedCert(string) (bool, error)           { return false, e.err }
@@ -1395,3 +1552,35 @@ func TestBuildTLSConfig_ResumptionDisabled(t *testing.T) {
 		t.Error("VerifyPeerCertificate must be set to the CRL check")
 	}
 }
+
+type fakeHookSource struct {
+	enabled bool
+	hooks   map[string][]store.HookEntry
+}
+
+func (f *fakeHookSource) HooksEnabled() bool { return f.enabled }
+
+func (f *fakeHookSource) ListHooks(name string) ([]store.HookEntry, error) {
+	return f.hooks[name], nil
+}
+
+func TestUpdateHooks(t *testing.T) {
+	s := &Server{log: slog.Default()}
+	if defs, skip := s.updateHooks("h1", "db"); defs != nil || !skip {
+		t.Errorf("without a hook source: defs = %v, skip = %v; want none, skip", defs, skip)
+	}
+
+	src := &fakeHookSource{hooks: map[string][]store.HookEntry{
+		"h1::db": {{Phase: "pre-update", Command: []string{"pg_dump"}, Timeout: 60}},
+	}}
+	s.SetHookSource(src)
+	if _, skip := s.updateHooks("h1", "db"); !skip {
+		t.Error("hooks disabled should tell the agent to skip")
+	}
+
+	src.enabled = true
+	defs, skip := s.updateHooks("h1", "db")
+	if skip || len(defs) != 1 || defs[0].GetPhase() != "pre-update" || defs[0].GetTimeoutSeconds() != 60 {
+		t.Errorf("defs = %v, skip = %v; want the host-scoped pre-update hook", defs, skip)
+	}
+}
@@ -11,6 +11,11 @@ const (
 	HostDecommissioned HostState = "decommissioned" // certs revoked, data GC'd
 )
 
+// MinAgentVersion is the oldest agent release this server can drive. Earlier
+// agents do not push a fresh container list before reporting an update
+// result, which the server relies on.
+const MinAgentVersion = "2.5.0"
+
 // HostInfo describes a registered remote agent host.
 type HostInfo struct {
 	ID           string    `json:"id"`          // unique host identifier (generated on enrollment)
@@ -52,6 +57,12 @@ type EnrollToken struct {
 	CreatedAt time.Time `json:"created_at"`
 	ExpiresAt time.Time `json:"expires_at"`
 	Used      bool      `json:"used"`
+	CreatedBy string    `json:"created_by,omitempty"` // username that generated the token
+
+	// Set when an agent consumes the token.
+	UsedAt     time.Time `json:"used_at,omitempty"`
+	UsedByID   string    `json:"used_by_id,omitempty"`   // host ID issued to the agent
+	UsedByName string    `json:"used_by_name,omitempty"` // host name the agent enrolled with
 }
 
 // PortMapping represents a single host-bound port on a container.
@@ -68,9 +79,10 @@ type PortMapping struct {
 type ContainerInfo struct {
 	ID          string            `json:"id"`
 	Name        string            `json:"name"`
-	Image       string            `json:"image"`        // full image reference
-	ImageDigest string            `json:"image_digest"` // current running digest
-	State       string            `json:"state"`        // running, stopped, etc.
+	Image       string            `json:"image"`            // full image reference
+	ImageDigest string            `json:"image_digest"`     // current running digest
+	State       string            `json:"state"`            // running, stopped, etc.
+	Health      string            `json:"health,omitempty"` // healthy, unhealthy, starting; empty without a healthcheck
 	Labels      map[string]string `json:"labels,omitempty"`
 	Created     time.Time         `json:"created"`
 	Ports       []PortMapping     `json:"ports,omitempty"`
@@ -3,7 +3,9 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -19,10 +21,14 @@ type Config struct {
 	DockerSock string
 
 	// Storage
-	DBPath string
+	DBPath            string
+	DBDriver          string // SENTINEL_DB_DRIVER — storage backend: "bolt" (default) or "sqlite"
+	EncryptionKey     string // SENTINEL_ENCRYPTION_KEY — encrypts secrets at rest in the database
+	EncryptionKeyFile string // SENTINEL_ENCRYPTION_KEY_FILE — file holding the key, used when EncryptionKey is empty
 
 	// Logging
-	LogJSON bool
+	LogJSON  bool
+	LogLevel string // SENTINEL_LOG_LEVEL — base level: debug, info, warn or error
 
 	// Notifications
 	GotifyURL      string
@@ -36,15 +42,24 @@ type Config struct {
 	HostAddress string // SENTINEL_HOST — Docker host IP/hostname for port links (auto-detected if empty)
 
 	// Authentication
-	AuthEnabled   *bool // nil = use DB default (true); non-nil = env override
-	SessionExpiry time.Duration
-	CookieSecure  bool
+	AuthEnabled    *bool // nil = use DB default (true); non-nil = env override
+	SessionExpiry  time.Duration
+	CookieSecure   bool
+	CookieDomain   string // SENTINEL_COOKIE_DOMAIN — share cookies across subdomains; empty = host-only
+	CookieSameSite string // SENTINEL_COOKIE_SAMESITE — "lax" (default), "strict" or "none"
+	// SENTINEL_ALLOWED_ORIGINS — comma-separated origins the dashboard is
+	// reached on (e.g. "http://192.0.2.10:8080,https://sentinel.example.com").
+	AllowedOrigins string
 
 	// TLS
 	TLSCert string // path to TLS certificate PEM file
 	TLSKey  string // path to TLS private key PEM file
 	TLSAuto bool   // auto-generate self-signed certificate
 
+	// API rate limiting (token bucket per API token or client IP)
+	APIRateLimit int // requests per minute; 0 disables
+	APIRateBurst int // bucket size; 0 = same as APIRateLimit
+
 	// WebAuthn passkeys (all empty = disabled)
 	WebAuthnRPID        string // Relying Party ID (e.g. "192.0.2.10")
 	WebAuthnDisplayName string // RP display name shown by authenticators
@@ -53,7 +68,7 @@ type Config struct {
 	MetricsTextfile     string // SENTINEL_METRICS_TEXTFILE — path for node_exporter textfile collector
 
 	// Cluster / multi-host
-	Mode                 string        // "server" or "agent" — set via subcommand or SENTINEL_MODE env
+	Mode                 string        // "server", "agent" or "exporter" — set via subcommand or SENTINEL_MODE env
 	ClusterEnabled       bool          // server: whether to start the gRPC listener
 	ClusterPort          string        // gRPC port (default "9443")
 	ClusterDataDir       string        // CA/cert storage directory (default "/data/cluster")
@@ -63,10 +78,22 @@ type Config struct {
 	HostName             string        // agent: human-readable label for this host
 	GracePeriodOffline   time.Duration // agent: time before switching to autonomous mode (default 30m)
 
+	// Exporter mode (registry rate limits only)
+	ExporterRegistries string        // comma-separated registry hosts to probe (default "docker.io")
+	ExporterInterval   time.Duration // how often to probe them (default 5m)
+	DockerConfig       string        // path to a Docker config.json with registry credentials; empty = $DOCKER_CONFIG or ~/.docker
+
+	// Shutdown / startup
+	DrainTimeout time.Duration // how long to wait for in-flight updates on SIGTERM
+	CatchUp      bool          // on startup, run scans/digests whose schedule passed while down
+
 	// Portainer integration
 	PortainerURL   string
 	PortainerToken string
 
+	// Custom update sources ("name=pattern=url[|TOKEN_VAR];..."), see registry.ParseHTTPSources
+	UpdateSources string
+
 	// NPM (Nginx Proxy Manager) integration
 	NPMURL      string
 	NPMEmail    string
@@ -114,7 +141,11 @@ func Load() *Config {
 		defaultPolicy:       envStr("SENTINEL_DEFAULT_POLICY", "manual"),
 		latestAutoUpdate:    envBool("SENTINEL_LATEST_AUTO_UPDATE", false),
 		DBPath:              envStr("SENTINEL_DB_PATH", "/data/sentinel.db"),
+		DBDriver:            envStr("SENTINEL_DB_DRIVER", "bolt"),
+		EncryptionKey:       envStr("SENTINEL_ENCRYPTION_KEY", ""),
+		EncryptionKeyFile:   envStr("SENTINEL_ENCRYPTION_KEY_FILE", ""),
 		LogJSON:             envBool("SENTINEL_LOG_JSON", true),
+		LogLevel:            envStr("SENTINEL_LOG_LEVEL", "debug"),
 		GotifyURL:           envStr("SENTINEL_GOTIFY_URL", ""),
 		GotifyToken:         envStr("SENTINEL_GOTIFY_TOKEN", ""),
 		WebhookURL:          envStr("SENTINEL_WEBHOOK_URL", ""),
@@ -125,9 +156,14 @@ func Load() *Config {
 		AuthEnabled:         envBoolPtr("SENTINEL_AUTH_ENABLED"),
 		SessionExpiry:       envDuration("SENTINEL_SESSION_EXPIRY", 720*time.Hour),
 		CookieSecure:        envBool("SENTINEL_COOKIE_SECURE", true),
+		CookieDomain:        envStr("SENTINEL_COOKIE_DOMAIN", ""),
+		CookieSameSite:      envStr("SENTINEL_COOKIE_SAMESITE", "lax"),
+		AllowedOrigins:      envStr("SENTINEL_ALLOWED_ORIGINS", ""),
 		TLSCert:             envStr("SENTINEL_TLS_CERT", ""),
 		TLSKey:              envStr("SENTINEL_TLS_KEY", ""),
 		TLSAuto:             envBool("SENTINEL_TLS_AUTO", false),
+		APIRateLimit:        envInt("SENTINEL_API_RATE_LIMIT", 600),
+		APIRateBurst:        envInt("SENTINEL_API_RATE_BURST", 120),
 		WebAuthnRPID:        envStr("SENTINEL_WEBAUTHN_RPID", ""),
 		WebAuthnDisplayName: envStr("SENTINEL_WEBAUTHN_DISPLAY_NAME", "Docker-Sentinel"),
 		WebAuthnOrigins:     envStr("SENTINEL_WEBAUTHN_ORIGINS", ""),
@@ -156,6 +192,15 @@ func Load() *Config {
 		HostName:             envStr("SENTINEL_HOST_NAME", ""),
 		GracePeriodOffline:   envDuration("SENTINEL_GRACE_PERIOD_OFFLINE", 30*time.Minute),
 
+		// Exporter mode
+		ExporterRegistries: envStr("SENTINEL_EXPORTER_REGISTRIES", "docker.io"),
+		ExporterInterval:   envDuration("SENTINEL_EXPORTER_INTERVAL", 5*time.Minute),
+		DockerConfig:       envStr("SENTINEL_DOCKER_CONFIG", ""),
+
+		// Shutdown / startup
+		DrainTimeout: envDuration("SENTINEL_DRAIN_TIMEOUT", 60*time.Second),
+		CatchUp:      envBool("SENTINEL_CATCH_UP", true),
+
 		// Portainer integration
 		PortainerURL:   envStr("SENTINEL_PORTAINER_URL", ""),
 		PortainerToken: envStr("SENTINEL_PORTAINER_TOKEN", ""),
@@ -164,6 +209,9 @@ func Load() *Config {
 		NPMURL:      envStr("SENTINEL_NPM_URL", ""),
 		NPMEmail:    envStr("SENTINEL_NPM_EMAIL", ""),
 		NPMPassword: envStr("SENTINEL_NPM_PASSWORD", ""),
+
+		// Custom update sources
+		UpdateSources: envStr("SENTINEL_UPDATE_SOURCES", ""),
 	}
 }
 
@@ -191,6 +239,34 @@ func (c *Config) Validate() error {
 	if (c.TLSCert == "") != (c.TLSKey == "") {
 		errs = append(errs, fmt.Errorf("SENTINEL_TLS_CERT and SENTINEL_TLS_KEY must both be set or both empty"))
 	}
+	switch strings.ToLower(c.LogLevel) {
+	case "", "debug", "info", "warn", "warning", "error":
+	default:
+		errs = append(errs, fmt.Errorf("SENTINEL_LOG_LEVEL must be debug, info, warn or error, got %q", c.LogLevel))
+	}
+	switch c.DBDriver {
+	case "", "bolt", "sqlite":
+		// valid
+	default:
+		errs = append(errs, fmt.Errorf("SENTINEL_DB_DRIVER must be bolt or sqlite, got %q", c.DBDriver))
+	}
+	if c.APIRateLimit < 0 || c.APIRateBurst < 0 {
+		errs = append(errs, fmt.Errorf("SENTINEL_API_RATE_LIMIT and SENTINEL_API_RATE_BURST must be >= 0"))
+	}
+	switch strings.ToLower(c.CookieSameSite) {
+	case "", "lax", "strict":
+	case "none":
+		if !c.CookieSecure {
+			errs = append(errs, fmt.Errorf("SENTINEL_COOKIE_SAMESITE=none requires SENTINEL_COOKIE_SECURE=true"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("SENTINEL_COOKIE_SAMESITE must be lax, strict or none, got %q", c.CookieSameSite))
+	}
+	for _, o := range splitList(c.AllowedOrigins) {
+		if u, err := url.Parse(o); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("SENTINEL_ALLOWED_ORIGINS entry %q must be an http(s) origin", o))
+		}
+	}
 	// WebAuthn: RPID and origins must both be set or both empty.
 	if c.WebAuthnRPID != "" && c.WebAuthnOrigins == "" {
 		errs = append(errs, fmt.Errorf("SENTINEL_WEBAUTHN_ORIGINS is required when SENTINEL_WEBAUTHN_RPID is set"))
@@ -200,8 +276,8 @@ func (c *Config) Validate() error {
 	}
 
 	// Cluster mode validation.
-	if c.Mode != "" && c.Mode != "server" && c.Mode != "agent" {
-		errs = append(errs, fmt.Errorf("SENTINEL_MODE must be 'server' or 'agent', got %q", c.Mode))
+	if c.Mode != "" && c.Mode != "server" && c.Mode != "agent" && c.Mode != "exporter" {
+		errs = append(errs, fmt.Errorf("SENTINEL_MODE must be 'server', 'agent' or 'exporter', got %q", c.Mode))
 	}
 	if c.Mode == "agent" {
 		if c.ServerAddr == "" && c.EnrollToken == "" {
@@ -209,6 +285,14 @@ func (c *Config) Validate() error {
 		}
 		// Web UI check removed: agents now run a minimal web UI for setup.
 	}
+	if c.Mode == "exporter" {
+		if len(c.ExporterRegistryList()) == 0 {
+			errs = append(errs, fmt.Errorf("SENTINEL_EXPORTER_REGISTRIES must list at least one registry in exporter mode"))
+		}
+		if c.ExporterInterval < 30*time.Second {
+			errs = append(errs, fmt.Errorf("SENTINEL_EXPORTER_INTERVAL must be >= 30s, got %s", c.ExporterInterval))
+		}
+	}
 
 	return errors.Join(errs...)
 }
@@ -238,7 +322,11 @@ func (c *Config) Values() map[string]string {
 		"SENTINEL_GRACE_PERIOD":          gp.String(),
 		"SENTINEL_DEFAULT_POLICY":        dp,
 		"SENTINEL_DB_PATH":               c.DBPath,
+		"SENTINEL_DB_DRIVER":             c.DBDriver,
+		"SENTINEL_ENCRYPTION_KEY":        redactPath(c.EncryptionKey),
+		"SENTINEL_ENCRYPTION_KEY_FILE":   c.EncryptionKeyFile,
 		"SENTINEL_LOG_JSON":              fmt.Sprintf("%t", c.LogJSON),
+		"SENTINEL_LOG_LEVEL":             c.LogLevel,
 		"SENTINEL_GOTIFY_URL":            c.GotifyURL,
 		"SENTINEL_WEBHOOK_URL":           c.WebhookURL,
 		"SENTINEL_WEB_PORT":              c.WebPort,
@@ -246,9 +334,14 @@ func (c *Config) Values() map[string]string {
 		"SENTINEL_HOST":                  c.HostAddress,
 		"SENTINEL_SESSION_EXPIRY":        c.SessionExpiry.String(),
 		"SENTINEL_COOKIE_SECURE":         fmt.Sprintf("%t", c.CookieSecure),
+		"SENTINEL_COOKIE_DOMAIN":         c.CookieDomain,
+		"SENTINEL_COOKIE_SAMESITE":       c.CookieSameSite,
+		"SENTINEL_ALLOWED_ORIGINS":       c.AllowedOrigins,
 		"SENTINEL_TLS_CERT":              c.TLSCert,
 		"SENTINEL_TLS_KEY":               redactPath(c.TLSKey),
 		"SENTINEL_TLS_AUTO":              fmt.Sprintf("%t", c.TLSAuto),
+		"SENTINEL_API_RATE_LIMIT":        fmt.Sprintf("%d", c.APIRateLimit),
+		"SENTINEL_API_RATE_BURST":        fmt.Sprintf("%d", c.APIRateBurst),
 		"SENTINEL_WEBAUTHN_RPID":         c.WebAuthnRPID,
 		"SENTINEL_WEBAUTHN_DISPLAY_NAME": c.WebAuthnDisplayName,
 		"SENTINEL_WEBAUTHN_ORIGINS":      c.WebAuthnOrigins,
@@ -269,17 +362,27 @@ func (c *Config) Values() map[string]string {
 		"SENTINEL_SERVER_ADDR":          c.ServerAddr,
 		"SENTINEL_HOST_NAME":            c.HostName,
 		"SENTINEL_GRACE_PERIOD_OFFLINE": c.GracePeriodOffline.String(),
+		"SENTINEL_DRAIN_TIMEOUT":        c.DrainTimeout.String(),
+		"SENTINEL_CATCH_UP":             fmt.Sprintf("%t", c.CatchUp),
 		"SENTINEL_IMAGE_BACKUP":         fmt.Sprintf("%t", ib),
 		"SENTINEL_SHOW_STOPPED":         fmt.Sprintf("%t", ss),
 		"SENTINEL_REMOVE_VOLUMES":       fmt.Sprintf("%t", rv),
 		"SENTINEL_SCAN_CONCURRENCY":     fmt.Sprintf("%d", sc),
 		"SENTINEL_MAINTENANCE_WINDOW":   mw,
 
+		// Exporter mode
+		"SENTINEL_EXPORTER_REGISTRIES": c.ExporterRegistries,
+		"SENTINEL_EXPORTER_INTERVAL":   c.ExporterInterval.String(),
+		"SENTINEL_DOCKER_CONFIG":       c.DockerConfig,
+
 		// Portainer
 		"SENTINEL_PORTAINER_URL": c.PortainerURL,
 
 		// NPM
 		"SENTINEL_NPM_URL": c.NPMURL,
+
+		// Custom update sources
+		"SENTINEL_UPDATE_SOURCES": c.UpdateSources,
 	}
 }
 
@@ -537,6 +640,26 @@ func (c *Config) SetMaintenanceWindow(s string) {
 	c.mu.Unlock()
 }
 
+// EncryptionSecret returns the secret for encryption at rest, from
+// SENTINEL_ENCRYPTION_KEY or else the key file. It returns nil when neither
+// is set, and an error for an unreadable or empty key file.
+func (c *Config) EncryptionSecret() ([]byte, error) {
+	if c.EncryptionKey != "" {
+		return []byte(c.EncryptionKey), nil
+	}
+	if c.EncryptionKeyFile == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(c.EncryptionKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("read encryption key file: %w", err)
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return nil, fmt.Errorf("encryption key file %s is empty", c.EncryptionKeyFile)
+	}
+	return data, nil
+}
+
 // redactPath returns "(set)" if the path is non-empty, empty string otherwise.
 func redactPath(s string) string {
 	if s != "" {
@@ -557,23 +680,68 @@ func (c *Config) WebAuthnEnabled() bool {
 
 // WebAuthnOriginList parses the comma-separated origins into a slice.
 func (c *Config) WebAuthnOriginList() []string {
-	if c.WebAuthnOrigins == "" {
-		return nil
-	}
-	var origins []string
-	for _, o := range strings.Split(c.WebAuthnOrigins, ",") {
-		if trimmed := strings.TrimSpace(o); trimmed != "" {
-			origins = append(origins, trimmed)
+	return splitList(c.WebAuthnOrigins)
+}
+
+// AllowedOriginList parses SENTINEL_ALLOWED_ORIGINS into a slice.
+func (c *Config) AllowedOriginList() []string {
+	return splitList(c.AllowedOrigins)
+}
+
+// WebAuthnRPOrigins returns the WebAuthn origins plus any allowed origins
+// whose host falls under the relying party ID, so passkeys work on every
+// address that can use them. Origins outside the RP ID are left out: the
+// browser refuses passkeys there regardless.
+func (c *Config) WebAuthnRPOrigins() []string {
+	origins := c.WebAuthnOriginList()
+	rpid := strings.ToLower(c.WebAuthnRPID)
+	for _, o := range c.AllowedOriginList() {
+		u, err := url.Parse(o)
+		if err != nil || rpid == "" {
+			continue
+		}
+		host := strings.ToLower(u.Hostname())
+		if (host == rpid || strings.HasSuffix(host, "."+rpid)) && !slices.Contains(origins, o) {
+			origins = append(origins, o)
 		}
 	}
 	return origins
 }
 
+// splitList splits a comma-separated value, dropping blanks.
+func splitList(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if trimmed := strings.TrimSpace(v); trimmed != "" {
+			out = append(out, trimmed)
+		}
+	}
+	return out
+}
+
 // IsAgent returns true when running in agent mode.
 func (c *Config) IsAgent() bool {
 	return c.Mode == "agent"
 }
 
+// IsExporter returns true when running as a standalone registry rate limit
+// exporter.
+func (c *Config) IsExporter() bool {
+	return c.Mode == "exporter"
+}
+
+// ExporterRegistryList returns the registry hosts from ExporterRegistries,
+// trimmed and with empty entries dropped.
+func (c *Config) ExporterRegistryList() []string {
+	var hosts []string
+	for _, h := range strings.Split(c.ExporterRegistries, ",") {
+		if trimmed := strings.TrimSpace(h); trimmed != "" {
+			hosts = append(hosts, trimmed)
+		}
+	}
+	return hosts
+}
+
 // IsServer returns true when running in server mode (explicit or default).
 func (c *Config) IsServer() bool {
 	return c.Mode == "" || c.Mode == "server"
@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"path/filepath"
+	"slices"
 	"sync"
 	"testing"
 	"time"
@@ -11,7 +13,7 @@ func TestLoadDefaults(t *testing.T) {
 	// Unset all sentinel env vars to get defaults.
 	for _, k := range []string{
 		"SENTINEL_DOCKER_SOCK", "SENTINEL_POLL_INTERVAL", "SENTINEL_GRACE_PERIOD",
-		"SENTINEL_DEFAULT_POLICY", "SENTINEL_DB_PATH", "SENTINEL_LOG_JSON",
+		"SENTINEL_DEFAULT_POLICY", "SENTINEL_DB_PATH", "SENTINEL_DB_DRIVER", "SENTINEL_LOG_JSON",
 	} {
 		os.Unsetenv(k)
 	}
@@ -81,6 +83,31 @@ func TestValidate(t *testing.T) {
 			c.WebAuthnOrigins = "https://example.com"
 		}, false},
 		{"WebAuthn both empty", func(c *Config) {}, false},
+		{"cookie samesite strict", func(c *Config) { c.CookieSameSite = "strict" }, false},
+		{"cookie samesite unknown", func(c *Config) { c.CookieSameSite = "loose" }, true},
+		{"cookie samesite none without secure", func(c *Config) { c.CookieSameSite = "none"; c.CookieSecure = false }, true},
+		{"cookie samesite none with secure", func(c *Config) { c.CookieSameSite = "none"; c.CookieSecure = true }, false},
+		{"allowed origins valid", func(c *Config) { c.AllowedOrigins = "http://192.0.2.10:8080, https://sentinel.example.com" }, false},
+		{"allowed origin without scheme", func(c *Config) { c.AllowedOrigins = "sentinel.example.com" }, true},
+		{"exporter mode valid", func(c *Config) {
+			c.Mode = "exporter"
+			c.ExporterRegistries = "docker.io, ghcr.io"
+			c.ExporterInterval = time.Minute
+		}, false},
+		{"exporter mode without registries", func(c *Config) {
+			c.Mode = "exporter"
+			c.ExporterRegistries = " , "
+			c.ExporterInterval = time.Minute
+		}, true},
+		{"exporter interval too short", func(c *Config) {
+			c.Mode = "exporter"
+			c.ExporterRegistries = "docker.io"
+			c.ExporterInterval = time.Second
+		}, true},
+		{"unknown mode", func(c *Config) { c.Mode = "sidecar" }, true},
+		{"bolt driver valid", func(c *Config) { c.DBDriver = "bolt" }, false},
+		{"sqlite driver valid", func(c *Config) { c.DBDriver = "sqlite" }, false},
+		{"unknown driver", func(c *Config) { c.DBDriver = "postgres" }, true},
 	}
 
 	for _, tt := range tests {
@@ -295,6 +322,18 @@ func TestWebAuthnOriginList(t *testing.T) {
 	}
 }
 
+func TestWebAuthnRPOrigins(t *testing.T) {
+	cfg := NewTestConfig()
+	cfg.WebAuthnRPID = "example.com"
+	cfg.WebAuthnOrigins = "https://example.com"
+	cfg.AllowedOrigins = "http://192.0.2.10:8080,https://sentinel.example.com,https://example.com"
+	got := cfg.WebAuthnRPOrigins()
+	want := []string{"https://example.com", "https://sentinel.example.com"}
+	if !slices.Equal(got, want) {
+		t.Errorf("WebAuthnRPOrigins() = %v, want %v", got, want)
+	}
+}
+
 func TestIsAgentIsServer(t *testing.T) {
 	tests := []struct {
 		mode     string
@@ -319,6 +358,55 @@ func TestIsAgentIsServer(t *testing.T) {
 	}
 }
 
+func TestIsExporter(t *testing.T) {
+	cfg := NewTestConfig()
+	cfg.Mode = "exporter"
+	if !cfg.IsExporter() || cfg.IsServer() || cfg.IsAgent() {
+		t.Errorf("exporter mode: IsExporter=%v IsServer=%v IsAgent=%v", cfg.IsExporter(), cfg.IsServer(), cfg.IsAgent())
+	}
+	cfg.ExporterRegistries = " docker.io,,ghcr.io "
+	got := cfg.ExporterRegistryList()
+	if len(got) != 2 || got[0] != "docker.io" || got[1] != "ghcr.io" {
+		t.Errorf("ExporterRegistryList() = %q", got)
+	}
+}
+
+func TestEncryptionSecret(t *testing.T) {
+	cfg := NewTestConfig()
+	if secret, err := cfg.EncryptionSecret(); err != nil || secret != nil {
+		t.Errorf("unset: got %q, %v, want nil", secret, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(path, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg.EncryptionKeyFile = path
+	if secret, err := cfg.EncryptionSecret(); err != nil || string(secret) != "from-file\n" {
+		t.Errorf("key file: got %q, %v", secret, err)
+	}
+
+	cfg.EncryptionKey = "from-env"
+	if secret, _ := cfg.EncryptionSecret(); string(secret) != "from-env" {
+		t.Errorf("env key should win over the file, got %q", secret)
+	}
+	if v := cfg.Values()["SENTINEL_ENCRYPTION_KEY"]; v != "(set)" {
+		t.Errorf("Values() shows the key as %q, want it redacted", v)
+	}
+
+	cfg.EncryptionKey = ""
+	if err := os.WriteFile(path, []byte("  \n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cfg.EncryptionSecret(); err == nil {
+		t.Error("empty key file accepted")
+	}
+	cfg.EncryptionKeyFile = filepath.Join(t.TempDir(), "missing")
+	if _, err := cfg.EncryptionSecret(); err == nil {
+		t.Error("missing key file accepted")
+	}
+}
+
 func TestValuesCorrectness(t *testing.T) {
 	cfg := NewTestConfig()
 	cfg.SetSchedule("0 2 * * *")
@@ -0,0 +1,241 @@
+// Package ctl implements "sentinel ctl", a command-line client for the
+// Sentinel HTTP API authenticated with an API token.
+package ctl
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// apiPrefix is the versioned API path every request is sent under.
+const apiPrefix = "/api/v1"
+
+// Client calls the Sentinel API with a bearer token.
+type Client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// NewClient returns a client for the server at baseURL, e.g.
+// "https://sentinel.lan:8080". insecure skips TLS certificate checks for
+// servers using a self-signed certificate.
+func NewClient(baseURL, token string, insecure bool) *Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if insecure {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec // opt-in via --insecure
+	}
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		http:    &http.Client{Timeout: 60 * time.Second, Transport: transport},
+	}
+}
+
+// APIError is a non-2xx response from the server.
+type APIError struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+func (e *APIError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("%s (%d %s)", e.Message, e.Status, e.Code)
+	}
+	return fmt.Sprintf("%s (%d)", e.Message, e.Status)
+}
+
+// do sends a request to apiPrefix+path and decodes a JSON response into
+// out when it is non-nil.
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+apiPrefix+path, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := &APIError{Status: resp.StatusCode}
+		var e struct {
+			Error   string `json:"error"`
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &e) == nil {
+			apiErr.Code = e.Code
+			apiErr.Message = e.Message
+			if apiErr.Message == "" {
+				apiErr.Message = e.Error
+			}
+		}
+		if apiErr.Message == "" {
+			apiErr.Message = strings.TrimSpace(string(data))
+		}
+		if apiErr.Message == "" {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+		return apiErr
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("decode response from %s %s: %w", method, path, err)
+	}
+	return nil
+}
+
+// Container is one row of GET /api/v1/containers.
+type Container struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Image       string `json:"image"`
+	Policy      string `json:"policy"`
+	State       string `json:"state"`
+	Maintenance bool   `json:"maintenance"`
+	Stack       string `json:"stack,omitempty"`
+	Version     string `json:"current_version,omitempty"`
+	Candidate   string `json:"candidate_version,omitempty"`
+	Failing     bool   `json:"update_failing,omitempty"`
+}
+
+// PendingUpdate is one entry of GET /api/v1/queue.
+type PendingUpdate struct {
+	ContainerName         string    `json:"container_name"`
+	CurrentImage          string    `json:"current_image"`
+	DetectedAt            time.Time `json:"detected_at"`
+	NewerVersions         []string  `json:"newer_versions,omitempty"`
+	ResolvedTargetVersion string    `json:"resolved_target_version,omitempty"`
+	Type                  string    `json:"type,omitempty"`
+	HostID                string    `json:"host_id,omitempty"`
+	HostName              string    `json:"host_name,omitempty"`
+}
+
+// Key is the queue key approve and reject take: "hostID::name" for
+// containers on cluster hosts, otherwise the name.
+func (p PendingUpdate) Key() string {
+	if p.HostID == "" {
+		return p.ContainerName
+	}
+	return p.HostID + "::" + p.ContainerName
+}
+
+// HistoryRecord is one update in GET /api/v1/history.
+type HistoryRecord struct {
+	Timestamp     time.Time     `json:"timestamp"`
+	ContainerName string        `json:"container_name"`
+	OldImage      string        `json:"old_image"`
+	NewImage      string        `json:"new_image"`
+	Outcome       string        `json:"outcome"`
+	Duration      time.Duration `json:"duration"`
+	Error         string        `json:"error,omitempty"`
+	HostName      string        `json:"host_name,omitempty"`
+}
+
+// Result is the status and message most action endpoints answer with.
+type Result struct {
+	Status        string   `json:"status,omitempty"`
+	Name          string   `json:"name,omitempty"`
+	Message       string   `json:"message"`
+	NewerVersions []string `json:"newer_versions,omitempty"`
+}
+
+// Containers lists the containers and services the server manages.
+func (c *Client) Containers(ctx context.Context) ([]Container, error) {
+	var out []Container
+	return out, c.do(ctx, http.MethodGet, "/containers", nil, &out)
+}
+
+// Queue lists updates awaiting approval.
+func (c *Client) Queue(ctx context.Context) ([]PendingUpdate, error) {
+	var out []PendingUpdate
+	return out, c.do(ctx, http.MethodGet, "/queue", nil, &out)
+}
+
+// History returns the most recent updates, or those of one container when
+// name is set.
+func (c *Client) History(ctx context.Context, name string, limit int) ([]HistoryRecord, error) {
+	if name == "" {
+		var out []HistoryRecord
+		return out, c.do(ctx, http.MethodGet, fmt.Sprintf("/history?limit=%d", limit), nil, &out)
+	}
+	var detail struct {
+		History []HistoryRecord `json:"history"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/containers/"+url.PathEscape(name), nil, &detail); err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(detail.History) > limit {
+		detail.History = detail.History[:limit]
+	}
+	return detail.History, nil
+}
+
+// Check runs a registry check for one container.
+func (c *Client) Check(ctx context.Context, name string) (Result, error) {
+	return c.action(ctx, "/check/"+url.PathEscape(name))
+}
+
+// Update starts an update of one container.
+func (c *Client) Update(ctx context.Context, name string) (Result, error) {
+	return c.action(ctx, "/update/"+url.PathEscape(name))
+}
+
+// Approve approves a queued update by its queue key.
+func (c *Client) Approve(ctx context.Context, key string) (Result, error) {
+	return c.action(ctx, "/approve/"+url.PathEscape(key))
+}
+
+// Reject drops a queued update by its queue key.
+func (c *Client) Reject(ctx context.Context, key string) (Result, error) {
+	return c.action(ctx, "/reject/"+url.PathEscape(key))
+}
+
+// Rollback restores a container's previous image.
+func (c *Client) Rollback(ctx context.Context, name string) (Result, error) {
+	return c.action(ctx, "/containers/"+url.PathEscape(name)+"/rollback")
+}
+
+// Scan starts a full scan cycle.
+func (c *Client) Scan(ctx context.Context) (Result, error) {
+	return c.action(ctx, "/scan")
+}
+
+func (c *Client) action(ctx context.Context, path string) (Result, error) {
+	var out Result
+	err := c.do(ctx, http.MethodPost, path, nil, &out)
+	return out, err
+}
@@ -0,0 +1,266 @@
+package ctl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const usage = `Usage: sentinel ctl [flags] <command> [args]
+
+Commands:
+  list [--pending]             list containers, or updates awaiting approval
+  check <name>                 check the registry for an update
+  update <name>                update a container now
+  approve <key>                approve a queued update
+  reject <key>                 reject a queued update
+  rollback <name>              roll a container back to its previous image
+  history [--limit n] [name]   show recent updates
+  scan                         start a full scan
+
+Flags:
+  --server url     Sentinel URL (env SENTINEL_URL, default http://localhost:8080)
+  --token token    API token, created under Account (env SENTINEL_TOKEN)
+  --output format  table or json (default table)
+  --insecure       skip TLS certificate verification
+`
+
+// options are the global flags shared by every command.
+type options struct {
+	server   string
+	token    string
+	output   string
+	insecure bool
+}
+
+// Run executes "sentinel ctl" with args (the words after "ctl") and returns
+// the process exit code. getenv supplies SENTINEL_URL and SENTINEL_TOKEN.
+func Run(ctx context.Context, args []string, stdout, stderr io.Writer, getenv func(string) string) int {
+	opts := options{server: getenv("SENTINEL_URL"), token: getenv("SENTINEL_TOKEN")}
+	if opts.server == "" {
+		opts.server = "http://localhost:8080"
+	}
+	fs := flag.NewFlagSet("sentinel ctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { fmt.Fprint(stderr, usage) }
+	fs.StringVar(&opts.server, "server", opts.server, "Sentinel URL")
+	fs.StringVar(&opts.token, "token", opts.token, "API token")
+	fs.StringVar(&opts.output, "output", "table", "output format: table or json")
+	fs.BoolVar(&opts.insecure, "insecure", false, "skip TLS certificate verification")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if opts.output != "table" && opts.output != "json" {
+		fmt.Fprintf(stderr, "invalid --output %q: want table or json\n", opts.output)
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+	if fs.Arg(0) == "help" {
+		fmt.Fprint(stdout, usage)
+		return 0
+	}
+	if opts.token == "" {
+		fmt.Fprintln(stderr, "no API token: pass --token or set SENTINEL_TOKEN")
+		return 2
+	}
+
+	c := NewClient(opts.server, opts.token, opts.insecure)
+	cmd, rest := fs.Arg(0), fs.Args()[1:]
+	err := runCommand(ctx, c, opts, cmd, rest, stdout, stderr)
+	var usageErr usageError
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &usageErr):
+		fmt.Fprintln(stderr, err)
+		return 2
+	default:
+		fmt.Fprintln(stderr, "error:", err)
+		return 1
+	}
+}
+
+// usageError reports a malformed command line.
+type usageError string
+
+func (e usageError) Error() string { return string(e) }
+
+func runCommand(ctx context.Context, c *Client, opts options, cmd string, args []string, stdout, stderr io.Writer) error {
+	// oneArg returns the single positional argument a command takes.
+	oneArg := func(what string) (string, error) {
+		if len(args) != 1 || args[0] == "" {
+			return "", usageError("usage: sentinel ctl " + cmd + " <" + what + ">")
+		}
+		return args[0], nil
+	}
+	action := func(res Result, err error) error {
+		if err != nil {
+			return err
+		}
+		return printResult(stdout, opts.output, res)
+	}
+
+	switch cmd {
+	case "list", "ls":
+		fs := flag.NewFlagSet("list", flag.ContinueOnError)
+		fs.SetOutput(stderr)
+		pending := fs.Bool("pending", false, "list updates awaiting approval")
+		if err := fs.Parse(args); err != nil {
+			return usageError("usage: sentinel ctl list [--pending]")
+		}
+		if *pending {
+			queue, err := c.Queue(ctx)
+			if err != nil {
+				return err
+			}
+			return printQueue(stdout, opts.output, queue)
+		}
+		containers, err := c.Containers(ctx)
+		if err != nil {
+			return err
+		}
+		return printContainers(stdout, opts.output, containers)
+
+	case "check":
+		name, err := oneArg("name")
+		if err != nil {
+			return err
+		}
+		return action(c.Check(ctx, name))
+	case "update":
+		name, err := oneArg("name")
+		if err != nil {
+			return err
+		}
+		return action(c.Update(ctx, name))
+	case "approve":
+		key, err := oneArg("key")
+		if err != nil {
+			return err
+		}
+		return action(c.Approve(ctx, key))
+	case "reject":
+		key, err := oneArg("key")
+		if err != nil {
+			return err
+		}
+		return action(c.Reject(ctx, key))
+	case "rollback":
+		name, err := oneArg("name")
+		if err != nil {
+			return err
+		}
+		return action(c.Rollback(ctx, name))
+	case "scan":
+		if len(args) != 0 {
+			return usageError("usage: sentinel ctl scan")
+		}
+		return action(c.Scan(ctx))
+
+	case "history":
+		fs := flag.NewFlagSet("history", flag.ContinueOnError)
+		fs.SetOutput(stderr)
+		limit := fs.Int("limit", 20, "number of records, at most 200")
+		if err := fs.Parse(args); err != nil || fs.NArg() > 1 || *limit < 1 || *limit > 200 {
+			return usageError("usage: sentinel ctl history [--limit 1-200] [name]")
+		}
+		records, err := c.History(ctx, fs.Arg(0), *limit)
+		if err != nil {
+			return err
+		}
+		return printHistory(stdout, opts.output, records)
+	}
+	return usageError(fmt.Sprintf("unknown command %q; run \"sentinel ctl help\"", cmd))
+}
+
+func printJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func printResult(w io.Writer, output string, res Result) error {
+	if output == "json" {
+		return printJSON(w, res)
+	}
+	msg := res.Message
+	if msg == "" {
+		msg = res.Status
+	}
+	if len(res.NewerVersions) > 0 {
+		msg += " (" + strings.Join(res.NewerVersions, ", ") + ")"
+	}
+	_, err := fmt.Fprintln(w, msg)
+	return err
+}
+
+func printContainers(w io.Writer, output string, containers []Container) error {
+	if output == "json" {
+		return printJSON(w, containers)
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tIMAGE\tPOLICY\tSTATE\tVERSION\tCANDIDATE")
+	for _, c := range containers {
+		state := c.State
+		switch {
+		case c.Maintenance:
+			state += " (maintenance)"
+		case c.Failing:
+			state += " (update failing)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", c.Name, c.Image, c.Policy, state, dash(c.Version), dash(c.Candidate))
+	}
+	return tw.Flush()
+}
+
+func printQueue(w io.Writer, output string, queue []PendingUpdate) error {
+	if output == "json" {
+		return printJSON(w, queue)
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tIMAGE\tTARGET\tHOST\tDETECTED")
+	for _, p := range queue {
+		target := p.ResolvedTargetVersion
+		if len(p.NewerVersions) > 0 {
+			target = p.NewerVersions[0]
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", p.Key(), p.CurrentImage, dash(target), dash(p.HostName), p.DetectedAt.Local().Format(time.DateTime))
+	}
+	return tw.Flush()
+}
+
+func printHistory(w io.Writer, output string, records []HistoryRecord) error {
+	if output == "json" {
+		return printJSON(w, records)
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tCONTAINER\tOUTCOME\tFROM\tTO\tDURATION")
+	for _, r := range records {
+		outcome := r.Outcome
+		if r.Error != "" {
+			outcome += ": " + r.Error
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", r.Timestamp.Local().Format(time.DateTime), r.ContainerName, outcome, dash(r.OldImage), dash(r.NewImage), r.Duration.Round(time.Millisecond))
+	}
+	return tw.Flush()
+}
+
+// dash stands in for empty table cells.
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
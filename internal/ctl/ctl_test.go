@@ -0,0 +1,150 @@
+package ctl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeServer answers the API calls ctl makes and records each request.
+func fakeServer(t *testing.T) (*httptest.Server, *[]string) {
+	t.Helper()
+	var calls []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer stk_test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(map[string]any{"error": "authentication required", "code": "UNAUTHENTICATED", "message": "authentication required"})
+			return
+		}
+		calls = append(calls, r.Method+" "+r.URL.RequestURI())
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v1/containers":
+			_, _ = w.Write([]byte(`[{"name":"web","image":"nginx:1.25","policy":"manual","state":"running","current_version":"1.25.3","candidate_version":"1.27.0"}]`))
+		case "GET /api/v1/queue":
+			_, _ = w.Write([]byte(`[{"container_name":"db","host_id":"h1","host_name":"nas","current_image":"postgres:16","newer_versions":["17"]}]`))
+		case "GET /api/v1/history":
+			_, _ = w.Write([]byte(`[{"container_name":"web","outcome":"success","old_image":"nginx:1.24","new_image":"nginx:1.25"}]`))
+		case "GET /api/v1/containers/web":
+			_, _ = w.Write([]byte(`{"name":"web","history":[{"container_name":"web","outcome":"rollback"},{"container_name":"web","outcome":"success"}]}`))
+		case "POST /api/v1/approve/h1::db":
+			_, _ = w.Write([]byte(`{"status":"approved","name":"db","message":"update approved for db"}`))
+		case "POST /api/v1/update/missing":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"container not found: missing","code":"NOT_FOUND","message":"container not found: missing"}`))
+		default:
+			_, _ = w.Write([]byte(`{"message":"ok"}`))
+		}
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func run(t *testing.T, srv *httptest.Server, args ...string) (int, string, string) {
+	t.Helper()
+	env := map[string]string{"SENTINEL_URL": srv.URL, "SENTINEL_TOKEN": "stk_test"}
+	var stdout, stderr bytes.Buffer
+	code := Run(context.Background(), args, &stdout, &stderr, func(k string) string { return env[k] })
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRunList(t *testing.T) {
+	srv, _ := fakeServer(t)
+
+	code, out, errOut := run(t, srv, "list")
+	if code != 0 {
+		t.Fatalf("list: exit %d, stderr %q", code, errOut)
+	}
+	if !strings.HasPrefix(out, "NAME") || !strings.Contains(out, "web") || !strings.Contains(out, "1.27.0") {
+		t.Errorf("list table = %q", out)
+	}
+
+	code, out, _ = run(t, srv, "--output", "json", "list", "--pending")
+	if code != 0 {
+		t.Fatalf("list --pending: exit %d", code)
+	}
+	var queue []PendingUpdate
+	if err := json.Unmarshal([]byte(out), &queue); err != nil || len(queue) != 1 || queue[0].Key() != "h1::db" {
+		t.Errorf("queue json = %q (%v)", out, err)
+	}
+}
+
+func TestRunActions(t *testing.T) {
+	srv, calls := fakeServer(t)
+
+	for _, args := range [][]string{
+		{"check", "web"}, {"update", "web"}, {"approve", "h1::db"}, {"reject", "web"}, {"rollback", "web"}, {"scan"},
+	} {
+		if code, _, errOut := run(t, srv, args...); code != 0 {
+			t.Errorf("%v: exit %d, stderr %q", args, code, errOut)
+		}
+	}
+	want := []string{
+		"POST /api/v1/check/web",
+		"POST /api/v1/update/web",
+		"POST /api/v1/approve/h1::db",
+		"POST /api/v1/reject/web",
+		"POST /api/v1/containers/web/rollback",
+		"POST /api/v1/scan",
+	}
+	if strings.Join(*calls, "\n") != strings.Join(want, "\n") {
+		t.Errorf("calls = %q, want %q", *calls, want)
+	}
+
+	code, out, _ := run(t, srv, "approve", "h1::db")
+	if code != 0 || out != "update approved for db\n" {
+		t.Errorf("approve output = %q (exit %d)", out, code)
+	}
+
+	code, _, errOut := run(t, srv, "update", "missing")
+	if code != 1 || !strings.Contains(errOut, "container not found: missing (404 NOT_FOUND)") {
+		t.Errorf("API error: exit %d, stderr %q", code, errOut)
+	}
+}
+
+func TestRunHistory(t *testing.T) {
+	srv, calls := fakeServer(t)
+
+	if code, out, _ := run(t, srv, "history", "--limit", "5"); code != 0 || !strings.Contains(out, "nginx:1.24") {
+		t.Errorf("history: exit %d, out %q", code, out)
+	}
+	code, out, _ := run(t, srv, "-output", "json", "history", "--limit", "1", "web")
+	var records []HistoryRecord
+	if err := json.Unmarshal([]byte(out), &records); code != 0 || err != nil || len(records) != 1 || records[0].Outcome != "rollback" {
+		t.Errorf("history web = %q (exit %d, %v)", out, code, err)
+	}
+	if (*calls)[0] != "GET /api/v1/history?limit=5" {
+		t.Errorf("calls = %q", *calls)
+	}
+}
+
+func TestRunUsageErrors(t *testing.T) {
+	srv, calls := fakeServer(t)
+
+	for _, args := range [][]string{
+		{},
+		{"frobnicate"},
+		{"update"},
+		{"scan", "extra"},
+		{"--output", "yaml", "list"},
+		{"history", "--limit", "0"},
+	} {
+		if code, _, _ := run(t, srv, args...); code != 2 {
+			t.Errorf("%q: exit %d, want 2", args, code)
+		}
+	}
+	if len(*calls) != 0 {
+		t.Errorf("usage errors reached the server: %q", *calls)
+	}
+
+	var stderr bytes.Buffer
+	code := Run(context.Background(), []string{"--server", srv.URL, "list"}, &bytes.Buffer{}, &stderr, func(string) string { return "" })
+	if code != 2 || !strings.Contains(stderr.String(), "SENTINEL_TOKEN") {
+		t.Errorf("no token: exit %d, stderr %q", code, stderr.String())
+	}
+}
@@ -8,50 +8,129 @@ import (
 // ContainerInfo holds the minimal info needed to build the dependency graph.
 type ContainerInfo struct {
 	Name        string
+	ID          string // optional: resolves "container:<id>" network modes
+	Host        string // optional: keeps compose services of different hosts apart
 	Labels      map[string]string
-	NetworkMode string // from HostConfig.NetworkMode
+	NetworkMode string   // from HostConfig.NetworkMode
+	Names       []string // optional: every name Docker lists, including "/consumer/alias" link names
+}
+
+// Edge kinds: why one container depends on another.
+const (
+	KindLabel   = "label"   // sentinel.depends-on
+	KindCompose = "compose" // compose depends_on
+	KindLink    = "link"    // --link
+	KindNetwork = "network" // network_mode: container:<name>
+)
+
+// Edge is one dependency: From depends on To.
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind string `json:"kind"`
 }
 
 // Graph represents a directed acyclic graph of container dependencies.
 type Graph struct {
-	adj map[string][]string // container -> its dependencies (what it depends ON)
-	all map[string]bool     // all known container names
+	adj   map[string][]string  // container -> its dependencies (what it depends ON)
+	all   map[string]bool      // all known container names
+	kinds map[[2]string]string // {dependent, dependency} -> edge kind
 }
 
-// Build constructs the dependency graph from container info.
+// Build constructs the dependency graph from container info. Compose
+// depends_on entries name services, so they resolve to the containers of
+// that service in the same project, or to a container of that name.
 func Build(containers []ContainerInfo) *Graph {
 	g := &Graph{
-		adj: make(map[string][]string),
-		all: make(map[string]bool),
+		adj:   make(map[string][]string),
+		all:   make(map[string]bool),
+		kinds: make(map[[2]string]string),
 	}
 
+	ids := make(map[string]string)
+	services := make(map[string][]string) // host/project/service -> containers
 	for _, c := range containers {
 		g.all[c.Name] = true
+		if c.ID != "" {
+			ids[c.ID] = c.Name
+		}
+		if key := serviceKey(c.Host, c.Labels["com.docker.compose.project"], c.Labels["com.docker.compose.service"]); key != "" {
+			services[key] = append(services[key], c.Name)
+		}
 	}
 
 	for _, c := range containers {
-		var deps []string
-
 		// Label-based dependencies
-		for _, dep := range ParseDependsOn(c.Labels) {
-			if g.all[dep] {
-				deps = append(deps, dep)
+		for _, dep := range parseSentinelDependsOn(c.Labels) {
+			g.addEdge(c.Name, dep, KindLabel)
+		}
+		project := c.Labels["com.docker.compose.project"]
+		for _, svc := range parseComposeDependsOn(c.Labels) {
+			if members, ok := services[serviceKey(c.Host, project, svc)]; ok {
+				for _, m := range members {
+					g.addEdge(c.Name, m, KindCompose)
+				}
+			} else {
+				g.addEdge(c.Name, svc, KindCompose)
 			}
 		}
 
-		// Network namespace dependency
-		if netDep := ParseNetworkDependency(c.NetworkMode); netDep != "" && g.all[netDep] {
-			deps = append(deps, netDep)
+		// Legacy links are listed on the provider.
+		for _, name := range c.Names {
+			if consumer, ok := ParseLinkName(name); ok {
+				g.addEdge(consumer, c.Name, KindLink)
+			}
 		}
 
-		if len(deps) > 0 {
-			g.adj[c.Name] = deps
+		// Network namespace dependency
+		if netDep := ParseNetworkDependency(c.NetworkMode); netDep != "" {
+			if name, ok := ids[netDep]; ok {
+				netDep = name
+			}
+			g.addEdge(c.Name, netDep, KindNetwork)
 		}
 	}
 
 	return g
 }
 
+// serviceKey identifies a compose service, or "" outside compose.
+func serviceKey(host, project, service string) string {
+	if project == "" || service == "" {
+		return ""
+	}
+	return host + "/" + project + "/" + service
+}
+
+// addEdge records that from depends on to. Unknown containers, self
+// references and repeats are ignored; the first kind seen is kept.
+func (g *Graph) addEdge(from, to, kind string) {
+	if from == to || !g.all[from] || !g.all[to] {
+		return
+	}
+	key := [2]string{from, to}
+	if _, ok := g.kinds[key]; ok {
+		return
+	}
+	g.kinds[key] = kind
+	g.adj[from] = append(g.adj[from], to)
+}
+
+// Edges returns every dependency, sorted by dependent then dependency.
+func (g *Graph) Edges() []Edge {
+	edges := make([]Edge, 0, len(g.kinds))
+	for key, kind := range g.kinds {
+		edges = append(edges, Edge{From: key[0], To: key[1], Kind: kind})
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].From != edges[j].From {
+			return edges[i].From < edges[j].From
+		}
+		return edges[i].To < edges[j].To
+	})
+	return edges
+}
+
 // Sort returns container names in topological order (dependencies first) using Kahn's algorithm.
 // Returns error if cycles are detected.
 func (g *Graph) Sort() ([]string, error) {
@@ -175,3 +254,65 @@ func (g *Graph) Dependencies(name string) []string {
 	sort.Strings(result)
 	return result
 }
+
+// RestartOrder returns every container that depends on name, directly or
+// through others, ordered so each comes after the containers it relies on.
+// Containers caught in a cycle follow the rest in name order.
+func (g *Graph) RestartOrder(name string) []string {
+	affected := make(map[string]bool)
+	queue := []string{name}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, dep := range g.Dependents(node) {
+			if dep != name && !affected[dep] {
+				affected[dep] = true
+				queue = append(queue, dep)
+			}
+		}
+	}
+
+	// Kahn's algorithm over the affected containers only.
+	inDegree := make(map[string]int, len(affected))
+	for c := range affected {
+		for _, dep := range g.adj[c] {
+			if affected[dep] {
+				inDegree[c]++
+			}
+		}
+	}
+	var ready []string
+	for c := range affected {
+		if inDegree[c] == 0 {
+			ready = append(ready, c)
+		}
+	}
+	sort.Strings(ready)
+
+	order := make([]string, 0, len(affected))
+	done := make(map[string]bool, len(affected))
+	for len(ready) > 0 {
+		node := ready[0]
+		ready = ready[1:]
+		order = append(order, node)
+		done[node] = true
+		for _, dep := range g.Dependents(node) {
+			if !affected[dep] {
+				continue
+			}
+			inDegree[dep]--
+			if inDegree[dep] == 0 {
+				ready = append(ready, dep)
+			}
+		}
+	}
+
+	var rest []string
+	for c := range affected {
+		if !done[c] {
+			rest = append(rest, c)
+		}
+	}
+	sort.Strings(rest)
+	return append(order, rest...)
+}
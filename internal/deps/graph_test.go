@@ -1,6 +1,7 @@
 package deps
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -145,3 +146,51 @@ func TestUnknownDepsIgnored(t *testing.T) {
 		t.Errorf("expected [app], got %v", order)
 	}
 }
+
+func TestComposeServicesAndLinks(t *testing.T) {
+	compose := func(project, service, dependsOn string) map[string]string {
+		return map[string]string{
+			"com.docker.compose.project":    project,
+			"com.docker.compose.service":    service,
+			"com.docker.compose.depends_on": dependsOn,
+		}
+	}
+	containers := []ContainerInfo{
+		{Name: "shop-db-1", Labels: compose("shop", "db", "")},
+		{Name: "shop-api-1", Labels: compose("shop", "api", "db:service_healthy:false")},
+		{Name: "blog-api-1", Labels: compose("blog", "api", "db:service_started:false")},
+		{Name: "legacy-db", Names: []string{"/legacy-db", "/legacy-app/db"}},
+		{Name: "legacy-app"},
+		{Name: "vpn", ID: "abc123"},
+		{Name: "torrent", NetworkMode: "container:abc123"},
+	}
+
+	g := Build(containers)
+	want := []Edge{
+		{From: "legacy-app", To: "legacy-db", Kind: KindLink},
+		{From: "shop-api-1", To: "shop-db-1", Kind: KindCompose},
+		{From: "torrent", To: "vpn", Kind: KindNetwork},
+	}
+	if got := g.Edges(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Edges() = %v, want %v", got, want)
+	}
+}
+
+func TestRestartOrder(t *testing.T) {
+	containers := []ContainerInfo{
+		{Name: "db"},
+		{Name: "api", Labels: map[string]string{"sentinel.depends-on": "db"}},
+		{Name: "worker", Labels: map[string]string{"sentinel.depends-on": "api,db"}},
+		{Name: "proxy", Labels: map[string]string{"sentinel.depends-on": "api"}},
+		{Name: "other"},
+	}
+
+	got := Build(containers).RestartOrder("db")
+	want := []string{"api", "proxy", "worker"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RestartOrder(db) = %v, want %v", got, want)
+	}
+	if got := Build(containers).RestartOrder("proxy"); len(got) != 0 {
+		t.Errorf("RestartOrder(proxy) = %v, want none", got)
+	}
+}
@@ -9,8 +9,12 @@ import (
 //   - sentinel.depends-on: "container1,container2"
 //   - com.docker.compose.depends_on: "svc1:service_started:true,svc2:service_healthy:true"
 func ParseDependsOn(labels map[string]string) []string {
-	var deps []string
+	return append(parseSentinelDependsOn(labels), parseComposeDependsOn(labels)...)
+}
 
+// parseSentinelDependsOn returns the container names in sentinel.depends-on.
+func parseSentinelDependsOn(labels map[string]string) []string {
+	var deps []string
 	if v, ok := labels["sentinel.depends-on"]; ok && v != "" {
 		for _, name := range strings.Split(v, ",") {
 			if trimmed := strings.TrimSpace(name); trimmed != "" {
@@ -18,7 +22,13 @@ func ParseDependsOn(labels map[string]string) []string {
 			}
 		}
 	}
+	return deps
+}
 
+// parseComposeDependsOn returns the service names in
+// com.docker.compose.depends_on.
+func parseComposeDependsOn(labels map[string]string) []string {
+	var deps []string
 	if v, ok := labels["com.docker.compose.depends_on"]; ok && v != "" {
 		for _, entry := range strings.Split(v, ",") {
 			// Format: "service_name:condition:restart" or just "service_name"
@@ -28,7 +38,37 @@ func ParseDependsOn(labels map[string]string) []string {
 			}
 		}
 	}
+	return deps
+}
+
+// ClusterDependency is a dependency on a container running on another
+// cluster host.
+type ClusterDependency struct {
+	Host      string // host name or ID
+	Container string
+}
+
+// String returns the dependency in its label form, "host/container".
+func (d ClusterDependency) String() string {
+	return d.Host + "/" + d.Container
+}
 
+// ParseClusterDependsOn extracts cross-host dependencies from the
+// sentinel.cluster-depends-on label: "db-host/postgres,cache-host/redis".
+// Entries without both a host and a container are ignored.
+func ParseClusterDependsOn(labels map[string]string) []ClusterDependency {
+	v := labels["sentinel.cluster-depends-on"]
+	if v == "" {
+		return nil
+	}
+	var deps []ClusterDependency
+	for _, entry := range strings.Split(v, ",") {
+		host, name, ok := strings.Cut(strings.TrimSpace(entry), "/")
+		host, name = strings.TrimSpace(host), strings.TrimSpace(name)
+		if ok && host != "" && name != "" {
+			deps = append(deps, ClusterDependency{Host: host, Container: name})
+		}
+	}
 	return deps
 }
 
@@ -39,3 +79,13 @@ func ParseNetworkDependency(networkMode string) string {
 	}
 	return ""
 }
+
+// ParseLinkName extracts the consumer from a legacy link name. Docker lists a
+// container linked with --link under an extra "/consumer/alias" name.
+func ParseLinkName(name string) (consumer string, ok bool) {
+	consumer, alias, ok := strings.Cut(strings.TrimPrefix(name, "/"), "/")
+	if !ok || consumer == "" || alias == "" {
+		return "", false
+	}
+	return consumer, true
+}
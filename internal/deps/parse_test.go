@@ -38,6 +38,23 @@ func TestParseBothLabels(t *testing.T) {
 	}
 }
 
+func TestParseClusterDependsOn(t *testing.T) {
+	labels := map[string]string{
+		"sentinel.cluster-depends-on": "db-host/postgres, cache-host / redis,no-host,/missing,orphan/",
+	}
+	deps := ParseClusterDependsOn(labels)
+	expected := []ClusterDependency{
+		{Host: "db-host", Container: "postgres"},
+		{Host: "cache-host", Container: "redis"},
+	}
+	if !reflect.DeepEqual(deps, expected) {
+		t.Errorf("got %v, want %v", deps, expected)
+	}
+	if got := ParseClusterDependsOn(map[string]string{}); got != nil {
+		t.Errorf("empty labels: got %v, want nil", got)
+	}
+}
+
 func TestParseNetworkDependency(t *testing.T) {
 	tests := []struct {
 		mode string
@@ -62,3 +79,14 @@ func TestParseEmptyLabels(t *testing.T) {
 		t.Errorf("expected no deps, got %v", deps)
 	}
 }
+
+func TestParseLinkName(t *testing.T) {
+	if consumer, ok := ParseLinkName("/web/db"); !ok || consumer != "web" {
+		t.Errorf("ParseLinkName(/web/db) = %q, %v; want web", consumer, ok)
+	}
+	for _, name := range []string{"/db", "db", "/web/"} {
+		if _, ok := ParseLinkName(name); ok {
+			t.Errorf("ParseLinkName(%q) matched, want no link", name)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/client"
+)
+
+// Operation is a class of Docker API calls that a socket proxy can allow or
+// deny independently (e.g. Tecnativa's CONTAINERS, IMAGES, POST, EXEC).
+type Operation string
+
+const (
+	OpList        Operation = "list"
+	OpPull        Operation = "pull"
+	OpCreate      Operation = "create"
+	OpStart       Operation = "start"
+	OpStop        Operation = "stop"
+	OpRemove      Operation = "remove"
+	OpRename      Operation = "rename"
+	OpExec        Operation = "exec"
+	OpRemoveImage Operation = "remove_image"
+)
+
+// UpdateOperations are the calls a recreate update makes. Missing any of
+// them means an update could fail after the old container is gone.
+var UpdateOperations = []Operation{OpPull, OpStop, OpRemove, OpCreate, OpStart}
+
+// ErrOperationDenied is returned when a pre-flight check finds the Docker
+// API will refuse an operation the update needs.
+var ErrOperationDenied = errors.New("docker operation not permitted")
+
+// probeName is a container/image name that never exists, so probes reach
+// the API (and any proxy in front of it) without touching real resources.
+const probeName = "sentinel-permission-probe-0000000000"
+
+// Capabilities is the set of operations the daemon connection permits.
+type Capabilities struct {
+	Denied    map[Operation]string `json:"denied,omitempty"` // operation → error returned by the proxy
+	CheckedAt time.Time            `json:"checked_at"`
+}
+
+// Missing returns which of ops are denied, in the order given.
+func (c Capabilities) Missing(ops ...Operation) []Operation {
+	var missing []Operation
+	for _, op := range ops {
+		if _, denied := c.Denied[op]; denied {
+			missing = append(missing, op)
+		}
+	}
+	return missing
+}
+
+// Reason explains why ops cannot run, or returns "" if all are permitted.
+func (c Capabilities) Reason(ops ...Operation) string {
+	missing := c.Missing(ops...)
+	if len(missing) == 0 {
+		return ""
+	}
+	names := make([]string, len(missing))
+	for i, op := range missing {
+		names[i] = string(op)
+	}
+	return "Docker API denies " + strings.Join(names, ", ") + " (check socket proxy permissions)"
+}
+
+// CheckOperations returns an error wrapping ErrOperationDenied if any of ops
+// is denied.
+func (c Capabilities) CheckOperations(ops ...Operation) error {
+	if reason := c.Reason(ops...); reason != "" {
+		return fmt.Errorf("%w: %s", ErrOperationDenied, reason)
+	}
+	return nil
+}
+
+// ProbeCapabilities checks which operations the daemon connection permits.
+// Each probe targets a resource that does not exist: a daemon answers 404
+// or 400, while a socket proxy that forbids the endpoint answers 403.
+func (c *Client) ProbeCapabilities(ctx context.Context) Capabilities {
+	timeout := 10
+	return probeCapabilities(ctx, map[Operation]func(context.Context) error{
+		OpList: func(ctx context.Context) error {
+			_, err := c.api.ContainerList(ctx, client.ContainerListOptions{Limit: 1})
+			return err
+		},
+		OpPull: func(ctx context.Context) error {
+			// localhost:1 refuses connections, so the daemon fails fast.
+			resp, err := c.api.ImagePull(ctx, "localhost:1/"+probeName+":probe", client.ImagePullOptions{})
+			if err == nil {
+				resp.Close()
+			}
+			return err
+		},
+		OpCreate: func(ctx context.Context) error {
+			_, err := c.api.ContainerCreate(ctx, client.ContainerCreateOptions{
+				Name:   probeName,
+				Config: &container.Config{Image: "localhost:1/" + probeName + ":probe"},
+			})
+			return err
+		},
+		OpStart: func(ctx context.Context) error {
+			_, err := c.api.ContainerStart(ctx, probeName, client.ContainerStartOptions{})
+			return err
+		},
+		OpStop: func(ctx context.Context) error {
+			_, err := c.api.ContainerStop(ctx, probeName, client.ContainerStopOptions{Timeout: &timeout})
+			return err
+		},
+		OpRemove: func(ctx context.Context) error {
+			_, err := c.api.ContainerRemove(ctx, probeName, client.ContainerRemoveOptions{})
+			return err
+		},
+		OpRename: func(ctx context.Context) error {
+			_, err := c.api.ContainerRename(ctx, probeName, client.ContainerRenameOptions{NewName: probeName + "-renamed"})
+			return err
+		},
+		OpExec: func(ctx context.Context) error {
+			_, err := c.api.ExecCreate(ctx, probeName, client.ExecCreateOptions{Cmd: []string{"true"}})
+			return err
+		},
+		OpRemoveImage: func(ctx context.Context) error {
+			_, err := c.api.ImageRemove(ctx, "localhost:1/"+probeName+":probe", client.ImageRemoveOptions{})
+			return err
+		},
+	})
+}
+
+// probeCapabilities runs each probe and records the ones refused with a
+// permission error. Other failures (not found, bad request, timeouts) say
+// nothing about permissions, so the operation is assumed allowed.
+func probeCapabilities(ctx context.Context, probes map[Operation]func(context.Context) error) Capabilities {
+	caps := Capabilities{Denied: make(map[Operation]string), CheckedAt: time.Now()}
+	ops := make([]Operation, 0, len(probes))
+	for op := range probes {
+		ops = append(ops, op)
+	}
+	sort.Slice(ops, func(i, j int) bool { return ops[i] < ops[j] })
+	for _, op := range ops {
+		pctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		err := probes[op](pctx)
+		cancel()
+		if IsPermissionDenied(err) {
+			caps.Denied[op] = err.Error()
+		}
+	}
+	return caps
+}
+
+// IsPermissionDenied reports whether err is a 403 from the daemon or a
+// socket proxy in front of it.
+func IsPermissionDenied(err error) bool {
+	return err != nil && cerrdefs.IsPermissionDenied(err)
+}
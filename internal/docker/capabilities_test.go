@@ -0,0 +1,31 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	cerrdefs "github.com/containerd/errdefs"
+)
+
+func TestProbeCapabilities(t *testing.T) {
+	caps := probeCapabilities(context.Background(), map[Operation]func(context.Context) error{
+		OpList:   func(context.Context) error { return nil },
+		OpCreate: func(context.Context) error { return cerrdefs.ErrPermissionDenied.WithMessage("403 Forbidden") },
+		OpRemove: func(context.Context) error { return cerrdefs.ErrNotFound.WithMessage("No such container") },
+		OpPull:   func(context.Context) error { return errors.New("connection reset") },
+	})
+
+	if got := caps.Missing(UpdateOperations...); len(got) != 1 || got[0] != OpCreate {
+		t.Errorf("Missing = %v, want [create]", got)
+	}
+	if err := caps.CheckOperations(UpdateOperations...); !errors.Is(err, ErrOperationDenied) {
+		t.Errorf("CheckOperations = %v, want ErrOperationDenied", err)
+	}
+	if err := caps.CheckOperations(OpList, OpPull); err != nil {
+		t.Errorf("CheckOperations(list, pull) = %v, want nil", err)
+	}
+	if caps.Reason(OpCreate) == "" {
+		t.Error("Reason(create) is empty")
+	}
+}
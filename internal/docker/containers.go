@@ -70,6 +70,16 @@ func (c *Client) CreateContainer(ctx context.Context, name string, cfg *containe
 	return resp.ID, nil
 }
 
+// CopyFromContainer returns a tar archive of path inside the container's
+// filesystem. The container does not need to be running.
+func (c *Client) CopyFromContainer(ctx context.Context, id, path string) (io.ReadCloser, error) {
+	result, err := c.api.CopyFromContainer(ctx, id, client.CopyFromContainerOptions{SourcePath: path})
+	if err != nil {
+		return nil, err
+	}
+	return result.Content, nil
+}
+
 // StartContainer starts a stopped container.
 func (c *Client) StartContainer(ctx context.Context, id string) error {
 	_, err := c.api.ContainerStart(ctx, id, client.ContainerStartOptions{})
@@ -82,6 +92,25 @@ func (c *Client) RestartContainer(ctx context.Context, id string) error {
 	return err
 }
 
+// PauseContainer freezes all processes in a running container.
+func (c *Client) PauseContainer(ctx context.Context, id string) error {
+	_, err := c.api.ContainerPause(ctx, id, client.ContainerPauseOptions{})
+	return err
+}
+
+// UnpauseContainer resumes a paused container.
+func (c *Client) UnpauseContainer(ctx context.Context, id string) error {
+	_, err := c.api.ContainerUnpause(ctx, id, client.ContainerUnpauseOptions{})
+	return err
+}
+
+// KillContainer sends a signal to a container's main process. An empty
+// signal sends SIGKILL.
+func (c *Client) KillContainer(ctx context.Context, id, signal string) error {
+	_, err := c.api.ContainerKill(ctx, id, client.ContainerKillOptions{Signal: signal})
+	return err
+}
+
 // RenameContainer changes the name of a container.
 func (c *Client) RenameContainer(ctx context.Context, id string, newName string) error {
 	_, err := c.api.ContainerRename(ctx, id, client.ContainerRenameOptions{
@@ -129,6 +158,27 @@ func (c *Client) ImageID(ctx context.Context, imageRef string) (string, error) {
 	return resp.ID, nil
 }
 
+// ImageCreated returns when a local image was built and its first repo
+// digest, if it was pulled from a registry.
+func (c *Client) ImageCreated(ctx context.Context, imageRef string) (time.Time, string, error) {
+	resp, err := c.api.ImageInspect(ctx, imageRef)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+	var digest string
+	if len(resp.RepoDigests) > 0 {
+		digest = resp.RepoDigests[0]
+	}
+	if resp.Created == "" {
+		return time.Time{}, digest, nil
+	}
+	created, err := time.Parse(time.RFC3339Nano, resp.Created)
+	if err != nil {
+		return time.Time{}, digest, fmt.Errorf("parse image created time: %w", err)
+	}
+	return created, digest, nil
+}
+
 // DistributionDigest queries the registry for the current digest of an image
 // reference, using the daemon's configured credentials.
 func (c *Client) DistributionDigest(ctx context.Context, imageRef string) (string, error) {
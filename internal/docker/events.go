@@ -0,0 +1,59 @@
+package docker
+
+import (
+	"context"
+	"time"
+
+	"github.com/moby/moby/api/types/events"
+	"github.com/moby/moby/client"
+)
+
+// ContainerEvent is a container lifecycle event from the Docker daemon.
+type ContainerEvent struct {
+	Action string // "start" or "destroy"
+	ID     string
+	Name   string
+	Image  string
+	Labels map[string]string
+	Time   time.Time
+}
+
+// WatchContainerEvents streams container start and destroy events until ctx
+// is cancelled or the stream fails, at which point an error is sent and both
+// channels are closed. Callers reconnect by calling it again.
+func (c *Client) WatchContainerEvents(ctx context.Context) (<-chan ContainerEvent, <-chan error) {
+	out := make(chan ContainerEvent)
+	errs := make(chan error, 1)
+	res := c.api.Events(ctx, client.EventsListOptions{
+		Filters: make(client.Filters).
+			Add("type", string(events.ContainerEventType)).
+			Add("event", string(events.ActionStart), string(events.ActionDestroy)),
+	})
+	go func() {
+		defer close(out)
+		defer close(errs)
+		for {
+			select {
+			case msg := <-res.Messages:
+				evt := ContainerEvent{
+					Action: string(msg.Action),
+					ID:     msg.Actor.ID,
+					Name:   msg.Actor.Attributes["name"],
+					Image:  msg.Actor.Attributes["image"],
+					Labels: msg.Actor.Attributes, // container labels plus name/image
+					Time:   time.Unix(0, msg.TimeNano),
+				}
+				select {
+				case out <- evt:
+				case <-ctx.Done():
+					errs <- ctx.Err()
+					return
+				}
+			case err := <-res.Err:
+				errs <- err
+				return
+			}
+		}
+	}()
+	return out, errs
+}
@@ -79,6 +79,25 @@ func ContainerPullOnly(labels map[string]string) bool {
 	return strings.EqualFold(labels["sentinel.pull-only"], "true")
 }
 
+// ContainerTrackTag returns true when the container has sentinel.track-tag=true.
+// Images referenced as "repo:tag@sha256:..." are treated as pinned unless this
+// is set, in which case the tag is checked for a new digest or version and
+// the container is recreated from the tag without the digest.
+func ContainerTrackTag(labels map[string]string) bool {
+	return strings.EqualFold(labels["sentinel.track-tag"], "true")
+}
+
+// ContainerStrategy returns the lower-cased sentinel.strategy label value
+// (e.g. "blue-green"), or empty string if not set. "bluegreen" is accepted
+// as a spelling of "blue-green".
+func ContainerStrategy(labels map[string]string) string {
+	v := strings.ToLower(strings.TrimSpace(labels["sentinel.strategy"]))
+	if v == "bluegreen" {
+		return "blue-green"
+	}
+	return v
+}
+
 // ContainerRemoveVolumes returns true when the container has sentinel.remove-volumes=true.
 func ContainerRemoveVolumes(labels map[string]string) bool {
 	return strings.EqualFold(labels["sentinel.remove-volumes"], "true")
@@ -99,6 +118,30 @@ func ContainerNotifySnooze(labels map[string]string) time.Duration {
 	return d
 }
 
+// ContainerNotifyMode returns the lower-cased sentinel.notify label value when
+// it names a notification mode (default, every_scan, digest_only, muted),
+// or empty string if the label is absent or invalid.
+func ContainerNotifyMode(labels map[string]string) string {
+	v := strings.ToLower(strings.TrimSpace(labels["sentinel.notify"]))
+	switch v {
+	case "default", "every_scan", "digest_only", "muted":
+		return v
+	}
+	return ""
+}
+
+// ContainerNotifyChannels returns the channel names or IDs listed in the
+// comma-separated sentinel.channels label, or nil if not set.
+func ContainerNotifyChannels(labels map[string]string) []string {
+	var out []string
+	for _, v := range strings.Split(labels["sentinel.channels"], ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
 // ContainerUpdateDelay reads the sentinel.delay label and returns the minimum
 // age an update must have been tracked before it is applied. Returns 0 if the
 // label is absent or invalid.
@@ -136,6 +179,160 @@ func ContainerGracePeriod(labels map[string]string) time.Duration {
 	return d
 }
 
+// ContainerHealthTimeout reads the sentinel.health-timeout label: how long
+// validation waits for a healthcheck or probe to pass before rolling back.
+// Returns 0 if the label is absent or invalid. Caps at 1 hour.
+func ContainerHealthTimeout(labels map[string]string) time.Duration {
+	v := strings.TrimSpace(labels["sentinel.health-timeout"])
+	if v == "" {
+		return 0
+	}
+	d, err := ParseDurationWithDays(v)
+	if err != nil || d <= 0 {
+		return 0
+	}
+	return min(d, time.Hour)
+}
+
+// ContainerValidation reads the sentinel.validate label: "state",
+// "healthcheck" or "probe". Returns "" (automatic) when absent or invalid.
+func ContainerValidation(labels map[string]string) string {
+	switch v := strings.ToLower(strings.TrimSpace(labels["sentinel.validate"])); v {
+	case "state", "healthcheck", "probe":
+		return v
+	default:
+		return ""
+	}
+}
+
+// ContainerProbe reads the sentinel.probe label: the URL probed when the
+// validation strategy is "probe", e.g. "http://:8080/healthz" or "tcp://:5432".
+// An empty host means the container's own IP address.
+func ContainerProbe(labels map[string]string) string {
+	return strings.TrimSpace(labels["sentinel.probe"])
+}
+
+// ContainerProbeStatus reads the sentinel.probe-status label: the exact HTTP
+// status an HTTP probe must return, e.g. "200". Returns 0 (any status below
+// 400) when absent or not a valid status code.
+func ContainerProbeStatus(labels map[string]string) int {
+	n, err := strconv.Atoi(strings.TrimSpace(labels["sentinel.probe-status"]))
+	if err != nil || n < 100 || n > 599 {
+		return 0
+	}
+	return n
+}
+
+// ContainerProbeBody reads the sentinel.probe-body label: a substring the
+// HTTP probe's response body must contain, e.g. "healthy".
+func ContainerProbeBody(labels map[string]string) string {
+	return labels["sentinel.probe-body"]
+}
+
+// ContainerWaitFor reads the sentinel.wait-for label: a comma-separated list
+// of conditions that must hold before the container is updated, e.g.
+// "path:/mnt/media,tcp://db:5432,http://nas.lan/health". Returns nil when absent.
+func ContainerWaitFor(labels map[string]string) []string {
+	var out []string
+	for _, c := range strings.Split(labels["sentinel.wait-for"], ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// ContainerWaitForAction reads the sentinel.wait-for-action label: "defer"
+// or "skip". Returns "" (defer) when absent or invalid.
+func ContainerWaitForAction(labels map[string]string) string {
+	switch v := strings.ToLower(strings.TrimSpace(labels["sentinel.wait-for-action"])); v {
+	case "defer", "skip":
+		return v
+	default:
+		return ""
+	}
+}
+
+// ContainerConfigDiff reads the sentinel.config-diff label. set is false when
+// the label is absent or not a boolean, leaving the global setting in charge.
+func ContainerConfigDiff(labels map[string]string) (enabled, set bool) {
+	switch strings.ToLower(strings.TrimSpace(labels["sentinel.config-diff"])) {
+	case "true":
+		return true, true
+	case "false":
+		return false, true
+	}
+	return false, false
+}
+
+// ContainerConfigPaths reads the sentinel.config-paths label: a comma-separated
+// list of absolute paths inside the image to compare on update, e.g.
+// "/etc/nginx,/usr/share/nginx/html". Returns nil when absent.
+func ContainerConfigPaths(labels map[string]string) []string {
+	var out []string
+	for _, p := range strings.Split(labels["sentinel.config-paths"], ",") {
+		if p = strings.TrimSpace(p); strings.HasPrefix(p, "/") {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// ContainerBaseImage returns the base image a locally built container was
+// built FROM and, when the build recorded it, the digest it was built
+// against. Docker merges image labels into the container's, so both the
+// OCI org.opencontainers.image.base.* labels set at build time and a
+// sentinel.base-image label on the container are seen here; the latter wins.
+func ContainerBaseImage(labels map[string]string) (ref, digest string) {
+	ociRef := strings.TrimSpace(labels["org.opencontainers.image.base.name"])
+	ociDigest := strings.TrimSpace(labels["org.opencontainers.image.base.digest"])
+	if v := strings.TrimSpace(labels["sentinel.base-image"]); v != "" {
+		if v != ociRef {
+			return v, ""
+		}
+	}
+	if ociRef == "" {
+		return "", ""
+	}
+	return ociRef, ociDigest
+}
+
+// ContainerRebuildWebhook reads the sentinel.rebuild-webhook label. Only
+// "off" has an effect: it skips the global rebuild webhook for the
+// container.
+func ContainerRebuildWebhook(labels map[string]string) string {
+	return strings.TrimSpace(labels["sentinel.rebuild-webhook"])
+}
+
+// ContainerValidateWebhook reads the sentinel.validate-webhook label. Only
+// "off" has an effect: it skips the global validation webhook for the
+// container.
+func ContainerValidateWebhook(labels map[string]string) string {
+	return strings.TrimSpace(labels["sentinel.validate-webhook"])
+}
+
+// ContainerRolloutGroup reads the sentinel.rollout-group label: the staged
+// rollout group the container belongs to, e.g. "canary" or "prod". Empty
+// when the container is not part of a staged rollout.
+func ContainerRolloutGroup(labels map[string]string) string {
+	return strings.ToLower(strings.TrimSpace(labels["sentinel.rollout-group"]))
+}
+
+// ContainerCheckImage reads the sentinel.check-image label: the repository
+// update checks should follow instead of the one the container runs, e.g.
+// "docker.io/library/nginx" for an internal mirror of nginx. Empty when the
+// container is checked against its own image.
+func ContainerCheckImage(labels map[string]string) string {
+	return strings.TrimSpace(labels["sentinel.check-image"])
+}
+
+// ContainerWindow reads the sentinel.window label: a maintenance window
+// expression that replaces the global and stack windows for this container,
+// or "any" to allow auto-updates at any time. Empty when not set.
+func ContainerWindow(labels map[string]string) string {
+	return strings.TrimSpace(labels["sentinel.window"])
+}
+
 // SemverScope controls the version range considered when finding newer versions.
 type SemverScope string
 
@@ -147,28 +344,52 @@ const (
 	ScopeMajor   SemverScope = "major"  // any newer version
 )
 
+// IsConstraint reports whether the scope is a semver range such as "~1.27"
+// or ">=2 <3" rather than one of the named scopes. Ranges are parsed and
+// evaluated by the registry package.
+func (s SemverScope) IsConstraint() bool {
+	switch s {
+	case ScopeDefault, ScopeStrict, ScopePatch, ScopeMinor, ScopeMajor:
+		return false
+	}
+	return strings.ContainsAny(string(s), "0123456789")
+}
+
 // ContainerTagFilters reads sentinel.include-tags and sentinel.exclude-tags labels.
 func ContainerTagFilters(labels map[string]string) (include, exclude string) {
 	return labels["sentinel.include-tags"], labels["sentinel.exclude-tags"]
 }
 
 // ContainerSemverScope reads the sentinel.semver label and returns the
-// explicit version scope. Returns ScopeDefault if the label is absent or invalid.
+// explicit version scope: a named scope ("patch-only" and "minor-only" are
+// accepted as aliases) or a semver range such as "~1.27" or ">=2 <3".
+// Returns ScopeDefault if the label is absent or invalid.
 func ContainerSemverScope(labels map[string]string) SemverScope {
-	switch strings.ToLower(labels["sentinel.semver"]) {
-	case "patch":
+	v := strings.TrimSpace(labels["sentinel.semver"])
+	switch strings.ToLower(v) {
+	case "patch", "patch-only":
 		return ScopePatch
-	case "minor":
+	case "minor", "minor-only":
 		return ScopeMinor
 	case "major", "all":
 		return ScopeMajor
-	default:
-		return ScopeDefault
 	}
+	if scope := SemverScope(v); scope.IsConstraint() {
+		return scope
+	}
+	return ScopeDefault
 }
 
-// ParseDurationWithDays extends time.ParseDuration with a "d" suffix for days.
+// ParseDurationWithDays extends time.ParseDuration with "d" and "w" suffixes
+// for days and weeks.
 func ParseDurationWithDays(s string) (time.Duration, error) {
+	if weeks, ok := strings.CutSuffix(s, "w"); ok {
+		n, err := strconv.Atoi(weeks)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n) * 7 * 24 * time.Hour, nil
+	}
 	if strings.HasSuffix(s, "d") {
 		days := strings.TrimSuffix(s, "d")
 		n, err := strconv.Atoi(days)
@@ -1,6 +1,7 @@
 package docker
 
 import (
+	"slices"
 	"testing"
 	"time"
 )
@@ -49,6 +50,10 @@ func TestContainerSemverScope(t *testing.T) {
 		{"all alias", map[string]string{"sentinel.semver": "all"}, ScopeMajor},
 		{"case insensitive", map[string]string{"sentinel.semver": "MINOR"}, ScopeMinor},
 		{"invalid falls back", map[string]string{"sentinel.semver": "yolo"}, ScopeDefault},
+		{"patch-only alias", map[string]string{"sentinel.semver": "patch-only"}, ScopePatch},
+		{"minor-only alias", map[string]string{"sentinel.semver": "Minor-Only"}, ScopeMinor},
+		{"tilde range", map[string]string{"sentinel.semver": " ~1.27 "}, SemverScope("~1.27")},
+		{"compound range", map[string]string{"sentinel.semver": ">=2 <3"}, SemverScope(">=2 <3")},
 	}
 
 	for _, tt := range tests {
@@ -87,6 +92,25 @@ func TestContainerGracePeriod(t *testing.T) {
 	}
 }
 
+func TestContainerHealthTimeout(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"90s", 90 * time.Second},
+		{"5m", 5 * time.Minute},
+		{"2h", time.Hour},
+		{"abc", 0},
+		{"-5s", 0},
+	}
+	for _, tt := range tests {
+		if got := ContainerHealthTimeout(map[string]string{"sentinel.health-timeout": tt.value}); got != tt.want {
+			t.Errorf("ContainerHealthTimeout(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
 func TestIsLocalImage(t *testing.T) {
 	tests := []struct {
 		imageRef string
@@ -218,6 +242,33 @@ func TestContainerNotifySnooze(t *testing.T) {
 	}
 }
 
+func TestContainerNotifyLabels(t *testing.T) {
+	tests := []struct {
+		name     string
+		labels   map[string]string
+		mode     string
+		channels []string
+	}{
+		{"no labels", map[string]string{}, "", nil},
+		{"digest only", map[string]string{"sentinel.notify": "digest_only"}, "digest_only", nil},
+		{"mixed case", map[string]string{"sentinel.notify": " Muted "}, "muted", nil},
+		{"invalid mode", map[string]string{"sentinel.notify": "loud"}, "", nil},
+		{"channels", map[string]string{"sentinel.channels": "oncall, Team Slack,,"}, "", []string{"oncall", "Team Slack"}},
+		{"empty channels", map[string]string{"sentinel.channels": " "}, "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ContainerNotifyMode(tt.labels); got != tt.mode {
+				t.Errorf("ContainerNotifyMode() = %q, want %q", got, tt.mode)
+			}
+			if got := ContainerNotifyChannels(tt.labels); !slices.Equal(got, tt.channels) {
+				t.Errorf("ContainerNotifyChannels() = %q, want %q", got, tt.channels)
+			}
+		})
+	}
+}
+
 func TestContainerUpdateDelay(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -296,6 +347,8 @@ func TestParseDurationWithDays(t *testing.T) {
 		{"empty string", "", 0, true},
 		{"invalid string", "abc", 0, true},
 		{"invalid day prefix", "xd", 0, true},
+		{"two weeks", "2w", 14 * 24 * time.Hour, false},
+		{"non-integer weeks", "1.5w", 0, true},
 		{"negative days", "-1d", -24 * time.Hour, false},
 		{"negative standard", "-5m", -5 * time.Minute, false},
 	}
@@ -313,3 +366,83 @@ func TestParseDurationWithDays(t *testing.T) {
 		})
 	}
 }
+
+func TestContainerValidation(t *testing.T) {
+	tests := map[string]string{
+		"state":       "state",
+		" Probe ":     "probe",
+		"healthcheck": "healthcheck",
+		"bogus":       "",
+		"":            "",
+	}
+	for in, want := range tests {
+		if got := ContainerValidation(map[string]string{"sentinel.validate": in}); got != want {
+			t.Errorf("ContainerValidation(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if got := ContainerProbe(map[string]string{"sentinel.probe": " tcp://:5432 "}); got != "tcp://:5432" {
+		t.Errorf("ContainerProbe = %q, want tcp://:5432", got)
+	}
+	for in, want := range map[string]int{"200": 200, " 204 ": 204, "99": 0, "600": 0, "ok": 0, "": 0} {
+		if got := ContainerProbeStatus(map[string]string{"sentinel.probe-status": in}); got != want {
+			t.Errorf("ContainerProbeStatus(%q) = %d, want %d", in, got, want)
+		}
+	}
+	if got := ContainerProbeBody(map[string]string{"sentinel.probe-body": `"status":"ok"`}); got != `"status":"ok"` {
+		t.Errorf("ContainerProbeBody = %q", got)
+	}
+}
+
+func TestContainerWaitFor(t *testing.T) {
+	got := ContainerWaitFor(map[string]string{"sentinel.wait-for": " path:/mnt/media, ,tcp://db:5432 "})
+	if len(got) != 2 || got[0] != "path:/mnt/media" || got[1] != "tcp://db:5432" {
+		t.Errorf("ContainerWaitFor = %q", got)
+	}
+	if got := ContainerWaitFor(nil); got != nil {
+		t.Errorf("ContainerWaitFor(nil) = %q, want nil", got)
+	}
+	for in, want := range map[string]string{"Skip": "skip", "defer": "defer", "later": "", "": ""} {
+		if got := ContainerWaitForAction(map[string]string{"sentinel.wait-for-action": in}); got != want {
+			t.Errorf("ContainerWaitForAction(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestContainerConfigDiff(t *testing.T) {
+	for in, want := range map[string][2]bool{"TRUE": {true, true}, "false": {false, true}, "yes": {false, false}, "": {false, false}} {
+		enabled, set := ContainerConfigDiff(map[string]string{"sentinel.config-diff": in})
+		if enabled != want[0] || set != want[1] {
+			t.Errorf("ContainerConfigDiff(%q) = %v, %v, want %v, %v", in, enabled, set, want[0], want[1])
+		}
+	}
+	got := ContainerConfigPaths(map[string]string{"sentinel.config-paths": " /etc/nginx, ,relative,/usr/share/nginx/html"})
+	if !slices.Equal(got, []string{"/etc/nginx", "/usr/share/nginx/html"}) {
+		t.Errorf("ContainerConfigPaths = %q", got)
+	}
+}
+
+func TestContainerBaseImage(t *testing.T) {
+	tests := []struct {
+		labels    map[string]string
+		ref, dgst string
+	}{
+		{nil, "", ""},
+		{map[string]string{
+			"org.opencontainers.image.base.name":   "docker.io/library/python:3.12-slim",
+			"org.opencontainers.image.base.digest": "sha256:aaa",
+		}, "docker.io/library/python:3.12-slim", "sha256:aaa"},
+		// An override naming a different base drops the recorded digest.
+		{map[string]string{
+			"org.opencontainers.image.base.name":   "python:3.12",
+			"org.opencontainers.image.base.digest": "sha256:aaa",
+			"sentinel.base-image":                  "python:3.13",
+		}, "python:3.13", ""},
+		{map[string]string{"sentinel.base-image": " alpine:3.20 "}, "alpine:3.20", ""},
+	}
+	for _, tt := range tests {
+		ref, dgst := ContainerBaseImage(tt.labels)
+		if ref != tt.ref || dgst != tt.dgst {
+			t.Errorf("ContainerBaseImage(%v) = %q, %q, want %q, %q", tt.labels, ref, dgst, tt.ref, tt.dgst)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package docker
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/moby/moby/api/types/mount"
+	"github.com/moby/moby/client"
+)
+
+// NetworkSummary describes a Docker network and the containers attached to it.
+type NetworkSummary struct {
+	ID         string
+	Name       string
+	Driver     string
+	Scope      string
+	Internal   bool
+	Builtin    bool     // bridge/host/none, ingress and swarm-scoped networks
+	Containers []string // names of attached containers, running or stopped
+}
+
+// Orphan reports whether no container uses the network and it can be removed.
+func (n NetworkSummary) Orphan() bool {
+	return !n.Builtin && len(n.Containers) == 0
+}
+
+// VolumeSummary describes a Docker volume and the containers mounting it.
+type VolumeSummary struct {
+	Name       string
+	Driver     string
+	Mountpoint string
+	CreatedAt  string
+	Containers []string // names of containers mounting it, running or stopped
+}
+
+// Orphan reports whether no container mounts the volume.
+func (v VolumeSummary) Orphan() bool {
+	return len(v.Containers) == 0
+}
+
+// ListNetworks returns all networks with the containers attached to each.
+func (c *Client) ListNetworks(ctx context.Context) ([]NetworkSummary, error) {
+	nets, err := c.api.NetworkList(ctx, client.NetworkListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	containers, err := c.api.ContainerList(ctx, client.ContainerListOptions{All: true})
+	if err != nil {
+		return nil, err
+	}
+
+	// Stopped containers keep their network settings, so both are counted.
+	attached := make(map[string][]string)
+	for _, ctr := range containers.Items {
+		if ctr.NetworkSettings == nil {
+			continue
+		}
+		name := containerName(ctr.Names)
+		for netName, ep := range ctr.NetworkSettings.Networks {
+			key := netName
+			if ep != nil && ep.NetworkID != "" {
+				key = ep.NetworkID
+			}
+			attached[key] = append(attached[key], name)
+		}
+	}
+
+	result := make([]NetworkSummary, 0, len(nets.Items))
+	for _, n := range nets.Items {
+		users := attached[n.ID]
+		if len(users) == 0 {
+			users = attached[n.Name]
+		}
+		sort.Strings(users)
+		result = append(result, NetworkSummary{
+			ID:         n.ID,
+			Name:       n.Name,
+			Driver:     n.Driver,
+			Scope:      n.Scope,
+			Internal:   n.Internal,
+			Builtin:    isBuiltinNetwork(n.Name) || n.Ingress || n.Scope == "swarm",
+			Containers: users,
+		})
+	}
+	return result, nil
+}
+
+// ListVolumes returns all volumes with the containers mounting each.
+func (c *Client) ListVolumes(ctx context.Context) ([]VolumeSummary, error) {
+	vols, err := c.api.VolumeList(ctx, client.VolumeListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	containers, err := c.api.ContainerList(ctx, client.ContainerListOptions{All: true})
+	if err != nil {
+		return nil, err
+	}
+
+	mounted := make(map[string][]string)
+	for _, ctr := range containers.Items {
+		name := containerName(ctr.Names)
+		for _, m := range ctr.Mounts {
+			if m.Type == mount.TypeVolume && m.Name != "" {
+				mounted[m.Name] = append(mounted[m.Name], name)
+			}
+		}
+	}
+
+	result := make([]VolumeSummary, 0, len(vols.Items))
+	for _, v := range vols.Items {
+		users := mounted[v.Name]
+		sort.Strings(users)
+		result = append(result, VolumeSummary{
+			Name:       v.Name,
+			Driver:     v.Driver,
+			Mountpoint: v.Mountpoint,
+			CreatedAt:  v.CreatedAt,
+			Containers: users,
+		})
+	}
+	return result, nil
+}
+
+// RemoveNetwork removes a network by ID or name.
+func (c *Client) RemoveNetwork(ctx context.Context, id string) error {
+	_, err := c.api.NetworkRemove(ctx, id, client.NetworkRemoveOptions{})
+	return err
+}
+
+// RemoveVolume removes a volume by name. It fails if the volume is in use.
+func (c *Client) RemoveVolume(ctx context.Context, name string) error {
+	_, err := c.api.VolumeRemove(ctx, name, client.VolumeRemoveOptions{})
+	return err
+}
+
+func isBuiltinNetwork(name string) bool {
+	return name == "bridge" || name == "host" || name == "none"
+}
+
+func containerName(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	return strings.TrimPrefix(names[0], "/")
+}
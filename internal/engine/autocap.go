@@ -0,0 +1,75 @@
+package engine
+
+import (
+	"strconv"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+)
+
+// Overflow handling for auto-updates over the per-scan cap.
+const (
+	// CapOverflowDefer leaves the update for a later scan (the default).
+	CapOverflowDefer = "defer"
+	// CapOverflowQueue queues the update for manual approval.
+	CapOverflowQueue = "queue"
+)
+
+// maxAutoUpdates reads the max_auto_updates setting: how many auto-updates
+// one scan may start across local containers, services, cluster hosts and
+// Portainer. 0 means no cap.
+func (u *Updater) maxAutoUpdates() int {
+	if u.settings == nil {
+		return 0
+	}
+	val, err := u.settings.LoadSetting("max_auto_updates")
+	if err != nil || val == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(val)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// capOverflowToQueue reports whether auto-updates over the cap are queued
+// for approval rather than deferred to the next scan.
+func (u *Updater) capOverflowToQueue() bool {
+	if u.settings == nil {
+		return false
+	}
+	val, _ := u.settings.LoadSetting("auto_update_overflow")
+	return val == CapOverflowQueue
+}
+
+// claimAutoSlot takes one of the scan's auto-update slots. It returns false
+// once the cap is used up, counting the update as capped; the caller then
+// defers or queues it per capOverflowToQueue.
+func (u *Updater) claimAutoSlot(result *ScanResult) bool {
+	limit := u.maxAutoUpdates()
+	if limit == 0 {
+		return true
+	}
+	if result.autoStarted >= limit {
+		result.Capped++
+		return false
+	}
+	result.autoStarted++
+	return true
+}
+
+// overflowAutoUpdate handles an auto-update over the cap: queued for
+// approval when configured, else left for the next scan. key is the queue
+// key (host-scoped for remote containers).
+func (u *Updater) overflowAutoUpdate(result *ScanResult, key string, pending PendingUpdate) {
+	if !u.capOverflowToQueue() {
+		u.log.Info("auto-update cap reached, deferring to next scan", "name", key)
+		result.Skipped++
+		return
+	}
+	pending.DetectedAt = u.clock.Now()
+	u.queue.Add(pending)
+	u.log.Info("auto-update cap reached, queued for approval", "name", key)
+	u.publishEvent(events.EventQueueChange, key, "queued for approval: auto-update cap reached")
+	result.Queued++
+}
@@ -0,0 +1,83 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/moby/moby/api/types/container"
+)
+
+// cappedScanMock returns three auto-policy containers with updates whose
+// pulls fail, so each started auto-update counts as Failed.
+func cappedScanMock() *mockDocker {
+	mock := newMockDocker()
+	for _, name := range []string{"app1", "app2", "app3"} {
+		image := "fake.local/" + name + ":1.0"
+		labels := map[string]string{"sentinel.policy": "auto"}
+		mock.containers = append(mock.containers, container.Summary{
+			ID: name, Names: []string{"/" + name}, Image: image, Labels: labels,
+		})
+		mock.imageDigests[image] = "fake.local/" + name + "@sha256:old"
+		mock.distDigests[image] = "sha256:new"
+		mock.inspectResults[name] = container.InspectResponse{
+			ID:              name,
+			Name:            "/" + name,
+			Config:          &container.Config{Image: image, Labels: labels},
+			HostConfig:      &container.HostConfig{},
+			NetworkSettings: &container.NetworkSettings{},
+		}
+		mock.pullErr[image] = fmt.Errorf("network timeout")
+	}
+	return mock
+}
+
+func TestScanAutoUpdateCapDefers(t *testing.T) {
+	u, _ := newTestUpdater(t, cappedScanMock())
+	u.SetSettingsReader(u.store)
+	_ = u.store.SaveSetting("max_auto_updates", "2")
+
+	res := u.Scan(context.Background(), ScanScheduled)
+	if res.AutoCount != 3 {
+		t.Errorf("AutoCount = %d, want 3", res.AutoCount)
+	}
+	if res.Failed != 2 {
+		t.Errorf("Failed = %d, want 2 (updates started under the cap)", res.Failed)
+	}
+	if res.Capped != 1 || res.Skipped != 1 {
+		t.Errorf("Capped = %d, Skipped = %d; want 1, 1", res.Capped, res.Skipped)
+	}
+	if u.queue.Len() != 0 {
+		t.Errorf("queue.Len() = %d, want 0 when deferring", u.queue.Len())
+	}
+}
+
+func TestScanAutoUpdateCapQueues(t *testing.T) {
+	u, _ := newTestUpdater(t, cappedScanMock())
+	u.SetSettingsReader(u.store)
+	_ = u.store.SaveSetting("max_auto_updates", "1")
+	_ = u.store.SaveSetting("auto_update_overflow", CapOverflowQueue)
+
+	res := u.Scan(context.Background(), ScanScheduled)
+	if res.Failed != 1 {
+		t.Errorf("Failed = %d, want 1", res.Failed)
+	}
+	if res.Capped != 2 || res.Queued != 2 {
+		t.Errorf("Capped = %d, Queued = %d; want 2, 2", res.Capped, res.Queued)
+	}
+	for _, name := range []string{"app2", "app3"} {
+		if _, ok := u.queue.Get(name); !ok {
+			t.Errorf("%s should be queued for approval", name)
+		}
+	}
+}
+
+func TestScanAutoUpdateUncapped(t *testing.T) {
+	u, _ := newTestUpdater(t, cappedScanMock())
+	u.SetSettingsReader(u.store)
+
+	res := u.Scan(context.Background(), ScanScheduled)
+	if res.Failed != 3 || res.Capped != 0 {
+		t.Errorf("Failed = %d, Capped = %d; want 3, 0", res.Failed, res.Capped)
+	}
+}
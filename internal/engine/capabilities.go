@@ -0,0 +1,82 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/docker"
+	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+)
+
+// capabilityTTL is how long a permission probe result is trusted before
+// the next update re-probes. Socket proxy config rarely changes at runtime.
+const capabilityTTL = 10 * time.Minute
+
+// CapabilityProber reports which Docker operations the daemon connection
+// permits. Implemented by *docker.Client.
+type CapabilityProber interface {
+	ProbeCapabilities(ctx context.Context) docker.Capabilities
+}
+
+// SetCapabilityProber enables pre-flight permission checks before updates.
+func (u *Updater) SetCapabilityProber(p CapabilityProber) {
+	u.capProber = p
+}
+
+// Capabilities returns the effective capability set, probing again when the
+// cached result is older than capabilityTTL. Without a prober everything is
+// assumed permitted.
+func (u *Updater) Capabilities(ctx context.Context) docker.Capabilities {
+	u.capMu.Lock()
+	defer u.capMu.Unlock()
+	if u.capProber == nil {
+		return docker.Capabilities{}
+	}
+	if u.caps.CheckedAt.IsZero() || u.clock.Since(u.caps.CheckedAt) > capabilityTTL {
+		u.caps = u.capProber.ProbeCapabilities(ctx)
+		u.caps.CheckedAt = u.clock.Now()
+	}
+	return u.caps
+}
+
+// RefreshCapabilities re-probes immediately, e.g. after the proxy config
+// has been changed.
+func (u *Updater) RefreshCapabilities(ctx context.Context) docker.Capabilities {
+	u.capMu.Lock()
+	u.caps = docker.Capabilities{}
+	u.capMu.Unlock()
+	return u.Capabilities(ctx)
+}
+
+// requiredOperations lists the Docker operations a strategy needs.
+func requiredOperations(strategy string) []docker.Operation {
+	switch strategy {
+	case StrategyPullOnly:
+		return []docker.Operation{docker.OpPull}
+	case StrategyBlueGreen:
+		return append(docker.UpdateOperations[:len(docker.UpdateOperations):len(docker.UpdateOperations)], docker.OpRename)
+	case StrategySwarmService:
+		return nil // service updates go through the Swarm API, not container calls
+	default:
+		return docker.UpdateOperations
+	}
+}
+
+// UpdateBlocked returns why the container cannot be updated with the
+// current Docker permissions, or "" if nothing is known to be missing.
+func (u *Updater) UpdateBlocked(ctx context.Context, name string, labels map[string]string) string {
+	return u.Capabilities(ctx).Reason(requiredOperations(u.strategyFor(name, labels).Name())...)
+}
+
+// checkCapabilities refuses an update whose strategy needs an operation the
+// daemon connection denies, before anything has been stopped or removed.
+func (u *Updater) checkCapabilities(ctx context.Context, name, strategy string) error {
+	err := u.Capabilities(ctx).CheckOperations(requiredOperations(strategy)...)
+	if err != nil {
+		u.log.Warn("update blocked by missing Docker permissions", "name", name, "strategy", strategy, "error", err)
+		u.publishEvent(events.EventContainerUpdate, name, "update blocked: "+err.Error())
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/docker"
+	"github.com/moby/moby/api/types/container"
+)
+
+type mockProber struct {
+	denied map[docker.Operation]string
+	calls  int
+}
+
+func (m *mockProber) ProbeCapabilities(context.Context) docker.Capabilities {
+	m.calls++
+	return docker.Capabilities{Denied: m.denied}
+}
+
+func TestUpdateBlockedByMissingPermission(t *testing.T) {
+	mock := newMockDocker()
+	mock.inspectResults["aaa"] = container.InspectResponse{
+		ID:              "aaa",
+		Name:            "/nginx",
+		Config:          &container.Config{Image: "nginx:1.25", Labels: map[string]string{}},
+		HostConfig:      &container.HostConfig{},
+		NetworkSettings: &container.NetworkSettings{},
+	}
+	u, _ := newTestUpdater(t, mock)
+	u.SetCapabilityProber(&mockProber{denied: map[docker.Operation]string{docker.OpCreate: "403 Forbidden"}})
+
+	err := u.UpdateContainer(context.Background(), "aaa", "nginx", "")
+	if !errors.Is(err, docker.ErrOperationDenied) {
+		t.Fatalf("err = %v, want ErrOperationDenied", err)
+	}
+	if len(mock.pullCalls) != 0 || len(mock.stopCalls) != 0 || len(mock.removeCalls) != 0 {
+		t.Errorf("docker touched before permission check: pull=%v stop=%v remove=%v", mock.pullCalls, mock.stopCalls, mock.removeCalls)
+	}
+
+	if got := u.UpdateBlocked(context.Background(), "nginx", nil); got == "" {
+		t.Error("UpdateBlocked = \"\", want a reason")
+	}
+	pullOnly := map[string]string{"sentinel.strategy": StrategyPullOnly}
+	if got := u.UpdateBlocked(context.Background(), "nginx", pullOnly); got != "" {
+		t.Errorf("UpdateBlocked(pull-only) = %q, want \"\"", got)
+	}
+}
+
+func TestCapabilitiesCachedUntilTTL(t *testing.T) {
+	u, clk := newTestUpdater(t, newMockDocker())
+	p := &mockProber{}
+	u.SetCapabilityProber(p)
+
+	u.Capabilities(context.Background())
+	u.Capabilities(context.Background())
+	if p.calls != 1 {
+		t.Errorf("probe calls = %d, want 1 (cached)", p.calls)
+	}
+	clk.Advance(capabilityTTL + time.Minute)
+	u.Capabilities(context.Background())
+	if p.calls != 2 {
+		t.Errorf("probe calls = %d, want 2 after TTL", p.calls)
+	}
+	u.RefreshCapabilities(context.Background())
+	if p.calls != 3 {
+		t.Errorf("probe calls = %d, want 3 after refresh", p.calls)
+	}
+}
@@ -0,0 +1,59 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/docker"
+	"github.com/Will-Luck/Docker-Sentinel/internal/registry"
+)
+
+// checkSourceRef returns the reference update checks should query for a
+// container running imageRef. It is imageRef unless sentinel.check-image
+// names another repository, such as the upstream image an internal copy
+// mirrors; the running tag is kept unless the label sets its own.
+//
+//	internal/nginx:1.25 + sentinel.check-image=docker.io/library/nginx
+//	  => docker.io/library/nginx:1.25
+func checkSourceRef(imageRef string, labels map[string]string) string {
+	src := docker.ContainerCheckImage(labels)
+	if src == "" {
+		return imageRef
+	}
+	if registry.ExtractTag(src) != "" {
+		return src
+	}
+	tag := registry.ExtractTag(imageRef)
+	if tag == "" {
+		tag = "latest"
+	}
+	return src + ":" + tag
+}
+
+// checkVersions checks a container's image for updates. knownDigest, when
+// set, stands in for the local digest lookup. A container with a check
+// source is compared against that repository using the digest of the image
+// it runs, so an internal copy counts as current while it matches upstream;
+// updates still pull the container's own repository.
+func (u *Updater) checkVersions(ctx context.Context, imageRef, knownDigest string, labels map[string]string) registry.CheckResult {
+	scope := docker.ContainerSemverScope(labels)
+	includeRE, excludeRE := docker.ContainerTagFilters(labels)
+
+	src := checkSourceRef(imageRef, labels)
+	if src == imageRef {
+		if knownDigest != "" {
+			return u.checker.CheckVersionedWithDigest(ctx, imageRef, knownDigest, scope, includeRE, excludeRE)
+		}
+		return u.checker.CheckVersioned(ctx, imageRef, scope, includeRE, excludeRE)
+	}
+
+	if knownDigest == "" {
+		digest, err := u.docker.ImageDigest(ctx, imageRef)
+		if err != nil {
+			return registry.CheckResult{ImageRef: src, Error: fmt.Errorf("local digest of %s: %w", imageRef, err)}
+		}
+		knownDigest = digest
+	}
+	u.log.Debug("checking upstream image", "image", imageRef, "source", src)
+	return u.checker.CheckVersionedWithDigest(ctx, src, knownDigest, scope, includeRE, excludeRE)
+}
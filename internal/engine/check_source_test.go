@@ -0,0 +1,46 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/moby/moby/api/types/container"
+)
+
+func TestCheckSourceRef(t *testing.T) {
+	label := func(v string) map[string]string { return map[string]string{"sentinel.check-image": v} }
+	tests := []struct {
+		image  string
+		labels map[string]string
+		want   string
+	}{
+		{"internal/nginx:1.25", nil, "internal/nginx:1.25"},
+		{"internal/nginx:1.25", label("docker.io/library/nginx"), "docker.io/library/nginx:1.25"},
+		{"registry.local:5000/nginx", label("nginx"), "nginx:latest"},
+		{"internal/nginx:stable", label("nginx:1.27"), "nginx:1.27"},
+	}
+	for _, tt := range tests {
+		if got := checkSourceRef(tt.image, tt.labels); got != tt.want {
+			t.Errorf("checkSourceRef(%q, %v) = %q, want %q", tt.image, tt.labels, got, tt.want)
+		}
+	}
+}
+
+func TestScanChecksUpstreamImage(t *testing.T) {
+	mock := newMockDocker()
+	mock.containers = []container.Summary{
+		{ID: "aaa", Names: []string{"/web"}, Image: "registry.internal/nginx:latest",
+			Labels: map[string]string{"sentinel.check-image": "docker.io/library/nginx"}},
+	}
+	// The internal copy matches upstream, and the internal registry itself
+	// is never queried.
+	mock.imageDigests["registry.internal/nginx:latest"] = "registry.internal/nginx@sha256:aaa"
+	mock.distDigests["docker.io/library/nginx:latest"] = "sha256:aaa"
+	mock.distDigests["registry.internal/nginx:latest"] = "sha256:stale"
+
+	u, _ := newTestUpdater(t, mock)
+	result := u.Scan(context.Background(), ScanScheduled)
+	if result.UpToDate != 1 || len(result.Errors) != 0 {
+		t.Errorf("UpToDate = %d, errors = %v; want up to date against upstream", result.UpToDate, result.Errors)
+	}
+}
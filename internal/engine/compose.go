@@ -1,85 +1,310 @@
 package engine
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/compose"
+)
+
+// Errors returned by PreviewComposeSync and SyncComposeFile.
+var (
+	ErrNotCompose        = errors.New("container is not managed by Docker Compose")
+	ErrContainerNotFound = errors.New("container not found")
 )
 
+// composeFile returns the compose file and service a container was created
+// from, using the labels Docker Compose sets. ok is false for containers not
+// managed by Compose.
+func composeFile(labels map[string]string) (path, service string, ok bool) {
+	workDir := labels["com.docker.compose.project.working_dir"]
+	configFiles := labels["com.docker.compose.project.config_files"]
+	service = labels["com.docker.compose.service"]
+	if workDir == "" || configFiles == "" || service == "" {
+		return "", "", false
+	}
+	path = configFiles
+	if i := strings.Index(path, ","); i >= 0 {
+		path = path[:i]
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = workDir + "/" + path
+	}
+	return path, service, true
+}
+
+// composeFileFor resolves the compose file Sentinel should edit. The labels
+// hold the path on the Docker host; when the project's file is mounted
+// somewhere else inside Sentinel's container, the per-stack override wins.
+// An override naming a directory keeps the file name from the labels.
+func (u *Updater) composeFileFor(labels map[string]string) (path, service string, ok bool) {
+	path, service, ok = composeFile(labels)
+	if !ok {
+		return "", "", false
+	}
+	if override, set := u.store.GetComposePath(compose.ProjectName(labels)); set {
+		if info, err := os.Stat(override); err == nil && info.IsDir() {
+			override = filepath.Join(override, filepath.Base(path))
+		}
+		path = override
+	}
+	return path, service, true
+}
+
+// ComposeEdit is the change compose sync would make to one service's image
+// line. Line is 0 when the file would be left as it is; Reason says why.
+type ComposeEdit struct {
+	Path    string `json:"path"`
+	Service string `json:"service"`
+	Line    int    `json:"line,omitempty"` // 1-based line number of the image key
+	OldLine string `json:"old_line,omitempty"`
+	NewLine string `json:"new_line,omitempty"`
+	Diff    string `json:"diff,omitempty"` // unified diff of the edit
+	Reason  string `json:"reason,omitempty"`
+}
+
+// Changed reports whether the edit modifies the file.
+func (e ComposeEdit) Changed() bool { return e.Line > 0 }
+
+// PlanComposeTag works out how UpdateComposeTag would change the file,
+// without writing anything.
+func PlanComposeTag(composePath, serviceName, newImage string) (ComposeEdit, error) {
+	data, err := os.ReadFile(composePath) // #nosec G304 -- composePath comes from Docker compose labels or an admin-set override, not request input
+	if err != nil {
+		return ComposeEdit{}, fmt.Errorf("read compose file: %w", err)
+	}
+	edit, _ := planComposeEdit(string(data), composePath, serviceName, newImage)
+	return edit, nil
+}
+
 // UpdateComposeTag reads a Docker Compose file and updates the image tag for the
 // given service. Creates a .bak backup before modifying. Returns nil if the
 // service or image line was not found (no-op).
 func UpdateComposeTag(composePath, serviceName, newImage string) error {
-	data, err := os.ReadFile(composePath)
-	if err != nil {
-		return fmt.Errorf("read compose file: %w", err)
-	}
-
-	content := string(data)
+	_, err := writeComposeTag(composePath, serviceName, newImage)
+	return err
+}
 
-	// Extract just the tag from the new image.
-	newTag := ""
-	if i := strings.LastIndex(newImage, ":"); i >= 0 {
-		newTag = newImage[i+1:]
+// writeComposeTag applies the planned edit and returns it.
+func writeComposeTag(composePath, serviceName, newImage string) (ComposeEdit, error) {
+	data, err := os.ReadFile(composePath) // #nosec G304 -- composePath comes from Docker compose labels or an admin-set override, not request input
+	if err != nil {
+		return ComposeEdit{}, fmt.Errorf("read compose file: %w", err)
 	}
-	if newTag == "" {
-		return nil // no tag to update
-	}
-
-	// Find the service block and update its image line.
-	// Best-effort regex approach for standard compose files.
-	updated := updateImageInCompose(content, serviceName, newTag)
-	if updated == content {
-		return nil // no change
+	edit, updated := planComposeEdit(string(data), composePath, serviceName, newImage)
+	if !edit.Changed() {
+		return edit, nil
 	}
 
 	// Write backup.
 	if err := os.WriteFile(composePath+".bak", data, 0600); err != nil { // #nosec G703 -- composePath comes from Docker compose labels on the host, not request input
-		return fmt.Errorf("write compose backup: %w", err)
+		return edit, fmt.Errorf("write compose backup: %w", err)
 	}
 
 	// Write updated file.
 	if err := os.WriteFile(composePath, []byte(updated), 0600); err != nil { // #nosec G703 -- composePath comes from Docker compose labels on the host, not request input
-		return fmt.Errorf("write compose file: %w", err)
+		return edit, fmt.Errorf("write compose file: %w", err)
 	}
-
-	return nil
+	return edit, nil
 }
 
-// updateImageInCompose finds the image line for a service and updates its tag.
-func updateImageInCompose(content, serviceName, newTag string) string {
+// planComposeEdit finds the service's image line and retags it, returning
+// the edit and the resulting file content.
+func planComposeEdit(content, path, serviceName, newImage string) (ComposeEdit, string) {
+	edit := ComposeEdit{Path: path, Service: serviceName}
+	newTag := imageTag(newImage)
+	if newTag == "" {
+		edit.Reason = "new image has no tag"
+		return edit, content
+	}
+	if !composeTagRe.MatchString(newTag) {
+		edit.Reason = "new image tag is not valid"
+		return edit, content
+	}
+
 	lines := strings.Split(content, "\n")
+	idx := findImageLine(lines, serviceName)
+	if idx < 0 {
+		edit.Reason = "no image line for service " + serviceName
+		return edit, content
+	}
+	updated, reason := retagImageLine(lines[idx], newTag)
+	if reason != "" {
+		edit.Reason = reason
+		return edit, content
+	}
+
+	edit.Line = idx + 1
+	edit.OldLine = lines[idx]
+	edit.NewLine = updated
+	edit.Diff = lineDiff(path, lines, idx, updated)
+	lines[idx] = updated
+	return edit, strings.Join(lines, "\n")
+}
+
+// findImageLine returns the index of the image key inside the service's
+// block, or -1.
+func findImageLine(lines []string, serviceName string) int {
 	inService := false
 	serviceIndent := 0
 
 	for i, line := range lines {
-		trimmed := strings.TrimSpace(line)
+		trimmed := strings.TrimSpace(stripComment(line))
+		if trimmed == "" {
+			continue
+		}
+		currentIndent := len(line) - len(strings.TrimLeft(line, " "))
 
 		// Detect service name (e.g. "  servicename:" at indent under services).
-		if trimmed == serviceName+":" {
-			inService = true
-			serviceIndent = len(line) - len(strings.TrimLeft(line, " "))
+		if !inService {
+			key := strings.Trim(strings.TrimSuffix(trimmed, ":"), `"'`)
+			if strings.HasSuffix(trimmed, ":") && key == serviceName {
+				inService = true
+				serviceIndent = currentIndent
+			}
 			continue
 		}
 
-		if inService {
-			currentIndent := len(line) - len(strings.TrimLeft(line, " "))
+		// Same or lesser indent on non-empty line means we left the block.
+		if currentIndent <= serviceIndent {
+			return -1
+		}
+		if strings.HasPrefix(trimmed, "image:") {
+			return i
+		}
+	}
+	return -1
+}
 
-			// Same or lesser indent on non-empty line means we left the block.
-			if trimmed != "" && currentIndent <= serviceIndent {
-				inService = false
-				continue
-			}
+// composeTagRe matches a Docker image tag. Anything else is refused so a
+// crafted reference can't write YAML into the compose file.
+var composeTagRe = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9._-]{0,127}$`)
 
-			// Match "image: repo:tag" or "image: repo"
-			re := regexp.MustCompile(`^(\s+image:\s*\S+?):(\S+)\s*$`)
-			if m := re.FindStringSubmatch(line); m != nil {
-				lines[i] = m[1] + ":" + newTag
-				return strings.Join(lines, "\n")
-			}
+// imageLineRe splits "  image: "repo:tag"  # note" into indent+key, quote,
+// reference, closing quote and trailing space/comment.
+var imageLineRe = regexp.MustCompile(`^(\s*image:\s*)(["']?)([^"'\s#]+)(["']?)(\s*(?:#.*)?)$`)
+
+// retagImageLine replaces the tag in an image line, keeping the repository,
+// quoting and any trailing comment. A digest pin is dropped since it would
+// no longer match the new tag. Returns a reason when the line is left alone.
+func retagImageLine(line, newTag string) (string, string) {
+	m := imageLineRe.FindStringSubmatch(line)
+	if m == nil {
+		return line, "image line not understood"
+	}
+	ref := m[3]
+	if strings.Contains(ref, "$") {
+		return line, "image uses variable substitution"
+	}
+	if i := strings.Index(ref, "@"); i >= 0 {
+		ref = ref[:i]
+	}
+	name := ref
+	if colon := strings.LastIndex(ref, ":"); colon > strings.LastIndex(ref, "/") {
+		name = ref[:colon]
+	}
+	newRef := name + ":" + newTag
+	if newRef == m[3] {
+		return line, "already at " + newTag
+	}
+	return m[1] + m[2] + newRef + m[4] + m[5], ""
+}
+
+// imageTag returns the tag of an image reference, ignoring registry ports
+// and digests.
+func imageTag(ref string) string {
+	if i := strings.Index(ref, "@"); i >= 0 {
+		ref = ref[:i]
+	}
+	if colon := strings.LastIndex(ref, ":"); colon > strings.LastIndex(ref, "/") {
+		return ref[colon+1:]
+	}
+	return ""
+}
+
+// stripComment removes a trailing YAML comment from a line.
+func stripComment(line string) string {
+	if i := strings.Index(line, " #"); i >= 0 {
+		return line[:i]
+	}
+	if strings.HasPrefix(strings.TrimSpace(line), "#") {
+		return ""
+	}
+	return line
+}
+
+// lineDiff renders a one-line change as a unified diff with two lines of
+// context either side.
+func lineDiff(path string, lines []string, idx int, newLine string) string {
+	start := max(idx-2, 0)
+	end := min(idx+3, len(lines))
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n@@ -%d,%d +%d,%d @@\n", path, path, start+1, end-start, start+1, end-start)
+	for i := start; i < end; i++ {
+		if i == idx {
+			fmt.Fprintf(&b, "-%s\n+%s\n", lines[i], newLine)
+			continue
+		}
+		fmt.Fprintf(&b, " %s\n", lines[i])
+	}
+	return b.String()
+}
+
+// composeTarget finds a container by name and resolves its compose file.
+// image defaults to the container's current image.
+func (u *Updater) composeTarget(ctx context.Context, name, image string) (path, service, target string, err error) {
+	containers, err := u.docker.ListAllContainers(ctx)
+	if err != nil {
+		return "", "", "", fmt.Errorf("list containers: %w", err)
+	}
+	for _, c := range containers {
+		if containerName(c) != name {
+			continue
+		}
+		inspect, err := u.docker.InspectContainer(ctx, c.ID)
+		if err != nil {
+			return "", "", "", fmt.Errorf("inspect %s: %w", name, err)
+		}
+		if inspect.Config == nil {
+			return "", "", "", fmt.Errorf("inspect %s: container config is nil", name)
 		}
+		path, service, ok := u.composeFileFor(inspect.Config.Labels)
+		if !ok {
+			return "", "", "", ErrNotCompose
+		}
+		if image == "" {
+			image = inspect.Config.Image
+		}
+		return path, service, image, nil
+	}
+	return "", "", "", fmt.Errorf("%s: %w", name, ErrContainerNotFound)
+}
+
+// PreviewComposeSync shows how compose sync would change the container's
+// compose file for image (default: the running image) without writing.
+func (u *Updater) PreviewComposeSync(ctx context.Context, name, image string) (ComposeEdit, error) {
+	path, service, target, err := u.composeTarget(ctx, name, image)
+	if err != nil {
+		return ComposeEdit{}, err
 	}
+	return PlanComposeTag(path, service, target)
+}
 
-	return content
+// SyncComposeFile writes image (default: the running image) into the
+// container's compose file, so `docker compose up` keeps the current version.
+func (u *Updater) SyncComposeFile(ctx context.Context, name, image string) (ComposeEdit, error) {
+	path, service, target, err := u.composeTarget(ctx, name, image)
+	if err != nil {
+		return ComposeEdit{}, err
+	}
+	edit, err := writeComposeTag(path, service, target)
+	if err == nil && edit.Changed() {
+		u.log.Info("compose file updated", "name", name, "file", path, "line", edit.Line)
+	}
+	return edit, err
 }
@@ -1,9 +1,12 @@
 package engine
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/moby/moby/api/types/container"
 )
 
 const testComposeContent = `services:
@@ -96,6 +99,34 @@ func TestUpdateComposeTag_NoTagInNewImage(t *testing.T) {
 	}
 }
 
+func TestUpdateComposeTag_RejectsInvalidTag(t *testing.T) {
+	p := writeComposeFile(t, testComposeContent)
+
+	for _, image := range []string{
+		`nginx: ["sh","-c","id"] #`,
+		"nginx:1.25\n    command: id",
+		"nginx:1.25 # note",
+	} {
+		edit, err := PlanComposeTag(p, "nginx", image)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if edit.Changed() {
+			t.Errorf("PlanComposeTag(%q) = %+v, want no edit", image, edit)
+		}
+		if err := UpdateComposeTag(p, "nginx", image); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != testComposeContent {
+		t.Errorf("file changed:\n%s", data)
+	}
+}
+
 func TestUpdateComposeTag_FileNotFound(t *testing.T) {
 	err := UpdateComposeTag("/nonexistent/path/docker-compose.yml", "nginx", "nginx:2.0")
 	if err == nil {
@@ -125,6 +156,77 @@ func TestUpdateComposeTag_SecondService(t *testing.T) {
 	}
 }
 
+func TestRetagImageLine(t *testing.T) {
+	tests := []struct {
+		line, want, reason string
+	}{
+		{"    image: nginx:1.24", "    image: nginx:1.25", ""},
+		{"    image: registry.local:5000/app:1.24", "    image: registry.local:5000/app:1.25", ""},
+		{`    image: "nginx:1.24"  # pinned`, `    image: "nginx:1.25"  # pinned`, ""},
+		{"    image: nginx", "    image: nginx:1.25", ""},
+		{"    image: nginx:1.24@sha256:abc", "    image: nginx:1.25", ""},
+		{"    image: nginx:${TAG}", "    image: nginx:${TAG}", "image uses variable substitution"},
+		{"    image: nginx:1.25", "    image: nginx:1.25", "already at 1.25"},
+	}
+	for _, tt := range tests {
+		got, reason := retagImageLine(tt.line, "1.25")
+		if got != tt.want || reason != tt.reason {
+			t.Errorf("retagImageLine(%q) = %q, %q; want %q, %q", tt.line, got, reason, tt.want, tt.reason)
+		}
+	}
+}
+
+func TestPlanComposeTagDoesNotWrite(t *testing.T) {
+	p := writeComposeFile(t, testComposeContent)
+
+	edit, err := PlanComposeTag(p, "redis", "redis:8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !edit.Changed() || edit.Line != 7 || edit.NewLine != "    image: redis:8" {
+		t.Errorf("edit = %+v", edit)
+	}
+	if !containsString(edit.Diff, "-    image: redis:7\n+    image: redis:8") {
+		t.Errorf("diff missing change:\n%s", edit.Diff)
+	}
+	data, _ := os.ReadFile(p)
+	if string(data) != testComposeContent {
+		t.Error("PlanComposeTag modified the file")
+	}
+}
+
+func TestPreviewComposeSyncUsesStackPathOverride(t *testing.T) {
+	p := writeComposeFile(t, testComposeContent)
+	mock := newMockDocker()
+	mock.containers = []container.Summary{{ID: "web-id", Names: []string{"/shop-nginx-1"}}}
+	mock.inspectResults["web-id"] = container.InspectResponse{
+		Config: &container.Config{
+			Image: "nginx:1.25",
+			Labels: map[string]string{
+				"com.docker.compose.project":              "shop",
+				"com.docker.compose.service":              "nginx",
+				"com.docker.compose.project.working_dir":  "/srv/shop", // host path, not mounted here
+				"com.docker.compose.project.config_files": "/srv/shop/docker-compose.yml",
+			},
+		},
+	}
+	u, _ := newTestUpdater(t, mock)
+
+	if _, err := u.PreviewComposeSync(context.Background(), "shop-nginx-1", ""); err == nil {
+		t.Fatal("expected error reading the unmounted host path")
+	}
+	if err := u.store.SetComposePath("shop", filepath.Dir(p)); err != nil {
+		t.Fatal(err)
+	}
+	edit, err := u.PreviewComposeSync(context.Background(), "shop-nginx-1", "")
+	if err != nil {
+		t.Fatalf("PreviewComposeSync: %v", err)
+	}
+	if edit.Path != p || edit.NewLine != "    image: nginx:1.25" {
+		t.Errorf("edit = %+v, want %s retagged to 1.25", edit, p)
+	}
+}
+
 func containsString(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > 0 && stringContains(s, substr))
 }
@@ -0,0 +1,376 @@
+package engine
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/api/types/mount"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/docker"
+)
+
+const (
+	// configDiffTimeout bounds the pull, scratch containers and archive
+	// reads for one container.
+	configDiffTimeout = 2 * time.Minute
+
+	// maxConfigDiffFiles caps how many files are read from each image.
+	maxConfigDiffFiles = 500
+
+	// maxConfigFileSize is the largest file whose content is compared;
+	// bigger files are compared by size only.
+	maxConfigFileSize = 1 << 20
+
+	// maxConfigTextSize is the largest file a line diff is produced for.
+	maxConfigTextSize = 64 << 10
+
+	// maxConfigDiffLen caps the diff text kept per changed file.
+	maxConfigDiffLen = 4 << 10
+
+	// configDiffEntrypoint is set on scratch containers so images without
+	// a command can still be created; they are never started.
+	configDiffEntrypoint = "/sentinel-config-diff"
+)
+
+// ConfigFileReader reads paths out of a container's filesystem as tar
+// archives. Implemented by *docker.Client.
+type ConfigFileReader interface {
+	CopyFromContainer(ctx context.Context, id, path string) (io.ReadCloser, error)
+}
+
+// SetConfigFileReader enables config file previews for manual updates.
+func (u *Updater) SetConfigFileReader(r ConfigFileReader) {
+	u.configReader = r
+}
+
+// ConfigChange is one file that differs between the current and the new
+// image under a watched path.
+type ConfigChange struct {
+	Path   string `json:"path"`
+	Change string `json:"change"` // "added", "removed" or "modified"
+	// Overridden is set when a bind mount or volume covers the file, so the
+	// container keeps its own copy and never sees the new default.
+	Overridden bool   `json:"overridden,omitempty"`
+	MountPath  string `json:"mount_path,omitempty"`
+	Diff       string `json:"diff,omitempty"` // line diff for small text files
+}
+
+// ConfigDiff compares the default config files of the current image with
+// those of the update target, for the paths the container mounts over or
+// lists in sentinel.config-paths.
+type ConfigDiff struct {
+	TargetImage string         `json:"target_image"`
+	Paths       []string       `json:"paths"`
+	Changes     []ConfigChange `json:"changes,omitempty"`
+	Warnings    []string       `json:"warnings,omitempty"`
+	Error       string         `json:"error,omitempty"`
+	CheckedAt   time.Time      `json:"checked_at"`
+}
+
+// configFile is one regular file or symlink read from an image.
+type configFile struct {
+	size int64
+	sum  [sha256.Size]byte
+	link string // symlink target
+	text string // content, for small text files
+}
+
+// configDiffEnabled reports whether config previews run for a container:
+// the sentinel.config-diff label wins over the config_diff setting.
+func (u *Updater) configDiffEnabled(labels map[string]string) bool {
+	if u.configReader == nil {
+		return false
+	}
+	if enabled, set := docker.ContainerConfigDiff(labels); set {
+		return enabled
+	}
+	if u.settings == nil {
+		return false
+	}
+	val, err := u.settings.LoadSetting("config_diff")
+	return err == nil && val == "true"
+}
+
+// configWatchPaths returns the paths to compare for a container: the
+// destinations of its bind mounts and volumes, plus sentinel.config-paths.
+// The map gives the mount destination covering each mounted path.
+func configWatchPaths(c container.Summary) ([]string, map[string]bool) {
+	mounted := make(map[string]bool)
+	seen := make(map[string]bool)
+	var paths []string
+	add := func(p string) {
+		p = path.Clean(p)
+		if p == "/" || seen[p] {
+			return
+		}
+		seen[p] = true
+		paths = append(paths, p)
+	}
+	for _, m := range c.Mounts {
+		if m.Type != mount.TypeBind && m.Type != mount.TypeVolume {
+			continue
+		}
+		if m.Destination == "" {
+			continue
+		}
+		mounted[path.Clean(m.Destination)] = true
+		add(m.Destination)
+	}
+	for _, p := range docker.ContainerConfigPaths(c.Labels) {
+		add(p)
+	}
+	sort.Strings(paths)
+	return paths, mounted
+}
+
+// mountCovering returns the mount destination that contains p, or "".
+func mountCovering(p string, mounted map[string]bool) string {
+	for dir := p; ; dir = path.Dir(dir) {
+		if mounted[dir] {
+			return dir
+		}
+		if dir == "/" || dir == "." {
+			return ""
+		}
+	}
+}
+
+// previewConfigChanges compares the watched paths of a container's current
+// image with targetImage. Failures are recorded in the result rather than
+// returned, so a broken preview never blocks queueing the update.
+func (u *Updater) previewConfigChanges(ctx context.Context, c container.Summary, targetImage string) *ConfigDiff {
+	paths, mounted := configWatchPaths(c)
+	if len(paths) == 0 {
+		return nil
+	}
+	diff := &ConfigDiff{TargetImage: targetImage, Paths: paths, CheckedAt: u.clock.Now()}
+
+	ctx, cancel := context.WithTimeout(ctx, configDiffTimeout)
+	defer cancel()
+
+	oldFiles, err := u.snapshotConfigPaths(ctx, c.ImageID, paths)
+	if err != nil {
+		diff.Error = "read current image: " + err.Error()
+		return diff
+	}
+	if err := u.docker.PullImage(ctx, targetImage); err != nil {
+		diff.Error = "pull " + targetImage + ": " + err.Error()
+		return diff
+	}
+	newFiles, err := u.snapshotConfigPaths(ctx, targetImage, paths)
+	if err != nil {
+		diff.Error = "read new image: " + err.Error()
+		return diff
+	}
+
+	diff.Changes = compareConfigFiles(oldFiles, newFiles, mounted)
+	if len(oldFiles) >= maxConfigDiffFiles || len(newFiles) >= maxConfigDiffFiles {
+		diff.Warnings = append(diff.Warnings, fmt.Sprintf("only the first %d files of each image were compared", maxConfigDiffFiles))
+	}
+	for _, ch := range diff.Changes {
+		if ch.Overridden {
+			diff.Warnings = append(diff.Warnings, fmt.Sprintf("default %s was %s upstream but %s is mounted over it", ch.Path, ch.Change, ch.MountPath))
+		}
+	}
+	return diff
+}
+
+// snapshotConfigPaths creates a stopped scratch container from image and
+// reads the files under paths out of it. Paths missing from the image are
+// skipped.
+func (u *Updater) snapshotConfigPaths(ctx context.Context, image string, paths []string) (map[string]configFile, error) {
+	id, err := u.docker.CreateContainer(ctx, "",
+		&container.Config{Image: image, Entrypoint: []string{configDiffEntrypoint}},
+		&container.HostConfig{NetworkMode: "none"}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create scratch container: %w", err)
+	}
+	defer func() {
+		if err := u.docker.RemoveContainer(context.WithoutCancel(ctx), id); err != nil {
+			u.log.Warn("failed to remove config diff container", "id", id, "error", err)
+		}
+	}()
+
+	files := make(map[string]configFile)
+	for _, p := range paths {
+		rc, err := u.configReader.CopyFromContainer(ctx, id, p)
+		if cerrdefs.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("copy %s: %w", p, err)
+		}
+		err = readConfigArchive(rc, path.Dir(p), files)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", p, err)
+		}
+	}
+	return files, nil
+}
+
+// readConfigArchive adds the regular files and symlinks in a
+// CopyFromContainer archive to files. Entry names are relative to dir.
+func readConfigArchive(r io.Reader, dir string, files map[string]configFile) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(files) >= maxConfigDiffFiles {
+			return nil
+		}
+		name := path.Join(dir, hdr.Name)
+		switch hdr.Typeflag {
+		case tar.TypeSymlink:
+			files[name] = configFile{link: hdr.Linkname}
+		case tar.TypeReg:
+			f := configFile{size: hdr.Size}
+			if hdr.Size <= maxConfigFileSize {
+				data, err := io.ReadAll(tr)
+				if err != nil {
+					return err
+				}
+				f.sum = sha256.Sum256(data)
+				if len(data) <= maxConfigTextSize && isConfigText(data) {
+					f.text = string(data)
+				}
+			}
+			files[name] = f
+		}
+	}
+}
+
+// isConfigText reports whether data looks like a text file.
+func isConfigText(data []byte) bool {
+	return utf8.Valid(data) && !strings.ContainsRune(string(data), 0)
+}
+
+// compareConfigFiles lists the files that differ between two snapshots,
+// sorted by path.
+func compareConfigFiles(oldFiles, newFiles map[string]configFile, mounted map[string]bool) []ConfigChange {
+	var changes []ConfigChange
+	add := func(p, change string, diff string) {
+		ch := ConfigChange{Path: p, Change: change, Diff: diff, MountPath: mountCovering(p, mounted)}
+		ch.Overridden = ch.MountPath != ""
+		changes = append(changes, ch)
+	}
+	for p, nf := range newFiles {
+		of, ok := oldFiles[p]
+		switch {
+		case !ok:
+			add(p, "added", "")
+		case of != nf:
+			d := ""
+			if of.link == "" && nf.link == "" && (of.text != "" || nf.text != "") {
+				d = configLineDiff(of.text, nf.text)
+			}
+			add(p, "modified", d)
+		}
+	}
+	for p := range oldFiles {
+		if _, ok := newFiles[p]; !ok {
+			add(p, "removed", "")
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// configLineDiff returns the removed ("-") and added ("+") lines between two
+// texts, computed from their longest common subsequence and truncated to
+// maxConfigDiffLen.
+func configLineDiff(a, b string) string {
+	al := strings.Split(strings.TrimSuffix(a, "\n"), "\n")
+	bl := strings.Split(strings.TrimSuffix(b, "\n"), "\n")
+	if a == "" {
+		al = nil
+	}
+	if b == "" {
+		bl = nil
+	}
+	if len(al)*len(bl) > 1<<20 {
+		return fmt.Sprintf("(%d lines changed to %d lines; too large to diff)\n", len(al), len(bl))
+	}
+
+	// lcs[i][j] is the LCS length of al[i:] and bl[j:].
+	lcs := make([][]int, len(al)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(bl)+1)
+	}
+	for i := len(al) - 1; i >= 0; i-- {
+		for j := len(bl) - 1; j >= 0; j-- {
+			if al[i] == bl[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out strings.Builder
+	write := func(prefix, line string) bool {
+		if out.Len()+len(line)+2 > maxConfigDiffLen {
+			out.WriteString("...\n")
+			return false
+		}
+		out.WriteString(prefix + line + "\n")
+		return true
+	}
+	i, j := 0, 0
+	for i < len(al) || j < len(bl) {
+		var ok bool
+		switch {
+		case i < len(al) && j < len(bl) && al[i] == bl[j]:
+			i, j = i+1, j+1
+			continue
+		case j < len(bl) && (i == len(al) || lcs[i][j+1] > lcs[i+1][j]):
+			ok = write("+", bl[j])
+			j++
+		default:
+			ok = write("-", al[i])
+			i++
+		}
+		if !ok {
+			break
+		}
+	}
+	return out.String()
+}
+
+// queuedConfigDiff returns the config preview for a manual update, reusing
+// the one already queued when the remote image has not changed since.
+func (u *Updater) queuedConfigDiff(ctx context.Context, c container.Summary, pending PendingUpdate, scanTarget string) *ConfigDiff {
+	target := scanTarget
+	if target == "" {
+		target = pending.CurrentImage
+	}
+	if prev, ok := u.queue.Get(pending.Key()); ok && prev.ConfigDiff != nil &&
+		prev.RemoteDigest == pending.RemoteDigest && prev.ConfigDiff.TargetImage == target {
+		return prev.ConfigDiff
+	}
+	diff := u.previewConfigChanges(ctx, c, target)
+	switch {
+	case diff == nil:
+	case diff.Error != "":
+		u.log.Warn("config preview failed", "name", pending.ContainerName, "error", diff.Error)
+	case len(diff.Warnings) > 0:
+		u.log.Warn("upstream config defaults changed under mounted paths", "name", pending.ContainerName, "warnings", len(diff.Warnings))
+	}
+	return diff
+}
@@ -0,0 +1,168 @@
+package engine
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/api/types/mount"
+)
+
+// fakeConfigReader serves files per image, keyed by absolute path. The
+// image of the scratch container is read from the mock's last create call.
+type fakeConfigReader struct {
+	mu     sync.Mutex
+	mock   *mockDocker
+	images map[string]map[string]string
+	calls  int
+}
+
+func (f *fakeConfigReader) CopyFromContainer(_ context.Context, _, src string) (io.ReadCloser, error) {
+	f.mu.Lock()
+	f.calls++
+	f.mu.Unlock()
+	f.mock.mu.Lock()
+	image := f.mock.createConfigs[""].Image
+	f.mock.mu.Unlock()
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	found := false
+	for name, content := range f.images[image] {
+		if name != src && !strings.HasPrefix(name, src+"/") {
+			continue
+		}
+		found = true
+		rel := path.Join(path.Base(src), strings.TrimPrefix(name, src))
+		_ = tw.WriteHeader(&tar.Header{Name: rel, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(content))})
+		_, _ = tw.Write([]byte(content))
+	}
+	_ = tw.Close()
+	if !found {
+		return nil, cerrdefs.ErrNotFound
+	}
+	return io.NopCloser(&buf), nil
+}
+
+func TestScanAttachesConfigDiff(t *testing.T) {
+	mock := newMockDocker()
+	mock.containers = []container.Summary{{
+		ID: "aaa", Names: []string{"/web"}, Image: "nginx:1.25", ImageID: "sha256:old",
+		Labels: map[string]string{"sentinel.config-diff": "true", "sentinel.config-paths": "/usr/share/nginx"},
+		Mounts: []container.MountPoint{
+			{Type: mount.TypeBind, Source: "/srv/nginx", Destination: "/etc/nginx/conf.d"},
+			{Type: mount.TypeBind, Source: "/var/run/docker.sock", Destination: "/var/run/docker.sock"},
+		},
+	}}
+	mock.distDigests["nginx:1.25"] = "sha256:remote"
+	mock.imageDigests["nginx:1.25"] = "sha256:local"
+
+	reader := &fakeConfigReader{mock: mock, images: map[string]map[string]string{
+		"sha256:old": {
+			"/etc/nginx/conf.d/default.conf": "server {\n  listen 80;\n}\n",
+			"/etc/nginx/conf.d/old.conf":     "# gone\n",
+			"/usr/share/nginx/index.html":    "hello\n",
+		},
+		"nginx:1.25": {
+			"/etc/nginx/conf.d/default.conf": "server {\n  listen 8080;\n}\n",
+			"/usr/share/nginx/index.html":    "hello\n",
+		},
+	}}
+
+	u, _ := newTestUpdater(t, mock)
+	u.SetConfigFileReader(reader)
+	u.Scan(context.Background(), ScanScheduled)
+
+	queued, ok := u.queue.Get("web")
+	if !ok || queued.ConfigDiff == nil {
+		t.Fatalf("queued = %+v, want a config diff", queued)
+	}
+	diff := queued.ConfigDiff
+	if diff.Error != "" {
+		t.Fatalf("Error = %q", diff.Error)
+	}
+	if want := []string{"/etc/nginx/conf.d", "/usr/share/nginx", "/var/run/docker.sock"}; strings.Join(diff.Paths, ",") != strings.Join(want, ",") {
+		t.Errorf("Paths = %q, want %q", diff.Paths, want)
+	}
+	if len(diff.Changes) != 2 {
+		t.Fatalf("Changes = %+v, want default.conf modified and old.conf removed", diff.Changes)
+	}
+	mod := diff.Changes[0]
+	if mod.Path != "/etc/nginx/conf.d/default.conf" || mod.Change != "modified" || !mod.Overridden || mod.MountPath != "/etc/nginx/conf.d" {
+		t.Errorf("Changes[0] = %+v", mod)
+	}
+	if mod.Diff != "-  listen 80;\n+  listen 8080;\n" {
+		t.Errorf("Diff = %q", mod.Diff)
+	}
+	if diff.Changes[1].Change != "removed" {
+		t.Errorf("Changes[1] = %+v, want removed", diff.Changes[1])
+	}
+	if len(diff.Warnings) != 2 || !strings.Contains(diff.Warnings[0], "mounted over") {
+		t.Errorf("Warnings = %q", diff.Warnings)
+	}
+
+	// The next scan sees the same remote digest and keeps the preview.
+	calls := reader.calls
+	u.Scan(context.Background(), ScanScheduled)
+	if reader.calls != calls {
+		t.Errorf("config files read again for an unchanged update (%d -> %d calls)", calls, reader.calls)
+	}
+	if queued, _ := u.queue.Get("web"); queued.ConfigDiff == nil {
+		t.Error("config diff dropped on rescan")
+	}
+	for _, id := range mock.removeCalls {
+		if id != "new-" {
+			t.Errorf("unexpected container removed: %q", id)
+		}
+	}
+	if len(mock.removeCalls) != 2 {
+		t.Errorf("removeCalls = %q, want both scratch containers removed", mock.removeCalls)
+	}
+}
+
+func TestScanSkipsConfigDiffWhenDisabled(t *testing.T) {
+	mock := newMockDocker()
+	mock.containers = []container.Summary{{
+		ID: "aaa", Names: []string{"/web"}, Image: "nginx:1.25", ImageID: "sha256:old",
+		Mounts: []container.MountPoint{{Type: mount.TypeBind, Destination: "/etc/nginx"}},
+	}}
+	mock.distDigests["nginx:1.25"] = "sha256:remote"
+	mock.imageDigests["nginx:1.25"] = "sha256:local"
+	reader := &fakeConfigReader{mock: mock}
+
+	u, _ := newTestUpdater(t, mock)
+	u.SetConfigFileReader(reader)
+	u.Scan(context.Background(), ScanScheduled)
+
+	if queued, ok := u.queue.Get("web"); !ok || queued.ConfigDiff != nil {
+		t.Errorf("queued = %+v, want no config diff without the setting", queued)
+	}
+	if reader.calls != 0 {
+		t.Errorf("reader called %d times", reader.calls)
+	}
+}
+
+func TestConfigLineDiff(t *testing.T) {
+	tests := []struct{ a, b, want string }{
+		{"a\nb\nc\n", "a\nc\nd\n", "-b\n+d\n"},
+		{"", "x\n", "+x\n"},
+		{"x\n", "", "-x\n"},
+		{"same\n", "same\n", ""},
+	}
+	for _, tt := range tests {
+		if got := configLineDiff(tt.a, tt.b); got != tt.want {
+			t.Errorf("configLineDiff(%q, %q) = %q, want %q", tt.a, tt.b, got, tt.want)
+		}
+	}
+	long := strings.Repeat("line\n", maxConfigDiffLen)
+	if got := configLineDiff("", long); len(got) > maxConfigDiffLen+4 || !strings.HasSuffix(got, "...\n") {
+		t.Errorf("long diff not truncated: %d bytes", len(got))
+	}
+}
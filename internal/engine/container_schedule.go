@@ -0,0 +1,38 @@
+package engine
+
+import (
+	"github.com/Will-Luck/Docker-Sentinel/internal/docker"
+	"github.com/robfig/cron/v3"
+)
+
+// ParseContainerSchedule parses a per-container cron expression
+// (minute hour day-of-month month day-of-week).
+func ParseContainerSchedule(expr string) (cron.Schedule, error) {
+	parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+	return parser.Parse(expr)
+}
+
+// containerSchedule resolves a container's cron schedule.
+// Precedence: store override → sentinel.schedule label.
+func (u *Updater) containerSchedule(name string, labels map[string]string) string {
+	if expr, ok := u.store.GetContainerSchedule(name); ok {
+		return expr
+	}
+	return docker.ContainerSchedule(labels)
+}
+
+// scheduleDue reports whether a container with its own schedule is due for
+// a check. Containers without one, or with an invalid one, are always due.
+func (u *Updater) scheduleDue(name string, labels map[string]string) bool {
+	sched := u.containerSchedule(name, labels)
+	if sched == "" {
+		return true
+	}
+	schedule, err := ParseContainerSchedule(sched)
+	if err != nil {
+		u.log.Warn("invalid schedule", "name", name, "schedule", sched, "error", err)
+		return true
+	}
+	lastChecked, _ := u.store.GetLastContainerScan(name)
+	return lastChecked.IsZero() || !u.clock.Now().Before(schedule.Next(lastChecked))
+}
@@ -0,0 +1,49 @@
+package engine
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleDue(t *testing.T) {
+	u, clk := newTestUpdater(t, newMockDocker())
+	labels := map[string]string{"sentinel.schedule": "0 3 * * *"} // daily at 03:00
+
+	if !u.scheduleDue("app", nil) {
+		t.Error("container without a schedule should always be due")
+	}
+	if !u.scheduleDue("app", labels) {
+		t.Error("never-scanned container should be due")
+	}
+
+	// Scanned at 00:00 on 1 Jan; the label's next window is 03:00.
+	if err := u.store.SetLastContainerScan("app", clk.Now()); err != nil {
+		t.Fatal(err)
+	}
+	clk.Advance(time.Hour)
+	if u.scheduleDue("app", labels) {
+		t.Error("label schedule: due before 03:00")
+	}
+	clk.Advance(2 * time.Hour)
+	if !u.scheduleDue("app", labels) {
+		t.Error("label schedule: not due at 03:00")
+	}
+
+	// A store override takes precedence: Sundays only (4 Jan 2026).
+	if err := u.store.SetContainerSchedule("app", "0 3 * * 0"); err != nil {
+		t.Fatal(err)
+	}
+	if u.scheduleDue("app", labels) {
+		t.Error("override schedule: due before Sunday")
+	}
+	if got := u.containerSchedule("app", labels); got != "0 3 * * 0" {
+		t.Errorf("containerSchedule = %q, want override", got)
+	}
+
+	if err := u.store.SetContainerSchedule("app", "not a cron"); err != nil {
+		t.Fatal(err)
+	}
+	if !u.scheduleDue("app", labels) {
+		t.Error("invalid schedule should not block checks")
+	}
+}
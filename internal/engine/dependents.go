@@ -0,0 +1,57 @@
+package engine
+
+import (
+	"context"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/deps"
+	"github.com/moby/moby/api/types/container"
+)
+
+// dependencyInfos converts a container list into dependency graph input,
+// keeping the network mode and link names the graph needs.
+func dependencyInfos(containers []container.Summary) []deps.ContainerInfo {
+	infos := make([]deps.ContainerInfo, 0, len(containers))
+	for _, c := range containers {
+		infos = append(infos, deps.ContainerInfo{
+			Name:        containerName(c),
+			ID:          c.ID,
+			Labels:      c.Labels,
+			NetworkMode: c.HostConfig.NetworkMode,
+			Names:       c.Names,
+		})
+	}
+	return infos
+}
+
+// restartDependents restarts the running containers that depend on provider,
+// directly or through others, after it was updated. The graph is built from
+// the current container list, so dependents restart in topological order:
+// a container only restarts once everything it depends on has.
+func (u *Updater) restartDependents(ctx context.Context, provider string) {
+	containers, err := u.docker.ListAllContainers(ctx)
+	if err != nil {
+		u.log.Warn("deps: failed to list containers", "error", err)
+		return
+	}
+	running := make(map[string]string, len(containers))
+	for _, c := range containers {
+		if c.State == container.StateRunning {
+			running[containerName(c)] = c.ID
+		}
+	}
+
+	for _, dep := range deps.Build(dependencyInfos(containers)).RestartOrder(provider) {
+		if ctx.Err() != nil {
+			return
+		}
+		id, ok := running[dep]
+		if !ok {
+			u.log.Debug("deps: dependent not running, skipping restart", "dependent", dep, "provider", provider)
+			continue
+		}
+		u.log.Info("restarting dependent container", "dependent", dep, "provider", provider)
+		if err := u.docker.RestartContainer(ctx, id); err != nil {
+			u.log.Warn("failed to restart dependent", "dependent", dep, "error", err)
+		}
+	}
+}
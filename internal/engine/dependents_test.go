@@ -0,0 +1,37 @@
+package engine
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/moby/moby/api/types/container"
+)
+
+func TestRestartDependentsInOrder(t *testing.T) {
+	compose := func(service, dependsOn string) map[string]string {
+		return map[string]string{
+			"com.docker.compose.project":    "shop",
+			"com.docker.compose.service":    service,
+			"com.docker.compose.depends_on": dependsOn,
+		}
+	}
+	member := func(id, name string, labels map[string]string, state container.ContainerState) container.Summary {
+		return container.Summary{ID: id, Names: []string{"/" + name}, Labels: labels, State: state}
+	}
+	mock := newMockDocker()
+	mock.containers = []container.Summary{
+		member("db1", "shop-db-1", compose("db", ""), container.StateRunning),
+		member("web1", "shop-web-1", compose("web", "api:service_started:false"), container.StateRunning),
+		member("api1", "shop-api-1", compose("api", "db:service_healthy:false"), container.StateRunning),
+		member("job1", "shop-job-1", compose("job", "db:service_started:false"), container.StateExited),
+	}
+	u, _ := newTestUpdater(t, mock)
+
+	u.restartDependents(context.Background(), "shop-db-1")
+
+	// api restarts before web, which depends on it; the stopped job stays down.
+	if want := []string{"api1", "web1"}; !reflect.DeepEqual(mock.restartCalls, want) {
+		t.Errorf("restarts = %v, want %v", mock.restartCalls, want)
+	}
+}
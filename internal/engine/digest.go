@@ -3,6 +3,7 @@ package engine
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -35,6 +36,10 @@ type DigestScheduler struct {
 	resetCh  chan struct{}
 	mu       sync.Mutex
 	lastRun  time.Time
+
+	channels      ChannelSource // nil = per-channel schedules disabled
+	containerInfo ContainerInfo // nil = inclusion rules match nothing
+	catchUp       bool          // send digests missed while down on startup
 }
 
 // NewDigestScheduler creates a DigestScheduler.
@@ -55,11 +60,24 @@ func (d *DigestScheduler) SetSettingsReader(sr SettingsReader) {
 	d.settings = sr
 }
 
-// Run starts the digest loop. It calculates the time until the next digest fire,
-// sleeps until then, fires the digest, then repeats. Exits when ctx is cancelled.
+// SetCatchUp enables sending digests whose scheduled time passed while
+// Sentinel was down as soon as the scheduler starts.
+func (d *DigestScheduler) SetCatchUp(enabled bool) {
+	d.catchUp = enabled
+}
+
+// Run starts the digest loop. It calculates the time until the next digest fire
+// (global or per-channel), sleeps until then, fires whatever is due, then
+// repeats. digest_enabled only switches off the global digest; channels
+// with their own schedule keep theirs. Exits when ctx is cancelled.
 func (d *DigestScheduler) Run(ctx context.Context) error {
+	if d.catchUp {
+		d.catchUpMissed(ctx)
+	}
 	for {
-		if !d.isEnabled() {
+		delay, global, due := d.nextFire()
+		if !global && len(due) == 0 {
+			// Global digest off and no channel schedules: wait for a change.
 			select {
 			case <-d.clock.After(1 * time.Minute):
 				continue
@@ -72,12 +90,16 @@ func (d *DigestScheduler) Run(ctx context.Context) error {
 			}
 		}
 
-		delay := d.timeUntilNext()
-		d.log.Info("digest scheduled", "delay", delay)
+		d.log.Info("digest scheduled", "delay", delay, "global", global, "channels", len(due))
 
 		select {
 		case <-d.clock.After(delay):
-			d.fire(ctx)
+			if global && d.isEnabled() {
+				d.fire(ctx)
+			}
+			for _, cd := range due {
+				d.fireChannel(ctx, cd)
+			}
 		case <-d.resetCh:
 			d.log.Info("digest config changed, resetting timer")
 			continue
@@ -96,10 +118,14 @@ func (d *DigestScheduler) SetDigestConfig() {
 	}
 }
 
-// TriggerDigest runs an immediate digest outside the normal timer.
+// TriggerDigest runs an immediate digest outside the normal timer, including
+// every per-channel schedule.
 func (d *DigestScheduler) TriggerDigest(ctx context.Context) {
 	d.log.Info("manual digest triggered")
 	d.fire(ctx)
+	for _, cd := range d.channelDigests() {
+		d.fireChannel(ctx, cd)
+	}
 }
 
 // LastRunTime returns when the last digest was sent.
@@ -109,18 +135,63 @@ func (d *DigestScheduler) LastRunTime() time.Time {
 	return d.lastRun
 }
 
-// fire collects pending updates and sends a consolidated notification.
+// fire collects pending updates and sends the global digest. Channels with
+// their own schedule are skipped; they receive their digest from fireChannel.
 func (d *DigestScheduler) fire(ctx context.Context) {
-	states, err := d.store.AllNotifyStates()
+	names, err := d.pendingNames()
 	if err != nil {
-		d.log.Error("digest: failed to load notify states", "error", err)
+		d.log.Error("digest: failed to collect pending updates", "error", err)
+		return
+	}
+
+	d.mu.Lock()
+	d.lastRun = d.clock.Now()
+	d.mu.Unlock()
+	d.markRun("last_digest_at")
+
+	if len(names) == 0 {
+		d.log.Info("digest: no pending updates")
 		return
 	}
 
+	d.log.Info("sending digest", "containers", len(names))
+
+	event := d.digestEvent(names)
+	scheduled := d.channelDigests()
+	if d.channels != nil && len(scheduled) > 0 {
+		chans, err := d.channels()
+		if err != nil {
+			d.log.Error("digest: failed to load channels", "error", err)
+			return
+		}
+		unscheduled := slices.DeleteFunc(slices.Clone(chans), func(c notify.Channel) bool {
+			return slices.ContainsFunc(scheduled, func(cd ChannelDigest) bool { return cd.ChannelID == c.ID })
+		})
+		d.sendTo(ctx, unscheduled, chans, event)
+	} else {
+		d.notifier.Notify(ctx, event)
+	}
+
+	if d.events != nil {
+		d.events.Publish(events.SSEEvent{
+			Type:      events.EventDigestReady,
+			Message:   digestMessage(names),
+			Timestamp: d.clock.Now(),
+		})
+	}
+}
+
+// pendingNames returns the sorted, de-duplicated names of containers with
+// pending updates, excluding muted ones.
+func (d *DigestScheduler) pendingNames() ([]string, error) {
+	states, err := d.store.AllNotifyStates()
+	if err != nil {
+		return nil, fmt.Errorf("load notify states: %w", err)
+	}
+
 	prefs, err := d.store.AllNotifyPrefs()
 	if err != nil {
-		d.log.Error("digest: failed to load notify prefs", "error", err)
-		return
+		return nil, fmt.Errorf("load notify prefs: %w", err)
 	}
 
 	// Collect containers with pending updates, excluding muted ones.
@@ -138,38 +209,87 @@ func (d *DigestScheduler) fire(ctx context.Context) {
 		}
 	}
 
-	d.mu.Lock()
-	d.lastRun = d.clock.Now()
-	d.mu.Unlock()
-
-	if len(seen) == 0 {
-		d.log.Info("digest: no pending updates")
-		return
-	}
-
 	// Sort for deterministic output.
 	names := make([]string, 0, len(seen))
 	for name := range seen {
 		names = append(names, name)
 	}
 	sort.Strings(names)
+	return names, nil
+}
 
-	msg := fmt.Sprintf("Pending updates: %s (%d containers awaiting action)", strings.Join(names, ", "), len(names))
-	d.log.Info("sending digest", "containers", len(names))
-
-	d.notifier.Notify(ctx, notify.Event{
+// digestEvent builds the notification event for a set of pending names,
+// summarising any of them that have waited past the stale threshold.
+func (d *DigestScheduler) digestEvent(names []string) notify.Event {
+	now := d.clock.Now()
+	var stale string
+	if d.settings != nil {
+		val, _ := d.settings.LoadSetting("queue_stale_after")
+		stale = staleSummary(d.queue.Stale(QueueStaleAfter(val), now), names, now)
+	}
+	event := notify.Event{
 		Type:           notify.EventDigest,
-		ContainerName:  names[0],
 		ContainerNames: names,
-		Timestamp:      d.clock.Now(),
-	})
+		Message:        stale,
+		Timestamp:      now,
+	}
+	if len(names) > 0 {
+		event.ContainerName = names[0]
+	}
+	return event
+}
 
-	if d.events != nil {
-		d.events.Publish(events.SSEEvent{
-			Type:      events.EventDigestReady,
-			Message:   msg,
-			Timestamp: d.clock.Now(),
-		})
+// digestMessage renders the human-readable digest summary.
+func digestMessage(names []string) string {
+	if len(names) == 0 {
+		return "No pending updates"
+	}
+	return fmt.Sprintf("Pending updates: %s (%d containers awaiting action)", strings.Join(names, ", "), len(names))
+}
+
+// markRun persists when a digest was last sent so missed runs can be
+// detected after a restart.
+func (d *DigestScheduler) markRun(key string) {
+	if err := d.store.SaveSetting(key, d.clock.Now().UTC().Format(time.RFC3339)); err != nil {
+		d.log.Debug("digest: failed to persist last run", "key", key, "error", err)
+	}
+}
+
+// lastRunAt returns a persisted last-run time, or zero when never recorded.
+func (d *DigestScheduler) lastRunAt(key string) time.Time {
+	val, err := d.store.LoadSetting(key)
+	if err != nil || val == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339, val)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// catchUpMissed sends the global digest (when enabled) and any channel
+// digests whose most recent scheduled time passed without a run. Digests that have never run
+// are left to their normal schedule so a fresh install doesn't fire at once.
+func (d *DigestScheduler) catchUpMissed(ctx context.Context) {
+	now := d.clock.Now()
+
+	if d.isEnabled() {
+		last := d.lastRunAt("last_digest_at")
+		prev := now.Add(d.timeUntilNext()).Add(-d.digestInterval())
+		if !last.IsZero() && last.Before(prev) {
+			d.log.Info("sending catch-up digest, scheduled run was missed while down", "last_run", last, "missed", prev)
+			d.fire(ctx)
+		}
+	}
+
+	for _, cd := range d.channelDigests() {
+		last := d.lastRunAt(channelRunKey(cd.ChannelID))
+		prev := previousChannelFire(now, cd)
+		if !last.IsZero() && last.Before(prev) {
+			d.log.Info("sending catch-up channel digest", "channel", cd.ChannelID, "last_run", last, "missed", prev)
+			d.fireChannel(ctx, cd)
+		}
 	}
 }
 
@@ -0,0 +1,366 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/notify"
+	"github.com/Will-Luck/Docker-Sentinel/internal/registry"
+)
+
+// ErrDigestChannelNotFound is returned when a digest preview names a channel
+// that does not exist.
+var ErrDigestChannelNotFound = errors.New("notification channel not found")
+
+// ErrDigestChannelDisabled is returned when an on-demand summary names a
+// channel that is switched off.
+var ErrDigestChannelDisabled = errors.New("notification channel is disabled")
+
+// ChannelDigest is a per-channel digest schedule. Channels with a schedule
+// receive their own digest at their own time instead of the global one.
+type ChannelDigest struct {
+	ChannelID string   `json:"channel_id"`
+	Time      string   `json:"time"`               // HH:MM, defaults to 09:00
+	Weekday   string   `json:"weekday,omitempty"`  // e.g. "monday"; empty = daily
+	Policies  []string `json:"policies,omitempty"` // only include these policies; empty = all
+	Stacks    []string `json:"stacks,omitempty"`   // only include these compose projects; empty = all
+}
+
+// ChannelSource lists the configured notification channels.
+type ChannelSource func() ([]notify.Channel, error)
+
+// ContainerInfo resolves a container's effective policy and compose stack
+// for digest inclusion rules.
+type ContainerInfo func(ctx context.Context, name string) (policy, stack string)
+
+// ParseChannelDigests decodes and validates the digest_channels setting.
+func ParseChannelDigests(raw string) ([]ChannelDigest, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	var list []ChannelDigest
+	if err := json.Unmarshal([]byte(raw), &list); err != nil {
+		return nil, fmt.Errorf("invalid digest channel schedules: %w", err)
+	}
+	seen := make(map[string]bool, len(list))
+	for i := range list {
+		cd := &list[i]
+		if cd.ChannelID == "" {
+			return nil, fmt.Errorf("schedule %d: channel_id is required", i)
+		}
+		if seen[cd.ChannelID] {
+			return nil, fmt.Errorf("duplicate schedule for channel %s", cd.ChannelID)
+		}
+		seen[cd.ChannelID] = true
+		if cd.Time == "" {
+			cd.Time = "09:00"
+		}
+		if _, err := time.Parse("15:04", cd.Time); err != nil {
+			return nil, fmt.Errorf("channel %s: invalid time %q, use HH:MM", cd.ChannelID, cd.Time)
+		}
+		if cd.Weekday != "" {
+			if _, ok := parseWeekday(cd.Weekday); !ok {
+				return nil, fmt.Errorf("channel %s: invalid weekday %q", cd.ChannelID, cd.Weekday)
+			}
+		}
+		for _, p := range cd.Policies {
+			if err := ValidatePolicy(p); err != nil {
+				return nil, fmt.Errorf("channel %s: %w", cd.ChannelID, err)
+			}
+		}
+	}
+	return list, nil
+}
+
+// SetChannelSource attaches the notification channel list used for
+// per-channel digests.
+func (d *DigestScheduler) SetChannelSource(src ChannelSource) {
+	d.channels = src
+}
+
+// SetContainerInfo attaches the policy/stack resolver used by inclusion rules.
+func (d *DigestScheduler) SetContainerInfo(fn ContainerInfo) {
+	d.containerInfo = fn
+}
+
+// PreviewDigest returns the containers and message that would be sent to a
+// channel right now. An empty channelID previews the global digest.
+func (d *DigestScheduler) PreviewDigest(ctx context.Context, channelID string) ([]string, string, error) {
+	names, err := d.pendingNames()
+	if err != nil {
+		return nil, "", err
+	}
+	if channelID != "" {
+		if d.channels != nil {
+			chans, err := d.channels()
+			if err != nil {
+				return nil, "", fmt.Errorf("load channels: %w", err)
+			}
+			if !slices.ContainsFunc(chans, func(c notify.Channel) bool { return c.ID == channelID }) {
+				return nil, "", ErrDigestChannelNotFound
+			}
+		}
+		if cd, ok := d.channelSchedule(channelID); ok {
+			names = d.filterForChannel(ctx, cd, names)
+		}
+	}
+	if names == nil {
+		names = []string{}
+	}
+	return names, digestMessage(names), nil
+}
+
+// channelDigests returns the configured per-channel schedules. Invalid
+// settings are logged and treated as no schedules.
+func (d *DigestScheduler) channelDigests() []ChannelDigest {
+	raw, err := d.store.LoadSetting("digest_channels")
+	if err != nil || raw == "" {
+		return nil
+	}
+	list, err := ParseChannelDigests(raw)
+	if err != nil {
+		d.log.Warn("digest: ignoring invalid channel schedules", "error", err)
+		return nil
+	}
+	return list
+}
+
+// channelSchedule returns the schedule for one channel, if any.
+func (d *DigestScheduler) channelSchedule(channelID string) (ChannelDigest, bool) {
+	for _, cd := range d.channelDigests() {
+		if cd.ChannelID == channelID {
+			return cd, true
+		}
+	}
+	return ChannelDigest{}, false
+}
+
+// nextFire returns the delay until the earliest scheduled digest, whether
+// the global digest is due then, and which channel digests are due then.
+// The global digest only counts while digest_enabled is on; per-channel
+// schedules always do. When neither applies nothing is due.
+func (d *DigestScheduler) nextFire() (time.Duration, bool, []ChannelDigest) {
+	now := d.clock.Now()
+	var earliest time.Time
+	global := d.isEnabled()
+	if global {
+		earliest = now.Add(d.timeUntilNext())
+	}
+	var due []ChannelDigest
+	for _, cd := range d.channelDigests() {
+		next := nextChannelFire(now, cd)
+		switch {
+		case earliest.IsZero() || next.Before(earliest):
+			earliest, global, due = next, false, []ChannelDigest{cd}
+		case next.Equal(earliest):
+			due = append(due, cd)
+		}
+	}
+	if earliest.IsZero() {
+		return 0, false, nil
+	}
+	delay := earliest.Sub(now)
+	if delay < 0 {
+		delay = 1 * time.Minute
+	}
+	return delay, global, due
+}
+
+// nextChannelFire returns the next time a channel schedule fires after now.
+func nextChannelFire(now time.Time, cd ChannelDigest) time.Time {
+	hour, min := 9, 0
+	if t, err := time.Parse("15:04", cd.Time); err == nil {
+		hour, min = t.Hour(), t.Minute()
+	}
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, min, 0, 0, now.Location())
+	if wd, ok := parseWeekday(cd.Weekday); ok {
+		next = next.AddDate(0, 0, (int(wd)-int(next.Weekday())+7)%7)
+		if !now.Before(next) {
+			next = next.AddDate(0, 0, 7)
+		}
+		return next
+	}
+	if !now.Before(next) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// previousChannelFire returns the most recent time a channel schedule was
+// due at or before now.
+func previousChannelFire(now time.Time, cd ChannelDigest) time.Time {
+	next := nextChannelFire(now, cd)
+	if _, weekly := parseWeekday(cd.Weekday); weekly {
+		return next.AddDate(0, 0, -7)
+	}
+	return next.AddDate(0, 0, -1)
+}
+
+// channelRunKey is the settings key holding a channel digest's last run.
+func channelRunKey(channelID string) string {
+	return "last_digest_at:" + channelID
+}
+
+// parseWeekday accepts full or three-letter English weekday names.
+func parseWeekday(s string) (time.Weekday, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return 0, false
+	}
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		full := strings.ToLower(wd.String())
+		if s == full || s == full[:3] {
+			return wd, true
+		}
+	}
+	return 0, false
+}
+
+// filterForChannel applies a schedule's inclusion rules to pending names.
+func (d *DigestScheduler) filterForChannel(ctx context.Context, cd ChannelDigest, names []string) []string {
+	if len(cd.Policies) == 0 && len(cd.Stacks) == 0 {
+		return names
+	}
+	var out []string
+	for _, name := range names {
+		var policy, stack string
+		if d.containerInfo != nil {
+			policy, stack = d.containerInfo(ctx, name)
+		}
+		if len(cd.Policies) > 0 && !slices.Contains(cd.Policies, policy) {
+			continue
+		}
+		if len(cd.Stacks) > 0 && !slices.Contains(cd.Stacks, stack) {
+			continue
+		}
+		out = append(out, name)
+	}
+	return out
+}
+
+// fireChannel sends a digest to a single scheduled channel.
+func (d *DigestScheduler) fireChannel(ctx context.Context, cd ChannelDigest) {
+	if d.channels == nil {
+		return
+	}
+	chans, err := d.channels()
+	if err != nil {
+		d.log.Error("digest: failed to load channels", "error", err)
+		return
+	}
+	idx := slices.IndexFunc(chans, func(c notify.Channel) bool { return c.ID == cd.ChannelID })
+	if idx < 0 || !chans[idx].Enabled {
+		return
+	}
+	d.markRun(channelRunKey(cd.ChannelID))
+
+	names, err := d.pendingNames()
+	if err != nil {
+		d.log.Error("digest: failed to collect pending updates", "error", err)
+		return
+	}
+	names = d.filterForChannel(ctx, cd, names)
+	if len(names) == 0 {
+		d.log.Info("digest: no pending updates for channel", "channel", chans[idx].Name)
+		return
+	}
+
+	d.log.Info("sending channel digest", "channel", chans[idx].Name, "containers", len(names))
+	d.sendTo(ctx, chans[idx:idx+1], chans, d.digestEvent(names))
+}
+
+// SendSummary sends the current state to one channel straight away,
+// rendered as a digest: pending updates plus the failures and offline hosts
+// the caller collected. Unlike a scheduled digest it is sent even when
+// nothing is pending. It returns the message that was sent.
+func (d *DigestScheduler) SendSummary(ctx context.Context, channelID string, failures, offline []string) (string, error) {
+	if d.channels == nil {
+		return "", ErrDigestChannelNotFound
+	}
+	chans, err := d.channels()
+	if err != nil {
+		return "", fmt.Errorf("load channels: %w", err)
+	}
+	idx := slices.IndexFunc(chans, func(c notify.Channel) bool { return c.ID == channelID })
+	if idx < 0 {
+		return "", ErrDigestChannelNotFound
+	}
+	if !chans[idx].Enabled {
+		return "", ErrDigestChannelDisabled
+	}
+
+	names, err := d.pendingNames()
+	if err != nil {
+		return "", err
+	}
+	event := d.digestEvent(names)
+	event.Message = summaryMessage(names, failures, offline, event.Message)
+
+	d.log.Info("sending on-demand summary", "channel", chans[idx].Name, "containers", len(names))
+	if err := d.sendTo(ctx, chans[idx:idx+1], chans, event); err != nil {
+		return "", err
+	}
+	return event.Message, nil
+}
+
+// summaryMessage renders an on-demand summary, one line per section. stale
+// is the digest's stale approval line, if any.
+func summaryMessage(names, failures, offline []string, stale string) string {
+	lines := []string{digestMessage(names)}
+	if stale != "" {
+		lines = append(lines, stale)
+	}
+	if len(failures) > 0 {
+		lines = append(lines, fmt.Sprintf("Recent failures: %s", strings.Join(failures, ", ")))
+	} else {
+		lines = append(lines, "No recent failures")
+	}
+	if len(offline) > 0 {
+		lines = append(lines, fmt.Sprintf("Hosts offline: %s", strings.Join(offline, ", ")))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// sendTo delivers an event directly to the given channels, bypassing the
+// shared Multi so scheduled channels can be excluded from the global digest.
+// Groups in chans are resolved against all. Failures are logged, and the
+// last one is returned for callers that report it.
+func (d *DigestScheduler) sendTo(ctx context.Context, chans, all []notify.Channel, event notify.Event) error {
+	notifiers := notify.BuildChannels(chans, all, func(ch notify.Channel, err error) {
+		d.log.Warn("digest: failed to build notifier", "channel", ch.Name, "error", err)
+	})
+	if len(notifiers) == 0 {
+		return errors.New("no notifier could be built for the channel")
+	}
+	var lastErr error
+	for _, n := range notifiers {
+		if err := n.Send(ctx, event); err != nil {
+			d.log.Error("digest: send failed", "channel", n.Name(), "error", err)
+			lastErr = fmt.Errorf("send to %s: %w", n.Name(), err)
+		}
+	}
+	return lastErr
+}
+
+// DigestContainerInfo resolves a local container's effective policy and
+// compose stack. It satisfies ContainerInfo for the digest scheduler.
+func (u *Updater) DigestContainerInfo(ctx context.Context, name string) (string, string) {
+	containers, err := u.docker.ListAllContainers(ctx)
+	if err != nil {
+		return "", ""
+	}
+	for _, c := range containers {
+		if containerName(c) != name {
+			continue
+		}
+		tag := registry.ExtractTag(c.Image)
+		resolved := ResolvePolicy(u.store, c.Labels, name, tag, u.cfg.DefaultPolicy(), u.cfg.LatestAutoUpdate())
+		return resolved.Policy, c.Labels["com.docker.compose.project"]
+	}
+	return "", ""
+}
@@ -0,0 +1,239 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/logging"
+	"github.com/Will-Luck/Docker-Sentinel/internal/notify"
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+)
+
+func newTestDigestScheduler(t *testing.T, now time.Time) (*DigestScheduler, *store.Store) {
+	t.Helper()
+	s := testStore(t)
+	q := NewQueue(s, nil, nil)
+	log := logging.New(false)
+	d := NewDigestScheduler(s, q, notify.NewMulti(log), nil, log, newMockClock(now))
+	d.SetSettingsReader(s)
+	return d, s
+}
+
+func TestNextChannelFire(t *testing.T) {
+	// 2026-01-01 is a Thursday.
+	now := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		cd   ChannelDigest
+		want time.Time
+	}{
+		{"daily later today", ChannelDigest{Time: "18:30"}, time.Date(2026, 1, 1, 18, 30, 0, 0, time.UTC)},
+		{"daily already passed", ChannelDigest{Time: "09:00"}, time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)},
+		{"weekly monday", ChannelDigest{Time: "09:00", Weekday: "monday"}, time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)},
+		{"weekly today later", ChannelDigest{Time: "12:00", Weekday: "thu"}, time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)},
+		{"weekly today passed", ChannelDigest{Time: "08:00", Weekday: "Thursday"}, time.Date(2026, 1, 8, 8, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextChannelFire(now, tt.cd); !got.Equal(tt.want) {
+				t.Errorf("nextChannelFire = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseChannelDigests(t *testing.T) {
+	list, err := ParseChannelDigests(`[{"channel_id":"a","weekday":"mon"}]`)
+	if err != nil {
+		t.Fatalf("ParseChannelDigests: %v", err)
+	}
+	if len(list) != 1 || list[0].Time != "09:00" {
+		t.Errorf("got %+v, want one schedule defaulting to 09:00", list)
+	}
+
+	bad := []string{
+		`not json`,
+		`[{"time":"09:00"}]`,
+		`[{"channel_id":"a","time":"25:00"}]`,
+		`[{"channel_id":"a","weekday":"someday"}]`,
+		`[{"channel_id":"a","policies":["yolo"]}]`,
+		`[{"channel_id":"a"},{"channel_id":"a"}]`,
+	}
+	for _, raw := range bad {
+		if _, err := ParseChannelDigests(raw); err == nil {
+			t.Errorf("ParseChannelDigests(%s) succeeded, want error", raw)
+		}
+	}
+}
+
+func TestNextFirePicksEarliestChannel(t *testing.T) {
+	d, s := newTestDigestScheduler(t, time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC))
+	// Global digest defaults to 09:00 daily, so next is tomorrow 09:00.
+	_ = s.SaveSetting("digest_channels", `[{"channel_id":"ntfy","time":"12:00"},{"channel_id":"email","time":"09:00","weekday":"monday"}]`)
+
+	delay, global, due := d.nextFire()
+	if delay != 2*time.Hour {
+		t.Errorf("delay = %v, want 2h", delay)
+	}
+	if global {
+		t.Error("global digest should not be due before the ntfy schedule")
+	}
+	if len(due) != 1 || due[0].ChannelID != "ntfy" {
+		t.Errorf("due = %+v, want ntfy only", due)
+	}
+}
+
+func TestNextFireWithGlobalDigestDisabled(t *testing.T) {
+	d, s := newTestDigestScheduler(t, time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC))
+	_ = s.SaveSetting("digest_enabled", "false")
+
+	if _, global, due := d.nextFire(); global || len(due) != 0 {
+		t.Errorf("global = %v, due = %+v, want nothing scheduled", global, due)
+	}
+
+	// A channel schedule still fires with the global digest off, even when
+	// it comes after the global digest's time.
+	_ = s.SaveSetting("digest_channels", `[{"channel_id":"email","time":"09:00","weekday":"monday"}]`)
+	delay, global, due := d.nextFire()
+	if global {
+		t.Error("global digest should not be scheduled while disabled")
+	}
+	if len(due) != 1 || due[0].ChannelID != "email" {
+		t.Errorf("due = %+v, want email", due)
+	}
+	if want := 4*24*time.Hour - time.Hour; delay != want {
+		t.Errorf("delay = %v, want %v", delay, want)
+	}
+}
+
+func TestPreviewDigestAppliesRules(t *testing.T) {
+	d, s := newTestDigestScheduler(t, time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC))
+	for _, name := range []string{"web", "db", "cache"} {
+		if err := s.SetNotifyState(name, &store.NotifyState{LastDigest: "sha256:x"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = s.SaveSetting("digest_channels", `[{"channel_id":"ntfy","policies":["manual"],"stacks":["app"]}]`)
+
+	d.SetChannelSource(func() ([]notify.Channel, error) {
+		return []notify.Channel{{ID: "ntfy", Enabled: true}, {ID: "email", Enabled: true}}, nil
+	})
+	info := map[string][2]string{
+		"web":   {"manual", "app"},
+		"db":    {"auto", "app"},
+		"cache": {"manual", "other"},
+	}
+	d.SetContainerInfo(func(_ context.Context, name string) (string, string) {
+		return info[name][0], info[name][1]
+	})
+
+	ctx := context.Background()
+	names, msg, err := d.PreviewDigest(ctx, "ntfy")
+	if err != nil {
+		t.Fatalf("PreviewDigest(ntfy): %v", err)
+	}
+	if !slices.Equal(names, []string{"web"}) {
+		t.Errorf("ntfy preview = %v, want [web]", names)
+	}
+	if msg == "" {
+		t.Error("expected a rendered message")
+	}
+
+	// A channel without its own schedule gets the unfiltered global digest.
+	names, _, err = d.PreviewDigest(ctx, "email")
+	if err != nil {
+		t.Fatalf("PreviewDigest(email): %v", err)
+	}
+	if !slices.Equal(names, []string{"cache", "db", "web"}) {
+		t.Errorf("email preview = %v, want all three", names)
+	}
+
+	if _, _, err := d.PreviewDigest(ctx, "missing"); !errors.Is(err, ErrDigestChannelNotFound) {
+		t.Errorf("PreviewDigest(missing) error = %v, want ErrDigestChannelNotFound", err)
+	}
+}
+
+func TestDigestCatchUpMissed(t *testing.T) {
+	// 10:00 on a Thursday: today's 09:00 global digest has passed.
+	now := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	d, s := newTestDigestScheduler(t, now)
+	if err := s.SetNotifyState("web", &store.NotifyState{LastDigest: "sha256:x"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Last sent yesterday morning, so today's 09:00 run was missed.
+	_ = s.SaveSetting("last_digest_at", now.Add(-25*time.Hour).Format(time.RFC3339))
+	d.catchUpMissed(context.Background())
+	if d.LastRunTime().IsZero() {
+		t.Fatal("expected a catch-up digest to be sent")
+	}
+	if got := d.lastRunAt("last_digest_at"); !got.Equal(now) {
+		t.Errorf("last_digest_at = %v, want %v", got, now)
+	}
+
+	// Sent after today's 09:00: nothing to catch up.
+	d2, s2 := newTestDigestScheduler(t, now)
+	_ = s2.SaveSetting("last_digest_at", now.Add(-30*time.Minute).Format(time.RFC3339))
+	d2.catchUpMissed(context.Background())
+	if !d2.LastRunTime().IsZero() {
+		t.Error("digest should not be caught up when the last run is current")
+	}
+}
+
+func TestPreviousChannelFire(t *testing.T) {
+	now := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC) // Thursday
+	if got, want := previousChannelFire(now, ChannelDigest{Time: "09:00"}), time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("daily previous = %v, want %v", got, want)
+	}
+	if got, want := previousChannelFire(now, ChannelDigest{Time: "09:00", Weekday: "monday"}), time.Date(2025, 12, 29, 9, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("weekly previous = %v, want %v", got, want)
+	}
+}
+
+func TestSendSummary(t *testing.T) {
+	d, s := newTestDigestScheduler(t, time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC))
+	if err := s.SetNotifyState("web", &store.NotifyState{LastDigest: "sha256:x"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var got notify.Event
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer srv.Close()
+	settings, _ := json.Marshal(notify.WebhookSettings{URL: srv.URL})
+	d.SetChannelSource(func() ([]notify.Channel, error) {
+		return []notify.Channel{
+			{ID: "hook", Name: "hook", Type: notify.ProviderWebhook, Enabled: true, Settings: settings},
+			{ID: "off", Name: "off", Type: notify.ProviderWebhook, Settings: settings},
+		}, nil
+	})
+
+	ctx := context.Background()
+	msg, err := d.SendSummary(ctx, "hook", []string{"db"}, []string{"edge-1"})
+	if err != nil {
+		t.Fatalf("SendSummary: %v", err)
+	}
+	if got.Type != notify.EventDigest || !slices.Equal(got.ContainerNames, []string{"web"}) {
+		t.Errorf("sent event = %+v, want a digest for web", got)
+	}
+	for _, want := range []string{"Pending updates: web", "Recent failures: db", "Hosts offline: edge-1"} {
+		if !strings.Contains(msg, want) || !strings.Contains(got.Message, want) {
+			t.Errorf("message %q missing %q", got.Message, want)
+		}
+	}
+
+	if _, err := d.SendSummary(ctx, "off", nil, nil); !errors.Is(err, ErrDigestChannelDisabled) {
+		t.Errorf("SendSummary(off) error = %v, want ErrDigestChannelDisabled", err)
+	}
+	if _, err := d.SendSummary(ctx, "missing", nil, nil); !errors.Is(err, ErrDigestChannelNotFound) {
+		t.Errorf("SendSummary(missing) error = %v, want ErrDigestChannelNotFound", err)
+	}
+}
@@ -0,0 +1,242 @@
+package engine
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/clock"
+	"github.com/Will-Luck/Docker-Sentinel/internal/docker"
+	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+	"github.com/Will-Luck/Docker-Sentinel/internal/logging"
+	"github.com/Will-Luck/Docker-Sentinel/internal/registry"
+)
+
+// Reconnect backoff for the Docker event stream.
+const (
+	discoveryRetryMin = 5 * time.Second
+	discoveryRetryMax = 1 * time.Minute
+)
+
+// ContainerEventSource streams container lifecycle events. Implemented by
+// *docker.Client.
+type ContainerEventSource interface {
+	WatchContainerEvents(ctx context.Context) (<-chan docker.ContainerEvent, <-chan error)
+}
+
+// Discovery picks up containers started between scans from the Docker event
+// stream, so a freshly deployed service appears within seconds instead of
+// after the next poll. Controlled by two settings:
+//   - container_discovery: "false" turns discovery off (default on)
+//   - discovery_check: "true" also checks the new container's registry
+//     straight away (default off)
+type Discovery struct {
+	updater  *Updater
+	source   ContainerEventSource
+	log      *logging.Logger
+	clock    clock.Clock
+	settings SettingsReader
+
+	mu    sync.Mutex
+	known map[string]bool // container IDs already seen
+}
+
+// NewDiscovery creates a Discovery watching src.
+func NewDiscovery(u *Updater, src ContainerEventSource, log *logging.Logger, clk clock.Clock) *Discovery {
+	return &Discovery{
+		updater: u,
+		source:  src,
+		log:     log,
+		clock:   clk,
+		known:   make(map[string]bool),
+	}
+}
+
+// SetSettingsReader attaches a settings reader for the discovery toggles.
+func (d *Discovery) SetSettingsReader(sr SettingsReader) {
+	d.settings = sr
+}
+
+// Run watches for new containers until ctx is cancelled, reconnecting to
+// the event stream with backoff. Containers started while disconnected are
+// picked up on reconnect.
+func (d *Discovery) Run(ctx context.Context) {
+	d.sync(ctx, false)
+	wait := discoveryRetryMin
+	for ctx.Err() == nil {
+		evts, errs := d.source.WatchContainerEvents(ctx)
+		connected := d.clock.Now()
+		err := d.consume(ctx, evts, errs)
+		if ctx.Err() != nil {
+			return
+		}
+		if d.clock.Since(connected) > discoveryRetryMax {
+			wait = discoveryRetryMin // stream was healthy for a while
+		}
+		d.log.Warn("docker event stream lost, reconnecting", "error", err, "retry_in", wait)
+		select {
+		case <-ctx.Done():
+			return
+		case <-d.clock.After(wait):
+		}
+		wait = min(wait*2, discoveryRetryMax)
+		d.sync(ctx, true)
+	}
+}
+
+// consume handles events until the stream ends, returning its error.
+func (d *Discovery) consume(ctx context.Context, evts <-chan docker.ContainerEvent, errs <-chan error) error {
+	for {
+		select {
+		case evt, ok := <-evts:
+			if !ok {
+				return <-errs
+			}
+			switch evt.Action {
+			case "start":
+				d.discover(ctx, evt.ID, evt.Name, evt.Image, evt.Labels)
+			case "destroy":
+				d.mu.Lock()
+				delete(d.known, evt.ID)
+				d.mu.Unlock()
+			}
+		case err := <-errs:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// sync records the running containers as known. With announce set, any not
+// seen before are handled as newly discovered.
+func (d *Discovery) sync(ctx context.Context, announce bool) {
+	containers, err := d.updater.docker.ListContainers(ctx)
+	if err != nil {
+		d.log.Warn("discovery: failed to list containers", "error", err)
+		return
+	}
+	for _, c := range containers {
+		if announce {
+			d.discover(ctx, c.ID, containerName(c), c.Image, c.Labels)
+			continue
+		}
+		d.mu.Lock()
+		d.known[c.ID] = true
+		d.mu.Unlock()
+	}
+}
+
+// discover registers a container the first time it is seen. Containers
+// Sentinel is replacing itself are not new and are ignored.
+func (d *Discovery) discover(ctx context.Context, id, name, image string, labels map[string]string) {
+	d.mu.Lock()
+	seen := d.known[id]
+	d.known[id] = true
+	d.mu.Unlock()
+	if seen || !d.enabled() {
+		return
+	}
+
+	u := d.updater
+	switch {
+	case name == "" || image == "":
+		return
+	case labels["com.docker.swarm.task"] != "" || labels["com.docker.swarm.service.id"] != "":
+		return // handled per service by the scan
+	case labels["sentinel.maintenance"] == "true" || strings.HasSuffix(name, candidateSuffix) || strings.HasSuffix(name, replacementSuffix) || u.IsUpdating(name):
+		return // our own replacement or blue-green candidate
+	}
+	if _, unmanaged := u.store.AllUnmanaged()[name]; unmanaged {
+		return
+	}
+
+	tag := registry.ExtractTag(image)
+	resolved := ResolvePolicy(u.store, labels, name, tag, u.cfg.DefaultPolicy(), u.cfg.LatestAutoUpdate())
+	d.log.Info("new container discovered", "name", name, "image", image, "policy", resolved.Policy, "source", resolved.Source)
+	u.publishEvent(events.EventContainerState, name, "discovered")
+
+	if !d.checkEnabled() || isSentinel(labels) || docker.Policy(resolved.Policy) == docker.PolicyPinned {
+		return
+	}
+	if excluded, _ := u.loadFilters().Excluded(name, image, labels); excluded {
+		return
+	}
+	u.checkDiscovered(ctx, id, name, image, labels, docker.Policy(resolved.Policy))
+}
+
+func (d *Discovery) enabled() bool {
+	if d.settings == nil {
+		return true
+	}
+	v, _ := d.settings.LoadSetting("container_discovery")
+	return v != "false"
+}
+
+func (d *Discovery) checkEnabled() bool {
+	if d.settings == nil {
+		return false
+	}
+	v, _ := d.settings.LoadSetting("discovery_check")
+	return v == "true"
+}
+
+// checkDiscovered checks a newly discovered container against its registry.
+// Updates for manual containers are queued; auto-updates are left to the
+// next scan so a fresh deployment is never replaced within seconds.
+func (u *Updater) checkDiscovered(ctx context.Context, id, name, image string, labels map[string]string, policy docker.Policy) {
+	image, pinnedDigest, digestPinned := checkRef(image, labels)
+	if digestPinned {
+		return
+	}
+	check := u.checkVersions(ctx, image, pinnedDigest, labels)
+	if check.Error != nil {
+		u.log.Warn("discovery: registry check failed", "name", name, "image", image, "error", check.Error)
+		return
+	}
+	if check.IsLocal {
+		return
+	}
+	_ = u.store.SetLastContainerScan(name, u.clock.Now())
+	if !check.UpdateAvailable {
+		u.recordVersion(name, labels, image, check)
+		return
+	}
+	if len(check.NewerVersions) > 0 {
+		if ignored, _ := u.store.GetIgnoredVersions(name); len(ignored) > 0 {
+			var kept []string
+			for _, v := range check.NewerVersions {
+				if !slices.Contains(ignored, v) {
+					kept = append(kept, v)
+				}
+			}
+			if len(kept) == 0 {
+				u.recordVersion(name, labels, image, registry.CheckResult{ResolvedCurrentVersion: check.ResolvedCurrentVersion})
+				return
+			}
+			check.NewerVersions = kept
+		}
+	}
+	u.recordVersion(name, labels, image, check)
+	u.log.Info("update available for new container", "name", name, "image", image)
+	u.publishEvent(events.EventContainerUpdate, name, "update available")
+
+	if policy != docker.PolicyManual {
+		return
+	}
+	u.queue.Add(PendingUpdate{
+		ContainerID:            id,
+		ContainerName:          name,
+		CurrentImage:           image,
+		CurrentDigest:          check.LocalDigest,
+		RemoteDigest:           check.RemoteDigest,
+		DetectedAt:             u.clock.Now(),
+		NewerVersions:          check.NewerVersions,
+		Candidates:             check.Candidates,
+		ResolvedCurrentVersion: check.ResolvedCurrentVersion,
+		ResolvedTargetVersion:  check.ResolvedTargetVersion,
+	})
+	u.publishEvent(events.EventQueueChange, name, "queued for approval")
+}
@@ -0,0 +1,86 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/docker"
+	"github.com/moby/moby/api/types/container"
+)
+
+// fakeEventSource hands out one event stream per WatchContainerEvents call.
+type fakeEventSource struct {
+	streams chan chan docker.ContainerEvent
+}
+
+func (f *fakeEventSource) WatchContainerEvents(ctx context.Context) (<-chan docker.ContainerEvent, <-chan error) {
+	errs := make(chan error, 1)
+	select {
+	case ch := <-f.streams:
+		return ch, errs
+	case <-ctx.Done():
+		errs <- ctx.Err()
+		return nil, errs
+	}
+}
+
+func TestDiscoveryQueuesNewManualContainer(t *testing.T) {
+	mock := newMockDocker()
+	mock.containers = []container.Summary{
+		{ID: "old", Names: []string{"/existing"}, Image: "docker.io/library/redis:7"},
+	}
+	mock.imageDigests["docker.io/library/nginx:1.25"] = "docker.io/library/nginx@sha256:old"
+	mock.distDigests["docker.io/library/nginx:1.25"] = "sha256:new"
+	u, clk := newTestUpdater(t, mock)
+	_ = u.store.SaveSetting("discovery_check", "true")
+
+	src := &fakeEventSource{streams: make(chan chan docker.ContainerEvent, 1)}
+	stream := make(chan docker.ContainerEvent)
+	src.streams <- stream
+	d := NewDiscovery(u, src, u.log, clk)
+	d.SetSettingsReader(u.store)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() { d.Run(ctx); close(done) }()
+
+	// A restart of a container that was already running is not new.
+	stream <- docker.ContainerEvent{Action: "start", ID: "old", Name: "existing", Image: "docker.io/library/redis:7"}
+	stream <- docker.ContainerEvent{Action: "start", ID: "bbb", Name: "nginx", Image: "docker.io/library/nginx:1.25", Labels: map[string]string{}}
+	// A second event forces the previous one to have been handled.
+	stream <- docker.ContainerEvent{Action: "destroy", ID: "zzz"}
+	cancel()
+	<-done
+
+	if u.queue.Len() != 1 {
+		t.Fatalf("queue.Len() = %d, want 1", u.queue.Len())
+	}
+	if _, ok := u.queue.Get("nginx"); !ok {
+		t.Error("new container not queued")
+	}
+}
+
+func TestDiscoverySkipsOwnAndDisabled(t *testing.T) {
+	mock := newMockDocker()
+	u, clk := newTestUpdater(t, mock)
+	d := NewDiscovery(u, &fakeEventSource{}, u.log, clk)
+	d.SetSettingsReader(u.store)
+	_ = u.store.SaveSetting("discovery_check", "true")
+	mock.imageDigests["app:1"] = "app@sha256:old"
+	mock.distDigests["app:1"] = "sha256:new"
+	ctx := context.Background()
+
+	// Sentinel's own replacement and blue-green candidates are ignored.
+	d.discover(ctx, "c1", "app", "app:1", map[string]string{"sentinel.maintenance": "true"})
+	d.discover(ctx, "c2", "app"+candidateSuffix, "app:1", nil)
+	if u.queue.Len() != 0 {
+		t.Errorf("queue.Len() = %d, want 0 for Sentinel-created containers", u.queue.Len())
+	}
+
+	// Discovery switched off.
+	_ = u.store.SaveSetting("container_discovery", "false")
+	d.discover(ctx, "c3", "app", "app:1", nil)
+	if u.queue.Len() != 0 {
+		t.Errorf("queue.Len() = %d, want 0 with discovery disabled", u.queue.Len())
+	}
+}
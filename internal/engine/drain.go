@@ -0,0 +1,110 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+	"github.com/Will-Luck/Docker-Sentinel/internal/notify"
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+)
+
+// ErrShuttingDown is returned by UpdateContainer and UpdateService once
+// Drain has been called.
+var ErrShuttingDown = errors.New("sentinel is shutting down")
+
+// Drain refuses new updates and waits up to timeout for in-flight ones to
+// finish. It returns the names of updates still running when the timeout
+// expired; those keep their maintenance flag, journal entry and an
+// "interrupted" history record so RecoverInterrupted can reconcile them on
+// the next start.
+//
+// The timeout only helps if Docker gives Sentinel that long: set the
+// container's stop_grace_period above SENTINEL_DRAIN_TIMEOUT.
+func (u *Updater) Drain(timeout time.Duration) []string {
+	u.draining.Store(true)
+	if u.IsIdle() {
+		return nil
+	}
+	u.log.Info("waiting for in-flight updates to finish", "timeout", timeout)
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	tick := time.NewTicker(100 * time.Millisecond)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			if u.IsIdle() {
+				u.log.Info("in-flight updates finished")
+				return nil
+			}
+		case <-deadline.C:
+			stuck := u.inFlight()
+			for _, name := range stuck {
+				u.log.Error("update still running at shutdown, marking interrupted", "name", name)
+				_ = u.store.RecordUpdate(store.UpdateRecord{
+					Timestamp:     time.Now(),
+					ContainerName: name,
+					Outcome:       "interrupted",
+					Error:         "shutdown drain timeout expired",
+				})
+			}
+			return stuck
+		}
+	}
+}
+
+// inFlight returns the names of containers and services with an update in progress.
+func (u *Updater) inFlight() []string {
+	var names []string
+	u.updating.Range(func(k, _ any) bool {
+		names = append(names, k.(string))
+		return true
+	})
+	sort.Strings(names)
+	return names
+}
+
+// RecoverInterrupted reconciles updates cut off by a shutdown or crash.
+// Journalled updates are resumed or rolled back (see resumeJournal). Any
+// other leftover maintenance flag is cleared; if its container is missing
+// the user is notified so they can restore it from the last snapshot.
+// Returns the names that needed attention.
+func (u *Updater) RecoverInterrupted(ctx context.Context) []string {
+	recovered := u.recoverJournal(ctx)
+	names, err := u.store.ListMaintenance()
+	if err != nil || len(names) == 0 {
+		return recovered
+	}
+	containers, err := u.docker.ListAllContainers(ctx)
+	if err != nil {
+		u.log.Warn("cannot reconcile interrupted updates", "error", err)
+		return recovered
+	}
+	present := make(map[string]bool, len(containers))
+	for _, c := range containers {
+		present[containerName(c)] = true
+	}
+
+	for _, name := range names {
+		if present[name] {
+			u.log.Info("clearing stale maintenance flag from interrupted update", "name", name)
+		} else {
+			u.log.Error("interrupted update left container missing", "name", name)
+			u.publishEvent(events.EventContainerUpdate, name, "update interrupted, container missing")
+			u.notifier.Notify(ctx, notify.Event{
+				Type:          notify.EventUpdateFailed,
+				ContainerName: name,
+				Error:         "update was interrupted by a restart and the container is missing; roll back to restore it",
+				Timestamp:     u.clock.Now(),
+			})
+		}
+		if err := u.store.SetMaintenance(name, false); err != nil {
+			u.log.Warn("failed to clear maintenance flag", "name", name, "error", err)
+		}
+	}
+	return append(recovered, names...)
+}
@@ -0,0 +1,81 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/moby/moby/api/types/container"
+)
+
+func TestDrainIdleRefusesNewUpdates(t *testing.T) {
+	mock := newMockDocker()
+	u, _ := newTestUpdater(t, mock)
+
+	if stuck := u.Drain(time.Second); len(stuck) != 0 {
+		t.Fatalf("Drain() = %v, want none", stuck)
+	}
+	if err := u.UpdateContainer(context.Background(), "aaa", "app", ""); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("UpdateContainer after Drain = %v, want ErrShuttingDown", err)
+	}
+}
+
+func TestDrainTimeoutMarksInterrupted(t *testing.T) {
+	mock := newMockDocker()
+	u, _ := newTestUpdater(t, mock)
+
+	// Simulate an update that never reaches a safe point.
+	if !u.tryLock("app") {
+		t.Fatal("tryLock failed")
+	}
+	defer u.unlock("app")
+
+	stuck := u.Drain(150 * time.Millisecond)
+	if len(stuck) != 1 || stuck[0] != "app" {
+		t.Fatalf("Drain() = %v, want [app]", stuck)
+	}
+	recs, err := u.store.ListHistory(10, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 1 || recs[0].Outcome != "interrupted" {
+		t.Errorf("history = %+v, want one interrupted record", recs)
+	}
+}
+
+func TestDrainWaitsForInFlightUpdate(t *testing.T) {
+	mock := newMockDocker()
+	u, _ := newTestUpdater(t, mock)
+
+	if !u.tryLock("app") {
+		t.Fatal("tryLock failed")
+	}
+	go func() {
+		time.Sleep(150 * time.Millisecond)
+		u.unlock("app")
+	}()
+
+	if stuck := u.Drain(5 * time.Second); len(stuck) != 0 {
+		t.Errorf("Drain() = %v, want none after update finished", stuck)
+	}
+}
+
+func TestRecoverInterruptedClearsStaleFlags(t *testing.T) {
+	mock := newMockDocker()
+	mock.containers = []container.Summary{
+		{ID: "aaa", Names: []string{"/present"}, Image: "fake.local/app:1.0"},
+	}
+	u, _ := newTestUpdater(t, mock)
+	_ = u.store.SetMaintenance("present", true)
+	_ = u.store.SetMaintenance("missing", true)
+
+	got := u.RecoverInterrupted(context.Background())
+	if len(got) != 2 {
+		t.Fatalf("RecoverInterrupted() = %v, want 2 names", got)
+	}
+	left, _ := u.store.ListMaintenance()
+	if len(left) != 0 {
+		t.Errorf("maintenance flags left = %v, want none", left)
+	}
+}
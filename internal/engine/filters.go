@@ -0,0 +1,138 @@
+package engine
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Scan filter kinds.
+const (
+	FilterName  = "name"
+	FilterImage = "image"
+	FilterLabel = "label"
+)
+
+// ScanFilter is one scan exclusion rule. The "filters" setting holds one
+// rule per line:
+//
+//	nginx-*                 container name glob (a bare pattern, as before)
+//	image:*.internal.io/*   image reference glob; "*" also matches "/"
+//	image:*:nightly
+//	label:env=dev           label selector; the value may be a glob
+//	label:com.example.skip  any container that has the label
+//	!name:nginx-keep        "!" re-includes a container an earlier rule excluded
+//
+// Rules are evaluated top to bottom and the last matching rule decides, so
+// broad exclusions go first and exceptions after them.
+type ScanFilter struct {
+	Kind    string
+	Pattern string
+	Negate  bool
+}
+
+// String returns the rule in its setting form.
+func (f ScanFilter) String() string {
+	s := f.Kind + ":" + f.Pattern
+	if f.Negate {
+		s = "!" + s
+	}
+	return s
+}
+
+// ParseScanFilter parses one rule line.
+func ParseScanFilter(line string) (ScanFilter, error) {
+	var f ScanFilter
+	line = strings.TrimSpace(line)
+	if rest, ok := strings.CutPrefix(line, "!"); ok {
+		f.Negate = true
+		line = strings.TrimSpace(rest)
+	}
+	f.Kind, f.Pattern = FilterName, line
+	if kind, pattern, ok := strings.Cut(line, ":"); ok {
+		switch kind {
+		case FilterName, FilterImage, FilterLabel:
+			f.Kind, f.Pattern = kind, strings.TrimSpace(pattern)
+		default:
+			return f, fmt.Errorf("unknown filter type %q (use name:, image: or label:)", kind)
+		}
+	}
+	if f.Pattern == "" {
+		return f, fmt.Errorf("empty %s filter", f.Kind)
+	}
+	glob := f.Pattern
+	if f.Kind == FilterLabel {
+		key, value, _ := strings.Cut(f.Pattern, "=")
+		if key == "" {
+			return f, fmt.Errorf("label filter %q has no key", f.Pattern)
+		}
+		glob = value
+	}
+	if _, err := path.Match(glob, ""); err != nil {
+		return f, fmt.Errorf("invalid pattern %q: %w", f.Pattern, err)
+	}
+	return f, nil
+}
+
+// ScanFilters is an ordered rule list.
+type ScanFilters []ScanFilter
+
+// ParseScanFilters parses non-blank lines into rules, stopping at the first
+// invalid one.
+func ParseScanFilters(lines []string) (ScanFilters, error) {
+	var filters ScanFilters
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		f, err := ParseScanFilter(line)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, f)
+	}
+	return filters, nil
+}
+
+// Match reports whether the rule matches the container, ignoring Negate.
+func (f ScanFilter) Match(name, image string, labels map[string]string) bool {
+	switch f.Kind {
+	case FilterName:
+		return MatchesFilter(name, []string{f.Pattern})
+	case FilterImage:
+		return matchImage(f.Pattern, image)
+	case FilterLabel:
+		key, value, hasValue := strings.Cut(f.Pattern, "=")
+		v, ok := labels[key]
+		if !ok {
+			return false
+		}
+		if !hasValue {
+			return true
+		}
+		matched, _ := path.Match(value, v)
+		return matched
+	}
+	return false
+}
+
+// Excluded reports whether the container is excluded from scans, and the
+// rule that decided it ("" when no rule matched).
+func (fs ScanFilters) Excluded(name, image string, labels map[string]string) (bool, string) {
+	excluded, rule := false, ""
+	for _, f := range fs {
+		if f.Match(name, image, labels) {
+			excluded, rule = !f.Negate, f.String()
+		}
+	}
+	return excluded, rule
+}
+
+// matchImage matches an image reference against a glob in which "*" spans
+// path separators, so "*:nightly" matches "ghcr.io/org/app:nightly".
+func matchImage(pattern, image string) bool {
+	// path.Match stops "*" at "/"; swap it for a byte that never appears in
+	// image references.
+	matched, _ := path.Match(strings.ReplaceAll(pattern, "/", "\x00"), strings.ReplaceAll(image, "/", "\x00"))
+	return matched
+}
@@ -0,0 +1,74 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseScanFilter(t *testing.T) {
+	tests := []struct {
+		line    string
+		want    ScanFilter
+		wantErr string
+	}{
+		{"nginx-*", ScanFilter{Kind: FilterName, Pattern: "nginx-*"}, ""},
+		{"image: *:nightly", ScanFilter{Kind: FilterImage, Pattern: "*:nightly"}, ""},
+		{"label:env=dev", ScanFilter{Kind: FilterLabel, Pattern: "env=dev"}, ""},
+		{"!name:keep", ScanFilter{Kind: FilterName, Pattern: "keep", Negate: true}, ""},
+		{"tag:latest", ScanFilter{}, "unknown filter type"},
+		{"image:", ScanFilter{}, "empty image filter"},
+		{"label:=dev", ScanFilter{}, "no key"},
+		{"image:[", ScanFilter{}, "invalid pattern"},
+	}
+	for _, tt := range tests {
+		got, err := ParseScanFilter(tt.line)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%q: err = %v, want %q", tt.line, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", tt.line, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%q = %+v, want %+v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestScanFiltersExcluded(t *testing.T) {
+	filters, err := ParseScanFilters([]string{
+		"watchtower",
+		"image:*.internal.registry/*",
+		"image:*:nightly",
+		"label:env=dev*",
+		"label:com.example.skip",
+		"!name:nightly-keep",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name, image string
+		labels      map[string]string
+		want        bool
+		wantRule    string
+	}{
+		{"watchtower", "containrrr/watchtower:latest", nil, true, "name:watchtower"},
+		{"api", "git.internal.registry/team/api:1.2", nil, true, "image:*.internal.registry/*"},
+		{"app", "ghcr.io/org/app:nightly", nil, true, "image:*:nightly"},
+		{"web", "nginx:1.25", map[string]string{"env": "development"}, true, "label:env=dev*"},
+		{"db", "postgres:16", map[string]string{"com.example.skip": ""}, true, "label:com.example.skip"},
+		{"nightly-keep", "ghcr.io/org/app:nightly", nil, false, "!name:nightly-keep"},
+		{"web", "nginx:1.25", map[string]string{"env": "prod"}, false, ""},
+	}
+	for _, tt := range tests {
+		got, rule := filters.Excluded(tt.name, tt.image, tt.labels)
+		if got != tt.want || rule != tt.wantRule {
+			t.Errorf("Excluded(%s, %s) = %v, %q; want %v, %q", tt.name, tt.image, got, rule, tt.want, tt.wantRule)
+		}
+	}
+}
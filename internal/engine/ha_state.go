@@ -0,0 +1,165 @@
+package engine
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+	"github.com/Will-Luck/Docker-Sentinel/internal/notify"
+)
+
+// haFailedOutcomes are history outcomes that leave a container's last update
+// marked as failed. Outcomes missing from both lists (dry runs, rate-limited
+// or failed checks) do not describe an update attempt and are skipped.
+var (
+	haFailedOutcomes = []string{"failed", "rollback", "interrupted", "partial"}
+	haUpdateOutcomes = append([]string{"success", "identical", "pull_only"}, haFailedOutcomes...)
+)
+
+// RunHAStateSync keeps the Home Assistant health sensors current. It follows
+// the event bus, so the update_available, update_in_progress and
+// last_update_failed sensors and the aggregate problem sensor change as
+// updates happen, not only at scan boundaries. It blocks until ctx is
+// cancelled and does nothing without HA discovery and an event bus.
+func (u *Updater) RunHAStateSync(ctx context.Context) {
+	if u.haDiscovery == nil || u.events == nil {
+		return
+	}
+	ch, cancel := u.events.Subscribe()
+	defer cancel()
+
+	health := u.seedHAHealth()
+	for name, h := range health {
+		u.publishHAHealth(name, h)
+	}
+	u.publishHAProblem(health)
+	_ = u.haDiscovery.PublishPendingCount(u.queue.Len())
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case evt, ok := <-ch:
+			if !ok {
+				return
+			}
+			// Only local containers have sensors; remote hosts are
+			// published by their own agents.
+			if evt.HostID != "" || evt.ContainerName == "" {
+				if evt.Type == events.EventQueueChange {
+					_ = u.haDiscovery.PublishPendingCount(u.queue.Len())
+				}
+				continue
+			}
+			prev := health[evt.ContainerName]
+			next, changed := u.applyHAEvent(prev, evt)
+			if evt.Type == events.EventQueueChange {
+				_ = u.haDiscovery.PublishPendingCount(u.queue.Len())
+			}
+			if !changed {
+				continue
+			}
+			health[evt.ContainerName] = next
+			u.publishHAHealth(evt.ContainerName, next)
+			if prev.LastUpdateFailed != next.LastUpdateFailed {
+				u.publishHAProblem(health)
+			}
+		}
+	}
+}
+
+// applyHAEvent derives a container's next health from a bus event.
+func (u *Updater) applyHAEvent(h notify.ContainerHealth, evt events.SSEEvent) (notify.ContainerHealth, bool) {
+	next := h
+	switch evt.Type {
+	case events.EventQueueChange:
+		_, next.UpdateAvailable = u.queue.Get(evt.ContainerName)
+	case events.EventContainerUpdate, events.EventServiceUpdate:
+		next = haHealthFromMessage(h, evt.Message)
+	default:
+		return h, false
+	}
+	return next, next != h
+}
+
+// haHealthFromMessage applies an update lifecycle message from the engine
+// ("update started", "update failed", "rollback succeeded", ...) to h.
+func haHealthFromMessage(h notify.ContainerHealth, msg string) notify.ContainerHealth {
+	switch {
+	case strings.HasSuffix(msg, "update started"):
+		h.UpdateInProgress = true
+	case strings.HasSuffix(msg, "update available"):
+		h.UpdateAvailable = true
+	case strings.HasSuffix(msg, "update succeeded"), strings.HasPrefix(msg, "update complete"),
+		msg == "no change detected", msg == "interrupted update resumed":
+		h.UpdateInProgress = false
+		h.UpdateAvailable = false
+		h.LastUpdateFailed = false
+	case strings.Contains(msg, "failed"), strings.Contains(msg, "failing"),
+		strings.Contains(msg, "rollback"), strings.Contains(msg, "rolled back"),
+		strings.Contains(msg, "interrupted"), strings.HasPrefix(msg, "update blocked"):
+		h.UpdateInProgress = false
+		h.LastUpdateFailed = true
+	}
+	return h
+}
+
+// seedHAHealth builds the starting state from the queue and update history,
+// so sensors are correct before the first event arrives.
+func (u *Updater) seedHAHealth() map[string]notify.ContainerHealth {
+	health := make(map[string]notify.ContainerHealth)
+	for _, item := range u.queue.List() {
+		if item.HostID != "" {
+			continue
+		}
+		h := health[item.ContainerName]
+		h.UpdateAvailable = true
+		health[item.ContainerName] = h
+	}
+
+	records, err := u.store.ListHistory(500, "")
+	if err != nil {
+		u.log.Debug("ha discovery: failed to load history", "error", err)
+		return health
+	}
+	seen := make(map[string]bool)
+	for _, rec := range records { // newest first
+		if rec.ContainerName == "" || rec.HostID != "" || seen[rec.ContainerName] || !slices.Contains(haUpdateOutcomes, rec.Outcome) {
+			continue
+		}
+		seen[rec.ContainerName] = true
+		if slices.Contains(haFailedOutcomes, rec.Outcome) {
+			h := health[rec.ContainerName]
+			h.LastUpdateFailed = true
+			health[rec.ContainerName] = h
+		}
+	}
+	for name := range health {
+		if u.IsUpdating(name) {
+			h := health[name]
+			h.UpdateInProgress = true
+			health[name] = h
+		}
+	}
+	return health
+}
+
+func (u *Updater) publishHAHealth(name string, h notify.ContainerHealth) {
+	if err := u.haDiscovery.PublishContainerHealth(name, h); err != nil {
+		u.log.Debug("ha discovery: failed to publish health", "name", name, "error", err)
+	}
+}
+
+func (u *Updater) publishHAProblem(health map[string]notify.ContainerHealth) {
+	var failing []string
+	for name, h := range health {
+		if h.LastUpdateFailed {
+			failing = append(failing, name)
+		}
+	}
+	slices.Sort(failing)
+	if err := u.haDiscovery.PublishProblem(failing); err != nil {
+		u.log.Debug("ha discovery: failed to publish problem sensor", "error", err)
+	}
+}
@@ -0,0 +1,33 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/notify"
+)
+
+func TestHAHealthFromMessage(t *testing.T) {
+	queued := notify.ContainerHealth{UpdateAvailable: true}
+	running := notify.ContainerHealth{UpdateAvailable: true, UpdateInProgress: true}
+	tests := []struct {
+		msg  string
+		from notify.ContainerHealth
+		want notify.ContainerHealth
+	}{
+		{"update started", queued, running},
+		{"service update started", queued, running},
+		{"update succeeded", running, notify.ContainerHealth{}},
+		{"update complete with finalise warning", running, notify.ContainerHealth{}},
+		{"update failed", running, notify.ContainerHealth{UpdateAvailable: true, LastUpdateFailed: true}},
+		{"rollback succeeded", running, notify.ContainerHealth{UpdateAvailable: true, LastUpdateFailed: true}},
+		{"interrupted update rollback", running, notify.ContainerHealth{UpdateAvailable: true, LastUpdateFailed: true}},
+		{"interrupted update resumed", running, notify.ContainerHealth{}},
+		{"update available", notify.ContainerHealth{LastUpdateFailed: true}, notify.ContainerHealth{UpdateAvailable: true, LastUpdateFailed: true}},
+		{"something else", queued, queued},
+	}
+	for _, tt := range tests {
+		if got := haHealthFromMessage(tt.from, tt.msg); got != tt.want {
+			t.Errorf("%q: got %+v, want %+v", tt.msg, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package engine
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/moby/moby/api/types/container"
+)
+
+func TestReplaceTag(t *testing.T) {
+	tests := []struct {
+		imageRef, newTag, want string
+	}{
+		{"dxflrs/garage:v2.1.0", "v2.2.0", "dxflrs/garage:v2.2.0"},
+		{"nginx", "1.27", "nginx:1.27"},
+		{"localhost:5000/app", "v2", "localhost:5000/app:v2"},
+		{"localhost:5000/app:v1", "v2", "localhost:5000/app:v2"},
+		{"nginx:1.25@sha256:abc", "1.27", "nginx:1.27"},
+		{"localhost:5000/org/app:v1@sha256:abc", "v2", "localhost:5000/org/app:v2"},
+		{"localhost:5000/app@sha256:abc", "v2", "localhost:5000/app:v2"},
+	}
+	for _, tt := range tests {
+		if got := replaceTag(tt.imageRef, tt.newTag); got != tt.want {
+			t.Errorf("replaceTag(%q, %q) = %q, want %q", tt.imageRef, tt.newTag, got, tt.want)
+		}
+	}
+}
+
+func TestCheckRef(t *testing.T) {
+	track := map[string]string{"sentinel.track-tag": "true"}
+	tests := []struct {
+		imageRef   string
+		labels     map[string]string
+		wantRef    string
+		wantDigest string
+		wantPinned bool
+	}{
+		{"nginx:1.25", nil, "nginx:1.25", "", false},
+		{"nginx:1.25", track, "nginx:1.25", "", false},
+		{"nginx:1.25@sha256:abc", nil, "nginx:1.25@sha256:abc", "", true},
+		{"nginx:1.25@sha256:abc", track, "nginx:1.25", "sha256:abc", false},
+		{"localhost:5000/app:v1@sha256:abc", track, "localhost:5000/app:v1", "sha256:abc", false},
+		// Without a tag there is nothing to track.
+		{"localhost:5000/app@sha256:abc", track, "localhost:5000/app@sha256:abc", "", true},
+	}
+	for _, tt := range tests {
+		ref, digest, pinned := checkRef(tt.imageRef, tt.labels)
+		if ref != tt.wantRef || digest != tt.wantDigest || pinned != tt.wantPinned {
+			t.Errorf("checkRef(%q, %v) = %q, %q, %v, want %q, %q, %v",
+				tt.imageRef, tt.labels, ref, digest, pinned, tt.wantRef, tt.wantDigest, tt.wantPinned)
+		}
+	}
+}
+
+func TestScanTagAndDigest_PinnedByDefault(t *testing.T) {
+	mock := newMockDocker()
+	mock.containers = []container.Summary{
+		{ID: "aaa", Names: []string{"/app"}, Image: "fake.local/app:1.0@sha256:old",
+			Labels: map[string]string{"sentinel.policy": "manual"}},
+	}
+	mock.distDigests["fake.local/app:1.0"] = "sha256:new"
+
+	u, _ := newTestUpdater(t, mock)
+	res := u.Scan(context.Background(), ScanScheduled)
+
+	if res.Skipped != 1 {
+		t.Errorf("Skipped = %d, want 1", res.Skipped)
+	}
+	if u.queue.Len() != 0 {
+		t.Errorf("queue.Len() = %d, want 0 for a digest-pinned image", u.queue.Len())
+	}
+}
+
+func TestScanTagAndDigest_TrackTag(t *testing.T) {
+	labels := map[string]string{"sentinel.policy": "auto", "sentinel.track-tag": "true"}
+	mock := newMockDocker()
+	mock.containers = []container.Summary{
+		{ID: "aaa", Names: []string{"/app"}, Image: "fake.local/app:1.0@sha256:old", Labels: labels},
+	}
+	mock.distDigests["fake.local/app:1.0"] = "sha256:new"
+	mock.inspectResults["aaa"] = container.InspectResponse{
+		ID:              "aaa",
+		Name:            "/app",
+		Config:          &container.Config{Image: "fake.local/app:1.0@sha256:old", Labels: labels},
+		HostConfig:      &container.HostConfig{},
+		NetworkSettings: &container.NetworkSettings{},
+	}
+	mock.inspectResults["new-app"] = container.InspectResponse{
+		ID:              "new-app",
+		Name:            "/app",
+		State:           &container.State{Running: true},
+		Config:          &container.Config{Image: "fake.local/app:1.0", Labels: map[string]string{"sentinel.maintenance": "true"}},
+		HostConfig:      &container.HostConfig{},
+		NetworkSettings: &container.NetworkSettings{},
+	}
+
+	u, _ := newTestUpdater(t, mock)
+	res := u.Scan(context.Background(), ScanScheduled)
+
+	if res.Updated != 1 {
+		t.Fatalf("Updated = %d, want 1 (errors: %v)", res.Updated, res.Errors)
+	}
+	if !slices.Contains(mock.pullCalls, "fake.local/app:1.0") {
+		t.Errorf("pullCalls = %v, want the tag re-pulled", mock.pullCalls)
+	}
+	if cfg := mock.createConfigs["app"]; cfg == nil || cfg.Image != "fake.local/app:1.0" {
+		t.Errorf("new container config = %+v, want image fake.local/app:1.0", cfg)
+	}
+}
@@ -0,0 +1,151 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/registry"
+)
+
+// imageRewriteLabel holds per-container rewrite rules, separated by commas.
+// They are tried before the global "image_rewrites" setting.
+const imageRewriteLabel = "sentinel.image-rewrite"
+
+// ImageRewrite redirects image pulls to another location, such as a
+// pull-through cache, without changing the image name containers are created
+// from. The "image_rewrites" setting holds one rule per line:
+//
+//	docker.io/* => my-harbor.local/proxy-cache/*
+//	ghcr.io/acme/app => registry.local/mirror/{repo}:{tag}
+//
+// From is matched against the fully qualified repository without its tag
+// ("nginx:1.25" is "docker.io/library/nginx"). A trailing "*" matches any
+// remainder, which replaces "*" in To. To may also use {registry}, {repo}
+// and {tag}; unless it uses {tag}, the original tag is kept.
+type ImageRewrite struct {
+	From string
+	To   string
+}
+
+// String returns the rule in its setting form.
+func (r ImageRewrite) String() string {
+	return r.From + " => " + r.To
+}
+
+// ParseImageRewrite parses one "from => to" rule.
+func ParseImageRewrite(line string) (ImageRewrite, error) {
+	from, to, ok := strings.Cut(line, "=>")
+	if !ok {
+		return ImageRewrite{}, fmt.Errorf("image rewrite %q: expected \"from => to\"", strings.TrimSpace(line))
+	}
+	r := ImageRewrite{From: strings.TrimSpace(from), To: strings.TrimSpace(to)}
+	switch {
+	case r.From == "" || r.To == "":
+		return r, fmt.Errorf("image rewrite %q: from and to are required", strings.TrimSpace(line))
+	case strings.Contains(strings.TrimSuffix(r.From, "*"), "*"):
+		return r, fmt.Errorf("image rewrite %q: \"*\" is only allowed at the end of from", r.From)
+	case strings.Contains(r.To, "*") && !strings.HasSuffix(r.From, "*"):
+		return r, fmt.Errorf("image rewrite %q: to uses \"*\" but from has no wildcard", r.String())
+	}
+	return r, nil
+}
+
+// ImageRewrites is an ordered rule list; the first matching rule applies.
+type ImageRewrites []ImageRewrite
+
+// ParseImageRewrites parses non-blank lines into rules, stopping at the
+// first invalid one.
+func ParseImageRewrites(lines []string) (ImageRewrites, error) {
+	var rules ImageRewrites
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		r, err := ParseImageRewrite(line)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// Apply returns the image to pull in place of image, or false when no rule
+// matches. Digest references are never rewritten: the re-tag that keeps the
+// canonical name cannot target a digest.
+func (rules ImageRewrites) Apply(image string) (string, bool) {
+	if image == "" || strings.Contains(image, "@") {
+		return "", false
+	}
+	host := registry.RegistryHost(image)
+	repo := registry.RepoPath(image)
+	tag := registry.ExtractTag(image)
+	if tag == "" {
+		tag = "latest"
+	}
+	name := host + "/" + repo
+	for _, r := range rules {
+		var rest string
+		if prefix, ok := strings.CutSuffix(r.From, "*"); ok {
+			if !strings.HasPrefix(name, prefix) {
+				continue
+			}
+			rest = name[len(prefix):]
+		} else if name != r.From {
+			continue
+		}
+		out := strings.NewReplacer("*", rest, "{registry}", host, "{repo}", repo, "{tag}", tag).Replace(r.To)
+		if !strings.Contains(r.To, "{tag}") {
+			out += ":" + tag
+		}
+		return out, true
+	}
+	return "", false
+}
+
+// imageRewrites returns the rewrite rules for a container: its label rules
+// first, then the global setting. Invalid rules are logged and skipped.
+func (u *Updater) imageRewrites(labels map[string]string) ImageRewrites {
+	var rules ImageRewrites
+	add := func(source, line string) {
+		if strings.TrimSpace(line) == "" {
+			return
+		}
+		r, err := ParseImageRewrite(line)
+		if err != nil {
+			u.log.Warn("ignoring invalid image rewrite", "source", source, "error", err)
+			return
+		}
+		rules = append(rules, r)
+	}
+	for _, line := range strings.Split(labels[imageRewriteLabel], ",") {
+		add(imageRewriteLabel, line)
+	}
+	if u.settings != nil {
+		if val, err := u.settings.LoadSetting("image_rewrites"); err == nil {
+			for _, line := range strings.Split(val, "\n") {
+				add("image_rewrites", line)
+			}
+		}
+	}
+	return rules
+}
+
+// pullImage pulls image for a container, through the first matching rewrite
+// rule when there is one. A rewritten pull is tagged back to image so the
+// container, its history and notifications keep the canonical name.
+func (u *Updater) pullImage(ctx context.Context, name, image string, labels map[string]string) error {
+	src, ok := u.imageRewrites(labels).Apply(image)
+	if !ok || src == image {
+		return u.docker.PullImage(ctx, image)
+	}
+	u.log.Info("pulling through image rewrite", "name", name, "image", image, "source", src)
+	if err := u.docker.PullImage(ctx, src); err != nil {
+		return fmt.Errorf("pull %s (rewritten from %s): %w", src, image, err)
+	}
+	if err := u.docker.TagImage(ctx, src, image); err != nil {
+		return fmt.Errorf("tag %s as %s: %w", src, image, err)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/moby/moby/api/types/container"
+)
+
+func TestImageRewritesApply(t *testing.T) {
+	rules, err := ParseImageRewrites([]string{
+		"ghcr.io/acme/app => registry.local/mirror/{repo}:{tag}-mirror",
+		"",
+		"docker.io/* => my-harbor.local/proxy-cache/*",
+		"quay.io/prom/* => mirror.local/{registry}/*",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		image, want string
+		ok          bool
+	}{
+		{"nginx:1.25", "my-harbor.local/proxy-cache/library/nginx:1.25", true},
+		{"gitea/gitea", "my-harbor.local/proxy-cache/gitea/gitea:latest", true},
+		{"docker.io/library/redis:7", "my-harbor.local/proxy-cache/library/redis:7", true},
+		{"ghcr.io/acme/app:2.0", "registry.local/mirror/acme/app:2.0-mirror", true},
+		{"quay.io/prom/node-exporter:v1", "mirror.local/quay.io/node-exporter:v1", true},
+		{"ghcr.io/acme/other:1", "", false},
+		{"nginx@sha256:abc", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := rules.Apply(tt.image)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Apply(%q) = %q, %v; want %q, %v", tt.image, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseImageRewriteInvalid(t *testing.T) {
+	for _, line := range []string{
+		"docker.io/*",
+		"=> my-harbor.local/*",
+		"docker.io/*/x => y/*",
+		"docker.io/library/nginx => mirror.local/*",
+	} {
+		if _, err := ParseImageRewrite(line); err == nil {
+			t.Errorf("ParseImageRewrite(%q) succeeded, want error", line)
+		}
+	}
+}
+
+func TestUpdateContainerPullsThroughRewrite(t *testing.T) {
+	mock := newMockDocker()
+	mock.inspectResults["aaa"] = container.InspectResponse{
+		ID:    "aaa",
+		Image: "sha256:old",
+		Config: &container.Config{Image: "nginx:1.25", Labels: map[string]string{
+			"sentinel.image-rewrite": "docker.io/library/* => cache.local/hub/*",
+		}},
+		HostConfig:      &container.HostConfig{},
+		NetworkSettings: &container.NetworkSettings{},
+	}
+	mock.inspectResults["new-web"] = container.InspectResponse{
+		ID:     "new-web",
+		State:  &container.State{Running: true},
+		Config: &container.Config{Image: "nginx:1.26"},
+	}
+	u, _ := newTestUpdater(t, mock)
+	u.SetSettingsReader(u.store)
+	_ = u.store.SaveSetting("image_rewrites", "docker.io/* => harbor.local/proxy/*")
+
+	if err := u.UpdateContainer(context.Background(), "aaa", "web", "nginx:1.26"); err != nil {
+		t.Fatalf("UpdateContainer: %v", err)
+	}
+	// The label rule wins over the global one.
+	if !slices.Equal(mock.pullCalls, []string{"cache.local/hub/nginx:1.26"}) {
+		t.Errorf("pullCalls = %v, want the rewritten image", mock.pullCalls)
+	}
+	if !slices.Contains(mock.tagImageCalls, "cache.local/hub/nginx:1.26->nginx:1.26") {
+		t.Errorf("tagImageCalls = %v, want the pull tagged back to nginx:1.26", mock.tagImageCalls)
+	}
+	history, _ := u.store.ListHistoryByContainer("web", 1)
+	if len(history) != 1 || history[0].NewImage != "nginx:1.26" {
+		t.Errorf("history = %+v, want the canonical new image", history)
+	}
+}
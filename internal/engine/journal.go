@@ -0,0 +1,156 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+	"github.com/Will-Luck/Docker-Sentinel/internal/notify"
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+	"github.com/moby/moby/api/types/container"
+)
+
+// writeJournal advances j to phase and persists it. Failures are logged but
+// don't abort the update: the journal only matters if we crash.
+func (u *Updater) writeJournal(j *store.UpdateJournal, phase string) {
+	j.Phase = phase
+	j.UpdatedAt = u.clock.Now()
+	if err := u.store.SaveJournal(*j); err != nil {
+		u.log.Warn("failed to write update journal", "name", j.Name, "phase", phase, "error", err)
+	}
+}
+
+// resumeJournal brings a container left mid-update back to a running state.
+// Docker is treated as the source of truth rather than the recorded phase,
+// since a crash can land between a Docker call and its journal write:
+//   - container exists and runs: keep it (finalising if it still carries
+//     the maintenance label);
+//   - container exists but stopped: start it;
+//   - container missing: finish the recreate with the pulled image;
+//   - anything above fails: roll back to the journalled snapshot.
+//
+// Returns the history outcome ("" when the old container was never touched).
+func (u *Updater) resumeJournal(ctx context.Context, j store.UpdateJournal) (string, error) {
+	// A blue-green swap may have left its replacement under a temporary name.
+	u.removeLeftover(ctx, j.Name+replacementSuffix)
+
+	c, found, err := u.findContainer(ctx, j.Name)
+	if err != nil {
+		return "", err
+	}
+
+	if found {
+		if j.Phase == store.JournalPulled && c.ID == j.OldContainerID && c.State == container.StateRunning {
+			return "", nil // crashed before touching the old container
+		}
+		if c.State != container.StateRunning {
+			if err := u.docker.StartContainer(ctx, c.ID); err != nil {
+				u.log.Warn("could not start container left by interrupted update", "name", j.Name, "error", err)
+				return u.rollbackJournal(ctx, j)
+			}
+		}
+		if c.ID == j.OldContainerID {
+			return "resumed", nil
+		}
+		if _, err := u.finaliseContainer(ctx, c.ID, j.Name); err != nil {
+			u.log.Warn("finalise after interrupted update failed", "name", j.Name, "error", err)
+			return u.rollbackJournal(ctx, j)
+		}
+		return "resumed", nil
+	}
+
+	// Old container is gone and no replacement exists.
+	var inspect container.InspectResponse
+	if err := json.Unmarshal(j.Snapshot, &inspect); err != nil || inspect.Config == nil {
+		return "failed", fmt.Errorf("journal snapshot for %s is unusable", j.Name)
+	}
+	cfg := cloneConfig(inspect.Config)
+	if j.TargetImage != "" {
+		cfg.Image = j.TargetImage
+	}
+	newID, err := u.docker.CreateContainer(ctx, j.Name, cfg, inspect.HostConfig, rebuildNetworkingConfig(inspect.NetworkSettings))
+	if err == nil {
+		if err = u.docker.StartContainer(ctx, newID); err == nil {
+			return "resumed", nil
+		}
+		_ = u.docker.RemoveContainer(ctx, newID)
+	}
+	u.log.Warn("could not finish interrupted recreate", "name", j.Name, "error", err)
+	return u.rollbackJournal(ctx, j)
+}
+
+// rollbackJournal restores the container from the journalled snapshot.
+func (u *Updater) rollbackJournal(ctx context.Context, j store.UpdateJournal) (string, error) {
+	if err := rollback(ctx, u.docker, j.Name, j.Snapshot, u.log); err != nil {
+		return "failed", err
+	}
+	return "rollback", nil
+}
+
+// findContainer looks a container up by name, including stopped ones.
+func (u *Updater) findContainer(ctx context.Context, name string) (container.Summary, bool, error) {
+	containers, err := u.docker.ListAllContainers(ctx)
+	if err != nil {
+		return container.Summary{}, false, err
+	}
+	for _, c := range containers {
+		if containerName(c) == name {
+			return c, true, nil
+		}
+	}
+	return container.Summary{}, false, nil
+}
+
+// recoverJournal processes every journal entry left by a previous run and
+// returns the names it handled.
+func (u *Updater) recoverJournal(ctx context.Context) []string {
+	entries, err := u.store.ListJournal()
+	if err != nil || len(entries) == 0 {
+		return nil
+	}
+	var names []string
+	for _, j := range entries {
+		outcome, err := u.resumeJournal(ctx, j)
+		switch {
+		case err != nil:
+			u.log.Error("could not recover interrupted update", "name", j.Name, "phase", j.Phase, "error", err)
+			u.publishEvent(events.EventContainerUpdate, j.Name, "interrupted update recovery failed")
+			u.notifier.Notify(ctx, notify.Event{
+				Type:          notify.EventRollbackFailed,
+				ContainerName: j.Name,
+				OldImage:      j.OldImage,
+				NewImage:      j.TargetImage,
+				Error:         fmt.Sprintf("update interrupted at %q and recovery failed: %v", j.Phase, err),
+				Timestamp:     u.clock.Now(),
+			})
+		case outcome != "":
+			u.log.Info("recovered interrupted update", "name", j.Name, "phase", j.Phase, "outcome", outcome)
+			u.publishEvent(events.EventContainerUpdate, j.Name, "interrupted update "+outcome)
+		}
+		if outcome != "" {
+			rec := store.UpdateRecord{
+				Timestamp:     u.clock.Now(),
+				ContainerName: j.Name,
+				OldImage:      j.OldImage,
+				NewImage:      j.TargetImage,
+				Outcome:       outcome,
+				Duration:      u.clock.Since(j.StartedAt),
+			}
+			if err != nil {
+				rec.Error = err.Error()
+			} else if outcome != "resumed" {
+				rec.Error = "update interrupted at phase " + j.Phase
+			}
+			_ = u.store.RecordUpdate(rec)
+		}
+		if err := u.store.SetMaintenance(j.Name, false); err != nil {
+			u.log.Warn("failed to clear maintenance flag", "name", j.Name, "error", err)
+		}
+		if err := u.store.DeleteJournal(j.Name); err != nil {
+			u.log.Warn("failed to clear update journal", "name", j.Name, "error", err)
+		}
+		names = append(names, j.Name)
+	}
+	return names
+}
@@ -0,0 +1,131 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+	"github.com/moby/moby/api/types/container"
+)
+
+func journalSnapshot(t *testing.T, image string) []byte {
+	t.Helper()
+	data, err := json.Marshal(container.InspectResponse{
+		Config:     &container.Config{Image: image},
+		HostConfig: &container.HostConfig{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestUpdateClearsJournalOnSuccess(t *testing.T) {
+	_, u := setupUpdateMock(t)
+
+	if err := u.UpdateContainer(context.Background(), "aaa", "nginx", ""); err != nil {
+		t.Fatalf("UpdateContainer: %v", err)
+	}
+	entries, err := u.store.ListJournal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("journal entries = %+v, want none after completed update", entries)
+	}
+}
+
+func TestRecoverJournalRecreatesMissingContainer(t *testing.T) {
+	mock := newMockDocker()
+	u, _ := newTestUpdater(t, mock)
+	_ = u.store.SetMaintenance("app", true)
+	if err := u.store.SaveJournal(store.UpdateJournal{
+		Name:           "app",
+		Phase:          store.JournalRemoved,
+		OldContainerID: "old-app",
+		OldImage:       "fake.local/app:1.0",
+		TargetImage:    "fake.local/app:2.0",
+		Snapshot:       journalSnapshot(t, "fake.local/app:1.0"),
+		StartedAt:      time.Now(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	got := u.RecoverInterrupted(context.Background())
+	if !slices.Equal(got, []string{"app"}) {
+		t.Fatalf("RecoverInterrupted() = %v, want [app]", got)
+	}
+	if cfg := mock.createConfigs["app"]; cfg == nil || cfg.Image != "fake.local/app:2.0" {
+		t.Errorf("recreated config = %+v, want image fake.local/app:2.0", cfg)
+	}
+	if !slices.Contains(mock.startCalls, "new-app") {
+		t.Errorf("startCalls = %v, want new-app started", mock.startCalls)
+	}
+
+	history, _ := u.store.ListHistory(10, "")
+	if len(history) != 1 || history[0].Outcome != "resumed" {
+		t.Errorf("history = %+v, want one resumed record", history)
+	}
+	if entries, _ := u.store.ListJournal(); len(entries) != 0 {
+		t.Errorf("journal not cleared: %+v", entries)
+	}
+	if left, _ := u.store.ListMaintenance(); len(left) != 0 {
+		t.Errorf("maintenance flags left = %v", left)
+	}
+}
+
+func TestRecoverJournalStartsStoppedReplacement(t *testing.T) {
+	mock := newMockDocker()
+	mock.containers = []container.Summary{
+		{ID: "new-app", Names: []string{"/app"}, State: container.StateExited},
+	}
+	mock.inspectResults["new-app"] = container.InspectResponse{
+		ID:     "new-app",
+		Config: &container.Config{Image: "fake.local/app:2.0"},
+	}
+	u, _ := newTestUpdater(t, mock)
+	if err := u.store.SaveJournal(store.UpdateJournal{
+		Name:           "app",
+		Phase:          store.JournalCreated,
+		OldContainerID: "old-app",
+		NewContainerID: "new-app",
+		Snapshot:       journalSnapshot(t, "fake.local/app:1.0"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	u.RecoverInterrupted(context.Background())
+
+	if !slices.Equal(mock.startCalls, []string{"new-app"}) {
+		t.Errorf("startCalls = %v, want [new-app]", mock.startCalls)
+	}
+	if len(mock.createCalls) != 0 {
+		t.Errorf("createCalls = %v, want none", mock.createCalls)
+	}
+}
+
+func TestRecoverJournalUntouchedOldContainer(t *testing.T) {
+	mock := newMockDocker()
+	mock.containers = []container.Summary{
+		{ID: "old-app", Names: []string{"/app"}, State: container.StateRunning},
+	}
+	u, _ := newTestUpdater(t, mock)
+	_ = u.store.SaveJournal(store.UpdateJournal{
+		Name:           "app",
+		Phase:          store.JournalPulled,
+		OldContainerID: "old-app",
+		Snapshot:       journalSnapshot(t, "fake.local/app:1.0"),
+	})
+
+	u.RecoverInterrupted(context.Background())
+
+	if len(mock.startCalls)+len(mock.createCalls) != 0 {
+		t.Errorf("expected no Docker changes, got start=%v create=%v", mock.startCalls, mock.createCalls)
+	}
+	if history, _ := u.store.ListHistory(10, ""); len(history) != 0 {
+		t.Errorf("history = %+v, want none", history)
+	}
+}
@@ -0,0 +1,71 @@
+package engine
+
+import (
+	"context"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/lifecycle"
+)
+
+// SetLifecycleSink attaches the machine event sink that receives every
+// update state transition.
+func (u *Updater) SetLifecycleSink(s *lifecycle.Sink) {
+	u.lifecycle = s
+}
+
+// SetLifecycleSink attaches the machine event sink for queued and approved
+// transitions.
+func (q *Queue) SetLifecycleSink(s *lifecycle.Sink) {
+	q.lifecycle = s
+}
+
+// lifecycleRun reports the transitions of one local update. A nil run
+// (no update in progress) ignores everything.
+type lifecycleRun struct {
+	sink     *lifecycle.Sink
+	name     string
+	newImage string
+	done     bool
+}
+
+// lifecycleKey carries the current lifecycleRun through an update so the
+// strategies and rollback can report intermediate states.
+type lifecycleKey struct{}
+
+func withLifecycle(ctx context.Context, run *lifecycleRun) context.Context {
+	return context.WithValue(ctx, lifecycleKey{}, run)
+}
+
+func lifecycleFrom(ctx context.Context) *lifecycleRun {
+	run, _ := ctx.Value(lifecycleKey{}).(*lifecycleRun)
+	return run
+}
+
+// startLifecycle emits the updating state and returns the run for the rest
+// of the update.
+func (u *Updater) startLifecycle(name, oldImage, newImage string) *lifecycleRun {
+	run := &lifecycleRun{sink: u.lifecycle, name: name, newImage: newImage}
+	run.sink.Emit(lifecycle.Event{State: lifecycle.StateUpdating, Container: name, OldImage: oldImage, NewImage: newImage})
+	return run
+}
+
+// emit reports a state. After a terminal state the run is closed and
+// further emits are ignored.
+func (r *lifecycleRun) emit(state lifecycle.State, message string) {
+	if r == nil || r.done {
+		return
+	}
+	if state == lifecycle.StateFinalised || state == lifecycle.StateRolledBack || state == lifecycle.StateFailed {
+		r.done = true
+	}
+	r.sink.Emit(lifecycle.Event{State: state, Container: r.name, NewImage: r.newImage, Message: message})
+}
+
+// finish closes the run from the update's result unless a terminal state
+// (e.g. rolled_back) was already reported.
+func (r *lifecycleRun) finish(err error) {
+	if err != nil {
+		r.emit(lifecycle.StateFailed, err.Error())
+		return
+	}
+	r.emit(lifecycle.StateFinalised, "")
+}
@@ -0,0 +1,135 @@
+package engine
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/lifecycle"
+	"github.com/moby/moby/api/types/container"
+)
+
+// lifecycleRecorder is an NDJSON endpoint collecting lifecycle events.
+type lifecycleRecorder struct {
+	mu     sync.Mutex
+	events []lifecycle.Event
+}
+
+func (rec *lifecycleRecorder) ServeHTTP(_ http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	sc := bufio.NewScanner(bytes.NewReader(body))
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	for sc.Scan() {
+		var e lifecycle.Event
+		if json.Unmarshal(sc.Bytes(), &e) == nil {
+			rec.events = append(rec.events, e)
+		}
+	}
+}
+
+// states waits until n events arrived and returns their states.
+func (rec *lifecycleRecorder) states(t *testing.T, n int) []lifecycle.State {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		rec.mu.Lock()
+		if len(rec.events) >= n {
+			out := make([]lifecycle.State, len(rec.events))
+			for i, e := range rec.events {
+				out[i] = e.State
+			}
+			rec.mu.Unlock()
+			return out
+		}
+		rec.mu.Unlock()
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %d lifecycle events", n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func attachLifecycle(t *testing.T, u *Updater) *lifecycleRecorder {
+	t.Helper()
+	rec := &lifecycleRecorder{}
+	srv := httptest.NewServer(rec)
+	t.Cleanup(srv.Close)
+	sink := lifecycle.New(func() lifecycle.Config { return lifecycle.Config{URL: srv.URL} }, slog.Default())
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go sink.Run(ctx)
+	u.SetLifecycleSink(sink)
+	u.queue.SetLifecycleSink(sink)
+	return rec
+}
+
+func lifecycleMock(newRunning bool) *mockDocker {
+	mock := newMockDocker()
+	mock.inspectResults["aaa"] = container.InspectResponse{
+		ID:              "aaa",
+		Name:            "/nginx",
+		Config:          &container.Config{Image: "nginx:1.25", Labels: map[string]string{}},
+		HostConfig:      &container.HostConfig{},
+		NetworkSettings: &container.NetworkSettings{},
+	}
+	mock.inspectResults["new-nginx"] = container.InspectResponse{
+		State:  &container.State{Running: newRunning, Restarting: !newRunning},
+		Config: &container.Config{Image: "nginx:1.26"},
+	}
+	return mock
+}
+
+func TestLifecycleEventsOnSuccess(t *testing.T) {
+	u, _ := newTestUpdater(t, lifecycleMock(true))
+	rec := attachLifecycle(t, u)
+
+	u.queue.Add(PendingUpdate{ContainerID: "aaa", ContainerName: "nginx"})
+	if _, ok := u.queue.Approve("nginx"); !ok {
+		t.Fatal("approve failed")
+	}
+	if err := u.UpdateContainer(context.Background(), "aaa", "nginx", "nginx:1.26"); err != nil {
+		t.Fatalf("UpdateContainer: %v", err)
+	}
+
+	want := []lifecycle.State{lifecycle.StateQueued, lifecycle.StateApproved, lifecycle.StateUpdating, lifecycle.StateValidated, lifecycle.StateFinalised}
+	got := rec.states(t, len(want))
+	if len(got) != len(want) {
+		t.Fatalf("states = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("states = %v, want %v", got, want)
+		}
+	}
+	corr := rec.events[0].CorrelationID
+	for _, e := range rec.events {
+		if e.CorrelationID != corr {
+			t.Errorf("event %s has correlation %s, want %s", e.State, e.CorrelationID, corr)
+		}
+	}
+}
+
+func TestLifecycleEventsOnRollback(t *testing.T) {
+	u, _ := newTestUpdater(t, lifecycleMock(false))
+	rec := attachLifecycle(t, u)
+
+	if err := u.UpdateContainer(context.Background(), "aaa", "nginx", "nginx:1.26"); err == nil {
+		t.Fatal("expected validation failure")
+	}
+
+	got := rec.states(t, 2)
+	time.Sleep(50 * time.Millisecond) // catch any stray trailing event
+	got = rec.states(t, 2)
+	if len(got) != 2 || got[0] != lifecycle.StateUpdating || got[1] != lifecycle.StateRolledBack {
+		t.Errorf("states = %v, want [updating rolled_back]", got)
+	}
+}
@@ -34,6 +34,7 @@ type mockDocker struct {
 	createErr     map[string]error
 	createCalls   []string
 	createConfigs map[string]*container.Config
+	createHosts   map[string]*container.HostConfig
 
 	startCalls []string
 	startErr   map[string]error
@@ -99,6 +100,7 @@ func newMockDocker() *mockDocker {
 		createResult:      make(map[string]string),
 		createErr:         make(map[string]error),
 		createConfigs:     make(map[string]*container.Config),
+		createHosts:       make(map[string]*container.HostConfig),
 		startErr:          make(map[string]error),
 		restartErr:        make(map[string]error),
 		renameErr:         make(map[string]error),
@@ -161,12 +163,15 @@ func (m *mockDocker) RemoveContainer(_ context.Context, id string) error {
 	return nil
 }
 
-func (m *mockDocker) CreateContainer(_ context.Context, name string, cfg *container.Config, _ *container.HostConfig, _ *network.NetworkingConfig) (string, error) {
+func (m *mockDocker) CreateContainer(_ context.Context, name string, cfg *container.Config, hostCfg *container.HostConfig, _ *network.NetworkingConfig) (string, error) {
 	m.mu.Lock()
 	m.createCalls = append(m.createCalls, name)
 	if cfg != nil {
 		m.createConfigs[name] = cfg
 	}
+	if hostCfg != nil {
+		m.createHosts[name] = hostCfg
+	}
 	m.mu.Unlock()
 	if err, ok := m.createErr[name]; ok {
 		return "", err
@@ -0,0 +1,97 @@
+package engine
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/docker"
+	"github.com/Will-Luck/Docker-Sentinel/internal/notify"
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/api/types/swarm"
+)
+
+// syncLabelNotifyPrefs keeps label-sourced notification preferences in step
+// with the sentinel.notify and sentinel.channels labels of the scanned
+// containers and services, so routing declared in a compose file follows the
+// service when other tools recreate it. Preferences set in the UI win and
+// are left alone; clearing one lets the labels apply again on the next scan.
+func (u *Updater) syncLabelNotifyPrefs(containers []container.Summary, services []swarm.Service) {
+	labelsByName := make(map[string]map[string]string, len(containers)+len(services))
+	for _, c := range containers {
+		labelsByName[containerName(c)] = c.Labels
+	}
+	for _, svc := range services {
+		labelsByName[svc.Spec.Name] = svc.Spec.Labels
+	}
+
+	var channels []notify.Channel
+	channelsLoaded := false
+	for name, labels := range labelsByName {
+		existing, err := u.store.GetNotifyPref(name)
+		if err != nil {
+			u.log.Debug("failed to load notify pref", "name", name, "error", err)
+			continue
+		}
+		if existing != nil && existing.Source != store.NotifyPrefSourceLabel {
+			continue
+		}
+
+		want := store.NotifyPref{Mode: docker.ContainerNotifyMode(labels), Source: store.NotifyPrefSourceLabel}
+		if want.Mode == "" {
+			want.Mode = "default"
+		}
+		if refs := docker.ContainerNotifyChannels(labels); len(refs) > 0 {
+			if !channelsLoaded {
+				channels, err = u.store.GetNotificationChannels()
+				if err != nil {
+					u.log.Warn("failed to load notification channels for label routing", "error", err)
+				}
+				channelsLoaded = true
+			}
+			var unknown []string
+			want.Channels, unknown = resolveNotifyChannels(refs, channels)
+			if len(unknown) > 0 {
+				u.log.Warn("sentinel.channels label names unknown notification channels", "name", name, "channels", strings.Join(unknown, ", "))
+			}
+		}
+
+		if want.Mode == "default" && len(want.Channels) == 0 {
+			if existing != nil {
+				if err := u.store.DeleteNotifyPref(name); err != nil {
+					u.log.Warn("failed to remove label notify pref", "name", name, "error", err)
+					continue
+				}
+				u.log.Info("notification labels removed, using global defaults", "name", name)
+			}
+			continue
+		}
+		if existing != nil && existing.Mode == want.Mode && slices.Equal(existing.Channels, want.Channels) {
+			continue
+		}
+		if err := u.store.SetNotifyPref(name, &want); err != nil {
+			u.log.Warn("failed to save label notify pref", "name", name, "error", err)
+			continue
+		}
+		u.log.Info("notification preference set from labels", "name", name, "mode", want.Mode, "channels", len(want.Channels))
+	}
+}
+
+// resolveNotifyChannels maps channel names (case-insensitive) or IDs to
+// channel IDs, in label order without duplicates, and returns the references
+// that match no channel.
+func resolveNotifyChannels(refs []string, channels []notify.Channel) (ids, unknown []string) {
+	for _, ref := range refs {
+		idx := slices.IndexFunc(channels, func(ch notify.Channel) bool {
+			return ch.ID == ref || strings.EqualFold(ch.Name, ref)
+		})
+		if idx < 0 {
+			unknown = append(unknown, ref)
+			continue
+		}
+		if !slices.Contains(ids, channels[idx].ID) {
+			ids = append(ids, channels[idx].ID)
+		}
+	}
+	return ids, unknown
+}
@@ -0,0 +1,57 @@
+package engine
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/notify"
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+	"github.com/moby/moby/api/types/container"
+)
+
+func TestSyncLabelNotifyPrefs(t *testing.T) {
+	mock := newMockDocker()
+	u, _ := newTestUpdater(t, mock)
+	if err := u.store.SetNotificationChannels([]notify.Channel{
+		{ID: "c1", Name: "OnCall", Type: notify.ProviderWebhook, Enabled: true},
+		{ID: "c2", Name: "team", Type: notify.ProviderWebhook, Enabled: true},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// A preference set in the UI outranks labels.
+	if err := u.store.SetNotifyPref("manual", &store.NotifyPref{Mode: "muted"}); err != nil {
+		t.Fatal(err)
+	}
+
+	mock.containers = []container.Summary{
+		{ID: "1", Names: []string{"/web"}, Image: "nginx:1.25", Labels: map[string]string{
+			"sentinel.notify":   "digest_only",
+			"sentinel.channels": "oncall, c2, nope, OnCall",
+		}},
+		{ID: "2", Names: []string{"/manual"}, Image: "redis:7", Labels: map[string]string{"sentinel.notify": "every_scan"}},
+		{ID: "3", Names: []string{"/plain"}, Image: "alpine:3"},
+	}
+	u.Scan(context.Background(), ScanScheduled)
+
+	web, _ := u.store.GetNotifyPref("web")
+	if web == nil || web.Mode != "digest_only" || web.Source != store.NotifyPrefSourceLabel || !slices.Equal(web.Channels, []string{"c1", "c2"}) {
+		t.Errorf("web pref = %+v, want digest_only to c1,c2 from labels", web)
+	}
+	if m, _ := u.store.GetNotifyPref("manual"); m == nil || m.Mode != "muted" || m.Source != "" {
+		t.Errorf("manual pref = %+v, want the UI preference kept", m)
+	}
+	if p, _ := u.store.GetNotifyPref("plain"); p != nil {
+		t.Errorf("plain pref = %+v, want none", p)
+	}
+	if got := u.effectiveNotifyMode("web"); got != "digest_only" {
+		t.Errorf("effectiveNotifyMode(web) = %q, want digest_only", got)
+	}
+
+	// Dropping the labels removes the synced preference.
+	mock.containers[0].Labels = nil
+	u.Scan(context.Background(), ScanScheduled)
+	if p, _ := u.store.GetNotifyPref("web"); p != nil {
+		t.Errorf("web pref after labels removed = %+v, want none", p)
+	}
+}
@@ -0,0 +1,82 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+	"github.com/Will-Luck/Docker-Sentinel/internal/notify"
+)
+
+// pinReminderLead is how long before a timed pin expires the reminder is sent.
+const pinReminderLead = 24 * time.Hour
+
+// reviewPins handles timed pins at the start of each scan: reminders go out
+// a day before expiry, and expired pins revert the policy override to its
+// value before pinning so the scan that follows re-evaluates the container.
+// A pin whose override was changed by hand since is simply dropped.
+func (u *Updater) reviewPins(ctx context.Context) {
+	now := u.clock.Now()
+	for key, pin := range u.store.AllTimedPins() {
+		if now.Before(pin.Until) {
+			if !pin.Reminded && pin.Until.Sub(pin.PinnedAt) > pinReminderLead && pin.Until.Sub(now) <= pinReminderLead {
+				u.remindPin(ctx, key, pin.Until, pin.PreviousPolicy)
+				pin.Reminded = true
+				if err := u.store.SetTimedPin(key, pin); err != nil {
+					u.log.Warn("failed to mark pin reminder sent", "name", key, "error", err)
+				}
+			}
+			continue
+		}
+
+		if err := u.store.DeleteTimedPin(key); err != nil {
+			u.log.Warn("failed to remove expired pin", "name", key, "error", err)
+			continue
+		}
+		if current, _ := u.store.GetPolicyOverride(key); current != "pinned" {
+			u.log.Info("timed pin expired but policy was changed since, leaving it", "name", key, "policy", current)
+			continue
+		}
+		var err error
+		if pin.PreviousPolicy == "" {
+			err = u.store.DeletePolicyOverride(key)
+		} else {
+			err = u.store.SetPolicyOverride(key, pin.PreviousPolicy)
+		}
+		if err != nil {
+			u.log.Error("failed to revert expired pin", "name", key, "error", err)
+			continue
+		}
+
+		msg := "Pin expired, policy reverted to " + revertedPolicyLabel(pin.PreviousPolicy)
+		u.log.Info("timed pin expired", "name", key, "policy", revertedPolicyLabel(pin.PreviousPolicy))
+		u.publishEvent(events.EventPolicyChange, key, msg)
+		u.notifier.Notify(ctx, notify.Event{
+			Type:          notify.EventPinExpired,
+			ContainerName: key,
+			Message:       msg,
+			Timestamp:     now,
+		})
+	}
+}
+
+// remindPin notifies that a timed pin is about to expire.
+func (u *Updater) remindPin(ctx context.Context, key string, until time.Time, previous string) {
+	msg := fmt.Sprintf("Pin expires %s, policy will revert to %s", until.Format("2006-01-02 15:04 MST"), revertedPolicyLabel(previous))
+	u.log.Info("timed pin expiring soon", "name", key, "until", until)
+	u.notifier.Notify(ctx, notify.Event{
+		Type:          notify.EventPinExpiring,
+		ContainerName: key,
+		Message:       msg,
+		Timestamp:     u.clock.Now(),
+	})
+}
+
+// revertedPolicyLabel describes the policy a pin reverts to.
+func revertedPolicyLabel(previous string) string {
+	if previous == "" {
+		return "the label/default policy"
+	}
+	return previous
+}
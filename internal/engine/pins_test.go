@@ -0,0 +1,57 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+)
+
+func TestReviewPinsRevertsExpired(t *testing.T) {
+	u, clk := newTestUpdater(t, newMockDocker())
+	now := clk.Now()
+
+	_ = u.store.SetPolicyOverride("restored", "pinned")
+	_ = u.store.SetTimedPin("restored", store.TimedPin{PinnedAt: now.Add(-48 * time.Hour), Until: now.Add(-time.Minute), PreviousPolicy: "auto"})
+	_ = u.store.SetPolicyOverride("cleared", "pinned")
+	_ = u.store.SetTimedPin("cleared", store.TimedPin{PinnedAt: now.Add(-48 * time.Hour), Until: now.Add(-time.Minute)})
+	_ = u.store.SetPolicyOverride("manual", "manual")
+	_ = u.store.SetTimedPin("manual", store.TimedPin{PinnedAt: now.Add(-48 * time.Hour), Until: now.Add(-time.Minute), PreviousPolicy: "auto"})
+
+	u.reviewPins(context.Background())
+
+	if p, _ := u.store.GetPolicyOverride("restored"); p != "auto" {
+		t.Errorf("restored policy = %q, want auto", p)
+	}
+	if _, ok := u.store.GetPolicyOverride("cleared"); ok {
+		t.Error("cleared should have no override after expiry")
+	}
+	if p, _ := u.store.GetPolicyOverride("manual"); p != "manual" {
+		t.Errorf("manually changed policy = %q, want manual", p)
+	}
+	if pins := u.store.AllTimedPins(); len(pins) != 0 {
+		t.Errorf("expected all expired pins removed, got %d", len(pins))
+	}
+}
+
+func TestReviewPinsReminder(t *testing.T) {
+	u, clk := newTestUpdater(t, newMockDocker())
+	now := clk.Now()
+
+	_ = u.store.SetPolicyOverride("app", "pinned")
+	_ = u.store.SetTimedPin("app", store.TimedPin{PinnedAt: now.Add(-6 * 24 * time.Hour), Until: now.Add(12 * time.Hour)})
+
+	u.reviewPins(context.Background())
+
+	pin, ok := u.store.GetTimedPin("app")
+	if !ok {
+		t.Fatal("pin removed before expiry")
+	}
+	if !pin.Reminded {
+		t.Error("expected reminder to be marked as sent")
+	}
+	if p, _ := u.store.GetPolicyOverride("app"); p != "pinned" {
+		t.Errorf("policy = %q, want pinned until expiry", p)
+	}
+}
@@ -0,0 +1,178 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/docker"
+	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+	"github.com/moby/moby/api/types/container"
+)
+
+// What to do with an update whose wait-for conditions are not met.
+const (
+	PreconditionDefer = "defer" // leave it for a later attempt (default)
+	PreconditionSkip  = "skip"  // drop this attempt
+)
+
+var (
+	// ErrPreconditionDeferred is returned by UpdateContainer when a wait-for
+	// condition failed and the update should be retried later.
+	ErrPreconditionDeferred = errors.New("update deferred: wait-for condition not met")
+
+	// ErrPreconditionSkipped is returned by UpdateContainer when a wait-for
+	// condition failed and the container is set to skip the update.
+	ErrPreconditionSkipped = errors.New("update skipped: wait-for condition not met")
+)
+
+// ValidateCondition checks that a wait-for condition is usable:
+// "path:/abs/path", an http(s) URL or "tcp://host:port".
+func ValidateCondition(c string) error {
+	if p, ok := strings.CutPrefix(c, "path:"); ok {
+		if !strings.HasPrefix(p, "/") {
+			return fmt.Errorf("invalid condition %q: path must be absolute", c)
+		}
+		return nil
+	}
+	u, err := parseProbeURL(c)
+	if err != nil {
+		return fmt.Errorf("invalid condition %q: must be path:, http, https or tcp", c)
+	}
+	if u.Hostname() == "" {
+		return fmt.Errorf("invalid condition %q: host required", c)
+	}
+	return nil
+}
+
+// ValidPreconditionAction reports whether a is a recognised wait-for action.
+func ValidPreconditionAction(a string) bool {
+	return a == "" || a == PreconditionDefer || a == PreconditionSkip
+}
+
+// preconditionsFor resolves a container's wait-for conditions.
+// A store override replaces the sentinel.wait-for labels entirely.
+func (u *Updater) preconditionsFor(name string, labels map[string]string) store.Preconditions {
+	if p, ok := u.store.GetPreconditions(name); ok {
+		return p
+	}
+	return store.Preconditions{
+		Conditions: docker.ContainerWaitFor(labels),
+		Action:     docker.ContainerWaitForAction(labels),
+	}
+}
+
+// checkPreconditions evaluates a container's wait-for conditions right before
+// an update. When any fails, the attempt is recorded in history as deferred
+// or skipped and ErrPreconditionDeferred or ErrPreconditionSkipped is
+// returned. On success the results are returned for the update record.
+func (u *Updater) checkPreconditions(ctx context.Context, name, targetImage string, inspect container.InspectResponse) ([]store.PreconditionResult, error) {
+	var labels map[string]string
+	if inspect.Config != nil {
+		labels = inspect.Config.Labels
+	}
+	pre := u.preconditionsFor(name, labels)
+	if len(pre.Conditions) == 0 {
+		return nil, nil
+	}
+
+	results := make([]store.PreconditionResult, 0, len(pre.Conditions))
+	var failed []string
+	for _, c := range pre.Conditions {
+		res := store.PreconditionResult{Condition: c, OK: true}
+		if err := u.checkCondition(ctx, c); err != nil {
+			res.OK = false
+			res.Detail = err.Error()
+			failed = append(failed, c)
+		}
+		results = append(results, res)
+	}
+	if len(failed) == 0 {
+		u.log.Debug("wait-for conditions met", "name", name, "conditions", len(results))
+		return results, nil
+	}
+
+	outcome, sentinel := "deferred", ErrPreconditionDeferred
+	if pre.Action == PreconditionSkip {
+		outcome, sentinel = "skipped", ErrPreconditionSkipped
+	}
+	u.log.Warn("wait-for condition not met", "name", name, "failed", failed, "action", outcome)
+	newImage := targetImage
+	if newImage == "" && inspect.Config != nil {
+		newImage = inspect.Config.Image
+	}
+	rec := store.UpdateRecord{
+		Timestamp:     u.clock.Now(),
+		ContainerName: name,
+		NewImage:      newImage,
+		Outcome:       outcome,
+		Error:         "wait-for not met: " + strings.Join(failed, ", "),
+		FailureClass:  store.FailurePrecondition,
+		Preconditions: results,
+		Comment:       approvalComment(ctx),
+	}
+	if inspect.Config != nil {
+		rec.OldImage = inspect.Config.Image
+		rec.OldDigest = extractDigestForRecord(inspect)
+	}
+	if err := u.store.RecordUpdate(rec); err != nil {
+		u.log.Warn("failed to persist precondition record", "name", name, "error", err)
+	}
+	u.publishEvent(events.EventContainerUpdate, name, "update "+outcome+": wait-for not met")
+	return results, fmt.Errorf("%s: %w", strings.Join(failed, ", "), sentinel)
+}
+
+// checkCondition evaluates a single wait-for condition.
+func (u *Updater) checkCondition(ctx context.Context, c string) error {
+	if err := ValidateCondition(c); err != nil {
+		return err
+	}
+	if p, ok := strings.CutPrefix(c, "path:"); ok {
+		return checkPath(ctx, p)
+	}
+	target, _ := url.Parse(c)
+	probe := u.probeFn
+	if probe == nil {
+		probe = runProbe
+	}
+	return probe(ctx, target)
+}
+
+// checkPath stats path from Sentinel's own filesystem, so the path must be
+// mounted into the Sentinel container. The stat runs in the background
+// because a dead NFS mount can block it indefinitely.
+func checkPath(ctx context.Context, path string) error {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		_, err := os.Stat(path)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("stat %s: %w", path, ctx.Err())
+	}
+}
+
+// preconditionsKey carries the met wait-for results through an update so
+// they are stored on the final history record.
+type preconditionsKey struct{}
+
+func withPreconditions(ctx context.Context, results []store.PreconditionResult) context.Context {
+	if len(results) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, preconditionsKey{}, results)
+}
+
+func preconditionsFrom(ctx context.Context) []store.PreconditionResult {
+	results, _ := ctx.Value(preconditionsKey{}).([]store.PreconditionResult)
+	return results
+}
@@ -0,0 +1,127 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/moby/moby/api/types/container"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+)
+
+// resultStrategy captures the wait-for results passed down to a strategy.
+type resultStrategy struct {
+	calls   int
+	results []store.PreconditionResult
+}
+
+func (s *resultStrategy) Name() string { return "capture" }
+
+func (s *resultStrategy) Update(ctx context.Context, _ UpdateTarget) error {
+	s.calls++
+	s.results = preconditionsFrom(ctx)
+	return nil
+}
+
+func preconditionTestUpdater(t *testing.T, labels map[string]string) (*Updater, *resultStrategy) {
+	t.Helper()
+	labels["sentinel.strategy"] = "capture"
+	mock := newMockDocker()
+	mock.inspectResults["aaa"] = container.InspectResponse{
+		ID:     "aaa",
+		Config: &container.Config{Image: "app:1", Labels: labels},
+	}
+	u, _ := newTestUpdater(t, mock)
+	s := &resultStrategy{}
+	u.RegisterStrategy(s)
+	return u, s
+}
+
+func TestPreconditionsMet(t *testing.T) {
+	dir := t.TempDir()
+	u, s := preconditionTestUpdater(t, map[string]string{"sentinel.wait-for": "path:" + dir + ",tcp://db:5432"})
+	var probed []string
+	u.probeFn = func(_ context.Context, target *url.URL) error {
+		probed = append(probed, target.String())
+		return nil
+	}
+
+	if err := u.UpdateContainer(context.Background(), "aaa", "app", "app:2"); err != nil {
+		t.Fatalf("UpdateContainer: %v", err)
+	}
+	if s.calls != 1 {
+		t.Fatalf("strategy calls = %d, want 1", s.calls)
+	}
+	if len(probed) != 1 || probed[0] != "tcp://db:5432" {
+		t.Errorf("probed = %v, want [tcp://db:5432]", probed)
+	}
+	if len(s.results) != 2 || !s.results[0].OK || !s.results[1].OK {
+		t.Errorf("results = %+v, want two passing conditions", s.results)
+	}
+}
+
+func TestPreconditionsDeferAndSkip(t *testing.T) {
+	missing := "path:" + filepath.Join(t.TempDir(), "nfs")
+	tests := []struct {
+		action  string
+		want    error
+		outcome string
+	}{
+		{"", ErrPreconditionDeferred, "deferred"},
+		{"skip", ErrPreconditionSkipped, "skipped"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.outcome, func(t *testing.T) {
+			u, s := preconditionTestUpdater(t, map[string]string{
+				"sentinel.wait-for":        missing,
+				"sentinel.wait-for-action": tt.action,
+			})
+
+			err := u.UpdateContainer(context.Background(), "aaa", "app", "app:2")
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("error = %v, want %v", err, tt.want)
+			}
+			if s.calls != 0 {
+				t.Errorf("strategy ran despite failed precondition")
+			}
+			history, _ := u.store.ListHistory(10, "")
+			if len(history) != 1 || history[0].Outcome != tt.outcome || history[0].FailureClass != store.FailurePrecondition {
+				t.Fatalf("history = %+v, want one %s record", history, tt.outcome)
+			}
+			if p := history[0].Preconditions; len(p) != 1 || p[0].OK || p[0].Detail == "" {
+				t.Errorf("recorded preconditions = %+v", p)
+			}
+		})
+	}
+}
+
+func TestPreconditionsOverrideReplacesLabels(t *testing.T) {
+	u, s := preconditionTestUpdater(t, map[string]string{"sentinel.wait-for": "http://nas.lan/health"})
+	u.probeFn = func(context.Context, *url.URL) error { return errors.New("connection refused") }
+	if err := u.store.SetPreconditions("app", store.Preconditions{Conditions: []string{"path:" + t.TempDir()}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := u.UpdateContainer(context.Background(), "aaa", "app", "app:2"); err != nil {
+		t.Fatalf("UpdateContainer: %v", err)
+	}
+	if s.calls != 1 || len(s.results) != 1 {
+		t.Errorf("calls = %d, results = %+v; want override condition only", s.calls, s.results)
+	}
+}
+
+func TestValidateCondition(t *testing.T) {
+	for _, c := range []string{"path:/mnt/media", "http://nas.lan/health", "https://api.example.com", "tcp://db:5432"} {
+		if err := ValidateCondition(c); err != nil {
+			t.Errorf("ValidateCondition(%q) = %v, want nil", c, err)
+		}
+	}
+	for _, c := range []string{"path:relative", "ftp://x/", "tcp://:5432", "/mnt/media"} {
+		if err := ValidateCondition(c); err == nil {
+			t.Errorf("ValidateCondition(%q) = nil, want error", c)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+	"github.com/Will-Luck/Docker-Sentinel/internal/metrics"
+	"github.com/Will-Luck/Docker-Sentinel/internal/registry"
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+	"github.com/moby/moby/api/types/container"
+)
+
+// ErrPullCheckFailed is returned by UpdateContainer when the registry was
+// unreachable or the Docker host was short of disk space before the pull.
+// Nothing has been changed; the update can be retried once fixed.
+var ErrPullCheckFailed = errors.New("update aborted: pre-pull check failed")
+
+const (
+	pullCheckTimeout = 15 * time.Second
+	// pullSpaceFactor scales the compressed image size to the room needed
+	// while pulling: the downloaded layers plus their unpacked copy.
+	pullSpaceFactor = 3
+	// pullSpaceReserve is kept free on top of the image itself so the pull
+	// does not leave the host with a full disk.
+	pullSpaceReserve = 512 << 20
+)
+
+// pullCheckRegistryEnabled reads the pull_check_registry setting. On by
+// default: the reachability probe is one manifest lookup.
+func (u *Updater) pullCheckRegistryEnabled() bool {
+	if u.settings == nil {
+		return true
+	}
+	val, _ := u.settings.LoadSetting("pull_check_registry")
+	return val != "false"
+}
+
+// pullCheckDiskPath reads the pull_check_disk_path setting: a path inside
+// Sentinel's container on the same filesystem as Docker's data root
+// (e.g. /var/lib/docker mounted read-only). Empty disables the disk check.
+func (u *Updater) pullCheckDiskPath() string {
+	if u.settings == nil {
+		return ""
+	}
+	val, _ := u.settings.LoadSetting("pull_check_disk_path")
+	return strings.TrimSpace(val)
+}
+
+// checkPull runs the pre-pull checks for an update, before any hook, backup
+// tag or pull has touched the container: the registry must answer, and the
+// disk must have room for the image (compressed size × pullSpaceFactor plus
+// pullSpaceReserve; just the reserve when the size is unknown). The estimate
+// is pessimistic because layers already present are not pulled again. A
+// failed check is recorded in history as "aborted" and ErrPullCheckFailed
+// is returned. Swarm services are skipped; their nodes pull for themselves.
+func (u *Updater) checkPull(ctx context.Context, name, strategy, targetImage string, inspect container.InspectResponse) error {
+	if strategy == StrategySwarmService || inspect.Config == nil {
+		return nil
+	}
+	pullImage := targetImage
+	if pullImage == "" {
+		pullImage = inspect.Config.Image
+		if ref, digest, _ := checkRef(pullImage, inspect.Config.Labels); digest != "" {
+			pullImage = ref
+		}
+	}
+
+	if u.pullCheckRegistryEnabled() {
+		cctx, cancel := context.WithTimeout(ctx, pullCheckTimeout)
+		_, err := u.docker.DistributionDigest(cctx, pullImage)
+		cancel()
+		if registryUnreachable(err) {
+			host := registry.RegistryHost(pullImage)
+			return u.abortPull(ctx, name, pullImage, inspect, store.FailureRegistryUnreachable,
+				fmt.Sprintf("registry %s unreachable: %v", host, err))
+		}
+	}
+
+	if path := u.pullCheckDiskPath(); path != "" {
+		free, err := u.freeSpace(path)
+		if err != nil {
+			u.log.Warn("disk space check skipped", "name", name, "path", path, "error", err)
+			return nil
+		}
+		need := uint64(pullSpaceReserve)
+		if size, err := u.fetchImageSize(ctx, pullImage); err != nil {
+			u.log.Debug("image size unknown, checking reserve only", "name", name, "image", pullImage, "error", err)
+		} else {
+			need += uint64(size) * pullSpaceFactor
+		}
+		if free < need {
+			return u.abortPull(ctx, name, pullImage, inspect, store.FailureDiskSpace,
+				fmt.Sprintf("not enough disk space to pull %s: %s free on %s, %s needed", pullImage, formatBytes(free), path, formatBytes(need)))
+		}
+		u.log.Debug("disk space check passed", "name", name, "free", free, "need", need)
+	}
+	return nil
+}
+
+// abortPull records an update stopped by a pre-pull check and returns
+// ErrPullCheckFailed wrapping the reason.
+func (u *Updater) abortPull(ctx context.Context, name, pullImage string, inspect container.InspectResponse, class, reason string) error {
+	u.log.Warn("update aborted before pull", "name", name, "reason", reason)
+	rec := store.UpdateRecord{
+		Timestamp:     u.clock.Now(),
+		ContainerName: name,
+		OldImage:      inspect.Config.Image,
+		OldDigest:     extractDigestForRecord(inspect),
+		NewImage:      pullImage,
+		Outcome:       "aborted",
+		Error:         reason,
+		FailureClass:  class,
+		Comment:       approvalComment(ctx),
+	}
+	if err := u.store.RecordUpdate(rec); err != nil {
+		u.log.Warn("failed to persist aborted update record", "name", name, "error", err)
+	}
+	metrics.UpdatesTotal.WithLabelValues("aborted").Inc()
+	u.publishEvent(events.EventContainerUpdate, name, "update aborted: "+reason)
+	return fmt.Errorf("%s: %w", reason, ErrPullCheckFailed)
+}
+
+// registryUnreachable reports whether a registry lookup failed because the
+// registry could not be reached at all. Auth, not-found and quota errors
+// mean it answered, so the pull is left to report those itself.
+func registryUnreachable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	// Errors relayed by the daemon arrive as plain strings.
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{
+		"no such host",
+		"connection refused",
+		"connection reset",
+		"i/o timeout",
+		"network is unreachable",
+		"no route to host",
+		"tls handshake timeout",
+		"server misbehaving",
+		"context deadline exceeded",
+	} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchImageSize reads the compressed size of image from its registry.
+func (u *Updater) fetchImageSize(ctx context.Context, image string) (int64, error) {
+	if u.imageSize != nil {
+		return u.imageSize(ctx, image)
+	}
+	ctx, cancel := context.WithTimeout(ctx, pullCheckTimeout)
+	defer cancel()
+	token, host, cred, err := u.registryAuth(ctx, image)
+	if err != nil {
+		return 0, err
+	}
+	return registry.FetchImageSize(ctx, image, token, host, cred)
+}
+
+// freeSpace returns the bytes available to unprivileged users on the
+// filesystem holding path.
+func (u *Updater) freeSpace(path string) (uint64, error) {
+	if u.diskFree != nil {
+		return u.diskFree(path)
+	}
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, fmt.Errorf("statfs %s: %w", path, err)
+	}
+	return uint64(st.Bavail) * uint64(st.Bsize), nil
+}
+
+// formatBytes renders n in binary units, e.g. "1.5 GiB".
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,164 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/moby/moby/api/types/container"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+)
+
+func pullCheckTestUpdater(t *testing.T) (*Updater, *mockDocker, *resultStrategy) {
+	t.Helper()
+	mock := newMockDocker()
+	mock.inspectResults["aaa"] = container.InspectResponse{
+		ID:     "aaa",
+		Config: &container.Config{Image: "app:1", Labels: map[string]string{"sentinel.strategy": "capture"}},
+	}
+	u, _ := newTestUpdater(t, mock)
+	u.SetSettingsReader(u.store)
+	s := &resultStrategy{}
+	u.RegisterStrategy(s)
+	return u, mock, s
+}
+
+func TestPullCheckRegistryUnreachable(t *testing.T) {
+	u, mock, s := pullCheckTestUpdater(t)
+	mock.distErr["app:2"] = errors.New("Get \"https://registry-1.docker.io/v2/\": dial tcp: lookup registry-1.docker.io: no such host")
+
+	err := u.UpdateContainer(context.Background(), "aaa", "app", "app:2")
+	if !errors.Is(err, ErrPullCheckFailed) {
+		t.Fatalf("error = %v, want ErrPullCheckFailed", err)
+	}
+	if s.calls != 0 {
+		t.Error("strategy ran despite an unreachable registry")
+	}
+	history, _ := u.store.ListHistory(10, "")
+	if len(history) != 1 || history[0].Outcome != "aborted" || history[0].FailureClass != store.FailureRegistryUnreachable {
+		t.Fatalf("history = %+v, want one aborted registry_unreachable record", history)
+	}
+	if history[0].NewImage != "app:2" || history[0].OldImage != "app:1" {
+		t.Errorf("images = %s -> %s, want app:1 -> app:2", history[0].OldImage, history[0].NewImage)
+	}
+}
+
+func TestPullCheckRegistryAnswered(t *testing.T) {
+	u, mock, s := pullCheckTestUpdater(t)
+	// The registry replied; the pull reports auth problems itself.
+	mock.distErr["app:2"] = errors.New("401 unauthorized")
+
+	if err := u.UpdateContainer(context.Background(), "aaa", "app", "app:2"); err != nil {
+		t.Fatalf("UpdateContainer: %v", err)
+	}
+	if s.calls != 1 {
+		t.Errorf("strategy calls = %d, want 1", s.calls)
+	}
+}
+
+func TestPullCheckRegistryDisabled(t *testing.T) {
+	u, mock, s := pullCheckTestUpdater(t)
+	mock.distErr["app:2"] = errors.New("connection refused")
+	if err := u.store.SaveSetting("pull_check_registry", "false"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := u.UpdateContainer(context.Background(), "aaa", "app", "app:2"); err != nil {
+		t.Fatalf("UpdateContainer: %v", err)
+	}
+	if s.calls != 1 {
+		t.Errorf("strategy calls = %d, want 1", s.calls)
+	}
+}
+
+func TestPullCheckDiskSpace(t *testing.T) {
+	tests := []struct {
+		name    string
+		free    uint64
+		size    int64
+		sizeErr error
+		abort   bool
+	}{
+		{"room", 2 << 30, 100 << 20, nil, false},
+		{"short", 600 << 20, 100 << 20, nil, true},
+		{"size unknown, reserve met", 1 << 30, 0, errors.New("manifest: returned 404"), false},
+		{"size unknown, reserve short", 100 << 20, 0, errors.New("manifest: returned 404"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, _, s := pullCheckTestUpdater(t)
+			if err := u.store.SaveSetting("pull_check_disk_path", "/var/lib/docker"); err != nil {
+				t.Fatal(err)
+			}
+			var gotPath string
+			u.diskFree = func(path string) (uint64, error) {
+				gotPath = path
+				return tt.free, nil
+			}
+			u.imageSize = func(_ context.Context, image string) (int64, error) {
+				if image != "app:2" {
+					t.Errorf("sized %q, want app:2", image)
+				}
+				return tt.size, tt.sizeErr
+			}
+
+			err := u.UpdateContainer(context.Background(), "aaa", "app", "app:2")
+			if gotPath != "/var/lib/docker" {
+				t.Errorf("checked %q, want /var/lib/docker", gotPath)
+			}
+			if !tt.abort {
+				if err != nil || s.calls != 1 {
+					t.Fatalf("err = %v, calls = %d; want the update to run", err, s.calls)
+				}
+				return
+			}
+			if !errors.Is(err, ErrPullCheckFailed) || s.calls != 0 {
+				t.Fatalf("err = %v, calls = %d; want aborted before the strategy", err, s.calls)
+			}
+			history, _ := u.store.ListHistory(10, "")
+			if len(history) != 1 || history[0].Outcome != "aborted" || history[0].FailureClass != store.FailureDiskSpace {
+				t.Fatalf("history = %+v, want one aborted disk_space record", history)
+			}
+		})
+	}
+}
+
+func TestPullCheckDiskOffByDefault(t *testing.T) {
+	u, _, s := pullCheckTestUpdater(t)
+	u.diskFree = func(string) (uint64, error) {
+		t.Error("disk checked without pull_check_disk_path")
+		return 0, nil
+	}
+	if err := u.UpdateContainer(context.Background(), "aaa", "app", "app:2"); err != nil {
+		t.Fatalf("UpdateContainer: %v", err)
+	}
+	if s.calls != 1 {
+		t.Errorf("strategy calls = %d, want 1", s.calls)
+	}
+}
+
+func TestRegistryUnreachable(t *testing.T) {
+	for _, err := range []error{
+		context.DeadlineExceeded,
+		errors.New("dial tcp 10.0.0.5:443: connect: connection refused"),
+		errors.New("net/http: TLS handshake timeout"),
+	} {
+		if !registryUnreachable(err) {
+			t.Errorf("registryUnreachable(%q) = false, want true", err)
+		}
+	}
+	for _, err := range []error{nil, errors.New("401 unauthorized"), errors.New("manifest unknown")} {
+		if registryUnreachable(err) {
+			t.Errorf("registryUnreachable(%v) = true, want false", err)
+		}
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	for n, want := range map[uint64]string{512: "512 B", 1536: "1.5 KiB", 3 << 30: "3.0 GiB"} {
+		if got := formatBytes(n); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
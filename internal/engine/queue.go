@@ -1,14 +1,19 @@
 package engine
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+	"github.com/Will-Luck/Docker-Sentinel/internal/lifecycle"
+	"github.com/Will-Luck/Docker-Sentinel/internal/registry"
+	"github.com/Will-Luck/Docker-Sentinel/internal/scanner"
 	"github.com/Will-Luck/Docker-Sentinel/internal/store"
 )
 
@@ -26,6 +31,42 @@ type PendingUpdate struct {
 	Type                   string    `json:"type,omitempty"`    // "container" (default) or "service"
 	HostID                 string    `json:"host_id,omitempty"` // cluster host ID (empty = local)
 	HostName               string    `json:"host_name,omitempty"`
+	// Candidates classifies each of NewerVersions as a major, minor, patch
+	// or build change, so an approver can pick a version other than the newest.
+	Candidates []registry.VersionCandidate `json:"candidates,omitempty"`
+	// ScanReport holds the findings at or above ScanThreshold when the
+	// pre-update scan held back an update; approving the entry overrides it.
+	ScanReport    *scanner.ScanResult `json:"scan_report,omitempty"`
+	ScanThreshold scanner.Severity    `json:"scan_threshold,omitempty"`
+	// ConfigDiff lists default config files that change in the new image
+	// under paths the container mounts over; nil when previews are off.
+	ConfigDiff *ConfigDiff `json:"config_diff,omitempty"`
+	// Order is the execution position within the queue; lower runs first.
+	// Assigned on Add and changed with Reorder.
+	Order int `json:"order"`
+	// StaleNotified is set once the aging alert for this entry has been sent.
+	StaleNotified bool `json:"stale_notified,omitempty"`
+	// Comment is an operator's note on the entry (e.g. why it is being held),
+	// copied to the history record when the update is approved or rejected.
+	Comment string `json:"comment,omitempty"`
+}
+
+// approvalCommentKey carries the approver's comment through an update so it
+// is stored on the history records the update writes.
+type approvalCommentKey struct{}
+
+// WithApprovalComment returns a context whose update history records carry
+// comment. Use it when running an approved queue entry.
+func WithApprovalComment(ctx context.Context, comment string) context.Context {
+	if comment == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, approvalCommentKey{}, comment)
+}
+
+func approvalComment(ctx context.Context) string {
+	c, _ := ctx.Value(approvalCommentKey{}).(string)
+	return c
 }
 
 // Queue manages pending updates with BoltDB persistence.
@@ -35,6 +76,8 @@ type Queue struct {
 	store   *store.Store
 	events  *events.Bus
 	log     *slog.Logger
+
+	lifecycle *lifecycle.Sink // optional: machine event sink
 }
 
 // NewQueue creates a queue, optionally restoring from BoltDB.
@@ -51,7 +94,19 @@ func NewQueue(s *store.Store, bus *events.Bus, log *slog.Logger) *Queue {
 	if err == nil && data != nil {
 		var items []PendingUpdate
 		if json.Unmarshal(data, &items) == nil {
-			for _, item := range items {
+			// Entries persisted before ordering existed have Order 0; place
+			// them after any ordered entries, oldest first.
+			sort.SliceStable(items, func(i, j int) bool {
+				if (items[i].Order == 0) != (items[j].Order == 0) {
+					return items[i].Order != 0
+				}
+				if items[i].Order != items[j].Order {
+					return items[i].Order < items[j].Order
+				}
+				return items[i].DetectedAt.Before(items[j].DetectedAt)
+			})
+			for i, item := range items {
+				item.Order = i + 1
 				q.pending[item.Key()] = item
 			}
 		}
@@ -60,16 +115,41 @@ func NewQueue(s *store.Store, bus *events.Bus, log *slog.Logger) *Queue {
 	return q
 }
 
-// Add adds or replaces a pending update.
+// Add adds or replaces a pending update. A replaced entry keeps its position
+// and the time it was first detected, so re-scans don't reset its age; a new
+// one goes to the back of the queue unless it already carries an Order (e.g.
+// an approved update re-enqueued because another update was running).
 // Snapshots data under lock (fast), persists to BoltDB outside lock (slow I/O).
 func (q *Queue) Add(update PendingUpdate) {
 	var data []byte
 	q.mu.Lock()
+	existing, replaced := q.pending[update.Key()]
+	if replaced {
+		update.Order = existing.Order
+		if !existing.DetectedAt.IsZero() && existing.DetectedAt.Before(update.DetectedAt) {
+			update.DetectedAt = existing.DetectedAt
+		}
+		update.StaleNotified = update.StaleNotified || existing.StaleNotified
+		if update.Comment == "" {
+			update.Comment = existing.Comment
+		}
+	} else if update.Order == 0 {
+		update.Order = q.maxOrderLocked() + 1
+	}
 	q.pending[update.Key()] = update
 	data = q.snapshotLocked()
 	q.mu.Unlock()
 	q.persistData(data)
 	q.publishEvent(update.Key(), "added")
+	if !replaced {
+		q.lifecycle.Emit(lifecycle.Event{
+			State:     lifecycle.StateQueued,
+			Container: update.ContainerName,
+			HostID:    update.HostID,
+			OldImage:  update.CurrentImage,
+			NewImage:  update.ResolvedTargetVersion,
+		})
+	}
 }
 
 // Key returns the queue map key for this update. Remote containers use
@@ -114,21 +194,104 @@ func (q *Queue) Approve(name string) (PendingUpdate, bool) {
 	if ok {
 		q.persistData(data)
 		q.publishEvent(name, "approved")
+		q.lifecycle.Emit(lifecycle.Event{State: lifecycle.StateApproved, Container: u.ContainerName, HostID: u.HostID})
 	}
 	return u, ok
 }
 
-// List returns all pending updates.
+// SetComment replaces the operator comment on a pending update. Returns
+// false if name is not queued.
+func (q *Queue) SetComment(name, comment string) bool {
+	var data []byte
+	q.mu.Lock()
+	u, ok := q.pending[name]
+	if ok {
+		u.Comment = comment
+		q.pending[name] = u
+		data = q.snapshotLocked()
+	}
+	q.mu.Unlock()
+	if ok {
+		q.persistData(data)
+		q.publishEvent(name, "comment updated")
+	}
+	return ok
+}
+
+// List returns all pending updates in execution order.
 func (q *Queue) List() []PendingUpdate {
 	q.mu.Lock()
 	defer q.mu.Unlock()
+	return q.sortedLocked()
+}
+
+// Reorder moves the given keys to the front of the queue in the order
+// supplied. Entries not listed keep their relative order behind them.
+// Returns an error (and changes nothing) if a key is unknown or repeated.
+func (q *Queue) Reorder(keys []string) error {
+	q.mu.Lock()
+	seen := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		if _, ok := q.pending[k]; !ok {
+			q.mu.Unlock()
+			return fmt.Errorf("no pending update for %s", k)
+		}
+		if seen[k] {
+			q.mu.Unlock()
+			return fmt.Errorf("duplicate key %s", k)
+		}
+		seen[k] = true
+	}
+	rest := q.sortedLocked()
+	pos := 1
+	for _, k := range keys {
+		u := q.pending[k]
+		u.Order = pos
+		q.pending[k] = u
+		pos++
+	}
+	for _, u := range rest {
+		if seen[u.Key()] {
+			continue
+		}
+		u.Order = pos
+		q.pending[u.Key()] = u
+		pos++
+	}
+	data := q.snapshotLocked()
+	q.mu.Unlock()
+	q.persistData(data)
+	q.publishEvent("", "reordered")
+	return nil
+}
+
+// sortedLocked returns the pending updates sorted by Order, with the key as
+// a tie-breaker. Must be called with q.mu held.
+func (q *Queue) sortedLocked() []PendingUpdate {
 	result := make([]PendingUpdate, 0, len(q.pending))
 	for _, u := range q.pending {
 		result = append(result, u)
 	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Order != result[j].Order {
+			return result[i].Order < result[j].Order
+		}
+		return result[i].Key() < result[j].Key()
+	})
 	return result
 }
 
+// maxOrderLocked returns the highest Order in the queue. Must be called with q.mu held.
+func (q *Queue) maxOrderLocked() int {
+	var maxOrder int
+	for _, u := range q.pending {
+		if u.Order > maxOrder {
+			maxOrder = u.Order
+		}
+	}
+	return maxOrder
+}
+
 // Len returns the number of pending updates.
 func (q *Queue) Len() int {
 	q.mu.Lock()
@@ -177,11 +340,7 @@ func (q *Queue) publishEvent(name, message string) {
 
 // snapshotLocked marshals the current queue state. Must be called with q.mu held.
 func (q *Queue) snapshotLocked() []byte {
-	items := make([]PendingUpdate, 0, len(q.pending))
-	for _, u := range q.pending {
-		items = append(items, u)
-	}
-	data, err := json.Marshal(items)
+	data, err := json.Marshal(q.sortedLocked())
 	if err != nil {
 		return nil
 	}
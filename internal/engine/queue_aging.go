@@ -0,0 +1,126 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/docker"
+	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+	"github.com/Will-Luck/Docker-Sentinel/internal/notify"
+)
+
+// QueueStaleAfter parses the queue_stale_after setting: how long a pending
+// approval may wait before it is flagged as stale (e.g. "14d"). Empty, "0"
+// and invalid values disable aging alerts.
+func QueueStaleAfter(val string) time.Duration {
+	if val == "" || val == "0" {
+		return 0
+	}
+	d, err := docker.ParseDurationWithDays(val)
+	if err != nil || d <= 0 {
+		return 0
+	}
+	return d
+}
+
+// Stale returns the pending updates that have waited at least after, oldest
+// first. A zero after disables aging and returns nil.
+func (q *Queue) Stale(after time.Duration, now time.Time) []PendingUpdate {
+	if after <= 0 {
+		return nil
+	}
+	q.mu.Lock()
+	var out []PendingUpdate
+	for _, item := range q.pending {
+		if !item.DetectedAt.IsZero() && now.Sub(item.DetectedAt) >= after {
+			out = append(out, item)
+		}
+	}
+	q.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].DetectedAt.Before(out[j].DetectedAt) })
+	return out
+}
+
+// MarkStaleNotified records that the aging alert for a queue entry was sent.
+func (q *Queue) MarkStaleNotified(key string) {
+	q.mu.Lock()
+	item, ok := q.pending[key]
+	if ok {
+		item.StaleNotified = true
+		q.pending[key] = item
+		q.persist()
+	}
+	q.mu.Unlock()
+}
+
+// queueStaleAfter reads the queue_stale_after setting.
+func (u *Updater) queueStaleAfter() time.Duration {
+	if u.settings == nil {
+		return 0
+	}
+	val, _ := u.settings.LoadSetting("queue_stale_after")
+	return QueueStaleAfter(val)
+}
+
+// reviewStaleQueue alerts once for each pending approval that has waited
+// longer than the queue_stale_after setting, so updates don't sit in the
+// queue unnoticed. Approving or rejecting the entry clears it; a later
+// detection starts the clock again.
+func (u *Updater) reviewStaleQueue(ctx context.Context) {
+	now := u.clock.Now()
+	for _, item := range u.queue.Stale(u.queueStaleAfter(), now) {
+		if item.StaleNotified {
+			continue
+		}
+		msg := "Awaiting approval for " + waitingFor(now.Sub(item.DetectedAt)) + ", since " + item.DetectedAt.Format("2006-01-02")
+		if item.HostName != "" {
+			msg += " on " + item.HostName
+		}
+		u.log.Info("pending approval is stale", "name", item.Key(), "detected", item.DetectedAt)
+		u.publishEvent(events.EventQueueChange, item.Key(), msg)
+		u.notifier.Notify(ctx, notify.Event{
+			Type:          notify.EventQueueStale,
+			ContainerName: item.ContainerName,
+			HostID:        item.HostID,
+			OldImage:      item.CurrentImage,
+			OldVersion:    item.ResolvedCurrentVersion,
+			NewVersion:    item.ResolvedTargetVersion,
+			Message:       msg,
+			Timestamp:     now,
+		})
+		u.queue.MarkStaleNotified(item.Key())
+	}
+}
+
+// staleSummary renders the stale approvals among names for the digest, or ""
+// when there are none.
+func staleSummary(stale []PendingUpdate, names []string, now time.Time) string {
+	var parts []string
+	for _, item := range stale {
+		if !slices.Contains(names, item.ContainerName) {
+			continue
+		}
+		label := item.ContainerName
+		if item.HostName != "" {
+			label += " on " + item.HostName
+		}
+		parts = append(parts, fmt.Sprintf("%s (%s)", label, waitingFor(now.Sub(item.DetectedAt))))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Stale approvals: %s", strings.Join(parts, ", "))
+}
+
+// waitingFor renders how long an approval has waited: whole days from two
+// days up, hours below that.
+func waitingFor(d time.Duration) string {
+	if d >= 48*time.Hour {
+		return fmt.Sprintf("%d days", int(d.Hours()/24))
+	}
+	return fmt.Sprintf("%d hours", int(d.Hours()))
+}
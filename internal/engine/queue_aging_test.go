@@ -0,0 +1,84 @@
+package engine
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQueueStaleAfter(t *testing.T) {
+	tests := map[string]time.Duration{
+		"":    0,
+		"0":   0,
+		"14d": 14 * 24 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+		"36h": 36 * time.Hour,
+		"-1h": 0,
+		"bad": 0,
+	}
+	for val, want := range tests {
+		if got := QueueStaleAfter(val); got != want {
+			t.Errorf("QueueStaleAfter(%q) = %v, want %v", val, got, want)
+		}
+	}
+}
+
+func TestQueueAddKeepsDetectedAt(t *testing.T) {
+	q := NewQueue(testStore(t), nil, nil)
+	first := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	q.Add(PendingUpdate{ContainerName: "app", DetectedAt: first})
+	q.MarkStaleNotified("app")
+	q.Add(PendingUpdate{ContainerName: "app", RemoteDigest: "sha256:new", DetectedAt: first.Add(48 * time.Hour)})
+
+	got, _ := q.Get("app")
+	if !got.DetectedAt.Equal(first) {
+		t.Errorf("DetectedAt = %v, want the first detection %v", got.DetectedAt, first)
+	}
+	if !got.StaleNotified || got.RemoteDigest != "sha256:new" {
+		t.Errorf("replaced entry = %+v, want new digest with the stale alert kept", got)
+	}
+}
+
+func TestReviewStaleQueue(t *testing.T) {
+	u, clk := newTestUpdater(t, newMockDocker())
+	u.SetSettingsReader(u.store)
+	now := clk.Now()
+
+	u.queue.Add(PendingUpdate{ContainerName: "old", DetectedAt: now.Add(-20 * 24 * time.Hour)})
+	u.queue.Add(PendingUpdate{ContainerName: "new", DetectedAt: now.Add(-time.Hour)})
+
+	// Aging is off by default.
+	u.reviewStaleQueue(context.Background())
+	if item, _ := u.queue.Get("old"); item.StaleNotified {
+		t.Fatal("alerted with aging disabled")
+	}
+
+	_ = u.store.SaveSetting("queue_stale_after", "14d")
+	u.reviewStaleQueue(context.Background())
+	if item, _ := u.queue.Get("old"); !item.StaleNotified {
+		t.Error("old approval not alerted")
+	}
+	if item, _ := u.queue.Get("new"); item.StaleNotified {
+		t.Error("recent approval alerted")
+	}
+	if stale := u.queue.Stale(14*24*time.Hour, now); len(stale) != 1 || stale[0].ContainerName != "old" {
+		t.Errorf("Stale = %+v, want only old", stale)
+	}
+}
+
+func TestDigestSummarisesStaleApprovals(t *testing.T) {
+	now := time.Date(2026, 1, 30, 9, 0, 0, 0, time.UTC)
+	d, s := newTestDigestScheduler(t, now)
+	_ = s.SaveSetting("queue_stale_after", "14d")
+	d.queue.Add(PendingUpdate{ContainerName: "db", DetectedAt: now.Add(-20 * 24 * time.Hour)})
+	d.queue.Add(PendingUpdate{ContainerName: "web", DetectedAt: now.Add(-time.Hour)})
+
+	ev := d.digestEvent([]string{"db", "web"})
+	if !strings.Contains(ev.Message, "db (20 days)") || strings.Contains(ev.Message, "web") {
+		t.Errorf("Message = %q, want only db listed as stale", ev.Message)
+	}
+	if ev := d.digestEvent([]string{"web"}); ev.Message != "" {
+		t.Errorf("Message = %q, want none for a digest without stale approvals", ev.Message)
+	}
+}
@@ -135,3 +135,89 @@ func TestQueuePersistence(t *testing.T) {
 		t.Errorf("restored nginx = %+v, ok=%v", u, ok)
 	}
 }
+
+func TestQueueOrderAssignedOnAdd(t *testing.T) {
+	s := testStore(t)
+	q := NewQueue(s, nil, nil)
+
+	q.Add(PendingUpdate{ContainerName: "redis"})
+	q.Add(PendingUpdate{ContainerName: "api"})
+	q.Add(PendingUpdate{ContainerName: "db"})
+	// Replacing an entry keeps its position.
+	q.Add(PendingUpdate{ContainerName: "redis", RemoteDigest: "sha256:new"})
+
+	want := []string{"redis", "api", "db"}
+	for i, u := range q.List() {
+		if u.Key() != want[i] {
+			t.Errorf("List()[%d] = %q, want %q", i, u.Key(), want[i])
+		}
+	}
+}
+
+func TestQueueReorder(t *testing.T) {
+	s := testStore(t)
+	q := NewQueue(s, nil, nil)
+
+	q.Add(PendingUpdate{ContainerName: "api"})
+	q.Add(PendingUpdate{ContainerName: "web"})
+	q.Add(PendingUpdate{ContainerName: "db", HostID: "h1"})
+
+	if err := q.Reorder([]string{"h1::db", "web"}); err != nil {
+		t.Fatalf("Reorder: %v", err)
+	}
+	want := []string{"h1::db", "web", "api"}
+	for i, u := range q.List() {
+		if u.Key() != want[i] {
+			t.Errorf("List()[%d] = %q, want %q", i, u.Key(), want[i])
+		}
+	}
+
+	// Order survives a restart.
+	q2 := NewQueue(s, nil, nil)
+	for i, u := range q2.List() {
+		if u.Key() != want[i] {
+			t.Errorf("restored List()[%d] = %q, want %q", i, u.Key(), want[i])
+		}
+	}
+}
+
+func TestQueueReorderRejectsUnknownKey(t *testing.T) {
+	s := testStore(t)
+	q := NewQueue(s, nil, nil)
+	q.Add(PendingUpdate{ContainerName: "api"})
+	q.Add(PendingUpdate{ContainerName: "db"})
+
+	if err := q.Reorder([]string{"db", "missing"}); err == nil {
+		t.Fatal("expected error for unknown key")
+	}
+	if err := q.Reorder([]string{"db", "db"}); err == nil {
+		t.Fatal("expected error for duplicate key")
+	}
+	if got := q.List()[0].Key(); got != "api" {
+		t.Errorf("failed Reorder changed order: first = %q, want api", got)
+	}
+}
+
+func TestQueueSetCommentSurvivesRescanAndRestart(t *testing.T) {
+	s := testStore(t)
+	q := NewQueue(s, nil, nil)
+	q.Add(PendingUpdate{ContainerName: "app", NewerVersions: []string{"3.0"}})
+
+	if !q.SetComment("app", "waiting for plugin compatibility") {
+		t.Fatal("SetComment returned false for a queued entry")
+	}
+	if q.SetComment("missing", "x") {
+		t.Error("SetComment returned true for an unknown entry")
+	}
+
+	// A re-scan replaces the entry without a comment.
+	q.Add(PendingUpdate{ContainerName: "app", NewerVersions: []string{"3.0.1"}})
+	if u, _ := q.Get("app"); u.Comment != "waiting for plugin compatibility" {
+		t.Errorf("Comment after re-scan = %q", u.Comment)
+	}
+
+	q2 := NewQueue(s, nil, nil)
+	if u, _ := q2.Get("app"); u.Comment != "waiting for plugin compatibility" {
+		t.Errorf("Comment after restart = %q", u.Comment)
+	}
+}
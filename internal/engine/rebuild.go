@@ -0,0 +1,128 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/docker"
+	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+	"github.com/Will-Luck/Docker-Sentinel/internal/notify"
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+)
+
+// rebuildWatchEnabled reads the rebuild_watch setting. Off by default:
+// locally built images are otherwise skipped by the scan.
+func (u *Updater) rebuildWatchEnabled() bool {
+	if u.settings == nil {
+		return false
+	}
+	val, err := u.settings.LoadSetting("rebuild_watch")
+	return err == nil && val == "true"
+}
+
+// rebuildWebhookURL returns the webhook to call when a container's base image
+// changes. It comes only from the operator's rebuild_webhook_url setting, so
+// a label can't send container details to a URL of its choosing; the
+// sentinel.rebuild-webhook label can only opt a container out with "off".
+// Empty means notify only.
+func (u *Updater) rebuildWebhookURL(name string, labels map[string]string) string {
+	if u.settings == nil {
+		return ""
+	}
+	val, _ := u.settings.LoadSetting("rebuild_webhook_url")
+	val = strings.TrimSpace(val)
+	switch v := docker.ContainerRebuildWebhook(labels); strings.ToLower(v) {
+	case "":
+	case "off", "none":
+		return ""
+	default:
+		u.log.Warn("ignoring sentinel.rebuild-webhook label; only \"off\" is supported", "name", name, "value", v)
+	}
+	if val != "" {
+		if parsed, err := url.Parse(val); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			u.log.Warn("rebuild webhook is not an http or https URL, skipping it", "url", val)
+			return ""
+		}
+	}
+	return val
+}
+
+// checkBaseImage handles a container whose image has no registry to check,
+// typically one built locally from a Dockerfile. When rebuild watching is on
+// and the build metadata names a base image, the base is looked up in its
+// registry; once it has moved on from the digest the image was built
+// against, a Rebuild Needed alert goes out (once per new base digest) and
+// the rebuild webhook, if any, is called so CI can rebuild the image.
+// Returns true when the base image was checked.
+func (u *Updater) checkBaseImage(ctx context.Context, name, imageRef, imageID string, labels map[string]string) bool {
+	base, builtDigest := docker.ContainerBaseImage(labels)
+	if base == "" || !u.rebuildWatchEnabled() {
+		return false
+	}
+	latest, err := u.docker.DistributionDigest(ctx, base)
+	if err != nil {
+		u.log.Warn("base image check failed", "name", name, "base", base, "error", err)
+		return false
+	}
+
+	st, ok := u.store.GetRebuildState(name)
+	if !ok || st.ImageID != imageID || st.BaseImage != base {
+		// First check, or the image has been rebuilt since: compare against
+		// the digest the build recorded, else the base as it is now.
+		st = store.RebuildState{ImageID: imageID, BaseImage: base, BaseDigest: builtDigest}
+		if st.BaseDigest == "" {
+			st.BaseDigest = latest
+		}
+	}
+	st.LatestDigest = latest
+	st.CheckedAt = u.clock.Now()
+
+	if st.RebuildNeeded() && st.NotifiedDigest != latest {
+		u.notifyRebuild(ctx, name, imageRef, labels, st)
+		st.NotifiedDigest = latest
+	}
+	if err := u.store.SetRebuildState(name, st); err != nil {
+		u.log.Warn("failed to save rebuild state", "name", name, "error", err)
+	}
+	return true
+}
+
+// notifyRebuild alerts that a container's base image has changed and calls
+// the rebuild webhook. The webhook receives the same JSON event as a
+// generic webhook channel.
+func (u *Updater) notifyRebuild(ctx context.Context, name, imageRef string, labels map[string]string, st store.RebuildState) {
+	event := notify.Event{
+		Type:          notify.EventRebuildNeeded,
+		ContainerName: name,
+		OldImage:      imageRef,
+		NewImage:      st.BaseImage,
+		OldDigest:     st.BaseDigest,
+		NewDigest:     st.LatestDigest,
+		Timestamp:     u.clock.Now(),
+	}
+	event.Message = "Base image " + st.BaseImage + " updated, rebuild needed"
+
+	if hook := u.rebuildWebhookURL(name, labels); hook != "" {
+		if err := triggerRebuild(ctx, hook, event); err != nil {
+			u.log.Warn("rebuild webhook failed", "name", name, "error", err)
+			event.Message = fmt.Sprintf("Base image %s updated, rebuild webhook failed: %v", st.BaseImage, err)
+		} else {
+			event.Message = "Base image " + st.BaseImage + " updated, rebuild triggered"
+		}
+	}
+
+	u.log.Info("base image updated", "name", name, "base", st.BaseImage, "digest", st.LatestDigest)
+	u.publishEvent(events.EventContainerUpdate, name, event.Message)
+	u.notifier.Notify(ctx, event)
+}
+
+// triggerRebuild posts the rebuild event to a CI webhook.
+func triggerRebuild(ctx context.Context, url string, event notify.Event) error {
+	hook, err := notify.NewWebhook(url, nil)
+	if err != nil {
+		return err
+	}
+	return hook.Send(ctx, event)
+}
@@ -0,0 +1,130 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/notify"
+	"github.com/moby/moby/api/types/container"
+)
+
+func TestScanLocalImage_BaseImageRebuildTrigger(t *testing.T) {
+	var mu sync.Mutex
+	var calls []notify.Event
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ev notify.Event
+		_ = json.NewDecoder(r.Body).Decode(&ev)
+		mu.Lock()
+		calls = append(calls, ev)
+		mu.Unlock()
+	}))
+	defer hook.Close()
+
+	labels := map[string]string{
+		"org.opencontainers.image.base.name":   "python:3.12-slim",
+		"org.opencontainers.image.base.digest": "sha256:built",
+	}
+	mock := newMockDocker()
+	mock.containers = []container.Summary{
+		{ID: "aaa", Names: []string{"/myapp"}, Image: "myapp:latest", ImageID: "sha256:img1", Labels: labels},
+	}
+	mock.distErr["myapp:latest"] = errors.New("repository does not exist")
+	mock.distDigests["python:3.12-slim"] = "sha256:built"
+
+	u, _ := newTestUpdater(t, mock)
+	u.SetSettingsReader(u.store)
+	ctx := context.Background()
+
+	// Off by default: the local image is skipped without a base check.
+	u.Scan(ctx, ScanScheduled)
+	if _, ok := u.store.GetRebuildState("myapp"); ok {
+		t.Fatal("base image checked with rebuild watching off")
+	}
+
+	_ = u.store.SaveSetting("rebuild_watch", "true")
+	_ = u.store.SaveSetting("rebuild_webhook_url", hook.URL)
+	u.Scan(ctx, ScanScheduled)
+	st, ok := u.store.GetRebuildState("myapp")
+	if !ok || st.RebuildNeeded() {
+		t.Fatalf("state = %+v, %v, want up to date", st, ok)
+	}
+
+	// The base moves on: one webhook call, not repeated on the next scan.
+	mock.distDigests["python:3.12-slim"] = "sha256:newer"
+	u.Scan(ctx, ScanScheduled)
+	u.Scan(ctx, ScanScheduled)
+	mu.Lock()
+	if len(calls) != 1 {
+		t.Fatalf("webhook calls = %d, want 1", len(calls))
+	}
+	got := calls[0]
+	mu.Unlock()
+	if got.Type != notify.EventRebuildNeeded || got.ContainerName != "myapp" || got.NewImage != "python:3.12-slim" ||
+		got.OldDigest != "sha256:built" || got.NewDigest != "sha256:newer" {
+		t.Errorf("webhook event = %+v", got)
+	}
+
+	// Rebuilt against the new base: nothing further to do.
+	mock.containers[0].ImageID = "sha256:img2"
+	mock.containers[0].Labels = map[string]string{
+		"org.opencontainers.image.base.name":   "python:3.12-slim",
+		"org.opencontainers.image.base.digest": "sha256:newer",
+	}
+	u.Scan(ctx, ScanScheduled)
+	if st, _ := u.store.GetRebuildState("myapp"); st.RebuildNeeded() || st.ImageID != "sha256:img2" {
+		t.Errorf("state after rebuild = %+v", st)
+	}
+}
+
+func TestCheckBaseImage_NoRecordedDigestUsesFirstSeen(t *testing.T) {
+	mock := newMockDocker()
+	mock.distDigests["alpine:3.20"] = "sha256:a"
+	u, _ := newTestUpdater(t, mock)
+	u.SetSettingsReader(u.store)
+	_ = u.store.SaveSetting("rebuild_watch", "true")
+	ctx := context.Background()
+	labels := map[string]string{"sentinel.base-image": "alpine:3.20"}
+
+	if !u.checkBaseImage(ctx, "tool", "tool:dev", "sha256:img", labels) {
+		t.Fatal("base image not checked")
+	}
+	if st, _ := u.store.GetRebuildState("tool"); st.BaseDigest != "sha256:a" || st.RebuildNeeded() {
+		t.Errorf("state = %+v, want the first seen digest as baseline", st)
+	}
+
+	mock.distDigests["alpine:3.20"] = "sha256:b"
+	u.checkBaseImage(ctx, "tool", "tool:dev", "sha256:img", labels)
+	if st, _ := u.store.GetRebuildState("tool"); !st.RebuildNeeded() || st.NotifiedDigest != "sha256:b" {
+		t.Errorf("state = %+v, want rebuild needed and notified", st)
+	}
+
+	if u.checkBaseImage(ctx, "other", "other:dev", "sha256:x", nil) {
+		t.Error("checked a container without base image metadata")
+	}
+}
+
+func TestRebuildWebhookURL_LabelCanOnlyOptOut(t *testing.T) {
+	u, _ := newTestUpdater(t, newMockDocker())
+	u.SetSettingsReader(u.store)
+	_ = u.store.SaveSetting("rebuild_webhook_url", "https://ci.example/rebuild")
+
+	if got := u.rebuildWebhookURL("app", nil); got != "https://ci.example/rebuild" {
+		t.Errorf("global = %q", got)
+	}
+	if got := u.rebuildWebhookURL("app", map[string]string{"sentinel.rebuild-webhook": "http://attacker.example/collect"}); got != "https://ci.example/rebuild" {
+		t.Errorf("label URL = %q, want the operator's webhook", got)
+	}
+	if got := u.rebuildWebhookURL("app", map[string]string{"sentinel.rebuild-webhook": "off"}); got != "" {
+		t.Errorf("off = %q, want none", got)
+	}
+
+	_ = u.store.SaveSetting("rebuild_webhook_url", "file:///etc/passwd")
+	if got := u.rebuildWebhookURL("app", nil); got != "" {
+		t.Errorf("non-http webhook = %q, want it skipped", got)
+	}
+}
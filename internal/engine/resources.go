@@ -0,0 +1,130 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/moby/moby/api/types/container"
+)
+
+// resourceLimits holds the HostConfig values that `docker update` can change
+// on a running container. They are captured from the live container when
+// the update snapshot is taken, reapplied to the replacement, and checked
+// again once the update has finished.
+type resourceLimits struct {
+	Memory            int64
+	MemoryReservation int64
+	MemorySwap        int64
+	NanoCPUs          int64
+	CPUShares         int64
+	CPUQuota          int64
+	CPUPeriod         int64
+	CpusetCpus        string
+	CpusetMems        string
+	PidsLimit         int64 // 0 = unset
+	BlkioWeight       uint16
+	RestartPolicy     string
+	RestartRetries    int
+}
+
+// captureLimits reads the resource limits from a container's HostConfig.
+func captureLimits(hc *container.HostConfig) resourceLimits {
+	if hc == nil {
+		return resourceLimits{}
+	}
+	r := hc.Resources
+	l := resourceLimits{
+		Memory:            r.Memory,
+		MemoryReservation: r.MemoryReservation,
+		MemorySwap:        r.MemorySwap,
+		NanoCPUs:          r.NanoCPUs,
+		CPUShares:         r.CPUShares,
+		CPUQuota:          r.CPUQuota,
+		CPUPeriod:         r.CPUPeriod,
+		CpusetCpus:        r.CpusetCpus,
+		CpusetMems:        r.CpusetMems,
+		BlkioWeight:       r.BlkioWeight,
+		RestartPolicy:     string(hc.RestartPolicy.Name),
+		RestartRetries:    hc.RestartPolicy.MaximumRetryCount,
+	}
+	if r.PidsLimit != nil {
+		l.PidsLimit = *r.PidsLimit
+	}
+	return l
+}
+
+// applyTo writes the captured limits onto hc, so the replacement container
+// keeps values changed with `docker update` after the original was created.
+func (l resourceLimits) applyTo(hc *container.HostConfig) {
+	if hc == nil {
+		return
+	}
+	hc.Memory = l.Memory
+	hc.MemoryReservation = l.MemoryReservation
+	hc.MemorySwap = l.MemorySwap
+	hc.NanoCPUs = l.NanoCPUs
+	hc.CPUShares = l.CPUShares
+	hc.CPUQuota = l.CPUQuota
+	hc.CPUPeriod = l.CPUPeriod
+	hc.CpusetCpus = l.CpusetCpus
+	hc.CpusetMems = l.CpusetMems
+	hc.BlkioWeight = l.BlkioWeight
+	if l.PidsLimit != 0 {
+		pids := l.PidsLimit
+		hc.PidsLimit = &pids
+	} else {
+		hc.PidsLimit = nil
+	}
+	hc.RestartPolicy.Name = container.RestartPolicyMode(l.RestartPolicy)
+	hc.RestartPolicy.MaximumRetryCount = l.RestartRetries
+}
+
+// diff lists the limits that differ between the captured values (l) and
+// those found on the updated container (got), as "field: want → got".
+func (l resourceLimits) diff(got resourceLimits) []string {
+	var drift []string
+	add := func(field, want, have string) {
+		if want == have {
+			return
+		}
+		if want == "" {
+			want = "unset"
+		}
+		if have == "" {
+			have = "unset"
+		}
+		drift = append(drift, fmt.Sprintf("%s: %s → %s", field, want, have))
+	}
+	i := func(v int64) string { return strconv.FormatInt(v, 10) }
+	add("memory", i(l.Memory), i(got.Memory))
+	add("memory_reservation", i(l.MemoryReservation), i(got.MemoryReservation))
+	add("memory_swap", i(l.MemorySwap), i(got.MemorySwap))
+	add("nano_cpus", i(l.NanoCPUs), i(got.NanoCPUs))
+	add("cpu_shares", i(l.CPUShares), i(got.CPUShares))
+	add("cpu_quota", i(l.CPUQuota), i(got.CPUQuota))
+	add("cpu_period", i(l.CPUPeriod), i(got.CPUPeriod))
+	add("cpuset_cpus", l.CpusetCpus, got.CpusetCpus)
+	add("cpuset_mems", l.CpusetMems, got.CpusetMems)
+	add("pids_limit", i(l.PidsLimit), i(got.PidsLimit))
+	add("blkio_weight", i(int64(l.BlkioWeight)), i(int64(got.BlkioWeight)))
+	add("restart_policy", l.RestartPolicy, got.RestartPolicy)
+	add("restart_retries", strconv.Itoa(l.RestartRetries), strconv.Itoa(got.RestartRetries))
+	return drift
+}
+
+// verifyLimits inspects the updated container and reports any resource
+// limits that did not survive the recreate. Returns nil when the container
+// cannot be inspected; the update itself has already succeeded.
+func (u *Updater) verifyLimits(ctx context.Context, id, name string, want resourceLimits) []string {
+	inspect, err := u.docker.InspectContainer(ctx, id)
+	if err != nil || inspect.HostConfig == nil {
+		u.log.Debug("resource limit verification skipped", "name", name, "error", err)
+		return nil
+	}
+	drift := want.diff(captureLimits(inspect.HostConfig))
+	if len(drift) > 0 {
+		u.log.Warn("resource limits changed by update", "name", name, "drift", drift)
+	}
+	return drift
+}
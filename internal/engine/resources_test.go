@@ -0,0 +1,82 @@
+package engine
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/moby/moby/api/types/container"
+)
+
+func TestResourceLimitsRoundTrip(t *testing.T) {
+	pids := int64(200)
+	hc := &container.HostConfig{
+		Resources: container.Resources{
+			Memory:     512 << 20,
+			NanoCPUs:   1_500_000_000,
+			CpusetCpus: "0-1",
+			PidsLimit:  &pids,
+		},
+		RestartPolicy: container.RestartPolicy{Name: container.RestartPolicyOnFailure, MaximumRetryCount: 3},
+	}
+	want := captureLimits(hc)
+
+	fresh := &container.HostConfig{}
+	want.applyTo(fresh)
+	if drift := want.diff(captureLimits(fresh)); drift != nil {
+		t.Errorf("drift after applyTo = %v, want none", drift)
+	}
+
+	got := captureLimits(&container.HostConfig{Resources: container.Resources{Memory: 512 << 20, CpusetCpus: "0-1"}})
+	drift := want.diff(got)
+	wantDrift := []string{
+		"nano_cpus: 1500000000 → 0",
+		"pids_limit: 200 → 0",
+		"restart_policy: on-failure → unset",
+		"restart_retries: 3 → 0",
+	}
+	if !slices.Equal(drift, wantDrift) {
+		t.Errorf("drift = %q, want %q", drift, wantDrift)
+	}
+}
+
+func TestUpdateContainerPreservesResourceLimits(t *testing.T) {
+	mock := newMockDocker()
+	mock.inspectResults["aaa"] = container.InspectResponse{
+		ID:     "aaa",
+		Image:  "sha256:old",
+		Config: &container.Config{Image: "nginx:1.25"},
+		// Limits raised with `docker update` after the container was created.
+		HostConfig: &container.HostConfig{
+			Resources:     container.Resources{Memory: 1 << 30, CPUQuota: 50000},
+			RestartPolicy: container.RestartPolicy{Name: container.RestartPolicyUnlessStopped},
+		},
+		NetworkSettings: &container.NetworkSettings{},
+	}
+	// The replacement comes up with the memory limit but without the CPU quota.
+	mock.inspectResults["new-web"] = container.InspectResponse{
+		ID:     "new-web",
+		State:  &container.State{Running: true},
+		Config: &container.Config{Image: "nginx:1.26"},
+		HostConfig: &container.HostConfig{
+			Resources:     container.Resources{Memory: 1 << 30},
+			RestartPolicy: container.RestartPolicy{Name: container.RestartPolicyUnlessStopped},
+		},
+	}
+	u, _ := newTestUpdater(t, mock)
+
+	if err := u.UpdateContainer(context.Background(), "aaa", "web", "nginx:1.26"); err != nil {
+		t.Fatalf("UpdateContainer: %v", err)
+	}
+	hc := mock.createHosts["web"]
+	if hc == nil || hc.Memory != 1<<30 || hc.CPUQuota != 50000 || hc.RestartPolicy.Name != container.RestartPolicyUnlessStopped {
+		t.Fatalf("create HostConfig = %+v, want the live limits reapplied", hc)
+	}
+	history, _ := u.store.ListHistoryByContainer("web", 1)
+	if len(history) != 1 || history[0].Outcome != "success" {
+		t.Fatalf("history = %+v", history)
+	}
+	if want := []string{"cpu_quota: 50000 → 0"}; !slices.Equal(history[0].ResourceDrift, want) {
+		t.Errorf("ResourceDrift = %q, want %q", history[0].ResourceDrift, want)
+	}
+}
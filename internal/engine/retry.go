@@ -0,0 +1,145 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/docker"
+	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+	"github.com/Will-Luck/Docker-Sentinel/internal/notify"
+)
+
+// Retry defaults, used when the retry_max_attempts / retry_backoff settings
+// are unset or invalid.
+const (
+	defaultRetryMaxAttempts = 3
+	defaultRetryBackoff     = 30 * time.Minute
+	maxRetryBackoff         = 24 * time.Hour
+)
+
+// retryAttemptKey marks a context as a retry of a previously failed
+// auto-update, so repeat failure notifications are suppressed.
+type retryAttemptKey struct{}
+
+func withRetryAttempt(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryAttemptKey{}, true)
+}
+
+func isRetryAttempt(ctx context.Context) bool {
+	v, _ := ctx.Value(retryAttemptKey{}).(bool)
+	return v
+}
+
+// notifyAttempt sends an update lifecycle notification unless this is a
+// retry attempt and the event would only repeat the first failure.
+// Success and failed rollbacks are always sent.
+func (u *Updater) notifyAttempt(ctx context.Context, event notify.Event) {
+	if isRetryAttempt(ctx) {
+		switch event.Type {
+		case notify.EventUpdateStarted, notify.EventUpdateFailed, notify.EventRollbackOK:
+			return
+		}
+	}
+	u.notifier.Notify(ctx, event)
+}
+
+// retryMaxAttempts returns how many consecutive failed auto-updates are
+// allowed before a container is marked failing. 0 retries forever.
+func (u *Updater) retryMaxAttempts() int {
+	if u.settings != nil {
+		if val, err := u.settings.LoadSetting("retry_max_attempts"); err == nil && val != "" {
+			if n, err := strconv.Atoi(val); err == nil && n >= 0 {
+				return n
+			}
+		}
+	}
+	return defaultRetryMaxAttempts
+}
+
+// retryBackoff returns the base delay after the first failure. It doubles
+// with each further failure, capped at maxRetryBackoff.
+func (u *Updater) retryBackoff() time.Duration {
+	if u.settings != nil {
+		if val, err := u.settings.LoadSetting("retry_backoff"); err == nil && val != "" {
+			if d, err := docker.ParseDurationWithDays(val); err == nil && d > 0 {
+				return d
+			}
+		}
+	}
+	return defaultRetryBackoff
+}
+
+// backoffFor returns the delay before the next attempt after the given
+// number of consecutive failures.
+func backoffFor(base time.Duration, attempts int) time.Duration {
+	d := base
+	for i := 1; i < attempts && d < maxRetryBackoff; i++ {
+		d *= 2
+	}
+	return min(d, maxRetryBackoff)
+}
+
+// retryBlocked reports whether an auto-update for name should be skipped
+// because it is failing or still backing off, and why.
+func (u *Updater) retryBlocked(name string) (bool, string) {
+	st, ok := u.store.GetRetryState(name)
+	if !ok {
+		return false, ""
+	}
+	if st.Failing {
+		return true, "marked failing after repeated update failures"
+	}
+	if u.clock.Now().Before(st.NextAttempt) {
+		return true, "backing off until " + st.NextAttempt.Format(time.RFC3339)
+	}
+	return false, ""
+}
+
+// recordAutoUpdateFailure bumps a container's retry state after a failed
+// auto-update and marks it failing once the attempt limit is reached.
+// Failures that never attempted the update (locked, shutting down) are ignored.
+func (u *Updater) recordAutoUpdateFailure(ctx context.Context, name string, updateErr error) {
+	if errors.Is(updateErr, ErrUpdateInProgress) || errors.Is(updateErr, ErrShuttingDown) || ctx.Err() != nil {
+		return
+	}
+	st, _ := u.store.GetRetryState(name)
+	now := u.clock.Now()
+	st.Attempts++
+	st.LastError = updateErr.Error()
+	st.LastFailure = now
+	st.NextAttempt = now.Add(backoffFor(u.retryBackoff(), st.Attempts))
+
+	limit := u.retryMaxAttempts()
+	if limit > 0 && st.Attempts >= limit && !st.Failing {
+		st.Failing = true
+		st.NextAttempt = time.Time{}
+		u.log.Warn("auto-update marked failing, retries paused until cleared",
+			"name", name, "attempts", st.Attempts, "error", updateErr)
+		u.publishEvent(events.EventContainerUpdate, name, "update failing")
+		u.notifier.Notify(ctx, notify.Event{
+			Type:          notify.EventUpdateFailed,
+			ContainerName: name,
+			Error:         fmt.Sprintf("giving up after %d failed attempts, auto-updates paused until cleared: %v", st.Attempts, updateErr),
+			Timestamp:     now,
+		})
+	} else {
+		u.log.Info("auto-update will be retried", "name", name,
+			"attempts", st.Attempts, "next_attempt", st.NextAttempt)
+	}
+	if err := u.store.SetRetryState(name, st); err != nil {
+		u.log.Warn("failed to persist retry state", "name", name, "error", err)
+	}
+}
+
+// clearRetryState forgets previous failures after a successful update.
+func (u *Updater) clearRetryState(name string) {
+	if _, ok := u.store.GetRetryState(name); !ok {
+		return
+	}
+	if err := u.store.DeleteRetryState(name); err != nil {
+		u.log.Warn("failed to clear retry state", "name", name, "error", err)
+	}
+}
@@ -0,0 +1,114 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/moby/moby/api/types/container"
+)
+
+func TestBackoffFor(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Minute},
+		{2, time.Hour},
+		{3, 2 * time.Hour},
+		{10, maxRetryBackoff},
+	}
+	for _, tt := range tests {
+		if got := backoffFor(30*time.Minute, tt.attempts); got != tt.want {
+			t.Errorf("backoffFor(30m, %d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestScanRetryBackoffAndFailing(t *testing.T) {
+	mock := newMockDocker()
+	mock.containers = []container.Summary{
+		{
+			ID: "auto1", Names: []string{"/auto-svc"}, Image: "fake.local/auto:4.0",
+			Labels: map[string]string{"sentinel.policy": "auto"},
+		},
+	}
+	mock.imageDigests["fake.local/auto:4.0"] = "fake.local/auto@sha256:oldauto"
+	mock.distDigests["fake.local/auto:4.0"] = "sha256:newauto"
+	mock.inspectResults["auto1"] = container.InspectResponse{
+		ID:              "auto1",
+		Name:            "/auto-svc",
+		Config:          &container.Config{Image: "fake.local/auto:4.0", Labels: map[string]string{"sentinel.policy": "auto"}},
+		HostConfig:      &container.HostConfig{},
+		NetworkSettings: &container.NetworkSettings{},
+	}
+	mock.pullErr["fake.local/auto:4.0"] = fmt.Errorf("network timeout")
+
+	u, clk := newTestUpdater(t, mock)
+	ctx := context.Background()
+
+	// First failure starts the backoff.
+	if res := u.Scan(ctx, ScanScheduled); res.Failed != 1 {
+		t.Fatalf("scan 1: Failed = %d, want 1", res.Failed)
+	}
+	st, ok := u.store.GetRetryState("auto-svc")
+	if !ok || st.Attempts != 1 || st.Failing {
+		t.Fatalf("after scan 1: state = %+v, %v", st, ok)
+	}
+
+	// Still within the backoff: skipped without pulling.
+	if res := u.Scan(ctx, ScanScheduled); res.Skipped != 1 || res.Failed != 0 {
+		t.Errorf("scan 2: Skipped = %d, Failed = %d; want 1, 0", res.Skipped, res.Failed)
+	}
+	if len(mock.pullCalls) != 1 {
+		t.Errorf("pullCalls = %d, want 1", len(mock.pullCalls))
+	}
+
+	// Attempts 2 and 3 run once each backoff has elapsed; the third marks it failing.
+	for i := 2; i <= defaultRetryMaxAttempts; i++ {
+		clk.Advance(maxRetryBackoff)
+		if res := u.Scan(ctx, ScanScheduled); res.Failed != 1 {
+			t.Fatalf("attempt %d: Failed = %d, want 1", i, res.Failed)
+		}
+	}
+	st, _ = u.store.GetRetryState("auto-svc")
+	if !st.Failing || st.Attempts != defaultRetryMaxAttempts {
+		t.Fatalf("state = %+v, want failing after %d attempts", st, defaultRetryMaxAttempts)
+	}
+
+	// Failing containers are not retried however long we wait.
+	clk.Advance(7 * 24 * time.Hour)
+	if res := u.Scan(ctx, ScanScheduled); res.Skipped != 1 || res.Failed != 0 {
+		t.Errorf("failing: Skipped = %d, Failed = %d; want 1, 0", res.Skipped, res.Failed)
+	}
+
+	// Clearing the state resumes updates; success leaves no state behind.
+	if err := u.store.DeleteRetryState("auto-svc"); err != nil {
+		t.Fatal(err)
+	}
+	delete(mock.pullErr, "fake.local/auto:4.0")
+	mock.inspectResults["new-auto-svc"] = container.InspectResponse{
+		ID:              "new-auto-svc",
+		Name:            "/auto-svc",
+		State:           &container.State{Running: true},
+		Config:          &container.Config{Image: "fake.local/auto:4.0", Labels: map[string]string{"sentinel.maintenance": "true"}},
+		HostConfig:      &container.HostConfig{},
+		NetworkSettings: &container.NetworkSettings{},
+	}
+	if res := u.Scan(ctx, ScanScheduled); res.Updated != 1 {
+		t.Errorf("after clear: Updated = %d, want 1", res.Updated)
+	}
+	if _, ok := u.store.GetRetryState("auto-svc"); ok {
+		t.Error("retry state should be cleared after a successful update")
+	}
+}
+
+func TestRetryAttemptContext(t *testing.T) {
+	if isRetryAttempt(context.Background()) {
+		t.Error("plain context should not be a retry attempt")
+	}
+	if !isRetryAttempt(withRetryAttempt(context.Background())) {
+		t.Error("withRetryAttempt context should be a retry attempt")
+	}
+}
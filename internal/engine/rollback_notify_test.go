@@ -0,0 +1,62 @@
+package engine
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/notify"
+	"github.com/moby/moby/api/types/container"
+)
+
+type captureNotifier struct {
+	mu     sync.Mutex
+	events []notify.Event
+}
+
+func (c *captureNotifier) Send(_ context.Context, e notify.Event) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.events = append(c.events, e)
+	return nil
+}
+
+func (c *captureNotifier) Name() string { return "capture" }
+
+func TestRollbackSendsOneNotification(t *testing.T) {
+	mock, u := setupUpdateMock(t)
+	capture := &captureNotifier{}
+	u.notifier.Reconfigure(capture)
+
+	// The replacement never runs, so validation fails and the container is
+	// rolled back; the restored container reports healthy.
+	failed := mock.inspectResults["new-nginx"]
+	failed.State = &container.State{Running: false}
+	mock.inspectResults["new-nginx"] = failed
+	mock.containers = []container.Summary{{ID: "restored", Names: []string{"/nginx"}}}
+	mock.inspectResults["restored"] = container.InspectResponse{
+		ID:    "restored",
+		State: &container.State{Running: true, Health: &container.Health{Status: container.Healthy}},
+	}
+
+	if err := u.UpdateContainer(context.Background(), "aaa", "nginx", ""); err == nil {
+		t.Fatal("expected validation failure")
+	}
+
+	var got []notify.Event
+	for _, e := range capture.events {
+		if e.Type != notify.EventUpdateStarted {
+			got = append(got, e)
+		}
+	}
+	if len(got) != 1 || got[0].Type != notify.EventRollbackOK {
+		t.Fatalf("notifications after start = %+v, want a single rollback_succeeded", got)
+	}
+	if msg := got[0].Message; !strings.Contains(msg, "Update failed at validate, rolled back to") || !strings.HasSuffix(msg, "container healthy") {
+		t.Errorf("message = %q", msg)
+	}
+	if !strings.HasPrefix(got[0].Error, "validation failed") {
+		t.Errorf("error = %q, want the validation failure", got[0].Error)
+	}
+}
@@ -0,0 +1,343 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/docker"
+	"github.com/Will-Luck/Docker-Sentinel/internal/registry"
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+	"github.com/moby/moby/api/types/container"
+)
+
+// Rollout errors returned to the pause/resume/promote API.
+var (
+	ErrRolloutNotFound  = errors.New("rollout not found")
+	ErrRolloutCompleted = errors.New("rollout already completed")
+)
+
+// RolloutGroup is one stage of a staged rollout. Groups update in the order
+// they are listed in the rollout_groups setting: containers labelled with a
+// later group wait until every earlier group has updated and baked for its
+// bake time without a failed update or rollback.
+type RolloutGroup struct {
+	Name string `json:"name"`
+	Bake string `json:"bake,omitempty"` // e.g. "6h" or "2d"; empty promotes after the first update
+}
+
+// bakeTime returns the group's parsed bake time. ParseRolloutGroups has
+// already validated it.
+func (g RolloutGroup) bakeTime() time.Duration {
+	d, _ := docker.ParseDurationWithDays(g.Bake)
+	return d
+}
+
+// ParseRolloutGroups decodes and validates the rollout_groups setting.
+func ParseRolloutGroups(raw string) ([]RolloutGroup, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	var groups []RolloutGroup
+	if err := json.Unmarshal([]byte(raw), &groups); err != nil {
+		return nil, fmt.Errorf("invalid rollout groups: %w", err)
+	}
+	seen := make(map[string]bool, len(groups))
+	for i := range groups {
+		g := &groups[i]
+		g.Name = strings.ToLower(strings.TrimSpace(g.Name))
+		if g.Name == "" {
+			return nil, fmt.Errorf("group %d: name is required", i)
+		}
+		if seen[g.Name] {
+			return nil, fmt.Errorf("duplicate rollout group %s", g.Name)
+		}
+		seen[g.Name] = true
+		if g.Bake != "" {
+			if d, err := docker.ParseDurationWithDays(g.Bake); err != nil || d < 0 {
+				return nil, fmt.Errorf("group %s: invalid bake time %q", g.Name, g.Bake)
+			}
+		}
+	}
+	return groups, nil
+}
+
+// rolloutGroups returns the configured rollout groups in order. Invalid
+// settings are logged and treated as no groups, which disables staging.
+func (u *Updater) rolloutGroups() []RolloutGroup {
+	if u.settings == nil {
+		return nil
+	}
+	raw, err := u.settings.LoadSetting("rollout_groups")
+	if err != nil || raw == "" {
+		return nil
+	}
+	groups, err := ParseRolloutGroups(raw)
+	if err != nil {
+		u.log.Warn("ignoring invalid rollout groups", "error", err)
+		return nil
+	}
+	return groups
+}
+
+// rolloutImage identifies an image across tags: registry host and
+// repository path, so "nginx:1.27" and "docker.io/library/nginx" match.
+func rolloutImage(imageRef string) string {
+	return registry.RegistryHost(imageRef) + "/" + registry.RepoPath(imageRef)
+}
+
+// rolloutTarget names what a rollout moves to: the new version tag, or the
+// remote digest when the tag itself was rebuilt.
+func rolloutTarget(scanTarget, remoteDigest string) string {
+	if scanTarget != "" {
+		return registry.ExtractTag(scanTarget)
+	}
+	return remoteDigest
+}
+
+// rolloutMembers returns the containers running image that belong to group.
+func rolloutMembers(containers []container.Summary, image, group string) []string {
+	var names []string
+	for _, c := range containers {
+		if docker.ContainerRolloutGroup(c.Labels) == group && rolloutImage(c.Image) == image {
+			names = append(names, containerName(c))
+		}
+	}
+	return names
+}
+
+// nextRolloutGroup returns the index of the first group after from that has
+// members running image, or -1 when none is left.
+func nextRolloutGroup(groups []RolloutGroup, from int, containers []container.Summary, image string) int {
+	for i := from + 1; i < len(groups); i++ {
+		if len(rolloutMembers(containers, image, groups[i].Name)) > 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// groupIndex returns the position of a group by name, or -1.
+func groupIndex(groups []RolloutGroup, name string) int {
+	return slices.IndexFunc(groups, func(g RolloutGroup) bool { return g.Name == name })
+}
+
+// rolloutGate decides whether a container in a rollout group may auto-update
+// to target now. The first sight of an update starts its rollout at the
+// earliest group running the image; a group whose bake time has passed
+// without failures promotes the next one. key is empty for containers that
+// are not part of a staged rollout; reason is empty when the update may go
+// ahead.
+func (u *Updater) rolloutGate(labels map[string]string, imageRef, target string, containers []container.Summary) (key, reason string) {
+	group := docker.ContainerRolloutGroup(labels)
+	if group == "" || target == "" {
+		return "", ""
+	}
+	groups := u.rolloutGroups()
+	gi := groupIndex(groups, group)
+	if gi < 0 {
+		return "", ""
+	}
+
+	u.rolloutMu.Lock()
+	defer u.rolloutMu.Unlock()
+
+	image := rolloutImage(imageRef)
+	key = store.RolloutKey(image, target)
+	r, ok := u.store.GetRollout(key)
+	if !ok {
+		r = u.startRollout(key, image, target, groups, containers)
+	} else if u.bakeDone(r) {
+		if failed := u.rolloutFailures(r); failed != "" {
+			r.State, r.Reason = store.RolloutHalted, failed
+			u.log.Warn("rollout halted", "rollout", key, "group", r.Group, "reason", failed)
+		} else {
+			u.promoteRollout(&r, groups, containers)
+		}
+		u.saveRollout(&r)
+	}
+
+	switch r.State {
+	case store.RolloutCompleted:
+		return key, ""
+	case store.RolloutPaused:
+		return key, "rollout paused"
+	case store.RolloutHalted:
+		return key, "rollout halted: " + r.Reason
+	}
+	if cur := groupIndex(groups, r.Group); gi > cur && cur >= 0 {
+		if !r.BakeUntil.IsZero() {
+			return key, fmt.Sprintf("waiting for rollout group %s to bake until %s", r.Group, r.BakeUntil.Format(time.RFC3339))
+		}
+		return key, "waiting for rollout group " + r.Group + " to update"
+	}
+	return key, ""
+}
+
+// startRollout creates and persists a rollout at the first group running
+// image. Rollouts of older targets for the same image are superseded and
+// removed.
+func (u *Updater) startRollout(key, image, target string, groups []RolloutGroup, containers []container.Summary) store.Rollout {
+	for _, old := range u.store.ListRollouts() {
+		if old.Image == image && old.Key != key {
+			_ = u.store.DeleteRollout(old.Key)
+		}
+	}
+	now := u.clock.Now()
+	r := store.Rollout{Key: key, Image: image, Target: target, State: store.RolloutRunning, StartedAt: now}
+	if first := nextRolloutGroup(groups, -1, containers, image); first >= 0 {
+		r.Group = groups[first].Name
+	} else {
+		r.State = store.RolloutCompleted
+	}
+	u.log.Info("rollout started", "rollout", key, "group", r.Group)
+	u.saveRollout(&r)
+	return r
+}
+
+// bakeDone reports whether a running rollout's current group has baked.
+func (u *Updater) bakeDone(r store.Rollout) bool {
+	return r.State == store.RolloutRunning && !r.BakeUntil.IsZero() && !u.clock.Now().Before(r.BakeUntil)
+}
+
+// rolloutFailures returns a description of any failed update or rollback
+// of the current group's containers since the rollout started or was last
+// resumed, or "" when there were none.
+func (u *Updater) rolloutFailures(r store.Rollout) string {
+	from := r.StartedAt
+	if r.ResumedAt.After(from) {
+		from = r.ResumedAt
+	}
+	var failed string
+	_ = u.store.ForEachHistory(from, time.Time{}, func(rec store.UpdateRecord) error {
+		if (rec.Outcome == "failed" || rec.Outcome == "rollback") && slices.Contains(r.Updated, rec.ContainerName) {
+			failed = fmt.Sprintf("%s: update %s", rec.ContainerName, rec.Outcome)
+			return errStopIteration
+		}
+		return nil
+	})
+	return failed
+}
+
+// errStopIteration ends a history walk early.
+var errStopIteration = errors.New("stop")
+
+// promoteRollout moves a rollout on to the next group running its image, or
+// completes it when none is left.
+func (u *Updater) promoteRollout(r *store.Rollout, groups []RolloutGroup, containers []container.Summary) {
+	next := nextRolloutGroup(groups, groupIndex(groups, r.Group), containers, r.Image)
+	r.Updated, r.BakeUntil, r.Reason = nil, time.Time{}, ""
+	if next < 0 {
+		r.State = store.RolloutCompleted
+		u.log.Info("rollout completed", "rollout", r.Key)
+		return
+	}
+	r.Group, r.State = groups[next].Name, store.RolloutRunning
+	u.log.Info("rollout promoted", "rollout", r.Key, "group", r.Group)
+}
+
+// rolloutUpdated records a successful update of a rollout member. An update
+// in the current group (re)starts its bake time.
+func (u *Updater) rolloutUpdated(key, name string, labels map[string]string) {
+	if key == "" {
+		return
+	}
+	u.rolloutMu.Lock()
+	defer u.rolloutMu.Unlock()
+	r, ok := u.store.GetRollout(key)
+	if !ok || r.State != store.RolloutRunning || docker.ContainerRolloutGroup(labels) != r.Group {
+		return
+	}
+	if !slices.Contains(r.Updated, name) {
+		r.Updated = append(r.Updated, name)
+	}
+	var bake time.Duration
+	groups := u.rolloutGroups()
+	if gi := groupIndex(groups, r.Group); gi >= 0 {
+		bake = groups[gi].bakeTime()
+	}
+	r.BakeUntil = u.clock.Now().Add(bake)
+	u.saveRollout(&r)
+}
+
+// rolloutFailed halts a rollout after one of its members failed to update.
+// Failures that never attempted the update (locked, shutting down) are
+// ignored.
+func (u *Updater) rolloutFailed(ctx context.Context, key, name string, updateErr error) {
+	if key == "" || errors.Is(updateErr, ErrUpdateInProgress) || errors.Is(updateErr, ErrShuttingDown) || ctx.Err() != nil {
+		return
+	}
+	u.rolloutMu.Lock()
+	defer u.rolloutMu.Unlock()
+	r, ok := u.store.GetRollout(key)
+	if !ok || r.State == store.RolloutCompleted {
+		return
+	}
+	r.State, r.Reason = store.RolloutHalted, fmt.Sprintf("%s: %v", name, updateErr)
+	u.log.Warn("rollout halted", "rollout", key, "name", name, "error", updateErr)
+	u.saveRollout(&r)
+}
+
+// saveRollout stamps and persists a rollout.
+func (u *Updater) saveRollout(r *store.Rollout) {
+	r.UpdatedAt = u.clock.Now()
+	if err := u.store.SetRollout(*r); err != nil {
+		u.log.Warn("failed to persist rollout", "rollout", r.Key, "error", err)
+	}
+}
+
+// Rollouts returns every tracked rollout, newest first.
+func (u *Updater) Rollouts() []store.Rollout {
+	return u.store.ListRollouts()
+}
+
+// PauseRollout stops a rollout: no member updates until it is resumed or
+// promoted.
+func (u *Updater) PauseRollout(key string) (store.Rollout, error) {
+	return u.changeRollout(key, func(r *store.Rollout) {
+		r.State, r.Reason = store.RolloutPaused, "paused by operator"
+	})
+}
+
+// ResumeRollout restarts a paused or halted rollout in its current group.
+// Failures before the resume no longer hold it back.
+func (u *Updater) ResumeRollout(key string) (store.Rollout, error) {
+	return u.changeRollout(key, func(r *store.Rollout) {
+		r.State, r.Reason, r.ResumedAt = store.RolloutRunning, "", u.clock.Now()
+	})
+}
+
+// PromoteRollout lets the next group update now, skipping what is left of
+// the current group's bake time. It also resumes a paused or halted rollout.
+func (u *Updater) PromoteRollout(ctx context.Context, key string) (store.Rollout, error) {
+	containers, err := u.docker.ListContainers(ctx)
+	if err != nil {
+		return store.Rollout{}, fmt.Errorf("list containers: %w", err)
+	}
+	containers = u.dropUnmanaged(containers)
+	groups := u.rolloutGroups()
+	return u.changeRollout(key, func(r *store.Rollout) {
+		r.ResumedAt = u.clock.Now()
+		u.promoteRollout(r, groups, containers)
+	})
+}
+
+// changeRollout applies fn to a stored rollout that has not completed.
+func (u *Updater) changeRollout(key string, fn func(r *store.Rollout)) (store.Rollout, error) {
+	u.rolloutMu.Lock()
+	defer u.rolloutMu.Unlock()
+	r, ok := u.store.GetRollout(key)
+	if !ok {
+		return store.Rollout{}, ErrRolloutNotFound
+	}
+	if r.State == store.RolloutCompleted {
+		return r, ErrRolloutCompleted
+	}
+	fn(&r)
+	u.saveRollout(&r)
+	return r, nil
+}
@@ -0,0 +1,155 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+	"github.com/moby/moby/api/types/container"
+)
+
+func TestParseRolloutGroups(t *testing.T) {
+	groups, err := ParseRolloutGroups(`[{"name":" Canary ","bake":"6h"},{"name":"prod"}]`)
+	if err != nil {
+		t.Fatalf("ParseRolloutGroups: %v", err)
+	}
+	if len(groups) != 2 || groups[0].Name != "canary" || groups[0].bakeTime() != 6*time.Hour {
+		t.Errorf("groups = %+v, want canary (6h) then prod", groups)
+	}
+
+	for _, raw := range []string{
+		`not json`,
+		`[{"bake":"1h"}]`,
+		`[{"name":"a"},{"name":"A"}]`,
+		`[{"name":"a","bake":"soon"}]`,
+	} {
+		if _, err := ParseRolloutGroups(raw); err == nil {
+			t.Errorf("ParseRolloutGroups(%s) succeeded, want error", raw)
+		}
+	}
+}
+
+func rolloutContainers() []container.Summary {
+	member := func(name, group string) container.Summary {
+		return container.Summary{
+			ID: name, Names: []string{"/" + name}, Image: "nginx:1.27",
+			Labels: map[string]string{"sentinel.rollout-group": group},
+		}
+	}
+	return []container.Summary{member("web-canary", "canary"), member("web-1", "prod"), member("web-2", "prod")}
+}
+
+func TestRolloutGateStagesGroups(t *testing.T) {
+	mock := newMockDocker()
+	u, clk := newTestUpdater(t, mock)
+	u.SetSettingsReader(u.store)
+	_ = u.store.SaveSetting("rollout_groups", `[{"name":"canary","bake":"6h"},{"name":"prod"}]`)
+	containers := rolloutContainers()
+	canary, prod := containers[0].Labels, containers[1].Labels
+
+	// Prod waits for the canary, which may go first.
+	key, reason := u.rolloutGate(prod, "nginx:1.27", "1.28", containers)
+	if key == "" || !strings.Contains(reason, "canary") {
+		t.Fatalf("prod gate = %q, %q; want waiting for canary", key, reason)
+	}
+	if _, reason := u.rolloutGate(canary, "nginx:1.27", "1.28", containers); reason != "" {
+		t.Fatalf("canary gate blocked: %s", reason)
+	}
+	u.rolloutUpdated(key, "web-canary", canary)
+
+	// Still baking.
+	clk.Advance(5 * time.Hour)
+	if _, reason := u.rolloutGate(prod, "nginx:1.27", "1.28", containers); !strings.Contains(reason, "bake") {
+		t.Errorf("prod gate during bake = %q, want baking", reason)
+	}
+
+	// Baked without failures: prod is promoted and the rollout completes
+	// once prod has updated too.
+	clk.Advance(time.Hour)
+	if _, reason := u.rolloutGate(prod, "nginx:1.27", "1.28", containers); reason != "" {
+		t.Fatalf("prod gate after bake blocked: %s", reason)
+	}
+	u.rolloutUpdated(key, "web-1", prod)
+	if _, reason := u.rolloutGate(prod, "nginx:1.27", "1.28", containers); reason != "" {
+		t.Fatalf("second prod container blocked: %s", reason)
+	}
+	if r, _ := u.store.GetRollout(key); r.State != store.RolloutCompleted {
+		t.Errorf("state = %q, want completed", r.State)
+	}
+
+	// Containers outside the groups are never gated.
+	if key, reason := u.rolloutGate(map[string]string{}, "nginx:1.27", "1.28", containers); key != "" || reason != "" {
+		t.Errorf("ungrouped gate = %q, %q; want not gated", key, reason)
+	}
+}
+
+func TestRolloutHaltsOnFailure(t *testing.T) {
+	mock := newMockDocker()
+	mock.containers = rolloutContainers()
+	u, clk := newTestUpdater(t, mock)
+	u.SetSettingsReader(u.store)
+	_ = u.store.SaveSetting("rollout_groups", `[{"name":"canary","bake":"1h"},{"name":"prod"}]`)
+	containers := mock.containers
+	canary, prod := containers[0].Labels, containers[1].Labels
+	ctx := context.Background()
+
+	key, _ := u.rolloutGate(canary, "nginx:1.27", "1.28", containers)
+	u.rolloutUpdated(key, "web-canary", canary)
+	// A rollback during the bake time stops the rollout.
+	_ = u.store.RecordUpdate(store.UpdateRecord{Timestamp: clk.Now(), ContainerName: "web-canary", Outcome: "rollback"})
+	clk.Advance(2 * time.Hour)
+	if _, reason := u.rolloutGate(prod, "nginx:1.27", "1.28", containers); !strings.Contains(reason, "halted") {
+		t.Fatalf("prod gate = %q, want halted", reason)
+	}
+
+	// Promoting overrides the halt and lets prod update.
+	r, err := u.PromoteRollout(ctx, key)
+	if err != nil {
+		t.Fatalf("PromoteRollout: %v", err)
+	}
+	if r.Group != "prod" || r.State != store.RolloutRunning {
+		t.Errorf("after promote: %+v, want prod running", r)
+	}
+	if _, reason := u.rolloutGate(prod, "nginx:1.27", "1.28", containers); reason != "" {
+		t.Errorf("prod gate after promote blocked: %s", reason)
+	}
+
+	// Pause blocks every group; a failed update halts again after resume.
+	if _, err := u.PauseRollout(key); err != nil {
+		t.Fatalf("PauseRollout: %v", err)
+	}
+	if _, reason := u.rolloutGate(prod, "nginx:1.27", "1.28", containers); reason != "rollout paused" {
+		t.Errorf("gate while paused = %q", reason)
+	}
+	if _, err := u.ResumeRollout(key); err != nil {
+		t.Fatalf("ResumeRollout: %v", err)
+	}
+	u.rolloutFailed(ctx, key, "web-1", errors.New("pull failed"))
+	if r, _ := u.store.GetRollout(key); r.State != store.RolloutHalted || !strings.Contains(r.Reason, "web-1") {
+		t.Errorf("after failure: %+v, want halted by web-1", r)
+	}
+
+	if _, err := u.PauseRollout("missing"); !errors.Is(err, ErrRolloutNotFound) {
+		t.Errorf("PauseRollout(missing) error = %v, want ErrRolloutNotFound", err)
+	}
+}
+
+func TestRolloutNewTargetSupersedes(t *testing.T) {
+	mock := newMockDocker()
+	u, _ := newTestUpdater(t, mock)
+	u.SetSettingsReader(u.store)
+	_ = u.store.SaveSetting("rollout_groups", `[{"name":"canary"},{"name":"prod"}]`)
+	containers := rolloutContainers()
+
+	old, _ := u.rolloutGate(containers[0].Labels, "nginx:1.27", "1.28", containers)
+	next, _ := u.rolloutGate(containers[0].Labels, "nginx:1.27", "1.29", containers)
+	if _, ok := u.store.GetRollout(old); ok {
+		t.Error("rollout to 1.28 should be removed once 1.29 starts")
+	}
+	if list := u.Rollouts(); len(list) != 1 || list[0].Key != next {
+		t.Errorf("rollouts = %+v, want only %s", list, next)
+	}
+}
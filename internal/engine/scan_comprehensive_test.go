@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
 	"github.com/moby/moby/api/types/container"
 )
 
@@ -357,3 +358,31 @@ func TestScanRemovesStaleQueue(t *testing.T) {
 		t.Errorf("after second scan: queue.Len() = %d, want 0 (stale entry removed)", u.queue.Len())
 	}
 }
+
+func TestScanSkipsUnmanaged(t *testing.T) {
+	mock := newMockDocker()
+	mock.containers = []container.Summary{
+		{ID: "aaa", Names: []string{"/managed"}, Image: "fake.local/app:1.0"},
+		{ID: "bbb", Names: []string{"/ignored"}, Image: "fake.local/other:1.0"},
+	}
+	mock.imageDigests["fake.local/app:1.0"] = "fake.local/app@sha256:old"
+	mock.distDigests["fake.local/app:1.0"] = "sha256:new"
+	mock.imageDigests["fake.local/other:1.0"] = "fake.local/other@sha256:old"
+	mock.distDigests["fake.local/other:1.0"] = "sha256:new"
+
+	u, _ := newTestUpdater(t, mock)
+	if err := u.store.SetUnmanaged("ignored", store.UnmanagedEntry{}); err != nil {
+		t.Fatal(err)
+	}
+	res := u.Scan(context.Background(), ScanScheduled)
+
+	if res.Total != 1 {
+		t.Errorf("Total = %d, want 1", res.Total)
+	}
+	if _, ok := u.queue.Get("ignored"); ok {
+		t.Error("unmanaged container should not be queued")
+	}
+	if _, ok := u.queue.Get("managed"); !ok {
+		t.Error("managed container should be queued")
+	}
+}
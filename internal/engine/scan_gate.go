@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+	"github.com/Will-Luck/Docker-Sentinel/internal/registry"
+	"github.com/Will-Luck/Docker-Sentinel/internal/scanner"
+	"github.com/moby/moby/api/types/container"
+)
+
+// ErrScanBlocked is returned by UpdateContainer when the pre-update scan
+// finds vulnerabilities at or above the severity threshold. The update is
+// put in the approval queue with the scan report attached instead.
+var ErrScanBlocked = errors.New("update blocked by vulnerability scan")
+
+// maxQueuedVulns caps the findings kept on a queue entry so one badly
+// affected image cannot bloat the persisted queue.
+const maxQueuedVulns = 50
+
+// scanApprovalKey marks a context as carrying an operator's approval of an
+// update whose scan report they have seen, so the scan gate lets it through.
+type scanApprovalKey struct{}
+
+// WithScanApproval returns a context that lets UpdateContainer proceed past
+// the pre-update scan gate. Use it only when approving a queue entry that
+// carries a scan report.
+func WithScanApproval(ctx context.Context) context.Context {
+	return context.WithValue(ctx, scanApprovalKey{}, true)
+}
+
+func scanApproved(ctx context.Context) bool {
+	v, _ := ctx.Value(scanApprovalKey{}).(bool)
+	return v
+}
+
+// queueScanBlocked puts a scan-blocked update in the approval queue with the
+// findings at or above the threshold attached. An existing entry keeps its
+// digests and versions; the scan report replaces any earlier one.
+func (u *Updater) queueScanBlocked(id, name, targetImage string, inspect container.InspectResponse, result *scanner.ScanResult) {
+	update, ok := u.queue.Get(name)
+	if !ok {
+		update = PendingUpdate{
+			ContainerID:   id,
+			ContainerName: name,
+			CurrentImage:  inspect.Config.Image,
+			CurrentDigest: extractDigestForRecord(inspect),
+			DetectedAt:    u.clock.Now(),
+		}
+		update.ResolvedCurrentVersion, update.ResolvedTargetVersion = u.updateVersions(name, targetImage)
+		if tag := registry.ExtractTag(targetImage); targetImage != "" && tag != "" {
+			update.NewerVersions = []string{tag}
+		}
+	}
+	update.ScanReport = result.AtOrAbove(u.severityThresh, maxQueuedVulns)
+	update.ScanThreshold = u.severityThresh
+	u.queue.Add(update)
+	u.publishEvent(events.EventQueueChange, name, "queued for approval: vulnerability scan")
+}
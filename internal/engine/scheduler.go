@@ -19,6 +19,13 @@ type SettingsReader interface {
 	LoadSetting(key string) (string, error)
 }
 
+// RunStore persists last-run times so schedules missed while Sentinel was
+// down can be caught up on the next start.
+type RunStore interface {
+	LoadSetting(key string) (string, error)
+	SaveSetting(key, value string) error
+}
+
 // Scheduler runs scan cycles at the configured poll interval.
 type Scheduler struct {
 	updater      *Updater
@@ -26,6 +33,7 @@ type Scheduler struct {
 	log          *logging.Logger
 	clock        clock.Clock
 	settings     SettingsReader
+	runs         RunStore     // optional: persists last scan time for catch-up
 	selfUpdater  *SelfUpdater // optional: for auto self-update when idle
 	resetCh      chan struct{}
 	mu           sync.Mutex
@@ -51,6 +59,12 @@ func (s *Scheduler) SetSettingsReader(sr SettingsReader) {
 	s.settings = sr
 }
 
+// SetRunStore attaches persistence for the last scan time. Without it every
+// start runs an initial scan, as if the schedule had never fired.
+func (s *Scheduler) SetRunStore(rs RunStore) {
+	s.runs = rs
+}
+
 // SetScanCallback registers a function called after each scan completes.
 func (s *Scheduler) SetScanCallback(fn func()) {
 	s.scanCallback = fn
@@ -81,15 +95,19 @@ func (s *Scheduler) Run(ctx context.Context) error {
 		}
 	}
 
-	if !s.isPaused() {
-		s.log.Info("starting initial scan")
+	if s.isPaused() {
+		s.log.Info("scheduler is paused, skipping initial scan")
+	} else if run, catchUp := s.startupScan(); !run {
+		s.log.Info("catch-up disabled, waiting for next scheduled scan")
+	} else {
+		if catchUp {
+			s.log.Info("starting catch-up scan, schedule was missed while down", "last_scan", s.persistedLastScan())
+		} else {
+			s.log.Info("starting initial scan")
+		}
 		result := s.updater.Scan(ctx, ScanScheduled)
-		s.mu.Lock()
-		s.lastScan = s.clock.Now()
-		s.mu.Unlock()
+		s.markScanned()
 		s.logResult(result)
-	} else {
-		s.log.Info("scheduler is paused, skipping initial scan")
 	}
 
 	for {
@@ -101,9 +119,7 @@ func (s *Scheduler) Run(ctx context.Context) error {
 			}
 			s.log.Info("starting scheduled scan")
 			result := s.updater.Scan(ctx, ScanScheduled)
-			s.mu.Lock()
-			s.lastScan = s.clock.Now()
-			s.mu.Unlock()
+			s.markScanned()
 			s.logResult(result)
 		case <-s.resetCh:
 			s.log.Info("poll interval changed, resetting timer", "interval", s.cfg.PollInterval())
@@ -207,9 +223,7 @@ func (s *Scheduler) SetPollInterval(d time.Duration) {
 func (s *Scheduler) TriggerScan(ctx context.Context) {
 	s.log.Info("starting manual scan")
 	result := s.updater.Scan(ctx, ScanManual)
-	s.mu.Lock()
-	s.lastScan = s.clock.Now()
-	s.mu.Unlock()
+	s.markScanned()
 	s.logResult(result)
 }
 
@@ -220,6 +234,57 @@ func (s *Scheduler) LastScanTime() time.Time {
 	return s.lastScan
 }
 
+// markScanned records a completed scan in memory and, when a run store is
+// attached, persistently for catch-up after a restart.
+func (s *Scheduler) markScanned() {
+	now := s.clock.Now()
+	s.mu.Lock()
+	s.lastScan = now
+	s.mu.Unlock()
+	if s.runs != nil {
+		if err := s.runs.SaveSetting("last_scan_at", now.UTC().Format(time.RFC3339)); err != nil {
+			s.log.Debug("failed to persist last scan time", "error", err)
+		}
+	}
+}
+
+// persistedLastScan returns the last scan time saved by a previous run, or
+// the zero time when unknown.
+func (s *Scheduler) persistedLastScan() time.Time {
+	if s.runs == nil {
+		return time.Time{}
+	}
+	val, err := s.runs.LoadSetting("last_scan_at")
+	if err != nil || val == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339, val)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// startupScan decides whether Run scans immediately on start. A first start
+// (no recorded scan) always scans. Otherwise, with catch-up enabled the
+// initial scan runs as before and is a catch-up when a scheduled occurrence
+// passed while Sentinel was down; with catch-up disabled the scheduler waits
+// for the next occurrence.
+func (s *Scheduler) startupScan() (run, catchUp bool) {
+	last := s.persistedLastScan()
+	if last.IsZero() {
+		return true, false
+	}
+	if !s.cfg.CatchUp {
+		return false, false
+	}
+	now := s.clock.Now()
+	if sched, ok := s.cronSchedule(); ok {
+		return true, !sched.Next(last).After(now)
+	}
+	return true, now.Sub(last) >= s.cfg.PollInterval()
+}
+
 // isPaused checks whether the scheduler is paused via a runtime setting.
 func (s *Scheduler) isPaused() bool {
 	if s.settings == nil {
@@ -237,25 +302,35 @@ func (s *Scheduler) isPaused() bool {
 // If a cron schedule is configured, it computes the next fire time from the expression.
 // Otherwise, it falls back to the poll interval.
 func (s *Scheduler) nextTick() <-chan time.Time {
-	if sched := s.cfg.Schedule(); sched != "" {
-		parser := cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
-		schedule, err := parser.Parse(sched)
-		if err != nil {
-			s.log.Warn("invalid cron schedule, falling back to poll interval", "schedule", sched, "error", err)
-			return s.clock.After(s.cfg.PollInterval())
-		}
+	if schedule, ok := s.cronSchedule(); ok {
 		now := s.clock.Now()
 		next := schedule.Next(now)
 		wait := next.Sub(now)
 		if wait < 0 {
 			wait = 0
 		}
-		s.log.Debug("next cron tick", "schedule", sched, "next", next, "wait", wait)
+		s.log.Debug("next cron tick", "schedule", s.cfg.Schedule(), "next", next, "wait", wait)
 		return s.clock.After(wait)
 	}
 	return s.clock.After(s.cfg.PollInterval())
 }
 
+// cronSchedule parses the configured cron schedule. It returns false when no
+// schedule is set or it is invalid, in which case the poll interval applies.
+func (s *Scheduler) cronSchedule() (cron.Schedule, bool) {
+	sched := s.cfg.Schedule()
+	if sched == "" {
+		return nil, false
+	}
+	parser := cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+	schedule, err := parser.Parse(sched)
+	if err != nil {
+		s.log.Warn("invalid cron schedule, falling back to poll interval", "schedule", sched, "error", err)
+		return nil, false
+	}
+	return schedule, true
+}
+
 // SetSchedule updates the cron schedule at runtime and signals the scheduler to reset.
 func (s *Scheduler) SetSchedule(sched string) {
 	s.cfg.SetSchedule(sched)
@@ -362,3 +362,64 @@ func TestMaybeSelfUpdate_ConcurrentGuard(t *testing.T) {
 		t.Errorf("queue.Len() = %d, want 1 (concurrent guard should prevent update)", q.Len())
 	}
 }
+
+// testRunStore is an in-memory RunStore for tests.
+type testRunStore struct {
+	data map[string]string
+}
+
+func (rs *testRunStore) LoadSetting(key string) (string, error) { return rs.data[key], nil }
+func (rs *testRunStore) SaveSetting(key, value string) error {
+	rs.data[key] = value
+	return nil
+}
+
+func TestSchedulerStartupScan(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		lastScan    time.Time
+		schedule    string
+		catchUp     bool
+		wantRun     bool
+		wantCatchUp bool
+	}{
+		{name: "first start", wantRun: true},
+		{name: "interval missed", lastScan: now.Add(-7 * time.Hour), catchUp: true, wantRun: true, wantCatchUp: true},
+		{name: "interval not missed", lastScan: now.Add(-1 * time.Hour), catchUp: true, wantRun: true},
+		{name: "cron missed", lastScan: now.Add(-30 * time.Hour), schedule: "0 3 * * *", catchUp: true, wantRun: true, wantCatchUp: true},
+		{name: "cron not missed", lastScan: now.Add(-2 * time.Hour), schedule: "0 3 * * *", catchUp: true, wantRun: true},
+		{name: "catch-up disabled", lastScan: now.Add(-30 * time.Hour), schedule: "0 3 * * *"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.NewTestConfig()
+			cfg.SetPollInterval(6 * time.Hour)
+			cfg.SetSchedule(tt.schedule)
+			cfg.CatchUp = tt.catchUp
+			sched := NewScheduler(nil, cfg, logging.New(false), newMockClock(now))
+			rs := &testRunStore{data: map[string]string{}}
+			if !tt.lastScan.IsZero() {
+				rs.data["last_scan_at"] = tt.lastScan.Format(time.RFC3339)
+			}
+			sched.SetRunStore(rs)
+
+			run, catchUp := sched.startupScan()
+			if run != tt.wantRun || catchUp != tt.wantCatchUp {
+				t.Errorf("startupScan() = (%v, %v), want (%v, %v)", run, catchUp, tt.wantRun, tt.wantCatchUp)
+			}
+		})
+	}
+}
+
+func TestMarkScannedPersists(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	sched := NewScheduler(nil, config.NewTestConfig(), logging.New(false), newMockClock(now))
+	rs := &testRunStore{data: map[string]string{}}
+	sched.SetRunStore(rs)
+
+	sched.markScanned()
+	if got := sched.persistedLastScan(); !got.Equal(now) {
+		t.Errorf("persistedLastScan() = %v, want %v", got, now)
+	}
+}
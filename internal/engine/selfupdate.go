@@ -7,6 +7,7 @@ import (
 
 	"github.com/Will-Luck/Docker-Sentinel/internal/docker"
 	"github.com/Will-Luck/Docker-Sentinel/internal/logging"
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
 	"github.com/moby/moby/api/types/network"
 )
 
@@ -15,8 +16,17 @@ import (
 // original name, and starts it. The old container (this process) exits
 // naturally after the new one is running.
 type SelfUpdater struct {
-	docker docker.API
-	log    *logging.Logger
+	docker  docker.API
+	log     *logging.Logger
+	markers RestartMarkerStore
+	version string
+}
+
+// RestartMarkerStore persists the marker that tells the replacement instance
+// which version it was updated from. Implemented by *store.Store.
+type RestartMarkerStore interface {
+	SetRestartMarker(m store.RestartMarker) error
+	ClearRestartMarker() error
 }
 
 // NewSelfUpdater creates a SelfUpdater.
@@ -24,6 +34,23 @@ func NewSelfUpdater(d docker.API, log *logging.Logger) *SelfUpdater {
 	return &SelfUpdater{docker: d, log: log}
 }
 
+// SetRestartMarker makes Update leave a restart marker recording version
+// (the running build) before handing over to the replacement.
+func (su *SelfUpdater) SetRestartMarker(ms RestartMarkerStore, version string) {
+	su.markers = ms
+	su.version = version
+}
+
+// clearMarker drops the restart marker after a rolled-back self-update.
+func (su *SelfUpdater) clearMarker() {
+	if su.markers == nil {
+		return
+	}
+	if err := su.markers.ClearRestartMarker(); err != nil {
+		su.log.Warn("failed to clear restart marker", "error", err)
+	}
+}
+
 // Update performs a self-update using rename-before-replace.
 // It pulls the new image, renames the current container out of the way,
 // creates a new container with the original name and config, connects
@@ -73,10 +100,22 @@ func (su *SelfUpdater) Update(ctx context.Context, targetImage string) error {
 		return fmt.Errorf("pull image: %w", err)
 	}
 
+	// 3b. Leave a marker so the replacement can report the version change.
+	if su.markers != nil {
+		if err := su.markers.SetRestartMarker(store.RestartMarker{
+			FromVersion: su.version,
+			TargetImage: imageRef,
+			StartedAt:   time.Now().UTC(),
+		}); err != nil {
+			su.log.Warn("failed to write restart marker", "error", err)
+		}
+	}
+
 	// 4. Rename self out of the way.
 	oldName := fmt.Sprintf("%s-old-%d", selfName, time.Now().Unix())
 	su.log.Info("renaming self", "from", selfName, "to", oldName)
 	if err := su.docker.RenameContainer(ctx, selfID, oldName); err != nil {
+		su.clearMarker()
 		return fmt.Errorf("rename self: %w", err)
 	}
 
@@ -117,6 +156,7 @@ func (su *SelfUpdater) Update(ctx context.Context, targetImage string) error {
 		// Rollback: rename back to original name.
 		su.log.Error("create failed, rolling back rename", "error", err)
 		_ = su.docker.RenameContainer(ctx, selfID, selfName)
+		su.clearMarker()
 		return fmt.Errorf("create replacement: %w", err)
 	}
 
@@ -135,6 +175,7 @@ func (su *SelfUpdater) Update(ctx context.Context, targetImage string) error {
 		su.log.Error("start failed, rolling back", "error", err)
 		_ = su.docker.RemoveContainer(ctx, newID)
 		_ = su.docker.RenameContainer(ctx, selfID, selfName)
+		su.clearMarker()
 		return fmt.Errorf("start replacement: %w", err)
 	}
 
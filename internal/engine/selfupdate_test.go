@@ -205,3 +205,32 @@ func TestSelfUpdateMultiNetwork(t *testing.T) {
 		t.Fatalf("expected 1 NetworkConnect call for second network, got %d", len(mock.networkConnectCalls))
 	}
 }
+
+func TestSelfUpdateRestartMarker(t *testing.T) {
+	mock := newMockDocker()
+	mock.containers = []container.Summary{sentinelContainer("abc123", "sentinel", "img:2.9.1")}
+	mock.inspectResults["abc123"] = sentinelInspect("img:2.9.1")
+	s := testStore(t)
+
+	su := newTestSelfUpdater(mock)
+	su.SetRestartMarker(s, "2.9.1")
+	if err := su.Update(context.Background(), "img:2.10.2"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m, ok, err := s.TakeRestartMarker()
+	if err != nil || !ok {
+		t.Fatalf("TakeRestartMarker = %v, %v; want marker", ok, err)
+	}
+	if m.FromVersion != "2.9.1" || m.TargetImage != "img:2.10.2" {
+		t.Errorf("marker = %+v", m)
+	}
+
+	// A rolled-back self-update leaves no marker behind.
+	mock.createErr["sentinel"] = fmt.Errorf("name conflict")
+	if err := su.Update(context.Background(), "img:2.10.2"); err == nil {
+		t.Fatal("expected error when create fails")
+	}
+	if _, ok, _ := s.TakeRestartMarker(); ok {
+		t.Error("marker left after rolled-back self-update")
+	}
+}
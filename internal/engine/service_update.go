@@ -8,17 +8,17 @@ import (
 
 	"github.com/Will-Luck/Docker-Sentinel/internal/docker"
 	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+	"github.com/Will-Luck/Docker-Sentinel/internal/lifecycle"
 	"github.com/Will-Luck/Docker-Sentinel/internal/notify"
 	"github.com/Will-Luck/Docker-Sentinel/internal/registry"
 	"github.com/Will-Luck/Docker-Sentinel/internal/store"
 	"github.com/moby/moby/api/types/swarm"
-	"github.com/robfig/cron/v3"
 )
 
 // scanServices checks pre-fetched Swarm services for image updates,
 // routing them through the same policy/queue/notification flow as containers.
 // The services list is fetched once in Scan() to avoid duplicate API calls.
-func (u *Updater) scanServices(ctx context.Context, services []swarm.Service, mode ScanMode, result *ScanResult, filters []string, reserve int) {
+func (u *Updater) scanServices(ctx context.Context, services []swarm.Service, mode ScanMode, result *ScanResult, filters ScanFilters, reserve int) {
 	u.log.Debug("scanning swarm services", "count", len(services))
 	result.Services = len(services)
 
@@ -57,24 +57,15 @@ func (u *Updater) scanServices(ctx context.Context, services []swarm.Service, mo
 			continue
 		}
 
-		if MatchesFilter(name, filters) {
-			u.log.Debug("skipping filtered service", "name", name)
+		if excluded, rule := filters.Excluded(name, imageRef, labels); excluded {
+			u.log.Debug("skipping filtered service", "name", name, "filter", rule)
 			continue
 		}
 
 		// Per-container schedule check.
-		if sched := docker.ContainerSchedule(labels); sched != "" {
-			parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
-			schedule, err := parser.Parse(sched)
-			if err != nil {
-				u.log.Warn("invalid schedule", "name", name, "schedule", sched, "error", err)
-			} else {
-				lastChecked, _ := u.store.GetLastContainerScan(name)
-				if !lastChecked.IsZero() && u.clock.Now().Before(schedule.Next(lastChecked)) {
-					result.Skipped++
-					continue
-				}
-			}
+		if !u.scheduleDue(name, labels) {
+			result.Skipped++
+			continue
 		}
 
 		// Rate limit check — skip this registry but keep scanning
@@ -278,6 +269,21 @@ func (u *Updater) scanServices(ctx context.Context, services []swarm.Service, mo
 				result.Updated++
 				continue
 			}
+			if !u.claimAutoSlot(result) {
+				u.overflowAutoUpdate(result, name, PendingUpdate{
+					ContainerID:            svc.ID,
+					ContainerName:          name,
+					CurrentImage:           imageRef,
+					CurrentDigest:          check.LocalDigest,
+					RemoteDigest:           check.RemoteDigest,
+					NewerVersions:          check.NewerVersions,
+					Candidates:             check.Candidates,
+					ResolvedCurrentVersion: check.ResolvedCurrentVersion,
+					ResolvedTargetVersion:  check.ResolvedTargetVersion,
+					Type:                   "service",
+				})
+				continue
+			}
 			if err := u.UpdateService(ctx, svc.ID, name, scanTarget); err != nil {
 				u.log.Error("auto service update failed", "name", name, "error", err)
 				result.Failed++
@@ -296,6 +302,7 @@ func (u *Updater) scanServices(ctx context.Context, services []swarm.Service, mo
 				RemoteDigest:           check.RemoteDigest,
 				DetectedAt:             u.clock.Now(),
 				NewerVersions:          check.NewerVersions,
+				Candidates:             check.Candidates,
 				ResolvedCurrentVersion: check.ResolvedCurrentVersion,
 				ResolvedTargetVersion:  check.ResolvedTargetVersion,
 				Type:                   "service",
@@ -310,11 +317,23 @@ func (u *Updater) scanServices(ctx context.Context, services []swarm.Service, mo
 // UpdateService performs a rolling update on a Swarm service. It modifies the
 // service spec to use the new image, then polls UpdateStatus until the rollout
 // completes, pauses, or times out.
-func (u *Updater) UpdateService(ctx context.Context, serviceID, name, targetImage string) error {
-	if !u.tryLock(name) {
+func (u *Updater) UpdateService(ctx context.Context, serviceID, name, targetImage string) (err error) {
+	if u.draining.Load() {
+		return ErrShuttingDown
+	}
+	ctx, lock, ok := u.lockUpdate(ctx, name)
+	if !ok {
 		return ErrUpdateInProgress
 	}
-	defer u.unlock(name)
+	defer u.releaseUpdate(lock)
+
+	// Called directly for queued services; the swarm-service strategy
+	// already runs inside UpdateContainer's lifecycle.
+	if lifecycleFrom(ctx) == nil {
+		run := u.startLifecycle(name, "", targetImage)
+		ctx = withLifecycle(ctx, run)
+		defer func() { run.finish(err) }()
+	}
 
 	start := u.clock.Now()
 
@@ -355,6 +374,7 @@ func (u *Updater) UpdateService(ctx context.Context, serviceID, name, targetImag
 	}
 
 	// Poll for rollout completion.
+	u.setUpdatePhase(ctx, "rollout")
 	outcome, pollErr := u.pollServiceUpdate(ctx, serviceID, name)
 
 	duration := u.clock.Since(start)
@@ -367,6 +387,7 @@ func (u *Updater) UpdateService(ctx context.Context, serviceID, name, targetImag
 		Outcome:       outcome,
 		Duration:      duration,
 		Type:          "service",
+		Comment:       approvalComment(ctx),
 	}
 	if pollErr != nil {
 		record.Error = pollErr.Error()
@@ -413,6 +434,7 @@ func (u *Updater) UpdateService(ctx context.Context, serviceID, name, targetImag
 			Timestamp:     u.clock.Now(),
 		})
 		u.publishEvent(events.EventServiceUpdate, name, "service rolled back by Swarm")
+		lifecycleFrom(ctx).emit(lifecycle.StateRolledBack, "rolled back by Swarm")
 
 	default: // "failed", "timeout"
 		errMsg := outcome
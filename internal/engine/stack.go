@@ -0,0 +1,258 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/compose"
+	"github.com/Will-Luck/Docker-Sentinel/internal/deps"
+	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+	"github.com/Will-Luck/Docker-Sentinel/internal/notify"
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+	"github.com/moby/moby/api/types/container"
+)
+
+// ErrStackNotFound is returned when no running container belongs to the
+// requested Compose project.
+var ErrStackNotFound = errors.New("no running containers in stack")
+
+// StackResult reports what a stack update did to each member.
+type StackResult struct {
+	Project    string   `json:"project"`
+	Order      []string `json:"order"`                 // update order, dependencies first
+	Updated    []string `json:"updated,omitempty"`     // recreated on a new image
+	Unchanged  []string `json:"unchanged,omitempty"`   // pull resolved to the running image
+	RolledBack []string `json:"rolled_back,omitempty"` // restored after another member failed
+	Failed     string   `json:"failed,omitempty"`      // member whose pull or update failed
+}
+
+// StackUpdater updates every container of a Compose project as one unit:
+// all images are pulled before any container is touched, members are
+// recreated in dependency order, and if one fails the members already
+// updated are rolled back to their snapshots.
+type StackUpdater struct {
+	u *Updater
+}
+
+// NewStackUpdater creates a stack updater backed by u.
+func NewStackUpdater(u *Updater) *StackUpdater {
+	return &StackUpdater{u: u}
+}
+
+// stackMember is one container of a stack, resolved before the update.
+type stackMember struct {
+	target    UpdateTarget
+	pullImage string
+}
+
+// Update updates all running containers of the Compose project. Members
+// with a queued semver bump move to that version; the rest re-pull their
+// current tag. Returns ErrStackNotFound, ErrShuttingDown, or
+// ErrUpdateInProgress when a member is already being updated.
+func (s *StackUpdater) Update(ctx context.Context, project string) (StackResult, error) {
+	u := s.u
+	res := StackResult{Project: project}
+	if u.draining.Load() {
+		return res, ErrShuttingDown
+	}
+
+	members, err := s.members(ctx, project)
+	if err != nil {
+		return res, err
+	}
+	for _, m := range members {
+		res.Order = append(res.Order, m.target.Name)
+	}
+
+	locks := make(map[string]*updateLock, len(members))
+	defer func() {
+		for _, lock := range locks {
+			u.releaseUpdate(lock)
+		}
+	}()
+	for _, m := range members {
+		lockCtx, lock, ok := u.lockUpdate(ctx, m.target.Name)
+		if !ok {
+			return res, fmt.Errorf("%s: %w", m.target.Name, ErrUpdateInProgress)
+		}
+		ctx = lockCtx
+		locks[m.target.Name] = lock
+	}
+
+	for _, m := range members {
+		strategy := u.strategyFor(m.target.Name, m.target.Inspect.Config.Labels).Name()
+		if err := u.checkCapabilities(ctx, m.target.Name, strategy); err != nil {
+			res.Failed = m.target.Name
+			return res, err
+		}
+	}
+
+	u.log.Info("stack update started", "project", project, "order", strings.Join(res.Order, ","))
+
+	// 1. Pull every image before touching any container, so a registry
+	// failure leaves the whole stack as it was.
+	var changed []stackMember
+	for _, m := range members {
+		locks[m.target.Name].setPhase("pull", u.clock.Now())
+		if err := u.pullImage(ctx, m.target.Name, m.pullImage, m.target.Inspect.Config.Labels); err != nil {
+			res.Failed = m.target.Name
+			u.log.Error("stack pull failed, nothing changed", "project", project, "name", m.target.Name, "error", err)
+			return res, fmt.Errorf("pull %s for %s: %w", m.pullImage, m.target.Name, err)
+		}
+		locks[m.target.Name].setPhase("waiting for stack", u.clock.Now())
+		if id, err := u.docker.ImageID(ctx, m.pullImage); err == nil && id == m.target.Inspect.Image {
+			res.Unchanged = append(res.Unchanged, m.target.Name)
+			continue
+		}
+		changed = append(changed, m)
+	}
+
+	// 2. Recreate in dependency order. Each member's own strategy handles
+	// validation and rolls that member back if it fails.
+	var done []stackMember
+	for _, m := range changed {
+		run := u.startLifecycle(m.target.Name, m.target.Inspect.Config.Image, m.target.TargetImage)
+		memberCtx := withUpdateLock(withLifecycle(ctx, run), locks[m.target.Name])
+		err := u.strategyFor(m.target.Name, m.target.Inspect.Config.Labels).Update(memberCtx, m.target)
+		run.finish(err)
+		if err != nil {
+			res.Failed = m.target.Name
+			res.RolledBack = s.rollbackMembers(context.WithoutCancel(ctx), project, m.target.Name, done)
+			return res, fmt.Errorf("update %s: %w", m.target.Name, err)
+		}
+		done = append(done, m)
+		res.Updated = append(res.Updated, m.target.Name)
+	}
+
+	u.log.Info("stack update complete", "project", project, "updated", len(res.Updated), "unchanged", len(res.Unchanged))
+	return res, nil
+}
+
+// members lists the running containers of a project in dependency order.
+func (s *StackUpdater) members(ctx context.Context, project string) ([]stackMember, error) {
+	u := s.u
+	containers, err := u.docker.ListContainers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list containers: %w", err)
+	}
+
+	byName := make(map[string]stackMember)
+	services := make(map[string]string) // compose service → container name
+	for _, c := range containers {
+		if compose.ProjectName(c.Labels) != project || isSentinel(c.Labels) {
+			continue
+		}
+		name := containerName(c)
+		inspect, err := u.docker.InspectContainer(ctx, c.ID)
+		if err != nil {
+			return nil, fmt.Errorf("inspect %s: %w", name, err)
+		}
+		if inspect.Config == nil {
+			return nil, fmt.Errorf("inspect %s: container config is nil", name)
+		}
+		m := stackMember{
+			target:    UpdateTarget{ID: c.ID, Name: name, Inspect: inspect},
+			pullImage: inspect.Config.Image,
+		}
+		if pending, ok := u.queue.Get(name); ok && len(pending.NewerVersions) > 0 {
+			m.target.TargetImage = replaceTag(inspect.Config.Image, pending.NewerVersions[0])
+			m.pullImage = m.target.TargetImage
+		}
+		byName[name] = m
+		if svc := compose.ServiceName(c.Labels); svc != "" {
+			services[svc] = name
+		}
+	}
+	if len(byName) == 0 {
+		return nil, ErrStackNotFound
+	}
+
+	order, err := stackOrder(byName, services)
+	if err != nil {
+		return nil, err
+	}
+	members := make([]stackMember, len(order))
+	for i, name := range order {
+		members[i] = byName[name]
+	}
+	return members, nil
+}
+
+// stackOrder sorts stack members so dependencies come first. Compose
+// depends_on refers to services, so those are mapped to container names.
+func stackOrder(byName map[string]stackMember, services map[string]string) ([]string, error) {
+	infos := make([]deps.ContainerInfo, 0, len(byName))
+	for name, m := range byName {
+		var dependsOn []string
+		for _, dep := range deps.ParseDependsOn(m.target.Inspect.Config.Labels) {
+			if c, ok := services[dep]; ok {
+				dependsOn = append(dependsOn, c)
+			} else if _, ok := byName[dep]; ok {
+				dependsOn = append(dependsOn, dep)
+			}
+		}
+		info := deps.ContainerInfo{
+			Name:   name,
+			Labels: map[string]string{"sentinel.depends-on": strings.Join(dependsOn, ",")},
+		}
+		if m.target.Inspect.HostConfig != nil {
+			info.NetworkMode = string(m.target.Inspect.HostConfig.NetworkMode)
+		}
+		infos = append(infos, info)
+	}
+	return deps.Build(infos).Sort()
+}
+
+// rollbackMembers restores members that were already updated, newest first,
+// after failed could not be updated. Returns the names restored.
+func (s *StackUpdater) rollbackMembers(ctx context.Context, project, failed string, done []stackMember) []string {
+	u := s.u
+	var restored []string
+	for i := len(done) - 1; i >= 0; i-- {
+		m := done[i]
+		name := m.target.Name
+		reason := fmt.Sprintf("stack %s rolled back: %s failed", project, failed)
+		outcome := "rollback"
+		if err := rollback(ctx, u.docker, name, snapshotOf(m.target.Inspect), u.log); err != nil {
+			u.log.Error("stack rollback failed", "project", project, "name", name, "error", err)
+			u.publishEvent(events.EventContainerUpdate, name, "rollback failed")
+			u.notifier.Notify(ctx, notify.Event{
+				Type:          notify.EventRollbackFailed,
+				ContainerName: name,
+				Error:         err.Error(),
+				Timestamp:     u.clock.Now(),
+			})
+			outcome = "failed"
+			reason += "; rollback failed: " + err.Error()
+		} else {
+			restored = append(restored, name)
+			u.publishEvent(events.EventContainerUpdate, name, "rollback succeeded")
+			u.notifyAttempt(ctx, notify.Event{
+				Type:          notify.EventRollbackOK,
+				ContainerName: name,
+				Error:         reason,
+				Timestamp:     u.clock.Now(),
+			})
+		}
+		if err := u.store.RecordUpdate(store.UpdateRecord{
+			Timestamp:     u.clock.Now(),
+			ContainerName: name,
+			OldImage:      m.pullImage,
+			NewImage:      m.target.Inspect.Config.Image,
+			Outcome:       outcome,
+			Error:         reason,
+		}); err != nil {
+			u.log.Warn("failed to persist stack rollback record", "name", name, "error", err)
+		}
+	}
+	return restored
+}
+
+// snapshotOf encodes an inspect result the way recreate stores snapshots.
+func snapshotOf(inspect container.InspectResponse) []byte {
+	data, _ := json.Marshal(inspect)
+	return data
+}
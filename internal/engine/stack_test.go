@@ -0,0 +1,129 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/moby/moby/api/types/container"
+)
+
+// stackMock sets up a two-service Compose project where app depends on db.
+func stackMock() *mockDocker {
+	mock := newMockDocker()
+	for _, svc := range []struct{ id, name, service, dependsOn string }{
+		{"app-id", "shop-app-1", "app", "db:service_started:false"},
+		{"db-id", "shop-db-1", "db", ""},
+	} {
+		labels := map[string]string{
+			"com.docker.compose.project": "shop",
+			"com.docker.compose.service": svc.service,
+		}
+		if svc.dependsOn != "" {
+			labels["com.docker.compose.depends_on"] = svc.dependsOn
+		}
+		mock.containers = append(mock.containers, container.Summary{
+			ID: svc.id, Names: []string{"/" + svc.name}, Labels: labels,
+		})
+		mock.inspectResults[svc.id] = container.InspectResponse{
+			ID:              svc.id,
+			Name:            "/" + svc.name,
+			Image:           "sha256:old-" + svc.service,
+			Config:          &container.Config{Image: "example/" + svc.service + ":1", Labels: labels},
+			HostConfig:      &container.HostConfig{},
+			NetworkSettings: &container.NetworkSettings{},
+		}
+		mock.inspectResults["new-"+svc.name] = container.InspectResponse{
+			ID:              "new-" + svc.name,
+			Name:            "/" + svc.name,
+			State:           &container.State{Running: true},
+			Config:          &container.Config{Image: "example/" + svc.service + ":1"},
+			HostConfig:      &container.HostConfig{},
+			NetworkSettings: &container.NetworkSettings{},
+		}
+		mock.imageIDs["example/"+svc.service+":1"] = "sha256:new-" + svc.service
+	}
+	// An unrelated container outside the project.
+	mock.containers = append(mock.containers, container.Summary{ID: "other-id", Names: []string{"/other"}})
+	return mock
+}
+
+func TestStackUpdateOrdersByDependency(t *testing.T) {
+	mock := stackMock()
+	u, _ := newTestUpdater(t, mock)
+
+	res, err := NewStackUpdater(u).Update(context.Background(), "shop")
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if len(res.Order) != 2 || res.Order[0] != "shop-db-1" || res.Order[1] != "shop-app-1" {
+		t.Errorf("Order = %v, want [shop-db-1 shop-app-1]", res.Order)
+	}
+	if len(res.Updated) != 2 {
+		t.Errorf("Updated = %v, want both members", res.Updated)
+	}
+	if mock.createCalls[0] != "shop-db-1" {
+		t.Errorf("first create = %q, want shop-db-1", mock.createCalls[0])
+	}
+}
+
+func TestStackUpdatePullFailureChangesNothing(t *testing.T) {
+	mock := stackMock()
+	mock.pullErr["example/app:1"] = errors.New("manifest unknown")
+	u, _ := newTestUpdater(t, mock)
+
+	res, err := NewStackUpdater(u).Update(context.Background(), "shop")
+	if err == nil {
+		t.Fatal("expected pull error")
+	}
+	if res.Failed != "shop-app-1" {
+		t.Errorf("Failed = %q, want shop-app-1", res.Failed)
+	}
+	if len(mock.stopCalls) != 0 || len(mock.createCalls) != 0 {
+		t.Errorf("containers touched after pull failure: stop=%v create=%v", mock.stopCalls, mock.createCalls)
+	}
+}
+
+func TestStackUpdateRollsBackEarlierMembers(t *testing.T) {
+	mock := stackMock()
+	// app's new container never comes up, so db must be restored too.
+	mock.inspectResults["new-shop-app-1"] = container.InspectResponse{
+		ID:     "new-shop-app-1",
+		Name:   "/shop-app-1",
+		State:  &container.State{Running: false, Restarting: true},
+		Config: &container.Config{Image: "example/app:1"},
+	}
+	u, _ := newTestUpdater(t, mock)
+
+	res, err := NewStackUpdater(u).Update(context.Background(), "shop")
+	if err == nil {
+		t.Fatal("expected update error")
+	}
+	if res.Failed != "shop-app-1" {
+		t.Errorf("Failed = %q, want shop-app-1", res.Failed)
+	}
+	if len(res.RolledBack) != 1 || res.RolledBack[0] != "shop-db-1" {
+		t.Errorf("RolledBack = %v, want [shop-db-1]", res.RolledBack)
+	}
+
+	records, err := u.store.ListHistory(10, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, r := range records {
+		if r.ContainerName == "shop-db-1" && r.Outcome == "rollback" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("no rollback history record for shop-db-1")
+	}
+}
+
+func TestStackUpdateUnknownProject(t *testing.T) {
+	u, _ := newTestUpdater(t, stackMock())
+	if _, err := NewStackUpdater(u).Update(context.Background(), "nope"); !errors.Is(err, ErrStackNotFound) {
+		t.Errorf("err = %v, want ErrStackNotFound", err)
+	}
+}
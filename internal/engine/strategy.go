@@ -0,0 +1,150 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/docker"
+	"github.com/moby/moby/api/types/container"
+)
+
+// Built-in update strategy names, as used in the sentinel.strategy label.
+const (
+	StrategyRecreate     = "recreate"
+	StrategyBlueGreen    = "blue-green"
+	StrategyPullOnly     = "pull-only"
+	StrategyCompose      = "compose"
+	StrategySwarmService = "swarm-service"
+)
+
+// UpdateTarget is a container about to be updated. Inspect is taken once by
+// UpdateContainer so strategies see a consistent view of the container.
+type UpdateTarget struct {
+	ID          string
+	Name        string
+	TargetImage string // empty = re-pull the current tag
+	Inspect     container.InspectResponse
+}
+
+// UpdateStrategy applies an update to a single container. Strategies are
+// called with the container's update lock held and must record the outcome
+// in history themselves.
+type UpdateStrategy interface {
+	Name() string
+	Update(ctx context.Context, t UpdateTarget) error
+}
+
+// RegisterStrategy adds or replaces an update strategy, making it
+// selectable via the sentinel.strategy label.
+func (u *Updater) RegisterStrategy(s UpdateStrategy) {
+	u.strategies[s.Name()] = s
+}
+
+// builtinStrategies returns the strategies every Updater starts with.
+func builtinStrategies(u *Updater) map[string]UpdateStrategy {
+	m := make(map[string]UpdateStrategy)
+	for _, s := range []UpdateStrategy{
+		&recreateStrategy{u: u},
+		&blueGreenStrategy{u: u},
+		&pullOnlyStrategy{u: u},
+		&composeStrategy{u: u},
+		&swarmServiceStrategy{u: u},
+	} {
+		m[s.Name()] = s
+	}
+	return m
+}
+
+// strategyFor picks the strategy for a container from its labels:
+// an explicit sentinel.strategy wins, then sentinel.pull-only, then Swarm
+// task labels. Everything else is recreated. Unknown strategy names are
+// logged and fall back to recreate.
+func (u *Updater) strategyFor(name string, labels map[string]string) UpdateStrategy {
+	want := docker.ContainerStrategy(labels)
+	switch {
+	case want != "":
+	case docker.ContainerPullOnly(labels):
+		want = StrategyPullOnly
+	case labels["com.docker.swarm.service.id"] != "":
+		want = StrategySwarmService
+	default:
+		want = StrategyRecreate
+	}
+	if s, ok := u.strategies[want]; ok {
+		return s
+	}
+	u.log.Warn("unknown update strategy, using recreate", "name", name, "strategy", want)
+	return u.strategies[StrategyRecreate]
+}
+
+// UpdateContainer updates a single container using the strategy selected by
+// its labels (see strategyFor). Returns ErrUpdateInProgress if the container
+// already has an update running, ErrPreconditionDeferred /
+// ErrPreconditionSkipped when its wait-for conditions are not met, or
+// ErrPullCheckFailed when the registry or disk space check fails.
+//
+// targetImage overrides the image to pull for semver version bumps (e.g.
+// "dxflrs/garage:v2.2.0"). When empty, the current image tag is re-pulled
+// (correct for :latest-style updates where the tag is mutable).
+func (u *Updater) UpdateContainer(ctx context.Context, id, name, targetImage string) error {
+	if u.draining.Load() {
+		return ErrShuttingDown
+	}
+	ctx, lock, ok := u.lockUpdate(ctx, name)
+	if !ok {
+		return ErrUpdateInProgress
+	}
+	defer u.releaseUpdate(lock)
+
+	inspect, err := u.docker.InspectContainer(ctx, id)
+	if err != nil {
+		return fmt.Errorf("inspect %s: %w", name, err)
+	}
+	var labels map[string]string
+	oldImage := ""
+	if inspect.Config != nil {
+		labels = inspect.Config.Labels
+		oldImage = inspect.Config.Image
+	}
+	s := u.strategyFor(name, labels)
+	u.log.Debug("updating container", "name", name, "strategy", s.Name())
+
+	if err := u.checkCapabilities(ctx, name, s.Name()); err != nil {
+		return err
+	}
+
+	u.setUpdatePhase(ctx, "preconditions")
+	met, err := u.checkPreconditions(ctx, name, targetImage, inspect)
+	if err != nil {
+		return err
+	}
+	ctx = withPreconditions(ctx, met)
+
+	u.setUpdatePhase(ctx, "pull checks")
+	if err := u.checkPull(ctx, name, s.Name(), targetImage, inspect); err != nil {
+		return err
+	}
+
+	run := u.startLifecycle(name, oldImage, targetImage)
+	err = s.Update(withLifecycle(ctx, run), UpdateTarget{ID: id, Name: name, TargetImage: targetImage, Inspect: inspect})
+	run.finish(err)
+	return err
+}
+
+// recreateOptions tunes the shared recreate lifecycle for strategies built
+// on top of it.
+type recreateOptions struct {
+	preflight       bool // start and validate a candidate before stopping the old container
+	swap            bool // create the replacement before stopping the old container, then rename it in
+	skipComposeSync bool // the compose file has already been updated by the caller
+}
+
+// recreateStrategy stops the old container and starts a new one with the
+// same configuration, rolling back if validation fails. This is the default.
+type recreateStrategy struct{ u *Updater }
+
+func (s *recreateStrategy) Name() string { return StrategyRecreate }
+
+func (s *recreateStrategy) Update(ctx context.Context, t UpdateTarget) error {
+	return s.u.recreate(ctx, t, recreateOptions{})
+}
@@ -0,0 +1,266 @@
+package engine
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+	"github.com/Will-Luck/Docker-Sentinel/internal/metrics"
+	"github.com/Will-Luck/Docker-Sentinel/internal/notify"
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/api/types/network"
+)
+
+const (
+	// candidateSuffix names the throwaway container started by blue-green
+	// pre-flight checks.
+	candidateSuffix = "-sentinel-candidate"
+	// replacementSuffix names the blue-green replacement while it is created
+	// next to the old container, before it is renamed into place.
+	replacementSuffix = "-sentinel-next"
+)
+
+// blueGreenStrategy starts the new image as a candidate container next to
+// the old one and only swaps once the candidate passes validation. A
+// candidate that fails leaves the old container untouched. The swap creates
+// the replacement up front, so the container is only down for a stop and a
+// start.
+//
+// The candidate shares the old container's volumes and networks but not its
+// static IPs, so this suits stateless services; anything that takes an
+// exclusive lock on its data should use recreate. Published ports are moved
+// to temporary host ports from the bluegreen_port_range setting, or dropped
+// when it is unset.
+type blueGreenStrategy struct{ u *Updater }
+
+func (s *blueGreenStrategy) Name() string { return StrategyBlueGreen }
+
+func (s *blueGreenStrategy) Update(ctx context.Context, t UpdateTarget) error {
+	return s.u.recreate(ctx, t, recreateOptions{preflight: true, swap: true})
+}
+
+// preflightCandidate runs pullImage as a candidate container and validates
+// it with the container's usual validation plan. The candidate is always
+// removed. On failure the update is recorded as failed.
+func (u *Updater) preflightCandidate(ctx context.Context, t UpdateTarget, pullImage string, start time.Time, stages map[string]time.Duration) error {
+	name := t.Name + candidateSuffix
+	cfg := cloneConfig(t.Inspect.Config)
+	cfg.Image = pullImage
+	addMaintenanceLabel(cfg) // keep Guardian away from the candidate
+
+	u.setUpdatePhase(ctx, "preflight")
+	preflightStart := u.clock.Now()
+	u.log.Info("starting blue-green candidate", "name", t.Name, "candidate", name, "image", pullImage)
+	hostCfg := candidateHostConfig(t.Inspect.HostConfig)
+	ports, err := u.candidatePorts(ctx, t.Inspect.HostConfig)
+	healthy := false
+	if err == nil {
+		if ports != nil {
+			hostCfg.PortBindings = ports
+			u.log.Info("blue-green candidate published on temporary ports", "name", t.Name, "ports", describePorts(ports))
+		}
+		healthy, err = u.runCandidate(ctx, name, cfg, hostCfg, candidateNetworkingConfig(t.Inspect.NetworkSettings), t)
+	}
+	stages["preflight"] = u.clock.Since(preflightStart)
+	if err == nil && healthy {
+		u.log.Info("blue-green candidate passed validation", "name", t.Name)
+		return nil
+	}
+	if err == nil {
+		err = errors.New("candidate unhealthy")
+	}
+
+	u.log.Error("blue-green candidate failed, keeping old container", "name", t.Name, "error", err)
+	class := store.FailureHealthFailed
+	if isValidationTimeout(err) {
+		class = store.FailureValidationTimeout
+	}
+	if recErr := u.store.RecordUpdate(store.UpdateRecord{
+		Timestamp:     u.clock.Now(),
+		ContainerName: t.Name,
+		OldImage:      t.Inspect.Config.Image,
+		OldDigest:     extractDigestForRecord(t.Inspect),
+		NewImage:      pullImage,
+		Outcome:       "failed",
+		Duration:      u.clock.Since(start),
+		Error:         "pre-flight: " + err.Error(),
+		FailureClass:  class,
+		Stages:        stages,
+		Comment:       approvalComment(ctx),
+	}); recErr != nil {
+		u.log.Warn("failed to persist pre-flight failure record", "name", t.Name, "error", recErr)
+	}
+	metrics.UpdatesTotal.WithLabelValues("failed").Inc()
+	u.publishEvent(events.EventContainerUpdate, t.Name, "pre-flight failed")
+	u.notifyAttempt(ctx, notify.Event{
+		Type:          notify.EventUpdateFailed,
+		ContainerName: t.Name,
+		OldImage:      t.Inspect.Config.Image,
+		NewImage:      pullImage,
+		Error:         fmt.Sprintf("pre-flight validation failed: %v", err),
+		Timestamp:     u.clock.Now(),
+	})
+	return fmt.Errorf("pre-flight candidate for %s failed validation: %w", t.Name, err)
+}
+
+// runCandidate creates, starts and validates a candidate container, removing
+// it before returning.
+func (u *Updater) runCandidate(ctx context.Context, name string, cfg *container.Config, hostCfg *container.HostConfig, netCfg *network.NetworkingConfig, t UpdateTarget) (bool, error) {
+	u.removeLeftover(ctx, name)
+	id, err := u.docker.CreateContainer(ctx, name, cfg, hostCfg, netCfg)
+	if err != nil {
+		return false, fmt.Errorf("create candidate: %w", err)
+	}
+	defer func() {
+		cleanupCtx := context.WithoutCancel(ctx)
+		_ = u.docker.StopContainer(cleanupCtx, id, 10)
+		if err := u.docker.RemoveContainer(cleanupCtx, id); err != nil {
+			u.log.Warn("failed to remove blue-green candidate", "candidate", name, "error", err)
+		}
+	}()
+	if err := u.docker.StartContainer(ctx, id); err != nil {
+		return false, fmt.Errorf("start candidate: %w", err)
+	}
+
+	plan := u.validationPlanFor(t.Name, t.Inspect.Config.Labels)
+	select {
+	case <-u.clock.After(plan.grace):
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+	return u.validateContainer(ctx, id, plan)
+}
+
+// removeLeftover removes a container still holding a blue-green temporary
+// name, left by an update that crashed between creating it and swapping it
+// in. Creating the next one would otherwise fail on the name conflict.
+func (u *Updater) removeLeftover(ctx context.Context, name string) {
+	c, found, err := u.findContainer(ctx, name)
+	if err != nil || !found {
+		return
+	}
+	u.log.Warn("removing container left by an interrupted blue-green update", "container", name)
+	_ = u.docker.StopContainer(ctx, c.ID, 10)
+	if err := u.docker.RemoveContainer(ctx, c.ID); err != nil {
+		u.log.Warn("could not remove leftover blue-green container", "container", name, "error", err)
+	}
+}
+
+// candidateHostConfig copies the host config without published ports, which
+// the old container still holds. candidatePorts may put temporary ones back.
+func candidateHostConfig(hc *container.HostConfig) *container.HostConfig {
+	if hc == nil {
+		return nil
+	}
+	out := *hc
+	out.PortBindings = nil
+	out.PublishAllPorts = false
+	return &out
+}
+
+// candidateNetworkingConfig joins the candidate to the old container's
+// networks without its static addresses or aliases, so it neither clashes
+// with nor receives the old container's traffic.
+func candidateNetworkingConfig(ns *container.NetworkSettings) *network.NetworkingConfig {
+	nc := rebuildNetworkingConfig(ns)
+	if nc == nil {
+		return nil
+	}
+	for _, ep := range nc.EndpointsConfig {
+		ep.IPAMConfig = nil
+		ep.Aliases = nil
+		ep.MacAddress = nil
+	}
+	return nc
+}
+
+// blueGreenPortRange reads the bluegreen_port_range setting. ok is false
+// when it is unset or invalid.
+func (u *Updater) blueGreenPortRange() (lo, hi int, ok bool) {
+	if u.settings == nil {
+		return 0, 0, false
+	}
+	val, _ := u.settings.LoadSetting("bluegreen_port_range")
+	if strings.TrimSpace(val) == "" {
+		return 0, 0, false
+	}
+	lo, hi, err := ParsePortRange(val)
+	if err != nil {
+		u.log.Warn("ignoring invalid bluegreen_port_range", "value", val, "error", err)
+		return 0, 0, false
+	}
+	return lo, hi, true
+}
+
+// ParsePortRange parses a host port range such as "20000-20999".
+func ParsePortRange(s string) (lo, hi int, err error) {
+	from, to, found := strings.Cut(strings.TrimSpace(s), "-")
+	if !found {
+		return 0, 0, fmt.Errorf("port range %q must be start-end", s)
+	}
+	lo, err1 := strconv.Atoi(strings.TrimSpace(from))
+	hi, err2 := strconv.Atoi(strings.TrimSpace(to))
+	if err1 != nil || err2 != nil || lo < 1 || hi > 65535 || lo > hi {
+		return 0, 0, fmt.Errorf("port range %q must be two ports between 1 and 65535, lowest first", s)
+	}
+	return lo, hi, nil
+}
+
+// candidatePorts maps each published port of hc onto a free host port from
+// the blue-green port range, so the candidate is reachable on the host
+// while it is validated. Ports published by other containers are skipped.
+// Returns nil when there is no range or nothing is published.
+func (u *Updater) candidatePorts(ctx context.Context, hc *container.HostConfig) (network.PortMap, error) {
+	lo, hi, ok := u.blueGreenPortRange()
+	if !ok || hc == nil || len(hc.PortBindings) == 0 {
+		return nil, nil
+	}
+	used := make(map[int]bool)
+	if containers, err := u.docker.ListAllContainers(ctx); err == nil {
+		for _, c := range containers {
+			for _, p := range c.Ports {
+				used[int(p.PublicPort)] = true
+			}
+		}
+	}
+
+	keys := slices.SortedFunc(maps.Keys(hc.PortBindings), func(a, b network.Port) int {
+		return cmp.Or(cmp.Compare(a.Num(), b.Num()), cmp.Compare(a.Proto(), b.Proto()))
+	})
+	out := make(network.PortMap, len(keys))
+	next := lo
+	for _, port := range keys {
+		for _, b := range hc.PortBindings[port] {
+			for next <= hi && used[next] {
+				next++
+			}
+			if next > hi {
+				return nil, fmt.Errorf("blue-green port range %d-%d has no free port left", lo, hi)
+			}
+			out[port] = append(out[port], network.PortBinding{HostIP: b.HostIP, HostPort: strconv.Itoa(next)})
+			next++
+		}
+	}
+	return out, nil
+}
+
+// describePorts renders a port map for logs, e.g. "80/tcp->20000".
+func describePorts(pm network.PortMap) string {
+	var parts []string
+	for port, bindings := range pm {
+		for _, b := range bindings {
+			parts = append(parts, port.String()+"->"+b.HostPort)
+		}
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,160 @@
+package engine
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/api/types/network"
+)
+
+func blueGreenMock() *mockDocker {
+	mock := newMockDocker()
+	mock.inspectResults["aaa"] = container.InspectResponse{
+		ID:   "aaa",
+		Name: "/web",
+		Config: &container.Config{
+			Image:  "web:1",
+			Labels: map[string]string{"sentinel.strategy": "blue-green"},
+		},
+		HostConfig: &container.HostConfig{
+			PortBindings: network.PortMap{},
+		},
+		NetworkSettings: &container.NetworkSettings{},
+	}
+	return mock
+}
+
+func TestBlueGreenCandidateFailureKeepsOldContainer(t *testing.T) {
+	mock := blueGreenMock()
+	mock.inspectResults["new-web"+candidateSuffix] = container.InspectResponse{
+		State:  &container.State{Running: false, Restarting: true},
+		Config: &container.Config{Image: "web:2"},
+	}
+	u, _ := newTestUpdater(t, mock)
+
+	if err := u.UpdateContainer(context.Background(), "aaa", "web", "web:2"); err == nil {
+		t.Fatal("expected pre-flight failure")
+	}
+	if slices.Contains(mock.stopCalls, "aaa") || slices.Contains(mock.removeCalls, "aaa") {
+		t.Error("old container was touched after candidate failed")
+	}
+	if !slices.Contains(mock.removeCalls, "new-web"+candidateSuffix) {
+		t.Error("candidate container was not removed")
+	}
+	if slices.Contains(mock.createCalls, "web") {
+		t.Error("replacement container created after candidate failed")
+	}
+	history, _ := u.store.ListHistory(10, "")
+	if len(history) != 1 || history[0].Outcome != "failed" {
+		t.Errorf("history = %+v, want one failed record", history)
+	}
+	if maint, _ := u.store.GetMaintenance("web"); maint {
+		t.Error("maintenance flag left set after pre-flight failure")
+	}
+}
+
+func TestBlueGreenCandidatePassSwaps(t *testing.T) {
+	mock := blueGreenMock()
+	running := container.InspectResponse{
+		State:  &container.State{Running: true},
+		Config: &container.Config{Image: "web:2"},
+	}
+	mock.inspectResults["new-web"+candidateSuffix] = running
+	mock.inspectResults["new-web"+replacementSuffix] = running
+	u, _ := newTestUpdater(t, mock)
+
+	if err := u.UpdateContainer(context.Background(), "aaa", "web", "web:2"); err != nil {
+		t.Fatalf("UpdateContainer: %v", err)
+	}
+	want := []string{"web" + candidateSuffix, "web" + replacementSuffix}
+	if !slices.Equal(mock.createCalls, want) {
+		t.Errorf("createCalls = %v, want %v (no finalise recreate)", mock.createCalls, want)
+	}
+	if !slices.Contains(mock.removeCalls, "aaa") {
+		t.Error("old container not replaced after candidate passed")
+	}
+	if len(mock.renameCalls) != 1 || mock.renameCalls[0].id != "new-web"+replacementSuffix || mock.renameCalls[0].newName != "web" {
+		t.Errorf("renameCalls = %+v, want the replacement renamed to web", mock.renameCalls)
+	}
+	if _, ok := mock.createConfigs["web"+replacementSuffix].Labels["sentinel.maintenance"]; ok {
+		t.Error("replacement created with the maintenance label")
+	}
+}
+
+func TestBlueGreenRemovesLeftoverReplacement(t *testing.T) {
+	mock := blueGreenMock()
+	running := container.InspectResponse{
+		State:  &container.State{Running: true},
+		Config: &container.Config{Image: "web:2"},
+	}
+	mock.inspectResults["new-web"+candidateSuffix] = running
+	mock.inspectResults["new-web"+replacementSuffix] = running
+	// A crash mid-swap left the previous attempt's replacement behind.
+	mock.containers = []container.Summary{{ID: "stale", Names: []string{"/web" + replacementSuffix}}}
+	u, _ := newTestUpdater(t, mock)
+
+	if err := u.UpdateContainer(context.Background(), "aaa", "web", "web:2"); err != nil {
+		t.Fatalf("UpdateContainer: %v", err)
+	}
+	if !slices.Contains(mock.removeCalls, "stale") {
+		t.Errorf("removeCalls = %v, want the leftover replacement removed", mock.removeCalls)
+	}
+	if !slices.Contains(mock.createCalls, "web"+replacementSuffix) {
+		t.Error("replacement not created after removing the leftover")
+	}
+}
+
+func TestBlueGreenCandidateTemporaryPorts(t *testing.T) {
+	mock := blueGreenMock()
+	mock.inspectResults["aaa"].HostConfig.PortBindings = network.PortMap{
+		network.MustParsePort("80/tcp"):  {{HostPort: "80"}},
+		network.MustParsePort("443/tcp"): {{HostPort: "443"}},
+	}
+	mock.containers = []container.Summary{{ID: "other", Names: []string{"/other"}, Ports: []container.PortSummary{{PublicPort: 20000}}}}
+	mock.inspectResults["new-web"+candidateSuffix] = container.InspectResponse{
+		State:  &container.State{Running: false},
+		Config: &container.Config{Image: "web:2"},
+	}
+	u, _ := newTestUpdater(t, mock)
+	u.SetSettingsReader(u.store)
+	if err := u.store.SaveSetting("bluegreen_port_range", "20000-20010"); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = u.UpdateContainer(context.Background(), "aaa", "web", "web:2")
+	got := mock.createHosts["web"+candidateSuffix].PortBindings
+	if got[network.MustParsePort("80/tcp")][0].HostPort != "20001" || got[network.MustParsePort("443/tcp")][0].HostPort != "20002" {
+		t.Errorf("candidate ports = %v, want 80->20001 and 443->20002 (20000 in use)", got)
+	}
+}
+
+func TestParsePortRange(t *testing.T) {
+	if lo, hi, err := ParsePortRange(" 20000 - 20999 "); err != nil || lo != 20000 || hi != 20999 {
+		t.Errorf("ParsePortRange = (%d, %d, %v)", lo, hi, err)
+	}
+	for _, bad := range []string{"20000", "b-c", "0-10", "300-200", "60000-70000"} {
+		if _, _, err := ParsePortRange(bad); err == nil {
+			t.Errorf("ParsePortRange(%q) = nil error", bad)
+		}
+	}
+}
+
+func TestCandidateHostConfigDropsPorts(t *testing.T) {
+	hc := &container.HostConfig{
+		PortBindings:    network.PortMap{},
+		PublishAllPorts: true,
+		Binds:           []string{"/data:/data"},
+	}
+	got := candidateHostConfig(hc)
+	if got.PortBindings != nil || got.PublishAllPorts {
+		t.Errorf("candidate host config still publishes ports: %+v", got)
+	}
+	if len(got.Binds) != 1 {
+		t.Errorf("Binds = %v, want volumes kept", got.Binds)
+	}
+	if hc.PortBindings == nil || !hc.PublishAllPorts {
+		t.Error("original host config was modified")
+	}
+}
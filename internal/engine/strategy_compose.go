@@ -0,0 +1,49 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// composeStrategy updates the image tag in the container's compose file
+// before recreating it, so the file never lags behind the running
+// container. The file is restored if the update fails. Containers not
+// created by Compose are recreated as normal.
+type composeStrategy struct{ u *Updater }
+
+func (s *composeStrategy) Name() string { return StrategyCompose }
+
+func (s *composeStrategy) Update(ctx context.Context, t UpdateTarget) error {
+	if t.Inspect.Config == nil {
+		return fmt.Errorf("inspect %s: container config is nil", t.Name)
+	}
+	path, service, ok := s.u.composeFileFor(t.Inspect.Config.Labels)
+	if !ok {
+		s.u.log.Warn("compose strategy on non-compose container, recreating", "name", t.Name)
+		return s.u.recreate(ctx, t, recreateOptions{})
+	}
+	target := t.TargetImage
+	if target == "" {
+		target = t.Inspect.Config.Image
+	}
+
+	original, err := os.ReadFile(path) // #nosec G304 -- path comes from Docker compose labels on the host, not request input
+	if err != nil {
+		return fmt.Errorf("read compose file for %s: %w", t.Name, err)
+	}
+	if err := UpdateComposeTag(path, service, target); err != nil {
+		return fmt.Errorf("update compose file for %s: %w", t.Name, err)
+	}
+
+	updateErr := s.u.recreate(ctx, t, recreateOptions{skipComposeSync: true})
+	if updateErr != nil && !errors.Is(updateErr, context.Canceled) {
+		if err := os.WriteFile(path, original, 0600); err != nil { // #nosec G703 -- path comes from Docker compose labels on the host, not request input
+			s.u.log.Error("failed to restore compose file", "name", t.Name, "file", path, "error", err)
+		} else {
+			s.u.log.Info("compose file restored after failed update", "name", t.Name, "file", path)
+		}
+	}
+	return updateErr
+}
@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/moby/moby/api/types/container"
+)
+
+const strategyComposeYAML = `services:
+  web:
+    image: nginx:1.25
+`
+
+func composeStrategyMock(t *testing.T, newRunning bool) (*mockDocker, string) {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "docker-compose.yml")
+	if err := os.WriteFile(path, []byte(strategyComposeYAML), 0600); err != nil {
+		t.Fatal(err)
+	}
+	mock := newMockDocker()
+	mock.inspectResults["aaa"] = container.InspectResponse{
+		ID:   "aaa",
+		Name: "/web",
+		Config: &container.Config{
+			Image: "nginx:1.25",
+			Labels: map[string]string{
+				"sentinel.strategy":                       "compose",
+				"com.docker.compose.project.working_dir":  dir,
+				"com.docker.compose.project.config_files": "docker-compose.yml",
+				"com.docker.compose.service":              "web",
+			},
+		},
+		HostConfig:      &container.HostConfig{},
+		NetworkSettings: &container.NetworkSettings{},
+	}
+	mock.inspectResults["new-web"] = container.InspectResponse{
+		State:  &container.State{Running: newRunning, Restarting: !newRunning},
+		Config: &container.Config{Image: "nginx:1.26"},
+	}
+	return mock, path
+}
+
+func TestComposeStrategyUpdatesFile(t *testing.T) {
+	mock, path := composeStrategyMock(t, true)
+	u, _ := newTestUpdater(t, mock)
+
+	if err := u.UpdateContainer(context.Background(), "aaa", "web", "nginx:1.26"); err != nil {
+		t.Fatalf("UpdateContainer: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "nginx:1.26") {
+		t.Errorf("compose file not updated:\n%s", data)
+	}
+}
+
+func TestComposeStrategyRestoresFileOnFailure(t *testing.T) {
+	mock, path := composeStrategyMock(t, false)
+	u, _ := newTestUpdater(t, mock)
+
+	if err := u.UpdateContainer(context.Background(), "aaa", "web", "nginx:1.26"); err == nil {
+		t.Fatal("expected validation failure")
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != strategyComposeYAML {
+		t.Errorf("compose file not restored:\n%s", data)
+	}
+}
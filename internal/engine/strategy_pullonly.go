@@ -0,0 +1,45 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+)
+
+// pullOnlyStrategy pulls the new image without touching the running
+// container, leaving the restart to the operator.
+type pullOnlyStrategy struct{ u *Updater }
+
+func (s *pullOnlyStrategy) Name() string { return StrategyPullOnly }
+
+func (s *pullOnlyStrategy) Update(ctx context.Context, t UpdateTarget) error {
+	current := ""
+	var labels map[string]string
+	if t.Inspect.Config != nil {
+		current, labels = t.Inspect.Config.Image, t.Inspect.Config.Labels
+	}
+	target := t.TargetImage
+	if target == "" {
+		target = current
+	}
+	return s.u.pullOnly(ctx, t.Name, current, target, labels)
+}
+
+// pullOnly pulls target and records a pull_only history entry. Shared by the
+// pull-only strategy and the scan's pull_only setting.
+func (u *Updater) pullOnly(ctx context.Context, name, current, target string, labels map[string]string) error {
+	if err := u.pullImage(ctx, name, target, labels); err != nil {
+		return fmt.Errorf("pull image for %s: %w", name, err)
+	}
+	_ = u.store.RecordUpdate(store.UpdateRecord{
+		Timestamp:     u.clock.Now(),
+		ContainerName: name,
+		OldImage:      current,
+		NewImage:      target,
+		Outcome:       "pull_only",
+		Comment:       approvalComment(ctx),
+	})
+	u.queue.Remove(name)
+	return nil
+}
@@ -0,0 +1,49 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/moby/moby/api/types/container"
+)
+
+func TestPullOnlyStrategyLeavesContainerRunning(t *testing.T) {
+	mock := newMockDocker()
+	mock.inspectResults["aaa"] = container.InspectResponse{
+		ID:     "aaa",
+		Config: &container.Config{Image: "app:1", Labels: map[string]string{"sentinel.pull-only": "true"}},
+	}
+	u, _ := newTestUpdater(t, mock)
+
+	if err := u.UpdateContainer(context.Background(), "aaa", "app", "app:2"); err != nil {
+		t.Fatalf("UpdateContainer: %v", err)
+	}
+	if len(mock.pullCalls) != 1 || mock.pullCalls[0] != "app:2" {
+		t.Errorf("pullCalls = %v, want [app:2]", mock.pullCalls)
+	}
+	if len(mock.stopCalls) != 0 || len(mock.createCalls) != 0 {
+		t.Errorf("container touched: stop=%v create=%v", mock.stopCalls, mock.createCalls)
+	}
+	history, _ := u.store.ListHistory(10, "")
+	if len(history) != 1 || history[0].Outcome != "pull_only" || history[0].OldImage != "app:1" {
+		t.Errorf("history = %+v, want one pull_only record from app:1", history)
+	}
+}
+
+func TestPullOnlyStrategyPullFailure(t *testing.T) {
+	mock := newMockDocker()
+	mock.inspectResults["aaa"] = container.InspectResponse{
+		ID:     "aaa",
+		Config: &container.Config{Image: "app:1", Labels: map[string]string{"sentinel.strategy": "pull-only"}},
+	}
+	mock.pullErr["app:1"] = errors.New("registry down")
+	u, _ := newTestUpdater(t, mock)
+
+	if err := u.UpdateContainer(context.Background(), "aaa", "app", ""); err == nil {
+		t.Fatal("expected pull error")
+	}
+	if history, _ := u.store.ListHistory(10, ""); len(history) != 0 {
+		t.Errorf("history = %+v, want none after failed pull", history)
+	}
+}
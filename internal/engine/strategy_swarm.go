@@ -0,0 +1,28 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+)
+
+// swarmServiceStrategy updates the Swarm service a task container belongs
+// to instead of the container itself, letting Swarm roll the tasks.
+type swarmServiceStrategy struct{ u *Updater }
+
+func (s *swarmServiceStrategy) Name() string { return StrategySwarmService }
+
+func (s *swarmServiceStrategy) Update(ctx context.Context, t UpdateTarget) error {
+	var labels map[string]string
+	if t.Inspect.Config != nil {
+		labels = t.Inspect.Config.Labels
+	}
+	serviceID := labels["com.docker.swarm.service.id"]
+	if serviceID == "" {
+		return fmt.Errorf("%s is not a swarm task", t.Name)
+	}
+	serviceName := labels["com.docker.swarm.service.name"]
+	if serviceName == "" {
+		serviceName = serviceID
+	}
+	return s.u.UpdateService(ctx, serviceID, serviceName, t.TargetImage)
+}
@@ -0,0 +1,43 @@
+package engine
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/moby/moby/api/types/container"
+)
+
+func TestSwarmServiceStrategyUpdatesService(t *testing.T) {
+	mock := newMockDocker()
+	mock.inspectResults["task1"] = container.InspectResponse{
+		ID: "task1",
+		Config: &container.Config{
+			Image: "app:1",
+			Labels: map[string]string{
+				"com.docker.swarm.service.id":   "svc1",
+				"com.docker.swarm.service.name": "app",
+			},
+		},
+	}
+	u, _ := newTestUpdater(t, mock)
+
+	// No service is registered in the mock, so the service inspect fails —
+	// proving the update went to the service rather than the container.
+	err := u.UpdateContainer(context.Background(), "task1", "app.1.abc", "app:2")
+	if err == nil || !strings.Contains(err.Error(), "inspect service app") {
+		t.Fatalf("err = %v, want service inspect error", err)
+	}
+	if len(mock.stopCalls) != 0 || len(mock.createCalls) != 0 {
+		t.Errorf("task container touched: stop=%v create=%v", mock.stopCalls, mock.createCalls)
+	}
+}
+
+func TestSwarmServiceStrategyRequiresTask(t *testing.T) {
+	u, _ := newTestUpdater(t, newMockDocker())
+	s := &swarmServiceStrategy{u: u}
+	err := s.Update(context.Background(), UpdateTarget{Name: "app", Inspect: container.InspectResponse{Config: &container.Config{}}})
+	if err == nil {
+		t.Fatal("expected error for non-task container")
+	}
+}
@@ -0,0 +1,66 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/moby/moby/api/types/container"
+)
+
+// stubStrategy records the targets it was asked to update.
+type stubStrategy struct {
+	name  string
+	calls []UpdateTarget
+}
+
+func (s *stubStrategy) Name() string { return s.name }
+
+func (s *stubStrategy) Update(_ context.Context, t UpdateTarget) error {
+	s.calls = append(s.calls, t)
+	return nil
+}
+
+func TestStrategyForSelection(t *testing.T) {
+	u, _ := newTestUpdater(t, newMockDocker())
+	tests := []struct {
+		labels map[string]string
+		want   string
+	}{
+		{nil, StrategyRecreate},
+		{map[string]string{"sentinel.strategy": "Blue-Green"}, StrategyBlueGreen},
+		{map[string]string{"sentinel.strategy": "bluegreen"}, StrategyBlueGreen},
+		{map[string]string{"sentinel.pull-only": "true"}, StrategyPullOnly},
+		{map[string]string{"sentinel.strategy": "compose", "sentinel.pull-only": "true"}, StrategyCompose},
+		{map[string]string{"com.docker.swarm.service.id": "svc1"}, StrategySwarmService},
+		{map[string]string{"sentinel.strategy": "bogus"}, StrategyRecreate},
+	}
+	for _, tt := range tests {
+		if got := u.strategyFor("app", tt.labels).Name(); got != tt.want {
+			t.Errorf("strategyFor(%v) = %q, want %q", tt.labels, got, tt.want)
+		}
+	}
+}
+
+func TestUpdateContainerDispatchesToRegisteredStrategy(t *testing.T) {
+	mock := newMockDocker()
+	mock.inspectResults["aaa"] = container.InspectResponse{
+		ID:     "aaa",
+		Config: &container.Config{Image: "app:1", Labels: map[string]string{"sentinel.strategy": "canary"}},
+	}
+	u, _ := newTestUpdater(t, mock)
+	stub := &stubStrategy{name: "canary"}
+	u.RegisterStrategy(stub)
+
+	if err := u.UpdateContainer(context.Background(), "aaa", "app", "app:2"); err != nil {
+		t.Fatalf("UpdateContainer: %v", err)
+	}
+	if len(stub.calls) != 1 {
+		t.Fatalf("strategy calls = %d, want 1", len(stub.calls))
+	}
+	if got := stub.calls[0]; got.ID != "aaa" || got.Name != "app" || got.TargetImage != "app:2" || got.Inspect.Config.Image != "app:1" {
+		t.Errorf("target = %+v", got)
+	}
+	if len(mock.pullCalls) != 0 {
+		t.Errorf("pullCalls = %d, want 0 (stub strategy does nothing)", len(mock.pullCalls))
+	}
+}
@@ -0,0 +1,337 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/registry"
+	"github.com/moby/moby/api/types/swarm"
+)
+
+// PlacementNode is one swarm node in a placement preview.
+type PlacementNode struct {
+	ID           string `json:"id"`
+	Hostname     string `json:"hostname"`
+	Role         string `json:"role"`
+	Platform     string `json:"platform"` // as the node reports it, e.g. "linux/aarch64"
+	Eligible     bool   `json:"eligible"`
+	Reason       string `json:"reason,omitempty"` // why the node cannot take tasks
+	CurrentTasks int    `json:"current_tasks"`
+	PlannedTasks int    `json:"planned_tasks"`
+}
+
+// ServicePlacement predicts where a service's tasks land after an update.
+// PlannedTasks is an estimate: the swarm scheduler also weighs resources
+// and tasks of other services, which the preview does not model.
+type ServicePlacement struct {
+	Service        string          `json:"service"`
+	Image          string          `json:"image"`
+	Mode           string          `json:"mode"` // "replicated" or "global"
+	Replicas       uint64          `json:"replicas,omitempty"`
+	Constraints    []string        `json:"constraints,omitempty"`
+	ImagePlatforms []string        `json:"image_platforms,omitempty"`
+	Nodes          []PlacementNode `json:"nodes"`
+	Warnings       []string        `json:"warnings,omitempty"`
+}
+
+// placementConstraint is a parsed "key==value" or "key!=value" expression.
+type placementConstraint struct {
+	expr  string
+	key   string
+	equal bool
+	value string
+}
+
+func parsePlacementConstraint(expr string) (placementConstraint, error) {
+	c := placementConstraint{expr: expr}
+	for _, op := range []string{"==", "!="} {
+		if key, value, ok := strings.Cut(expr, op); ok {
+			c.key = strings.TrimSpace(key)
+			c.value = strings.TrimSpace(value)
+			c.equal = op == "=="
+			if c.key == "" || c.value == "" {
+				break
+			}
+			return c, nil
+		}
+	}
+	return c, fmt.Errorf("invalid constraint %q", expr)
+}
+
+// nodeAttr returns the value a constraint key selects on a node, and false
+// when the node has no such label.
+func nodeAttr(n swarm.Node, key string) (string, bool) {
+	switch {
+	case key == "node.id":
+		return n.ID, true
+	case key == "node.hostname":
+		return n.Description.Hostname, true
+	case key == "node.role":
+		return string(n.Spec.Role), true
+	case key == "node.platform.os":
+		return n.Description.Platform.OS, true
+	case key == "node.platform.arch":
+		return n.Description.Platform.Architecture, true
+	case strings.HasPrefix(key, "node.labels."):
+		v, ok := n.Spec.Labels[strings.TrimPrefix(key, "node.labels.")]
+		return v, ok
+	case strings.HasPrefix(key, "engine.labels."):
+		v, ok := n.Description.Engine.Labels[strings.TrimPrefix(key, "engine.labels.")]
+		return v, ok
+	}
+	return "", false
+}
+
+// matches follows swarmkit: values compare case-insensitively, and a node
+// without the label satisfies "!=" but not "==".
+func (c placementConstraint) matches(n swarm.Node) bool {
+	v, ok := nodeAttr(n, c.key)
+	if !ok {
+		return !c.equal
+	}
+	return strings.EqualFold(v, c.value) == c.equal
+}
+
+// PlanServicePlacement previews which nodes receive svc's tasks once it runs
+// image. Nodes must be ready and active, meet every constraint and platform
+// of the service, and, when platforms is non-empty, be able to run one of the
+// image's platforms. Replicated tasks are spread over the first spread
+// preference's label values, then over the nodes with the fewest tasks.
+func PlanServicePlacement(svc swarm.Service, image string, nodes []swarm.Node, tasks []swarm.Task, platforms []registry.Platform) ServicePlacement {
+	plan := ServicePlacement{Service: svc.Spec.Name, Image: image, Mode: "replicated"}
+	var placement swarm.Placement
+	if svc.Spec.TaskTemplate.Placement != nil {
+		placement = *svc.Spec.TaskTemplate.Placement
+	}
+	plan.Constraints = placement.Constraints
+	for _, p := range platforms {
+		plan.ImagePlatforms = append(plan.ImagePlatforms, p.String())
+	}
+
+	var constraints []placementConstraint
+	for _, expr := range placement.Constraints {
+		c, err := parsePlacementConstraint(expr)
+		if err != nil {
+			plan.Warnings = append(plan.Warnings, err.Error()+"; ignored in this preview")
+			continue
+		}
+		constraints = append(constraints, c)
+	}
+
+	current := make(map[string]int)
+	for _, t := range tasks {
+		if t.DesiredState == swarm.TaskStateRunning && t.NodeID != "" {
+			current[t.NodeID]++
+		}
+	}
+
+	for _, n := range nodes {
+		pn := PlacementNode{
+			ID:           n.ID,
+			Hostname:     n.Description.Hostname,
+			Role:         string(n.Spec.Role),
+			Platform:     n.Description.Platform.OS + "/" + n.Description.Platform.Architecture,
+			CurrentTasks: current[n.ID],
+		}
+		pn.Reason = nodeIneligibleReason(n, constraints, placement.Platforms, platforms)
+		pn.Eligible = pn.Reason == ""
+		plan.Nodes = append(plan.Nodes, pn)
+
+		if !pn.Eligible && strings.HasPrefix(pn.Reason, "image has no") {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("%s on %s: tasks cannot run there", pn.Reason, pn.Hostname))
+		}
+		if !pn.Eligible && pn.CurrentTasks > 0 {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("%d running task(s) on %s must move elsewhere (%s)", pn.CurrentTasks, pn.Hostname, pn.Reason))
+		}
+	}
+	sort.SliceStable(plan.Nodes, func(i, j int) bool { return plan.Nodes[i].Hostname < plan.Nodes[j].Hostname })
+
+	eligible := 0
+	for _, pn := range plan.Nodes {
+		if pn.Eligible {
+			eligible++
+		}
+	}
+
+	switch {
+	case svc.Spec.Mode.Global != nil:
+		plan.Mode = "global"
+		for i := range plan.Nodes {
+			if plan.Nodes[i].Eligible {
+				plan.Nodes[i].PlannedTasks = 1
+			}
+		}
+	case svc.Spec.Mode.Replicated != nil && svc.Spec.Mode.Replicated.Replicas != nil:
+		plan.Replicas = *svc.Spec.Mode.Replicated.Replicas
+		spread := ""
+		for _, pref := range placement.Preferences {
+			if pref.Spread != nil {
+				spread = pref.Spread.SpreadDescriptor
+				break
+			}
+		}
+		unplaced := spreadReplicas(plan.Nodes, nodes, plan.Replicas, placement.MaxReplicas, spread)
+		if unplaced > 0 && eligible > 0 {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("%d of %d replicas cannot be placed: %d eligible node(s) with at most %d task(s) each", unplaced, plan.Replicas, eligible, placement.MaxReplicas))
+		}
+	}
+
+	if eligible == 0 && len(nodes) > 0 {
+		plan.Warnings = append(plan.Warnings, "no node satisfies the service's placement; new tasks will stay pending")
+	}
+	return plan
+}
+
+// nodeIneligibleReason returns why a node cannot run the service's tasks,
+// or "" when it can.
+func nodeIneligibleReason(n swarm.Node, constraints []placementConstraint, servicePlatforms []swarm.Platform, imagePlatforms []registry.Platform) string {
+	if n.Status.State != swarm.NodeStateReady {
+		return "node is " + string(n.Status.State)
+	}
+	if n.Spec.Availability != "" && n.Spec.Availability != swarm.NodeAvailabilityActive {
+		return "availability is " + string(n.Spec.Availability)
+	}
+	for _, c := range constraints {
+		if !c.matches(n) {
+			return "constraint " + c.expr + " not met"
+		}
+	}
+	os, arch := n.Description.Platform.OS, n.Description.Platform.Architecture
+	if len(servicePlatforms) > 0 {
+		ok := false
+		for _, p := range servicePlatforms {
+			if (p.OS == "" || strings.EqualFold(p.OS, os)) && (p.Architecture == "" || registry.NormaliseArch(p.Architecture) == registry.NormaliseArch(arch)) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return "platform " + os + "/" + arch + " not in the service's platforms"
+		}
+	}
+	if len(imagePlatforms) > 0 {
+		for _, p := range imagePlatforms {
+			if p.Supports(os, arch) {
+				return ""
+			}
+		}
+		return "image has no " + os + "/" + registry.NormaliseArch(arch) + " build"
+	}
+	return ""
+}
+
+// spreadReplicas assigns replicas to eligible nodes in plan (parallel to
+// nodes by ID) and returns how many could not be placed.
+func spreadReplicas(plan []PlacementNode, nodes []swarm.Node, replicas, maxPerNode uint64, spread string) uint64 {
+	byID := make(map[string]swarm.Node, len(nodes))
+	for _, n := range nodes {
+		byID[n.ID] = n
+	}
+	group := func(pn PlacementNode) string {
+		if spread == "" {
+			return ""
+		}
+		v, _ := nodeAttr(byID[pn.ID], spread)
+		return v
+	}
+	groupTasks := make(map[string]uint64)
+
+	var unplaced uint64
+	for range replicas {
+		best := -1
+		for i, pn := range plan {
+			if !pn.Eligible || (maxPerNode > 0 && uint64(pn.PlannedTasks) >= maxPerNode) {
+				continue
+			}
+			if best < 0 {
+				best = i
+				continue
+			}
+			b := plan[best]
+			gi, gb := groupTasks[group(pn)], groupTasks[group(b)]
+			switch {
+			case gi != gb:
+				if gi < gb {
+					best = i
+				}
+			case pn.PlannedTasks != b.PlannedTasks:
+				if pn.PlannedTasks < b.PlannedTasks {
+					best = i
+				}
+			case pn.CurrentTasks > b.CurrentTasks:
+				// Prefer nodes already running the service, as swarm
+				// replaces tasks in place where it can.
+				best = i
+			}
+		}
+		if best < 0 {
+			unplaced++
+			continue
+		}
+		plan[best].PlannedTasks++
+		groupTasks[group(plan[best])]++
+	}
+	return unplaced
+}
+
+// PreviewServicePlacement loads a service, its tasks and the swarm's nodes,
+// and previews where tasks run once it is updated to targetImage (or its
+// current image when empty). When the image's platforms cannot be read from
+// the registry the architecture check is skipped and a warning says so.
+func (u *Updater) PreviewServicePlacement(ctx context.Context, serviceID, targetImage string) (ServicePlacement, error) {
+	svc, err := u.docker.InspectService(ctx, serviceID)
+	if err != nil {
+		return ServicePlacement{}, fmt.Errorf("inspect service: %w", err)
+	}
+	nodes, err := u.docker.ListNodes(ctx)
+	if err != nil {
+		return ServicePlacement{}, fmt.Errorf("list nodes: %w", err)
+	}
+	tasks, err := u.docker.ListServiceTasks(ctx, serviceID)
+	if err != nil {
+		return ServicePlacement{}, fmt.Errorf("list tasks: %w", err)
+	}
+
+	image := targetImage
+	if image == "" {
+		image = svc.Spec.TaskTemplate.ContainerSpec.Image
+	}
+	platforms, perr := u.fetchImagePlatforms(ctx, image)
+
+	plan := PlanServicePlacement(svc, image, nodes, tasks, platforms)
+	if perr != nil {
+		plan.Warnings = append(plan.Warnings, "could not read image platforms, architecture check skipped: "+perr.Error())
+	}
+	return plan, nil
+}
+
+// fetchImagePlatforms reads the platforms an image is published for, using
+// stored registry credentials when there are any.
+func (u *Updater) fetchImagePlatforms(ctx context.Context, image string) ([]registry.Platform, error) {
+	if u.imagePlatforms != nil {
+		return u.imagePlatforms(ctx, image)
+	}
+	token, host, cred, err := u.registryAuth(ctx, image)
+	if err != nil {
+		return nil, err
+	}
+	return registry.FetchImagePlatforms(ctx, image, token, host, cred)
+}
+
+// registryAuth returns the registry host of image with a token and any
+// stored credentials for reading its manifests.
+func (u *Updater) registryAuth(ctx context.Context, image string) (token, host string, cred *registry.RegistryCredential, err error) {
+	host = registry.RegistryHost(image)
+	if u.checker != nil && u.checker.CredentialStore() != nil {
+		if creds, cerr := u.checker.CredentialStore().GetRegistryCredentials(); cerr == nil {
+			cred = registry.FindByRegistry(creds, host)
+		}
+	}
+	token, err = registry.FetchToken(ctx, registry.RepoPath(image), cred, host)
+	if err != nil {
+		return "", host, cred, fmt.Errorf("fetch token: %w", err)
+	}
+	return token, host, cred, nil
+}
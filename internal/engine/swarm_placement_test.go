@@ -0,0 +1,180 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/registry"
+	"github.com/moby/moby/api/types/swarm"
+)
+
+func testNode(id, host, role, arch string, labels map[string]string) swarm.Node {
+	n := swarm.Node{ID: id}
+	n.Spec.Role = swarm.NodeRole(role)
+	n.Spec.Availability = swarm.NodeAvailabilityActive
+	n.Spec.Labels = labels
+	n.Description.Hostname = host
+	n.Description.Platform = swarm.Platform{OS: "linux", Architecture: arch}
+	n.Status.State = swarm.NodeStateReady
+	return n
+}
+
+func replicatedService(name string, replicas uint64, placement *swarm.Placement) swarm.Service {
+	svc := swarm.Service{ID: name + "-id"}
+	svc.Spec.Name = name
+	svc.Spec.Mode.Replicated = &swarm.ReplicatedService{Replicas: &replicas}
+	svc.Spec.TaskTemplate.Placement = placement
+	svc.Spec.TaskTemplate.ContainerSpec = &swarm.ContainerSpec{Image: "acme/web:1.0"}
+	return svc
+}
+
+func planned(plan ServicePlacement) map[string]int {
+	out := make(map[string]int)
+	for _, n := range plan.Nodes {
+		if n.PlannedTasks > 0 {
+			out[n.Hostname] = n.PlannedTasks
+		}
+	}
+	return out
+}
+
+func hasWarning(plan ServicePlacement, substr string) bool {
+	for _, w := range plan.Warnings {
+		if strings.Contains(w, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestPlanServicePlacement_Constraints(t *testing.T) {
+	drained := testNode("n4", "drained", "worker", "x86_64", map[string]string{"tier": "web"})
+	drained.Spec.Availability = swarm.NodeAvailabilityDrain
+	nodes := []swarm.Node{
+		testNode("n1", "mgr", "manager", "x86_64", nil),
+		testNode("n2", "web-a", "worker", "x86_64", map[string]string{"tier": "web"}),
+		testNode("n3", "web-b", "worker", "x86_64", map[string]string{"tier": "WEB"}),
+		drained,
+	}
+	svc := replicatedService("web", 4, &swarm.Placement{Constraints: []string{"node.role!=manager", "node.labels.tier==web"}})
+	tasks := []swarm.Task{{NodeID: "n1", DesiredState: swarm.TaskStateRunning}}
+
+	plan := PlanServicePlacement(svc, "acme/web:1.1", nodes, tasks, nil)
+
+	if got := planned(plan); got["web-a"] != 2 || got["web-b"] != 2 || len(got) != 2 {
+		t.Errorf("planned = %v, want 2 each on web-a and web-b", got)
+	}
+	for _, n := range plan.Nodes {
+		switch n.Hostname {
+		case "mgr":
+			if n.Eligible || !strings.Contains(n.Reason, "node.role!=manager") {
+				t.Errorf("mgr: eligible=%v reason=%q", n.Eligible, n.Reason)
+			}
+		case "drained":
+			if n.Eligible || n.Reason != "availability is drain" {
+				t.Errorf("drained: eligible=%v reason=%q", n.Eligible, n.Reason)
+			}
+		}
+	}
+	if !hasWarning(plan, "1 running task(s) on mgr must move") {
+		t.Errorf("warnings = %v, want a move warning for mgr", plan.Warnings)
+	}
+}
+
+func TestPlanServicePlacement_ImageArchitecture(t *testing.T) {
+	nodes := []swarm.Node{
+		testNode("n1", "amd", "worker", "x86_64", nil),
+		testNode("n2", "pi", "worker", "aarch64", nil),
+	}
+	svc := replicatedService("web", 2, nil)
+	platforms := []registry.Platform{{OS: "linux", Architecture: "amd64"}}
+
+	plan := PlanServicePlacement(svc, "acme/web:1.1", nodes, nil, platforms)
+
+	if got := planned(plan); got["amd"] != 2 || got["pi"] != 0 {
+		t.Errorf("planned = %v, want both replicas on amd", got)
+	}
+	if !hasWarning(plan, "image has no linux/arm64 build on pi") {
+		t.Errorf("warnings = %v, want an architecture warning for pi", plan.Warnings)
+	}
+	if len(plan.ImagePlatforms) != 1 || plan.ImagePlatforms[0] != "linux/amd64" {
+		t.Errorf("image platforms = %v", plan.ImagePlatforms)
+	}
+
+	// No node can run the image at all.
+	plan = PlanServicePlacement(svc, "acme/web:1.1", nodes[1:], nil, platforms)
+	if !hasWarning(plan, "no node satisfies") {
+		t.Errorf("warnings = %v, want a pending warning", plan.Warnings)
+	}
+}
+
+func TestPlanServicePlacement_SpreadAndMaxReplicas(t *testing.T) {
+	nodes := []swarm.Node{
+		testNode("n1", "a1", "worker", "x86_64", map[string]string{"zone": "a"}),
+		testNode("n2", "a2", "worker", "x86_64", map[string]string{"zone": "a"}),
+		testNode("n3", "b1", "worker", "x86_64", map[string]string{"zone": "b"}),
+	}
+	svc := replicatedService("web", 4, &swarm.Placement{
+		Preferences: []swarm.PlacementPreference{{Spread: &swarm.SpreadOver{SpreadDescriptor: "node.labels.zone"}}},
+	})
+
+	// Two replicas per zone: b1 takes both of zone b's.
+	if got := planned(PlanServicePlacement(svc, "", nodes, nil, nil)); got["b1"] != 2 || got["a1"]+got["a2"] != 2 {
+		t.Errorf("spread planned = %v", got)
+	}
+
+	svc = replicatedService("web", 5, &swarm.Placement{MaxReplicas: 1})
+	plan := PlanServicePlacement(svc, "", nodes, nil, nil)
+	if got := planned(plan); len(got) != 3 {
+		t.Errorf("max replicas planned = %v, want one per node", got)
+	}
+	if !hasWarning(plan, "2 of 5 replicas cannot be placed") {
+		t.Errorf("warnings = %v", plan.Warnings)
+	}
+}
+
+func TestPlanServicePlacement_Global(t *testing.T) {
+	down := testNode("n3", "down", "worker", "x86_64", nil)
+	down.Status.State = swarm.NodeStateDown
+	nodes := []swarm.Node{testNode("n1", "a", "manager", "x86_64", nil), testNode("n2", "b", "worker", "x86_64", nil), down}
+	svc := replicatedService("agent", 0, nil)
+	svc.Spec.Mode = swarm.ServiceMode{Global: &swarm.GlobalService{}}
+
+	plan := PlanServicePlacement(svc, "", nodes, nil, nil)
+	if plan.Mode != "global" {
+		t.Errorf("mode = %q", plan.Mode)
+	}
+	if got := planned(plan); got["a"] != 1 || got["b"] != 1 || got["down"] != 0 {
+		t.Errorf("planned = %v", got)
+	}
+}
+
+func TestPreviewServicePlacement(t *testing.T) {
+	mock := newMockDocker()
+	svc := replicatedService("web", 2, nil)
+	mock.inspectService[svc.ID] = svc
+	mock.nodes = []swarm.Node{testNode("n1", "amd", "worker", "x86_64", nil)}
+	u, _ := newTestUpdater(t, mock)
+
+	var fetched string
+	u.imagePlatforms = func(_ context.Context, image string) ([]registry.Platform, error) {
+		fetched = image
+		return nil, errors.New("unauthorised")
+	}
+
+	plan, err := u.PreviewServicePlacement(context.Background(), svc.ID, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fetched != "acme/web:1.0" || plan.Image != "acme/web:1.0" {
+		t.Errorf("image = %q (fetched %q), want the current image", plan.Image, fetched)
+	}
+	if !hasWarning(plan, "architecture check skipped") {
+		t.Errorf("warnings = %v", plan.Warnings)
+	}
+	if got := planned(plan); got["amd"] != 2 {
+		t.Errorf("planned = %v", got)
+	}
+}
@@ -13,9 +13,11 @@ import (
 	"github.com/Will-Luck/Docker-Sentinel/internal/events"
 	"github.com/Will-Luck/Docker-Sentinel/internal/guardian"
 	"github.com/Will-Luck/Docker-Sentinel/internal/hooks"
+	"github.com/Will-Luck/Docker-Sentinel/internal/lifecycle"
 	"github.com/Will-Luck/Docker-Sentinel/internal/metrics"
 	"github.com/Will-Luck/Docker-Sentinel/internal/notify"
 	portainerPkg "github.com/Will-Luck/Docker-Sentinel/internal/portainer"
+	"github.com/Will-Luck/Docker-Sentinel/internal/registry"
 	"github.com/Will-Luck/Docker-Sentinel/internal/scanner"
 	"github.com/Will-Luck/Docker-Sentinel/internal/store"
 	"github.com/Will-Luck/Docker-Sentinel/internal/verify"
@@ -23,27 +25,21 @@ import (
 	"github.com/moby/moby/api/types/network"
 )
 
-// UpdateContainer performs the full update lifecycle for a single container:
+// recreate performs the full update lifecycle for a single container:
 // snapshot → pull → stop → remove → create → start → validate → (rollback on failure).
-// Returns ErrUpdateInProgress if the container already has an update running.
+// It backs the recreate and blue-green strategies; the caller holds the
+// container's update lock and has already inspected it.
 //
-// targetImage overrides the image to pull for semver version bumps (e.g.
+// t.TargetImage overrides the image to pull for semver version bumps (e.g.
 // "dxflrs/garage:v2.2.0"). When empty, the current image tag is re-pulled
 // (correct for :latest-style updates where the tag is mutable).
-func (u *Updater) UpdateContainer(ctx context.Context, id, name, targetImage string) error {
-	if !u.tryLock(name) {
-		return ErrUpdateInProgress
-	}
-	defer u.unlock(name)
-
+func (u *Updater) recreate(ctx context.Context, t UpdateTarget, opts recreateOptions) error {
+	id, name, targetImage, inspect := t.ID, t.Name, t.TargetImage, t.Inspect
 	start := u.clock.Now()
+	stages := make(map[string]time.Duration)
 
-	// 1. Inspect and snapshot the current container.
-	inspect, err := u.docker.InspectContainer(ctx, id)
-	if err != nil {
-		return fmt.Errorf("inspect %s: %w", name, err)
-	}
-
+	// 1. Snapshot the current container.
+	u.setUpdatePhase(ctx, "snapshot")
 	snapshotData, err := json.Marshal(inspect)
 	if err != nil {
 		return fmt.Errorf("marshal snapshot for %s: %w", name, err)
@@ -55,21 +51,33 @@ func (u *Updater) UpdateContainer(ctx context.Context, id, name, targetImage str
 	if inspect.Config == nil {
 		return fmt.Errorf("inspect %s: container config is nil", name)
 	}
+	limits := captureLimits(inspect.HostConfig)
 
 	oldImage := inspect.Config.Image
 	oldImageID := inspect.Image // full image ID for cleanup
+	// A tracked tag@digest reference is re-pulled by tag; the pinned digest
+	// would only bring back the image already running.
+	if targetImage == "" {
+		if ref, digest, _ := checkRef(oldImage, inspect.Config.Labels); digest != "" {
+			targetImage = ref
+		}
+	}
 	// Determine which image to pull: targetImage (semver bump) or oldImage (mutable tag re-pull).
 	pullImage := oldImage
 	if targetImage != "" {
 		pullImage = targetImage
 	}
+	oldVersion, newVersion := u.updateVersions(name, targetImage)
+	cause := rollbackCause{oldImage: oldImage, oldVersion: oldVersion, newVersion: newVersion}
 	u.log.Info("saved snapshot", "name", name, "image", oldImage)
 	u.publishEvent(events.EventContainerUpdate, name, "update started")
 
-	u.notifier.Notify(ctx, notify.Event{
+	u.notifyAttempt(ctx, notify.Event{
 		Type:          notify.EventUpdateStarted,
 		ContainerName: name,
 		OldImage:      oldImage,
+		OldVersion:    oldVersion,
+		NewVersion:    newVersion,
 		Timestamp:     u.clock.Now(),
 	})
 
@@ -79,6 +87,7 @@ func (u *Updater) UpdateContainer(ctx context.Context, id, name, targetImage str
 	}
 
 	// 2.5. Run pre-update hooks.
+	u.setUpdatePhase(ctx, "pre-update hooks")
 	if u.hooks != nil && u.cfg.HooksEnabled() {
 		if err := u.hooks.RunPreUpdate(ctx, id, name); err != nil {
 			if errors.Is(err, hooks.ErrSkipUpdate) {
@@ -101,13 +110,33 @@ func (u *Updater) UpdateContainer(ctx context.Context, id, name, targetImage str
 	}
 
 	// 3. Pull the new image.
+	u.setUpdatePhase(ctx, "pull")
 	u.log.Info("pulling image", "name", name, "image", pullImage)
-	if err := u.docker.PullImage(ctx, pullImage); err != nil {
+	pullStart := u.clock.Now()
+	if err := u.pullImage(ctx, name, pullImage, inspect.Config.Labels); err != nil {
 		if mErr := u.store.SetMaintenance(name, false); mErr != nil {
 			u.log.Warn("failed to clear maintenance flag after pull failure", "name", name, "error", mErr)
 		}
+		stages["pull"] = u.clock.Since(pullStart)
+		if recErr := u.store.RecordUpdate(store.UpdateRecord{
+			Timestamp:     u.clock.Now(),
+			ContainerName: name,
+			OldImage:      oldImage,
+			OldDigest:     extractDigestForRecord(inspect),
+			NewImage:      pullImage,
+			Outcome:       "failed",
+			Duration:      u.clock.Since(start),
+			Error:         err.Error(),
+			FailureClass:  store.FailurePullError,
+			Stages:        stages,
+			Comment:       approvalComment(ctx),
+		}); recErr != nil {
+			u.log.Warn("failed to persist pull failure record", "name", name, "error", recErr)
+		}
+		metrics.UpdatesTotal.WithLabelValues("failed").Inc()
 		return fmt.Errorf("pull image for %s: %w", name, err)
 	}
+	stages["pull"] = u.clock.Since(pullStart)
 
 	// Get new image digest for the record.
 	newDigest, err := u.docker.ImageDigest(ctx, pullImage)
@@ -132,6 +161,8 @@ func (u *Updater) UpdateContainer(ctx context.Context, id, name, targetImage str
 			NewDigest:     newImageID,
 			Outcome:       "identical",
 			Duration:      duration,
+			Stages:        stages,
+			Comment:       approvalComment(ctx),
 		})
 		// Cache the digest pair that the next scan will actually compare:
 		// post-pull repo digest (ImageDigest) vs registry manifest digest
@@ -151,26 +182,45 @@ func (u *Updater) UpdateContainer(ctx context.Context, id, name, targetImage str
 		return nil
 	}
 
-	// 3.6 Signature verification gate.
-	if u.imgVerifier != nil && u.verifyMode != verify.ModeDisabled {
-		effectiveMode := verify.ResolveMode(
-			inspect.Config.Labels[verify.ContainerLabel], "", u.verifyMode,
-		)
-		if effectiveMode != verify.ModeDisabled {
-			result := u.imgVerifier.Verify(ctx, pullImage)
-			if !result.Verified {
-				if effectiveMode == verify.ModeEnforce {
-					u.log.Warn("signature verification failed, blocking update",
-						"name", name, "image", pullImage, "error", result.Error)
-					_ = u.store.SetMaintenance(name, false)
-					return fmt.Errorf("signature verification failed for %s: %s", name, result.Error)
-				}
-				// Warn mode: log and proceed.
-				u.log.Warn("signature verification failed, proceeding (warn mode)",
+	// 3.6 Signature verification gate. The sentinel.verify label can turn
+	// verification on for one container even when the global mode is off.
+	if effectiveMode := verify.ResolveMode(inspect.Config.Labels[verify.ContainerLabel], "", u.verifyMode); effectiveMode != verify.ModeDisabled {
+		result := &verify.Result{Error: "cosign is not available"}
+		if dv, ok := u.imgVerifier.(DigestVerifier); ok {
+			result = dv.VerifyDigest(ctx, pullImage, newDigest)
+		} else if u.imgVerifier != nil {
+			result = u.imgVerifier.Verify(ctx, pullImage)
+		}
+		if !result.Verified {
+			if effectiveMode == verify.ModeEnforce {
+				u.log.Warn("signature verification failed, blocking update",
 					"name", name, "image", pullImage, "error", result.Error)
-			} else {
-				u.log.Info("signature verified", "name", name, "image", pullImage)
+				_ = u.store.SetMaintenance(name, false)
+				err := fmt.Errorf("signature verification failed for %s: %s", name, result.Error)
+				if recErr := u.store.RecordUpdate(store.UpdateRecord{
+					Timestamp:     u.clock.Now(),
+					ContainerName: name,
+					OldImage:      oldImage,
+					OldDigest:     extractDigestForRecord(inspect),
+					NewImage:      pullImage,
+					NewDigest:     newDigest,
+					Outcome:       "failed",
+					Duration:      u.clock.Since(start),
+					Error:         err.Error(),
+					FailureClass:  store.FailureSignature,
+					Stages:        stages,
+					Comment:       approvalComment(ctx),
+				}); recErr != nil {
+					u.log.Warn("failed to persist signature failure record", "name", name, "error", recErr)
+				}
+				metrics.UpdatesTotal.WithLabelValues("failed").Inc()
+				return err
 			}
+			// Warn mode: log and proceed.
+			u.log.Warn("signature verification failed, proceeding (warn mode)",
+				"name", name, "image", pullImage, "error", result.Error)
+		} else {
+			u.log.Info("signature verified", "name", name, "image", pullImage, "key", result.Key, "cached", result.Cached)
 		}
 	}
 
@@ -181,23 +231,100 @@ func (u *Updater) UpdateContainer(ctx context.Context, id, name, targetImage str
 			// Scan tool failure should not block updates.
 			u.log.Warn("pre-update scan failed, proceeding",
 				"name", name, "image", pullImage, "error", scanErr)
+		} else if scanResult.ExceedsThreshold(u.severityThresh) && scanApproved(ctx) {
+			u.log.Warn("pre-update scan found vulnerabilities above threshold, proceeding with approval",
+				"name", name, "image", pullImage,
+				"critical", scanResult.Summary.Critical,
+				"high", scanResult.Summary.High,
+				"threshold", string(u.severityThresh))
 		} else if scanResult.ExceedsThreshold(u.severityThresh) {
-			u.log.Warn("pre-update scan found vulnerabilities above threshold, blocking update",
+			u.log.Warn("pre-update scan found vulnerabilities above threshold, queued for approval",
 				"name", name, "image", pullImage,
 				"critical", scanResult.Summary.Critical,
 				"high", scanResult.Summary.High,
 				"threshold", string(u.severityThresh))
 			_ = u.store.SetMaintenance(name, false)
-			return fmt.Errorf("pre-update scan blocked %s: %d critical, %d high vulnerabilities",
-				name, scanResult.Summary.Critical, scanResult.Summary.High)
+			u.queueScanBlocked(id, name, targetImage, inspect, scanResult)
+			return fmt.Errorf("%w: %s: %d critical, %d high vulnerabilities",
+				ErrScanBlocked, name, scanResult.Summary.Critical, scanResult.Summary.High)
 		} else {
 			u.log.Info("pre-update scan passed",
 				"name", name, "image", pullImage, "total_vulns", scanResult.Summary.Total)
 		}
 	}
 
+	// 3.8. Blue/green pre-flight: prove the new image starts and validates
+	// next to the old container before the old one is touched.
+	if opts.preflight {
+		if err := u.preflightCandidate(ctx, t, pullImage, start, stages); err != nil {
+			if mErr := u.store.SetMaintenance(name, false); mErr != nil {
+				u.log.Warn("failed to clear maintenance flag after pre-flight failure", "name", name, "error", mErr)
+			}
+			return err
+		}
+	}
+
+	// 3.9. Journal the update so a crash from here on can be resumed or
+	// rolled back on the next start (see RecoverInterrupted). The entry is
+	// removed when we return, unless we stop early for a shutdown.
+	journal := store.UpdateJournal{
+		Name:           name,
+		OldContainerID: id,
+		OldImage:       oldImage,
+		TargetImage:    pullImage,
+		Snapshot:       snapshotData,
+		StartedAt:      start,
+	}
+	u.writeJournal(&journal, store.JournalPulled)
+	keepJournal := false
+	defer func() {
+		if !keepJournal {
+			if err := u.store.DeleteJournal(name); err != nil {
+				u.log.Warn("failed to clear update journal", "name", name, "error", err)
+			}
+		}
+	}()
+
+	newConfig := cloneConfig(inspect.Config)
+	if targetImage != "" {
+		newConfig.Image = targetImage
+	}
+	hostConfig := inspect.HostConfig
+	limits.applyTo(hostConfig)
+	netConfig := rebuildNetworkingConfig(inspect.NetworkSettings)
+
+	// 3.95. Blue/green swap: create the replacement next to the old container
+	// so the cut-over below is just stop, rename and start. The candidate
+	// already passed validation, so it goes in without the maintenance label
+	// and needs no finalise recreate.
+	var newID string
+	if opts.swap {
+		u.setUpdatePhase(ctx, "create")
+		createStart := u.clock.Now()
+		u.removeLeftover(ctx, name+replacementSuffix)
+		newID, err = u.docker.CreateContainer(ctx, name+replacementSuffix, newConfig, hostConfig, netConfig)
+		stages["create"] = u.clock.Since(createStart)
+		if err != nil {
+			if mErr := u.store.SetMaintenance(name, false); mErr != nil {
+				u.log.Warn("failed to clear maintenance flag after create failure", "name", name, "error", mErr)
+			}
+			return fmt.Errorf("create replacement for %s: %w", name, err)
+		}
+	} else {
+		addMaintenanceLabel(newConfig)
+	}
+
 	// 4. Stop and remove the old container.
+	u.setUpdatePhase(ctx, "stop")
+
+	// From here until the replacement is running the container is down, so
+	// cancellation (e.g. SIGTERM) must not abort us half-way. Detach the
+	// context; Drain waits for us instead. shutdownCtx still cuts the grace
+	// period short, once the new container is already up.
+	shutdownCtx := ctx
+	ctx = context.WithoutCancel(ctx)
 	u.log.Info("stopping old container", "name", name)
+	stopStart := u.clock.Now()
 	if err := u.docker.StopContainer(ctx, id, 30); err != nil {
 		u.log.Warn("stop failed, proceeding with force remove", "name", name, "error", err)
 	}
@@ -209,69 +336,94 @@ func (u *Updater) UpdateContainer(ctx context.Context, id, name, targetImage str
 		removeErr = u.docker.RemoveContainer(ctx, id)
 	}
 	if removeErr != nil {
+		if newID != "" {
+			_ = u.docker.RemoveContainer(ctx, newID)
+		}
 		if mErr := u.store.SetMaintenance(name, false); mErr != nil {
 			u.log.Warn("failed to clear maintenance flag after remove failure", "name", name, "error", mErr)
 		}
 		return fmt.Errorf("remove old container %s: %w", name, removeErr)
 	}
-
-	// 5. Create and start the new container.
-	newConfig := cloneConfig(inspect.Config)
-	if targetImage != "" {
-		newConfig.Image = targetImage
-	}
-	addMaintenanceLabel(newConfig)
-
-	hostConfig := inspect.HostConfig
-	netConfig := rebuildNetworkingConfig(inspect.NetworkSettings)
-
-	u.log.Info("creating new container", "name", name, "image", pullImage)
-	newID, err := u.docker.CreateContainer(ctx, name, newConfig, hostConfig, netConfig)
-	if err != nil {
-		u.log.Error("create failed, rolling back", "name", name, "error", err)
-		u.doRollback(ctx, name, snapshotData, start)
-		return fmt.Errorf("create new container %s: %w", name, err)
+	stages["stop"] = u.clock.Since(stopStart)
+	u.writeJournal(&journal, store.JournalRemoved)
+
+	// 5. Create (or rename the swapped-in replacement) and start the new container.
+	u.setUpdatePhase(ctx, "create")
+	createStart := u.clock.Now()
+	if opts.swap {
+		u.log.Info("swapping in blue-green replacement", "name", name, "image", pullImage)
+		if err := u.docker.RenameContainer(ctx, newID, name); err != nil {
+			u.log.Error("rename failed, rolling back", "name", name, "error", err)
+			_ = u.docker.RemoveContainer(ctx, newID)
+			stages["create"] += u.clock.Since(createStart)
+			cause.class, cause.stage, cause.reason = store.FailureStartError, "create", "rename replacement: "+err.Error()
+			u.doRollback(ctx, name, snapshotData, start, cause, stages)
+			return fmt.Errorf("rename replacement for %s: %w", name, err)
+		}
+	} else {
+		u.log.Info("creating new container", "name", name, "image", pullImage)
+		newID, err = u.docker.CreateContainer(ctx, name, newConfig, hostConfig, netConfig)
+		if err != nil {
+			u.log.Error("create failed, rolling back", "name", name, "error", err)
+			stages["create"] = u.clock.Since(createStart)
+			cause.class, cause.stage, cause.reason = store.FailureStartError, "create", "create failed: "+err.Error()
+			u.doRollback(ctx, name, snapshotData, start, cause, stages)
+			return fmt.Errorf("create new container %s: %w", name, err)
+		}
 	}
+	journal.NewContainerID = newID
+	u.writeJournal(&journal, store.JournalCreated)
 
 	if err := u.docker.StartContainer(ctx, newID); err != nil {
 		u.log.Error("start failed, rolling back", "name", name, "error", err)
 		// Clean up the failed new container, then rollback.
 		_ = u.docker.RemoveContainer(ctx, newID)
-		u.doRollback(ctx, name, snapshotData, start)
+		stages["create"] += u.clock.Since(createStart)
+		cause.class, cause.stage, cause.reason = store.FailureStartError, "start", "start failed: "+err.Error()
+		u.doRollback(ctx, name, snapshotData, start, cause, stages)
 		return fmt.Errorf("start new container %s: %w", name, err)
 	}
+	stages["create"] += u.clock.Since(createStart)
+	u.writeJournal(&journal, store.JournalStarted)
 
 	// 6. Wait grace period and validate.
-	gracePeriod := u.cfg.GracePeriod()
-	if override := docker.ContainerGracePeriod(inspect.Config.Labels); override > 0 {
-		gracePeriod = override
-	}
-	u.log.Info("waiting grace period", "name", name, "duration", gracePeriod)
+	u.setUpdatePhase(ctx, "validate")
+	plan := u.validationPlanFor(name, inspect.Config.Labels)
+	u.log.Info("waiting grace period", "name", name, "duration", plan.grace, "strategy", plan.strategy)
+	validateStart := u.clock.Now()
 	select {
-	case <-u.clock.After(gracePeriod):
-	case <-ctx.Done():
-		return ctx.Err()
+	case <-u.clock.After(plan.grace):
+	case <-shutdownCtx.Done():
+		// New container is running; leave the maintenance flag set so
+		// RecoverInterrupted reconciles it on the next start.
+		u.log.Warn("shutdown during validation, new container left running", "name", name)
+		keepJournal = true
+		return shutdownCtx.Err()
 	}
 
-	healthy, err := u.validateContainer(ctx, newID)
+	healthy, err := u.validateContainer(ctx, newID, plan)
+	stages["validate"] = u.clock.Since(validateStart)
 	if err != nil || !healthy {
 		u.log.Error("validation failed, rolling back", "name", name, "error", err)
 		u.publishEvent(events.EventContainerUpdate, name, "update failed")
-		u.notifier.Notify(ctx, notify.Event{
-			Type:          notify.EventUpdateFailed,
-			ContainerName: name,
-			OldImage:      oldImage,
-			Error:         fmt.Sprintf("validation failed: %v", err),
-			Timestamp:     u.clock.Now(),
-		})
 		metrics.UpdatesTotal.WithLabelValues("failed").Inc()
 		_ = u.docker.StopContainer(ctx, newID, 10)
 		_ = u.docker.RemoveContainer(ctx, newID)
-		u.doRollback(ctx, name, snapshotData, start)
+		cause.class, cause.stage, cause.reason = store.FailureHealthFailed, "validate", "validation failed"
+		if isValidationTimeout(err) {
+			cause.class = store.FailureValidationTimeout
+		}
+		if err != nil {
+			cause.reason += ": " + err.Error()
+		}
+		u.doRollback(ctx, name, snapshotData, start, cause, stages)
 		return fmt.Errorf("new container %s failed validation", name)
 	}
 
+	lifecycleFrom(ctx).emit(lifecycle.StateValidated, "")
+
 	// 6.5. Run post-update hooks.
+	u.setUpdatePhase(ctx, "post-update hooks")
 	if u.hooks != nil && u.cfg.HooksEnabled() {
 		if err := u.hooks.RunPostUpdate(ctx, newID, name); err != nil {
 			u.log.Warn("post-update hook failed", "name", name, "error", err)
@@ -279,7 +431,10 @@ func (u *Updater) UpdateContainer(ctx context.Context, id, name, targetImage str
 	}
 
 	// 7. Remove maintenance label for Guardian compatibility.
+	u.setUpdatePhase(ctx, "finalise")
+	finaliseStart := u.clock.Now()
 	finaliseNewID, finaliseErr := u.finaliseContainer(ctx, newID, name)
+	stages["finalise"] = u.clock.Since(finaliseStart)
 	if finaliseErr != nil {
 		var fErr *finaliseError
 		if errors.As(finaliseErr, &fErr) && finaliseStageIsDestructive(fErr.stage) {
@@ -296,7 +451,8 @@ func (u *Updater) UpdateContainer(ctx context.Context, id, name, targetImage str
 				}
 			}
 
-			u.doRollback(ctx, name, snapshotData, start)
+			cause.class, cause.stage, cause.reason = store.FailureFinaliseDestructive, "finalise "+fErr.stage, finaliseErr.Error()
+			u.doRollback(ctx, name, snapshotData, start, cause, stages)
 
 			// Record as failed — never as success.
 			duration := u.clock.Since(start)
@@ -310,18 +466,14 @@ func (u *Updater) UpdateContainer(ctx context.Context, id, name, targetImage str
 				Outcome:       "failed",
 				Duration:      duration,
 				Error:         finaliseErr.Error(),
+				FailureClass:  store.FailureFinaliseDestructive,
+				Stages:        stages,
+				Comment:       approvalComment(ctx),
 			}); recErr != nil {
 				u.log.Warn("failed to persist finalise failure record", "name", name, "error", recErr)
 			}
 
 			u.publishEvent(events.EventContainerUpdate, name, "finalise failed — rollback attempted")
-			u.notifier.Notify(ctx, notify.Event{
-				Type:          notify.EventUpdateFailed,
-				ContainerName: name,
-				OldImage:      oldImage,
-				Error:         finaliseErr.Error(),
-				Timestamp:     u.clock.Now(),
-			})
 			return finaliseErr
 		}
 
@@ -346,6 +498,8 @@ func (u *Updater) UpdateContainer(ctx context.Context, id, name, targetImage str
 			Outcome:       "partial",
 			Duration:      duration,
 			Error:         finaliseErr.Error(),
+			Stages:        stages,
+			Comment:       approvalComment(ctx),
 		}); recErr != nil {
 			u.log.Warn("failed to persist finalise warning record", "name", name, "error", recErr)
 		}
@@ -354,8 +508,8 @@ func (u *Updater) UpdateContainer(ctx context.Context, id, name, targetImage str
 		return finaliseErr
 	}
 
-	// 8. Success — clear maintenance and record.
-	_ = finaliseNewID // new ID tracked internally; not needed further
+	// 8. Success — check resource limits survived, clear maintenance and record.
+	drift := u.verifyLimits(ctx, finaliseNewID, name, limits)
 	if err := u.store.SetMaintenance(name, false); err != nil {
 		u.log.Warn("failed to clear maintenance flag", "name", name, "error", err)
 	}
@@ -371,6 +525,10 @@ func (u *Updater) UpdateContainer(ctx context.Context, id, name, targetImage str
 		NewDigest:     newDigest,
 		Outcome:       "success",
 		Duration:      duration,
+		Stages:        stages,
+		Preconditions: preconditionsFrom(ctx),
+		Comment:       approvalComment(ctx),
+		ResourceDrift: drift,
 	}); err != nil {
 		u.log.Warn("failed to persist update record", "name", name, "error", err)
 	}
@@ -387,8 +545,12 @@ func (u *Updater) UpdateContainer(ctx context.Context, id, name, targetImage str
 		OldImage:      oldImage,
 		NewImage:      pullImage,
 		NewDigest:     newDigest,
+		OldVersion:    oldVersion,
+		NewVersion:    newVersion,
 		Timestamp:     u.clock.Now(),
 	})
+	u.promoteVersion(name, newVersion)
+	u.clearRetryState(name)
 
 	// Clear notification state so re-detection gets a fresh notification.
 	if err := u.store.ClearNotifyState(name); err != nil {
@@ -412,19 +574,8 @@ func (u *Updater) UpdateContainer(ctx context.Context, id, name, targetImage str
 	}
 
 	// Compose file sync (opt-in).
-	if u.isComposeSync() {
-		labels := inspect.Config.Labels
-		workDir := labels["com.docker.compose.project.working_dir"]
-		configFiles := labels["com.docker.compose.project.config_files"]
-		svcName := labels["com.docker.compose.service"]
-		if workDir != "" && configFiles != "" && svcName != "" {
-			composePath := configFiles
-			if i := strings.Index(composePath, ","); i >= 0 {
-				composePath = composePath[:i]
-			}
-			if !strings.HasPrefix(composePath, "/") {
-				composePath = workDir + "/" + composePath
-			}
+	if u.isComposeSync() && !opts.skipComposeSync {
+		if composePath, svcName, ok := u.composeFileFor(inspect.Config.Labels); ok {
 			if err := UpdateComposeTag(composePath, svcName, pullImage); err != nil {
 				u.log.Warn("compose sync failed", "name", name, "file", composePath, "error", err)
 			} else {
@@ -450,23 +601,8 @@ func (u *Updater) UpdateContainer(ctx context.Context, id, name, targetImage str
 	u.cleanupOldImage(ctx, oldImageID, name)
 
 	// 12. Restart dependents (dependency-aware).
-	if u.deps != nil && u.cfg.DependencyAware() {
-		for _, dep := range u.deps.Dependents(name) {
-			depContainers, err := u.docker.ListContainers(ctx)
-			if err != nil {
-				u.log.Warn("deps: failed to list containers", "error", err)
-				break
-			}
-			for _, dc := range depContainers {
-				if containerName(dc) == dep {
-					u.log.Info("restarting dependent container", "dependent", dep, "provider", name)
-					if err := u.docker.RestartContainer(ctx, dc.ID); err != nil {
-						u.log.Warn("failed to restart dependent", "dependent", dep, "error", err)
-					}
-					break
-				}
-			}
-		}
+	if u.cfg.DependencyAware() {
+		u.restartDependents(ctx, name)
 	}
 
 	u.log.Info("update complete", "name", name, "duration", duration)
@@ -474,9 +610,25 @@ func (u *Updater) UpdateContainer(ctx context.Context, id, name, targetImage str
 	return nil
 }
 
-// validateContainer checks that a container is running, not restarting, and
-// healthy (if a healthcheck is defined).
-func (u *Updater) validateContainer(ctx context.Context, id string) (bool, error) {
+// validateContainer checks that a container is running and not restarting,
+// then applies the plan's strategy: by default the healthcheck is honoured
+// when one is defined. A container that passes is then handed to the
+// external validators, if any are configured.
+func (u *Updater) validateContainer(ctx context.Context, id string, plan validationPlan) (bool, error) {
+	ok, err := u.checkContainer(ctx, id, plan)
+	if !ok || err != nil || (plan.webhook == "" && plan.command == "") {
+		return ok, err
+	}
+	inspect, err := u.docker.InspectContainer(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	return u.runExternalValidators(ctx, inspect, plan)
+}
+
+// checkContainer runs the built-in validation strategy of plan against the
+// new container.
+func (u *Updater) checkContainer(ctx context.Context, id string, plan validationPlan) (bool, error) {
 	inspect, err := u.docker.InspectContainer(ctx, id)
 	if err != nil {
 		return false, err
@@ -488,6 +640,16 @@ func (u *Updater) validateContainer(ctx context.Context, id string) (bool, error
 	if !state.Running || state.Restarting {
 		return false, nil
 	}
+	switch plan.strategy {
+	case ValidateState:
+		return true, nil
+	case ValidateProbe:
+		return u.waitForProbe(ctx, inspect, plan.probe, plan.expect, plan.healthTimeout)
+	case ValidateHealthcheck:
+		if state.Health == nil || state.Health.Status == "" {
+			return false, errNoHealthcheck
+		}
+	}
 	if state.Health == nil || state.Health.Status == "" {
 		return true, nil
 	}
@@ -497,27 +659,35 @@ func (u *Updater) validateContainer(ctx context.Context, id string) (bool, error
 	case "unhealthy":
 		return false, nil
 	case "starting":
-		return u.waitForHealthy(ctx, id)
+		return u.waitForHealthy(ctx, id, plan.healthTimeout)
 	default:
 		return true, nil
 	}
 }
 
 const healthPollInterval = 2 * time.Second
-const healthPollTimeout = 60 * time.Second
+
+// defaultHealthTimeout bounds the wait for a healthcheck or probe unless the
+// health_timeout setting, the sentinel.health-timeout label or a validation
+// override sets another.
+const defaultHealthTimeout = 60 * time.Second
+
+// errHealthTimeout is returned when a healthcheck is still "starting" after
+// the health timeout. Classified separately from an explicit unhealthy result.
+var errHealthTimeout = errors.New("healthcheck timeout waiting for healthy")
 
 // waitForHealthy polls the container's healthcheck status until it resolves
 // to healthy/unhealthy or the timeout expires.
-func (u *Updater) waitForHealthy(ctx context.Context, id string) (bool, error) {
-	deadline := u.clock.Now().Add(healthPollTimeout)
+func (u *Updater) waitForHealthy(ctx context.Context, id string, timeout time.Duration) (bool, error) {
+	deadline := u.clock.Now().Add(timeout)
 	for {
 		select {
 		case <-ctx.Done():
 			return false, ctx.Err()
 		case <-u.clock.After(healthPollInterval):
 			if u.clock.Now().After(deadline) {
-				u.log.Warn("healthcheck timeout waiting for healthy", "id", id)
-				return false, nil
+				u.log.Warn("healthcheck timeout waiting for healthy", "id", id, "timeout", timeout)
+				return false, errHealthTimeout
 			}
 			inspect, err := u.docker.InspectContainer(ctx, id)
 			if err != nil {
@@ -536,14 +706,49 @@ func (u *Updater) waitForHealthy(ctx context.Context, id string) (bool, error) {
 	}
 }
 
-// doRollback performs a rollback and records the failure.
-func (u *Updater) doRollback(ctx context.Context, name string, snapshotData []byte, start time.Time) {
+// rollbackCause describes the failed update a rollback undoes. It makes up
+// the single notification sent once the rollback settles, in place of
+// separate failure and rollback messages.
+type rollbackCause struct {
+	class      string // store.Failure* classification
+	stage      string // update step that failed, e.g. "validate"
+	reason     string
+	oldImage   string
+	oldVersion string
+	newVersion string
+}
+
+// restoredTo names what the rollback went back to.
+func (c rollbackCause) restoredTo() string {
+	switch {
+	case c.oldVersion != "":
+		return c.oldVersion
+	case c.oldImage != "":
+		return c.oldImage
+	}
+	return "the previous version"
+}
+
+// rollbackHealthPolls bounds how many health polls a rollback notification
+// waits for a restored container whose health check is still starting.
+const rollbackHealthPolls = 15
+
+// doRollback performs a rollback, records the failure and sends one
+// notification covering both: "update failed at <stage>, rolled back to
+// <version>, container healthy", or the rollback error if that failed too.
+func (u *Updater) doRollback(ctx context.Context, name string, snapshotData []byte, start time.Time, cause rollbackCause, stages map[string]time.Duration) {
+	u.setUpdatePhase(ctx, "rollback")
 	if err := rollback(ctx, u.docker, name, snapshotData, u.log); err != nil {
 		u.log.Error("rollback also failed", "name", name, "error", err)
 		u.publishEvent(events.EventContainerUpdate, name, "rollback failed")
+		lifecycleFrom(ctx).emit(lifecycle.StateFailed, "rollback failed: "+err.Error())
 		u.notifier.Notify(ctx, notify.Event{
 			Type:          notify.EventRollbackFailed,
 			ContainerName: name,
+			OldImage:      cause.oldImage,
+			OldVersion:    cause.oldVersion,
+			NewVersion:    cause.newVersion,
+			Message:       fmt.Sprintf("Update failed at %s (%s) and the rollback failed", cause.stage, cause.reason),
 			Error:         err.Error(),
 			Timestamp:     u.clock.Now(),
 		})
@@ -558,9 +763,15 @@ func (u *Updater) doRollback(ctx context.Context, name string, snapshotData []by
 			}
 		}
 		u.publishEvent(events.EventContainerUpdate, name, "rollback succeeded")
-		u.notifier.Notify(ctx, notify.Event{
+		lifecycleFrom(ctx).emit(lifecycle.StateRolledBack, "")
+		u.notifyAttempt(ctx, notify.Event{
 			Type:          notify.EventRollbackOK,
 			ContainerName: name,
+			OldImage:      cause.oldImage,
+			OldVersion:    cause.oldVersion,
+			NewVersion:    cause.newVersion,
+			Message:       fmt.Sprintf("Update failed at %s, rolled back to %s, %s", cause.stage, cause.restoredTo(), u.restoredState(ctx, name)),
+			Error:         cause.reason,
 			Timestamp:     u.clock.Now(),
 		})
 		metrics.UpdatesTotal.WithLabelValues("rollback").Inc()
@@ -574,12 +785,53 @@ func (u *Updater) doRollback(ctx context.Context, name string, snapshotData []by
 		ContainerName: name,
 		Outcome:       "rollback",
 		Duration:      u.clock.Since(start),
-		Error:         "update validation failed",
+		Error:         cause.reason,
+		FailureClass:  cause.class,
+		Stages:        stages,
+		Comment:       approvalComment(ctx),
 	}); err != nil {
 		u.log.Warn("failed to persist rollback record", "name", name, "error", err)
 	}
 }
 
+// restoredState describes the container a rollback recreated, for the
+// rollback notification. A health check that is still starting is polled
+// for a short while so the message can say whether it came up healthy.
+func (u *Updater) restoredState(ctx context.Context, name string) string {
+	id := ""
+	if containers, err := u.docker.ListAllContainers(ctx); err == nil {
+		for _, c := range containers {
+			if containerName(c) == name {
+				id = c.ID
+				break
+			}
+		}
+	}
+	if id == "" {
+		return "container state unknown"
+	}
+	for i := 0; ; i++ {
+		inspect, err := u.docker.InspectContainer(ctx, id)
+		switch {
+		case err != nil:
+			return "container state unknown"
+		case inspect.State == nil || !inspect.State.Running:
+			return "container not running"
+		case inspect.State.Health == nil:
+			return "container running"
+		case inspect.State.Health.Status == "healthy":
+			return "container healthy"
+		case inspect.State.Health.Status != "starting" || i >= rollbackHealthPolls:
+			return "container running, health " + string(inspect.State.Health.Status)
+		}
+		select {
+		case <-ctx.Done():
+			return "container running, health " + string(inspect.State.Health.Status)
+		case <-u.clock.After(healthPollInterval):
+		}
+	}
+}
+
 // backupTag builds the backup image reference for the current image before an update.
 // Format: base-repo:sentinel-backup-20060102-150405
 func (u *Updater) backupTag(imageRef string) string {
@@ -723,7 +975,10 @@ func extractDigestForRecord(inspect container.InspectResponse) string {
 
 // replaceTag replaces the tag portion of an image reference.
 // e.g. replaceTag("dxflrs/garage:v2.1.0", "v2.2.0") → "dxflrs/garage:v2.2.0"
+// and replaceTag("registry:5000/app:v1@sha256:...", "v2") → "registry:5000/app:v2".
 func replaceTag(imageRef, newTag string) string {
+	// A digest pins the old tag's image, so it is dropped with the tag.
+	imageRef, _ = registry.SplitDigest(imageRef)
 	if i := strings.LastIndex(imageRef, ":"); i >= 0 {
 		// Ensure the colon separates repo from tag (not registry:port).
 		candidate := imageRef[i+1:]
@@ -734,6 +989,21 @@ func replaceTag(imageRef, newTag string) string {
 	return imageRef + ":" + newTag
 }
 
+// checkRef returns the reference to check for updates. An image referenced
+// as "repo:tag@sha256:..." is pinned unless the container sets
+// sentinel.track-tag, in which case the tag is checked and digest is the
+// pinned digest to compare it against.
+func checkRef(imageRef string, labels map[string]string) (ref, digest string, pinned bool) {
+	name, digest := registry.SplitDigest(imageRef)
+	if digest == "" {
+		return imageRef, "", false
+	}
+	if !docker.ContainerTrackTag(labels) || registry.ExtractTag(name) == "" {
+		return imageRef, "", true
+	}
+	return name, digest, false
+}
+
 // truncateID safely truncates a container ID to 12 characters for logging.
 func truncateID(id string) string {
 	if len(id) > 12 {
@@ -0,0 +1,234 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/docker"
+	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+)
+
+// defaultStuckUpdateThreshold is how long an update may stay in one phase
+// before it is reported as stuck, unless the stuck_update_threshold setting
+// overrides it.
+const defaultStuckUpdateThreshold = 30 * time.Minute
+
+var (
+	// ErrNotUpdating is returned by ForceUnlock when no update holds the lock.
+	ErrNotUpdating = errors.New("no update in progress")
+	// ErrUpdateNotStuck is returned by ForceUnlock for an update that is
+	// still within the stuck threshold.
+	ErrUpdateNotStuck = errors.New("update is not stuck")
+)
+
+// updateLock is the per-container (or per-service) update lock held in
+// Updater.updating for the duration of an update. Holding it is what
+// stops a second update of the same target; it also records which phase
+// the update is in so stuck updates can be found and released.
+type updateLock struct {
+	name      string
+	startedAt time.Time
+	cancel    context.CancelFunc
+	released  atomic.Bool // set once by releaseUpdate or ForceUnlock
+	warned    atomic.Bool // stuck warning already published
+
+	mu      sync.Mutex
+	phase   string
+	phaseAt time.Time
+}
+
+// ActiveUpdate describes an update in progress.
+type ActiveUpdate struct {
+	Name       string    `json:"name"`
+	Phase      string    `json:"phase"`
+	StartedAt  time.Time `json:"started_at"`
+	PhaseSince time.Time `json:"phase_since"`
+	HostID     string    `json:"host_id,omitempty"`
+	HostName   string    `json:"host_name,omitempty"`
+	Stuck      bool      `json:"stuck"`
+}
+
+// ForceUnlockResult reports what ForceUnlock released and what it found.
+type ForceUnlockResult struct {
+	Name     string   `json:"name"`
+	Phase    string   `json:"phase"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+type updateLockKey struct{}
+
+// lockUpdate acquires the update lock for name. It returns false if an
+// update of name is already in progress. The returned context is cancelled
+// when the lock is released or forcibly unlocked, and carries the lock so
+// setUpdatePhase can record progress.
+func (u *Updater) lockUpdate(ctx context.Context, name string) (context.Context, *updateLock, bool) {
+	now := u.clock.Now()
+	ctx, cancel := context.WithCancel(ctx)
+	lk := &updateLock{name: name, startedAt: now, cancel: cancel, phase: "starting", phaseAt: now}
+	if _, loaded := u.updating.LoadOrStore(name, lk); loaded {
+		cancel()
+		return nil, nil, false
+	}
+	u.activeUpdates.Add(1)
+	return withUpdateLock(ctx, lk), lk, true
+}
+
+// withUpdateLock makes setUpdatePhase under ctx record to lk.
+func withUpdateLock(ctx context.Context, lk *updateLock) context.Context {
+	return context.WithValue(ctx, updateLockKey{}, lk)
+}
+
+// releaseUpdate releases a lock taken by lockUpdate. It is a no-op for a
+// lock that ForceUnlock already released, so a stuck update finishing late
+// cannot release the lock of a newer update of the same name.
+func (u *Updater) releaseUpdate(lk *updateLock) {
+	if lk.released.Swap(true) {
+		return
+	}
+	u.updating.CompareAndDelete(lk.name, lk)
+	u.activeUpdates.Add(-1)
+	lk.cancel()
+}
+
+// IsUpdating reports whether a container currently has an update in progress.
+func (u *Updater) IsUpdating(name string) bool {
+	_, ok := u.updating.Load(name)
+	return ok
+}
+
+// setUpdatePhase records the phase of the update running under ctx.
+func (u *Updater) setUpdatePhase(ctx context.Context, phase string) {
+	if lk, ok := ctx.Value(updateLockKey{}).(*updateLock); ok {
+		lk.setPhase(phase, u.clock.Now())
+	}
+}
+
+func (lk *updateLock) setPhase(phase string, now time.Time) {
+	lk.mu.Lock()
+	defer lk.mu.Unlock()
+	if lk.phase != phase {
+		lk.phase, lk.phaseAt = phase, now
+		lk.warned.Store(false)
+	}
+}
+
+func (lk *updateLock) snapshot() (string, time.Time) {
+	lk.mu.Lock()
+	defer lk.mu.Unlock()
+	return lk.phase, lk.phaseAt
+}
+
+// stuckUpdateThreshold reads the stuck_update_threshold setting.
+func (u *Updater) stuckUpdateThreshold() time.Duration {
+	if u.settings != nil {
+		if val, err := u.settings.LoadSetting("stuck_update_threshold"); err == nil && val != "" {
+			if d, err := docker.ParseDurationWithDays(val); err == nil && d > 0 {
+				return d
+			}
+		}
+	}
+	return defaultStuckUpdateThreshold
+}
+
+// ActiveUpdates lists the updates in progress on this host, oldest first.
+// An update is stuck once it has spent longer than the stuck threshold in
+// its current phase.
+func (u *Updater) ActiveUpdates() []ActiveUpdate {
+	threshold := u.stuckUpdateThreshold()
+	now := u.clock.Now()
+	var out []ActiveUpdate
+	u.updating.Range(func(_, v any) bool {
+		lk := v.(*updateLock)
+		phase, since := lk.snapshot()
+		out = append(out, ActiveUpdate{
+			Name:       lk.name,
+			Phase:      phase,
+			StartedAt:  lk.startedAt,
+			PhaseSince: since,
+			Stuck:      now.Sub(since) > threshold,
+		})
+		return true
+	})
+	sort.Slice(out, func(i, j int) bool { return out[i].StartedAt.Before(out[j].StartedAt) })
+	return out
+}
+
+// checkStuckUpdates warns once about each update that has become stuck, so
+// it shows up in the logs and the dashboard without anyone polling for it.
+func (u *Updater) checkStuckUpdates() {
+	threshold := u.stuckUpdateThreshold()
+	now := u.clock.Now()
+	u.updating.Range(func(_, v any) bool {
+		lk := v.(*updateLock)
+		phase, since := lk.snapshot()
+		if now.Sub(since) > threshold && !lk.warned.Swap(true) {
+			stuckFor := now.Sub(since).Round(time.Second)
+			u.log.Warn("update appears stuck", "name", lk.name, "phase", phase, "for", stuckFor)
+			u.publishEvent(events.EventContainerUpdate, lk.name, fmt.Sprintf("update stuck in %s phase for %s", phase, stuckFor))
+		}
+		return true
+	})
+}
+
+// ForceUnlock releases the update lock of a stuck update so it can be
+// retried, and cancels the update's context so a hung pull, hook or rollout
+// gives up. (Between stopping the old container and starting the new one
+// recreate ignores cancellation, so it is never cut off half-way.) Updates
+// still within the stuck threshold are refused with ErrUpdateNotStuck.
+//
+// Afterwards it checks what the update left behind: a running container
+// has its maintenance flag cleared, while a missing or stopped one keeps it
+// and is reported in Warnings so the user can roll back.
+func (u *Updater) ForceUnlock(ctx context.Context, name string) (ForceUnlockResult, error) {
+	v, ok := u.updating.Load(name)
+	if !ok {
+		return ForceUnlockResult{}, ErrNotUpdating
+	}
+	lk := v.(*updateLock)
+	phase, since := lk.snapshot()
+	if threshold := u.stuckUpdateThreshold(); u.clock.Since(since) <= threshold {
+		return ForceUnlockResult{}, fmt.Errorf("%w: in %s phase for %s, threshold %s",
+			ErrUpdateNotStuck, phase, u.clock.Since(since).Round(time.Second), threshold)
+	}
+	if lk.released.Swap(true) {
+		return ForceUnlockResult{}, ErrNotUpdating
+	}
+	u.updating.CompareAndDelete(name, lk)
+	u.activeUpdates.Add(-1)
+	lk.cancel()
+	u.log.Warn("update lock forcibly released", "name", name, "phase", phase)
+
+	res := ForceUnlockResult{Name: name, Phase: phase}
+	containers, err := u.docker.ListAllContainers(ctx)
+	if err != nil {
+		res.Warnings = append(res.Warnings, "could not check the container: "+err.Error())
+		return res, nil
+	}
+	for _, c := range containers {
+		if containerName(c) != name {
+			continue
+		}
+		if c.State != "running" {
+			res.Warnings = append(res.Warnings, fmt.Sprintf("container is %s; check it and roll back if needed", c.State))
+			return res, nil
+		}
+		if err := u.store.SetMaintenance(name, false); err != nil {
+			res.Warnings = append(res.Warnings, "failed to clear maintenance flag: "+err.Error())
+		}
+		return res, nil
+	}
+	if services, err := u.docker.ListServices(ctx); err == nil {
+		for _, svc := range services {
+			if svc.Spec.Name == name {
+				return res, nil
+			}
+		}
+	}
+	res.Warnings = append(res.Warnings, "container is missing; roll back to restore it from the last snapshot")
+	return res, nil
+}
@@ -0,0 +1,120 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/moby/moby/api/types/container"
+)
+
+// tryLock and unlock take and release an update lock by name, standing in
+// for an update running in another goroutine.
+func (u *Updater) tryLock(name string) bool {
+	_, _, ok := u.lockUpdate(context.Background(), name)
+	return ok
+}
+
+func (u *Updater) unlock(name string) {
+	if v, ok := u.updating.Load(name); ok {
+		u.releaseUpdate(v.(*updateLock))
+	}
+}
+
+func TestActiveUpdatesReportsPhaseAndStuck(t *testing.T) {
+	u, clk := newTestUpdater(t, newMockDocker())
+
+	ctx, lock, ok := u.lockUpdate(context.Background(), "web")
+	if !ok {
+		t.Fatal("lockUpdate failed")
+	}
+	defer u.releaseUpdate(lock)
+	started := clk.Now()
+	clk.Advance(time.Minute)
+	u.setUpdatePhase(ctx, "pull")
+
+	active := u.ActiveUpdates()
+	if len(active) != 1 {
+		t.Fatalf("ActiveUpdates = %+v, want one", active)
+	}
+	a := active[0]
+	if a.Name != "web" || a.Phase != "pull" || !a.StartedAt.Equal(started) || !a.PhaseSince.Equal(clk.Now()) || a.Stuck {
+		t.Errorf("active = %+v", a)
+	}
+
+	clk.Advance(defaultStuckUpdateThreshold + time.Second)
+	if !u.ActiveUpdates()[0].Stuck {
+		t.Error("update not reported stuck past the threshold")
+	}
+
+	// A new phase restarts the clock.
+	u.setUpdatePhase(ctx, "stop")
+	if u.ActiveUpdates()[0].Stuck {
+		t.Error("update still stuck after moving to a new phase")
+	}
+}
+
+func TestForceUnlock(t *testing.T) {
+	mock := newMockDocker()
+	mock.containers = []container.Summary{{ID: "aaa", Names: []string{"/web"}, State: "running"}}
+	u, clk := newTestUpdater(t, mock)
+	_ = u.store.SetMaintenance("web", true)
+
+	ctx, lock, _ := u.lockUpdate(context.Background(), "web")
+	u.setUpdatePhase(ctx, "pull")
+
+	if _, err := u.ForceUnlock(context.Background(), "web"); !errors.Is(err, ErrUpdateNotStuck) {
+		t.Fatalf("err = %v, want ErrUpdateNotStuck for a fresh update", err)
+	}
+	if _, err := u.ForceUnlock(context.Background(), "db"); !errors.Is(err, ErrNotUpdating) {
+		t.Fatalf("err = %v, want ErrNotUpdating", err)
+	}
+
+	clk.Advance(defaultStuckUpdateThreshold + time.Minute)
+	res, err := u.ForceUnlock(context.Background(), "web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Phase != "pull" || len(res.Warnings) != 0 {
+		t.Errorf("result = %+v", res)
+	}
+	if ctx.Err() == nil {
+		t.Error("stuck update's context not cancelled")
+	}
+	if u.IsUpdating("web") || !u.IsIdle() {
+		t.Error("lock still held after ForceUnlock")
+	}
+	if m, _ := u.store.GetMaintenance("web"); m {
+		t.Error("maintenance flag left set on a running container")
+	}
+
+	// A retry can take the lock, and the stuck update finishing late must
+	// not release it.
+	if !u.tryLock("web") {
+		t.Fatal("retry could not take the lock")
+	}
+	u.releaseUpdate(lock)
+	if !u.IsUpdating("web") {
+		t.Error("late release of the stuck update dropped the retry's lock")
+	}
+	u.unlock("web")
+}
+
+func TestForceUnlockWarnsWhenContainerMissing(t *testing.T) {
+	u, clk := newTestUpdater(t, newMockDocker())
+	_ = u.store.SetMaintenance("web", true)
+	u.tryLock("web")
+	clk.Advance(defaultStuckUpdateThreshold + time.Minute)
+
+	res, err := u.ForceUnlock(context.Background(), "web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Warnings) != 1 {
+		t.Errorf("Warnings = %q, want the missing container reported", res.Warnings)
+	}
+	if m, _ := u.store.GetMaintenance("web"); !m {
+		t.Error("maintenance flag cleared although the container is missing")
+	}
+}
@@ -2,11 +2,14 @@ package engine
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/Will-Luck/Docker-Sentinel/internal/scanner"
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
 	"github.com/Will-Luck/Docker-Sentinel/internal/verify"
 	"github.com/moby/moby/api/types/container"
 )
@@ -95,8 +98,8 @@ func TestUpdateContainer_PreScanBlocks(t *testing.T) {
 	u.SetSeverityThreshold(scanner.SeverityHigh)
 
 	err := u.UpdateContainer(context.Background(), "aaa", "nginx", "")
-	if err == nil {
-		t.Fatal("expected error from pre-scan blocking update")
+	if !errors.Is(err, ErrScanBlocked) {
+		t.Fatalf("err = %v, want ErrScanBlocked", err)
 	}
 
 	// Pull should have been called (scan happens after pull).
@@ -116,6 +119,46 @@ func TestUpdateContainer_PreScanBlocks(t *testing.T) {
 	if len(mock.createCalls) != 0 {
 		t.Errorf("createCalls = %d, want 0 (scan should block)", len(mock.createCalls))
 	}
+
+	// Falls back to manual approval with the report attached.
+	queued, ok := u.queue.Get("nginx")
+	if !ok {
+		t.Fatal("blocked update should be queued for approval")
+	}
+	if queued.ScanReport == nil || len(queued.ScanReport.Vulns) != 2 {
+		t.Fatalf("scan report = %+v, want 2 findings", queued.ScanReport)
+	}
+	if queued.ScanThreshold != scanner.SeverityHigh {
+		t.Errorf("scan threshold = %q, want HIGH", queued.ScanThreshold)
+	}
+	if queued.CurrentImage != "docker.io/library/nginx:latest" {
+		t.Errorf("current image = %q", queued.CurrentImage)
+	}
+}
+
+func TestUpdateContainer_PreScanApprovalOverrides(t *testing.T) {
+	mock, u := setupUpdateWithScanVerify(t)
+
+	ms := &mockImageScanner{
+		result: &scanner.ScanResult{
+			ImageRef: "docker.io/library/nginx:latest",
+			Summary:  scanner.Summary{Critical: 1, Total: 1},
+			Vulns:    []scanner.Vulnerability{{ID: "CVE-2024-0001", Severity: scanner.SeverityCritical}},
+		},
+	}
+	u.SetScanner(ms)
+	u.SetScanMode(scanner.ScanPreUpdate)
+	u.SetSeverityThreshold(scanner.SeverityHigh)
+
+	if err := u.UpdateContainer(WithScanApproval(context.Background()), "aaa", "nginx", ""); err != nil {
+		t.Fatalf("approved update should proceed, got: %v", err)
+	}
+	if len(mock.createCalls) == 0 {
+		t.Error("approved update should create the new container")
+	}
+	if _, ok := u.queue.Get("nginx"); ok {
+		t.Error("approved update should not be re-queued")
+	}
 }
 
 func TestUpdateContainer_PreScanPasses(t *testing.T) {
@@ -181,6 +224,32 @@ func TestUpdateContainer_VerifyEnforceBlocks(t *testing.T) {
 	if len(mock.stopCalls) != 0 {
 		t.Errorf("stopCalls = %d, want 0 (verify enforce should block)", len(mock.stopCalls))
 	}
+
+	// The block is recorded in history with a signature failure class.
+	history, _ := u.store.ListHistory(10, "")
+	if len(history) != 1 || history[0].Outcome != "failed" || history[0].FailureClass != store.FailureSignature {
+		t.Fatalf("history = %+v, want one failed signature record", history)
+	}
+	if !strings.Contains(history[0].Error, "no matching signatures") {
+		t.Errorf("history error = %q, want the cosign message", history[0].Error)
+	}
+}
+
+func TestUpdateContainer_VerifyLabelEnforcesWithoutCosign(t *testing.T) {
+	mock, u := setupUpdateWithScanVerify(t)
+	inspect := mock.inspectResults["aaa"]
+	inspect.Config.Labels[verify.ContainerLabel] = "enforce"
+	mock.inspectResults["aaa"] = inspect
+
+	// Global mode stays disabled and no verifier is configured: the label
+	// still demands a verified signature, so the update must not proceed.
+	err := u.UpdateContainer(context.Background(), "aaa", "nginx", "")
+	if err == nil || !strings.Contains(err.Error(), "cosign is not available") {
+		t.Fatalf("err = %v, want signature failure", err)
+	}
+	if len(mock.stopCalls) != 0 {
+		t.Errorf("stopCalls = %d, want 0", len(mock.stopCalls))
+	}
 }
 
 func TestUpdateContainer_VerifyWarnProceeds(t *testing.T) {
@@ -0,0 +1,31 @@
+package engine
+
+import "testing"
+
+func TestUpdateWindowPrecedence(t *testing.T) {
+	u, _ := newTestUpdater(t, newMockDocker())
+	u.cfg.SetMaintenanceWindow("02:00-04:00")
+	if err := u.store.SetStackWindow("db", "Sun 03:00-Sun 05:00"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		labels     map[string]string
+		wantExpr   string
+		wantSource string
+	}{
+		{"global", map[string]string{}, "02:00-04:00", "global"},
+		{"other stack", map[string]string{"com.docker.compose.project": "web"}, "02:00-04:00", "global"},
+		{"stack override", map[string]string{"com.docker.compose.project": "db"}, "Sun 03:00-Sun 05:00", "stack"},
+		{"label beats stack", map[string]string{"com.docker.compose.project": "db", "sentinel.window": "any"}, "any", "label"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, source := u.updateWindow(tt.labels)
+			if expr != tt.wantExpr || source != tt.wantSource {
+				t.Errorf("updateWindow = %q, %q; want %q, %q", expr, source, tt.wantExpr, tt.wantSource)
+			}
+		})
+	}
+}
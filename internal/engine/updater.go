@@ -3,15 +3,16 @@ package engine
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"sync"
 	"sync/atomic"
 
 	"github.com/Will-Luck/Docker-Sentinel/internal/clock"
 	"github.com/Will-Luck/Docker-Sentinel/internal/config"
-	"github.com/Will-Luck/Docker-Sentinel/internal/deps"
 	"github.com/Will-Luck/Docker-Sentinel/internal/docker"
 	"github.com/Will-Luck/Docker-Sentinel/internal/events"
 	"github.com/Will-Luck/Docker-Sentinel/internal/hooks"
+	"github.com/Will-Luck/Docker-Sentinel/internal/lifecycle"
 	"github.com/Will-Luck/Docker-Sentinel/internal/logging"
 	"github.com/Will-Luck/Docker-Sentinel/internal/notify"
 	"github.com/Will-Luck/Docker-Sentinel/internal/registry"
@@ -34,6 +35,12 @@ type ImageVerifier interface {
 	Verify(ctx context.Context, imageRef string) *verify.Result
 }
 
+// DigestVerifier is an ImageVerifier that can reuse the result of an
+// earlier verification of the same image digest.
+type DigestVerifier interface {
+	VerifyDigest(ctx context.Context, imageRef, digest string) *verify.Result
+}
+
 // finaliseError wraps an error with the stage at which finaliseContainer failed.
 // Stage values: "inspect", "stop", "remove", "create", "start".
 type finaliseError struct {
@@ -60,35 +67,49 @@ type Updater struct {
 	log                *logging.Logger
 	clock              clock.Clock
 	notifier           *notify.Multi
+	probeFn            func(ctx context.Context, target *url.URL) error // nil = runProbe; overridden in tests
 	events             *events.Bus
 	settings           SettingsReader
 	rateTracker        *registry.RateLimitTracker // optional: rate limit awareness
 	rateSaver          func([]byte) error         // optional: persist rate limits after scan
 	ghcrCache          *registry.GHCRCache        // optional: GHCR alternative detection cache
 	ghcrSaver          func([]byte) error         // optional: persist GHCR cache after checks
-	updating           sync.Map                   // map[string]*sync.Mutex — per-container update locks
+	updating           sync.Map                   // map[string]*updateLock — per-container update locks
 	activeUpdates      atomic.Int32               // tracks number of in-progress updates for IsIdle()
+	draining           atomic.Bool                // set by Drain; refuses new updates during shutdown
 	hooks              *hooks.Runner              // optional: lifecycle hook runner
-	deps               *deps.Graph                // optional: dependency graph (rebuilt each scan)
 	cluster            ClusterScanner             // optional: nil = single-host mode
 	haDiscovery        *notify.HADiscovery        // optional: HA MQTT auto-discovery publisher
 	portainerMu        sync.RWMutex
 	portainerInstances []PortainerInstance
-	imgScanner         ImageScanner       // optional: trivy vulnerability scanner
-	imgVerifier        ImageVerifier      // optional: cosign signature verifier
-	scanMode           scanner.ScanMode   // disabled/pre-update/post-update
-	verifyMode         verify.Mode        // disabled/warn/enforce
-	severityThresh     scanner.Severity   // block threshold for pre-update scans
-	ghcrWg             sync.WaitGroup     // tracks background GHCR alternative checks
-	ghcrRunning        atomic.Bool        // prevents concurrent GHCR checks
-	ghcrCancel         context.CancelFunc // cancels the running GHCR check on shutdown
-	selfUpdateQueued   atomic.Bool        // set when a self-update is queued during scan
-	selfUpdateKey      atomic.Value       // stores queue key (string) of the self-update entry
+	imgScanner         ImageScanner                                                              // optional: trivy vulnerability scanner
+	imgVerifier        ImageVerifier                                                             // optional: cosign signature verifier
+	scanMode           scanner.ScanMode                                                          // disabled/pre-update/post-update
+	verifyMode         verify.Mode                                                               // disabled/warn/enforce
+	severityThresh     scanner.Severity                                                          // block threshold for pre-update scans
+	ghcrWg             sync.WaitGroup                                                            // tracks background GHCR alternative checks
+	ghcrRunning        atomic.Bool                                                               // prevents concurrent GHCR checks
+	ghcrCancel         context.CancelFunc                                                        // cancels the running GHCR check on shutdown
+	selfUpdateQueued   atomic.Bool                                                               // set when a self-update is queued during scan
+	selfUpdateKey      atomic.Value                                                              // stores queue key (string) of the self-update entry
+	releaseSources     func() []registry.ReleaseSource                                           // optional: enables release note links in notifications
+	releaseNotes       func(ctx context.Context, imageRef, version string) *registry.ReleaseInfo // nil = GitHub lookup; overridden in tests
+	repoRelease        func(ctx context.Context, repo string) (*registry.RepoReleaseInfo, error) // nil = GitHub lookup; overridden in tests
+	strategies         map[string]UpdateStrategy                                                 // update strategies by name (see strategyFor)
+	lifecycle          *lifecycle.Sink                                                           // optional: machine event sink for state transitions
+	capProber          CapabilityProber                                                          // optional: pre-flight Docker permission probes
+	capMu              sync.Mutex
+	rolloutMu          sync.Mutex                                                           // serialises staged rollout state changes
+	caps               docker.Capabilities                                                  // last probe result; zero until first probe
+	imagePlatforms     func(ctx context.Context, image string) ([]registry.Platform, error) // nil = registry lookup; overridden in tests
+	imageSize          func(ctx context.Context, image string) (int64, error)               // nil = registry lookup; overridden in tests
+	diskFree           func(path string) (uint64, error)                                    // nil = statfs; overridden in tests
+	configReader       ConfigFileReader                                                     // optional: enables config file previews for manual updates
 }
 
 // NewUpdater creates an Updater with all dependencies.
 func NewUpdater(d docker.API, checker *registry.Checker, s *store.Store, q *Queue, cfg *config.Config, log *logging.Logger, clk clock.Clock, notifier *notify.Multi, bus *events.Bus) *Updater {
-	return &Updater{
+	u := &Updater{
 		docker:   d,
 		checker:  checker,
 		store:    s,
@@ -99,6 +120,8 @@ func NewUpdater(d docker.API, checker *registry.Checker, s *store.Store, q *Queu
 		notifier: notifier,
 		events:   bus,
 	}
+	u.strategies = builtinStrategies(u)
+	return u
 }
 
 // SetSettingsReader attaches a settings reader for runtime filter checks.
@@ -126,6 +149,12 @@ func (u *Updater) SetGHCRSaver(fn func([]byte) error) {
 	u.ghcrSaver = fn
 }
 
+// SetReleaseSources attaches a provider of custom release source mappings.
+// When set, update-available notifications carry a release notes link.
+func (u *Updater) SetReleaseSources(fn func() []registry.ReleaseSource) {
+	u.releaseSources = fn
+}
+
 // SetHookRunner attaches a lifecycle hook runner.
 func (u *Updater) SetHookRunner(r *hooks.Runner) {
 	u.hooks = r
@@ -207,43 +236,6 @@ func (u *Updater) Close() {
 	u.ghcrWg.Wait()
 }
 
-// tryLock attempts to acquire the per-container update lock.
-// Returns false if the container already has an update in progress.
-func (u *Updater) tryLock(name string) bool {
-	mu := &sync.Mutex{}
-	actual, _ := u.updating.LoadOrStore(name, mu)
-	if actual.(*sync.Mutex).TryLock() {
-		u.activeUpdates.Add(1)
-		return true
-	}
-	return false
-}
-
-// unlock releases the per-container update lock and removes the entry
-// from the map atomically to prevent stale mutex accumulation.
-// LoadAndDelete is atomic — no window for another goroutine to LoadOrStore
-// between our Unlock and Delete (which was the previous race condition).
-func (u *Updater) unlock(name string) {
-	if val, ok := u.updating.LoadAndDelete(name); ok {
-		val.(*sync.Mutex).Unlock()
-		u.activeUpdates.Add(-1)
-	}
-}
-
-// IsUpdating reports whether a container currently has an update in progress.
-func (u *Updater) IsUpdating(name string) bool {
-	val, ok := u.updating.Load(name)
-	if !ok {
-		return false
-	}
-	mu := val.(*sync.Mutex)
-	if mu.TryLock() {
-		mu.Unlock()
-		return false
-	}
-	return true
-}
-
 // IsIdle returns true when no container updates are in progress.
 func (u *Updater) IsIdle() bool {
 	return u.activeUpdates.Load() == 0
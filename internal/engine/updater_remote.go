@@ -19,7 +19,10 @@ import (
 // scanRemoteHosts iterates connected agents and scans their containers for
 // updates. Registry checks run server-side (shared rate limit pool); the
 // actual pull/restart is dispatched to the remote agent via ClusterScanner.
-func (u *Updater) scanRemoteHosts(ctx context.Context, mode ScanMode, result *ScanResult, filters []string, reserve int) {
+// With dependency awareness on, hosts providing a sentinel.cluster-depends-on
+// dependency are scanned first and their dependents wait for them to come
+// back healthy.
+func (u *Updater) scanRemoteHosts(ctx context.Context, mode ScanMode, result *ScanResult, filters ScanFilters, reserve int) {
 	hosts := u.cluster.ConnectedHosts()
 	if len(hosts) == 0 {
 		return
@@ -27,6 +30,7 @@ func (u *Updater) scanRemoteHosts(ctx context.Context, mode ScanMode, result *Sc
 
 	u.log.Info("scanning remote hosts", "count", len(hosts))
 
+	scans := make([]remoteHostScan, 0, len(hosts))
 	for _, hostID := range hosts {
 		if ctx.Err() != nil {
 			return
@@ -37,22 +41,40 @@ func (u *Updater) scanRemoteHosts(ctx context.Context, mode ScanMode, result *Sc
 			continue
 		}
 
-		u.scanRemoteHost(ctx, hostID, hostCtx, mode, result, filters, reserve)
+		containers, err := u.cluster.ListContainers(ctx, hostID)
+		if err != nil {
+			u.log.Error("failed to list remote containers", "host", hostCtx.HostName, "error", err)
+			continue
+		}
+		scans = append(scans, remoteHostScan{id: hostID, host: hostCtx, containers: containers})
+	}
+
+	var rd *remoteDeps
+	if u.cfg.DependencyAware() {
+		rd = newRemoteDeps(scans)
+		var cyclic bool
+		if scans, cyclic = orderRemoteHosts(scans, rd); cyclic {
+			u.log.Warn("cross-host dependency cycle detected, scanning remaining hosts in listed order")
+		}
+	}
+
+	for _, hs := range scans {
+		if ctx.Err() != nil {
+			return
+		}
+		u.scanRemoteHost(ctx, hs.id, hs.host, hs.containers, mode, result, filters, reserve, rd)
 	}
 }
 
 // scanRemoteHost scans a single remote host's containers for updates.
 // Policy resolution, filtering, and registry checks all happen server-side.
-// Only the container update itself is dispatched to the remote agent.
-func (u *Updater) scanRemoteHost(ctx context.Context, hostID string, host HostContext, mode ScanMode, result *ScanResult, filters []string, reserve int) {
-	containers, err := u.cluster.ListContainers(ctx, hostID)
-	if err != nil {
-		u.log.Error("failed to list remote containers", "host", host.HostName, "error", err)
-		return
-	}
-
+// Only the container update itself is dispatched to the remote agent. rd is
+// nil when dependency awareness is off.
+func (u *Updater) scanRemoteHost(ctx context.Context, hostID string, host HostContext, containers []RemoteContainer, mode ScanMode, result *ScanResult, filters ScanFilters, reserve int, rd *remoteDeps) {
 	u.log.Info("scanning remote host", "host", host.HostName, "containers", len(containers))
 
+	unmanaged := u.store.AllUnmanaged()
+
 	remoteDefault := u.cfg.DefaultPolicy()
 	if u.settings != nil {
 		if v, err := u.settings.LoadSetting(store.SettingClusterRemotePolicy); err == nil && v != "" {
@@ -69,6 +91,9 @@ func (u *Updater) scanRemoteHost(ctx context.Context, hostID string, host HostCo
 		if _, isTask := c.Labels["com.docker.swarm.task"]; isTask {
 			continue
 		}
+		if _, ok := unmanaged[store.ScopedKey(hostID, c.Name)]; ok {
+			continue
+		}
 
 		result.Total++
 
@@ -87,8 +112,8 @@ func (u *Updater) scanRemoteHost(ctx context.Context, hostID string, host HostCo
 		remoteSelf := isSentinel(c.Labels)
 
 		// Skip containers matching filter patterns.
-		if MatchesFilter(c.Name, filters) {
-			u.log.Debug("skipping filtered remote container", "host", host.HostName, "name", c.Name)
+		if excluded, rule := filters.Excluded(c.Name, c.Image, c.Labels); excluded {
+			u.log.Debug("skipping filtered remote container", "host", host.HostName, "name", c.Name, "filter", rule)
 			result.Skipped++
 			continue
 		}
@@ -96,7 +121,7 @@ func (u *Updater) scanRemoteHost(ctx context.Context, hostID string, host HostCo
 		// Rate limit check (shared server-side pool).
 		// Skip this container but keep scanning — other registries may be available.
 		if u.rateTracker != nil {
-			regHost := registry.RegistryHost(c.Image)
+			regHost := registry.RegistryHost(checkSourceRef(c.Image, c.Labels))
 			canProceed, wait := u.rateTracker.CanProceed(regHost, reserve)
 			if !canProceed {
 				u.log.Debug("rate limit low, skipping remote container",
@@ -111,12 +136,16 @@ func (u *Updater) scanRemoteHost(ctx context.Context, hostID string, host HostCo
 		// the server's Docker daemon.
 		semverScope := docker.ContainerSemverScope(c.Labels)
 		includeRE, excludeRE := docker.ContainerTagFilters(c.Labels)
-		check := u.checker.CheckVersionedWithDigest(ctx, c.Image, c.ImageDigest, semverScope, includeRE, excludeRE)
+		checkImage, _, _ := checkRef(c.Image, c.Labels)
+		check := u.checker.CheckVersionedWithDigest(ctx, checkSourceRef(checkImage, c.Labels), c.ImageDigest, semverScope, includeRE, excludeRE)
 		if check.Error != nil {
 			u.log.Warn("registry check failed for remote container",
 				"host", host.HostName, "name", c.Name, "error", check.Error)
 			continue
 		}
+		if !check.IsLocal {
+			u.recordVersion(store.ScopedKey(hostID, c.Name), c.Labels, c.Image, check)
+		}
 
 		if check.IsLocal || !check.UpdateAvailable {
 			continue
@@ -130,6 +159,9 @@ func (u *Updater) scanRemoteHost(ctx context.Context, hostID string, host HostCo
 		scanTarget := ""
 		if len(check.NewerVersions) > 0 {
 			scanTarget = replaceTag(c.Image, check.NewerVersions[0])
+		} else if checkImage != c.Image {
+			// Tracked tag@digest: re-pull the tag, not the pinned digest.
+			scanTarget = checkImage
 		}
 
 		scopedName := store.ScopedKey(hostID, c.Name)
@@ -143,6 +175,7 @@ func (u *Updater) scanRemoteHost(ctx context.Context, hostID string, host HostCo
 				RemoteDigest:           check.RemoteDigest,
 				DetectedAt:             u.clock.Now(),
 				NewerVersions:          check.NewerVersions,
+				Candidates:             check.Candidates,
 				ResolvedCurrentVersion: check.ResolvedCurrentVersion,
 				ResolvedTargetVersion:  check.ResolvedTargetVersion,
 				HostID:                 hostID,
@@ -191,6 +224,31 @@ func (u *Updater) scanRemoteHost(ctx context.Context, hostID string, host HostCo
 				}
 			}
 
+			// Providers on other hosts must be healthy before this update goes out.
+			if rd != nil {
+				if err := u.awaitRemoteDependencies(ctx, c.Labels, rd); err != nil {
+					u.deferRemoteUpdate(host, scopedName, c, scanTarget, err)
+					result.Skipped++
+					continue
+				}
+			}
+
+			if !u.claimAutoSlot(result) {
+				u.overflowAutoUpdate(result, scopedName, PendingUpdate{
+					ContainerName:          c.Name,
+					CurrentImage:           c.Image,
+					CurrentDigest:          c.ImageDigest,
+					RemoteDigest:           check.RemoteDigest,
+					NewerVersions:          check.NewerVersions,
+					Candidates:             check.Candidates,
+					ResolvedCurrentVersion: check.ResolvedCurrentVersion,
+					ResolvedTargetVersion:  check.ResolvedTargetVersion,
+					HostID:                 hostID,
+					HostName:               host.HostName,
+				})
+				continue
+			}
+
 			// Dispatch update to the remote agent.
 			ur, updateErr := u.cluster.UpdateContainer(ctx, hostID, c.Name, scanTarget, check.RemoteDigest)
 			if updateErr != nil {
@@ -198,8 +256,14 @@ func (u *Updater) scanRemoteHost(ctx context.Context, hostID string, host HostCo
 					"host", host.HostName, "name", c.Name, "error", updateErr)
 				result.Errors = append(result.Errors, fmt.Errorf("%s/%s: %w", host.HostName, c.Name, updateErr))
 				result.Failed++
+				if rd != nil {
+					rd.updated[scopedName] = "failed"
+				}
 				continue
 			}
+			if rd != nil {
+				rd.updated[scopedName] = ur.Outcome
+			}
 
 			// Record update in host-scoped history.
 			if err := u.store.RecordUpdate(store.UpdateRecord{
@@ -211,6 +275,7 @@ func (u *Updater) scanRemoteHost(ctx context.Context, hostID string, host HostCo
 				NewDigest:     ur.NewDigest,
 				Outcome:       ur.Outcome,
 				Duration:      ur.Duration,
+				Hooks:         ur.Hooks,
 			}); err != nil {
 				u.log.Warn("failed to record remote update history", "name", scopedName, "error", err)
 			}
@@ -226,9 +291,12 @@ func (u *Updater) scanRemoteHost(ctx context.Context, hostID string, host HostCo
 				})
 			}
 
-			if ur.Outcome == "success" {
+			switch ur.Outcome {
+			case "success":
 				result.Updated++
-			} else {
+			case "skipped": // a pre-update hook on the agent cancelled it
+				result.Skipped++
+			default:
 				result.Failed++
 			}
 
@@ -241,6 +309,7 @@ func (u *Updater) scanRemoteHost(ctx context.Context, hostID string, host HostCo
 				RemoteDigest:           check.RemoteDigest,
 				DetectedAt:             u.clock.Now(),
 				NewerVersions:          check.NewerVersions,
+				Candidates:             check.Candidates,
 				ResolvedCurrentVersion: check.ResolvedCurrentVersion,
 				ResolvedTargetVersion:  check.ResolvedTargetVersion,
 				HostID:                 hostID,
@@ -254,8 +323,39 @@ func (u *Updater) scanRemoteHost(ctx context.Context, hostID string, host HostCo
 	}
 }
 
+// deferRemoteUpdate records a remote update held back because a cross-host
+// dependency did not come back healthy. The update is retried next scan.
+func (u *Updater) deferRemoteUpdate(host HostContext, scopedName string, c RemoteContainer, target string, reason error) {
+	u.log.Warn("remote update deferred", "host", host.HostName, "name", c.Name, "reason", reason)
+	newImage := target
+	if newImage == "" {
+		newImage = c.Image
+	}
+	if err := u.store.RecordUpdate(store.UpdateRecord{
+		Timestamp:     u.clock.Now(),
+		ContainerName: scopedName,
+		OldImage:      c.Image,
+		OldDigest:     c.ImageDigest,
+		NewImage:      newImage,
+		Outcome:       "deferred",
+		Error:         reason.Error(),
+		FailureClass:  store.FailurePrecondition,
+	}); err != nil {
+		u.log.Warn("failed to record deferred remote update", "name", scopedName, "error", err)
+	}
+	if u.events != nil {
+		u.events.Publish(events.SSEEvent{
+			Type:          events.EventContainerUpdate,
+			ContainerName: c.Name,
+			HostName:      host.HostName,
+			Message:       fmt.Sprintf("update of %s on %s deferred: %s", c.Name, host.HostName, reason),
+			Timestamp:     u.clock.Now(),
+		})
+	}
+}
+
 // scanPortainerInstances iterates all configured Portainer instances and their endpoints.
-func (u *Updater) scanPortainerInstances(ctx context.Context, mode ScanMode, result *ScanResult, filters []string, reserve int, localIDs map[string]bool) {
+func (u *Updater) scanPortainerInstances(ctx context.Context, mode ScanMode, result *ScanResult, filters ScanFilters, reserve int, localIDs map[string]bool) {
 	// Snapshot the slice under read lock so mutations from HTTP handlers
 	// (Add/Remove/SetPortainerInstances) don't reallocate the backing array
 	// while we hold pointers into it.
@@ -297,7 +397,7 @@ func (u *Updater) scanPortainerInstances(ctx context.Context, mode ScanMode, res
 }
 
 // scanPortainerEndpoint scans a single Portainer endpoint's containers for updates.
-func (u *Updater) scanPortainerEndpoint(ctx context.Context, inst *PortainerInstance, ep PortainerEndpointInfo, mode ScanMode, result *ScanResult, filters []string, reserve int, localIDs map[string]bool) {
+func (u *Updater) scanPortainerEndpoint(ctx context.Context, inst *PortainerInstance, ep PortainerEndpointInfo, mode ScanMode, result *ScanResult, filters ScanFilters, reserve int, localIDs map[string]bool) {
 	containers, err := inst.Scanner.EndpointContainers(ctx, ep.ID)
 	if err != nil {
 		u.log.Error("failed to list Portainer endpoint containers", "instance", inst.Name, "endpoint", ep.Name, "error", err)
@@ -308,6 +408,7 @@ func (u *Updater) scanPortainerEndpoint(ctx context.Context, inst *PortainerInst
 
 	hostID := fmt.Sprintf("portainer:%s:%d", inst.ID, ep.ID)
 	remoteDefault := u.cfg.DefaultPolicy()
+	unmanaged := u.store.AllUnmanaged()
 
 	// Track redeployed stacks to avoid re-triggering the same stack multiple times.
 	redeployedStacks := make(map[int]bool)
@@ -325,6 +426,9 @@ func (u *Updater) scanPortainerEndpoint(ctx context.Context, inst *PortainerInst
 				"endpoint", ep.Name, "name", c.Name, "id", c.ID[:12])
 			continue
 		}
+		if _, ok := unmanaged[store.ScopedKey(hostID, c.Name)]; ok {
+			continue
+		}
 
 		result.Total++
 
@@ -343,14 +447,14 @@ func (u *Updater) scanPortainerEndpoint(ctx context.Context, inst *PortainerInst
 		// Updating Portainer through its own API kills the API mid-request.
 		remoteSelf := isSentinel(c.Labels) || isPortainerSelf(c.Image)
 
-		if MatchesFilter(c.Name, filters) {
-			u.log.Debug("skipping filtered Portainer container", "endpoint", ep.Name, "name", c.Name)
+		if excluded, rule := filters.Excluded(c.Name, c.Image, c.Labels); excluded {
+			u.log.Debug("skipping filtered Portainer container", "endpoint", ep.Name, "name", c.Name, "filter", rule)
 			result.Skipped++
 			continue
 		}
 
 		if u.rateTracker != nil {
-			regHost := registry.RegistryHost(c.Image)
+			regHost := registry.RegistryHost(checkSourceRef(c.Image, c.Labels))
 			canProceed, wait := u.rateTracker.CanProceed(regHost, reserve)
 			if !canProceed {
 				u.log.Debug("rate limit low, skipping Portainer container",
@@ -365,13 +469,17 @@ func (u *Updater) scanPortainerEndpoint(ctx context.Context, inst *PortainerInst
 		u.log.Debug("checking Portainer container",
 			"endpoint", ep.Name, "name", c.Name, "image", c.Image,
 			"semverScope", string(semverScope), "digest", c.ImageDigest[:min(len(c.ImageDigest), 30)])
-		check := u.checker.CheckVersionedWithDigest(ctx, c.Image, c.ImageDigest, semverScope, includeRE, excludeRE)
+		checkImage, _, _ := checkRef(c.Image, c.Labels)
+		check := u.checker.CheckVersionedWithDigest(ctx, checkSourceRef(checkImage, c.Labels), c.ImageDigest, semverScope, includeRE, excludeRE)
 		if check.Error != nil {
 			u.log.Warn("registry check failed for Portainer container",
 				"endpoint", ep.Name, "name", c.Name, "error", check.Error)
 			continue
 		}
 
+		if !check.IsLocal {
+			u.recordVersion(store.ScopedKey(hostID, c.Name), c.Labels, c.Image, check)
+		}
 		if check.IsLocal {
 			u.log.Debug("Portainer container treated as local",
 				"endpoint", ep.Name, "name", c.Name, "image", c.Image)
@@ -416,7 +524,7 @@ func (u *Updater) scanPortainerEndpoint(ctx context.Context, inst *PortainerInst
 
 		// Fix 1: fallback to c.Image when semver resolution yields no target.
 		if scanTarget == "" {
-			scanTarget = c.Image
+			scanTarget = checkImage
 		}
 
 		scopedName := store.ScopedKey(hostID, c.Name)
@@ -429,6 +537,7 @@ func (u *Updater) scanPortainerEndpoint(ctx context.Context, inst *PortainerInst
 				RemoteDigest:           check.RemoteDigest,
 				DetectedAt:             u.clock.Now(),
 				NewerVersions:          check.NewerVersions,
+				Candidates:             check.Candidates,
 				ResolvedCurrentVersion: check.ResolvedCurrentVersion,
 				ResolvedTargetVersion:  check.ResolvedTargetVersion,
 				HostID:                 hostID,
@@ -480,13 +589,29 @@ func (u *Updater) scanPortainerEndpoint(ctx context.Context, inst *PortainerInst
 				}
 			}
 
+			// Stack container already covered by this scan's redeploy.
+			if c.StackID != 0 && redeployedStacks[c.StackID] {
+				result.Updated++
+				continue
+			}
+			if !u.claimAutoSlot(result) {
+				u.overflowAutoUpdate(result, scopedName, PendingUpdate{
+					ContainerName:          c.Name,
+					CurrentImage:           c.Image,
+					RemoteDigest:           check.RemoteDigest,
+					NewerVersions:          check.NewerVersions,
+					Candidates:             check.Candidates,
+					ResolvedCurrentVersion: check.ResolvedCurrentVersion,
+					ResolvedTargetVersion:  check.ResolvedTargetVersion,
+					HostID:                 hostID,
+					HostName:               ep.Name,
+				})
+				continue
+			}
+
 			var updateErr error
 			if c.StackID != 0 {
 				// Stack container — redeploy the whole stack once.
-				if redeployedStacks[c.StackID] {
-					result.Updated++
-					continue
-				}
 				updateErr = inst.Scanner.RedeployStack(ctx, c.StackID, ep.ID)
 				if updateErr == nil {
 					redeployedStacks[c.StackID] = true
@@ -534,6 +659,7 @@ func (u *Updater) scanPortainerEndpoint(ctx context.Context, inst *PortainerInst
 				RemoteDigest:           check.RemoteDigest,
 				DetectedAt:             u.clock.Now(),
 				NewerVersions:          check.NewerVersions,
+				Candidates:             check.Candidates,
 				ResolvedCurrentVersion: check.ResolvedCurrentVersion,
 				ResolvedTargetVersion:  check.ResolvedTargetVersion,
 				HostID:                 hostID,
@@ -0,0 +1,172 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/deps"
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+)
+
+// remoteHealthTimeout bounds how long a dependent waits for a provider on
+// another host to report healthy after that provider was updated.
+const remoteHealthTimeout = 2 * time.Minute
+
+// remoteHostScan is a connected host with its container list. All hosts are
+// listed before any remote update is dispatched, so that cross-host
+// dependencies can decide the order they are scanned in.
+type remoteHostScan struct {
+	id         string
+	host       HostContext
+	containers []RemoteContainer
+}
+
+// remoteDeps tracks the cross-host dependencies of one scan: how host
+// references resolve, which containers were updated and which providers have
+// already been confirmed healthy.
+type remoteDeps struct {
+	hostIDs   map[string]string // host name or ID -> host ID
+	updated   map[string]string // scoped key -> outcome of this scan's update
+	confirmed map[string]bool   // scoped key -> reported healthy after its update
+}
+
+func newRemoteDeps(scans []remoteHostScan) *remoteDeps {
+	rd := &remoteDeps{
+		hostIDs:   make(map[string]string, 2*len(scans)),
+		updated:   make(map[string]string),
+		confirmed: make(map[string]bool),
+	}
+	for _, s := range scans {
+		rd.hostIDs[s.id] = s.id
+		if s.host.HostName != "" {
+			rd.hostIDs[s.host.HostName] = s.id
+		}
+	}
+	return rd
+}
+
+// resolve returns the host ID a dependency points at, or false when the host
+// is unknown or not connected.
+func (rd *remoteDeps) resolve(d deps.ClusterDependency) (string, bool) {
+	id, ok := rd.hostIDs[d.Host]
+	return id, ok
+}
+
+// orderRemoteHosts sorts hosts so that a host providing a cross-host
+// dependency is scanned before the hosts depending on it. Hosts caught in a
+// cycle keep their listed order after the rest; cyclic reports whether that
+// happened.
+func orderRemoteHosts(scans []remoteHostScan, rd *remoteDeps) (ordered []remoteHostScan, cyclic bool) {
+	index := make(map[string]int, len(scans))
+	for i, s := range scans {
+		index[s.id] = i
+	}
+	// providers[i] holds the hosts that host i depends on.
+	providers := make([]map[int]bool, len(scans))
+	for i, s := range scans {
+		for _, c := range s.containers {
+			for _, d := range deps.ParseClusterDependsOn(c.Labels) {
+				id, ok := rd.resolve(d)
+				if !ok || id == s.id {
+					continue
+				}
+				if providers[i] == nil {
+					providers[i] = make(map[int]bool)
+				}
+				providers[i][index[id]] = true
+			}
+		}
+	}
+
+	done := make([]bool, len(scans))
+	for len(ordered) < len(scans) {
+		progressed := false
+		for i, s := range scans {
+			if done[i] {
+				continue
+			}
+			ready := true
+			for p := range providers[i] {
+				if !done[p] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				ordered = append(ordered, s)
+				done[i] = true
+				progressed = true
+			}
+		}
+		if !progressed {
+			for i, s := range scans {
+				if !done[i] {
+					ordered = append(ordered, s)
+				}
+			}
+			return ordered, true
+		}
+	}
+	return ordered, false
+}
+
+// awaitRemoteDependencies checks the cross-host dependencies declared in
+// labels before a remote update is dispatched. A provider updated earlier in
+// this scan must have succeeded and report healthy; providers that were not
+// updated, or whose host is not connected, are not waited on.
+func (u *Updater) awaitRemoteDependencies(ctx context.Context, labels map[string]string, rd *remoteDeps) error {
+	for _, d := range deps.ParseClusterDependsOn(labels) {
+		hostID, ok := rd.resolve(d)
+		if !ok {
+			continue
+		}
+		key := store.ScopedKey(hostID, d.Container)
+		outcome, updated := rd.updated[key]
+		if !updated || outcome == "skipped" || rd.confirmed[key] {
+			continue
+		}
+		if outcome != "success" {
+			return fmt.Errorf("dependency %s update %s", d, outcome)
+		}
+		u.log.Info("waiting for remote dependency to become healthy", "dependency", d.String())
+		if err := u.waitForRemoteHealthy(ctx, hostID, d.Container); err != nil {
+			return fmt.Errorf("dependency %s: %w", d, err)
+		}
+		rd.confirmed[key] = true
+	}
+	return nil
+}
+
+// waitForRemoteHealthy polls a host's container list until the named
+// container is running and its healthcheck, if it has one, reports healthy.
+func (u *Updater) waitForRemoteHealthy(ctx context.Context, hostID, name string) error {
+	deadline := u.clock.Now().Add(remoteHealthTimeout)
+	for {
+		containers, err := u.cluster.ListContainers(ctx, hostID)
+		if err != nil {
+			return fmt.Errorf("list containers: %w", err)
+		}
+		for _, c := range containers {
+			if c.Name != name {
+				continue
+			}
+			switch {
+			case c.Health == "unhealthy":
+				return fmt.Errorf("unhealthy")
+			case c.State == "exited" || c.State == "dead":
+				return fmt.Errorf("not running (%s)", c.State)
+			case c.State == "running" && (c.Health == "" || c.Health == "healthy"):
+				return nil
+			}
+		}
+		if u.clock.Now().After(deadline) {
+			return fmt.Errorf("not healthy after %s", remoteHealthTimeout)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-u.clock.After(healthPollInterval):
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+)
+
+// mockClusterScanner serves canned remote containers. After a container is
+// updated, its host's listings report the states queued in health[key], one
+// per call, holding the last.
+type mockClusterScanner struct {
+	hosts      []HostContext
+	containers map[string][]RemoteContainer // host ID -> containers
+	health     map[string][]string          // scoped key -> health reported after update
+	updated    []string                     // scoped keys in dispatch order
+	listCalls  map[string]int
+}
+
+func (m *mockClusterScanner) ConnectedHosts() []string {
+	ids := make([]string, len(m.hosts))
+	for i, h := range m.hosts {
+		ids[i] = h.HostID
+	}
+	return ids
+}
+
+func (m *mockClusterScanner) HostInfo(hostID string) (HostContext, bool) {
+	for _, h := range m.hosts {
+		if h.HostID == hostID {
+			return h, true
+		}
+	}
+	return HostContext{}, false
+}
+
+func (m *mockClusterScanner) ListContainers(_ context.Context, hostID string) ([]RemoteContainer, error) {
+	m.listCalls[hostID]++
+	out := slices.Clone(m.containers[hostID])
+	for i, c := range out {
+		key := store.ScopedKey(hostID, c.Name)
+		if !slices.Contains(m.updated, key) {
+			continue
+		}
+		if states := m.health[key]; len(states) > 0 {
+			out[i].Health = states[0]
+			if len(states) > 1 {
+				m.health[key] = states[1:]
+			}
+		}
+	}
+	return out, nil
+}
+
+func (m *mockClusterScanner) UpdateContainer(_ context.Context, hostID, name, _, _ string) (RemoteUpdateResult, error) {
+	m.updated = append(m.updated, store.ScopedKey(hostID, name))
+	return RemoteUpdateResult{ContainerName: name, Outcome: "success"}, nil
+}
+
+// newCrossHostScan sets up "app" on app-host depending on "postgres" on
+// db-host. app-host is listed first so only dependency ordering puts db-host
+// ahead of it.
+func newCrossHostScan(t *testing.T, dbHealth ...string) (*Updater, *mockClusterScanner) {
+	t.Helper()
+	mock := newMockDocker()
+	mock.distDigests["fake.local/app:1"] = "sha256:newapp"
+	mock.distDigests["fake.local/pg:16"] = "sha256:newpg"
+
+	cs := &mockClusterScanner{
+		hosts: []HostContext{
+			{HostID: "h-app", HostName: "app-host"},
+			{HostID: "h-db", HostName: "db-host"},
+		},
+		containers: map[string][]RemoteContainer{
+			"h-app": {{Name: "app", Image: "fake.local/app:1", ImageDigest: "sha256:oldapp", State: "running",
+				Labels: map[string]string{"sentinel.policy": "auto", "sentinel.cluster-depends-on": "db-host/postgres"}}},
+			"h-db": {{Name: "postgres", Image: "fake.local/pg:16", ImageDigest: "sha256:oldpg", State: "running",
+				Labels: map[string]string{"sentinel.policy": "auto"}}},
+		},
+		health:    map[string][]string{"h-db::postgres": dbHealth},
+		listCalls: make(map[string]int),
+	}
+	u, _ := newTestUpdater(t, mock)
+	u.SetClusterScanner(cs)
+	return u, cs
+}
+
+func TestScanRemoteCrossHostDependencyOrder(t *testing.T) {
+	u, cs := newCrossHostScan(t, "starting", "starting", "healthy")
+	res := u.Scan(context.Background(), ScanScheduled)
+
+	want := []string{"h-db::postgres", "h-app::app"}
+	if !slices.Equal(cs.updated, want) {
+		t.Fatalf("update order = %v, want %v", cs.updated, want)
+	}
+	if res.Updated != 2 {
+		t.Errorf("Updated = %d, want 2", res.Updated)
+	}
+	// Listed by the queue prune and the remote scan, then polled three times
+	// until postgres reported healthy.
+	if cs.listCalls["h-db"] != 5 {
+		t.Errorf("db-host listed %d times, want 5", cs.listCalls["h-db"])
+	}
+}
+
+func TestScanRemoteCrossHostDependencyUnhealthy(t *testing.T) {
+	u, cs := newCrossHostScan(t, "unhealthy")
+	// Scan the hosts directly: the mock clock is frozen, so Scan's summary
+	// record would overwrite the deferred one (history is keyed by time).
+	var res ScanResult
+	u.scanRemoteHosts(context.Background(), ScanScheduled, &res, nil, 0)
+
+	if !slices.Equal(cs.updated, []string{"h-db::postgres"}) {
+		t.Fatalf("updated = %v, want only postgres", cs.updated)
+	}
+	if res.Skipped != 1 {
+		t.Errorf("Skipped = %d, want 1", res.Skipped)
+	}
+	recs, err := u.store.ListHistoryByContainer("h-app::app", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 1 || recs[0].Outcome != "deferred" || recs[0].FailureClass != store.FailurePrecondition {
+		t.Fatalf("app history = %+v, want one deferred record", recs)
+	}
+	if want := "dependency db-host/postgres: unhealthy"; recs[0].Error != want {
+		t.Errorf("Error = %q, want %q", recs[0].Error, want)
+	}
+}
+
+func TestScanRemoteCrossHostDependencyDisabled(t *testing.T) {
+	u, cs := newCrossHostScan(t, "unhealthy")
+	u.cfg.SetDependencyAware(false)
+	u.Scan(context.Background(), ScanScheduled)
+
+	want := []string{"h-app::app", "h-db::postgres"}
+	if !slices.Equal(cs.updated, want) {
+		t.Fatalf("update order = %v, want listed order %v", cs.updated, want)
+	}
+}
+
+func TestOrderRemoteHostsCycle(t *testing.T) {
+	dependsOn := func(ref string) []RemoteContainer {
+		return []RemoteContainer{{Name: "c", Labels: map[string]string{"sentinel.cluster-depends-on": ref}}}
+	}
+	scans := []remoteHostScan{
+		{id: "a", host: HostContext{HostName: "host-a"}, containers: dependsOn("host-b/c")},
+		{id: "b", host: HostContext{HostName: "host-b"}, containers: dependsOn("a/c")},
+		{id: "c", host: HostContext{HostName: "host-c"}},
+	}
+	ordered, cyclic := orderRemoteHosts(scans, newRemoteDeps(scans))
+	var ids []string
+	for _, s := range ordered {
+		ids = append(ids, s.id)
+	}
+	if !cyclic || fmt.Sprint(ids) != "[c a b]" {
+		t.Errorf("order = %v, cyclic = %v; want [c a b], true", ids, cyclic)
+	}
+}
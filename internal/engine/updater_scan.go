@@ -2,6 +2,7 @@ package engine
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -13,8 +14,8 @@ import (
 	"github.com/Will-Luck/Docker-Sentinel/internal/notify"
 	"github.com/Will-Luck/Docker-Sentinel/internal/registry"
 	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+	"github.com/moby/moby/api/types/container"
 	"github.com/moby/moby/api/types/swarm"
-	"github.com/robfig/cron/v3"
 )
 
 // ScanMode controls rate limit headroom during scans.
@@ -37,11 +38,14 @@ type ScanResult struct {
 	Failed      int
 	RateLimited int // containers skipped due to rate limits
 	UpToDate    int // containers where the registry confirmed no update
+	Capped      int // auto-updates held back by the max_auto_updates cap
 	Errors      []error
 
 	// Swarm service stats (only populated when Swarm mode is active).
 	Services       int
 	ServiceUpdates int
+
+	autoStarted int // auto-updates started this scan, checked against the cap
 }
 
 // ClusterScanner provides access to remote host containers for multi-host scanning.
@@ -115,6 +119,7 @@ type RemoteContainer struct {
 	Image       string
 	ImageDigest string
 	State       string
+	Health      string // healthcheck status; empty without a healthcheck
 	Labels      map[string]string
 }
 
@@ -128,10 +133,12 @@ type RemoteUpdateResult struct {
 	Outcome       string
 	Error         string
 	Duration      time.Duration
+	Hooks         []store.HookRun // hooks the agent ran around the update
 }
 
-// loadFilters reads filter patterns from the settings store.
-func (u *Updater) loadFilters() []string {
+// loadFilters reads the scan exclusion rules from the settings store.
+// Invalid lines are logged and skipped so one typo does not disable the rest.
+func (u *Updater) loadFilters() ScanFilters {
 	if u.settings == nil {
 		return nil
 	}
@@ -142,14 +149,19 @@ func (u *Updater) loadFilters() []string {
 	if val == "" {
 		return nil
 	}
-	var patterns []string
-	for _, p := range strings.Split(val, "\n") {
-		p = strings.TrimSpace(p)
-		if p != "" {
-			patterns = append(patterns, p)
+	var filters ScanFilters
+	for _, line := range strings.Split(val, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
 		}
+		f, err := ParseScanFilter(line)
+		if err != nil {
+			u.log.Warn("ignoring invalid scan filter", "filter", line, "error", err)
+			continue
+		}
+		filters = append(filters, f)
 	}
-	return patterns
+	return filters
 }
 
 // publishEvent emits an SSE event if the event bus is configured.
@@ -293,6 +305,21 @@ func (u *Updater) maintenanceWindow() string {
 	return u.cfg.MaintenanceWindow()
 }
 
+// updateWindow resolves the maintenance window governing a container's
+// auto-updates and where it came from. Precedence: sentinel.window label →
+// the Compose stack's override → the global window.
+func (u *Updater) updateWindow(labels map[string]string) (expr, source string) {
+	if expr := docker.ContainerWindow(labels); expr != "" {
+		return expr, "label"
+	}
+	if project := labels["com.docker.compose.project"]; project != "" {
+		if expr, ok := u.store.GetStackWindow(project); ok {
+			return expr, "stack"
+		}
+	}
+	return u.maintenanceWindow(), "global"
+}
+
 // Scan lists running containers, checks for updates, and processes them
 // according to each container's policy. The mode controls rate limit headroom.
 func (u *Updater) Scan(ctx context.Context, mode ScanMode) ScanResult {
@@ -304,12 +331,22 @@ func (u *Updater) Scan(ctx context.Context, mode ScanMode) ScanResult {
 		u.log.Info("scan concurrency enabled (experimental)", "concurrency", c)
 	}
 
+	// Expire timed pins first so their containers are evaluated in this scan.
+	u.reviewPins(ctx)
+
+	// Report updates that have been stuck in one phase since the last scan.
+	u.checkStuckUpdates()
+
+	// Remind about approvals left waiting in the queue.
+	u.reviewStaleQueue(ctx)
+
 	containers, err := u.docker.ListContainers(ctx)
 	if err != nil {
 		u.log.Error("failed to list containers", "error", err)
 		result.Errors = append(result.Errors, err)
 		return result
 	}
+	containers = u.dropUnmanaged(containers)
 	result.Total = len(containers)
 
 	// Discover registries and probe for fresh rate limit data.
@@ -426,10 +463,14 @@ func (u *Updater) Scan(ctx context.Context, mode ScanMode) ScanResult {
 		u.log.Info("pruned stale queue entries", "count", pruned)
 	}
 
+	// Pick up notification routing declared in sentinel.notify and
+	// sentinel.channels labels before anything is notified this scan.
+	u.syncLabelNotifyPrefs(containers, swarmServices)
+
 	// Publish scan start event so the UI can show a progress bar.
 	u.publishEvent(events.EventScanStart, "", fmt.Sprintf("total=%d", len(containers)))
 
-	// Load filter patterns once per scan.
+	// Load filter rules once per scan.
 	filters := u.loadFilters()
 
 	// Rate limit headroom depends on scan mode.
@@ -461,32 +502,31 @@ func (u *Updater) Scan(ctx context.Context, mode ScanMode) ScanResult {
 		selfContainer := isSentinel(labels)
 
 		// Skip containers matching filter patterns.
-		if MatchesFilter(name, filters) {
-			u.log.Debug("skipping filtered container", "name", name)
+		if excluded, rule := filters.Excluded(name, c.Image, labels); excluded {
+			u.log.Debug("skipping filtered container", "name", name, "filter", rule)
 			result.Skipped++
 			continue
 		}
 
 		// Per-container schedule check.
-		if sched := docker.ContainerSchedule(labels); sched != "" {
-			parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
-			schedule, err := parser.Parse(sched)
-			if err != nil {
-				u.log.Warn("invalid schedule", "name", name, "schedule", sched, "error", err)
-			} else {
-				lastChecked, _ := u.store.GetLastContainerScan(name)
-				if !lastChecked.IsZero() && u.clock.Now().Before(schedule.Next(lastChecked)) {
-					result.Skipped++
-					continue
-				}
-			}
+		if !u.scheduleDue(name, labels) {
+			result.Skipped++
+			continue
+		}
+
+		// Digest-pinned images are left alone unless sentinel.track-tag asks
+		// for the tag of a tag@digest reference to be followed.
+		imageRef, pinnedDigest, digestPinned := checkRef(c.Image, labels)
+		if digestPinned {
+			u.log.Debug("skipping digest-pinned container", "name", name, "image", c.Image)
+			result.Skipped++
+			continue
 		}
 
 		// Rate limit check: skip if registry quota is too low.
 		// Continue to next container — other registries may still be available.
-		imageRef := c.Image
 		if u.rateTracker != nil {
-			host := registry.RegistryHost(imageRef)
+			host := registry.RegistryHost(checkSourceRef(imageRef, labels))
 			canProceed, wait := u.rateTracker.CanProceed(host, reserve)
 			if !canProceed {
 				u.log.Debug("rate limit low, skipping container", "name", name, "registry", host, "resets_in", wait)
@@ -496,6 +536,7 @@ func (u *Updater) Scan(ctx context.Context, mode ScanMode) ScanResult {
 					OldImage:      imageRef,
 					Outcome:       "rate_limited",
 					Error:         "rate limit low on " + host,
+					FailureClass:  store.FailureRateLimited,
 				})
 				result.RateLimited++
 				continue
@@ -503,9 +544,7 @@ func (u *Updater) Scan(ctx context.Context, mode ScanMode) ScanResult {
 		}
 
 		// Check the registry for an update (versioned check also finds newer semver tags).
-		semverScope := docker.ContainerSemverScope(labels)
-		includeRE, excludeRE := docker.ContainerTagFilters(labels)
-		check := u.checker.CheckVersioned(ctx, imageRef, semverScope, includeRE, excludeRE)
+		check := u.checkVersions(ctx, imageRef, pinnedDigest, labels)
 
 		if check.Error != nil {
 			u.log.Warn("registry check failed", "name", name, "image", imageRef, "error", check.Error)
@@ -521,7 +560,9 @@ func (u *Updater) Scan(ctx context.Context, mode ScanMode) ScanResult {
 		}
 
 		if check.IsLocal {
-			u.log.Debug("local/unresolvable image, skipping", "name", name, "image", imageRef)
+			if !u.checkBaseImage(ctx, name, imageRef, c.ImageID, labels) {
+				u.log.Debug("local/unresolvable image, skipping", "name", name, "image", imageRef)
+			}
 			result.Skipped++
 			continue
 		}
@@ -530,6 +571,8 @@ func (u *Updater) Scan(ctx context.Context, mode ScanMode) ScanResult {
 		_ = u.store.SetLastContainerScan(name, u.clock.Now())
 
 		if !check.UpdateAvailable {
+			u.recordVersion(name, labels, imageRef, check)
+
 			// Prune stale queue entries: if this container is in the queue
 			// but the registry now reports it as up-to-date, remove it.
 			if _, queued := u.queue.Get(name); queued {
@@ -572,11 +615,14 @@ func (u *Updater) Scan(ctx context.Context, mode ScanMode) ScanResult {
 				}
 				if len(filtered) == 0 {
 					u.log.Debug("all newer versions ignored", "name", name, "ignored", ignored)
+					// Nothing to offer: record the running version only.
+					u.recordVersion(name, labels, imageRef, registry.CheckResult{ResolvedCurrentVersion: check.ResolvedCurrentVersion})
 					continue
 				}
 				check.NewerVersions = filtered
 			}
 		}
+		ver := u.recordVersion(name, labels, imageRef, check)
 
 		u.log.Info("update available", "name", name, "image", imageRef,
 			"local_digest", check.LocalDigest, "remote_digest", check.RemoteDigest)
@@ -607,12 +653,17 @@ func (u *Updater) Scan(ctx context.Context, mode ScanMode) ScanResult {
 
 		notifyOK := false
 		if shouldNotify {
+			releaseURL, hint := u.releaseDetails(ctx, checkSourceRef(imageRef, labels), labels, check.NewerVersions)
 			notifyOK = u.notifier.Notify(ctx, notify.Event{
 				Type:          notify.EventUpdateAvailable,
 				ContainerName: name,
 				OldImage:      imageRef,
 				OldDigest:     check.LocalDigest,
 				NewDigest:     check.RemoteDigest,
+				ReleaseURL:    releaseURL,
+				ReleaseHint:   hint,
+				OldVersion:    ver.Current,
+				NewVersion:    ver.Candidate,
 				Timestamp:     u.clock.Now(),
 			})
 		}
@@ -666,6 +717,7 @@ func (u *Updater) Scan(ctx context.Context, mode ScanMode) ScanResult {
 				RemoteDigest:           check.RemoteDigest,
 				DetectedAt:             u.clock.Now(),
 				NewerVersions:          check.NewerVersions,
+				Candidates:             check.Candidates,
 				ResolvedCurrentVersion: check.ResolvedCurrentVersion,
 				ResolvedTargetVersion:  check.ResolvedTargetVersion,
 			})
@@ -696,19 +748,12 @@ func (u *Updater) Scan(ctx context.Context, mode ScanMode) ScanResult {
 				if target == "" {
 					target = imageRef
 				}
-				if err := u.docker.PullImage(ctx, target); err != nil {
+				if err := u.pullOnly(ctx, name, imageRef, target, labels); err != nil {
 					u.log.Error("pull-only failed", "name", name, "error", err)
 					result.Failed++
 					result.Errors = append(result.Errors, fmt.Errorf("%s: pull-only: %w", name, err))
 					continue
 				}
-				_ = u.store.RecordUpdate(store.UpdateRecord{
-					Timestamp:     u.clock.Now(),
-					ContainerName: name,
-					OldImage:      imageRef,
-					NewImage:      target,
-					Outcome:       "pull_only",
-				})
 				result.Updated++
 				continue
 			}
@@ -734,26 +779,74 @@ func (u *Updater) Scan(ctx context.Context, mode ScanMode) ScanResult {
 				}
 			}
 			// Maintenance window check: skip auto-update if outside window.
-			if windowExpr := u.maintenanceWindow(); windowExpr != "" {
-				win, err := ParseWindow(windowExpr)
+			if windowExpr, source := u.updateWindow(labels); windowExpr != "" {
+				win, err := ParseWindowOverride(windowExpr)
 				if err != nil {
-					u.log.Warn("invalid maintenance window, proceeding with update (fail-open)", "name", name, "window", windowExpr, "error", err)
+					u.log.Warn("invalid maintenance window, proceeding with update (fail-open)", "name", name, "window", windowExpr, "source", source, "error", err)
 				} else if win != nil && !win.IsOpen(u.clock.Now()) {
-					u.log.Info("outside maintenance window, deferring auto-update", "name", name, "window", windowExpr)
+					u.log.Info("outside maintenance window, deferring auto-update", "name", name, "window", windowExpr, "source", source)
 					result.Skipped++
 					continue
 				}
 			}
-			if err := u.UpdateContainer(ctx, c.ID, name, scanTarget); err != nil {
+			// Retry check: skip containers backing off or marked failing.
+			if blocked, reason := u.retryBlocked(name); blocked {
+				u.log.Info("skipping auto-update", "name", name, "reason", reason)
+				result.Skipped++
+				continue
+			}
+			// Staged rollout check: later groups wait for earlier ones to bake.
+			rolloutKey, reason := u.rolloutGate(labels, imageRef, rolloutTarget(scanTarget, check.RemoteDigest), containers)
+			if reason != "" {
+				u.log.Info("skipping auto-update", "name", name, "rollout", rolloutKey, "reason", reason)
+				result.Skipped++
+				continue
+			}
+			if !u.claimAutoSlot(&result) {
+				u.overflowAutoUpdate(&result, name, PendingUpdate{
+					ContainerID:            c.ID,
+					ContainerName:          name,
+					CurrentImage:           imageRef,
+					CurrentDigest:          check.LocalDigest,
+					RemoteDigest:           check.RemoteDigest,
+					NewerVersions:          check.NewerVersions,
+					Candidates:             check.Candidates,
+					ResolvedCurrentVersion: check.ResolvedCurrentVersion,
+					ResolvedTargetVersion:  check.ResolvedTargetVersion,
+				})
+				continue
+			}
+			updateCtx := ctx
+			if _, retrying := u.store.GetRetryState(name); retrying {
+				updateCtx = withRetryAttempt(ctx)
+			}
+			if err := u.UpdateContainer(updateCtx, c.ID, name, scanTarget); errors.Is(err, ErrPreconditionDeferred) || errors.Is(err, ErrPreconditionSkipped) {
+				// Recorded in history already; the next scan checks again.
+				result.Skipped++
+			} else if errors.Is(err, ErrScanBlocked) {
+				// Queued with the scan report; fill in what the registry check knows.
+				if queued, ok := u.queue.Get(name); ok {
+					queued.CurrentDigest = check.LocalDigest
+					queued.RemoteDigest = check.RemoteDigest
+					queued.NewerVersions = check.NewerVersions
+					queued.ResolvedCurrentVersion = check.ResolvedCurrentVersion
+					queued.ResolvedTargetVersion = check.ResolvedTargetVersion
+					u.queue.Add(queued)
+				}
+				result.Queued++
+			} else if err != nil {
 				u.log.Error("auto-update failed", "name", name, "error", err)
+				u.recordAutoUpdateFailure(ctx, name, err)
+				u.rolloutFailed(ctx, rolloutKey, name, err)
 				result.Failed++
 				result.Errors = append(result.Errors, err)
 			} else {
+				u.rolloutUpdated(rolloutKey, name, labels)
 				result.Updated++
 			}
 
 		case docker.PolicyManual:
-			u.queue.Add(PendingUpdate{
+			pending := PendingUpdate{
 				ContainerID:            c.ID,
 				ContainerName:          name,
 				CurrentImage:           imageRef,
@@ -761,9 +854,14 @@ func (u *Updater) Scan(ctx context.Context, mode ScanMode) ScanResult {
 				RemoteDigest:           check.RemoteDigest,
 				DetectedAt:             u.clock.Now(),
 				NewerVersions:          check.NewerVersions,
+				Candidates:             check.Candidates,
 				ResolvedCurrentVersion: check.ResolvedCurrentVersion,
 				ResolvedTargetVersion:  check.ResolvedTargetVersion,
-			})
+			}
+			if u.configDiffEnabled(labels) {
+				pending.ConfigDiff = u.queuedConfigDiff(ctx, c, pending, scanTarget)
+			}
+			u.queue.Add(pending)
 			u.log.Info("update queued for manual approval", "name", name)
 			u.publishEvent(events.EventQueueChange, name, "queued for approval")
 			result.Queued++
@@ -825,6 +923,7 @@ func (u *Updater) Scan(ctx context.Context, mode ScanMode) ScanResult {
 	}
 
 	metrics.ScansTotal.Inc()
+	metrics.LastScanTimestamp.Set(float64(u.clock.Now().Unix()))
 	metrics.ContainersTotal.Set(float64(result.Total))
 	metrics.ContainersMonitored.Set(float64(result.Total - result.Skipped))
 	metrics.PendingUpdates.Set(float64(result.Queued))
@@ -857,3 +956,85 @@ func (u *Updater) Scan(ctx context.Context, mode ScanMode) ScanResult {
 
 	return result
 }
+
+// releaseLookupTimeout bounds the GitHub lookups made for one notification.
+// Results are cached for an hour, so only the first notification for a
+// repository waits on GitHub.
+const releaseLookupTimeout = 3 * time.Second
+
+// releaseDetails returns the GitHub release link for the newest version and
+// a hint about the project's releases (possibly end-of-life, or a newer
+// GitHub release than the target), for update-available notifications.
+// Either is "" when release sources are not wired or nothing was found in
+// time.
+func (u *Updater) releaseDetails(ctx context.Context, imageRef string, labels map[string]string, newer []string) (url, hint string) {
+	if u.releaseSources == nil {
+		return "", ""
+	}
+	sources := u.releaseSources()
+	ctx, cancel := context.WithTimeout(ctx, releaseLookupTimeout)
+	defer cancel()
+
+	version := ""
+	if len(newer) > 0 {
+		version = newer[0]
+		var info *registry.ReleaseInfo
+		if u.releaseNotes != nil {
+			info = u.releaseNotes(ctx, imageRef, version)
+		} else {
+			info = registry.FetchReleaseNotesWithSources(ctx, imageRef, version, sources)
+		}
+		if info != nil {
+			url = info.URL
+		}
+	}
+
+	repo, _ := registry.ResolveGitHubRepo(imageRef, labels, sources)
+	if repo == "" {
+		return url, ""
+	}
+	var repoInfo *registry.RepoReleaseInfo
+	var err error
+	if u.repoRelease != nil {
+		repoInfo, err = u.repoRelease(ctx, repo)
+	} else {
+		repoInfo, err = registry.FetchRepoReleaseInfo(ctx, repo, false)
+	}
+	if err != nil {
+		u.log.Debug("release info lookup failed", "repo", repo, "error", err)
+		return url, ""
+	}
+	return url, releaseHint(repoInfo, version)
+}
+
+// releaseHint summarises a repository's release state for a notification.
+func releaseHint(info *registry.RepoReleaseInfo, version string) string {
+	if info == nil {
+		return ""
+	}
+	if info.EOL {
+		return "possibly end-of-life: " + info.EOLReason
+	}
+	if info.Latest != nil && version != "" && strings.TrimPrefix(info.Latest.Tag, "v") != strings.TrimPrefix(version, "v") {
+		return "latest GitHub release is " + info.Latest.Tag
+	}
+	return ""
+}
+
+// dropUnmanaged removes containers the user has marked as unmanaged. They
+// are excluded before anything else so they never count towards totals,
+// reach the queue, or trigger notifications.
+func (u *Updater) dropUnmanaged(containers []container.Summary) []container.Summary {
+	unmanaged := u.store.AllUnmanaged()
+	if len(unmanaged) == 0 {
+		return containers
+	}
+	kept := containers[:0]
+	for _, c := range containers {
+		if _, ok := unmanaged[containerName(c)]; ok {
+			continue
+		}
+		kept = append(kept, c)
+	}
+	return kept
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/Will-Luck/Docker-Sentinel/internal/logging"
 	"github.com/Will-Luck/Docker-Sentinel/internal/notify"
 	"github.com/Will-Luck/Docker-Sentinel/internal/registry"
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
 	"github.com/moby/moby/api/types/container"
 )
 
@@ -767,6 +768,84 @@ func TestUpdateFinaliseRemoveFailureTriggersRollback(t *testing.T) {
 	}
 }
 
+func TestUpdatePullFailureRecordsClass(t *testing.T) {
+	mock, u := setupUpdateMock(t)
+	mock.pullErr["nginx:latest"] = fmt.Errorf("manifest unknown")
+
+	if err := u.UpdateContainer(context.Background(), "aaa", "nginx", ""); err == nil {
+		t.Fatal("expected error from pull failure")
+	}
+
+	history, err := u.store.ListHistory(10, "")
+	if err != nil {
+		t.Fatalf("ListHistory: %v", err)
+	}
+	if len(history) != 1 {
+		t.Fatalf("history len = %d, want 1", len(history))
+	}
+	if history[0].FailureClass != store.FailurePullError {
+		t.Errorf("failure class = %q, want %q", history[0].FailureClass, store.FailurePullError)
+	}
+	if _, ok := history[0].Stages["pull"]; !ok {
+		t.Error("expected pull stage timing to be recorded")
+	}
+}
+
+func TestUpdateSuccessRecordsStageTimings(t *testing.T) {
+	_, u := setupUpdateMock(t)
+
+	if err := u.UpdateContainer(context.Background(), "aaa", "nginx", ""); err != nil {
+		t.Fatalf("UpdateContainer: %v", err)
+	}
+
+	history, err := u.store.ListHistory(10, "")
+	if err != nil {
+		t.Fatalf("ListHistory: %v", err)
+	}
+	if len(history) != 1 {
+		t.Fatalf("history len = %d, want 1", len(history))
+	}
+	if history[0].FailureClass != "" {
+		t.Errorf("failure class = %q, want empty on success", history[0].FailureClass)
+	}
+	for _, stage := range []string{"pull", "stop", "create", "validate", "finalise"} {
+		if _, ok := history[0].Stages[stage]; !ok {
+			t.Errorf("missing stage timing %q", stage)
+		}
+	}
+}
+
+func TestUpdateValidationFailureRecordsHealthFailed(t *testing.T) {
+	mock, u := setupUpdateMock(t)
+	mock.inspectResults["new-nginx"] = container.InspectResponse{
+		ID:     "new-nginx",
+		Name:   "/nginx",
+		State:  &container.State{Running: false, Restarting: true},
+		Config: &container.Config{Image: "nginx:latest"},
+	}
+
+	if err := u.UpdateContainer(context.Background(), "aaa", "nginx", ""); err == nil {
+		t.Fatal("expected error from failed validation")
+	}
+
+	history, err := u.store.ListHistory(10, "")
+	if err != nil {
+		t.Fatalf("ListHistory: %v", err)
+	}
+	found := false
+	for _, h := range history {
+		if h.Outcome == "rollback" {
+			found = true
+			if h.FailureClass != store.FailureHealthFailed {
+				t.Errorf("failure class = %q, want %q", h.FailureClass, store.FailureHealthFailed)
+			}
+		}
+	}
+	if !found {
+		t.Error("expected a rollback record")
+	}
+}
+
 // TestUpdateFinaliseInspectFailureRecordsWarning tests that an inspect failure
 // during finalise (non-destructive) does not trigger rollback.
 func TestUpdateFinaliseInspectFailureRecordsWarning(t *testing.T) {
@@ -1126,3 +1205,77 @@ func TestUpdateContainerImageIDGuardProceedsOnDifferentID(t *testing.T) {
 		t.Errorf("createCalls = %d, want >= 1", len(mock.createCalls))
 	}
 }
+
+func TestReleaseDetailsFetchesOnFirstNotification(t *testing.T) {
+	u, _ := newTestUpdater(t, newMockDocker())
+	u.SetReleaseSources(func() []registry.ReleaseSource {
+		return []registry.ReleaseSource{{ImagePattern: "eol-app", GitHubRepo: "example/eol-app"}}
+	})
+	u.releaseNotes = func(_ context.Context, imageRef, version string) *registry.ReleaseInfo {
+		return &registry.ReleaseInfo{URL: "https://github.com/example/eol-app/releases/tag/" + version}
+	}
+	u.repoRelease = func(_ context.Context, repo string) (*registry.RepoReleaseInfo, error) {
+		if repo != "example/eol-app" {
+			t.Errorf("repo = %q, want example/eol-app", repo)
+		}
+		return &registry.RepoReleaseInfo{EOL: true, EOLReason: "source repository is archived"}, nil
+	}
+
+	url, hint := u.releaseDetails(context.Background(), "eol-app:1.0.0", nil, []string{"1.1.0"})
+	if url != "https://github.com/example/eol-app/releases/tag/1.1.0" {
+		t.Errorf("url = %q, want the release page on the first lookup", url)
+	}
+	if hint != "possibly end-of-life: source repository is archived" {
+		t.Errorf("hint = %q", hint)
+	}
+
+	if _, hint := u.releaseDetails(context.Background(), "unmapped/app:1.0.0", nil, []string{"1.1.0"}); hint != "" {
+		t.Errorf("unmapped image hint = %q, want empty", hint)
+	}
+}
+
+func TestReleaseDetailsBoundedByTimeout(t *testing.T) {
+	u, _ := newTestUpdater(t, newMockDocker())
+	u.SetReleaseSources(func() []registry.ReleaseSource {
+		return []registry.ReleaseSource{{ImagePattern: "slow-app", GitHubRepo: "example/slow-app"}}
+	})
+	u.releaseNotes = func(ctx context.Context, _, _ string) *registry.ReleaseInfo {
+		<-ctx.Done()
+		return nil
+	}
+	u.repoRelease = func(ctx context.Context, _ string) (*registry.RepoReleaseInfo, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	start := time.Now()
+	url, hint := u.releaseDetails(context.Background(), "slow-app:1.0.0", nil, []string{"1.1.0"})
+	if url != "" || hint != "" {
+		t.Errorf("releaseDetails = %q, %q, want empty after timeout", url, hint)
+	}
+	if elapsed := time.Since(start); elapsed > 2*releaseLookupTimeout {
+		t.Errorf("releaseDetails took %v, want at most %v", elapsed, 2*releaseLookupTimeout)
+	}
+}
+
+func TestReleaseHint(t *testing.T) {
+	tests := []struct {
+		name    string
+		info    *registry.RepoReleaseInfo
+		version string
+		want    string
+	}{
+		{"nil", nil, "1.1.0", ""},
+		{"eol", &registry.RepoReleaseInfo{EOL: true, EOLReason: "no release in over 2 years", Latest: &registry.LatestRelease{Tag: "v1.1.0"}}, "1.1.0", "possibly end-of-life: no release in over 2 years"},
+		{"latest matches", &registry.RepoReleaseInfo{Latest: &registry.LatestRelease{Tag: "v1.1.0"}}, "1.1.0", ""},
+		{"latest differs", &registry.RepoReleaseInfo{Latest: &registry.LatestRelease{Tag: "v1.2.0"}}, "1.1.0", "latest GitHub release is v1.2.0"},
+		{"no releases", &registry.RepoReleaseInfo{}, "1.1.0", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := releaseHint(tt.info, tt.version); got != tt.want {
+				t.Errorf("releaseHint = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/moby/moby/api/types/container"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/docker"
+)
+
+// Validation strategies for the post-update check.
+const (
+	ValidateAuto        = ""            // healthcheck when defined, otherwise running state
+	ValidateState       = "state"       // running and not restarting; healthchecks ignored
+	ValidateHealthcheck = "healthcheck" // a healthcheck must be defined and report healthy
+	ValidateProbe       = "probe"       // running, then an HTTP or TCP probe must succeed
+)
+
+const probeTimeout = 5 * time.Second
+
+var (
+	// errNoHealthcheck is returned by the healthcheck strategy when the new
+	// container defines no healthcheck.
+	errNoHealthcheck = errors.New("healthcheck validation requested but container has no healthcheck")
+
+	// errProbeTimeout is returned when a probe never succeeded within the
+	// health timeout.
+	errProbeTimeout = errors.New("probe timeout waiting for success")
+)
+
+// isValidationTimeout reports whether a validation error means the check
+// never settled, as opposed to an explicit failure.
+func isValidationTimeout(err error) bool {
+	return errors.Is(err, errHealthTimeout) || errors.Is(err, errProbeTimeout) || errors.Is(err, errValidatorTimeout)
+}
+
+// ValidStrategy reports whether s is a recognised validation strategy.
+func ValidStrategy(s string) bool {
+	switch s {
+	case ValidateAuto, ValidateState, ValidateHealthcheck, ValidateProbe:
+		return true
+	}
+	return false
+}
+
+// validationPlan is the effective grace period and strategy for one update.
+type validationPlan struct {
+	grace         time.Duration
+	strategy      string
+	probe         string
+	expect        probeExpect   // extra conditions on an HTTP probe's response
+	healthTimeout time.Duration // how long a healthcheck, probe or external validator may take to pass
+	webhook       string        // external validation URL, called after the built-in checks pass
+	command       string        // external validation command, run after the webhook
+}
+
+// validationPlanFor resolves a container's validation settings.
+// Precedence: store override → label → global config.
+func (u *Updater) validationPlanFor(name string, labels map[string]string) validationPlan {
+	plan := validationPlan{
+		grace:         u.cfg.GracePeriod(),
+		strategy:      docker.ContainerValidation(labels),
+		probe:         docker.ContainerProbe(labels),
+		expect:        probeExpect{status: docker.ContainerProbeStatus(labels), body: docker.ContainerProbeBody(labels)},
+		healthTimeout: u.healthTimeout(),
+	}
+	plan.webhook, plan.command = u.externalValidators(labels)
+	if g := docker.ContainerGracePeriod(labels); g > 0 {
+		plan.grace = g
+	}
+	if t := docker.ContainerHealthTimeout(labels); t > 0 {
+		plan.healthTimeout = t
+	}
+	if o, ok := u.store.GetValidationOverride(name); ok {
+		if o.GracePeriod > 0 {
+			plan.grace = o.GracePeriod
+		}
+		if o.HealthTimeout > 0 {
+			plan.healthTimeout = o.HealthTimeout
+		}
+		if o.Strategy != "" {
+			plan.strategy = o.Strategy
+		}
+		if o.ProbeURL != "" {
+			plan.probe = o.ProbeURL
+		}
+		if o.ProbeStatus != 0 {
+			plan.expect.status = o.ProbeStatus
+		}
+		if o.ProbeBody != "" {
+			plan.expect.body = o.ProbeBody
+		}
+	}
+	return plan
+}
+
+// healthTimeout reads the health_timeout setting, falling back to
+// defaultHealthTimeout.
+func (u *Updater) healthTimeout() time.Duration {
+	if u.settings != nil {
+		if val, err := u.settings.LoadSetting("health_timeout"); err == nil && val != "" {
+			if d, err := docker.ParseDurationWithDays(val); err == nil && d > 0 {
+				return d
+			}
+		}
+	}
+	return defaultHealthTimeout
+}
+
+// waitForProbe polls the probe target until it succeeds or timeout expires.
+func (u *Updater) waitForProbe(ctx context.Context, inspect container.InspectResponse, rawURL string, expect probeExpect, timeout time.Duration) (bool, error) {
+	target, err := probeTarget(inspect, rawURL)
+	if err != nil {
+		return false, err
+	}
+	probe := u.probeFn
+	if probe == nil {
+		probe = func(ctx context.Context, target *url.URL) error {
+			return runProbeExpect(ctx, target, expect)
+		}
+	}
+	deadline := u.clock.Now().Add(timeout)
+	for {
+		err := probe(ctx, target)
+		if err == nil {
+			return true, nil
+		}
+		u.log.Debug("probe failed, retrying", "target", target.String(), "error", err)
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-u.clock.After(healthPollInterval):
+			if u.clock.Now().After(deadline) {
+				u.log.Warn("probe timeout waiting for success", "target", target.String())
+				return false, errProbeTimeout
+			}
+		}
+	}
+}
+
+// probeTarget resolves a probe URL against the container. An empty host is
+// replaced with the container's first network IP address.
+func probeTarget(inspect container.InspectResponse, rawURL string) (*url.URL, error) {
+	if rawURL == "" {
+		return nil, errors.New("probe validation requested but no probe URL set")
+	}
+	u, err := parseProbeURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Hostname() == "" {
+		ip := containerIP(inspect)
+		if ip == "" {
+			return nil, errors.New("probe URL has no host and container has no IP address")
+		}
+		if port := u.Port(); port != "" {
+			u.Host = net.JoinHostPort(ip, port)
+		} else {
+			u.Host = ip
+		}
+	}
+	return u, nil
+}
+
+// ValidateProbeURL checks that a probe URL is usable: http, https or tcp.
+func ValidateProbeURL(rawURL string) error {
+	_, err := parseProbeURL(rawURL)
+	return err
+}
+
+func parseProbeURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid probe URL %q: %w", rawURL, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "tcp":
+		return u, nil
+	default:
+		return nil, fmt.Errorf("invalid probe URL %q: scheme must be http, https or tcp", rawURL)
+	}
+}
+
+// containerIP returns the first valid network IP of a container.
+func containerIP(inspect container.InspectResponse) string {
+	if inspect.NetworkSettings == nil {
+		return ""
+	}
+	for _, ep := range inspect.NetworkSettings.Networks {
+		if ep != nil && ep.IPAddress.IsValid() {
+			return ep.IPAddress.String()
+		}
+	}
+	return ""
+}
+
+// probeExpect narrows what counts as a passing HTTP probe. The zero value
+// accepts any status below 400. TCP probes ignore it.
+type probeExpect struct {
+	status int    // exact status code required; 0 = any below 400
+	body   string // substring the response body must contain
+}
+
+// probeBodyLimit caps how much of a response body is searched for
+// probeExpect.body.
+const probeBodyLimit = 64 << 10
+
+// runProbe performs a single HTTP GET (any status below 400 passes) or TCP
+// connect against target.
+func runProbe(ctx context.Context, target *url.URL) error {
+	return runProbeExpect(ctx, target, probeExpect{})
+}
+
+// runProbeExpect is runProbe with the response checked against expect.
+func runProbeExpect(ctx context.Context, target *url.URL, expect probeExpect) error {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	if target.Scheme == "tcp" {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", target.Host)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch {
+	case expect.status != 0 && resp.StatusCode != expect.status:
+		return fmt.Errorf("probe returned HTTP %d, want %d", resp.StatusCode, expect.status)
+	case expect.status == 0 && resp.StatusCode >= 400:
+		return fmt.Errorf("probe returned HTTP %d", resp.StatusCode)
+	}
+	if expect.body == "" {
+		return nil
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, probeBodyLimit))
+	if err != nil {
+		return fmt.Errorf("read probe response: %w", err)
+	}
+	if !strings.Contains(string(data), expect.body) {
+		return fmt.Errorf("probe response does not contain %q", expect.body)
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/api/types/network"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+)
+
+func TestValidationPlanPrecedence(t *testing.T) {
+	u, _ := newTestUpdater(t, newMockDocker())
+	u.cfg.SetGracePeriod(30 * time.Second)
+	labels := map[string]string{
+		"sentinel.grace-period": "5s",
+		"sentinel.validate":     "state",
+	}
+
+	plan := u.validationPlanFor("app", nil)
+	if plan.grace != 30*time.Second || plan.strategy != ValidateAuto {
+		t.Errorf("global plan = %+v, want 30s auto", plan)
+	}
+
+	plan = u.validationPlanFor("app", labels)
+	if plan.grace != 5*time.Second || plan.strategy != ValidateState {
+		t.Errorf("label plan = %+v, want 5s state", plan)
+	}
+
+	if err := u.store.SetValidationOverride("app", store.ValidationOverride{GracePeriod: 2 * time.Minute, Strategy: ValidateProbe, ProbeURL: "tcp://:5432"}); err != nil {
+		t.Fatal(err)
+	}
+	plan = u.validationPlanFor("app", labels)
+	if plan.grace != 2*time.Minute || plan.strategy != ValidateProbe || plan.probe != "tcp://:5432" {
+		t.Errorf("override plan = %+v, want 2m probe tcp://:5432", plan)
+	}
+}
+
+func TestValidateContainerStrategies(t *testing.T) {
+	mock := newMockDocker()
+	mock.inspectResults["unhealthy"] = container.InspectResponse{
+		ID: "unhealthy",
+		State: &container.State{
+			Running: true,
+			Health:  &container.Health{Status: "unhealthy"},
+		},
+	}
+	mock.inspectResults["nohealth"] = container.InspectResponse{
+		ID:    "nohealth",
+		State: &container.State{Running: true},
+		NetworkSettings: &container.NetworkSettings{
+			Networks: map[string]*network.EndpointSettings{
+				"bridge": {IPAddress: netip.MustParseAddr("172.17.0.5")},
+			},
+		},
+	}
+	u, clk := newTestUpdater(t, mock)
+	ctx := context.Background()
+
+	// Auto honours the healthcheck; state ignores it.
+	if ok, _ := u.validateContainer(ctx, "unhealthy", validationPlan{}); ok {
+		t.Error("auto: unhealthy container passed validation")
+	}
+	if ok, err := u.validateContainer(ctx, "unhealthy", validationPlan{strategy: ValidateState}); !ok || err != nil {
+		t.Errorf("state: got (%v, %v), want pass", ok, err)
+	}
+
+	// Healthcheck strategy requires a healthcheck.
+	if _, err := u.validateContainer(ctx, "nohealth", validationPlan{strategy: ValidateHealthcheck}); !errors.Is(err, errNoHealthcheck) {
+		t.Errorf("healthcheck: error = %v, want errNoHealthcheck", err)
+	}
+
+	// Probe targets the container IP when the URL has no host.
+	var probed string
+	u.probeFn = func(_ context.Context, target *url.URL) error {
+		probed = target.String()
+		return nil
+	}
+	if ok, err := u.validateContainer(ctx, "nohealth", validationPlan{strategy: ValidateProbe, probe: "http://:8080/healthz"}); !ok || err != nil {
+		t.Errorf("probe: got (%v, %v), want pass", ok, err)
+	}
+	if probed != "http://172.17.0.5:8080/healthz" {
+		t.Errorf("probed %q, want http://172.17.0.5:8080/healthz", probed)
+	}
+
+	// A probe that never succeeds times out.
+	u.probeFn = func(context.Context, *url.URL) error {
+		clk.Advance(10 * time.Second)
+		return errors.New("connection refused")
+	}
+	if _, err := u.validateContainer(ctx, "nohealth", validationPlan{strategy: ValidateProbe, probe: "tcp://:5432"}); !errors.Is(err, errProbeTimeout) {
+		t.Errorf("failing probe: error = %v, want errProbeTimeout", err)
+	}
+}
+
+func TestValidateProbeURL(t *testing.T) {
+	for _, ok := range []string{"http://:8080/health", "https://app.local/", "tcp://:5432"} {
+		if err := ValidateProbeURL(ok); err != nil {
+			t.Errorf("ValidateProbeURL(%q) = %v, want nil", ok, err)
+		}
+	}
+	for _, bad := range []string{"ftp://host/", "localhost:80", "::bad"} {
+		if err := ValidateProbeURL(bad); err == nil {
+			t.Errorf("ValidateProbeURL(%q) = nil, want error", bad)
+		}
+	}
+}
+
+func TestValidationHealthTimeoutPrecedence(t *testing.T) {
+	u, _ := newTestUpdater(t, newMockDocker())
+	u.SetSettingsReader(u.store)
+	labels := map[string]string{"sentinel.health-timeout": "5m"}
+
+	if plan := u.validationPlanFor("app", nil); plan.healthTimeout != defaultHealthTimeout {
+		t.Errorf("default health timeout = %s, want %s", plan.healthTimeout, defaultHealthTimeout)
+	}
+	if err := u.store.SaveSetting("health_timeout", "2m"); err != nil {
+		t.Fatal(err)
+	}
+	if plan := u.validationPlanFor("app", nil); plan.healthTimeout != 2*time.Minute {
+		t.Errorf("setting health timeout = %s, want 2m", plan.healthTimeout)
+	}
+	if plan := u.validationPlanFor("app", labels); plan.healthTimeout != 5*time.Minute {
+		t.Errorf("label health timeout = %s, want 5m", plan.healthTimeout)
+	}
+	if err := u.store.SetValidationOverride("app", store.ValidationOverride{HealthTimeout: 90 * time.Second}); err != nil {
+		t.Fatal(err)
+	}
+	if plan := u.validationPlanFor("app", labels); plan.healthTimeout != 90*time.Second {
+		t.Errorf("override health timeout = %s, want 90s", plan.healthTimeout)
+	}
+}
+
+// tickingDocker advances the clock on every inspect, as if each healthcheck
+// poll took step.
+type tickingDocker struct {
+	*mockDocker
+	clk  *mockClock
+	step time.Duration
+}
+
+func (d *tickingDocker) InspectContainer(ctx context.Context, id string) (container.InspectResponse, error) {
+	d.clk.Advance(d.step)
+	return d.mockDocker.InspectContainer(ctx, id)
+}
+
+func TestValidateContainerHealthTimeout(t *testing.T) {
+	mock := newMockDocker()
+	mock.inspectResults["starting"] = container.InspectResponse{
+		ID:    "starting",
+		State: &container.State{Running: true, Health: &container.Health{Status: "starting"}},
+	}
+	u, clk := newTestUpdater(t, mock)
+	u.docker = &tickingDocker{mockDocker: mock, clk: clk, step: healthPollInterval}
+	start := clk.Now()
+
+	_, err := u.validateContainer(context.Background(), "starting", validationPlan{healthTimeout: 10 * time.Second})
+	if !errors.Is(err, errHealthTimeout) {
+		t.Fatalf("error = %v, want errHealthTimeout", err)
+	}
+	if waited := clk.Since(start); waited < 10*time.Second || waited > 10*time.Second+2*healthPollInterval {
+		t.Errorf("waited %s, want about 10s", waited)
+	}
+
+	// A healthcheck that turns unhealthy fails straight away.
+	mock.inspectResults["starting"] = container.InspectResponse{
+		ID:    "starting",
+		State: &container.State{Running: true, Health: &container.Health{Status: "unhealthy"}},
+	}
+	if ok, err := u.validateContainer(context.Background(), "starting", validationPlan{healthTimeout: time.Hour}); ok || err != nil {
+		t.Errorf("unhealthy: got (%v, %v), want fail without error", ok, err)
+	}
+}
+
+func TestProbeExpectPrecedence(t *testing.T) {
+	u, _ := newTestUpdater(t, newMockDocker())
+	labels := map[string]string{"sentinel.probe-status": "204", "sentinel.probe-body": "ready"}
+
+	if plan := u.validationPlanFor("app", labels); plan.expect != (probeExpect{status: 204, body: "ready"}) {
+		t.Errorf("label expect = %+v, want 204 ready", plan.expect)
+	}
+	if err := u.store.SetValidationOverride("app", store.ValidationOverride{ProbeBody: "healthy"}); err != nil {
+		t.Fatal(err)
+	}
+	if plan := u.validationPlanFor("app", labels); plan.expect != (probeExpect{status: 204, body: "healthy"}) {
+		t.Errorf("override expect = %+v, want label status with override body", plan.expect)
+	}
+}
+
+func TestRunProbeExpect(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/maintenance":
+			_, _ = w.Write([]byte("<h1>Down for maintenance</h1>"))
+		case "/redirect":
+			w.WriteHeader(http.StatusFound)
+		default:
+			_, _ = w.Write([]byte(`{"status":"ok"}`))
+		}
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+	probe := func(path string, expect probeExpect) error {
+		target, _ := url.Parse(srv.URL + path)
+		return runProbeExpect(ctx, target, expect)
+	}
+
+	if err := probe("/", probeExpect{status: 200, body: `"status":"ok"`}); err != nil {
+		t.Errorf("matching response: %v", err)
+	}
+	if err := probe("/maintenance", probeExpect{body: `"status":"ok"`}); err == nil {
+		t.Error("maintenance page passed a body check")
+	}
+	if err := probe("/redirect", probeExpect{}); err != nil {
+		t.Errorf("302 without expectations: %v", err)
+	}
+	if err := probe("/redirect", probeExpect{status: 200}); err == nil {
+		t.Error("302 passed an exact 200 check")
+	}
+}
@@ -0,0 +1,198 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/moby/moby/api/types/container"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/docker"
+)
+
+var (
+	// errValidatorRejected is returned when an external validator reports
+	// the new container as broken.
+	errValidatorRejected = errors.New("external validation failed")
+
+	// errValidatorTimeout is returned when an external validator did not
+	// answer within the health timeout.
+	errValidatorTimeout = errors.New("external validation timed out")
+)
+
+// validatorOutputLimit caps how much of a validator's response or output is
+// kept for the error message.
+const validatorOutputLimit = 300
+
+// ValidatorRequest is the container context sent to an external validator:
+// the JSON body of the webhook POST and the stdin of the validation command.
+type ValidatorRequest struct {
+	Container   string    `json:"container"`
+	ContainerID string    `json:"container_id"`
+	Image       string    `json:"image"`
+	ImageID     string    `json:"image_id,omitempty"`
+	IPAddress   string    `json:"ip_address,omitempty"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// validatorResponse is the optional JSON reply of a validation webhook.
+// A 2xx reply without a body passes.
+type validatorResponse struct {
+	OK      *bool  `json:"ok"`
+	Message string `json:"message"`
+}
+
+// externalValidators resolves the webhook and command run after the built-in
+// checks. Both come only from the operator's validation_webhook_url and
+// validation_command settings: a label can't send container details to a
+// URL of its choosing, and the command runs inside Sentinel. The
+// sentinel.validate-webhook label can only opt a container out of the
+// webhook with "off"; the command still runs.
+func (u *Updater) externalValidators(labels map[string]string) (webhook, command string) {
+	if u.settings != nil {
+		webhook, _ = u.settings.LoadSetting("validation_webhook_url")
+		command, _ = u.settings.LoadSetting("validation_command")
+	}
+	webhook, command = strings.TrimSpace(webhook), strings.TrimSpace(command)
+	switch v := docker.ContainerValidateWebhook(labels); strings.ToLower(v) {
+	case "":
+	case "off", "none":
+		webhook = ""
+	default:
+		u.log.Warn("ignoring sentinel.validate-webhook label; only \"off\" is supported", "value", v)
+	}
+	if webhook != "" {
+		if parsed, err := url.Parse(webhook); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			u.log.Warn("validation webhook is not an http or https URL, skipping it", "url", webhook)
+			webhook = ""
+		}
+	}
+	return webhook, command
+}
+
+// runExternalValidators calls the plan's webhook, then its command, for a
+// container that passed the built-in checks. Either failing fails
+// validation; each gets the plan's health timeout.
+func (u *Updater) runExternalValidators(ctx context.Context, inspect container.InspectResponse, plan validationPlan) (bool, error) {
+	req := ValidatorRequest{
+		Container:   strings.TrimPrefix(inspect.Name, "/"),
+		ContainerID: inspect.ID,
+		ImageID:     inspect.Image,
+		IPAddress:   containerIP(inspect),
+		Timestamp:   u.clock.Now(),
+	}
+	if inspect.Config != nil {
+		req.Image = inspect.Config.Image
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return false, err
+	}
+	timeout := plan.healthTimeout
+	if timeout <= 0 {
+		timeout = defaultHealthTimeout
+	}
+
+	if plan.webhook != "" {
+		u.log.Info("calling validation webhook", "name", req.Container, "url", plan.webhook)
+		if err := callValidationWebhook(ctx, plan.webhook, body, timeout); err != nil {
+			return false, err
+		}
+	}
+	if plan.command != "" {
+		u.log.Info("running validation command", "name", req.Container, "command", plan.command)
+		if err := runValidationCommand(ctx, plan.command, body, req, timeout); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// callValidationWebhook POSTs body to url. Anything but a 2xx reply, or a
+// JSON reply with "ok": false, is a failure.
+func callValidationWebhook(ctx context.Context, url string, body []byte, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("validation webhook: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Docker-Sentinel")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("webhook after %s: %w", timeout, errValidatorTimeout)
+		}
+		return fmt.Errorf("validation webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg := truncateOutput(string(data))
+		if msg == "" {
+			msg = resp.Status
+		}
+		return fmt.Errorf("webhook returned %d: %s: %w", resp.StatusCode, msg, errValidatorRejected)
+	}
+	var reply validatorResponse
+	if json.Unmarshal(data, &reply) == nil && reply.OK != nil && !*reply.OK {
+		msg := reply.Message
+		if msg == "" {
+			msg = "webhook reported failure"
+		}
+		return fmt.Errorf("%s: %w", truncateOutput(msg), errValidatorRejected)
+	}
+	return nil
+}
+
+// runValidationCommand runs the validation command with the request on
+// stdin and in SENTINEL_* environment variables. A non-zero exit is a
+// failure.
+func runValidationCommand(ctx context.Context, command string, body []byte, req ValidatorRequest, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, command) //nolint:gosec // command is operator-configured, not user input
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Env = append(cmd.Environ(),
+		"SENTINEL_CONTAINER="+req.Container,
+		"SENTINEL_CONTAINER_ID="+req.ContainerID,
+		"SENTINEL_IMAGE="+req.Image,
+		"SENTINEL_IP_ADDRESS="+req.IPAddress,
+	)
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("command after %s: %w", timeout, errValidatorTimeout)
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return fmt.Errorf("validation command: %w", err)
+	}
+	msg := truncateOutput(string(out))
+	if msg == "" {
+		msg = "no output"
+	}
+	return fmt.Errorf("command exited %d: %s: %w", exitErr.ExitCode(), msg, errValidatorRejected)
+}
+
+// truncateOutput trims s and keeps its last validatorOutputLimit bytes,
+// where test runners print their summary.
+func truncateOutput(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) > validatorOutputLimit {
+		s = "…" + strings.ToValidUTF8(s[len(s)-validatorOutputLimit:], "")
+	}
+	return s
+}
@@ -0,0 +1,149 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moby/moby/api/types/container"
+)
+
+func TestExternalValidatorsPrecedence(t *testing.T) {
+	u, _ := newTestUpdater(t, newMockDocker())
+	u.SetSettingsReader(u.store)
+	_ = u.store.SaveSetting("validation_webhook_url", "https://ci.example/smoke")
+	_ = u.store.SaveSetting("validation_command", "/plugins/smoke")
+
+	if hook, cmd := u.externalValidators(nil); hook != "https://ci.example/smoke" || cmd != "/plugins/smoke" {
+		t.Errorf("global = (%q, %q)", hook, cmd)
+	}
+	// A label can't redirect the webhook, only opt out of it.
+	if hook, cmd := u.externalValidators(map[string]string{"sentinel.validate-webhook": "http://attacker.example/collect"}); hook != "https://ci.example/smoke" || cmd != "/plugins/smoke" {
+		t.Errorf("label URL = (%q, %q), want the operator's webhook and command", hook, cmd)
+	}
+	if hook, cmd := u.externalValidators(map[string]string{"sentinel.validate-webhook": "off"}); hook != "" || cmd != "/plugins/smoke" {
+		t.Errorf("off = (%q, %q), want no webhook and the operator's command", hook, cmd)
+	}
+
+	_ = u.store.SaveSetting("validation_webhook_url", "file:///etc/passwd")
+	if hook, _ := u.externalValidators(nil); hook != "" {
+		t.Errorf("non-http webhook = %q, want it skipped", hook)
+	}
+}
+
+func TestCallValidationWebhook(t *testing.T) {
+	var got ValidatorRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		switch got.Container {
+		case "pass":
+			w.WriteHeader(http.StatusNoContent)
+		case "negative":
+			_, _ = w.Write([]byte(`{"ok":false,"message":"checkout page returned 500"}`))
+		case "slow":
+			time.Sleep(200 * time.Millisecond)
+		default:
+			http.Error(w, "2 of 14 tests failed", http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+	call := func(name string, timeout time.Duration) error {
+		body, _ := json.Marshal(ValidatorRequest{Container: name, Image: "shop:2"})
+		return callValidationWebhook(ctx, srv.URL, body, timeout)
+	}
+
+	if err := call("pass", time.Second); err != nil {
+		t.Errorf("pass: %v", err)
+	}
+	if got.Image != "shop:2" {
+		t.Errorf("posted image = %q, want shop:2", got.Image)
+	}
+	if err := call("negative", time.Second); !errors.Is(err, errValidatorRejected) || !strings.Contains(err.Error(), "checkout page returned 500") {
+		t.Errorf("negative reply: %v", err)
+	}
+	if err := call("fail", time.Second); !errors.Is(err, errValidatorRejected) || !strings.Contains(err.Error(), "2 of 14 tests failed") {
+		t.Errorf("500 reply: %v", err)
+	}
+	if err := call("slow", 50*time.Millisecond); !errors.Is(err, errValidatorTimeout) || !isValidationTimeout(err) {
+		t.Errorf("slow reply: %v, want timeout", err)
+	}
+}
+
+func TestRunValidationCommand(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "smoke")
+	body := `#!/bin/sh
+input=$(cat)
+case "$input" in *'"image":"shop:2"'*) ;; *) echo "bad stdin: $input"; exit 2;; esac
+[ "$SENTINEL_CONTAINER" = shop ] || { echo "bad env"; exit 3; }
+[ "$SENTINEL_IMAGE" = "shop:2" ] && exit 0
+echo "smoke test failed"
+exit 1
+`
+	if err := os.WriteFile(script, []byte(body), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	run := func(image string) error {
+		req := ValidatorRequest{Container: "shop", Image: image}
+		data, _ := json.Marshal(req)
+		return runValidationCommand(context.Background(), script, data, req, 5*time.Second)
+	}
+
+	if err := run("shop:2"); err != nil {
+		t.Errorf("passing command: %v", err)
+	}
+	if err := run("shop:3"); !errors.Is(err, errValidatorRejected) {
+		t.Errorf("failing command: %v, want rejected", err)
+	}
+}
+
+func TestValidateContainerExternalWebhook(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	mock := newMockDocker()
+	mock.inspectResults["new"] = container.InspectResponse{
+		ID:     "new",
+		Name:   "/shop",
+		State:  &container.State{Running: true},
+		Config: &container.Config{Image: "shop:2"},
+	}
+	mock.inspectResults["down"] = container.InspectResponse{ID: "down", State: &container.State{Running: false}}
+	u, _ := newTestUpdater(t, mock)
+	plan := validationPlan{webhook: srv.URL, healthTimeout: time.Second}
+
+	ok, err := u.validateContainer(context.Background(), "new", plan)
+	if ok || !errors.Is(err, errValidatorRejected) {
+		t.Errorf("got (%v, %v), want rejected by webhook", ok, err)
+	}
+	// A container failing the built-in checks never reaches the webhook.
+	if ok, _ := u.validateContainer(context.Background(), "down", plan); ok {
+		t.Error("stopped container passed validation")
+	}
+	if calls != 1 {
+		t.Errorf("webhook calls = %d, want 1", calls)
+	}
+}
+
+func TestTruncateOutput(t *testing.T) {
+	long := strings.Repeat("x", validatorOutputLimit) + "summary"
+	got := truncateOutput(long)
+	if !strings.HasSuffix(got, "summary") || !strings.HasPrefix(got, "…") {
+		t.Errorf("truncateOutput kept %q", got)
+	}
+	if got := truncateOutput("  short \n"); got != "short" {
+		t.Errorf("truncateOutput(short) = %q", got)
+	}
+}
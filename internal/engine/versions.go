@@ -0,0 +1,78 @@
+package engine
+
+import (
+	"github.com/Will-Luck/Docker-Sentinel/internal/registry"
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+)
+
+// ociVersionLabel is the standard image label carrying the upstream version.
+const ociVersionLabel = "org.opencontainers.image.version"
+
+// resolveVersion derives the human version pair for a container. The running
+// version prefers the OCI version label, then the registry tag matching the
+// local digest, then the tag itself. The candidate is only set when the check
+// found an update.
+func resolveVersion(labels map[string]string, imageRef string, check registry.CheckResult) store.VersionInfo {
+	tag := registry.ExtractTag(imageRef)
+	info := store.VersionInfo{Tag: tag}
+
+	switch {
+	case registry.NormaliseVersion(labels[ociVersionLabel]) != "":
+		info.Current = registry.NormaliseVersion(labels[ociVersionLabel])
+		info.Source = store.VersionSourceLabel
+	case registry.NormaliseVersion(check.ResolvedCurrentVersion) != "":
+		info.Current = registry.NormaliseVersion(check.ResolvedCurrentVersion)
+		info.Source = store.VersionSourceResolved
+	case registry.NormaliseVersion(tag) != "":
+		info.Current = registry.NormaliseVersion(tag)
+		info.Source = store.VersionSourceTag
+	}
+
+	if check.UpdateAvailable {
+		switch {
+		case len(check.NewerVersions) > 0:
+			info.Candidate = registry.NormaliseVersion(check.NewerVersions[0])
+		case check.ResolvedTargetVersion != "":
+			info.Candidate = registry.NormaliseVersion(check.ResolvedTargetVersion)
+		}
+	}
+	return info
+}
+
+// recordVersion resolves and persists a container's version after a scan.
+func (u *Updater) recordVersion(key string, labels map[string]string, imageRef string, check registry.CheckResult) store.VersionInfo {
+	info := resolveVersion(labels, imageRef, check)
+	info.UpdatedAt = u.clock.Now()
+	if err := u.store.SetVersionInfo(key, info); err != nil {
+		u.log.Debug("failed to persist version info", "name", key, "error", err)
+	}
+	return info
+}
+
+// updateVersions returns the version pair for an update about to run. An
+// explicit target image wins over the candidate recorded by the last scan.
+func (u *Updater) updateVersions(key, targetImage string) (string, string) {
+	info, _ := u.store.GetVersionInfo(key)
+	candidate := info.Candidate
+	if targetImage != "" {
+		if v := registry.NormaliseVersion(registry.ExtractTag(targetImage)); v != "" {
+			candidate = v
+		}
+	}
+	return info.Current, candidate
+}
+
+// promoteVersion records a successful update: the candidate becomes the
+// running version until the next scan refreshes it.
+func (u *Updater) promoteVersion(key, newVersion string) {
+	info, ok := u.store.GetVersionInfo(key)
+	if !ok || newVersion == "" {
+		return
+	}
+	info.Current = newVersion
+	info.Candidate = ""
+	info.UpdatedAt = u.clock.Now()
+	if err := u.store.SetVersionInfo(key, info); err != nil {
+		u.log.Debug("failed to persist version info", "name", key, "error", err)
+	}
+}
@@ -0,0 +1,98 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/moby/moby/api/types/container"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/registry"
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+)
+
+func TestResolveVersion(t *testing.T) {
+	tests := []struct {
+		name          string
+		labels        map[string]string
+		image         string
+		check         registry.CheckResult
+		wantCurrent   string
+		wantCandidate string
+		wantSource    string
+	}{
+		{
+			name:          "label wins over tag",
+			labels:        map[string]string{ociVersionLabel: "v1.25.3"},
+			image:         "nginx:1.25",
+			check:         registry.CheckResult{UpdateAvailable: true, NewerVersions: []string{"1.27.0"}},
+			wantCurrent:   "1.25.3",
+			wantCandidate: "1.27.0",
+			wantSource:    store.VersionSourceLabel,
+		},
+		{
+			name:          "latest resolved from registry",
+			image:         "app:latest",
+			check:         registry.CheckResult{UpdateAvailable: true, ResolvedCurrentVersion: "v2.1.0", ResolvedTargetVersion: "v2.2.0"},
+			wantCurrent:   "2.1.0",
+			wantCandidate: "2.2.0",
+			wantSource:    store.VersionSourceResolved,
+		},
+		{
+			name:        "tag only, up to date",
+			image:       "redis:7.2.4",
+			check:       registry.CheckResult{NewerVersions: []string{"7.4.0"}},
+			wantCurrent: "7.2.4",
+			wantSource:  store.VersionSourceTag,
+		},
+		{
+			name:  "latest with nothing resolvable",
+			image: "app:latest",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := resolveVersion(tt.labels, tt.image, tt.check)
+			if got.Current != tt.wantCurrent || got.Candidate != tt.wantCandidate || got.Source != tt.wantSource {
+				t.Errorf("resolveVersion = %+v, want current=%q candidate=%q source=%q",
+					got, tt.wantCurrent, tt.wantCandidate, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestScanRecordsVersionForUpToDateContainer(t *testing.T) {
+	mock := newMockDocker()
+	mock.containers = []container.Summary{
+		{ID: "aaa", Names: []string{"/app"}, Image: "fake.local/app:latest",
+			Labels: map[string]string{ociVersionLabel: "3.4.5"}},
+	}
+	mock.imageDigests["fake.local/app:latest"] = "fake.local/app@sha256:same"
+	mock.distDigests["fake.local/app:latest"] = "sha256:same"
+
+	u, _ := newTestUpdater(t, mock)
+	u.Scan(context.Background(), ScanScheduled)
+
+	info, ok := u.store.GetVersionInfo("app")
+	if !ok {
+		t.Fatal("expected version info to be recorded for an up-to-date container")
+	}
+	if info.Current != "3.4.5" || info.Candidate != "" || info.Tag != "latest" {
+		t.Errorf("version info = %+v, want current 3.4.5, no candidate, tag latest", info)
+	}
+}
+
+func TestUpdatePromotesCandidateVersion(t *testing.T) {
+	_, u := setupUpdateMock(t)
+	if err := u.store.SetVersionInfo("nginx", store.VersionInfo{Tag: "1.25", Current: "1.25.0", Candidate: "1.26.0"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := u.UpdateContainer(context.Background(), "aaa", "nginx", ""); err != nil {
+		t.Fatalf("UpdateContainer: %v", err)
+	}
+
+	info, _ := u.store.GetVersionInfo("nginx")
+	if info.Current != "1.26.0" || info.Candidate != "" {
+		t.Errorf("version info after update = %+v, want current 1.26.0 and no candidate", info)
+	}
+}
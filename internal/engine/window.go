@@ -54,6 +54,20 @@ func ParseWindow(expr string) (*MaintenanceWindow, error) {
 	return &MaintenanceWindow{windows: specs}, nil
 }
 
+// WindowAnytime is the window override that lifts every restriction, so a
+// container or stack can update at any time despite a global window.
+const WindowAnytime = "any"
+
+// ParseWindowOverride parses a per-container or per-stack window. It accepts
+// everything ParseWindow does plus WindowAnytime, which yields a nil
+// (always open) window.
+func ParseWindowOverride(expr string) (*MaintenanceWindow, error) {
+	if strings.EqualFold(strings.TrimSpace(expr), WindowAnytime) {
+		return nil, nil
+	}
+	return ParseWindow(expr)
+}
+
 // IsOpen returns true if the given time falls within any maintenance window.
 // A nil MaintenanceWindow is always open (no restriction).
 func (w *MaintenanceWindow) IsOpen(t time.Time) bool {
@@ -330,6 +330,22 @@ func TestParseWindow_EmptyWindowsAlwaysOpen(t *testing.T) {
 	}
 }
 
+func TestParseWindowOverride_Anytime(t *testing.T) {
+	for _, expr := range []string{"any", " ANY "} {
+		w, err := ParseWindowOverride(expr)
+		if err != nil || w != nil {
+			t.Errorf("ParseWindowOverride(%q) = %v, %v; want nil, nil", expr, w, err)
+		}
+	}
+	w, err := ParseWindowOverride("Sun 03:00-Sun 05:00")
+	if err != nil || w == nil {
+		t.Fatalf("ParseWindowOverride(weekly) = %v, %v", w, err)
+	}
+	if _, err := ParseWindowOverride("anytime"); err == nil {
+		t.Error("expected error for unknown keyword")
+	}
+}
+
 func makeTime(year, month, day, hour, min int) time.Time {
 	return time.Date(year, time.Month(month), day, hour, min, 0, 0, time.UTC)
 }
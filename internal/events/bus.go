@@ -3,6 +3,7 @@ package events
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -21,6 +22,7 @@ const (
 	EventGHCRCheck       EventType = "ghcr_check"
 	EventScanStart       EventType = "scan_start"
 	EventScanProgress    EventType = "scan_progress"
+	EventCheckProgress   EventType = "check_progress" // bulk registry check progress
 	EventServiceUpdate   EventType = "service_update"
 	EventClusterHost     EventType = "cluster_host"   // host connected/disconnected/enrolled
 	EventSourceOverlap   EventType = "source_overlap" // Portainer endpoint auto-blocked due to Engine ID overlap
@@ -43,9 +45,10 @@ const subscriberBufferSize = 64
 // after they subscribe. Slow subscribers that fall behind have events dropped
 // rather than blocking publishers.
 type Bus struct {
-	mu   sync.RWMutex
-	subs map[uint64]chan SSEEvent
-	next uint64
+	mu      sync.RWMutex
+	subs    map[uint64]chan SSEEvent
+	next    uint64
+	dropped atomic.Uint64
 }
 
 // New creates a ready-to-use Bus.
@@ -66,10 +69,17 @@ func (b *Bus) Publish(evt SSEEvent) {
 		case ch <- evt:
 		default:
 			// Subscriber buffer full -- drop the event rather than blocking.
+			b.dropped.Add(1)
 		}
 	}
 }
 
+// Dropped returns how many events have been dropped for subscribers that
+// fell behind.
+func (b *Bus) Dropped() uint64 {
+	return b.dropped.Load()
+}
+
 // Subscribe returns a channel that receives all future events and a cancel
 // function that unsubscribes and closes the channel. The caller must invoke
 // cancel when done to avoid resource leaks.
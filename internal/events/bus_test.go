@@ -133,6 +133,9 @@ func TestSlowSubscriberDropsEvents(t *testing.T) {
 	default:
 		// Good -- buffer is empty.
 	}
+	if got := bus.Dropped(); got != 1 {
+		t.Errorf("Dropped() = %d, want 1", got)
+	}
 }
 
 func TestConcurrentPublish(t *testing.T) {
@@ -0,0 +1,277 @@
+// Package lifecycle streams update state transitions to a single machine
+// consumer (a workflow engine, audit pipeline, etc.) over HTTP, as NDJSON or
+// CloudEvents. Unlike notifications it reports every transition, and each
+// event carries a correlation ID shared by all events of one update.
+package lifecycle
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// State is an update lifecycle state.
+type State string
+
+const (
+	StateQueued     State = "queued"
+	StateApproved   State = "approved"
+	StateUpdating   State = "updating"
+	StateValidated  State = "validated"
+	StateFinalised  State = "finalised"
+	StateRolledBack State = "rolled_back"
+	StateFailed     State = "failed"
+)
+
+// terminal reports whether a state ends an update's correlation.
+func (st State) terminal() bool {
+	return st == StateFinalised || st == StateRolledBack || st == StateFailed
+}
+
+// Delivery formats.
+const (
+	FormatNDJSON      = "ndjson"
+	FormatCloudEvents = "cloudevents"
+)
+
+// Delivery tuning.
+const (
+	bufferSize   = 256
+	maxBatchSize = 100
+)
+
+// Event is one state transition. ID, CorrelationID and Time are filled in
+// by Emit.
+type Event struct {
+	ID            string    `json:"id"`
+	CorrelationID string    `json:"correlation_id"`
+	State         State     `json:"state"`
+	Container     string    `json:"container"`
+	HostID        string    `json:"host_id,omitempty"`
+	OldImage      string    `json:"old_image,omitempty"`
+	NewImage      string    `json:"new_image,omitempty"`
+	Message       string    `json:"message,omitempty"`
+	Time          time.Time `json:"time"`
+}
+
+// key identifies the container an event belongs to.
+func (e Event) key() string {
+	if e.HostID != "" {
+		return e.HostID + "::" + e.Container
+	}
+	return e.Container
+}
+
+// Config is the sink endpoint. An empty URL disables delivery.
+type Config struct {
+	URL    string `json:"url"`
+	Format string `json:"format"` // "ndjson" (default) or "cloudevents"
+}
+
+// ValidateConfig checks that the URL is http(s) and the format is known.
+// An empty URL is valid and disables the sink.
+func ValidateConfig(c Config) error {
+	if c.URL != "" {
+		u, err := url.Parse(c.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid event sink URL %q (must be http or https)", c.URL)
+		}
+	}
+	switch c.Format {
+	case "", FormatNDJSON, FormatCloudEvents:
+		return nil
+	default:
+		return fmt.Errorf("unknown event sink format %q", c.Format)
+	}
+}
+
+// Sink queues events and delivers them in batches from Run. A nil *Sink
+// discards everything, so callers need not check whether one is wired.
+type Sink struct {
+	config func() Config
+	client *http.Client
+	log    *slog.Logger
+	events chan Event
+
+	mu        sync.Mutex
+	active    map[string]string // container key → correlation ID of its in-flight update
+	observers []func(Event)
+}
+
+// New creates a sink. config is read on every emit and delivery, so setting
+// changes apply without a restart.
+func New(config func() Config, log *slog.Logger) *Sink {
+	return &Sink{
+		config: config,
+		client: &http.Client{Timeout: 10 * time.Second},
+		log:    log,
+		events: make(chan Event, bufferSize),
+		active: make(map[string]string),
+	}
+}
+
+// Observe registers fn to receive every transition, whether or not a sink URL
+// is configured. fn runs on the emitting goroutine and must not block.
+func (s *Sink) Observe(fn func(Event)) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.observers = append(s.observers, fn)
+	s.mu.Unlock()
+}
+
+// Emit records a state transition. Queued always starts a new correlation;
+// other states join the container's current one (starting one if needed),
+// and terminal states close it. Emit never blocks: when the buffer is full
+// the event is dropped.
+func (s *Sink) Emit(e Event) {
+	if s == nil {
+		return
+	}
+	key := e.key()
+	s.mu.Lock()
+	corr, ok := s.active[key]
+	if !ok || e.State == StateQueued {
+		corr = newID()
+		s.active[key] = corr
+	}
+	if e.State.terminal() {
+		delete(s.active, key)
+	}
+	observers := s.observers
+	s.mu.Unlock()
+
+	e.ID = newID()
+	e.CorrelationID = corr
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	for _, fn := range observers {
+		fn(e)
+	}
+
+	if s.config().URL == "" {
+		return
+	}
+	select {
+	case s.events <- e:
+	default:
+		s.log.Warn("lifecycle event buffer full, dropping event", "container", e.Container, "state", e.State)
+	}
+}
+
+// Run delivers queued events until ctx is cancelled. Events already waiting
+// are sent together in one request. Failed deliveries are logged and dropped.
+func (s *Sink) Run(ctx context.Context) {
+	for {
+		var batch []Event
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-s.events:
+			batch = append(batch, e)
+		}
+	drain:
+		for len(batch) < maxBatchSize {
+			select {
+			case e := <-s.events:
+				batch = append(batch, e)
+			default:
+				break drain
+			}
+		}
+		if err := s.deliver(ctx, batch); err != nil {
+			s.log.Warn("lifecycle event delivery failed", "events", len(batch), "error", err)
+		}
+	}
+}
+
+// deliver posts a batch in the configured format.
+func (s *Sink) deliver(ctx context.Context, batch []Event) error {
+	cfg := s.config()
+	if cfg.URL == "" {
+		return nil
+	}
+	body, contentType, err := encode(cfg.Format, batch)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send events: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("event sink returned %s", resp.Status)
+	}
+	return nil
+}
+
+// cloudEvent is a CloudEvents 1.0 envelope in structured JSON mode.
+type cloudEvent struct {
+	SpecVersion     string    `json:"specversion"`
+	ID              string    `json:"id"`
+	Source          string    `json:"source"`
+	Type            string    `json:"type"`
+	Subject         string    `json:"subject"`
+	Time            time.Time `json:"time"`
+	DataContentType string    `json:"datacontenttype"`
+	CorrelationID   string    `json:"correlationid"`
+	Data            Event     `json:"data"`
+}
+
+// encode renders a batch as NDJSON (one event per line) or as a CloudEvents
+// JSON batch.
+func encode(format string, batch []Event) ([]byte, string, error) {
+	if format == FormatCloudEvents {
+		out := make([]cloudEvent, len(batch))
+		for i, e := range batch {
+			out[i] = cloudEvent{
+				SpecVersion:     "1.0",
+				ID:              e.ID,
+				Source:          "docker-sentinel",
+				Type:            "io.docker-sentinel.update." + string(e.State),
+				Subject:         e.key(),
+				Time:            e.Time,
+				DataContentType: "application/json",
+				CorrelationID:   e.CorrelationID,
+				Data:            e,
+			}
+		}
+		body, err := json.Marshal(out)
+		if err != nil {
+			return nil, "", fmt.Errorf("marshal events: %w", err)
+		}
+		return body, "application/cloudevents-batch+json", nil
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf) // Encode appends the newline
+	for _, e := range batch {
+		if err := enc.Encode(e); err != nil {
+			return nil, "", fmt.Errorf("marshal events: %w", err)
+		}
+	}
+	return buf.Bytes(), "application/x-ndjson", nil
+}
+
+// newID returns a random 128-bit hex identifier.
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
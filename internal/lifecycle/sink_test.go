@@ -0,0 +1,157 @@
+package lifecycle
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// collector is an HTTP endpoint that records request bodies.
+type collector struct {
+	srv    *httptest.Server
+	bodies chan []byte
+	types  chan string
+}
+
+func newCollector(t *testing.T) *collector {
+	t.Helper()
+	c := &collector{bodies: make(chan []byte, 16), types: make(chan string, 16)}
+	c.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		c.types <- r.Header.Get("Content-Type")
+		c.bodies <- body
+	}))
+	t.Cleanup(c.srv.Close)
+	return c
+}
+
+func (c *collector) next(t *testing.T) (string, []byte) {
+	t.Helper()
+	select {
+	case body := <-c.bodies:
+		return <-c.types, body
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for delivery")
+		return "", nil
+	}
+}
+
+func startSink(t *testing.T, cfg Config) *Sink {
+	t.Helper()
+	s := New(func() Config { return cfg }, slog.Default())
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go s.Run(ctx)
+	return s
+}
+
+func TestSinkNDJSONCorrelation(t *testing.T) {
+	c := newCollector(t)
+	s := startSink(t, Config{URL: c.srv.URL})
+
+	s.Emit(Event{State: StateQueued, Container: "web"})
+	s.Emit(Event{State: StateUpdating, Container: "web"})
+	s.Emit(Event{State: StateFinalised, Container: "web"})
+	s.Emit(Event{State: StateUpdating, Container: "web"})
+
+	var got []Event
+	for len(got) < 4 {
+		ct, body := c.next(t)
+		if ct != "application/x-ndjson" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		sc := bufio.NewScanner(bytes.NewReader(body))
+		for sc.Scan() {
+			var e Event
+			if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+				t.Fatalf("bad line %q: %v", sc.Text(), err)
+			}
+			got = append(got, e)
+		}
+	}
+
+	if got[0].CorrelationID == "" || got[0].CorrelationID != got[1].CorrelationID || got[1].CorrelationID != got[2].CorrelationID {
+		t.Errorf("events of one update should share a correlation ID: %+v", got[:3])
+	}
+	if got[3].CorrelationID == got[2].CorrelationID {
+		t.Error("update after finalised should start a new correlation")
+	}
+	if got[0].ID == got[1].ID {
+		t.Error("event IDs should be unique")
+	}
+}
+
+func TestSinkCloudEvents(t *testing.T) {
+	c := newCollector(t)
+	s := startSink(t, Config{URL: c.srv.URL, Format: FormatCloudEvents})
+
+	s.Emit(Event{State: StateRolledBack, Container: "db", HostID: "h1"})
+
+	ct, body := c.next(t)
+	if ct != "application/cloudevents-batch+json" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	var batch []map[string]any
+	if err := json.Unmarshal(body, &batch); err != nil || len(batch) != 1 {
+		t.Fatalf("body = %s, err = %v", body, err)
+	}
+	ce := batch[0]
+	if ce["specversion"] != "1.0" || ce["type"] != "io.docker-sentinel.update.rolled_back" || ce["subject"] != "h1::db" {
+		t.Errorf("cloudevent = %v", ce)
+	}
+	if ce["correlationid"] == "" {
+		t.Error("missing correlationid extension")
+	}
+}
+
+func TestSinkDisabledAndNil(t *testing.T) {
+	s := New(func() Config { return Config{} }, slog.Default())
+	s.Emit(Event{State: StateQueued, Container: "web"})
+	if len(s.events) != 0 {
+		t.Error("disabled sink should not buffer events")
+	}
+
+	var nilSink *Sink
+	nilSink.Emit(Event{State: StateQueued, Container: "web"}) // must not panic
+}
+
+func TestSinkObserveWithoutURL(t *testing.T) {
+	s := New(func() Config { return Config{} }, slog.Default())
+	var got []Event
+	s.Observe(func(e Event) { got = append(got, e) })
+
+	s.Emit(Event{State: StateQueued, Container: "web"})
+	s.Emit(Event{State: StateFinalised, Container: "web"})
+
+	if len(got) != 2 {
+		t.Fatalf("observed %d events, want 2", len(got))
+	}
+	if got[0].CorrelationID == "" || got[0].CorrelationID != got[1].CorrelationID {
+		t.Errorf("correlation IDs = %q, %q; want equal and set", got[0].CorrelationID, got[1].CorrelationID)
+	}
+	if len(s.events) != 0 {
+		t.Error("disabled sink should not buffer events")
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	valid := []Config{{}, {URL: "https://example.com/x"}, {URL: "http://h:8080", Format: FormatNDJSON}, {URL: "http://h", Format: FormatCloudEvents}}
+	for _, c := range valid {
+		if err := ValidateConfig(c); err != nil {
+			t.Errorf("ValidateConfig(%+v) = %v", c, err)
+		}
+	}
+	invalid := []Config{{URL: "ftp://h"}, {URL: "https://"}, {URL: "http://h", Format: "xml"}}
+	for _, c := range invalid {
+		if err := ValidateConfig(c); err == nil {
+			t.Errorf("ValidateConfig(%+v) = nil, want error", c)
+		}
+	}
+}
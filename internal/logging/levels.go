@@ -0,0 +1,142 @@
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Components whose log level can be changed at runtime. Records from
+// anything else are tagged core and follow the base level.
+const (
+	ComponentCore     = "core"
+	ComponentEngine   = "engine"
+	ComponentRegistry = "registry"
+	ComponentCluster  = "cluster"
+	ComponentWeb      = "web"
+	ComponentNotify   = "notify"
+)
+
+// Components lists the components with adjustable levels.
+var Components = []string{ComponentEngine, ComponentRegistry, ComponentCluster, ComponentWeb, ComponentNotify}
+
+// Levels holds the base log level and temporary per-component overrides.
+// Overrides are cleared together when their duration runs out, so a
+// troubleshooting session cannot leave debug logging on for good.
+type Levels struct {
+	mu        sync.RWMutex
+	base      slog.Level
+	overrides map[string]slog.Level
+	resetAt   time.Time
+	timer     *time.Timer
+}
+
+// NewLevels creates a registry logging every component at base.
+func NewLevels(base slog.Level) *Levels {
+	return &Levels{base: base, overrides: map[string]slog.Level{}}
+}
+
+// Level returns the level a component currently logs at.
+func (l *Levels) Level(component string) slog.Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if lvl, ok := l.overrides[component]; ok {
+		return lvl
+	}
+	return l.base
+}
+
+// SetBase changes the level used by components without an override.
+func (l *Levels) SetBase(level slog.Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.base = level
+}
+
+// Override replaces the per-component overrides. When d is positive they
+// are cleared again after d; an empty map clears them now.
+func (l *Levels) Override(levels map[string]slog.Level, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.timer != nil {
+		l.timer.Stop()
+		l.timer = nil
+	}
+	l.overrides = maps.Clone(levels)
+	if l.overrides == nil {
+		l.overrides = map[string]slog.Level{}
+	}
+	l.resetAt = time.Time{}
+	if len(l.overrides) > 0 && d > 0 {
+		l.resetAt = time.Now().Add(d)
+		var timer *time.Timer
+		timer = time.AfterFunc(d, func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			if l.timer == timer {
+				l.overrides, l.resetAt, l.timer = map[string]slog.Level{}, time.Time{}, nil
+			}
+		})
+		l.timer = timer
+	}
+}
+
+// LevelState is a snapshot of the levels for reporting.
+type LevelState struct {
+	Base       string            `json:"base"`
+	Components map[string]string `json:"components"` // effective level of each adjustable component
+	Overrides  map[string]string `json:"overrides"`
+	ResetAt    time.Time         `json:"reset_at,omitzero"`
+}
+
+// State returns the current levels.
+func (l *Levels) State() LevelState {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	st := LevelState{
+		Base:       levelName(l.base),
+		Components: make(map[string]string, len(Components)),
+		Overrides:  make(map[string]string, len(l.overrides)),
+		ResetAt:    l.resetAt,
+	}
+	for _, c := range Components {
+		lvl := l.base
+		if o, ok := l.overrides[c]; ok {
+			lvl = o
+		}
+		st.Components[c] = levelName(lvl)
+	}
+	for c, lvl := range l.overrides {
+		st.Overrides[c] = levelName(lvl)
+	}
+	return st
+}
+
+// ParseLevel accepts debug, info, warn/warning or error, in any case.
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("invalid log level %q (use debug, info, warn or error)", s)
+}
+
+// IsComponent reports whether name is a component with an adjustable level.
+func IsComponent(name string) bool {
+	return slices.Contains(Components, name)
+}
+
+// levelName renders a level the way ParseLevel accepts it.
+func levelName(l slog.Level) string {
+	return strings.ToLower(l.String())
+}
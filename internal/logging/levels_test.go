@@ -0,0 +1,62 @@
+package logging
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOverrideResets(t *testing.T) {
+	l := NewLevels(slog.LevelInfo)
+	l.Override(map[string]slog.Level{ComponentEngine: slog.LevelDebug}, 20*time.Millisecond)
+	if got := l.Level(ComponentEngine); got != slog.LevelDebug {
+		t.Fatalf("engine level = %v, want debug", got)
+	}
+	if got := l.Level(ComponentWeb); got != slog.LevelInfo {
+		t.Errorf("web level = %v, want base info", got)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for l.Level(ComponentEngine) != slog.LevelInfo {
+		if time.Now().After(deadline) {
+			t.Fatal("override was not reset")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if st := l.State(); len(st.Overrides) != 0 || !st.ResetAt.IsZero() {
+		t.Errorf("state after reset = %+v", st)
+	}
+}
+
+func TestComponentFiltersAndTags(t *testing.T) {
+	var buf bytes.Buffer
+	levels := NewLevels(slog.LevelInfo)
+	root := &Logger{slog.New(newComponentHandler(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug - 4}), levels, ComponentCore)), levels}
+	engine := root.Component(ComponentEngine).With("scan", 1)
+	web := root.Component(ComponentWeb)
+
+	engine.Debug("hidden")
+	levels.Override(map[string]slog.Level{ComponentEngine: slog.LevelDebug}, time.Hour)
+	engine.Debug("shown")
+	web.Debug("web hidden")
+
+	out := buf.String()
+	if strings.Contains(out, "hidden") {
+		t.Errorf("debug records below the level were written:\n%s", out)
+	}
+	if !strings.Contains(out, "component=engine") || !strings.Contains(out, "scan=1") || !strings.Contains(out, "msg=shown") {
+		t.Errorf("missing engine record with component and attrs:\n%s", out)
+	}
+	levels.Override(nil, 0)
+}
+
+func TestParseLevel(t *testing.T) {
+	if lvl, err := ParseLevel(" WARNING "); err != nil || lvl != slog.LevelWarn {
+		t.Errorf("ParseLevel(WARNING) = %v, %v", lvl, err)
+	}
+	if _, err := ParseLevel("trace"); err == nil {
+		t.Error("ParseLevel(trace) succeeded, want error")
+	}
+}
@@ -1,22 +1,82 @@
 package logging
 
 import (
+	"context"
 	"log/slog"
 	"os"
 )
 
-// Logger wraps slog for structured logging.
+// Logger wraps slog for structured logging. Every record carries a
+// component field, and each component's level can be changed at runtime
+// through Levels.
 type Logger struct {
 	*slog.Logger
+	levels *Levels
 }
 
-// New creates a Logger that outputs text or JSON depending on config.
+// New creates a Logger that outputs text or JSON depending on config. It
+// logs as the core component at debug level until SetBase says otherwise.
 func New(jsonMode bool) *Logger {
+	// Filtering happens in componentHandler; the output handler takes
+	// whatever gets that far.
+	opts := &slog.HandlerOptions{Level: slog.LevelDebug - 4}
 	var handler slog.Handler
 	if jsonMode {
-		handler = slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})
+		handler = slog.NewJSONHandler(os.Stdout, opts)
 	} else {
-		handler = slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})
+		handler = slog.NewTextHandler(os.Stdout, opts)
 	}
-	return &Logger{slog.New(handler)}
+	levels := NewLevels(slog.LevelDebug)
+	return &Logger{slog.New(newComponentHandler(handler, levels, ComponentCore)), levels}
+}
+
+// Levels returns the level registry shared by this logger and every
+// component logger derived from it.
+func (l *Logger) Levels() *Levels {
+	return l.levels
+}
+
+// Component returns a logger for one component: its records carry
+// component=name and are filtered at that component's level.
+func (l *Logger) Component(name string) *Logger {
+	h, ok := l.Handler().(*componentHandler)
+	if !ok {
+		return &Logger{l.With("component", name), l.levels}
+	}
+	return &Logger{slog.New(newComponentHandler(h.base, h.levels, name)), l.levels}
+}
+
+// componentHandler filters records by its component's current level and
+// tags them with the component. base is the wrapped handler without the
+// component attribute, so sibling components can be derived from it.
+type componentHandler struct {
+	base      slog.Handler
+	inner     slog.Handler
+	levels    *Levels
+	component string
+}
+
+func newComponentHandler(base slog.Handler, levels *Levels, component string) *componentHandler {
+	return &componentHandler{
+		base:      base,
+		inner:     base.WithAttrs([]slog.Attr{slog.String("component", component)}),
+		levels:    levels,
+		component: component,
+	}
+}
+
+func (h *componentHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.levels.Level(h.component)
+}
+
+func (h *componentHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.inner.Handle(ctx, r)
+}
+
+func (h *componentHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &componentHandler{h.base.WithAttrs(attrs), h.inner.WithAttrs(attrs), h.levels, h.component}
+}
+
+func (h *componentHandler) WithGroup(name string) slog.Handler {
+	return &componentHandler{h.base.WithGroup(name), h.inner.WithGroup(name), h.levels, h.component}
 }
@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"fmt"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Metric names referenced by the alerting rules. The collectors register
+// under the same constants, so renaming a metric keeps the rules in step.
+const (
+	MetricUpdatesTotal          = "sentinel_updates_total"
+	MetricLastScanTimestamp     = "sentinel_last_scan_timestamp_seconds"
+	MetricRateLimitRemaining    = "sentinel_registry_rate_limit_remaining"
+	MetricRateLimitDetected     = "sentinel_registry_rate_limit_detected"
+	MetricClusterAgentConnected = "sentinel_cluster_agent_connected"
+)
+
+// Alert names, shared by the Prometheus rules and the /api/alerts endpoint.
+const (
+	AlertScanStale          = "SentinelScanStale"
+	AlertUpdateFailed       = "SentinelUpdateFailed"
+	AlertRateLimitExhausted = "SentinelRateLimitExhausted"
+	AlertAgentOffline       = "SentinelAgentOffline"
+)
+
+// FailedUpdateWindow is how far back a failed update keeps its alert firing.
+const FailedUpdateWindow = time.Hour
+
+// AlertRule is one Prometheus alerting rule.
+type AlertRule struct {
+	Alert       string            `yaml:"alert"`
+	Expr        string            `yaml:"expr"`
+	For         string            `yaml:"for,omitempty"`
+	Labels      map[string]string `yaml:"labels"`
+	Annotations map[string]string `yaml:"annotations"`
+}
+
+// AlertRules returns the alerting rules for Sentinel's metrics. A scan is
+// stale once staleAfter has passed without one completing.
+func AlertRules(staleAfter time.Duration) []AlertRule {
+	return []AlertRule{
+		{
+			Alert:  AlertScanStale,
+			Expr:   fmt.Sprintf("%[1]s > 0 and time() - %[1]s > %d", MetricLastScanTimestamp, int64(staleAfter.Seconds())),
+			For:    "10m",
+			Labels: map[string]string{"severity": "warning"},
+			Annotations: map[string]string{
+				"summary":     "Sentinel has not completed a scan recently",
+				"description": fmt.Sprintf("No update scan has completed in the last %s.", staleAfter),
+			},
+		},
+		{
+			Alert:  AlertUpdateFailed,
+			Expr:   fmt.Sprintf(`increase(%s{status=~"failed|rollback"}[%s]) > 0`, MetricUpdatesTotal, promDuration(FailedUpdateWindow)),
+			Labels: map[string]string{"severity": "warning"},
+			Annotations: map[string]string{
+				"summary":     "Container updates are failing",
+				"description": fmt.Sprintf("{{ $value | humanize }} update(s) failed or were rolled back in the last %s.", FailedUpdateWindow),
+			},
+		},
+		{
+			Alert:  AlertRateLimitExhausted,
+			Expr:   fmt.Sprintf("%s == 0 and on(registry) %s == 1", MetricRateLimitRemaining, MetricRateLimitDetected),
+			For:    "5m",
+			Labels: map[string]string{"severity": "warning"},
+			Annotations: map[string]string{
+				"summary":     "Registry rate limit exhausted",
+				"description": "No pulls left on {{ $labels.registry }} until the rate limit window resets.",
+			},
+		},
+		{
+			Alert:  AlertAgentOffline,
+			Expr:   MetricClusterAgentConnected + " == 0",
+			For:    "5m",
+			Labels: map[string]string{"severity": "critical"},
+			Annotations: map[string]string{
+				"summary":     "Cluster agent offline",
+				"description": "Agent {{ $labels.host }} has been disconnected for more than 5 minutes.",
+			},
+		},
+	}
+}
+
+// AlertRulesYAML renders AlertRules as a Prometheus rule file.
+func AlertRulesYAML(staleAfter time.Duration) ([]byte, error) {
+	type group struct {
+		Name  string      `yaml:"name"`
+		Rules []AlertRule `yaml:"rules"`
+	}
+	file := struct {
+		Groups []group `yaml:"groups"`
+	}{Groups: []group{{Name: "docker-sentinel", Rules: AlertRules(staleAfter)}}}
+	return yaml.Marshal(file)
+}
+
+// promDuration formats d as a Prometheus duration ("1h", "90m", "45s").
+func promDuration(d time.Duration) string {
+	switch {
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d%time.Minute == 0:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	default:
+		return fmt.Sprintf("%ds", d/time.Second)
+	}
+}
@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestAlertRulesYAML(t *testing.T) {
+	out, err := AlertRulesYAML(3 * time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var file struct {
+		Groups []struct {
+			Name  string      `yaml:"name"`
+			Rules []AlertRule `yaml:"rules"`
+		} `yaml:"groups"`
+	}
+	if err := yaml.Unmarshal(out, &file); err != nil {
+		t.Fatalf("rules file does not parse: %v\n%s", err, out)
+	}
+	if len(file.Groups) != 1 || len(file.Groups[0].Rules) != 4 {
+		t.Fatalf("groups = %+v", file.Groups)
+	}
+
+	want := map[string]string{
+		AlertScanStale:          MetricLastScanTimestamp,
+		AlertUpdateFailed:       MetricUpdatesTotal,
+		AlertRateLimitExhausted: MetricRateLimitRemaining,
+		AlertAgentOffline:       MetricClusterAgentConnected,
+	}
+	for _, r := range file.Groups[0].Rules {
+		metric, ok := want[r.Alert]
+		if !ok {
+			t.Errorf("unexpected alert %q", r.Alert)
+			continue
+		}
+		if !strings.Contains(r.Expr, metric) {
+			t.Errorf("%s expr %q does not use %s", r.Alert, r.Expr, metric)
+		}
+		if r.Labels["severity"] == "" || r.Annotations["summary"] == "" {
+			t.Errorf("%s is missing severity or summary", r.Alert)
+		}
+	}
+	if !strings.Contains(string(out), "> 10800") {
+		t.Errorf("stale threshold not rendered in seconds:\n%s", out)
+	}
+}
+
+func TestPromDuration(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		time.Hour:        "1h",
+		90 * time.Minute: "90m",
+		45 * time.Second: "45s",
+	} {
+		if got := promDuration(d); got != want {
+			t.Errorf("promDuration(%s) = %q, want %q", d, got, want)
+		}
+	}
+}
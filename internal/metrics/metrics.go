@@ -15,7 +15,7 @@ var (
 		Help: "Number of containers being monitored for updates.",
 	})
 	UpdatesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
-		Name: "sentinel_updates_total",
+		Name: MetricUpdatesTotal,
 		Help: "Total number of container updates by status.",
 	}, []string{"status"})
 	UpdateDuration = promauto.NewHistogram(prometheus.HistogramOpts{
@@ -32,6 +32,10 @@ var (
 		Name: "sentinel_scans_total",
 		Help: "Total number of update scans performed.",
 	})
+	LastScanTimestamp = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: MetricLastScanTimestamp,
+		Help: "Unix time at which the last update scan completed.",
+	})
 	PendingUpdates = promauto.NewGauge(prometheus.GaugeOpts{
 		Name: "sentinel_pending_updates",
 		Help: "Number of containers with available updates.",
@@ -48,4 +52,31 @@ var (
 		Name: "sentinel_registry_errors_total",
 		Help: "Total number of registry check errors by registry.",
 	}, []string{"registry"})
+	StoreTxWait = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "sentinel_store_tx_wait_seconds",
+		Help:    "Time spent waiting to begin a BoltDB transaction, by bucket and mode.",
+		Buckets: []float64{.0001, .0005, .001, .005, .01, .05, .1, .5, 1, 5},
+	}, []string{"bucket", "mode"})
+	StoreTxHold = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "sentinel_store_tx_hold_seconds",
+		Help:    "Time a BoltDB transaction was held open, by bucket and mode.",
+		Buckets: []float64{.0001, .0005, .001, .005, .01, .05, .1, .5, 1, 5},
+	}, []string{"bucket", "mode"})
+	ClusterCommandDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "sentinel_cluster_command_duration_seconds",
+		Help:    "Time from sending a command to a cluster agent until its response, by host and command.",
+		Buckets: []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 120, 300},
+	}, []string{"host", "command"})
+	ClusterCommandsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "sentinel_cluster_commands_total",
+		Help: "Total number of commands sent to cluster agents, by host, command and outcome.",
+	}, []string{"host", "command", "outcome"})
+	ClusterAgentSlow = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "sentinel_cluster_agent_slow",
+		Help: "Whether a cluster agent is flagged as consistently slow to respond (1) or not (0).",
+	}, []string{"host"})
+	ClusterAgentConnected = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: MetricClusterAgentConnected,
+		Help: "Whether a cluster agent is connected (1) or not (0).",
+	}, []string{"host"})
 )
@@ -2,8 +2,11 @@ package metrics
 
 import (
 	"testing"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/registry"
 )
 
 func TestMetricsRegistered(t *testing.T) {
@@ -11,6 +14,12 @@ func TestMetricsRegistered(t *testing.T) {
 	// CounterVec metrics are not gathered until at least one label set is created.
 	UpdatesTotal.WithLabelValues("success")
 	RegistryErrors.WithLabelValues("docker.io")
+	StoreTxWait.WithLabelValues("history", "read")
+	StoreTxHold.WithLabelValues("history", "read")
+	ClusterCommandDuration.WithLabelValues("host-1", "list")
+	ClusterCommandsTotal.WithLabelValues("host-1", "list", "ok")
+	ClusterAgentSlow.WithLabelValues("host-1")
+	ClusterAgentConnected.WithLabelValues("host-1")
 
 	// Verify all metrics are registered by gathering them.
 	// promauto registers on init, so if we get here without panic, registration succeeded.
@@ -20,16 +29,23 @@ func TestMetricsRegistered(t *testing.T) {
 	}
 
 	expected := map[string]bool{
-		"sentinel_containers_total":        false,
-		"sentinel_containers_monitored":    false,
-		"sentinel_updates_total":           false,
-		"sentinel_update_duration_seconds": false,
-		"sentinel_scan_duration_seconds":   false,
-		"sentinel_scans_total":             false,
-		"sentinel_pending_updates":         false,
-		"sentinel_queued_updates":          false,
-		"sentinel_image_cleanups_total":    false,
-		"sentinel_registry_errors_total":   false,
+		"sentinel_containers_total":                 false,
+		"sentinel_containers_monitored":             false,
+		"sentinel_updates_total":                    false,
+		"sentinel_update_duration_seconds":          false,
+		"sentinel_scan_duration_seconds":            false,
+		"sentinel_scans_total":                      false,
+		"sentinel_pending_updates":                  false,
+		"sentinel_queued_updates":                   false,
+		"sentinel_image_cleanups_total":             false,
+		"sentinel_registry_errors_total":            false,
+		"sentinel_store_tx_wait_seconds":            false,
+		"sentinel_store_tx_hold_seconds":            false,
+		"sentinel_cluster_command_duration_seconds": false,
+		"sentinel_cluster_commands_total":           false,
+		"sentinel_cluster_agent_slow":               false,
+		"sentinel_cluster_agent_connected":          false,
+		"sentinel_last_scan_timestamp_seconds":      false,
 	}
 
 	for _, mf := range mfs {
@@ -60,3 +76,47 @@ func TestGaugeSets(t *testing.T) {
 	QueuedUpdates.Set(2)
 	// No panic = success.
 }
+
+type fakeRateLimits []registry.RegistryStatus
+
+func (f fakeRateLimits) Status() []registry.RegistryStatus { return f }
+
+func TestRateLimitCollector(t *testing.T) {
+	reset := time.Unix(1_800_000_000, 0)
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(NewRateLimitCollector(fakeRateLimits{
+		{Registry: "docker.io", Limit: 100, Remaining: 42, ResetAt: reset, HasLimits: true, IsAuth: true, ContainerCount: 3, LastUpdated: reset},
+		{Registry: "quay.io", Limit: -1},
+	}))
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]float64{}
+	for _, mf := range mfs {
+		for _, m := range mf.GetMetric() {
+			got[mf.GetName()+"/"+m.GetLabel()[0].GetValue()] = m.GetGauge().GetValue()
+		}
+	}
+	want := map[string]float64{
+		"sentinel_registry_rate_limit/docker.io":                                100,
+		"sentinel_registry_rate_limit_remaining/docker.io":                      42,
+		"sentinel_registry_rate_limit_reset_timestamp_seconds/docker.io":        1_800_000_000,
+		"sentinel_registry_rate_limit_detected/docker.io":                       1,
+		"sentinel_registry_rate_limit_authenticated/docker.io":                  1,
+		"sentinel_registry_rate_limit_containers/docker.io":                     3,
+		"sentinel_registry_rate_limit_last_updated_timestamp_seconds/docker.io": 1_800_000_000,
+		"sentinel_registry_rate_limit_detected/quay.io":                         0,
+		"sentinel_registry_rate_limit_authenticated/quay.io":                    0,
+		"sentinel_registry_rate_limit_containers/quay.io":                       0,
+	}
+	if len(got) != len(want) {
+		t.Errorf("got %d series, want %d: %v", len(got), len(want), got)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %v, want %v", k, got[k], v)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/registry"
+)
+
+var (
+	rateLimitLimitDesc = prometheus.NewDesc("sentinel_registry_rate_limit",
+		"Pull limit of the current rate limit window, by registry.",
+		[]string{"registry"}, nil)
+	rateLimitRemainingDesc = prometheus.NewDesc(MetricRateLimitRemaining,
+		"Pulls left in the current rate limit window, by registry.",
+		[]string{"registry"}, nil)
+	rateLimitResetDesc = prometheus.NewDesc("sentinel_registry_rate_limit_reset_timestamp_seconds",
+		"Unix time at which the rate limit window resets, by registry.",
+		[]string{"registry"}, nil)
+	rateLimitDetectedDesc = prometheus.NewDesc(MetricRateLimitDetected,
+		"1 if the registry returns rate limit headers, 0 otherwise.",
+		[]string{"registry"}, nil)
+	rateLimitAuthDesc = prometheus.NewDesc("sentinel_registry_rate_limit_authenticated",
+		"1 if requests to the registry use stored credentials, 0 if anonymous.",
+		[]string{"registry"}, nil)
+	rateLimitContainersDesc = prometheus.NewDesc("sentinel_registry_rate_limit_containers",
+		"Number of monitored containers pulling from the registry.",
+		[]string{"registry"}, nil)
+	rateLimitUpdatedDesc = prometheus.NewDesc("sentinel_registry_rate_limit_last_updated_timestamp_seconds",
+		"Unix time of the last registry response carrying rate limit information.",
+		[]string{"registry"}, nil)
+)
+
+// RateLimitSource is the part of registry.RateLimitTracker the collector reads.
+type RateLimitSource interface {
+	Status() []registry.RegistryStatus
+}
+
+// rateLimitCollector reports registry rate limits as read from the tracker
+// at scrape time, so the values are never staler than the tracker itself.
+type rateLimitCollector struct {
+	src RateLimitSource
+}
+
+// NewRateLimitCollector returns a collector exposing per-registry rate limit
+// metrics from src. Limit, remaining and reset are only reported for
+// registries that return rate limit headers.
+func NewRateLimitCollector(src RateLimitSource) prometheus.Collector {
+	return &rateLimitCollector{src: src}
+}
+
+func (c *rateLimitCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- rateLimitLimitDesc
+	ch <- rateLimitRemainingDesc
+	ch <- rateLimitResetDesc
+	ch <- rateLimitDetectedDesc
+	ch <- rateLimitAuthDesc
+	ch <- rateLimitContainersDesc
+	ch <- rateLimitUpdatedDesc
+}
+
+func (c *rateLimitCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, s := range c.src.Status() {
+		gauge := func(desc *prometheus.Desc, v float64) {
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, v, s.Registry)
+		}
+		gauge(rateLimitDetectedDesc, boolFloat(s.HasLimits))
+		gauge(rateLimitAuthDesc, boolFloat(s.IsAuth))
+		gauge(rateLimitContainersDesc, float64(s.ContainerCount))
+		if !s.LastUpdated.IsZero() {
+			gauge(rateLimitUpdatedDesc, float64(s.LastUpdated.Unix()))
+		}
+		if !s.HasLimits {
+			continue
+		}
+		gauge(rateLimitLimitDesc, float64(s.Limit))
+		gauge(rateLimitRemainingDesc, float64(s.Remaining))
+		if !s.ResetAt.IsZero() {
+			gauge(rateLimitResetDesc, float64(s.ResetAt.Unix()))
+		}
+	}
+}
+
+func boolFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -6,35 +6,53 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 )
 
+// AppriseSettings holds configuration for an Apprise API gateway channel.
+// Tag names a persistent configuration on the Apprise server; without it,
+// Urls are sent for stateless delivery.
 type AppriseSettings struct {
 	URL  string `json:"url"`
-	Tag  string `json:"tag,omitempty"`
-	Urls string `json:"urls,omitempty"`
+	Tag  string `json:"tag,omitempty"`  // persistent config key
+	Urls string `json:"urls,omitempty"` // stateless Apprise URL(s), space or comma separated
+	Tags string `json:"tags,omitempty"` // Apprise tags to notify within the config, e.g. "sentinel" or "all"
 }
 
+// Apprise posts notifications to an Apprise API server, which fans each one
+// out to the services in its configuration (Signal, Teams, SMS and dozens
+// more that Sentinel has no native provider for).
 type Apprise struct {
 	url    string
 	tag    string
 	urls   string
+	tags   string
 	client *http.Client
 }
 
-func NewApprise(url, tag, urls string) *Apprise {
+// NewApprise creates an Apprise notifier. Either tag (a persistent config
+// key) or urls (stateless mode) must be set; tags filters which of the
+// config's services are notified.
+func NewApprise(url, tag, urls, tags string) *Apprise {
 	return &Apprise{
 		url:    strings.TrimRight(url, "/"),
 		tag:    tag,
 		urls:   urls,
+		tags:   tags,
 		client: &http.Client{Timeout: 15 * time.Second},
 	}
 }
 
+// Name returns the provider name for logging.
 func (a *Apprise) Name() string { return "apprise" }
 
+// Send posts a notification to the Apprise API.
 func (a *Apprise) Send(ctx context.Context, event Event) error {
+	if a.tag == "" && a.urls == "" {
+		return fmt.Errorf("apprise needs a config key or Apprise URLs")
+	}
 	title := formatTitle(event.Type)
 	body := formatMessage(event)
 
@@ -55,11 +73,14 @@ func (a *Apprise) Send(ctx context.Context, event Event) error {
 	}
 
 	if a.tag != "" {
-		endpoint = a.url + "/notify/" + a.tag
+		endpoint = a.url + "/notify/" + url.PathEscape(a.tag)
 	} else {
 		endpoint = a.url + "/notify/"
 		payload["urls"] = a.urls
 	}
+	if a.tags != "" {
+		payload["tag"] = a.tags
+	}
 
 	data, err := json.Marshal(payload)
 	if err != nil {
@@ -79,6 +100,14 @@ func (a *Apprise) Send(ctx context.Context, event Event) error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		// The API explains failures (no matching tags, a service that
+		// rejected the message) in an "error" field.
+		var aerr struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&aerr) == nil && aerr.Error != "" {
+			return fmt.Errorf("apprise returned %s: %s", resp.Status, aerr.Error)
+		}
 		return fmt.Errorf("apprise returned %s", resp.Status)
 	}
 	return nil
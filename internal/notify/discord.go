@@ -6,18 +6,33 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
+	"sync"
 	"time"
 )
 
+// discordMaxAttempts bounds sends to a rate-limited webhook; discordMaxWait
+// caps how long one Retry-After is honoured before giving up.
+const (
+	discordMaxAttempts = 3
+	discordMaxWait     = 30 * time.Second
+)
+
 // DiscordSettings holds configuration for a Discord webhook notification channel.
 type DiscordSettings struct {
 	WebhookURL string `json:"webhook_url"`
 }
 
-// Discord sends notifications to a Discord webhook.
+// Discord sends notifications to a Discord webhook. It follows Discord's
+// rate limits: an exhausted bucket delays the next send until it resets,
+// and a 429 is retried after the advertised Retry-After.
 type Discord struct {
 	webhookURL string
 	client     *http.Client
+	sleep      func(context.Context, time.Duration) error // overridden in tests
+
+	mu      sync.Mutex
+	resetAt time.Time // bucket exhausted until then; zero = not limited
 }
 
 // NewDiscord creates a Discord notifier for the given webhook URL.
@@ -25,6 +40,7 @@ func NewDiscord(webhookURL string) *Discord {
 	return &Discord{
 		webhookURL: webhookURL,
 		client:     &http.Client{Timeout: 10 * time.Second},
+		sleep:      sleepContext,
 	}
 }
 
@@ -35,13 +51,16 @@ func (d *Discord) Name() string { return "discord" }
 func (d *Discord) Send(ctx context.Context, event Event) error {
 	embed := discordEmbed{
 		Title:     formatTitle(event.Type),
+		URL:       event.ReleaseURL,
 		Color:     discordColor(event.Type),
 		Timestamp: event.Timestamp.UTC().Format(time.RFC3339),
 	}
 
-	embed.Fields = append(embed.Fields, discordField{
-		Name: "Container", Value: event.ContainerName, Inline: true,
-	})
+	if event.ContainerName != "" || event.Message == "" {
+		embed.Fields = append(embed.Fields, discordField{
+			Name: "Container", Value: event.ContainerName, Inline: true,
+		})
+	}
 	if event.OldImage != "" {
 		embed.Fields = append(embed.Fields, discordField{
 			Name: "Old Image", Value: event.OldImage, Inline: true,
@@ -52,33 +71,155 @@ func (d *Discord) Send(ctx context.Context, event Event) error {
 			Name: "New Image", Value: event.NewImage, Inline: true,
 		})
 	}
+	if v := event.VersionChange(); v != "" {
+		embed.Fields = append(embed.Fields, discordField{
+			Name: "Version", Value: v, Inline: true,
+		})
+	}
+	if event.ReleaseURL != "" {
+		embed.Fields = append(embed.Fields, discordField{
+			Name: "Changelog", Value: "[Release notes](" + event.ReleaseURL + ")", Inline: false,
+		})
+	}
+	if event.ReleaseHint != "" {
+		embed.Fields = append(embed.Fields, discordField{
+			Name: "Release", Value: event.ReleaseHint, Inline: false,
+		})
+	}
+	if event.URL != "" {
+		embed.Fields = append(embed.Fields, discordField{
+			Name: "Sentinel", Value: "[Open in Sentinel](" + event.URL + ")", Inline: false,
+		})
+		if embed.URL == "" {
+			embed.URL = event.URL
+		}
+	}
+	if event.Message != "" {
+		embed.Description = truncateRunes(event.Message, 4096)
+	}
 	if event.Error != "" {
 		embed.Fields = append(embed.Fields, discordField{
 			Name: "Error", Value: event.Error, Inline: false,
 		})
 	}
+	// A custom template replaces the fields with its own rendered body.
+	if event.Body != "" {
+		embed.Fields = nil
+		embed.Description = truncateRunes(event.Body, 4096)
+	}
+	// Discord rejects the whole message if any field exceeds its limit.
+	for i := range embed.Fields {
+		embed.Fields[i].Value = truncateRunes(embed.Fields[i].Value, 1024)
+	}
 
 	body, err := json.Marshal(discordPayload{Embeds: []discordEmbed{embed}})
 	if err != nil {
 		return fmt.Errorf("marshal discord payload: %w", err)
 	}
 
+	for attempt := 1; ; attempt++ {
+		if err := d.waitForBucket(ctx); err != nil {
+			return err
+		}
+		retryAfter, err := d.post(ctx, body)
+		if err == nil || retryAfter == 0 {
+			return err
+		}
+		if attempt == discordMaxAttempts || retryAfter > discordMaxWait {
+			return fmt.Errorf("%w (retry after %s)", err, retryAfter)
+		}
+		if err := d.sleep(ctx, retryAfter); err != nil {
+			return err
+		}
+	}
+}
+
+// post sends one webhook request. A 429 returns the Retry-After delay along
+// with the error; other failures return a zero delay.
+func (d *Discord) post(ctx context.Context, body []byte) (time.Duration, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.webhookURL, bytes.NewReader(body))
 	if err != nil {
-		return fmt.Errorf("create discord request: %w", err)
+		return 0, fmt.Errorf("create discord request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := d.client.Do(req)
 	if err != nil {
-		return fmt.Errorf("send discord request: %w", err)
+		return 0, fmt.Errorf("send discord request: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset := parseSeconds(resp.Header.Get("X-RateLimit-Reset-After")); reset > 0 {
+			d.mu.Lock()
+			d.resetAt = time.Now().Add(reset)
+			d.mu.Unlock()
+		}
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		retryAfter := parseSeconds(resp.Header.Get("Retry-After"))
+		if retryAfter == 0 {
+			var rl struct {
+				RetryAfter float64 `json:"retry_after"`
+			}
+			if json.NewDecoder(resp.Body).Decode(&rl) == nil {
+				retryAfter = time.Duration(rl.RetryAfter * float64(time.Second))
+			}
+		}
+		if retryAfter <= 0 {
+			retryAfter = time.Second
+		}
+		return retryAfter, fmt.Errorf("discord returned %s", resp.Status)
+	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("discord returned %s", resp.Status)
+		return 0, fmt.Errorf("discord returned %s", resp.Status)
+	}
+	return 0, nil
+}
+
+// waitForBucket blocks until an exhausted rate limit bucket resets.
+func (d *Discord) waitForBucket(ctx context.Context) error {
+	d.mu.Lock()
+	wait := time.Until(d.resetAt)
+	d.mu.Unlock()
+	if wait <= 0 {
+		return nil
+	}
+	if wait > discordMaxWait {
+		wait = discordMaxWait
+	}
+	return d.sleep(ctx, wait)
+}
+
+// parseSeconds parses a (possibly fractional) seconds header value.
+func parseSeconds(v string) time.Duration {
+	secs, err := strconv.ParseFloat(v, 64)
+	if err != nil || secs <= 0 {
+		return 0
+	}
+	return time.Duration(secs * float64(time.Second))
+}
+
+// sleepContext waits for d or until ctx is cancelled.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// truncateRunes shortens s to at most n runes, marking the cut with "…".
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
 	}
-	return nil
+	return string(r[:n-1]) + "…"
 }
 
 func discordColor(t EventType) int {
@@ -87,7 +228,7 @@ func discordColor(t EventType) int {
 		return 0x2ECC71 // green
 	case EventUpdateFailed, EventRollbackFailed:
 		return 0xE74C3C // red
-	case EventUpdateAvailable, EventVersionAvailable:
+	case EventUpdateAvailable, EventVersionAvailable, EventPinExpiring, EventQueueStale, EventRebuildNeeded:
 		return 0xF39C12 // orange
 	default:
 		return 0x3498DB // blue
@@ -99,10 +240,12 @@ type discordPayload struct {
 }
 
 type discordEmbed struct {
-	Title     string         `json:"title"`
-	Color     int            `json:"color"`
-	Fields    []discordField `json:"fields,omitempty"`
-	Timestamp string         `json:"timestamp,omitempty"`
+	Title       string         `json:"title"`
+	URL         string         `json:"url,omitempty"` // makes the title a link, e.g. to release notes
+	Description string         `json:"description,omitempty"`
+	Color       int            `json:"color"`
+	Fields      []discordField `json:"fields,omitempty"`
+	Timestamp   string         `json:"timestamp,omitempty"`
 }
 
 type discordField struct {
@@ -41,13 +41,20 @@ func newFilteredNotifier(inner Notifier, events []string) *filteredNotifier {
 // Name returns the name of the wrapped notifier.
 func (f *filteredNotifier) Name() string { return f.inner.Name() }
 
+// allows reports whether an event type is in the allowed set.
+func (f *filteredNotifier) allows(t EventType) bool {
+	if len(f.allowed) == 0 {
+		return true
+	}
+	_, ok := f.allowed[t]
+	return ok
+}
+
 // Send forwards the event to the inner notifier only if the event type
 // is in the allowed set.
 func (f *filteredNotifier) Send(ctx context.Context, event Event) error {
-	if len(f.allowed) > 0 {
-		if _, ok := f.allowed[event.Type]; !ok {
-			return nil
-		}
+	if !f.allows(event.Type) {
+		return nil
 	}
 	return f.inner.Send(ctx, event)
 }
@@ -43,6 +43,16 @@ func TestFormatMessage(t *testing.T) {
 		wantContains []string
 		wantMissing  []string
 	}{
+		{
+			name: "version change",
+			event: Event{
+				ContainerName: "app",
+				OldImage:      "app:latest",
+				OldVersion:    "1.4.2",
+				NewVersion:    "1.5.0",
+			},
+			wantContains: []string{"Version: 1.4.2 → 1.5.0"},
+		},
 		{
 			name: "all fields populated",
 			event: Event{
@@ -86,6 +96,14 @@ func TestFormatMessage(t *testing.T) {
 			},
 			wantContains: []string{"Container: \n"},
 		},
+		{
+			name: "message only",
+			event: Event{
+				Message: "Updates applied: 3",
+			},
+			wantContains: []string{"Updates applied: 3"},
+			wantMissing:  []string{"Container:"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -105,6 +123,24 @@ func TestFormatMessage(t *testing.T) {
 	}
 }
 
+func TestEventVersionChange(t *testing.T) {
+	tests := []struct {
+		old, new, want string
+	}{
+		{"1.0.0", "1.1.0", "1.0.0 → 1.1.0"},
+		{"1.0.0", "", "1.0.0"},
+		{"", "2.0.0", "→ 2.0.0"},
+		{"1.0.0", "1.0.0", "1.0.0"},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		e := Event{OldVersion: tt.old, NewVersion: tt.new}
+		if got := e.VersionChange(); got != tt.want {
+			t.Errorf("VersionChange(%q, %q) = %q, want %q", tt.old, tt.new, got, tt.want)
+		}
+	}
+}
+
 // --- formatMessageMarkdown tests ---
 
 func TestFormatMessageMarkdown(t *testing.T) {
@@ -43,6 +43,7 @@ func (g *Gotify) Send(ctx context.Context, event Event) error {
 		Title:    formatTitle(event.Type),
 		Message:  formatMessage(event),
 		Priority: priority(event.Type),
+		Extras:   gotifyExtras(event.URL),
 	})
 	if err != nil {
 		return fmt.Errorf("marshal gotify payload: %w", err)
@@ -68,9 +69,20 @@ func (g *Gotify) Send(ctx context.Context, event Event) error {
 }
 
 type gotifyMessage struct {
-	Title    string `json:"title"`
-	Message  string `json:"message"`
-	Priority int    `json:"priority"`
+	Title    string         `json:"title"`
+	Message  string         `json:"message"`
+	Priority int            `json:"priority"`
+	Extras   map[string]any `json:"extras,omitempty"`
+}
+
+// gotifyExtras makes a tap on the notification in the Gotify app open link.
+func gotifyExtras(link string) map[string]any {
+	if link == "" {
+		return nil
+	}
+	return map[string]any{
+		"client::notification": map[string]any{"click": map[string]string{"url": link}},
+	}
 }
 
 // formatTitle produces a human-readable notification title.
@@ -86,33 +98,74 @@ func formatTitle(t EventType) string {
 
 // formatMessage builds the notification body from event fields.
 func formatMessage(e Event) string {
+	if e.Body != "" {
+		return e.Body
+	}
 	var b strings.Builder
-	fmt.Fprintf(&b, "Container: %s\n", e.ContainerName)
+	// Message-only events such as the weekly report have no container.
+	if e.ContainerName != "" || e.Message == "" {
+		fmt.Fprintf(&b, "Container: %s\n", e.ContainerName)
+	}
 	if e.OldImage != "" {
 		fmt.Fprintf(&b, "Old image: %s\n", e.OldImage)
 	}
 	if e.NewImage != "" {
 		fmt.Fprintf(&b, "New image: %s\n", e.NewImage)
 	}
+	if v := e.VersionChange(); v != "" {
+		fmt.Fprintf(&b, "Version: %s\n", v)
+	}
+	if e.Message != "" {
+		fmt.Fprintf(&b, "%s\n", e.Message)
+	}
 	if e.Error != "" {
 		fmt.Fprintf(&b, "Error: %s\n", e.Error)
 	}
+	if e.ReleaseURL != "" {
+		fmt.Fprintf(&b, "Release notes: %s\n", e.ReleaseURL)
+	}
+	if e.ReleaseHint != "" {
+		fmt.Fprintf(&b, "Release: %s\n", e.ReleaseHint)
+	}
+	if e.URL != "" {
+		fmt.Fprintf(&b, "Open in Sentinel: %s\n", e.URL)
+	}
 	return b.String()
 }
 
 // formatMessageMarkdown builds the notification body with markdown formatting.
 func formatMessageMarkdown(e Event) string {
+	if e.Body != "" {
+		return e.Body
+	}
 	var b strings.Builder
-	fmt.Fprintf(&b, "**Container:** `%s`\n", e.ContainerName)
+	if e.ContainerName != "" || e.Message == "" {
+		fmt.Fprintf(&b, "**Container:** `%s`\n", e.ContainerName)
+	}
 	if e.OldImage != "" {
 		fmt.Fprintf(&b, "**Old image:** `%s`\n", e.OldImage)
 	}
 	if e.NewImage != "" {
 		fmt.Fprintf(&b, "**New image:** `%s`\n", e.NewImage)
 	}
+	if v := e.VersionChange(); v != "" {
+		fmt.Fprintf(&b, "**Version:** %s\n", v)
+	}
+	if e.Message != "" {
+		fmt.Fprintf(&b, "%s\n", e.Message)
+	}
 	if e.Error != "" {
 		fmt.Fprintf(&b, "**Error:** %s\n", e.Error)
 	}
+	if e.ReleaseURL != "" {
+		fmt.Fprintf(&b, "**Release notes:** %s\n", e.ReleaseURL)
+	}
+	if e.ReleaseHint != "" {
+		fmt.Fprintf(&b, "**Release:** %s\n", e.ReleaseHint)
+	}
+	if e.URL != "" {
+		fmt.Fprintf(&b, "**Open in Sentinel:** %s\n", e.URL)
+	}
 	return b.String()
 }
 
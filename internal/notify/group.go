@@ -0,0 +1,221 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// GroupPolicy decides when a group send counts as delivered.
+type GroupPolicy string
+
+const (
+	GroupAny GroupPolicy = "any" // delivered if at least one member succeeds (default)
+	GroupAll GroupPolicy = "all" // delivered only if every member succeeds
+)
+
+// GroupSettings holds configuration for a channel group: several channels
+// addressed as one target.
+type GroupSettings struct {
+	Members []string    `json:"members"`          // channel IDs
+	Policy  GroupPolicy `json:"policy,omitempty"` // "any" (default) or "all"
+}
+
+// ValidateGroups checks that every group names at least one existing,
+// non-group channel and uses a known failure policy.
+func ValidateGroups(channels []Channel) error {
+	byID := make(map[string]Channel, len(channels))
+	for _, ch := range channels {
+		byID[ch.ID] = ch
+	}
+	for _, ch := range channels {
+		if ch.Type != ProviderGroup {
+			continue
+		}
+		var s GroupSettings
+		if err := json.Unmarshal(ch.Settings, &s); err != nil {
+			return fmt.Errorf("group %q: invalid settings: %w", ch.Name, err)
+		}
+		if len(s.Members) == 0 {
+			return fmt.Errorf("group %q has no members", ch.Name)
+		}
+		switch s.Policy {
+		case "", GroupAny, GroupAll:
+		default:
+			return fmt.Errorf("group %q: policy must be any or all", ch.Name)
+		}
+		for _, id := range s.Members {
+			m, ok := byID[id]
+			if !ok {
+				return fmt.Errorf("group %q: unknown member channel %s", ch.Name, id)
+			}
+			if m.Type == ProviderGroup {
+				return fmt.Errorf("group %q: groups cannot contain other groups", ch.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// BuildChannel constructs the notifier for one channel, resolving a group's
+// members against all. Each member keeps its own event filter; disabled
+// members are skipped. The result is tagged with the channel ID for routing.
+func BuildChannel(ch Channel, all []Channel) (Notifier, error) {
+	if ch.Type != ProviderGroup {
+		n, err := BuildFilteredNotifier(ch)
+		if err != nil {
+			return nil, err
+		}
+		return &channelNotifier{Notifier: n, id: ch.ID}, nil
+	}
+
+	var s GroupSettings
+	if err := json.Unmarshal(ch.Settings, &s); err != nil {
+		return nil, fmt.Errorf("unmarshal group settings: %w", err)
+	}
+	g := &groupNotifier{id: ch.ID, name: ch.Name, requireAll: s.Policy == GroupAll}
+	if len(ch.Events) > 0 {
+		g.filter = newFilteredNotifier(nil, ch.Events)
+	}
+	for _, id := range s.Members {
+		idx := slices.IndexFunc(all, func(c Channel) bool { return c.ID == id })
+		if idx < 0 || !all[idx].Enabled || all[idx].Type == ProviderGroup {
+			continue
+		}
+		n, err := BuildFilteredNotifier(all[idx])
+		if err != nil {
+			return nil, fmt.Errorf("member %s: %w", all[idx].Name, err)
+		}
+		g.members = append(g.members, &channelNotifier{Notifier: n, id: id})
+	}
+	if len(g.members) == 0 {
+		return nil, fmt.Errorf("group %q has no enabled members", ch.Name)
+	}
+	return g, nil
+}
+
+// BuildChannels constructs notifiers for the enabled channels in list,
+// resolving groups against all. A channel that belongs to an enabled group in
+// list is reached through the group only, so it is not notified twice.
+// Channels that fail to build are reported to onErr and skipped.
+func BuildChannels(list, all []Channel, onErr func(Channel, error)) []Notifier {
+	grouped := make(map[string]bool)
+	for _, ch := range list {
+		if ch.Type != ProviderGroup || !ch.Enabled {
+			continue
+		}
+		var s GroupSettings
+		if json.Unmarshal(ch.Settings, &s) == nil {
+			for _, id := range s.Members {
+				grouped[id] = true
+			}
+		}
+	}
+
+	var notifiers []Notifier
+	for _, ch := range list {
+		if !ch.Enabled || grouped[ch.ID] {
+			continue
+		}
+		n, err := BuildChannel(ch, all)
+		if err != nil {
+			if onErr != nil {
+				onErr(ch, err)
+			}
+			continue
+		}
+		notifiers = append(notifiers, n)
+	}
+	return notifiers
+}
+
+// routable is implemented by notifiers that belong to a channel, so a
+// per-container route can select them. Route returns the notifier to use
+// for the target channel IDs, or nil when none of them apply.
+type routable interface {
+	Route(targets map[string]bool) Notifier
+}
+
+// channelNotifier tags a built notifier with its channel ID.
+type channelNotifier struct {
+	Notifier
+	id        string
+	templates Templates // custom templates; see ApplyTemplates
+}
+
+// Send renders the event body from the channel's own template, or from the
+// global one when no group has rendered it already, then delivers it.
+func (c *channelNotifier) Send(ctx context.Context, event Event) error {
+	eventType := string(event.Type)
+	if tmpl := c.templates[ChannelTemplateKey(c.id, eventType)]; tmpl != "" {
+		event.Body = renderBody(tmpl, event)
+	} else if tmpl := c.templates[eventType]; tmpl != "" && event.Body == "" {
+		event.Body = renderBody(tmpl, event)
+	}
+	return c.Notifier.Send(ctx, event)
+}
+
+// Route selects the channel when it is one of the targets.
+func (c *channelNotifier) Route(targets map[string]bool) Notifier {
+	if targets[c.id] {
+		return c
+	}
+	return nil
+}
+
+// groupNotifier sends to several channels as one logical target.
+type groupNotifier struct {
+	id         string
+	name       string
+	members    []Notifier
+	requireAll bool
+	filter     *filteredNotifier // group-level event filter; nil = all events
+	templates  Templates         // custom templates; see ApplyTemplates
+}
+
+// Name returns the group's name.
+func (g *groupNotifier) Name() string { return g.name }
+
+// Send delivers the event to every member. With the default policy it
+// succeeds if any member delivers; with GroupAll every member must.
+func (g *groupNotifier) Send(ctx context.Context, event Event) error {
+	if g.filter != nil && !g.filter.allows(event.Type) {
+		return nil
+	}
+	// The group's own template applies to members without one of their own.
+	if tmpl := g.templates[ChannelTemplateKey(g.id, string(event.Type))]; tmpl != "" {
+		event.Body = renderBody(tmpl, event)
+	}
+	var errs []error
+	for _, m := range g.members {
+		if err := m.Send(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", m.Name(), err))
+		}
+	}
+	if len(errs) == 0 || (!g.requireAll && len(errs) < len(g.members)) {
+		return nil
+	}
+	return errors.Join(errs...)
+}
+
+// Route selects the whole group when it is a target, or just the members
+// that are targeted themselves.
+func (g *groupNotifier) Route(targets map[string]bool) Notifier {
+	if targets[g.id] {
+		return g
+	}
+	var picked []Notifier
+	for _, m := range g.members {
+		if r, ok := m.(routable); ok && r.Route(targets) != nil {
+			picked = append(picked, m)
+		}
+	}
+	if len(picked) == 0 {
+		return nil
+	}
+	sub := *g
+	sub.members = picked
+	return &sub
+}
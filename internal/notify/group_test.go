@@ -0,0 +1,137 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func groupChannel(t *testing.T, id string, policy GroupPolicy, members ...string) Channel {
+	t.Helper()
+	settings, err := json.Marshal(GroupSettings{Members: members, Policy: policy})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Channel{ID: id, Type: ProviderGroup, Name: id, Enabled: true, Settings: settings}
+}
+
+func gotifyChannel(id string) Channel {
+	return Channel{ID: id, Type: ProviderGotify, Name: id, Enabled: true, Settings: []byte(`{"url":"http://example.com","token":"tok"}`)}
+}
+
+func TestGroupNotifierPolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		requireAll bool
+		errs       []error
+		wantErr    bool
+	}{
+		{"any, one fails", false, []error{errors.New("down"), nil}, false},
+		{"any, all fail", false, []error{errors.New("down"), errors.New("down")}, true},
+		{"all, one fails", true, []error{errors.New("down"), nil}, true},
+		{"all, none fail", true, []error{nil, nil}, false},
+	}
+	for _, tt := range tests {
+		g := &groupNotifier{name: "oncall", requireAll: tt.requireAll}
+		var stubs []*stubNotifier
+		for i, err := range tt.errs {
+			s := &stubNotifier{name: string(rune('a' + i)), err: err}
+			stubs = append(stubs, s)
+			g.members = append(g.members, s)
+		}
+		err := g.Send(context.Background(), testEvent(EventUpdateFailed))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		for _, s := range stubs {
+			if len(s.sent) != 1 {
+				t.Errorf("%s: member %s got %d events, want 1", tt.name, s.name, len(s.sent))
+			}
+		}
+		if err != nil && !strings.Contains(err.Error(), "a: down") {
+			t.Errorf("%s: error %q does not name the failing member", tt.name, err)
+		}
+	}
+}
+
+func TestValidateGroups(t *testing.T) {
+	a, b := gotifyChannel("a"), gotifyChannel("b")
+	tests := []struct {
+		name    string
+		chans   []Channel
+		wantErr string
+	}{
+		{"valid", []Channel{a, b, groupChannel(t, "g", GroupAny, "a", "b")}, ""},
+		{"empty", []Channel{a, groupChannel(t, "g", "")}, "no members"},
+		{"unknown member", []Channel{a, groupChannel(t, "g", "", "zz")}, "unknown member"},
+		{"nested", []Channel{a, groupChannel(t, "g1", "", "a"), groupChannel(t, "g2", "", "g1")}, "other groups"},
+		{"bad policy", []Channel{a, groupChannel(t, "g", "most", "a")}, "policy"},
+	}
+	for _, tt := range tests {
+		err := ValidateGroups(tt.chans)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", tt.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: err = %v, want it to mention %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestBuildChannelsSkipsGroupedMembers(t *testing.T) {
+	a, b, c := gotifyChannel("a"), gotifyChannel("b"), gotifyChannel("c")
+	disabled := gotifyChannel("d")
+	disabled.Enabled = false
+	chans := []Channel{a, b, c, disabled, groupChannel(t, "g", GroupAny, "a", "b", "d")}
+
+	notifiers := BuildChannels(chans, chans, func(ch Channel, err error) {
+		t.Errorf("build %s: %v", ch.ID, err)
+	})
+	if len(notifiers) != 2 {
+		t.Fatalf("got %d notifiers, want 2 (c and the group)", len(notifiers))
+	}
+	g, ok := notifiers[1].(*groupNotifier)
+	if !ok {
+		t.Fatalf("second notifier is %T, want *groupNotifier", notifiers[1])
+	}
+	if len(g.members) != 2 {
+		t.Errorf("group has %d members, want 2 (disabled member skipped)", len(g.members))
+	}
+}
+
+func TestMultiRouter(t *testing.T) {
+	a := &stubNotifier{name: "a"}
+	b := &stubNotifier{name: "b"}
+	c := &stubNotifier{name: "c"}
+	logN := &stubNotifier{name: "log"}
+	group := &groupNotifier{id: "g", name: "oncall", members: []Notifier{
+		&channelNotifier{Notifier: a, id: "a"},
+		&channelNotifier{Notifier: b, id: "b"},
+	}}
+	m := NewMulti(&spyLogger{}, logN, group, &channelNotifier{Notifier: c, id: "c"})
+	routes := map[string][]string{"nginx": {"g"}, "redis": {"b"}}
+	m.SetRouter(func(name string) []string { return routes[name] })
+
+	m.Notify(context.Background(), testEvent(EventUpdateSucceeded))
+	if len(a.sent) != 1 || len(b.sent) != 1 || len(c.sent) != 0 || len(logN.sent) != 1 {
+		t.Fatalf("group route: a=%d b=%d c=%d log=%d, want 1 1 0 1", len(a.sent), len(b.sent), len(c.sent), len(logN.sent))
+	}
+
+	ev := testEvent(EventUpdateSucceeded)
+	ev.ContainerName = "redis"
+	m.Notify(context.Background(), ev)
+	if len(a.sent) != 1 || len(b.sent) != 2 || len(c.sent) != 0 {
+		t.Fatalf("member route: a=%d b=%d c=%d, want 1 2 0", len(a.sent), len(b.sent), len(c.sent))
+	}
+
+	ev.ContainerName = "postgres"
+	m.Notify(context.Background(), ev)
+	if len(a.sent) != 2 || len(b.sent) != 3 || len(c.sent) != 1 {
+		t.Fatalf("unrouted: a=%d b=%d c=%d, want 2 3 1", len(a.sent), len(b.sent), len(c.sent))
+	}
+}
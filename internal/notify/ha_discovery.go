@@ -144,6 +144,97 @@ func (h *HADiscovery) PublishPendingCount(count int) error {
 	return nil
 }
 
+// ContainerHealth is the per-container state published as HA binary sensors.
+type ContainerHealth struct {
+	UpdateAvailable  bool
+	UpdateInProgress bool
+	LastUpdateFailed bool
+}
+
+// PublishContainerHealth publishes the update_available, update_in_progress
+// and last_update_failed binary sensors for a container.
+func (h *HADiscovery) PublishContainerHealth(name string, health ContainerHealth) error {
+	if err := h.PublishContainerState(name, health.UpdateAvailable); err != nil {
+		return err
+	}
+	safeID := sanitizeID(name)
+	sensors := []struct {
+		key, label, class string
+		on                bool
+	}{
+		{"update_in_progress", "Updating", "running", health.UpdateInProgress},
+		{"last_update_failed", "Last Update Failed", "problem", health.LastUpdateFailed},
+	}
+	for _, sensor := range sensors {
+		objectID := fmt.Sprintf("sentinel_%s_%s", safeID, sensor.key)
+		stateTopic := fmt.Sprintf("%s/containers/%s/%s", h.baseTopic, safeID, sensor.key)
+		if err := h.publishBinarySensor(objectID, fmt.Sprintf("Sentinel %s %s", name, sensor.label), sensor.class, stateTopic, sensor.on, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PublishProblem publishes the aggregate problem sensor, which is on while
+// any container's last update failed. The failing containers are published
+// as attributes.
+func (h *HADiscovery) PublishProblem(failing []string) error {
+	if failing == nil {
+		failing = []string{}
+	}
+	return h.publishBinarySensor("sentinel_problem", "Sentinel Problem", "problem",
+		h.baseTopic+"/problem", len(failing) > 0, map[string]interface{}{"failing": failing})
+}
+
+// publishBinarySensor publishes a retained discovery config and state for a
+// binary sensor. Non-nil attrs are published to a JSON attributes topic.
+func (h *HADiscovery) publishBinarySensor(objectID, name, deviceClass, stateTopic string, on bool, attrs map[string]interface{}) error {
+	config := map[string]interface{}{
+		"name":         name,
+		"unique_id":    objectID,
+		"state_topic":  stateTopic,
+		"payload_on":   "ON",
+		"payload_off":  "OFF",
+		"device_class": deviceClass,
+		"device": map[string]interface{}{
+			"identifiers":  []string{"docker_sentinel"},
+			"name":         "Docker Sentinel",
+			"manufacturer": "Docker Sentinel",
+			"model":        "Container Update Monitor",
+		},
+	}
+	if attrs != nil {
+		config["json_attributes_topic"] = stateTopic + "/attributes"
+	}
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	configTopic := fmt.Sprintf("%s/binary_sensor/%s/config", h.prefix, objectID)
+	if token := h.broker.Publish(configTopic, 1, true, configJSON); token.WaitTimeout(5*time.Second) && token.Error() != nil {
+		return token.Error()
+	}
+
+	state := "OFF"
+	if on {
+		state = "ON"
+	}
+	if token := h.broker.Publish(stateTopic, 1, true, []byte(state)); token.WaitTimeout(5*time.Second) && token.Error() != nil {
+		return token.Error()
+	}
+
+	if attrs != nil {
+		attrsJSON, err := json.Marshal(attrs)
+		if err != nil {
+			return err
+		}
+		if token := h.broker.Publish(stateTopic+"/attributes", 1, true, attrsJSON); token.WaitTimeout(5*time.Second) && token.Error() != nil {
+			return token.Error()
+		}
+	}
+	return nil
+}
+
 func sanitizeID(s string) string {
 	var b []byte
 	for _, c := range []byte(s) {
@@ -0,0 +1,83 @@
+package notify
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// fakeBroker records retained publishes by topic.
+type fakeBroker struct {
+	mqtt.Client
+	published map[string]string
+}
+
+func (f *fakeBroker) Publish(topic string, _ byte, _ bool, payload interface{}) mqtt.Token {
+	f.published[topic] = string(payload.([]byte))
+	return doneToken{}
+}
+
+type doneToken struct{}
+
+func (doneToken) Wait() bool                     { return true }
+func (doneToken) WaitTimeout(time.Duration) bool { return true }
+func (doneToken) Done() <-chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}
+func (doneToken) Error() error { return nil }
+
+func TestPublishContainerHealth(t *testing.T) {
+	broker := &fakeBroker{published: map[string]string{}}
+	h := &HADiscovery{broker: broker, prefix: "homeassistant", baseTopic: "sentinel"}
+
+	if err := h.PublishContainerHealth("my-app", ContainerHealth{UpdateInProgress: true, LastUpdateFailed: true}); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"sentinel/containers/my_app/update_available":   "OFF",
+		"sentinel/containers/my_app/update_in_progress": "ON",
+		"sentinel/containers/my_app/last_update_failed": "ON",
+	}
+	for topic, state := range want {
+		if broker.published[topic] != state {
+			t.Errorf("%s = %q, want %q", topic, broker.published[topic], state)
+		}
+	}
+
+	var config map[string]any
+	if err := json.Unmarshal([]byte(broker.published["homeassistant/binary_sensor/sentinel_my_app_last_update_failed/config"]), &config); err != nil {
+		t.Fatalf("last_update_failed config: %v", err)
+	}
+	if config["device_class"] != "problem" {
+		t.Errorf("device_class = %v, want problem", config["device_class"])
+	}
+}
+
+func TestPublishProblem(t *testing.T) {
+	broker := &fakeBroker{published: map[string]string{}}
+	h := &HADiscovery{broker: broker, prefix: "homeassistant", baseTopic: "sentinel"}
+
+	if err := h.PublishProblem([]string{"db"}); err != nil {
+		t.Fatal(err)
+	}
+	if broker.published["sentinel/problem"] != "ON" {
+		t.Errorf("problem = %q, want ON", broker.published["sentinel/problem"])
+	}
+	if got := broker.published["sentinel/problem/attributes"]; got != `{"failing":["db"]}` {
+		t.Errorf("attributes = %s", got)
+	}
+
+	if err := h.PublishProblem(nil); err != nil {
+		t.Fatal(err)
+	}
+	if broker.published["sentinel/problem"] != "OFF" {
+		t.Errorf("problem = %q, want OFF once nothing is failing", broker.published["sentinel/problem"])
+	}
+	if got := broker.published["sentinel/problem/attributes"]; got != `{"failing":[]}` {
+		t.Errorf("attributes = %s, want an empty list", got)
+	}
+}
@@ -0,0 +1,45 @@
+package notify
+
+import (
+	"net/url"
+	"strings"
+)
+
+// DeepLink returns the web UI page for an event under base, the external
+// URL Sentinel is reached at: the approval queue for stale approvals, the
+// container's timeline for rollbacks, so the failed attempt can be traced,
+// the container page (scoped to its cluster host, if any) for other
+// single-container events, else the dashboard. Returns "" when base is empty.
+func DeepLink(base string, e Event) string {
+	base = strings.TrimRight(base, "/")
+	if base == "" {
+		return ""
+	}
+	switch {
+	case e.Type == EventQueueStale:
+		return base + "/queue"
+	case e.ContainerName == "" || len(e.ContainerNames) > 0:
+		// Message-only events and batched summaries have no single page.
+		return base + "/"
+	}
+	if e.Type == EventRollbackOK || e.Type == EventRollbackFailed {
+		q := url.Values{"container": {e.ContainerName}}
+		if e.HostID != "" {
+			q.Set("host", e.HostID)
+		}
+		return base + "/timeline?" + q.Encode()
+	}
+	link := base + "/container/" + url.PathEscape(e.ContainerName)
+	if e.HostID != "" {
+		link += "?host=" + url.QueryEscape(e.HostID)
+	}
+	return link
+}
+
+// SetExternalURL sets the base URL used for links back to the web UI in
+// notifications. An empty URL leaves links out.
+func (m *Multi) SetExternalURL(base string) {
+	m.mu.Lock()
+	m.externalURL = strings.TrimRight(base, "/")
+	m.mu.Unlock()
+}
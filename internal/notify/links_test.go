@@ -0,0 +1,86 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDeepLink(t *testing.T) {
+	tests := []struct {
+		name  string
+		base  string
+		event Event
+		want  string
+	}{
+		{"no base", "", Event{Type: EventUpdateAvailable, ContainerName: "nginx"}, ""},
+		{"container", "https://sentinel.example.com/", Event{Type: EventUpdateAvailable, ContainerName: "nginx"}, "https://sentinel.example.com/container/nginx"},
+		{"sub-path base", "https://example.com/sentinel", Event{Type: EventUpdateFailed, ContainerName: "web app"}, "https://example.com/sentinel/container/web%20app"},
+		{"stale approval", "https://s.example", Event{Type: EventQueueStale, ContainerName: "db", HostID: "h1"}, "https://s.example/queue"},
+		{"host-scoped container", "https://s.example", Event{Type: EventUpdateAvailable, ContainerName: "db", HostID: "edge 1"}, "https://s.example/container/db?host=edge+1"},
+		{"rollback timeline", "https://s.example", Event{Type: EventRollbackOK, ContainerName: "db", HostID: "h1"}, "https://s.example/timeline?container=db&host=h1"},
+		{"message only", "https://s.example", Event{Type: EventReport, Message: "weekly"}, "https://s.example/"},
+		{"batch summary", "https://s.example", Event{Type: EventUpdateAvailable, ContainerName: "3 containers", ContainerNames: []string{"a", "b", "c"}}, "https://s.example/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DeepLink(tt.base, tt.event); got != tt.want {
+				t.Errorf("DeepLink = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMultiAddsDeepLink(t *testing.T) {
+	n := &stubNotifier{name: "a"}
+	m := NewMulti(&spyLogger{}, n)
+
+	m.Notify(context.Background(), testEvent(EventUpdateAvailable))
+	if n.sent[0].URL != "" {
+		t.Errorf("URL = %q without an external URL, want empty", n.sent[0].URL)
+	}
+
+	m.SetExternalURL("https://sentinel.example.com/")
+	m.Notify(context.Background(), testEvent(EventUpdateAvailable))
+	if got := n.sent[1].URL; got != "https://sentinel.example.com/container/nginx" {
+		t.Errorf("URL = %q", got)
+	}
+
+	// A link set by the caller is kept.
+	e := testEvent(EventUpdateAvailable)
+	e.URL = "https://other.example/x"
+	m.Notify(context.Background(), e)
+	if got := n.sent[2].URL; got != e.URL {
+		t.Errorf("URL = %q, want caller's %q", got, e.URL)
+	}
+}
+
+func TestDeepLinkInPayloads(t *testing.T) {
+	e := testEvent(EventUpdateAvailable)
+	e.URL = "https://sentinel.example.com/container/nginx"
+
+	for name, body := range map[string]string{
+		"text":     formatMessage(e),
+		"markdown": formatMessageMarkdown(e),
+		"html":     formatMessageHTML(e),
+		"template": renderBody("{{.ContainerName}} {{.URL}}", e),
+	} {
+		if !strings.Contains(body, e.URL) {
+			t.Errorf("%s body has no link: %q", name, body)
+		}
+	}
+
+	var click string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		click = r.Header.Get("X-Click")
+	}))
+	defer srv.Close()
+	if err := NewNtfy(srv.URL, "topic", 3, "", "", "").Send(context.Background(), e); err != nil {
+		t.Fatal(err)
+	}
+	if click != e.URL {
+		t.Errorf("ntfy X-Click = %q, want %q", click, e.URL)
+	}
+}
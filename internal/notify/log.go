@@ -26,6 +26,7 @@ func (l *LogNotifier) Send(_ context.Context, event Event) error {
 		"old_digest", event.OldDigest,
 		"new_digest", event.NewDigest,
 		"error", event.Error,
+		"message", event.Message,
 		"timestamp", event.Timestamp.String(),
 	)
 	return nil
@@ -0,0 +1,139 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// MatrixSettings holds configuration for a Matrix notification channel.
+type MatrixSettings struct {
+	Homeserver  string `json:"homeserver"`   // e.g. "https://matrix.example.org"
+	AccessToken string `json:"access_token"` // token of the account that posts
+	RoomID      string `json:"room_id"`      // e.g. "!abcdef:example.org"
+}
+
+// Matrix posts notifications to a Matrix room through the client-server
+// API. Messages are sent as m.notice, the msgtype for bot output, with an
+// HTML body for clients that render it.
+type Matrix struct {
+	homeserver string
+	token      string
+	roomID     string
+	client     *http.Client
+	txn        atomic.Uint64
+}
+
+// NewMatrix creates a Matrix notifier. The account behind token must
+// already have joined roomID.
+func NewMatrix(homeserver, token, roomID string) *Matrix {
+	return &Matrix{
+		homeserver: strings.TrimRight(homeserver, "/"),
+		token:      token,
+		roomID:     roomID,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name returns the provider name for logging.
+func (m *Matrix) Name() string { return "matrix" }
+
+// Send posts a notification message to the configured room.
+func (m *Matrix) Send(ctx context.Context, event Event) error {
+	title := formatTitle(event.Type)
+	body, err := json.Marshal(matrixMessage{
+		MsgType:       "m.notice",
+		Body:          title + "\n" + formatMessage(event),
+		Format:        "org.matrix.custom.html",
+		FormattedBody: "<b>" + html.EscapeString(title) + "</b><br>" + formatMessageHTML(event),
+	})
+	if err != nil {
+		return fmt.Errorf("marshal matrix payload: %w", err)
+	}
+
+	// The transaction ID makes retries idempotent: the homeserver drops a
+	// repeated PUT with the same ID instead of posting twice.
+	txnID := fmt.Sprintf("sentinel-%d-%d", time.Now().UnixNano(), m.txn.Add(1))
+	endpoint := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		m.homeserver, url.PathEscape(m.roomID), txnID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create matrix request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+m.token)
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send matrix request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var merr struct {
+			ErrCode string `json:"errcode"`
+			Error   string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&merr) == nil && merr.ErrCode != "" {
+			return fmt.Errorf("matrix returned %s: %s %s", resp.Status, merr.ErrCode, merr.Error)
+		}
+		return fmt.Errorf("matrix returned %s", resp.Status)
+	}
+	return nil
+}
+
+type matrixMessage struct {
+	MsgType       string `json:"msgtype"`
+	Body          string `json:"body"`
+	Format        string `json:"format,omitempty"`
+	FormattedBody string `json:"formatted_body,omitempty"`
+}
+
+// formatMessageHTML builds the notification body as escaped HTML, for
+// providers that accept it.
+func formatMessageHTML(e Event) string {
+	if e.Body != "" {
+		return strings.ReplaceAll(html.EscapeString(e.Body), "\n", "<br>")
+	}
+	var b strings.Builder
+	line := func(label, value string) {
+		fmt.Fprintf(&b, "<b>%s:</b> %s<br>", label, html.EscapeString(value))
+	}
+	if e.ContainerName != "" || e.Message == "" {
+		fmt.Fprintf(&b, "<b>Container:</b> <code>%s</code><br>", html.EscapeString(e.ContainerName))
+	}
+	if e.OldImage != "" {
+		fmt.Fprintf(&b, "<b>Old image:</b> <code>%s</code><br>", html.EscapeString(e.OldImage))
+	}
+	if e.NewImage != "" {
+		fmt.Fprintf(&b, "<b>New image:</b> <code>%s</code><br>", html.EscapeString(e.NewImage))
+	}
+	if v := e.VersionChange(); v != "" {
+		line("Version", v)
+	}
+	if e.Message != "" {
+		b.WriteString(strings.ReplaceAll(html.EscapeString(e.Message), "\n", "<br>"))
+		b.WriteString("<br>")
+	}
+	if e.Error != "" {
+		line("Error", e.Error)
+	}
+	if e.ReleaseURL != "" {
+		fmt.Fprintf(&b, "<a href=\"%s\">Release notes</a><br>", html.EscapeString(e.ReleaseURL))
+	}
+	if e.ReleaseHint != "" {
+		line("Release", e.ReleaseHint)
+	}
+	if e.URL != "" {
+		fmt.Fprintf(&b, "<a href=\"%s\">Open in Sentinel</a><br>", html.EscapeString(e.URL))
+	}
+	return b.String()
+}
@@ -80,7 +80,10 @@ func (m *MQTT) Send(ctx context.Context, event Event) error {
 		ContainerName: event.ContainerName,
 		OldImage:      event.OldImage,
 		NewImage:      event.NewImage,
+		OldVersion:    event.OldVersion,
+		NewVersion:    event.NewVersion,
 		Error:         event.Error,
+		URL:           event.URL,
 		Timestamp:     event.Timestamp.UTC().Format(time.RFC3339),
 	}
 	body, err := json.Marshal(payload)
@@ -103,6 +106,9 @@ type mqttPayload struct {
 	ContainerName string `json:"container_name"`
 	OldImage      string `json:"old_image,omitempty"`
 	NewImage      string `json:"new_image,omitempty"`
+	OldVersion    string `json:"old_version,omitempty"`
+	NewVersion    string `json:"new_version,omitempty"`
 	Error         string `json:"error,omitempty"`
+	URL           string `json:"url,omitempty"`
 	Timestamp     string `json:"timestamp"`
 }
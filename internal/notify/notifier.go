@@ -20,6 +20,11 @@ const (
 	EventVersionAvailable EventType = "version_available"
 	EventContainerState   EventType = "container_state"
 	EventDigest           EventType = "digest"
+	EventPinExpiring      EventType = "pin_expiring"
+	EventPinExpired       EventType = "pin_expired"
+	EventReport           EventType = "report"
+	EventQueueStale       EventType = "queue_stale"
+	EventRebuildNeeded    EventType = "rebuild_needed"
 )
 
 // AllEventTypes returns all event types that can be filtered for notifications.
@@ -34,6 +39,11 @@ func AllEventTypes() []EventType {
 		EventRollbackFailed,
 		EventContainerState,
 		EventDigest,
+		EventPinExpiring,
+		EventPinExpired,
+		EventReport,
+		EventQueueStale,
+		EventRebuildNeeded,
 	}
 }
 
@@ -47,9 +57,30 @@ type Event struct {
 	NewDigest      string    `json:"new_digest,omitempty"`
 	Error          string    `json:"error,omitempty"`
 	ContainerNames []string  `json:"container_names,omitempty"`
+	ReleaseURL     string    `json:"release_url,omitempty"`  // changelog link for the new version, when known
+	ReleaseHint    string    `json:"release_hint,omitempty"` // note on the project's releases, e.g. possibly end-of-life
+	HostID         string    `json:"host_id,omitempty"`      // cluster host the container runs on; "" = local
+	URL            string    `json:"url,omitempty"`          // link to the event's page in the web UI, when an external URL is set
+	OldVersion     string    `json:"old_version,omitempty"`  // human version currently running (e.g. "1.25.3")
+	NewVersion     string    `json:"new_version,omitempty"`  // human version of the candidate, when known
+	Message        string    `json:"message,omitempty"`      // free-form detail for events without an image change
+	Body           string    `json:"body,omitempty"`         // body rendered from the channel's custom template, if any
 	Timestamp      time.Time `json:"timestamp"`
 }
 
+// VersionChange renders the event's version transition as "old → new",
+// or just the known side when only one is set.
+func (e Event) VersionChange() string {
+	switch {
+	case e.OldVersion != "" && e.NewVersion != "" && e.OldVersion != e.NewVersion:
+		return e.OldVersion + " → " + e.NewVersion
+	case e.NewVersion != "" && e.OldVersion == "":
+		return "→ " + e.NewVersion
+	default:
+		return e.OldVersion
+	}
+}
+
 // Notifier sends events to an external system.
 type Notifier interface {
 	Send(ctx context.Context, event Event) error
@@ -81,6 +112,9 @@ type Multi struct {
 	retryMu      sync.RWMutex
 	maxRetries   int           // 0 = disabled (default)
 	retryBackoff time.Duration // initial backoff, doubles each retry
+
+	router      func(container string) []string // per-container target channel IDs; nil/empty = all
+	externalURL string                          // base URL for links back to the web UI; "" = no links
 }
 
 // NewMulti creates a dispatcher from the given notifiers.
@@ -136,6 +170,15 @@ func (m *Multi) SetRetry(maxRetries int, backoff time.Duration) {
 	m.retryMu.Unlock()
 }
 
+// SetRouter installs a per-container route lookup. When it returns channel
+// or group IDs for an event's container, only those targets (and notifiers
+// that belong to no channel, such as the log) receive the event.
+func (m *Multi) SetRouter(route func(container string) []string) {
+	m.mu.Lock()
+	m.router = route
+	m.mu.Unlock()
+}
+
 // Stop flushes any remaining pending events and stops the flush timer.
 // Call this on shutdown to ensure no buffered events are lost.
 func (m *Multi) Stop() {
@@ -162,11 +205,20 @@ func (m *Multi) Stop() {
 func (m *Multi) dispatch(ctx context.Context, event Event) bool {
 	m.mu.RLock()
 	notifiers := m.notifiers
+	router := m.router
+	base := m.externalURL
 	m.mu.RUnlock()
 
+	if event.URL == "" {
+		event.URL = DeepLink(base, event)
+	}
+
 	if len(notifiers) == 0 {
 		return true
 	}
+	if router != nil && event.ContainerName != "" {
+		notifiers = routeNotifiers(notifiers, router(event.ContainerName))
+	}
 
 	anyOK := false
 	for _, n := range notifiers {
@@ -177,6 +229,30 @@ func (m *Multi) dispatch(ctx context.Context, event Event) bool {
 	return anyOK
 }
 
+// routeNotifiers narrows the chain to the target channel IDs. Notifiers not
+// tied to a channel always stay. No targets means no narrowing.
+func routeNotifiers(notifiers []Notifier, targets []string) []Notifier {
+	if len(targets) == 0 {
+		return notifiers
+	}
+	set := make(map[string]bool, len(targets))
+	for _, id := range targets {
+		set[id] = true
+	}
+	routed := make([]Notifier, 0, len(notifiers))
+	for _, n := range notifiers {
+		r, ok := n.(routable)
+		if !ok {
+			routed = append(routed, n)
+			continue
+		}
+		if picked := r.Route(set); picked != nil {
+			routed = append(routed, picked)
+		}
+	}
+	return routed
+}
+
 // retrySend attempts to send an event via a single notifier, retrying with
 // exponential backoff on failure. Returns true if the send eventually
 // succeeded, false if all attempts were exhausted or the context was cancelled.
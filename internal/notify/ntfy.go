@@ -65,6 +65,9 @@ func (n *Ntfy) Send(ctx context.Context, event Event) error {
 	req.Header.Set("X-Title", formatTitle(event.Type))
 	req.Header.Set("X-Priority", strconv.Itoa(n.priority))
 	req.Header.Set("X-Markdown", "true")
+	if event.URL != "" {
+		req.Header.Set("X-Click", event.URL)
+	}
 
 	resp, err := n.client.Do(req)
 	if err != nil {
@@ -22,6 +22,8 @@ const (
 	ProviderSMTP     ProviderType = "smtp"
 	ProviderApprise  ProviderType = "apprise"
 	ProviderMQTT     ProviderType = "mqtt"
+	ProviderMatrix   ProviderType = "matrix"
+	ProviderGroup    ProviderType = "group" // several channels as one target; see GroupSettings
 )
 
 // Channel represents a single notification channel with typed settings.
@@ -98,7 +100,9 @@ func BuildNotifier(ch Channel) (Notifier, error) {
 		if err := json.Unmarshal(ch.Settings, &s); err != nil {
 			return nil, fmt.Errorf("unmarshal telegram settings: %w", err)
 		}
-		return NewTelegram(s.BotToken, s.ChatID), nil
+		t := NewTelegram(s.BotToken, s.ChatID)
+		t.actions = s.Actions == "true"
+		return t, nil
 
 	case ProviderPushover:
 		var s PushoverSettings
@@ -119,7 +123,7 @@ func BuildNotifier(ch Channel) (Notifier, error) {
 		if err := json.Unmarshal(ch.Settings, &s); err != nil {
 			return nil, fmt.Errorf("unmarshal apprise settings: %w", err)
 		}
-		return NewApprise(s.URL, s.Tag, s.Urls), nil
+		return NewApprise(s.URL, s.Tag, s.Urls, s.Tags), nil
 
 	case ProviderMQTT:
 		var s MQTTSettings
@@ -128,6 +132,16 @@ func BuildNotifier(ch Channel) (Notifier, error) {
 		}
 		return NewMQTT(s.Broker, s.Topic, s.ClientID, s.Username, s.Password, s.QoS), nil
 
+	case ProviderMatrix:
+		var s MatrixSettings
+		if err := json.Unmarshal(ch.Settings, &s); err != nil {
+			return nil, fmt.Errorf("unmarshal matrix settings: %w", err)
+		}
+		return NewMatrix(s.Homeserver, s.AccessToken, s.RoomID), nil
+
+	case ProviderGroup:
+		return nil, fmt.Errorf("group channels need the full channel list; use BuildChannel")
+
 	default:
 		return nil, fmt.Errorf("unknown provider type: %q", ch.Type)
 	}
@@ -158,6 +172,8 @@ func MaskSecrets(ch Channel) Channel {
 		masked.Settings = maskStringField(ch.Settings, "urls")
 	case ProviderMQTT:
 		masked.Settings = maskStringField(ch.Settings, "password")
+	case ProviderMatrix:
+		masked.Settings = maskStringField(ch.Settings, "access_token")
 	}
 	return masked
 }
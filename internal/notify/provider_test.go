@@ -167,6 +167,19 @@ func TestMaskSecrets(t *testing.T) {
 			checkField: "password",
 			wantValue:  "mqtt****",
 		},
+		{
+			name: "matrix masks access_token",
+			channel: Channel{
+				Type: ProviderMatrix,
+				Settings: mustJSON(MatrixSettings{
+					Homeserver:  "https://matrix.example.org",
+					AccessToken: "syt_secret_token",
+					RoomID:      "!room:example.org",
+				}),
+			},
+			checkField: "access_token",
+			wantValue:  "syt_****",
+		},
 	}
 
 	for _, tt := range tests {
@@ -398,6 +411,16 @@ func TestBuildNotifier(t *testing.T) {
 			},
 			wantName: "mqtt",
 		},
+		{
+			name: "matrix",
+			channel: Channel{
+				Type: ProviderMatrix,
+				Settings: mustJSON(MatrixSettings{
+					Homeserver: "https://matrix.example.org", AccessToken: "tok", RoomID: "!room:example.org",
+				}),
+			},
+			wantName: "matrix",
+		},
 		{
 			name: "unknown provider",
 			channel: Channel{
@@ -183,6 +183,107 @@ func TestDiscordSendMinimalEvent(t *testing.T) {
 	}
 }
 
+func TestDiscordSendReleaseLink(t *testing.T) {
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	event := sampleSendEvent()
+	event.ReleaseURL = "https://github.com/nginx/nginx/releases/tag/release-1.26.0"
+	event.ReleaseHint = "possibly end-of-life: source repository is archived"
+	event.Error = strings.Repeat("e", 2000)
+	if err := NewDiscord(srv.URL).Send(context.Background(), event); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	var payload discordPayload
+	if err := json.Unmarshal(gotBody, &payload); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	embed := payload.Embeds[0]
+	if embed.URL != event.ReleaseURL {
+		t.Errorf("embed url = %q, want %q", embed.URL, event.ReleaseURL)
+	}
+	var changelog, hint bool
+	for _, f := range embed.Fields {
+		if f.Name == "Changelog" && strings.Contains(f.Value, event.ReleaseURL) {
+			changelog = true
+		}
+		if f.Name == "Release" && f.Value == event.ReleaseHint {
+			hint = true
+		}
+		if n := len([]rune(f.Value)); n > 1024 {
+			t.Errorf("field %q is %d runes, over Discord's 1024 limit", f.Name, n)
+		}
+	}
+	if !changelog {
+		t.Error("missing Changelog field with the release URL")
+	}
+	if !hint {
+		t.Error("missing Release field with the release hint")
+	}
+}
+
+func TestDiscordSendRetriesRateLimit(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "1.5")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset-After", "2")
+	}))
+	defer srv.Close()
+
+	var waits []time.Duration
+	d := NewDiscord(srv.URL)
+	d.sleep = func(_ context.Context, wait time.Duration) error {
+		waits = append(waits, wait)
+		return nil
+	}
+
+	if err := d.Send(context.Background(), sampleSendEvent()); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if calls != 2 || len(waits) != 1 || waits[0] != 1500*time.Millisecond {
+		t.Fatalf("calls = %d, waits = %v; want 2 calls after one 1.5s wait", calls, waits)
+	}
+
+	// The exhausted bucket delays the next send until it resets.
+	if err := d.Send(context.Background(), sampleSendEvent()); err != nil {
+		t.Fatalf("second Send() error = %v", err)
+	}
+	if len(waits) != 2 || waits[1] <= time.Second || waits[1] > 2*time.Second {
+		t.Errorf("waits = %v, want a bucket wait of up to 2s", waits)
+	}
+}
+
+func TestDiscordSendGivesUpAfterRetries(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"retry_after":0.2,"global":false}`))
+	}))
+	defer srv.Close()
+
+	d := NewDiscord(srv.URL)
+	d.sleep = func(context.Context, time.Duration) error { return nil }
+	err := d.Send(context.Background(), sampleSendEvent())
+	if err == nil || !strings.Contains(err.Error(), "discord returned") {
+		t.Fatalf("error = %v, want discord returned 429", err)
+	}
+	if calls != discordMaxAttempts {
+		t.Errorf("calls = %d, want %d", calls, discordMaxAttempts)
+	}
+}
+
 // --- Ntfy Send() tests ---
 
 func TestNtfySendWithToken(t *testing.T) {
@@ -443,6 +544,66 @@ func TestPushoverSendErrorStatus(t *testing.T) {
 	}
 }
 
+// --- Matrix Send() tests ---
+
+func TestMatrixSendSuccess(t *testing.T) {
+	var gotBody []byte
+	var gotMethod, gotPath, gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+		gotPath = r.URL.EscapedPath()
+		gotAuth = r.Header.Get("Authorization")
+		gotBody, _ = io.ReadAll(r.Body)
+		_, _ = w.Write([]byte(`{"event_id":"$abc"}`))
+	}))
+	defer srv.Close()
+
+	m := NewMatrix(srv.URL+"/", "syt_token", "!room:example.org")
+	if m.Name() != "matrix" {
+		t.Errorf("Name() = %q, want 'matrix'", m.Name())
+	}
+
+	event := sampleSendEvent()
+	event.Error = "<b>oops</b>"
+	if err := m.Send(context.Background(), event); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if gotMethod != http.MethodPut {
+		t.Errorf("method = %s, want PUT", gotMethod)
+	}
+	if !strings.HasPrefix(gotPath, "/_matrix/client/v3/rooms/%21room:example.org/send/m.room.message/sentinel-") {
+		t.Errorf("path = %q, want the room send endpoint with a transaction ID", gotPath)
+	}
+	if gotAuth != "Bearer syt_token" {
+		t.Errorf("Authorization = %q, want 'Bearer syt_token'", gotAuth)
+	}
+
+	var msg matrixMessage
+	if err := json.Unmarshal(gotBody, &msg); err != nil {
+		t.Fatalf("unmarshal matrix body: %v", err)
+	}
+	if msg.MsgType != "m.notice" || !strings.Contains(msg.Body, "nginx") {
+		t.Errorf("msgtype = %q, body = %q; want an m.notice naming the container", msg.MsgType, msg.Body)
+	}
+	if strings.Contains(msg.FormattedBody, "<b>oops</b>") || !strings.Contains(msg.FormattedBody, "&lt;b&gt;oops") {
+		t.Errorf("formatted_body should escape event text, got %q", msg.FormattedBody)
+	}
+}
+
+func TestMatrixSendErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"errcode":"M_FORBIDDEN","error":"not in room"}`))
+	}))
+	defer srv.Close()
+
+	err := NewMatrix(srv.URL, "tok", "!room:example.org").Send(context.Background(), sampleSendEvent())
+	if err == nil || !strings.Contains(err.Error(), "M_FORBIDDEN") {
+		t.Fatalf("error = %v, want the Matrix errcode", err)
+	}
+}
+
 // --- Apprise Send() tests ---
 
 func TestAppriseSendWithTag(t *testing.T) {
@@ -457,7 +618,7 @@ func TestAppriseSendWithTag(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	a := NewApprise(srv.URL, "alerts", "")
+	a := NewApprise(srv.URL, "alerts", "", "")
 	if a.Name() != "apprise" {
 		t.Errorf("Name() = %q, want 'apprise'", a.Name())
 	}
@@ -504,7 +665,7 @@ func TestAppriseSendWithUrls(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	a := NewApprise(srv.URL, "", "slack://xoxb-token/channel")
+	a := NewApprise(srv.URL, "", "slack://xoxb-token/channel", "")
 	err := a.Send(context.Background(), sampleSendEvent())
 	if err != nil {
 		t.Fatalf("Send() error = %v", err)
@@ -547,7 +708,7 @@ func TestAppriseSendMsgType(t *testing.T) {
 			}))
 			defer srv.Close()
 
-			a := NewApprise(srv.URL, "test", "")
+			a := NewApprise(srv.URL, "test", "", "")
 			err := a.Send(context.Background(), Event{
 				Type:          tt.eventType,
 				ContainerName: "test",
@@ -574,7 +735,7 @@ func TestAppriseSendErrorStatus(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	a := NewApprise(srv.URL, "test", "")
+	a := NewApprise(srv.URL, "test", "", "")
 	err := a.Send(context.Background(), sampleSendEvent())
 	if err == nil {
 		t.Fatal("expected error for 503 response")
@@ -584,6 +745,33 @@ func TestAppriseSendErrorStatus(t *testing.T) {
 	}
 }
 
+func TestAppriseSendTagsAndErrorDetail(t *testing.T) {
+	var payload map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusFailedDependency)
+		_, _ = w.Write([]byte(`{"error":"One or more notification could not be sent."}`))
+	}))
+	defer srv.Close()
+
+	a := NewApprise(srv.URL, "home", "", "sentinel,ops")
+	err := a.Send(context.Background(), sampleSendEvent())
+	if err == nil || !strings.Contains(err.Error(), "could not be sent") {
+		t.Errorf("error = %v, want the Apprise error detail", err)
+	}
+	if payload["tag"] != "sentinel,ops" {
+		t.Errorf("tag = %q, want sentinel,ops", payload["tag"])
+	}
+}
+
+func TestAppriseSendRequiresTarget(t *testing.T) {
+	a := NewApprise("http://apprise:8000", "", "", "")
+	if err := a.Send(context.Background(), sampleSendEvent()); err == nil {
+		t.Error("expected error without a config key or URLs")
+	}
+}
+
 func TestAppriseSendTrailingSlash(t *testing.T) {
 	var gotPath string
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -593,7 +781,7 @@ func TestAppriseSendTrailingSlash(t *testing.T) {
 	defer srv.Close()
 
 	// Ensure trailing slash on URL is stripped (no double slash).
-	a := NewApprise(srv.URL+"/", "tag", "")
+	a := NewApprise(srv.URL+"/", "tag", "", "")
 	err := a.Send(context.Background(), sampleSendEvent())
 	if err != nil {
 		t.Fatalf("Send() error = %v", err)
@@ -44,6 +44,10 @@ func (p *Pushover) Send(ctx context.Context, event Event) error {
 		"title":   {formatTitle(event.Type)},
 		"message": {formatMessage(event)},
 	}
+	if event.URL != "" {
+		form.Set("url", event.URL)
+		form.Set("url_title", "Open in Sentinel")
+	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint,
 		strings.NewReader(form.Encode()))
@@ -13,12 +13,14 @@ import (
 type TelegramSettings struct {
 	BotToken string `json:"bot_token"`
 	ChatID   string `json:"chat_id"`
+	Actions  string `json:"actions,omitempty"` // "true" adds approve/reject buttons to update notifications
 }
 
 // Telegram sends notifications via the Telegram Bot API.
 type Telegram struct {
 	botToken string
 	chatID   string
+	actions  bool
 	client   *http.Client
 }
 
@@ -39,11 +41,15 @@ func (t *Telegram) Send(ctx context.Context, event Event) error {
 	text := formatTitle(event.Type) + "\n" + formatMessageMarkdown(event)
 	endpoint := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", t.botToken)
 
-	body, err := json.Marshal(telegramPayload{
+	payload := telegramPayload{
 		ChatID:    t.chatID,
 		Text:      text,
 		ParseMode: "Markdown",
-	})
+	}
+	if t.actions && event.Type == EventUpdateAvailable {
+		payload.ReplyMarkup = approvalKeyboard(event.ContainerName)
+	}
+	body, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("marshal telegram payload: %w", err)
 	}
@@ -67,7 +73,30 @@ func (t *Telegram) Send(ctx context.Context, event Event) error {
 }
 
 type telegramPayload struct {
-	ChatID    string `json:"chat_id"`
-	Text      string `json:"text"`
-	ParseMode string `json:"parse_mode,omitempty"`
+	ChatID      string            `json:"chat_id"`
+	Text        string            `json:"text"`
+	ParseMode   string            `json:"parse_mode,omitempty"`
+	ReplyMarkup *telegramKeyboard `json:"reply_markup,omitempty"`
+}
+
+type telegramKeyboard struct {
+	InlineKeyboard [][]telegramButton `json:"inline_keyboard"`
+}
+
+type telegramButton struct {
+	Text         string `json:"text"`
+	CallbackData string `json:"callback_data"`
+}
+
+// approvalKeyboard returns approve/reject buttons for a pending update.
+// Telegram caps callback data at 64 bytes, so very long container names get
+// no buttons.
+func approvalKeyboard(name string) *telegramKeyboard {
+	if len(callbackReject)+len(name) > 64 {
+		return nil
+	}
+	return &telegramKeyboard{InlineKeyboard: [][]telegramButton{{
+		{Text: "✅ Approve", CallbackData: callbackApprove + name},
+		{Text: "❌ Reject", CallbackData: callbackReject + name},
+	}}}
 }
@@ -0,0 +1,252 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Callback data prefixes carried by the approval buttons.
+const (
+	callbackApprove = "approve:"
+	callbackReject  = "reject:"
+	callbackDone    = "done"
+)
+
+// telegramPollTimeout is how long each getUpdates call waits for a button
+// press before returning empty.
+const telegramPollTimeout = 30 * time.Second
+
+// ApprovalHandler acts on approve/reject buttons pressed in a chat. by names
+// who pressed the button, for the audit log.
+type ApprovalHandler interface {
+	ApproveUpdate(ctx context.Context, name, by string) error
+	RejectUpdate(ctx context.Context, name, by string) error
+}
+
+// TelegramReceiver long-polls the Bot API of every Telegram channel that has
+// approval buttons enabled and hands button presses to an ApprovalHandler.
+// Long polling needs no public URL, so it works behind NAT. Only presses in
+// a configured chat are honoured; anyone who can post in that chat can
+// approve updates.
+type TelegramReceiver struct {
+	parent  context.Context
+	handler ApprovalHandler
+	log     Logger
+	client  *http.Client
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+// NewTelegramReceiver creates a receiver. Pollers run until ctx is cancelled
+// or the next Reload.
+func NewTelegramReceiver(ctx context.Context, handler ApprovalHandler, log Logger) *TelegramReceiver {
+	return &TelegramReceiver{
+		parent:  ctx,
+		handler: handler,
+		log:     log,
+		client:  &http.Client{Timeout: telegramPollTimeout + 10*time.Second},
+	}
+}
+
+// Reload stops the running pollers and starts one per bot token among the
+// enabled Telegram channels with actions turned on. Channels sharing a bot
+// share a poller, since Telegram allows only one getUpdates consumer.
+func (r *TelegramReceiver) Reload(channels []Channel) {
+	bots := make(map[string]map[string]bool) // bot token -> allowed chat IDs
+	for _, ch := range channels {
+		if ch.Type != ProviderTelegram || !ch.Enabled {
+			continue
+		}
+		var s TelegramSettings
+		if err := json.Unmarshal(ch.Settings, &s); err != nil || s.Actions != "true" || s.BotToken == "" {
+			continue
+		}
+		if bots[s.BotToken] == nil {
+			bots[s.BotToken] = make(map[string]bool)
+		}
+		bots[s.BotToken][s.ChatID] = true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cancel != nil {
+		r.cancel()
+		r.cancel = nil
+	}
+	if len(bots) == 0 {
+		return
+	}
+	ctx, cancel := context.WithCancel(r.parent)
+	r.cancel = cancel
+	for token, chats := range bots {
+		go r.poll(ctx, token, chats)
+	}
+	r.log.Info("telegram approval receiver started", "bots", len(bots))
+}
+
+// Stop stops all pollers.
+func (r *TelegramReceiver) Stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cancel != nil {
+		r.cancel()
+		r.cancel = nil
+	}
+}
+
+type telegramUpdate struct {
+	UpdateID      int64 `json:"update_id"`
+	CallbackQuery *struct {
+		ID   string `json:"id"`
+		Data string `json:"data"`
+		From struct {
+			Username  string `json:"username"`
+			FirstName string `json:"first_name"`
+		} `json:"from"`
+		Message *struct {
+			MessageID int64 `json:"message_id"`
+			Chat      struct {
+				ID int64 `json:"id"`
+			} `json:"chat"`
+		} `json:"message"`
+	} `json:"callback_query"`
+}
+
+// poll runs getUpdates in a loop until ctx is cancelled, backing off after
+// errors.
+func (r *TelegramReceiver) poll(ctx context.Context, token string, chats map[string]bool) {
+	var offset int64
+	for ctx.Err() == nil {
+		updates, err := r.getUpdates(ctx, token, offset)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			r.log.Error("telegram getUpdates failed", "error", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(5 * time.Second):
+			}
+			continue
+		}
+		for _, u := range updates {
+			offset = u.UpdateID + 1
+			r.handleUpdate(ctx, token, chats, u)
+		}
+	}
+}
+
+func (r *TelegramReceiver) getUpdates(ctx context.Context, token string, offset int64) ([]telegramUpdate, error) {
+	q := url.Values{}
+	q.Set("timeout", strconv.Itoa(int(telegramPollTimeout.Seconds())))
+	q.Set("offset", strconv.FormatInt(offset, 10))
+	q.Set("allowed_updates", `["callback_query"]`)
+	endpoint := fmt.Sprintf("https://api.telegram.org/bot%s/getUpdates?%s", token, q.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("telegram returned %s", resp.Status)
+	}
+	var body struct {
+		OK     bool             `json:"ok"`
+		Result []telegramUpdate `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decode telegram updates: %w", err)
+	}
+	return body.Result, nil
+}
+
+// handleUpdate applies one button press and replaces the buttons with the
+// outcome so the chat shows who acted.
+func (r *TelegramReceiver) handleUpdate(ctx context.Context, token string, chats map[string]bool, u telegramUpdate) {
+	cq := u.CallbackQuery
+	if cq == nil || cq.Message == nil {
+		return
+	}
+	chatID := strconv.FormatInt(cq.Message.Chat.ID, 10)
+	if !chats[chatID] {
+		r.answer(ctx, token, cq.ID, "This chat is not allowed to act on updates")
+		return
+	}
+
+	by := cq.From.Username
+	if by == "" {
+		by = cq.From.FirstName
+	}
+	by = "telegram:" + by
+
+	var name, label string
+	var err error
+	switch {
+	case strings.HasPrefix(cq.Data, callbackApprove):
+		name = strings.TrimPrefix(cq.Data, callbackApprove)
+		err = r.handler.ApproveUpdate(ctx, name, by)
+		label = "✅ Approved"
+	case strings.HasPrefix(cq.Data, callbackReject):
+		name = strings.TrimPrefix(cq.Data, callbackReject)
+		err = r.handler.RejectUpdate(ctx, name, by)
+		label = "❌ Rejected"
+	default:
+		r.answer(ctx, token, cq.ID, "")
+		return
+	}
+	if err != nil {
+		r.log.Error("telegram action failed", "name", name, "action", cq.Data, "error", err)
+		r.answer(ctx, token, cq.ID, err.Error())
+		return
+	}
+	r.log.Info("telegram action applied", "name", name, "action", label, "by", by)
+	r.answer(ctx, token, cq.ID, label+" "+name)
+	r.call(ctx, token, "editMessageReplyMarkup", map[string]any{
+		"chat_id":    cq.Message.Chat.ID,
+		"message_id": cq.Message.MessageID,
+		"reply_markup": telegramKeyboard{InlineKeyboard: [][]telegramButton{{
+			{Text: label + " by " + strings.TrimPrefix(by, "telegram:"), CallbackData: callbackDone},
+		}}},
+	})
+}
+
+// answer acknowledges a button press, showing text as a toast in the client.
+func (r *TelegramReceiver) answer(ctx context.Context, token, id, text string) {
+	r.call(ctx, token, "answerCallbackQuery", map[string]any{"callback_query_id": id, "text": text})
+}
+
+// call posts a Bot API method. Failures are logged only: the action itself
+// has already been applied.
+func (r *TelegramReceiver) call(ctx context.Context, token, method string, payload any) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+	endpoint := fmt.Sprintf("https://api.telegram.org/bot%s/%s", token, method)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := r.client.Do(req)
+	if err != nil {
+		r.log.Error("telegram "+method+" failed", "error", err)
+		return
+	}
+	resp.Body.Close()
+}
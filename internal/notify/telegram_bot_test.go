@@ -0,0 +1,144 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type recordingApprovals struct {
+	approved []string
+	rejected []string
+}
+
+func (h *recordingApprovals) ApproveUpdate(_ context.Context, name, by string) error {
+	h.approved = append(h.approved, name+"|"+by)
+	return nil
+}
+
+func (h *recordingApprovals) RejectUpdate(_ context.Context, name, by string) error {
+	h.rejected = append(h.rejected, name+"|"+by)
+	return nil
+}
+
+func TestTelegramSendApprovalButtons(t *testing.T) {
+	var bodies [][]byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, b)
+	}))
+	defer srv.Close()
+
+	tg := NewTelegram("tok", "999")
+	tg.actions = true
+	tg.client = srv.Client()
+	tg.client.Transport = rewriteTransport{base: srv.URL}
+
+	ev := sampleSendEvent()
+	ev.Type = EventUpdateAvailable
+	if err := tg.Send(context.Background(), ev); err != nil {
+		t.Fatal(err)
+	}
+	if err := tg.Send(context.Background(), sampleSendEvent()); err != nil {
+		t.Fatal(err)
+	}
+
+	var avail, done telegramPayload
+	_ = json.Unmarshal(bodies[0], &avail)
+	_ = json.Unmarshal(bodies[1], &done)
+	if avail.ReplyMarkup == nil || len(avail.ReplyMarkup.InlineKeyboard[0]) != 2 {
+		t.Fatalf("update_available should carry two buttons, got %+v", avail.ReplyMarkup)
+	}
+	if got := avail.ReplyMarkup.InlineKeyboard[0][0].CallbackData; got != "approve:nginx" {
+		t.Errorf("approve callback = %q, want approve:nginx", got)
+	}
+	if done.ReplyMarkup != nil {
+		t.Error("update_succeeded should not carry buttons")
+	}
+
+	if approvalKeyboard(strings.Repeat("x", 60)) != nil {
+		t.Error("names over the callback data limit should get no buttons")
+	}
+}
+
+func TestTelegramReceiverHandleUpdate(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls = append(calls, r.URL.Path)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	h := &recordingApprovals{}
+	rcv := NewTelegramReceiver(context.Background(), h, &spyLogger{})
+	rcv.client = srv.Client()
+	rcv.client.Transport = rewriteTransport{base: srv.URL}
+
+	press := func(chatID int64, data string) telegramUpdate {
+		var u telegramUpdate
+		raw := `{"update_id":1,"callback_query":{"id":"q","data":"` + data +
+			`","from":{"username":"alice"},"message":{"message_id":7,"chat":{"id":` +
+			strconv.FormatInt(chatID, 10) + `}}}}`
+		if err := json.Unmarshal([]byte(raw), &u); err != nil {
+			t.Fatal(err)
+		}
+		return u
+	}
+	chats := map[string]bool{"999": true}
+
+	rcv.handleUpdate(context.Background(), "tok", chats, press(999, "approve:nginx"))
+	rcv.handleUpdate(context.Background(), "tok", chats, press(999, "reject:redis"))
+	rcv.handleUpdate(context.Background(), "tok", chats, press(123, "approve:postgres"))
+
+	if len(h.approved) != 1 || h.approved[0] != "nginx|telegram:alice" {
+		t.Errorf("approved = %v, want [nginx|telegram:alice]", h.approved)
+	}
+	if len(h.rejected) != 1 || h.rejected[0] != "redis|telegram:alice" {
+		t.Errorf("rejected = %v, want [redis|telegram:alice]", h.rejected)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	edits := 0
+	for _, c := range calls {
+		if strings.HasSuffix(c, "/editMessageReplyMarkup") {
+			edits++
+		}
+	}
+	if edits != 2 {
+		t.Errorf("buttons replaced %d times, want 2 (the foreign chat is refused)", edits)
+	}
+}
+
+func TestTelegramReceiverReloadSelectsBots(t *testing.T) {
+	on := Channel{ID: "a", Type: ProviderTelegram, Enabled: true,
+		Settings: mustJSON(TelegramSettings{BotToken: "tok", ChatID: "1", Actions: "true"})}
+	off := Channel{ID: "b", Type: ProviderTelegram, Enabled: true,
+		Settings: mustJSON(TelegramSettings{BotToken: "tok2", ChatID: "2"})}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rcv := NewTelegramReceiver(ctx, &recordingApprovals{}, &spyLogger{})
+	rcv.client.Transport = rewriteTransport{base: "http://127.0.0.1:1"}
+
+	rcv.Reload([]Channel{off})
+	if rcv.cancel != nil {
+		t.Error("no pollers should start without actions enabled")
+	}
+	rcv.Reload([]Channel{on, off})
+	if rcv.cancel == nil {
+		t.Error("a poller should start for the channel with actions")
+	}
+	rcv.Stop()
+	if rcv.cancel != nil {
+		t.Error("Stop should clear the pollers")
+	}
+}
@@ -14,7 +14,13 @@ type TemplateData struct {
 	NewImage      string
 	OldDigest     string
 	NewDigest     string
+	OldVersion    string
+	NewVersion    string
 	Error         string
+	Message       string
+	ReleaseURL    string
+	ReleaseHint   string
+	URL           string // link to the event's page in the web UI; empty without an external URL
 	Type          string // event type name
 	Timestamp     time.Time
 	Title         string
@@ -22,6 +28,89 @@ type TemplateData struct {
 	Severity      string
 }
 
+// channelTemplateSep separates the channel ID from the event type in the key
+// of a channel's own template. Global template keys are bare event types.
+const channelTemplateSep = ":"
+
+// ChannelTemplateKey returns the template store key for a channel's own
+// template for one event type.
+func ChannelTemplateKey(channelID, eventType string) string {
+	return channelID + channelTemplateSep + eventType
+}
+
+// Templates holds the custom templates from the template store, keyed by
+// event type (global) or by ChannelTemplateKey (one channel only).
+type Templates map[string]string
+
+// Global returns the templates that apply to every channel, by event type.
+func (t Templates) Global() map[string]string {
+	out := make(map[string]string)
+	for k, v := range t {
+		if !strings.Contains(k, channelTemplateSep) {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// Channel returns one channel's own templates, by event type.
+func (t Templates) Channel(channelID string) map[string]string {
+	out := make(map[string]string)
+	prefix := channelID + channelTemplateSep
+	for k, v := range t {
+		if eventType, ok := strings.CutPrefix(k, prefix); ok {
+			out[eventType] = v
+		}
+	}
+	return out
+}
+
+// ApplyTemplates attaches the custom templates to notifiers built by
+// BuildChannel, so each renders the event body from its channel's template,
+// falling back to the global one for the event type.
+func ApplyTemplates(notifiers []Notifier, t Templates) {
+	for _, n := range notifiers {
+		switch n := n.(type) {
+		case *channelNotifier:
+			n.templates = t
+		case *groupNotifier:
+			n.templates = t
+			ApplyTemplates(n.members, t)
+		}
+	}
+}
+
+// renderBody renders an event with a template. A template that fails to
+// render leaves the body empty, so the provider's default format is used.
+func renderBody(tmplStr string, event Event) string {
+	body, err := executeTemplate(tmplStr, templateData(event))
+	if err != nil {
+		return ""
+	}
+	return body
+}
+
+// templateData converts an event into the variables available to templates.
+func templateData(e Event) TemplateData {
+	return TemplateData{
+		ContainerName: e.ContainerName,
+		OldImage:      e.OldImage,
+		NewImage:      e.NewImage,
+		OldDigest:     e.OldDigest,
+		NewDigest:     e.NewDigest,
+		OldVersion:    e.OldVersion,
+		NewVersion:    e.NewVersion,
+		Error:         e.Error,
+		Message:       e.Message,
+		ReleaseURL:    e.ReleaseURL,
+		ReleaseHint:   e.ReleaseHint,
+		URL:           e.URL,
+		Type:          string(e.Type),
+		Timestamp:     e.Timestamp,
+		Title:         formatTitle(e.Type),
+	}
+}
+
 // TemplateEngine renders notification messages using Go text/template.
 // When no custom template is set for an event type, the default format is used.
 type TemplateEngine struct {
@@ -95,11 +184,23 @@ func defaultFormat(data TemplateData) string {
 		b.WriteString(data.NewImage)
 		b.WriteString("\n")
 	}
+	if data.OldVersion != "" && data.NewVersion != "" && data.OldVersion != data.NewVersion {
+		b.WriteString("Version: ")
+		b.WriteString(data.OldVersion)
+		b.WriteString(" → ")
+		b.WriteString(data.NewVersion)
+		b.WriteString("\n")
+	}
 	if data.Error != "" {
 		b.WriteString("Error: ")
 		b.WriteString(data.Error)
 		b.WriteString("\n")
 	}
+	if data.URL != "" {
+		b.WriteString("Open in Sentinel: ")
+		b.WriteString(data.URL)
+		b.WriteString("\n")
+	}
 	return b.String()
 }
 
@@ -110,6 +211,11 @@ func sampleData(eventType string) TemplateData {
 		NewImage:      "nginx:1.25",
 		OldDigest:     "sha256:abc123...",
 		NewDigest:     "sha256:def456...",
+		OldVersion:    "1.24.0",
+		NewVersion:    "1.25.3",
+		ReleaseURL:    "https://github.com/nginx/nginx/releases/tag/release-1.25.3",
+		ReleaseHint:   "latest GitHub release is release-1.27.0",
+		URL:           "https://sentinel.example.com/container/nginx",
 		Type:          eventType,
 		Timestamp:     time.Now(),
 		Title:         "Update Available",
@@ -117,3 +223,20 @@ func sampleData(eventType string) TemplateData {
 		Severity:      "info",
 	}
 }
+
+// DefaultPreview renders the sample event in the default format, as sent
+// when no custom template applies.
+func DefaultPreview(eventType string) string {
+	data := sampleData(eventType)
+	return formatMessage(Event{
+		Type:          EventType(eventType),
+		ContainerName: data.ContainerName,
+		OldImage:      data.OldImage,
+		NewImage:      data.NewImage,
+		OldVersion:    data.OldVersion,
+		NewVersion:    data.NewVersion,
+		ReleaseURL:    data.ReleaseURL,
+		ReleaseHint:   data.ReleaseHint,
+		URL:           data.URL,
+	})
+}
@@ -1,6 +1,7 @@
 package notify
 
 import (
+	"context"
 	"strings"
 	"testing"
 	"time"
@@ -225,3 +226,66 @@ func TestDefaultFormat(t *testing.T) {
 		})
 	}
 }
+
+// --- ApplyTemplates tests ---
+
+func TestApplyTemplates(t *testing.T) {
+	a := &stubNotifier{name: "a"}
+	b := &stubNotifier{name: "b"}
+	c := &stubNotifier{name: "c"}
+	group := &groupNotifier{id: "g", name: "oncall", members: []Notifier{
+		&channelNotifier{Notifier: a, id: "a"},
+		&channelNotifier{Notifier: b, id: "b"},
+	}}
+	notifiers := []Notifier{group, &channelNotifier{Notifier: c, id: "c"}}
+	ApplyTemplates(notifiers, Templates{
+		"update_succeeded": "global {{.ContainerName}}",
+		"update_failed":    "{{.Broken",
+		ChannelTemplateKey("g", "update_succeeded"):     "group {{.ContainerName}}",
+		ChannelTemplateKey("b", "update_succeeded"):     "own {{.NewImage}}",
+		ChannelTemplateKey("other", "update_available"): "unused",
+	})
+
+	for _, n := range notifiers {
+		if err := n.Send(context.Background(), testEvent(EventUpdateSucceeded)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, tt := range []struct {
+		n    *stubNotifier
+		want string
+	}{
+		{a, "group nginx"},
+		{b, "own nginx:1.26"},
+		{c, "global nginx"},
+	} {
+		if got := tt.n.sent[0].Body; got != tt.want {
+			t.Errorf("%s body = %q, want %q", tt.n.name, got, tt.want)
+		}
+	}
+
+	// A template that fails to parse leaves the provider's default format.
+	if err := notifiers[1].Send(context.Background(), testEvent(EventUpdateFailed)); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.sent[1].Body; got != "" {
+		t.Errorf("invalid template body = %q, want empty", got)
+	}
+	if got := formatMessage(c.sent[1]); !strings.Contains(got, "Container: nginx") {
+		t.Errorf("fallback message = %q, want default format", got)
+	}
+}
+
+func TestTemplatesGlobalAndChannel(t *testing.T) {
+	tmpls := Templates{
+		"update_available":                         "g",
+		ChannelTemplateKey("ch1", "update_failed"): "c1",
+		ChannelTemplateKey("ch2", "update_failed"): "c2",
+	}
+	if got := tmpls.Global(); len(got) != 1 || got["update_available"] != "g" {
+		t.Errorf("Global() = %v", got)
+	}
+	if got := tmpls.Channel("ch1"); len(got) != 1 || got["update_failed"] != "c1" {
+		t.Errorf("Channel(ch1) = %v", got)
+	}
+}
@@ -0,0 +1,60 @@
+package registry
+
+// Change classes for a newer version relative to the running one.
+const (
+	ChangeMajor = "major"
+	ChangeMinor = "minor"
+	ChangePatch = "patch"
+	ChangeBuild = "build" // same numeric version, different pre-release or suffix
+)
+
+// VersionCandidate is one newer version an update could move to, with how
+// far it is from the current version.
+type VersionCandidate struct {
+	Version string `json:"version"`
+	Change  string `json:"change,omitempty"` // ChangeMajor, ChangeMinor, ChangePatch or ChangeBuild
+}
+
+// ClassifyChange reports whether moving from current to next is a major,
+// minor, patch or build change. Returns "" if either is not a version.
+func ClassifyChange(current, next string) string {
+	cur, okC := ParseSemVer(current)
+	nw, okN := ParseSemVer(next)
+	if !okC || !okN {
+		return ""
+	}
+	switch {
+	case nw.Major != cur.Major:
+		return ChangeMajor
+	case nw.Minor != cur.Minor:
+		return ChangeMinor
+	case nw.Patch != cur.Patch:
+		return ChangePatch
+	}
+	return ChangeBuild
+}
+
+// ClassifyVersions pairs each of versions with its change class relative to
+// current, keeping the order.
+func ClassifyVersions(current string, versions []string) []VersionCandidate {
+	if len(versions) == 0 {
+		return nil
+	}
+	out := make([]VersionCandidate, len(versions))
+	for i, v := range versions {
+		out[i] = VersionCandidate{Version: v, Change: ClassifyChange(current, v)}
+	}
+	return out
+}
+
+// setNewerVersions records newer (newest first) on result as both the plain
+// version list and the classified candidates.
+func setNewerVersions(result *CheckResult, current string, newer []SemVer) {
+	for _, sv := range newer {
+		result.NewerVersions = append(result.NewerVersions, sv.Raw)
+	}
+	result.Candidates = ClassifyVersions(current, result.NewerVersions)
+	if len(newer) > 0 {
+		result.UpdateAvailable = true
+	}
+}
@@ -0,0 +1,23 @@
+package registry
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestClassifyVersions(t *testing.T) {
+	got := ClassifyVersions("1.27.1", []string{"2.0.0", "1.28.3", "1.27.2", "1.27.1-r1", "latest"})
+	want := []VersionCandidate{
+		{Version: "2.0.0", Change: ChangeMajor},
+		{Version: "1.28.3", Change: ChangeMinor},
+		{Version: "1.27.2", Change: ChangePatch},
+		{Version: "1.27.1-r1", Change: ChangeBuild},
+		{Version: "latest"},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("ClassifyVersions = %+v, want %+v", got, want)
+	}
+	if ClassifyVersions("1.0", nil) != nil {
+		t.Error("no versions should classify to nil")
+	}
+}
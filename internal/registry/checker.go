@@ -10,15 +10,18 @@ import (
 
 // CheckResult holds the outcome of a registry digest check.
 type CheckResult struct {
-	ImageRef               string
-	LocalDigest            string
-	RemoteDigest           string
-	UpdateAvailable        bool
-	IsLocal                bool
-	Error                  error
-	NewerVersions          []string // Newer semver versions available (newest first)
-	ResolvedCurrentVersion string   // Semver tag matching local digest (for latest-tagged images)
-	ResolvedTargetVersion  string   // Semver tag matching remote digest (for latest-tagged images)
+	ImageRef        string
+	LocalDigest     string
+	RemoteDigest    string
+	UpdateAvailable bool
+	IsLocal         bool
+	Error           error
+	NewerVersions   []string // Newer semver versions available (newest first)
+	// Candidates mirrors NewerVersions with each version classified as a
+	// major, minor, patch or build change from the current tag.
+	Candidates             []VersionCandidate
+	ResolvedCurrentVersion string // Semver tag matching local digest (for latest-tagged images)
+	ResolvedTargetVersion  string // Semver tag matching remote digest (for latest-tagged images)
 	// HigherVersionsBeyondScope counts registry versions higher than current that
 	// exist beyond the effective Version Scope (i.e. would only appear under a wider
 	// scope). Lets the UI hint that the scope is hiding releases (#83).
@@ -82,9 +85,9 @@ func (c *Checker) Check(ctx context.Context, imageRef string) CheckResult {
 		return result
 	}
 
-	// Strip the tag if present to get just repo:tag for digest lookup.
-	// If the ref already contains @sha256:, it's pinned by digest — skip.
-	if strings.Contains(imageRef, "@sha256:") {
+	// If the ref contains a digest (repo@sha256:... or repo:tag@sha256:...),
+	// it's pinned by digest — skip.
+	if _, digest := SplitDigest(imageRef); digest != "" {
 		result.IsLocal = true // treat pinned-by-digest as not updatable
 		return result
 	}
@@ -161,83 +164,7 @@ func extractHash(digest string) string {
 // embedded in the service spec but the tag-only ref may not exist in the local
 // image store.
 func (c *Checker) CheckVersionedWithDigest(ctx context.Context, imageRef, knownDigest string, scope docker.SemverScope, includeRE, excludeRE string) CheckResult {
-	result := CheckResult{ImageRef: imageRef}
-
-	if docker.IsLocalImage(imageRef) {
-		result.IsLocal = true
-		return result
-	}
-
-	result.LocalDigest = knownDigest
-
-	remoteDigest, err := c.docker.DistributionDigest(ctx, imageRef)
-	if err != nil {
-		c.log.Debug("failed to get remote digest, treating as local", "image", imageRef, "error", err)
-		result.IsLocal = true
-		return result
-	}
-	result.RemoteDigest = remoteDigest
-	if !digestsMatch(knownDigest, remoteDigest) {
-		if c.equiv != nil && c.equiv.CheckDigestEquivalence(knownDigest, remoteDigest) {
-			c.log.Debug("digests differ but cached as equivalent", "image", imageRef)
-		} else {
-			result.UpdateAvailable = true
-		}
-	}
-
-	// Version lookup — same as CheckVersioned.
-	tag := ExtractTag(imageRef)
-	if tag == "" || result.IsLocal {
-		return result
-	}
-
-	_, ok := ParseSemVer(tag)
-	if !ok {
-		if result.UpdateAvailable {
-			c.resolveLatestVersions(ctx, imageRef, &result)
-		}
-		return result
-	}
-
-	repo := RepoPath(imageRef)
-	host := RegistryHost(imageRef)
-
-	var cred *RegistryCredential
-	if c.creds != nil {
-		creds, err := c.creds.GetRegistryCredentials()
-		if err == nil {
-			cred = FindByRegistry(creds, host)
-		}
-	}
-
-	token, err := FetchToken(ctx, repo, cred, host)
-	if err != nil {
-		c.log.Debug("failed to fetch token for version check", "repo", repo, "error", err)
-		return result
-	}
-
-	tagsResult, err := ListTags(ctx, imageRef, token, host, cred)
-	if err != nil {
-		c.log.Debug("failed to list tags for version check", "repo", repo, "error", err)
-		return result
-	}
-
-	if c.tracker != nil {
-		c.tracker.Record(host, tagsResult.Headers)
-		c.tracker.SetAuth(host, cred != nil)
-	}
-
-	filteredTags := FilterTags(tagsResult.Tags, includeRE, excludeRE)
-	newer, beyond := NewerVersionsScopedWithBeyond(tag, filteredTags, scope, c.defaultScope)
-	result.HigherVersionsBeyondScope = beyond
-	for _, sv := range newer {
-		result.NewerVersions = append(result.NewerVersions, sv.Raw)
-	}
-	if len(newer) > 0 {
-		result.UpdateAvailable = true
-	}
-
-	return result
+	return c.checkVersioned(ctx, imageRef, &knownDigest, scope, includeRE, excludeRE)
 }
 
 // CheckVersioned performs a digest check and, for versioned tags, also looks
@@ -249,7 +176,24 @@ func (c *Checker) CheckVersionedWithDigest(ctx context.Context, imageRef, knownD
 // (DistributionInspect uses the Docker daemon's internal client which does
 // not expose HTTP headers).
 func (c *Checker) CheckVersioned(ctx context.Context, imageRef string, scope docker.SemverScope, includeRE, excludeRE string) CheckResult {
-	result := c.Check(ctx, imageRef)
+	return c.checkVersioned(ctx, imageRef, nil, scope, includeRE, excludeRE)
+}
+
+// checkVersioned is the lookup behind CheckVersioned and
+// CheckVersionedWithDigest. A non-nil knownDigest stands in for the local
+// image digest instead of asking the Docker daemon.
+func (c *Checker) checkVersioned(ctx context.Context, imageRef string, knownDigest *string, scope docker.SemverScope, includeRE, excludeRE string) CheckResult {
+	// Images claimed by a registered custom source bypass the registry.
+	if src := sourceFor(imageRef); src != nil {
+		return c.checkSource(ctx, src, imageRef, knownDigest, scope, includeRE, excludeRE)
+	}
+
+	var result CheckResult
+	if knownDigest != nil {
+		result = c.checkKnownDigest(ctx, imageRef, *knownDigest)
+	} else {
+		result = c.Check(ctx, imageRef)
+	}
 
 	// Only attempt version lookup if the base check succeeded and the image
 	// has a semver-like tag.
@@ -301,13 +245,45 @@ func (c *Checker) CheckVersioned(ctx context.Context, imageRef string, scope doc
 	filteredTags := FilterTags(tagsResult.Tags, includeRE, excludeRE)
 	newer, beyond := NewerVersionsScopedWithBeyond(tag, filteredTags, scope, c.defaultScope)
 	result.HigherVersionsBeyondScope = beyond
-	for _, sv := range newer {
-		result.NewerVersions = append(result.NewerVersions, sv.Raw)
+	setNewerVersions(&result, tag, newer)
+
+	return result
+}
+
+// checkKnownDigest is the digest half of CheckVersionedWithDigest: it
+// compares knownDigest against the registry. Registry failures mark the
+// result local rather than failing it.
+func (c *Checker) checkKnownDigest(ctx context.Context, imageRef, knownDigest string) CheckResult {
+	result := CheckResult{ImageRef: imageRef}
+
+	if docker.IsLocalImage(imageRef) {
+		result.IsLocal = true
+		return result
 	}
-	if len(newer) > 0 {
-		result.UpdateAvailable = true
+
+	// A digest in the reference pins it, as in Check. Callers tracking the
+	// tag of a "repo:tag@sha256:..." reference pass the tag-only name.
+	if _, digest := SplitDigest(imageRef); digest != "" {
+		result.IsLocal = true
+		return result
 	}
 
+	result.LocalDigest = knownDigest
+
+	remoteDigest, err := c.docker.DistributionDigest(ctx, imageRef)
+	if err != nil {
+		c.log.Debug("failed to get remote digest, treating as local", "image", imageRef, "error", err)
+		result.IsLocal = true
+		return result
+	}
+	result.RemoteDigest = remoteDigest
+	if !digestsMatch(knownDigest, remoteDigest) {
+		if c.equiv != nil && c.equiv.CheckDigestEquivalence(knownDigest, remoteDigest) {
+			c.log.Debug("digests differ but cached as equivalent", "image", imageRef)
+		} else {
+			result.UpdateAvailable = true
+		}
+	}
 	return result
 }
 
@@ -353,16 +329,16 @@ func (c *Checker) resolveLatestVersions(ctx context.Context, imageRef string, re
 	// Populate NewerVersions with the target so the Ignore button appears.
 	if target != "" {
 		result.NewerVersions = []string{target}
+		result.Candidates = ClassifyVersions(current, result.NewerVersions)
 	}
 }
 
 // ExtractTag returns the tag portion of an image reference, or empty string
-// if there is no tag (e.g. digest-pinned or bare image name).
+// if there is no tag (e.g. a bare image name or "repo@sha256:..."). For
+// "repo:tag@sha256:..." the tag before the digest is returned.
 func ExtractTag(imageRef string) string {
 	// Remove digest portion if present.
-	if idx := strings.Index(imageRef, "@"); idx >= 0 {
-		return ""
-	}
+	imageRef, _ = SplitDigest(imageRef)
 
 	if idx := strings.LastIndex(imageRef, ":"); idx >= 0 {
 		// Ensure the colon is after any slash (not part of a registry hostname
@@ -176,6 +176,30 @@ func TestCheckPinnedDigest(t *testing.T) {
 	}
 }
 
+func TestCheckTagAndDigestPinned(t *testing.T) {
+	mock := newMockRegistry()
+	checker := NewChecker(mock, logging.New(false))
+	ctx := context.Background()
+
+	// The tag alongside the digest must not trigger a version lookup: the
+	// digest pins the image whichever check is used.
+	for _, ref := range []string{
+		"nginx:1.25@sha256:abc123",
+		"localhost:5000/app:1.0@sha256:abc123",
+		"localhost:5000/org/app:v2.1.0@sha256:abc123",
+	} {
+		if r := checker.Check(ctx, ref); !r.IsLocal {
+			t.Errorf("Check(%q): expected IsLocal=true", ref)
+		}
+		if r := checker.CheckVersioned(ctx, ref, docker.ScopeDefault, "", ""); !r.IsLocal || len(r.NewerVersions) > 0 {
+			t.Errorf("CheckVersioned(%q) = %+v, want pinned", ref, r)
+		}
+		if r := checker.CheckVersionedWithDigest(ctx, ref, "sha256:abc123", docker.ScopeDefault, "", ""); !r.IsLocal || r.UpdateAvailable {
+			t.Errorf("CheckVersionedWithDigest(%q) = %+v, want pinned", ref, r)
+		}
+	}
+}
+
 func TestCheckDistributionError(t *testing.T) {
 	mock := newMockRegistry()
 	mock.imageDigests["ghcr.io/owner/app:latest"] = "sha256:aaa111"
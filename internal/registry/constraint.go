@@ -0,0 +1,201 @@
+package registry
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Constraint is a semver range such as "~1.27", "^2", ">=2 <3" or "1.x",
+// used by the sentinel.semver label to bound which newer versions count as
+// updates. Space- or comma-separated comparators must all hold; "||"
+// separates alternative ranges.
+type Constraint struct {
+	raw    string
+	ranges [][]comparator
+}
+
+// comparator is one "op version" term. Versions are compared on their
+// numeric part only, so "<3" also excludes 3.0.0-rc1.
+type comparator struct {
+	op  string // "=", "<", "<=", ">", ">="
+	ver [3]int
+}
+
+// ParseConstraint parses a semver range. Supported forms:
+//   - comparisons: "=1.2.3", ">1.2", ">=2", "<3", "<=2.5"
+//   - tilde: "~1.27" (>=1.27.0 <1.28.0), "~1" (>=1.0.0 <2.0.0)
+//   - caret: "^1.2.3" (>=1.2.3 <2.0.0), "^0.4" (>=0.4.0 <0.5.0)
+//   - partial or wildcard versions: "1.27", "1.27.x", "2.*" (that subtree)
+func ParseConstraint(s string) (Constraint, error) {
+	c := Constraint{raw: strings.TrimSpace(s)}
+	if c.raw == "" {
+		return c, fmt.Errorf("empty constraint")
+	}
+	for _, alt := range strings.Split(c.raw, "||") {
+		fields := strings.FieldsFunc(alt, func(r rune) bool { return r == ' ' || r == ',' })
+		// Allow a space between an operator and its version (">= 2").
+		var terms []string
+		for i := 0; i < len(fields); i++ {
+			f := fields[i]
+			if strings.Trim(f, "<>=~^") == "" && i+1 < len(fields) {
+				f += fields[i+1]
+				i++
+			}
+			terms = append(terms, f)
+		}
+		if len(terms) == 0 {
+			return c, fmt.Errorf("empty range in %q", c.raw)
+		}
+		var rng []comparator
+		for _, term := range terms {
+			cmps, err := parseTerm(term)
+			if err != nil {
+				return c, err
+			}
+			rng = append(rng, cmps...)
+		}
+		c.ranges = append(c.ranges, rng)
+	}
+	return c, nil
+}
+
+// String returns the constraint as written.
+func (c Constraint) String() string { return c.raw }
+
+// Check reports whether v satisfies the constraint.
+func (c Constraint) Check(v SemVer) bool {
+	ver := [3]int{v.Major, v.Minor, v.Patch}
+	for _, rng := range c.ranges {
+		ok := true
+		for _, cmp := range rng {
+			if !cmp.match(ver) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (cmp comparator) match(v [3]int) bool {
+	d := compareParts(v, cmp.ver)
+	switch cmp.op {
+	case "<":
+		return d < 0
+	case "<=":
+		return d <= 0
+	case ">":
+		return d > 0
+	case ">=":
+		return d >= 0
+	default:
+		return d == 0
+	}
+}
+
+func compareParts(a, b [3]int) int {
+	for i := range a {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// parseTerm expands one term into the comparators it stands for.
+func parseTerm(term string) ([]comparator, error) {
+	op := ""
+	for _, p := range []string{">=", "<=", "==", ">", "<", "=", "~", "^"} {
+		if strings.HasPrefix(term, p) {
+			op = p
+			break
+		}
+	}
+	ver, n, err := parsePartial(strings.TrimPrefix(term, op))
+	if err != nil {
+		return nil, fmt.Errorf("invalid constraint %q: %w", term, err)
+	}
+	// upper is the first version past the n given parts: 1.27 → 1.28.0.
+	upper := func(parts int) [3]int {
+		var u [3]int
+		copy(u[:parts], ver[:parts])
+		u[parts-1]++
+		return u
+	}
+
+	switch op {
+	case ">", "<=":
+		if n < 3 && n > 0 {
+			// ">1.2" means past the whole 1.2 line; "<=1.2" includes all of it.
+			inv := "<"
+			if op == ">" {
+				inv = ">="
+			}
+			return []comparator{{inv, upper(n)}}, nil
+		}
+		return []comparator{{op, ver}}, nil
+	case ">=", "<":
+		return []comparator{{op, ver}}, nil
+	case "~":
+		if n == 0 {
+			return nil, nil
+		}
+		parts := n
+		if parts == 3 {
+			parts = 2
+		}
+		return []comparator{{">=", ver}, {"<", upper(parts)}}, nil
+	case "^":
+		if n == 0 {
+			return nil, nil
+		}
+		// Bump the first non-zero part given (or the last part given).
+		parts := 1
+		for parts < n && ver[parts-1] == 0 {
+			parts++
+		}
+		return []comparator{{">=", ver}, {"<", upper(parts)}}, nil
+	default: // "", "=", "=="
+		switch n {
+		case 0:
+			return nil, nil // "*" or "x": any version
+		case 3:
+			return []comparator{{"=", ver}}, nil
+		}
+		return []comparator{{">=", ver}, {"<", upper(n)}}, nil
+	}
+}
+
+// parsePartial parses "1", "1.2", "1.2.3" (with optional "v" prefix and
+// "x"/"*" wildcards) and returns the version and how many parts were fixed.
+func parsePartial(s string) ([3]int, int, error) {
+	var ver [3]int
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "v"), "V")
+	if s == "" {
+		return ver, 0, fmt.Errorf("missing version")
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return ver, 0, fmt.Errorf("too many parts in %q", s)
+	}
+	n := 0
+	for i, p := range parts {
+		if p == "x" || p == "X" || p == "*" {
+			break
+		}
+		v, err := strconv.Atoi(p)
+		if err != nil || v < 0 {
+			return ver, 0, fmt.Errorf("bad version part %q", p)
+		}
+		ver[i] = v
+		n = i + 1
+	}
+	return ver, n, nil
+}
@@ -0,0 +1,51 @@
+package registry
+
+import "testing"
+
+func TestConstraintCheck(t *testing.T) {
+	tests := []struct {
+		constraint string
+		match      []string
+		reject     []string
+	}{
+		{"~1.27", []string{"1.27.0", "1.27.9"}, []string{"1.26.5", "1.28.0", "2.0.0"}},
+		{"~1.27.2", []string{"1.27.2", "1.27.8"}, []string{"1.27.1", "1.28.0"}},
+		{"~1", []string{"1.0.0", "1.99.1"}, []string{"0.9.0", "2.0.0"}},
+		{"^1.2.3", []string{"1.2.3", "1.9.0"}, []string{"1.2.2", "2.0.0"}},
+		{"^0.4", []string{"0.4.0", "0.4.7"}, []string{"0.5.0", "1.0.0"}},
+		{">=2 <3", []string{"2.0.0", "2.9.9"}, []string{"1.9.9", "3.0.0", "3.0.0-rc1"}},
+		{">= 2, < 3", []string{"2.5.0"}, []string{"3.1.0"}},
+		{">1.2", []string{"1.3.0", "2.0.0"}, []string{"1.2.9"}},
+		{"<=1.2", []string{"1.2.9", "1.0.0"}, []string{"1.3.0"}},
+		{"1.27.x", []string{"1.27.0", "1.27.4"}, []string{"1.28.0"}},
+		{"2.*", []string{"2.0.0", "2.8.1"}, []string{"3.0.0", "1.9.0"}},
+		{"=1.2.3", []string{"1.2.3"}, []string{"1.2.4"}},
+		{"~1.27 || >=3", []string{"1.27.5", "3.2.0"}, []string{"2.0.0"}},
+	}
+	for _, tt := range tests {
+		c, err := ParseConstraint(tt.constraint)
+		if err != nil {
+			t.Fatalf("ParseConstraint(%q): %v", tt.constraint, err)
+		}
+		for _, v := range tt.match {
+			sv, _ := ParseSemVer(v)
+			if !c.Check(sv) {
+				t.Errorf("%q should match %s", tt.constraint, v)
+			}
+		}
+		for _, v := range tt.reject {
+			sv, _ := ParseSemVer(v)
+			if c.Check(sv) {
+				t.Errorf("%q should reject %s", tt.constraint, v)
+			}
+		}
+	}
+}
+
+func TestParseConstraintInvalid(t *testing.T) {
+	for _, s := range []string{"", ">=", "~1.a", "1.2.3.4", ">=2 ||"} {
+		if _, err := ParseConstraint(s); err == nil {
+			t.Errorf("ParseConstraint(%q) succeeded, want error", s)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package registry
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultDockerConfigPath returns the config.json the Docker CLI would use:
+// $DOCKER_CONFIG/config.json, or ~/.docker/config.json.
+func DefaultDockerConfigPath() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return filepath.Join(dir, "config.json")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".docker", "config.json")
+}
+
+// LoadDockerConfigCredentials reads the registry logins stored inline in a
+// Docker CLI config.json (the "auths" map written by docker login). Logins
+// held by a credential helper are not stored in the file and are skipped.
+// Registry keys are normalised, so "https://index.docker.io/v1/" becomes
+// "docker.io".
+func LoadDockerConfigCredentials(path string) ([]RegistryCredential, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg struct {
+		Auths map[string]struct {
+			Auth     string `json:"auth"`
+			Username string `json:"username"`
+			Password string `json:"password"`
+		} `json:"auths"`
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	var creds []RegistryCredential
+	for key, a := range cfg.Auths {
+		user, secret := a.Username, a.Password
+		if a.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(a.Auth)
+			if err != nil {
+				return nil, fmt.Errorf("parse %s: auth for %s: %w", path, key, err)
+			}
+			var ok bool
+			user, secret, ok = strings.Cut(string(decoded), ":")
+			if !ok {
+				return nil, fmt.Errorf("parse %s: auth for %s is not user:password", path, key)
+			}
+		}
+		if user == "" || secret == "" {
+			continue
+		}
+		host := dockerConfigHost(key)
+		creds = append(creds, RegistryCredential{ID: host, Registry: host, Username: user, Secret: secret})
+	}
+	sort.Slice(creds, func(i, j int) bool { return creds[i].Registry < creds[j].Registry })
+	return creds, nil
+}
+
+// dockerConfigHost turns an auths key ("https://index.docker.io/v1/",
+// "ghcr.io") into a normalised registry host.
+func dockerConfigHost(key string) string {
+	key = strings.TrimPrefix(strings.TrimPrefix(key, "https://"), "http://")
+	if i := strings.IndexByte(key, '/'); i >= 0 {
+		key = key[:i]
+	}
+	return NormaliseRegistryHost(key)
+}
@@ -0,0 +1,51 @@
+package registry
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadDockerConfigCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	hub := base64.StdEncoding.EncodeToString([]byte("ci-bot:dckr_pat_123"))
+	data := `{
+		"auths": {
+			"https://index.docker.io/v1/": {"auth": "` + hub + `"},
+			"ghcr.io": {"username": "octo", "password": "ghp_abc"},
+			"registry.example.com": {}
+		},
+		"credsStore": "desktop"
+	}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	creds, err := LoadDockerConfigCredentials(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(creds) != 2 {
+		t.Fatalf("creds = %+v, want docker.io and ghcr.io", creds)
+	}
+	if c := creds[0]; c.Registry != "docker.io" || c.Username != "ci-bot" || c.Secret != "dckr_pat_123" {
+		t.Errorf("creds[0] = %+v", c)
+	}
+	if c := creds[1]; c.Registry != "ghcr.io" || c.Username != "octo" || c.Secret != "ghp_abc" {
+		t.Errorf("creds[1] = %+v", c)
+	}
+}
+
+func TestLoadDockerConfigCredentialsErrors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := LoadDockerConfigCredentials(filepath.Join(dir, "missing.json")); !os.IsNotExist(err) {
+		t.Errorf("missing file: err = %v, want not-exist", err)
+	}
+
+	bad := filepath.Join(dir, "bad.json")
+	_ = os.WriteFile(bad, []byte(`{"auths": {"ghcr.io": {"auth": "not base64!"}}}`), 0o600)
+	if _, err := LoadDockerConfigCredentials(bad); err == nil {
+		t.Error("invalid auth accepted")
+	}
+}
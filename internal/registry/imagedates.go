@@ -0,0 +1,182 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// dockerHubAPIBase is the Docker Hub web API, which reports when each tag
+// was last pushed. Overridable in tests.
+var dockerHubAPIBase = "https://hub.docker.com"
+
+// ImageDates records when an image was built and when its tag was pushed.
+// Pushed is only known for Docker Hub; the registry v2 API has no push time.
+type ImageDates struct {
+	Digest  string    `json:"digest,omitempty"`
+	Created time.Time `json:"created,omitzero"` // from the image config
+	Pushed  time.Time `json:"pushed,omitzero"`  // tag last pushed, Docker Hub only
+}
+
+// imageManifest covers the fields of a manifest or index that locate the
+// image config and size its layers.
+type imageManifest struct {
+	Config struct {
+		Digest string `json:"digest"`
+	} `json:"config"`
+	Layers []struct {
+		Size int64 `json:"size"`
+	} `json:"layers"`
+	Manifests []struct {
+		Digest   string `json:"digest"`
+		Platform struct {
+			OS           string `json:"os"`
+			Architecture string `json:"architecture"`
+			Variant      string `json:"variant"`
+		} `json:"platform"`
+	} `json:"manifests"`
+}
+
+// FetchImageDates reads the build date of imageRef from its config blob and,
+// for Docker Hub images, when the tag was last pushed. A multi-arch index is
+// resolved to the linux image for this architecture. Manifest GETs count
+// towards Docker Hub pull limits, so callers should cache the result.
+func FetchImageDates(ctx context.Context, imageRef, token, host string, cred *RegistryCredential) (ImageDates, error) {
+	tag := ExtractTag(imageRef)
+	if tag == "" {
+		tag = "latest"
+	}
+	repo := RepoPath(imageRef)
+	base := "https://registry-1.docker.io"
+	if host != "" && host != "docker.io" {
+		base = "https://" + host
+	}
+
+	dates, err := fetchImageCreated(ctx, base, repo, tag, token, cred)
+	if err != nil {
+		return dates, err
+	}
+	if host == "" || host == "docker.io" {
+		if pushed, err := fetchHubPushed(ctx, repo, tag); err == nil {
+			dates.Pushed = pushed
+		}
+	}
+	return dates, nil
+}
+
+// fetchImageCreated follows tag → (index →) manifest → config and returns
+// the config's created time along with the tag's digest.
+func fetchImageCreated(ctx context.Context, base, repo, tag, token string, cred *RegistryCredential) (ImageDates, error) {
+	var dates ImageDates
+	var m imageManifest
+	headers, err := registryGet(ctx, base+"/v2/"+repo+"/manifests/"+tag, token, cred, &m)
+	if err != nil {
+		return dates, fmt.Errorf("manifest: %w", err)
+	}
+	dates.Digest = headers.Get("Docker-Content-Digest")
+
+	if len(m.Manifests) > 0 {
+		digest := pickPlatform(m)
+		if digest == "" {
+			return dates, fmt.Errorf("no linux image in index")
+		}
+		m = imageManifest{}
+		if _, err := registryGet(ctx, base+"/v2/"+repo+"/manifests/"+digest, token, cred, &m); err != nil {
+			return dates, fmt.Errorf("platform manifest: %w", err)
+		}
+	}
+	if m.Config.Digest == "" {
+		return dates, fmt.Errorf("manifest has no config")
+	}
+
+	var config struct {
+		Created time.Time `json:"created"`
+	}
+	if _, err := registryGet(ctx, base+"/v2/"+repo+"/blobs/"+m.Config.Digest, token, cred, &config); err != nil {
+		return dates, fmt.Errorf("config blob: %w", err)
+	}
+	dates.Created = config.Created
+	return dates, nil
+}
+
+// pickPlatform returns the index entry for linux on this architecture,
+// falling back to the first linux entry. Attestation manifests use the
+// "unknown" platform and are never picked.
+func pickPlatform(m imageManifest) string {
+	var fallback string
+	for _, e := range m.Manifests {
+		if e.Platform.OS != "linux" {
+			continue
+		}
+		if e.Platform.Architecture == runtime.GOARCH {
+			return e.Digest
+		}
+		if fallback == "" {
+			fallback = e.Digest
+		}
+	}
+	return fallback
+}
+
+// registryGet fetches a registry v2 URL and decodes the JSON body into out.
+func registryGet(ctx context.Context, url, token string, cred *RegistryCredential, out any) (http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", strings.Join([]string{
+		"application/vnd.oci.image.index.v1+json",
+		"application/vnd.docker.distribution.manifest.list.v2+json",
+		"application/vnd.oci.image.manifest.v1+json",
+		"application/vnd.docker.distribution.manifest.v2+json",
+	}, ", "))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else if cred != nil {
+		req.SetBasicAuth(cred.Username, cred.Secret)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return resp.Header, fmt.Errorf("returned %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return resp.Header, fmt.Errorf("decode: %w", err)
+	}
+	return resp.Header, nil
+}
+
+// fetchHubPushed asks the Docker Hub API when a tag was last pushed.
+func fetchHubPushed(ctx context.Context, repo, tag string) (time.Time, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, dockerHubAPIBase+"/v2/repositories/"+repo+"/tags/"+tag, nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return time.Time{}, fmt.Errorf("hub tag lookup returned %d", resp.StatusCode)
+	}
+	var body struct {
+		TagLastPushed time.Time `json:"tag_last_pushed"`
+		LastUpdated   time.Time `json:"last_updated"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return time.Time{}, fmt.Errorf("decode hub tag: %w", err)
+	}
+	if !body.TagLastPushed.IsZero() {
+		return body.TagLastPushed, nil
+	}
+	return body.LastUpdated, nil
+}
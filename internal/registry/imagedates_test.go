@@ -0,0 +1,81 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestFetchImageCreated_ResolvesIndex(t *testing.T) {
+	built := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/library/nginx/manifests/1.25":
+			w.Header().Set("Docker-Content-Digest", "sha256:index")
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"manifests": []map[string]any{
+					{"digest": "sha256:attest", "platform": map[string]string{"os": "unknown", "architecture": "unknown"}},
+					{"digest": "sha256:other", "platform": map[string]string{"os": "linux", "architecture": "s390x"}},
+					{"digest": "sha256:native", "platform": map[string]string{"os": "linux", "architecture": runtime.GOARCH}},
+				},
+			})
+		case "/v2/library/nginx/manifests/sha256:native":
+			_ = json.NewEncoder(w).Encode(map[string]any{"config": map[string]string{"digest": "sha256:cfg"}})
+		case "/v2/library/nginx/blobs/sha256:cfg":
+			_ = json.NewEncoder(w).Encode(map[string]any{"created": built})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	dates, err := fetchImageCreated(context.Background(), srv.URL, "library/nginx", "1.25", "", nil)
+	if err != nil {
+		t.Fatalf("fetchImageCreated: %v", err)
+	}
+	if dates.Digest != "sha256:index" {
+		t.Errorf("digest = %q, want sha256:index", dates.Digest)
+	}
+	if !dates.Created.Equal(built) {
+		t.Errorf("created = %v, want %v", dates.Created, built)
+	}
+}
+
+func TestFetchImageCreated_NoConfig(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	if _, err := fetchImageCreated(context.Background(), srv.URL, "a/b", "1", "", nil); err == nil {
+		t.Fatal("expected error for a manifest without a config")
+	}
+}
+
+func TestFetchHubPushed(t *testing.T) {
+	pushed := time.Date(2023, 9, 14, 8, 30, 0, 0, time.UTC)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/repositories/library/nginx/tags/1.25" {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"tag_last_pushed": pushed, "last_updated": time.Now()})
+	}))
+	defer srv.Close()
+
+	orig := dockerHubAPIBase
+	dockerHubAPIBase = srv.URL
+	t.Cleanup(func() { dockerHubAPIBase = orig })
+
+	got, err := fetchHubPushed(context.Background(), "library/nginx", "1.25")
+	if err != nil {
+		t.Fatalf("fetchHubPushed: %v", err)
+	}
+	if !got.Equal(pushed) {
+		t.Errorf("pushed = %v, want %v", got, pushed)
+	}
+}
@@ -0,0 +1,48 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+)
+
+// FetchImageSize returns the compressed size in bytes of imageRef, the sum
+// of the layer sizes in its manifest. A multi-arch index is resolved to the
+// linux image for this architecture. The unpacked image takes more room on
+// disk, typically two to three times this.
+func FetchImageSize(ctx context.Context, imageRef, token, host string, cred *RegistryCredential) (int64, error) {
+	tag := ExtractTag(imageRef)
+	if tag == "" {
+		tag = "latest"
+	}
+	base := "https://registry-1.docker.io"
+	if host != "" && host != "docker.io" {
+		base = "https://" + host
+	}
+	return fetchImageSize(ctx, base, RepoPath(imageRef), tag, token, cred)
+}
+
+// fetchImageSize follows tag → (index →) manifest and sums the layers.
+func fetchImageSize(ctx context.Context, base, repo, tag, token string, cred *RegistryCredential) (int64, error) {
+	var m imageManifest
+	if _, err := registryGet(ctx, base+"/v2/"+repo+"/manifests/"+tag, token, cred, &m); err != nil {
+		return 0, fmt.Errorf("manifest: %w", err)
+	}
+	if len(m.Manifests) > 0 {
+		digest := pickPlatform(m)
+		if digest == "" {
+			return 0, fmt.Errorf("no linux image in index")
+		}
+		m = imageManifest{}
+		if _, err := registryGet(ctx, base+"/v2/"+repo+"/manifests/"+digest, token, cred, &m); err != nil {
+			return 0, fmt.Errorf("platform manifest: %w", err)
+		}
+	}
+	if len(m.Layers) == 0 {
+		return 0, fmt.Errorf("manifest lists no layers")
+	}
+	var size int64
+	for _, l := range m.Layers {
+		size += l.Size
+	}
+	return size, nil
+}
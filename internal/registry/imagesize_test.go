@@ -0,0 +1,51 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+)
+
+func TestFetchImageSize_ResolvesIndex(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/library/nginx/manifests/1.25":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"manifests": []map[string]any{
+					{"digest": "sha256:attest", "platform": map[string]string{"os": "unknown", "architecture": "unknown"}},
+					{"digest": "sha256:native", "platform": map[string]string{"os": "linux", "architecture": runtime.GOARCH}},
+				},
+			})
+		case "/v2/library/nginx/manifests/sha256:native":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"config": map[string]string{"digest": "sha256:cfg"},
+				"layers": []map[string]int64{{"size": 1000}, {"size": 234}},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	size, err := fetchImageSize(context.Background(), srv.URL, "library/nginx", "1.25", "", nil)
+	if err != nil {
+		t.Fatalf("fetchImageSize: %v", err)
+	}
+	if size != 1234 {
+		t.Errorf("size = %d, want 1234", size)
+	}
+}
+
+func TestFetchImageSize_NoLayers(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"config":{"digest":"sha256:cfg"}}`))
+	}))
+	defer srv.Close()
+
+	if _, err := fetchImageSize(context.Background(), srv.URL, "a/b", "1", "", nil); err == nil {
+		t.Fatal("expected error for a manifest without layers")
+	}
+}
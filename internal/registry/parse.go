@@ -37,3 +37,19 @@ func RegistryHost(imageRef string) string {
 	// Otherwise it's a Docker Hub org/image like "gitea/gitea".
 	return "docker.io"
 }
+
+// SplitDigest separates a digest from an image reference, leaving any tag on
+// the name. The digest is returned without the "@".
+//
+// Examples:
+//
+//	"nginx:1.25@sha256:abc"                -> "nginx:1.25", "sha256:abc"
+//	"registry:5000/app:v1@sha256:abc"      -> "registry:5000/app:v1", "sha256:abc"
+//	"nginx@sha256:abc"                     -> "nginx", "sha256:abc"
+//	"nginx:1.25"                           -> "nginx:1.25", ""
+func SplitDigest(imageRef string) (name, digest string) {
+	if i := strings.Index(imageRef, "@"); i >= 0 {
+		return imageRef[:i], imageRef[i+1:]
+	}
+	return imageRef, ""
+}
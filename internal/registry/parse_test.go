@@ -16,6 +16,7 @@ func TestRegistryHost(t *testing.T) {
 		{"registry-1.docker.io/library/nginx:latest", "docker.io"},
 		{"lscr.io/linuxserver/radarr:latest", "lscr.io"},
 		{"docker.io/library/nginx", "docker.io"},
+		{"localhost:5000/app:v1@sha256:abc", "localhost:5000"},
 		{"", "docker.io"},
 	}
 	for _, tt := range tests {
@@ -27,3 +28,27 @@ func TestRegistryHost(t *testing.T) {
 		})
 	}
 }
+
+func TestSplitDigest(t *testing.T) {
+	tests := []struct {
+		imageRef   string
+		wantName   string
+		wantDigest string
+	}{
+		{"nginx:1.25", "nginx:1.25", ""},
+		{"nginx@sha256:abc", "nginx", "sha256:abc"},
+		{"nginx:1.25@sha256:abc", "nginx:1.25", "sha256:abc"},
+		{"ghcr.io/owner/app:v1.2.3@sha256:abc", "ghcr.io/owner/app:v1.2.3", "sha256:abc"},
+		{"localhost:5000/app:v1@sha256:abc", "localhost:5000/app:v1", "sha256:abc"},
+		{"localhost:5000/app@sha256:abc", "localhost:5000/app", "sha256:abc"},
+		{"localhost:5000/app", "localhost:5000/app", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.imageRef, func(t *testing.T) {
+			name, digest := SplitDigest(tt.imageRef)
+			if name != tt.wantName || digest != tt.wantDigest {
+				t.Errorf("SplitDigest(%q) = %q, %q, want %q, %q", tt.imageRef, name, digest, tt.wantName, tt.wantDigest)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Platform is an OS and CPU architecture an image is published for.
+type Platform struct {
+	OS           string `json:"os"`
+	Architecture string `json:"architecture"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+// String returns the platform in docker's "os/arch[/variant]" form.
+func (p Platform) String() string {
+	s := p.OS + "/" + p.Architecture
+	if p.Variant != "" {
+		s += "/" + p.Variant
+	}
+	return s
+}
+
+// Supports reports whether an image built for p runs on a node reporting
+// os and arch, as given by docker ("x86_64" and "aarch64" are accepted for
+// amd64 and arm64).
+func (p Platform) Supports(os, arch string) bool {
+	if os != "" && !strings.EqualFold(p.OS, os) {
+		return false
+	}
+	return p.Architecture == NormaliseArch(arch)
+}
+
+// NormaliseArch maps the kernel architecture names nodes report to the
+// GOARCH names used in image indexes.
+func NormaliseArch(arch string) string {
+	switch strings.ToLower(arch) {
+	case "x86_64", "x86-64", "amd64":
+		return "amd64"
+	case "aarch64", "arm64":
+		return "arm64"
+	case "armv7l", "armv6l", "armhf", "arm":
+		return "arm"
+	case "i386", "i686", "386":
+		return "386"
+	}
+	return strings.ToLower(arch)
+}
+
+// FetchImagePlatforms lists the platforms imageRef is published for. For a
+// multi-arch index these are its entries, minus attestation manifests; for
+// a single image, the platform in its config blob.
+func FetchImagePlatforms(ctx context.Context, imageRef, token, host string, cred *RegistryCredential) ([]Platform, error) {
+	ref := imageRef
+	if i := strings.Index(ref, "@"); i >= 0 {
+		ref = ref[i+1:]
+	} else {
+		ref = ExtractTag(imageRef)
+		if ref == "" {
+			ref = "latest"
+		}
+	}
+	base := "https://registry-1.docker.io"
+	if host != "" && host != "docker.io" {
+		base = "https://" + host
+	}
+	return fetchPlatforms(ctx, base, RepoPath(imageRef), ref, token, cred)
+}
+
+// fetchPlatforms reads the platforms of repo at ref (a tag or digest).
+func fetchPlatforms(ctx context.Context, base, repo, ref, token string, cred *RegistryCredential) ([]Platform, error) {
+	var m imageManifest
+	if _, err := registryGet(ctx, base+"/v2/"+repo+"/manifests/"+ref, token, cred, &m); err != nil {
+		return nil, fmt.Errorf("manifest: %w", err)
+	}
+	if len(m.Manifests) > 0 {
+		var platforms []Platform
+		for _, e := range m.Manifests {
+			if e.Platform.OS == "" || e.Platform.OS == "unknown" {
+				continue
+			}
+			platforms = append(platforms, Platform{OS: e.Platform.OS, Architecture: e.Platform.Architecture, Variant: e.Platform.Variant})
+		}
+		return platforms, nil
+	}
+	if m.Config.Digest == "" {
+		return nil, fmt.Errorf("manifest has no config")
+	}
+
+	var config Platform
+	if _, err := registryGet(ctx, base+"/v2/"+repo+"/blobs/"+m.Config.Digest, token, cred, &config); err != nil {
+		return nil, fmt.Errorf("config blob: %w", err)
+	}
+	return []Platform{config}, nil
+}
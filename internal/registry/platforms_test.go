@@ -0,0 +1,66 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFetchPlatforms(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/library/nginx/manifests/1.25":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"manifests": []map[string]any{
+					{"digest": "sha256:a", "platform": map[string]string{"os": "linux", "architecture": "amd64"}},
+					{"digest": "sha256:b", "platform": map[string]string{"os": "linux", "architecture": "arm", "variant": "v7"}},
+					{"digest": "sha256:attest", "platform": map[string]string{"os": "unknown", "architecture": "unknown"}},
+				},
+			})
+		case "/v2/acme/app/manifests/1.0":
+			_ = json.NewEncoder(w).Encode(map[string]any{"config": map[string]string{"digest": "sha256:cfg"}})
+		case "/v2/acme/app/blobs/sha256:cfg":
+			_ = json.NewEncoder(w).Encode(map[string]string{"os": "linux", "architecture": "arm64"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	got, err := fetchPlatforms(context.Background(), srv.URL, "library/nginx", "1.25", "", nil)
+	if err != nil {
+		t.Fatalf("index: %v", err)
+	}
+	if len(got) != 2 || got[0].String() != "linux/amd64" || got[1].String() != "linux/arm/v7" {
+		t.Errorf("index platforms = %v", got)
+	}
+
+	got, err = fetchPlatforms(context.Background(), srv.URL, "acme/app", "1.0", "", nil)
+	if err != nil {
+		t.Fatalf("single image: %v", err)
+	}
+	if len(got) != 1 || got[0].String() != "linux/arm64" {
+		t.Errorf("single image platforms = %v", got)
+	}
+}
+
+func TestPlatformSupports(t *testing.T) {
+	tests := []struct {
+		p        Platform
+		os, arch string
+		want     bool
+	}{
+		{Platform{OS: "linux", Architecture: "amd64"}, "linux", "x86_64", true},
+		{Platform{OS: "linux", Architecture: "arm64"}, "linux", "aarch64", true},
+		{Platform{OS: "linux", Architecture: "amd64"}, "linux", "aarch64", false},
+		{Platform{OS: "windows", Architecture: "amd64"}, "linux", "x86_64", false},
+		{Platform{OS: "linux", Architecture: "arm", Variant: "v7"}, "linux", "armv7l", true},
+	}
+	for _, tt := range tests {
+		if got := tt.p.Supports(tt.os, tt.arch); got != tt.want {
+			t.Errorf("%s.Supports(%q, %q) = %v, want %v", tt.p, tt.os, tt.arch, got, tt.want)
+		}
+	}
+}
@@ -69,8 +69,36 @@ func FetchReleaseNotesWithSources(ctx context.Context, imageRef, version string,
 	return info
 }
 
+// CachedReleaseNotes returns a release previously fetched by
+// FetchReleaseNotesWithSources without touching the network. ok is false when
+// the lookup has not been made yet or its cache entry has expired. Images with
+// no known GitHub repo report ok with a nil info, as there is nothing to fetch.
+func CachedReleaseNotes(imageRef, version string, sources []ReleaseSource) (info *ReleaseInfo, ok bool) {
+	repo := imageToGitHubRepoWithSources(imageRef, sources)
+	if repo == "" {
+		return nil, true
+	}
+
+	releaseCache.Lock()
+	defer releaseCache.Unlock()
+	entry, ok := releaseCache.entries[repo+":"+version]
+	if !ok || time.Since(entry.fetchedAt) >= time.Hour {
+		return nil, false
+	}
+	return entry.info, true
+}
+
 // imageToGitHubRepoWithSources checks custom sources first, then built-in mappings.
 func imageToGitHubRepoWithSources(imageRef string, sources []ReleaseSource) string {
+	if repo := customGitHubRepo(imageRef, sources); repo != "" {
+		return repo
+	}
+	return imageToGitHubRepo(imageRef)
+}
+
+// customGitHubRepo returns the repo of the first custom source matching
+// imageRef (tag and digest stripped), or "".
+func customGitHubRepo(imageRef string, sources []ReleaseSource) string {
 	ref := imageRef
 	if i := strings.Index(ref, "@"); i >= 0 {
 		ref = ref[:i]
@@ -87,8 +115,7 @@ func imageToGitHubRepoWithSources(imageRef string, sources []ReleaseSource) stri
 			return src.GitHubRepo
 		}
 	}
-
-	return imageToGitHubRepo(imageRef)
+	return ""
 }
 
 // matchImagePattern returns true if imageRef matches pattern.
@@ -182,3 +209,191 @@ func fetchGitHubRelease(ctx context.Context, repo, version string) *ReleaseInfo
 
 	return nil
 }
+
+// Release source origins reported by ResolveGitHubRepo.
+const (
+	RepoOriginCustom  = "custom"  // user-configured ReleaseSource mapping
+	RepoOriginLabel   = "label"   // guessed from OCI source labels
+	RepoOriginBuiltin = "builtin" // built-in registry naming conventions
+)
+
+// sourceLabels are the image labels checked, in order, when guessing the
+// source repository of an image.
+var sourceLabels = []string{
+	"org.opencontainers.image.source",
+	"org.label-schema.vcs-url",
+	"org.opencontainers.image.url",
+	"org.label-schema.url",
+}
+
+// GuessGitHubRepo extracts an "owner/repo" path from OCI source labels.
+// Returns "" if no label points at github.com.
+func GuessGitHubRepo(labels map[string]string) string {
+	for _, key := range sourceLabels {
+		if repo := githubRepoFromURL(labels[key]); repo != "" {
+			return repo
+		}
+	}
+	return ""
+}
+
+// githubRepoFromURL parses "https://github.com/owner/repo(.git)(/...)" and
+// "git@github.com:owner/repo.git" into "owner/repo".
+func githubRepoFromURL(raw string) string {
+	s := strings.TrimSpace(raw)
+	switch {
+	case strings.HasPrefix(s, "git@github.com:"):
+		s = strings.TrimPrefix(s, "git@github.com:")
+	default:
+		s = strings.TrimPrefix(s, "https://")
+		s = strings.TrimPrefix(s, "http://")
+		s = strings.TrimPrefix(s, "www.")
+		if !strings.HasPrefix(s, "github.com/") {
+			return ""
+		}
+		s = strings.TrimPrefix(s, "github.com/")
+	}
+	parts := strings.Split(s, "/")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return ""
+	}
+	return parts[0] + "/" + strings.TrimSuffix(parts[1], ".git")
+}
+
+// ResolveGitHubRepo finds the GitHub repository for an image. Custom sources
+// win, then OCI labels, then the built-in mappings. origin is one of the
+// RepoOrigin* constants, or "" when no repository could be found.
+func ResolveGitHubRepo(imageRef string, labels map[string]string, sources []ReleaseSource) (repo, origin string) {
+	if repo := customGitHubRepo(imageRef, sources); repo != "" {
+		return repo, RepoOriginCustom
+	}
+	if repo := GuessGitHubRepo(labels); repo != "" {
+		return repo, RepoOriginLabel
+	}
+	if repo := imageToGitHubRepo(imageRef); repo != "" {
+		return repo, RepoOriginBuiltin
+	}
+	return "", ""
+}
+
+// LatestRelease describes a single GitHub release.
+type LatestRelease struct {
+	Tag         string    `json:"tag"`
+	Name        string    `json:"name,omitempty"`
+	URL         string    `json:"url"`
+	PublishedAt time.Time `json:"published_at"`
+	Prerelease  bool      `json:"prerelease"`
+}
+
+// RepoReleaseInfo summarises a repository's release state for the UI:
+// the newest release, where to find the changelog, and end-of-life hints.
+type RepoReleaseInfo struct {
+	Repo         string         `json:"repo"`
+	ChangelogURL string         `json:"changelog_url"`
+	Latest       *LatestRelease `json:"latest,omitempty"`
+	Archived     bool           `json:"archived"`
+	EOL          bool           `json:"eol"`                  // archived or no release for staleReleaseAge
+	EOLReason    string         `json:"eol_reason,omitempty"` // human-readable hint
+}
+
+// staleReleaseAge is how long without a release before a repo is hinted as EOL.
+const staleReleaseAge = 18 * 30 * 24 * time.Hour
+
+var repoInfoCache struct {
+	sync.Mutex
+	entries map[string]repoInfoCacheEntry
+}
+
+type repoInfoCacheEntry struct {
+	info      *RepoReleaseInfo
+	fetchedAt time.Time
+}
+
+// githubAPIBase is overridden in tests.
+var githubAPIBase = "https://api.github.com"
+
+// FetchRepoReleaseInfo looks up the newest release of a GitHub repository.
+// Pre-releases are skipped unless includePrerelease is set. Results are
+// cached for 1 hour.
+func FetchRepoReleaseInfo(ctx context.Context, repo string, includePrerelease bool) (*RepoReleaseInfo, error) {
+	cacheKey := fmt.Sprintf("%s:%t", repo, includePrerelease)
+	repoInfoCache.Lock()
+	if repoInfoCache.entries == nil {
+		repoInfoCache.entries = make(map[string]repoInfoCacheEntry)
+	}
+	if entry, ok := repoInfoCache.entries[cacheKey]; ok && time.Since(entry.fetchedAt) < time.Hour {
+		repoInfoCache.Unlock()
+		return entry.info, nil
+	}
+	repoInfoCache.Unlock()
+
+	info := &RepoReleaseInfo{
+		Repo:         repo,
+		ChangelogURL: "https://github.com/" + repo + "/releases",
+	}
+
+	var meta struct {
+		Archived bool `json:"archived"`
+	}
+	if err := githubGet(ctx, "/repos/"+repo, &meta); err != nil {
+		return nil, err
+	}
+	info.Archived = meta.Archived
+
+	var releases []struct {
+		TagName     string    `json:"tag_name"`
+		Name        string    `json:"name"`
+		HTMLURL     string    `json:"html_url"`
+		Draft       bool      `json:"draft"`
+		Prerelease  bool      `json:"prerelease"`
+		PublishedAt time.Time `json:"published_at"`
+	}
+	if err := githubGet(ctx, "/repos/"+repo+"/releases?per_page=20", &releases); err != nil {
+		return nil, err
+	}
+	for _, rel := range releases {
+		if rel.Draft || (rel.Prerelease && !includePrerelease) {
+			continue
+		}
+		info.Latest = &LatestRelease{
+			Tag:         rel.TagName,
+			Name:        rel.Name,
+			URL:         rel.HTMLURL,
+			PublishedAt: rel.PublishedAt,
+			Prerelease:  rel.Prerelease,
+		}
+		break
+	}
+
+	switch {
+	case info.Archived:
+		info.EOL = true
+		info.EOLReason = "source repository is archived"
+	case info.Latest != nil && time.Since(info.Latest.PublishedAt) > staleReleaseAge:
+		info.EOL = true
+		info.EOLReason = "no release in over 18 months"
+	}
+
+	repoInfoCache.Lock()
+	repoInfoCache.entries[cacheKey] = repoInfoCacheEntry{info: info, fetchedAt: time.Now()}
+	repoInfoCache.Unlock()
+	return info, nil
+}
+
+// githubGet performs an unauthenticated GitHub API GET and decodes the JSON body.
+func githubGet(ctx context.Context, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, githubAPIBase+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("github %s: %s", path, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
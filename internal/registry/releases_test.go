@@ -1,6 +1,13 @@
 package registry
 
-import "testing"
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
 
 func TestImageToGitHubRepo(t *testing.T) {
 	tests := []struct {
@@ -129,3 +136,116 @@ func TestImageToGitHubRepoWithSources(t *testing.T) {
 		})
 	}
 }
+
+func TestGuessGitHubRepo(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   string
+	}{
+		{"oci source", map[string]string{"org.opencontainers.image.source": "https://github.com/owner/app"}, "owner/app"},
+		{"oci source with .git", map[string]string{"org.opencontainers.image.source": "https://github.com/owner/app.git"}, "owner/app"},
+		{"oci source deep path", map[string]string{"org.opencontainers.image.source": "https://github.com/owner/app/tree/main/docker"}, "owner/app"},
+		{"label-schema ssh", map[string]string{"org.label-schema.vcs-url": "git@github.com:owner/app.git"}, "owner/app"},
+		{"non-github source", map[string]string{"org.opencontainers.image.source": "https://gitlab.com/owner/app"}, ""},
+		{"no labels", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GuessGitHubRepo(tt.labels); got != tt.want {
+				t.Errorf("GuessGitHubRepo() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveGitHubRepoOrigin(t *testing.T) {
+	labels := map[string]string{"org.opencontainers.image.source": "https://github.com/label/repo"}
+	sources := []ReleaseSource{{ImagePattern: "myorg/*", GitHubRepo: "custom/repo"}}
+
+	if repo, origin := ResolveGitHubRepo("myorg/app:1.0", labels, sources); repo != "custom/repo" || origin != RepoOriginCustom {
+		t.Errorf("custom: got %q/%q", repo, origin)
+	}
+	if repo, origin := ResolveGitHubRepo("other/app:1.0", labels, sources); repo != "label/repo" || origin != RepoOriginLabel {
+		t.Errorf("label: got %q/%q", repo, origin)
+	}
+	if repo, origin := ResolveGitHubRepo("ghcr.io/owner/app:1.0", nil, nil); repo != "owner/app" || origin != RepoOriginBuiltin {
+		t.Errorf("builtin: got %q/%q", repo, origin)
+	}
+	if repo, origin := ResolveGitHubRepo("nginx:1.25", nil, nil); repo != "" || origin != "" {
+		t.Errorf("unmapped: got %q/%q", repo, origin)
+	}
+}
+
+func TestFetchRepoReleaseInfo(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/app":
+			_ = json.NewEncoder(w).Encode(map[string]any{"archived": false})
+		case "/repos/owner/app/releases":
+			_ = json.NewEncoder(w).Encode([]map[string]any{
+				{"tag_name": "v2.0.0-rc1", "html_url": "https://github.com/owner/app/releases/tag/v2.0.0-rc1", "prerelease": true, "published_at": time.Now()},
+				{"tag_name": "v1.9.0", "html_url": "https://github.com/owner/app/releases/tag/v1.9.0", "published_at": time.Now().Add(-2 * 365 * 24 * time.Hour)},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	orig := githubAPIBase
+	githubAPIBase = srv.URL
+	t.Cleanup(func() { githubAPIBase = orig })
+
+	info, err := FetchRepoReleaseInfo(context.Background(), "owner/app", false)
+	if err != nil {
+		t.Fatalf("FetchRepoReleaseInfo: %v", err)
+	}
+	if info.Latest == nil || info.Latest.Tag != "v1.9.0" {
+		t.Fatalf("latest = %+v, want v1.9.0 (pre-release skipped)", info.Latest)
+	}
+	if !info.EOL {
+		t.Error("expected EOL hint for a release older than 18 months")
+	}
+	if info.ChangelogURL != "https://github.com/owner/app/releases" {
+		t.Errorf("changelog url = %q", info.ChangelogURL)
+	}
+
+	pre, err := FetchRepoReleaseInfo(context.Background(), "owner/app", true)
+	if err != nil {
+		t.Fatalf("FetchRepoReleaseInfo(prerelease): %v", err)
+	}
+	if pre.Latest == nil || pre.Latest.Tag != "v2.0.0-rc1" || pre.EOL {
+		t.Errorf("prerelease latest = %+v eol=%v, want v2.0.0-rc1 and no EOL", pre.Latest, pre.EOL)
+	}
+}
+
+func TestCachedReleaseNotes(t *testing.T) {
+	sources := []ReleaseSource{{ImagePattern: "cached-app", GitHubRepo: "org/cached-app"}}
+
+	if info, ok := CachedReleaseNotes("unknown/image:v1", "1.0.0", nil); !ok || info != nil {
+		t.Errorf("unmapped image = (%v, %v), want (nil, true)", info, ok)
+	}
+	if _, ok := CachedReleaseNotes("cached-app:1.0.0", "1.1.0", sources); ok {
+		t.Error("uncached lookup reported ok")
+	}
+
+	want := &ReleaseInfo{URL: "https://github.com/org/cached-app/releases/tag/v1.1.0"}
+	releaseCache.Lock()
+	releaseCache.entries["org/cached-app:1.1.0"] = releaseCacheEntry{info: want, fetchedAt: time.Now()}
+	releaseCache.entries["org/cached-app:1.2.0"] = releaseCacheEntry{info: want, fetchedAt: time.Now().Add(-2 * time.Hour)}
+	releaseCache.Unlock()
+	t.Cleanup(func() {
+		releaseCache.Lock()
+		delete(releaseCache.entries, "org/cached-app:1.1.0")
+		delete(releaseCache.entries, "org/cached-app:1.2.0")
+		releaseCache.Unlock()
+	})
+
+	if info, ok := CachedReleaseNotes("cached-app:1.0.0", "1.1.0", sources); !ok || info != want {
+		t.Errorf("cached lookup = (%v, %v), want (%v, true)", info, ok, want)
+	}
+	if _, ok := CachedReleaseNotes("cached-app:1.0.0", "1.2.0", sources); ok {
+		t.Error("expired lookup reported ok")
+	}
+}
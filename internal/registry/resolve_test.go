@@ -28,6 +28,8 @@ func TestRepoPath(t *testing.T) {
 		{"localhost:5000/myrepo:tag", "library/myrepo"},
 		{"localhost:5000/myrepo", "library/myrepo"},
 		{"localhost:5000/org/app:tag", "org/app"},
+		{"localhost:5000/org/app:tag@sha256:abc123", "org/app"},
+		{"nginx:1.25@sha256:abc123", "library/nginx"},
 		{"registry.example.com:443/org/app:v2.0", "org/app"},
 	}
 	for _, tt := range tests {
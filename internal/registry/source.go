@@ -0,0 +1,242 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/docker"
+)
+
+// Source is a custom update source consulted instead of the OCI registry
+// for the images it claims. It lets in-house images be checked against an
+// internal artifact API, a Git tag list, etc. without touching the checker.
+//
+// Implementations register themselves with RegisterSource, typically from
+// an init function or during startup wiring.
+type Source interface {
+	// Name identifies the source in logs and the registry of sources.
+	Name() string
+	// Match reports whether this source handles the given image reference.
+	Match(imageRef string) bool
+	// Lookup returns what the source currently knows about imageRef.
+	Lookup(ctx context.Context, imageRef string) (SourceResult, error)
+}
+
+// SourceResult is what a Source reports for an image. Both fields are
+// optional: a digest-only source leaves Tags empty, a tag-only source
+// leaves Digest empty.
+type SourceResult struct {
+	Digest string   `json:"digest,omitempty"` // current digest for the image's tag
+	Tags   []string `json:"tags,omitempty"`   // available tags; semver ones are compared against the current tag
+}
+
+var (
+	sourcesMu sync.RWMutex
+	sources   = make(map[string]Source)
+)
+
+// RegisterSource adds a custom update source. Registering a second source
+// with the same name replaces the first.
+func RegisterSource(s Source) {
+	sourcesMu.Lock()
+	defer sourcesMu.Unlock()
+	sources[s.Name()] = s
+}
+
+// UnregisterSource removes a custom update source by name.
+func UnregisterSource(name string) {
+	sourcesMu.Lock()
+	defer sourcesMu.Unlock()
+	delete(sources, name)
+}
+
+// Sources returns the names of all registered sources, sorted.
+func Sources() []string {
+	sourcesMu.RLock()
+	defer sourcesMu.RUnlock()
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sourceFor returns the first registered source (by name order) that
+// matches imageRef, or nil when the image should go to the registry.
+func sourceFor(imageRef string) Source {
+	sourcesMu.RLock()
+	defer sourcesMu.RUnlock()
+	if len(sources) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if sources[name].Match(imageRef) {
+			return sources[name]
+		}
+	}
+	return nil
+}
+
+// checkSource runs a version check against a custom source. The local
+// digest comes from knownDigest when set, otherwise from the Docker daemon;
+// the remote side comes from the source.
+func (c *Checker) checkSource(ctx context.Context, src Source, imageRef string, knownDigest *string, scope docker.SemverScope, includeRE, excludeRE string) CheckResult {
+	result := CheckResult{ImageRef: imageRef}
+
+	res, err := src.Lookup(ctx, imageRef)
+	if err != nil {
+		c.log.Warn("custom source check failed", "source", src.Name(), "image", imageRef, "error", err)
+		result.Error = fmt.Errorf("source %s: %w", src.Name(), err)
+		return result
+	}
+
+	if res.Digest != "" {
+		var localDigest string
+		if knownDigest != nil {
+			localDigest = *knownDigest
+		} else {
+			d, err := c.docker.ImageDigest(ctx, imageRef)
+			if err != nil {
+				c.log.Warn("failed to get local digest", "image", imageRef, "error", err)
+				result.Error = err
+				return result
+			}
+			localDigest = d
+		}
+		result.LocalDigest = localDigest
+		result.RemoteDigest = res.Digest
+		if !digestsMatch(localDigest, res.Digest) {
+			result.UpdateAvailable = true
+		}
+	}
+
+	tag := ExtractTag(imageRef)
+	if _, ok := ParseSemVer(tag); !ok || len(res.Tags) == 0 {
+		return result
+	}
+	filtered := FilterTags(res.Tags, includeRE, excludeRE)
+	newer, beyond := NewerVersionsScopedWithBeyond(tag, filtered, scope, c.defaultScope)
+	result.HigherVersionsBeyondScope = beyond
+	setNewerVersions(&result, tag, newer)
+	return result
+}
+
+// HTTPSource is a Source backed by a simple HTTP protocol, so custom
+// sources can live outside the Sentinel binary. For each matching image
+// it sends GET <URL>?image=<ref> and expects a JSON SourceResult body.
+type HTTPSource struct {
+	SourceName string // unique name
+	Pattern    string // glob matched against the image repository (e.g. "registry.internal/*")
+	URL        string // endpoint queried with ?image=<ref>
+	Token      string // optional bearer token
+
+	client *http.Client
+}
+
+// Name implements Source.
+func (h *HTTPSource) Name() string { return h.SourceName }
+
+// Match implements Source. The tag and digest are stripped before matching.
+func (h *HTTPSource) Match(imageRef string) bool {
+	repo := imageRef
+	if i := strings.Index(repo, "@"); i >= 0 {
+		repo = repo[:i]
+	}
+	if i := strings.LastIndex(repo, ":"); i >= 0 && i > strings.LastIndex(repo, "/") {
+		repo = repo[:i]
+	}
+	ok, err := path.Match(h.Pattern, repo)
+	return err == nil && ok
+}
+
+// Lookup implements Source.
+func (h *HTTPSource) Lookup(ctx context.Context, imageRef string) (SourceResult, error) {
+	u, err := url.Parse(h.URL)
+	if err != nil {
+		return SourceResult{}, fmt.Errorf("parse source url: %w", err)
+	}
+	q := u.Query()
+	q.Set("image", imageRef)
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return SourceResult{}, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if h.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+h.Token)
+	}
+
+	client := h.client
+	if client == nil {
+		client = httpClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return SourceResult{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return SourceResult{}, fmt.Errorf("source returned %s", resp.Status)
+	}
+
+	var res SourceResult
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return SourceResult{}, fmt.Errorf("decode source response: %w", err)
+	}
+	return res, nil
+}
+
+// ParseHTTPSources parses a SENTINEL_UPDATE_SOURCES value into HTTP sources.
+// Entries are separated by ";" and each is name=pattern=url, e.g.
+// "artifacts=registry.internal/*=https://artifacts.internal/sentinel".
+// A source that needs a bearer token names the environment variable holding
+// it after a "|", e.g. "...=https://artifacts.internal/sentinel|ARTIFACTS_TOKEN",
+// so the token itself never appears in the displayed configuration.
+func ParseHTTPSources(spec string) ([]*HTTPSource, error) {
+	var out []*HTTPSource
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		var tokenEnv string
+		if i := strings.LastIndex(entry, "|"); i >= 0 {
+			entry, tokenEnv = entry[:i], strings.TrimSpace(entry[i+1:])
+			if tokenEnv == "" {
+				return nil, fmt.Errorf("invalid update source %q: empty token variable after |", entry)
+			}
+		}
+		parts := strings.SplitN(entry, "=", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+			return nil, fmt.Errorf("invalid update source %q: want name=pattern=url[|TOKEN_VAR]", entry)
+		}
+		if _, err := path.Match(parts[1], ""); err != nil {
+			return nil, fmt.Errorf("invalid update source pattern %q: %w", parts[1], err)
+		}
+		src := &HTTPSource{SourceName: parts[0], Pattern: parts[1], URL: parts[2]}
+		if tokenEnv != "" {
+			src.Token = os.Getenv(tokenEnv)
+			if src.Token == "" {
+				return nil, fmt.Errorf("update source %q: token variable %s is not set", parts[0], tokenEnv)
+			}
+		}
+		out = append(out, src)
+	}
+	return out, nil
+}
@@ -0,0 +1,191 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/logging"
+)
+
+// staticSource is a Source that returns a fixed result for matching images.
+type staticSource struct {
+	name   string
+	prefix string
+	result SourceResult
+}
+
+func (s *staticSource) Name() string { return s.name }
+func (s *staticSource) Match(ref string) bool {
+	return len(ref) >= len(s.prefix) && ref[:len(s.prefix)] == s.prefix
+}
+func (s *staticSource) Lookup(_ context.Context, _ string) (SourceResult, error) {
+	return s.result, nil
+}
+
+func TestCheckVersionedUsesRegisteredSource(t *testing.T) {
+	src := &staticSource{
+		name:   "test-internal",
+		prefix: "artifacts.internal/",
+		result: SourceResult{Tags: []string{"1.0.0", "1.0.1", "1.0.2", "nightly"}},
+	}
+	RegisterSource(src)
+	t.Cleanup(func() { UnregisterSource(src.name) })
+
+	mock := newMockRegistry()
+	// A registry error proves the registry path was never taken.
+	mock.distributionErr["artifacts.internal/app:1.0.0"] = context.DeadlineExceeded
+	checker := NewChecker(mock, logging.New(false))
+
+	result := checker.CheckVersioned(context.Background(), "artifacts.internal/app:1.0.0", "", "", "")
+	if result.Error != nil {
+		t.Fatalf("unexpected error: %v", result.Error)
+	}
+	if !result.UpdateAvailable {
+		t.Error("expected update available from source tags")
+	}
+	if len(result.NewerVersions) != 2 || result.NewerVersions[0] != "1.0.2" {
+		t.Errorf("NewerVersions = %v, want [1.0.2 1.0.1]", result.NewerVersions)
+	}
+}
+
+func TestCheckVersionedSourceDigest(t *testing.T) {
+	src := &staticSource{
+		name:   "test-digest",
+		prefix: "git.internal/",
+		result: SourceResult{Digest: "sha256:new"},
+	}
+	RegisterSource(src)
+	t.Cleanup(func() { UnregisterSource(src.name) })
+
+	mock := newMockRegistry()
+	mock.imageDigests["git.internal/tool:latest"] = "git.internal/tool@sha256:old"
+	checker := NewChecker(mock, logging.New(false))
+
+	result := checker.CheckVersioned(context.Background(), "git.internal/tool:latest", "", "", "")
+	if !result.UpdateAvailable {
+		t.Error("expected update when source digest differs")
+	}
+	if result.RemoteDigest != "sha256:new" {
+		t.Errorf("RemoteDigest = %q, want sha256:new", result.RemoteDigest)
+	}
+}
+
+func TestCheckVersionedWithDigestUsesRegisteredSource(t *testing.T) {
+	src := &staticSource{
+		name:   "test-swarm",
+		prefix: "artifacts.internal/",
+		result: SourceResult{Digest: "sha256:new", Tags: []string{"2.0.0", "2.0.1"}},
+	}
+	RegisterSource(src)
+	t.Cleanup(func() { UnregisterSource(src.name) })
+
+	mock := newMockRegistry()
+	// Neither the registry nor the local image store may be consulted:
+	// the service spec supplies the local digest.
+	mock.distributionErr["artifacts.internal/svc:2.0.0"] = context.DeadlineExceeded
+	mock.imageDigestErr["artifacts.internal/svc:2.0.0"] = context.DeadlineExceeded
+	checker := NewChecker(mock, logging.New(false))
+
+	result := checker.CheckVersionedWithDigest(context.Background(), "artifacts.internal/svc:2.0.0", "sha256:old", "", "", "")
+	if result.Error != nil {
+		t.Fatalf("unexpected error: %v", result.Error)
+	}
+	if result.IsLocal {
+		t.Fatal("source-backed image should not be treated as local")
+	}
+	if result.LocalDigest != "sha256:old" || result.RemoteDigest != "sha256:new" {
+		t.Errorf("digests = %q -> %q, want sha256:old -> sha256:new", result.LocalDigest, result.RemoteDigest)
+	}
+	if !result.UpdateAvailable {
+		t.Error("expected update when source digest differs")
+	}
+	if len(result.NewerVersions) != 1 || result.NewerVersions[0] != "2.0.1" {
+		t.Errorf("NewerVersions = %v, want [2.0.1]", result.NewerVersions)
+	}
+}
+
+func TestHTTPSource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("image") != "registry.internal/team/app:2.0.0" {
+			http.Error(w, "bad image", http.StatusBadRequest)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unauthorised", http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(SourceResult{Tags: []string{"2.0.0", "2.0.1"}})
+	}))
+	defer srv.Close()
+
+	src := &HTTPSource{SourceName: "http", Pattern: "registry.internal/*/*", URL: srv.URL, Token: "secret"}
+	if !src.Match("registry.internal/team/app:2.0.0") {
+		t.Fatal("expected pattern to match")
+	}
+	if src.Match("docker.io/library/nginx:latest") {
+		t.Fatal("pattern should not match unrelated image")
+	}
+
+	res, err := src.Lookup(context.Background(), "registry.internal/team/app:2.0.0")
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	if len(res.Tags) != 2 {
+		t.Errorf("tags = %v, want 2 entries", res.Tags)
+	}
+}
+
+func TestParseHTTPSources(t *testing.T) {
+	srcs, err := ParseHTTPSources("a=registry.internal/*=https://a.example/check?x=1; b=git.internal/*=https://b.example")
+	if err != nil {
+		t.Fatalf("ParseHTTPSources: %v", err)
+	}
+	if len(srcs) != 2 {
+		t.Fatalf("got %d sources, want 2", len(srcs))
+	}
+	if srcs[0].URL != "https://a.example/check?x=1" {
+		t.Errorf("URL = %q, want query preserved", srcs[0].URL)
+	}
+
+	if _, err := ParseHTTPSources("missing-parts"); err == nil {
+		t.Error("expected error for malformed entry")
+	}
+}
+
+func TestParseHTTPSourcesToken(t *testing.T) {
+	t.Setenv("ARTIFACTS_TOKEN", "s3cret")
+	srcs, err := ParseHTTPSources("a=registry.internal/*=https://a.example/check?x=1|ARTIFACTS_TOKEN;b=git.internal/*=https://b.example")
+	if err != nil {
+		t.Fatalf("ParseHTTPSources: %v", err)
+	}
+	if srcs[0].URL != "https://a.example/check?x=1" || srcs[0].Token != "s3cret" {
+		t.Errorf("source a = %q token %q, want URL without the variable and the token from the environment", srcs[0].URL, srcs[0].Token)
+	}
+	if srcs[1].Token != "" {
+		t.Errorf("source b token = %q, want none", srcs[1].Token)
+	}
+
+	var gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte(`{"tags":["1.0.0"]}`))
+	}))
+	defer srv.Close()
+	srcs[0].URL = srv.URL
+	if _, err := srcs[0].Lookup(context.Background(), "registry.internal/app:1.0.0"); err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	if gotAuth != "Bearer s3cret" {
+		t.Errorf("Authorization = %q, want the parsed token", gotAuth)
+	}
+
+	if _, err := ParseHTTPSources("a=registry.internal/*=https://a.example|UNSET_SENTINEL_TOKEN"); err == nil {
+		t.Error("expected error for an unset token variable")
+	}
+	if _, err := ParseHTTPSources("a=registry.internal/*=https://a.example|"); err == nil {
+		t.Error("expected error for an empty token variable")
+	}
+}
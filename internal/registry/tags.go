@@ -165,6 +165,25 @@ func ParseSemVer(tag string) (SemVer, bool) {
 	}, true
 }
 
+// NormaliseVersion turns a tag or version label into a display version:
+// whitespace is trimmed, a "v" prefix before a digit is dropped and build
+// metadata ("+build.5") is removed. Floating tags such as "latest" or
+// "stable" carry no version information and normalise to "".
+func NormaliseVersion(v string) string {
+	v = strings.TrimSpace(v)
+	if i := strings.Index(v, "+"); i >= 0 {
+		v = v[:i]
+	}
+	if len(v) > 1 && (v[0] == 'v' || v[0] == 'V') && v[1] >= '0' && v[1] <= '9' {
+		v = v[1:]
+	}
+	switch strings.ToLower(v) {
+	case "", "latest", "stable", "edge", "nightly", "main", "master", "dev", "develop", "beta", "alpha", "rolling":
+		return ""
+	}
+	return v
+}
+
 // LessThan returns true if v is strictly less than other.
 // Pre-release versions are considered less than their release counterpart
 // (e.g. 1.2.3-rc1 < 1.2.3). When both have pre-release strings, they are
@@ -240,6 +259,16 @@ func NewerVersionsScoped(current string, tags []string, scope, defaultScope dock
 		return nil
 	}
 
+	// A semver range in place of a named scope bounds candidates directly,
+	// calver included. An unparseable range falls back to the default scope.
+	var constraint *Constraint
+	if scope.IsConstraint() {
+		if c, err := ParseConstraint(string(scope)); err == nil {
+			constraint = &c
+		}
+		scope = docker.ScopeDefault
+	}
+
 	var newer []SemVer
 	curIsCalver := cur.Major >= 1900
 	curVariant := cur.Variant()
@@ -279,7 +308,11 @@ func NewerVersionsScoped(current string, tags []string, scope, defaultScope dock
 		}
 
 		// Scope constraint: calver is exempt from scope filtering.
-		if !curIsCalver {
+		if constraint != nil {
+			if !constraint.Check(sv) {
+				continue
+			}
+		} else if !curIsCalver {
 			switch scope {
 			case docker.ScopeDefault:
 				if defaultScope == docker.ScopeStrict {
@@ -80,6 +80,24 @@ func TestParseSemVerInvalid(t *testing.T) {
 	}
 }
 
+func TestNormaliseVersion(t *testing.T) {
+	tests := map[string]string{
+		"v1.2.3":        "1.2.3",
+		" 1.25.3 ":      "1.25.3",
+		"2.0.0+build.7": "2.0.0",
+		"V3":            "3",
+		"version":       "version",
+		"latest":        "",
+		"Stable":        "",
+		"":              "",
+	}
+	for in, want := range tests {
+		if got := NormaliseVersion(in); got != want {
+			t.Errorf("NormaliseVersion(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
 func TestLessThan(t *testing.T) {
 	tests := []struct {
 		name string
@@ -231,6 +249,12 @@ func TestNewerVersionsScoped(t *testing.T) {
 		{"2part_patch_narrows", "1.13", docker.ScopePatch, nil},
 		{"2part_minor_same_as_default", "1.13", docker.ScopeMinor, []string{"1.15.0", "1.14.0"}},
 		{"2part_major_all", "1.13", docker.ScopeMajor, []string{"2.0.0", "1.15.0", "1.14.0"}},
+		// Semver ranges from the sentinel.semver label.
+		{"range_tilde", "v1.13.3", docker.SemverScope("~1.13"), []string{"1.13.5", "1.13.4"}},
+		{"range_below_major", "v1.13.3", docker.SemverScope(">=1.14 <2"), []string{"1.15.0", "1.14.0"}},
+		{"range_caret", "v1.13.3", docker.SemverScope("^1"), []string{"1.15.0", "1.14.0", "1.13.5", "1.13.4"}},
+		{"range_or", "v1.13.3", docker.SemverScope("1.13.5 || 2.x"), []string{"2.0.0", "1.13.5"}},
+		{"range_invalid_falls_back", "v1.13.3", docker.SemverScope(">=1.a"), []string{"1.13.5", "1.13.4"}},
 	}
 
 	for _, tt := range tests {
@@ -453,6 +477,10 @@ func TestExtractTag(t *testing.T) {
 		{"nginx", ""},
 		{"nginx@sha256:abc123", ""},
 		{"ghcr.io/owner/app:v1.2.3", "v1.2.3"},
+		{"nginx:1.25@sha256:abc123", "1.25"},
+		{"localhost:5000/app:v1@sha256:abc123", "v1"},
+		{"localhost:5000/app@sha256:abc123", ""},
+		{"localhost:5000/org/app", ""},
 	}
 
 	for _, tt := range tests {
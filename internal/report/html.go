@@ -0,0 +1,70 @@
+package report
+
+import (
+	"bytes"
+	"html/template"
+	"time"
+)
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"when": func(t time.Time) string { return t.Format("2006-01-02 15:04") },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Roboto, sans-serif; color: #1f2328; max-width: 60rem; margin: 2rem auto; padding: 0 1rem; }
+h1 { font-size: 1.4rem; }
+h2 { font-size: 1.1rem; margin-top: 2rem; border-bottom: 1px solid #d0d7de; padding-bottom: .3rem; }
+table { border-collapse: collapse; width: 100%; font-size: .9rem; }
+th, td { text-align: left; padding: .35rem .5rem; border-bottom: 1px solid #eaeef2; vertical-align: top; }
+.stats { display: flex; gap: 1rem; flex-wrap: wrap; }
+.stat { border: 1px solid #d0d7de; border-radius: 6px; padding: .6rem 1rem; min-width: 9rem; }
+.stat b { display: block; font-size: 1.4rem; }
+.muted { color: #656d76; }
+.mono { font-family: ui-monospace, monospace; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="muted">Generated {{when .To}}</p>
+<div class="stats">
+<div class="stat"><b>{{len .Applied}}</b>Updates applied</div>
+<div class="stat"><b>{{len .Failures}}</b>Failures</div>
+<div class="stat"><b>{{len .RateLimited}}</b>Rate limit incidents</div>
+<div class="stat"><b>{{len .StalePending}}</b>Approvals waiting 7+ days</div>
+<div class="stat"><b>{{len .OfflineHosts}}</b>Hosts offline</div>
+</div>
+{{define "entries"}}{{if .}}<table>
+<tr><th>Time</th><th>Container</th><th>Image</th><th>Detail</th></tr>
+{{range .}}<tr><td>{{when .Time}}</td><td>{{.Container}}{{if .Host}} <span class="muted">({{.Host}})</span>{{end}}</td><td class="mono">{{.NewImage}}</td><td>{{.Detail}}</td></tr>
+{{end}}</table>{{else}}<p class="muted">None</p>{{end}}{{end}}
+<h2>Updates applied</h2>
+{{template "entries" .Applied}}
+<h2>Failures</h2>
+{{template "entries" .Failures}}
+<h2>Rate limit incidents</h2>
+{{template "entries" .RateLimited}}
+<h2>Pending approvals older than 7 days</h2>
+{{if .StalePending}}<table>
+<tr><th>Container</th><th>Target</th><th>Waiting since</th></tr>
+{{range .StalePending}}<tr><td>{{.Container}}{{if .Host}} <span class="muted">({{.Host}})</span>{{end}}</td><td class="mono">{{.Target}}</td><td>{{when .DetectedAt}}</td></tr>
+{{end}}</table>{{else}}<p class="muted">None</p>{{end}}
+<h2>Hosts offline</h2>
+{{if .OfflineHosts}}<table>
+<tr><th>Host</th><th>Since</th><th>Detail</th></tr>
+{{range .OfflineHosts}}<tr><td>{{.Name}}</td><td>{{if not .Since.IsZero}}{{when .Since}}{{end}}</td><td>{{.Detail}}</td></tr>
+{{end}}</table>{{else}}<p class="muted">None</p>{{end}}
+</body>
+</html>
+`))
+
+// HTML renders the report as a standalone HTML page.
+func (r *Report) HTML() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, r); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,111 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Page layout for the PDF rendering (A4, points).
+const (
+	pdfWidth      = 595
+	pdfHeight     = 842
+	pdfMargin     = 50
+	pdfLeading    = 14
+	pdfFontSize   = 10
+	pdfMaxLineLen = 110
+)
+
+// PDF renders the report as a plain-text PDF. It uses only the standard
+// Helvetica fonts so no font files need embedding.
+func (r *Report) PDF() []byte {
+	lines := r.Lines()
+	perPage := (pdfHeight - 2*pdfMargin) / pdfLeading
+	var pages [][]string
+	for len(lines) > 0 {
+		n := min(perPage, len(lines))
+		pages = append(pages, lines[:n])
+		lines = lines[n:]
+	}
+	return writePDF(pages)
+}
+
+// writePDF lays out pre-split pages. Headings (lines not indented) use
+// the bold font.
+func writePDF(pages [][]string) []byte {
+	var buf bytes.Buffer
+	var offsets []int
+	obj := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+	// Objects 1-4 are the catalog, page tree and fonts; each page then takes
+	// two objects (page + content stream) starting at 5.
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	obj("<< /Type /Catalog /Pages 2 0 R >>")
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, lines := range pages {
+		var content bytes.Buffer
+		fmt.Fprintf(&content, "BT\n%d TL\n%d %d Td\n", pdfLeading, pdfMargin, pdfHeight-pdfMargin)
+		for _, line := range lines {
+			font := "F1"
+			if line != "" && !strings.HasPrefix(line, " ") {
+				font = "F2"
+			}
+			fmt.Fprintf(&content, "/%s %d Tf (%s) Tj T*\n", font, pdfFontSize, pdfEscape(line))
+		}
+		content.WriteString("ET")
+
+		obj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pdfWidth, pdfHeight, 6+2*i))
+		obj(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return buf.Bytes()
+}
+
+// pdfEscape converts a line to a WinAnsi PDF string literal body, escaping
+// delimiters and replacing characters the encoding cannot show.
+func pdfEscape(s string) string {
+	var b strings.Builder
+	n := 0
+	for _, r := range s {
+		if n >= pdfMaxLineLen {
+			b.WriteString("...")
+			break
+		}
+		n++
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '–':
+			b.WriteString(`\226`)
+		case r == '—':
+			b.WriteString(`\227`)
+		case r == '→':
+			b.WriteString("->")
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, `\%03o`, r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
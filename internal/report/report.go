@@ -0,0 +1,225 @@
+// Package report builds the periodic summary of what Sentinel did: updates
+// applied, failures, rate limit incidents, approvals left waiting and hosts
+// offline. Reports render as HTML, PDF or plain text and are sent weekly
+// through the notification channels.
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/clock"
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+)
+
+// StaleAfter is how long a pending approval waits before the report flags it.
+const StaleAfter = 7 * 24 * time.Hour
+
+// Report is a summary of one period.
+type Report struct {
+	From         time.Time `json:"from"`
+	To           time.Time `json:"to"`
+	Applied      []Entry   `json:"applied"`
+	Failures     []Entry   `json:"failures"`
+	RateLimited  []Entry   `json:"rate_limited"`
+	StalePending []Pending `json:"stale_pending"`
+	OfflineHosts []Host    `json:"offline_hosts"`
+}
+
+// Entry is one history record in the report.
+type Entry struct {
+	Time      time.Time `json:"time"`
+	Container string    `json:"container"`
+	Host      string    `json:"host,omitempty"`
+	OldImage  string    `json:"old_image,omitempty"`
+	NewImage  string    `json:"new_image,omitempty"`
+	Outcome   string    `json:"outcome"`
+	Detail    string    `json:"detail,omitempty"`
+}
+
+// Pending is an update waiting for approval.
+type Pending struct {
+	Container  string    `json:"container"`
+	Host       string    `json:"host,omitempty"`
+	Target     string    `json:"target,omitempty"`
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// Host is a cluster host that is not connected.
+type Host struct {
+	Name   string    `json:"name"`
+	Since  time.Time `json:"since,omitempty"`
+	Detail string    `json:"detail,omitempty"`
+}
+
+// Source supplies the data a report is built from. Nil functions are
+// treated as having nothing to report.
+type Source struct {
+	History      func(since time.Time) ([]store.UpdateRecord, error)
+	Pending      func() []Pending
+	OfflineHosts func() []Host
+}
+
+// Builder assembles reports from a Source.
+type Builder struct {
+	src   Source
+	clock clock.Clock
+}
+
+// NewBuilder creates a report builder.
+func NewBuilder(src Source, clk clock.Clock) *Builder {
+	return &Builder{src: src, clock: clk}
+}
+
+// Build summarises the period ending now.
+func (b *Builder) Build(period time.Duration) (*Report, error) {
+	now := b.clock.Now()
+	r := &Report{From: now.Add(-period), To: now}
+
+	if b.src.History != nil {
+		records, err := b.src.History(r.From)
+		if err != nil {
+			return nil, fmt.Errorf("load history: %w", err)
+		}
+		for _, rec := range records {
+			e := Entry{
+				Time:      rec.Timestamp,
+				Container: rec.ContainerName,
+				Host:      rec.HostName,
+				OldImage:  rec.OldImage,
+				NewImage:  rec.NewImage,
+				Outcome:   rec.Outcome,
+				Detail:    rec.Error,
+			}
+			switch {
+			case rec.Outcome == "rate_limited" || rec.FailureClass == store.FailureRateLimited:
+				r.RateLimited = append(r.RateLimited, e)
+			case rec.Outcome == "success":
+				r.Applied = append(r.Applied, e)
+			case rec.Outcome == "failed" || rec.Outcome == "rollback" || rec.Outcome == "partial":
+				r.Failures = append(r.Failures, e)
+			}
+		}
+	}
+
+	if b.src.Pending != nil {
+		for _, p := range b.src.Pending() {
+			if now.Sub(p.DetectedAt) >= StaleAfter {
+				r.StalePending = append(r.StalePending, p)
+			}
+		}
+		sort.Slice(r.StalePending, func(i, j int) bool {
+			return r.StalePending[i].DetectedAt.Before(r.StalePending[j].DetectedAt)
+		})
+	}
+
+	if b.src.OfflineHosts != nil {
+		r.OfflineHosts = b.src.OfflineHosts()
+	}
+	return r, nil
+}
+
+// Title names the report by its period.
+func (r *Report) Title() string {
+	return fmt.Sprintf("Sentinel report %s – %s", r.From.Format("2 Jan 2006"), r.To.Format("2 Jan 2006"))
+}
+
+// Summary is the short form sent through notification channels.
+func (r *Report) Summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n", r.Title())
+	fmt.Fprintf(&b, "Updates applied: %d\n", len(r.Applied))
+	fmt.Fprintf(&b, "Failures: %d%s\n", len(r.Failures), entryNames(r.Failures))
+	fmt.Fprintf(&b, "Rate limit incidents: %d\n", len(r.RateLimited))
+	pending := make([]string, len(r.StalePending))
+	for i, p := range r.StalePending {
+		pending[i] = p.Container
+	}
+	fmt.Fprintf(&b, "Pending approvals older than 7 days: %d%s\n", len(r.StalePending), nameList(pending))
+	hosts := make([]string, len(r.OfflineHosts))
+	for i, h := range r.OfflineHosts {
+		hosts[i] = h.Name
+	}
+	fmt.Fprintf(&b, "Hosts offline: %d%s", len(r.OfflineHosts), nameList(hosts))
+	return b.String()
+}
+
+// Lines renders the full report as plain text, one line per row.
+func (r *Report) Lines() []string {
+	lines := []string{r.Title(), "Generated " + r.To.Format(time.RFC1123), ""}
+	section := func(title string, rows []string) {
+		lines = append(lines, fmt.Sprintf("%s (%d)", title, len(rows)))
+		if len(rows) == 0 {
+			lines = append(lines, "  None")
+		}
+		for _, row := range rows {
+			lines = append(lines, "  "+row)
+		}
+		lines = append(lines, "")
+	}
+	section("Updates applied", entryRows(r.Applied))
+	section("Failures", entryRows(r.Failures))
+	section("Rate limit incidents", entryRows(r.RateLimited))
+
+	pending := make([]string, len(r.StalePending))
+	for i, p := range r.StalePending {
+		pending[i] = fmt.Sprintf("%s  %s  waiting since %s", p.Container, p.Target, p.DetectedAt.Format("2006-01-02"))
+	}
+	section("Pending approvals older than 7 days", pending)
+
+	hosts := make([]string, len(r.OfflineHosts))
+	for i, h := range r.OfflineHosts {
+		hosts[i] = h.Name
+		if !h.Since.IsZero() {
+			hosts[i] += "  since " + h.Since.Format("2006-01-02 15:04")
+		}
+		if h.Detail != "" {
+			hosts[i] += "  " + h.Detail
+		}
+	}
+	section("Hosts offline", hosts)
+	return lines
+}
+
+func entryRows(entries []Entry) []string {
+	rows := make([]string, len(entries))
+	for i, e := range entries {
+		row := e.Time.Format("2006-01-02 15:04") + "  " + e.Container
+		if e.Host != "" {
+			row += " (" + e.Host + ")"
+		}
+		if e.NewImage != "" {
+			row += "  " + e.NewImage
+		}
+		if e.Detail != "" {
+			row += "  " + e.Detail
+		}
+		rows[i] = row
+	}
+	return rows
+}
+
+func entryNames(entries []Entry) string {
+	names := make([]string, 0, len(entries))
+	seen := make(map[string]bool)
+	for _, e := range entries {
+		if !seen[e.Container] {
+			seen[e.Container] = true
+			names = append(names, e.Container)
+		}
+	}
+	return nameList(names)
+}
+
+// nameList renders " (a, b, c)" for a non-empty list, capped at ten names.
+func nameList(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	if len(names) > 10 {
+		names = append(names[:10:10], fmt.Sprintf("+%d more", len(names)-10))
+	}
+	return " (" + strings.Join(names, ", ") + ")"
+}
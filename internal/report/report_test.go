@@ -0,0 +1,123 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+)
+
+type fixedClock struct{ now time.Time }
+
+func (c fixedClock) Now() time.Time                         { return c.now }
+func (c fixedClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (c fixedClock) Since(t time.Time) time.Duration        { return c.now.Sub(t) }
+
+type mapSettings map[string]string
+
+func (m mapSettings) LoadSetting(key string) (string, error) { return m[key], nil }
+
+var reportNow = time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC) // a Wednesday
+
+func testBuilder() *Builder {
+	return NewBuilder(Source{
+		History: func(since time.Time) ([]store.UpdateRecord, error) {
+			return []store.UpdateRecord{
+				{Timestamp: since.Add(time.Hour), ContainerName: "nginx", NewImage: "nginx:1.27", Outcome: "success"},
+				{Timestamp: since.Add(2 * time.Hour), ContainerName: "db", Outcome: "rollback", Error: "health check failed"},
+				{Timestamp: since.Add(3 * time.Hour), ContainerName: "app", Outcome: "check_failed", FailureClass: store.FailureRateLimited},
+				{Timestamp: since.Add(4 * time.Hour), ContainerName: "cache", Outcome: "identical"},
+			}, nil
+		},
+		Pending: func() []Pending {
+			return []Pending{
+				{Container: "old", Target: "2.0", DetectedAt: reportNow.Add(-10 * 24 * time.Hour)},
+				{Container: "fresh", DetectedAt: reportNow.Add(-time.Hour)},
+			}
+		},
+		OfflineHosts: func() []Host {
+			return []Host{{Name: "nas", Since: reportNow.Add(-time.Hour)}}
+		},
+	}, fixedClock{reportNow})
+}
+
+func TestBuildClassifiesRecords(t *testing.T) {
+	r, err := testBuilder().Build(Period)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Applied) != 1 || r.Applied[0].Container != "nginx" {
+		t.Errorf("Applied = %+v", r.Applied)
+	}
+	if len(r.Failures) != 1 || r.Failures[0].Container != "db" {
+		t.Errorf("Failures = %+v", r.Failures)
+	}
+	if len(r.RateLimited) != 1 || r.RateLimited[0].Container != "app" {
+		t.Errorf("RateLimited = %+v", r.RateLimited)
+	}
+	if len(r.StalePending) != 1 || r.StalePending[0].Container != "old" {
+		t.Errorf("StalePending = %+v", r.StalePending)
+	}
+	if len(r.OfflineHosts) != 1 {
+		t.Errorf("OfflineHosts = %+v", r.OfflineHosts)
+	}
+
+	summary := r.Summary()
+	for _, want := range []string{"Updates applied: 1", "Failures: 1 (db)", "older than 7 days: 1 (old)", "Hosts offline: 1 (nas)"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("summary missing %q:\n%s", want, summary)
+		}
+	}
+}
+
+func TestRenderHTMLAndPDF(t *testing.T) {
+	r, err := testBuilder().Build(Period)
+	if err != nil {
+		t.Fatal(err)
+	}
+	html, err := r.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(html, []byte("health check failed")) || !bytes.Contains(html, []byte("<h2>Hosts offline</h2>")) {
+		t.Error("HTML report missing expected content")
+	}
+
+	pdf := r.PDF()
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.4")) || !bytes.HasSuffix(pdf, []byte("%%EOF\n")) {
+		t.Error("PDF missing header or trailer")
+	}
+	if !bytes.Contains(pdf, []byte("(Failures \\(1\\)) Tj")) {
+		t.Error("PDF missing escaped section heading")
+	}
+}
+
+func TestPDFPaginates(t *testing.T) {
+	r := &Report{From: reportNow.Add(-Period), To: reportNow}
+	for i := 0; i < 120; i++ {
+		r.Applied = append(r.Applied, Entry{Time: reportNow, Container: "c", Outcome: "success"})
+	}
+	if got := bytes.Count(r.PDF(), []byte("/Type /Page ")); got != 3 {
+		t.Errorf("pages = %d, want 3", got)
+	}
+}
+
+func TestTimeUntilNext(t *testing.T) {
+	tests := []struct {
+		day, at string
+		want    time.Duration
+	}{
+		{"", "", 5*24*time.Hour - time.Hour},               // next Monday 09:00
+		{"wednesday", "12:00", 2 * time.Hour},              // later today
+		{"wednesday", "09:00", 7*24*time.Hour - time.Hour}, // passed today, so next week
+		{"Friday", "10:00", 2 * 24 * time.Hour},
+	}
+	for _, tt := range tests {
+		s := NewScheduler(nil, nil, mapSettings{SettingDay: tt.day, SettingTime: tt.at}, fixedClock{reportNow}, nil)
+		if got := s.timeUntilNext(); got != tt.want {
+			t.Errorf("day=%q time=%q: got %v, want %v", tt.day, tt.at, got, tt.want)
+		}
+	}
+}
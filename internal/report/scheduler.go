@@ -0,0 +1,146 @@
+package report
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/clock"
+	"github.com/Will-Luck/Docker-Sentinel/internal/notify"
+)
+
+// Settings keys for the weekly report (stored in the settings bucket).
+const (
+	SettingEnabled = "report_enabled" // "true" / "false" (default)
+	SettingDay     = "report_day"     // weekday name, default "monday"
+	SettingTime    = "report_time"    // "15:04", default "09:00"
+)
+
+// Period is the span a scheduled report covers.
+const Period = 7 * 24 * time.Hour
+
+// SettingsReader reads runtime settings.
+type SettingsReader interface {
+	LoadSetting(key string) (string, error)
+}
+
+// Scheduler sends the weekly report through the notification channels.
+type Scheduler struct {
+	builder  *Builder
+	notifier *notify.Multi
+	settings SettingsReader
+	clock    clock.Clock
+	log      *slog.Logger
+	resetCh  chan struct{}
+}
+
+// NewScheduler creates a weekly report scheduler.
+func NewScheduler(b *Builder, n *notify.Multi, s SettingsReader, clk clock.Clock, log *slog.Logger) *Scheduler {
+	return &Scheduler{
+		builder:  b,
+		notifier: n,
+		settings: s,
+		clock:    clk,
+		log:      log,
+		resetCh:  make(chan struct{}, 1),
+	}
+}
+
+// Run waits for each scheduled time and sends the report. Exits when ctx
+// is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	for {
+		wait := time.Minute
+		enabled := s.setting(SettingEnabled) == "true"
+		if enabled {
+			wait = s.timeUntilNext()
+			s.log.Info("weekly report scheduled", "delay", wait)
+		}
+		select {
+		case <-s.clock.After(wait):
+			if enabled {
+				if err := s.Send(ctx); err != nil {
+					s.log.Error("weekly report failed", "error", err)
+				}
+			}
+		case <-s.resetCh:
+			continue
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Reschedule makes Run pick up changed settings.
+func (s *Scheduler) Reschedule() {
+	select {
+	case s.resetCh <- struct{}{}:
+	default:
+	}
+}
+
+// Build assembles a report covering the period ending now.
+func (s *Scheduler) Build(period time.Duration) (*Report, error) {
+	return s.builder.Build(period)
+}
+
+// Send builds the report for the last week and sends its summary now.
+func (s *Scheduler) Send(ctx context.Context) error {
+	r, err := s.builder.Build(Period)
+	if err != nil {
+		return err
+	}
+	s.log.Info("sending weekly report", "applied", len(r.Applied), "failures", len(r.Failures))
+	s.notifier.Notify(ctx, notify.Event{
+		Type:      notify.EventReport,
+		Message:   r.Summary(),
+		Timestamp: s.clock.Now(),
+	})
+	return nil
+}
+
+// timeUntilNext returns the delay until the configured weekday and time.
+func (s *Scheduler) timeUntilNext() time.Duration {
+	now := s.clock.Now()
+	day := parseWeekday(s.setting(SettingDay))
+	hour, minute := 9, 0
+	if t, err := time.Parse("15:04", s.setting(SettingTime)); err == nil {
+		hour, minute = t.Hour(), t.Minute()
+	}
+
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
+	next = next.AddDate(0, 0, (int(day)-int(next.Weekday())+7)%7)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 7)
+	}
+	return next.Sub(now)
+}
+
+func (s *Scheduler) setting(key string) string {
+	if s.settings == nil {
+		return ""
+	}
+	v, _ := s.settings.LoadSetting(key)
+	return v
+}
+
+// parseWeekday maps a weekday name to time.Weekday, defaulting to Monday.
+func parseWeekday(name string) time.Weekday {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.EqualFold(name, d.String()) {
+			return d
+		}
+	}
+	return time.Monday
+}
+
+// ValidWeekday reports whether name is a weekday name.
+func ValidWeekday(name string) bool {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.EqualFold(name, d.String()) {
+			return true
+		}
+	}
+	return false
+}
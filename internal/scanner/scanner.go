@@ -20,6 +20,7 @@ type Logger interface {
 type Scanner struct {
 	trivyPath  string // path to trivy binary (default: "trivy")
 	severities string // comma-separated severity filter (e.g. "CRITICAL,HIGH")
+	server     string // Trivy server URL for client/server mode (empty = scan locally)
 	log        Logger
 }
 
@@ -36,6 +37,13 @@ func WithSeverities(sev string) Option {
 	return func(s *Scanner) { s.severities = sev }
 }
 
+// WithServer runs scans in client/server mode against a Trivy server, such
+// as a sidecar container, instead of downloading the vulnerability database
+// locally. The local trivy binary is still needed as the client.
+func WithServer(url string) Option {
+	return func(s *Scanner) { s.server = url }
+}
+
 // New creates a Scanner with the given options.
 func New(log Logger, opts ...Option) *Scanner {
 	s := &Scanner{
@@ -61,16 +69,8 @@ func (s *Scanner) Scan(ctx context.Context, imageRef string) (*ScanResult, error
 		return nil, fmt.Errorf("empty image reference")
 	}
 
-	args := []string{
-		"image",
-		"--format", "json",
-		"--severity", s.severities,
-		"--quiet",
-		imageRef,
-	}
-
 	var stdout, stderr bytes.Buffer
-	cmd := exec.CommandContext(ctx, s.trivyPath, args...) //nolint:gosec // trivyPath is operator-configured, not user input
+	cmd := exec.CommandContext(ctx, s.trivyPath, s.args(imageRef)...) //nolint:gosec // trivyPath is operator-configured, not user input
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
@@ -86,6 +86,20 @@ func (s *Scanner) Scan(ctx context.Context, imageRef string) (*ScanResult, error
 	return parseTrivyOutput(imageRef, stdout.Bytes())
 }
 
+// args builds the trivy command line for scanning imageRef.
+func (s *Scanner) args(imageRef string) []string {
+	args := []string{
+		"image",
+		"--format", "json",
+		"--severity", s.severities,
+		"--quiet",
+	}
+	if s.server != "" {
+		args = append(args, "--server", s.server)
+	}
+	return append(args, imageRef)
+}
+
 // parseTrivyOutput converts Trivy JSON output into a ScanResult.
 func parseTrivyOutput(imageRef string, data []byte) (*ScanResult, error) {
 	// Trivy JSON format has a top-level "Results" array, each with
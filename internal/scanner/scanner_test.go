@@ -1,6 +1,7 @@
 package scanner
 
 import (
+	"strings"
 	"testing"
 	"time"
 )
@@ -207,3 +208,48 @@ func TestAvailableNonExistent(t *testing.T) {
 		t.Error("expected not available")
 	}
 }
+
+func TestAtOrAbove(t *testing.T) {
+	result := &ScanResult{
+		ImageRef: "nginx:1.27",
+		Summary:  Summary{Critical: 1, High: 2, Low: 1, Total: 4},
+		Vulns: []Vulnerability{
+			{ID: "CVE-1", Severity: SeverityLow},
+			{ID: "CVE-2", Severity: SeverityHigh},
+			{ID: "CVE-3", Severity: SeverityCritical},
+			{ID: "CVE-4", Severity: SeverityHigh},
+		},
+	}
+
+	got := result.AtOrAbove(SeverityHigh, 0)
+	if len(got.Vulns) != 3 {
+		t.Fatalf("vulns = %d, want 3", len(got.Vulns))
+	}
+	if got.Summary.Total != 4 {
+		t.Errorf("summary total = %d, want the full count 4", got.Summary.Total)
+	}
+
+	got = result.AtOrAbove(SeverityHigh, 2)
+	if len(got.Vulns) != 2 || got.Vulns[0].ID != "CVE-2" || got.Vulns[1].ID != "CVE-3" {
+		t.Errorf("limited vulns = %+v, want CVE-2, CVE-3", got.Vulns)
+	}
+	if len(result.Vulns) != 4 {
+		t.Error("original result should be unchanged")
+	}
+}
+
+func TestArgsServerMode(t *testing.T) {
+	local := strings.Join(New(&testLogger{}).args("nginx:1.27"), " ")
+	if strings.Contains(local, "--server") {
+		t.Errorf("local args %q should not use --server", local)
+	}
+
+	s := New(&testLogger{}, WithServer("http://trivy:4954"))
+	args := s.args("nginx:1.27")
+	if got := strings.Join(args, " "); !strings.Contains(got, "--server http://trivy:4954") {
+		t.Errorf("args %q missing --server flag", got)
+	}
+	if args[len(args)-1] != "nginx:1.27" {
+		t.Errorf("image ref should be last, got %q", args[len(args)-1])
+	}
+}
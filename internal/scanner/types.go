@@ -97,3 +97,21 @@ func (r *ScanResult) ExceedsThreshold(threshold Severity) bool {
 	}
 	return false
 }
+
+// AtOrAbove returns a copy of the result keeping only vulnerabilities at or
+// above the threshold, at most limit of them (0 = no limit). The summary is
+// left untouched so the full counts are still reported.
+func (r *ScanResult) AtOrAbove(threshold Severity, limit int) *ScanResult {
+	threshPri := SeverityPriority(threshold)
+	out := &ScanResult{ImageRef: r.ImageRef, ScannedAt: r.ScannedAt, Summary: r.Summary}
+	for _, v := range r.Vulns {
+		if SeverityPriority(v.Severity) < threshPri {
+			continue
+		}
+		if limit > 0 && len(out.Vulns) == limit {
+			break
+		}
+		out.Vulns = append(out.Vulns, v)
+	}
+	return out
+}
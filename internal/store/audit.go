@@ -0,0 +1,167 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// AuditEntry records one mutating API request: who made it, from where,
+// what it asked for and, where the handler knows, the state before and
+// after the change.
+type AuditEntry struct {
+	Timestamp time.Time       `json:"timestamp"`
+	Actor     string          `json:"actor,omitempty"`      // username; empty for anonymous requests (e.g. login)
+	TokenName string          `json:"token_name,omitempty"` // API token used, if any
+	IP        string          `json:"ip"`
+	Method    string          `json:"method"`
+	Path      string          `json:"path"` // unversioned, e.g. /api/containers/web/policy
+	Status    int             `json:"status"`
+	Container string          `json:"container,omitempty"`
+	Request   json.RawMessage `json:"request,omitempty"` // JSON body with secrets redacted
+	Before    json.RawMessage `json:"before,omitempty"`
+	After     json.RawMessage `json:"after,omitempty"`
+	Cursor    string          `json:"cursor,omitempty"` // set by ListAudit, not stored
+}
+
+// AuditQuery filters ListAudit. Zero values match everything.
+type AuditQuery struct {
+	Actor     string    // exact username
+	Method    string    // HTTP method
+	Path      string    // path prefix
+	Container string    // exact container name
+	Since     time.Time // entries at or after
+	Failed    bool      // only requests answered with a 4xx or 5xx status
+	Before    string    // cursor from a previous page
+	Limit     int
+}
+
+// ErrInvalidAuditCursor is returned by ListAudit for a malformed cursor.
+var ErrInvalidAuditCursor = errors.New("invalid audit cursor")
+
+// auditKey orders audit entries by time. The timestamp is fixed width so
+// keys sort chronologically; seq breaks ties.
+func auditKey(ts time.Time, seq uint64) []byte {
+	return []byte(fmt.Sprintf("%s::%020d", ts.UTC().Format("2006-01-02T15:04:05.000000000Z"), seq))
+}
+
+// AppendAudit writes an entry to the audit bucket.
+func (s *Store) AppendAudit(e AuditEntry) error {
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now().UTC()
+	}
+	e.Cursor = ""
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshal audit entry: %w", err)
+	}
+	return s.update(bucketAudit, func(tx kvTx) error {
+		b, err := bucket(tx, bucketAudit)
+		if err != nil {
+			return err
+		}
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		return b.Put(auditKey(e.Timestamp, seq), data)
+	})
+}
+
+// ListAudit returns matching audit entries, newest first, and the cursor to
+// pass as q.Before for the next page ("" when there are no more).
+func (s *Store) ListAudit(q AuditQuery) ([]AuditEntry, string, error) {
+	var start []byte
+	if q.Before != "" {
+		if !strings.Contains(q.Before, "::") {
+			return nil, "", ErrInvalidAuditCursor
+		}
+		start = []byte(q.Before)
+	}
+	var sinceKey []byte
+	if !q.Since.IsZero() {
+		sinceKey = auditKey(q.Since, 0)
+	}
+
+	var entries []AuditEntry
+	var next string
+	err := s.view(bucketAudit, func(tx kvTx) error {
+		b, err := bucket(tx, bucketAudit)
+		if err != nil {
+			return err
+		}
+		c := b.Cursor()
+		var k, v []byte
+		if start == nil {
+			k, v = c.Last()
+		} else {
+			// Position on the cursor key (or the first key after it), then
+			// step back to the first entry strictly before it.
+			if k, _ = c.Seek(start); k == nil {
+				k, v = c.Last()
+			} else {
+				k, v = c.Prev()
+			}
+		}
+		for ; k != nil; k, v = c.Prev() {
+			if sinceKey != nil && bytes.Compare(k, sinceKey) < 0 {
+				break
+			}
+			var e AuditEntry
+			if err := json.Unmarshal(v, &e); err != nil {
+				slog.Warn("corrupt entry in audit bucket, skipping", "key", string(k), "error", err)
+				continue
+			}
+			if !q.matches(e) {
+				continue
+			}
+			if q.Limit > 0 && len(entries) == q.Limit {
+				next = entries[len(entries)-1].Cursor
+				break
+			}
+			e.Cursor = string(k)
+			entries = append(entries, e)
+		}
+		return nil
+	})
+	return entries, next, err
+}
+
+func (q AuditQuery) matches(e AuditEntry) bool {
+	return (q.Actor == "" || e.Actor == q.Actor) &&
+		(q.Method == "" || strings.EqualFold(e.Method, q.Method)) &&
+		(q.Path == "" || strings.HasPrefix(e.Path, q.Path)) &&
+		(q.Container == "" || e.Container == q.Container) &&
+		(!q.Failed || e.Status >= 400)
+}
+
+// PruneAudit deletes entries recorded before cutoff and returns how many
+// were removed.
+func (s *Store) PruneAudit(cutoff time.Time) (int, error) {
+	limit := auditKey(cutoff, 0)
+	var removed int
+	err := s.update(bucketAudit, func(tx kvTx) error {
+		b, err := bucket(tx, bucketAudit)
+		if err != nil {
+			return err
+		}
+		// Collect keys first — mutating during iteration is undefined behaviour in BoltDB.
+		var stale [][]byte
+		c := b.Cursor()
+		for k, _ := c.First(); k != nil && bytes.Compare(k, limit) < 0; k, _ = c.Next() {
+			stale = append(stale, append([]byte(nil), k...))
+		}
+		for _, k := range stale {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		removed = len(stale)
+		return nil
+	})
+	return removed, err
+}
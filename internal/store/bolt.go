@@ -2,11 +2,13 @@ package store
 
 import (
 	"bytes"
+	"crypto/cipher"
 	"encoding/json"
 	"fmt"
 	"log/slog"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	bolt "go.etcd.io/bbolt"
@@ -30,6 +32,24 @@ var (
 	bucketReleaseSources   = []byte("release_sources")
 	bucketNotifyTemplates  = []byte("notification_templates")
 	bucketPortConfig       = []byte("port_config")
+	bucketUnmanaged        = []byte("unmanaged")
+	bucketUpdateJournal    = []byte("update_journal")
+	bucketVersions         = []byte("versions")
+	bucketValidation       = []byte("validation_overrides")
+	bucketPreconditions    = []byte("update_preconditions")
+	bucketTimedPins        = []byte("timed_pins")
+	bucketTickets          = []byte("tickets")
+	bucketRetry            = []byte("update_retries")
+	bucketSchedules        = []byte("container_schedules")
+	bucketComposePaths     = []byte("compose_paths")
+	bucketTrustedKeys      = []byte("trusted_keys")
+	bucketDeployTokens     = []byte("deploy_tokens")
+	bucketDeployRequests   = []byte("deploy_requests")
+	bucketAudit            = []byte("audit")
+	bucketVerifyCache      = []byte("verify_cache")
+	bucketRebuildWatch     = []byte("rebuild_watch")
+	bucketRollouts         = []byte("rollouts")
+	bucketStackWindows     = []byte("stack_windows")
 
 	// Cluster / multi-host
 	bucketClusterHosts       = []byte("cluster_hosts")
@@ -37,6 +57,7 @@ var (
 	bucketClusterJournal     = []byte("cluster_journal")
 	bucketClusterConfigCache = []byte("cluster_config_cache")
 	bucketClusterRevoked     = []byte("cluster_revoked")
+	bucketClusterHostEvents  = []byte("cluster_host_events")
 	bucketDigestEquiv        = []byte("digest_equivalence")
 
 	// Multi-instance Portainer
@@ -51,6 +72,9 @@ const (
 	SettingClusterRemotePolicy     = "cluster_remote_policy"      // "auto" / "manual" / "pinned"
 	SettingClusterAutoUpdateAgents = "cluster_auto_update_agents" // "true" / "false"
 	SettingClusterAdvertise        = "cluster_advertise"          // comma-separated IPs/hostnames for TLS SANs
+	SettingClusterEnrollTTL        = "cluster_enroll_ttl"         // default enrollment token lifetime, e.g. "24h"
+	SettingClusterMinAgentVersion  = "cluster_min_agent_version"  // oldest supported agent release, e.g. "2.5.0"
+	SettingClusterOutdatedAgents   = "cluster_outdated_agents"    // "degrade" / "refuse"
 )
 
 // Portainer settings keys (stored in bucketSettings).
@@ -60,6 +84,11 @@ const (
 	SettingPortainerToken   = "portainer_token"
 )
 
+// Ticketing settings key (stored in bucketSettings).
+const (
+	SettingTicketing = "ticketing" // JSON-encoded ticket.Config
+)
+
 // NPM settings keys (stored in bucketSettings).
 const (
 	SettingNPMEnabled  = "npm_enabled"
@@ -85,6 +114,10 @@ const (
 const (
 	SettingWebhookEnabled = "webhook_enabled" // "true" / "false"
 	SettingWebhookSecret  = "webhook_secret"  // hex-encoded random secret
+
+	// SettingRegistryWebhookToken authenticates registry push webhooks. It
+	// is part of the URL because registries cannot send custom headers.
+	SettingRegistryWebhookToken = "registry_webhook_token" // hex-encoded random token
 )
 
 // Scanner (Trivy) settings keys (stored in bucketSettings).
@@ -92,14 +125,18 @@ const (
 	SettingScannerMode      = "scanner_mode"      // "disabled" / "pre-update" / "post-update"
 	SettingScannerThreshold = "scanner_threshold" // "CRITICAL" / "HIGH" / "MEDIUM" / "LOW"
 	SettingTrivyPath        = "trivy_path"        // path to trivy binary (default: "trivy")
+	SettingTrivyServer      = "trivy_server"      // Trivy server URL for client/server mode (empty = scan locally)
 )
 
 // Verifier (cosign) settings keys (stored in bucketSettings).
 const (
-	SettingVerifyMode    = "verify_mode"     // "disabled" / "warn" / "enforce"
-	SettingCosignPath    = "cosign_path"     // path to cosign binary (default: "cosign")
-	SettingCosignKeyless = "cosign_keyless"  // "true" / "false"
-	SettingCosignKeyPath = "cosign_key_path" // path to public key PEM
+	SettingVerifyMode     = "verify_mode"      // "disabled" / "warn" / "enforce"
+	SettingCosignPath     = "cosign_path"      // path to cosign binary (default: "cosign")
+	SettingCosignKeyless  = "cosign_keyless"   // "true" / "false"
+	SettingCosignKeyPath  = "cosign_key_path"  // path to public key PEM
+	SettingCosignIdentity = "cosign_identity"  // keyless: certificate identity regexp (empty = any)
+	SettingCosignIssuer   = "cosign_issuer"    // keyless: OIDC issuer regexp (empty = any)
+	SettingVerifyCacheTTL = "verify_cache_ttl" // how long a verified digest is trusted, e.g. "24h"; "0" disables the cache
 )
 
 // Notification retry settings keys (stored in bucketSettings).
@@ -116,23 +153,63 @@ type UpdateRecord struct {
 	OldDigest     string        `json:"old_digest"`
 	NewImage      string        `json:"new_image"`
 	NewDigest     string        `json:"new_digest"`
-	Outcome       string        `json:"outcome"` // "success", "failed", "rollback", "identical", "partial", "rate_limited", "check_failed", "dry_run", "pull_only", "scan_summary"
+	Outcome       string        `json:"outcome"` // "success", "failed", "rollback", "identical", "partial", "rate_limited", "check_failed", "dry_run", "pull_only", "deferred", "skipped", "rejected", "ignored", "scan_summary"
 	Duration      time.Duration `json:"duration"`
 	Error         string        `json:"error,omitempty"`
 	Type          string        `json:"type,omitempty"`      // "container" (default) or "service"
 	HostID        string        `json:"host_id,omitempty"`   // cluster host (empty = local)
 	HostName      string        `json:"host_name,omitempty"` // cluster host name (empty = local)
+
+	// FailureClass is a structured cause for non-success outcomes (see Failure* constants).
+	FailureClass string `json:"failure_class,omitempty"`
+	// Stages holds per-phase timings ("pull", "stop", "create", "validate", "finalise").
+	Stages map[string]time.Duration `json:"stages,omitempty"`
+	// Preconditions holds the wait-for condition results checked before the update.
+	Preconditions []PreconditionResult `json:"preconditions,omitempty"`
+	// Hooks holds the lifecycle hooks run during the update, as reported by a
+	// remote agent.
+	Hooks []HookRun `json:"hooks,omitempty"`
+	// Comment is the note an operator gave when approving, rejecting or
+	// ignoring the update in the approval queue.
+	Comment string `json:"comment,omitempty"`
+	// ResourceDrift lists resource limits (memory, CPU, PIDs, restart policy)
+	// that differed on the updated container from the values captured before
+	// the update, as "field: before → after". Empty when all were preserved.
+	ResourceDrift []string `json:"resource_drift,omitempty"`
 }
 
-// Store wraps a BoltDB database for Sentinel persistence.
+// Failure classes recorded in UpdateRecord.FailureClass. The free-form
+// Outcome string says what happened; the class says why it went wrong.
+const (
+	FailurePullError           = "pull_error"           // image pull failed
+	FailureStartError          = "start_error"          // new container could not be created or started
+	FailureValidationTimeout   = "validation_timeout"   // healthcheck never settled within the poll timeout
+	FailureHealthFailed        = "health_failed"        // container exited, restarted or reported unhealthy
+	FailureFinaliseDestructive = "finalise_destructive" // finalise failed after the container was removed
+	FailureRateLimited         = "rate_limited"         // registry quota too low to check
+	FailurePrecondition        = "precondition"         // a wait-for condition was not met
+	FailureSignature           = "signature"            // the image signature could not be verified
+	FailureDiskSpace           = "disk_space"           // not enough free space on the Docker host to pull
+	FailureRegistryUnreachable = "registry_unreachable" // the registry could not be reached before pulling
+)
+
+// Store wraps the database used for Sentinel persistence: a BoltDB file by
+// default, or SQLite (see OpenDriver).
 type Store struct {
-	db *bolt.DB
+	// mu is held for reading by every transaction and for writing while
+	// Restore swaps the database file underneath.
+	mu     sync.RWMutex
+	db     kvDB
+	driver string
+	path   string
+	// aead encrypts secret values at rest; nil until EnableEncryption.
+	aead cipher.AEAD
 }
 
 // bucket retrieves a bucket by name, returning an error instead of nil.
 // All bucket access should go through this helper to prevent nil-pointer
 // panics if the database is corrupted or a bucket is unexpectedly missing.
-func bucket(tx *bolt.Tx, name []byte) (*bolt.Bucket, error) {
+func bucket(tx kvTx, name []byte) (kvBucket, error) {
 	b := tx.Bucket(name)
 	if b == nil {
 		return nil, fmt.Errorf("bucket %q not found", string(name))
@@ -143,42 +220,50 @@ func bucket(tx *bolt.Tx, name []byte) (*bolt.Bucket, error) {
 // Open creates or opens a BoltDB database at the given path and ensures
 // all required buckets exist.
 func Open(path string) (*Store, error) {
-	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
-	if err != nil {
-		return nil, fmt.Errorf("open bolt db: %w", err)
-	}
+	return OpenDriver(DriverBolt, path)
+}
 
-	err = db.Update(func(tx *bolt.Tx) error {
-		for _, b := range [][]byte{bucketSnapshots, bucketHistory, bucketState, bucketQueue, bucketPolicies, bucketLogs, bucketSettings, bucketNotifyState, bucketNotifyPrefs, bucketIgnoredVersions, bucketRegistryCreds, bucketRateLimits, bucketGHCRAlternatives, bucketHooks, bucketReleaseSources, bucketNotifyTemplates, bucketPortConfig, bucketClusterHosts, bucketClusterTokens, bucketClusterJournal, bucketClusterConfigCache, bucketClusterRevoked, bucketDigestEquiv, bucketPortainerInstances} {
-			if _, err := tx.CreateBucketIfNotExists(b); err != nil {
-				return err
-			}
-		}
-		return nil
-	})
+// OpenDriver is like Open but stores the data with the given driver
+// (DriverBolt or DriverSQLite).
+func OpenDriver(driver, path string) (*Store, error) {
+	if driver == "" {
+		driver = DriverBolt
+	}
+	db, err := openKV(driver, path)
 	if err != nil {
-		db.Close()
-		return nil, fmt.Errorf("create buckets: %w", err)
+		return nil, err
 	}
+	return &Store{db: db, driver: driver, path: path}, nil
+}
 
-	return &Store{db: db}, nil
+// Driver returns the storage driver the store was opened with.
+func (s *Store) Driver() string {
+	return s.driver
 }
 
-// Close closes the underlying BoltDB.
+// Close closes the underlying database.
 func (s *Store) Close() error {
-	return s.db.Close()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.db.close()
 }
 
-// DB returns the underlying BoltDB instance.
-// Used by the backup package for hot backups via Tx.WriteTo().
+// DB returns the underlying BoltDB instance, or nil when the store uses
+// another driver. The instance changes when the database is restored;
+// prefer the store's own methods.
 func (s *Store) DB() *bolt.DB {
-	return s.db
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if b, ok := s.db.(*boltDB); ok {
+		return b.db
+	}
+	return nil
 }
 
 // SaveSnapshot stores a container inspect JSON snapshot.
 // Key format: "{name}::{RFC3339Nano}" for chronological ordering.
 func (s *Store) SaveSnapshot(name string, data []byte) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketSnapshots, func(tx kvTx) error {
 		b, err := bucket(tx, bucketSnapshots)
 		if err != nil {
 			return err
@@ -194,7 +279,7 @@ func (s *Store) GetLatestSnapshot(name string) ([]byte, error) {
 	var data []byte
 	prefix := []byte(name + "::")
 
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketSnapshots, func(tx kvTx) error {
 		b, err := bucket(tx, bucketSnapshots)
 		if err != nil {
 			return err
@@ -234,7 +319,7 @@ func (s *Store) RecordUpdate(rec UpdateRecord) error {
 	if err != nil {
 		return fmt.Errorf("marshal update record: %w", err)
 	}
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketHistory, func(tx kvTx) error {
 		b, err := bucket(tx, bucketHistory)
 		if err != nil {
 			return err
@@ -250,7 +335,7 @@ func (s *Store) RecordUpdate(rec UpdateRecord) error {
 func (s *Store) ListHistory(limit int, before string) ([]UpdateRecord, error) {
 	var records []UpdateRecord
 
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketHistory, func(tx kvTx) error {
 		b, err := bucket(tx, bucketHistory)
 		if err != nil {
 			return err
@@ -278,9 +363,34 @@ func (s *Store) ListHistory(limit int, before string) ([]UpdateRecord, error) {
 	return records, err
 }
 
+// ListHistorySince returns every update record at or after since, oldest first.
+func (s *Store) ListHistorySince(since time.Time) ([]UpdateRecord, error) {
+	var records []UpdateRecord
+	err := s.view(bucketHistory, func(tx kvTx) error {
+		b, err := bucket(tx, bucketHistory)
+		if err != nil {
+			return err
+		}
+		c := b.Cursor()
+		for k, v := c.Seek([]byte(since.UTC().Format(time.RFC3339Nano))); k != nil; k, v = c.Next() {
+			var rec UpdateRecord
+			if err := json.Unmarshal(v, &rec); err != nil {
+				slog.Warn("corrupt entry in history bucket, skipping", "key", string(k), "error", err)
+				continue
+			}
+			if rec.Timestamp.Before(since) {
+				continue
+			}
+			records = append(records, rec)
+		}
+		return nil
+	})
+	return records, err
+}
+
 // SetMaintenance marks a container as in or out of a maintenance window.
 func (s *Store) SetMaintenance(name string, active bool) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketState, func(tx kvTx) error {
 		b, err := bucket(tx, bucketState)
 		if err != nil {
 			return err
@@ -296,7 +406,7 @@ func (s *Store) SetMaintenance(name string, active bool) error {
 // GetMaintenance returns whether a container is currently in maintenance.
 func (s *Store) GetMaintenance(name string) (bool, error) {
 	var active bool
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketState, func(tx kvTx) error {
 		b, err := bucket(tx, bucketState)
 		if err != nil {
 			return err
@@ -308,9 +418,31 @@ func (s *Store) GetMaintenance(name string) (bool, error) {
 	return active, err
 }
 
+// ListMaintenance returns the names of all containers currently flagged as
+// in maintenance. Entries that survive a restart belong to updates that were
+// interrupted before they could clear the flag.
+func (s *Store) ListMaintenance() ([]string, error) {
+	var names []string
+	prefix := []byte("maintenance::")
+	err := s.view(bucketState, func(tx kvTx) error {
+		b, err := bucket(tx, bucketState)
+		if err != nil {
+			return err
+		}
+		c := b.Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			if string(v) == "true" {
+				names = append(names, string(k[len(prefix):]))
+			}
+		}
+		return nil
+	})
+	return names, err
+}
+
 // SavePendingQueue persists the pending update queue as JSON.
 func (s *Store) SavePendingQueue(data []byte) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketQueue, func(tx kvTx) error {
 		b, err := bucket(tx, bucketQueue)
 		if err != nil {
 			return err
@@ -323,7 +455,7 @@ func (s *Store) SavePendingQueue(data []byte) error {
 // Returns nil, nil if no queue is saved.
 func (s *Store) LoadPendingQueue() ([]byte, error) {
 	var data []byte
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketQueue, func(tx kvTx) error {
 		b, err := bucket(tx, bucketQueue)
 		if err != nil {
 			return err
@@ -349,7 +481,7 @@ func (s *Store) ListSnapshots(name string) ([]SnapshotEntry, error) {
 	var entries []SnapshotEntry
 	prefix := []byte(name + "::")
 
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketSnapshots, func(tx kvTx) error {
 		b, err := bucket(tx, bucketSnapshots)
 		if err != nil {
 			return err
@@ -389,7 +521,7 @@ func (s *Store) ListSnapshots(name string) ([]SnapshotEntry, error) {
 // ListAllHistory returns all update records, newest first.
 func (s *Store) ListAllHistory() ([]UpdateRecord, error) {
 	var records []UpdateRecord
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketHistory, func(tx kvTx) error {
 		b, err := bucket(tx, bucketHistory)
 		if err != nil {
 			return err
@@ -408,12 +540,90 @@ func (s *Store) ListAllHistory() ([]UpdateRecord, error) {
 	return records, err
 }
 
+// historyBatchSize is how many records ForEachHistory reads per transaction.
+const historyBatchSize = 500
+
+// ForEachHistory calls fn for every update record with a timestamp in
+// [from, to), newest first; a zero from or to leaves that end open. Records
+// are read in batches, each in its own transaction, so a slow fn (such as a
+// client downloading an export) does not hold the database open. An error
+// from fn stops the iteration and is returned.
+func (s *Store) ForEachHistory(from, to time.Time, fn func(UpdateRecord) error) error {
+	// Keys are RFC3339Nano, which drops trailing zeros, so key order can
+	// stray from time order within a second. Keys of the same second are
+	// adjacent, so batches only end between seconds and are sorted by
+	// timestamp, and the range is sought a second wide and then filtered.
+	var last []byte
+	for {
+		var batch []UpdateRecord
+		done := true
+		err := s.view(bucketHistory, func(tx kvTx) error {
+			b, err := bucket(tx, bucketHistory)
+			if err != nil {
+				return err
+			}
+			c := b.Cursor()
+			var k, v []byte
+			switch {
+			case last != nil:
+				c.Seek(last)
+				k, v = c.Prev()
+			case !to.IsZero():
+				if k, _ = c.Seek([]byte(to.Add(time.Second).UTC().Format(time.RFC3339Nano))); k == nil {
+					k, v = c.Last()
+				} else {
+					k, v = c.Prev()
+				}
+			default:
+				k, v = c.Last()
+			}
+			for ; k != nil; k, v = c.Prev() {
+				if len(batch) >= historyBatchSize && !bytes.Equal(keySecond(k), keySecond(last)) {
+					done = false
+					return nil
+				}
+				last = append(last[:0], k...)
+				var rec UpdateRecord
+				if err := json.Unmarshal(v, &rec); err != nil {
+					slog.Warn("corrupt entry in history bucket, skipping", "key", string(k), "error", err)
+					continue
+				}
+				if !from.IsZero() && rec.Timestamp.Before(from.Add(-time.Second)) {
+					return nil
+				}
+				if (!from.IsZero() && rec.Timestamp.Before(from)) || (!to.IsZero() && !rec.Timestamp.Before(to)) {
+					continue
+				}
+				batch = append(batch, rec)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		sort.SliceStable(batch, func(i, j int) bool { return batch[i].Timestamp.After(batch[j].Timestamp) })
+		for _, rec := range batch {
+			if err := fn(rec); err != nil {
+				return err
+			}
+		}
+		if done {
+			return nil
+		}
+	}
+}
+
+// keySecond trims an RFC3339Nano key to its whole second.
+func keySecond(k []byte) []byte {
+	return k[:min(len(k), len("2006-01-02T15:04:05"))]
+}
+
 // ListHistoryByContainer returns update records filtered by container name,
 // newest first, up to limit.
 func (s *Store) ListHistoryByContainer(name string, limit int) ([]UpdateRecord, error) {
 	var records []UpdateRecord
 
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketHistory, func(tx kvTx) error {
 		b, err := bucket(tx, bucketHistory)
 		if err != nil {
 			return err
@@ -440,7 +650,7 @@ func (s *Store) ListHistoryByContainer(name string, limit int) ([]UpdateRecord,
 // Returns ("", false) if no override exists.
 func (s *Store) GetPolicyOverride(name string) (string, bool) {
 	var policy string
-	_ = s.db.View(func(tx *bolt.Tx) error {
+	_ = s.view(bucketPolicies, func(tx kvTx) error {
 		b, err := bucket(tx, bucketPolicies)
 		if err != nil {
 			return err
@@ -456,7 +666,7 @@ func (s *Store) GetPolicyOverride(name string) (string, bool) {
 
 // SetPolicyOverride stores a policy override for a container in BoltDB.
 func (s *Store) SetPolicyOverride(name, policy string) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketPolicies, func(tx kvTx) error {
 		b, err := bucket(tx, bucketPolicies)
 		if err != nil {
 			return err
@@ -467,7 +677,7 @@ func (s *Store) SetPolicyOverride(name, policy string) error {
 
 // DeletePolicyOverride removes the policy override for a container.
 func (s *Store) DeletePolicyOverride(name string) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketPolicies, func(tx kvTx) error {
 		b, err := bucket(tx, bucketPolicies)
 		if err != nil {
 			return err
@@ -479,7 +689,7 @@ func (s *Store) DeletePolicyOverride(name string) error {
 // AllPolicyOverrides returns all stored policy overrides.
 func (s *Store) AllPolicyOverrides() map[string]string {
 	result := make(map[string]string)
-	_ = s.db.View(func(tx *bolt.Tx) error {
+	_ = s.view(bucketPolicies, func(tx kvTx) error {
 		b, err := bucket(tx, bucketPolicies)
 		if err != nil {
 			return err
@@ -511,7 +721,7 @@ func (s *Store) AppendLog(entry LogEntry) error {
 	if err != nil {
 		return fmt.Errorf("marshal log entry: %w", err)
 	}
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketLogs, func(tx kvTx) error {
 		b, err := bucket(tx, bucketLogs)
 		if err != nil {
 			return err
@@ -524,7 +734,7 @@ func (s *Store) AppendLog(entry LogEntry) error {
 // ListLogs returns the most recent log entries, newest first, up to limit.
 func (s *Store) ListLogs(limit int) ([]LogEntry, error) {
 	var entries []LogEntry
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketLogs, func(tx kvTx) error {
 		b, err := bucket(tx, bucketLogs)
 		if err != nil {
 			return err
@@ -545,7 +755,7 @@ func (s *Store) ListLogs(limit int) ([]LogEntry, error) {
 
 // DeleteOldSnapshots removes all but the N most recent snapshots for a container.
 func (s *Store) DeleteOldSnapshots(name string, keep int) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketSnapshots, func(tx kvTx) error {
 		b, err := bucket(tx, bucketSnapshots)
 		if err != nil {
 			return err
@@ -578,20 +788,25 @@ func (s *Store) DeleteOldSnapshots(name string, keep int) error {
 }
 
 // SaveSetting stores a setting key-value pair in the settings bucket.
+// Secret settings are encrypted when encryption at rest is enabled.
 func (s *Store) SaveSetting(key, value string) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketSettings, func(tx kvTx) error {
 		b, err := bucket(tx, bucketSettings)
 		if err != nil {
 			return err
 		}
-		return b.Put([]byte(key), []byte(value))
+		v, err := s.sealSetting(key, []byte(value))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(key), v)
 	})
 }
 
 // DeleteSetting removes a setting key from the settings bucket.
 // Deleting a non-existent key is a silent no-op (BoltDB behaviour).
 func (s *Store) DeleteSetting(key string) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketSettings, func(tx kvTx) error {
 		b, err := bucket(tx, bucketSettings)
 		if err != nil {
 			return err
@@ -604,12 +819,15 @@ func (s *Store) DeleteSetting(key string) error {
 // Returns empty string if the key doesn't exist.
 func (s *Store) LoadSetting(key string) (string, error) {
 	var val string
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketSettings, func(tx kvTx) error {
 		b, err := bucket(tx, bucketSettings)
 		if err != nil {
 			return err
 		}
-		v := b.Get([]byte(key))
+		v, err := s.unseal(b.Get([]byte(key)))
+		if err != nil {
+			return fmt.Errorf("setting %s: %w", key, err)
+		}
 		if v != nil {
 			val = string(v)
 		}
@@ -632,9 +850,11 @@ func (s *Store) SetVersionScope(scope string) error {
 // GetAllSettings returns all key-value pairs from the settings bucket.
 // Keys used internally (notification_config, notification_channels) are excluded
 // to avoid leaking large JSON blobs — only simple string settings are returned.
+// Encrypted values that cannot be decrypted are left out; LoadSetting reports
+// the error for an individual key.
 func (s *Store) GetAllSettings() (map[string]string, error) {
 	result := make(map[string]string)
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketSettings, func(tx kvTx) error {
 		b, err := bucket(tx, bucketSettings)
 		if err != nil {
 			return err
@@ -642,10 +862,14 @@ func (s *Store) GetAllSettings() (map[string]string, error) {
 		return b.ForEach(func(k, v []byte) error {
 			key := string(k)
 			// Skip internal compound keys that store JSON blobs.
-			if key == "notification_config" || key == "notification_channels" {
+			if key == "notification_config" || key == "notification_channels" || key == encryptionCheckKey {
 				return nil
 			}
-			result[key] = string(v)
+			plain, err := s.unseal(v)
+			if err != nil {
+				return nil
+			}
+			result[key] = string(plain)
 			return nil
 		})
 	})
@@ -653,30 +877,30 @@ func (s *Store) GetAllSettings() (map[string]string, error) {
 }
 
 // CountHistory returns the number of entries in the history bucket.
-// Uses bucket stats for O(1) counting.
+// Uses bucket stats for O(1) counting on BoltDB.
 func (s *Store) CountHistory() (int, error) {
 	var count int
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketHistory, func(tx kvTx) error {
 		b, err := bucket(tx, bucketHistory)
 		if err != nil {
 			return err
 		}
-		count = b.Stats().KeyN
+		count = b.KeyN()
 		return nil
 	})
 	return count, err
 }
 
 // CountSnapshots returns the number of entries in the snapshots bucket.
-// Uses bucket stats for O(1) counting.
+// Uses bucket stats for O(1) counting on BoltDB.
 func (s *Store) CountSnapshots() (int, error) {
 	var count int
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketSnapshots, func(tx kvTx) error {
 		b, err := bucket(tx, bucketSnapshots)
 		if err != nil {
 			return err
 		}
-		count = b.Stats().KeyN
+		count = b.KeyN()
 		return nil
 	})
 	return count, err
@@ -686,7 +910,7 @@ func (s *Store) CountSnapshots() (int, error) {
 // Returns zero time and nil error if never scanned.
 func (s *Store) GetLastContainerScan(name string) (time.Time, error) {
 	var t time.Time
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketSettings, func(tx kvTx) error {
 		b, err := bucket(tx, bucketSettings)
 		if err != nil {
 			return err
@@ -706,7 +930,7 @@ func (s *Store) SetLastContainerScan(name string, t time.Time) error {
 	if err != nil {
 		return err
 	}
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketSettings, func(tx kvTx) error {
 		b, err := bucket(tx, bucketSettings)
 		if err != nil {
 			return err
@@ -731,7 +955,7 @@ func ScopedKey(hostID, name string) string {
 
 // SaveClusterHost persists a host registration to the cluster_hosts bucket.
 func (s *Store) SaveClusterHost(id string, data []byte) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketClusterHosts, func(tx kvTx) error {
 		b, err := bucket(tx, bucketClusterHosts)
 		if err != nil {
 			return err
@@ -743,7 +967,7 @@ func (s *Store) SaveClusterHost(id string, data []byte) error {
 // GetClusterHost retrieves a host registration by ID.
 func (s *Store) GetClusterHost(id string) ([]byte, error) {
 	var data []byte
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketClusterHosts, func(tx kvTx) error {
 		b, err := bucket(tx, bucketClusterHosts)
 		if err != nil {
 			return err
@@ -761,7 +985,7 @@ func (s *Store) GetClusterHost(id string) ([]byte, error) {
 // ListClusterHosts returns all registered hosts.
 func (s *Store) ListClusterHosts() (map[string][]byte, error) {
 	result := make(map[string][]byte)
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketClusterHosts, func(tx kvTx) error {
 		b, err := bucket(tx, bucketClusterHosts)
 		if err != nil {
 			return err
@@ -778,7 +1002,7 @@ func (s *Store) ListClusterHosts() (map[string][]byte, error) {
 
 // DeleteClusterHost removes a host registration.
 func (s *Store) DeleteClusterHost(id string) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketClusterHosts, func(tx kvTx) error {
 		b, err := bucket(tx, bucketClusterHosts)
 		if err != nil {
 			return err
@@ -793,7 +1017,7 @@ func (s *Store) DeleteClusterHost(id string) error {
 
 // SaveEnrollToken stores an enrollment token (hashed).
 func (s *Store) SaveEnrollToken(id string, data []byte) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketClusterTokens, func(tx kvTx) error {
 		b, err := bucket(tx, bucketClusterTokens)
 		if err != nil {
 			return err
@@ -805,7 +1029,7 @@ func (s *Store) SaveEnrollToken(id string, data []byte) error {
 // GetEnrollToken retrieves an enrollment token by ID.
 func (s *Store) GetEnrollToken(id string) ([]byte, error) {
 	var data []byte
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketClusterTokens, func(tx kvTx) error {
 		b, err := bucket(tx, bucketClusterTokens)
 		if err != nil {
 			return err
@@ -820,9 +1044,27 @@ func (s *Store) GetEnrollToken(id string) ([]byte, error) {
 	return data, err
 }
 
+// ListEnrollTokens returns all stored enrollment tokens keyed by ID.
+func (s *Store) ListEnrollTokens() (map[string][]byte, error) {
+	result := make(map[string][]byte)
+	err := s.view(bucketClusterTokens, func(tx kvTx) error {
+		b, err := bucket(tx, bucketClusterTokens)
+		if err != nil {
+			return err
+		}
+		return b.ForEach(func(k, v []byte) error {
+			data := make([]byte, len(v))
+			copy(data, v)
+			result[string(k)] = data
+			return nil
+		})
+	})
+	return result, err
+}
+
 // DeleteEnrollToken removes a used or expired token.
 func (s *Store) DeleteEnrollToken(id string) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketClusterTokens, func(tx kvTx) error {
 		b, err := bucket(tx, bucketClusterTokens)
 		if err != nil {
 			return err
@@ -837,7 +1079,7 @@ func (s *Store) DeleteEnrollToken(id string) error {
 
 // AddRevokedCert adds a certificate serial to the revocation list.
 func (s *Store) AddRevokedCert(serial string) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketClusterRevoked, func(tx kvTx) error {
 		b, err := bucket(tx, bucketClusterRevoked)
 		if err != nil {
 			return err
@@ -849,7 +1091,7 @@ func (s *Store) AddRevokedCert(serial string) error {
 // IsRevokedCert checks if a certificate serial is revoked.
 func (s *Store) IsRevokedCert(serial string) (bool, error) {
 	var revoked bool
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketClusterRevoked, func(tx kvTx) error {
 		b, err := bucket(tx, bucketClusterRevoked)
 		if err != nil {
 			return err
@@ -864,7 +1106,7 @@ func (s *Store) IsRevokedCert(serial string) (bool, error) {
 // ListRevokedCerts returns all revoked certificate serials with their revocation timestamps.
 func (s *Store) ListRevokedCerts() (map[string]string, error) {
 	result := make(map[string]string)
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketClusterRevoked, func(tx kvTx) error {
 		b, err := bucket(tx, bucketClusterRevoked)
 		if err != nil {
 			return err
@@ -883,7 +1125,7 @@ func (s *Store) ListRevokedCerts() (map[string]string, error) {
 
 // SaveClusterJournal stores an offline action journal entry.
 func (s *Store) SaveClusterJournal(id string, data []byte) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketClusterJournal, func(tx kvTx) error {
 		b, err := bucket(tx, bucketClusterJournal)
 		if err != nil {
 			return err
@@ -895,7 +1137,7 @@ func (s *Store) SaveClusterJournal(id string, data []byte) error {
 // ListClusterJournal returns all journal entries.
 func (s *Store) ListClusterJournal() (map[string][]byte, error) {
 	result := make(map[string][]byte)
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketClusterJournal, func(tx kvTx) error {
 		b, err := bucket(tx, bucketClusterJournal)
 		if err != nil {
 			return err
@@ -912,7 +1154,7 @@ func (s *Store) ListClusterJournal() (map[string][]byte, error) {
 
 // ClearClusterJournal removes all journal entries (after successful sync).
 func (s *Store) ClearClusterJournal() error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketClusterJournal, func(tx kvTx) error {
 		b, err := bucket(tx, bucketClusterJournal)
 		if err != nil {
 			return err
@@ -944,7 +1186,7 @@ func (s *Store) ClearClusterJournal() error {
 
 // SaveClusterConfigCache stores cached settings/policies for autonomous mode.
 func (s *Store) SaveClusterConfigCache(key string, data []byte) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketClusterConfigCache, func(tx kvTx) error {
 		b, err := bucket(tx, bucketClusterConfigCache)
 		if err != nil {
 			return err
@@ -956,7 +1198,7 @@ func (s *Store) SaveClusterConfigCache(key string, data []byte) error {
 // GetClusterConfigCache retrieves a cached config value.
 func (s *Store) GetClusterConfigCache(key string) ([]byte, error) {
 	var data []byte
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketClusterConfigCache, func(tx kvTx) error {
 		b, err := bucket(tx, bucketClusterConfigCache)
 		if err != nil {
 			return err
@@ -979,7 +1221,7 @@ func (s *Store) GetClusterConfigCache(key string) ([]byte, error) {
 // Returns empty string and nil error if no template is set.
 func (s *Store) GetNotifyTemplate(eventType string) (string, error) {
 	var tmpl string
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketNotifyTemplates, func(tx kvTx) error {
 		b, err := bucket(tx, bucketNotifyTemplates)
 		if err != nil {
 			return err
@@ -995,7 +1237,7 @@ func (s *Store) GetNotifyTemplate(eventType string) (string, error) {
 
 // SaveNotifyTemplate stores a custom template for an event type.
 func (s *Store) SaveNotifyTemplate(eventType, tmpl string) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketNotifyTemplates, func(tx kvTx) error {
 		b, err := bucket(tx, bucketNotifyTemplates)
 		if err != nil {
 			return err
@@ -1007,7 +1249,7 @@ func (s *Store) SaveNotifyTemplate(eventType, tmpl string) error {
 // DeleteNotifyTemplate removes the custom template for an event type,
 // reverting to the default format.
 func (s *Store) DeleteNotifyTemplate(eventType string) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketNotifyTemplates, func(tx kvTx) error {
 		b, err := bucket(tx, bucketNotifyTemplates)
 		if err != nil {
 			return err
@@ -1020,7 +1262,7 @@ func (s *Store) DeleteNotifyTemplate(eventType string) error {
 // as a map of event_type -> template string.
 func (s *Store) GetAllNotifyTemplates() (map[string]string, error) {
 	result := make(map[string]string)
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketNotifyTemplates, func(tx kvTx) error {
 		b, err := bucket(tx, bucketNotifyTemplates)
 		if err != nil {
 			return err
@@ -1040,7 +1282,7 @@ func (s *Store) GetAllNotifyTemplates() (map[string]string, error) {
 // CacheDigestEquivalence records that localDigest and remoteDigest refer to the same image.
 func (s *Store) CacheDigestEquivalence(localDigest, remoteDigest string) error {
 	key := extractHash(localDigest) + "|" + extractHash(remoteDigest)
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketDigestEquiv, func(tx kvTx) error {
 		b, err := bucket(tx, bucketDigestEquiv)
 		if err != nil {
 			return err
@@ -1053,7 +1295,7 @@ func (s *Store) CacheDigestEquivalence(localDigest, remoteDigest string) error {
 func (s *Store) CheckDigestEquivalence(localDigest, remoteDigest string) bool {
 	key := extractHash(localDigest) + "|" + extractHash(remoteDigest)
 	var found bool
-	_ = s.db.View(func(tx *bolt.Tx) error {
+	_ = s.view(bucketDigestEquiv, func(tx kvTx) error {
 		b, err := bucket(tx, bucketDigestEquiv)
 		if err != nil {
 			return err
@@ -1071,7 +1313,7 @@ func (s *Store) ClearDigestEquivalence(imageRef string) error {
 		// No sha256: prefix found — nothing meaningful to match.
 		return nil
 	}
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketDigestEquiv, func(tx kvTx) error {
 		b, err := bucket(tx, bucketDigestEquiv)
 		if err != nil {
 			return err
@@ -4,10 +4,9 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"time"
 
-	bolt "go.etcd.io/bbolt"
-
 	"github.com/Will-Luck/Docker-Sentinel/internal/auth"
 )
 
@@ -54,7 +53,7 @@ func (s *Store) CreateUser(user auth.User) error {
 	if err != nil {
 		return fmt.Errorf("marshal user: %w", err)
 	}
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketUsers, func(tx kvTx) error {
 		b, err := bucket(tx, bucketUsers)
 		if err != nil {
 			return err
@@ -79,7 +78,7 @@ func (s *Store) CreateFirstUser(user auth.User) error {
 	if err != nil {
 		return fmt.Errorf("marshal user: %w", err)
 	}
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketUsers, func(tx kvTx) error {
 		b, err := bucket(tx, bucketUsers)
 		if err != nil {
 			return err
@@ -107,7 +106,7 @@ func (s *Store) CreateFirstUser(user auth.User) error {
 // GetUser retrieves a user by ID.
 func (s *Store) GetUser(id string) (*auth.User, error) {
 	var user auth.User
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketUsers, func(tx kvTx) error {
 		b, err := bucket(tx, bucketUsers)
 		if err != nil {
 			return err
@@ -127,7 +126,7 @@ func (s *Store) GetUser(id string) (*auth.User, error) {
 // GetUserByUsername retrieves a user by their unique username.
 func (s *Store) GetUserByUsername(username string) (*auth.User, error) {
 	var user auth.User
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketUsers, func(tx kvTx) error {
 		b, err := bucket(tx, bucketUsers)
 		if err != nil {
 			return err
@@ -157,7 +156,7 @@ func (s *Store) UpdateUser(user auth.User) error {
 	if err != nil {
 		return fmt.Errorf("marshal user: %w", err)
 	}
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketUsers, func(tx kvTx) error {
 		b, err := bucket(tx, bucketUsers)
 		if err != nil {
 			return err
@@ -195,7 +194,7 @@ func (s *Store) UpdateUser(user auth.User) error {
 // DeleteUser removes a user, its username index, and all associated sessions
 // and API tokens in a single transaction.
 func (s *Store) DeleteUser(id string) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketUsers, func(tx kvTx) error {
 		ub, err := bucket(tx, bucketUsers)
 		if err != nil {
 			return err
@@ -301,7 +300,7 @@ func (s *Store) DeleteUser(id string) error {
 // ListUsers returns all users (excluding index keys).
 func (s *Store) ListUsers() ([]auth.User, error) {
 	var users []auth.User
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketUsers, func(tx kvTx) error {
 		b, err := bucket(tx, bucketUsers)
 		if err != nil {
 			return err
@@ -324,7 +323,7 @@ func (s *Store) ListUsers() ([]auth.User, error) {
 // UserCount returns the number of user records (excluding index keys).
 func (s *Store) UserCount() (int, error) {
 	var count int
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketUsers, func(tx kvTx) error {
 		b, err := bucket(tx, bucketUsers)
 		if err != nil {
 			return err
@@ -350,7 +349,7 @@ func (s *Store) CreateSession(session auth.Session) error {
 	if err != nil {
 		return fmt.Errorf("marshal session: %w", err)
 	}
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketSessions, func(tx kvTx) error {
 		b, err := bucket(tx, bucketSessions)
 		if err != nil {
 			return err
@@ -365,7 +364,7 @@ func (s *Store) CreateSession(session auth.Session) error {
 // GetSession retrieves a session by its token.
 func (s *Store) GetSession(token string) (*auth.Session, error) {
 	var session auth.Session
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketSessions, func(tx kvTx) error {
 		b, err := bucket(tx, bucketSessions)
 		if err != nil {
 			return err
@@ -384,7 +383,7 @@ func (s *Store) GetSession(token string) (*auth.Session, error) {
 
 // DeleteSession removes a session and its user index entry.
 func (s *Store) DeleteSession(token string) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketSessions, func(tx kvTx) error {
 		b, err := bucket(tx, bucketSessions)
 		if err != nil {
 			return err
@@ -411,7 +410,7 @@ func (s *Store) DeleteSession(token string) error {
 
 // DeleteSessionsForUser removes all sessions belonging to the given user.
 func (s *Store) DeleteSessionsForUser(userID string) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketSessions, func(tx kvTx) error {
 		b, err := bucket(tx, bucketSessions)
 		if err != nil {
 			return err
@@ -445,7 +444,7 @@ func (s *Store) DeleteSessionsForUser(userID string) error {
 // ListSessionsForUser returns all sessions belonging to the given user.
 func (s *Store) ListSessionsForUser(userID string) ([]auth.Session, error) {
 	var sessions []auth.Session
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketSessions, func(tx kvTx) error {
 		b, err := bucket(tx, bucketSessions)
 		if err != nil {
 			return err
@@ -470,13 +469,37 @@ func (s *Store) ListSessionsForUser(userID string) ([]auth.Session, error) {
 	return sessions, err
 }
 
+// ListSessions returns every session, for checks that span users such as
+// OIDC back-channel logout.
+func (s *Store) ListSessions() ([]auth.Session, error) {
+	var sessions []auth.Session
+	err := s.view(bucketSessions, func(tx kvTx) error {
+		b, err := bucket(tx, bucketSessions)
+		if err != nil {
+			return err
+		}
+		return b.ForEach(func(k, v []byte) error {
+			if isIndexKey(k) {
+				return nil
+			}
+			var session auth.Session
+			if err := json.Unmarshal(v, &session); err != nil {
+				return nil
+			}
+			sessions = append(sessions, session)
+			return nil
+		})
+	})
+	return sessions, err
+}
+
 // DeleteExpiredSessions removes all sessions whose ExpiresAt is in the past.
 // Returns the number of sessions deleted.
 func (s *Store) DeleteExpiredSessions() (int, error) {
 	var deleted int
 	now := time.Now().UTC()
 
-	err := s.db.Update(func(tx *bolt.Tx) error {
+	err := s.update(bucketSessions, func(tx kvTx) error {
 		b, err := bucket(tx, bucketSessions)
 		if err != nil {
 			return err
@@ -528,7 +551,7 @@ func (s *Store) DeleteExpiredSessions() (int, error) {
 // GetRole retrieves a role by ID.
 func (s *Store) GetRole(id string) (*auth.Role, error) {
 	var role auth.Role
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketRoles, func(tx kvTx) error {
 		b, err := bucket(tx, bucketRoles)
 		if err != nil {
 			return err
@@ -548,7 +571,7 @@ func (s *Store) GetRole(id string) (*auth.Role, error) {
 // ListRoles returns all stored roles.
 func (s *Store) ListRoles() ([]auth.Role, error) {
 	var roles []auth.Role
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketRoles, func(tx kvTx) error {
 		b, err := bucket(tx, bucketRoles)
 		if err != nil {
 			return err
@@ -566,16 +589,22 @@ func (s *Store) ListRoles() ([]auth.Role, error) {
 }
 
 // SeedBuiltinRoles inserts the built-in roles if they don't already exist.
+// Built-in roles that are already stored have their permission set refreshed
+// so permissions added in later releases reach existing installs.
 func (s *Store) SeedBuiltinRoles() error {
 	roles := auth.BuiltinRoles()
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketRoles, func(tx kvTx) error {
 		b, err := bucket(tx, bucketRoles)
 		if err != nil {
 			return err
 		}
 		for _, role := range roles {
 			if existing := b.Get([]byte(role.ID)); existing != nil {
-				continue // don't overwrite existing roles
+				var stored auth.Role
+				if err := json.Unmarshal(existing, &stored); err != nil || !stored.BuiltIn ||
+					slices.Equal(stored.Permissions, role.Permissions) {
+					continue // don't overwrite custom or up-to-date roles
+				}
 			}
 			data, err := json.Marshal(role)
 			if err != nil {
@@ -599,7 +628,7 @@ func (s *Store) CreateAPIToken(token auth.APIToken) error {
 	if err != nil {
 		return fmt.Errorf("marshal api token: %w", err)
 	}
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketAPITokens, func(tx kvTx) error {
 		b, err := bucket(tx, bucketAPITokens)
 		if err != nil {
 			return err
@@ -615,10 +644,70 @@ func (s *Store) CreateAPIToken(token auth.APIToken) error {
 	})
 }
 
+// tokenPermSplitKey marks that API tokens have been granted the permissions
+// split out of settings.modify.
+const tokenPermSplitKey = "token_perm_split_done"
+
+// MigrateTokenPermissions grants auth.SettingsModifySplit to every API token
+// scoped to settings.modify, so tokens keep cluster and self-update access
+// after those moved to their own permissions. Runs once; returns true when
+// the migration ran.
+func (s *Store) MigrateTokenPermissions() (bool, error) {
+	ran := false
+	err := s.update(bucketSettings, func(tx kvTx) error {
+		settings, err := bucket(tx, bucketSettings)
+		if err != nil {
+			return err
+		}
+		if settings.Get([]byte(tokenPermSplitKey)) != nil {
+			return nil
+		}
+		b, err := bucket(tx, bucketAPITokens)
+		if err != nil {
+			return err
+		}
+		updated := make(map[string][]byte)
+		err = b.ForEach(func(k, v []byte) error {
+			if bytes.HasPrefix(k, indexPrefix) {
+				return nil
+			}
+			var token auth.APIToken
+			if err := json.Unmarshal(v, &token); err != nil {
+				return nil
+			}
+			if !slices.Contains(token.Permissions, auth.PermSettingsModify) {
+				return nil
+			}
+			for _, p := range auth.SettingsModifySplit() {
+				if !slices.Contains(token.Permissions, p) {
+					token.Permissions = append(token.Permissions, p)
+				}
+			}
+			data, err := json.Marshal(token)
+			if err != nil {
+				return fmt.Errorf("marshal api token: %w", err)
+			}
+			updated[string(k)] = data
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for k, data := range updated {
+			if err := b.Put([]byte(k), data); err != nil {
+				return err
+			}
+		}
+		ran = true
+		return settings.Put([]byte(tokenPermSplitKey), []byte("true"))
+	})
+	return ran, err
+}
+
 // GetAPITokenByHash retrieves an API token by its SHA-256 hash.
 func (s *Store) GetAPITokenByHash(hash string) (*auth.APIToken, error) {
 	var token auth.APIToken
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketAPITokens, func(tx kvTx) error {
 		b, err := bucket(tx, bucketAPITokens)
 		if err != nil {
 			return err
@@ -643,7 +732,7 @@ func (s *Store) GetAPITokenByHash(hash string) (*auth.APIToken, error) {
 
 // DeleteAPIToken removes an API token and all its indexes.
 func (s *Store) DeleteAPIToken(id string) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketAPITokens, func(tx kvTx) error {
 		b, err := bucket(tx, bucketAPITokens)
 		if err != nil {
 			return err
@@ -672,7 +761,7 @@ func (s *Store) DeleteAPIToken(id string) error {
 
 // TouchAPIToken updates only the LastUsedAt timestamp for an API token.
 func (s *Store) TouchAPIToken(id string, t time.Time) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketAPITokens, func(tx kvTx) error {
 		b, err := bucket(tx, bucketAPITokens)
 		if err != nil {
 			return err
@@ -697,7 +786,7 @@ func (s *Store) TouchAPIToken(id string, t time.Time) error {
 // ListAPITokensForUser returns all API tokens belonging to the given user.
 func (s *Store) ListAPITokensForUser(userID string) ([]auth.APIToken, error) {
 	var tokens []auth.APIToken
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketAPITokens, func(tx kvTx) error {
 		b, err := bucket(tx, bucketAPITokens)
 		if err != nil {
 			return err
@@ -738,7 +827,7 @@ func (s *Store) SavePendingTOTP(token, userID string, expiresAt time.Time) error
 	if err != nil {
 		return fmt.Errorf("marshal pending TOTP: %w", err)
 	}
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketTOTPPending, func(tx kvTx) error {
 		b, err := bucket(tx, bucketTOTPPending)
 		if err != nil {
 			return err
@@ -751,7 +840,7 @@ func (s *Store) SavePendingTOTP(token, userID string, expiresAt time.Time) error
 // Returns empty string and nil error if the token is expired or not found.
 func (s *Store) GetPendingTOTP(token string) (string, error) {
 	var entry pendingTOTPEntry
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketTOTPPending, func(tx kvTx) error {
 		b, err := bucket(tx, bucketTOTPPending)
 		if err != nil {
 			return err
@@ -779,7 +868,7 @@ func (s *Store) GetPendingTOTP(token string) (string, error) {
 
 // DeletePendingTOTP removes a pending TOTP token.
 func (s *Store) DeletePendingTOTP(token string) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketTOTPPending, func(tx kvTx) error {
 		b, err := bucket(tx, bucketTOTPPending)
 		if err != nil {
 			return err
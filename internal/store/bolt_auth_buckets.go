@@ -1,7 +1,5 @@
 package store
 
-import bolt "go.etcd.io/bbolt"
-
 var (
 	bucketUsers         = []byte("users")
 	bucketSessions      = []byte("sessions")
@@ -14,7 +12,7 @@ var (
 // EnsureAuthBuckets creates the auth-related BoltDB buckets if they
 // do not already exist. Call this after Open() to initialise auth storage.
 func (s *Store) EnsureAuthBuckets() error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(nil, func(tx kvTx) error {
 		for _, b := range [][]byte{bucketUsers, bucketSessions, bucketRoles, bucketAPITokens, bucketWebAuthnCreds, bucketTOTPPending} {
 			if _, err := tx.CreateBucketIfNotExists(b); err != nil {
 				return err
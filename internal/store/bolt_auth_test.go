@@ -1,6 +1,7 @@
 package store
 
 import (
+	"encoding/json"
 	"errors"
 	"testing"
 	"time"
@@ -465,6 +466,33 @@ func TestListSessionsForUser(t *testing.T) {
 	}
 }
 
+func TestListSessions(t *testing.T) {
+	s := testAuthStore(t)
+
+	for _, sess := range []auth.Session{
+		{Token: "u1-s1", UserID: "u1", ExpiresAt: time.Now().UTC().Add(time.Hour), OIDCSubject: "sub-1", OIDCSessionID: "sid-1"},
+		{Token: "u2-s1", UserID: "u2", ExpiresAt: time.Now().UTC().Add(time.Hour)},
+	} {
+		if err := s.CreateSession(sess); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The user index keys share the bucket and must not show up as sessions.
+	sessions, err := s.ListSessions()
+	if err != nil {
+		t.Fatalf("ListSessions: %v", err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(sessions))
+	}
+	for _, sess := range sessions {
+		if sess.Token == "u1-s1" && (sess.OIDCSubject != "sub-1" || sess.OIDCSessionID != "sid-1") {
+			t.Errorf("OIDC fields not persisted: %+v", sess)
+		}
+	}
+}
+
 func TestDeleteExpiredSessions(t *testing.T) {
 	s := testAuthStore(t)
 
@@ -767,6 +795,67 @@ func TestSeedBuiltinRoles(t *testing.T) {
 	}
 }
 
+func TestSeedBuiltinRolesRefreshesPermissions(t *testing.T) {
+	s := testAuthStore(t)
+
+	// A built-in role stored by an older release lacks newer permissions.
+	stale := auth.Role{ID: auth.RoleAdminID, Name: "Admin", BuiltIn: true,
+		Permissions: []auth.Permission{auth.PermContainersView, auth.PermSettingsModify}}
+	data, _ := json.Marshal(stale)
+	if err := s.update(bucketRoles, func(tx kvTx) error {
+		return tx.Bucket(bucketRoles).Put([]byte(stale.ID), data)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.SeedBuiltinRoles(); err != nil {
+		t.Fatalf("SeedBuiltinRoles: %v", err)
+	}
+	admin, err := s.GetRole(auth.RoleAdminID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(admin.Permissions) != len(auth.AllPermissions()) {
+		t.Errorf("admin permissions = %v, want all", admin.Permissions)
+	}
+}
+
+func TestMigrateTokenPermissions(t *testing.T) {
+	s := testAuthStore(t)
+
+	scoped := auth.APIToken{ID: "t1", UserID: "u1", TokenHash: "h1", Permissions: []auth.Permission{auth.PermSettingsModify}}
+	narrow := auth.APIToken{ID: "t2", UserID: "u1", TokenHash: "h2", Permissions: []auth.Permission{auth.PermContainersView}}
+	for _, tok := range []auth.APIToken{scoped, narrow} {
+		if err := s.CreateAPIToken(tok); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ran, err := s.MigrateTokenPermissions()
+	if err != nil || !ran {
+		t.Fatalf("MigrateTokenPermissions = %v, %v; want true, nil", ran, err)
+	}
+	got, err := s.GetAPITokenByHash("h1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Permissions) != 1+len(auth.SettingsModifySplit()) {
+		t.Errorf("scoped token permissions = %v", got.Permissions)
+	}
+	got, err = s.GetAPITokenByHash("h2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Permissions) != 1 {
+		t.Errorf("narrow token permissions = %v, want unchanged", got.Permissions)
+	}
+
+	// Second run is a no-op.
+	if ran, err := s.MigrateTokenPermissions(); err != nil || ran {
+		t.Errorf("second MigrateTokenPermissions = %v, %v; want false, nil", ran, err)
+	}
+}
+
 func TestGetRoleNotFound(t *testing.T) {
 	s := testAuthStore(t)
 
@@ -0,0 +1,132 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Cluster host event types.
+const (
+	HostEventEnrolled     = "enrolled"
+	HostEventConnected    = "connected"
+	HostEventDisconnected = "disconnected"
+	HostEventCommand      = "command"
+	HostEventResult       = "result"
+	HostEventCertRenewal  = "cert_renewal"
+	HostEventVersion      = "version"
+)
+
+// MaxClusterHostEvents is how many events are kept per host; older ones are
+// dropped as new ones arrive.
+const MaxClusterHostEvents = 500
+
+// ClusterHostEvent is one entry in a cluster host's activity timeline.
+type ClusterHostEvent struct {
+	Timestamp time.Time `json:"timestamp"`
+	HostID    string    `json:"host_id"`
+	Type      string    `json:"type"` // one of the HostEvent* constants
+	Container string    `json:"container,omitempty"`
+	Message   string    `json:"message"`
+	Failed    bool      `json:"failed,omitempty"` // the command, result or renewal did not succeed
+}
+
+// hostEventKey orders events by time within a host. The timestamp is fixed
+// width so keys sort chronologically; seq breaks ties.
+func hostEventKey(hostID string, ts time.Time, seq uint64) []byte {
+	return []byte(fmt.Sprintf("%s::%s::%020d", hostID, ts.UTC().Format("2006-01-02T15:04:05.000000000Z"), seq))
+}
+
+// AppendClusterHostEvent records an event for a host and trims the host's
+// timeline to MaxClusterHostEvents.
+func (s *Store) AppendClusterHostEvent(e ClusterHostEvent) error {
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now().UTC()
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshal host event: %w", err)
+	}
+	return s.update(bucketClusterHostEvents, func(tx kvTx) error {
+		b, err := bucket(tx, bucketClusterHostEvents)
+		if err != nil {
+			return err
+		}
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		if err := b.Put(hostEventKey(e.HostID, e.Timestamp, seq), data); err != nil {
+			return err
+		}
+
+		// Collect keys first — mutating during iteration is undefined behaviour in BoltDB.
+		prefix := []byte(e.HostID + "::")
+		var keys [][]byte
+		c := b.Cursor()
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			keys = append(keys, append([]byte(nil), k...))
+		}
+		for i := 0; i < len(keys)-MaxClusterHostEvents; i++ {
+			if err := b.Delete(keys[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ListClusterHostEvents returns a host's most recent events, newest first,
+// up to limit.
+func (s *Store) ListClusterHostEvents(hostID string, limit int) ([]ClusterHostEvent, error) {
+	var events []ClusterHostEvent
+	prefix := []byte(hostID + "::")
+	err := s.view(bucketClusterHostEvents, func(tx kvTx) error {
+		b, err := bucket(tx, bucketClusterHostEvents)
+		if err != nil {
+			return err
+		}
+		c := b.Cursor()
+		// Seek past the host's last key, then walk backwards.
+		k, v := c.Seek(append(append([]byte(nil), prefix...), 0xff))
+		if k == nil {
+			k, v = c.Last()
+		} else {
+			k, v = c.Prev()
+		}
+		for ; k != nil && bytes.HasPrefix(k, prefix) && len(events) < limit; k, v = c.Prev() {
+			var e ClusterHostEvent
+			if err := json.Unmarshal(v, &e); err != nil {
+				slog.Warn("corrupt entry in cluster host events bucket, skipping", "key", string(k), "error", err)
+				continue
+			}
+			events = append(events, e)
+		}
+		return nil
+	})
+	return events, err
+}
+
+// DeleteClusterHostEvents removes a host's whole timeline.
+func (s *Store) DeleteClusterHostEvents(hostID string) error {
+	prefix := []byte(hostID + "::")
+	return s.update(bucketClusterHostEvents, func(tx kvTx) error {
+		b, err := bucket(tx, bucketClusterHostEvents)
+		if err != nil {
+			return err
+		}
+		var keys [][]byte
+		c := b.Cursor()
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			keys = append(keys, append([]byte(nil), k...))
+		}
+		for _, k := range keys {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -1,6 +1,10 @@
 package store
 
-import "testing"
+import (
+	"fmt"
+	"testing"
+	"time"
+)
 
 // ---------------------------------------------------------------------------
 // Cluster Hosts
@@ -116,6 +120,24 @@ func TestEnrollTokenRoundTrip(t *testing.T) {
 	}
 }
 
+func TestListEnrollTokens(t *testing.T) {
+	s := testStore(t)
+
+	for _, id := range []string{"tok-1", "tok-2"} {
+		if err := s.SaveEnrollToken(id, []byte(id)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := s.ListEnrollTokens()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || string(got["tok-1"]) != "tok-1" || string(got["tok-2"]) != "tok-2" {
+		t.Errorf("ListEnrollTokens() = %q", got)
+	}
+}
+
 func TestDeleteEnrollToken(t *testing.T) {
 	s := testStore(t)
 
@@ -336,3 +358,73 @@ func TestScopedKey(t *testing.T) {
 		}
 	}
 }
+
+// ---------------------------------------------------------------------------
+// Host event timeline
+// ---------------------------------------------------------------------------
+
+func TestClusterHostEvents(t *testing.T) {
+	s := testStore(t)
+
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, e := range []ClusterHostEvent{
+		{HostID: "h1", Type: HostEventConnected, Message: "agent connected", Timestamp: base},
+		{HostID: "h2", Type: HostEventConnected, Message: "other host", Timestamp: base.Add(time.Second)},
+		{HostID: "h1", Type: HostEventCommand, Container: "web", Message: "update web", Timestamp: base.Add(2 * time.Second)},
+		{HostID: "h1", Type: HostEventResult, Container: "web", Message: "update web: failed", Failed: true, Timestamp: base.Add(3 * time.Second)},
+	} {
+		if err := s.AppendClusterHostEvent(e); err != nil {
+			t.Fatalf("append %d: %v", i, err)
+		}
+	}
+
+	got, err := s.ListClusterHostEvents("h1", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || got[0].Type != HostEventResult || !got[0].Failed || got[2].Type != HostEventConnected {
+		t.Fatalf("h1 events = %+v, want 3 newest first", got)
+	}
+	if got, _ := s.ListClusterHostEvents("h1", 2); len(got) != 2 || got[1].Type != HostEventCommand {
+		t.Errorf("limited events = %+v", got)
+	}
+	if got, _ := s.ListClusterHostEvents("h2", 10); len(got) != 1 || got[0].Message != "other host" {
+		t.Errorf("h2 events = %+v", got)
+	}
+
+	if err := s.DeleteClusterHostEvents("h1"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.ListClusterHostEvents("h1", 10); len(got) != 0 {
+		t.Errorf("after delete, h1 events = %+v", got)
+	}
+	if got, _ := s.ListClusterHostEvents("h2", 10); len(got) != 1 {
+		t.Errorf("delete removed another host's events: %+v", got)
+	}
+}
+
+func TestClusterHostEventsTrimmed(t *testing.T) {
+	s := testStore(t)
+
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i := range MaxClusterHostEvents + 5 {
+		if err := s.AppendClusterHostEvent(ClusterHostEvent{
+			HostID:    "h1",
+			Type:      HostEventCommand,
+			Message:   fmt.Sprintf("command %d", i),
+			Timestamp: base.Add(time.Duration(i) * time.Millisecond),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := s.ListClusterHostEvents("h1", 2*MaxClusterHostEvents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != MaxClusterHostEvents {
+		t.Fatalf("kept %d events, want %d", len(got), MaxClusterHostEvents)
+	}
+	if got[len(got)-1].Message != "command 5" {
+		t.Errorf("oldest kept = %q, want command 5", got[len(got)-1].Message)
+	}
+}
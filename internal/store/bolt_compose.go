@@ -0,0 +1,56 @@
+package store
+
+// SetComposePath stores where a Compose project's file is mounted inside
+// Sentinel's container. path may be the file or its directory.
+func (s *Store) SetComposePath(project, path string) error {
+	return s.update(bucketComposePaths, func(tx kvTx) error {
+		b, err := bucket(tx, bucketComposePaths)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(project), []byte(path))
+	})
+}
+
+// GetComposePath returns a project's compose path override, if any.
+func (s *Store) GetComposePath(project string) (string, bool) {
+	var path string
+	_ = s.view(bucketComposePaths, func(tx kvTx) error {
+		b, err := bucket(tx, bucketComposePaths)
+		if err != nil {
+			return err
+		}
+		if v := b.Get([]byte(project)); v != nil {
+			path = string(v)
+		}
+		return nil
+	})
+	return path, path != ""
+}
+
+// ListComposePaths returns all compose path overrides keyed by project.
+func (s *Store) ListComposePaths() (map[string]string, error) {
+	result := make(map[string]string)
+	err := s.view(bucketComposePaths, func(tx kvTx) error {
+		b, err := bucket(tx, bucketComposePaths)
+		if err != nil {
+			return err
+		}
+		return b.ForEach(func(k, v []byte) error {
+			result[string(k)] = string(v)
+			return nil
+		})
+	})
+	return result, err
+}
+
+// DeleteComposePath removes a project's compose path override.
+func (s *Store) DeleteComposePath(project string) error {
+	return s.update(bucketComposePaths, func(tx kvTx) error {
+		b, err := bucket(tx, bucketComposePaths)
+		if err != nil {
+			return err
+		}
+		return b.Delete([]byte(project))
+	})
+}
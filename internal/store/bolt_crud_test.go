@@ -1,7 +1,9 @@
 package store
 
 import (
+	"errors"
 	"fmt"
+	"slices"
 	"testing"
 	"time"
 )
@@ -682,6 +684,32 @@ func TestHookIsolation(t *testing.T) {
 	}
 }
 
+func TestListAllHooks(t *testing.T) {
+	s := testStore(t)
+
+	for _, h := range []HookEntry{
+		{ContainerName: "app-b", Phase: "pre-update", Command: []string{"cmd-b"}},
+		{ContainerName: "app-a", Phase: "post-update", Command: []string{"cmd-a2"}},
+		{ContainerName: "app-a", Phase: "pre-update", Command: []string{"cmd-a1"}},
+	} {
+		if err := s.SaveHook(h); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	hooks, err := s.ListAllHooks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, h := range hooks {
+		got = append(got, h.Command[0])
+	}
+	if want := []string{"cmd-a2", "cmd-a1", "cmd-b"}; !slices.Equal(got, want) {
+		t.Errorf("commands = %v, want %v", got, want)
+	}
+}
+
 func TestHookOverwrite(t *testing.T) {
 	s := testStore(t)
 
@@ -787,3 +815,695 @@ func TestListAllHistoryEmpty(t *testing.T) {
 		t.Errorf("expected 0, got %d", len(got))
 	}
 }
+
+func TestForEachHistory(t *testing.T) {
+	s := testStore(t)
+	base := time.Date(2026, 9, 30, 23, 0, 0, 0, time.UTC)
+	// More records than one batch, half a second apart so that keys within
+	// a second sort out of time order ("…00.5Z" < "…00Z").
+	const n = historyBatchSize + 100
+	for i := 0; i < n; i++ {
+		rec := UpdateRecord{Timestamp: base.Add(time.Duration(i) * 500 * time.Millisecond), ContainerName: "app", Outcome: "success"}
+		if err := s.RecordUpdate(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	collect := func(from, to time.Time) []time.Time {
+		t.Helper()
+		var got []time.Time
+		err := s.ForEachHistory(from, to, func(r UpdateRecord) error {
+			got = append(got, r.Timestamp)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	all := collect(time.Time{}, time.Time{})
+	if len(all) != n {
+		t.Fatalf("got %d records, want %d", len(all), n)
+	}
+	for i := 1; i < len(all); i++ {
+		if !all[i].Before(all[i-1]) {
+			t.Fatalf("records not newest first at %d: %v after %v", i, all[i], all[i-1])
+		}
+	}
+
+	// [from, to) covers records 10 through 19.
+	from, to := base.Add(5*time.Second), base.Add(10*time.Second)
+	got := collect(from, to)
+	if len(got) != 10 {
+		t.Fatalf("range returned %d records, want 10: %v", len(got), got)
+	}
+	for _, ts := range got {
+		if ts.Before(from) || !ts.Before(to) {
+			t.Errorf("record at %v outside [%v, %v)", ts, from, to)
+		}
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err := s.ForEachHistory(time.Time{}, time.Time{}, func(UpdateRecord) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("err = %v after %d calls, want stop after 1", err, calls)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Unmanaged containers
+// ---------------------------------------------------------------------------
+
+func TestValidationOverrideRoundTrip(t *testing.T) {
+	s := testStore(t)
+
+	if _, ok := s.GetValidationOverride("app"); ok {
+		t.Fatal("expected no override before first write")
+	}
+	o := ValidationOverride{GracePeriod: 90 * time.Second, Strategy: "probe", ProbeURL: "http://:8080/healthz"}
+	if err := s.SetValidationOverride("app", o); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := s.GetValidationOverride("app"); !ok || got != o {
+		t.Errorf("GetValidationOverride = %+v, %v; want %+v", got, ok, o)
+	}
+	if err := s.DeleteValidationOverride("app"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.GetValidationOverride("app"); ok {
+		t.Error("override still present after delete")
+	}
+}
+
+func TestPreconditionsRoundTrip(t *testing.T) {
+	s := testStore(t)
+
+	if _, ok := s.GetPreconditions("app"); ok {
+		t.Fatal("expected no preconditions before first write")
+	}
+	p := Preconditions{Conditions: []string{"path:/mnt/media", "tcp://db:5432"}, Action: "skip"}
+	if err := s.SetPreconditions("app", p); err != nil {
+		t.Fatal(err)
+	}
+	got, ok := s.GetPreconditions("app")
+	if !ok || got.Action != "skip" || len(got.Conditions) != 2 || got.Conditions[1] != "tcp://db:5432" {
+		t.Errorf("GetPreconditions = %+v, %v; want %+v", got, ok, p)
+	}
+	if err := s.DeletePreconditions("app"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.GetPreconditions("app"); ok {
+		t.Error("preconditions still present after delete")
+	}
+}
+
+func TestRestartMarkerTake(t *testing.T) {
+	s := testStore(t)
+
+	if _, ok, err := s.TakeRestartMarker(); err != nil || ok {
+		t.Fatalf("TakeRestartMarker on empty store = %v, %v", ok, err)
+	}
+	m := RestartMarker{FromVersion: "2.9.1", TargetImage: "sentinel:2.10.2", StartedAt: time.Now().UTC().Truncate(time.Second)}
+	if err := s.SetRestartMarker(m); err != nil {
+		t.Fatal(err)
+	}
+	got, ok, err := s.TakeRestartMarker()
+	if err != nil || !ok || got.FromVersion != "2.9.1" || !got.StartedAt.Equal(m.StartedAt) {
+		t.Errorf("TakeRestartMarker = %+v, %v, %v; want %+v", got, ok, err, m)
+	}
+	if _, ok, _ := s.TakeRestartMarker(); ok {
+		t.Error("marker still present after take")
+	}
+
+	_ = s.SetRestartMarker(m)
+	if err := s.ClearRestartMarker(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := s.TakeRestartMarker(); ok {
+		t.Error("marker still present after clear")
+	}
+}
+
+func TestTimedPinRoundTrip(t *testing.T) {
+	s := testStore(t)
+
+	if _, ok := s.GetTimedPin("app"); ok {
+		t.Fatal("expected no pin before first write")
+	}
+	until := time.Now().Add(14 * 24 * time.Hour).UTC().Truncate(time.Second)
+	if err := s.SetTimedPin("app", TimedPin{Until: until, PreviousPolicy: "auto", Digest: "sha256:abc"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetTimedPin("h1::db", TimedPin{Until: until}); err != nil {
+		t.Fatal(err)
+	}
+	got, ok := s.GetTimedPin("app")
+	if !ok || !got.Until.Equal(until) || got.PreviousPolicy != "auto" || got.Digest != "sha256:abc" {
+		t.Errorf("GetTimedPin = %+v, %v", got, ok)
+	}
+	if all := s.AllTimedPins(); len(all) != 2 {
+		t.Errorf("AllTimedPins() = %d entries, want 2", len(all))
+	}
+	if err := s.DeleteTimedPin("app"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.GetTimedPin("app"); ok {
+		t.Error("pin still present after delete")
+	}
+}
+
+func TestTicketRoundTrip(t *testing.T) {
+	s := testStore(t)
+
+	if _, ok := s.GetTicket("app"); ok {
+		t.Fatal("expected no ticket before first write")
+	}
+	if err := s.SetTicket("app", TicketRef{Provider: "github", ID: "42", URL: "https://github.com/o/r/issues/42"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetTicket("h1::db", TicketRef{Provider: "jira", ID: "OPS-7"}); err != nil {
+		t.Fatal(err)
+	}
+	got, ok := s.GetTicket("app")
+	if !ok || got.Provider != "github" || got.ID != "42" {
+		t.Errorf("GetTicket = %+v, %v", got, ok)
+	}
+	if all := s.AllTickets(); len(all) != 2 || all["h1::db"].ID != "OPS-7" {
+		t.Errorf("AllTickets() = %+v", all)
+	}
+	if err := s.DeleteTicket("app"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.GetTicket("app"); ok {
+		t.Error("ticket still present after delete")
+	}
+}
+
+func TestContainerScheduleRoundTrip(t *testing.T) {
+	s := testStore(t)
+
+	if _, ok := s.GetContainerSchedule("nginx"); ok {
+		t.Fatal("expected no schedule before set")
+	}
+	if err := s.SetContainerSchedule("nginx", "0 3 * * 0"); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := s.GetContainerSchedule("nginx"); !ok || got != "0 3 * * 0" {
+		t.Errorf("GetContainerSchedule = %q, %v", got, ok)
+	}
+	if err := s.DeleteContainerSchedule("nginx"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.GetContainerSchedule("nginx"); ok {
+		t.Error("schedule still present after delete")
+	}
+}
+
+func TestComposePathRoundTrip(t *testing.T) {
+	s := testStore(t)
+
+	if err := s.SetComposePath("shop", "/stacks/shop"); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := s.GetComposePath("shop"); !ok || got != "/stacks/shop" {
+		t.Errorf("GetComposePath = %q, %v", got, ok)
+	}
+	paths, err := s.ListComposePaths()
+	if err != nil || len(paths) != 1 {
+		t.Errorf("ListComposePaths = %v, %v", paths, err)
+	}
+	if err := s.DeleteComposePath("shop"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.GetComposePath("shop"); ok {
+		t.Error("path still present after delete")
+	}
+}
+
+func TestTrustedKeyRoundTrip(t *testing.T) {
+	s := testStore(t)
+
+	for _, name := range []string{"release", "ci"} {
+		if err := s.SaveTrustedKey(TrustedKey{Name: name, PEM: "pem-" + name, Fingerprint: "sha256:" + name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	keys, err := s.ListTrustedKeys()
+	if err != nil || len(keys) != 2 || keys[0].Name != "ci" || keys[1].PEM != "pem-release" {
+		t.Fatalf("ListTrustedKeys = %+v, %v", keys, err)
+	}
+	if err := s.DeleteTrustedKey("ci"); err != nil {
+		t.Fatal(err)
+	}
+	if keys, _ := s.ListTrustedKeys(); len(keys) != 1 || keys[0].Name != "release" {
+		t.Errorf("after delete = %+v", keys)
+	}
+}
+
+func TestDeployTokenRoundTrip(t *testing.T) {
+	s := testStore(t)
+
+	tok := DeployToken{ID: "a1", Name: "ci", TokenHash: "hash", Containers: []string{"web", "api-*"}}
+	if err := s.SaveDeployToken(tok); err != nil {
+		t.Fatal(err)
+	}
+	tokens, err := s.ListDeployTokens()
+	if err != nil || len(tokens) != 1 || tokens[0].Containers[1] != "api-*" {
+		t.Fatalf("ListDeployTokens = %+v, %v", tokens, err)
+	}
+
+	old := DeployRequest{TokenID: "a1", Key: "old", Container: "web", CreatedAt: time.Now().Add(-48 * time.Hour)}
+	fresh := DeployRequest{TokenID: "a1", Key: "build-42", Container: "web", TargetImage: "web:42", CreatedAt: time.Now()}
+	for _, req := range []DeployRequest{old, fresh} {
+		if _, claimed, err := s.ClaimDeployRequest(req); err != nil || !claimed {
+			t.Fatalf("ClaimDeployRequest(%s) = %v, %v", req.Key, claimed, err)
+		}
+	}
+	again := DeployRequest{TokenID: "a1", Key: "build-42", Container: "api", CreatedAt: time.Now()}
+	if held, claimed, err := s.ClaimDeployRequest(again); err != nil || claimed || held.Container != "web" {
+		t.Errorf("second claim = %+v, %v, %v; want the first request kept", held, claimed, err)
+	}
+	got, ok, err := s.GetDeployRequest("a1", "build-42")
+	if err != nil || !ok || got.TargetImage != "web:42" {
+		t.Errorf("GetDeployRequest = %+v, %v, %v", got, ok, err)
+	}
+	if n, err := s.PruneDeployRequests(time.Now().Add(-24 * time.Hour)); err != nil || n != 1 {
+		t.Errorf("PruneDeployRequests = %d, %v; want 1", n, err)
+	}
+	if _, ok, _ := s.GetDeployRequest("a1", "old"); ok {
+		t.Error("stale request survived prune")
+	}
+
+	// A released key can be claimed again.
+	if err := s.ReleaseDeployRequest("a1", "build-42"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := s.GetDeployRequest("a1", "build-42"); ok {
+		t.Error("released request still recorded")
+	}
+	if _, claimed, err := s.ClaimDeployRequest(again); err != nil || !claimed {
+		t.Errorf("claim after release = %v, %v; want claimed", claimed, err)
+	}
+
+	// An expired key is neither returned nor held before it is pruned.
+	expired := DeployRequest{TokenID: "a1", Key: "build-1", Container: "web", CreatedAt: time.Now().Add(-25 * time.Hour)}
+	if _, claimed, err := s.ClaimDeployRequest(expired); err != nil || !claimed {
+		t.Fatalf("claim expired = %v, %v", claimed, err)
+	}
+	if _, ok, _ := s.GetDeployRequest("a1", "build-1"); ok {
+		t.Error("expired request returned")
+	}
+	renewed := DeployRequest{TokenID: "a1", Key: "build-1", Container: "api", CreatedAt: time.Now()}
+	if held, claimed, err := s.ClaimDeployRequest(renewed); err != nil || !claimed || held.Container != "api" {
+		t.Errorf("claim over expired = %+v, %v, %v; want the new request", held, claimed, err)
+	}
+
+	if err := s.DeleteDeployToken("a1"); err != nil {
+		t.Fatal(err)
+	}
+	if tokens, _ := s.ListDeployTokens(); len(tokens) != 0 {
+		t.Errorf("after delete = %+v", tokens)
+	}
+	if _, ok, _ := s.GetDeployRequest("a1", "build-42"); ok {
+		t.Error("request survived token delete")
+	}
+}
+
+func TestVersionInfoRoundTrip(t *testing.T) {
+	s := testStore(t)
+
+	if _, ok := s.GetVersionInfo("nginx"); ok {
+		t.Fatal("expected no version info before first write")
+	}
+	info := VersionInfo{Tag: "latest", Current: "1.25.3", Candidate: "1.27.0", Source: VersionSourceLabel}
+	if err := s.SetVersionInfo("nginx", info); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetVersionInfo("host-1::redis", VersionInfo{Tag: "7.2", Current: "7.2", Source: VersionSourceTag}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, ok := s.GetVersionInfo("nginx")
+	if !ok || got.Current != "1.25.3" || got.Candidate != "1.27.0" {
+		t.Errorf("GetVersionInfo(nginx) = %+v, %v", got, ok)
+	}
+	if all := s.AllVersionInfo(); len(all) != 2 || all["host-1::redis"].Current != "7.2" {
+		t.Errorf("AllVersionInfo() = %+v", all)
+	}
+}
+
+func TestUnmanagedRoundTrip(t *testing.T) {
+	s := testStore(t)
+
+	if got := s.AllUnmanaged(); len(got) != 0 {
+		t.Fatalf("expected empty map, got %v", got)
+	}
+
+	since := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := s.SetUnmanaged("nginx", UnmanagedEntry{Since: since}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetUnmanaged("host-1::redis", UnmanagedEntry{Since: since, Hidden: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	all := s.AllUnmanaged()
+	if len(all) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(all))
+	}
+	if e := all["nginx"]; e.Hidden || !e.Since.Equal(since) {
+		t.Errorf("nginx = %+v, want visible since %v", e, since)
+	}
+	if e := all["host-1::redis"]; !e.Hidden {
+		t.Errorf("host-1::redis Hidden = false, want true")
+	}
+
+	if err := s.DeleteUnmanaged("nginx"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.AllUnmanaged()["nginx"]; ok {
+		t.Error("nginx still unmanaged after delete")
+	}
+}
+
+func TestListMaintenance(t *testing.T) {
+	s := testStore(t)
+
+	if err := s.SetMaintenance("nginx", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetMaintenance("redis", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetMaintenance("redis", false); err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := s.ListMaintenance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 1 || names[0] != "nginx" {
+		t.Errorf("ListMaintenance() = %v, want [nginx]", names)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Update journal
+// ---------------------------------------------------------------------------
+
+func TestUpdateJournalRoundTrip(t *testing.T) {
+	s := testStore(t)
+
+	j := UpdateJournal{Name: "app", Phase: JournalPulled, OldContainerID: "abc", Snapshot: []byte(`{"Id":"abc"}`)}
+	if err := s.SaveJournal(j); err != nil {
+		t.Fatal(err)
+	}
+	j.Phase = JournalCreated
+	j.NewContainerID = "def"
+	if err := s.SaveJournal(j); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := s.ListJournal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	if entries[0].Phase != JournalCreated || entries[0].NewContainerID != "def" || string(entries[0].Snapshot) != `{"Id":"abc"}` {
+		t.Errorf("unexpected entry %+v", entries[0])
+	}
+
+	if err := s.DeleteJournal("app"); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := s.ListJournal(); len(entries) != 0 {
+		t.Errorf("expected empty journal after delete, got %d", len(entries))
+	}
+}
+
+func TestRetryStateRoundTrip(t *testing.T) {
+	s := testStore(t)
+
+	if _, ok := s.GetRetryState("app"); ok {
+		t.Fatal("expected no retry state before first write")
+	}
+	st := RetryState{Attempts: 2, LastError: "pull failed", Failing: true}
+	if err := s.SetRetryState("app", st); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := s.GetRetryState("app"); !ok || got.Attempts != 2 || !got.Failing {
+		t.Errorf("GetRetryState = %+v, %v; want %+v", got, ok, st)
+	}
+	if all := s.AllRetryStates(); len(all) != 1 {
+		t.Errorf("AllRetryStates() = %+v, want 1 entry", all)
+	}
+	if err := s.DeleteRetryState("app"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.GetRetryState("app"); ok {
+		t.Error("retry state still present after delete")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Timeline
+// ---------------------------------------------------------------------------
+
+func TestListTimeline(t *testing.T) {
+	s := testStore(t)
+	base := time.Date(2026, 10, 16, 22, 0, 0, 0, time.UTC)
+	at := func(min int) time.Time { return base.Add(time.Duration(min) * time.Minute) }
+
+	for _, l := range []LogEntry{
+		{Timestamp: at(0), Type: "settings", Message: "Poll interval changed", User: "admin"},
+		{Timestamp: at(30), Type: "approve", Container: "web", Message: "Update approved", User: "admin"},
+	} {
+		if err := s.AppendLog(l); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, r := range []UpdateRecord{
+		{Timestamp: at(10), ContainerName: "db", Outcome: "failed", OldImage: "postgres:16", NewImage: "postgres:17", Error: "unhealthy"},
+		{Timestamp: at(31), ContainerName: "web", Outcome: "success", OldImage: "nginx:1.25", NewImage: "nginx:1.27"},
+		{Timestamp: at(40), ContainerName: "app", Outcome: "success", HostID: "h1", HostName: "nas"},
+	} {
+		if err := s.RecordUpdate(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, e := range []ClusterHostEvent{
+		{Timestamp: at(20), HostID: "h1", Type: HostEventDisconnected, Message: "agent disconnected"},
+		{Timestamp: at(25), HostID: "h2", Type: HostEventConnected, Message: "agent connected"},
+	} {
+		if err := s.AppendClusterHostEvent(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, next, err := s.ListTimeline(TimelineQuery{Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Source+":"+e.Type)
+	}
+	want := "history:success history:success log:approve cluster:connected cluster:disconnected history:failed log:settings"
+	if fmt.Sprint(got) != "["+want+"]" || next != "" {
+		t.Fatalf("timeline = %v (next %q), want [%s]", got, next, want)
+	}
+	if e := entries[5]; !e.Failed || e.Message != "failed: postgres:16 -> postgres:17 (unhealthy)" {
+		t.Errorf("failed update entry = %+v", e)
+	}
+
+	// Keyset pages of three cover everything exactly once.
+	var paged []string
+	cursor := ""
+	for page := 0; page < 5; page++ {
+		entries, next, err := s.ListTimeline(TimelineQuery{Limit: 3, Before: cursor})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range entries {
+			paged = append(paged, e.Source+":"+e.Type)
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	if fmt.Sprint(paged) != "["+want+"]" {
+		t.Errorf("paged = %v, want [%s]", paged, want)
+	}
+
+	// Filters.
+	count := func(q TimelineQuery) int {
+		t.Helper()
+		entries, _, err := s.ListTimeline(q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(entries)
+	}
+	if n := count(TimelineQuery{Container: "web"}); n != 2 {
+		t.Errorf("container=web: %d entries, want 2", n)
+	}
+	if n := count(TimelineQuery{HostID: "h1"}); n != 2 {
+		t.Errorf("host=h1: %d entries, want 2", n)
+	}
+	if n := count(TimelineQuery{Types: []string{"cluster", "failed"}}); n != 3 {
+		t.Errorf("type=cluster,failed: %d entries, want 3", n)
+	}
+	if n := count(TimelineQuery{Sources: []string{TimelineSourceHistory, TimelineSourceCluster}, Types: []string{"success", "connected"}}); n != 3 {
+		t.Errorf("sources=history,cluster: %d entries, want 3", n)
+	}
+	if n := count(TimelineQuery{Since: at(25)}); n != 4 {
+		t.Errorf("since: %d entries, want 4", n)
+	}
+
+	if _, _, err := s.ListTimeline(TimelineQuery{Before: "not a cursor!"}); err != ErrInvalidCursor {
+		t.Errorf("bad cursor: err = %v, want ErrInvalidCursor", err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Audit log
+// ---------------------------------------------------------------------------
+
+func TestAuditListAndPrune(t *testing.T) {
+	s := testStore(t)
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	entries := []AuditEntry{
+		{Timestamp: base, Actor: "alice", Method: "POST", Path: "/api/settings/poll-interval", Status: 200},
+		{Timestamp: base.Add(time.Minute), Actor: "bob", Method: "POST", Path: "/api/containers/web/policy", Container: "web", Status: 200},
+		{Timestamp: base.Add(time.Minute), Actor: "alice", Method: "DELETE", Path: "/api/containers/web/policy", Container: "web", Status: 404},
+		{Timestamp: base.Add(2 * time.Minute), Actor: "alice", Method: "POST", Path: "/api/containers/db/update", Container: "db", Status: 200},
+	}
+	for _, e := range entries {
+		if err := s.AppendAudit(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Newest first; same-timestamp entries keep insertion order reversed.
+	all, next, err := s.ListAudit(AuditQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 4 || next != "" || all[0].Container != "db" || all[1].Method != "DELETE" || all[3].Path != "/api/settings/poll-interval" {
+		t.Fatalf("all = %+v, next = %q", all, next)
+	}
+
+	// Paging.
+	page, next, _ := s.ListAudit(AuditQuery{Limit: 3})
+	if len(page) != 3 || next == "" || next != page[2].Cursor {
+		t.Fatalf("page 1 = %d entries, next = %q", len(page), next)
+	}
+	page, next, _ = s.ListAudit(AuditQuery{Limit: 3, Before: next})
+	if len(page) != 1 || next != "" || page[0].Actor != "alice" || page[0].Method != "POST" {
+		t.Errorf("page 2 = %+v, next = %q", page, next)
+	}
+
+	// Filters.
+	tests := []struct {
+		name string
+		q    AuditQuery
+		want int
+	}{
+		{"actor", AuditQuery{Actor: "alice"}, 3},
+		{"method", AuditQuery{Method: "delete"}, 1},
+		{"path prefix", AuditQuery{Path: "/api/containers/"}, 3},
+		{"container", AuditQuery{Container: "web"}, 2},
+		{"failed", AuditQuery{Failed: true}, 1},
+		{"since", AuditQuery{Since: base.Add(time.Minute)}, 3},
+	}
+	for _, tt := range tests {
+		got, _, err := s.ListAudit(tt.q)
+		if err != nil || len(got) != tt.want {
+			t.Errorf("%s: got %d entries (err %v), want %d", tt.name, len(got), err, tt.want)
+		}
+	}
+
+	if _, _, err := s.ListAudit(AuditQuery{Before: "garbage"}); !errors.Is(err, ErrInvalidAuditCursor) {
+		t.Errorf("bad cursor: err = %v", err)
+	}
+
+	removed, err := s.PruneAudit(base.Add(time.Minute))
+	if err != nil || removed != 1 {
+		t.Fatalf("PruneAudit = %d, %v; want 1", removed, err)
+	}
+	if left, _, _ := s.ListAudit(AuditQuery{}); len(left) != 3 {
+		t.Errorf("%d entries left after prune, want 3", len(left))
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Signature verification cache
+// ---------------------------------------------------------------------------
+
+func TestVerifyCacheRoundTrip(t *testing.T) {
+	s := testStore(t)
+	now := time.Now().UTC().Truncate(time.Second)
+	for _, d := range []string{"nginx@sha256:aaa", "redis@sha256:bbb"} {
+		if err := s.SaveVerifyCache(VerifyCacheEntry{Digest: d, Key: "release", Policy: "p1", VerifiedAt: now}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	e, ok, err := s.GetVerifyCache("nginx@sha256:aaa")
+	if err != nil || !ok || e.Key != "release" || e.Policy != "p1" || !e.VerifiedAt.Equal(now) {
+		t.Fatalf("GetVerifyCache = %+v, %v, %v", e, ok, err)
+	}
+	if _, ok, _ := s.GetVerifyCache("nginx@sha256:ccc"); ok {
+		t.Error("found an entry that was never saved")
+	}
+
+	if found, err := s.DeleteVerifyCache("nginx@sha256:aaa"); err != nil || !found {
+		t.Errorf("DeleteVerifyCache = %v, %v", found, err)
+	}
+	if found, _ := s.DeleteVerifyCache("nginx@sha256:aaa"); found {
+		t.Error("deleted the same entry twice")
+	}
+	if list, _ := s.ListVerifyCache(); len(list) != 1 || list[0].Digest != "redis@sha256:bbb" {
+		t.Errorf("ListVerifyCache = %+v", list)
+	}
+
+	if n, err := s.ClearVerifyCache(); err != nil || n != 1 {
+		t.Errorf("ClearVerifyCache = %d, %v", n, err)
+	}
+	if list, _ := s.ListVerifyCache(); len(list) != 0 {
+		t.Errorf("%d entries left after clear", len(list))
+	}
+}
+
+func TestStackWindowRoundTrip(t *testing.T) {
+	s := testStore(t)
+
+	if _, ok := s.GetStackWindow("db"); ok {
+		t.Fatal("expected no window before set")
+	}
+	if err := s.SetStackWindow("db", "Sun 03:00-05:00"); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := s.GetStackWindow("db"); !ok || got != "Sun 03:00-05:00" {
+		t.Errorf("GetStackWindow = %q, %v", got, ok)
+	}
+	windows, err := s.ListStackWindows()
+	if err != nil || len(windows) != 1 || windows["db"] != "Sun 03:00-05:00" {
+		t.Errorf("ListStackWindows = %v, %v", windows, err)
+	}
+	if err := s.DeleteStackWindow("db"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.GetStackWindow("db"); ok {
+		t.Error("window still present after delete")
+	}
+}
@@ -0,0 +1,203 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// DeployToken authorises CI pipelines to redeploy named containers through
+// the deploy hook. Only the SHA-256 hash of the token is stored.
+type DeployToken struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	TokenHash  string    `json:"token_hash"`
+	Containers []string  `json:"containers"` // container names or path.Match patterns
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+}
+
+// DeployRequestTTL is how long a deploy hook idempotency key is remembered.
+const DeployRequestTTL = 24 * time.Hour
+
+// DeployRequest records a deploy hook call by its idempotency key, so a
+// retried call is answered without deploying twice.
+type DeployRequest struct {
+	TokenID     string    `json:"token_id"`
+	Key         string    `json:"key"`
+	Container   string    `json:"container"`
+	TargetImage string    `json:"target_image,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ListDeployTokens returns all deploy tokens, ordered by ID.
+func (s *Store) ListDeployTokens() ([]DeployToken, error) {
+	var tokens []DeployToken
+	err := s.view(bucketDeployTokens, func(tx kvTx) error {
+		b, err := bucket(tx, bucketDeployTokens)
+		if err != nil {
+			return err
+		}
+		return b.ForEach(func(_, v []byte) error {
+			var t DeployToken
+			if err := json.Unmarshal(v, &t); err != nil {
+				return nil
+			}
+			tokens = append(tokens, t)
+			return nil
+		})
+	})
+	return tokens, err
+}
+
+// SaveDeployToken adds or replaces a deploy token.
+func (s *Store) SaveDeployToken(token DeployToken) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("marshal deploy token: %w", err)
+	}
+	return s.update(bucketDeployTokens, func(tx kvTx) error {
+		b, err := bucket(tx, bucketDeployTokens)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(token.ID), data)
+	})
+}
+
+// DeleteDeployToken removes a deploy token and its recorded requests.
+func (s *Store) DeleteDeployToken(id string) error {
+	return s.update(bucketDeployTokens, func(tx kvTx) error {
+		b, err := bucket(tx, bucketDeployTokens)
+		if err != nil {
+			return err
+		}
+		if err := b.Delete([]byte(id)); err != nil {
+			return err
+		}
+		rb, err := bucket(tx, bucketDeployRequests)
+		if err != nil {
+			return err
+		}
+		prefix := []byte(id + "::")
+		var keys [][]byte
+		c := rb.Cursor()
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			keys = append(keys, append([]byte(nil), k...))
+		}
+		for _, k := range keys {
+			if err := rb.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// expired reports whether the request's key is past DeployRequestTTL at now.
+func (r DeployRequest) expired(now time.Time) bool {
+	return r.CreatedAt.Before(now.Add(-DeployRequestTTL))
+}
+
+// GetDeployRequest returns the request recorded for a token's idempotency
+// key, if any. Expired keys not yet pruned are not returned.
+func (s *Store) GetDeployRequest(tokenID, key string) (DeployRequest, bool, error) {
+	var req DeployRequest
+	var found bool
+	err := s.view(bucketDeployRequests, func(tx kvTx) error {
+		b, err := bucket(tx, bucketDeployRequests)
+		if err != nil {
+			return err
+		}
+		v := b.Get([]byte(tokenID + "::" + key))
+		if v == nil {
+			return nil
+		}
+		if err := json.Unmarshal(v, &req); err != nil {
+			return err
+		}
+		found = !req.expired(time.Now())
+		return nil
+	})
+	return req, found, err
+}
+
+// ClaimDeployRequest records req under its idempotency key unless the key
+// is already taken, checking and writing in one transaction so concurrent
+// deliveries of the same key can't both claim it. An expired key is taken
+// over. It returns the request holding the key and whether req was the one
+// recorded.
+func (s *Store) ClaimDeployRequest(req DeployRequest) (DeployRequest, bool, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return DeployRequest{}, false, fmt.Errorf("marshal deploy request: %w", err)
+	}
+	held := req
+	claimed := false
+	err = s.update(bucketDeployRequests, func(tx kvTx) error {
+		b, err := bucket(tx, bucketDeployRequests)
+		if err != nil {
+			return err
+		}
+		k := []byte(req.TokenID + "::" + req.Key)
+		if v := b.Get(k); v != nil {
+			var prev DeployRequest
+			if err := json.Unmarshal(v, &prev); err != nil {
+				return err
+			}
+			if !prev.expired(req.CreatedAt) {
+				held = prev
+				return nil
+			}
+		}
+		claimed = true
+		return b.Put(k, data)
+	})
+	if err != nil {
+		return DeployRequest{}, false, err
+	}
+	return held, claimed, nil
+}
+
+// ReleaseDeployRequest forgets a token's idempotency key, so a retry with
+// the same key deploys again. Used when the claimed deploy failed.
+func (s *Store) ReleaseDeployRequest(tokenID, key string) error {
+	return s.update(bucketDeployRequests, func(tx kvTx) error {
+		b, err := bucket(tx, bucketDeployRequests)
+		if err != nil {
+			return err
+		}
+		return b.Delete([]byte(tokenID + "::" + key))
+	})
+}
+
+// PruneDeployRequests deletes requests recorded before cutoff and returns
+// how many were removed.
+func (s *Store) PruneDeployRequests(cutoff time.Time) (int, error) {
+	var removed int
+	err := s.update(bucketDeployRequests, func(tx kvTx) error {
+		b, err := bucket(tx, bucketDeployRequests)
+		if err != nil {
+			return err
+		}
+		var stale [][]byte
+		if err := b.ForEach(func(k, v []byte) error {
+			var req DeployRequest
+			if err := json.Unmarshal(v, &req); err != nil || req.CreatedAt.Before(cutoff) {
+				stale = append(stale, append([]byte(nil), k...))
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		for _, k := range stale {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		removed = len(stale)
+		return nil
+	})
+	return removed, err
+}
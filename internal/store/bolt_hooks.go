@@ -4,8 +4,6 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-
-	bolt "go.etcd.io/bbolt"
 )
 
 // HookEntry is the store representation of a lifecycle hook.
@@ -16,12 +14,20 @@ type HookEntry struct {
 	Timeout       int      `json:"timeout"`
 }
 
+// HookRun is the outcome of one lifecycle hook run, recorded in update history.
+type HookRun struct {
+	Phase    string `json:"phase"`
+	ExitCode int    `json:"exit_code"`
+	Output   string `json:"output,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
 // ListHooks returns all hooks for a container.
 func (s *Store) ListHooks(containerName string) ([]HookEntry, error) {
 	var entries []HookEntry
 	prefix := []byte(containerName + "::")
 
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketHooks, func(tx kvTx) error {
 		b, err := bucket(tx, bucketHooks)
 		if err != nil {
 			return err
@@ -39,13 +45,33 @@ func (s *Store) ListHooks(containerName string) ([]HookEntry, error) {
 	return entries, err
 }
 
+// ListAllHooks returns the hooks of every container, in key order.
+func (s *Store) ListAllHooks() ([]HookEntry, error) {
+	var entries []HookEntry
+	err := s.view(bucketHooks, func(tx kvTx) error {
+		b, err := bucket(tx, bucketHooks)
+		if err != nil {
+			return err
+		}
+		return b.ForEach(func(_, v []byte) error {
+			var entry HookEntry
+			if err := json.Unmarshal(v, &entry); err != nil {
+				return nil
+			}
+			entries = append(entries, entry)
+			return nil
+		})
+	})
+	return entries, err
+}
+
 // SaveHook saves or updates a hook for a container.
 func (s *Store) SaveHook(hook HookEntry) error {
 	data, err := json.Marshal(hook)
 	if err != nil {
 		return fmt.Errorf("marshal hook: %w", err)
 	}
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketHooks, func(tx kvTx) error {
 		b, err := bucket(tx, bucketHooks)
 		if err != nil {
 			return err
@@ -57,7 +83,7 @@ func (s *Store) SaveHook(hook HookEntry) error {
 
 // DeleteHook removes a hook for a container.
 func (s *Store) DeleteHook(containerName, phase string) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketHooks, func(tx kvTx) error {
 		b, err := bucket(tx, bucketHooks)
 		if err != nil {
 			return err
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Update journal phases, in the order UpdateContainer reaches them.
+const (
+	JournalPulled  = "pulled"  // new image pulled; old container untouched
+	JournalRemoved = "removed" // old container stopped and removed
+	JournalCreated = "created" // replacement created (NewContainerID set), not yet started
+	JournalStarted = "started" // replacement running, not yet validated/finalised
+)
+
+// UpdateJournal records how far an in-flight update got. It is written at
+// each phase and deleted when the update returns, so an entry found at
+// startup means Sentinel stopped mid-update.
+type UpdateJournal struct {
+	Name           string    `json:"name"`
+	Phase          string    `json:"phase"`
+	OldContainerID string    `json:"old_container_id"`
+	NewContainerID string    `json:"new_container_id,omitempty"`
+	OldImage       string    `json:"old_image"`
+	TargetImage    string    `json:"target_image"`
+	Snapshot       []byte    `json:"snapshot"` // container inspect JSON taken before the update
+	StartedAt      time.Time `json:"started_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// SaveJournal writes or replaces the journal entry for j.Name.
+func (s *Store) SaveJournal(j UpdateJournal) error {
+	data, err := json.Marshal(j)
+	if err != nil {
+		return fmt.Errorf("marshal update journal: %w", err)
+	}
+	return s.update(bucketUpdateJournal, func(tx kvTx) error {
+		b, err := bucket(tx, bucketUpdateJournal)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(j.Name), data)
+	})
+}
+
+// DeleteJournal removes the journal entry for a container.
+func (s *Store) DeleteJournal(name string) error {
+	return s.update(bucketUpdateJournal, func(tx kvTx) error {
+		b, err := bucket(tx, bucketUpdateJournal)
+		if err != nil {
+			return err
+		}
+		return b.Delete([]byte(name))
+	})
+}
+
+// ListJournal returns every journal entry left in the store.
+func (s *Store) ListJournal() ([]UpdateJournal, error) {
+	var entries []UpdateJournal
+	err := s.view(bucketUpdateJournal, func(tx kvTx) error {
+		b, err := bucket(tx, bucketUpdateJournal)
+		if err != nil {
+			return err
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var j UpdateJournal
+			if err := json.Unmarshal(v, &j); err != nil {
+				slog.Warn("corrupt entry in update_journal bucket, skipping", "key", string(k), "error", err)
+				return nil
+			}
+			entries = append(entries, j)
+			return nil
+		})
+	})
+	return entries, err
+}
@@ -0,0 +1,88 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// TimedPin is a temporary pin: the container keeps its current image until
+// Until, after which its policy override reverts to PreviousPolicy.
+type TimedPin struct {
+	PinnedAt       time.Time `json:"pinned_at"`
+	Until          time.Time `json:"until"`
+	PreviousPolicy string    `json:"previous_policy,omitempty"` // "" = no override before pinning
+	Digest         string    `json:"digest,omitempty"`          // image the container was pinned on
+	Reminded       bool      `json:"reminded,omitempty"`        // expiry reminder already sent
+}
+
+// SetTimedPin stores a container's timed pin.
+func (s *Store) SetTimedPin(key string, p TimedPin) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("marshal timed pin: %w", err)
+	}
+	return s.update(bucketTimedPins, func(tx kvTx) error {
+		b, err := bucket(tx, bucketTimedPins)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(key), data)
+	})
+}
+
+// GetTimedPin returns a container's timed pin, if any.
+func (s *Store) GetTimedPin(key string) (TimedPin, bool) {
+	var p TimedPin
+	var found bool
+	_ = s.view(bucketTimedPins, func(tx kvTx) error {
+		b, err := bucket(tx, bucketTimedPins)
+		if err != nil {
+			return err
+		}
+		v := b.Get([]byte(key))
+		if v == nil {
+			return nil
+		}
+		if err := json.Unmarshal(v, &p); err != nil {
+			slog.Warn("corrupt entry in timed pins bucket, skipping", "key", key, "error", err)
+			return nil
+		}
+		found = true
+		return nil
+	})
+	return p, found
+}
+
+// DeleteTimedPin removes a container's timed pin.
+func (s *Store) DeleteTimedPin(key string) error {
+	return s.update(bucketTimedPins, func(tx kvTx) error {
+		b, err := bucket(tx, bucketTimedPins)
+		if err != nil {
+			return err
+		}
+		return b.Delete([]byte(key))
+	})
+}
+
+// AllTimedPins returns every timed pin keyed by container.
+func (s *Store) AllTimedPins() map[string]TimedPin {
+	result := make(map[string]TimedPin)
+	_ = s.view(bucketTimedPins, func(tx kvTx) error {
+		b, err := bucket(tx, bucketTimedPins)
+		if err != nil {
+			return err
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var p TimedPin
+			if err := json.Unmarshal(v, &p); err != nil {
+				slog.Warn("corrupt entry in timed pins bucket, skipping", "key", string(k), "error", err)
+				return nil
+			}
+			result[string(k)] = p
+			return nil
+		})
+	})
+	return result
+}
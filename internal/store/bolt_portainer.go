@@ -6,8 +6,6 @@ import (
 	"sort"
 	"strconv"
 	"strings"
-
-	bolt "go.etcd.io/bbolt"
 )
 
 // PortainerInstance represents a configured Portainer server.
@@ -39,12 +37,16 @@ func (s *Store) SavePortainerInstance(inst PortainerInstance) error {
 	if err != nil {
 		return fmt.Errorf("marshal portainer instance: %w", err)
 	}
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketPortainerInstances, func(tx kvTx) error {
 		b, err := bucket(tx, bucketPortainerInstances)
 		if err != nil {
 			return err
 		}
-		return b.Put([]byte(inst.ID), data)
+		sealed, err := s.seal(data)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(inst.ID), sealed)
 	})
 }
 
@@ -52,7 +54,7 @@ func (s *Store) SavePortainerInstance(inst PortainerInstance) error {
 // Returns an error if the instance does not exist.
 func (s *Store) GetPortainerInstance(id string) (PortainerInstance, error) {
 	var inst PortainerInstance
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketPortainerInstances, func(tx kvTx) error {
 		b, err := bucket(tx, bucketPortainerInstances)
 		if err != nil {
 			return err
@@ -61,6 +63,10 @@ func (s *Store) GetPortainerInstance(id string) (PortainerInstance, error) {
 		if v == nil {
 			return fmt.Errorf("portainer instance %q not found", id)
 		}
+		v, err = s.unseal(v)
+		if err != nil {
+			return err
+		}
 		return json.Unmarshal(v, &inst)
 	})
 	return inst, err
@@ -69,12 +75,16 @@ func (s *Store) GetPortainerInstance(id string) (PortainerInstance, error) {
 // ListPortainerInstances returns all configured instances, sorted by ID.
 func (s *Store) ListPortainerInstances() ([]PortainerInstance, error) {
 	var instances []PortainerInstance
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketPortainerInstances, func(tx kvTx) error {
 		b, err := bucket(tx, bucketPortainerInstances)
 		if err != nil {
 			return err
 		}
 		return b.ForEach(func(k, v []byte) error {
+			v, err := s.unseal(v)
+			if err != nil {
+				return err
+			}
 			var inst PortainerInstance
 			if err := json.Unmarshal(v, &inst); err != nil {
 				return err
@@ -94,7 +104,7 @@ func (s *Store) ListPortainerInstances() ([]PortainerInstance, error) {
 // DeletePortainerInstance removes an instance by ID.
 // Deleting a non-existent ID is a silent no-op (BoltDB behaviour).
 func (s *Store) DeletePortainerInstance(id string) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketPortainerInstances, func(tx kvTx) error {
 		b, err := bucket(tx, bucketPortainerInstances)
 		if err != nil {
 			return err
@@ -196,14 +206,16 @@ func (s *Store) MigratePortainerKeys(instanceID string) error {
 	}
 
 	// --- History migration ---
-	return s.db.Update(func(tx *bolt.Tx) error {
+	// Scan in a read snapshot so the writer lock is only held for the
+	// rewrites, not for a walk over the whole history bucket.
+	type kv struct{ key, val []byte }
+	var rewrites []kv
+	err = s.view(bucketHistory, func(tx kvTx) error {
 		b, err := bucket(tx, bucketHistory)
 		if err != nil {
 			return err
 		}
-		type kv struct{ key, val []byte }
-		var rewrites []kv
-		if err := b.ForEach(func(k, v []byte) error {
+		return b.ForEach(func(k, v []byte) error {
 			var rec UpdateRecord
 			if json.Unmarshal(v, &rec) != nil {
 				return nil
@@ -224,7 +236,14 @@ func (s *Store) MigratePortainerKeys(instanceID string) error {
 			copy(keyCopy, k)
 			rewrites = append(rewrites, kv{key: keyCopy, val: newVal})
 			return nil
-		}); err != nil {
+		})
+	})
+	if err != nil || len(rewrites) == 0 {
+		return err
+	}
+	return s.update(bucketHistory, func(tx kvTx) error {
+		b, err := bucket(tx, bucketHistory)
+		if err != nil {
 			return err
 		}
 		for _, rw := range rewrites {
@@ -240,7 +259,7 @@ func (s *Store) MigratePortainerKeys(instanceID string) error {
 // Scans existing keys to find the highest numeric suffix and increments.
 func (s *Store) NextPortainerID() (string, error) {
 	var maxNum int
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketPortainerInstances, func(tx kvTx) error {
 		b, err := bucket(tx, bucketPortainerInstances)
 		if err != nil {
 			return err
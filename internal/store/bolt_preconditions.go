@@ -0,0 +1,70 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+)
+
+// Preconditions replaces the sentinel.wait-for labels for one container.
+type Preconditions struct {
+	Conditions []string `json:"conditions"`       // "path:/mnt/x", "http(s)://..." or "tcp://host:port"
+	Action     string   `json:"action,omitempty"` // "defer" (default) or "skip"
+}
+
+// PreconditionResult is the outcome of one wait-for condition, recorded in
+// update history.
+type PreconditionResult struct {
+	Condition string `json:"condition"`
+	OK        bool   `json:"ok"`
+	Detail    string `json:"detail,omitempty"`
+}
+
+// SetPreconditions stores a container's precondition override.
+func (s *Store) SetPreconditions(key string, p Preconditions) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("marshal preconditions: %w", err)
+	}
+	return s.update(bucketPreconditions, func(tx kvTx) error {
+		b, err := bucket(tx, bucketPreconditions)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(key), data)
+	})
+}
+
+// GetPreconditions returns a container's precondition override, if any.
+func (s *Store) GetPreconditions(key string) (Preconditions, bool) {
+	var p Preconditions
+	var found bool
+	_ = s.view(bucketPreconditions, func(tx kvTx) error {
+		b, err := bucket(tx, bucketPreconditions)
+		if err != nil {
+			return err
+		}
+		v := b.Get([]byte(key))
+		if v == nil {
+			return nil
+		}
+		if err := json.Unmarshal(v, &p); err != nil {
+			slog.Warn("corrupt entry in preconditions bucket, skipping", "key", key, "error", err)
+			return nil
+		}
+		found = true
+		return nil
+	})
+	return p, found
+}
+
+// DeletePreconditions removes a container's precondition override.
+func (s *Store) DeletePreconditions(key string) error {
+	return s.update(bucketPreconditions, func(tx kvTx) error {
+		b, err := bucket(tx, bucketPreconditions)
+		if err != nil {
+			return err
+		}
+		return b.Delete([]byte(key))
+	})
+}
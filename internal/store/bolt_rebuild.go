@@ -0,0 +1,102 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// RebuildState tracks the base image of one locally built container, so a
+// newer base is reported once rather than on every scan.
+type RebuildState struct {
+	// ImageID is the local image the state belongs to. A rebuild changes it
+	// and starts the comparison again.
+	ImageID   string `json:"image_id"`
+	BaseImage string `json:"base_image"`
+	// BaseDigest is the base image digest the local image was built against,
+	// or the one seen when watching began if the build did not record it.
+	BaseDigest string `json:"base_digest"`
+	// LatestDigest is the registry digest of the base image at the last check.
+	LatestDigest string `json:"latest_digest"`
+	// NotifiedDigest is the latest digest a rebuild alert was sent for.
+	NotifiedDigest string    `json:"notified_digest,omitempty"`
+	CheckedAt      time.Time `json:"checked_at"`
+}
+
+// RebuildNeeded reports whether the base image has moved on since the local
+// image was built.
+func (st RebuildState) RebuildNeeded() bool {
+	return st.LatestDigest != "" && st.LatestDigest != st.BaseDigest
+}
+
+// SetRebuildState stores a container's base image state, replacing any
+// existing entry.
+func (s *Store) SetRebuildState(name string, st RebuildState) error {
+	data, err := json.Marshal(st)
+	if err != nil {
+		return fmt.Errorf("marshal rebuild state: %w", err)
+	}
+	return s.update(bucketRebuildWatch, func(tx kvTx) error {
+		b, err := bucket(tx, bucketRebuildWatch)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(name), data)
+	})
+}
+
+// GetRebuildState returns a container's base image state, if any.
+func (s *Store) GetRebuildState(name string) (RebuildState, bool) {
+	var st RebuildState
+	var found bool
+	_ = s.view(bucketRebuildWatch, func(tx kvTx) error {
+		b, err := bucket(tx, bucketRebuildWatch)
+		if err != nil {
+			return err
+		}
+		v := b.Get([]byte(name))
+		if v == nil {
+			return nil
+		}
+		if err := json.Unmarshal(v, &st); err != nil {
+			slog.Warn("corrupt entry in rebuild watch bucket, skipping", "key", name, "error", err)
+			return nil
+		}
+		found = true
+		return nil
+	})
+	return st, found
+}
+
+// DeleteRebuildState stops tracking a container's base image.
+func (s *Store) DeleteRebuildState(name string) error {
+	return s.update(bucketRebuildWatch, func(tx kvTx) error {
+		b, err := bucket(tx, bucketRebuildWatch)
+		if err != nil {
+			return err
+		}
+		return b.Delete([]byte(name))
+	})
+}
+
+// AllRebuildStates returns the base image state of every watched container.
+func (s *Store) AllRebuildStates() map[string]RebuildState {
+	result := make(map[string]RebuildState)
+	_ = s.view(bucketRebuildWatch, func(tx kvTx) error {
+		b, err := bucket(tx, bucketRebuildWatch)
+		if err != nil {
+			return err
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var st RebuildState
+			if err := json.Unmarshal(v, &st); err != nil {
+				slog.Warn("corrupt entry in rebuild watch bucket, skipping", "key", string(k), "error", err)
+				return nil
+			}
+			result[string(k)] = st
+			return nil
+		})
+	})
+	return result
+}
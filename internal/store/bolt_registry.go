@@ -4,15 +4,13 @@ import (
 	"encoding/json"
 	"fmt"
 
-	bolt "go.etcd.io/bbolt"
-
 	"github.com/Will-Luck/Docker-Sentinel/internal/registry"
 )
 
 // AddIgnoredVersion records that a specific version should be ignored for a container.
 // The value stored under each container name is a JSON array of version strings.
 func (s *Store) AddIgnoredVersion(containerName, version string) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketIgnoredVersions, func(tx kvTx) error {
 		b, err := bucket(tx, bucketIgnoredVersions)
 		if err != nil {
 			return err
@@ -46,7 +44,7 @@ func (s *Store) AddIgnoredVersion(containerName, version string) error {
 // Returns an empty slice if none are stored.
 func (s *Store) GetIgnoredVersions(containerName string) ([]string, error) {
 	var versions []string
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketIgnoredVersions, func(tx kvTx) error {
 		b, err := bucket(tx, bucketIgnoredVersions)
 		if err != nil {
 			return err
@@ -63,9 +61,30 @@ func (s *Store) GetIgnoredVersions(containerName string) ([]string, error) {
 	return versions, err
 }
 
+// AllIgnoredVersions returns the ignored versions of every container, keyed
+// by container name.
+func (s *Store) AllIgnoredVersions() (map[string][]string, error) {
+	result := make(map[string][]string)
+	err := s.view(bucketIgnoredVersions, func(tx kvTx) error {
+		b, err := bucket(tx, bucketIgnoredVersions)
+		if err != nil {
+			return err
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var versions []string
+			if err := json.Unmarshal(v, &versions); err != nil {
+				return fmt.Errorf("unmarshal ignored versions for %s: %w", k, err)
+			}
+			result[string(k)] = versions
+			return nil
+		})
+	})
+	return result, err
+}
+
 // ClearIgnoredVersions removes all ignored versions for a container.
 func (s *Store) ClearIgnoredVersions(containerName string) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketIgnoredVersions, func(tx kvTx) error {
 		b, err := bucket(tx, bucketIgnoredVersions)
 		if err != nil {
 			return err
@@ -77,14 +96,14 @@ func (s *Store) ClearIgnoredVersions(containerName string) error {
 // GetRegistryCredentials loads registry credentials from the registry_credentials bucket.
 func (s *Store) GetRegistryCredentials() ([]registry.RegistryCredential, error) {
 	var creds []registry.RegistryCredential
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketRegistryCreds, func(tx kvTx) error {
 		b, err := bucket(tx, bucketRegistryCreds)
 		if err != nil {
 			return err
 		}
-		v := b.Get([]byte("credentials"))
-		if v == nil {
-			return nil
+		v, err := s.unseal(b.Get([]byte("credentials")))
+		if err != nil || v == nil {
+			return err
 		}
 		return json.Unmarshal(v, &creds)
 	})
@@ -97,18 +116,22 @@ func (s *Store) SetRegistryCredentials(creds []registry.RegistryCredential) erro
 	if err != nil {
 		return fmt.Errorf("marshal registry credentials: %w", err)
 	}
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketRegistryCreds, func(tx kvTx) error {
 		b, err := bucket(tx, bucketRegistryCreds)
 		if err != nil {
 			return err
 		}
-		return b.Put([]byte("credentials"), data)
+		sealed, err := s.seal(data)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte("credentials"), sealed)
 	})
 }
 
 // SaveRateLimits persists rate limit state for all registries.
 func (s *Store) SaveRateLimits(data []byte) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketRateLimits, func(tx kvTx) error {
 		b, err := bucket(tx, bucketRateLimits)
 		if err != nil {
 			return err
@@ -121,7 +144,7 @@ func (s *Store) SaveRateLimits(data []byte) error {
 // Returns nil, nil if nothing is stored.
 func (s *Store) LoadRateLimits() ([]byte, error) {
 	var data []byte
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketRateLimits, func(tx kvTx) error {
 		b, err := bucket(tx, bucketRateLimits)
 		if err != nil {
 			return err
@@ -145,7 +168,7 @@ var keyReleaseSources = []byte("sources")
 // GetReleaseSources returns all configured release sources.
 func (s *Store) GetReleaseSources() ([]registry.ReleaseSource, error) {
 	var sources []registry.ReleaseSource
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketReleaseSources, func(tx kvTx) error {
 		b, err := bucket(tx, bucketReleaseSources)
 		if err != nil {
 			return err
@@ -168,7 +191,7 @@ func (s *Store) SetReleaseSources(sources []registry.ReleaseSource) error {
 	if err != nil {
 		return fmt.Errorf("marshal release sources: %w", err)
 	}
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketReleaseSources, func(tx kvTx) error {
 		b, err := bucket(tx, bucketReleaseSources)
 		if err != nil {
 			return err
@@ -179,7 +202,7 @@ func (s *Store) SetReleaseSources(sources []registry.ReleaseSource) error {
 
 // SaveGHCRCache persists GHCR alternative detection cache.
 func (s *Store) SaveGHCRCache(data []byte) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketGHCRAlternatives, func(tx kvTx) error {
 		b, err := bucket(tx, bucketGHCRAlternatives)
 		if err != nil {
 			return err
@@ -192,7 +215,7 @@ func (s *Store) SaveGHCRCache(data []byte) error {
 // Returns nil, nil if nothing is stored.
 func (s *Store) LoadGHCRCache() ([]byte, error) {
 	var data []byte
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketGHCRAlternatives, func(tx kvTx) error {
 		b, err := bucket(tx, bucketGHCRAlternatives)
 		if err != nil {
 			return err
@@ -55,6 +55,22 @@ func TestIgnoredVersionsDeduplication(t *testing.T) {
 	}
 }
 
+func TestAllIgnoredVersions(t *testing.T) {
+	s := testStore(t)
+
+	_ = s.AddIgnoredVersion("nginx", "1.27")
+	_ = s.AddIgnoredVersion("redis", "8.0")
+	_ = s.AddIgnoredVersion("redis", "8.2")
+
+	all, err := s.AllIgnoredVersions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 || len(all["nginx"]) != 1 || len(all["redis"]) != 2 {
+		t.Errorf("AllIgnoredVersions = %v", all)
+	}
+}
+
 func TestClearIgnoredVersions(t *testing.T) {
 	s := testStore(t)
 
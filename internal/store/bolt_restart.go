@@ -0,0 +1,71 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// restartMarkerKey holds the self-update marker in bucketState.
+const restartMarkerKey = "self_update::marker"
+
+// RestartMarker is written by a Sentinel instance just before it replaces
+// its own container, and read back by the replacement on startup so it can
+// report which version it was updated from.
+type RestartMarker struct {
+	FromVersion string    `json:"from_version"`
+	TargetImage string    `json:"target_image,omitempty"`
+	StartedAt   time.Time `json:"started_at"`
+}
+
+// SetRestartMarker records a self-update about to restart Sentinel.
+func (s *Store) SetRestartMarker(m RestartMarker) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("marshal restart marker: %w", err)
+	}
+	return s.update(bucketState, func(tx kvTx) error {
+		b, err := bucket(tx, bucketState)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(restartMarkerKey), data)
+	})
+}
+
+// ClearRestartMarker removes the marker, e.g. when a self-update is rolled
+// back before the replacement started.
+func (s *Store) ClearRestartMarker() error {
+	return s.update(bucketState, func(tx kvTx) error {
+		b, err := bucket(tx, bucketState)
+		if err != nil {
+			return err
+		}
+		return b.Delete([]byte(restartMarkerKey))
+	})
+}
+
+// TakeRestartMarker returns and removes the marker left by the previous
+// instance, if any. A corrupt marker is dropped.
+func (s *Store) TakeRestartMarker() (RestartMarker, bool, error) {
+	var m RestartMarker
+	var found bool
+	err := s.update(bucketState, func(tx kvTx) error {
+		b, err := bucket(tx, bucketState)
+		if err != nil {
+			return err
+		}
+		v := b.Get([]byte(restartMarkerKey))
+		if v == nil {
+			return nil
+		}
+		if err := json.Unmarshal(v, &m); err != nil {
+			slog.Warn("corrupt restart marker in state bucket, skipping", "error", err)
+		} else {
+			found = true
+		}
+		return b.Delete([]byte(restartMarkerKey))
+	})
+	return m, found, err
+}
@@ -0,0 +1,90 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// RetryState tracks consecutive failed auto-updates for one container.
+// It is cleared on the next successful update or by an operator.
+type RetryState struct {
+	Attempts    int       `json:"attempts"`
+	LastError   string    `json:"last_error,omitempty"`
+	LastFailure time.Time `json:"last_failure"`
+	NextAttempt time.Time `json:"next_attempt"`
+	// Failing is set once the attempt limit is reached. Auto-updates stop
+	// until the state is cleared.
+	Failing bool `json:"failing"`
+}
+
+// SetRetryState stores a container's retry state, replacing any existing entry.
+func (s *Store) SetRetryState(name string, st RetryState) error {
+	data, err := json.Marshal(st)
+	if err != nil {
+		return fmt.Errorf("marshal retry state: %w", err)
+	}
+	return s.update(bucketRetry, func(tx kvTx) error {
+		b, err := bucket(tx, bucketRetry)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(name), data)
+	})
+}
+
+// GetRetryState returns a container's retry state, if any.
+func (s *Store) GetRetryState(name string) (RetryState, bool) {
+	var st RetryState
+	var found bool
+	_ = s.view(bucketRetry, func(tx kvTx) error {
+		b, err := bucket(tx, bucketRetry)
+		if err != nil {
+			return err
+		}
+		v := b.Get([]byte(name))
+		if v == nil {
+			return nil
+		}
+		if err := json.Unmarshal(v, &st); err != nil {
+			slog.Warn("corrupt entry in update retries bucket, skipping", "key", name, "error", err)
+			return nil
+		}
+		found = true
+		return nil
+	})
+	return st, found
+}
+
+// DeleteRetryState clears a container's retry state.
+func (s *Store) DeleteRetryState(name string) error {
+	return s.update(bucketRetry, func(tx kvTx) error {
+		b, err := bucket(tx, bucketRetry)
+		if err != nil {
+			return err
+		}
+		return b.Delete([]byte(name))
+	})
+}
+
+// AllRetryStates returns every container with failed update attempts.
+func (s *Store) AllRetryStates() map[string]RetryState {
+	result := make(map[string]RetryState)
+	_ = s.view(bucketRetry, func(tx kvTx) error {
+		b, err := bucket(tx, bucketRetry)
+		if err != nil {
+			return err
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var st RetryState
+			if err := json.Unmarshal(v, &st); err != nil {
+				slog.Warn("corrupt entry in update retries bucket, skipping", "key", string(k), "error", err)
+				return nil
+			}
+			result[string(k)] = st
+			return nil
+		})
+	})
+	return result
+}
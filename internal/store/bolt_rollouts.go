@@ -0,0 +1,115 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+// Rollout states.
+const (
+	RolloutRunning   = "running"   // the current group may update; later groups wait
+	RolloutPaused    = "paused"    // stopped by an operator until resumed or promoted
+	RolloutHalted    = "halted"    // stopped after a failed update or rollback
+	RolloutCompleted = "completed" // every group may update
+)
+
+// Rollout tracks one image update moving through the staged rollout
+// groups. It is keyed by Key: the image repository and the target version
+// or digest.
+type Rollout struct {
+	Key    string `json:"key"`
+	Image  string `json:"image"`  // repository, without tag or digest
+	Target string `json:"target"` // target version tag, or remote digest for same-tag updates
+	Group  string `json:"group"`  // group now allowed to update
+	State  string `json:"state"`
+	Reason string `json:"reason,omitempty"` // why the rollout is paused or halted
+	// Updated lists the containers updated in the current group. BakeUntil is
+	// when the group has baked long enough to promote the next one; zero
+	// until the group's first update.
+	Updated   []string  `json:"updated,omitempty"`
+	BakeUntil time.Time `json:"bake_until,omitzero"`
+	StartedAt time.Time `json:"started_at"`
+	// ResumedAt is when an operator last resumed or promoted the rollout;
+	// failures before it no longer halt it.
+	ResumedAt time.Time `json:"resumed_at,omitzero"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// RolloutKey returns the key of the rollout of image to target.
+func RolloutKey(image, target string) string {
+	return image + "@" + target
+}
+
+// SetRollout stores a rollout, replacing any existing entry with its key.
+func (s *Store) SetRollout(r Rollout) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("marshal rollout: %w", err)
+	}
+	return s.update(bucketRollouts, func(tx kvTx) error {
+		b, err := bucket(tx, bucketRollouts)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(r.Key), data)
+	})
+}
+
+// GetRollout returns a rollout by key, if any.
+func (s *Store) GetRollout(key string) (Rollout, bool) {
+	var r Rollout
+	var found bool
+	_ = s.view(bucketRollouts, func(tx kvTx) error {
+		b, err := bucket(tx, bucketRollouts)
+		if err != nil {
+			return err
+		}
+		v := b.Get([]byte(key))
+		if v == nil {
+			return nil
+		}
+		if err := json.Unmarshal(v, &r); err != nil {
+			slog.Warn("corrupt entry in rollouts bucket, skipping", "key", key, "error", err)
+			return nil
+		}
+		found = true
+		return nil
+	})
+	return r, found
+}
+
+// DeleteRollout removes a rollout.
+func (s *Store) DeleteRollout(key string) error {
+	return s.update(bucketRollouts, func(tx kvTx) error {
+		b, err := bucket(tx, bucketRollouts)
+		if err != nil {
+			return err
+		}
+		return b.Delete([]byte(key))
+	})
+}
+
+// ListRollouts returns every rollout, newest first.
+func (s *Store) ListRollouts() []Rollout {
+	var result []Rollout
+	_ = s.view(bucketRollouts, func(tx kvTx) error {
+		b, err := bucket(tx, bucketRollouts)
+		if err != nil {
+			return err
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var r Rollout
+			if err := json.Unmarshal(v, &r); err != nil {
+				slog.Warn("corrupt entry in rollouts bucket, skipping", "key", string(k), "error", err)
+				return nil
+			}
+			result = append(result, r)
+			return nil
+		})
+	})
+	sort.Slice(result, func(i, j int) bool { return result[i].StartedAt.After(result[j].StartedAt) })
+	return result
+}
@@ -0,0 +1,40 @@
+package store
+
+// SetContainerSchedule stores a container's cron schedule override, which
+// takes precedence over the sentinel.schedule label.
+func (s *Store) SetContainerSchedule(key, expr string) error {
+	return s.update(bucketSchedules, func(tx kvTx) error {
+		b, err := bucket(tx, bucketSchedules)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(key), []byte(expr))
+	})
+}
+
+// GetContainerSchedule returns a container's schedule override, if any.
+func (s *Store) GetContainerSchedule(key string) (string, bool) {
+	var expr string
+	_ = s.view(bucketSchedules, func(tx kvTx) error {
+		b, err := bucket(tx, bucketSchedules)
+		if err != nil {
+			return err
+		}
+		if v := b.Get([]byte(key)); v != nil {
+			expr = string(v)
+		}
+		return nil
+	})
+	return expr, expr != ""
+}
+
+// DeleteContainerSchedule removes a container's schedule override.
+func (s *Store) DeleteContainerSchedule(key string) error {
+	return s.update(bucketSchedules, func(tx kvTx) error {
+		b, err := bucket(tx, bucketSchedules)
+		if err != nil {
+			return err
+		}
+		return b.Delete([]byte(key))
+	})
+}
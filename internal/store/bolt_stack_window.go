@@ -0,0 +1,57 @@
+package store
+
+// SetStackWindow stores the maintenance window override of a Compose
+// project. It applies to the stack's containers in place of the global
+// maintenance window, unless a container sets its own sentinel.window label.
+func (s *Store) SetStackWindow(project, expr string) error {
+	return s.update(bucketStackWindows, func(tx kvTx) error {
+		b, err := bucket(tx, bucketStackWindows)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(project), []byte(expr))
+	})
+}
+
+// GetStackWindow returns a stack's maintenance window override, if any.
+func (s *Store) GetStackWindow(project string) (string, bool) {
+	var expr string
+	_ = s.view(bucketStackWindows, func(tx kvTx) error {
+		b, err := bucket(tx, bucketStackWindows)
+		if err != nil {
+			return err
+		}
+		if v := b.Get([]byte(project)); v != nil {
+			expr = string(v)
+		}
+		return nil
+	})
+	return expr, expr != ""
+}
+
+// DeleteStackWindow removes a stack's maintenance window override.
+func (s *Store) DeleteStackWindow(project string) error {
+	return s.update(bucketStackWindows, func(tx kvTx) error {
+		b, err := bucket(tx, bucketStackWindows)
+		if err != nil {
+			return err
+		}
+		return b.Delete([]byte(project))
+	})
+}
+
+// ListStackWindows returns every stack window override keyed by project.
+func (s *Store) ListStackWindows() (map[string]string, error) {
+	result := make(map[string]string)
+	err := s.view(bucketStackWindows, func(tx kvTx) error {
+		b, err := bucket(tx, bucketStackWindows)
+		if err != nil {
+			return err
+		}
+		return b.ForEach(func(k, v []byte) error {
+			result[string(k)] = string(v)
+			return nil
+		})
+	})
+	return result, err
+}
@@ -2,15 +2,20 @@ package store
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"testing"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
 )
 
+// testStore opens a fresh store. Set SENTINEL_TEST_DB_DRIVER=sqlite to run
+// the suite against the SQLite driver.
 func testStore(t *testing.T) *Store {
 	t.Helper()
 	path := filepath.Join(t.TempDir(), "test.db")
-	s, err := Open(path)
+	s, err := OpenDriver(os.Getenv("SENTINEL_TEST_DB_DRIVER"), path)
 	if err != nil {
 		t.Fatalf("Open(%q): %v", path, err)
 	}
@@ -491,6 +496,33 @@ func TestAppendLogDuplicateTimestamp(t *testing.T) {
 // Edge Cases: Pagination with before cursor
 // ---------------------------------------------------------------------------
 
+func TestListHistorySince(t *testing.T) {
+	s := testStore(t)
+
+	base := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		rec := UpdateRecord{
+			Timestamp:     base.Add(time.Duration(i) * time.Hour),
+			ContainerName: fmt.Sprintf("app-%d", i),
+			Outcome:       "success",
+		}
+		if err := s.RecordUpdate(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := s.ListHistorySince(base.Add(2 * time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(got))
+	}
+	if got[0].ContainerName != "app-2" || got[2].ContainerName != "app-4" {
+		t.Errorf("records = %s..%s, want app-2..app-4 oldest first", got[0].ContainerName, got[2].ContainerName)
+	}
+}
+
 func TestListHistoryPagination(t *testing.T) {
 	s := testStore(t)
 
@@ -561,3 +593,46 @@ func TestListHistoryPagination(t *testing.T) {
 		t.Errorf("page4: expected 0 records, got %d", len(page4))
 	}
 }
+
+// txSamples returns how many transactions were observed for the bucket and
+// mode in the hold-time histogram.
+func txSamples(t *testing.T, bucket, mode string) uint64 {
+	t.Helper()
+	mfs, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mf := range mfs {
+		if mf.GetName() != "sentinel_store_tx_hold_seconds" {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			labels := map[string]string{}
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			if labels["bucket"] == bucket && labels["mode"] == mode {
+				return m.GetHistogram().GetSampleCount()
+			}
+		}
+	}
+	return 0
+}
+
+func TestTxMetricsLabelledByBucket(t *testing.T) {
+	s := testStore(t)
+
+	writes, reads := txSamples(t, "history", "write"), txSamples(t, "history", "read")
+	if err := s.RecordUpdate(UpdateRecord{Timestamp: time.Now(), ContainerName: "app"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ListHistory(10, ""); err != nil {
+		t.Fatal(err)
+	}
+	if got := txSamples(t, "history", "write"); got != writes+1 {
+		t.Errorf("history writes observed = %d, want %d", got, writes+1)
+	}
+	if got := txSamples(t, "history", "read"); got != reads+1 {
+		t.Errorf("history reads observed = %d, want %d", got, reads+1)
+	}
+}
@@ -0,0 +1,86 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// TicketRef links a pending update's queue key to the ticket opened for it.
+type TicketRef struct {
+	Provider string    `json:"provider"` // "github" or "jira"
+	ID       string    `json:"id"`       // issue number or Jira issue key
+	URL      string    `json:"url,omitempty"`
+	OpenedAt time.Time `json:"opened_at"`
+}
+
+// SetTicket stores the ticket for a queue key.
+func (s *Store) SetTicket(key string, t TicketRef) error {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return fmt.Errorf("marshal ticket ref: %w", err)
+	}
+	return s.update(bucketTickets, func(tx kvTx) error {
+		b, err := bucket(tx, bucketTickets)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(key), data)
+	})
+}
+
+// GetTicket returns the ticket for a queue key, if any.
+func (s *Store) GetTicket(key string) (TicketRef, bool) {
+	var t TicketRef
+	var found bool
+	_ = s.view(bucketTickets, func(tx kvTx) error {
+		b, err := bucket(tx, bucketTickets)
+		if err != nil {
+			return err
+		}
+		v := b.Get([]byte(key))
+		if v == nil {
+			return nil
+		}
+		if err := json.Unmarshal(v, &t); err != nil {
+			slog.Warn("corrupt entry in tickets bucket, skipping", "key", key, "error", err)
+			return nil
+		}
+		found = true
+		return nil
+	})
+	return t, found
+}
+
+// DeleteTicket removes the ticket mapping for a queue key.
+func (s *Store) DeleteTicket(key string) error {
+	return s.update(bucketTickets, func(tx kvTx) error {
+		b, err := bucket(tx, bucketTickets)
+		if err != nil {
+			return err
+		}
+		return b.Delete([]byte(key))
+	})
+}
+
+// AllTickets returns every ticket mapping keyed by queue key.
+func (s *Store) AllTickets() map[string]TicketRef {
+	result := make(map[string]TicketRef)
+	_ = s.view(bucketTickets, func(tx kvTx) error {
+		b, err := bucket(tx, bucketTickets)
+		if err != nil {
+			return err
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var t TicketRef
+			if err := json.Unmarshal(v, &t); err != nil {
+				slog.Warn("corrupt entry in tickets bucket, skipping", "key", string(k), "error", err)
+				return nil
+			}
+			result[string(k)] = t
+			return nil
+		})
+	})
+	return result
+}
@@ -0,0 +1,62 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// TrustedKey is a cosign public key that image signatures are checked
+// against, keyed by name.
+type TrustedKey struct {
+	Name        string    `json:"name"`
+	PEM         string    `json:"pem"`
+	Fingerprint string    `json:"fingerprint"`
+	AddedAt     time.Time `json:"added_at"`
+}
+
+// ListTrustedKeys returns all trusted keys, ordered by name.
+func (s *Store) ListTrustedKeys() ([]TrustedKey, error) {
+	var keys []TrustedKey
+	err := s.view(bucketTrustedKeys, func(tx kvTx) error {
+		b, err := bucket(tx, bucketTrustedKeys)
+		if err != nil {
+			return err
+		}
+		return b.ForEach(func(_, v []byte) error {
+			var k TrustedKey
+			if err := json.Unmarshal(v, &k); err != nil {
+				return nil
+			}
+			keys = append(keys, k)
+			return nil
+		})
+	})
+	return keys, err
+}
+
+// SaveTrustedKey adds or replaces a trusted key.
+func (s *Store) SaveTrustedKey(key TrustedKey) error {
+	data, err := json.Marshal(key)
+	if err != nil {
+		return fmt.Errorf("marshal trusted key: %w", err)
+	}
+	return s.update(bucketTrustedKeys, func(tx kvTx) error {
+		b, err := bucket(tx, bucketTrustedKeys)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(key.Name), data)
+	})
+}
+
+// DeleteTrustedKey removes a trusted key by name.
+func (s *Store) DeleteTrustedKey(name string) error {
+	return s.update(bucketTrustedKeys, func(tx kvTx) error {
+		b, err := bucket(tx, bucketTrustedKeys)
+		if err != nil {
+			return err
+		}
+		return b.Delete([]byte(name))
+	})
+}
@@ -0,0 +1,54 @@
+package store
+
+import (
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/metrics"
+)
+
+// view runs fn in a read-only snapshot transaction and records how long it
+// waited for and held the transaction. name is the bucket the lock metrics
+// are labelled with, normally the first bucket fn reads.
+func (s *Store) view(name []byte, fn func(kvTx) error) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	queued := time.Now()
+	var started time.Time
+	err := s.db.view(func(tx kvTx) error {
+		started = time.Now()
+		return fn(tx)
+	})
+	observeTx(name, "read", queued, started)
+	return err
+}
+
+// update runs fn in a read-write transaction and records how long it waited
+// for the writer lock and how long it held it. Keep work inside fn small:
+// every other writer queues behind it. name labels the metrics as for view;
+// nil is recorded as "none".
+func (s *Store) update(name []byte, fn func(kvTx) error) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	queued := time.Now()
+	var started time.Time
+	err := s.db.update(func(tx kvTx) error {
+		started = time.Now()
+		return fn(tx)
+	})
+	observeTx(name, "write", queued, started)
+	return err
+}
+
+// observeTx records the wait and hold times of a finished transaction.
+// Nothing is recorded when the transaction never began.
+func observeTx(name []byte, mode string, queued, started time.Time) {
+	if started.IsZero() {
+		return
+	}
+	label := "none"
+	if len(name) > 0 {
+		label = string(name)
+	}
+	metrics.StoreTxWait.WithLabelValues(label, mode).Observe(started.Sub(queued).Seconds())
+	metrics.StoreTxHold.WithLabelValues(label, mode).Observe(time.Since(started).Seconds())
+}
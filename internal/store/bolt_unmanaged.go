@@ -0,0 +1,65 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// UnmanagedEntry marks a container as unmanaged: Sentinel ignores it entirely
+// (no scans, stats, queue entries or notifications). Keys use the same
+// scoping as policy overrides ("name" locally, "hostID::name" for remotes).
+type UnmanagedEntry struct {
+	Since time.Time `json:"since"`
+	// Hidden also removes the container from the dashboard. When false it
+	// still appears there, just without any update tracking.
+	Hidden bool `json:"hidden"`
+}
+
+// SetUnmanaged marks a container as unmanaged, replacing any existing entry.
+func (s *Store) SetUnmanaged(key string, entry UnmanagedEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("marshal unmanaged entry: %w", err)
+	}
+	return s.update(bucketUnmanaged, func(tx kvTx) error {
+		b, err := bucket(tx, bucketUnmanaged)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(key), data)
+	})
+}
+
+// DeleteUnmanaged returns a container to normal management.
+func (s *Store) DeleteUnmanaged(key string) error {
+	return s.update(bucketUnmanaged, func(tx kvTx) error {
+		b, err := bucket(tx, bucketUnmanaged)
+		if err != nil {
+			return err
+		}
+		return b.Delete([]byte(key))
+	})
+}
+
+// AllUnmanaged returns every unmanaged container keyed by name.
+func (s *Store) AllUnmanaged() map[string]UnmanagedEntry {
+	result := make(map[string]UnmanagedEntry)
+	_ = s.view(bucketUnmanaged, func(tx kvTx) error {
+		b, err := bucket(tx, bucketUnmanaged)
+		if err != nil {
+			return err
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var entry UnmanagedEntry
+			if err := json.Unmarshal(v, &entry); err != nil {
+				slog.Warn("corrupt entry in unmanaged bucket, skipping", "key", string(k), "error", err)
+				return nil
+			}
+			result[string(k)] = entry
+			return nil
+		})
+	})
+	return result
+}
@@ -0,0 +1,71 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// ValidationOverride replaces the label/global grace period and validation
+// strategy for one container. Zero fields fall through to the next source.
+type ValidationOverride struct {
+	GracePeriod time.Duration `json:"grace_period,omitempty"`
+	Strategy    string        `json:"strategy,omitempty"`  // "state", "healthcheck" or "probe"
+	ProbeURL    string        `json:"probe_url,omitempty"` // used by the "probe" strategy
+	// HealthTimeout bounds the wait for a healthcheck or probe to pass.
+	HealthTimeout time.Duration `json:"health_timeout,omitempty"`
+	// ProbeStatus and ProbeBody tighten an HTTP probe: the exact status
+	// expected (0 = any below 400) and a substring the body must contain.
+	ProbeStatus int    `json:"probe_status,omitempty"`
+	ProbeBody   string `json:"probe_body,omitempty"`
+}
+
+// SetValidationOverride stores a container's validation override.
+func (s *Store) SetValidationOverride(key string, o ValidationOverride) error {
+	data, err := json.Marshal(o)
+	if err != nil {
+		return fmt.Errorf("marshal validation override: %w", err)
+	}
+	return s.update(bucketValidation, func(tx kvTx) error {
+		b, err := bucket(tx, bucketValidation)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(key), data)
+	})
+}
+
+// GetValidationOverride returns a container's validation override, if any.
+func (s *Store) GetValidationOverride(key string) (ValidationOverride, bool) {
+	var o ValidationOverride
+	var found bool
+	_ = s.view(bucketValidation, func(tx kvTx) error {
+		b, err := bucket(tx, bucketValidation)
+		if err != nil {
+			return err
+		}
+		v := b.Get([]byte(key))
+		if v == nil {
+			return nil
+		}
+		if err := json.Unmarshal(v, &o); err != nil {
+			slog.Warn("corrupt entry in validation overrides bucket, skipping", "key", key, "error", err)
+			return nil
+		}
+		found = true
+		return nil
+	})
+	return o, found
+}
+
+// DeleteValidationOverride removes a container's validation override.
+func (s *Store) DeleteValidationOverride(key string) error {
+	return s.update(bucketValidation, func(tx kvTx) error {
+		b, err := bucket(tx, bucketValidation)
+		if err != nil {
+			return err
+		}
+		return b.Delete([]byte(key))
+	})
+}
@@ -0,0 +1,117 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// VerifyCacheEntry remembers that an image digest passed signature
+// verification, so unchanged images are not re-verified on every update.
+type VerifyCacheEntry struct {
+	Digest     string    `json:"digest"` // repo digest, e.g. nginx@sha256:...
+	ImageRef   string    `json:"image_ref"`
+	Key        string    `json:"key,omitempty"` // trusted key that verified it, or "keyless"
+	Policy     string    `json:"policy"`        // fingerprint of the verifier configuration
+	VerifiedAt time.Time `json:"verified_at"`
+}
+
+// GetVerifyCache returns the cached result for a digest, if any.
+func (s *Store) GetVerifyCache(digest string) (VerifyCacheEntry, bool, error) {
+	var e VerifyCacheEntry
+	var found bool
+	err := s.view(bucketVerifyCache, func(tx kvTx) error {
+		b, err := bucket(tx, bucketVerifyCache)
+		if err != nil {
+			return err
+		}
+		v := b.Get([]byte(digest))
+		if v == nil {
+			return nil
+		}
+		if err := json.Unmarshal(v, &e); err != nil {
+			return fmt.Errorf("unmarshal verify cache entry: %w", err)
+		}
+		found = true
+		return nil
+	})
+	return e, found, err
+}
+
+// SaveVerifyCache adds or replaces the cached result for a digest.
+func (s *Store) SaveVerifyCache(e VerifyCacheEntry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshal verify cache entry: %w", err)
+	}
+	return s.update(bucketVerifyCache, func(tx kvTx) error {
+		b, err := bucket(tx, bucketVerifyCache)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(e.Digest), data)
+	})
+}
+
+// ListVerifyCache returns every cached result, ordered by digest.
+func (s *Store) ListVerifyCache() ([]VerifyCacheEntry, error) {
+	var entries []VerifyCacheEntry
+	err := s.view(bucketVerifyCache, func(tx kvTx) error {
+		b, err := bucket(tx, bucketVerifyCache)
+		if err != nil {
+			return err
+		}
+		return b.ForEach(func(_, v []byte) error {
+			var e VerifyCacheEntry
+			if err := json.Unmarshal(v, &e); err != nil {
+				return nil
+			}
+			entries = append(entries, e)
+			return nil
+		})
+	})
+	return entries, err
+}
+
+// DeleteVerifyCache removes the cached result for a digest and reports
+// whether there was one.
+func (s *Store) DeleteVerifyCache(digest string) (bool, error) {
+	var found bool
+	err := s.update(bucketVerifyCache, func(tx kvTx) error {
+		b, err := bucket(tx, bucketVerifyCache)
+		if err != nil {
+			return err
+		}
+		found = b.Get([]byte(digest)) != nil
+		return b.Delete([]byte(digest))
+	})
+	return found, err
+}
+
+// ClearVerifyCache removes every cached result and returns how many there
+// were.
+func (s *Store) ClearVerifyCache() (int, error) {
+	var n int
+	err := s.update(bucketVerifyCache, func(tx kvTx) error {
+		b, err := bucket(tx, bucketVerifyCache)
+		if err != nil {
+			return err
+		}
+		// Collect keys first — mutating during iteration is undefined behaviour in BoltDB.
+		var keys [][]byte
+		if err := b.ForEach(func(k, _ []byte) error {
+			keys = append(keys, append([]byte(nil), k...))
+			return nil
+		}); err != nil {
+			return err
+		}
+		for _, k := range keys {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		n = len(keys)
+		return nil
+	})
+	return n, err
+}
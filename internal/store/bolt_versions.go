@@ -0,0 +1,84 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Version sources, in order of preference.
+const (
+	VersionSourceLabel    = "label"    // org.opencontainers.image.version
+	VersionSourceResolved = "resolved" // registry tag matching the running digest
+	VersionSourceTag      = "tag"      // the image tag itself
+)
+
+// VersionInfo is the human-readable version of a monitored container,
+// refreshed on every scan. Keys use the same scoping as policy overrides.
+type VersionInfo struct {
+	Tag       string    `json:"tag"`                 // image tag as configured (e.g. "latest")
+	Current   string    `json:"current,omitempty"`   // normalised running version
+	Candidate string    `json:"candidate,omitempty"` // normalised update version, when one is available
+	Source    string    `json:"source,omitempty"`    // where Current came from
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SetVersionInfo stores the resolved version for a container.
+func (s *Store) SetVersionInfo(key string, info VersionInfo) error {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("marshal version info: %w", err)
+	}
+	return s.update(bucketVersions, func(tx kvTx) error {
+		b, err := bucket(tx, bucketVersions)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(key), data)
+	})
+}
+
+// GetVersionInfo returns the stored version for a container, if any.
+func (s *Store) GetVersionInfo(key string) (VersionInfo, bool) {
+	var info VersionInfo
+	var found bool
+	_ = s.view(bucketVersions, func(tx kvTx) error {
+		b, err := bucket(tx, bucketVersions)
+		if err != nil {
+			return err
+		}
+		v := b.Get([]byte(key))
+		if v == nil {
+			return nil
+		}
+		if err := json.Unmarshal(v, &info); err != nil {
+			slog.Warn("corrupt entry in versions bucket, skipping", "key", key, "error", err)
+			return nil
+		}
+		found = true
+		return nil
+	})
+	return info, found
+}
+
+// AllVersionInfo returns every stored version keyed by container.
+func (s *Store) AllVersionInfo() map[string]VersionInfo {
+	result := make(map[string]VersionInfo)
+	_ = s.view(bucketVersions, func(tx kvTx) error {
+		b, err := bucket(tx, bucketVersions)
+		if err != nil {
+			return err
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var info VersionInfo
+			if err := json.Unmarshal(v, &info); err != nil {
+				slog.Warn("corrupt entry in versions bucket, skipping", "key", string(k), "error", err)
+				return nil
+			}
+			result[string(k)] = info
+			return nil
+		})
+	})
+	return result
+}
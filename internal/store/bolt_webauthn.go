@@ -6,8 +6,6 @@ import (
 	"encoding/json"
 	"fmt"
 
-	bolt "go.etcd.io/bbolt"
-
 	"github.com/Will-Luck/Docker-Sentinel/internal/auth"
 )
 
@@ -35,7 +33,7 @@ func (s *Store) CreateWebAuthnCredential(cred auth.WebAuthnCredential) error {
 	if err != nil {
 		return fmt.Errorf("marshal webauthn credential: %w", err)
 	}
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketWebAuthnCreds, func(tx kvTx) error {
 		b, err := bucket(tx, bucketWebAuthnCreds)
 		if err != nil {
 			return err
@@ -68,7 +66,7 @@ func (s *Store) CreateWebAuthnCredential(cred auth.WebAuthnCredential) error {
 // GetWebAuthnCredential retrieves a credential by its ID.
 func (s *Store) GetWebAuthnCredential(credID []byte) (*auth.WebAuthnCredential, error) {
 	var cred auth.WebAuthnCredential
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketWebAuthnCreds, func(tx kvTx) error {
 		b, err := bucket(tx, bucketWebAuthnCreds)
 		if err != nil {
 			return err
@@ -88,7 +86,7 @@ func (s *Store) GetWebAuthnCredential(credID []byte) (*auth.WebAuthnCredential,
 // ListWebAuthnCredentialsForUser returns all credentials for a user.
 func (s *Store) ListWebAuthnCredentialsForUser(userID string) ([]auth.WebAuthnCredential, error) {
 	var creds []auth.WebAuthnCredential
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketWebAuthnCreds, func(tx kvTx) error {
 		b, err := bucket(tx, bucketWebAuthnCreds)
 		if err != nil {
 			return err
@@ -119,7 +117,7 @@ func (s *Store) ListWebAuthnCredentialsForUser(userID string) ([]auth.WebAuthnCr
 
 // DeleteWebAuthnCredential removes a credential and its indexes.
 func (s *Store) DeleteWebAuthnCredential(credID []byte) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketWebAuthnCreds, func(tx kvTx) error {
 		b, err := bucket(tx, bucketWebAuthnCreds)
 		if err != nil {
 			return err
@@ -146,7 +144,7 @@ func (s *Store) DeleteWebAuthnCredential(credID []byte) error {
 // GetUserByWebAuthnHandle looks up a user by WebAuthn user handle (for discoverable login).
 func (s *Store) GetUserByWebAuthnHandle(handle []byte) (*auth.User, error) {
 	var user auth.User
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketWebAuthnCreds, func(tx kvTx) error {
 		wb, err := bucket(tx, bucketWebAuthnCreds)
 		if err != nil {
 			return err
@@ -174,7 +172,7 @@ func (s *Store) GetUserByWebAuthnHandle(handle []byte) (*auth.User, error) {
 // AnyWebAuthnCredentialsExist checks if any passkeys are registered system-wide.
 func (s *Store) AnyWebAuthnCredentialsExist() (bool, error) {
 	var exists bool
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketWebAuthnCreds, func(tx kvTx) error {
 		b, err := bucket(tx, bucketWebAuthnCreds)
 		if err != nil {
 			return err
@@ -3,8 +3,6 @@ package store
 import (
 	"encoding/json"
 	"fmt"
-
-	bolt "go.etcd.io/bbolt"
 )
 
 // PortConfig holds per-port URL overrides for a container.
@@ -22,7 +20,7 @@ type PortOverride struct {
 // Returns nil, nil if no configuration exists.
 func (s *Store) GetPortConfig(name string) (*PortConfig, error) {
 	var cfg *PortConfig
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketPortConfig, func(tx kvTx) error {
 		b, err := bucket(tx, bucketPortConfig)
 		if err != nil {
 			return err
@@ -45,7 +43,7 @@ func (s *Store) GetPortConfig(name string) (*PortConfig, error) {
 // SetPortOverride sets a URL override for a specific port on a container.
 // Creates a new PortConfig if one doesn't exist.
 func (s *Store) SetPortOverride(name string, hostPort uint16, override PortOverride) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketPortConfig, func(tx kvTx) error {
 		b, err := bucket(tx, bucketPortConfig)
 		if err != nil {
 			return err
@@ -79,7 +77,7 @@ func (s *Store) SetPortOverride(name string, hostPort uint16, override PortOverr
 // DeletePortOverride removes the URL override for a specific port on a container.
 // If no ports remain, the entire entry is deleted.
 func (s *Store) DeletePortOverride(name string, hostPort uint16) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketPortConfig, func(tx kvTx) error {
 		b, err := bucket(tx, bucketPortConfig)
 		if err != nil {
 			return err
@@ -118,7 +116,7 @@ func (s *Store) DeletePortOverride(name string, hostPort uint16) error {
 // AllPortConfigs returns all stored port configurations, keyed by container name.
 func (s *Store) AllPortConfigs() (map[string]*PortConfig, error) {
 	result := make(map[string]*PortConfig)
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketPortConfig, func(tx kvTx) error {
 		b, err := bucket(tx, bucketPortConfig)
 		if err != nil {
 			return err
@@ -0,0 +1,140 @@
+package store
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Storage drivers, selected with SENTINEL_DB_DRIVER.
+const (
+	DriverBolt   = "bolt"   // BoltDB file (default)
+	DriverSQLite = "sqlite" // SQLite file via the pure-Go modernc driver
+)
+
+// errTxReadOnly is returned for a write attempted inside a view.
+var errTxReadOnly = errors.New("tx not writable")
+
+// kvDB is the storage engine behind a Store: named buckets of byte-ordered
+// keys, read and written in serialisable transactions. The store's methods
+// are written against this BoltDB-shaped API, so the SQLite driver only has
+// to provide the same semantics on top of a single table.
+type kvDB interface {
+	view(fn func(kvTx) error) error
+	update(fn func(kvTx) error) error
+	// check runs a full consistency check of the database file.
+	check() error
+	// writeTo writes a consistent copy of the live database to w.
+	writeTo(w io.Writer) (int64, error)
+	// compactTo writes a defragmented copy of the database to a new file.
+	compactTo(path string) error
+	close() error
+}
+
+// kvTx is an open transaction.
+type kvTx interface {
+	// Bucket returns the named bucket, or nil if it does not exist.
+	Bucket(name []byte) kvBucket
+	CreateBucketIfNotExists(name []byte) (kvBucket, error)
+	// ForEachBucket calls fn for every top-level bucket in name order.
+	ForEachBucket(fn func(name []byte, b kvBucket) error) error
+}
+
+// kvBucket is a set of keys kept in byte order. Slices returned by Get and
+// by cursors are only valid until the transaction ends.
+type kvBucket interface {
+	Get(key []byte) []byte
+	Put(key, value []byte) error
+	Delete(key []byte) error
+	// ForEach calls fn for every key in order. fn must not modify the bucket.
+	ForEach(fn func(k, v []byte) error) error
+	Cursor() kvCursor
+	NextSequence() (uint64, error)
+	Sequence() uint64
+	SetSequence(v uint64) error
+	// KeyN returns the number of keys in the bucket.
+	KeyN() int
+}
+
+// kvCursor walks a bucket in key order. Moving off either end returns a
+// nil key.
+type kvCursor interface {
+	First() (key, value []byte)
+	Last() (key, value []byte)
+	Next() (key, value []byte)
+	Prev() (key, value []byte)
+	Seek(seek []byte) (key, value []byte)
+}
+
+// storeBuckets are created whenever a database is opened. Auth buckets are
+// created separately by EnsureAuthBuckets.
+var storeBuckets = [][]byte{bucketSnapshots, bucketHistory, bucketState, bucketQueue, bucketPolicies, bucketLogs, bucketSettings, bucketNotifyState, bucketNotifyPrefs, bucketIgnoredVersions, bucketRegistryCreds, bucketRateLimits, bucketGHCRAlternatives, bucketHooks, bucketReleaseSources, bucketNotifyTemplates, bucketPortConfig, bucketUnmanaged, bucketUpdateJournal, bucketVersions, bucketValidation, bucketPreconditions, bucketTimedPins, bucketTickets, bucketRetry, bucketSchedules, bucketComposePaths, bucketTrustedKeys, bucketDeployTokens, bucketDeployRequests, bucketAudit, bucketVerifyCache, bucketRebuildWatch, bucketRollouts, bucketStackWindows, bucketClusterHosts, bucketClusterTokens, bucketClusterJournal, bucketClusterConfigCache, bucketClusterRevoked, bucketClusterHostEvents, bucketDigestEquiv, bucketPortainerInstances}
+
+// openKV opens or creates the database at path with the given driver and
+// creates any missing buckets.
+func openKV(driver, path string) (kvDB, error) {
+	var db kvDB
+	var err error
+	switch driver {
+	case "", DriverBolt:
+		db, err = openBoltDB(path, false)
+	case DriverSQLite:
+		db, err = openSQLiteDB(path, false)
+	default:
+		return nil, fmt.Errorf("unknown database driver %q", driver)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.update(func(tx kvTx) error {
+		for _, b := range storeBuckets {
+			if _, err := tx.CreateBucketIfNotExists(b); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.close()
+		return nil, fmt.Errorf("create buckets: %w", err)
+	}
+	return db, nil
+}
+
+// openKVReadOnly opens an existing database file for inspection, detecting
+// the driver that wrote it. Nothing is created or modified.
+func openKVReadOnly(path string) (kvDB, string, error) {
+	driver, err := detectDriver(path)
+	if err != nil {
+		return nil, "", err
+	}
+	var db kvDB
+	if driver == DriverSQLite {
+		db, err = openSQLiteDB(path, true)
+	} else {
+		db, err = openBoltDB(path, true)
+	}
+	return db, driver, err
+}
+
+// sqliteMagic starts every SQLite database file.
+var sqliteMagic = []byte("SQLite format 3\x00")
+
+// detectDriver reports which driver wrote the database file at path.
+// Anything that is not a SQLite file is handed to BoltDB, which rejects
+// files it does not recognise.
+func detectDriver(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	header := make([]byte, len(sqliteMagic))
+	if _, err := io.ReadFull(f, header); err == nil && bytes.Equal(header, sqliteMagic) {
+		return DriverSQLite, nil
+	}
+	return DriverBolt, nil
+}
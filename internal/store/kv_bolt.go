@@ -0,0 +1,114 @@
+package store
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// boltDB is the default driver: a single BoltDB file.
+type boltDB struct {
+	db *bolt.DB
+}
+
+func openBoltDB(path string, readOnly bool) (*boltDB, error) {
+	opts := &bolt.Options{Timeout: 5 * time.Second}
+	if readOnly {
+		opts = &bolt.Options{Timeout: time.Second, ReadOnly: true}
+	}
+	db, err := bolt.Open(path, 0600, opts)
+	if err != nil {
+		return nil, fmt.Errorf("open bolt db: %w", err)
+	}
+	return &boltDB{db: db}, nil
+}
+
+func (b *boltDB) view(fn func(kvTx) error) error {
+	return b.db.View(func(tx *bolt.Tx) error { return fn(boltTx{tx}) })
+}
+
+func (b *boltDB) update(fn func(kvTx) error) error {
+	return b.db.Update(func(tx *bolt.Tx) error { return fn(boltTx{tx}) })
+}
+
+func (b *boltDB) check() error {
+	return b.db.View(func(tx *bolt.Tx) error {
+		// Drain every error so the checker is done before the tx closes.
+		var first error
+		for err := range tx.Check() {
+			if first == nil {
+				first = err
+			}
+		}
+		return first
+	})
+}
+
+// writeTo holds a read transaction for the whole copy, during which the
+// file can't be remapped.
+func (b *boltDB) writeTo(w io.Writer) (int64, error) {
+	var n int64
+	err := b.db.View(func(tx *bolt.Tx) error {
+		var err error
+		n, err = tx.WriteTo(w)
+		return err
+	})
+	return n, err
+}
+
+func (b *boltDB) compactTo(path string) error {
+	dst, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return fmt.Errorf("create compacted database: %w", err)
+	}
+	if err := bolt.Compact(dst, b.db, compactTxSize); err != nil {
+		dst.Close()
+		return fmt.Errorf("compact database: %w", err)
+	}
+	return dst.Close()
+}
+
+func (b *boltDB) close() error {
+	return b.db.Close()
+}
+
+// boltTx adapts a BoltDB transaction to kvTx.
+type boltTx struct {
+	tx *bolt.Tx
+}
+
+func (t boltTx) Bucket(name []byte) kvBucket {
+	b := t.tx.Bucket(name)
+	if b == nil {
+		return nil
+	}
+	return boltBucket{b}
+}
+
+func (t boltTx) CreateBucketIfNotExists(name []byte) (kvBucket, error) {
+	b, err := t.tx.CreateBucketIfNotExists(name)
+	if err != nil {
+		return nil, err
+	}
+	return boltBucket{b}, nil
+}
+
+func (t boltTx) ForEachBucket(fn func(name []byte, b kvBucket) error) error {
+	return t.tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+		return fn(name, boltBucket{b})
+	})
+}
+
+type boltBucket struct {
+	*bolt.Bucket
+}
+
+func (b boltBucket) Cursor() kvCursor {
+	return b.Bucket.Cursor()
+}
+
+func (b boltBucket) KeyN() int {
+	return b.Stats().KeyN
+}
@@ -0,0 +1,423 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	_ "modernc.org/sqlite" // registers the "sqlite" database/sql driver
+)
+
+// sqliteSchema keeps every bucket in one table. SQLite compares BLOBs with
+// memcmp, so keys sort in the same byte order BoltDB uses and cursors behave
+// the same on both drivers.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS buckets (
+	name TEXT PRIMARY KEY,
+	seq  INTEGER NOT NULL DEFAULT 0
+);
+CREATE TABLE IF NOT EXISTS kv (
+	bucket TEXT NOT NULL,
+	key    BLOB NOT NULL,
+	value  BLOB NOT NULL,
+	PRIMARY KEY (bucket, key)
+) WITHOUT ROWID;`
+
+// sqliteDB is the SQLite driver. The file runs in WAL mode, so reads never
+// wait for the writer and a long read does not block writes.
+type sqliteDB struct {
+	db   *sql.DB
+	path string
+	// writer serialises write transactions. SQLite allows one writer at a
+	// time; queueing here matches BoltDB and keeps writers off busy_timeout.
+	writer sync.Mutex
+}
+
+// sqliteDSN builds the connection string. Read-only handles are used to
+// inspect backups and must not convert or create anything.
+func sqliteDSN(path string, readOnly bool) string {
+	q := url.Values{}
+	q.Add("_pragma", "busy_timeout(5000)")
+	if readOnly {
+		q.Set("mode", "ro")
+	} else {
+		q.Add("_pragma", "journal_mode(WAL)")
+		q.Add("_pragma", "synchronous(NORMAL)")
+		q.Set("_txlock", "immediate")
+	}
+	return "file:" + (&url.URL{Path: path}).EscapedPath() + "?" + q.Encode()
+}
+
+func openSQLiteDB(path string, readOnly bool) (*sqliteDB, error) {
+	if readOnly {
+		if _, err := os.Stat(path); err != nil {
+			return nil, fmt.Errorf("open sqlite db: %w", err)
+		}
+	}
+	db, err := sql.Open("sqlite", sqliteDSN(path, readOnly))
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite db: %w", err)
+	}
+	if !readOnly {
+		if _, err := db.Exec(sqliteSchema); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("open sqlite db: %w", err)
+		}
+	} else if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("open sqlite db: %w", err)
+	}
+	return &sqliteDB{db: db, path: path}, nil
+}
+
+func (d *sqliteDB) view(fn func(kvTx) error) error {
+	return d.run(false, fn)
+}
+
+func (d *sqliteDB) update(fn func(kvTx) error) error {
+	d.writer.Lock()
+	defer d.writer.Unlock()
+	return d.run(true, fn)
+}
+
+// run executes fn in a transaction, committing writable ones when fn and
+// every statement inside it succeeded.
+func (d *sqliteDB) run(writable bool, fn func(kvTx) error) error {
+	tx, err := d.db.BeginTx(context.Background(), &sql.TxOptions{ReadOnly: !writable})
+	if err != nil {
+		return fmt.Errorf("begin sqlite tx: %w", err)
+	}
+	t := &sqliteTx{tx: tx, writable: writable}
+	if err := fn(t); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if t.err != nil {
+		tx.Rollback()
+		return t.err
+	}
+	if !writable {
+		return tx.Rollback()
+	}
+	return tx.Commit()
+}
+
+func (d *sqliteDB) check() error {
+	rows, err := d.db.Query(`PRAGMA integrity_check`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	var problems []string
+	for rows.Next() {
+		var msg string
+		if err := rows.Scan(&msg); err != nil {
+			return err
+		}
+		if msg != "ok" {
+			problems = append(problems, msg)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// writeTo snapshots the database into a temporary file next to it with
+// VACUUM INTO, then copies that file to w. The snapshot is a read
+// transaction, so writers carry on meanwhile.
+func (d *sqliteDB) writeTo(w io.Writer) (int64, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(d.path), filepath.Base(d.path)+".snapshot-*")
+	if err != nil {
+		return 0, fmt.Errorf("create snapshot file: %w", err)
+	}
+	name := tmp.Name()
+	tmp.Close()
+	defer os.Remove(name)
+	// VACUUM INTO refuses to overwrite anything but an empty file.
+	if err := d.compactTo(name); err != nil {
+		return 0, err
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	return io.Copy(w, f)
+}
+
+func (d *sqliteDB) compactTo(path string) error {
+	if _, err := d.db.Exec(`VACUUM INTO ?`, path); err != nil {
+		return fmt.Errorf("compact database: %w", err)
+	}
+	return nil
+}
+
+// close checkpoints the write-ahead log into the main file first, so the
+// file can be copied or swapped on its own.
+func (d *sqliteDB) close() error {
+	_, _ = d.db.Exec(`PRAGMA wal_checkpoint(TRUNCATE)`)
+	return d.db.Close()
+}
+
+// sqliteTx adapts a database/sql transaction to kvTx. The bucket and
+// cursor methods have no error results, so the first failed statement is
+// kept in err and fails the whole transaction when fn returns.
+type sqliteTx struct {
+	tx       *sql.Tx
+	writable bool
+	err      error
+}
+
+func (t *sqliteTx) fail(err error) {
+	if t.err == nil && err != nil {
+		t.err = err
+	}
+}
+
+func (t *sqliteTx) Bucket(name []byte) kvBucket {
+	var one int
+	err := t.tx.QueryRow(`SELECT 1 FROM buckets WHERE name = ?`, string(name)).Scan(&one)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			t.fail(err)
+		}
+		return nil
+	}
+	return &sqliteBucket{tx: t, name: string(name)}
+}
+
+func (t *sqliteTx) CreateBucketIfNotExists(name []byte) (kvBucket, error) {
+	if !t.writable {
+		return nil, errTxReadOnly
+	}
+	if len(name) == 0 {
+		return nil, errors.New("bucket name required")
+	}
+	if _, err := t.tx.Exec(`INSERT INTO buckets (name) VALUES (?) ON CONFLICT (name) DO NOTHING`, string(name)); err != nil {
+		return nil, err
+	}
+	return &sqliteBucket{tx: t, name: string(name)}, nil
+}
+
+func (t *sqliteTx) ForEachBucket(fn func(name []byte, b kvBucket) error) error {
+	rows, err := t.tx.Query(`SELECT name FROM buckets ORDER BY name`)
+	if err != nil {
+		return err
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		names = append(names, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := fn([]byte(name), &sqliteBucket{tx: t, name: name}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type sqliteBucket struct {
+	tx   *sqliteTx
+	name string
+}
+
+func (b *sqliteBucket) Get(key []byte) []byte {
+	var v []byte
+	err := b.tx.tx.QueryRow(`SELECT value FROM kv WHERE bucket = ? AND key = ?`, b.name, key).Scan(&v)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			b.tx.fail(err)
+		}
+		return nil
+	}
+	if v == nil {
+		v = []byte{}
+	}
+	return v
+}
+
+func (b *sqliteBucket) Put(key, value []byte) error {
+	if !b.tx.writable {
+		return errTxReadOnly
+	}
+	if len(key) == 0 {
+		return errors.New("key required")
+	}
+	if value == nil {
+		value = []byte{}
+	}
+	_, err := b.tx.tx.Exec(`INSERT INTO kv (bucket, key, value) VALUES (?, ?, ?)
+		ON CONFLICT (bucket, key) DO UPDATE SET value = excluded.value`, b.name, key, value)
+	return err
+}
+
+func (b *sqliteBucket) Delete(key []byte) error {
+	if !b.tx.writable {
+		return errTxReadOnly
+	}
+	_, err := b.tx.tx.Exec(`DELETE FROM kv WHERE bucket = ? AND key = ?`, b.name, key)
+	return err
+}
+
+// ForEach reads the bucket before calling fn, so fn may run other
+// statements in the same transaction.
+func (b *sqliteBucket) ForEach(fn func(k, v []byte) error) error {
+	rows, err := b.tx.tx.Query(`SELECT key, value FROM kv WHERE bucket = ? ORDER BY key`, b.name)
+	if err != nil {
+		return err
+	}
+	var keys, values [][]byte
+	for rows.Next() {
+		var k, v []byte
+		if err := rows.Scan(&k, &v); err != nil {
+			rows.Close()
+			return err
+		}
+		keys, values = append(keys, k), append(values, v)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for i := range keys {
+		if err := fn(keys[i], values[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *sqliteBucket) Cursor() kvCursor {
+	return &sqliteCursor{b: b}
+}
+
+func (b *sqliteBucket) NextSequence() (uint64, error) {
+	if !b.tx.writable {
+		return 0, errTxReadOnly
+	}
+	var seq uint64
+	err := b.tx.tx.QueryRow(`UPDATE buckets SET seq = seq + 1 WHERE name = ? RETURNING seq`, b.name).Scan(&seq)
+	return seq, err
+}
+
+func (b *sqliteBucket) Sequence() uint64 {
+	var seq uint64
+	if err := b.tx.tx.QueryRow(`SELECT seq FROM buckets WHERE name = ?`, b.name).Scan(&seq); err != nil {
+		b.tx.fail(err)
+	}
+	return seq
+}
+
+func (b *sqliteBucket) SetSequence(v uint64) error {
+	if !b.tx.writable {
+		return errTxReadOnly
+	}
+	_, err := b.tx.tx.Exec(`UPDATE buckets SET seq = ? WHERE name = ?`, v, b.name)
+	return err
+}
+
+func (b *sqliteBucket) KeyN() int {
+	var n int
+	if err := b.tx.tx.QueryRow(`SELECT COUNT(*) FROM kv WHERE bucket = ?`, b.name).Scan(&n); err != nil {
+		b.tx.fail(err)
+	}
+	return n
+}
+
+// Cursor positions, tracked so stepping off an end behaves as in BoltDB.
+const (
+	cursorUnset = iota
+	cursorAt
+	cursorPastEnd
+)
+
+// sqliteCursor re-queries from the current key on every move, so the
+// bucket may be modified while it is open.
+type sqliteCursor struct {
+	b   *sqliteBucket
+	key []byte
+	pos int
+}
+
+func (c *sqliteCursor) First() ([]byte, []byte) {
+	return c.move(`ORDER BY key LIMIT 1`)
+}
+
+func (c *sqliteCursor) Last() ([]byte, []byte) {
+	return c.move(`ORDER BY key DESC LIMIT 1`)
+}
+
+func (c *sqliteCursor) Seek(seek []byte) ([]byte, []byte) {
+	if len(seek) == 0 {
+		return c.First()
+	}
+	return c.move(`AND key >= ? ORDER BY key LIMIT 1`, seek)
+}
+
+// Next and Prev step from the current key. Running off either end leaves
+// the cursor on that key, as BoltDB does.
+func (c *sqliteCursor) Next() ([]byte, []byte) {
+	if c.pos != cursorAt {
+		return nil, nil
+	}
+	return c.query(`AND key > ? ORDER BY key LIMIT 1`, c.key)
+}
+
+func (c *sqliteCursor) Prev() ([]byte, []byte) {
+	switch c.pos {
+	case cursorAt:
+		return c.query(`AND key < ? ORDER BY key DESC LIMIT 1`, c.key)
+	case cursorPastEnd:
+		return c.Last()
+	}
+	return nil, nil
+}
+
+// move runs a positioning query. Finding nothing leaves the cursor past
+// the end, from where Prev returns the last key.
+func (c *sqliteCursor) move(clause string, args ...any) ([]byte, []byte) {
+	k, v := c.query(clause, args...)
+	if k == nil {
+		c.pos, c.key = cursorPastEnd, nil
+	}
+	return k, v
+}
+
+func (c *sqliteCursor) query(clause string, args ...any) ([]byte, []byte) {
+	var k, v []byte
+	err := c.b.tx.tx.QueryRow(`SELECT key, value FROM kv WHERE bucket = ? `+clause,
+		append([]any{c.b.name}, args...)...).Scan(&k, &v)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			c.b.tx.fail(err)
+		}
+		return nil, nil
+	}
+	if v == nil {
+		v = []byte{}
+	}
+	c.pos, c.key = cursorAt, bytes.Clone(k)
+	return k, v
+}
@@ -0,0 +1,85 @@
+package store
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"testing"
+)
+
+// openTestKV opens an empty database with each driver.
+func openTestKV(t *testing.T) map[string]kvDB {
+	t.Helper()
+	dbs := map[string]kvDB{}
+	for _, driver := range []string{DriverBolt, DriverSQLite} {
+		db, err := openKV(driver, filepath.Join(t.TempDir(), driver+".db"))
+		if err != nil {
+			t.Fatalf("openKV(%s): %v", driver, err)
+		}
+		t.Cleanup(func() { db.close() })
+		dbs[driver] = db
+	}
+	return dbs
+}
+
+// TestKVCursorConformance replays the same random cursor walks on both
+// drivers, including stepping off either end, and expects identical keys.
+func TestKVCursorConformance(t *testing.T) {
+	dbs := openTestKV(t)
+	keys := []string{"a", "a::1", "a::2", "b", "b::1", "c", "c::;", "d"}
+	for _, db := range dbs {
+		if err := db.update(func(tx kvTx) error {
+			b := tx.Bucket(bucketLogs)
+			for _, k := range keys {
+				if err := b.Put([]byte(k), []byte("v-"+k)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	seeks := []string{"", "0", "a", "a::", "a::3", "b::1", "c::", "d", "z"}
+	rng := rand.New(rand.NewSource(1))
+	for walk := 0; walk < 200; walk++ {
+		ops := make([]int, 12)
+		for i := range ops {
+			// Weight steps over jumps so walks run off the ends.
+			if ops[i] = rng.Intn(4 + len(seeks)); ops[i] >= 4 && rng.Intn(2) == 0 {
+				ops[i] = 2 + rng.Intn(2)
+			}
+		}
+		results := map[string][]string{}
+		for driver, db := range dbs {
+			_ = db.view(func(tx kvTx) error {
+				c := tx.Bucket(bucketLogs).Cursor()
+				for _, op := range ops {
+					var k, v []byte
+					switch op {
+					case 0:
+						k, v = c.First()
+					case 1:
+						k, v = c.Last()
+					case 2:
+						k, v = c.Next()
+					case 3:
+						k, v = c.Prev()
+					default:
+						k, v = c.Seek([]byte(seeks[op-4]))
+					}
+					if k != nil && !bytes.Equal(v, []byte("v-"+string(k))) {
+						t.Errorf("%s: value for %q = %q", driver, k, v)
+					}
+					results[driver] = append(results[driver], fmt.Sprintf("%d:%s", op, k))
+				}
+				return nil
+			})
+		}
+		if fmt.Sprint(results[DriverBolt]) != fmt.Sprint(results[DriverSQLite]) {
+			t.Fatalf("walk %d differs:\nbolt   %v\nsqlite %v", walk, results[DriverBolt], results[DriverSQLite])
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// MigrateStats summarises a completed migration.
+type MigrateStats struct {
+	Buckets int
+	Keys    int
+}
+
+// MigrateBoltToSQLite copies every bucket of the BoltDB database at src into
+// a new SQLite database at dst, keeping keys, values and bucket sequences
+// as they are. Secrets encrypted at rest stay sealed with the same key.
+// Sentinel must be stopped first: BoltDB locks the file while it is open.
+// dst must not exist yet; a failed migration removes it again.
+func MigrateBoltToSQLite(src, dst string) (MigrateStats, error) {
+	var stats MigrateStats
+	if _, err := os.Stat(dst); err == nil {
+		return stats, fmt.Errorf("%s already exists", dst)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return stats, err
+	}
+	if driver, err := detectDriver(src); err != nil {
+		return stats, err
+	} else if driver != DriverBolt {
+		return stats, fmt.Errorf("%s is not a BoltDB database", src)
+	}
+
+	from, err := openBoltDB(src, true)
+	if err != nil {
+		return stats, fmt.Errorf("%w (is Sentinel still running?)", err)
+	}
+	defer from.close()
+
+	to, err := openKV(DriverSQLite, dst)
+	if err != nil {
+		return stats, err
+	}
+	if stats, err = copyKV(from, to); err != nil {
+		to.close()
+		removeSQLiteFiles(dst)
+		return MigrateStats{}, err
+	}
+	if err := to.close(); err != nil {
+		removeSQLiteFiles(dst)
+		return MigrateStats{}, fmt.Errorf("close %s: %w", dst, err)
+	}
+	return stats, nil
+}
+
+// copyKV copies every top-level bucket of src into dst, one write
+// transaction per bucket.
+func copyKV(src, dst kvDB) (MigrateStats, error) {
+	var stats MigrateStats
+	err := src.view(func(stx kvTx) error {
+		return stx.ForEachBucket(func(name []byte, sb kvBucket) error {
+			err := dst.update(func(dtx kvTx) error {
+				db, err := dtx.CreateBucketIfNotExists(name)
+				if err != nil {
+					return err
+				}
+				if err := sb.ForEach(func(k, v []byte) error {
+					if v == nil {
+						return fmt.Errorf("nested bucket %q is not supported", k)
+					}
+					stats.Keys++
+					return db.Put(k, v)
+				}); err != nil {
+					return err
+				}
+				return db.SetSequence(sb.Sequence())
+			})
+			if err != nil {
+				return fmt.Errorf("copy bucket %q: %w", name, err)
+			}
+			stats.Buckets++
+			return nil
+		})
+	})
+	return stats, err
+}
+
+// removeSQLiteFiles deletes a SQLite database with its WAL and shared
+// memory files.
+func removeSQLiteFiles(path string) {
+	for _, suffix := range []string{"", "-wal", "-shm"} {
+		_ = os.Remove(path + suffix)
+	}
+}
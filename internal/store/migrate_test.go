@@ -0,0 +1,93 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMigrateBoltToSQLite(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "sentinel.db")
+	dst := filepath.Join(dir, "sentinel.sqlite")
+
+	s, err := Open(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.EnsureAuthBuckets(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveSetting("poll_interval", "6h"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveSetting("empty", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AppendAudit(AuditEntry{Path: "/api/settings"}); err != nil {
+		t.Fatal(err)
+	}
+	var seq uint64
+	_ = s.view(bucketAudit, func(tx kvTx) error {
+		seq = tx.Bucket(bucketAudit).Sequence()
+		return nil
+	})
+	s.Close()
+
+	stats, err := MigrateBoltToSQLite(src, dst)
+	if err != nil {
+		t.Fatalf("MigrateBoltToSQLite: %v", err)
+	}
+	if stats.Buckets < len(storeBuckets) || stats.Keys < 3 {
+		t.Errorf("stats = %+v, want every bucket and key copied", stats)
+	}
+
+	m, err := OpenDriver(DriverSQLite, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	if v, _ := m.LoadSetting("poll_interval"); v != "6h" {
+		t.Errorf("poll_interval = %q, want 6h", v)
+	}
+	_ = m.view(bucketUsers, func(tx kvTx) error {
+		if tx.Bucket(bucketUsers) == nil {
+			t.Error("auth bucket not copied")
+		}
+		if v := tx.Bucket(bucketSettings).Get([]byte("empty")); v == nil || len(v) != 0 {
+			t.Errorf("empty setting = %v, want a present empty value", v)
+		}
+		if got := tx.Bucket(bucketAudit).Sequence(); got != seq {
+			t.Errorf("audit sequence = %d, want %d", got, seq)
+		}
+		return nil
+	})
+	entries, _, err := m.ListAudit(AuditQuery{})
+	if err != nil || len(entries) != 1 || entries[0].Path != "/api/settings" {
+		t.Errorf("ListAudit = %+v, %v", entries, err)
+	}
+
+	// An existing destination is never overwritten.
+	if _, err := MigrateBoltToSQLite(src, dst); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("second migration = %v, want already exists", err)
+	}
+}
+
+func TestMigrateBoltToSQLite_RejectsSQLiteSource(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "sentinel.sqlite")
+	s, err := OpenDriver(DriverSQLite, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	dst := filepath.Join(dir, "copy.sqlite")
+	if _, err := MigrateBoltToSQLite(src, dst); err == nil {
+		t.Fatal("migrating a SQLite file succeeded")
+	}
+	if _, err := os.Stat(dst); !os.IsNotExist(err) {
+		t.Errorf("destination left behind: %v", err)
+	}
+}
@@ -6,8 +6,6 @@ import (
 	"log/slog"
 	"time"
 
-	bolt "go.etcd.io/bbolt"
-
 	"github.com/Will-Luck/Docker-Sentinel/internal/notify"
 )
 
@@ -21,14 +19,21 @@ type NotifyState struct {
 
 // NotifyPref holds per-container notification mode preferences.
 type NotifyPref struct {
-	Mode string `json:"mode"` // "default", "every_scan", "digest_only", "muted"
+	Mode     string   `json:"mode"`               // "default", "every_scan", "digest_only", "muted"
+	Channels []string `json:"channels,omitempty"` // channel or group IDs to notify; empty = all
+	Source   string   `json:"source,omitempty"`   // NotifyPrefSourceLabel when synced from labels; "" = set in the UI
 }
 
+// NotifyPrefSourceLabel marks a preference synced from the container's
+// sentinel.notify and sentinel.channels labels. Scans keep it in step with
+// the labels; a preference set in the UI replaces it and wins until cleared.
+const NotifyPrefSourceLabel = "label"
+
 // GetNotifyState loads the notification state for a container.
 // Returns nil, nil if no state exists.
 func (s *Store) GetNotifyState(name string) (*NotifyState, error) {
 	var state *NotifyState
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketNotifyState, func(tx kvTx) error {
 		b, err := bucket(tx, bucketNotifyState)
 		if err != nil {
 			return err
@@ -49,7 +54,7 @@ func (s *Store) SetNotifyState(name string, state *NotifyState) error {
 	if err != nil {
 		return fmt.Errorf("marshal notify state: %w", err)
 	}
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketNotifyState, func(tx kvTx) error {
 		b, err := bucket(tx, bucketNotifyState)
 		if err != nil {
 			return err
@@ -61,7 +66,7 @@ func (s *Store) SetNotifyState(name string, state *NotifyState) error {
 // ClearNotifyState removes the notification state for a container.
 // Called after a successful update to reset the deduplication slate.
 func (s *Store) ClearNotifyState(name string) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketNotifyState, func(tx kvTx) error {
 		b, err := bucket(tx, bucketNotifyState)
 		if err != nil {
 			return err
@@ -73,7 +78,7 @@ func (s *Store) ClearNotifyState(name string) error {
 // AllNotifyStates returns all stored notification states.
 func (s *Store) AllNotifyStates() (map[string]*NotifyState, error) {
 	result := make(map[string]*NotifyState)
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketNotifyState, func(tx kvTx) error {
 		b, err := bucket(tx, bucketNotifyState)
 		if err != nil {
 			return err
@@ -95,7 +100,7 @@ func (s *Store) AllNotifyStates() (map[string]*NotifyState, error) {
 // Returns nil, nil if no preference is set (falls back to global default).
 func (s *Store) GetNotifyPref(name string) (*NotifyPref, error) {
 	var pref *NotifyPref
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketNotifyPrefs, func(tx kvTx) error {
 		b, err := bucket(tx, bucketNotifyPrefs)
 		if err != nil {
 			return err
@@ -116,7 +121,7 @@ func (s *Store) SetNotifyPref(name string, pref *NotifyPref) error {
 	if err != nil {
 		return fmt.Errorf("marshal notify pref: %w", err)
 	}
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketNotifyPrefs, func(tx kvTx) error {
 		b, err := bucket(tx, bucketNotifyPrefs)
 		if err != nil {
 			return err
@@ -128,7 +133,7 @@ func (s *Store) SetNotifyPref(name string, pref *NotifyPref) error {
 // DeleteNotifyPref removes a per-container notification preference,
 // causing it to fall back to the global default.
 func (s *Store) DeleteNotifyPref(name string) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketNotifyPrefs, func(tx kvTx) error {
 		b, err := bucket(tx, bucketNotifyPrefs)
 		if err != nil {
 			return err
@@ -140,7 +145,7 @@ func (s *Store) DeleteNotifyPref(name string) error {
 // AllNotifyPrefs returns all stored per-container notification preferences.
 func (s *Store) AllNotifyPrefs() (map[string]*NotifyPref, error) {
 	result := make(map[string]*NotifyPref)
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketNotifyPrefs, func(tx kvTx) error {
 		b, err := bucket(tx, bucketNotifyPrefs)
 		if err != nil {
 			return err
@@ -169,14 +174,14 @@ type NotificationConfig struct {
 // GetNotificationConfig loads the notification configuration from the settings bucket.
 func (s *Store) GetNotificationConfig() (NotificationConfig, error) {
 	var cfg NotificationConfig
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketSettings, func(tx kvTx) error {
 		b, err := bucket(tx, bucketSettings)
 		if err != nil {
 			return err
 		}
-		v := b.Get([]byte("notification_config"))
-		if v == nil {
-			return nil
+		v, err := s.unseal(b.Get([]byte("notification_config")))
+		if err != nil || v == nil {
+			return err
 		}
 		return json.Unmarshal(v, &cfg)
 	})
@@ -189,12 +194,16 @@ func (s *Store) SetNotificationConfig(cfg NotificationConfig) error {
 	if err != nil {
 		return fmt.Errorf("marshal notification config: %w", err)
 	}
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketSettings, func(tx kvTx) error {
 		b, err := bucket(tx, bucketSettings)
 		if err != nil {
 			return err
 		}
-		return b.Put([]byte("notification_config"), data)
+		sealed, err := s.seal(data)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte("notification_config"), sealed)
 	})
 }
 
@@ -203,15 +212,21 @@ func (s *Store) SetNotificationConfig(cfg NotificationConfig) error {
 // it converts it to the new channel array format.
 func (s *Store) GetNotificationChannels() ([]notify.Channel, error) {
 	var channels []notify.Channel
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(bucketSettings, func(tx kvTx) error {
 		b, err := bucket(tx, bucketSettings)
 		if err != nil {
 			return err
 		}
-		v := b.Get([]byte("notification_channels"))
+		v, err := s.unseal(b.Get([]byte("notification_channels")))
+		if err != nil {
+			return err
+		}
 		if v == nil {
 			// Try legacy key migration.
-			legacy := b.Get([]byte("notification_config"))
+			legacy, err := s.unseal(b.Get([]byte("notification_config")))
+			if err != nil {
+				return err
+			}
 			if legacy != nil {
 				channels = migrateFromLegacy(legacy)
 			}
@@ -228,12 +243,16 @@ func (s *Store) SetNotificationChannels(channels []notify.Channel) error {
 	if err != nil {
 		return fmt.Errorf("marshal notification channels: %w", err)
 	}
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(bucketSettings, func(tx kvTx) error {
 		b, err := bucket(tx, bucketSettings)
 		if err != nil {
 			return err
 		}
-		return b.Put([]byte("notification_channels"), data)
+		sealed, err := s.seal(data)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte("notification_channels"), sealed)
 	})
 }
 
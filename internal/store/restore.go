@@ -0,0 +1,212 @@
+package store
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ErrInvalidBackup is returned for a restore file that is not a usable
+// Sentinel database.
+var ErrInvalidBackup = errors.New("invalid backup")
+
+// restoreRequiredBuckets must exist in a file for it to be accepted as a
+// Sentinel database. Buckets added in later releases are created on open.
+var restoreRequiredBuckets = [][]byte{bucketSettings, bucketPolicies, bucketHistory, bucketState}
+
+// WriteTo writes a consistent copy of the live database to w and returns
+// the number of bytes written. It lets the backup package take hot
+// snapshots that keep working across a restore.
+func (s *Store) WriteTo(w io.Writer) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.db.writeTo(w)
+}
+
+// ValidateBackup checks that the file at path is a consistent BoltDB or
+// SQLite database with Sentinel's core buckets. Errors wrap
+// ErrInvalidBackup.
+func ValidateBackup(path string) error {
+	_, err := validateBackup(path)
+	return err
+}
+
+// validateBackup is ValidateBackup, also returning the driver that wrote
+// the file.
+func validateBackup(path string) (string, error) {
+	db, driver, err := openKVReadOnly(path)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	defer db.close()
+
+	err = db.view(func(tx kvTx) error {
+		for _, name := range restoreRequiredBuckets {
+			if tx.Bucket(name) == nil {
+				return fmt.Errorf("%w: no %q bucket, not a Sentinel database", ErrInvalidBackup, name)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if !errors.Is(err, ErrInvalidBackup) {
+			err = fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+		}
+		return "", err
+	}
+	if err := db.check(); err != nil {
+		return "", fmt.Errorf("%w: consistency check: %v", ErrInvalidBackup, err)
+	}
+	return driver, nil
+}
+
+// Restore replaces the live database with the backup at src, which is
+// validated first. Transactions in flight finish before the swap and later
+// ones wait for it. The replaced file is kept as <path>.pre-restore; if the
+// backup cannot be opened the previous database is put back. With
+// encryption at rest enabled, plaintext secrets in the backup are encrypted.
+// A backup encrypted with another key, restored into a store without one,
+// or written by another storage driver is refused before anything is
+// swapped.
+func (s *Store) Restore(src string) error {
+	driver, err := validateBackup(src)
+	if err != nil {
+		return err
+	}
+	if driver != s.driver {
+		return fmt.Errorf("%w: backup is a %s database but the store uses %s", ErrInvalidBackup, driver, s.driver)
+	}
+	if err := s.checkBackupKey(src); err != nil {
+		return err
+	}
+	staged := s.path + ".restore"
+	if err := copyFile(src, staged); err != nil {
+		return fmt.Errorf("stage backup: %w", err)
+	}
+	defer os.Remove(staged)
+
+	if err := s.swapIn(staged); err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	encrypted := s.aead != nil
+	s.mu.RUnlock()
+	if encrypted {
+		if _, err := s.encryptSecrets(); err != nil {
+			return s.swapBack(fmt.Errorf("encrypt restored secrets: %w", err))
+		}
+	}
+	return nil
+}
+
+// checkBackupKey refuses a backup whose secrets the store could not read:
+// one sealed with a different key, or any encrypted backup when the store
+// has no key. Backups without a check value were never encrypted.
+func (s *Store) checkBackupKey(path string) error {
+	db, _, err := openKVReadOnly(path)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	defer db.close()
+
+	var check []byte
+	if err := db.view(func(tx kvTx) error {
+		check = bytes.Clone(tx.Bucket(bucketSettings).Get([]byte(encryptionCheckKey)))
+		return nil
+	}); err != nil {
+		return err
+	}
+	if check == nil {
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, err := s.unseal(check)
+	if errors.Is(err, ErrEncryptionKeyRequired) {
+		return fmt.Errorf("backup is encrypted at rest: %w", err)
+	}
+	if err != nil || !bytes.Equal(v, encryptionCheckValue) {
+		return fmt.Errorf("backup was encrypted with another key: %w", ErrWrongEncryptionKey)
+	}
+	return nil
+}
+
+// swapBack puts the database replaced by a restore back in place after the
+// restored one turned out unusable, and returns cause.
+func (s *Store) swapBack(cause error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rejected := s.path + ".rejected"
+	if err := s.swapInLocked(s.path+".pre-restore", rejected); err != nil {
+		return fmt.Errorf("%w; putting the previous database back failed: %v", cause, err)
+	}
+	_ = os.Remove(rejected)
+	return fmt.Errorf("%w; previous database put back", cause)
+}
+
+// swapIn closes the database, moves the staged file into its place and
+// reopens it, putting the previous file back on failure.
+func (s *Store) swapIn(staged string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.swapInLocked(staged, s.path+".pre-restore")
+}
+
+// swapInLocked is swapIn with s.mu already held. The replaced file is kept
+// at prev.
+func (s *Store) swapInLocked(staged, prev string) error {
+	if err := s.db.close(); err != nil {
+		return fmt.Errorf("close database: %w", err)
+	}
+	if err := os.Rename(s.path, prev); err != nil {
+		return s.reopen(fmt.Errorf("keep current database: %w", err))
+	}
+	if err := os.Rename(staged, s.path); err != nil {
+		_ = os.Rename(prev, s.path)
+		return s.reopen(fmt.Errorf("swap in new database: %w", err))
+	}
+	db, err := openKV(s.driver, s.path)
+	if err != nil {
+		_ = os.Rename(prev, s.path)
+		return s.reopen(fmt.Errorf("open new database: %w", err))
+	}
+	s.db = db
+	return nil
+}
+
+// reopen reopens the database at s.path after a failed swap and returns
+// cause. The caller holds s.mu.
+func (s *Store) reopen(cause error) error {
+	db, err := openKV(s.driver, s.path)
+	if err != nil {
+		return fmt.Errorf("%w; reopening previous database also failed: %v", cause, err)
+	}
+	s.db = db
+	return cause
+}
+
+// copyFile copies src to dst, syncing dst before it is closed.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,116 @@
+package store
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// snapshotTo writes a hot backup of s to a file and returns its path.
+func snapshotTo(t *testing.T, s *Store) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "backup.db")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := s.WriteTo(f); err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	return path
+}
+
+func TestRestore(t *testing.T) {
+	s := testStore(t)
+	if err := s.SaveSetting("poll_interval", "6h"); err != nil {
+		t.Fatal(err)
+	}
+	backup := snapshotTo(t, s)
+
+	if err := s.SaveSetting("poll_interval", "1h"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Restore(backup); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+
+	if v, _ := s.LoadSetting("poll_interval"); v != "6h" {
+		t.Errorf("poll_interval = %q after restore, want 6h", v)
+	}
+	if _, err := os.Stat(s.path + ".pre-restore"); err != nil {
+		t.Errorf("replaced database not kept: %v", err)
+	}
+	// The restored database is live for writes too.
+	if err := s.SaveSetting("poll_interval", "2h"); err != nil {
+		t.Errorf("write after restore: %v", err)
+	}
+}
+
+func TestRestore_RejectsInvalidBackup(t *testing.T) {
+	s := testStore(t)
+	if err := s.SaveSetting("poll_interval", "6h"); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+
+	garbage := filepath.Join(dir, "garbage.db")
+	if err := os.WriteFile(garbage, []byte("not a database"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// A valid BoltDB file that isn't a Sentinel database.
+	foreign := filepath.Join(dir, "foreign.db")
+	db, err := bolt.Open(foreign, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucket([]byte("other"))
+		return err
+	})
+	db.Close()
+
+	for _, path := range []string{garbage, foreign} {
+		if err := s.Restore(path); !errors.Is(err, ErrInvalidBackup) {
+			t.Errorf("Restore(%s) = %v, want ErrInvalidBackup", filepath.Base(path), err)
+		}
+	}
+	if v, _ := s.LoadSetting("poll_interval"); v != "6h" {
+		t.Errorf("poll_interval = %q, want the live database untouched", v)
+	}
+}
+
+func TestRestore_RefusesBackupWithOtherKey(t *testing.T) {
+	src := testStore(t)
+	if _, err := src.EnableEncryption(EncryptionKey([]byte("key-one"))); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.SaveSetting("oidc_client_secret", "s3cret"); err != nil {
+		t.Fatal(err)
+	}
+	backup := snapshotTo(t, src)
+
+	s := testStore(t)
+	if _, err := s.EnableEncryption(EncryptionKey([]byte("key-two"))); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveSetting("oidc_client_secret", "mine"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Restore(backup); !errors.Is(err, ErrWrongEncryptionKey) {
+		t.Fatalf("Restore = %v, want ErrWrongEncryptionKey", err)
+	}
+	if v, err := s.LoadSetting("oidc_client_secret"); err != nil || v != "mine" {
+		t.Errorf("secret after refused restore = %q, %v; want the live value", v, err)
+	}
+
+	plain := testStore(t)
+	if err := plain.Restore(backup); !errors.Is(err, ErrEncryptionKeyRequired) {
+		t.Errorf("Restore without a key = %v, want ErrEncryptionKeyRequired", err)
+	}
+}
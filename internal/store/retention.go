@@ -0,0 +1,229 @@
+package store
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Retention limits how much of a time-ordered bucket is kept. A zero field
+// means no limit on that axis; both zero keeps everything.
+type Retention struct {
+	Days       int `json:"days"`
+	MaxRecords int `json:"max_records"`
+}
+
+// Enabled reports whether the retention removes anything.
+func (r Retention) Enabled() bool {
+	return r.Days > 0 || r.MaxRecords > 0
+}
+
+// RetentionPolicy holds the retention for update history, the activity log
+// and container snapshots. Snapshot limits apply per container.
+type RetentionPolicy struct {
+	History   Retention `json:"history"`
+	Logs      Retention `json:"logs"`
+	Snapshots Retention `json:"snapshots"`
+}
+
+// RetentionReport counts the entries removed by ApplyRetention.
+type RetentionReport struct {
+	History   int `json:"history"`
+	Logs      int `json:"logs"`
+	Snapshots int `json:"snapshots"`
+}
+
+// Total returns the number of entries removed across all buckets.
+func (r RetentionReport) Total() int {
+	return r.History + r.Logs + r.Snapshots
+}
+
+// LoadRetentionPolicy reads the retention_<kind>_days and
+// retention_<kind>_max settings. Missing or invalid values mean no limit.
+func (s *Store) LoadRetentionPolicy() RetentionPolicy {
+	load := func(kind string) Retention {
+		return Retention{
+			Days:       s.loadNonNegative("retention_" + kind + "_days"),
+			MaxRecords: s.loadNonNegative("retention_" + kind + "_max"),
+		}
+	}
+	return RetentionPolicy{
+		History:   load("history"),
+		Logs:      load("logs"),
+		Snapshots: load("snapshots"),
+	}
+}
+
+func (s *Store) loadNonNegative(key string) int {
+	v, err := s.LoadSetting(key)
+	if err != nil || v == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// ApplyRetention prunes history, logs and snapshots according to the
+// configured retention policy. now is the reference point for day limits.
+func (s *Store) ApplyRetention(now time.Time) (RetentionReport, error) {
+	p := s.LoadRetentionPolicy()
+	var rep RetentionReport
+	var err error
+	if rep.History, err = s.pruneTimeOrdered(bucketHistory, p.History, now); err != nil {
+		return rep, fmt.Errorf("prune history: %w", err)
+	}
+	if rep.Logs, err = s.pruneTimeOrdered(bucketLogs, p.Logs, now); err != nil {
+		return rep, fmt.Errorf("prune logs: %w", err)
+	}
+	if rep.Snapshots, err = s.pruneSnapshots(p.Snapshots, now); err != nil {
+		return rep, fmt.Errorf("prune snapshots: %w", err)
+	}
+	return rep, nil
+}
+
+// cutoff returns the oldest time kept, or the zero time without a day limit.
+func (r Retention) cutoff(now time.Time) time.Time {
+	if r.Days <= 0 {
+		return time.Time{}
+	}
+	return now.AddDate(0, 0, -r.Days)
+}
+
+// expired reports whether a timestamp key is older than cutoff. Keys that
+// do not parse are kept.
+func expired(key string, cutoff time.Time) bool {
+	if cutoff.IsZero() {
+		return false
+	}
+	t, err := time.Parse(time.RFC3339Nano, key)
+	return err == nil && t.Before(cutoff)
+}
+
+// pruneTimeOrdered applies r to a bucket keyed by RFC3339Nano timestamps,
+// such as history and logs. Keys sort oldest first, so the record limit
+// drops from the front.
+func (s *Store) pruneTimeOrdered(name []byte, r Retention, now time.Time) (int, error) {
+	if !r.Enabled() {
+		return 0, nil
+	}
+	cutoff := r.cutoff(now)
+	var removed int
+	err := s.update(name, func(tx kvTx) error {
+		b, err := bucket(tx, name)
+		if err != nil {
+			return err
+		}
+		// Collect keys first — mutating during iteration is undefined behaviour in BoltDB.
+		var keys [][]byte
+		c := b.Cursor()
+		for k, _ := c.First(); k != nil; k, _ = c.Next() {
+			keys = append(keys, append([]byte(nil), k...))
+		}
+		excess := 0
+		if r.MaxRecords > 0 && len(keys) > r.MaxRecords {
+			excess = len(keys) - r.MaxRecords
+		}
+		for i, k := range keys {
+			if i >= excess && !expired(string(k), cutoff) {
+				continue
+			}
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+			removed++
+		}
+		return nil
+	})
+	return removed, err
+}
+
+// pruneSnapshots applies r to each container's snapshots separately. The
+// newest snapshot of every container is always kept so a rollback stays
+// possible however old it is.
+func (s *Store) pruneSnapshots(r Retention, now time.Time) (int, error) {
+	if !r.Enabled() {
+		return 0, nil
+	}
+	cutoff := r.cutoff(now)
+	keep := r.MaxRecords
+	var removed int
+	err := s.update(bucketSnapshots, func(tx kvTx) error {
+		b, err := bucket(tx, bucketSnapshots)
+		if err != nil {
+			return err
+		}
+		byName := make(map[string][][]byte)
+		c := b.Cursor()
+		for k, _ := c.First(); k != nil; k, _ = c.Next() {
+			i := bytes.LastIndex(k, []byte("::"))
+			if i < 0 {
+				continue
+			}
+			name := string(k[:i])
+			byName[name] = append(byName[name], append([]byte(nil), k...))
+		}
+		names := make([]string, 0, len(byName))
+		for name := range byName {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			keys := byName[name]
+			excess := 0
+			if keep > 0 && len(keys) > keep {
+				excess = len(keys) - keep
+			}
+			for i, k := range keys[:len(keys)-1] {
+				ts := strings.TrimPrefix(string(k), name+"::")
+				if i >= excess && !expired(ts, cutoff) {
+					continue
+				}
+				if err := b.Delete(k); err != nil {
+					return err
+				}
+				removed++
+			}
+		}
+		return nil
+	})
+	return removed, err
+}
+
+// compactTxSize bounds each copy transaction during compaction.
+const compactTxSize = 64 << 20
+
+// Compact rewrites the database into a fresh file and swaps it in, returning
+// the space the database keeps free after deletes to the filesystem.
+// Transactions wait while it runs. Returns the file size before and after.
+func (s *Store) Compact() (before, after int64, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if fi, err := os.Stat(s.path); err == nil {
+		before = fi.Size()
+	}
+	staged := s.path + ".compact"
+	_ = os.Remove(staged)
+	if err := s.db.compactTo(staged); err != nil {
+		os.Remove(staged)
+		return 0, 0, err
+	}
+
+	prev := s.path + ".pre-compact"
+	if err := s.swapInLocked(staged, prev); err != nil {
+		os.Remove(staged)
+		return 0, 0, err
+	}
+	_ = os.Remove(prev)
+	if fi, err := os.Stat(s.path); err == nil {
+		after = fi.Size()
+	}
+	return before, after, nil
+}
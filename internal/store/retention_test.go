@@ -0,0 +1,153 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApplyRetention_HistoryAndLogs(t *testing.T) {
+	s := testStore(t)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 10; i++ {
+		ts := now.AddDate(0, 0, -i*10) // 0, 10, ... 90 days old
+		if err := s.RecordUpdate(UpdateRecord{Timestamp: ts, ContainerName: fmt.Sprintf("c%d", i), Outcome: "success"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.AppendLog(LogEntry{Timestamp: ts, Type: "update", Message: "m"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// History: 30 days keeps 0, 10, 20 (30 is not older than the cutoff).
+	// Logs: at most 5 records.
+	for k, v := range map[string]string{"retention_history_days": "30", "retention_logs_max": "5"} {
+		if err := s.SaveSetting(k, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rep, err := s.ApplyRetention(now)
+	if err != nil {
+		t.Fatalf("ApplyRetention: %v", err)
+	}
+	if rep.History != 6 || rep.Logs != 5 || rep.Snapshots != 0 {
+		t.Errorf("report = %+v, want 6 history, 5 logs", rep)
+	}
+
+	hist, _ := s.ListHistory(100, "")
+	if len(hist) != 4 {
+		t.Fatalf("history len = %d, want 4", len(hist))
+	}
+	if oldest := hist[len(hist)-1].Timestamp; now.Sub(oldest) > 30*24*time.Hour {
+		t.Errorf("oldest kept record %s is past retention", oldest)
+	}
+	logs, _ := s.ListLogs(100)
+	if len(logs) != 5 {
+		t.Fatalf("logs len = %d, want 5", len(logs))
+	}
+	if !logs[0].Timestamp.Equal(now) {
+		t.Errorf("newest log = %s, want %s", logs[0].Timestamp, now)
+	}
+}
+
+func TestApplyRetention_DisabledKeepsEverything(t *testing.T) {
+	s := testStore(t)
+	old := time.Now().AddDate(-5, 0, 0)
+	if err := s.RecordUpdate(UpdateRecord{Timestamp: old, ContainerName: "web"}); err != nil {
+		t.Fatal(err)
+	}
+	rep, err := s.ApplyRetention(time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Total() != 0 {
+		t.Errorf("removed %d entries with no retention configured", rep.Total())
+	}
+}
+
+func TestApplyRetention_SnapshotsKeepNewestPerContainer(t *testing.T) {
+	s := testStore(t)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	put := func(name string, age int) {
+		t.Helper()
+		key := name + "::" + now.AddDate(0, 0, -age).Format(time.RFC3339Nano)
+		if err := s.update(bucketSnapshots, func(tx kvTx) error {
+			return tx.Bucket(bucketSnapshots).Put([]byte(key), []byte(name))
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, age := range []int{1, 5, 10} {
+		put("web", age)
+	}
+	// Only very old snapshots: the newest must survive for rollback.
+	put("db", 400)
+	put("db", 500)
+
+	if err := s.SaveSetting("retention_snapshots_days", "7"); err != nil {
+		t.Fatal(err)
+	}
+	rep, err := s.ApplyRetention(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Snapshots != 2 {
+		t.Errorf("removed %d snapshots, want 2", rep.Snapshots)
+	}
+	web, _ := s.ListSnapshots("web")
+	if len(web) != 2 {
+		t.Errorf("web snapshots = %d, want 2", len(web))
+	}
+	db, _ := s.ListSnapshots("db")
+	if len(db) != 1 {
+		t.Fatalf("db snapshots = %d, want 1", len(db))
+	}
+	if got, _ := s.GetLatestSnapshot("db"); string(got) != "db" {
+		t.Errorf("latest db snapshot lost")
+	}
+
+	// A record limit of 1 leaves exactly the newest for each container.
+	if err := s.SaveSetting("retention_snapshots_max", "1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ApplyRetention(now); err != nil {
+		t.Fatal(err)
+	}
+	if web, _ := s.ListSnapshots("web"); len(web) != 1 {
+		t.Errorf("web snapshots = %d, want 1", len(web))
+	}
+}
+
+func TestCompact(t *testing.T) {
+	s := testStore(t)
+	big := strings.Repeat("x", 4096)
+	for i := 0; i < 500; i++ {
+		if err := s.SaveSetting(fmt.Sprintf("bulk_%03d", i), big); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 500; i++ {
+		if err := s.DeleteSetting(fmt.Sprintf("bulk_%03d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.SaveSetting("poll_interval", "6h"); err != nil {
+		t.Fatal(err)
+	}
+
+	before, after, err := s.Compact()
+	if err != nil {
+		t.Fatalf("Compact: %v", err)
+	}
+	if after >= before {
+		t.Errorf("size after = %d, want less than %d", after, before)
+	}
+	if v, _ := s.LoadSetting("poll_interval"); v != "6h" {
+		t.Errorf("poll_interval = %q after compaction, want 6h", v)
+	}
+	// The store stays writable after the swap.
+	if err := s.SaveSetting("poll_interval", "1h"); err != nil {
+		t.Fatalf("write after compaction: %v", err)
+	}
+}
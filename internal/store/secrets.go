@@ -0,0 +1,198 @@
+package store
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+)
+
+// Secret values are stored as sealedPrefix followed by the AES-GCM nonce and
+// ciphertext. Values without the prefix were written before encryption was
+// enabled and are read as plaintext until migrated.
+var sealedPrefix = []byte("sentinel-enc:v1:")
+
+// encryptionCheckKey holds a sealed known value in the settings bucket, so a
+// different key is refused instead of silently mixing ciphertexts.
+const encryptionCheckKey = "encryption_check"
+
+var encryptionCheckValue = []byte("docker-sentinel")
+
+// secretSettingKeys are the settings encrypted at rest.
+var secretSettingKeys = map[string]bool{
+	"notification_config":       true,
+	"notification_channels":     true,
+	"oidc_client_secret":        true,
+	"rebuild_webhook_url":       true,
+	"validation_webhook_url":    true,
+	SettingWebhookSecret:        true,
+	SettingRegistryWebhookToken: true,
+	SettingTicketing:            true,
+	SettingPortainerToken:       true,
+	SettingNPMPassword:          true,
+}
+
+// IsSecretSetting reports whether the setting key is encrypted at rest.
+func IsSecretSetting(key string) bool { return secretSettingKeys[key] }
+
+// secretBuckets are buckets whose values are all encrypted at rest.
+var secretBuckets = [][]byte{bucketRegistryCreds, bucketPortainerInstances}
+
+var (
+	// ErrEncryptionKeyRequired is returned when reading an encrypted value
+	// from a store opened without the encryption key.
+	ErrEncryptionKeyRequired = errors.New("value is encrypted at rest: set SENTINEL_ENCRYPTION_KEY or SENTINEL_ENCRYPTION_KEY_FILE")
+	// ErrWrongEncryptionKey is returned when the key does not match the one
+	// the database was encrypted with.
+	ErrWrongEncryptionKey = errors.New("encryption key does not match the database")
+)
+
+// EncryptionKey derives the 32-byte AES key from the configured secret
+// (SENTINEL_ENCRYPTION_KEY or the contents of the key file).
+func EncryptionKey(secret []byte) []byte {
+	sum := sha256.Sum256(bytes.TrimSpace(secret))
+	return sum[:]
+}
+
+// EnableEncryption turns on encryption at rest with key and encrypts any
+// secrets still stored as plaintext. It returns how many values were
+// migrated. The first call on a database records a check value; later
+// calls with a different key fail with ErrWrongEncryptionKey.
+func (s *Store) EnableEncryption(key []byte) (int, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return 0, fmt.Errorf("encryption key: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return 0, fmt.Errorf("encryption key: %w", err)
+	}
+
+	s.mu.Lock()
+	s.aead = aead
+	s.mu.Unlock()
+
+	n, err := s.encryptSecrets()
+	if err != nil {
+		s.mu.Lock()
+		s.aead = nil
+		s.mu.Unlock()
+		return 0, err
+	}
+	return n, nil
+}
+
+// encryptSecrets verifies the key against the database's check value and
+// seals every plaintext secret in one transaction.
+func (s *Store) encryptSecrets() (int, error) {
+	migrated := 0
+	err := s.update(bucketSettings, func(tx kvTx) error {
+		settings, err := bucket(tx, bucketSettings)
+		if err != nil {
+			return err
+		}
+		if check := settings.Get([]byte(encryptionCheckKey)); check != nil {
+			if v, err := s.unseal(check); err != nil || !bytes.Equal(v, encryptionCheckValue) {
+				return ErrWrongEncryptionKey
+			}
+		} else {
+			sealed, err := s.seal(encryptionCheckValue)
+			if err != nil {
+				return err
+			}
+			if err := settings.Put([]byte(encryptionCheckKey), sealed); err != nil {
+				return err
+			}
+		}
+
+		for key := range secretSettingKeys {
+			n, err := sealPlaintext(s, settings, []byte(key))
+			if err != nil {
+				return err
+			}
+			migrated += n
+		}
+		for _, name := range secretBuckets {
+			b, err := bucket(tx, name)
+			if err != nil {
+				return err
+			}
+			var keys [][]byte
+			if err := b.ForEach(func(k, _ []byte) error {
+				keys = append(keys, bytes.Clone(k))
+				return nil
+			}); err != nil {
+				return err
+			}
+			for _, k := range keys {
+				n, err := sealPlaintext(s, b, k)
+				if err != nil {
+					return err
+				}
+				migrated += n
+			}
+		}
+		return nil
+	})
+	return migrated, err
+}
+
+// sealPlaintext encrypts the value at key in b if it is still plaintext and
+// returns 1 if it was rewritten.
+func sealPlaintext(s *Store, b kvBucket, key []byte) (int, error) {
+	v := b.Get(key)
+	if v == nil || bytes.HasPrefix(v, sealedPrefix) {
+		return 0, nil
+	}
+	sealed, err := s.seal(v)
+	if err != nil {
+		return 0, err
+	}
+	return 1, b.Put(key, sealed)
+}
+
+// seal encrypts a secret for storage, or returns it unchanged when
+// encryption is not enabled. Callers hold s.mu (via view or update).
+func (s *Store) seal(plain []byte) ([]byte, error) {
+	if s.aead == nil {
+		return plain, nil
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("encryption nonce: %w", err)
+	}
+	out := append(bytes.Clone(sealedPrefix), nonce...)
+	return s.aead.Seal(out, nonce, plain, nil), nil
+}
+
+// unseal returns the plaintext of a stored secret. Plaintext values written
+// before encryption was enabled pass through unchanged. Callers hold s.mu.
+func (s *Store) unseal(v []byte) ([]byte, error) {
+	if !bytes.HasPrefix(v, sealedPrefix) {
+		return v, nil
+	}
+	if s.aead == nil {
+		return nil, ErrEncryptionKeyRequired
+	}
+	raw := v[len(sealedPrefix):]
+	n := s.aead.NonceSize()
+	if len(raw) < n {
+		return nil, ErrWrongEncryptionKey
+	}
+	plain, err := s.aead.Open(nil, raw[:n], raw[n:], nil)
+	if err != nil {
+		return nil, ErrWrongEncryptionKey
+	}
+	return plain, nil
+}
+
+// sealSetting encrypts value if key is a secret setting.
+func (s *Store) sealSetting(key string, value []byte) ([]byte, error) {
+	if !secretSettingKeys[key] {
+		return value, nil
+	}
+	return s.seal(value)
+}
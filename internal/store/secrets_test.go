@@ -0,0 +1,107 @@
+package store
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/registry"
+)
+
+// rawValue reads a value straight from BoltDB, bypassing decryption.
+func rawValue(t *testing.T, s *Store, b []byte, key string) []byte {
+	t.Helper()
+	var v []byte
+	if err := s.view(b, func(tx kvTx) error {
+		v = bytes.Clone(tx.Bucket(b).Get([]byte(key)))
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestEnableEncryption_MigratesPlaintext(t *testing.T) {
+	s := testStore(t)
+	if err := s.SaveSetting("oidc_client_secret", "s3cret"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveSetting("poll_interval", "6h"); err != nil {
+		t.Fatal(err)
+	}
+	creds := []registry.RegistryCredential{{ID: "r1", Registry: "ghcr.io", Username: "me", Secret: "pat"}}
+	if err := s.SetRegistryCredentials(creds); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := s.EnableEncryption(EncryptionKey([]byte("key-one")))
+	if err != nil {
+		t.Fatalf("EnableEncryption: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("migrated = %d, want 2", n)
+	}
+
+	if raw := rawValue(t, s, bucketSettings, "oidc_client_secret"); !bytes.HasPrefix(raw, sealedPrefix) || bytes.Contains(raw, []byte("s3cret")) {
+		t.Errorf("oidc_client_secret stored as %q, want ciphertext", raw)
+	}
+	if raw := rawValue(t, s, bucketRegistryCreds, "credentials"); bytes.Contains(raw, []byte("pat")) {
+		t.Error("registry credentials still stored in plaintext")
+	}
+	if raw := rawValue(t, s, bucketSettings, "poll_interval"); string(raw) != "6h" {
+		t.Errorf("poll_interval stored as %q, want plaintext", raw)
+	}
+
+	if v, err := s.LoadSetting("oidc_client_secret"); err != nil || v != "s3cret" {
+		t.Errorf("LoadSetting = %q, %v, want s3cret", v, err)
+	}
+	if got, err := s.GetRegistryCredentials(); err != nil || len(got) != 1 || got[0].Secret != "pat" {
+		t.Errorf("GetRegistryCredentials = %+v, %v", got, err)
+	}
+
+	// New writes are sealed too.
+	if err := s.SaveSetting("webhook_secret", "abc"); err != nil {
+		t.Fatal(err)
+	}
+	if raw := rawValue(t, s, bucketSettings, "webhook_secret"); !bytes.HasPrefix(raw, sealedPrefix) {
+		t.Errorf("webhook_secret stored as %q, want ciphertext", raw)
+	}
+	all, err := s.GetAllSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if all["webhook_secret"] != "abc" {
+		t.Errorf("GetAllSettings webhook_secret = %q, want abc", all["webhook_secret"])
+	}
+	if _, ok := all[encryptionCheckKey]; ok {
+		t.Error("GetAllSettings exposes the encryption check value")
+	}
+}
+
+func TestEnableEncryption_KeyRequiredAndChecked(t *testing.T) {
+	s := testStore(t)
+	if _, err := s.EnableEncryption(EncryptionKey([]byte("key-one"))); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveSetting("oidc_client_secret", "s3cret"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without the key, secrets are unreadable and left out of listings.
+	s.mu.Lock()
+	s.aead = nil
+	s.mu.Unlock()
+	if _, err := s.LoadSetting("oidc_client_secret"); !errors.Is(err, ErrEncryptionKeyRequired) {
+		t.Errorf("LoadSetting without key: err = %v, want ErrEncryptionKeyRequired", err)
+	}
+	if all, _ := s.GetAllSettings(); all["oidc_client_secret"] != "" {
+		t.Error("GetAllSettings returned an undecryptable value")
+	}
+
+	if _, err := s.EnableEncryption(EncryptionKey([]byte("key-two"))); !errors.Is(err, ErrWrongEncryptionKey) {
+		t.Errorf("wrong key: err = %v, want ErrWrongEncryptionKey", err)
+	}
+	if _, err := s.EnableEncryption(EncryptionKey([]byte("key-one"))); err != nil {
+		t.Errorf("original key: %v", err)
+	}
+}
@@ -0,0 +1,303 @@
+package store
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Timeline sources: the bucket an entry was read from.
+const (
+	TimelineSourceLog     = "log"     // activity log (dashboard and API actions)
+	TimelineSourceHistory = "history" // update history
+	TimelineSourceCluster = "cluster" // cluster host events
+)
+
+// MaxTimelineLimit caps the page size of ListTimeline.
+const MaxTimelineLimit = 500
+
+// keyOrderSlack is how far bucket key order may stray from timestamp order.
+// Log and history keys are RFC3339Nano, which drops trailing zeros, so keys
+// within the same second can sort out of time order.
+const keyOrderSlack = time.Second
+
+// ErrInvalidCursor is returned for a malformed TimelineQuery.Before.
+var ErrInvalidCursor = errors.New("invalid timeline cursor")
+
+// TimelineEntry is one event in the merged activity timeline.
+type TimelineEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Source    string    `json:"source"` // one of the TimelineSource* constants
+	Type      string    `json:"type"`   // log type, update outcome or host event type
+	Container string    `json:"container,omitempty"`
+	HostID    string    `json:"host_id,omitempty"`
+	HostName  string    `json:"host_name,omitempty"`
+	User      string    `json:"user,omitempty"`
+	Message   string    `json:"message"`
+	Failed    bool      `json:"failed,omitempty"`
+	// Cursor positions this entry in the timeline; pass it as
+	// TimelineQuery.Before to fetch the entries that follow it.
+	Cursor string `json:"cursor"`
+
+	key string // bucket key, breaks ties between equal timestamps
+}
+
+// TimelineQuery filters and pages ListTimeline. Entries come newest first.
+type TimelineQuery struct {
+	Since     time.Time // oldest timestamp to include; zero for no bound
+	Before    string    // cursor of the last entry of the previous page
+	Sources   []string  // TimelineSource* values to read; empty for all
+	Types     []string  // match an entry's Source or Type; empty for all
+	Container string
+	HostID    string
+	Limit     int
+}
+
+// timelinePos orders entries: newer first, then by source and key so that
+// entries with equal timestamps still page deterministically.
+type timelinePos struct {
+	ts     time.Time
+	source string
+	key    string
+}
+
+func (p timelinePos) before(o timelinePos) bool {
+	if !p.ts.Equal(o.ts) {
+		return p.ts.Before(o.ts)
+	}
+	if p.source != o.source {
+		return p.source < o.source
+	}
+	return p.key < o.key
+}
+
+func (e TimelineEntry) pos() timelinePos {
+	return timelinePos{ts: e.Timestamp, source: e.Source, key: e.key}
+}
+
+func encodeTimelineCursor(p timelinePos) string {
+	raw := strconv.FormatInt(p.ts.UnixNano(), 10) + "|" + p.source + "|" + p.key
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeTimelineCursor(s string) (timelinePos, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return timelinePos{}, ErrInvalidCursor
+	}
+	parts := strings.SplitN(string(raw), "|", 3)
+	if len(parts) != 3 {
+		return timelinePos{}, ErrInvalidCursor
+	}
+	nanos, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return timelinePos{}, ErrInvalidCursor
+	}
+	return timelinePos{ts: time.Unix(0, nanos).UTC(), source: parts[1], key: parts[2]}, nil
+}
+
+// matches applies the query's filters other than Since and Before.
+func (q TimelineQuery) matches(e TimelineEntry) bool {
+	if q.Container != "" && e.Container != q.Container {
+		return false
+	}
+	if q.HostID != "" && e.HostID != q.HostID {
+		return false
+	}
+	if len(q.Types) == 0 {
+		return true
+	}
+	for _, t := range q.Types {
+		if t == e.Source || t == e.Type {
+			return true
+		}
+	}
+	return false
+}
+
+// wantsSource reports whether any entry from source could pass the filters.
+// Activity log entries carry no host.
+func (q TimelineQuery) wantsSource(source string) bool {
+	if len(q.Sources) > 0 && !slices.Contains(q.Sources, source) {
+		return false
+	}
+	return q.HostID == "" || source != TimelineSourceLog
+}
+
+// ListTimeline merges the activity log, update history and cluster host
+// events into one timeline, newest first. It returns up to q.Limit entries
+// and the cursor for the next page, or "" when there are no more.
+func (s *Store) ListTimeline(q TimelineQuery) ([]TimelineEntry, string, error) {
+	if q.Limit <= 0 || q.Limit > MaxTimelineLimit {
+		q.Limit = MaxTimelineLimit
+	}
+	var cursor *timelinePos
+	if q.Before != "" {
+		p, err := decodeTimelineCursor(q.Before)
+		if err != nil {
+			return nil, "", err
+		}
+		cursor = &p
+	}
+
+	var entries []TimelineEntry
+	err := s.view(bucketLogs, func(tx kvTx) error {
+		if q.wantsSource(TimelineSourceLog) {
+			entries = append(entries, scanTimelineBucket(tx.Bucket(bucketLogs), q, cursor, logTimelineEntry)...)
+		}
+		if q.wantsSource(TimelineSourceHistory) {
+			entries = append(entries, scanTimelineBucket(tx.Bucket(bucketHistory), q, cursor, historyTimelineEntry)...)
+		}
+		if q.wantsSource(TimelineSourceCluster) {
+			entries = append(entries, scanHostEvents(tx.Bucket(bucketClusterHostEvents), q, cursor)...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[j].pos().before(entries[i].pos()) })
+	next := ""
+	if len(entries) > q.Limit {
+		entries = entries[:q.Limit]
+		next = entries[q.Limit-1].Cursor
+	}
+	return entries, next, nil
+}
+
+// scanTimelineBucket walks a bucket keyed by RFC3339Nano timestamps from
+// newest to oldest, collecting entries older than cursor that pass the
+// filters. It reads one entry past q.Limit so the caller knows whether
+// another page exists, plus keyOrderSlack to catch keys sorted out of order.
+func scanTimelineBucket(b kvBucket, q TimelineQuery, cursor *timelinePos, decode func(k, v []byte) (TimelineEntry, error)) []TimelineEntry {
+	if b == nil {
+		return nil
+	}
+	c := b.Cursor()
+	var k, v []byte
+	if cursor != nil {
+		start := []byte(cursor.ts.Add(keyOrderSlack).UTC().Format(time.RFC3339Nano))
+		if k, _ = c.Seek(start); k == nil {
+			k, v = c.Last()
+		} else {
+			k, v = c.Prev()
+		}
+	} else {
+		k, v = c.Last()
+	}
+
+	var out []TimelineEntry
+	var oldest time.Time
+	for ; k != nil; k, v = c.Prev() {
+		e, err := decode(k, v)
+		if err != nil {
+			slog.Warn("corrupt entry in timeline bucket, skipping", "key", string(k), "error", err)
+			continue
+		}
+		if !q.Since.IsZero() && e.Timestamp.Before(q.Since.Add(-keyOrderSlack)) {
+			break
+		}
+		if len(out) > q.Limit && e.Timestamp.Before(oldest.Add(-keyOrderSlack)) {
+			break
+		}
+		if (cursor != nil && !e.pos().before(*cursor)) || e.Timestamp.Before(q.Since) || !q.matches(e) {
+			continue
+		}
+		e.Cursor = encodeTimelineCursor(e.pos())
+		out = append(out, e)
+		if oldest.IsZero() || e.Timestamp.Before(oldest) {
+			oldest = e.Timestamp
+		}
+	}
+	return out
+}
+
+// scanHostEvents collects cluster host events. The bucket is keyed by host,
+// and each host keeps at most MaxClusterHostEvents, so it is read whole.
+func scanHostEvents(b kvBucket, q TimelineQuery, cursor *timelinePos) []TimelineEntry {
+	if b == nil {
+		return nil
+	}
+	var prefix []byte
+	if q.HostID != "" {
+		prefix = []byte(q.HostID + "::")
+	}
+	var out []TimelineEntry
+	c := b.Cursor()
+	for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+		var he ClusterHostEvent
+		if err := json.Unmarshal(v, &he); err != nil {
+			slog.Warn("corrupt entry in cluster host events bucket, skipping", "key", string(k), "error", err)
+			continue
+		}
+		e := TimelineEntry{
+			Timestamp: he.Timestamp,
+			Source:    TimelineSourceCluster,
+			Type:      he.Type,
+			Container: he.Container,
+			HostID:    he.HostID,
+			Message:   he.Message,
+			Failed:    he.Failed,
+			key:       string(k),
+		}
+		if (cursor != nil && !e.pos().before(*cursor)) || e.Timestamp.Before(q.Since) || !q.matches(e) {
+			continue
+		}
+		e.Cursor = encodeTimelineCursor(e.pos())
+		out = append(out, e)
+	}
+	return out
+}
+
+func logTimelineEntry(k, v []byte) (TimelineEntry, error) {
+	var l LogEntry
+	if err := json.Unmarshal(v, &l); err != nil {
+		return TimelineEntry{}, err
+	}
+	return TimelineEntry{
+		Timestamp: l.Timestamp,
+		Source:    TimelineSourceLog,
+		Type:      l.Type,
+		Container: l.Container,
+		User:      l.User,
+		Message:   l.Message,
+		key:       string(k),
+	}, nil
+}
+
+func historyTimelineEntry(k, v []byte) (TimelineEntry, error) {
+	var r UpdateRecord
+	if err := json.Unmarshal(v, &r); err != nil {
+		return TimelineEntry{}, err
+	}
+	msg := r.Outcome
+	switch {
+	case r.OldImage != "" && r.NewImage != "" && r.OldImage != r.NewImage:
+		msg = fmt.Sprintf("%s: %s -> %s", r.Outcome, r.OldImage, r.NewImage)
+	case r.NewImage != "":
+		msg = fmt.Sprintf("%s: %s", r.Outcome, r.NewImage)
+	}
+	if r.Error != "" {
+		msg += " (" + r.Error + ")"
+	}
+	return TimelineEntry{
+		Timestamp: r.Timestamp,
+		Source:    TimelineSourceHistory,
+		Type:      r.Outcome,
+		Container: r.ContainerName,
+		HostID:    r.HostID,
+		HostName:  r.HostName,
+		Message:   msg,
+		Failed:    r.Outcome == "failed" || r.Outcome == "rollback" || r.Outcome == "rollback_failed" || r.Outcome == "check_failed",
+		key:       string(k),
+	}, nil
+}
@@ -0,0 +1,54 @@
+package ticket
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const defaultGitHubAPI = "https://api.github.com"
+
+// gitHub files tickets as GitHub issues.
+type gitHub struct {
+	cfg    Config
+	client *http.Client
+}
+
+func (g *gitHub) base() string {
+	api := strings.TrimRight(g.cfg.URL, "/")
+	if api == "" {
+		api = defaultGitHubAPI
+	}
+	return api + "/repos/" + g.cfg.Repo + "/issues"
+}
+
+func (g *gitHub) auth(req *http.Request) {
+	req.Header.Set("Authorization", "Bearer "+g.cfg.Token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+}
+
+func (g *gitHub) Open(ctx context.Context, title, body string) (string, string, error) {
+	var resp struct {
+		Number  int    `json:"number"`
+		HTMLURL string `json:"html_url"`
+	}
+	in := map[string]string{"title": title, "body": body}
+	if err := doJSON(ctx, g.client, http.MethodPost, g.base(), g.auth, in, &resp); err != nil {
+		return "", "", err
+	}
+	return strconv.Itoa(resp.Number), resp.HTMLURL, nil
+}
+
+func (g *gitHub) Comment(ctx context.Context, id, body string) error {
+	return doJSON(ctx, g.client, http.MethodPost, g.base()+"/"+id+"/comments", g.auth, map[string]string{"body": body}, nil)
+}
+
+func (g *gitHub) Close(ctx context.Context, id, comment string) error {
+	if err := g.Comment(ctx, id, comment); err != nil {
+		return err
+	}
+	in := map[string]string{"state": "closed", "state_reason": "completed"}
+	return doJSON(ctx, g.client, http.MethodPatch, g.base()+"/"+id, g.auth, in, nil)
+}
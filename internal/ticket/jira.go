@@ -0,0 +1,81 @@
+package ticket
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// jira files tickets as Jira issues through the v2 REST API, which accepts
+// plain-text descriptions on both Cloud and Data Center.
+type jira struct {
+	cfg    Config
+	client *http.Client
+}
+
+func (j *jira) api(path string) string {
+	return strings.TrimRight(j.cfg.URL, "/") + "/rest/api/2" + path
+}
+
+// auth uses basic auth (email + API token) for Jira Cloud, or the token as
+// a bearer personal access token when no user is set.
+func (j *jira) auth(req *http.Request) {
+	if j.cfg.User != "" {
+		req.SetBasicAuth(j.cfg.User, j.cfg.Token)
+		return
+	}
+	req.Header.Set("Authorization", "Bearer "+j.cfg.Token)
+}
+
+func (j *jira) Open(ctx context.Context, title, body string) (string, string, error) {
+	issueType := j.cfg.IssueType
+	if issueType == "" {
+		issueType = "Task"
+	}
+	in := map[string]any{"fields": map[string]any{
+		"project":     map[string]string{"key": j.cfg.Project},
+		"summary":     title,
+		"description": body,
+		"issuetype":   map[string]string{"name": issueType},
+	}}
+	var resp struct {
+		Key string `json:"key"`
+	}
+	if err := doJSON(ctx, j.client, http.MethodPost, j.api("/issue"), j.auth, in, &resp); err != nil {
+		return "", "", err
+	}
+	return resp.Key, strings.TrimRight(j.cfg.URL, "/") + "/browse/" + resp.Key, nil
+}
+
+func (j *jira) Comment(ctx context.Context, id, body string) error {
+	return doJSON(ctx, j.client, http.MethodPost, j.api("/issue/"+id+"/comment"), j.auth, map[string]string{"body": body}, nil)
+}
+
+// Close comments and then applies the first transition that leads to a
+// status in the "done" category, since workflows name it differently.
+func (j *jira) Close(ctx context.Context, id, comment string) error {
+	if err := j.Comment(ctx, id, comment); err != nil {
+		return err
+	}
+	var resp struct {
+		Transitions []struct {
+			ID string `json:"id"`
+			To struct {
+				StatusCategory struct {
+					Key string `json:"key"`
+				} `json:"statusCategory"`
+			} `json:"to"`
+		} `json:"transitions"`
+	}
+	if err := doJSON(ctx, j.client, http.MethodGet, j.api("/issue/"+id+"/transitions"), j.auth, nil, &resp); err != nil {
+		return err
+	}
+	for _, t := range resp.Transitions {
+		if t.To.StatusCategory.Key == "done" {
+			in := map[string]any{"transition": map[string]string{"id": t.ID}}
+			return doJSON(ctx, j.client, http.MethodPost, j.api("/issue/"+id+"/transitions"), j.auth, in, nil)
+		}
+	}
+	return fmt.Errorf("no transition to a done status available for %s", id)
+}
@@ -0,0 +1,335 @@
+// Package ticket opens an issue in GitHub Issues or Jira for each pending
+// manual update, comments on it as the update is approved, and closes it
+// once the update completes. Queue keys are mapped to ticket IDs in the
+// store so the link survives restarts.
+package ticket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/lifecycle"
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+)
+
+// Supported providers.
+const (
+	ProviderGitHub = "github"
+	ProviderJira   = "jira"
+)
+
+const bufferSize = 64
+
+// Config selects the ticketing provider. An empty Provider disables the
+// integration.
+type Config struct {
+	Provider  string   `json:"provider"`
+	URL       string   `json:"url,omitempty"`        // Jira base URL, or a GitHub Enterprise API URL
+	Repo      string   `json:"repo,omitempty"`       // GitHub "owner/repo"
+	Project   string   `json:"project,omitempty"`    // Jira project key
+	IssueType string   `json:"issue_type,omitempty"` // Jira issue type (default "Task")
+	User      string   `json:"user,omitempty"`       // Jira Cloud account email; empty uses the token as a bearer PAT
+	Token     string   `json:"token,omitempty"`
+	Stacks    []string `json:"stacks,omitempty"` // only ticket containers in these Compose projects; empty = all
+}
+
+// ValidateConfig checks that the fields the chosen provider needs are set.
+func ValidateConfig(c Config) error {
+	switch c.Provider {
+	case "":
+		return nil
+	case ProviderGitHub:
+		if owner, repo, ok := strings.Cut(c.Repo, "/"); !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+			return fmt.Errorf("invalid GitHub repository %q (must be owner/repo)", c.Repo)
+		}
+	case ProviderJira:
+		if c.URL == "" {
+			return fmt.Errorf("jira URL is required")
+		}
+		if c.Project == "" {
+			return fmt.Errorf("jira project key is required")
+		}
+	default:
+		return fmt.Errorf("unknown ticketing provider %q", c.Provider)
+	}
+	if c.URL != "" {
+		u, err := url.Parse(c.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid URL %q (must be http or https)", c.URL)
+		}
+	}
+	if c.Token == "" {
+		return fmt.Errorf("API token is required")
+	}
+	return nil
+}
+
+// Provider is a ticketing backend.
+type Provider interface {
+	Open(ctx context.Context, title, body string) (id, link string, err error)
+	Comment(ctx context.Context, id, body string) error
+	Close(ctx context.Context, id, comment string) error
+}
+
+// newProvider builds the backend for a validated config.
+func newProvider(c Config, client *http.Client) Provider {
+	switch c.Provider {
+	case ProviderGitHub:
+		return &gitHub{cfg: c, client: client}
+	case ProviderJira:
+		return &jira{cfg: c, client: client}
+	}
+	return nil
+}
+
+// Details is what a ticket says about a pending update beyond the
+// lifecycle event itself.
+type Details struct {
+	Stack          string
+	HostName       string
+	CurrentImage   string
+	CurrentDigest  string
+	RemoteDigest   string
+	CurrentVersion string
+	TargetVersion  string
+	NewerVersions  []string
+	ReleaseURL     string
+	ReleaseNotes   string
+}
+
+// DetailsFunc looks up a queued update. ok is false when it is no longer
+// pending.
+type DetailsFunc func(ctx context.Context, key string) (d Details, ok bool)
+
+// Store persists queue key → ticket mappings.
+type Store interface {
+	GetTicket(key string) (store.TicketRef, bool)
+	SetTicket(key string, t store.TicketRef) error
+	DeleteTicket(key string) error
+}
+
+// Manager turns lifecycle transitions into ticket operations. Observe is
+// registered on the lifecycle sink and Run performs the API calls, so slow
+// ticketing backends never hold up the queue.
+type Manager struct {
+	config  func() Config
+	store   Store
+	details DetailsFunc
+	client  *http.Client
+	log     *slog.Logger
+	events  chan lifecycle.Event
+}
+
+// New creates a manager. config is read for every event, so setting changes
+// apply without a restart.
+func New(config func() Config, s Store, details DetailsFunc, log *slog.Logger) *Manager {
+	return &Manager{
+		config:  config,
+		store:   s,
+		details: details,
+		client:  &http.Client{Timeout: 15 * time.Second},
+		log:     log,
+		events:  make(chan lifecycle.Event, bufferSize),
+	}
+}
+
+// Observe queues a transition for Run. It never blocks: when the buffer is
+// full the event is dropped.
+func (m *Manager) Observe(e lifecycle.Event) {
+	switch e.State {
+	case lifecycle.StateQueued, lifecycle.StateApproved, lifecycle.StateFinalised,
+		lifecycle.StateFailed, lifecycle.StateRolledBack:
+	default:
+		return
+	}
+	if m.config().Provider == "" {
+		return
+	}
+	select {
+	case m.events <- e:
+	default:
+		m.log.Warn("ticket event buffer full, dropping event", "container", e.Container, "state", e.State)
+	}
+}
+
+// Run processes queued transitions until ctx is cancelled.
+func (m *Manager) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-m.events:
+			if err := m.handle(ctx, e); err != nil {
+				m.log.Warn("ticket update failed", "container", e.Container, "state", e.State, "error", err)
+			}
+		}
+	}
+}
+
+// handle applies one transition to the update's ticket.
+func (m *Manager) handle(ctx context.Context, e lifecycle.Event) error {
+	cfg := m.config()
+	if cfg.Provider == "" {
+		return nil
+	}
+	p := newProvider(cfg, m.client)
+	key := e.Container
+	if e.HostID != "" {
+		key = e.HostID + "::" + e.Container
+	}
+	ref, tracked := m.store.GetTicket(key)
+
+	switch e.State {
+	case lifecycle.StateQueued:
+		d, ok := m.details(ctx, key)
+		if !ok {
+			return nil
+		}
+		if len(cfg.Stacks) > 0 && !slices.Contains(cfg.Stacks, d.Stack) {
+			return nil
+		}
+		title, body := describe(e, d)
+		if tracked {
+			// A newer update replaced the one already ticketed.
+			return p.Comment(ctx, ref.ID, "A newer update was detected.\n\n"+body)
+		}
+		id, link, err := p.Open(ctx, title, body)
+		if err != nil {
+			return err
+		}
+		m.log.Info("opened ticket for pending update", "container", key, "ticket", id)
+		return m.store.SetTicket(key, store.TicketRef{Provider: cfg.Provider, ID: id, URL: link, OpenedAt: time.Now().UTC()})
+
+	case lifecycle.StateApproved:
+		if !tracked {
+			return nil
+		}
+		return p.Comment(ctx, ref.ID, "Update approved in Docker-Sentinel; applying now.")
+
+	case lifecycle.StateFinalised:
+		if !tracked {
+			return nil
+		}
+		msg := "Update completed"
+		if e.NewImage != "" {
+			msg += ": now running " + e.NewImage
+		}
+		if err := p.Close(ctx, ref.ID, msg+"."); err != nil {
+			return err
+		}
+		m.log.Info("closed ticket for completed update", "container", key, "ticket", ref.ID)
+		return m.store.DeleteTicket(key)
+
+	case lifecycle.StateFailed, lifecycle.StateRolledBack:
+		if !tracked {
+			return nil
+		}
+		msg := "Update failed"
+		if e.State == lifecycle.StateRolledBack {
+			msg = "Update rolled back"
+		}
+		if e.Message != "" {
+			msg += ": " + e.Message
+		}
+		return p.Comment(ctx, ref.ID, msg+". The ticket stays open.")
+	}
+	return nil
+}
+
+// describe renders a ticket title and a plain-text body that reads well in
+// both GitHub Markdown and Jira wiki markup.
+func describe(e lifecycle.Event, d Details) (title, body string) {
+	name := e.Container
+	if d.HostName != "" {
+		name += " on " + d.HostName
+	}
+	title = "Pending update: " + name
+	if d.TargetVersion != "" {
+		title += " → " + d.TargetVersion
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Docker-Sentinel has an update waiting for approval for %s.\n\n", name)
+	if d.Stack != "" {
+		fmt.Fprintf(&b, "- Stack: %s\n", d.Stack)
+	}
+	image := d.CurrentImage
+	if image == "" {
+		image = e.OldImage
+	}
+	if image != "" {
+		fmt.Fprintf(&b, "- Image: %s\n", image)
+	}
+	if d.CurrentVersion != "" || d.TargetVersion != "" {
+		fmt.Fprintf(&b, "- Version: %s → %s\n", orUnknown(d.CurrentVersion), orUnknown(d.TargetVersion))
+	}
+	if d.CurrentDigest != "" || d.RemoteDigest != "" {
+		fmt.Fprintf(&b, "- Digest: %s → %s\n", orUnknown(d.CurrentDigest), orUnknown(d.RemoteDigest))
+	}
+	if len(d.NewerVersions) > 0 {
+		fmt.Fprintf(&b, "- Newer versions: %s\n", strings.Join(d.NewerVersions, ", "))
+	}
+	if d.ReleaseURL != "" {
+		fmt.Fprintf(&b, "\nChangelog: %s\n", d.ReleaseURL)
+	}
+	if d.ReleaseNotes != "" {
+		fmt.Fprintf(&b, "\n%s\n", d.ReleaseNotes)
+	}
+	b.WriteString("\nThis ticket is updated when the update is approved and closed once it completes.\n")
+	return title, b.String()
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
+
+// doJSON sends a JSON request and decodes a JSON response into out when
+// non-nil.
+func doJSON(ctx context.Context, client *http.Client, method, rawURL string, auth func(*http.Request), in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("marshal request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, body)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	auth(req)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", method, req.URL.Path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s returned %s: %s", method, req.URL.Path, resp.Status, strings.TrimSpace(string(snippet)))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package ticket
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/lifecycle"
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+)
+
+type memStore struct {
+	refs map[string]store.TicketRef
+}
+
+func (m *memStore) GetTicket(key string) (store.TicketRef, bool) {
+	t, ok := m.refs[key]
+	return t, ok
+}
+
+func (m *memStore) SetTicket(key string, t store.TicketRef) error {
+	m.refs[key] = t
+	return nil
+}
+
+func (m *memStore) DeleteTicket(key string) error {
+	delete(m.refs, key)
+	return nil
+}
+
+// apiCall is one request received by a fake ticketing API.
+type apiCall struct {
+	method string
+	path   string
+	body   map[string]any
+}
+
+type fakeAPI struct {
+	mu    sync.Mutex
+	calls []apiCall
+	srv   *httptest.Server
+}
+
+func newFakeAPI(t *testing.T, respond func(w http.ResponseWriter, r *http.Request)) *fakeAPI {
+	t.Helper()
+	f := &fakeAPI{}
+	f.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		var body map[string]any
+		_ = json.Unmarshal(data, &body)
+		f.mu.Lock()
+		f.calls = append(f.calls, apiCall{method: r.Method, path: r.URL.Path, body: body})
+		f.mu.Unlock()
+		respond(w, r)
+	}))
+	t.Cleanup(f.srv.Close)
+	return f
+}
+
+func staticDetails(d Details) DetailsFunc {
+	return func(context.Context, string) (Details, bool) { return d, true }
+}
+
+func TestManagerGitHubLifecycle(t *testing.T) {
+	api := newFakeAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == "/repos/o/r/issues" {
+			_, _ = w.Write([]byte(`{"number": 7, "html_url": "https://github.com/o/r/issues/7"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	})
+	cfg := Config{Provider: ProviderGitHub, URL: api.srv.URL, Repo: "o/r", Token: "t"}
+	s := &memStore{refs: map[string]store.TicketRef{}}
+	m := New(func() Config { return cfg }, s, staticDetails(Details{
+		Stack: "prod", CurrentVersion: "1.25", TargetVersion: "1.27", ReleaseURL: "https://example.com/rel",
+	}), slog.Default())
+	ctx := context.Background()
+
+	if err := m.handle(ctx, lifecycle.Event{State: lifecycle.StateQueued, Container: "nginx", OldImage: "nginx:1.25"}); err != nil {
+		t.Fatal(err)
+	}
+	ref, ok := s.refs["nginx"]
+	if !ok || ref.ID != "7" || ref.Provider != ProviderGitHub {
+		t.Fatalf("stored ref = %+v, %v", ref, ok)
+	}
+	if body, _ := api.calls[0].body["body"].(string); !strings.Contains(body, "1.25 → 1.27") || !strings.Contains(body, "https://example.com/rel") {
+		t.Errorf("issue body missing version diff or changelog: %q", body)
+	}
+
+	if err := m.handle(ctx, lifecycle.Event{State: lifecycle.StateApproved, Container: "nginx"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.handle(ctx, lifecycle.Event{State: lifecycle.StateFinalised, Container: "nginx", NewImage: "nginx:1.27"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.refs["nginx"]; ok {
+		t.Error("mapping should be removed once the ticket is closed")
+	}
+
+	want := []string{
+		"POST /repos/o/r/issues",
+		"POST /repos/o/r/issues/7/comments",
+		"POST /repos/o/r/issues/7/comments",
+		"PATCH /repos/o/r/issues/7",
+	}
+	if len(api.calls) != len(want) {
+		t.Fatalf("got %d API calls, want %d", len(api.calls), len(want))
+	}
+	for i, w := range want {
+		if got := api.calls[i].method + " " + api.calls[i].path; got != w {
+			t.Errorf("call %d = %s, want %s", i, got, w)
+		}
+	}
+	if api.calls[3].body["state"] != "closed" {
+		t.Errorf("close body = %v", api.calls[3].body)
+	}
+}
+
+func TestManagerStackFilter(t *testing.T) {
+	api := newFakeAPI(t, func(w http.ResponseWriter, _ *http.Request) { _, _ = w.Write([]byte(`{}`)) })
+	cfg := Config{Provider: ProviderGitHub, URL: api.srv.URL, Repo: "o/r", Token: "t", Stacks: []string{"prod"}}
+	s := &memStore{refs: map[string]store.TicketRef{}}
+	m := New(func() Config { return cfg }, s, staticDetails(Details{Stack: "dev"}), slog.Default())
+
+	if err := m.handle(context.Background(), lifecycle.Event{State: lifecycle.StateQueued, Container: "web"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(api.calls) != 0 || len(s.refs) != 0 {
+		t.Errorf("container outside the configured stacks was ticketed")
+	}
+}
+
+func TestJiraCloseUsesDoneTransition(t *testing.T) {
+	api := newFakeAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/transitions") {
+			_, _ = w.Write([]byte(`{"transitions": [
+				{"id": "11", "to": {"statusCategory": {"key": "indeterminate"}}},
+				{"id": "31", "to": {"statusCategory": {"key": "done"}}}
+			]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"key": "OPS-3"}`))
+	})
+	j := &jira{cfg: Config{URL: api.srv.URL, Project: "OPS", Token: "t"}, client: http.DefaultClient}
+
+	id, link, err := j.Open(context.Background(), "title", "body")
+	if err != nil || id != "OPS-3" || link != api.srv.URL+"/browse/OPS-3" {
+		t.Fatalf("Open = %q, %q, %v", id, link, err)
+	}
+	if err := j.Close(context.Background(), "OPS-3", "done"); err != nil {
+		t.Fatal(err)
+	}
+	last := api.calls[len(api.calls)-1]
+	if last.path != "/rest/api/2/issue/OPS-3/transitions" || last.method != http.MethodPost {
+		t.Fatalf("last call = %s %s", last.method, last.path)
+	}
+	if tr, _ := last.body["transition"].(map[string]any); tr["id"] != "31" {
+		t.Errorf("transition = %v, want id 31", last.body)
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		ok   bool
+	}{
+		{"disabled", Config{}, true},
+		{"github", Config{Provider: ProviderGitHub, Repo: "o/r", Token: "t"}, true},
+		{"github bad repo", Config{Provider: ProviderGitHub, Repo: "o", Token: "t"}, false},
+		{"github no token", Config{Provider: ProviderGitHub, Repo: "o/r"}, false},
+		{"jira", Config{Provider: ProviderJira, URL: "https://x.atlassian.net", Project: "OPS", Token: "t"}, true},
+		{"jira no project", Config{Provider: ProviderJira, URL: "https://x.atlassian.net", Token: "t"}, false},
+		{"jira bad url", Config{Provider: ProviderJira, URL: "ftp://x", Project: "OPS", Token: "t"}, false},
+		{"unknown", Config{Provider: "linear", Token: "t"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateConfig(tt.cfg); (err == nil) != tt.ok {
+				t.Errorf("ValidateConfig() error = %v, want ok=%v", err, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package verify
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// DefaultCacheTTL is how long a verified digest is trusted when no TTL is
+// configured.
+const DefaultCacheTTL = 24 * time.Hour
+
+// CacheEntry records that an image digest passed verification.
+type CacheEntry struct {
+	Digest     string
+	ImageRef   string
+	Key        string
+	Policy     string // Verifier.Policy() at the time of verification
+	VerifiedAt time.Time
+}
+
+// CacheStore persists cache entries so they survive restarts.
+type CacheStore interface {
+	GetVerifyCache(digest string) (CacheEntry, bool, error)
+	SaveVerifyCache(e CacheEntry) error
+	ListVerifyCache() ([]CacheEntry, error)
+	DeleteVerifyCache(digest string) (bool, error)
+	ClearVerifyCache() (int, error)
+}
+
+// CacheStats summarises the cache for display.
+type CacheStats struct {
+	Entries int           `json:"entries"`
+	Expired int           `json:"expired"` // entries past the TTL, dropped on next lookup
+	Stale   int           `json:"stale"`   // entries verified under a different key or identity configuration
+	Hits    uint64        `json:"hits"`    // since start
+	Misses  uint64        `json:"misses"`  // since start
+	TTL     time.Duration `json:"ttl"`     // 0 = caching disabled
+}
+
+// Cache remembers successful verifications keyed by image digest. Only
+// passes are cached: a failure may be transient (registry or Rekor
+// unreachable) or fixed by adding a key, so it is always retried.
+type Cache struct {
+	store  CacheStore
+	ttl    func() time.Duration
+	now    func() time.Time
+	log    Logger
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// NewCache creates a Cache. ttl is read on every lookup so changes to the
+// setting take effect at once; a TTL of 0 disables caching.
+func NewCache(store CacheStore, ttl func() time.Duration, log Logger) *Cache {
+	return &Cache{store: store, ttl: ttl, now: time.Now, log: log}
+}
+
+// lookup returns the entry for digest if it is fresh and was verified
+// under the given policy.
+func (c *Cache) lookup(digest, policy string) (CacheEntry, bool) {
+	ttl := c.ttl()
+	if ttl <= 0 {
+		return CacheEntry{}, false
+	}
+	e, ok, err := c.store.GetVerifyCache(digest)
+	if err != nil {
+		c.log.Warn("failed to read verification cache", "digest", digest, "error", err)
+	}
+	if !ok || err != nil {
+		c.misses.Add(1)
+		return CacheEntry{}, false
+	}
+	if e.Policy != policy || c.now().Sub(e.VerifiedAt) >= ttl {
+		_, _ = c.store.DeleteVerifyCache(digest)
+		c.misses.Add(1)
+		return CacheEntry{}, false
+	}
+	c.hits.Add(1)
+	return e, true
+}
+
+// remember stores a successful verification.
+func (c *Cache) remember(e CacheEntry) {
+	if c.ttl() <= 0 {
+		return
+	}
+	e.VerifiedAt = c.now().UTC()
+	if err := c.store.SaveVerifyCache(e); err != nil {
+		c.log.Warn("failed to save verification cache", "digest", e.Digest, "error", err)
+	}
+}
+
+// Stats returns the cache size and hit counts. policy is the verifier's
+// current Policy(), used to count entries that will no longer be used.
+func (c *Cache) Stats(policy string) (CacheStats, error) {
+	entries, err := c.store.ListVerifyCache()
+	if err != nil {
+		return CacheStats{}, err
+	}
+	st := CacheStats{
+		Entries: len(entries),
+		Hits:    c.hits.Load(),
+		Misses:  c.misses.Load(),
+		TTL:     max(c.ttl(), 0),
+	}
+	now := c.now()
+	for _, e := range entries {
+		switch {
+		case e.Policy != policy:
+			st.Stale++
+		case now.Sub(e.VerifiedAt) >= st.TTL:
+			st.Expired++
+		}
+	}
+	return st, nil
+}
+
+// Invalidate drops the entry for digest, or every entry when digest is
+// empty, and returns how many were removed.
+func (c *Cache) Invalidate(digest string) (int, error) {
+	if digest == "" {
+		return c.store.ClearVerifyCache()
+	}
+	found, err := c.store.DeleteVerifyCache(digest)
+	if found {
+		return 1, err
+	}
+	return 0, err
+}
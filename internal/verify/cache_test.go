@@ -0,0 +1,131 @@
+package verify
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type memCacheStore struct{ entries map[string]CacheEntry }
+
+func (m *memCacheStore) GetVerifyCache(digest string) (CacheEntry, bool, error) {
+	e, ok := m.entries[digest]
+	return e, ok, nil
+}
+
+func (m *memCacheStore) SaveVerifyCache(e CacheEntry) error {
+	m.entries[e.Digest] = e
+	return nil
+}
+
+func (m *memCacheStore) ListVerifyCache() ([]CacheEntry, error) {
+	var out []CacheEntry
+	for _, e := range m.entries {
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+func (m *memCacheStore) DeleteVerifyCache(digest string) (bool, error) {
+	_, ok := m.entries[digest]
+	delete(m.entries, digest)
+	return ok, nil
+}
+
+func (m *memCacheStore) ClearVerifyCache() (int, error) {
+	n := len(m.entries)
+	m.entries = map[string]CacheEntry{}
+	return n, nil
+}
+
+// countingCosign wraps fakeCosign and returns a function reporting how many
+// times it has been run.
+func countingCosign(t *testing.T) (string, func() int) {
+	t.Helper()
+	inner := fakeCosign(t)
+	dir := t.TempDir()
+	log := filepath.Join(dir, "calls")
+	path := filepath.Join(dir, "cosign")
+	script := "#!/bin/sh\necho run >> " + log + "\nexec " + inner + " \"$@\"\n"
+	if err := os.WriteFile(path, []byte(script), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	return path, func() int {
+		data, _ := os.ReadFile(log)
+		return strings.Count(string(data), "run")
+	}
+}
+
+func TestVerifyDigestCache(t *testing.T) {
+	cosign, calls := countingCosign(t)
+	keys := []TrustedKey{{Name: "release", PEM: "GOOD"}}
+	ttl := time.Hour
+	cs := &memCacheStore{entries: map[string]CacheEntry{}}
+	cache := NewCache(cs, func() time.Duration { return ttl }, &testLogger{})
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	v := New(&testLogger{}, WithCosignPath(cosign), WithTrustedKeys(func() []TrustedKey { return keys }), WithCache(cache))
+
+	ctx := context.Background()
+	const digest = "nginx@sha256:aaa"
+	if r := v.VerifyDigest(ctx, "nginx:latest", digest); !r.Verified || r.Cached {
+		t.Fatalf("first verify = %+v, want verified and not cached", r)
+	}
+	r := v.VerifyDigest(ctx, "nginx:latest", digest)
+	if !r.Verified || !r.Cached || r.Key != "release" {
+		t.Fatalf("second verify = %+v, want a cached pass by release", r)
+	}
+	if calls() != 1 {
+		t.Fatalf("cosign calls = %d, want 1", calls())
+	}
+
+	// Adding a key changes the policy, so the digest is checked again.
+	keys = append(keys, TrustedKey{Name: "ci", PEM: "BAD"})
+	if r := v.VerifyDigest(ctx, "nginx:latest", digest); r.Cached {
+		t.Error("reused a result from a different key set")
+	}
+
+	// Past the TTL the digest is checked again.
+	now = now.Add(2 * time.Hour)
+	if r := v.VerifyDigest(ctx, "nginx:latest", digest); r.Cached {
+		t.Error("reused an expired result")
+	}
+	if calls() != 3 {
+		t.Fatalf("cosign calls = %d, want 3", calls())
+	}
+
+	// Failures are never cached.
+	keys = []TrustedKey{{Name: "ci", PEM: "BAD"}}
+	for range 2 {
+		if r := v.VerifyDigest(ctx, "redis:7", "redis@sha256:bbb"); r.Verified || r.Cached {
+			t.Fatalf("verify = %+v, want an uncached failure", r)
+		}
+	}
+
+	st, ok, err := v.CacheStats()
+	if err != nil || !ok {
+		t.Fatalf("CacheStats: ok = %v, err = %v", ok, err)
+	}
+	// The nginx entry was verified under the previous key set.
+	if st.Entries != 1 || st.Stale != 1 || st.Hits != 1 || st.TTL != time.Hour {
+		t.Errorf("stats = %+v", st)
+	}
+
+	if n, err := v.InvalidateCache(digest); err != nil || n != 1 {
+		t.Errorf("InvalidateCache = %d, %v, want 1", n, err)
+	}
+	if n, _ := v.InvalidateCache(""); n != 0 {
+		t.Errorf("clearing an empty cache removed %d", n)
+	}
+
+	// A TTL of 0 disables the cache.
+	keys = []TrustedKey{{Name: "release", PEM: "GOOD"}}
+	ttl = 0
+	v.VerifyDigest(ctx, "nginx:latest", digest)
+	if r := v.VerifyDigest(ctx, "nginx:latest", digest); r.Cached || len(cs.entries) != 0 {
+		t.Errorf("cached with TTL 0: %+v, %d entries", r, len(cs.entries))
+	}
+}
@@ -3,6 +3,13 @@ package verify
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
 	"os/exec"
 	"strings"
 )
@@ -31,8 +38,10 @@ func ParseMode(s string) Mode {
 // Result holds the outcome of a signature verification.
 type Result struct {
 	Verified bool   `json:"verified"`
+	Key      string `json:"key,omitempty"` // trusted key that verified the image, or "keyless"
 	Error    string `json:"error,omitempty"`
 	Output   string `json:"output,omitempty"` // cosign stdout for debugging
+	Cached   bool   `json:"cached,omitempty"` // reused from an earlier verification of the same digest
 }
 
 // Logger is a minimal logging interface.
@@ -42,11 +51,25 @@ type Logger interface {
 	Warn(msg string, args ...any)
 }
 
+// TrustedKey is a named cosign public key images may be signed with.
+type TrustedKey struct {
+	Name string
+	PEM  string
+}
+
+// KeySource returns the current trusted keys. It is called on every
+// verification so keys added or removed at runtime take effect at once.
+type KeySource func() []TrustedKey
+
 // Verifier checks image signatures using the cosign CLI.
 type Verifier struct {
-	cosignPath string // path to cosign binary (default: "cosign")
-	keyPath    string // path to public key PEM for keyed verification
-	keyless    bool   // use Sigstore keyless (Fulcio + Rekor)
+	cosignPath string    // path to cosign binary (default: "cosign")
+	keyPath    string    // path to public key PEM for keyed verification
+	keys       KeySource // optional: managed trusted keys
+	keyless    bool      // use Sigstore keyless (Fulcio + Rekor)
+	identity   string    // keyless: certificate identity regexp (default: any)
+	issuer     string    // keyless: OIDC issuer regexp (default: any)
+	cache      *Cache    // optional: results of earlier verifications by digest
 	log        Logger
 }
 
@@ -63,11 +86,32 @@ func WithKeyPath(path string) Option {
 	return func(v *Verifier) { v.keyPath = path }
 }
 
+// WithTrustedKeys adds managed public keys; an image passes if any of them
+// verifies its signature.
+func WithTrustedKeys(src KeySource) Option {
+	return func(v *Verifier) { v.keys = src }
+}
+
 // WithKeyless enables Sigstore keyless verification.
 func WithKeyless() Option {
 	return func(v *Verifier) { v.keyless = true }
 }
 
+// WithIdentity restricts keyless verification to signing certificates whose
+// identity and OIDC issuer match the given regular expressions. Empty
+// values match any identity or issuer.
+func WithIdentity(identity, issuer string) Option {
+	return func(v *Verifier) {
+		v.identity = identity
+		v.issuer = issuer
+	}
+}
+
+// WithCache reuses successful verifications of the same image digest.
+func WithCache(c *Cache) Option {
+	return func(v *Verifier) { v.cache = c }
+}
+
 // New creates a Verifier with the given options.
 func New(log Logger, opts ...Option) *Verifier {
 	v := &Verifier{
@@ -86,37 +130,149 @@ func (v *Verifier) Available() bool {
 	return err == nil
 }
 
-// Verify checks the signature of the given image reference.
-// Returns a Result indicating whether the signature is valid.
+// Verify checks the signature of the given image reference against the key
+// path, each trusted key, then keyless identity, stopping at the first that
+// verifies. Returns a Result indicating whether the signature is valid.
 func (v *Verifier) Verify(ctx context.Context, imageRef string) *Result {
 	if imageRef == "" {
 		return &Result{Error: "empty image reference"}
 	}
 
-	args := []string{"verify", imageRef}
+	var keys []TrustedKey
+	if v.keys != nil {
+		keys = v.keys()
+	}
+	if v.keyPath == "" && len(keys) == 0 && !v.keyless {
+		return &Result{Error: "no verification key or keyless mode configured"}
+	}
 
+	var failures []string
 	if v.keyPath != "" {
-		args = append(args, "--key", v.keyPath)
-	} else if v.keyless {
-		// Keyless verification uses Sigstore's transparency log.
-		// COSIGN_EXPERIMENTAL=1 is deprecated in newer cosign versions,
-		// but --certificate-identity-regexp and --certificate-oidc-issuer-regexp
-		// are the new way. For broad verification, use ".*" patterns.
-		args = append(args,
-			"--certificate-identity-regexp", ".*",
-			"--certificate-oidc-issuer-regexp", ".*",
+		r := v.run(ctx, "verify", imageRef, "--key", v.keyPath)
+		if r.Verified {
+			r.Key = v.keyPath
+			return r
+		}
+		failures = append(failures, v.keyPath+": "+r.Error)
+	}
+	for _, k := range keys {
+		r := v.verifyWithPEM(ctx, imageRef, k)
+		if r.Verified {
+			return r
+		}
+		failures = append(failures, k.Name+": "+r.Error)
+	}
+	if v.keyless {
+		// --certificate-identity-regexp and --certificate-oidc-issuer-regexp
+		// replace the deprecated COSIGN_EXPERIMENTAL=1. Unset patterns accept
+		// any signer with a valid Fulcio certificate.
+		r := v.run(ctx, "verify", imageRef,
+			"--certificate-identity-regexp", orAny(v.identity),
+			"--certificate-oidc-issuer-regexp", orAny(v.issuer),
 		)
-	} else {
-		return &Result{Error: "no verification key or keyless mode configured"}
+		if r.Verified {
+			r.Key = "keyless"
+			return r
+		}
+		failures = append(failures, "keyless: "+r.Error)
+	}
+
+	if len(failures) == 1 {
+		// Keep the cosign message as it is when there was only one attempt.
+		_, msg, _ := strings.Cut(failures[0], ": ")
+		return &Result{Error: msg}
+	}
+	return &Result{Error: strings.Join(failures, "; ")}
+}
+
+// VerifyDigest is Verify for an image whose repo digest is known. A digest
+// that already passed under the same keys and identity restrictions is
+// not checked again until the cache TTL runs out.
+func (v *Verifier) VerifyDigest(ctx context.Context, imageRef, digest string) *Result {
+	if v.cache == nil || digest == "" {
+		return v.Verify(ctx, imageRef)
+	}
+	policy := v.Policy()
+	if e, ok := v.cache.lookup(digest, policy); ok {
+		return &Result{Verified: true, Key: e.Key, Cached: true}
+	}
+	r := v.Verify(ctx, imageRef)
+	if r.Verified {
+		v.cache.remember(CacheEntry{Digest: digest, ImageRef: imageRef, Key: r.Key, Policy: policy})
 	}
+	return r
+}
 
+// Policy fingerprints what a signature is checked against: the key file
+// and its contents, the trusted keys, and the keyless identity
+// restrictions. Cached results from a different policy are not reused.
+func (v *Verifier) Policy() string {
+	h := sha256.New()
+	if v.keyPath != "" {
+		fmt.Fprintf(h, "keypath\x00%s\x00", v.keyPath)
+		if data, err := os.ReadFile(v.keyPath); err == nil {
+			h.Write(data)
+		}
+	}
+	if v.keys != nil {
+		for _, k := range v.keys() {
+			fmt.Fprintf(h, "\x00key\x00%s\x00%s", k.Name, k.PEM)
+		}
+	}
+	if v.keyless {
+		fmt.Fprintf(h, "\x00keyless\x00%s\x00%s", v.identity, v.issuer)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// CacheStats reports on the verification cache. ok is false when no cache
+// is attached.
+func (v *Verifier) CacheStats() (st CacheStats, ok bool, err error) {
+	if v.cache == nil {
+		return CacheStats{}, false, nil
+	}
+	st, err = v.cache.Stats(v.Policy())
+	return st, true, err
+}
+
+// InvalidateCache drops the cached result for digest, or all results when
+// digest is empty.
+func (v *Verifier) InvalidateCache(digest string) (int, error) {
+	if v.cache == nil {
+		return 0, nil
+	}
+	return v.cache.Invalidate(digest)
+}
+
+// verifyWithPEM writes a trusted key to a temporary file for cosign.
+func (v *Verifier) verifyWithPEM(ctx context.Context, imageRef string, k TrustedKey) *Result {
+	f, err := os.CreateTemp("", "sentinel-cosign-*.pub")
+	if err != nil {
+		return &Result{Error: "write key: " + err.Error()}
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(k.PEM); err != nil {
+		_ = f.Close()
+		return &Result{Error: "write key: " + err.Error()}
+	}
+	if err := f.Close(); err != nil {
+		return &Result{Error: "write key: " + err.Error()}
+	}
+	r := v.run(ctx, "verify", imageRef, "--key", f.Name())
+	if r.Verified {
+		r.Key = k.Name
+	}
+	return r
+}
+
+// run executes cosign and maps its exit status to a Result.
+func (v *Verifier) run(ctx context.Context, args ...string) *Result {
 	var stdout, stderr bytes.Buffer
 	cmd := exec.CommandContext(ctx, v.cosignPath, args...) //nolint:gosec // cosignPath is operator-configured, not user input
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	err := cmd.Run()
-	if err != nil {
+	if err := cmd.Run(); err != nil {
 		// cosign exits non-zero when verification fails.
 		errMsg := strings.TrimSpace(stderr.String())
 		if errMsg == "" {
@@ -134,3 +290,27 @@ func (v *Verifier) Verify(ctx context.Context, imageRef string) *Result {
 		Output:   strings.TrimSpace(stdout.String()),
 	}
 }
+
+func orAny(pattern string) string {
+	if pattern == "" {
+		return ".*"
+	}
+	return pattern
+}
+
+// Fingerprint validates a PEM-encoded public key and returns its SHA-256
+// fingerprint ("sha256:<hex>") for display.
+func Fingerprint(pemData string) (string, error) {
+	block, _ := pem.Decode([]byte(pemData))
+	if block == nil {
+		return "", errors.New("no PEM block found")
+	}
+	if block.Type != "PUBLIC KEY" {
+		return "", fmt.Errorf("unexpected PEM block %q, want PUBLIC KEY", block.Type)
+	}
+	if _, err := x509.ParsePKIXPublicKey(block.Bytes); err != nil {
+		return "", fmt.Errorf("parse public key: %w", err)
+	}
+	sum := sha256.Sum256(block.Bytes)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
@@ -2,6 +2,14 @@ package verify
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -85,3 +93,91 @@ func TestAvailable(t *testing.T) {
 		t.Error("expected not available for non-existent path")
 	}
 }
+
+// fakeCosign writes a stand-in cosign that accepts any key file containing
+// GOOD and rejects everything else.
+func fakeCosign(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "cosign")
+	script := `#!/bin/sh
+while [ $# -gt 0 ]; do
+  if [ "$1" = "--key" ]; then
+    grep -q GOOD "$2" && exit 0
+    echo "no matching signatures" >&2
+    exit 1
+  fi
+  shift
+done
+echo "keyless not supported" >&2
+exit 1
+`
+	if err := os.WriteFile(path, []byte(script), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestVerifyTrustedKeys(t *testing.T) {
+	keys := []TrustedKey{{Name: "old", PEM: "BAD"}, {Name: "release", PEM: "GOOD"}}
+	v := New(&testLogger{}, WithCosignPath(fakeCosign(t)), WithTrustedKeys(func() []TrustedKey { return keys }))
+
+	result := v.Verify(context.Background(), "nginx:latest")
+	if !result.Verified {
+		t.Fatalf("expected verified, got error %q", result.Error)
+	}
+	if result.Key != "release" {
+		t.Errorf("key = %q, want release", result.Key)
+	}
+
+	keys = keys[:1]
+	result = v.Verify(context.Background(), "nginx:latest")
+	if result.Verified {
+		t.Fatal("expected not verified with only the wrong key")
+	}
+	if result.Error != "no matching signatures" {
+		t.Errorf("error = %q, want the cosign message", result.Error)
+	}
+}
+
+func TestVerifyReportsEveryAttempt(t *testing.T) {
+	v := New(&testLogger{},
+		WithCosignPath(fakeCosign(t)),
+		WithTrustedKeys(func() []TrustedKey { return []TrustedKey{{Name: "release", PEM: "BAD"}} }),
+		WithKeyless(),
+	)
+	result := v.Verify(context.Background(), "nginx:latest")
+	if result.Verified {
+		t.Fatal("expected not verified")
+	}
+	if !strings.Contains(result.Error, "release: no matching signatures") || !strings.Contains(result.Error, "keyless: keyless not supported") {
+		t.Errorf("error = %q, want both attempts", result.Error)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemData := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+
+	fp, err := Fingerprint(pemData)
+	if err != nil {
+		t.Fatalf("Fingerprint: %v", err)
+	}
+	if !strings.HasPrefix(fp, "sha256:") || len(fp) != len("sha256:")+64 {
+		t.Errorf("fingerprint = %q", fp)
+	}
+
+	if _, err := Fingerprint("not a key"); err == nil {
+		t.Error("expected error for non-PEM input")
+	}
+	priv := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	if _, err := Fingerprint(priv); err == nil {
+		t.Error("expected error for a non-public-key block")
+	}
+}
@@ -163,6 +163,7 @@ func (as *AgentServer) handleLoginPost(w http.ResponseWriter, r *http.Request) {
 		UserID:    user.ID,
 		IP:        agentClientIP(r),
 		UserAgent: r.UserAgent(),
+		Source:    auth.SessionSourcePassword,
 		CreatedAt: time.Now().UTC(),
 		ExpiresAt: time.Now().UTC().Add(as.deps.Auth.SessionExpiry),
 	}
@@ -170,7 +171,7 @@ func (as *AgentServer) handleLoginPost(w http.ResponseWriter, r *http.Request) {
 		loginErr("Internal error")
 		return
 	}
-	auth.SetSessionCookie(w, token, session.ExpiresAt, as.deps.Auth.CookieSecure)
+	as.deps.Auth.Cookies().SetSession(w, token, session.ExpiresAt)
 
 	if isJSON {
 		writeAgentJSON(w, http.StatusOK, map[string]string{"redirect": "/"})
@@ -193,7 +194,7 @@ func (as *AgentServer) handleLogout(w http.ResponseWriter, r *http.Request) {
 	if token := auth.GetSessionToken(r); token != "" {
 		_ = as.deps.Auth.Sessions.DeleteSession(token)
 	}
-	auth.ClearSessionCookie(w, as.deps.Auth.CookieSecure)
+	as.deps.Auth.Cookies().ClearSession(w)
 	http.Redirect(w, r, "/login", http.StatusSeeOther)
 }
 
@@ -285,6 +286,10 @@ func (as *AgentServer) apiChangePassword(w http.ResponseWriter, r *http.Request)
 		writeAgentError(w, http.StatusInternalServerError, "failed to update password")
 		return
 	}
+	// Sessions signed in with the old password end; this one stays.
+	if _, err := as.deps.Auth.RevokeOtherSessions(rc.User.ID, auth.GetSessionToken(r)); err != nil {
+		as.deps.Log.Warn("failed to revoke sessions after password change", "error", err)
+	}
 	writeAgentJSON(w, http.StatusOK, map[string]string{"status": "ok"})
 }
 
@@ -348,7 +353,7 @@ func writeAgentJSON(w http.ResponseWriter, status int, v any) {
 }
 
 func writeAgentError(w http.ResponseWriter, status int, msg string) {
-	writeAgentJSON(w, status, map[string]string{"error": msg})
+	writeAgentJSON(w, status, newAPIError(status, msg))
 }
 
 func agentClientIP(r *http.Request) string {
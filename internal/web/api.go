@@ -8,6 +8,7 @@ import (
 
 	"github.com/Will-Luck/Docker-Sentinel/internal/auth"
 	"github.com/Will-Luck/Docker-Sentinel/internal/notify"
+	"github.com/Will-Luck/Docker-Sentinel/internal/registry"
 )
 
 // containerName extracts a clean container name from a summary.
@@ -153,9 +154,28 @@ func (s *Server) logEvent(r *http.Request, eventType, container, message string)
 	}
 }
 
+// logActionBy records an activity log entry for an action taken outside an
+// HTTP request, attributed to by.
+func (s *Server) logActionBy(by, eventType, container, message string) {
+	if s.deps.EventLog == nil {
+		return
+	}
+	if err := s.deps.EventLog.AppendLog(LogEntry{
+		Type:      eventType,
+		Message:   message,
+		Container: container,
+		User:      by,
+	}); err != nil {
+		s.deps.Log.Warn("failed to persist event log", "type", eventType, "container", container, "error", err)
+	}
+}
+
 // webReplaceTag replaces the tag portion of an image reference.
 // e.g. webReplaceTag("dxflrs/garage:v2.1.0", "v2.2.0") → "dxflrs/garage:v2.2.0"
+// and webReplaceTag("registry:5000/app:v1@sha256:...", "v2") → "registry:5000/app:v2".
 func webReplaceTag(imageRef, newTag string) string {
+	// A digest pins the old tag's image, so it is dropped with the tag.
+	imageRef, _ = registry.SplitDigest(imageRef)
 	if i := strings.LastIndex(imageRef, ":"); i >= 0 {
 		candidate := imageRef[i+1:]
 		if !strings.Contains(candidate, "/") {
@@ -0,0 +1,159 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/metrics"
+)
+
+// agentOfflineAfter matches the "for" duration of the agent offline rule.
+const agentOfflineAfter = 5 * time.Minute
+
+// Alert is one firing condition reported by /api/alerts. Names match the
+// alerts in the Prometheus rules file.
+type Alert struct {
+	Name     string    `json:"name"`
+	Severity string    `json:"severity"`
+	Subject  string    `json:"subject,omitempty"` // registry or host the alert is about
+	Message  string    `json:"message"`
+	Since    time.Time `json:"since,omitempty"`
+}
+
+// scanStaleAfter is how long without a completed scan before the scan is
+// considered stale: three poll intervals, and never less than an hour.
+func (s *Server) scanStaleAfter() time.Duration {
+	var raw string
+	if s.deps.Config != nil {
+		raw = s.deps.Config.Values()["SENTINEL_POLL_INTERVAL"]
+	}
+	if s.deps.SettingsStore != nil {
+		if saved, err := s.deps.SettingsStore.LoadSetting("poll_interval"); err == nil && saved != "" {
+			raw = saved
+		}
+	}
+	stale := time.Hour
+	if d, err := time.ParseDuration(raw); err == nil && 3*d > stale {
+		stale = 3 * d
+	}
+	return stale
+}
+
+// apiAlertRules serves the Prometheus alerting rules file for Sentinel's
+// metrics, using the current poll interval for the stale scan threshold.
+func (s *Server) apiAlertRules(w http.ResponseWriter, _ *http.Request) {
+	out, err := metrics.AlertRulesYAML(s.scanStaleAfter())
+	if err != nil {
+		s.deps.Log.Error("failed to render alert rules", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to render alert rules")
+		return
+	}
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Header().Set("Content-Disposition", `attachment; filename="sentinel-alerts.yml"`)
+	_, _ = w.Write(out)
+}
+
+// apiAlerts reports the conditions covered by the Prometheus rules (stale
+// scans, failed updates, exhausted rate limits, offline agents), evaluated
+// from Sentinel's own state for users without Prometheus. Scoped users only
+// count failed updates of their own containers.
+func (s *Server) apiAlerts(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	alerts := []Alert{}
+
+	if s.deps.Scheduler != nil {
+		staleAfter := s.scanStaleAfter()
+		if last := s.deps.Scheduler.LastScanTime(); !last.IsZero() && now.Sub(last) > staleAfter {
+			alerts = append(alerts, Alert{
+				Name:     metrics.AlertScanStale,
+				Severity: "warning",
+				Message:  fmt.Sprintf("No update scan has completed in the last %s.", staleAfter),
+				Since:    last,
+			})
+		}
+	}
+
+	if s.deps.Store != nil {
+		var failed int
+		var first time.Time
+		allowed := s.scopedHistoryFilter(r)
+		err := s.deps.Store.ForEachHistory(now.Add(-metrics.FailedUpdateWindow), time.Time{}, func(rec UpdateRecord) error {
+			if (rec.Outcome == "failed" || rec.Outcome == "rollback") && allowed(rec) {
+				failed++
+				first = rec.Timestamp // newest first, so this ends on the oldest
+			}
+			return nil
+		})
+		if err != nil {
+			s.deps.Log.Warn("failed to read history for alerts", "error", err)
+		} else if failed > 0 {
+			alerts = append(alerts, Alert{
+				Name:     metrics.AlertUpdateFailed,
+				Severity: "warning",
+				Message:  fmt.Sprintf("%d update(s) failed or were rolled back in the last %s.", failed, metrics.FailedUpdateWindow),
+				Since:    first,
+			})
+		}
+	}
+
+	if s.deps.RateTracker != nil {
+		for _, rl := range s.deps.RateTracker.Status() {
+			if rl.HasLimits && rl.Remaining == 0 && (rl.ResetAt.IsZero() || rl.ResetAt.After(now)) {
+				alerts = append(alerts, Alert{
+					Name:     metrics.AlertRateLimitExhausted,
+					Severity: "warning",
+					Subject:  rl.Registry,
+					Message:  fmt.Sprintf("No pulls left on %s until the rate limit window resets.", rl.Registry),
+					Since:    rl.LastUpdated,
+				})
+			}
+		}
+	}
+
+	for _, h := range s.offlineAgents(now) {
+		alerts = append(alerts, Alert{
+			Name:     metrics.AlertAgentOffline,
+			Severity: "critical",
+			Subject:  h.name,
+			Message:  fmt.Sprintf("Agent %s is disconnected.", h.name),
+			Since:    h.since,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"alerts": alerts, "firing": len(alerts)})
+}
+
+// offlineAgent is an active cluster host that has been disconnected for
+// longer than agentOfflineAfter.
+type offlineAgent struct {
+	name  string
+	since time.Time
+}
+
+// offlineAgents lists the active cluster hosts that are offline at now.
+// Hosts that dropped off within agentOfflineAfter are still reconnecting.
+func (s *Server) offlineAgents(now time.Time) []offlineAgent {
+	if s.deps.Cluster == nil || !s.deps.Cluster.Enabled() {
+		return nil
+	}
+	var out []offlineAgent
+	for _, h := range s.deps.Cluster.AllHosts() {
+		if h.Connected || h.State != "active" {
+			continue
+		}
+		since := h.DisconnectAt
+		if since.IsZero() {
+			since = h.LastSeen
+		}
+		if !since.IsZero() && now.Sub(since) < agentOfflineAfter {
+			continue
+		}
+		name := h.Name
+		if name == "" {
+			name = h.ID
+		}
+		out = append(out, offlineAgent{name: name, since: since})
+	}
+	return out
+}
@@ -0,0 +1,109 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/metrics"
+)
+
+type stubScheduler struct{ last time.Time }
+
+func (s *stubScheduler) SetPollInterval(time.Duration) {}
+func (s *stubScheduler) TriggerScan(context.Context)   {}
+func (s *stubScheduler) LastScanTime() time.Time       { return s.last }
+func (s *stubScheduler) SetSchedule(string)            {}
+
+func TestApiAlerts(t *testing.T) {
+	now := time.Now()
+	history := newMockHistoryStore()
+	history.records = []UpdateRecord{
+		{Timestamp: now.Add(-10 * time.Minute), ContainerName: "web", Outcome: "failed"},
+		{Timestamp: now.Add(-20 * time.Minute), ContainerName: "db", Outcome: "success"},
+		{Timestamp: now.Add(-3 * time.Hour), ContainerName: "old", Outcome: "failed"},
+	}
+	settings := newMockSettingsStore()
+	_ = settings.SaveSetting("poll_interval", "1h")
+	srv := &Server{deps: Dependencies{
+		SettingsStore: settings,
+		Store:         history,
+		Scheduler:     &stubScheduler{last: now.Add(-4 * time.Hour)},
+		RateTracker: &fakeRateTracker{statuses: []RateLimitStatus{
+			{Registry: "docker.io", Limit: 100, Remaining: 0, HasLimits: true, ResetAt: now.Add(time.Hour)},
+			{Registry: "ghcr.io", Limit: 100, Remaining: 0, HasLimits: true, ResetAt: now.Add(-time.Minute)},
+		}},
+		Log: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}}
+
+	w := httptest.NewRecorder()
+	srv.apiAlerts(w, httptest.NewRequest(http.MethodGet, "/api/alerts", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Alerts []Alert `json:"alerts"`
+		Firing int     `json:"firing"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]Alert{}
+	for _, a := range resp.Alerts {
+		got[a.Name] = a
+	}
+	if resp.Firing != 3 || len(got) != 3 {
+		t.Fatalf("alerts = %+v, want stale scan, failed update and docker.io rate limit", resp.Alerts)
+	}
+	if _, ok := got[metrics.AlertScanStale]; !ok {
+		t.Error("missing stale scan alert")
+	}
+	if a := got[metrics.AlertUpdateFailed]; !strings.HasPrefix(a.Message, "1 update(s)") {
+		t.Errorf("failed update alert = %+v, want 1 failure in the window", a)
+	}
+	if a := got[metrics.AlertRateLimitExhausted]; a.Subject != "docker.io" {
+		t.Errorf("rate limit alert subject = %q, want docker.io", a.Subject)
+	}
+}
+
+func TestApiAlertsQuiet(t *testing.T) {
+	srv := &Server{deps: Dependencies{
+		SettingsStore: newMockSettingsStore(),
+		Store:         newMockHistoryStore(),
+		Scheduler:     &stubScheduler{last: time.Now().Add(-time.Minute)},
+		Log:           slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}}
+	w := httptest.NewRecorder()
+	srv.apiAlerts(w, httptest.NewRequest(http.MethodGet, "/api/alerts", nil))
+	if !strings.Contains(w.Body.String(), `"firing":0`) || !strings.Contains(w.Body.String(), `"alerts":[]`) {
+		t.Errorf("body = %s, want no alerts", w.Body.String())
+	}
+}
+
+func TestApiAlertRules(t *testing.T) {
+	srv := &Server{deps: Dependencies{
+		SettingsStore: newMockSettingsStore(),
+		Log:           slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}}
+	_ = srv.deps.SettingsStore.SaveSetting("poll_interval", "2h")
+	w := httptest.NewRecorder()
+	srv.apiAlertRules(w, httptest.NewRequest(http.MethodGet, "/api/alerts/rules", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	if cd := w.Header().Get("Content-Disposition"); !strings.Contains(cd, "sentinel-alerts.yml") {
+		t.Errorf("Content-Disposition = %q", cd)
+	}
+	body := w.Body.String()
+	for _, want := range []string{metrics.MetricLastScanTimestamp, metrics.MetricUpdatesTotal, metrics.AlertAgentOffline, "> 21600"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("rules missing %q:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,425 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/auth"
+	"github.com/Will-Luck/Docker-Sentinel/internal/docker"
+)
+
+const (
+	auditMaxBody          = 64 << 10 // request bodies larger than this are not recorded
+	auditDefaultLimit     = 100
+	auditMaxLimit         = 500
+	defaultAuditRetention = 90 * 24 * time.Hour
+	auditPruneInterval    = time.Hour
+	auditRedacted         = "[redacted]"
+)
+
+// auditSensitiveFields are substrings of JSON field names whose values are
+// never written to the audit log.
+var auditSensitiveFields = []string{"password", "secret", "token", "apikey", "api_key", "private", "credential", "totp", "passphrase", "webhook"}
+
+type auditRecordKey struct{}
+
+// auditRecord collects what the layers below auditRequests learn about a
+// request: the actor once authentication has run, and the state a handler
+// changed.
+type auditRecord struct {
+	mu        sync.Mutex
+	actor     string
+	tokenName string
+	container string
+	before    json.RawMessage
+	after     json.RawMessage
+}
+
+func auditRecordFrom(ctx context.Context) *auditRecord {
+	rec, _ := ctx.Value(auditRecordKey{}).(*auditRecord)
+	return rec
+}
+
+// auditActor records the authenticated actor and target container on the
+// request's audit record. It runs inside the auth middleware, where the
+// request context carries the user.
+func auditActor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rec := auditRecordFrom(r.Context()); rec != nil {
+			rec.mu.Lock()
+			if rc := auth.GetRequestContext(r.Context()); rc != nil {
+				if rc.User != nil {
+					rec.actor = rc.User.Username
+				}
+				if rc.APIToken != nil {
+					rec.tokenName = rc.APIToken.Name
+				}
+			}
+			if name := r.PathValue("name"); name != "" && !strings.HasPrefix(unversionedAPIPath(r.URL.Path), "/api/volumes/") {
+				rec.container = name
+			}
+			rec.mu.Unlock()
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// auditState records the state a handler changed, for the audit log.
+// Either side may be nil, for something created or deleted.
+func auditState(r *http.Request, before, after any) {
+	rec := auditRecordFrom(r.Context())
+	if rec == nil {
+		return
+	}
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.before, rec.after = auditJSON(before), auditJSON(after)
+}
+
+// auditChanges collects before/after state for handlers that change many
+// entries at once, such as imports, grouped by section and entry key.
+type auditChanges struct {
+	before map[string]any
+	after  map[string]any
+}
+
+// entry records one changed entry of a section. before is nil for an entry
+// the request created.
+func (c *auditChanges) entry(section, key string, before, after any) {
+	if c.before == nil {
+		c.before, c.after = map[string]any{}, map[string]any{}
+	}
+	for _, side := range []struct {
+		m map[string]any
+		v any
+	}{{c.before, before}, {c.after, after}} {
+		entries, _ := side.m[section].(map[string]any)
+		if entries == nil {
+			entries = map[string]any{}
+			side.m[section] = entries
+		}
+		entries[key] = side.v
+	}
+}
+
+// section records a section that is replaced as a whole.
+func (c *auditChanges) section(name string, before, after any) {
+	if c.before == nil {
+		c.before, c.after = map[string]any{}, map[string]any{}
+	}
+	c.before[name], c.after[name] = before, after
+}
+
+// record hands the collected changes to the audit log, if there are any.
+func (c *auditChanges) record(r *http.Request) {
+	if c.before != nil {
+		auditState(r, c.before, c.after)
+	}
+}
+
+func auditJSON(v any) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil || string(data) == "null" { // typed nil, e.g. a nil pointer
+		return nil
+	}
+	return data
+}
+
+// isAuditedRequest reports whether r is a mutating API request.
+func isAuditedRequest(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return strings.HasPrefix(r.URL.Path, "/api/")
+}
+
+// auditStatusWriter records the status code a handler answered with.
+type auditStatusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *auditStatusWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *auditStatusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *auditStatusWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+// auditRequests writes an audit entry for every mutating API request once
+// it has been handled. Settings changes are diffed automatically; other
+// handlers report what they changed through auditState.
+func (s *Server) auditRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.deps.Audit == nil || !isAuditedRequest(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		path := unversionedAPIPath(r.URL.Path)
+		body := auditRequestBody(r)
+
+		var settingsBefore map[string]string
+		if strings.HasPrefix(path, "/api/settings") && s.deps.SettingsStore != nil {
+			settingsBefore, _ = s.deps.SettingsStore.GetAllSettings()
+		}
+
+		rec := &auditRecord{}
+		sw := &auditStatusWriter{ResponseWriter: w}
+		next.ServeHTTP(sw, r.WithContext(context.WithValue(r.Context(), auditRecordKey{}, rec)))
+
+		rec.mu.Lock()
+		defer rec.mu.Unlock()
+		if settingsBefore != nil && rec.before == nil && rec.after == nil {
+			if settingsAfter, err := s.deps.SettingsStore.GetAllSettings(); err == nil {
+				rec.before, rec.after = diffAuditSettings(settingsBefore, settingsAfter)
+			}
+		}
+		status := sw.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		if err := s.deps.Audit.AppendAudit(AuditEntry{
+			Timestamp: time.Now().UTC(),
+			Actor:     rec.actor,
+			TokenName: rec.tokenName,
+			IP:        clientIP(r),
+			Method:    r.Method,
+			Path:      path,
+			Status:    status,
+			Container: rec.container,
+			Request:   body,
+			Before:    rec.before,
+			After:     rec.after,
+		}); err != nil {
+			s.deps.Log.Warn("failed to write audit entry", "method", r.Method, "path", path, "error", err)
+		}
+		s.pruneAuditIfDue()
+	})
+}
+
+// auditRequestBody returns the JSON body of r with secrets redacted, and
+// puts the body back so the handler can still read it. Bodies that are not
+// JSON or are larger than auditMaxBody are not recorded.
+func auditRequestBody(r *http.Request) json.RawMessage {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+	buf, err := io.ReadAll(io.LimitReader(r.Body, auditMaxBody+1))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(buf), r.Body), r.Body}
+	if err != nil || len(buf) > auditMaxBody {
+		return nil
+	}
+	var v any
+	if err := json.Unmarshal(buf, &v); err != nil {
+		return nil
+	}
+	return auditJSON(redactAuditValue(v))
+}
+
+// redactAuditValue replaces the values of sensitive fields, at any depth.
+func redactAuditValue(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, val := range t {
+			if isSensitiveAuditField(k) {
+				t[k] = auditRedacted
+			} else {
+				t[k] = redactAuditValue(val)
+			}
+		}
+	case []any:
+		for i, val := range t {
+			t[i] = redactAuditValue(val)
+		}
+	}
+	return v
+}
+
+func isSensitiveAuditField(name string) bool {
+	lower := strings.ToLower(name)
+	for _, s := range auditSensitiveFields {
+		if strings.Contains(lower, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// diffAuditSettings returns the settings that changed, before and after.
+// Secret values are redacted but still show that they changed.
+func diffAuditSettings(before, after map[string]string) (json.RawMessage, json.RawMessage) {
+	b, a := map[string]string{}, map[string]string{}
+	for k, old := range before {
+		if nv, ok := after[k]; !ok || nv != old {
+			b[k] = old
+		}
+	}
+	for k, nv := range after {
+		if old, ok := before[k]; !ok || old != nv {
+			a[k] = nv
+		}
+	}
+	if len(b) == 0 && len(a) == 0 {
+		return nil, nil
+	}
+	for _, m := range []map[string]string{b, a} {
+		for k, v := range m {
+			if v != "" && (isSensitiveSetting(k) || isSensitiveAuditField(k)) {
+				m[k] = auditRedacted
+			}
+		}
+	}
+	return auditJSON(b), auditJSON(a)
+}
+
+// auditRetention reads the audit_retention setting. Zero means entries are
+// kept forever.
+func (s *Server) auditRetention() time.Duration {
+	if s.deps.SettingsStore != nil {
+		if v, err := s.deps.SettingsStore.LoadSetting("audit_retention"); err == nil && v != "" {
+			if d, err := docker.ParseDurationWithDays(v); err == nil && d >= 0 {
+				return d
+			}
+		}
+	}
+	return defaultAuditRetention
+}
+
+// pruneAuditIfDue drops entries older than the retention period, at most
+// once per auditPruneInterval.
+func (s *Server) pruneAuditIfDue() {
+	now := time.Now()
+	last := s.auditPrunedAt.Load()
+	if now.Sub(time.Unix(last, 0)) < auditPruneInterval || !s.auditPrunedAt.CompareAndSwap(last, now.Unix()) {
+		return
+	}
+	retention := s.auditRetention()
+	if retention == 0 {
+		return
+	}
+	if n, err := s.deps.Audit.PruneAudit(now.Add(-retention)); err != nil {
+		s.deps.Log.Warn("failed to prune audit log", "error", err)
+	} else if n > 0 {
+		s.deps.Log.Info("pruned audit log", "removed", n, "retention", retention)
+	}
+}
+
+// apiAudit lists audit entries, newest first, with keyset pagination.
+//
+// Query parameters: actor, method, path (prefix, e.g. /api/settings),
+// container, since (RFC3339 time or a duration such as 24h or 7d),
+// failed=true for 4xx/5xx responses only, before (cursor) and limit.
+// The log records every user's requests, so scoped users can't read it.
+func (s *Server) apiAudit(w http.ResponseWriter, r *http.Request) {
+	if !unscopedOnly(w, r) {
+		return
+	}
+	if s.deps.Audit == nil {
+		writeError(w, http.StatusNotImplemented, "audit log not available")
+		return
+	}
+	qv := r.URL.Query()
+	q := AuditQuery{
+		Actor:     qv.Get("actor"),
+		Method:    strings.ToUpper(qv.Get("method")),
+		Path:      unversionedAPIPath(qv.Get("path")),
+		Container: qv.Get("container"),
+		Failed:    qv.Get("failed") == "true",
+		Before:    qv.Get("before"),
+		Limit:     auditDefaultLimit,
+	}
+	if v := qv.Get("since"); v != "" {
+		since, err := parseTimelineSince(v, time.Now())
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "since must be an RFC3339 time or a duration such as 24h or 7d")
+			return
+		}
+		q.Since = since
+	}
+	if v := qv.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > auditMaxLimit {
+			writeError(w, http.StatusBadRequest, "limit must be between 1 and "+strconv.Itoa(auditMaxLimit))
+			return
+		}
+		q.Limit = n
+	}
+
+	entries, next, err := s.deps.Audit.ListAudit(q)
+	if errors.Is(err, ErrInvalidAuditCursor) {
+		writeError(w, http.StatusBadRequest, "invalid cursor")
+		return
+	}
+	if err != nil {
+		s.deps.Log.Error("failed to list audit log", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to load audit log")
+		return
+	}
+	if entries == nil {
+		entries = []AuditEntry{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"entries": entries, "next_cursor": next})
+}
+
+// apiSetAuditRetention sets how long audit entries are kept. An empty value
+// restores the default of 90 days; "0" keeps them forever.
+func (s *Server) apiSetAuditRetention(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Duration string `json:"duration"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+	if req.Duration != "" && req.Duration != "0" {
+		if d, err := docker.ParseDurationWithDays(req.Duration); err != nil || d < 24*time.Hour {
+			writeError(w, http.StatusBadRequest, "retention must be 0 (keep forever) or a duration of at least 1d")
+			return
+		}
+	}
+	if s.deps.SettingsStore == nil {
+		writeError(w, http.StatusNotImplemented, "settings store not available")
+		return
+	}
+	if err := s.deps.SettingsStore.SaveSetting("audit_retention", req.Duration); err != nil {
+		s.deps.Log.Error("failed to save audit_retention", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to save setting")
+		return
+	}
+	msg := "Audit retention reset to default (90d)"
+	switch req.Duration {
+	case "":
+	case "0":
+		msg = "Audit entries are kept forever"
+	default:
+		msg = "Audit retention set to " + req.Duration
+	}
+	s.logEvent(r, "settings", "", msg)
+	// Apply a shorter retention straight away.
+	s.auditPrunedAt.Store(0)
+	writeJSON(w, http.StatusOK, map[string]string{"message": msg})
+}
@@ -0,0 +1,275 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/auth"
+)
+
+// mockAuditStore keeps appended entries in memory and records the last
+// query and prune cutoff.
+type mockAuditStore struct {
+	entries []AuditEntry
+	next    string
+	err     error
+	query   AuditQuery
+	cutoff  time.Time
+}
+
+func (m *mockAuditStore) AppendAudit(e AuditEntry) error {
+	m.entries = append(m.entries, e)
+	return nil
+}
+
+func (m *mockAuditStore) ListAudit(q AuditQuery) ([]AuditEntry, string, error) {
+	m.query = q
+	return m.entries, m.next, m.err
+}
+
+func (m *mockAuditStore) PruneAudit(cutoff time.Time) (int, error) {
+	m.cutoff = cutoff
+	return 0, nil
+}
+
+// auditTestHandler serves mux through the audit middleware, with an
+// authenticated user injected the way the auth middleware would.
+func auditTestHandler(s *Server, mux *http.ServeMux, rc *auth.RequestContext) http.Handler {
+	return s.auditRequests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rc != nil {
+			r = r.WithContext(context.WithValue(r.Context(), auth.ContextKey, rc))
+		}
+		mux.ServeHTTP(w, r)
+	}))
+}
+
+func TestAuditRequests_RecordsActorAndState(t *testing.T) {
+	store := &mockAuditStore{}
+	s := &Server{deps: Dependencies{Audit: store, Log: slog.New(slog.NewTextHandler(io.Discard, nil))}}
+
+	var handlerBody string
+	mux := http.NewServeMux()
+	mux.Handle("POST /api/containers/{name}/policy", auditActor(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		handlerBody = string(b)
+		auditState(r, map[string]string{"policy": "manual"}, map[string]string{"policy": "auto"})
+		writeError(w, http.StatusConflict, "busy")
+	})))
+	mux.HandleFunc("GET /api/containers", func(w http.ResponseWriter, r *http.Request) {})
+
+	rc := &auth.RequestContext{
+		User:     &auth.User{Username: "alice"},
+		APIToken: &auth.APIToken{Name: "ci"},
+	}
+	h := auditTestHandler(s, mux, rc)
+
+	body := `{"policy":"auto","nested":{"api_token":"stk_secret"}}`
+	r := httptest.NewRequest(http.MethodPost, "/api/containers/web/policy", strings.NewReader(body))
+	r.RemoteAddr = "192.0.2.7:5555"
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	// Reads are not audited.
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/containers", nil))
+
+	if handlerBody != body {
+		t.Errorf("handler read %q, want the full body", handlerBody)
+	}
+	if len(store.entries) != 1 {
+		t.Fatalf("entries = %+v, want one", store.entries)
+	}
+	e := store.entries[0]
+	if e.Actor != "alice" || e.TokenName != "ci" || e.IP != "192.0.2.7" || e.Method != "POST" ||
+		e.Path != "/api/containers/web/policy" || e.Status != http.StatusConflict || e.Container != "web" {
+		t.Errorf("entry = %+v", e)
+	}
+	if string(e.Before) != `{"policy":"manual"}` || string(e.After) != `{"policy":"auto"}` {
+		t.Errorf("before = %s, after = %s", e.Before, e.After)
+	}
+	if strings.Contains(string(e.Request), "stk_secret") || !strings.Contains(string(e.Request), `"api_token":"[redacted]"`) {
+		t.Errorf("request = %s, want the token redacted", e.Request)
+	}
+}
+
+func TestAuditRequests_DiffsSettings(t *testing.T) {
+	store := &mockAuditStore{}
+	ss := newMockSettingsStore()
+	ss.data["poll_interval"] = "6h"
+	ss.data["oidc_client_secret"] = "old"
+	ss.data["paused"] = "false"
+	s := &Server{deps: Dependencies{Audit: store, SettingsStore: ss, Log: slog.New(slog.NewTextHandler(io.Discard, nil))}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/settings/test", func(w http.ResponseWriter, r *http.Request) {
+		_ = ss.SaveSetting("poll_interval", "12h")
+		_ = ss.SaveSetting("oidc_client_secret", "new")
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	h := auditTestHandler(s, mux, nil)
+	r := httptest.NewRequest(http.MethodPost, "/api/settings/test", strings.NewReader(`{"x":1}`))
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	if len(store.entries) != 1 {
+		t.Fatalf("entries = %+v", store.entries)
+	}
+	e := store.entries[0]
+	if e.Actor != "" || e.Status != http.StatusOK {
+		t.Errorf("entry = %+v", e)
+	}
+	var before, after map[string]string
+	_ = json.Unmarshal(e.Before, &before)
+	_ = json.Unmarshal(e.After, &after)
+	if len(before) != 2 || before["poll_interval"] != "6h" || before["oidc_client_secret"] != auditRedacted {
+		t.Errorf("before = %v", before)
+	}
+	if len(after) != 2 || after["poll_interval"] != "12h" || after["oidc_client_secret"] != auditRedacted {
+		t.Errorf("after = %v", after)
+	}
+}
+
+func TestAuditRequests_RecordsImportChanges(t *testing.T) {
+	store := &mockAuditStore{}
+	srv := newConfigTestServer()
+	srv.deps.Audit = store
+	srv.deps.SettingsStore.SaveSetting("poll_interval", "6h")
+	srv.deps.SettingsStore.SaveSetting("webhook_secret", "hunter2")
+	srv.deps.Policy.SetPolicyOverride("nginx", "pinned")
+	srv.deps.Queue = &mockQueue{}
+	srv.deps.IgnoredVersions = &mockIgnoredVersions{versions: map[string][]string{"redis": {"7.4"}}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/config/import", srv.apiConfigImport)
+	mux.HandleFunc("POST /api/state/import", srv.apiStateImport)
+	h := auditTestHandler(srv, mux, nil)
+
+	config := `{"version":"2","settings":{"poll_interval":"12h","webhook_secret":"s3cret"},"policies":{"nginx":"auto","redis":"manual"}}`
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/config/import", strings.NewReader(config)))
+	state := `{"version":"1","queue":[{"container_name":"nginx","current_image":"nginx:1.26"}],"ignored_versions":{"redis":["7.4","8.0"]}}`
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/state/import", strings.NewReader(state)))
+
+	if len(store.entries) != 2 {
+		t.Fatalf("entries = %+v, want one per import", store.entries)
+	}
+	cfg := store.entries[0]
+	for _, raw := range []json.RawMessage{cfg.Before, cfg.After} {
+		if strings.Contains(string(raw), "hunter2") || strings.Contains(string(raw), "s3cret") {
+			t.Errorf("audit state leaks a secret: %s", raw)
+		}
+	}
+	var before, after map[string]map[string]any
+	_ = json.Unmarshal(cfg.Before, &before)
+	_ = json.Unmarshal(cfg.After, &after)
+	if before["settings"]["poll_interval"] != "6h" || after["settings"]["poll_interval"] != "12h" {
+		t.Errorf("settings before = %v, after = %v", before["settings"], after["settings"])
+	}
+	if after["settings"]["webhook_secret"] != auditRedacted {
+		t.Errorf("webhook_secret after = %v, want redacted", after["settings"]["webhook_secret"])
+	}
+	if before["policies"]["nginx"] != "pinned" || after["policies"]["nginx"] != "auto" {
+		t.Errorf("policies before = %v, after = %v", before["policies"], after["policies"])
+	}
+	if v, ok := before["policies"]["redis"]; !ok || v != nil {
+		t.Errorf("redis policy before = %v, want null for a new override", v)
+	}
+
+	st := store.entries[1]
+	before, after = nil, nil
+	_ = json.Unmarshal(st.Before, &before)
+	_ = json.Unmarshal(st.After, &after)
+	if _, ok := before["queue"]["nginx"]; !ok || after["queue"]["nginx"] == nil {
+		t.Errorf("queue before = %v, after = %v", before["queue"], after["queue"])
+	}
+	if got, _ := json.Marshal(after["ignored_versions"]["redis"]); string(got) != `["7.4","8.0"]` {
+		t.Errorf("ignored versions after = %s", got)
+	}
+}
+
+func TestApiAudit(t *testing.T) {
+	store := &mockAuditStore{entries: []AuditEntry{{Actor: "alice", Path: "/api/settings/poll-interval", Cursor: "c1"}}, next: "c1"}
+	s := &Server{deps: Dependencies{Audit: store}}
+
+	w := httptest.NewRecorder()
+	s.apiAudit(w, httptest.NewRequest(http.MethodGet, "/api/audit?actor=alice&method=post&path=/api/v1/settings&container=web&since=24h&failed=true&before=c0&limit=10", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	q := store.query
+	if q.Actor != "alice" || q.Method != "POST" || q.Path != "/api/settings" || q.Container != "web" || !q.Failed || q.Before != "c0" || q.Limit != 10 {
+		t.Errorf("query = %+v", q)
+	}
+	if age := time.Since(q.Since); age < 23*time.Hour || age > 25*time.Hour {
+		t.Errorf("Since = %v, want about 24h ago", q.Since)
+	}
+	var resp struct {
+		Entries    []AuditEntry `json:"entries"`
+		NextCursor string       `json:"next_cursor"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || len(resp.Entries) != 1 || resp.NextCursor != "c1" {
+		t.Errorf("resp = %+v, err = %v", resp, err)
+	}
+
+	for _, url := range []string{"/api/audit?since=soon", "/api/audit?limit=0", "/api/audit?limit=501"} {
+		w := httptest.NewRecorder()
+		s.apiAudit(w, httptest.NewRequest(http.MethodGet, url, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", url, w.Code)
+		}
+	}
+
+	store.err = ErrInvalidAuditCursor
+	w = httptest.NewRecorder()
+	s.apiAudit(w, httptest.NewRequest(http.MethodGet, "/api/audit?before=zzz", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("bad cursor: status = %d, want 400", w.Code)
+	}
+}
+
+func TestAuditRetention(t *testing.T) {
+	store := &mockAuditStore{}
+	ss := newMockSettingsStore()
+	s := &Server{deps: Dependencies{Audit: store, SettingsStore: ss, Log: slog.New(slog.NewTextHandler(io.Discard, nil))}}
+
+	s.pruneAuditIfDue()
+	if age := time.Since(store.cutoff); age < defaultAuditRetention-time.Minute || age > defaultAuditRetention+time.Minute {
+		t.Errorf("cutoff = %v, want the default retention ago", store.cutoff)
+	}
+
+	// Within the prune interval nothing runs.
+	store.cutoff = time.Time{}
+	s.pruneAuditIfDue()
+	if !store.cutoff.IsZero() {
+		t.Error("pruned again within the interval")
+	}
+
+	// Saving a retention resets the interval; "0" keeps everything.
+	for _, tt := range []struct {
+		body   string
+		status int
+	}{
+		{`{"duration":"12h"}`, http.StatusBadRequest},
+		{`{"duration":"0"}`, http.StatusOK},
+	} {
+		w := httptest.NewRecorder()
+		s.apiSetAuditRetention(w, httptest.NewRequest(http.MethodPost, "/api/settings/audit-retention", strings.NewReader(tt.body)))
+		if w.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.body, w.Code, tt.status)
+		}
+	}
+	s.pruneAuditIfDue()
+	if !store.cutoff.IsZero() {
+		t.Error("pruned although retention is 0")
+	}
+
+	ss.data["audit_retention"] = "7d"
+	s.auditPrunedAt.Store(0)
+	s.pruneAuditIfDue()
+	if age := time.Since(store.cutoff); age < 7*24*time.Hour-time.Minute || age > 7*24*time.Hour+time.Minute {
+		t.Errorf("cutoff = %v, want 7 days ago", store.cutoff)
+	}
+}
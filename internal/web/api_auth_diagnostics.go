@@ -0,0 +1,146 @@
+package web
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/auth"
+)
+
+// authCheck is one finding of the sign-in diagnostics.
+type authCheck struct {
+	ID      string `json:"id"`
+	Status  string `json:"status"` // "ok", "warn" or "error"
+	Message string `json:"message"`
+}
+
+// apiAuthDiagnostics explains why sign-in may fail or loop back to the
+// login page on the address the browser is using: cookies the browser will
+// drop, origins that CSRF or WebAuthn will reject, and sessions that no
+// longer exist. Public, so it works while signed out; ?origin= is the
+// browser's window.location.origin, which can differ from what Sentinel
+// sees behind a proxy.
+// GET /api/auth/diagnostics
+func (s *Server) apiAuthDiagnostics(w http.ResponseWriter, r *http.Request) {
+	if s.deps.Auth == nil {
+		writeError(w, http.StatusNotFound, "authentication is not configured")
+		return
+	}
+	svc := s.deps.Auth
+	opts := svc.Cookies()
+	serverOrigin := auth.NormaliseOrigin(auth.RequestOrigin(r))
+	browserOrigin := auth.NormaliseOrigin(r.URL.Query().Get("origin"))
+	if browserOrigin == "" {
+		browserOrigin = serverOrigin
+	}
+	checks := s.authChecks(r, browserOrigin, serverOrigin)
+
+	problems := 0
+	for _, c := range checks {
+		if c.Status != "ok" {
+			problems++
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"origin":          browserOrigin,
+		"server_origin":   serverOrigin,
+		"auth_enabled":    svc.AuthEnabled(),
+		"cookie_secure":   opts.Secure,
+		"cookie_domain":   opts.Domain,
+		"cookie_samesite": auth.SameSiteName(opts.SameSite),
+		"allowed_origins": svc.AllowedOrigins,
+		"checks":          checks,
+		"problems":        problems,
+	})
+}
+
+// authChecks runs the sign-in diagnostics for a browser on browserOrigin
+// whose requests Sentinel sees as serverOrigin.
+func (s *Server) authChecks(r *http.Request, browserOrigin, serverOrigin string) []authCheck {
+	svc := s.deps.Auth
+	opts := svc.Cookies()
+	u, _ := url.Parse(browserOrigin)
+	host, scheme := u.Hostname(), u.Scheme
+	var checks []authCheck
+	add := func(id, status, msg string) {
+		checks = append(checks, authCheck{ID: id, Status: status, Message: msg})
+	}
+
+	// Secure cookies are discarded over plain HTTP (localhost excepted), so
+	// the session set at sign-in never comes back: the classic login loop.
+	switch {
+	case opts.Secure && scheme == "http" && !isLoopbackHost(host):
+		add("cookie_secure", "error", "Session cookies are marked Secure but "+browserOrigin+" is plain HTTP, so the browser drops them and every sign-in returns to the login page. Use HTTPS, or set SENTINEL_COOKIE_SECURE=false for HTTP access.")
+	case !opts.Secure && scheme == "https":
+		add("cookie_secure", "warn", "Session cookies are not marked Secure on an HTTPS address. Set SENTINEL_COOKIE_SECURE=true.")
+	default:
+		add("cookie_secure", "ok", "Cookie Secure flag suits "+scheme+".")
+	}
+
+	if opts.Domain != "" && !auth.DomainMatches(opts.Domain, host) {
+		add("cookie_domain", "error", "SENTINEL_COOKIE_DOMAIN is "+opts.Domain+", which does not cover "+host+", so the browser rejects the session cookie here. Clear it, or reach Sentinel through a host under "+opts.Domain+".")
+	} else {
+		add("cookie_domain", "ok", "Cookie domain is accepted on "+host+".")
+	}
+
+	switch opts.SameSite {
+	case http.SameSiteStrictMode:
+		if s.getOIDCProvider() != nil {
+			add("cookie_samesite", "error", "SameSite=strict cookies are not sent after the SSO redirect back from the identity provider, so SSO sign-in loops. Use SENTINEL_COOKIE_SAMESITE=lax.")
+		} else {
+			add("cookie_samesite", "warn", "SameSite=strict cookies are not sent when following a link from another site (such as a notification), so those links open the login page.")
+		}
+	case http.SameSiteNoneMode:
+		add("cookie_samesite", "ok", "SameSite=none: cookies are sent on cross-site requests too.")
+	default:
+		add("cookie_samesite", "ok", "SameSite=lax.")
+	}
+
+	if browserOrigin != serverOrigin {
+		add("proxy_origin", "warn", "Sentinel sees this request as "+serverOrigin+" but the browser is on "+browserOrigin+". Have the proxy forward X-Forwarded-Proto and X-Forwarded-Host, or add "+browserOrigin+" to SENTINEL_ALLOWED_ORIGINS.")
+	}
+	if len(svc.AllowedOrigins) > 0 {
+		if browserOrigin == serverOrigin || slices.Contains(svc.AllowedOrigins, browserOrigin) {
+			add("allowed_origin", "ok", browserOrigin+" is an accepted origin.")
+		} else {
+			add("allowed_origin", "error", browserOrigin+" is not in SENTINEL_ALLOWED_ORIGINS, so changes made from here fail the CSRF origin check.")
+		}
+	}
+
+	if s.webauthn != nil {
+		rpid := s.webauthn.Config.RPID
+		switch {
+		case slices.Contains(s.webauthn.Config.RPOrigins, browserOrigin):
+			add("webauthn_origin", "ok", "Passkeys are available on "+browserOrigin+".")
+		case host != rpid && !strings.HasSuffix(host, "."+rpid):
+			add("webauthn_origin", "warn", "Passkeys registered for "+rpid+" cannot be used on "+host+"; sign in with a password here.")
+		default:
+			add("webauthn_origin", "warn", "Passkeys are not enabled on "+browserOrigin+". Add it to SENTINEL_WEBAUTHN_ORIGINS or SENTINEL_ALLOWED_ORIGINS.")
+		}
+	}
+
+	if token := auth.GetSessionToken(r); token != "" {
+		if svc.ValidateSession(r.Context(), token) == nil {
+			add("session", "warn", "The browser sent a session that has expired or been revoked. Sign in again.")
+		} else {
+			add("session", "ok", "The browser sent a valid session.")
+			if _, err := r.Cookie(auth.CSRFCookieName); err != nil {
+				add("csrf_cookie", "warn", "No CSRF cookie was sent with the session, so changes will be rejected until the page is reloaded.")
+			}
+		}
+	}
+	return checks
+}
+
+// isLoopbackHost reports whether host is localhost or a loopback address,
+// where browsers keep Secure cookies even over HTTP.
+func isLoopbackHost(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
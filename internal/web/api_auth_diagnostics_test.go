@@ -0,0 +1,60 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestApiAuthDiagnostics(t *testing.T) {
+	srv := newMFATestServer()
+	srv.deps.Auth.CookieSecure = true
+	srv.deps.Auth.CookieDomain = "example.com"
+	srv.deps.Auth.AllowedOrigins = []string{"https://sentinel.example.com"}
+
+	diagnose := func(origin string) map[string]string {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "http://192.0.2.10:8080/api/auth/diagnostics?origin="+origin, nil)
+		w := httptest.NewRecorder()
+		srv.apiAuthDiagnostics(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+		}
+		var resp struct {
+			Checks []authCheck `json:"checks"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		got := map[string]string{}
+		for _, c := range resp.Checks {
+			got[c.ID] = c.Status
+		}
+		return got
+	}
+
+	// Plain HTTP on the LAN address: Secure cookies are dropped and the
+	// cookie domain does not cover an IP.
+	got := diagnose("")
+	if got["cookie_secure"] != "error" || got["cookie_domain"] != "error" {
+		t.Errorf("LAN checks = %v, want cookie_secure and cookie_domain errors", got)
+	}
+	if _, ok := got["proxy_origin"]; ok {
+		t.Errorf("LAN checks = %v, want no proxy warning for the server's own origin", got)
+	}
+
+	// Through the tunnel: HTTPS on an allowed origin under the cookie domain,
+	// but the proxy did not forward the browser's host.
+	got = diagnose("https://sentinel.example.com")
+	if got["cookie_secure"] != "ok" || got["cookie_domain"] != "ok" || got["allowed_origin"] != "ok" {
+		t.Errorf("tunnel checks = %v, want cookies and origin ok", got)
+	}
+	if got["proxy_origin"] != "warn" {
+		t.Errorf("tunnel checks = %v, want a proxy_origin warning", got)
+	}
+
+	if got = diagnose("https://other.example.net"); got["allowed_origin"] != "error" {
+		t.Errorf("unlisted origin checks = %v, want allowed_origin error", got)
+	}
+}
@@ -1,12 +1,28 @@
 package web
 
 import (
+	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+	cron "github.com/robfig/cron/v3"
 )
 
+// maxRestoreSize caps the size of an uploaded database for restore.
+const maxRestoreSize = 1 << 30
+
 // apiBackupTrigger creates an immediate backup.
 func (s *Server) apiBackupTrigger(w http.ResponseWriter, r *http.Request) {
+	if !unscopedOnly(w, r) {
+		return
+	}
 	if s.deps.Backup == nil {
 		writeError(w, http.StatusNotImplemented, "backup not configured")
 		return
@@ -22,7 +38,10 @@ func (s *Server) apiBackupTrigger(w http.ResponseWriter, r *http.Request) {
 }
 
 // apiBackupList returns available backup files.
-func (s *Server) apiBackupList(w http.ResponseWriter, _ *http.Request) {
+func (s *Server) apiBackupList(w http.ResponseWriter, r *http.Request) {
+	if !unscopedOnly(w, r) {
+		return
+	}
 	if s.deps.Backup == nil {
 		writeError(w, http.StatusNotImplemented, "backup not configured")
 		return
@@ -37,6 +56,9 @@ func (s *Server) apiBackupList(w http.ResponseWriter, _ *http.Request) {
 
 // apiBackupDownload serves a backup file for download.
 func (s *Server) apiBackupDownload(w http.ResponseWriter, r *http.Request) {
+	if !unscopedOnly(w, r) {
+		return
+	}
 	if s.deps.Backup == nil {
 		writeError(w, http.StatusNotImplemented, "backup not configured")
 		return
@@ -55,3 +77,145 @@ func (s *Server) apiBackupDownload(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/octet-stream")
 	http.ServeFile(w, r, path)
 }
+
+// apiAdminBackup streams a consistent snapshot of the database as a file
+// download, without keeping a copy on the server.
+func (s *Server) apiAdminBackup(w http.ResponseWriter, r *http.Request) {
+	if !unscopedOnly(w, r) {
+		return
+	}
+	if s.deps.Backup == nil {
+		writeError(w, http.StatusNotImplemented, "backup not configured")
+		return
+	}
+	filename := "sentinel-" + time.Now().UTC().Format("20060102-150405") + ".db"
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.Header().Set("Content-Type", "application/octet-stream")
+	n, err := s.deps.Backup.WriteSnapshot(w)
+	if err != nil {
+		// Headers are gone once bytes are written; all that's left is to log.
+		s.deps.Log.Error("database snapshot failed", "error", err, "written", n)
+		if n == 0 {
+			w.Header().Del("Content-Disposition")
+			writeError(w, http.StatusInternalServerError, "backup failed: "+err.Error())
+		}
+		return
+	}
+	s.logEvent(r, "backup", "", "Database backup downloaded ("+strconv.FormatInt(n, 10)+" bytes)")
+}
+
+// apiGetBackupSettings returns the scheduled backup settings.
+func (s *Server) apiGetBackupSettings(w http.ResponseWriter, _ *http.Request) {
+	if s.deps.Backup == nil {
+		writeError(w, http.StatusNotImplemented, "backup not configured")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.deps.Backup.Config())
+}
+
+// apiSaveBackupSettings validates, applies and persists the scheduled backup
+// settings. Changes take effect immediately.
+func (s *Server) apiSaveBackupSettings(w http.ResponseWriter, r *http.Request) {
+	if s.deps.Backup == nil {
+		writeError(w, http.StatusNotImplemented, "backup not configured")
+		return
+	}
+	var body BackupConfig
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	body.Schedule = strings.TrimSpace(body.Schedule)
+	body.Dir = strings.TrimSpace(body.Dir)
+	if body.Schedule != "" {
+		if _, err := cron.ParseStandard(body.Schedule); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid cron expression: "+err.Error())
+			return
+		}
+	}
+	if body.Dir == "" || !filepath.IsAbs(body.Dir) {
+		writeError(w, http.StatusBadRequest, "backup directory must be an absolute path")
+		return
+	}
+	if body.Retention < 0 {
+		writeError(w, http.StatusBadRequest, "retention must be 0 (unlimited) or more")
+		return
+	}
+	if s.deps.SettingsStore == nil {
+		writeError(w, http.StatusNotImplemented, "settings store not available")
+		return
+	}
+	if err := s.deps.Backup.Configure(body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	for key, val := range map[string]string{
+		"backup_schedule":  body.Schedule,
+		"backup_dir":       body.Dir,
+		"backup_retention": strconv.Itoa(body.Retention),
+	} {
+		if err := s.deps.SettingsStore.SaveSetting(key, val); err != nil {
+			s.deps.Log.Error("failed to save backup setting", "key", key, "error", err)
+			writeError(w, http.StatusInternalServerError, "failed to save setting")
+			return
+		}
+	}
+	msg := "Scheduled backups disabled"
+	if body.Schedule != "" {
+		msg = "Scheduled backups set to " + body.Schedule + " in " + body.Dir
+	}
+	s.logEvent(r, "settings", "", msg)
+	writeJSON(w, http.StatusOK, body)
+}
+
+// apiAdminRestore replaces the live database with an uploaded backup. The
+// request body is the raw database file. It is validated before anything
+// is touched; the replaced database is kept next to the live one.
+func (s *Server) apiAdminRestore(w http.ResponseWriter, r *http.Request) {
+	if !unscopedOnly(w, r) {
+		return
+	}
+	if s.deps.Restorer == nil {
+		writeError(w, http.StatusNotImplemented, "restore not available")
+		return
+	}
+	tmp, err := os.CreateTemp("", "sentinel-restore-*.db")
+	if err != nil {
+		s.deps.Log.Error("restore: create temp file", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to stage upload")
+		return
+	}
+	defer os.Remove(tmp.Name())
+	n, err := io.Copy(tmp, http.MaxBytesReader(w, r.Body, maxRestoreSize))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "failed to read upload: "+err.Error())
+		return
+	}
+	if n == 0 {
+		writeError(w, http.StatusBadRequest, "no database file uploaded")
+		return
+	}
+	if err := s.deps.Restorer.ValidateBackup(tmp.Name()); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.deps.Restorer.Restore(tmp.Name()); err != nil {
+		if errors.Is(err, store.ErrWrongEncryptionKey) || errors.Is(err, store.ErrEncryptionKeyRequired) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.deps.Log.Error("database restore failed", "error", err)
+		writeError(w, http.StatusInternalServerError, "restore failed: "+err.Error())
+		return
+	}
+	s.deps.Log.Warn("database restored from upload", "size", n)
+	s.logEvent(r, "restore", "", "Database restored from backup ("+strconv.FormatInt(n, 10)+" bytes)")
+	writeJSON(w, http.StatusOK, map[string]any{
+		"status":  "restored",
+		"size":    n,
+		"message": "Database restored. Sessions not in the backup are signed out; restart Sentinel to reload settings cached at startup.",
+	})
+}
@@ -8,8 +8,12 @@ import (
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/auth"
 )
 
 // ---------------------------------------------------------------------------
@@ -23,6 +27,23 @@ type mockBackupManager struct {
 	listErr    error
 	filePaths  map[string]string // filename -> path
 	fileErr    error
+	running    bool
+	snapshot   string
+	config     BackupConfig
+}
+
+func (m *mockBackupManager) InProgress() bool { return m.running }
+
+func (m *mockBackupManager) WriteSnapshot(w io.Writer) (int64, error) {
+	n, err := io.WriteString(w, m.snapshot)
+	return int64(n), err
+}
+
+func (m *mockBackupManager) Config() BackupConfig { return m.config }
+
+func (m *mockBackupManager) Configure(cfg BackupConfig) error {
+	m.config = cfg
+	return nil
 }
 
 func (m *mockBackupManager) CreateBackup(_ context.Context) (*BackupInfo, error) {
@@ -72,12 +93,15 @@ func TestApiBackupTrigger_NotConfigured(t *testing.T) {
 		t.Fatalf("status = %d, want %d; body: %s", w.Code, http.StatusNotImplemented, w.Body.String())
 	}
 
-	var resp map[string]string
+	var resp APIError
 	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("decode response: %v", err)
 	}
-	if resp["error"] != "backup not configured" {
-		t.Errorf("error = %q, want %q", resp["error"], "backup not configured")
+	if resp.Error != "backup not configured" {
+		t.Errorf("error = %q, want %q", resp.Error, "backup not configured")
+	}
+	if resp.Code != CodeNotConfigured {
+		t.Errorf("code = %q, want %q", resp.Code, CodeNotConfigured)
 	}
 }
 
@@ -229,3 +253,194 @@ func TestApiBackupDownload_FileNotFound(t *testing.T) {
 		t.Fatalf("status = %d, want %d; body: %s", w.Code, http.StatusNotFound, w.Body.String())
 	}
 }
+
+// ---------------------------------------------------------------------------
+// /api/admin backup and restore tests
+// ---------------------------------------------------------------------------
+
+type mockRestorer struct {
+	invalid  error
+	restored []byte
+}
+
+func (m *mockRestorer) ValidateBackup(string) error { return m.invalid }
+
+func (m *mockRestorer) Restore(path string) error {
+	data, err := os.ReadFile(path)
+	m.restored = data
+	return err
+}
+
+func TestApiAdminBackup_StreamsSnapshot(t *testing.T) {
+	srv := newBackupTestServer(&mockBackupManager{snapshot: "bolt-bytes"})
+
+	w := httptest.NewRecorder()
+	srv.apiAdminBackup(w, httptest.NewRequest(http.MethodPost, "/api/admin/backup", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	if w.Body.String() != "bolt-bytes" {
+		t.Errorf("body = %q, want the snapshot", w.Body.String())
+	}
+	if cd := w.Header().Get("Content-Disposition"); !strings.Contains(cd, "attachment") || !strings.Contains(cd, ".db") {
+		t.Errorf("Content-Disposition = %q, want a .db attachment", cd)
+	}
+}
+
+func TestApiSaveBackupSettings(t *testing.T) {
+	mgr := &mockBackupManager{}
+	settings := newMockSettingsStore()
+	srv := newBackupTestServer(mgr)
+	srv.deps.SettingsStore = settings
+
+	save := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		srv.apiSaveBackupSettings(w, httptest.NewRequest(http.MethodPost, "/api/admin/backup/settings", strings.NewReader(body)))
+		return w
+	}
+
+	for _, body := range []string{
+		`{"schedule":"not a cron","dir":"/data/backups","retention":7}`,
+		`{"schedule":"0 3 * * *","dir":"backups","retention":7}`,
+		`{"schedule":"0 3 * * *","dir":"/data/backups","retention":-1}`,
+	} {
+		if w := save(body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", body, w.Code)
+		}
+	}
+	if mgr.config != (BackupConfig{}) {
+		t.Fatalf("invalid settings were applied: %+v", mgr.config)
+	}
+
+	w := save(`{"schedule":"0 3 * * *","dir":"/data/backups","retention":14}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	want := BackupConfig{Schedule: "0 3 * * *", Dir: "/data/backups", Retention: 14}
+	if mgr.config != want {
+		t.Errorf("applied = %+v, want %+v", mgr.config, want)
+	}
+	if v, _ := settings.LoadSetting("backup_retention"); v != "14" {
+		t.Errorf("backup_retention = %q, want 14", v)
+	}
+	if v, _ := settings.LoadSetting("backup_schedule"); v != "0 3 * * *" {
+		t.Errorf("backup_schedule = %q", v)
+	}
+}
+
+func TestApiAdminRestore(t *testing.T) {
+	restorer := &mockRestorer{}
+	srv := newBackupTestServer(nil)
+	srv.deps.Restorer = restorer
+
+	restore := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		srv.apiAdminRestore(w, httptest.NewRequest(http.MethodPost, "/api/admin/restore", strings.NewReader(body)))
+		return w
+	}
+
+	if w := restore(""); w.Code != http.StatusBadRequest {
+		t.Errorf("empty upload: status = %d, want 400", w.Code)
+	}
+
+	restorer.invalid = errors.New("invalid backup: not a Sentinel database")
+	if w := restore("garbage"); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid upload: status = %d, want 400", w.Code)
+	}
+	if restorer.restored != nil {
+		t.Fatal("an invalid upload was restored")
+	}
+
+	restorer.invalid = nil
+	w := restore("bolt-bytes")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	if string(restorer.restored) != "bolt-bytes" {
+		t.Errorf("restored %q, want the uploaded file", restorer.restored)
+	}
+}
+
+// TestBackupRoutesScoped checks that every backup route, which covers all
+// containers, refuses users with a container scope.
+func TestBackupRoutesScoped(t *testing.T) {
+	mgr := &mockBackupManager{
+		createInfo: &BackupInfo{Filename: "sentinel-20260306-120000.db"},
+		listInfos:  []BackupInfo{{Filename: "sentinel-20260306-120000.db"}},
+		filePaths:  map[string]string{"backup.db": os.DevNull},
+		snapshot:   "bolt-bytes",
+	}
+	restorer := &mockRestorer{}
+	srv := newBackupTestServer(mgr)
+	srv.deps.Restorer = restorer
+	scope := &auth.ContainerScope{Stacks: []string{"shop"}}
+
+	for _, tt := range []struct {
+		method, path string
+		handler      http.HandlerFunc
+	}{
+		{http.MethodPost, "/api/backup/trigger", srv.apiBackupTrigger},
+		{http.MethodGet, "/api/backup/list", srv.apiBackupList},
+		{http.MethodGet, "/api/backup/download/backup.db", srv.apiBackupDownload},
+		{http.MethodPost, "/api/admin/backup", srv.apiAdminBackup},
+		{http.MethodPost, "/api/admin/restore", srv.apiAdminRestore},
+	} {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(tt.method, tt.path, strings.NewReader("bolt-bytes"))
+		r.SetPathValue("filename", "backup.db")
+		tt.handler(w, withScope(r, scope))
+		if w.Code != http.StatusForbidden {
+			t.Errorf("%s %s: status = %d, want 403", tt.method, tt.path, w.Code)
+		}
+		if strings.Contains(w.Body.String(), "bolt-bytes") || strings.Contains(w.Body.String(), "sentinel-2026") {
+			t.Errorf("%s %s leaked backup data: %s", tt.method, tt.path, w.Body.String())
+		}
+	}
+	if restorer.restored != nil {
+		t.Error("a scoped user restored a backup")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// readOnlyDuringBackup tests
+// ---------------------------------------------------------------------------
+
+func TestReadOnlyDuringBackup(t *testing.T) {
+	mgr := &mockBackupManager{running: true}
+	srv := newBackupTestServer(mgr)
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	h := srv.readOnlyDuringBackup(next)
+
+	cases := []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodPost, "/api/containers/nginx/update", http.StatusServiceUnavailable},
+		{http.MethodGet, "/api/containers", http.StatusNoContent},
+		{http.MethodPost, "/api/auth/logout", http.StatusNoContent},
+		{http.MethodPost, "/login", http.StatusNoContent},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+		if w.Code != tc.want {
+			t.Errorf("%s %s: status = %d, want %d", tc.method, tc.path, w.Code, tc.want)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/settings", nil))
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("expected Retry-After header on read-only rejection")
+	}
+
+	mgr.running = false
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/settings", nil))
+	if w.Code != http.StatusNoContent {
+		t.Errorf("after backup: status = %d, want 204", w.Code)
+	}
+}
@@ -0,0 +1,63 @@
+package web
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/docker"
+)
+
+// capabilitiesResponse describes what the local Docker connection permits.
+type capabilitiesResponse struct {
+	Host         string              `json:"host"`
+	Capabilities docker.Capabilities `json:"capabilities"`
+	UpdateReason string              `json:"update_blocked,omitempty"` // why recreate updates would fail
+}
+
+// apiCapabilities returns the effective capability set of the local host.
+func (s *Server) apiCapabilities(w http.ResponseWriter, r *http.Request) {
+	if s.deps.Capabilities == nil {
+		writeJSON(w, http.StatusOK, capabilitiesResponse{Host: "local"})
+		return
+	}
+	caps := s.deps.Capabilities.Capabilities(r.Context())
+	writeJSON(w, http.StatusOK, capabilitiesResponse{
+		Host:         "local",
+		Capabilities: caps,
+		UpdateReason: caps.Reason(docker.UpdateOperations...),
+	})
+}
+
+// apiRefreshCapabilities re-runs the permission probes, e.g. after the
+// socket proxy configuration has been changed.
+func (s *Server) apiRefreshCapabilities(w http.ResponseWriter, r *http.Request) {
+	if s.deps.Capabilities == nil {
+		writeError(w, http.StatusNotImplemented, "permission probes not available")
+		return
+	}
+	caps := s.deps.Capabilities.RefreshCapabilities(r.Context())
+	s.logEvent(r, "settings", "", "Docker permission probes re-run")
+	writeJSON(w, http.StatusOK, capabilitiesResponse{
+		Host:         "local",
+		Capabilities: caps,
+		UpdateReason: caps.Reason(docker.UpdateOperations...),
+	})
+}
+
+// updateBlocked returns why a local container cannot be updated with the
+// current Docker permissions, or "" when nothing is known to be missing.
+func (s *Server) updateBlocked(ctx context.Context, name string, labels map[string]string) string {
+	if s.deps.Capabilities == nil {
+		return ""
+	}
+	return s.deps.Capabilities.UpdateBlocked(ctx, name, labels)
+}
+
+// localUpdatesBlocked returns why recreate updates on the local host would be
+// refused, for the dashboard banner.
+func (s *Server) localUpdatesBlocked(ctx context.Context) string {
+	if s.deps.Capabilities == nil {
+		return ""
+	}
+	return s.deps.Capabilities.Capabilities(ctx).Reason(docker.UpdateOperations...)
+}
@@ -0,0 +1,79 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/docker"
+)
+
+type mockCapabilities struct {
+	caps docker.Capabilities
+}
+
+func (m *mockCapabilities) Capabilities(context.Context) docker.Capabilities        { return m.caps }
+func (m *mockCapabilities) RefreshCapabilities(context.Context) docker.Capabilities { return m.caps }
+func (m *mockCapabilities) UpdateBlocked(_ context.Context, _ string, _ map[string]string) string {
+	return m.caps.Reason(docker.UpdateOperations...)
+}
+
+func newCapabilitiesTestServer(caps CapabilityChecker) *Server {
+	return &Server{
+		deps: Dependencies{
+			Docker: &mockContainerLister{containers: []ContainerSummary{
+				{ID: "abc", Names: []string{"/nginx"}, State: "running"},
+			}},
+			Queue:        &mockUpdateQueue{},
+			Capabilities: caps,
+			Cluster:      NewClusterController(),
+			Log:          slog.New(slog.NewTextHandler(io.Discard, nil)),
+		},
+	}
+}
+
+func TestApiUpdateRefusedWhenPermissionDenied(t *testing.T) {
+	srv := newCapabilitiesTestServer(&mockCapabilities{caps: docker.Capabilities{
+		Denied: map[docker.Operation]string{docker.OpCreate: "403 Forbidden"},
+	}})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/update/nginx", nil)
+	r.SetPathValue("name", "nginx")
+	srv.apiUpdate(w, r)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want 409; body = %s", w.Code, w.Body.String())
+	}
+}
+
+func TestApiCapabilities(t *testing.T) {
+	srv := newCapabilitiesTestServer(&mockCapabilities{caps: docker.Capabilities{
+		Denied: map[docker.Operation]string{docker.OpStop: "403 Forbidden"},
+	}})
+
+	w := httptest.NewRecorder()
+	srv.apiCapabilities(w, httptest.NewRequest(http.MethodGet, "/api/capabilities", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	var resp capabilitiesResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.UpdateReason == "" || resp.Capabilities.Denied[docker.OpStop] == "" {
+		t.Errorf("response = %+v, want stop denied with a reason", resp)
+	}
+
+	// Without a checker nothing is reported as blocked.
+	w = httptest.NewRecorder()
+	newCapabilitiesTestServer(nil).apiCapabilities(w, httptest.NewRequest(http.MethodGet, "/api/capabilities", nil))
+	var empty capabilitiesResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &empty); err != nil || empty.UpdateReason != "" {
+		t.Errorf("nil checker: resp = %+v, err = %v", empty, err)
+	}
+}
@@ -0,0 +1,259 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+	"github.com/Will-Luck/Docker-Sentinel/internal/registry"
+)
+
+// bulkCheckRegistryConcurrency caps how many registries are checked at once.
+// Containers on the same registry are always checked one after another.
+const bulkCheckRegistryConcurrency = 4
+
+// bulkCheckRequest selects the containers for a bulk check. All filters are
+// optional; empty means every monitored container. Hosts are cluster host
+// IDs, with "local" selecting this host's containers.
+type bulkCheckRequest struct {
+	Names  []string `json:"names"`
+	Stacks []string `json:"stacks"`
+	Hosts  []string `json:"hosts"`
+}
+
+// bulkCheckTarget is one container queued for a bulk check.
+type bulkCheckTarget struct {
+	ID       string
+	Name     string
+	Image    string
+	HostID   string
+	HostName string
+}
+
+// bulkCheckItem is the per-container outcome of a bulk check.
+type bulkCheckItem struct {
+	Name          string   `json:"name"`
+	HostID        string   `json:"host_id,omitempty"`
+	Image         string   `json:"image"`
+	Registry      string   `json:"registry"`
+	Status        string   `json:"status"` // "update_available", "up_to_date", "ignored" or "failed"
+	NewerVersions []string `json:"newer_versions,omitempty"`
+	Error         string   `json:"error,omitempty"`
+}
+
+// bulkCheckResult is the consolidated response. Updates counts exactly the
+// entries that were added to the queue.
+type bulkCheckResult struct {
+	Total      int             `json:"total"`
+	Registries int             `json:"registries"`
+	Updates    int             `json:"updates"`
+	UpToDate   int             `json:"up_to_date"`
+	Failed     int             `json:"failed"`
+	Results    []bulkCheckItem `json:"results"`
+}
+
+// apiBulkCheck checks many containers against their registries in one call.
+// Checks are batched per registry (each image is checked once per registry
+// batch), progress is published as check_progress SSE events, and the
+// response summarises exactly what was queued.
+func (s *Server) apiBulkCheck(w http.ResponseWriter, r *http.Request) {
+	if s.deps.RegistryChecker == nil {
+		writeError(w, http.StatusNotImplemented, "registry checker not available")
+		return
+	}
+
+	var req bulkCheckRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	for _, name := range req.Names {
+		if !isValidContainerName(name) {
+			writeError(w, http.StatusBadRequest, "invalid container name: "+name)
+			return
+		}
+	}
+
+	targets, err := s.bulkCheckTargets(r.Context(), req)
+	if err != nil {
+		s.deps.Log.Error("failed to list containers for bulk check", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to list containers")
+		return
+	}
+
+	// Group by registry so each registry is hit serially.
+	batches := make(map[string][]bulkCheckTarget)
+	for _, t := range targets {
+		reg := registry.RegistryHost(t.Image)
+		batches[reg] = append(batches[reg], t)
+	}
+
+	s.logEvent(r, "check", "", fmt.Sprintf("Bulk registry check of %d containers across %d registries", len(targets), len(batches)))
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		done    int
+		results = make([]bulkCheckItem, 0, len(targets))
+		sem     = make(chan struct{}, bulkCheckRegistryConcurrency)
+	)
+	for reg, batch := range batches {
+		wg.Add(1)
+		go func(reg string, batch []bulkCheckTarget) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			type checked struct {
+				available       bool
+				newer           []string
+				resolvedCurrent string
+				resolvedTarget  string
+				err             error
+			}
+			cache := make(map[string]checked)
+			for _, t := range batch {
+				c, ok := cache[t.Image]
+				if !ok {
+					c.available, c.newer, c.resolvedCurrent, c.resolvedTarget, c.err = s.deps.RegistryChecker.CheckForUpdate(r.Context(), t.Image)
+					cache[t.Image] = c
+				}
+				item := s.applyBulkCheck(t, reg, c.available, c.newer, c.resolvedCurrent, c.resolvedTarget, c.err)
+
+				mu.Lock()
+				results = append(results, item)
+				done++
+				progress := done
+				mu.Unlock()
+
+				s.deps.EventBus.Publish(events.SSEEvent{
+					Type:          events.EventCheckProgress,
+					ContainerName: t.Name,
+					HostID:        t.HostID,
+					Message:       fmt.Sprintf("checked=%d total=%d status=%s", progress, len(targets), item.Status),
+					Timestamp:     time.Now(),
+				})
+			}
+		}(reg, batch)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].HostID != results[j].HostID {
+			return results[i].HostID < results[j].HostID
+		}
+		return results[i].Name < results[j].Name
+	})
+	out := bulkCheckResult{Total: len(results), Registries: len(batches), Results: results}
+	for _, it := range results {
+		switch it.Status {
+		case "update_available":
+			out.Updates++
+		case "failed":
+			out.Failed++
+		default:
+			out.UpToDate++
+		}
+	}
+	if out.Updates > 0 {
+		s.deps.EventBus.Publish(events.SSEEvent{
+			Type:      events.EventQueueChange,
+			Message:   fmt.Sprintf("%d updates found by bulk check", out.Updates),
+			Timestamp: time.Now(),
+		})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// bulkCheckTargets resolves the request filters to local and remote containers.
+// Unmanaged containers and Swarm tasks are skipped.
+func (s *Server) bulkCheckTargets(ctx context.Context, req bulkCheckRequest) ([]bulkCheckTarget, error) {
+	wantHost := func(id string) bool {
+		return len(req.Hosts) == 0 || slices.Contains(req.Hosts, id)
+	}
+	scope := contextScope(ctx)
+	wantContainer := func(name string, labels map[string]string) bool {
+		if !scope.Matches(name, labels) {
+			return false
+		}
+		if len(req.Names) > 0 && !slices.Contains(req.Names, name) {
+			return false
+		}
+		return len(req.Stacks) == 0 || slices.Contains(req.Stacks, labels["com.docker.compose.project"])
+	}
+	unmanaged := s.unmanagedSet()
+
+	var targets []bulkCheckTarget
+	if wantHost("local") {
+		containers, err := s.deps.Docker.ListAllContainers(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range containers {
+			if _, isTask := c.Labels["com.docker.swarm.task"]; isTask {
+				continue
+			}
+			name := containerName(c)
+			if _, skip := unmanaged[name]; skip || !wantContainer(name, c.Labels) {
+				continue
+			}
+			targets = append(targets, bulkCheckTarget{ID: c.ID, Name: name, Image: c.Image})
+		}
+	}
+	if s.deps.Cluster != nil && s.deps.Cluster.Enabled() {
+		for _, c := range s.deps.Cluster.AllHostContainers() {
+			if !wantHost(c.HostID) {
+				continue
+			}
+			if _, skip := unmanaged[c.HostID+"::"+c.Name]; skip || !wantContainer(c.Name, c.Labels) {
+				continue
+			}
+			targets = append(targets, bulkCheckTarget{Name: c.Name, Image: c.Image, HostID: c.HostID, HostName: c.HostName})
+		}
+	}
+	return targets, nil
+}
+
+// applyBulkCheck turns one registry check into a result item, queueing the
+// container when an update remains after ignored versions are dropped.
+func (s *Server) applyBulkCheck(t bulkCheckTarget, reg string, available bool, newer []string, resolvedCurrent, resolvedTarget string, err error) bulkCheckItem {
+	item := bulkCheckItem{Name: t.Name, HostID: t.HostID, Image: t.Image, Registry: reg}
+	switch {
+	case err != nil:
+		item.Status = "failed"
+		item.Error = err.Error()
+		return item
+	case !available:
+		item.Status = "up_to_date"
+		return item
+	}
+
+	hadNewer := len(newer) > 0
+	newer = s.filterIgnoredVersions(t.Name, newer)
+	if hadNewer && len(newer) == 0 {
+		item.Status = "ignored"
+		return item
+	}
+
+	s.deps.Queue.Add(PendingUpdate{
+		ContainerID:            t.ID,
+		ContainerName:          t.Name,
+		CurrentImage:           t.Image,
+		NewerVersions:          newer,
+		ResolvedCurrentVersion: resolvedCurrent,
+		ResolvedTargetVersion:  resolvedTarget,
+		HostID:                 t.HostID,
+		HostName:               t.HostName,
+	})
+	item.Status = "update_available"
+	item.NewerVersions = newer
+	return item
+}
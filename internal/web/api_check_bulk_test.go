@@ -0,0 +1,106 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+)
+
+// mockBulkChecker returns canned results per image and counts calls.
+type mockBulkChecker struct {
+	mu      sync.Mutex
+	calls   map[string]int
+	updates map[string][]string
+	errs    map[string]error
+}
+
+func (m *mockBulkChecker) CheckForUpdate(_ context.Context, imageRef string) (bool, []string, string, string, error) {
+	m.mu.Lock()
+	m.calls[imageRef]++
+	m.mu.Unlock()
+	if err := m.errs[imageRef]; err != nil {
+		return false, nil, "", "", err
+	}
+	newer, ok := m.updates[imageRef]
+	return ok, newer, "", "", nil
+}
+
+// recordingQueue records added updates.
+type recordingQueue struct {
+	mockUpdateQueue
+	mu    sync.Mutex
+	added []PendingUpdate
+}
+
+func (q *recordingQueue) Add(u PendingUpdate) {
+	q.mu.Lock()
+	q.added = append(q.added, u)
+	q.mu.Unlock()
+}
+
+func TestApiBulkCheck(t *testing.T) {
+	checker := &mockBulkChecker{
+		calls: map[string]int{},
+		updates: map[string][]string{
+			"nginx:1.25":         {"1.27"},
+			"ghcr.io/org/app:v1": {"v2"},
+		},
+		errs: map[string]error{"quay.io/x/broken:1": errors.New("unauthorised")},
+	}
+	queue := &recordingQueue{}
+	srv := &Server{deps: Dependencies{
+		Docker: &mockContainerLister{containers: []ContainerSummary{
+			{ID: "1", Names: []string{"/web-a"}, Image: "nginx:1.25", Labels: map[string]string{"com.docker.compose.project": "web"}},
+			{ID: "2", Names: []string{"/web-b"}, Image: "nginx:1.25", Labels: map[string]string{"com.docker.compose.project": "web"}},
+			{ID: "3", Names: []string{"/app"}, Image: "ghcr.io/org/app:v1"},
+			{ID: "4", Names: []string{"/redis"}, Image: "redis:7"},
+			{ID: "5", Names: []string{"/broken"}, Image: "quay.io/x/broken:1"},
+		}},
+		RegistryChecker: checker,
+		Queue:           queue,
+		EventBus:        events.New(),
+		Log:             slog.Default(),
+	}}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/check/bulk", nil)
+	srv.apiBulkCheck(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	var res bulkCheckResult
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Total != 5 || res.Registries != 3 || res.Updates != 3 || res.UpToDate != 1 || res.Failed != 1 {
+		t.Errorf("result = %+v", res)
+	}
+	if len(queue.added) != res.Updates {
+		t.Errorf("queued %d, reported %d updates", len(queue.added), res.Updates)
+	}
+	// Both nginx containers share one registry call.
+	if checker.calls["nginx:1.25"] != 1 {
+		t.Errorf("nginx:1.25 checked %d times, want 1", checker.calls["nginx:1.25"])
+	}
+
+	// Stack filter narrows the targets.
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodPost, "/api/check/bulk", strings.NewReader(`{"stacks":["web"]}`))
+	srv.apiBulkCheck(w, r)
+	res = bulkCheckResult{}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Total != 2 {
+		t.Errorf("stack filter: Total = %d, want 2", res.Total)
+	}
+}
@@ -0,0 +1,146 @@
+package web
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Remote container listing limits and the report age after which a host's
+// container list is flagged as stale.
+const (
+	clusterContainersDefaultPerPage = 100
+	clusterContainersMaxPerPage     = 500
+	clusterReportStaleAfter         = 15 * time.Minute
+)
+
+// clusterHostStatus describes how fresh a host's reported container list is.
+type clusterHostStatus struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Connected  bool      `json:"connected"`
+	LastReport time.Time `json:"last_report,omitempty"`
+	ReportAge  int64     `json:"report_age_seconds"` // -1 when the host never reported
+	Stale      bool      `json:"stale"`
+	Containers int       `json:"containers"` // matching containers on this host
+}
+
+// clusterContainer is a remote container plus its host's staleness marker.
+type clusterContainer struct {
+	RemoteContainer
+	Stale bool `json:"stale"`
+}
+
+// apiClusterContainers returns remote containers one page at a time.
+//
+// Query parameters:
+//   - host: host ID to include; repeat or comma-separate for several (default all)
+//   - q: case-insensitive substring match on container name or image
+//   - page: 1-based page number (default 1)
+//   - per_page: page size (default 100, max 500)
+//
+// Each host entry carries its last report time and a stale flag so the
+// dashboard can mark data from disconnected or silent agents.
+func (s *Server) apiClusterContainers(w http.ResponseWriter, r *http.Request) {
+	if s.deps.Cluster == nil || !s.deps.Cluster.Enabled() {
+		writeError(w, http.StatusServiceUnavailable, "cluster not enabled")
+		return
+	}
+
+	q := r.URL.Query()
+	page, err := queryInt(q.Get("page"), 1)
+	if err != nil || page < 1 {
+		writeError(w, http.StatusBadRequest, "page must be a positive integer")
+		return
+	}
+	perPage, err := queryInt(q.Get("per_page"), clusterContainersDefaultPerPage)
+	if err != nil || perPage < 1 {
+		writeError(w, http.StatusBadRequest, "per_page must be a positive integer")
+		return
+	}
+	perPage = min(perPage, clusterContainersMaxPerPage)
+
+	wantHosts := make(map[string]bool)
+	for _, v := range q["host"] {
+		for _, id := range strings.Split(v, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				wantHosts[id] = true
+			}
+		}
+	}
+	search := strings.ToLower(strings.TrimSpace(q.Get("q")))
+
+	connected := make(map[string]bool)
+	for _, id := range s.deps.Cluster.ConnectedHosts() {
+		connected[id] = true
+	}
+	now := time.Now()
+	statuses := make(map[string]*clusterHostStatus)
+	for _, h := range s.deps.Cluster.AllHosts() {
+		if len(wantHosts) > 0 && !wantHosts[h.ID] {
+			continue
+		}
+		st := &clusterHostStatus{
+			ID:         h.ID,
+			Name:       h.Name,
+			Connected:  connected[h.ID],
+			LastReport: h.LastReport,
+			ReportAge:  -1,
+		}
+		if !h.LastReport.IsZero() {
+			st.ReportAge = int64(now.Sub(h.LastReport).Seconds())
+		}
+		st.Stale = !st.Connected || h.LastReport.IsZero() || now.Sub(h.LastReport) > clusterReportStaleAfter
+		statuses[h.ID] = st
+	}
+
+	allowed := s.scopeFilter(r)
+	matched := []clusterContainer{}
+	for _, rc := range s.deps.Cluster.AllHostContainers() {
+		st, ok := statuses[rc.HostID]
+		if !ok || !allowed(containerKey(rc.HostID, rc.Name)) {
+			continue
+		}
+		if search != "" && !strings.Contains(strings.ToLower(rc.Name), search) &&
+			!strings.Contains(strings.ToLower(rc.Image), search) {
+			continue
+		}
+		st.Containers++
+		matched = append(matched, clusterContainer{RemoteContainer: rc, Stale: st.Stale})
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].HostName != matched[j].HostName {
+			return matched[i].HostName < matched[j].HostName
+		}
+		return matched[i].Name < matched[j].Name
+	})
+
+	hosts := make([]clusterHostStatus, 0, len(statuses))
+	for _, st := range statuses {
+		hosts = append(hosts, *st)
+	}
+	sort.Slice(hosts, func(i, j int) bool { return hosts[i].Name < hosts[j].Name })
+
+	total := len(matched)
+	start := min((page-1)*perPage, total)
+	end := min(start+perPage, total)
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"total":      total,
+		"page":       page,
+		"per_page":   perPage,
+		"pages":      (total + perPage - 1) / perPage,
+		"hosts":      hosts,
+		"containers": matched[start:end],
+	})
+}
+
+// queryInt parses an optional integer query value, returning def when empty.
+func queryInt(v string, def int) (int, error) {
+	if v == "" {
+		return def, nil
+	}
+	return strconv.Atoi(v)
+}
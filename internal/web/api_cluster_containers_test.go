@@ -0,0 +1,97 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newClusterContainersTestServer() *Server {
+	var containers []RemoteContainer
+	for i := range 5 {
+		containers = append(containers,
+			RemoteContainer{Name: fmt.Sprintf("app-%d", i), Image: "nginx:1.27", HostID: "h1", HostName: "alpha"},
+			RemoteContainer{Name: fmt.Sprintf("db-%d", i), Image: "postgres:16", HostID: "h2", HostName: "bravo"},
+		)
+	}
+	cc := NewClusterController()
+	cc.SetProvider(&mockClusterProviderWithContainers{
+		hosts: []ClusterHost{
+			{ID: "h1", Name: "alpha", LastReport: time.Now().Add(-time.Minute)},
+			{ID: "h2", Name: "bravo", LastReport: time.Now().Add(-time.Hour)},
+		},
+		connected:  []string{"h1", "h2"},
+		containers: containers,
+	})
+	return &Server{deps: Dependencies{Cluster: cc, Log: slog.Default()}}
+}
+
+type clusterContainersPage struct {
+	Total      int                 `json:"total"`
+	Pages      int                 `json:"pages"`
+	Hosts      []clusterHostStatus `json:"hosts"`
+	Containers []clusterContainer  `json:"containers"`
+}
+
+func getClusterContainers(t *testing.T, srv *Server, query string) clusterContainersPage {
+	t.Helper()
+	w := httptest.NewRecorder()
+	srv.apiClusterContainers(w, httptest.NewRequest(http.MethodGet, "/api/cluster/containers?"+query, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	var page clusterContainersPage
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+		t.Fatal(err)
+	}
+	return page
+}
+
+func TestApiClusterContainers_Pagination(t *testing.T) {
+	srv := newClusterContainersTestServer()
+
+	page := getClusterContainers(t, srv, "per_page=4&page=3")
+	if page.Total != 10 || page.Pages != 3 {
+		t.Errorf("total/pages = %d/%d, want 10/3", page.Total, page.Pages)
+	}
+	if len(page.Containers) != 2 {
+		t.Fatalf("last page has %d containers, want 2", len(page.Containers))
+	}
+	if page.Containers[1].Name != "db-4" {
+		t.Errorf("last container = %s, want db-4 (sorted by host then name)", page.Containers[1].Name)
+	}
+
+	if page := getClusterContainers(t, srv, "page=9"); len(page.Containers) != 0 {
+		t.Errorf("out-of-range page returned %d containers", len(page.Containers))
+	}
+}
+
+func TestApiClusterContainers_HostFilterAndStale(t *testing.T) {
+	srv := newClusterContainersTestServer()
+
+	page := getClusterContainers(t, srv, "host=h2")
+	if page.Total != 5 || len(page.Hosts) != 1 {
+		t.Fatalf("total = %d, hosts = %d; want 5, 1", page.Total, len(page.Hosts))
+	}
+	if !page.Hosts[0].Stale || !page.Containers[0].Stale {
+		t.Error("bravo reported an hour ago and should be stale")
+	}
+
+	page = getClusterContainers(t, srv, "host=h1&q=APP-1")
+	if page.Total != 1 || page.Containers[0].Stale {
+		t.Errorf("filtered page = %+v", page)
+	}
+}
+
+func TestApiClusterContainers_BadPage(t *testing.T) {
+	srv := newClusterContainersTestServer()
+	w := httptest.NewRecorder()
+	srv.apiClusterContainers(w, httptest.NewRequest(http.MethodGet, "/api/cluster/containers?page=0", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", w.Code)
+	}
+}
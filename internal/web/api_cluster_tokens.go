@@ -0,0 +1,126 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/auth"
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+)
+
+// Enrollment token lifetime bounds. The default applies when neither the
+// request nor the cluster_enroll_ttl setting picks one.
+const (
+	defaultEnrollTTL = 24 * time.Hour
+	minEnrollTTL     = 5 * time.Minute
+	maxEnrollTTL     = 30 * 24 * time.Hour
+)
+
+// enrollTTLChoices are the lifetimes offered for the cluster_enroll_ttl
+// setting.
+var enrollTTLChoices = map[string]bool{"1h": true, "6h": true, "24h": true, "168h": true, "720h": true}
+
+// defaultEnrollTokenTTL returns the configured default token lifetime.
+func (s *Server) defaultEnrollTokenTTL() time.Duration {
+	if s.deps.SettingsStore == nil {
+		return defaultEnrollTTL
+	}
+	v, err := s.deps.SettingsStore.LoadSetting(store.SettingClusterEnrollTTL)
+	if err != nil || v == "" {
+		return defaultEnrollTTL
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < minEnrollTTL || d > maxEnrollTTL {
+		return defaultEnrollTTL
+	}
+	return d
+}
+
+// handleGenerateEnrollToken creates a one-time enrollment token. The body
+// may set {"ttl": "1h"}; otherwise the configured default applies.
+func (s *Server) handleGenerateEnrollToken(w http.ResponseWriter, r *http.Request) {
+	if !s.deps.Cluster.Enabled() {
+		writeError(w, http.StatusServiceUnavailable, "cluster not enabled")
+		return
+	}
+	var req struct {
+		TTL string `json:"ttl"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	ttl := s.defaultEnrollTokenTTL()
+	if req.TTL != "" {
+		d, err := time.ParseDuration(req.TTL)
+		if err != nil || d < minEnrollTTL || d > maxEnrollTTL {
+			writeError(w, http.StatusBadRequest, "ttl must be a duration between 5m and 720h")
+			return
+		}
+		ttl = d
+	}
+
+	var createdBy string
+	if rc := auth.GetRequestContext(r.Context()); rc != nil && rc.User != nil {
+		createdBy = rc.User.Username
+	}
+	token, id, err := s.deps.Cluster.GenerateEnrollToken(ttl, createdBy)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	expiresAt := time.Now().Add(ttl)
+	s.logEvent(r, "cluster", "", "Enrollment token "+id+" generated, expires "+expiresAt.UTC().Format(time.RFC3339))
+	writeJSON(w, http.StatusOK, map[string]string{
+		"token":      token,
+		"id":         id,
+		"expires_at": expiresAt.Format(time.RFC3339),
+	})
+}
+
+// handleListEnrollTokens lists enrollment tokens with their creator, expiry
+// and, once consumed, the host that used them.
+func (s *Server) handleListEnrollTokens(w http.ResponseWriter, _ *http.Request) {
+	if !s.deps.Cluster.Enabled() {
+		writeError(w, http.StatusServiceUnavailable, "cluster not enabled")
+		return
+	}
+	tokens, err := s.deps.Cluster.ListEnrollTokens()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	now := time.Now()
+	for i := range tokens {
+		tokens[i].Expired = !tokens[i].Used && now.After(tokens[i].ExpiresAt)
+	}
+	if tokens == nil {
+		tokens = []EnrollToken{}
+	}
+	writeJSON(w, http.StatusOK, tokens)
+}
+
+// handleRevokeEnrollToken deletes an unused enrollment token.
+func (s *Server) handleRevokeEnrollToken(w http.ResponseWriter, r *http.Request) {
+	if !s.deps.Cluster.Enabled() {
+		writeError(w, http.StatusServiceUnavailable, "cluster not enabled")
+		return
+	}
+	id := r.PathValue("id")
+	if err := s.deps.Cluster.RevokeEnrollToken(id); err != nil {
+		switch {
+		case errors.Is(err, ErrEnrollTokenNotFound):
+			writeError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, ErrEnrollTokenUsed):
+			writeError(w, http.StatusConflict, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	s.logEvent(r, "cluster", "", "Enrollment token "+id+" revoked")
+	writeJSON(w, http.StatusOK, map[string]string{"status": "revoked"})
+}
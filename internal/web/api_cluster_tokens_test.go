@@ -0,0 +1,147 @@
+package web
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/auth"
+)
+
+func newEnrollTokenTestServer(p *mockClusterProvider, ss SettingsStore) *Server {
+	cc := NewClusterController()
+	cc.SetProvider(p)
+	return &Server{deps: Dependencies{Cluster: cc, SettingsStore: ss, Log: slog.Default()}}
+}
+
+func TestGenerateEnrollToken_TTL(t *testing.T) {
+	ms := newMockSettingsStore()
+	p := &mockClusterProvider{}
+	srv := newEnrollTokenTestServer(p, ms)
+
+	cases := []struct {
+		name    string
+		setting string
+		body    string
+		want    time.Duration
+		status  int
+	}{
+		{"default", "", "", 24 * time.Hour, http.StatusOK},
+		{"setting", "168h", "", 168 * time.Hour, http.StatusOK},
+		{"request overrides setting", "168h", `{"ttl":"1h"}`, time.Hour, http.StatusOK},
+		{"too short", "", `{"ttl":"1m"}`, 0, http.StatusBadRequest},
+		{"too long", "", `{"ttl":"2000h"}`, 0, http.StatusBadRequest},
+		{"not a duration", "", `{"ttl":"soon"}`, 0, http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ms.data["cluster_enroll_ttl"] = tc.setting
+			p.genTTL, p.genBy = 0, ""
+			r := httptest.NewRequest(http.MethodPost, "/api/cluster/enroll-token", strings.NewReader(tc.body))
+			r = reqWithAuthContext(r, &auth.User{Username: "admin"})
+			w := httptest.NewRecorder()
+			srv.handleGenerateEnrollToken(w, r)
+
+			if w.Code != tc.status {
+				t.Fatalf("status = %d, want %d; body: %s", w.Code, tc.status, w.Body.String())
+			}
+			if tc.status != http.StatusOK {
+				return
+			}
+			if p.genTTL != tc.want || p.genBy != "admin" {
+				t.Errorf("GenerateEnrollToken(%v, %q), want (%v, admin)", p.genTTL, p.genBy, tc.want)
+			}
+		})
+	}
+}
+
+func TestListEnrollTokens_MarksExpired(t *testing.T) {
+	now := time.Now()
+	srv := newEnrollTokenTestServer(&mockClusterProvider{tokens: []EnrollToken{
+		{ID: "active", ExpiresAt: now.Add(time.Hour)},
+		{ID: "stale", ExpiresAt: now.Add(-time.Hour)},
+		{ID: "used", ExpiresAt: now.Add(-time.Hour), Used: true, UsedByName: "web-01"},
+	}}, nil)
+
+	w := httptest.NewRecorder()
+	srv.handleListEnrollTokens(w, httptest.NewRequest(http.MethodGet, "/api/cluster/enroll-tokens", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	var got []EnrollToken
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	expired := map[string]bool{}
+	for _, tok := range got {
+		expired[tok.ID] = tok.Expired
+	}
+	if expired["active"] || !expired["stale"] || expired["used"] {
+		t.Errorf("expired flags = %v, want only stale", expired)
+	}
+}
+
+func TestRevokeEnrollToken_Errors(t *testing.T) {
+	cases := []struct {
+		err    error
+		status int
+	}{
+		{nil, http.StatusOK},
+		{ErrEnrollTokenNotFound, http.StatusNotFound},
+		{ErrEnrollTokenUsed, http.StatusConflict},
+	}
+	for _, tc := range cases {
+		srv := newEnrollTokenTestServer(&mockClusterProvider{revokeErr: tc.err}, nil)
+		r := httptest.NewRequest(http.MethodDelete, "/api/cluster/enroll-tokens/abcd1234", nil)
+		r.SetPathValue("id", "abcd1234")
+		w := httptest.NewRecorder()
+		srv.handleRevokeEnrollToken(w, r)
+		if w.Code != tc.status {
+			t.Errorf("revoke with %v: status = %d, want %d", tc.err, w.Code, tc.status)
+		}
+	}
+}
+
+func TestClusterHostEvents(t *testing.T) {
+	p := &mockClusterProvider{
+		hosts:  []ClusterHost{{ID: "h1", Name: "edge"}},
+		events: []ClusterHostEvent{{Type: "connected", Message: "agent connected"}},
+	}
+	srv := newEnrollTokenTestServer(p, nil)
+
+	cases := []struct {
+		url       string
+		status    int
+		wantLimit int
+	}{
+		{"/api/cluster/hosts/h1/events", http.StatusOK, 100},
+		{"/api/cluster/hosts/h1/events?limit=20", http.StatusOK, 20},
+		{"/api/cluster/hosts/h1/events?limit=9999", http.StatusOK, 100},
+		{"/api/cluster/hosts/missing/events", http.StatusNotFound, 0},
+	}
+	for _, tc := range cases {
+		p.eventsMax = 0
+		r := httptest.NewRequest(http.MethodGet, tc.url, nil)
+		r.SetPathValue("id", strings.Split(strings.TrimPrefix(tc.url, "/api/cluster/hosts/"), "/")[0])
+		w := httptest.NewRecorder()
+		srv.handleClusterHostEvents(w, r)
+
+		if w.Code != tc.status {
+			t.Fatalf("%s: status = %d, want %d", tc.url, w.Code, tc.status)
+		}
+		if tc.status != http.StatusOK {
+			continue
+		}
+		if p.eventsID != "h1" || p.eventsMax != tc.wantLimit {
+			t.Errorf("%s: HostEvents(%q, %d), want (h1, %d)", tc.url, p.eventsID, p.eventsMax, tc.wantLimit)
+		}
+		var got []ClusterHostEvent
+		if err := json.NewDecoder(w.Body).Decode(&got); err != nil || len(got) != 1 || got[0].Type != "connected" {
+			t.Errorf("%s: body = %v (err %v)", tc.url, got, err)
+		}
+	}
+}
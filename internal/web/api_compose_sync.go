@@ -0,0 +1,170 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/engine"
+)
+
+// apiPreviewComposeSync shows the diff compose sync would apply to a
+// container's compose file, without writing it.
+// Query: ?image=repo:tag (default: the running image).
+func (s *Server) apiPreviewComposeSync(w http.ResponseWriter, r *http.Request) {
+	s.composeSync(w, r, r.URL.Query().Get("image"), true)
+}
+
+// apiComposeSync writes an image tag into a container's compose file.
+// Body: {"image": "repo:tag", "dry_run": false}; image defaults to the
+// running image, so this also repairs a file that has drifted.
+func (s *Server) apiComposeSync(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Image  string `json:"image"`
+		DryRun bool   `json:"dry_run"`
+	}
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON body")
+			return
+		}
+	}
+	s.composeSync(w, r, body.Image, body.DryRun)
+}
+
+func (s *Server) composeSync(w http.ResponseWriter, r *http.Request, image string, dryRun bool) {
+	name := r.PathValue("name")
+	if !isValidContainerName(name) {
+		writeError(w, http.StatusBadRequest, "invalid container name")
+		return
+	}
+	if image != "" && !validComposeImage(image) {
+		writeError(w, http.StatusBadRequest, "invalid image reference, want repo:tag")
+		return
+	}
+	if s.deps.ComposeSync == nil {
+		writeError(w, http.StatusNotImplemented, "compose sync not available")
+		return
+	}
+
+	var edit engine.ComposeEdit
+	var err error
+	if dryRun {
+		edit, err = s.deps.ComposeSync.PreviewComposeSync(r.Context(), name, image)
+	} else {
+		edit, err = s.deps.ComposeSync.SyncComposeFile(r.Context(), name, image)
+	}
+	switch {
+	case errors.Is(err, engine.ErrContainerNotFound):
+		writeError(w, http.StatusNotFound, "container not found: "+name)
+		return
+	case errors.Is(err, engine.ErrNotCompose):
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, os.ErrNotExist):
+		writeError(w, http.StatusNotFound, "compose file not readable from Sentinel; set the stack's mounted path: "+err.Error())
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, "compose sync failed: "+err.Error())
+		return
+	}
+
+	if !dryRun && edit.Changed() {
+		s.logEvent(r, "compose-sync", name, "Compose file updated: "+edit.Path)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"name":    name,
+		"dry_run": dryRun,
+		"changed": edit.Changed(),
+		"edit":    edit,
+	})
+}
+
+// validComposeImage reports whether image is a repo:tag reference safe to
+// write into a compose file: image reference characters only, and a tag
+// after the last colon (an @digest suffix is allowed).
+func validComposeImage(image string) bool {
+	if len(image) > 256 || !isValidImageRef(image) {
+		return false
+	}
+	ref, _, _ := strings.Cut(image, "@")
+	colon := strings.LastIndex(ref, ":")
+	if colon <= strings.LastIndex(ref, "/") || colon == 0 {
+		return false
+	}
+	return validTag.MatchString(ref[colon+1:])
+}
+
+// apiListComposePaths returns the per-stack compose file mount paths.
+func (s *Server) apiListComposePaths(w http.ResponseWriter, _ *http.Request) {
+	if s.deps.ComposePaths == nil {
+		writeJSON(w, http.StatusOK, map[string]string{})
+		return
+	}
+	paths, err := s.deps.ComposePaths.ListComposePaths()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to load compose paths")
+		return
+	}
+	writeJSON(w, http.StatusOK, paths)
+}
+
+// apiSetComposePath records where a stack's compose file (or its directory)
+// is mounted inside Sentinel's container.
+// Body: {"project": "shop", "path": "/stacks/shop"}
+func (s *Server) apiSetComposePath(w http.ResponseWriter, r *http.Request) {
+	if s.deps.ComposePaths == nil {
+		writeError(w, http.StatusNotImplemented, "compose paths not available")
+		return
+	}
+	var body struct {
+		Project string `json:"project"`
+		Path    string `json:"path"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if !isValidContainerName(body.Project) {
+		writeError(w, http.StatusBadRequest, "invalid project name")
+		return
+	}
+	if !filepath.IsAbs(body.Path) {
+		writeError(w, http.StatusBadRequest, "path must be absolute")
+		return
+	}
+	path := filepath.Clean(body.Path)
+	if _, err := os.Stat(path); err != nil {
+		writeError(w, http.StatusBadRequest, "path not found inside Sentinel's container: "+path)
+		return
+	}
+	if err := s.deps.ComposePaths.SetComposePath(body.Project, path); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to save compose path")
+		return
+	}
+	s.logEvent(r, "settings", "", "Compose path for "+body.Project+" set to "+path)
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok", "project": body.Project, "path": path})
+}
+
+// apiDeleteComposePath removes a stack's compose path, falling back to the
+// host path from the Compose labels.
+func (s *Server) apiDeleteComposePath(w http.ResponseWriter, r *http.Request) {
+	project := r.PathValue("project")
+	if !isValidContainerName(project) {
+		writeError(w, http.StatusBadRequest, "invalid project name")
+		return
+	}
+	if s.deps.ComposePaths == nil {
+		writeError(w, http.StatusNotImplemented, "compose paths not available")
+		return
+	}
+	if err := s.deps.ComposePaths.DeleteComposePath(project); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to delete compose path")
+		return
+	}
+	s.logEvent(r, "settings", "", "Compose path for "+project+" removed")
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
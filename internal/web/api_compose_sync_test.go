@@ -0,0 +1,209 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/engine"
+	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+)
+
+type mockComposeSyncer struct {
+	written []string
+}
+
+func (m *mockComposeSyncer) edit(name, image string) (engine.ComposeEdit, error) {
+	switch name {
+	case "missing":
+		return engine.ComposeEdit{}, fmt.Errorf("%s: %w", name, engine.ErrContainerNotFound)
+	case "plain":
+		return engine.ComposeEdit{}, engine.ErrNotCompose
+	}
+	return engine.ComposeEdit{Path: "/stacks/shop/compose.yml", Service: "app", Line: 3, Diff: "-    image: app:1\n+    image: " + image + "\n"}, nil
+}
+
+func (m *mockComposeSyncer) PreviewComposeSync(_ context.Context, name, image string) (engine.ComposeEdit, error) {
+	return m.edit(name, image)
+}
+
+func (m *mockComposeSyncer) SyncComposeFile(_ context.Context, name, image string) (engine.ComposeEdit, error) {
+	edit, err := m.edit(name, image)
+	if err == nil {
+		m.written = append(m.written, name)
+	}
+	return edit, err
+}
+
+func newComposeSyncTestServer(syncer ComposeSyncer) *Server {
+	return &Server{
+		deps: Dependencies{
+			ComposeSync: syncer,
+			EventBus:    events.New(),
+			Log:         slog.New(slog.NewTextHandler(io.Discard, nil)),
+		},
+	}
+}
+
+func TestApiPreviewComposeSync(t *testing.T) {
+	syncer := &mockComposeSyncer{}
+	srv := newComposeSyncTestServer(syncer)
+
+	tests := []struct {
+		name string
+		want int
+	}{
+		{"app", http.StatusOK},
+		{"missing", http.StatusNotFound},
+		{"plain", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/containers/"+tt.name+"/compose-sync?image=app:2", nil)
+		r.SetPathValue("name", tt.name)
+		srv.apiPreviewComposeSync(w, r)
+		if w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d (%s)", tt.name, w.Code, tt.want, w.Body.String())
+		}
+	}
+	if len(syncer.written) != 0 {
+		t.Errorf("preview wrote files: %v", syncer.written)
+	}
+}
+
+func TestApiComposeSync(t *testing.T) {
+	syncer := &mockComposeSyncer{}
+	srv := newComposeSyncTestServer(syncer)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/containers/app/compose-sync", strings.NewReader(body))
+		r.SetPathValue("name", "app")
+		srv.apiComposeSync(w, r)
+		return w
+	}
+
+	w := post(`{"image":"app:2","dry_run":true}`)
+	if w.Code != http.StatusOK || len(syncer.written) != 0 {
+		t.Fatalf("dry run: status = %d, written = %v", w.Code, syncer.written)
+	}
+
+	w = post(`{"image":"app:2"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Changed bool               `json:"changed"`
+		Edit    engine.ComposeEdit `json:"edit"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Changed || !strings.Contains(resp.Edit.Diff, "+    image: app:2") {
+		t.Errorf("response = %+v", resp)
+	}
+	if len(syncer.written) != 1 {
+		t.Errorf("written = %v, want one write", syncer.written)
+	}
+}
+
+func TestApiComposeSyncRejectsInvalidImage(t *testing.T) {
+	syncer := &mockComposeSyncer{}
+	srv := newComposeSyncTestServer(syncer)
+
+	for _, image := range []string{
+		`nginx: [\"sh\",\"-c\",\"id\"] #`,
+		`nginx:1.25\n    command: id`,
+		"nginx:1.25 #",
+		"nginx",
+		"nginx:",
+		":1.25",
+	} {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/containers/app/compose-sync", strings.NewReader(`{"image":"`+image+`"}`))
+		r.SetPathValue("name", "app")
+		srv.apiComposeSync(w, r)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("image %s: status = %d, want 400", image, w.Code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api/containers/app/compose-sync?image="+url.QueryEscape(`nginx: ["sh"] #`), nil)
+	r.SetPathValue("name", "app")
+	srv.apiPreviewComposeSync(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("preview: status = %d, want 400", w.Code)
+	}
+	if len(syncer.written) != 0 {
+		t.Errorf("written = %v, want none", syncer.written)
+	}
+
+	// Registry ports and digests are fine.
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodPost, "/api/containers/app/compose-sync", strings.NewReader(`{"image":"registry.local:5000/app:2@sha256:abc"}`))
+	r.SetPathValue("name", "app")
+	srv.apiComposeSync(w, r)
+	if w.Code != http.StatusOK {
+		t.Errorf("valid image: status = %d, body = %s", w.Code, w.Body.String())
+	}
+}
+
+func TestApiComposeSyncUnavailable(t *testing.T) {
+	srv := newComposeSyncTestServer(nil)
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api/containers/app/compose-sync", nil)
+	r.SetPathValue("name", "app")
+	srv.apiPreviewComposeSync(w, r)
+	if w.Code != http.StatusNotImplemented {
+		t.Errorf("status = %d, want 501", w.Code)
+	}
+}
+
+func TestApiSetComposePathValidation(t *testing.T) {
+	srv := &Server{deps: Dependencies{
+		ComposePaths: &mockComposePaths{paths: map[string]string{}},
+		EventBus:     events.New(),
+		Log:          slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}}
+	dir := t.TempDir()
+
+	tests := []struct {
+		body string
+		want int
+	}{
+		{`{"project":"shop","path":"` + dir + `"}`, http.StatusOK},
+		{`{"project":"shop","path":"relative/dir"}`, http.StatusBadRequest},
+		{`{"project":"shop","path":"/does/not/exist"}`, http.StatusBadRequest},
+		{`{"project":"../shop","path":"` + dir + `"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/compose/paths", strings.NewReader(tt.body))
+		srv.apiSetComposePath(w, r)
+		if w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.body, w.Code, tt.want)
+		}
+	}
+}
+
+type mockComposePaths struct {
+	paths map[string]string
+}
+
+func (m *mockComposePaths) ListComposePaths() (map[string]string, error) { return m.paths, nil }
+func (m *mockComposePaths) SetComposePath(project, path string) error {
+	m.paths[project] = path
+	return nil
+}
+func (m *mockComposePaths) DeleteComposePath(project string) error {
+	delete(m.paths, project)
+	return nil
+}
@@ -11,24 +11,37 @@ import (
 )
 
 // ConfigExport is the top-level structure for a full configuration export.
+// Version 2 added policy overrides, hooks and passphrase encryption.
 type ConfigExport struct {
 	Version       string               `json:"version"`
 	ExportedAt    string               `json:"exported_at"`
+	Encryption    *ConfigEncryption    `json:"encryption,omitempty"`
 	Settings      map[string]string    `json:"settings"`
+	Policies      map[string]string    `json:"policies"`
 	Notifications []notify.Channel     `json:"notifications"`
 	Registries    []RegistryCredential `json:"registries"`
+	Hooks         []HookEntry          `json:"hooks"`
 }
 
 // ConfigImportResult summarises what was imported.
 type ConfigImportResult struct {
 	Message       string   `json:"message"`
 	Settings      int      `json:"settings_imported"`
+	Policies      int      `json:"policies_imported"`
 	Notifications int      `json:"notifications_imported"`
 	Registries    int      `json:"registries_imported"`
+	Hooks         int      `json:"hooks_imported"`
 	Skipped       int      `json:"redacted_skipped"`
 	Warnings      []string `json:"warnings,omitempty"`
 }
 
+// configExportVersion is written to new exports; older versions still import.
+const configExportVersion = "2"
+
+// configPassphraseHeader carries the passphrase that encrypts secrets on
+// export and decrypts them on import. A header keeps it out of access logs.
+const configPassphraseHeader = "X-Config-Passphrase"
+
 // redactedPlaceholder is the value used to mask secrets in exports.
 const redactedPlaceholder = "***REDACTED***"
 
@@ -36,10 +49,14 @@ const redactedPlaceholder = "***REDACTED***"
 // Using a whitelist instead of substring matching avoids false positives
 // (e.g. docker_tls_key is a file path, not a secret).
 var sensitiveKeys = map[string]bool{
-	"webhook_secret":        true,
-	"notification_config":   true, // contains provider credentials
-	"notification_channels": true, // channel settings may contain tokens
-	"oidc_client_secret":    true,
+	"webhook_secret":         true,
+	"registry_webhook_token": true,
+	"notification_config":    true, // contains provider credentials
+	"notification_channels":  true, // channel settings may contain tokens
+	"oidc_client_secret":     true,
+	"ticketing":              true, // contains the ticketing API token
+	"rebuild_webhook_url":    true, // CI trigger URLs often embed a token
+	"validation_webhook_url": true, // as above
 }
 
 // validSettingKeys is an allowlist of all setting keys that may be stored.
@@ -50,25 +67,44 @@ var validSettingKeys = map[string]bool{
 	"poll_interval":    true,
 	"schedule":         true,
 	"grace_period":     true,
+	"health_timeout":   true,
 	"paused":           true,
 	"scan_concurrency": true,
 	"filters":          true,
+	"image_rewrites":   true,
 	"update_delay":     true,
 
 	// Update behaviour.
-	"default_policy":     true,
-	"latest_auto_update": true,
-	"image_cleanup":      true,
-	"image_backup":       true,
-	"remove_volumes":     true,
-	"dry_run":            true,
-	"pull_only":          true,
-	"rollback_policy":    true,
-	"version_scope":      true,
-	"dependency_aware":   true,
-	"compose_sync":       true,
-	"maintenance_window": true,
-	"show_stopped":       true,
+	"default_policy":         true,
+	"latest_auto_update":     true,
+	"image_cleanup":          true,
+	"image_backup":           true,
+	"remove_volumes":         true,
+	"dry_run":                true,
+	"pull_only":              true,
+	"max_auto_updates":       true,
+	"auto_update_overflow":   true,
+	"rollback_policy":        true,
+	"version_scope":          true,
+	"dependency_aware":       true,
+	"compose_sync":           true,
+	"config_diff":            true,
+	"rebuild_watch":          true,
+	"rebuild_webhook_url":    true,
+	"validation_webhook_url": true,
+	"validation_command":     true,
+	"pull_check_registry":    true,
+	"pull_check_disk_path":   true,
+	"bluegreen_port_range":   true,
+	"maintenance_window":     true,
+	"retry_max_attempts":     true,
+	"retry_backoff":          true,
+	"rollout_groups":         true,
+	"stuck_update_threshold": true,
+	"audit_retention":        true,
+	"show_stopped":           true,
+	"container_discovery":    true,
+	"discovery_check":        true,
 
 	// Hooks.
 	"hooks_enabled":      true,
@@ -81,10 +117,36 @@ var validSettingKeys = map[string]bool{
 	"digest_time":           true,
 	"digest_interval":       true,
 	"default_notify_mode":   true,
+	"queue_stale_after":     true,
+	"report_enabled":        true,
+	"report_day":            true,
+	"report_time":           true,
+	"external_url":          true,
 
 	// Webhook.
-	"webhook_enabled": true,
-	"webhook_secret":  true,
+	"webhook_enabled":        true,
+	"webhook_secret":         true,
+	"registry_webhook_token": true,
+
+	// Machine event sink.
+	"event_sink_url":    true,
+	"event_sink_format": true,
+
+	// Ticketing.
+	"ticketing": true,
+
+	// Database backups.
+	"backup_schedule":  true,
+	"backup_dir":       true,
+	"backup_retention": true,
+
+	// History, log and snapshot retention.
+	"retention_history_days":   true,
+	"retention_history_max":    true,
+	"retention_logs_days":      true,
+	"retention_logs_max":       true,
+	"retention_snapshots_days": true,
+	"retention_snapshots_max":  true,
 
 	// Docker TLS.
 	"docker_tls_ca":   true,
@@ -97,6 +159,7 @@ var validSettingKeys = map[string]bool{
 	"cluster_grace_period":       true,
 	"cluster_remote_policy":      true,
 	"cluster_auto_update_agents": true,
+	"cluster_enroll_ttl":         true,
 
 	// Instance.
 	"instance_role":       true,
@@ -112,6 +175,10 @@ var validSettingKeys = map[string]bool{
 	"oidc_auto_create":   true,
 	"oidc_default_role":  true,
 
+	"oidc_post_logout_redirect_url": true,
+	"oidc_backchannel_logout":       true,
+	"oidc_revalidate_interval":      true,
+
 	// Agent/server.
 	"server_addr":  true,
 	"enroll_token": true,
@@ -138,12 +205,22 @@ func isSensitiveSetting(key string) bool {
 }
 
 // apiConfigExport builds a full configuration backup and sends it as a
-// downloadable JSON file.
+// downloadable JSON file, or YAML with ?format=yaml. Secrets are redacted
+// unless ?secrets=true; with a passphrase they are included encrypted.
 func (s *Server) apiConfigExport(w http.ResponseWriter, r *http.Request) {
-	includeSecrets := r.URL.Query().Get("secrets") == "true"
+	if !unscopedOnly(w, r) {
+		return
+	}
+	passphrase := r.Header.Get(configPassphraseHeader)
+	includeSecrets := r.URL.Query().Get("secrets") == "true" || passphrase != ""
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "yaml" {
+		writeError(w, http.StatusBadRequest, "format must be json or yaml")
+		return
+	}
 
 	export := ConfigExport{
-		Version:    "1",
+		Version:    configExportVersion,
 		ExportedAt: time.Now().UTC().Format(time.RFC3339),
 	}
 
@@ -168,6 +245,14 @@ func (s *Server) apiConfigExport(w http.ResponseWriter, r *http.Request) {
 		export.Settings = make(map[string]string)
 	}
 
+	// --- Policy overrides ---
+	if s.deps.Policy != nil {
+		export.Policies = s.deps.Policy.AllPolicyOverrides()
+	}
+	if export.Policies == nil {
+		export.Policies = make(map[string]string)
+	}
+
 	// --- Notification channels ---
 	if s.deps.NotifyConfig != nil {
 		channels, err := s.deps.NotifyConfig.GetNotificationChannels()
@@ -214,22 +299,60 @@ func (s *Server) apiConfigExport(w http.ResponseWriter, r *http.Request) {
 		export.Registries = []RegistryCredential{}
 	}
 
-	// Marshal with indentation for readability.
-	data, err := json.MarshalIndent(export, "", "  ")
+	// --- Hooks ---
+	if s.deps.HookStore != nil {
+		hooks, err := s.deps.HookStore.ListAllHooks()
+		if err != nil {
+			s.deps.Log.Error("config export: failed to load hooks", "error", err)
+			writeError(w, http.StatusInternalServerError, "failed to load hooks")
+			return
+		}
+		export.Hooks = hooks
+	}
+	if export.Hooks == nil {
+		export.Hooks = []HookEntry{}
+	}
+
+	if passphrase != "" {
+		if err := encryptExportSecrets(&export, passphrase); err != nil {
+			s.deps.Log.Error("config export: failed to encrypt secrets", "error", err)
+			writeError(w, http.StatusInternalServerError, "failed to encrypt secrets")
+			return
+		}
+	}
+
+	var (
+		data        []byte
+		err         error
+		ext         = "json"
+		contentType = "application/json; charset=utf-8"
+	)
+	if format == "yaml" {
+		data, err = marshalConfigYAML(export)
+		ext, contentType = "yaml", "application/yaml; charset=utf-8"
+	} else {
+		// Marshal with indentation for readability.
+		data, err = json.MarshalIndent(export, "", "  ")
+	}
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "failed to serialise config")
 		return
 	}
 
-	filename := fmt.Sprintf("sentinel-config-%s.json", time.Now().UTC().Format("20060102"))
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	filename := fmt.Sprintf("sentinel-config-%s.%s", time.Now().UTC().Format("20060102"), ext)
+	w.Header().Set("Content-Type", contentType)
 	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
 	w.WriteHeader(http.StatusOK)
 	_, _ = w.Write(data)
 }
 
-// apiConfigImport reads a JSON config backup and merges it into the current state.
+// apiConfigImport reads a JSON or YAML config backup and merges it into the
+// current state. Encrypted secrets need the export passphrase; without it
+// they are skipped like redacted ones.
 func (s *Server) apiConfigImport(w http.ResponseWriter, r *http.Request) {
+	if !unscopedOnly(w, r) {
+		return
+	}
 	// Limit upload to 5 MB.
 	r.Body = http.MaxBytesReader(w, r.Body, 5<<20)
 
@@ -239,9 +362,9 @@ func (s *Server) apiConfigImport(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var imported ConfigExport
-	if err := json.Unmarshal(body, &imported); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+	imported, err := unmarshalConfig(body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
@@ -250,17 +373,50 @@ func (s *Server) apiConfigImport(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "missing 'version' field — not a valid Sentinel config export")
 		return
 	}
-	if imported.Version != "1" {
-		writeError(w, http.StatusBadRequest, "unsupported config version: "+imported.Version+" (this instance supports version 1)")
+	if imported.Version != "1" && imported.Version != configExportVersion {
+		writeError(w, http.StatusBadRequest, "unsupported config version: "+imported.Version+" (this instance supports versions 1 and 2)")
 		return
 	}
 
 	result := ConfigImportResult{}
+	var changes auditChanges
+
+	var box *secretBox
+	if imported.Encryption != nil {
+		passphrase := r.Header.Get(configPassphraseHeader)
+		if passphrase == "" {
+			result.Warnings = append(result.Warnings, "export has encrypted secrets; they were skipped because no passphrase was given")
+		} else {
+			box, err = openImportEncryption(passphrase, imported.Encryption)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "cannot decrypt secrets: "+err.Error())
+				return
+			}
+		}
+	}
+	// reveal returns the plain form of an imported value, or false when it
+	// is redacted or encrypted without a passphrase and must be skipped.
+	reveal := func(v string) (string, bool) {
+		if v == redactedPlaceholder {
+			return "", false
+		}
+		if !isEncrypted(v) {
+			return v, true
+		}
+		if box == nil {
+			return "", false
+		}
+		plain, err := box.open(v)
+		return plain, err == nil
+	}
 
 	// --- Settings ---
 	if s.deps.SettingsStore != nil && len(imported.Settings) > 0 {
+		applied := make(map[string]string, len(imported.Settings))
+		current, _ := s.deps.SettingsStore.GetAllSettings()
 		for k, v := range imported.Settings {
-			if v == redactedPlaceholder {
+			v, ok := reveal(v)
+			if !ok {
 				result.Skipped++
 				continue
 			}
@@ -268,41 +424,75 @@ func (s *Server) apiConfigImport(w http.ResponseWriter, r *http.Request) {
 				result.Warnings = append(result.Warnings, "unknown setting key rejected: "+k)
 				continue
 			}
+			if err := validateSetting(k, v); err != nil {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("setting %s rejected: %v", k, err))
+				continue
+			}
 			if err := s.deps.SettingsStore.SaveSetting(k, v); err != nil {
 				s.deps.Log.Warn("config import: failed to save setting", "key", k, "error", err)
 				continue
 			}
+			applied[k] = v
 			result.Settings++
+			if old, ok := current[k]; !ok || old != v {
+				changes.entry("settings", k, auditSetting(k, old, ok), auditSetting(k, v, true))
+			}
 		}
 
 		// Apply key in-memory settings so changes take effect immediately.
-		s.applyImportedSettings(imported.Settings)
+		s.applyImportedSettings(applied)
+	}
+
+	// --- Policy overrides ---
+	if s.deps.Policy != nil && len(imported.Policies) > 0 {
+		for name, policy := range imported.Policies {
+			switch policy {
+			case "auto", "manual", "pinned":
+			default:
+				result.Warnings = append(result.Warnings, fmt.Sprintf("invalid policy %q for %s rejected", policy, name))
+				continue
+			}
+			if name == "" {
+				continue
+			}
+			old, hadOld := s.deps.Policy.GetPolicyOverride(name)
+			if err := s.deps.Policy.SetPolicyOverride(name, policy); err != nil {
+				s.deps.Log.Warn("config import: failed to save policy override", "container", name, "error", err)
+				continue
+			}
+			result.Policies++
+			if !hadOld || old != policy {
+				changes.entry("policies", name, auditOptional(old, hadOld), policy)
+			}
+		}
 	}
 
 	// --- Notification channels ---
 	if s.deps.NotifyConfig != nil && len(imported.Notifications) > 0 {
-		if err := s.deps.NotifyConfig.SetNotificationChannels(imported.Notifications); err != nil {
-			s.deps.Log.Error("config import: failed to save notification channels", "error", err)
-			result.Warnings = append(result.Warnings, "failed to import notification channels: "+err.Error())
-		} else {
-			result.Notifications = len(imported.Notifications)
-
-			// Rebuild the live notification chain (same pattern as apiSaveNotifications).
-			if s.deps.NotifyReconfigurer != nil {
-				var notifiers []notify.Notifier
-				notifiers = append(notifiers, notify.NewLogNotifier(s.deps.Log))
-				for _, ch := range imported.Notifications {
-					if !ch.Enabled {
-						continue
-					}
-					n, buildErr := notify.BuildFilteredNotifier(ch)
-					if buildErr != nil {
-						s.deps.Log.Warn("config import: failed to build notifier", "channel", ch.Name, "error", buildErr)
-						continue
-					}
-					notifiers = append(notifiers, n)
+		channels := make([]notify.Channel, 0, len(imported.Notifications))
+		for _, ch := range imported.Notifications {
+			if sealed := encryptedSettings(ch.Settings); sealed != "" {
+				plain, ok := reveal(sealed)
+				if !ok {
+					result.Skipped++
+					continue
 				}
-				s.deps.NotifyReconfigurer.Reconfigure(notifiers...)
+				ch.Settings = json.RawMessage(plain)
+			}
+			channels = append(channels, ch)
+		}
+		// Channels are replaced as a set, so keep the current ones when every
+		// imported channel had to be skipped.
+		if len(channels) > 0 {
+			previous, _ := s.deps.NotifyConfig.GetNotificationChannels()
+			if err := s.deps.NotifyConfig.SetNotificationChannels(channels); err != nil {
+				s.deps.Log.Error("config import: failed to save notification channels", "error", err)
+				result.Warnings = append(result.Warnings, "failed to import notification channels: "+err.Error())
+			} else {
+				result.Notifications = len(channels)
+				changes.section("notifications", maskedChannels(previous), maskedChannels(channels))
+
+				s.rebuildNotifiers(channels)
 			}
 		}
 	}
@@ -312,10 +502,12 @@ func (s *Server) apiConfigImport(w http.ResponseWriter, r *http.Request) {
 		// Filter out any registries where the secret is redacted.
 		clean := make([]RegistryCredential, 0, len(imported.Registries))
 		for _, c := range imported.Registries {
-			if c.Secret == redactedPlaceholder {
+			secret, ok := reveal(c.Secret)
+			if !ok {
 				result.Skipped++
 				continue
 			}
+			c.Secret = secret
 			clean = append(clean, c)
 		}
 
@@ -327,26 +519,89 @@ func (s *Server) apiConfigImport(w http.ResponseWriter, r *http.Request) {
 				s.deps.Log.Error("config import: failed to save registry credentials", "error", err)
 			} else {
 				result.Registries = len(clean)
+				changes.section("registries", redactedCredentials(existing), redactedCredentials(merged))
+			}
+		}
+	}
+
+	// --- Hooks ---
+	if s.deps.HookStore != nil && len(imported.Hooks) > 0 {
+		for _, h := range imported.Hooks {
+			if h.ContainerName == "" || (h.Phase != "pre-update" && h.Phase != "post-update") || len(h.Command) == 0 {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("invalid %s hook for %q rejected", h.Phase, h.ContainerName))
+				continue
+			}
+			if h.Timeout <= 0 {
+				h.Timeout = 30
+			}
+			old := s.currentHook(h.ContainerName, h.Phase)
+			if err := s.deps.HookStore.SaveHook(h); err != nil {
+				s.deps.Log.Warn("config import: failed to save hook", "container", h.ContainerName, "phase", h.Phase, "error", err)
+				continue
 			}
+			result.Hooks++
+			changes.entry("hooks", h.ContainerName+"/"+h.Phase, old, h)
 		}
 	}
 
 	s.logEvent(r, "config-import", "", fmt.Sprintf(
-		"Configuration imported: %d settings, %d notification channels, %d registries (%d redacted values skipped)",
-		result.Settings, result.Notifications, result.Registries, result.Skipped,
+		"Configuration imported: %d settings, %d policy overrides, %d notification channels, %d registries, %d hooks (%d redacted values skipped)",
+		result.Settings, result.Policies, result.Notifications, result.Registries, result.Hooks, result.Skipped,
 	))
 
 	result.Message = fmt.Sprintf(
-		"Imported %d settings, %d notification channels, %d registry credentials",
-		result.Settings, result.Notifications, result.Registries,
+		"Imported %d settings, %d policy overrides, %d notification channels, %d registry credentials, %d hooks",
+		result.Settings, result.Policies, result.Notifications, result.Registries, result.Hooks,
 	)
 	if result.Skipped > 0 {
 		result.Message += fmt.Sprintf(" (%d redacted values skipped)", result.Skipped)
 	}
 
+	changes.record(r)
 	writeJSON(w, http.StatusOK, result)
 }
 
+// auditSetting is a setting value as the audit log shows it: nil when unset,
+// redacted when sensitive.
+func auditSetting(key, value string, set bool) any {
+	if !set {
+		return nil
+	}
+	if value != "" && (isSensitiveSetting(key) || isSensitiveAuditField(key)) {
+		return auditRedacted
+	}
+	return value
+}
+
+// auditOptional returns value, or nil when it is not set.
+func auditOptional(value string, set bool) any {
+	if !set {
+		return nil
+	}
+	return value
+}
+
+// maskedChannels returns channels with their secrets masked.
+func maskedChannels(channels []notify.Channel) []notify.Channel {
+	masked := make([]notify.Channel, len(channels))
+	for i, ch := range channels {
+		masked[i] = notify.MaskSecrets(ch)
+	}
+	return masked
+}
+
+// redactedCredentials returns creds with their secrets redacted.
+func redactedCredentials(creds []RegistryCredential) []RegistryCredential {
+	out := make([]RegistryCredential, len(creds))
+	for i, c := range creds {
+		if c.Secret != "" {
+			c.Secret = auditRedacted
+		}
+		out[i] = c
+	}
+	return out
+}
+
 // applyImportedSettings pushes key settings into the running in-memory config
 // so they take effect without a restart.
 func (s *Server) applyImportedSettings(settings map[string]string) {
@@ -420,6 +675,38 @@ func (s *Server) applyImportedSettings(settings map[string]string) {
 	}
 }
 
+// encryptExportSecrets seals every secret in an unredacted export with the
+// passphrase: sensitive settings, registry secrets and whole channel
+// settings objects, whose secret fields vary by provider.
+func encryptExportSecrets(export *ConfigExport, passphrase string) error {
+	box, enc, err := newExportEncryption(passphrase)
+	if err != nil {
+		return err
+	}
+	export.Encryption = enc
+	for k, v := range export.Settings {
+		if isSensitiveSetting(k) && v != "" {
+			export.Settings[k] = box.seal(v)
+		}
+	}
+	for i := range export.Registries {
+		if export.Registries[i].Secret != "" {
+			export.Registries[i].Secret = box.seal(export.Registries[i].Secret)
+		}
+	}
+	for i := range export.Notifications {
+		if len(export.Notifications[i].Settings) == 0 {
+			continue
+		}
+		sealed, err := json.Marshal(box.seal(string(export.Notifications[i].Settings)))
+		if err != nil {
+			return err
+		}
+		export.Notifications[i].Settings = sealed
+	}
+	return nil
+}
+
 // mergeRegistries combines existing and imported registries. Imported entries
 // replace existing ones with the same ID; existing entries without a matching
 // import are preserved.
@@ -0,0 +1,288 @@
+package web
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/notify"
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+)
+
+type mockRegistryCredentials struct {
+	creds []RegistryCredential
+}
+
+func (m *mockRegistryCredentials) GetRegistryCredentials() ([]RegistryCredential, error) {
+	return append([]RegistryCredential(nil), m.creds...), nil
+}
+
+func (m *mockRegistryCredentials) SetRegistryCredentials(creds []RegistryCredential) error {
+	m.creds = creds
+	return nil
+}
+
+type mockHookStore struct {
+	hooks []HookEntry
+}
+
+func (m *mockHookStore) ListHooks(containerName string) ([]HookEntry, error) {
+	var out []HookEntry
+	for _, h := range m.hooks {
+		if h.ContainerName == containerName {
+			out = append(out, h)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockHookStore) ListAllHooks() ([]HookEntry, error) {
+	return append([]HookEntry(nil), m.hooks...), nil
+}
+
+func (m *mockHookStore) SaveHook(hook HookEntry) error {
+	m.hooks = append(m.hooks, hook)
+	return nil
+}
+
+func (m *mockHookStore) DeleteHook(string, string) error { return nil }
+
+// newConfigTestServer returns a server with empty stores for every part of
+// the configuration export.
+func newConfigTestServer() *Server {
+	return &Server{deps: Dependencies{
+		SettingsStore:       newMockSettingsStore(),
+		Policy:              newMockPolicyStore(),
+		NotifyConfig:        &mockNotifyConfig{},
+		RegistryCredentials: &mockRegistryCredentials{},
+		HookStore:           &mockHookStore{},
+		Log:                 slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}}
+}
+
+func exportConfig(t *testing.T, srv *Server, query, passphrase string) string {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodGet, "/api/config/export"+query, nil)
+	if passphrase != "" {
+		r.Header.Set(configPassphraseHeader, passphrase)
+	}
+	w := httptest.NewRecorder()
+	srv.apiConfigExport(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("export status = %d: %s", w.Code, w.Body.String())
+	}
+	return w.Body.String()
+}
+
+func importConfig(srv *Server, body, passphrase string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/api/config/import", strings.NewReader(body))
+	if passphrase != "" {
+		r.Header.Set(configPassphraseHeader, passphrase)
+	}
+	w := httptest.NewRecorder()
+	srv.apiConfigImport(w, r)
+	return w
+}
+
+func TestConfigExportImport_YAMLRoundTrip(t *testing.T) {
+	src := newConfigTestServer()
+	src.deps.SettingsStore.SaveSetting("poll_interval", "6h")
+	src.deps.SettingsStore.SaveSetting("webhook_secret", "hunter2")
+	src.deps.Policy.SetPolicyOverride("nginx", "pinned")
+	src.deps.NotifyConfig.SetNotificationChannels([]notify.Channel{
+		{ID: "c1", Type: "gotify", Name: "Gotify", Enabled: true, Settings: json.RawMessage(`{"url":"https://gotify.local","token":"tok"}`)},
+	})
+	src.deps.RegistryCredentials.SetRegistryCredentials([]RegistryCredential{
+		{ID: "r1", Registry: "ghcr.io", Username: "me", Secret: "pat"},
+	})
+	src.deps.HookStore.SaveHook(HookEntry{ContainerName: "db", Phase: "pre-update", Command: []string{"pg_dump"}, Timeout: 60})
+
+	doc := exportConfig(t, src, "?format=yaml", "correct horse")
+	if !strings.Contains(doc, "poll_interval: 6h") {
+		t.Errorf("export is not readable YAML:\n%s", doc)
+	}
+	for _, secret := range []string{"hunter2", "pat", "tok"} {
+		if strings.Contains(doc, secret) {
+			t.Errorf("export contains plain secret %q", secret)
+		}
+	}
+
+	dst := newConfigTestServer()
+	if w := importConfig(dst, doc, "wrong"); w.Code != http.StatusBadRequest {
+		t.Errorf("wrong passphrase: status = %d, want 400", w.Code)
+	}
+	if v, _ := dst.deps.SettingsStore.LoadSetting("poll_interval"); v != "" {
+		t.Error("settings imported despite the wrong passphrase")
+	}
+
+	w := importConfig(dst, doc, "correct horse")
+	if w.Code != http.StatusOK {
+		t.Fatalf("import status = %d: %s", w.Code, w.Body.String())
+	}
+	if v, _ := dst.deps.SettingsStore.LoadSetting("webhook_secret"); v != "hunter2" {
+		t.Errorf("webhook_secret = %q, want hunter2", v)
+	}
+	if p, _ := dst.deps.Policy.GetPolicyOverride("nginx"); p != "pinned" {
+		t.Errorf("nginx policy = %q, want pinned", p)
+	}
+	if creds, _ := dst.deps.RegistryCredentials.GetRegistryCredentials(); len(creds) != 1 || creds[0].Secret != "pat" {
+		t.Errorf("registries = %+v, want the decrypted secret", creds)
+	}
+	channels, _ := dst.deps.NotifyConfig.GetNotificationChannels()
+	if len(channels) != 1 || !strings.Contains(string(channels[0].Settings), `"token":"tok"`) {
+		t.Errorf("channels = %+v, want decrypted settings", channels)
+	}
+	if hooks, _ := dst.deps.HookStore.ListHooks("db"); len(hooks) != 1 || hooks[0].Command[0] != "pg_dump" {
+		t.Errorf("hooks = %+v, want the pg_dump hook", hooks)
+	}
+}
+
+func TestConfigImport_EncryptedWithoutPassphrase(t *testing.T) {
+	src := newConfigTestServer()
+	src.deps.SettingsStore.SaveSetting("poll_interval", "6h")
+	src.deps.RegistryCredentials.SetRegistryCredentials([]RegistryCredential{
+		{ID: "r1", Registry: "ghcr.io", Username: "me", Secret: "pat"},
+	})
+	doc := exportConfig(t, src, "", "pass")
+
+	dst := newConfigTestServer()
+	w := importConfig(dst, doc, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("import status = %d: %s", w.Code, w.Body.String())
+	}
+	var res ConfigImportResult
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Skipped != 1 || res.Registries != 0 || len(res.Warnings) == 0 {
+		t.Errorf("result = %+v, want the encrypted secret skipped with a warning", res)
+	}
+	if v, _ := dst.deps.SettingsStore.LoadSetting("poll_interval"); v != "6h" {
+		t.Errorf("poll_interval = %q, want plain settings imported", v)
+	}
+}
+
+func TestConfigImport_HandWrittenYAML(t *testing.T) {
+	doc := `version: "2"
+settings:
+  scan_concurrency: 4
+  paused: false
+  bogus_key: x
+policies:
+  redis: manual
+  app: sometimes
+hooks:
+  - container_name: web
+    phase: post-update
+    command: [curl, -f, http://localhost/health]
+`
+	srv := newConfigTestServer()
+	w := importConfig(srv, doc, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("import status = %d: %s", w.Code, w.Body.String())
+	}
+	if v, _ := srv.deps.SettingsStore.LoadSetting("scan_concurrency"); v != "4" {
+		t.Errorf("scan_concurrency = %q, want 4", v)
+	}
+	if v, _ := srv.deps.SettingsStore.LoadSetting("paused"); v != "false" {
+		t.Errorf("paused = %q, want false", v)
+	}
+	if _, ok := srv.deps.Policy.GetPolicyOverride("app"); ok {
+		t.Error("invalid policy was imported")
+	}
+	hooks, _ := srv.deps.HookStore.ListHooks("web")
+	if len(hooks) != 1 || hooks[0].Timeout != 30 {
+		t.Errorf("hooks = %+v, want one hook with the default timeout", hooks)
+	}
+	var res ConfigImportResult
+	_ = json.Unmarshal(w.Body.Bytes(), &res)
+	if res.Policies != 1 || len(res.Warnings) != 2 {
+		t.Errorf("result = %+v, want 1 policy and warnings for the bad key and policy", res)
+	}
+}
+
+func TestConfigImport_ValidatesSettingValues(t *testing.T) {
+	doc := `version: "2"
+settings:
+  validation_command: sh
+  validation_webhook_url: file:///etc/passwd
+  rebuild_webhook_url: ftp://ci.example/rebuild
+  maintenance_window: "25:00-26:00"
+  event_sink_url: https://events.example/ingest
+`
+	srv := newConfigTestServer()
+	w := importConfig(srv, doc, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("import status = %d: %s", w.Code, w.Body.String())
+	}
+	for _, key := range []string{"validation_command", "validation_webhook_url", "rebuild_webhook_url", "maintenance_window"} {
+		if v, _ := srv.deps.SettingsStore.LoadSetting(key); v != "" {
+			t.Errorf("%s = %q, want invalid value rejected", key, v)
+		}
+	}
+	if v, _ := srv.deps.SettingsStore.LoadSetting("event_sink_url"); v != "https://events.example/ingest" {
+		t.Errorf("event_sink_url = %q, want valid value imported", v)
+	}
+	var res ConfigImportResult
+	_ = json.Unmarshal(w.Body.Bytes(), &res)
+	if res.Settings != 1 || len(res.Warnings) != 4 {
+		t.Errorf("result = %+v, want 1 setting and 4 warnings", res)
+	}
+}
+
+func TestValidateSetting(t *testing.T) {
+	exe := filepath.Join(t.TempDir(), "smoke.sh")
+	if err := os.WriteFile(exe, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		key, value string
+		ok         bool
+	}{
+		{"validation_command", exe, true},
+		{"validation_command", "smoke.sh", false},
+		{"validation_command", filepath.Dir(exe), false},
+		{"validation_command", "", true},
+		{"validation_webhook_url", "https://ci.example/smoke", true},
+		{"validation_webhook_url", "ci.example/smoke", false},
+		{"rebuild_webhook_url", "javascript:alert(1)", false},
+		{"backup_dir", "backups", false},
+		{"poll_interval", "anything", true},
+	}
+	for _, tt := range tests {
+		if err := validateSetting(tt.key, tt.value); (err == nil) != tt.ok {
+			t.Errorf("validateSetting(%s, %q) = %v, want ok=%v", tt.key, tt.value, err, tt.ok)
+		}
+	}
+}
+
+func TestConfigImport_RejectsUnknownVersion(t *testing.T) {
+	srv := newConfigTestServer()
+	if w := importConfig(srv, `{"version":"9","settings":{}}`, ""); w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", w.Code)
+	}
+	if w := importConfig(srv, "version: [", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("malformed YAML: status = %d, want 400", w.Code)
+	}
+}
+
+// Settings redacted from exports must also be encrypted at rest, and any
+// exportable setting the store encrypts must be redacted.
+func TestSensitiveKeysMatchStoreSecrets(t *testing.T) {
+	for key := range sensitiveKeys {
+		if !store.IsSecretSetting(key) {
+			t.Errorf("%s is redacted from exports but not encrypted at rest", key)
+		}
+	}
+	for key := range validSettingKeys {
+		if store.IsSecretSetting(key) && !sensitiveKeys[key] {
+			t.Errorf("%s is encrypted at rest but not redacted from exports", key)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/docker"
+	"github.com/Will-Luck/Docker-Sentinel/internal/engine"
+)
+
+// apiGetContainerSchedule returns a container's cron schedule override alongside the
+// sentinel.schedule label it overrides. With neither set, the container is
+// checked on every global scan.
+func (s *Server) apiGetContainerSchedule(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if !isValidContainerName(name) {
+		writeError(w, http.StatusBadRequest, "invalid container name")
+		return
+	}
+
+	resp := map[string]any{"name": name, "override": "", "label": ""}
+	effective := ""
+	if s.deps.Schedules != nil {
+		if expr, ok := s.deps.Schedules.GetContainerSchedule(hostScopedKey(r, name)); ok {
+			resp["override"] = expr
+			effective = expr
+		}
+	}
+	if containers, err := s.deps.Docker.ListAllContainers(r.Context()); err == nil {
+		for _, c := range containers {
+			if containerName(c) == name {
+				label := docker.ContainerSchedule(c.Labels)
+				resp["label"] = label
+				if effective == "" {
+					effective = label
+				}
+				break
+			}
+		}
+	}
+	resp["schedule"] = effective
+	if effective != "" {
+		if sched, err := engine.ParseContainerSchedule(effective); err == nil {
+			resp["next"] = sched.Next(time.Now())
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// apiSetContainerSchedule stores a container's cron schedule override.
+// Body: {"schedule": "0 3 * * 0"}
+func (s *Server) apiSetContainerSchedule(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if !isValidContainerName(name) {
+		writeError(w, http.StatusBadRequest, "invalid container name")
+		return
+	}
+	if s.deps.Schedules == nil {
+		writeError(w, http.StatusNotImplemented, "schedule store not available")
+		return
+	}
+
+	var body struct {
+		Schedule string `json:"schedule"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	expr := strings.TrimSpace(body.Schedule)
+	if expr == "" {
+		writeError(w, http.StatusBadRequest, "schedule is required; use DELETE to clear")
+		return
+	}
+	sched, err := engine.ParseContainerSchedule(expr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid cron expression: "+err.Error())
+		return
+	}
+
+	if err := s.deps.Schedules.SetContainerSchedule(hostScopedKey(r, name), expr); err != nil {
+		s.deps.Log.Error("failed to save schedule", "name", name, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to save schedule")
+		return
+	}
+
+	s.logEvent(r, "schedule_set", name, "Update schedule set to "+expr)
+	writeJSON(w, http.StatusOK, map[string]any{
+		"status":   "ok",
+		"name":     name,
+		"schedule": expr,
+		"next":     sched.Next(time.Now()),
+	})
+}
+
+// apiDeleteContainerSchedule clears a container's schedule override, falling back to
+// the sentinel.schedule label if present.
+func (s *Server) apiDeleteContainerSchedule(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if !isValidContainerName(name) {
+		writeError(w, http.StatusBadRequest, "invalid container name")
+		return
+	}
+	if s.deps.Schedules == nil {
+		writeError(w, http.StatusNotImplemented, "schedule store not available")
+		return
+	}
+	if err := s.deps.Schedules.DeleteContainerSchedule(hostScopedKey(r, name)); err != nil {
+		s.deps.Log.Error("failed to delete schedule", "name", name, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to delete schedule")
+		return
+	}
+	s.logEvent(r, "schedule_delete", name, "Update schedule override cleared")
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok", "name": name})
+}
@@ -0,0 +1,91 @@
+package web
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type mockScheduleStore struct {
+	entries map[string]string
+}
+
+func (m *mockScheduleStore) GetContainerSchedule(key string) (string, bool) {
+	expr, ok := m.entries[key]
+	return expr, ok
+}
+
+func (m *mockScheduleStore) SetContainerSchedule(key, expr string) error {
+	m.entries[key] = expr
+	return nil
+}
+
+func (m *mockScheduleStore) DeleteContainerSchedule(key string) error {
+	delete(m.entries, key)
+	return nil
+}
+
+func newScheduleTestServer(ss *mockScheduleStore) *Server {
+	return &Server{
+		deps: Dependencies{
+			Docker: &mockContainerLister{containers: []ContainerSummary{
+				{Names: []string{"/backup"}, Labels: map[string]string{"sentinel.schedule": "0 3 * * *"}},
+			}},
+			Schedules: ss,
+			Log:       slog.Default(),
+		},
+	}
+}
+
+func TestApiSetContainerSchedule(t *testing.T) {
+	ss := &mockScheduleStore{entries: map[string]string{}}
+	srv := newScheduleTestServer(ss)
+
+	set := func(body string) int {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/containers/backup/schedule", strings.NewReader(body))
+		r.SetPathValue("name", "backup")
+		srv.apiSetContainerSchedule(w, r)
+		return w.Code
+	}
+	if code := set(`{"schedule":"not a cron"}`); code != http.StatusBadRequest {
+		t.Errorf("invalid cron: status = %d, want 400", code)
+	}
+	if code := set(`{"schedule":""}`); code != http.StatusBadRequest {
+		t.Errorf("empty: status = %d, want 400", code)
+	}
+	if code := set(`{"schedule":" 0 4 * * 6 "}`); code != http.StatusOK {
+		t.Fatalf("valid: status = %d, want 200", code)
+	}
+	if ss.entries["backup"] != "0 4 * * 6" {
+		t.Errorf("stored = %q", ss.entries["backup"])
+	}
+}
+
+func TestApiGetContainerSchedule_OverrideBeatsLabel(t *testing.T) {
+	ss := &mockScheduleStore{entries: map[string]string{}}
+	srv := newScheduleTestServer(ss)
+
+	get := func() map[string]any {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/containers/backup/schedule", nil)
+		r.SetPathValue("name", "backup")
+		srv.apiGetContainerSchedule(w, r)
+		var resp map[string]any
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	if resp := get(); resp["schedule"] != "0 3 * * *" || resp["next"] == nil {
+		t.Errorf("label only: %v", resp)
+	}
+	ss.entries["backup"] = "0 4 * * 6"
+	if resp := get(); resp["schedule"] != "0 4 * * 6" || resp["label"] != "0 3 * * *" {
+		t.Errorf("with override: %v", resp)
+	}
+}
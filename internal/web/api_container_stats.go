@@ -0,0 +1,106 @@
+package web
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// containerStatsHistoryLimit caps how many history records feed the update
+// statistics in the container detail view.
+const containerStatsHistoryLimit = 500
+
+// containerUpdateStats summarises a container's update history, to help
+// decide which containers are safe to move to the auto policy.
+type containerUpdateStats struct {
+	Updates       int   `json:"updates"`         // successful updates
+	Failures      int   `json:"failures"`        // failed, rolled back or partial updates
+	Rollbacks     int   `json:"rollbacks"`       // updates that were rolled back
+	AvgDurationMs int64 `json:"avg_duration_ms"` // mean duration of successful updates
+
+	LastSuccess       *time.Time      `json:"last_success,omitempty"`
+	SinceLastSuccessS int64           `json:"since_last_success_s,omitempty"` // seconds since LastSuccess
+	LastFailure       *failureSummary `json:"last_failure,omitempty"`
+
+	// DigestChanges counts the new image digests seen, oldest first.
+	// AvgDaysBetweenChanges is the mean gap between them, set once there
+	// are at least two.
+	DigestChanges         int     `json:"digest_changes"`
+	AvgDaysBetweenChanges float64 `json:"avg_days_between_changes,omitempty"`
+}
+
+// failureSummary is the most recent failed update and why it failed.
+type failureSummary struct {
+	Timestamp    time.Time `json:"timestamp"`
+	Outcome      string    `json:"outcome"`
+	FailureClass string    `json:"failure_class,omitempty"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// isFailedUpdate reports whether an outcome is an update that went wrong.
+// Check failures and skips are not updates and are left out.
+func isFailedUpdate(outcome string) bool {
+	switch outcome {
+	case "failed", "rollback", "rollback_failed", "partial":
+		return true
+	}
+	return false
+}
+
+// computeUpdateStats derives update statistics from a container's history,
+// given newest first as ListHistoryByContainer returns it.
+func computeUpdateStats(history []UpdateRecord, now time.Time) containerUpdateStats {
+	var stats containerUpdateStats
+	var total time.Duration
+	var changes []time.Time
+	lastDigest := ""
+
+	// Walk oldest first so digest changes are seen in order.
+	for _, rec := range slices.Backward(history) {
+		switch {
+		case rec.Outcome == "success":
+			stats.Updates++
+			total += rec.Duration
+			ts := rec.Timestamp
+			stats.LastSuccess = &ts
+		case isFailedUpdate(rec.Outcome):
+			stats.Failures++
+			stats.LastFailure = &failureSummary{
+				Timestamp:    rec.Timestamp,
+				Outcome:      rec.Outcome,
+				FailureClass: rec.FailureClass,
+				Error:        rec.Error,
+			}
+		}
+		if rec.Outcome == "rollback" || strings.HasPrefix(rec.Outcome, "rollback_") {
+			stats.Rollbacks++
+		}
+
+		// A digest counts as new when it differs from the last one seen, or
+		// from the digest it replaced for the first record.
+		if rec.NewDigest == "" {
+			continue
+		}
+		prev := lastDigest
+		if prev == "" {
+			prev = rec.OldDigest
+		}
+		if rec.NewDigest != prev {
+			changes = append(changes, rec.Timestamp)
+		}
+		lastDigest = rec.NewDigest
+	}
+
+	if stats.Updates > 0 {
+		stats.AvgDurationMs = (total / time.Duration(stats.Updates)).Milliseconds()
+	}
+	if stats.LastSuccess != nil {
+		stats.SinceLastSuccessS = int64(now.Sub(*stats.LastSuccess).Seconds())
+	}
+	stats.DigestChanges = len(changes)
+	if n := len(changes); n >= 2 {
+		span := changes[n-1].Sub(changes[0])
+		stats.AvgDaysBetweenChanges = span.Hours() / 24 / float64(n-1)
+	}
+	return stats
+}
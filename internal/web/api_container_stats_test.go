@@ -0,0 +1,50 @@
+package web
+
+import (
+	"testing"
+	"time"
+)
+
+func TestComputeUpdateStats(t *testing.T) {
+	day := 24 * time.Hour
+	t0 := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	// Newest first, as ListHistoryByContainer returns it.
+	history := []UpdateRecord{
+		{Timestamp: t0.Add(9 * day), Outcome: "check_failed", Error: "registry timeout"},
+		{Timestamp: t0.Add(8 * day), Outcome: "rollback", OldDigest: "sha256:c", NewDigest: "sha256:d", FailureClass: "validation_timeout", Error: "unhealthy"},
+		{Timestamp: t0.Add(4 * day), Outcome: "success", OldDigest: "sha256:b", NewDigest: "sha256:c", Duration: 30 * time.Second},
+		{Timestamp: t0.Add(2 * day), Outcome: "failed", OldDigest: "sha256:a", NewDigest: "sha256:b", Error: "pull failed"},
+		{Timestamp: t0.Add(2*day - time.Hour), Outcome: "identical", OldDigest: "sha256:a", NewDigest: "sha256:a"},
+		{Timestamp: t0, Outcome: "success", OldDigest: "sha256:0", NewDigest: "sha256:a", Duration: 10 * time.Second},
+	}
+
+	got := computeUpdateStats(history, t0.Add(10*day))
+
+	if got.Updates != 2 || got.Failures != 2 || got.Rollbacks != 1 {
+		t.Errorf("updates/failures/rollbacks = %d/%d/%d, want 2/2/1", got.Updates, got.Failures, got.Rollbacks)
+	}
+	if got.AvgDurationMs != 20000 {
+		t.Errorf("avg_duration_ms = %d, want 20000", got.AvgDurationMs)
+	}
+	if got.LastSuccess == nil || !got.LastSuccess.Equal(t0.Add(4*day)) {
+		t.Errorf("last_success = %v, want %v", got.LastSuccess, t0.Add(4*day))
+	}
+	if want := int64((6 * day).Seconds()); got.SinceLastSuccessS != want {
+		t.Errorf("since_last_success_s = %d, want %d", got.SinceLastSuccessS, want)
+	}
+	if got.LastFailure == nil || got.LastFailure.Outcome != "rollback" || got.LastFailure.FailureClass != "validation_timeout" {
+		t.Errorf("last_failure = %+v, want the rollback", got.LastFailure)
+	}
+	// a, b, c, d: four new digests over eight days.
+	if got.DigestChanges != 4 {
+		t.Errorf("digest_changes = %d, want 4", got.DigestChanges)
+	}
+	if want := 8.0 / 3; got.AvgDaysBetweenChanges != want {
+		t.Errorf("avg_days_between_changes = %v, want %v", got.AvgDaysBetweenChanges, want)
+	}
+
+	empty := computeUpdateStats(nil, t0)
+	if empty.Updates != 0 || empty.LastSuccess != nil || empty.LastFailure != nil || empty.AvgDurationMs != 0 {
+		t.Errorf("empty history = %+v, want zero stats", empty)
+	}
+}
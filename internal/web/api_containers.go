@@ -5,9 +5,13 @@ import (
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/registry"
 )
 
 // apiContainers returns all monitored containers with policy and maintenance status.
@@ -27,14 +31,23 @@ func (s *Server) apiContainers(w http.ResponseWriter, r *http.Request) {
 		State       string `json:"state"`
 		Maintenance bool   `json:"maintenance"`
 		Stack       string `json:"stack,omitempty"`
+		Version     string `json:"current_version,omitempty"`
+		Candidate   string `json:"candidate_version,omitempty"`
+		Failing     bool   `json:"update_failing,omitempty"`
 	}
 
+	versions := s.versionSet()
+	retries := s.retrySet()
+	scope := requestScope(r)
 	result := make([]containerInfo, 0, len(containers))
 	for _, c := range containers {
 		// Filter out Swarm task containers — they appear under Swarm Services.
 		if _, isTask := c.Labels["com.docker.swarm.task"]; isTask {
 			continue
 		}
+		if !scope.Matches(containerName(c), c.Labels) {
+			continue
+		}
 
 		name := containerName(c)
 		policy := s.resolvedPolicy(c.Labels, name)
@@ -52,6 +65,9 @@ func (s *Server) apiContainers(w http.ResponseWriter, r *http.Request) {
 			State:       c.State,
 			Maintenance: maintenance,
 			Stack:       c.Labels["com.docker.compose.project"],
+			Version:     versions[name].Current,
+			Candidate:   versions[name].Candidate,
+			Failing:     retries[name].Failing,
 		})
 	}
 
@@ -60,6 +76,9 @@ func (s *Server) apiContainers(w http.ResponseWriter, r *http.Request) {
 	if s.deps.Swarm != nil && s.deps.Swarm.IsSwarmMode() {
 		services, _ := s.deps.Swarm.ListServices(r.Context())
 		for _, svc := range services {
+			if !scope.Matches(svc.Name, svc.Labels) {
+				continue
+			}
 			result = append(result, containerInfo{
 				ID:    svc.ID,
 				Name:  svc.Name,
@@ -83,7 +102,7 @@ func (s *Server) apiHistory(w http.ResponseWriter, r *http.Request) {
 	}
 	before := r.URL.Query().Get("before")
 
-	records, err := s.deps.Store.ListHistory(limit, before)
+	records, err := s.scopedHistory(r, limit, before)
 	if err != nil {
 		s.deps.Log.Error("failed to list history", "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to list history")
@@ -124,11 +143,15 @@ func (s *Server) apiContainerDetail(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Gather history.
-	history, err := s.deps.Store.ListHistoryByContainer(name, 50)
+	// Gather history; statistics use a longer window than the list shown.
+	history, err := s.deps.Store.ListHistoryByContainer(name, containerStatsHistoryLimit)
 	if err != nil {
 		s.deps.Log.Warn("failed to list history for container", "name", name, "error", err)
 	}
+	stats := computeUpdateStats(history, time.Now())
+	if len(history) > 50 {
+		history = history[:50]
+	}
 	if history == nil {
 		history = []UpdateRecord{}
 	}
@@ -159,8 +182,16 @@ func (s *Server) apiContainerDetail(w http.ResponseWriter, r *http.Request) {
 		Policy      string          `json:"policy"`
 		State       string          `json:"state"`
 		Maintenance bool            `json:"maintenance"`
+		Version     *VersionInfo    `json:"version,omitempty"`
 		History     []UpdateRecord  `json:"history"`
 		Snapshots   []SnapshotEntry `json:"snapshots"`
+
+		Stats containerUpdateStats `json:"update_stats"`
+	}
+
+	var version *VersionInfo
+	if v, ok := s.versionSet()[name]; ok {
+		version = &v
 	}
 
 	writeJSON(w, http.StatusOK, detailResponse{
@@ -170,8 +201,10 @@ func (s *Server) apiContainerDetail(w http.ResponseWriter, r *http.Request) {
 		Policy:      s.resolvedPolicy(found.Labels, containerName(*found)),
 		State:       found.State,
 		Maintenance: maintenance,
+		Version:     version,
 		History:     history,
 		Snapshots:   snapshots,
+		Stats:       stats,
 	})
 }
 
@@ -266,27 +299,91 @@ func (s *Server) apiSaveStackOrder(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
 }
 
-// apiHistoryExport streams all history records as CSV or JSON.
+// parseExportTime parses a from/to bound of the history export: an RFC 3339
+// time or a date, which means midnight UTC at the start of that day.
+func parseExportTime(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, v)
+}
+
+// apiHistoryExport streams update history as CSV or JSON, newest first.
+// Optional from and to bound the export to [from, to), so that e.g.
+// from=2026-09-01&to=2026-10-01 exports September. Records are written as
+// they are read, so the full history can be exported without holding it all
+// in memory.
 func (s *Server) apiHistoryExport(w http.ResponseWriter, r *http.Request) {
-	records, err := s.deps.Store.ListAllHistory()
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to export history")
+	q := r.URL.Query()
+	format := q.Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "csv" {
+		writeError(w, http.StatusBadRequest, "format must be csv or json")
+		return
+	}
+	var from, to time.Time
+	for _, b := range []struct {
+		name string
+		dst  *time.Time
+	}{{"from", &from}, {"to", &to}} {
+		v := q.Get(b.name)
+		if v == "" {
+			continue
+		}
+		t, err := parseExportTime(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, b.name+" must be a date (2006-01-02) or RFC 3339 time")
+			return
+		}
+		*b.dst = t
+	}
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		writeError(w, http.StatusBadRequest, "from must be before to")
 		return
 	}
 
-	format := r.URL.Query().Get("format")
+	filename := "sentinel-history"
+	if !from.IsZero() {
+		filename += "-from-" + from.UTC().Format(time.DateOnly)
+	}
+	if !to.IsZero() {
+		filename += "-to-" + to.UTC().Format(time.DateOnly)
+	}
+	filename += "." + format
+
+	// Flush every hundred records so large exports start downloading at once.
+	var cw *csv.Writer
+	rc := http.NewResponseController(w)
+	n := 0
+	flush := func() {
+		if n++; n%100 != 0 {
+			return
+		}
+		if cw != nil {
+			cw.Flush()
+		}
+		_ = rc.Flush()
+	}
+
+	allowed := s.scopedHistoryFilter(r)
+	var err error
 	switch format {
 	case "csv":
 		w.Header().Set("Content-Type", "text/csv")
-		w.Header().Set("Content-Disposition", "attachment; filename=sentinel-history.csv")
-		cw := csv.NewWriter(w)
+		w.Header().Set("Content-Disposition", "attachment; filename="+filename)
+		cw = csv.NewWriter(w)
 		_ = cw.Write([]string{"timestamp", "container", "type", "old_image", "new_image", "outcome", "duration_s", "error", "host_id", "host_name"})
-		for _, rec := range records {
+		err = s.deps.Store.ForEachHistory(from, to, func(rec UpdateRecord) error {
+			if !allowed(rec) {
+				return nil
+			}
 			dur := ""
 			if rec.Duration > 0 {
 				dur = fmt.Sprintf("%.1f", rec.Duration.Seconds())
 			}
-			_ = cw.Write([]string{
+			if err := cw.Write([]string{
 				rec.Timestamp.Format(time.RFC3339),
 				rec.ContainerName,
 				rec.Type,
@@ -297,13 +394,37 @@ func (s *Server) apiHistoryExport(w http.ResponseWriter, r *http.Request) {
 				rec.Error,
 				rec.HostID,
 				rec.HostName,
-			})
-		}
+			}); err != nil {
+				return err
+			}
+			flush()
+			return nil
+		})
 		cw.Flush()
 	default:
 		w.Header().Set("Content-Type", "application/json")
-		w.Header().Set("Content-Disposition", "attachment; filename=sentinel-history.json")
-		_ = json.NewEncoder(w).Encode(records)
+		w.Header().Set("Content-Disposition", "attachment; filename="+filename)
+		enc := json.NewEncoder(w)
+		sep := "["
+		err = s.deps.Store.ForEachHistory(from, to, func(rec UpdateRecord) error {
+			if !allowed(rec) {
+				return nil
+			}
+			if _, err := io.WriteString(w, sep); err != nil {
+				return err
+			}
+			sep = ","
+			flush()
+			return enc.Encode(rec)
+		})
+		if sep == "[" {
+			_, _ = io.WriteString(w, "[")
+		}
+		_, _ = io.WriteString(w, "]\n")
+	}
+	if err != nil {
+		// The status line has been sent; the truncated body shows the failure.
+		s.deps.Log.Error("history export failed", "error", err)
 	}
 }
 
@@ -384,6 +505,216 @@ func (s *Server) apiSetReleaseSources(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// apiAddReleaseSource adds or replaces a single image-to-repo mapping,
+// keyed by image pattern.
+func (s *Server) apiAddReleaseSource(w http.ResponseWriter, r *http.Request) {
+	if s.deps.ReleaseSources == nil {
+		writeError(w, http.StatusNotImplemented, "release sources store not available")
+		return
+	}
+	var src ReleaseSource
+	if err := json.NewDecoder(r.Body).Decode(&src); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	src.ImagePattern = strings.TrimSpace(src.ImagePattern)
+	src.GitHubRepo = strings.Trim(strings.TrimSpace(src.GitHubRepo), "/")
+	if src.ImagePattern == "" {
+		writeError(w, http.StatusBadRequest, "image_pattern is required")
+		return
+	}
+	if parts := strings.Split(src.GitHubRepo, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		writeError(w, http.StatusBadRequest, "github_repo must be owner/repo")
+		return
+	}
+
+	sources, err := s.deps.ReleaseSources.GetReleaseSources()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	replaced := false
+	for i := range sources {
+		if sources[i].ImagePattern == src.ImagePattern {
+			sources[i] = src
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		sources = append(sources, src)
+	}
+	if err := s.deps.ReleaseSources.SetReleaseSources(sources); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.logEvent(r, "settings", "", "Release source "+src.ImagePattern+" -> "+src.GitHubRepo)
+	writeJSON(w, http.StatusOK, src)
+}
+
+// apiDeleteReleaseSource removes the mapping for ?pattern=.
+func (s *Server) apiDeleteReleaseSource(w http.ResponseWriter, r *http.Request) {
+	if s.deps.ReleaseSources == nil {
+		writeError(w, http.StatusNotImplemented, "release sources store not available")
+		return
+	}
+	pattern := r.URL.Query().Get("pattern")
+	if pattern == "" {
+		writeError(w, http.StatusBadRequest, "pattern is required")
+		return
+	}
+	sources, err := s.deps.ReleaseSources.GetReleaseSources()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	kept := make([]ReleaseSource, 0, len(sources))
+	for _, src := range sources {
+		if src.ImagePattern != pattern {
+			kept = append(kept, src)
+		}
+	}
+	if len(kept) == len(sources) {
+		writeError(w, http.StatusNotFound, "no release source for pattern: "+pattern)
+		return
+	}
+	if err := s.deps.ReleaseSources.SetReleaseSources(kept); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.logEvent(r, "settings", "", "Release source "+pattern+" removed")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// containerReleaseResponse is returned by apiContainerRelease.
+type containerReleaseResponse struct {
+	Image  string                    `json:"image"`
+	Repo   string                    `json:"repo,omitempty"`
+	Origin string                    `json:"origin,omitempty"` // "custom", "label", or "builtin"
+	Info   *registry.RepoReleaseInfo `json:"info,omitempty"`
+	Error  string                    `json:"error,omitempty"`
+}
+
+// apiContainerRelease resolves a container's source repository (custom
+// mapping, OCI labels, or built-in rules) and returns its latest release,
+// changelog link and end-of-life hints. Pass ?prerelease=true to include
+// pre-releases when picking the latest release.
+func (s *Server) apiContainerRelease(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if !isValidContainerName(name) {
+		writeError(w, http.StatusBadRequest, "invalid container name")
+		return
+	}
+
+	containers, err := s.deps.Docker.ListAllContainers(r.Context())
+	if err != nil {
+		s.deps.Log.Error("failed to list containers", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to list containers")
+		return
+	}
+	var resp containerReleaseResponse
+	var labels map[string]string
+	for _, c := range containers {
+		if containerName(c) == name {
+			resp.Image = c.Image
+			labels = c.Labels
+			break
+		}
+	}
+	if resp.Image == "" {
+		writeError(w, http.StatusNotFound, "container not found: "+name)
+		return
+	}
+
+	resp.Repo, resp.Origin = registry.ResolveGitHubRepo(resp.Image, labels, s.loadReleaseSources())
+	if resp.Repo == "" {
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+	info, err := registry.FetchRepoReleaseInfo(ctx, resp.Repo, r.URL.Query().Get("prerelease") == "true")
+	if err != nil {
+		s.deps.Log.Debug("release info lookup failed", "name", name, "repo", resp.Repo, "error", err)
+		resp.Error = err.Error()
+	}
+	resp.Info = info
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// containerImageDatesResponse is returned by apiContainerImageDates.
+type containerImageDatesResponse struct {
+	Image     string               `json:"image"`
+	Running   *registry.ImageDates `json:"running,omitempty"`
+	Candidate string               `json:"candidate,omitempty"` // image the registry dates describe
+	Registry  *registry.ImageDates `json:"registry,omitempty"`
+	Error     string               `json:"error,omitempty"`
+}
+
+// apiContainerImageDates returns when the running image was built and when
+// the update candidate was built and pushed. The candidate is the queued
+// update's target tag, else the resolved candidate version, else the
+// container's own tag (a digest-only update of a floating tag).
+func (s *Server) apiContainerImageDates(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if !isValidContainerName(name) {
+		writeError(w, http.StatusBadRequest, "invalid container name")
+		return
+	}
+	if s.deps.ImageDates == nil {
+		writeError(w, http.StatusNotImplemented, "image dates not available")
+		return
+	}
+
+	containers, err := s.deps.Docker.ListAllContainers(r.Context())
+	if err != nil {
+		s.deps.Log.Error("failed to list containers", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to list containers")
+		return
+	}
+	var found *ContainerSummary
+	for _, c := range containers {
+		if containerName(c) == name {
+			found = &c
+			break
+		}
+	}
+	if found == nil {
+		writeError(w, http.StatusNotFound, "container not found: "+name)
+		return
+	}
+
+	resp := containerImageDatesResponse{Image: found.Image}
+	if local, err := s.deps.ImageDates.LocalImageDates(r.Context(), found.ID); err != nil {
+		s.deps.Log.Debug("local image dates lookup failed", "name", name, "error", err)
+	} else {
+		resp.Running = &local
+	}
+
+	if strings.Contains(found.Image, "@") {
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+	resp.Candidate = found.Image
+	if pending, ok := s.deps.Queue.Get(name); ok && len(pending.NewerVersions) > 0 {
+		resp.Candidate = webReplaceTag(found.Image, pending.NewerVersions[0])
+	} else if v, ok := s.versionSet()[name]; ok && v.Candidate != "" {
+		resp.Candidate = webReplaceTag(found.Image, v.Candidate)
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+	remote, err := s.deps.ImageDates.RemoteImageDates(ctx, resp.Candidate)
+	if err != nil {
+		s.deps.Log.Debug("registry image dates lookup failed", "name", name, "image", resp.Candidate, "error", err)
+		resp.Error = err.Error()
+	} else {
+		resp.Registry = &remote
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
 // apiTriggerScan triggers an immediate scan cycle.
 func (s *Server) apiTriggerScan(w http.ResponseWriter, r *http.Request) {
 	if s.deps.Scheduler == nil {
@@ -7,9 +7,12 @@ import (
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+	"github.com/Will-Luck/Docker-Sentinel/internal/registry"
 )
 
 // ---------------------------------------------------------------------------
@@ -407,3 +410,254 @@ func TestApiStart_DockerListError(t *testing.T) {
 		t.Fatalf("status = %d, want %d", w.Code, http.StatusInternalServerError)
 	}
 }
+
+// ---------------------------------------------------------------------------
+// Release source mapping tests
+// ---------------------------------------------------------------------------
+
+type mockReleaseSourceStore struct {
+	sources []ReleaseSource
+}
+
+func (m *mockReleaseSourceStore) GetReleaseSources() ([]ReleaseSource, error) {
+	return append([]ReleaseSource(nil), m.sources...), nil
+}
+
+func (m *mockReleaseSourceStore) SetReleaseSources(sources []ReleaseSource) error {
+	m.sources = sources
+	return nil
+}
+
+func TestApiAddReleaseSource_Upsert(t *testing.T) {
+	rs := &mockReleaseSourceStore{sources: []ReleaseSource{{ImagePattern: "myorg/*", GitHubRepo: "old/repo"}}}
+	srv := &Server{deps: Dependencies{
+		ReleaseSources: rs,
+		Log:            slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}}
+
+	body := `{"image_pattern":"myorg/*","github_repo":"new/repo"}`
+	w := httptest.NewRecorder()
+	srv.apiAddReleaseSource(w, httptest.NewRequest(http.MethodPost, "/api/release-sources", strings.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	if len(rs.sources) != 1 || rs.sources[0].GitHubRepo != "new/repo" {
+		t.Errorf("sources = %+v, want single replaced mapping", rs.sources)
+	}
+
+	w = httptest.NewRecorder()
+	srv.apiAddReleaseSource(w, httptest.NewRequest(http.MethodPost, "/api/release-sources",
+		strings.NewReader(`{"image_pattern":"x","github_repo":"not-a-repo"}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("invalid repo: status = %d, want 400", w.Code)
+	}
+}
+
+func TestApiDeleteReleaseSource(t *testing.T) {
+	rs := &mockReleaseSourceStore{sources: []ReleaseSource{
+		{ImagePattern: "a/*", GitHubRepo: "a/a"},
+		{ImagePattern: "b/*", GitHubRepo: "b/b"},
+	}}
+	srv := &Server{deps: Dependencies{
+		ReleaseSources: rs,
+		Log:            slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}}
+
+	w := httptest.NewRecorder()
+	srv.apiDeleteReleaseSource(w, httptest.NewRequest(http.MethodDelete, "/api/release-sources?pattern=a/*", nil))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want 204", w.Code)
+	}
+	if len(rs.sources) != 1 || rs.sources[0].ImagePattern != "b/*" {
+		t.Errorf("sources = %+v, want only b/*", rs.sources)
+	}
+
+	w = httptest.NewRecorder()
+	srv.apiDeleteReleaseSource(w, httptest.NewRequest(http.MethodDelete, "/api/release-sources?pattern=missing", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("missing pattern: status = %d, want 404", w.Code)
+	}
+}
+
+func TestApiContainerRelease_LabelGuess(t *testing.T) {
+	lister := &mockContainerLister{containers: []ContainerSummary{{
+		ID:     "abc",
+		Names:  []string{"/app"},
+		Image:  "nginx:1.25",
+		Labels: map[string]string{},
+	}}}
+	srv := &Server{deps: Dependencies{
+		Docker: lister,
+		Log:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/containers/app/release", nil)
+	req.SetPathValue("name", "app")
+	w := httptest.NewRecorder()
+	srv.apiContainerRelease(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	var resp containerReleaseResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	// nginx has no built-in mapping and no source labels, so nothing resolves
+	// and no network lookup is attempted.
+	if resp.Repo != "" || resp.Info != nil {
+		t.Errorf("resp = %+v, want unresolved", resp)
+	}
+}
+
+type mockImageDates struct {
+	local    registry.ImageDates
+	remote   registry.ImageDates
+	gotImage string
+}
+
+func (m *mockImageDates) LocalImageDates(_ context.Context, _ string) (registry.ImageDates, error) {
+	return m.local, nil
+}
+
+func (m *mockImageDates) RemoteImageDates(_ context.Context, imageRef string) (registry.ImageDates, error) {
+	m.gotImage = imageRef
+	return m.remote, nil
+}
+
+func TestApiContainerImageDates_QueuedCandidate(t *testing.T) {
+	built := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	pushed := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+	dates := &mockImageDates{
+		local:  registry.ImageDates{Created: built.AddDate(-1, 0, 0)},
+		remote: registry.ImageDates{Created: built, Pushed: pushed},
+	}
+	srv := &Server{deps: Dependencies{
+		Docker: &mockContainerLister{containers: []ContainerSummary{{
+			ID: "abc", Names: []string{"/app"}, Image: "nginx:1.25",
+		}}},
+		Queue:      &mockQueue{items: []PendingUpdate{{ContainerName: "app", NewerVersions: []string{"1.27", "1.26"}}}},
+		ImageDates: dates,
+		Log:        slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/containers/app/image-dates", nil)
+	req.SetPathValue("name", "app")
+	w := httptest.NewRecorder()
+	srv.apiContainerImageDates(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	var resp containerImageDatesResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Candidate != "nginx:1.27" || dates.gotImage != "nginx:1.27" {
+		t.Errorf("candidate = %q (looked up %q), want nginx:1.27", resp.Candidate, dates.gotImage)
+	}
+	if resp.Running == nil || !resp.Running.Created.Equal(built.AddDate(-1, 0, 0)) {
+		t.Errorf("running = %+v", resp.Running)
+	}
+	if resp.Registry == nil || !resp.Registry.Pushed.Equal(pushed) {
+		t.Errorf("registry = %+v", resp.Registry)
+	}
+}
+
+func TestApiContainerImageDates_DigestPinnedSkipsRegistry(t *testing.T) {
+	dates := &mockImageDates{}
+	srv := &Server{deps: Dependencies{
+		Docker: &mockContainerLister{containers: []ContainerSummary{{
+			ID: "abc", Names: []string{"/app"}, Image: "nginx@sha256:abc",
+		}}},
+		Queue:      &mockQueue{},
+		ImageDates: dates,
+		Log:        slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/containers/app/image-dates", nil)
+	req.SetPathValue("name", "app")
+	w := httptest.NewRecorder()
+	srv.apiContainerImageDates(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	if dates.gotImage != "" {
+		t.Errorf("registry looked up %q for a digest-pinned image", dates.gotImage)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// apiHistoryExport tests
+// ---------------------------------------------------------------------------
+
+func newExportTestServer() *Server {
+	hs := newMockHistoryStore()
+	for i := 0; i < 250; i++ {
+		hs.records = append(hs.records, UpdateRecord{
+			Timestamp:     time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC).Add(-time.Duration(i) * time.Hour),
+			ContainerName: "app",
+			Outcome:       "success",
+			Duration:      1500 * time.Millisecond,
+		})
+	}
+	return &Server{deps: Dependencies{Store: hs, Log: slog.New(slog.NewTextHandler(io.Discard, nil))}}
+}
+
+func TestApiHistoryExport_JSONRange(t *testing.T) {
+	srv := newExportTestServer()
+	w := httptest.NewRecorder()
+	srv.apiHistoryExport(w, httptest.NewRequest(http.MethodGet, "/api/history/export?format=json&from=2026-09-30&to=2026-10-01", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if cd := w.Header().Get("Content-Disposition"); !strings.Contains(cd, "sentinel-history-from-2026-09-30-to-2026-10-01.json") {
+		t.Errorf("Content-Disposition = %q", cd)
+	}
+	var got []UpdateRecord
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v\n%s", err, w.Body.String())
+	}
+	// 1 October 00:00 is excluded; the 24 hours before it are not.
+	if len(got) != 24 {
+		t.Fatalf("got %d records, want 24", len(got))
+	}
+	if got[0].Timestamp.Day() != 30 || got[23].Timestamp.Hour() != 0 {
+		t.Errorf("range = %v .. %v", got[0].Timestamp, got[23].Timestamp)
+	}
+}
+
+func TestApiHistoryExport_FullCSV(t *testing.T) {
+	srv := newExportTestServer()
+	w := httptest.NewRecorder()
+	srv.apiHistoryExport(w, httptest.NewRequest(http.MethodGet, "/api/history/export?format=csv", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if len(lines) != 251 {
+		t.Fatalf("got %d lines, want header plus all 250 records", len(lines))
+	}
+	if lines[1] != "2026-10-01T00:00:00Z,app,,,,success,1.5,,," {
+		t.Errorf("first row = %q", lines[1])
+	}
+}
+
+func TestApiHistoryExport_EmptyJSON(t *testing.T) {
+	srv := &Server{deps: Dependencies{Store: newMockHistoryStore()}}
+	w := httptest.NewRecorder()
+	srv.apiHistoryExport(w, httptest.NewRequest(http.MethodGet, "/api/history/export", nil))
+	if body := strings.TrimSpace(w.Body.String()); body != "[]" {
+		t.Errorf("body = %q, want []", body)
+	}
+}
+
+func TestApiHistoryExport_BadParams(t *testing.T) {
+	for _, q := range []string{"format=xml", "from=last-month", "to=2026-13-01", "from=2026-10-01&to=2026-09-01"} {
+		w := httptest.NewRecorder()
+		newExportTestServer().apiHistoryExport(w, httptest.NewRequest(http.MethodGet, "/api/history/export?"+q, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", q, w.Code)
+		}
+	}
+}
@@ -320,7 +320,7 @@ func (s *Server) apiUpdate(w http.ResponseWriter, r *http.Request) {
 					s.deps.Log.Error("panic in remote update goroutine", "name", name, "host", hostID, "panic", rec)
 				}
 			}()
-			if err := s.deps.Cluster.UpdateRemoteContainer(context.Background(), hostID, name, targetImage, ""); err != nil {
+			if _, err := s.deps.Cluster.UpdateRemoteContainer(context.Background(), hostID, name, targetImage, ""); err != nil {
 				s.deps.Log.Error("remote update failed", "name", name, "host", hostID, "error", err)
 				s.deps.EventBus.Publish(events.SSEEvent{
 					Type:          events.EventContainerUpdate,
@@ -466,9 +466,11 @@ func (s *Server) apiUpdate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var containerID string
+	var labels map[string]string
 	for _, c := range containers {
 		if containerName(c) == name {
 			containerID = c.ID
+			labels = c.Labels
 			break
 		}
 	}
@@ -478,6 +480,12 @@ func (s *Server) apiUpdate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Refuse up front rather than failing after the old container is gone.
+	if reason := s.updateBlocked(r.Context(), name, labels); reason != "" {
+		writeErrorCode(w, http.StatusConflict, CodeUpdateBlocked, reason, map[string]string{"reason": reason})
+		return
+	}
+
 	// Build target image: look up the queue for a newer version (semver bump).
 	// Without this, the updater re-pulls the current tag instead of the newer version.
 	targetImage := ""
@@ -641,22 +649,7 @@ func (s *Server) apiCheck(w http.ResponseWriter, r *http.Request) {
 		}
 
 		if updateAvailable {
-			if len(newerVersions) > 0 && s.deps.IgnoredVersions != nil {
-				ignored, _ := s.deps.IgnoredVersions.GetIgnoredVersions(name)
-				if len(ignored) > 0 {
-					ignoredSet := make(map[string]bool, len(ignored))
-					for _, v := range ignored {
-						ignoredSet[v] = true
-					}
-					var filtered []string
-					for _, v := range newerVersions {
-						if !ignoredSet[v] {
-							filtered = append(filtered, v)
-						}
-					}
-					newerVersions = filtered
-				}
-			}
+			newerVersions = s.filterIgnoredVersions(name, newerVersions)
 
 			if len(newerVersions) == 0 {
 				writeJSON(w, http.StatusOK, map[string]any{
@@ -736,22 +729,7 @@ func (s *Server) apiCheck(w http.ResponseWriter, r *http.Request) {
 
 	if updateAvailable {
 		// Filter out ignored versions before queuing.
-		if len(newerVersions) > 0 && s.deps.IgnoredVersions != nil {
-			ignored, _ := s.deps.IgnoredVersions.GetIgnoredVersions(name)
-			if len(ignored) > 0 {
-				ignoredSet := make(map[string]bool, len(ignored))
-				for _, v := range ignored {
-					ignoredSet[v] = true
-				}
-				var filtered []string
-				for _, v := range newerVersions {
-					if !ignoredSet[v] {
-						filtered = append(filtered, v)
-					}
-				}
-				newerVersions = filtered
-			}
-		}
+		newerVersions = s.filterIgnoredVersions(name, newerVersions)
 
 		if len(newerVersions) == 0 {
 			writeJSON(w, http.StatusOK, map[string]any{
@@ -787,6 +765,29 @@ func (s *Server) apiCheck(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// filterIgnoredVersions drops versions the user has chosen to ignore for a
+// container.
+func (s *Server) filterIgnoredVersions(name string, versions []string) []string {
+	if len(versions) == 0 || s.deps.IgnoredVersions == nil {
+		return versions
+	}
+	ignored, _ := s.deps.IgnoredVersions.GetIgnoredVersions(name)
+	if len(ignored) == 0 {
+		return versions
+	}
+	ignoredSet := make(map[string]bool, len(ignored))
+	for _, v := range ignored {
+		ignoredSet[v] = true
+	}
+	var filtered []string
+	for _, v := range versions {
+		if !ignoredSet[v] {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered
+}
+
 // apiSelfUpdate triggers a self-update via an ephemeral helper container.
 func (s *Server) apiSelfUpdate(w http.ResponseWriter, r *http.Request) {
 	if s.deps.SelfUpdater == nil {
@@ -890,7 +891,7 @@ func (s *Server) apiUpdateToVersion(w http.ResponseWriter, r *http.Request) {
 					s.deps.Log.Error("panic in remote version-update goroutine", "name", name, "host", hostID, "tag", body.Tag, "panic", rec)
 				}
 			}()
-			if err := s.deps.Cluster.UpdateRemoteContainer(context.Background(), hostID, name, targetImage, ""); err != nil {
+			if _, err := s.deps.Cluster.UpdateRemoteContainer(context.Background(), hostID, name, targetImage, ""); err != nil {
 				s.deps.Log.Error("remote version update failed", "name", name, "host", hostID, "tag", body.Tag, "error", err)
 				s.deps.EventBus.Publish(events.SSEEvent{
 					Type:          events.EventContainerUpdate,
@@ -0,0 +1,207 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+)
+
+// killSignals are the signals the kill endpoint accepts. SIGKILL is the
+// default; the rest let a process shut down or reload on its own terms.
+var killSignals = map[string]bool{
+	"SIGKILL": true,
+	"SIGTERM": true,
+	"SIGINT":  true,
+	"SIGQUIT": true,
+	"SIGHUP":  true,
+	"SIGUSR1": true,
+	"SIGUSR2": true,
+}
+
+// parseKillSignal normalises a signal name ("term", "SIGTERM") and reports
+// whether it is allowed. Empty means SIGKILL.
+func parseKillSignal(sig string) (string, bool) {
+	sig = strings.ToUpper(strings.TrimSpace(sig))
+	if sig == "" {
+		return "SIGKILL", true
+	}
+	if !strings.HasPrefix(sig, "SIG") {
+		sig = "SIG" + sig
+	}
+	return sig, killSignals[sig]
+}
+
+// containerAction is a state change applied to a local container, or
+// forwarded to a cluster agent when the request names a host.
+type containerAction struct {
+	verb   string // audit event type, e.g. "pause"
+	remote string // action sent to cluster agents
+	status string // response status, e.g. "pausing"
+	done   string // past tense for messages, e.g. "paused"
+	run    func(ctx context.Context, id string) error
+}
+
+// runContainerAction routes act to the named container and responds once it
+// has been started; the outcome arrives over SSE.
+func (s *Server) runContainerAction(w http.ResponseWriter, r *http.Request, name string, act containerAction) {
+	hostID := r.URL.Query().Get("host")
+	if hostID != "" && !strings.HasPrefix(hostID, "portainer:") && s.deps.Cluster != nil && s.deps.Cluster.Enabled() {
+		go func() {
+			if err := s.deps.Cluster.RemoteContainerAction(context.Background(), hostID, name, act.remote); err != nil {
+				s.deps.Log.Error("remote "+act.verb+" failed", "name", name, "host", hostID, "error", err)
+				s.deps.EventBus.Publish(events.SSEEvent{
+					Type:          events.EventContainerState,
+					ContainerName: name,
+					HostID:        hostID,
+					Message:       act.verb + " failed on " + hostID + ": " + err.Error(),
+					Timestamp:     time.Now(),
+				})
+			}
+			// Success event is published by handleContainerActionResult.
+		}()
+		s.logEvent(r, act.verb, name, "Container "+act.done+" on "+hostID)
+		writeJSON(w, http.StatusOK, map[string]string{"status": act.status, "name": name, "message": act.verb + " initiated for " + name})
+		return
+	}
+
+	containers, err := s.deps.Docker.ListAllContainers(r.Context())
+	if err != nil {
+		s.deps.Log.Error("failed to list containers for "+act.verb, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to list containers")
+		return
+	}
+
+	var containerID string
+	for _, c := range containers {
+		if containerName(c) == name {
+			containerID = c.ID
+			break
+		}
+	}
+
+	if containerID == "" {
+		writeError(w, http.StatusNotFound, "container not found: "+name)
+		return
+	}
+
+	go func() {
+		if err := act.run(context.Background(), containerID); err != nil {
+			s.deps.Log.Error(act.verb+" failed", "name", name, "error", err)
+			s.deps.EventBus.Publish(events.SSEEvent{
+				Type:          events.EventContainerState,
+				ContainerName: name,
+				Message:       act.verb + " failed: " + err.Error(),
+				Timestamp:     time.Now(),
+			})
+			return
+		}
+		s.deps.EventBus.Publish(events.SSEEvent{
+			Type:          events.EventContainerState,
+			ContainerName: name,
+			Message:       "Container " + act.done + ": " + name,
+			Timestamp:     time.Now(),
+		})
+	}()
+
+	s.logEvent(r, act.verb, name, "Container "+act.done)
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"status":  act.status,
+		"name":    name,
+		"message": act.verb + " initiated for " + name,
+	})
+}
+
+// apiPause freezes a container's processes by name.
+func (s *Server) apiPause(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if !isValidContainerName(name) {
+		writeError(w, http.StatusBadRequest, "invalid container name")
+		return
+	}
+
+	if s.isProtectedContainer(r.Context(), name) {
+		writeError(w, http.StatusForbidden, "cannot pause sentinel itself via the dashboard")
+		return
+	}
+
+	if s.deps.Pauser == nil {
+		writeError(w, http.StatusNotImplemented, "pause not available")
+		return
+	}
+
+	s.runContainerAction(w, r, name, containerAction{
+		verb: "pause", remote: "pause", status: "pausing", done: "paused",
+		run: s.deps.Pauser.PauseContainer,
+	})
+}
+
+// apiUnpause resumes a paused container by name.
+func (s *Server) apiUnpause(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if !isValidContainerName(name) {
+		writeError(w, http.StatusBadRequest, "invalid container name")
+		return
+	}
+
+	if s.isProtectedContainer(r.Context(), name) {
+		writeError(w, http.StatusForbidden, "cannot unpause sentinel itself via the dashboard")
+		return
+	}
+
+	if s.deps.Pauser == nil {
+		writeError(w, http.StatusNotImplemented, "unpause not available")
+		return
+	}
+
+	s.runContainerAction(w, r, name, containerAction{
+		verb: "unpause", remote: "unpause", status: "unpausing", done: "unpaused",
+		run: s.deps.Pauser.UnpauseContainer,
+	})
+}
+
+// apiKill sends a signal to a container by name. The optional JSON body
+// {"signal": "SIGTERM"} picks the signal; the default is SIGKILL.
+func (s *Server) apiKill(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if !isValidContainerName(name) {
+		writeError(w, http.StatusBadRequest, "invalid container name")
+		return
+	}
+
+	var req struct {
+		Signal string `json:"signal"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	signal, ok := parseKillSignal(req.Signal)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "unsupported signal: "+req.Signal)
+		return
+	}
+
+	if s.isProtectedContainer(r.Context(), name) {
+		writeError(w, http.StatusForbidden, "cannot kill sentinel itself via the dashboard")
+		return
+	}
+
+	if s.deps.Killer == nil {
+		writeError(w, http.StatusNotImplemented, "kill not available")
+		return
+	}
+
+	s.runContainerAction(w, r, name, containerAction{
+		verb: "kill", remote: "kill:" + signal, status: "killing", done: "sent " + signal,
+		run: func(ctx context.Context, id string) error {
+			return s.deps.Killer.KillContainer(ctx, id, signal)
+		},
+	})
+}
@@ -0,0 +1,145 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type mockPauser struct {
+	paused   chan string
+	unpaused chan string
+}
+
+func newMockPauser() *mockPauser {
+	return &mockPauser{paused: make(chan string, 1), unpaused: make(chan string, 1)}
+}
+
+func (m *mockPauser) PauseContainer(_ context.Context, id string) error {
+	m.paused <- id
+	return nil
+}
+
+func (m *mockPauser) UnpauseContainer(_ context.Context, id string) error {
+	m.unpaused <- id
+	return nil
+}
+
+type mockKiller struct {
+	signals chan string
+}
+
+func (m *mockKiller) KillContainer(_ context.Context, _, signal string) error {
+	m.signals <- signal
+	return nil
+}
+
+func newSignalTestServer() (*Server, *mockPauser, *mockKiller) {
+	docker := &mockContainerLister{
+		containers: []ContainerSummary{
+			{ID: "abc123", Names: []string{"/nginx"}, State: "running"},
+			{ID: "self1", Names: []string{"/sentinel"}, Labels: map[string]string{"sentinel.self": "true"}, State: "running"},
+		},
+	}
+	srv := newControlTestServer(docker, nil, nil, nil)
+	pauser := newMockPauser()
+	killer := &mockKiller{signals: make(chan string, 1)}
+	srv.deps.Pauser = pauser
+	srv.deps.Killer = killer
+	return srv, pauser, killer
+}
+
+func receive(t *testing.T, ch chan string) string {
+	t.Helper()
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(2 * time.Second):
+		t.Fatal("action was not run")
+		return ""
+	}
+}
+
+func TestApiPauseUnpause(t *testing.T) {
+	srv, pauser, _ := newSignalTestServer()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/containers/nginx/pause", nil)
+	r.SetPathValue("name", "nginx")
+	srv.apiPause(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("pause status = %d, body: %s", w.Code, w.Body.String())
+	}
+	if id := receive(t, pauser.paused); id != "abc123" {
+		t.Errorf("paused %q, want abc123", id)
+	}
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodPost, "/api/containers/nginx/unpause", nil)
+	r.SetPathValue("name", "nginx")
+	srv.apiUnpause(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("unpause status = %d, body: %s", w.Code, w.Body.String())
+	}
+	if id := receive(t, pauser.unpaused); id != "abc123" {
+		t.Errorf("unpaused %q, want abc123", id)
+	}
+}
+
+func TestApiPause_SelfProtected(t *testing.T) {
+	srv, _, _ := newSignalTestServer()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/containers/sentinel/pause", nil)
+	r.SetPathValue("name", "sentinel")
+	srv.apiPause(w, r)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want 403", w.Code)
+	}
+}
+
+func TestApiKill(t *testing.T) {
+	tests := []struct {
+		body       string
+		wantStatus int
+		wantSignal string
+	}{
+		{"", http.StatusOK, "SIGKILL"},
+		{`{"signal":"term"}`, http.StatusOK, "SIGTERM"},
+		{`{"signal":"SIGHUP"}`, http.StatusOK, "SIGHUP"},
+		{`{"signal":"SIGSEGV"}`, http.StatusBadRequest, ""},
+		{`{bad`, http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		srv, _, killer := newSignalTestServer()
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/containers/nginx/kill", strings.NewReader(tt.body))
+		r.SetPathValue("name", "nginx")
+		srv.apiKill(w, r)
+		if w.Code != tt.wantStatus {
+			t.Errorf("body %q: status = %d, want %d", tt.body, w.Code, tt.wantStatus)
+			continue
+		}
+		if tt.wantSignal != "" {
+			if got := receive(t, killer.signals); got != tt.wantSignal {
+				t.Errorf("body %q: signal = %q, want %q", tt.body, got, tt.wantSignal)
+			}
+		}
+	}
+}
+
+func TestApiKill_NotConfigured(t *testing.T) {
+	srv, _, _ := newSignalTestServer()
+	srv.deps.Killer = nil
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/containers/nginx/kill", nil)
+	r.SetPathValue("name", "nginx")
+	srv.apiKill(w, r)
+	if w.Code != http.StatusNotImplemented {
+		t.Fatalf("status = %d, want 501", w.Code)
+	}
+}
@@ -0,0 +1,370 @@
+package web
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/auth"
+	"github.com/Will-Luck/Docker-Sentinel/internal/engine"
+	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+)
+
+const (
+	// deployTokenPrefix marks deploy hook tokens so they are not mistaken
+	// for API tokens (stk_).
+	deployTokenPrefix = "sdt_"
+
+	// maxIdempotencyKeyLen bounds the Idempotency-Key header.
+	maxIdempotencyKeyLen = 128
+)
+
+// allowsContainer reports whether the token is scoped to the container.
+// Scopes are container names or path.Match patterns such as "api-*".
+func (t DeployToken) allowsContainer(name string) bool {
+	for _, pattern := range t.Containers {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// generateDeployToken returns a new plaintext deploy token and its hash.
+func generateDeployToken() (plaintext, hash string, err error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", err
+	}
+	plaintext = deployTokenPrefix + base64.RawURLEncoding.EncodeToString(raw)
+	return plaintext, auth.HashToken(plaintext), nil
+}
+
+// deployTokenFor returns the stored token matching plaintext, comparing
+// hashes in constant time.
+func (s *Server) deployTokenFor(plaintext string) (DeployToken, bool) {
+	if !strings.HasPrefix(plaintext, deployTokenPrefix) {
+		return DeployToken{}, false
+	}
+	tokens, err := s.deps.DeployTokens.ListDeployTokens()
+	if err != nil {
+		s.deps.Log.Error("failed to list deploy tokens", "error", err)
+		return DeployToken{}, false
+	}
+	hash := []byte(auth.HashToken(plaintext))
+	for _, t := range tokens {
+		if subtle.ConstantTimeCompare(hash, []byte(t.TokenHash)) == 1 {
+			return t, true
+		}
+	}
+	return DeployToken{}, false
+}
+
+// apiDeployHook pulls and recreates one container on behalf of a CI
+// pipeline, so Sentinel can act as a lightweight deploy target. The caller
+// authenticates with a deploy token (Authorization: Bearer sdt_...) that
+// must be scoped to the container. An optional JSON body {"tag": "..."}
+// deploys another tag of the same image; without it the current tag is
+// re-pulled. A repeated Idempotency-Key is answered from the first call
+// without deploying again, unless that deploy failed.
+func (s *Server) apiDeployHook(w http.ResponseWriter, r *http.Request) {
+	if s.deps.DeployTokens == nil {
+		writeError(w, http.StatusNotImplemented, "deploy hooks not available")
+		return
+	}
+
+	plaintext := auth.ExtractBearerToken(r.Header.Get("Authorization"))
+	if plaintext == "" {
+		writeError(w, http.StatusUnauthorized, "missing deploy token")
+		return
+	}
+	token, ok := s.deployTokenFor(plaintext)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "invalid deploy token")
+		return
+	}
+
+	name := r.PathValue("container")
+	if !isValidContainerName(name) {
+		writeError(w, http.StatusBadRequest, "invalid container name")
+		return
+	}
+	if !token.allowsContainer(name) {
+		writeError(w, http.StatusForbidden, "deploy token is not scoped to "+name)
+		return
+	}
+
+	var body struct {
+		Tag string `json:"tag"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, 64<<10)).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if body.Tag != "" && !validTag.MatchString(body.Tag) {
+		writeError(w, http.StatusBadRequest, "invalid tag")
+		return
+	}
+
+	key := r.Header.Get("Idempotency-Key")
+	if len(key) > maxIdempotencyKeyLen {
+		writeError(w, http.StatusBadRequest, "Idempotency-Key is too long")
+		return
+	}
+	if key != "" {
+		prev, found, err := s.deps.DeployTokens.GetDeployRequest(token.ID, key)
+		if err != nil {
+			s.deps.Log.Error("failed to load deploy request", "error", err)
+			writeError(w, http.StatusInternalServerError, "failed to load deploy request")
+			return
+		}
+		if found {
+			replayDeploy(w, name, prev)
+			return
+		}
+	}
+
+	if s.isProtectedContainer(r.Context(), name) {
+		writeError(w, http.StatusForbidden, "cannot deploy sentinel itself")
+		return
+	}
+
+	containers, err := s.deps.Docker.ListAllContainers(r.Context())
+	if err != nil {
+		s.deps.Log.Error("failed to list containers for deploy hook", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to list containers")
+		return
+	}
+	var target *ContainerSummary
+	for i := range containers {
+		if containerName(containers[i]) == name {
+			target = &containers[i]
+			break
+		}
+	}
+	if target == nil {
+		writeError(w, http.StatusNotFound, "container not found: "+name)
+		return
+	}
+
+	if reason := s.updateBlocked(r.Context(), name, target.Labels); reason != "" {
+		writeErrorCode(w, http.StatusConflict, CodeUpdateBlocked, reason, map[string]string{"reason": reason})
+		return
+	}
+	if s.deps.Updater.IsUpdating(name) {
+		writeError(w, http.StatusConflict, "update already in progress for "+name)
+		return
+	}
+
+	// An empty target re-pulls the current tag, which is what a CI build
+	// pushing to a moving tag such as "latest" needs.
+	targetImage := ""
+	image := target.Image
+	if body.Tag != "" {
+		targetImage = webReplaceTag(target.Image, body.Tag)
+		image = targetImage
+	}
+
+	now := time.Now().UTC()
+	if key != "" {
+		// Claiming checks and records the key in one step: a concurrent
+		// delivery of the same key may have got here first.
+		req := DeployRequest{TokenID: token.ID, Key: key, Container: name, TargetImage: image, CreatedAt: now}
+		held, claimed, err := s.deps.DeployTokens.ClaimDeployRequest(req)
+		if err != nil {
+			s.deps.Log.Error("failed to save deploy request", "error", err)
+			writeError(w, http.StatusInternalServerError, "failed to save deploy request")
+			return
+		}
+		if !claimed {
+			replayDeploy(w, name, held)
+			return
+		}
+	}
+	token.LastUsedAt = now
+	if err := s.deps.DeployTokens.SaveDeployToken(token); err != nil {
+		s.deps.Log.Warn("failed to record deploy token use", "token", token.Name, "error", err)
+	}
+
+	containerID := target.ID
+	go func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				s.deps.Log.Error("panic in deploy hook goroutine", "name", name, "panic", rec)
+			}
+		}()
+		err := s.deps.Updater.UpdateContainer(context.Background(), containerID, name, targetImage)
+		if err != nil && key != "" {
+			// Nothing was deployed, so a retry with this key must run.
+			if relErr := s.deps.DeployTokens.ReleaseDeployRequest(token.ID, key); relErr != nil {
+				s.deps.Log.Warn("failed to release deploy request", "name", name, "error", relErr)
+			}
+		}
+		if errors.Is(err, engine.ErrUpdateInProgress) {
+			s.deps.Log.Warn("deploy skipped, update already in progress", "name", name)
+			return
+		}
+		if err != nil {
+			s.deps.Log.Error("deploy hook update failed", "name", name, "token", token.Name, "error", err)
+			s.deps.EventBus.Publish(events.SSEEvent{
+				Type:          events.EventContainerUpdate,
+				ContainerName: name,
+				Message:       "deploy failed: " + err.Error(),
+				Timestamp:     time.Now(),
+			})
+		}
+	}()
+
+	// Use nil request: deploy hooks are not session-authenticated.
+	s.logEvent(nil, "update", name, "Deploy hook triggered by token \""+token.Name+"\": "+image)
+
+	writeJSON(w, http.StatusAccepted, map[string]any{
+		"status":  "started",
+		"name":    name,
+		"image":   image,
+		"message": "deploy started for " + name,
+	})
+}
+
+// apiListDeployTokens returns the deploy tokens without their hashes.
+func (s *Server) apiListDeployTokens(w http.ResponseWriter, _ *http.Request) {
+	if s.deps.DeployTokens == nil {
+		writeError(w, http.StatusNotImplemented, "deploy hooks not available")
+		return
+	}
+	tokens, err := s.deps.DeployTokens.ListDeployTokens()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to load deploy tokens")
+		return
+	}
+	if tokens == nil {
+		tokens = []DeployToken{}
+	}
+	writeJSON(w, http.StatusOK, tokens)
+}
+
+// apiCreateDeployToken creates a deploy token scoped to the given
+// containers. The plaintext token is returned once and never stored.
+func (s *Server) apiCreateDeployToken(w http.ResponseWriter, r *http.Request) {
+	if s.deps.DeployTokens == nil {
+		writeError(w, http.StatusNotImplemented, "deploy hooks not available")
+		return
+	}
+	var req struct {
+		Name       string   `json:"name"`
+		Containers []string `json:"containers"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Name) > 64 {
+		writeError(w, http.StatusBadRequest, "name must be 1-64 characters")
+		return
+	}
+	var scopes []string
+	for _, c := range req.Containers {
+		c = strings.TrimSpace(c)
+		if c == "" {
+			continue
+		}
+		// Patterns may use * and ? on top of the container name characters.
+		if !isValidContainerName(strings.NewReplacer("*", "a", "?", "a").Replace(c)) {
+			writeError(w, http.StatusBadRequest, "invalid container scope: "+c)
+			return
+		}
+		scopes = append(scopes, c)
+	}
+	if len(scopes) == 0 {
+		writeError(w, http.StatusBadRequest, "at least one container is required")
+		return
+	}
+
+	plaintext, hash, err := generateDeployToken()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to generate token")
+		return
+	}
+	id, err := auth.GenerateTokenID()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to generate token")
+		return
+	}
+	token := DeployToken{
+		ID:         id,
+		Name:       req.Name,
+		TokenHash:  hash,
+		Containers: scopes,
+		CreatedAt:  time.Now().UTC(),
+	}
+	if err := s.deps.DeployTokens.SaveDeployToken(token); err != nil {
+		s.deps.Log.Error("failed to save deploy token", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to save deploy token")
+		return
+	}
+	auditState(r, nil, token)
+
+	s.logEvent(r, "settings", "", "Deploy token created: "+req.Name+" ("+strings.Join(scopes, ", ")+")")
+	writeJSON(w, http.StatusOK, map[string]any{
+		"id":         token.ID,
+		"name":       token.Name,
+		"containers": token.Containers,
+		"created_at": token.CreatedAt,
+		"token":      plaintext,
+	})
+}
+
+// apiDeleteDeployToken revokes a deploy token.
+func (s *Server) apiDeleteDeployToken(w http.ResponseWriter, r *http.Request) {
+	if s.deps.DeployTokens == nil {
+		writeError(w, http.StatusNotImplemented, "deploy hooks not available")
+		return
+	}
+	id := r.PathValue("id")
+	if id == "" {
+		writeError(w, http.StatusBadRequest, "missing token id")
+		return
+	}
+	var old any
+	if tokens, err := s.deps.DeployTokens.ListDeployTokens(); err == nil {
+		for _, t := range tokens {
+			if t.ID == id {
+				old = t
+			}
+		}
+	}
+	if err := s.deps.DeployTokens.DeleteDeployToken(id); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to delete deploy token")
+		return
+	}
+	auditState(r, old, nil)
+	s.logEvent(r, "settings", "", "Deploy token revoked: "+id)
+	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+// replayDeploy answers a deploy hook call whose Idempotency-Key was already
+// used: the first call's result for the same container, or 422 when the
+// key was used for another one.
+func replayDeploy(w http.ResponseWriter, name string, prev DeployRequest) {
+	if prev.Container != name {
+		writeError(w, http.StatusUnprocessableEntity, "Idempotency-Key was already used for "+prev.Container)
+		return
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	writeJSON(w, http.StatusAccepted, map[string]any{
+		"status":   "started",
+		"name":     name,
+		"image":    prev.TargetImage,
+		"replayed": true,
+		"message":  "deploy of " + name + " already started for this Idempotency-Key",
+	})
+}
@@ -0,0 +1,284 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+)
+
+// memDeployTokens is an in-memory DeployTokenStore.
+type memDeployTokens struct {
+	mu       sync.Mutex
+	tokens   map[string]DeployToken
+	requests map[string]DeployRequest
+}
+
+func newMemDeployTokens() *memDeployTokens {
+	return &memDeployTokens{tokens: map[string]DeployToken{}, requests: map[string]DeployRequest{}}
+}
+
+func (m *memDeployTokens) ListDeployTokens() ([]DeployToken, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []DeployToken
+	for _, t := range m.tokens {
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+func (m *memDeployTokens) SaveDeployToken(t DeployToken) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tokens[t.ID] = t
+	return nil
+}
+
+func (m *memDeployTokens) DeleteDeployToken(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.tokens, id)
+	return nil
+}
+
+func (m *memDeployTokens) GetDeployRequest(tokenID, key string) (DeployRequest, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r, ok := m.requests[tokenID+"::"+key]
+	return r, ok, nil
+}
+
+func (m *memDeployTokens) ClaimDeployRequest(r DeployRequest) (DeployRequest, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if held, ok := m.requests[r.TokenID+"::"+r.Key]; ok {
+		return held, false, nil
+	}
+	m.requests[r.TokenID+"::"+r.Key] = r
+	return r, true, nil
+}
+
+func (m *memDeployTokens) ReleaseDeployRequest(tokenID, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.requests, tokenID+"::"+key)
+	return nil
+}
+
+// deployUpdater records the target image of each UpdateContainer call.
+// With failNext set, the next call fails.
+type deployUpdater struct {
+	recordingUpdater
+	targets  chan string
+	failNext atomic.Bool
+}
+
+func (m *deployUpdater) UpdateContainer(_ context.Context, _, name, target string) error {
+	m.targets <- name + "=" + target
+	if m.failNext.CompareAndSwap(true, false) {
+		return errors.New("pull failed")
+	}
+	return nil
+}
+
+func TestApiDeployHook(t *testing.T) {
+	tokens := newMemDeployTokens()
+	updater := &deployUpdater{targets: make(chan string, 4)}
+	srv := &Server{deps: Dependencies{
+		DeployTokens: tokens,
+		Docker: &mockContainerLister{containers: []ContainerSummary{
+			{ID: "1", Names: []string{"/web"}, Image: "registry.local/web:1.0"},
+			{ID: "2", Names: []string{"/api-v1"}, Image: "registry.local/api:1.0"},
+			{ID: "3", Names: []string{"/db"}, Image: "postgres:16"},
+		}},
+		Updater:  updater,
+		EventBus: events.New(),
+		Log:      slog.Default(),
+	}}
+
+	// Create a token through the settings API.
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/settings/deploy-tokens", strings.NewReader(`{"name":"ci","containers":["web","api-*"]}`))
+	srv.apiCreateDeployToken(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("create: status = %d; body: %s", w.Code, w.Body.String())
+	}
+	var created struct {
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil || !strings.HasPrefix(created.Token, deployTokenPrefix) {
+		t.Fatalf("created token = %q, %v", created.Token, err)
+	}
+
+	deploy := func(token, container, key, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/hooks/deploy/"+container, strings.NewReader(body))
+		r.SetPathValue("container", container)
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		if key != "" {
+			r.Header.Set("Idempotency-Key", key)
+		}
+		srv.apiDeployHook(w, r)
+		return w
+	}
+	waitTarget := func(want string) {
+		t.Helper()
+		select {
+		case got := <-updater.targets:
+			if got != want {
+				t.Errorf("UpdateContainer = %q, want %q", got, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("UpdateContainer not called, want %q", want)
+		}
+	}
+
+	if w := deploy("", "web", "", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("no token: status = %d, want 401", w.Code)
+	}
+	if w := deploy("sdt_wrong", "web", "", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("wrong token: status = %d, want 401", w.Code)
+	}
+	if w := deploy(created.Token, "db", "", ""); w.Code != http.StatusForbidden {
+		t.Errorf("out of scope: status = %d, want 403", w.Code)
+	}
+	if w := deploy(created.Token, "web", "", `{"tag":"bad tag"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("bad tag: status = %d, want 400", w.Code)
+	}
+
+	// No body re-pulls the current tag.
+	if w := deploy(created.Token, "web", "", ""); w.Code != http.StatusAccepted {
+		t.Fatalf("deploy: status = %d; body: %s", w.Code, w.Body.String())
+	}
+	waitTarget("web=")
+
+	// Pattern scope and tag override.
+	if w := deploy(created.Token, "api-v1", "build-7", `{"tag":"1.1"}`); w.Code != http.StatusAccepted {
+		t.Fatalf("deploy tag: status = %d; body: %s", w.Code, w.Body.String())
+	}
+	waitTarget("api-v1=registry.local/api:1.1")
+
+	// Replaying the key does not deploy again.
+	w = deploy(created.Token, "api-v1", "build-7", `{"tag":"1.1"}`)
+	if w.Code != http.StatusAccepted || w.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("replay: status = %d, replayed header %q", w.Code, w.Header().Get("Idempotent-Replayed"))
+	}
+	if w := deploy(created.Token, "web", "build-7", ""); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("key reuse for another container: status = %d, want 422", w.Code)
+	}
+	select {
+	case got := <-updater.targets:
+		t.Errorf("unexpected deploy %q", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Concurrent deliveries of one key deploy exactly once.
+	var wg sync.WaitGroup
+	var started atomic.Int32
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := deploy(created.Token, "web", "build-9", "")
+			if w.Code == http.StatusAccepted && w.Header().Get("Idempotent-Replayed") == "" {
+				started.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := started.Load(); n != 1 {
+		t.Errorf("concurrent deliveries started %d deploys, want 1", n)
+	}
+	waitTarget("web=")
+	select {
+	case got := <-updater.targets:
+		t.Errorf("unexpected deploy %q", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// The list never exposes the hash, and usage is recorded.
+	w = httptest.NewRecorder()
+	srv.apiListDeployTokens(w, httptest.NewRequest(http.MethodGet, "/api/settings/deploy-tokens", nil))
+	if strings.Contains(w.Body.String(), "hash") || !strings.Contains(w.Body.String(), `"api-*"`) {
+		t.Errorf("list body = %s", w.Body.String())
+	}
+	list, _ := tokens.ListDeployTokens()
+	if len(list) != 1 || list[0].LastUsedAt.IsZero() {
+		t.Errorf("tokens = %+v, want one with LastUsedAt set", list)
+	}
+}
+
+func TestApiDeployHookRetriesFailedDeploy(t *testing.T) {
+	tokens := newMemDeployTokens()
+	plaintext, hash, err := generateDeployToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens.tokens["t1"] = DeployToken{ID: "t1", Name: "ci", TokenHash: hash, Containers: []string{"web"}}
+	updater := &deployUpdater{targets: make(chan string, 4)}
+	srv := &Server{deps: Dependencies{
+		DeployTokens: tokens,
+		Docker: &mockContainerLister{containers: []ContainerSummary{
+			{ID: "1", Names: []string{"/web"}, Image: "registry.local/web:1.0"},
+		}},
+		Updater:  updater,
+		EventBus: events.New(),
+		Log:      slog.Default(),
+	}}
+	deploy := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/hooks/deploy/web", strings.NewReader(`{"tag":"1.1"}`))
+		r.SetPathValue("container", "web")
+		r.Header.Set("Authorization", "Bearer "+plaintext)
+		r.Header.Set("Idempotency-Key", "build-3")
+		srv.apiDeployHook(w, r)
+		return w
+	}
+
+	updater.failNext.Store(true)
+	if w := deploy(); w.Code != http.StatusAccepted {
+		t.Fatalf("deploy: status = %d; body: %s", w.Code, w.Body.String())
+	}
+	<-updater.targets
+	// The key is released once the failed update returns.
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, held, _ := tokens.GetDeployRequest("t1", "build-3"); !held {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("key still held after the deploy failed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	w := deploy()
+	if w.Code != http.StatusAccepted || w.Header().Get("Idempotent-Replayed") != "" {
+		t.Fatalf("retry: status = %d, replayed header %q", w.Code, w.Header().Get("Idempotent-Replayed"))
+	}
+	select {
+	case got := <-updater.targets:
+		if got != "web=registry.local/web:1.1" {
+			t.Errorf("retry deployed %q", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("retry did not deploy")
+	}
+
+	// The successful retry holds the key again.
+	if w := deploy(); w.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("after success: replayed header %q, want true", w.Header().Get("Idempotent-Replayed"))
+	}
+}
@@ -0,0 +1,59 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/deps"
+)
+
+func TestApiGetDepsGraph(t *testing.T) {
+	compose := func(service, dependsOn string) map[string]string {
+		return map[string]string{
+			"com.docker.compose.project":    "shop",
+			"com.docker.compose.service":    service,
+			"com.docker.compose.depends_on": dependsOn,
+		}
+	}
+	srv := &Server{deps: Dependencies{
+		Docker: &mockContainerLister{containers: []ContainerSummary{
+			{ID: "db1", Names: []string{"/shop-db-1", "/legacy/db"}, Labels: compose("db", "")},
+			{ID: "api1", Names: []string{"/shop-api-1"}, Labels: compose("api", "db:service_healthy:false")},
+			{ID: "legacy1", Names: []string{"/legacy"}},
+			{ID: "vpn1", Names: []string{"/vpn"}},
+			{ID: "dl1", Names: []string{"/downloader"}, NetworkMode: "container:vpn1"},
+		}},
+	}}
+
+	w := httptest.NewRecorder()
+	srv.apiGetDepsGraph(w, httptest.NewRequest(http.MethodGet, "/api/deps/graph", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	var got struct {
+		Nodes []struct {
+			Name    string `json:"name"`
+			Service string `json:"service"`
+		} `json:"nodes"`
+		Edges  []deps.Edge `json:"edges"`
+		Order  []string    `json:"order"`
+		Cycles [][]string  `json:"cycles"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := []deps.Edge{
+		{From: "downloader", To: "vpn", Kind: deps.KindNetwork},
+		{From: "legacy", To: "shop-db-1", Kind: deps.KindLink},
+		{From: "shop-api-1", To: "shop-db-1", Kind: deps.KindCompose},
+	}
+	if !reflect.DeepEqual(got.Edges, want) {
+		t.Errorf("edges = %+v, want %+v", got.Edges, want)
+	}
+	if len(got.Nodes) != 5 || len(got.Order) != 5 || got.Cycles == nil {
+		t.Errorf("nodes = %d, order = %v, cycles = %v", len(got.Nodes), got.Order, got.Cycles)
+	}
+}
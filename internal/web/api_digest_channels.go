@@ -0,0 +1,195 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/engine"
+	"github.com/Will-Luck/Docker-Sentinel/internal/notify"
+)
+
+// apiGetDigestChannels returns the per-channel digest schedules.
+func (s *Server) apiGetDigestChannels(w http.ResponseWriter, r *http.Request) {
+	list := []engine.ChannelDigest{}
+	if s.deps.SettingsStore != nil {
+		raw, _ := s.deps.SettingsStore.LoadSetting("digest_channels")
+		parsed, err := engine.ParseChannelDigests(raw)
+		if err != nil {
+			s.deps.Log.Warn("invalid stored digest channel schedules", "error", err)
+		} else if parsed != nil {
+			list = parsed
+		}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// apiSaveDigestChannels replaces the per-channel digest schedules.
+// Body: [{"channel_id", "time", "weekday", "policies", "stacks"}, ...]
+// An empty list returns every channel to the global digest.
+func (s *Server) apiSaveDigestChannels(w http.ResponseWriter, r *http.Request) {
+	if s.deps.SettingsStore == nil {
+		writeError(w, http.StatusNotImplemented, "settings store not available")
+		return
+	}
+	raw, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 64<<10))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "failed to read request body")
+		return
+	}
+	list, err := engine.ParseChannelDigests(string(raw))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if s.deps.NotifyConfig != nil && len(list) > 0 {
+		chans, err := s.deps.NotifyConfig.GetNotificationChannels()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to load notification channels")
+			return
+		}
+		for _, cd := range list {
+			if !slices.ContainsFunc(chans, func(c notify.Channel) bool { return c.ID == cd.ChannelID }) {
+				writeError(w, http.StatusBadRequest, "unknown channel: "+cd.ChannelID)
+				return
+			}
+		}
+	}
+
+	value := ""
+	if len(list) > 0 {
+		data, err := json.Marshal(list)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to encode schedules")
+			return
+		}
+		value = string(data)
+	}
+	if err := s.deps.SettingsStore.SaveSetting("digest_channels", value); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to save digest channel schedules")
+		return
+	}
+
+	if s.deps.Digest != nil {
+		s.deps.Digest.SetDigestConfig()
+	}
+
+	s.logEvent(r, "settings", "", "Digest channel schedules updated")
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok", "message": "digest channel schedules saved"})
+}
+
+// apiPreviewDigest renders the digest that would be sent now. ?channel=
+// previews a specific channel with its inclusion rules applied. The digest
+// covers every container, so scoped users can't preview it.
+func (s *Server) apiPreviewDigest(w http.ResponseWriter, r *http.Request) {
+	if !unscopedOnly(w, r) {
+		return
+	}
+	if s.deps.Digest == nil {
+		writeError(w, http.StatusNotImplemented, "digest scheduler not available")
+		return
+	}
+	channelID := r.URL.Query().Get("channel")
+	names, msg, err := s.deps.Digest.PreviewDigest(r.Context(), channelID)
+	if err != nil {
+		if errors.Is(err, engine.ErrDigestChannelNotFound) {
+			writeError(w, http.StatusNotFound, "notification channel not found")
+			return
+		}
+		s.deps.Log.Error("digest preview failed", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to build digest preview")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"channel":    channelID,
+		"containers": names,
+		"count":      len(names),
+		"message":    msg,
+	})
+}
+
+// summaryFailureWindow is how far back an on-demand summary looks for
+// failed updates.
+const summaryFailureWindow = 24 * time.Hour
+
+// apiSendSummary sends the current dashboard state (pending updates, recent
+// failures, offline hosts) to one channel now, rendered as a digest.
+func (s *Server) apiSendSummary(w http.ResponseWriter, r *http.Request) {
+	if s.deps.Digest == nil {
+		writeError(w, http.StatusNotImplemented, "digest scheduler not available")
+		return
+	}
+	var body struct {
+		ChannelID string `json:"channel_id"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, 4096)).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if body.ChannelID == "" {
+		writeError(w, http.StatusBadRequest, "channel_id is required")
+		return
+	}
+
+	now := time.Now()
+	failures := s.recentFailures(now.Add(-summaryFailureWindow))
+	var offline []string
+	for _, h := range s.offlineAgents(now) {
+		offline = append(offline, h.name)
+	}
+
+	msg, err := s.deps.Digest.SendSummary(r.Context(), body.ChannelID, failures, offline)
+	switch {
+	case errors.Is(err, engine.ErrDigestChannelNotFound):
+		writeError(w, http.StatusNotFound, "notification channel not found")
+		return
+	case errors.Is(err, engine.ErrDigestChannelDisabled):
+		writeError(w, http.StatusConflict, "notification channel is disabled")
+		return
+	case err != nil:
+		s.deps.Log.Error("on-demand summary failed", "channel", body.ChannelID, "error", err)
+		writeError(w, http.StatusBadGateway, "failed to send summary: "+err.Error())
+		return
+	}
+
+	s.logEvent(r, "digest", "", "Summary sent on demand")
+	writeJSON(w, http.StatusOK, map[string]any{
+		"status":   "ok",
+		"message":  "summary sent",
+		"summary":  msg,
+		"failures": len(failures),
+		"offline":  len(offline),
+	})
+}
+
+// recentFailures returns the containers whose updates failed or were rolled
+// back since from, newest first, each listed once.
+func (s *Server) recentFailures(from time.Time) []string {
+	if s.deps.Store == nil {
+		return nil
+	}
+	var out []string
+	seen := make(map[string]bool)
+	err := s.deps.Store.ForEachHistory(from, time.Time{}, func(r UpdateRecord) error {
+		if r.Outcome != "failed" && r.Outcome != "rollback" {
+			return nil
+		}
+		label := r.ContainerName
+		if r.HostName != "" {
+			label += " on " + r.HostName
+		}
+		if !seen[label] {
+			seen[label] = true
+			out = append(out, label)
+		}
+		return nil
+	})
+	if err != nil {
+		s.deps.Log.Warn("failed to read history for summary", "error", err)
+	}
+	return out
+}
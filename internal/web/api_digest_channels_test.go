@@ -0,0 +1,128 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/engine"
+)
+
+// mockDigestController implements DigestController for testing.
+type mockDigestController struct {
+	reconfigured bool
+	preview      map[string][]string
+
+	summaryChannel  string
+	summaryFailures []string
+	summaryOffline  []string
+}
+
+func (m *mockDigestController) SetDigestConfig()              { m.reconfigured = true }
+func (m *mockDigestController) TriggerDigest(context.Context) {}
+func (m *mockDigestController) LastRunTime() time.Time        { return time.Time{} }
+func (m *mockDigestController) PreviewDigest(_ context.Context, id string) ([]string, string, error) {
+	names, ok := m.preview[id]
+	if !ok {
+		return nil, "", engine.ErrDigestChannelNotFound
+	}
+	return names, "Pending updates: " + strings.Join(names, ", "), nil
+}
+func (m *mockDigestController) SendSummary(_ context.Context, id string, failures, offline []string) (string, error) {
+	if _, ok := m.preview[id]; !ok {
+		return "", engine.ErrDigestChannelNotFound
+	}
+	m.summaryChannel, m.summaryFailures, m.summaryOffline = id, failures, offline
+	return "No pending updates", nil
+}
+
+func TestApiPreviewDigest(t *testing.T) {
+	dc := &mockDigestController{preview: map[string][]string{"": {"a", "b"}, "ntfy": {"a"}}}
+	srv := &Server{deps: Dependencies{Digest: dc, Log: slog.New(slog.NewTextHandler(io.Discard, nil))}}
+
+	w := httptest.NewRecorder()
+	srv.apiPreviewDigest(w, httptest.NewRequest(http.MethodGet, "/api/digest/preview?channel=ntfy", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	var got struct {
+		Containers []string `json:"containers"`
+		Count      int      `json:"count"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Count != 1 || got.Containers[0] != "a" {
+		t.Errorf("preview = %+v, want [a]", got)
+	}
+
+	w = httptest.NewRecorder()
+	srv.apiPreviewDigest(w, httptest.NewRequest(http.MethodGet, "/api/digest/preview?channel=nope", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("unknown channel status = %d, want 404", w.Code)
+	}
+}
+
+func TestApiSaveDigestChannels(t *testing.T) {
+	ss := newMockSettingsStore()
+	dc := &mockDigestController{}
+	srv := &Server{deps: Dependencies{SettingsStore: ss, Digest: dc, Log: slog.New(slog.NewTextHandler(io.Discard, nil))}}
+
+	body := `[{"channel_id":"ntfy","time":"09:00","weekday":"monday","policies":["manual"]}]`
+	w := httptest.NewRecorder()
+	srv.apiSaveDigestChannels(w, httptest.NewRequest(http.MethodPut, "/api/settings/digest/channels", strings.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	if !dc.reconfigured {
+		t.Error("expected scheduler to be reconfigured")
+	}
+	if !strings.Contains(ss.data["digest_channels"], `"weekday":"monday"`) {
+		t.Errorf("stored = %q", ss.data["digest_channels"])
+	}
+
+	w = httptest.NewRecorder()
+	srv.apiSaveDigestChannels(w, httptest.NewRequest(http.MethodPut, "/api/settings/digest/channels", strings.NewReader(`[{"channel_id":"x","weekday":"funday"}]`)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("invalid weekday status = %d, want 400", w.Code)
+	}
+}
+
+func TestApiSendSummary(t *testing.T) {
+	now := time.Now()
+	history := newMockHistoryStore()
+	history.records = []UpdateRecord{
+		{Timestamp: now.Add(-time.Hour), ContainerName: "web", Outcome: "failed"},
+		{Timestamp: now.Add(-2 * time.Hour), ContainerName: "web", Outcome: "rollback"},
+		{Timestamp: now.Add(-3 * time.Hour), ContainerName: "db", Outcome: "success"},
+		{Timestamp: now.Add(-48 * time.Hour), ContainerName: "old", Outcome: "failed"},
+	}
+	dc := &mockDigestController{preview: map[string][]string{"ntfy": nil}}
+	srv := &Server{deps: Dependencies{Digest: dc, Store: history, Log: slog.New(slog.NewTextHandler(io.Discard, nil))}}
+
+	w := httptest.NewRecorder()
+	srv.apiSendSummary(w, httptest.NewRequest(http.MethodPost, "/api/digest/send-summary", strings.NewReader(`{"channel_id":"ntfy"}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	if dc.summaryChannel != "ntfy" || len(dc.summaryFailures) != 1 || dc.summaryFailures[0] != "web" {
+		t.Errorf("sent to %q with failures %v, want ntfy with [web]", dc.summaryChannel, dc.summaryFailures)
+	}
+
+	for body, want := range map[string]int{
+		`{"channel_id":"nope"}`: http.StatusNotFound,
+		`{}`:                    http.StatusBadRequest,
+	} {
+		w = httptest.NewRecorder()
+		srv.apiSendSummary(w, httptest.NewRequest(http.MethodPost, "/api/digest/send-summary", strings.NewReader(body)))
+		if w.Code != want {
+			t.Errorf("%s: status = %d, want %d", body, w.Code, want)
+		}
+	}
+}
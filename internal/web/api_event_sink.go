@@ -0,0 +1,42 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/lifecycle"
+)
+
+// apiSetEventSink configures the machine event sink that receives every
+// update state transition. Body: {"url": "...", "format": "ndjson"|"cloudevents"}.
+// An empty URL disables the sink.
+func (s *Server) apiSetEventSink(w http.ResponseWriter, r *http.Request) {
+	var req lifecycle.Config
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+	if err := lifecycle.ValidateConfig(req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if s.deps.SettingsStore == nil {
+		writeError(w, http.StatusNotImplemented, "settings store not available")
+		return
+	}
+
+	for key, val := range map[string]string{"event_sink_url": req.URL, "event_sink_format": req.Format} {
+		if err := s.deps.SettingsStore.SaveSetting(key, val); err != nil {
+			s.deps.Log.Error("failed to save "+key, "error", err)
+			writeError(w, http.StatusInternalServerError, "failed to save setting")
+			return
+		}
+	}
+
+	msg := "Event sink disabled"
+	if req.URL != "" {
+		msg = "Event sink set to " + req.URL
+	}
+	s.logEvent(r, "settings", "", msg)
+	writeJSON(w, http.StatusOK, map[string]string{"message": "event sink updated"})
+}
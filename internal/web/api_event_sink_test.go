@@ -0,0 +1,36 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestApiSetEventSink(t *testing.T) {
+	ss := newMockSettingsStore()
+	srv := newTestServer(ss)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/settings/event-sink", strings.NewReader(`{"url":"https://flows.example.com/hook","format":"cloudevents"}`))
+	srv.apiSetEventSink(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	if got, _ := ss.LoadSetting("event_sink_url"); got != "https://flows.example.com/hook" {
+		t.Errorf("event_sink_url = %q", got)
+	}
+	if got, _ := ss.LoadSetting("event_sink_format"); got != "cloudevents" {
+		t.Errorf("event_sink_format = %q, want cloudevents", got)
+	}
+
+	for _, body := range []string{`{"url":"ftp://example.com"}`, `{"url":"https://example.com","format":"xml"}`} {
+		w = httptest.NewRecorder()
+		r = httptest.NewRequest(http.MethodPost, "/api/settings/event-sink", strings.NewReader(body))
+		srv.apiSetEventSink(w, r)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("body %s: status = %d, want 400", body, w.Code)
+		}
+	}
+}
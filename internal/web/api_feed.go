@@ -1,6 +1,7 @@
 package web
 
 import (
+	"context"
 	"encoding/xml"
 	"fmt"
 	"net/http"
@@ -58,9 +59,12 @@ func (s *Server) apiHistoryFeed(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusUnauthorized, "invalid or expired token")
 			return
 		}
+		// The feed sits outside the auth middleware; attach the token's
+		// context so its owner's container scope applies.
+		r = r.WithContext(context.WithValue(r.Context(), auth.ContextKey, rc))
 	}
 
-	records, err := s.deps.Store.ListHistory(50, "")
+	records, err := s.scopedHistory(r, 50, "")
 	if err != nil {
 		s.deps.Log.Error("failed to list history for feed", "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to load history")
@@ -70,6 +70,7 @@ func (s *feedSessionStore) GetSession(_ string) (*auth.Session, error)
 func (s *feedSessionStore) DeleteSession(_ string) error                         { return nil }
 func (s *feedSessionStore) DeleteSessionsForUser(_ string) error                 { return nil }
 func (s *feedSessionStore) ListSessionsForUser(_ string) ([]auth.Session, error) { return nil, nil }
+func (s *feedSessionStore) ListSessions() ([]auth.Session, error)                { return nil, nil }
 func (s *feedSessionStore) DeleteExpiredSessions() (int, error)                  { return 0, nil }
 
 type feedSettingsReader struct{}
@@ -125,6 +126,9 @@ func (s *feedHistoryStore) ListHistory(limit int, _ string) ([]UpdateRecord, err
 }
 
 func (s *feedHistoryStore) ListAllHistory() ([]UpdateRecord, error) { return s.records, nil }
+func (s *feedHistoryStore) ForEachHistory(_, _ time.Time, _ func(UpdateRecord) error) error {
+	return nil
+}
 func (s *feedHistoryStore) ListHistoryByContainer(_ string, _ int) ([]UpdateRecord, error) {
 	return nil, nil
 }
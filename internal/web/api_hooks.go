@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
 
 	"github.com/Will-Luck/Docker-Sentinel/internal/deps"
 	"github.com/Will-Luck/Docker-Sentinel/internal/store"
@@ -75,15 +76,31 @@ func (s *Server) apiSaveHook(w http.ResponseWriter, r *http.Request) {
 		Command:       body.Command,
 		Timeout:       body.Timeout,
 	}
+	old := s.currentHook(storeKey, body.Phase)
 	if err := s.deps.HookStore.SaveHook(entry); err != nil {
 		s.deps.Log.Error("failed to save hook", "container", name, "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to save hook")
 		return
 	}
+	auditState(r, old, entry)
 	s.logEvent(r, "hooks", name, fmt.Sprintf("Saved %s hook", body.Phase))
 	writeJSON(w, http.StatusOK, entry)
 }
 
+// currentHook returns the hook stored for storeKey and phase, or nil.
+func (s *Server) currentHook(storeKey, phase string) *HookEntry {
+	hooks, err := s.deps.HookStore.ListHooks(storeKey)
+	if err != nil {
+		return nil
+	}
+	for _, h := range hooks {
+		if h.Phase == phase {
+			return &h
+		}
+	}
+	return nil
+}
+
 // apiDeleteHook removes a hook for a container.
 func (s *Server) apiDeleteHook(w http.ResponseWriter, r *http.Request) {
 	name := r.PathValue("container")
@@ -99,40 +116,70 @@ func (s *Server) apiDeleteHook(w http.ResponseWriter, r *http.Request) {
 	// For remote containers, scope the key by host to avoid collisions.
 	hostID := r.URL.Query().Get("host")
 	storeKey := store.ScopedKey(hostID, name)
+	old := s.currentHook(storeKey, phase)
 	if err := s.deps.HookStore.DeleteHook(storeKey, phase); err != nil {
 		s.deps.Log.Error("failed to delete hook", "container", name, "phase", phase, "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to delete hook")
 		return
 	}
+	if old != nil {
+		auditState(r, old, nil)
+	}
 	s.logEvent(r, "hooks", name, fmt.Sprintf("Deleted %s hook", phase))
 	writeJSON(w, http.StatusOK, map[string]string{"message": "hook deleted"})
 }
 
-// apiGetDeps returns the full dependency graph.
-func (s *Server) apiGetDeps(w http.ResponseWriter, r *http.Request) {
+// dependencyInfos lists local containers, and those of connected cluster
+// hosts as "hostID::name", as dependency graph input. Scoped users only
+// get the containers in their scope.
+func (s *Server) dependencyInfos(r *http.Request) ([]deps.ContainerInfo, error) {
 	containers, err := s.deps.Docker.ListAllContainers(r.Context())
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to list containers")
-		return
+		return nil, err
 	}
 	infos := make([]deps.ContainerInfo, 0, len(containers))
 	for _, c := range containers {
 		infos = append(infos, deps.ContainerInfo{
-			Name:   containerName(c),
-			Labels: c.Labels,
+			Name:        containerName(c),
+			ID:          c.ID,
+			Labels:      c.Labels,
+			NetworkMode: c.NetworkMode,
+			Names:       c.Names,
 		})
 	}
 
-	// Include remote containers from cluster agents.
+	// Include remote containers so cross-host dependencies are visible.
 	if s.deps.Cluster != nil && s.deps.Cluster.Enabled() {
 		for _, rc := range s.deps.Cluster.AllHostContainers() {
 			infos = append(infos, deps.ContainerInfo{
 				Name:   rc.HostID + "::" + rc.Name,
+				Host:   rc.HostID,
 				Labels: rc.Labels,
 			})
 		}
 	}
 
+	if requestScope(r) != nil {
+		allowed := s.scopeFilter(r)
+		visible := infos[:0]
+		for _, ci := range infos {
+			if allowed(ci.Name) {
+				visible = append(visible, ci)
+			}
+		}
+		infos = visible
+	}
+	return infos, nil
+}
+
+// apiGetDeps returns the full dependency graph.
+func (s *Server) apiGetDeps(w http.ResponseWriter, r *http.Request) {
+	infos, err := s.dependencyInfos(r)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list containers")
+		return
+	}
+
 	graph := deps.Build(infos)
 	order, sortErr := graph.Sort()
 	cycles := graph.DetectCycles()
@@ -161,6 +208,51 @@ func (s *Server) apiGetDeps(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, resp)
 }
 
+// apiGetDepsGraph returns the whole dependency graph as nodes and typed
+// edges (label, compose, link or network), with the topological order
+// dependents restart in and any cycles.
+func (s *Server) apiGetDepsGraph(w http.ResponseWriter, r *http.Request) {
+	infos, err := s.dependencyInfos(r)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list containers")
+		return
+	}
+
+	type node struct {
+		Name    string `json:"name"`
+		Host    string `json:"host,omitempty"`
+		Project string `json:"project,omitempty"`
+		Service string `json:"service,omitempty"`
+	}
+	nodes := make([]node, 0, len(infos))
+	for _, ci := range infos {
+		nodes = append(nodes, node{
+			Name:    ci.Name,
+			Host:    ci.Host,
+			Project: ci.Labels["com.docker.compose.project"],
+			Service: ci.Labels["com.docker.compose.service"],
+		})
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+
+	graph := deps.Build(infos)
+	order, sortErr := graph.Sort()
+	cycles := graph.DetectCycles()
+	if cycles == nil {
+		cycles = [][]string{}
+	}
+	resp := map[string]any{
+		"nodes":  nodes,
+		"edges":  graph.Edges(),
+		"order":  order,
+		"cycles": cycles,
+	}
+	if sortErr != nil {
+		resp["error"] = sortErr.Error()
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
 // apiGetContainerDeps returns dependencies for a single container.
 func (s *Server) apiGetContainerDeps(w http.ResponseWriter, r *http.Request) {
 	name := r.PathValue("container")
@@ -175,28 +267,11 @@ func (s *Server) apiGetContainerDeps(w http.ResponseWriter, r *http.Request) {
 		lookupName = hostID + "::" + name
 	}
 
-	containers, err := s.deps.Docker.ListAllContainers(r.Context())
+	infos, err := s.dependencyInfos(r)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "failed to list containers")
 		return
 	}
-	infos := make([]deps.ContainerInfo, 0, len(containers))
-	for _, c := range containers {
-		infos = append(infos, deps.ContainerInfo{
-			Name:   containerName(c),
-			Labels: c.Labels,
-		})
-	}
-
-	// Include remote containers so cross-host dependencies are visible.
-	if s.deps.Cluster != nil && s.deps.Cluster.Enabled() {
-		for _, rc := range s.deps.Cluster.AllHostContainers() {
-			infos = append(infos, deps.ContainerInfo{
-				Name:   rc.HostID + "::" + rc.Name,
-				Labels: rc.Labels,
-			})
-		}
-	}
 
 	graph := deps.Build(infos)
 	writeJSON(w, http.StatusOK, map[string]any{
@@ -2,9 +2,12 @@ package web
 
 import (
 	"net/http"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/auth"
 )
 
-// apiListImages returns all Docker images as JSON.
+// apiListImages returns all Docker images as JSON. Scoped users only see
+// images used by their own containers.
 func (s *Server) apiListImages(w http.ResponseWriter, r *http.Request) {
 	if s.deps.ImageManager == nil {
 		writeError(w, http.StatusServiceUnavailable, "image management not available")
@@ -15,9 +18,35 @@ func (s *Server) apiListImages(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, "failed to list images: "+err.Error())
 		return
 	}
+	if scope := requestScope(r); scope != nil {
+		images = s.scopedImages(r, scope, images)
+	}
 	writeJSON(w, http.StatusOK, map[string]any{"images": images})
 }
 
+// scopedImages keeps the images tagged as the image of a container in the
+// request's scope.
+func (s *Server) scopedImages(r *http.Request, scope *auth.ContainerScope, images []ImageInfo) []ImageInfo {
+	refs := make(map[string]bool)
+	if containers, err := s.deps.Docker.ListAllContainers(r.Context()); err == nil {
+		for _, c := range containers {
+			if scope.Matches(containerName(c), c.Labels) {
+				refs[c.Image] = true
+			}
+		}
+	}
+	out := []ImageInfo{}
+	for _, img := range images {
+		for _, tag := range img.RepoTags {
+			if refs[tag] {
+				out = append(out, img)
+				break
+			}
+		}
+	}
+	return out
+}
+
 // apiPruneImages removes dangling (unused, untagged) images.
 func (s *Server) apiPruneImages(w http.ResponseWriter, r *http.Request) {
 	if s.deps.ImageManager == nil {
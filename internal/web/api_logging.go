@@ -0,0 +1,91 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/logging"
+)
+
+// Log level overrides are always temporary: they reset after the requested
+// duration, defaulting to logOverrideDefault and capped at logOverrideMax.
+const (
+	logOverrideDefault = 30 * time.Minute
+	logOverrideMax     = 24 * time.Hour
+)
+
+// apiGetLogLevels returns the base log level, the effective level of each
+// component and any active overrides with their reset time.
+func (s *Server) apiGetLogLevels(w http.ResponseWriter, _ *http.Request) {
+	if s.deps.LogLevels == nil {
+		writeError(w, http.StatusNotImplemented, "log levels not available")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.deps.LogLevels.State())
+}
+
+// apiSetLogLevels temporarily overrides component log levels.
+// Body: {"levels": {"engine": "debug"}, "duration": "30m"}. The overrides
+// replace any active ones; an empty levels map resets every component to
+// the base level now.
+func (s *Server) apiSetLogLevels(w http.ResponseWriter, r *http.Request) {
+	if s.deps.LogLevels == nil {
+		writeError(w, http.StatusNotImplemented, "log levels not available")
+		return
+	}
+	var body struct {
+		Levels   map[string]string `json:"levels"`
+		Duration string            `json:"duration"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	levels := make(map[string]slog.Level, len(body.Levels))
+	for name, value := range body.Levels {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !logging.IsComponent(name) {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown component %q (use %s)", name, strings.Join(logging.Components, ", ")))
+			return
+		}
+		lvl, err := logging.ParseLevel(value)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		levels[name] = lvl
+	}
+
+	d := logOverrideDefault
+	if body.Duration != "" {
+		parsed, err := time.ParseDuration(body.Duration)
+		if err != nil || parsed <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid duration")
+			return
+		}
+		if parsed > logOverrideMax {
+			writeError(w, http.StatusBadRequest, "duration must be at most "+logOverrideMax.String())
+			return
+		}
+		d = parsed
+	}
+
+	s.deps.LogLevels.Override(levels, d)
+	if len(levels) == 0 {
+		s.logEvent(r, "settings", "", "Log level overrides cleared")
+	} else {
+		parts := make([]string, 0, len(levels))
+		for name := range levels {
+			parts = append(parts, name+"="+strings.ToLower(levels[name].String()))
+		}
+		slices.Sort(parts)
+		s.logEvent(r, "settings", "", fmt.Sprintf("Log levels set to %s for %s", strings.Join(parts, ", "), d))
+	}
+	writeJSON(w, http.StatusOK, s.deps.LogLevels.State())
+}
@@ -0,0 +1,51 @@
+package web
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/logging"
+)
+
+func TestApiSetLogLevels(t *testing.T) {
+	levels := logging.NewLevels(slog.LevelInfo)
+	srv := &Server{deps: Dependencies{LogLevels: levels, Log: slog.New(slog.NewTextHandler(io.Discard, nil))}}
+
+	w := httptest.NewRecorder()
+	srv.apiSetLogLevels(w, httptest.NewRequest(http.MethodPut, "/api/settings/logging", strings.NewReader(`{"levels":{"Engine":"debug"},"duration":"10m"}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	var st logging.LevelState
+	if err := json.Unmarshal(w.Body.Bytes(), &st); err != nil {
+		t.Fatal(err)
+	}
+	if st.Components["engine"] != "debug" || st.Components["web"] != "info" || st.ResetAt.IsZero() {
+		t.Errorf("state = %+v, want engine at debug until a reset time", st)
+	}
+
+	for _, body := range []string{
+		`{"levels":{"kernel":"debug"}}`,
+		`{"levels":{"engine":"loud"}}`,
+		`{"levels":{"engine":"debug"},"duration":"48h"}`,
+		`{"levels":{"engine":"debug"},"duration":"-1m"}`,
+	} {
+		w = httptest.NewRecorder()
+		srv.apiSetLogLevels(w, httptest.NewRequest(http.MethodPut, "/api/settings/logging", strings.NewReader(body)))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", body, w.Code)
+		}
+	}
+
+	// An empty map resets now.
+	w = httptest.NewRecorder()
+	srv.apiSetLogLevels(w, httptest.NewRequest(http.MethodPut, "/api/settings/logging", strings.NewReader(`{"levels":{}}`)))
+	if w.Code != http.StatusOK || levels.Level(logging.ComponentEngine) != slog.LevelInfo {
+		t.Errorf("reset: status = %d, engine level = %v", w.Code, levels.Level(logging.ComponentEngine))
+	}
+}
@@ -3,11 +3,14 @@ package web
 import (
 	"encoding/json"
 	"net/http"
+	"slices"
+	"strings"
 
 	"github.com/Will-Luck/Docker-Sentinel/internal/notify"
 )
 
-// apiGetNotifyTemplates returns all custom notification templates.
+// apiGetNotifyTemplates returns the custom notification templates that apply
+// to every channel.
 func (s *Server) apiGetNotifyTemplates(w http.ResponseWriter, _ *http.Request) {
 	if s.deps.NotifyTemplateStore == nil {
 		writeJSON(w, http.StatusOK, map[string]any{"templates": map[string]string{}})
@@ -18,10 +21,7 @@ func (s *Server) apiGetNotifyTemplates(w http.ResponseWriter, _ *http.Request) {
 		writeError(w, http.StatusInternalServerError, "failed to load templates")
 		return
 	}
-	if templates == nil {
-		templates = make(map[string]string)
-	}
-	writeJSON(w, http.StatusOK, map[string]any{"templates": templates})
+	writeJSON(w, http.StatusOK, map[string]any{"templates": notify.Templates(templates).Global()})
 }
 
 // apiSaveNotifyTemplate saves a custom notification template for an event type.
@@ -38,6 +38,10 @@ func (s *Server) apiSaveNotifyTemplate(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "event_type is required")
 		return
 	}
+	if strings.Contains(req.EventType, ":") {
+		writeError(w, http.StatusBadRequest, "invalid event_type")
+		return
+	}
 
 	// Validate the template by doing a test render.
 	if req.Template != "" {
@@ -56,6 +60,7 @@ func (s *Server) apiSaveNotifyTemplate(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, "failed to save template")
 		return
 	}
+	s.reloadNotifyTemplates()
 	s.logEvent(r, "settings", "", "notification template updated: "+req.EventType)
 	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
 }
@@ -75,6 +80,7 @@ func (s *Server) apiDeleteNotifyTemplate(w http.ResponseWriter, r *http.Request)
 		writeError(w, http.StatusInternalServerError, "failed to delete template")
 		return
 	}
+	s.reloadNotifyTemplates()
 	s.logEvent(r, "settings", "", "notification template reset: "+eventType)
 	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
 }
@@ -100,3 +106,188 @@ func (s *Server) apiPreviewNotifyTemplate(w http.ResponseWriter, r *http.Request
 	}
 	writeJSON(w, http.StatusOK, map[string]any{"preview": result})
 }
+
+// notifyTemplates loads the custom templates for building notifiers. A
+// failed load is logged and the default formats are used.
+func (s *Server) notifyTemplates() notify.Templates {
+	if s.deps.NotifyTemplateStore == nil {
+		return nil
+	}
+	templates, err := s.deps.NotifyTemplateStore.GetAllNotifyTemplates()
+	if err != nil {
+		s.deps.Log.Warn("failed to load notification templates", "error", err)
+		return nil
+	}
+	return templates
+}
+
+// reloadNotifyTemplates rebuilds the notifier chain so saved template
+// changes apply to the next notification.
+func (s *Server) reloadNotifyTemplates() {
+	if s.deps.NotifyConfig == nil {
+		return
+	}
+	channels, err := s.deps.NotifyConfig.GetNotificationChannels()
+	if err != nil {
+		s.deps.Log.Warn("failed to load notification channels", "error", err)
+		return
+	}
+	s.rebuildNotifiers(channels)
+}
+
+// notifyChannel resolves the {id} path value to a saved channel, writing a
+// 404 when there is none.
+func (s *Server) notifyChannel(w http.ResponseWriter, r *http.Request) (notify.Channel, bool) {
+	id := r.PathValue("id")
+	if s.deps.NotifyConfig == nil {
+		writeError(w, http.StatusNotFound, "channel not found")
+		return notify.Channel{}, false
+	}
+	channels, err := s.deps.NotifyConfig.GetNotificationChannels()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to load notification channels")
+		return notify.Channel{}, false
+	}
+	idx := slices.IndexFunc(channels, func(ch notify.Channel) bool { return ch.ID == id })
+	if idx < 0 {
+		writeError(w, http.StatusNotFound, "channel not found")
+		return notify.Channel{}, false
+	}
+	return channels[idx], true
+}
+
+// validEventType reports whether t is an event type notifications are sent for.
+func validEventType(t string) bool {
+	return slices.Contains(notify.AllEventTypes(), notify.EventType(t))
+}
+
+// apiGetChannelTemplates returns a channel's own templates by event type.
+// Event types without one use the global template, then the default format.
+func (s *Server) apiGetChannelTemplates(w http.ResponseWriter, r *http.Request) {
+	ch, ok := s.notifyChannel(w, r)
+	if !ok {
+		return
+	}
+	if s.deps.NotifyTemplateStore == nil {
+		writeJSON(w, http.StatusOK, map[string]any{"templates": map[string]string{}})
+		return
+	}
+	templates, err := s.deps.NotifyTemplateStore.GetAllNotifyTemplates()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to load templates")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"templates": notify.Templates(templates).Channel(ch.ID)})
+}
+
+// apiSaveChannelTemplate saves a channel's template for one event type. An
+// empty template removes it.
+func (s *Server) apiSaveChannelTemplate(w http.ResponseWriter, r *http.Request) {
+	ch, ok := s.notifyChannel(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		EventType string `json:"event_type"`
+		Template  string `json:"template"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if !validEventType(req.EventType) {
+		writeError(w, http.StatusBadRequest, "unknown event_type: "+req.EventType)
+		return
+	}
+	if _, err := notify.RenderPreview(req.Template, req.EventType); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid template: "+err.Error())
+		return
+	}
+	if s.deps.NotifyTemplateStore == nil {
+		writeError(w, http.StatusServiceUnavailable, "template store not available")
+		return
+	}
+
+	key := notify.ChannelTemplateKey(ch.ID, req.EventType)
+	var err error
+	if req.Template == "" {
+		err = s.deps.NotifyTemplateStore.DeleteNotifyTemplate(key)
+	} else {
+		err = s.deps.NotifyTemplateStore.SaveNotifyTemplate(key, req.Template)
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to save template")
+		return
+	}
+	s.reloadNotifyTemplates()
+	s.logEvent(r, "settings", "", "notification template updated for "+ch.Name+": "+req.EventType)
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// apiDeleteChannelTemplate removes a channel's template for one event type,
+// reverting to the global template or the default format.
+func (s *Server) apiDeleteChannelTemplate(w http.ResponseWriter, r *http.Request) {
+	ch, ok := s.notifyChannel(w, r)
+	if !ok {
+		return
+	}
+	eventType := r.PathValue("type")
+	if !validEventType(eventType) {
+		writeError(w, http.StatusBadRequest, "unknown event type: "+eventType)
+		return
+	}
+	if s.deps.NotifyTemplateStore == nil {
+		writeError(w, http.StatusServiceUnavailable, "template store not available")
+		return
+	}
+	if err := s.deps.NotifyTemplateStore.DeleteNotifyTemplate(notify.ChannelTemplateKey(ch.ID, eventType)); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to delete template")
+		return
+	}
+	s.reloadNotifyTemplates()
+	s.logEvent(r, "settings", "", "notification template reset for "+ch.Name+": "+eventType)
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// apiPreviewChannelTemplate renders a sample event as the channel would send
+// it. Without a template in the request, the channel's saved template is
+// used, then the global one, then the default format. The response names
+// which one rendered the preview.
+func (s *Server) apiPreviewChannelTemplate(w http.ResponseWriter, r *http.Request) {
+	ch, ok := s.notifyChannel(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		EventType string `json:"event_type"`
+		Template  string `json:"template"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if !validEventType(req.EventType) {
+		writeError(w, http.StatusBadRequest, "unknown event_type: "+req.EventType)
+		return
+	}
+
+	tmpl, source := req.Template, "request"
+	if tmpl == "" {
+		templates := s.notifyTemplates()
+		if t := templates[notify.ChannelTemplateKey(ch.ID, req.EventType)]; t != "" {
+			tmpl, source = t, "channel"
+		} else if t := templates[req.EventType]; t != "" {
+			tmpl, source = t, "global"
+		}
+	}
+	if tmpl == "" {
+		writeJSON(w, http.StatusOK, map[string]any{"preview": notify.DefaultPreview(req.EventType), "source": "default"})
+		return
+	}
+	result, err := notify.RenderPreview(tmpl, req.EventType)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "template error: "+err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"preview": result, "source": source})
+}
@@ -0,0 +1,161 @@
+package web
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/notify"
+)
+
+type mockNotifyTemplateStore struct {
+	templates map[string]string
+}
+
+func (m *mockNotifyTemplateStore) GetAllNotifyTemplates() (map[string]string, error) {
+	return maps.Clone(m.templates), nil
+}
+
+func (m *mockNotifyTemplateStore) SaveNotifyTemplate(key, tmpl string) error {
+	m.templates[key] = tmpl
+	return nil
+}
+
+func (m *mockNotifyTemplateStore) DeleteNotifyTemplate(key string) error {
+	delete(m.templates, key)
+	return nil
+}
+
+func newTemplateTestServer(templates map[string]string) (*Server, *mockNotifyTemplateStore) {
+	srv, _, _ := newNotifyTestServer([]notify.Channel{
+		{ID: "a", Type: notify.ProviderGotify, Name: "Gotify"},
+		{ID: "b", Type: notify.ProviderSlack, Name: "Slack"},
+	})
+	ts := &mockNotifyTemplateStore{templates: templates}
+	srv.deps.NotifyTemplateStore = ts
+	return srv, ts
+}
+
+func TestApiPreviewChannelTemplate(t *testing.T) {
+	srv, _ := newTemplateTestServer(map[string]string{
+		"update_available": "global {{.ContainerName}}",
+		notify.ChannelTemplateKey("a", "update_available"): "channel {{.NewVersion}}",
+	})
+
+	preview := func(id, body string) (int, map[string]string) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/settings/notifications/"+id+"/template/preview", strings.NewReader(body))
+		r.SetPathValue("id", id)
+		srv.apiPreviewChannelTemplate(w, r)
+		var resp map[string]string
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp
+	}
+
+	tests := []struct {
+		name, id, body   string
+		wantCode         int
+		wantPreview      string
+		wantSource       string
+		previewHasPrefix bool
+	}{
+		{"request template", "a", `{"event_type":"update_available","template":"req {{.OldImage}}"}`, http.StatusOK, "req nginx:1.24", "request", false},
+		{"saved channel template", "a", `{"event_type":"update_available"}`, http.StatusOK, "channel 1.25.3", "channel", false},
+		{"global fallback", "b", `{"event_type":"update_available"}`, http.StatusOK, "global nginx", "global", false},
+		{"default format", "b", `{"event_type":"update_failed"}`, http.StatusOK, "Container: nginx", "default", true},
+		{"invalid template", "a", `{"event_type":"update_available","template":"{{.Broken"}`, http.StatusBadRequest, "", "", false},
+		{"unknown event type", "a", `{"event_type":"nope"}`, http.StatusBadRequest, "", "", false},
+		{"unknown channel", "zz", `{"event_type":"update_available"}`, http.StatusNotFound, "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, resp := preview(tt.id, tt.body)
+			if code != tt.wantCode {
+				t.Fatalf("status = %d, want %d (%v)", code, tt.wantCode, resp)
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			if resp["source"] != tt.wantSource {
+				t.Errorf("source = %q, want %q", resp["source"], tt.wantSource)
+			}
+			if tt.previewHasPrefix && !strings.HasPrefix(resp["preview"], tt.wantPreview) ||
+				!tt.previewHasPrefix && resp["preview"] != tt.wantPreview {
+				t.Errorf("preview = %q, want %q", resp["preview"], tt.wantPreview)
+			}
+		})
+	}
+}
+
+func TestApiChannelTemplatesSaveAndList(t *testing.T) {
+	srv, ts := newTemplateTestServer(map[string]string{"update_failed": "global"})
+
+	save := func(body string) int {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPut, "/api/settings/notifications/a/template", strings.NewReader(body))
+		r.SetPathValue("id", "a")
+		srv.apiSaveChannelTemplate(w, r)
+		return w.Code
+	}
+	if code := save(`{"event_type":"update_failed","template":"{{.Broken"}`); code != http.StatusBadRequest {
+		t.Fatalf("invalid template: status = %d, want 400", code)
+	}
+	if code := save(`{"event_type":"update_failed","template":"{{.Error}}"}`); code != http.StatusOK {
+		t.Fatalf("save: status = %d, want 200", code)
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api/settings/notifications/a/template", nil)
+	r.SetPathValue("id", "a")
+	srv.apiGetChannelTemplates(w, r)
+	var resp struct {
+		Templates map[string]string `json:"templates"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Templates) != 1 || resp.Templates["update_failed"] != "{{.Error}}" {
+		t.Errorf("channel templates = %v", resp.Templates)
+	}
+
+	// The global listing leaves channel templates out.
+	w = httptest.NewRecorder()
+	srv.apiGetNotifyTemplates(w, httptest.NewRequest(http.MethodGet, "/api/settings/notifications/templates", nil))
+	resp.Templates = nil
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Templates) != 1 || resp.Templates["update_failed"] != "global" {
+		t.Errorf("global templates = %v", resp.Templates)
+	}
+
+	// An empty template removes the channel's own.
+	if code := save(`{"event_type":"update_failed","template":""}`); code != http.StatusOK {
+		t.Fatalf("clear: status = %d, want 200", code)
+	}
+	if _, ok := ts.templates[notify.ChannelTemplateKey("a", "update_failed")]; ok {
+		t.Error("empty template should delete the channel template")
+	}
+}
+
+func TestApiSaveNotifications_PrunesChannelTemplates(t *testing.T) {
+	srv, ts := newTemplateTestServer(map[string]string{
+		"update_failed": "global",
+		notify.ChannelTemplateKey("a", "update_failed"): "a",
+		notify.ChannelTemplateKey("b", "update_failed"): "b",
+	})
+
+	body := `[{"id":"a","type":"gotify","name":"Gotify","enabled":false,"settings":{}}]`
+	w := httptest.NewRecorder()
+	srv.apiSaveNotifications(w, httptest.NewRequest(http.MethodPut, "/api/settings/notifications", strings.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	want := map[string]string{"update_failed": "global", notify.ChannelTemplateKey("a", "update_failed"): "a"}
+	if !maps.Equal(ts.templates, want) {
+		t.Errorf("templates = %v, want %v", ts.templates, want)
+	}
+}
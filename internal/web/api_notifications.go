@@ -5,8 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
 	"time"
 
+	"github.com/Will-Luck/Docker-Sentinel/internal/engine"
 	"github.com/Will-Luck/Docker-Sentinel/internal/notify"
 	"github.com/Will-Luck/Docker-Sentinel/internal/store"
 )
@@ -48,6 +51,10 @@ func (s *Server) apiSaveNotifications(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusNotImplemented, "notification config not available")
 		return
 	}
+	if err := notify.ValidateGroups(channels); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	// Restore masked secrets from existing saved channels.
 	existing, _ := s.deps.NotifyConfig.GetNotificationChannels()
@@ -67,23 +74,8 @@ func (s *Server) apiSaveNotifications(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Rebuild notifier chain.
-	if s.deps.NotifyReconfigurer != nil {
-		var notifiers []notify.Notifier
-		notifiers = append(notifiers, notify.NewLogNotifier(s.deps.Log))
-		for _, ch := range channels {
-			if !ch.Enabled {
-				continue
-			}
-			n, err := notify.BuildFilteredNotifier(ch)
-			if err != nil {
-				s.deps.Log.Warn("failed to build notifier", "channel", ch.Name, "type", string(ch.Type), "error", err)
-				continue
-			}
-			notifiers = append(notifiers, n)
-		}
-		s.deps.NotifyReconfigurer.Reconfigure(notifiers...)
-	}
+	s.pruneChannelTemplates(existing, channels)
+	s.rebuildNotifiers(channels)
 
 	s.logEvent(r, "settings", "", "Notification configuration updated")
 
@@ -93,7 +85,46 @@ func (s *Server) apiSaveNotifications(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// apiTestNotification sends a test event through the notification chain or a single channel.
+// pruneChannelTemplates deletes the custom templates of channels that were
+// removed from the configuration.
+func (s *Server) pruneChannelTemplates(before, after []notify.Channel) {
+	if s.deps.NotifyTemplateStore == nil {
+		return
+	}
+	templates := s.notifyTemplates()
+	for _, old := range before {
+		if slices.ContainsFunc(after, func(ch notify.Channel) bool { return ch.ID == old.ID }) {
+			continue
+		}
+		for eventType := range templates.Channel(old.ID) {
+			if err := s.deps.NotifyTemplateStore.DeleteNotifyTemplate(notify.ChannelTemplateKey(old.ID, eventType)); err != nil {
+				s.deps.Log.Warn("failed to delete channel template", "channel", old.Name, "event_type", eventType, "error", err)
+			}
+		}
+	}
+}
+
+// rebuildNotifiers replaces the live notifier chain with one built from
+// channels. Group members are reached through their group. The approval
+// receiver is reloaded too, so Telegram button settings take effect at once.
+func (s *Server) rebuildNotifiers(channels []notify.Channel) {
+	if s.approvalReceiver != nil {
+		s.approvalReceiver.Reload(channels)
+	}
+	if s.deps.NotifyReconfigurer == nil {
+		return
+	}
+	built := notify.BuildChannels(channels, channels, func(ch notify.Channel, err error) {
+		s.deps.Log.Warn("failed to build notifier", "channel", ch.Name, "type", string(ch.Type), "error", err)
+	})
+	notify.ApplyTemplates(built, s.notifyTemplates())
+	notifiers := append([]notify.Notifier{notify.NewLogNotifier(s.deps.Log)}, built...)
+	s.deps.NotifyReconfigurer.Reconfigure(notifiers...)
+}
+
+// apiTestNotification sends a test event through the notification chain or a
+// single channel. Testing a group sends to every member and reports the ones
+// that failed.
 func (s *Server) apiTestNotification(w http.ResponseWriter, r *http.Request) {
 	if s.deps.NotifyReconfigurer == nil {
 		writeError(w, http.StatusNotImplemented, "notifications not available")
@@ -122,11 +153,12 @@ func (s *Server) apiTestNotification(w http.ResponseWriter, r *http.Request) {
 		}
 		for _, ch := range channels {
 			if ch.ID == body.ID {
-				n, err := notify.BuildNotifier(ch)
+				n, err := notify.BuildChannel(ch, channels)
 				if err != nil {
 					writeError(w, http.StatusBadRequest, "failed to build notifier: "+err.Error())
 					return
 				}
+				notify.ApplyTemplates([]notify.Notifier{n}, s.notifyTemplates())
 				if err := n.Send(r.Context(), testEvent); err != nil {
 					writeError(w, http.StatusBadGateway, "test failed: "+err.Error())
 					return
@@ -198,7 +230,8 @@ func (s *Server) apiSetNotifyPref(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	var body struct {
-		Mode string `json:"mode"`
+		Mode     string    `json:"mode"`
+		Channels *[]string `json:"channels"` // channel or group IDs; omitted = keep current
 	}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid JSON body")
@@ -219,20 +252,45 @@ func (s *Server) apiSetNotifyPref(w http.ResponseWriter, r *http.Request) {
 	// For remote containers, scope the key by host to avoid collisions.
 	prefKey := store.ScopedKey(r.URL.Query().Get("host"), name)
 
-	if body.Mode == "default" {
-		// "default" means remove override — fall back to global setting.
+	var targets []string
+	if body.Channels != nil {
+		targets = *body.Channels
+	} else if existing, err := s.deps.NotifyState.GetNotifyPref(prefKey); err == nil && existing != nil {
+		targets = existing.Channels
+	}
+
+	if body.Channels != nil && len(targets) > 0 && s.deps.NotifyConfig != nil {
+		channels, err := s.deps.NotifyConfig.GetNotificationChannels()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to load notification channels")
+			return
+		}
+		for _, id := range targets {
+			if !slices.ContainsFunc(channels, func(ch notify.Channel) bool { return ch.ID == id }) {
+				writeError(w, http.StatusBadRequest, "unknown notification channel: "+id)
+				return
+			}
+		}
+	}
+
+	if body.Mode == "default" && len(targets) == 0 {
+		// "default" with no targets means remove override — fall back to global settings.
 		if err := s.deps.NotifyState.DeleteNotifyPref(prefKey); err != nil {
 			writeError(w, http.StatusInternalServerError, "failed to delete notification preference")
 			return
 		}
 	} else {
-		if err := s.deps.NotifyState.SetNotifyPref(prefKey, &NotifyPref{Mode: body.Mode}); err != nil {
+		if err := s.deps.NotifyState.SetNotifyPref(prefKey, &NotifyPref{Mode: body.Mode, Channels: targets}); err != nil {
 			writeError(w, http.StatusInternalServerError, "failed to save notification preference")
 			return
 		}
 	}
-	s.logEvent(r, "notify_pref", name, "Notification mode set to "+body.Mode)
-	writeJSON(w, http.StatusOK, map[string]string{"status": "ok", "mode": body.Mode})
+	msg := "Notification mode set to " + body.Mode
+	if len(targets) > 0 {
+		msg += ", routed to " + strconv.Itoa(len(targets)) + " channel(s)"
+	}
+	s.logEvent(r, "notify_pref", name, msg)
+	writeJSON(w, http.StatusOK, map[string]any{"status": "ok", "mode": body.Mode, "channels": targets})
 }
 
 // apiClearAllNotifyStates resets all notification dedup states, allowing
@@ -264,6 +322,7 @@ func (s *Server) apiGetDigestSettings(w http.ResponseWriter, r *http.Request) {
 		"digest_time":         "09:00",
 		"digest_interval":     "24h",
 		"default_notify_mode": "default",
+		"queue_stale_after":   "",
 	}
 	if s.deps.SettingsStore != nil {
 		for key := range settings {
@@ -278,10 +337,11 @@ func (s *Server) apiGetDigestSettings(w http.ResponseWriter, r *http.Request) {
 // apiSaveDigestSettings saves digest configuration and reconfigures the scheduler.
 func (s *Server) apiSaveDigestSettings(w http.ResponseWriter, r *http.Request) {
 	var body struct {
-		Enabled           *bool  `json:"digest_enabled,omitempty"`
-		Time              string `json:"digest_time,omitempty"`
-		Interval          string `json:"digest_interval,omitempty"`
-		DefaultNotifyMode string `json:"default_notify_mode,omitempty"`
+		Enabled           *bool   `json:"digest_enabled,omitempty"`
+		Time              string  `json:"digest_time,omitempty"`
+		Interval          string  `json:"digest_interval,omitempty"`
+		DefaultNotifyMode string  `json:"default_notify_mode,omitempty"`
+		QueueStaleAfter   *string `json:"queue_stale_after,omitempty"` // e.g. "14d"; "" or "0" disables
 	}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid JSON body")
@@ -314,6 +374,12 @@ func (s *Server) apiSaveDigestSettings(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
+	if body.QueueStaleAfter != nil {
+		if v := *body.QueueStaleAfter; v != "" && v != "0" && engine.QueueStaleAfter(v) == 0 {
+			writeError(w, http.StatusBadRequest, "invalid queue_stale_after duration")
+			return
+		}
+	}
 
 	// All valid — save atomically.
 	if body.Enabled != nil {
@@ -340,6 +406,11 @@ func (s *Server) apiSaveDigestSettings(w http.ResponseWriter, r *http.Request) {
 			s.deps.Log.Warn("failed to save digest setting", "key", "default_notify_mode", "error", err)
 		}
 	}
+	if body.QueueStaleAfter != nil {
+		if err := s.deps.SettingsStore.SaveSetting("queue_stale_after", *body.QueueStaleAfter); err != nil {
+			s.deps.Log.Warn("failed to save digest setting", "key", "queue_stale_after", "error", err)
+		}
+	}
 
 	// Signal digest scheduler to reconfigure.
 	if s.deps.Digest != nil {
@@ -361,12 +432,18 @@ func (s *Server) apiGetAllNotifyPrefs(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, "failed to load notification preferences")
 		return
 	}
-	// Convert to web types.
-	result := make(map[string]map[string]string, len(prefs))
-	for name, p := range prefs {
-		result[name] = map[string]string{"mode": p.Mode}
+	if prefs == nil {
+		prefs = map[string]*NotifyPref{}
 	}
-	writeJSON(w, http.StatusOK, result)
+	if requestScope(r) != nil {
+		allowed := s.scopeFilter(r)
+		for name := range prefs {
+			if !allowed(name) {
+				delete(prefs, name)
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, prefs)
 }
 
 // apiTriggerDigest triggers an immediate digest notification.
@@ -0,0 +1,123 @@
+package web
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/notify"
+)
+
+type mockNotifyConfig struct {
+	channels []notify.Channel
+}
+
+func (m *mockNotifyConfig) GetNotificationChannels() ([]notify.Channel, error) {
+	return m.channels, nil
+}
+
+func (m *mockNotifyConfig) SetNotificationChannels(channels []notify.Channel) error {
+	m.channels = channels
+	return nil
+}
+
+type mockNotifyState struct {
+	prefs map[string]*NotifyPref
+}
+
+func (m *mockNotifyState) GetNotifyPref(name string) (*NotifyPref, error) { return m.prefs[name], nil }
+func (m *mockNotifyState) SetNotifyPref(name string, pref *NotifyPref) error {
+	m.prefs[name] = pref
+	return nil
+}
+func (m *mockNotifyState) DeleteNotifyPref(name string) error {
+	delete(m.prefs, name)
+	return nil
+}
+func (m *mockNotifyState) AllNotifyPrefs() (map[string]*NotifyPref, error) { return m.prefs, nil }
+func (m *mockNotifyState) AllNotifyStates() (map[string]*NotifyState, error) {
+	return nil, nil
+}
+func (m *mockNotifyState) SetNotifyState(string, *NotifyState) error { return nil }
+func (m *mockNotifyState) ClearNotifyState(string) error             { return nil }
+
+func newNotifyTestServer(channels []notify.Channel) (*Server, *mockNotifyConfig, *mockNotifyState) {
+	cfg := &mockNotifyConfig{channels: channels}
+	state := &mockNotifyState{prefs: map[string]*NotifyPref{}}
+	return &Server{deps: Dependencies{
+		NotifyConfig: cfg,
+		NotifyState:  state,
+		Log:          slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}}, cfg, state
+}
+
+func TestApiSaveNotifications_RejectsInvalidGroup(t *testing.T) {
+	srv, cfg, _ := newNotifyTestServer(nil)
+
+	body := `[{"id":"a","type":"gotify","name":"Gotify","enabled":true,"settings":{}},
+		{"id":"g","type":"group","name":"oncall","enabled":true,"settings":{"members":["a","missing"]}}]`
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPut, "/api/settings/notifications", strings.NewReader(body))
+	srv.apiSaveNotifications(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400; body: %s", w.Code, w.Body.String())
+	}
+	if cfg.channels != nil {
+		t.Error("invalid group was saved")
+	}
+
+	body = strings.Replace(body, `,"missing"`, "", 1)
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodPut, "/api/settings/notifications", strings.NewReader(body))
+	srv.apiSaveNotifications(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	if len(cfg.channels) != 2 {
+		t.Errorf("saved %d channels, want 2", len(cfg.channels))
+	}
+}
+
+func TestApiSetNotifyPref_Channels(t *testing.T) {
+	srv, _, state := newNotifyTestServer([]notify.Channel{
+		{ID: "a", Type: notify.ProviderGotify, Name: "Gotify"},
+		{ID: "g", Type: notify.ProviderGroup, Name: "oncall"},
+	})
+
+	post := func(body string) int {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/containers/web/notify-pref", strings.NewReader(body))
+		r.SetPathValue("name", "web")
+		srv.apiSetNotifyPref(w, r)
+		return w.Code
+	}
+
+	if code := post(`{"mode":"default","channels":["zz"]}`); code != http.StatusBadRequest {
+		t.Fatalf("unknown channel: status = %d, want 400", code)
+	}
+
+	if code := post(`{"mode":"default","channels":["g"]}`); code != http.StatusOK {
+		t.Fatalf("group target: status = %d, want 200", code)
+	}
+	pref := state.prefs["web"]
+	if pref == nil || pref.Mode != "default" || len(pref.Channels) != 1 || pref.Channels[0] != "g" {
+		t.Fatalf("pref = %+v, want default mode routed to g", pref)
+	}
+
+	if code := post(`{"mode":"muted"}`); code != http.StatusOK {
+		t.Fatalf("mode only: status = %d, want 200", code)
+	}
+	if pref := state.prefs["web"]; pref == nil || pref.Mode != "muted" || len(pref.Channels) != 1 {
+		t.Fatalf("pref = %+v, want muted with routing kept", pref)
+	}
+
+	if code := post(`{"mode":"default","channels":[]}`); code != http.StatusOK {
+		t.Fatalf("reset: status = %d, want 200", code)
+	}
+	if _, ok := state.prefs["web"]; ok {
+		t.Error("default mode with routing cleared should remove the preference")
+	}
+}
@@ -0,0 +1,189 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/docker"
+	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+)
+
+// apiGetPin returns a container's timed pin, or null when it has none.
+func (s *Server) apiGetPin(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if !isValidContainerName(name) {
+		writeError(w, http.StatusBadRequest, "invalid container name")
+		return
+	}
+
+	resp := map[string]any{"name": name, "pin": nil}
+	if s.deps.Pins != nil {
+		if p, ok := s.deps.Pins.GetTimedPin(hostScopedKey(r, name)); ok {
+			resp["pin"] = p
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// apiSetPin pins a container to its current image for a limited time.
+// The policy override in place beforehand is restored when the pin expires.
+// Body: {"duration": "2w"}
+func (s *Server) apiSetPin(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if !isValidContainerName(name) {
+		writeError(w, http.StatusBadRequest, "invalid container name")
+		return
+	}
+	if s.deps.Pins == nil || s.deps.Policy == nil {
+		writeError(w, http.StatusNotImplemented, "timed pins not available")
+		return
+	}
+
+	var body struct {
+		Duration string `json:"duration"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	d, err := docker.ParseDurationWithDays(body.Duration)
+	if err != nil || d <= 0 {
+		writeError(w, http.StatusBadRequest, "duration must be positive, e.g. 3d or 2w")
+		return
+	}
+
+	if s.isProtectedContainer(r.Context(), name) {
+		writeError(w, http.StatusForbidden, "cannot pin sentinel itself")
+		return
+	}
+
+	key := hostScopedKey(r, name)
+	now := time.Now()
+	pin := TimedPin{PinnedAt: now, Until: now.Add(d)}
+
+	// Re-pinning extends the existing pin and keeps its original policy.
+	if prev, ok := s.deps.Pins.GetTimedPin(key); ok {
+		pin.PinnedAt = prev.PinnedAt
+		pin.PreviousPolicy = prev.PreviousPolicy
+		pin.Digest = prev.Digest
+	} else {
+		if p, ok := s.deps.Policy.GetPolicyOverride(key); ok {
+			pin.PreviousPolicy = p
+		}
+		// Digests are only resolvable for local containers.
+		if r.URL.Query().Get("host") == "" {
+			pin.Digest = s.currentDigest(r, name)
+		}
+	}
+
+	if err := s.deps.Policy.SetPolicyOverride(key, "pinned"); err != nil {
+		s.deps.Log.Error("failed to pin container", "name", name, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to set policy override")
+		return
+	}
+	if err := s.deps.Pins.SetTimedPin(key, pin); err != nil {
+		s.deps.Log.Error("failed to save timed pin", "name", name, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to save timed pin")
+		return
+	}
+
+	msg := "Pinned until " + pin.Until.Format("2006-01-02 15:04")
+	s.deps.Log.Info("timed pin set", "name", name, "until", pin.Until)
+	s.logEvent(r, "pin_set", name, msg)
+	s.deps.EventBus.Publish(events.SSEEvent{
+		Type:          events.EventPolicyChange,
+		ContainerName: name,
+		Message:       msg,
+		Timestamp:     now,
+	})
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"status": "ok",
+		"name":   name,
+		"pin":    pin,
+	})
+}
+
+// apiDeletePin ends a timed pin early, restoring the previous policy now.
+func (s *Server) apiDeletePin(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if !isValidContainerName(name) {
+		writeError(w, http.StatusBadRequest, "invalid container name")
+		return
+	}
+	if s.deps.Pins == nil || s.deps.Policy == nil {
+		writeError(w, http.StatusNotImplemented, "timed pins not available")
+		return
+	}
+
+	key := hostScopedKey(r, name)
+	pin, ok := s.deps.Pins.GetTimedPin(key)
+	if !ok {
+		writeError(w, http.StatusNotFound, "no timed pin for "+name)
+		return
+	}
+
+	var err error
+	if pin.PreviousPolicy == "" {
+		err = s.deps.Policy.DeletePolicyOverride(key)
+	} else {
+		err = s.deps.Policy.SetPolicyOverride(key, pin.PreviousPolicy)
+	}
+	if err != nil {
+		s.deps.Log.Error("failed to restore policy", "name", name, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to restore policy")
+		return
+	}
+	if err := s.deps.Pins.DeleteTimedPin(key); err != nil {
+		s.deps.Log.Error("failed to delete timed pin", "name", name, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to delete timed pin")
+		return
+	}
+
+	s.logEvent(r, "pin_delete", name, "Timed pin removed")
+	s.deps.EventBus.Publish(events.SSEEvent{
+		Type:          events.EventPolicyChange,
+		ContainerName: name,
+		Message:       "Timed pin removed for " + name,
+		Timestamp:     time.Now(),
+	})
+	writeJSON(w, http.StatusOK, map[string]string{
+		"status":          "ok",
+		"name":            name,
+		"previous_policy": pin.PreviousPolicy,
+	})
+}
+
+// currentDigest resolves the digest of a local container's image, or "".
+func (s *Server) currentDigest(r *http.Request, name string) string {
+	containers, err := s.deps.Docker.ListAllContainers(r.Context())
+	if err != nil {
+		return ""
+	}
+	for _, c := range containers {
+		if containerName(c) != name {
+			continue
+		}
+		digest, err := s.deps.Pins.ImageDigest(r.Context(), c.Image)
+		if err != nil {
+			s.deps.Log.Debug("failed to resolve pin digest", "name", name, "error", err)
+		}
+		return digest
+	}
+	return ""
+}
+
+// clearTimedPin drops a container's timed pin after its policy was changed
+// by hand, so expiry does not later overwrite that choice.
+func (s *Server) clearTimedPin(key string) {
+	if s.deps.Pins == nil {
+		return
+	}
+	if _, ok := s.deps.Pins.GetTimedPin(key); !ok {
+		return
+	}
+	if err := s.deps.Pins.DeleteTimedPin(key); err != nil {
+		s.deps.Log.Warn("failed to clear timed pin", "name", key, "error", err)
+	}
+}
@@ -0,0 +1,134 @@
+package web
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+)
+
+type mockPinStore struct {
+	pins map[string]TimedPin
+}
+
+func (m *mockPinStore) GetTimedPin(key string) (TimedPin, bool) {
+	p, ok := m.pins[key]
+	return p, ok
+}
+
+func (m *mockPinStore) SetTimedPin(key string, p TimedPin) error {
+	m.pins[key] = p
+	return nil
+}
+
+func (m *mockPinStore) DeleteTimedPin(key string) error {
+	delete(m.pins, key)
+	return nil
+}
+
+func (m *mockPinStore) ImageDigest(_ context.Context, _ string) (string, error) {
+	return "nginx@sha256:abc", nil
+}
+
+func newPinTestServer(ps *mockPinStore, policy *mockPolicyStore) *Server {
+	return &Server{
+		deps: Dependencies{
+			Docker: &mockContainerLister{containers: []ContainerSummary{
+				{Names: []string{"/nginx"}, Image: "nginx:1.27"},
+			}},
+			Pins:     ps,
+			Policy:   policy,
+			EventBus: events.New(),
+			Log:      slog.Default(),
+		},
+	}
+}
+
+func TestApiSetPin(t *testing.T) {
+	ps := &mockPinStore{pins: map[string]TimedPin{}}
+	policy := newMockPolicyStore()
+	policy.overrides["nginx"] = "auto"
+	srv := newPinTestServer(ps, policy)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPut, "/api/containers/nginx/pin", strings.NewReader(`{"duration":"2w"}`))
+	r.SetPathValue("name", "nginx")
+	srv.apiSetPin(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	if policy.overrides["nginx"] != "pinned" {
+		t.Errorf("policy = %q, want pinned", policy.overrides["nginx"])
+	}
+	pin, ok := ps.pins["nginx"]
+	if !ok {
+		t.Fatal("timed pin not stored")
+	}
+	if pin.PreviousPolicy != "auto" || pin.Digest != "nginx@sha256:abc" {
+		t.Errorf("pin = %+v", pin)
+	}
+	if got := pin.Until.Sub(pin.PinnedAt); got != 14*24*time.Hour {
+		t.Errorf("pin length = %v, want 2 weeks", got)
+	}
+}
+
+func TestApiSetPin_InvalidDuration(t *testing.T) {
+	for _, body := range []string{`{"duration":""}`, `{"duration":"0d"}`, `{"duration":"soon"}`} {
+		srv := newPinTestServer(&mockPinStore{pins: map[string]TimedPin{}}, newMockPolicyStore())
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPut, "/api/containers/nginx/pin", strings.NewReader(body))
+		r.SetPathValue("name", "nginx")
+		srv.apiSetPin(w, r)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", body, w.Code)
+		}
+	}
+}
+
+func TestApiDeletePin_RestoresPolicy(t *testing.T) {
+	ps := &mockPinStore{pins: map[string]TimedPin{
+		"h1::nginx": {Until: time.Now().Add(time.Hour)},
+	}}
+	policy := newMockPolicyStore()
+	policy.overrides["h1::nginx"] = "pinned"
+	srv := newPinTestServer(ps, policy)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodDelete, "/api/containers/nginx/pin?host=h1", nil)
+	r.SetPathValue("name", "nginx")
+	srv.apiDeletePin(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	if _, ok := policy.overrides["h1::nginx"]; ok {
+		t.Error("override should be removed when there was none before pinning")
+	}
+	if len(ps.pins) != 0 {
+		t.Error("timed pin not removed")
+	}
+}
+
+func TestApiChangePolicy_ClearsTimedPin(t *testing.T) {
+	ps := &mockPinStore{pins: map[string]TimedPin{"nginx": {Until: time.Now().Add(time.Hour)}}}
+	policy := newMockPolicyStore()
+	srv := newPinTestServer(ps, policy)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/containers/nginx/policy", strings.NewReader(`{"policy":"manual"}`))
+	r.SetPathValue("name", "nginx")
+	srv.apiChangePolicy(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	if len(ps.pins) != 0 {
+		t.Error("manual policy change should clear the timed pin")
+	}
+}
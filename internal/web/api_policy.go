@@ -51,11 +51,17 @@ func (s *Server) apiChangePolicy(w http.ResponseWriter, r *http.Request) {
 		policyKey = hostID + "::" + name
 	}
 
+	var before any
+	if old, ok := s.deps.Policy.GetPolicyOverride(policyKey); ok {
+		before = map[string]string{"policy": old}
+	}
 	if err := s.deps.Policy.SetPolicyOverride(policyKey, body.Policy); err != nil {
 		s.deps.Log.Error("policy change failed", "name", name, "policy", body.Policy, "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to set policy override")
 		return
 	}
+	s.clearTimedPin(policyKey)
+	auditState(r, before, map[string]string{"policy": body.Policy})
 
 	s.deps.Log.Info("policy override set", "name", name, "policy", body.Policy)
 	s.logEvent(r, "policy_set", name, "Policy set to "+body.Policy)
@@ -93,7 +99,8 @@ func (s *Server) apiDeletePolicy(w http.ResponseWriter, r *http.Request) {
 		policyKey = hostID + "::" + name
 	}
 
-	if _, ok := s.deps.Policy.GetPolicyOverride(policyKey); !ok {
+	old, ok := s.deps.Policy.GetPolicyOverride(policyKey)
+	if !ok {
 		writeError(w, http.StatusNotFound, "no policy override for "+name)
 		return
 	}
@@ -102,6 +109,8 @@ func (s *Server) apiDeletePolicy(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, "failed to delete policy override")
 		return
 	}
+	s.clearTimedPin(policyKey)
+	auditState(r, map[string]string{"policy": old}, nil)
 
 	s.logEvent(r, "policy_delete", name, "Policy override removed")
 
@@ -175,9 +184,15 @@ func (s *Server) apiBulkPolicy(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	scope := requestScope(r)
 	for _, name := range body.Containers {
 		labels := allLabels[name]
 
+		if !scope.Matches(name, labels) {
+			blocked = append(blocked, blockedEntry{Name: name, Reason: "not found"})
+			continue
+		}
+
 		// Self-protection check.
 		if labels != nil && labels["sentinel.self"] == "true" {
 			blocked = append(blocked, blockedEntry{Name: name, Reason: "self-protected"})
@@ -217,6 +232,7 @@ func (s *Server) apiBulkPolicy(w http.ResponseWriter, r *http.Request) {
 			s.deps.Log.Error("bulk policy change failed", "name", c.Name, "error", err)
 			continue
 		}
+		s.clearTimedPin(c.Key)
 		applied++
 	}
 
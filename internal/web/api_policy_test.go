@@ -8,7 +8,9 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/Will-Luck/Docker-Sentinel/internal/engine"
 	"github.com/Will-Luck/Docker-Sentinel/internal/events"
 )
 
@@ -74,6 +76,8 @@ func (m *mockPolicyStore) AllPolicyOverrides() map[string]string {
 type mockSwarmProvider struct {
 	swarmMode bool
 	services  []ServiceDetail
+	placement engine.ServicePlacement
+	previewed string // "id=targetImage" of the last PreviewPlacement call
 }
 
 func (m *mockSwarmProvider) IsSwarmMode() bool { return m.swarmMode }
@@ -95,6 +99,10 @@ func (m *mockSwarmProvider) RollbackService(_ context.Context, _, _ string) erro
 func (m *mockSwarmProvider) ScaleService(_ context.Context, _ string, _ uint64) error {
 	return nil
 }
+func (m *mockSwarmProvider) PreviewPlacement(_ context.Context, id, targetImage string) (engine.ServicePlacement, error) {
+	m.previewed = id + "=" + targetImage
+	return m.placement, nil
+}
 
 // ---------------------------------------------------------------------------
 // Mock: ClusterProvider with containers
@@ -121,16 +129,25 @@ func (m *mockClusterProviderWithContainers) GetHost(id string) (ClusterHost, boo
 
 func (m *mockClusterProviderWithContainers) ConnectedHosts() []string { return m.connected }
 
-func (m *mockClusterProviderWithContainers) GenerateEnrollToken() (string, string, error) {
+func (m *mockClusterProviderWithContainers) GenerateEnrollToken(time.Duration, string) (string, string, error) {
 	return "tok", "id", nil
 }
 
+func (m *mockClusterProviderWithContainers) ListEnrollTokens() ([]EnrollToken, error) {
+	return nil, nil
+}
+func (m *mockClusterProviderWithContainers) RevokeEnrollToken(_ string) error { return nil }
+
 func (m *mockClusterProviderWithContainers) RemoveHost(_ string) error { return nil }
 func (m *mockClusterProviderWithContainers) RevokeHost(_ string) error { return nil }
 func (m *mockClusterProviderWithContainers) PauseHost(_ string) error  { return nil }
 
-func (m *mockClusterProviderWithContainers) UpdateRemoteContainer(_ context.Context, _, _, _, _ string) error {
-	return nil
+func (m *mockClusterProviderWithContainers) HostEvents(_ string, _ int) ([]ClusterHostEvent, error) {
+	return nil, nil
+}
+
+func (m *mockClusterProviderWithContainers) UpdateRemoteContainer(_ context.Context, _, _, _, _ string) ([]HookRun, error) {
+	return nil, nil
 }
 
 func (m *mockClusterProviderWithContainers) RemoteContainerAction(_ context.Context, _, _, _ string) error {
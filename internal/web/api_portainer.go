@@ -418,10 +418,14 @@ func (s *Server) apiPortainerContainers(w http.ResponseWriter, r *http.Request)
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	if containers == nil {
-		containers = []PortainerContainerInfo{}
+	scope := requestScope(r)
+	visible := []PortainerContainerInfo{}
+	for _, c := range containers {
+		if scope.Matches(c.Name, c.Labels) {
+			visible = append(visible, c)
+		}
 	}
-	writeJSON(w, http.StatusOK, containers)
+	writeJSON(w, http.StatusOK, visible)
 }
 
 // overlapSource describes a higher-priority source monitoring the same Docker daemon.
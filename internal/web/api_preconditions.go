@@ -0,0 +1,114 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/docker"
+	"github.com/Will-Luck/Docker-Sentinel/internal/engine"
+)
+
+// preconditionsResponse is the JSON form of a container's wait-for conditions.
+type preconditionsResponse struct {
+	Conditions []string `json:"conditions"`
+	Action     string   `json:"action,omitempty"`
+}
+
+// apiGetPreconditions returns a container's wait-for override alongside the
+// sentinel.wait-for label values it replaces.
+func (s *Server) apiGetPreconditions(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if !isValidContainerName(name) {
+		writeError(w, http.StatusBadRequest, "invalid container name")
+		return
+	}
+
+	resp := map[string]any{"name": name, "override": nil}
+	if s.deps.Preconditions != nil {
+		if p, ok := s.deps.Preconditions.GetPreconditions(hostScopedKey(r, name)); ok {
+			resp["override"] = preconditionsResponse{Conditions: p.Conditions, Action: p.Action}
+		}
+	}
+
+	if containers, err := s.deps.Docker.ListAllContainers(r.Context()); err == nil {
+		for _, c := range containers {
+			if containerName(c) != name {
+				continue
+			}
+			resp["labels"] = preconditionsResponse{
+				Conditions: docker.ContainerWaitFor(c.Labels),
+				Action:     docker.ContainerWaitForAction(c.Labels),
+			}
+			break
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// apiSetPreconditions stores a container's wait-for override, replacing its
+// labels. Body: {"conditions": ["path:/mnt/media", "tcp://db:5432"], "action": "defer"}
+func (s *Server) apiSetPreconditions(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if !isValidContainerName(name) {
+		writeError(w, http.StatusBadRequest, "invalid container name")
+		return
+	}
+	if s.deps.Preconditions == nil {
+		writeError(w, http.StatusNotImplemented, "precondition store not available")
+		return
+	}
+
+	var body preconditionsResponse
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if len(body.Conditions) == 0 {
+		writeError(w, http.StatusBadRequest, "no conditions set; use DELETE to clear")
+		return
+	}
+	for _, c := range body.Conditions {
+		if err := engine.ValidateCondition(c); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if !engine.ValidPreconditionAction(body.Action) {
+		writeError(w, http.StatusBadRequest, "action must be defer or skip")
+		return
+	}
+
+	p := Preconditions{Conditions: body.Conditions, Action: body.Action}
+	if err := s.deps.Preconditions.SetPreconditions(hostScopedKey(r, name), p); err != nil {
+		s.deps.Log.Error("failed to save preconditions", "name", name, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to save preconditions")
+		return
+	}
+
+	s.logEvent(r, "preconditions_set", name, "Wait-for conditions updated")
+	writeJSON(w, http.StatusOK, map[string]any{
+		"status":   "ok",
+		"name":     name,
+		"override": body,
+	})
+}
+
+// apiDeletePreconditions clears a container's wait-for override.
+func (s *Server) apiDeletePreconditions(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if !isValidContainerName(name) {
+		writeError(w, http.StatusBadRequest, "invalid container name")
+		return
+	}
+	if s.deps.Preconditions == nil {
+		writeError(w, http.StatusNotImplemented, "precondition store not available")
+		return
+	}
+	if err := s.deps.Preconditions.DeletePreconditions(hostScopedKey(r, name)); err != nil {
+		s.deps.Log.Error("failed to delete preconditions", "name", name, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to delete preconditions")
+		return
+	}
+	s.logEvent(r, "preconditions_delete", name, "Wait-for conditions cleared")
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok", "name": name})
+}
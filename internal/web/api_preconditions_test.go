@@ -0,0 +1,132 @@
+package web
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type mockPreconditionStore struct {
+	entries map[string]Preconditions
+}
+
+func (m *mockPreconditionStore) GetPreconditions(key string) (Preconditions, bool) {
+	p, ok := m.entries[key]
+	return p, ok
+}
+
+func (m *mockPreconditionStore) SetPreconditions(key string, p Preconditions) error {
+	m.entries[key] = p
+	return nil
+}
+
+func (m *mockPreconditionStore) DeletePreconditions(key string) error {
+	delete(m.entries, key)
+	return nil
+}
+
+func newPreconditionTestServer(ps *mockPreconditionStore) *Server {
+	return &Server{
+		deps: Dependencies{
+			Docker: &mockContainerLister{containers: []ContainerSummary{
+				{Names: []string{"/plex"}, Labels: map[string]string{"sentinel.wait-for": "path:/mnt/media", "sentinel.wait-for-action": "skip"}},
+			}},
+			Preconditions: ps,
+			Log:           slog.Default(),
+		},
+	}
+}
+
+func TestApiSetPreconditions(t *testing.T) {
+	ps := &mockPreconditionStore{entries: map[string]Preconditions{}}
+	srv := newPreconditionTestServer(ps)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPut, "/api/containers/plex/preconditions?host=h1",
+		strings.NewReader(`{"conditions":["path:/mnt/media","http://nas.lan/health"],"action":"defer"}`))
+	r.SetPathValue("name", "plex")
+	srv.apiSetPreconditions(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	p, ok := ps.entries["h1::plex"]
+	if !ok || len(p.Conditions) != 2 || p.Action != "defer" {
+		t.Errorf("stored = %+v, %v", p, ok)
+	}
+}
+
+func TestApiSetPreconditions_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"empty", `{"conditions":[]}`},
+		{"relative path", `{"conditions":["path:mnt/media"]}`},
+		{"bad scheme", `{"conditions":["ftp://nas/"]}`},
+		{"no host", `{"conditions":["tcp://:5432"]}`},
+		{"bad action", `{"conditions":["tcp://db:5432"],"action":"later"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ps := &mockPreconditionStore{entries: map[string]Preconditions{}}
+			srv := newPreconditionTestServer(ps)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPut, "/api/containers/plex/preconditions", strings.NewReader(tt.body))
+			r.SetPathValue("name", "plex")
+			srv.apiSetPreconditions(w, r)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400; body: %s", w.Code, w.Body.String())
+			}
+			if len(ps.entries) != 0 {
+				t.Errorf("expected nothing stored, got %v", ps.entries)
+			}
+		})
+	}
+}
+
+func TestApiGetAndDeletePreconditions(t *testing.T) {
+	ps := &mockPreconditionStore{entries: map[string]Preconditions{
+		"plex": {Conditions: []string{"tcp://db:5432"}},
+	}}
+	srv := newPreconditionTestServer(ps)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api/containers/plex/preconditions", nil)
+	r.SetPathValue("name", "plex")
+	srv.apiGetPreconditions(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	var resp struct {
+		Override *preconditionsResponse `json:"override"`
+		Labels   preconditionsResponse  `json:"labels"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Override == nil || len(resp.Override.Conditions) != 1 {
+		t.Errorf("override = %+v", resp.Override)
+	}
+	if resp.Labels.Action != "skip" || len(resp.Labels.Conditions) != 1 {
+		t.Errorf("labels = %+v", resp.Labels)
+	}
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodDelete, "/api/containers/plex/preconditions", nil)
+	r.SetPathValue("name", "plex")
+	srv.apiDeletePreconditions(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("delete status = %d, want 200", w.Code)
+	}
+	if _, ok := ps.entries["plex"]; ok {
+		t.Error("preconditions still present after delete")
+	}
+}
@@ -6,13 +6,17 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/Will-Luck/Docker-Sentinel/internal/docker"
 	"github.com/Will-Luck/Docker-Sentinel/internal/engine"
 	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+	"github.com/Will-Luck/Docker-Sentinel/internal/hooks"
 	"github.com/Will-Luck/Docker-Sentinel/internal/portainer"
 	"github.com/Will-Luck/Docker-Sentinel/internal/registry"
 )
@@ -22,15 +26,25 @@ type queueResponse struct {
 	PendingUpdate
 	ReleaseNotesURL  string `json:"release_notes_url,omitempty"`
 	ReleaseNotesBody string `json:"release_notes_body,omitempty"`
+	CurrentVersion   string `json:"current_version,omitempty"`
+	CandidateVersion string `json:"candidate_version,omitempty"`
+	Stale            bool   `json:"stale,omitempty"` // waiting longer than queue_stale_after
 }
 
 // apiQueue returns all pending manual approvals, enriched with release notes URLs.
 func (s *Server) apiQueue(w http.ResponseWriter, r *http.Request) {
 	sources := s.loadReleaseSources()
-	items := s.deps.Queue.List()
+	items := s.scopedQueue(r)
+	versions := s.versionSet()
+	staleAfter, now := s.queueStaleAfter(), time.Now()
 	out := make([]queueResponse, len(items))
 	for i, item := range items {
-		out[i] = queueResponse{PendingUpdate: item}
+		out[i] = queueResponse{
+			PendingUpdate:    item,
+			CurrentVersion:   versions[item.Key()].Current,
+			CandidateVersion: versions[item.Key()].Candidate,
+			Stale:            isStale(item, staleAfter, now),
+		}
 		if len(item.NewerVersions) > 0 {
 			ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 			info := registry.FetchReleaseNotesWithSources(ctx, item.CurrentImage, item.NewerVersions[0], sources)
@@ -44,15 +58,38 @@ func (s *Server) apiQueue(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, out)
 }
 
-// apiQueueCount returns just the number of pending items (no release notes enrichment).
+// apiQueueCount returns just the number of pending items (no release notes
+// enrichment), and how many of them are stale.
 func (s *Server) apiQueueCount(w http.ResponseWriter, r *http.Request) {
-	count := len(s.deps.Queue.List())
-	writeJSON(w, http.StatusOK, map[string]int{"count": count})
+	items := s.scopedQueue(r)
+	staleAfter, now := s.queueStaleAfter(), time.Now()
+	stale := 0
+	for _, item := range items {
+		if isStale(item, staleAfter, now) {
+			stale++
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]int{"count": len(items), "stale": stale})
+}
+
+// queueStaleAfter returns how long an approval may wait before it is stale,
+// or 0 when aging alerts are off.
+func (s *Server) queueStaleAfter() time.Duration {
+	if s.deps.SettingsStore == nil {
+		return 0
+	}
+	val, _ := s.deps.SettingsStore.LoadSetting("queue_stale_after")
+	return engine.QueueStaleAfter(val)
+}
+
+// isStale reports whether a pending update has waited at least after.
+func isStale(item PendingUpdate, after time.Duration, now time.Time) bool {
+	return after > 0 && !item.DetectedAt.IsZero() && now.Sub(item.DetectedAt) >= after
 }
 
 // apiQueueExport streams all pending queue items as CSV or JSON.
 func (s *Server) apiQueueExport(w http.ResponseWriter, r *http.Request) {
-	items := s.deps.Queue.List()
+	items := s.scopedQueue(r)
 	if items == nil {
 		items = []PendingUpdate{}
 	}
@@ -118,7 +155,148 @@ func queueKeyName(r *http.Request) (key, name string) {
 	return key, name
 }
 
+// maxQueueCommentLen caps an operator comment on a queue entry.
+const maxQueueCommentLen = 500
+
+// queueAction is the optional JSON body of a queue action.
+type queueAction struct {
+	Comment string `json:"comment"`
+	Version string `json:"version"` // approve only: the version to move to; default the newest
+}
+
+// readQueueAction reads the optional body of a queue action. With no body,
+// or an empty comment, the comment already on the entry (stored) is used.
+func readQueueAction(r *http.Request, stored string) (queueAction, error) {
+	var body queueAction
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+		return body, errors.New("invalid JSON body")
+	}
+	body.Comment = strings.TrimSpace(body.Comment)
+	body.Version = strings.TrimSpace(body.Version)
+	if len(body.Comment) > maxQueueCommentLen {
+		return body, fmt.Errorf("comment must be at most %d characters", maxQueueCommentLen)
+	}
+	if body.Comment == "" {
+		body.Comment = stored
+	}
+	return body, nil
+}
+
+// queueComment reads the optional {"comment": "..."} body of a queue action.
+// With no body, or an empty comment, the comment already on the entry is used.
+func queueComment(r *http.Request, stored string) (string, error) {
+	body, err := readQueueAction(r, stored)
+	return body.Comment, err
+}
+
+// approveVersion returns the version approving update moves to: the one
+// picked on approval if any, otherwise the newest. Empty for digest-only
+// updates.
+func approveVersion(update PendingUpdate) string {
+	if update.TargetVersion != "" {
+		return update.TargetVersion
+	}
+	if len(update.NewerVersions) > 0 {
+		return update.NewerVersions[0]
+	}
+	return ""
+}
+
+// withComment appends an operator comment to an activity log message.
+func withComment(message, comment string) string {
+	if comment == "" {
+		return message
+	}
+	return message + ": " + comment
+}
+
+// recordQueueDecision writes a history record for a rejected or ignored
+// queue entry, so the decision and its comment sit alongside the updates.
+func (s *Server) recordQueueDecision(update PendingUpdate, outcome string) {
+	target := ""
+	if len(update.NewerVersions) > 0 {
+		target = webReplaceTag(update.CurrentImage, update.NewerVersions[0])
+	}
+	if err := s.deps.Store.RecordUpdate(UpdateRecord{
+		Timestamp:     time.Now(),
+		ContainerName: update.ContainerName,
+		OldImage:      update.CurrentImage,
+		OldDigest:     update.CurrentDigest,
+		NewImage:      target,
+		Outcome:       outcome,
+		Type:          update.Type,
+		HostID:        update.HostID,
+		HostName:      update.HostName,
+		Comment:       update.Comment,
+	}); err != nil {
+		s.deps.Log.Warn("failed to record queue decision", "name", update.ContainerName, "outcome", outcome, "error", err)
+	}
+}
+
+// queueAuditEntry is the part of a pending update the audit log keeps when
+// a decision takes it off the queue.
+func queueAuditEntry(u PendingUpdate) map[string]any {
+	return map[string]any{
+		"key":            u.Key(),
+		"image":          u.CurrentImage,
+		"remote_digest":  u.RemoteDigest,
+		"newer_versions": u.NewerVersions,
+		"comment":        u.Comment,
+	}
+}
+
+// queueKeys returns the keys of items, in order.
+func queueKeys(items []PendingUpdate) []string {
+	keys := make([]string, len(items))
+	for i, item := range items {
+		keys[i] = item.Key()
+	}
+	return keys
+}
+
+// apiQueueComment sets or clears the operator comment on a pending update.
+// Body: {"comment": "waiting for plugin compatibility"}.
+func (s *Server) apiQueueComment(w http.ResponseWriter, r *http.Request) {
+	key, name := queueKeyName(r)
+	if !s.scopeFilter(r)(key) {
+		writeError(w, http.StatusNotFound, "no pending update for "+name)
+		return
+	}
+	var body struct {
+		Comment string `json:"comment"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	comment := strings.TrimSpace(body.Comment)
+	if len(comment) > maxQueueCommentLen {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("comment must be at most %d characters", maxQueueCommentLen))
+		return
+	}
+	pending, _ := s.deps.Queue.Get(key)
+	if !s.deps.Queue.SetComment(key, comment) {
+		writeError(w, http.StatusNotFound, "no pending update for "+name)
+		return
+	}
+	auditState(r, map[string]string{"comment": pending.Comment}, map[string]string{"comment": comment})
+
+	if comment == "" {
+		s.logEvent(r, "queue_comment", name, "Queue comment cleared")
+	} else {
+		s.logEvent(r, "queue_comment", name, "Queue comment: "+comment)
+	}
+	writeJSON(w, http.StatusOK, map[string]string{
+		"status":  "ok",
+		"name":    name,
+		"comment": comment,
+	})
+}
+
 // apiApprove approves a pending update and triggers the update.
+// Body (optional): {"comment": "...", "version": "1.28.3"}. The comment is
+// kept on the history record; version picks one of the entry's newer
+// versions instead of the newest.
 func (s *Server) apiApprove(w http.ResponseWriter, r *http.Request) {
 	key, name := queueKeyName(r)
 	if key == "" {
@@ -126,88 +304,314 @@ func (s *Server) apiApprove(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	pending, queued := s.deps.Queue.Get(key)
+	body, err := readQueueAction(r, pending.Comment)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	comment := body.Comment
+	if queued && body.Version != "" && !slices.Contains(pending.NewerVersions, body.Version) {
+		writeError(w, http.StatusBadRequest, "version "+body.Version+" is not a pending version for "+name)
+		return
+	}
+
 	if s.isProtectedContainer(r.Context(), name) {
 		writeError(w, http.StatusForbidden, "cannot approve updates for sentinel itself")
 		return
 	}
 
+	// Local containers: refuse up front if the Docker connection lacks a
+	// permission the update needs, leaving the item in the queue.
+	if queued && pending.HostID == "" && pending.Type != "service" {
+		if reason := s.updateBlocked(r.Context(), name, s.getContainerLabels(r.Context(), name)); reason != "" {
+			writeErrorCode(w, http.StatusConflict, CodeUpdateBlocked, reason, map[string]string{"reason": reason})
+			return
+		}
+	}
+
 	update, ok := s.deps.Queue.Approve(key)
 	if !ok {
 		writeError(w, http.StatusNotFound, "no pending update for "+name)
 		return
 	}
+	update.Comment = comment
+	update.TargetVersion = body.Version
+	auditState(r, queueAuditEntry(pending), map[string]string{"decision": "approved", "version": approveVersion(update), "comment": comment})
+
+	// Trigger the update in background — don't block the HTTP response.
+	go s.runApprovedUpdate(update)
+
+	msg := "Update approved and started"
+	if body.Version != "" {
+		msg = "Update to " + body.Version + " approved and started"
+	}
+	s.logEvent(r, "approve", name, withComment(msg, comment))
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"status":  "approved",
+		"name":    name,
+		"version": approveVersion(update),
+		"message": "update started for " + name,
+	})
+}
 
+// ApproveUpdate approves a local pending update on behalf of by and starts
+// it in the background, applying the same checks as apiApprove. It
+// implements notify.ApprovalHandler for chat approval buttons.
+func (s *Server) ApproveUpdate(ctx context.Context, name, by string) error {
+	if s.isProtectedContainer(ctx, name) {
+		return fmt.Errorf("cannot approve updates for sentinel itself")
+	}
+	pending, ok := s.deps.Queue.Get(name)
+	if !ok {
+		return fmt.Errorf("no pending update for %s", name)
+	}
+	if pending.Type != "service" {
+		if reason := s.updateBlocked(ctx, name, s.getContainerLabels(ctx, name)); reason != "" {
+			return fmt.Errorf("%s", reason)
+		}
+	}
+	update, ok := s.deps.Queue.Approve(name)
+	if !ok {
+		return fmt.Errorf("no pending update for %s", name)
+	}
+	go s.runApprovedUpdate(update)
+	s.logActionBy(by, "approve", name, withComment("Update approved and started", update.Comment))
+	return nil
+}
+
+// RejectUpdate removes a local pending update on behalf of by.
+func (s *Server) RejectUpdate(_ context.Context, name, by string) error {
+	update, ok := s.deps.Queue.Get(name)
+	if !ok {
+		return fmt.Errorf("no pending update for %s", name)
+	}
+	s.deps.Queue.Remove(name)
+	s.recordQueueDecision(update, "rejected")
+	s.logActionBy(by, "reject", name, withComment("Update rejected", update.Comment))
+	return nil
+}
+
+// runApprovedUpdate executes an approved queue item and blocks until it
+// finishes. It uses a detached context because it outlives the HTTP request.
+// Routes to the Portainer, remote agent, service or local container updater.
+func (s *Server) runApprovedUpdate(update PendingUpdate) error {
 	// Build target image for semver version bumps.
 	approveTarget := ""
-	if len(update.NewerVersions) > 0 {
-		approveTarget = webReplaceTag(update.CurrentImage, update.NewerVersions[0])
+	if v := approveVersion(update); v != "" {
+		approveTarget = webReplaceTag(update.CurrentImage, v)
 	}
 
-	// Trigger the update in background — don't block the HTTP response.
-	// Use a detached context because r.Context() is cancelled when the handler returns.
-	// Route to service updater, remote agent, or local container updater.
-	go func() {
-		ctx := context.Background()
-		start := time.Now()
-		var err error
-		if strings.HasPrefix(update.HostID, "portainer:") && s.deps.Portainer != nil {
-			// Portainer-managed container — route through Portainer API.
-			err = s.approvePortainerUpdate(ctx, update, approveTarget)
-		} else if update.HostID != "" && s.deps.Cluster != nil && s.deps.Cluster.Enabled() {
-			// Remote container — dispatch to the agent via cluster.
-			s.markRemoteUpdating(update.HostID, update.ContainerName)
-			err = s.deps.Cluster.UpdateRemoteContainer(ctx, update.HostID, update.ContainerName, approveTarget, update.RemoteDigest)
-			time.AfterFunc(5*time.Second, func() { s.clearRemoteUpdating(update.HostID, update.ContainerName) })
-		} else if update.Type == "service" && s.deps.Swarm != nil {
-			err = s.deps.Swarm.UpdateService(ctx, update.ContainerID, update.ContainerName, approveTarget)
-		} else {
-			err = s.deps.Updater.UpdateContainer(ctx, update.ContainerID, update.ContainerName, approveTarget)
+	ctx := engine.WithApprovalComment(context.Background(), update.Comment)
+	start := time.Now()
+	name := update.ContainerName
+	var err error
+	var hookRuns []HookRun
+	if strings.HasPrefix(update.HostID, "portainer:") && s.deps.Portainer != nil {
+		// Portainer-managed container — route through Portainer API.
+		err = s.approvePortainerUpdate(ctx, update, approveTarget)
+	} else if update.HostID != "" && s.deps.Cluster != nil && s.deps.Cluster.Enabled() {
+		// Remote container — dispatch to the agent via cluster.
+		s.markRemoteUpdating(update.HostID, update.ContainerName)
+		hookRuns, err = s.deps.Cluster.UpdateRemoteContainer(ctx, update.HostID, update.ContainerName, approveTarget, update.RemoteDigest)
+		time.AfterFunc(5*time.Second, func() { s.clearRemoteUpdating(update.HostID, update.ContainerName) })
+	} else if update.Type == "service" && s.deps.Swarm != nil {
+		err = s.deps.Swarm.UpdateService(ctx, update.ContainerID, update.ContainerName, approveTarget)
+	} else {
+		if update.ScanReport != nil {
+			// The approver has seen the scan report; let the update through.
+			ctx = engine.WithScanApproval(ctx)
 		}
-		if errors.Is(err, engine.ErrUpdateInProgress) {
-			s.deps.Queue.Add(update)
-			s.deps.Log.Warn("update busy, re-enqueued", "name", name)
+		err = s.deps.Updater.UpdateContainer(ctx, update.ContainerID, update.ContainerName, approveTarget)
+	}
+	if errors.Is(err, engine.ErrUpdateInProgress) || errors.Is(err, engine.ErrShuttingDown) || errors.Is(err, docker.ErrOperationDenied) {
+		s.deps.Queue.Add(update)
+		s.deps.Log.Warn("update not started, re-enqueued", "name", name, "reason", err)
+		return err
+	}
+	if errors.Is(err, engine.ErrPreconditionDeferred) {
+		// History already records the deferral; keep it pending for another approval.
+		s.deps.Queue.Add(update)
+		s.deps.Log.Info("approved update deferred, re-enqueued", "name", name, "reason", err)
+		return err
+	}
+	if errors.Is(err, engine.ErrPullCheckFailed) {
+		// History records the aborted attempt; nothing was changed, so keep
+		// it pending until the registry or disk space problem is fixed.
+		s.deps.Queue.Add(update)
+		s.deps.Log.Warn("approved update aborted before pull, re-enqueued", "name", name, "reason", err)
+		return err
+	}
+	if errors.Is(err, engine.ErrScanBlocked) {
+		// The engine queued it again with the scan report; keep the
+		// approved entry's versions and digests alongside the report.
+		if queued, ok := s.deps.Queue.Get(update.Key()); ok {
+			update.ScanReport = queued.ScanReport
+			update.ScanThreshold = queued.ScanThreshold
+		}
+		s.deps.Queue.Add(update)
+		s.deps.Log.Info("approved update held back by vulnerability scan, re-enqueued", "name", name, "reason", err)
+		return err
+	}
+	if errors.Is(err, engine.ErrPreconditionSkipped) {
+		s.deps.Log.Info("approved update skipped", "name", name, "reason", err)
+		return err
+	}
+	if errors.Is(err, hooks.ErrSkipUpdate) {
+		// A pre-update hook on the agent cancelled the update.
+		s.deps.Log.Info("approved update skipped by hook", "name", name, "host", update.HostID)
+		_ = s.deps.Store.RecordUpdate(UpdateRecord{
+			Timestamp:     start,
+			ContainerName: update.ContainerName,
+			OldImage:      update.CurrentImage,
+			OldDigest:     update.CurrentDigest,
+			NewImage:      approveTarget,
+			Outcome:       "skipped",
+			Duration:      time.Since(start),
+			Type:          update.Type,
+			HostID:        update.HostID,
+			HostName:      update.HostName,
+			Hooks:         hookRuns,
+			Comment:       update.Comment,
+		})
+		return err
+	}
+	if err != nil {
+		s.deps.Log.Error("approved update failed", "name", name, "error", err)
+		_ = s.deps.Store.RecordUpdate(UpdateRecord{
+			Timestamp:     start,
+			ContainerName: update.ContainerName,
+			OldImage:      update.CurrentImage,
+			OldDigest:     update.CurrentDigest,
+			NewImage:      approveTarget,
+			Outcome:       "failed",
+			Duration:      time.Since(start),
+			Error:         err.Error(),
+			Type:          update.Type,
+			HostID:        update.HostID,
+			HostName:      update.HostName,
+			Hooks:         hookRuns,
+			Comment:       update.Comment,
+		})
+	} else if update.HostID != "" {
+		// Record success for remote updates (Portainer, cluster agent, swarm).
+		// Local updates record their own history inside UpdateContainer().
+		s.deps.Log.Info("remote update succeeded", "name", name, "host", update.HostID)
+		_ = s.deps.Store.RecordUpdate(UpdateRecord{
+			Timestamp:     start,
+			ContainerName: update.ContainerName,
+			OldImage:      update.CurrentImage,
+			OldDigest:     update.CurrentDigest,
+			NewImage:      approveTarget,
+			Outcome:       "success",
+			Duration:      time.Since(start),
+			Type:          update.Type,
+			HostID:        update.HostID,
+			HostName:      update.HostName,
+			Hooks:         hookRuns,
+			Comment:       update.Comment,
+		})
+	}
+	return err
+}
+
+// apiQueueReorder sets the execution order of the queue.
+// Body: {"keys": ["db", "host1::api", ...]} — listed keys move to the front
+// in that order; unlisted entries keep their relative order behind them.
+func (s *Server) apiQueueReorder(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Keys []string `json:"keys"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if len(body.Keys) == 0 {
+		writeError(w, http.StatusBadRequest, "keys required")
+		return
+	}
+	allowed := s.scopeFilter(r)
+	for _, key := range body.Keys {
+		if !allowed(key) {
+			_, name := splitContainerKey(key)
+			writeError(w, http.StatusNotFound, "container not found: "+name)
 			return
 		}
-		if err != nil {
-			s.deps.Log.Error("approved update failed", "name", name, "error", err)
-			_ = s.deps.Store.RecordUpdate(UpdateRecord{
-				Timestamp:     start,
-				ContainerName: update.ContainerName,
-				OldImage:      update.CurrentImage,
-				OldDigest:     update.CurrentDigest,
-				NewImage:      approveTarget,
-				Outcome:       "failed",
-				Duration:      time.Since(start),
-				Error:         err.Error(),
-				Type:          update.Type,
-				HostID:        update.HostID,
-				HostName:      update.HostName,
-			})
-		} else if update.HostID != "" {
-			// Record success for remote updates (Portainer, cluster agent, swarm).
-			// Local updates record their own history inside UpdateContainer().
-			s.deps.Log.Info("remote update succeeded", "name", name, "host", update.HostID)
-			_ = s.deps.Store.RecordUpdate(UpdateRecord{
-				Timestamp:     start,
-				ContainerName: update.ContainerName,
-				OldImage:      update.CurrentImage,
-				OldDigest:     update.CurrentDigest,
-				NewImage:      approveTarget,
-				Outcome:       "success",
-				Duration:      time.Since(start),
-				Type:          update.Type,
-				HostID:        update.HostID,
-				HostName:      update.HostName,
-			})
+	}
+	before := queueKeys(s.scopedQueue(r))
+	if err := s.deps.Queue.Reorder(body.Keys); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	after := s.scopedQueue(r)
+	auditState(r, map[string][]string{"order": before}, map[string][]string{"order": queueKeys(after)})
+
+	s.logEvent(r, "queue_reorder", "", fmt.Sprintf("Queue reordered (%d items moved)", len(body.Keys)))
+	writeJSON(w, http.StatusOK, after)
+}
+
+// apiApproveAll approves pending updates and runs them one at a time in
+// queue order, so a database can be updated before the services that depend
+// on it. Body (optional): {"keys": [...]} limits the run to those entries;
+// they still execute in queue order. Each entry is only taken off the queue
+// when its turn comes, and the run stops at the first failure so dependents
+// are left pending rather than updated against a broken dependency.
+func (s *Server) apiApproveAll(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Keys []string `json:"keys"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	var only map[string]bool
+	if len(body.Keys) > 0 {
+		only = make(map[string]bool, len(body.Keys))
+		for _, k := range body.Keys {
+			only[k] = true
 		}
-	}()
+	}
+
+	var keys []string
+	var skipped []string
+	var approved []map[string]any
+	for _, item := range s.scopedQueue(r) {
+		key := item.Key()
+		if only != nil && !only[key] {
+			continue
+		}
+		if s.isProtectedContainer(r.Context(), item.ContainerName) {
+			skipped = append(skipped, key)
+			continue
+		}
+		keys = append(keys, key)
+		approved = append(approved, queueAuditEntry(item))
+	}
+	if len(keys) == 0 {
+		writeError(w, http.StatusNotFound, "no pending updates to approve")
+		return
+	}
+	auditState(r, approved, map[string]any{"decision": "approved", "order": keys})
 
-	s.logEvent(r, "approve", name, "Update approved and started")
+	go func() {
+		for i, key := range keys {
+			update, ok := s.deps.Queue.Approve(key)
+			if !ok {
+				continue // removed or approved elsewhere in the meantime
+			}
+			if err := s.runApprovedUpdate(update); err != nil {
+				s.deps.Log.Warn("bulk approval stopped", "failed", key, "remaining", len(keys)-i-1)
+				return
+			}
+		}
+	}()
 
-	writeJSON(w, http.StatusOK, map[string]string{
+	s.logEvent(r, "approve", "", fmt.Sprintf("Bulk approval started for %d updates", len(keys)))
+	writeJSON(w, http.StatusOK, map[string]any{
 		"status":  "approved",
-		"name":    name,
-		"message": "update started for " + name,
+		"order":   keys,
+		"skipped": skipped,
 	})
 }
 
@@ -267,6 +671,7 @@ func (s *Server) approvePortainerUpdate(ctx context.Context, update PendingUpdat
 }
 
 // apiIgnoreVersion ignores a specific version for a container and removes it from the queue.
+// Body (optional): {"comment": "..."} is kept on the history record.
 func (s *Server) apiIgnoreVersion(w http.ResponseWriter, r *http.Request) {
 	key, name := queueKeyName(r)
 	if key == "" {
@@ -284,6 +689,12 @@ func (s *Server) apiIgnoreVersion(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "no specific version to ignore (digest-only update)")
 		return
 	}
+	comment, err := queueComment(r, update.Comment)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	update.Comment = comment
 
 	ignoredVersion := update.NewerVersions[0]
 	if s.deps.IgnoredVersions != nil {
@@ -295,7 +706,9 @@ func (s *Server) apiIgnoreVersion(w http.ResponseWriter, r *http.Request) {
 	}
 
 	s.deps.Queue.Remove(key)
-	s.logEvent(r, "ignore", name, "Ignored version "+ignoredVersion)
+	s.recordQueueDecision(update, "ignored")
+	auditState(r, queueAuditEntry(update), map[string]string{"decision": "ignored", "version": ignoredVersion, "comment": comment})
+	s.logEvent(r, "ignore", name, withComment("Ignored version "+ignoredVersion, comment))
 
 	writeJSON(w, http.StatusOK, map[string]string{
 		"status":  "ignored",
@@ -306,6 +719,7 @@ func (s *Server) apiIgnoreVersion(w http.ResponseWriter, r *http.Request) {
 }
 
 // apiReject rejects and removes a pending update from the queue.
+// Body (optional): {"comment": "..."} is kept on the history record.
 func (s *Server) apiReject(w http.ResponseWriter, r *http.Request) {
 	key, name := queueKeyName(r)
 	if key == "" {
@@ -313,8 +727,20 @@ func (s *Server) apiReject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	update, queued := s.deps.Queue.Get(key)
+	comment, err := queueComment(r, update.Comment)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	s.deps.Queue.Remove(key)
-	s.logEvent(r, "reject", name, "Update rejected")
+	if queued {
+		auditState(r, queueAuditEntry(update), map[string]string{"decision": "rejected", "comment": comment})
+		update.Comment = comment
+		s.recordQueueDecision(update, "rejected")
+	}
+	s.logEvent(r, "reject", name, withComment("Update rejected", comment))
 
 	writeJSON(w, http.StatusOK, map[string]string{
 		"status":  "rejected",
@@ -1,16 +1,23 @@
 package web
 
 import (
+	"context"
 	"encoding/csv"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/Will-Luck/Docker-Sentinel/internal/engine"
 	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+	"github.com/Will-Luck/Docker-Sentinel/internal/scanner"
 )
 
 // ---------------------------------------------------------------------------
@@ -33,6 +40,16 @@ func (m *mockQueue) Get(name string) (PendingUpdate, bool) {
 func (m *mockQueue) Add(update PendingUpdate)                  { m.items = append(m.items, update) }
 func (m *mockQueue) Approve(name string) (PendingUpdate, bool) { return PendingUpdate{}, false }
 func (m *mockQueue) Remove(name string)                        {}
+func (m *mockQueue) Reorder(keys []string) error               { return nil }
+func (m *mockQueue) SetComment(name, comment string) bool {
+	for i := range m.items {
+		if m.items[i].Key() == name {
+			m.items[i].Comment = comment
+			return true
+		}
+	}
+	return false
+}
 
 // ---------------------------------------------------------------------------
 // Test helpers
@@ -194,6 +211,34 @@ func TestApiQueueExport_DefaultFormat(t *testing.T) {
 	}
 }
 
+func TestApiQueueCount_Stale(t *testing.T) {
+	q := &mockQueue{items: []PendingUpdate{
+		{ContainerName: "old", DetectedAt: time.Now().Add(-20 * 24 * time.Hour)},
+		{ContainerName: "new", DetectedAt: time.Now().Add(-time.Hour)},
+	}}
+	srv := newQueueExportTestServer(q)
+	count := func() map[string]int {
+		t.Helper()
+		w := httptest.NewRecorder()
+		srv.apiQueueCount(w, httptest.NewRequest(http.MethodGet, "/api/queue/count", nil))
+		var got map[string]int
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return got
+	}
+
+	if got := count(); got["count"] != 2 || got["stale"] != 0 {
+		t.Errorf("without a threshold = %v, want count 2, stale 0", got)
+	}
+	ss := newMockSettingsStore()
+	ss.data["queue_stale_after"] = "14d"
+	srv.deps.SettingsStore = ss
+	if got := count(); got["count"] != 2 || got["stale"] != 1 {
+		t.Errorf("with 14d = %v, want count 2, stale 1", got)
+	}
+}
+
 func TestApiQueueExport_EmptyQueue_CSV(t *testing.T) {
 	q := &mockQueue{items: nil}
 	srv := newQueueExportTestServer(q)
@@ -237,3 +282,395 @@ func TestApiQueueExport_EmptyQueue_JSON(t *testing.T) {
 		t.Errorf("got %d items, want 0", len(items))
 	}
 }
+
+// ---------------------------------------------------------------------------
+// Ordering and bulk approval
+// ---------------------------------------------------------------------------
+
+// orderedQueue is a mockQueue whose Approve and Reorder actually mutate.
+type orderedQueue struct {
+	mockQueue
+}
+
+func (m *orderedQueue) Approve(name string) (PendingUpdate, bool) {
+	for i, item := range m.items {
+		if item.Key() == name {
+			m.items = append(m.items[:i], m.items[i+1:]...)
+			return item, true
+		}
+	}
+	return PendingUpdate{}, false
+}
+
+func (m *orderedQueue) Reorder(keys []string) error {
+	var out []PendingUpdate
+	for _, k := range keys {
+		item, ok := m.Get(k)
+		if !ok {
+			return errors.New("no pending update for " + k)
+		}
+		out = append(out, item)
+	}
+	for _, item := range m.items {
+		if !slices.Contains(keys, item.Key()) {
+			out = append(out, item)
+		}
+	}
+	m.items = out
+	return nil
+}
+
+// recordingUpdater records UpdateContainer calls in order and fails for
+// names listed in fail.
+type recordingUpdater struct {
+	mu      sync.Mutex
+	calls   []string
+	targets []string
+	fail    map[string]bool
+}
+
+func (m *recordingUpdater) UpdateContainer(_ context.Context, _, name, target string) error {
+	m.mu.Lock()
+	m.calls = append(m.calls, name)
+	m.targets = append(m.targets, target)
+	m.mu.Unlock()
+	if m.fail[name] {
+		return errors.New("boom")
+	}
+	return nil
+}
+func (m *recordingUpdater) IsUpdating(string) bool { return false }
+func (m *recordingUpdater) IsIdle() bool           { return true }
+func (m *recordingUpdater) SelfUpdateQueued() bool { return false }
+
+func TestApiQueueReorder(t *testing.T) {
+	q := &orderedQueue{mockQueue{items: []PendingUpdate{
+		{ContainerName: "api"}, {ContainerName: "web"}, {ContainerName: "db"},
+	}}}
+	srv := newQueueExportTestServer(q)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPatch, "/api/queue/order", strings.NewReader(`{"keys":["db"]}`))
+	srv.apiQueueReorder(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	if q.items[0].ContainerName != "db" {
+		t.Errorf("first = %q, want db", q.items[0].ContainerName)
+	}
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodPatch, "/api/queue/order", strings.NewReader(`{"keys":["nope"]}`))
+	srv.apiQueueReorder(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("unknown key: status = %d, want 400", w.Code)
+	}
+}
+
+func TestApiApproveAll_RunsInOrderAndStopsOnFailure(t *testing.T) {
+	q := &orderedQueue{mockQueue{items: []PendingUpdate{
+		{ContainerName: "db"}, {ContainerName: "api"}, {ContainerName: "web"},
+	}}}
+	upd := &recordingUpdater{fail: map[string]bool{"api": true}}
+	srv := newQueueExportTestServer(q)
+	srv.deps.Docker = &mockContainerLister{}
+	srv.deps.Updater = upd
+	srv.deps.Store = newMockHistoryStore()
+
+	w := httptest.NewRecorder()
+	srv.apiApproveAll(w, httptest.NewRequest(http.MethodPost, "/api/queue/approve-all", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		upd.mu.Lock()
+		n := len(upd.calls)
+		upd.mu.Unlock()
+		if n >= 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	upd.mu.Lock()
+	defer upd.mu.Unlock()
+	if want := []string{"db", "api"}; !slices.Equal(upd.calls, want) {
+		t.Errorf("calls = %v, want %v", upd.calls, want)
+	}
+	// web stays queued because api failed before it.
+	if _, ok := q.Get("web"); !ok {
+		t.Error("web should remain pending after api failed")
+	}
+}
+
+func TestApproveUpdate_FromChat(t *testing.T) {
+	q := &orderedQueue{mockQueue{items: []PendingUpdate{{ContainerName: "web"}}}}
+	upd := &recordingUpdater{}
+	srv := newQueueExportTestServer(q)
+	srv.deps.Docker = &mockContainerLister{}
+	srv.deps.Updater = upd
+	srv.deps.Store = newMockHistoryStore()
+
+	if err := srv.ApproveUpdate(context.Background(), "web", "telegram:alice"); err != nil {
+		t.Fatalf("ApproveUpdate: %v", err)
+	}
+	if _, ok := q.Get("web"); ok {
+		t.Error("web should leave the queue once approved")
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		upd.mu.Lock()
+		n := len(upd.calls)
+		upd.mu.Unlock()
+		if n == 1 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	upd.mu.Lock()
+	defer upd.mu.Unlock()
+	if !slices.Equal(upd.calls, []string{"web"}) {
+		t.Errorf("calls = %v, want [web]", upd.calls)
+	}
+
+	if err := srv.ApproveUpdate(context.Background(), "web", "telegram:alice"); err == nil {
+		t.Error("approving an update that is no longer pending should fail")
+	}
+	if err := srv.RejectUpdate(context.Background(), "db", "telegram:alice"); err == nil {
+		t.Error("rejecting an unknown update should fail")
+	}
+}
+
+func TestApiApprove_TargetVersion(t *testing.T) {
+	q := &orderedQueue{mockQueue{items: []PendingUpdate{{
+		ContainerName: "web",
+		CurrentImage:  "nginx:1.27.1",
+		NewerVersions: []string{"2.0.0", "1.28.3", "1.27.2"},
+	}}}}
+	upd := &recordingUpdater{}
+	srv := newQueueExportTestServer(q)
+	srv.deps.Docker = &mockContainerLister{}
+	srv.deps.Updater = upd
+	srv.deps.Store = newMockHistoryStore()
+
+	approve := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/approve/web", strings.NewReader(body))
+		req.SetPathValue("key", "web")
+		w := httptest.NewRecorder()
+		srv.apiApprove(w, req)
+		return w
+	}
+
+	if w := approve(`{"version":"1.29.0"}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("unknown version: status = %d, want 400", w.Code)
+	}
+	if _, ok := q.Get("web"); !ok {
+		t.Fatal("a rejected version should leave the entry queued")
+	}
+
+	w := approve(`{"version":"1.28.3"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"version":"1.28.3"`) {
+		t.Errorf("body = %s, want the chosen version", w.Body.String())
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		upd.mu.Lock()
+		n := len(upd.calls)
+		upd.mu.Unlock()
+		if n == 1 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	upd.mu.Lock()
+	defer upd.mu.Unlock()
+	if want := []string{"nginx:1.28.3"}; !slices.Equal(upd.targets, want) {
+		t.Errorf("targets = %v, want %v", upd.targets, want)
+	}
+}
+
+// scanBlockingUpdater queues the update with a scan report and returns
+// ErrScanBlocked, as the engine does when the pre-update scan holds it back.
+type scanBlockingUpdater struct {
+	recordingUpdater
+	queue UpdateQueue
+}
+
+func (m *scanBlockingUpdater) UpdateContainer(_ context.Context, _, name, _ string) error {
+	m.queue.Add(PendingUpdate{
+		ContainerName: name,
+		ScanReport:    &scanner.ScanResult{Summary: scanner.Summary{Critical: 1, Total: 1}},
+		ScanThreshold: scanner.SeverityHigh,
+	})
+	return fmt.Errorf("%w: %s", engine.ErrScanBlocked, name)
+}
+
+func TestRunApprovedUpdate_ScanBlockedRequeuesWithReport(t *testing.T) {
+	q := &mockQueue{}
+	srv := newQueueExportTestServer(q)
+	srv.deps.Updater = &scanBlockingUpdater{queue: q}
+	srv.deps.Store = newMockHistoryStore()
+
+	err := srv.runApprovedUpdate(PendingUpdate{ContainerName: "web", CurrentImage: "nginx:1.26", NewerVersions: []string{"1.27"}})
+	if !errors.Is(err, engine.ErrScanBlocked) {
+		t.Fatalf("err = %v, want ErrScanBlocked", err)
+	}
+	last := q.items[len(q.items)-1]
+	if last.ScanReport == nil || last.ScanThreshold != scanner.SeverityHigh {
+		t.Errorf("re-queued entry lost the scan report: %+v", last)
+	}
+	if !slices.Equal(last.NewerVersions, []string{"1.27"}) {
+		t.Errorf("re-queued entry lost its versions: %v", last.NewerVersions)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Operator comments
+// ---------------------------------------------------------------------------
+
+// recordingHistoryStore keeps the records written through RecordUpdate.
+type recordingHistoryStore struct {
+	*mockHistoryStore
+	mu      sync.Mutex
+	written []UpdateRecord
+}
+
+func (m *recordingHistoryStore) RecordUpdate(rec UpdateRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.written = append(m.written, rec)
+	return nil
+}
+
+func TestApiReject_CommentCopiedToHistoryAndLog(t *testing.T) {
+	q := &orderedQueue{mockQueue{items: []PendingUpdate{
+		{ContainerName: "nextcloud", CurrentImage: "nextcloud:29", NewerVersions: []string{"30"}},
+	}}}
+	hist := &recordingHistoryStore{mockHistoryStore: newMockHistoryStore()}
+	logs := &mockEventLogger{}
+	srv := newQueueExportTestServer(q)
+	srv.deps.Store = hist
+	srv.deps.EventLog = logs
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/reject/nextcloud", strings.NewReader(`{"comment":"waiting for plugin compatibility"}`))
+	r.SetPathValue("key", "nextcloud")
+	srv.apiReject(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+
+	if len(hist.written) != 1 {
+		t.Fatalf("history records = %d, want 1", len(hist.written))
+	}
+	rec := hist.written[0]
+	if rec.Outcome != "rejected" || rec.Comment != "waiting for plugin compatibility" || rec.NewImage != "nextcloud:30" {
+		t.Errorf("history record = %+v", rec)
+	}
+	if len(logs.entries) != 1 || !strings.Contains(logs.entries[0].Message, "waiting for plugin compatibility") {
+		t.Errorf("activity log = %+v, want the comment in the message", logs.entries)
+	}
+}
+
+func TestApiIgnoreVersion_UsesStoredComment(t *testing.T) {
+	q := &orderedQueue{mockQueue{items: []PendingUpdate{
+		{ContainerName: "app", CurrentImage: "app:2.9", NewerVersions: []string{"3.0"}},
+	}}}
+	hist := &recordingHistoryStore{mockHistoryStore: newMockHistoryStore()}
+	srv := newQueueExportTestServer(q)
+	srv.deps.Store = hist
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPut, "/api/queue/app/comment", strings.NewReader(`{"comment":"  3.0 drops the plugin API  "}`))
+	r.SetPathValue("key", "app")
+	srv.apiQueueComment(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("comment status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	if item, _ := q.Get("app"); item.Comment != "3.0 drops the plugin API" {
+		t.Errorf("queue comment = %q", item.Comment)
+	}
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodPost, "/api/ignore/app", nil)
+	r.SetPathValue("key", "app")
+	srv.apiIgnoreVersion(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("ignore status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	if len(hist.written) != 1 || hist.written[0].Outcome != "ignored" || hist.written[0].Comment != "3.0 drops the plugin API" {
+		t.Errorf("history = %+v, want an ignored record with the stored comment", hist.written)
+	}
+}
+
+func TestApiQueueComment_Validation(t *testing.T) {
+	q := &mockQueue{items: []PendingUpdate{{ContainerName: "web"}}}
+	srv := newQueueExportTestServer(q)
+
+	tests := []struct {
+		key, body string
+		want      int
+	}{
+		{"web", `{"comment":"` + strings.Repeat("x", maxQueueCommentLen+1) + `"}`, http.StatusBadRequest},
+		{"web", `not json`, http.StatusBadRequest},
+		{"db", `{"comment":"hold"}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPut, "/api/queue/"+tt.key+"/comment", strings.NewReader(tt.body))
+		r.SetPathValue("key", tt.key)
+		srv.apiQueueComment(w, r)
+		if w.Code != tt.want {
+			t.Errorf("key %s body %.20s: status = %d, want %d", tt.key, tt.body, w.Code, tt.want)
+		}
+	}
+}
+
+func TestRunApprovedUpdate_RemoteRecordKeepsComment(t *testing.T) {
+	hist := &recordingHistoryStore{mockHistoryStore: newMockHistoryStore()}
+	srv := newQueueExportTestServer(&mockQueue{})
+	srv.deps.Store = hist
+	srv.deps.Swarm = &mockSwarmProvider{}
+
+	update := PendingUpdate{ContainerName: "svc", Type: "service", HostID: "h1", Comment: "after the release freeze"}
+	if err := srv.runApprovedUpdate(update); err != nil {
+		t.Fatalf("runApprovedUpdate: %v", err)
+	}
+	if len(hist.written) != 1 || hist.written[0].Comment != "after the release freeze" {
+		t.Errorf("history = %+v, want the approval comment", hist.written)
+	}
+}
+
+// abortingUpdater fails every update with the pre-pull check sentinel.
+type abortingUpdater struct{ recordingUpdater }
+
+func (m *abortingUpdater) UpdateContainer(ctx context.Context, id, name, target string) error {
+	_ = m.recordingUpdater.UpdateContainer(ctx, id, name, target)
+	return fmt.Errorf("registry docker.io unreachable: %w", engine.ErrPullCheckFailed)
+}
+
+func TestRunApprovedUpdate_PullCheckFailedReenqueues(t *testing.T) {
+	q := &orderedQueue{mockQueue{}}
+	history := newMockHistoryStore()
+	srv := newQueueExportTestServer(q)
+	srv.deps.Docker = &mockContainerLister{}
+	srv.deps.Updater = &abortingUpdater{}
+	srv.deps.Store = history
+
+	err := srv.runApprovedUpdate(PendingUpdate{ContainerName: "web", CurrentImage: "nginx:1"})
+	if !errors.Is(err, engine.ErrPullCheckFailed) {
+		t.Fatalf("err = %v, want ErrPullCheckFailed", err)
+	}
+	if _, ok := q.Get("web"); !ok {
+		t.Error("aborted update should be back in the queue")
+	}
+	if len(history.records) != 0 {
+		t.Errorf("history = %+v, want none (the engine records the abort)", history.records)
+	}
+}
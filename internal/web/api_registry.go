@@ -281,18 +281,21 @@ func (s *Server) apiTestRegistryCredential(w http.ResponseWriter, r *http.Reques
 
 // apiGetRateLimits returns rate limit status for all registries (lower permission, for dashboard polling).
 func (s *Server) apiGetRateLimits(w http.ResponseWriter, r *http.Request) {
-	if s.deps.RateTracker == nil {
-		writeJSON(w, http.StatusOK, map[string]any{
+	writeJSON(w, http.StatusOK, rateLimitsResponse(s.deps.RateTracker))
+}
+
+// rateLimitsResponse is the /api/ratelimits body, shared with exporter mode.
+func rateLimitsResponse(p RateLimitProvider) map[string]any {
+	if p == nil {
+		return map[string]any{
 			"health":     "ok",
 			"registries": []RateLimitStatus{},
-		})
-		return
+		}
+	}
+	return map[string]any{
+		"health":     p.OverallHealth(),
+		"registries": p.Status(),
 	}
-
-	writeJSON(w, http.StatusOK, map[string]any{
-		"health":     s.deps.RateTracker.OverallHealth(),
-		"registries": s.deps.RateTracker.Status(),
-	})
 }
 
 // apiGetGHCRAlternatives returns all known GHCR alternatives for dashboard badges.
@@ -405,7 +408,7 @@ func (s *Server) apiSwitchToGHCR(w http.ResponseWriter, r *http.Request) {
 
 		s.markRemoteUpdating(hostID, name)
 		go func() {
-			if err := s.deps.Cluster.UpdateRemoteContainer(context.Background(), hostID, name, ghcrImage, ""); err != nil {
+			if _, err := s.deps.Cluster.UpdateRemoteContainer(context.Background(), hostID, name, ghcrImage, ""); err != nil {
 				s.deps.Log.Error("remote GHCR switch failed", "name", name, "host", hostID, "ghcr_image", ghcrImage, "error", err)
 				s.deps.EventBus.Publish(events.SSEEvent{
 					Type:          events.EventContainerUpdate,
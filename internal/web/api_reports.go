@@ -0,0 +1,140 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/report"
+)
+
+// apiReport builds the summary report. ?format=html|pdf downloads a
+// rendered copy; ?days=N changes the period (default 7). The report covers
+// every container, so scoped users can't read it.
+func (s *Server) apiReport(w http.ResponseWriter, r *http.Request) {
+	if !unscopedOnly(w, r) {
+		return
+	}
+	if s.deps.Reports == nil {
+		writeError(w, http.StatusNotImplemented, "reports not available")
+		return
+	}
+	period := report.Period
+	if v := r.URL.Query().Get("days"); v != "" {
+		days, err := strconv.Atoi(v)
+		if err != nil || days < 1 || days > 365 {
+			writeError(w, http.StatusBadRequest, "days must be between 1 and 365")
+			return
+		}
+		period = time.Duration(days) * 24 * time.Hour
+	}
+
+	rep, err := s.deps.Reports.Build(period)
+	if err != nil {
+		s.deps.Log.Error("failed to build report", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to build report")
+		return
+	}
+
+	filename := "sentinel-report-" + rep.To.Format("2006-01-02")
+	switch r.URL.Query().Get("format") {
+	case "html":
+		body, err := rep.HTML()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to render report")
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Disposition", "attachment; filename="+filename+".html")
+		_, _ = w.Write(body)
+	case "pdf":
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", "attachment; filename="+filename+".pdf")
+		_, _ = w.Write(rep.PDF())
+	default:
+		writeJSON(w, http.StatusOK, rep)
+	}
+}
+
+// apiSendReport sends the weekly report through the notification channels now.
+func (s *Server) apiSendReport(w http.ResponseWriter, r *http.Request) {
+	if s.deps.Reports == nil {
+		writeError(w, http.StatusNotImplemented, "reports not available")
+		return
+	}
+	if err := s.deps.Reports.Send(r.Context()); err != nil {
+		s.deps.Log.Error("failed to send report", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to send report")
+		return
+	}
+	s.logEvent(r, "report_sent", "", "Weekly report sent")
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// apiGetReportSettings returns the weekly report schedule.
+func (s *Server) apiGetReportSettings(w http.ResponseWriter, r *http.Request) {
+	settings := map[string]string{
+		report.SettingEnabled: "false",
+		report.SettingDay:     "monday",
+		report.SettingTime:    "09:00",
+	}
+	if s.deps.SettingsStore != nil {
+		for key := range settings {
+			if val, err := s.deps.SettingsStore.LoadSetting(key); err == nil && val != "" {
+				settings[key] = val
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, settings)
+}
+
+// apiSaveReportSettings saves the weekly report schedule and reschedules it.
+func (s *Server) apiSaveReportSettings(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Enabled *bool  `json:"report_enabled,omitempty"`
+		Day     string `json:"report_day,omitempty"`
+		Time    string `json:"report_time,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if s.deps.SettingsStore == nil {
+		writeError(w, http.StatusNotImplemented, "settings store not available")
+		return
+	}
+
+	if body.Day != "" && !report.ValidWeekday(body.Day) {
+		writeError(w, http.StatusBadRequest, "invalid day, use a weekday name")
+		return
+	}
+	if body.Time != "" {
+		if _, err := time.Parse("15:04", body.Time); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid time format, use HH:MM")
+			return
+		}
+	}
+
+	save := func(key, val string) {
+		if err := s.deps.SettingsStore.SaveSetting(key, val); err != nil {
+			s.deps.Log.Warn("failed to save report setting", "key", key, "error", err)
+		}
+	}
+	if body.Enabled != nil {
+		save(report.SettingEnabled, strconv.FormatBool(*body.Enabled))
+	}
+	if body.Day != "" {
+		save(report.SettingDay, strings.ToLower(body.Day))
+	}
+	if body.Time != "" {
+		save(report.SettingTime, body.Time)
+	}
+
+	if s.deps.Reports != nil {
+		s.deps.Reports.Reschedule()
+	}
+	s.logEvent(r, "settings", "", "Weekly report settings updated")
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
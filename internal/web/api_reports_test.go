@@ -0,0 +1,86 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/report"
+)
+
+type mockReportService struct {
+	period      time.Duration
+	rescheduled bool
+	applied     []report.Entry
+}
+
+func (m *mockReportService) Build(period time.Duration) (*report.Report, error) {
+	m.period = period
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	return &report.Report{From: now.Add(-period), To: now, Applied: m.applied}, nil
+}
+func (m *mockReportService) Send(context.Context) error { return nil }
+func (m *mockReportService) Reschedule()                { m.rescheduled = true }
+
+func TestApiReport_Formats(t *testing.T) {
+	reports := &mockReportService{}
+	srv := newTestServer(newMockSettingsStore())
+	srv.deps.Reports = reports
+
+	w := httptest.NewRecorder()
+	srv.apiReport(w, httptest.NewRequest(http.MethodGet, "/api/reports?format=pdf&days=30", nil))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/pdf" {
+		t.Fatalf("pdf: status = %d, type = %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	if !bytes.HasPrefix(w.Body.Bytes(), []byte("%PDF")) {
+		t.Error("pdf body missing header")
+	}
+	if reports.period != 30*24*time.Hour {
+		t.Errorf("period = %v, want 30 days", reports.period)
+	}
+
+	w = httptest.NewRecorder()
+	srv.apiReport(w, httptest.NewRequest(http.MethodGet, "/api/reports?format=html", nil))
+	if !strings.Contains(w.Header().Get("Content-Disposition"), "sentinel-report-2026-10-14.html") {
+		t.Errorf("html disposition = %q", w.Header().Get("Content-Disposition"))
+	}
+
+	w = httptest.NewRecorder()
+	srv.apiReport(w, httptest.NewRequest(http.MethodGet, "/api/reports?days=0", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("days=0 status = %d, want 400", w.Code)
+	}
+}
+
+func TestApiSaveReportSettings(t *testing.T) {
+	ss := newMockSettingsStore()
+	reports := &mockReportService{}
+	srv := newTestServer(ss)
+	srv.deps.Reports = reports
+	srv.deps.Log = slog.Default()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/settings/report", strings.NewReader(`{"report_enabled":true,"report_day":"Friday","report_time":"17:30"}`))
+	srv.apiSaveReportSettings(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	if ss.data[report.SettingEnabled] != "true" || ss.data[report.SettingDay] != "friday" || ss.data[report.SettingTime] != "17:30" {
+		t.Errorf("saved = %v", ss.data)
+	}
+	if !reports.rescheduled {
+		t.Error("scheduler not rescheduled")
+	}
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodPost, "/api/settings/report", strings.NewReader(`{"report_day":"someday"}`))
+	srv.apiSaveReportSettings(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("invalid day status = %d, want 400", w.Code)
+	}
+}
@@ -0,0 +1,137 @@
+package web
+
+import (
+	"net/http"
+)
+
+// apiListNetworks returns Docker networks with their attached containers.
+func (s *Server) apiListNetworks(w http.ResponseWriter, r *http.Request) {
+	if s.deps.Resources == nil {
+		writeError(w, http.StatusServiceUnavailable, "network inventory not available")
+		return
+	}
+	networks, err := s.deps.Resources.ListNetworks(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list networks: "+err.Error())
+		return
+	}
+	orphans := 0
+	for _, n := range networks {
+		if n.Orphan {
+			orphans++
+		}
+	}
+	if requestScope(r) != nil {
+		allowed := s.scopeFilter(r)
+		for i := range networks {
+			networks[i].Containers = filterNames(networks[i].Containers, allowed)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"networks": networks, "orphans": orphans})
+}
+
+// apiRemoveNetwork removes an orphaned network. Networks with containers
+// attached, and the built-in ones, are refused.
+func (s *Server) apiRemoveNetwork(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if id == "" {
+		writeError(w, http.StatusBadRequest, "network ID required")
+		return
+	}
+	if s.deps.Resources == nil {
+		writeError(w, http.StatusServiceUnavailable, "network inventory not available")
+		return
+	}
+	networks, err := s.deps.Resources.ListNetworks(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list networks: "+err.Error())
+		return
+	}
+	var target *NetworkInfo
+	for i := range networks {
+		if networks[i].ID == id || networks[i].Name == id {
+			target = &networks[i]
+			break
+		}
+	}
+	if target == nil {
+		writeError(w, http.StatusNotFound, "network not found")
+		return
+	}
+	if !target.Orphan {
+		writeError(w, http.StatusConflict, "only orphaned networks can be removed")
+		return
+	}
+	if err := s.deps.Resources.RemoveNetwork(r.Context(), target.ID); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to remove network: "+err.Error())
+		return
+	}
+	s.logEvent(r, "network_remove", "", "removed network: "+target.Name)
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// apiListVolumes returns Docker volumes with the containers mounting them.
+func (s *Server) apiListVolumes(w http.ResponseWriter, r *http.Request) {
+	if s.deps.Resources == nil {
+		writeError(w, http.StatusServiceUnavailable, "volume inventory not available")
+		return
+	}
+	volumes, err := s.deps.Resources.ListVolumes(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list volumes: "+err.Error())
+		return
+	}
+	orphans := 0
+	for _, v := range volumes {
+		if v.Orphan {
+			orphans++
+		}
+	}
+	if requestScope(r) != nil {
+		allowed := s.scopeFilter(r)
+		for i := range volumes {
+			volumes[i].Containers = filterNames(volumes[i].Containers, allowed)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"volumes": volumes, "orphans": orphans})
+}
+
+// apiRemoveVolume removes an orphaned volume. Volumes mounted by any
+// container, running or stopped, are refused.
+func (s *Server) apiRemoveVolume(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "volume name required")
+		return
+	}
+	if s.deps.Resources == nil {
+		writeError(w, http.StatusServiceUnavailable, "volume inventory not available")
+		return
+	}
+	volumes, err := s.deps.Resources.ListVolumes(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list volumes: "+err.Error())
+		return
+	}
+	var target *VolumeInfo
+	for i := range volumes {
+		if volumes[i].Name == name {
+			target = &volumes[i]
+			break
+		}
+	}
+	if target == nil {
+		writeError(w, http.StatusNotFound, "volume not found")
+		return
+	}
+	if !target.Orphan {
+		writeError(w, http.StatusConflict, "only orphaned volumes can be removed")
+		return
+	}
+	if err := s.deps.Resources.RemoveVolume(r.Context(), name); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to remove volume: "+err.Error())
+		return
+	}
+	s.logEvent(r, "volume_remove", "", "removed volume: "+name)
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
@@ -0,0 +1,112 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type mockResourceManager struct {
+	networks []NetworkInfo
+	volumes  []VolumeInfo
+	removed  []string
+}
+
+func (m *mockResourceManager) ListNetworks(context.Context) ([]NetworkInfo, error) {
+	return m.networks, nil
+}
+func (m *mockResourceManager) ListVolumes(context.Context) ([]VolumeInfo, error) {
+	return m.volumes, nil
+}
+func (m *mockResourceManager) RemoveNetwork(_ context.Context, id string) error {
+	m.removed = append(m.removed, id)
+	return nil
+}
+func (m *mockResourceManager) RemoveVolume(_ context.Context, name string) error {
+	m.removed = append(m.removed, name)
+	return nil
+}
+
+func newResourceTestServer() (*Server, *mockResourceManager) {
+	res := &mockResourceManager{
+		networks: []NetworkInfo{
+			{ID: "n1", Name: "bridge", Builtin: true},
+			{ID: "n2", Name: "app_default", Containers: []string{"web"}},
+			{ID: "n3", Name: "old_default", Orphan: true},
+		},
+		volumes: []VolumeInfo{
+			{Name: "pgdata", Containers: []string{"db"}},
+			{Name: "stale", Orphan: true},
+		},
+	}
+	srv := newTestServer(newMockSettingsStore())
+	srv.deps.Resources = res
+	return srv, res
+}
+
+func TestApiListNetworks_CountsOrphans(t *testing.T) {
+	srv, _ := newResourceTestServer()
+
+	w := httptest.NewRecorder()
+	srv.apiListNetworks(w, httptest.NewRequest(http.MethodGet, "/api/networks", nil))
+	var resp struct {
+		Networks []NetworkInfo `json:"networks"`
+		Orphans  int           `json:"orphans"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Networks) != 3 || resp.Orphans != 1 {
+		t.Errorf("networks = %d, orphans = %d", len(resp.Networks), resp.Orphans)
+	}
+}
+
+func TestApiRemoveNetwork_OnlyOrphans(t *testing.T) {
+	srv, res := newResourceTestServer()
+
+	remove := func(id string) int {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodDelete, "/api/networks/"+id, nil)
+		r.SetPathValue("id", id)
+		srv.apiRemoveNetwork(w, r)
+		return w.Code
+	}
+	if code := remove("bridge"); code != http.StatusConflict {
+		t.Errorf("builtin: status = %d, want 409", code)
+	}
+	if code := remove("n2"); code != http.StatusConflict {
+		t.Errorf("in use: status = %d, want 409", code)
+	}
+	if code := remove("missing"); code != http.StatusNotFound {
+		t.Errorf("missing: status = %d, want 404", code)
+	}
+	if code := remove("old_default"); code != http.StatusOK {
+		t.Errorf("orphan: status = %d, want 200", code)
+	}
+	if len(res.removed) != 1 || res.removed[0] != "n3" {
+		t.Errorf("removed = %v, want [n3]", res.removed)
+	}
+}
+
+func TestApiRemoveVolume_OnlyOrphans(t *testing.T) {
+	srv, res := newResourceTestServer()
+
+	remove := func(name string) int {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodDelete, "/api/volumes/"+name, nil)
+		r.SetPathValue("name", name)
+		srv.apiRemoveVolume(w, r)
+		return w.Code
+	}
+	if code := remove("pgdata"); code != http.StatusConflict {
+		t.Errorf("in use: status = %d, want 409", code)
+	}
+	if code := remove("stale"); code != http.StatusOK {
+		t.Errorf("orphan: status = %d, want 200", code)
+	}
+	if len(res.removed) != 1 || res.removed[0] != "stale" {
+		t.Errorf("removed = %v, want [stale]", res.removed)
+	}
+}
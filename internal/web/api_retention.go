@@ -0,0 +1,132 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// maxRetentionDays caps day limits at roughly a century.
+const maxRetentionDays = 36500
+
+// retentionKinds are the pruned buckets, in the order they are reported.
+var retentionKinds = []string{"history", "logs", "snapshots"}
+
+// RetentionLimit is one bucket's retention: days and/or a record count.
+// Zero means no limit.
+type RetentionLimit struct {
+	Days       int `json:"days"`
+	MaxRecords int `json:"max_records"`
+}
+
+// apiSetRetention saves the retention for update history, the activity log
+// and snapshots, then prunes straight away. Kinds missing from the body are
+// left as they are.
+// Body: {"history": {"days": 90, "max_records": 0}, "logs": {...}, "snapshots": {...}}
+func (s *Server) apiSetRetention(w http.ResponseWriter, r *http.Request) {
+	var body map[string]*RetentionLimit
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if s.deps.SettingsStore == nil {
+		writeError(w, http.StatusNotImplemented, "settings store not available")
+		return
+	}
+	for kind, lim := range body {
+		if !isRetentionKind(kind) {
+			writeError(w, http.StatusBadRequest, "unknown retention kind "+strconv.Quote(kind)+" (want history, logs or snapshots)")
+			return
+		}
+		if lim == nil {
+			continue
+		}
+		if lim.Days < 0 || lim.Days > maxRetentionDays || lim.MaxRecords < 0 {
+			writeError(w, http.StatusBadRequest, kind+": days must be 0-"+strconv.Itoa(maxRetentionDays)+" and max_records must be 0 or more")
+			return
+		}
+	}
+
+	for _, kind := range retentionKinds {
+		lim := body[kind]
+		if lim == nil {
+			continue
+		}
+		for key, v := range map[string]int{
+			"retention_" + kind + "_days": lim.Days,
+			"retention_" + kind + "_max":  lim.MaxRecords,
+		} {
+			if err := s.deps.SettingsStore.SaveSetting(key, strconv.Itoa(v)); err != nil {
+				s.deps.Log.Error("failed to save "+key, "error", err)
+				writeError(w, http.StatusInternalServerError, "failed to save setting")
+				return
+			}
+		}
+		s.logEvent(r, "settings", "", "Retention for "+kind+" set to "+describeRetention(*lim))
+	}
+
+	resp := map[string]any{"message": "Retention saved"}
+	if s.deps.Maintainer != nil {
+		rep, err := s.deps.Maintainer.ApplyRetention()
+		if err != nil {
+			s.deps.Log.Warn("retention prune failed", "error", err)
+			resp["message"] = "Retention saved, but pruning failed: " + err.Error()
+		} else {
+			resp["removed"] = rep
+			if n := rep.History + rep.Logs + rep.Snapshots; n > 0 {
+				resp["message"] = fmt.Sprintf("Retention saved, %d old entries removed", n)
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// apiAdminCompact rewrites the database file to hand space freed by pruning
+// back to the filesystem. Other requests wait while it runs.
+func (s *Server) apiAdminCompact(w http.ResponseWriter, r *http.Request) {
+	if s.deps.Maintainer == nil {
+		writeError(w, http.StatusNotImplemented, "compaction not available")
+		return
+	}
+	before, after, err := s.deps.Maintainer.Compact()
+	if err != nil {
+		s.deps.Log.Error("database compaction failed", "error", err)
+		writeError(w, http.StatusInternalServerError, "compaction failed: "+err.Error())
+		return
+	}
+	reclaimed := before - after
+	if reclaimed < 0 {
+		reclaimed = 0
+	}
+	s.deps.Log.Info("database compacted", "before", before, "after", after)
+	s.logEvent(r, "compact", "", fmt.Sprintf("Database compacted (%d bytes reclaimed)", reclaimed))
+	writeJSON(w, http.StatusOK, map[string]any{
+		"before":    before,
+		"after":     after,
+		"reclaimed": reclaimed,
+		"message":   fmt.Sprintf("Database compacted: %.1f MiB reclaimed", float64(reclaimed)/(1<<20)),
+	})
+}
+
+func isRetentionKind(kind string) bool {
+	for _, k := range retentionKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// describeRetention renders a limit for the activity log.
+func describeRetention(l RetentionLimit) string {
+	switch {
+	case l.Days > 0 && l.MaxRecords > 0:
+		return fmt.Sprintf("%d days, at most %d records", l.Days, l.MaxRecords)
+	case l.Days > 0:
+		return fmt.Sprintf("%d days", l.Days)
+	case l.MaxRecords > 0:
+		return fmt.Sprintf("at most %d records", l.MaxRecords)
+	}
+	return "keep forever"
+}
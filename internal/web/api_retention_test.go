@@ -0,0 +1,121 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type mockMaintainer struct {
+	report     RetentionReport
+	pruned     int
+	compactErr error
+}
+
+func (m *mockMaintainer) ApplyRetention() (RetentionReport, error) {
+	m.pruned++
+	return m.report, nil
+}
+
+func (m *mockMaintainer) Compact() (int64, int64, error) {
+	if m.compactErr != nil {
+		return 0, 0, m.compactErr
+	}
+	return 10 << 20, 4 << 20, nil
+}
+
+func TestApiSetRetention(t *testing.T) {
+	ss := newMockSettingsStore()
+	m := &mockMaintainer{report: RetentionReport{History: 3, Logs: 2}}
+	s := &Server{deps: Dependencies{SettingsStore: ss, Maintainer: m, Log: slog.New(slog.NewTextHandler(io.Discard, nil))}}
+
+	for _, tt := range []struct {
+		body   string
+		status int
+	}{
+		{`{"history":{"days":-1}}`, http.StatusBadRequest},
+		{`{"events":{"days":7}}`, http.StatusBadRequest},
+		{`{"logs":{"max_records":-5}}`, http.StatusBadRequest},
+		{`not json`, http.StatusBadRequest},
+	} {
+		w := httptest.NewRecorder()
+		s.apiSetRetention(w, httptest.NewRequest(http.MethodPost, "/api/settings/retention", strings.NewReader(tt.body)))
+		if w.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.body, w.Code, tt.status)
+		}
+	}
+	if len(ss.data) != 0 || m.pruned != 0 {
+		t.Fatalf("invalid requests saved %v and pruned %d times", ss.data, m.pruned)
+	}
+
+	w := httptest.NewRecorder()
+	body := `{"history":{"days":90},"logs":{"days":30,"max_records":5000}}`
+	s.apiSetRetention(w, httptest.NewRequest(http.MethodPost, "/api/settings/retention", strings.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+	}
+	want := map[string]string{
+		"retention_history_days": "90",
+		"retention_history_max":  "0",
+		"retention_logs_days":    "30",
+		"retention_logs_max":     "5000",
+	}
+	for k, v := range want {
+		if ss.data[k] != v {
+			t.Errorf("%s = %q, want %q", k, ss.data[k], v)
+		}
+	}
+	if _, ok := ss.data["retention_snapshots_days"]; ok {
+		t.Error("snapshots retention saved although it was not in the body")
+	}
+	if m.pruned != 1 {
+		t.Errorf("pruned %d times, want once", m.pruned)
+	}
+	var resp struct {
+		Message string          `json:"message"`
+		Removed RetentionReport `json:"removed"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Removed.History != 3 || !strings.Contains(resp.Message, "5 old entries") {
+		t.Errorf("response = %+v", resp)
+	}
+}
+
+func TestApiAdminCompact(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	w := httptest.NewRecorder()
+	(&Server{deps: Dependencies{Log: log}}).apiAdminCompact(w, httptest.NewRequest(http.MethodPost, "/api/admin/compact", nil))
+	if w.Code != http.StatusNotImplemented {
+		t.Errorf("without maintainer: status = %d, want 501", w.Code)
+	}
+
+	m := &mockMaintainer{}
+	s := &Server{deps: Dependencies{Maintainer: m, Log: log}}
+	w = httptest.NewRecorder()
+	s.apiAdminCompact(w, httptest.NewRequest(http.MethodPost, "/api/admin/compact", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+	}
+	var resp map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp["reclaimed"] != float64(6<<20) {
+		t.Errorf("reclaimed = %v, want %d", resp["reclaimed"], 6<<20)
+	}
+
+	m.compactErr = errors.New("disk full")
+	w = httptest.NewRecorder()
+	s.apiAdminCompact(w, httptest.NewRequest(http.MethodPost, "/api/admin/compact", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("on error: status = %d, want 500", w.Code)
+	}
+}
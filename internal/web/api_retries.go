@@ -0,0 +1,111 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/docker"
+	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+)
+
+// retrySet returns the retry state of every container with failed
+// auto-updates, or an empty map when the store is not wired.
+func (s *Server) retrySet() map[string]RetryState {
+	if s.deps.Retries == nil {
+		return map[string]RetryState{}
+	}
+	return s.deps.Retries.AllRetryStates()
+}
+
+// apiListRetries returns every container that is backing off or marked
+// failing after failed auto-updates. Scoped users only see their own
+// containers.
+func (s *Server) apiListRetries(w http.ResponseWriter, r *http.Request) {
+	retries := s.retrySet()
+	if requestScope(r) != nil {
+		allowed := s.scopeFilter(r)
+		for key := range retries {
+			if !allowed(key) {
+				delete(retries, key)
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, retries)
+}
+
+// apiClearRetry resets a container's failed update count so auto-updates
+// resume on the next scan.
+func (s *Server) apiClearRetry(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if !isValidContainerName(name) {
+		writeError(w, http.StatusBadRequest, "invalid container name")
+		return
+	}
+	if s.deps.Retries == nil {
+		writeError(w, http.StatusNotImplemented, "retry store not available")
+		return
+	}
+	if _, ok := s.retrySet()[name]; !ok {
+		writeError(w, http.StatusNotFound, name+" has no failed updates")
+		return
+	}
+	if err := s.deps.Retries.DeleteRetryState(name); err != nil {
+		s.deps.Log.Error("failed to clear retry state", "name", name, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to clear retry state")
+		return
+	}
+
+	s.logEvent(r, "retry_clear", name, "Failed update state cleared")
+	s.deps.EventBus.Publish(events.SSEEvent{
+		Type:          events.EventContainerUpdate,
+		ContainerName: name,
+		Message:       "auto-update retries reset",
+		Timestamp:     time.Now(),
+	})
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok", "name": name})
+}
+
+// apiSetRetryPolicy sets how many failed auto-updates are allowed before a
+// container is marked failing (0 = retry forever) and the base backoff,
+// which doubles after each failure. Empty values restore the defaults.
+func (s *Server) apiSetRetryPolicy(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		MaxAttempts *int   `json:"max_attempts"`
+		Backoff     string `json:"backoff"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+	if req.MaxAttempts != nil && (*req.MaxAttempts < 0 || *req.MaxAttempts > 100) {
+		writeError(w, http.StatusBadRequest, "max_attempts must be between 0 and 100")
+		return
+	}
+	if req.Backoff != "" {
+		if d, err := docker.ParseDurationWithDays(req.Backoff); err != nil || d <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid backoff duration: "+req.Backoff)
+			return
+		}
+	}
+	if s.deps.SettingsStore == nil {
+		writeError(w, http.StatusNotImplemented, "settings store not available")
+		return
+	}
+
+	attempts := ""
+	if req.MaxAttempts != nil {
+		attempts = strconv.Itoa(*req.MaxAttempts)
+	}
+	for key, val := range map[string]string{"retry_max_attempts": attempts, "retry_backoff": req.Backoff} {
+		if err := s.deps.SettingsStore.SaveSetting(key, val); err != nil {
+			s.deps.Log.Error("failed to save "+key, "error", err)
+			writeError(w, http.StatusInternalServerError, "failed to save setting")
+			return
+		}
+	}
+
+	s.logEvent(r, "settings", "", "Update retry policy changed")
+	writeJSON(w, http.StatusOK, map[string]string{"message": "retry policy updated"})
+}
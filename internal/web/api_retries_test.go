@@ -0,0 +1,80 @@
+package web
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+)
+
+type mockRetryStore struct {
+	states map[string]RetryState
+}
+
+func (m *mockRetryStore) AllRetryStates() map[string]RetryState {
+	out := make(map[string]RetryState, len(m.states))
+	for k, v := range m.states {
+		out[k] = v
+	}
+	return out
+}
+
+func (m *mockRetryStore) DeleteRetryState(name string) error {
+	delete(m.states, name)
+	return nil
+}
+
+func TestApiClearRetry(t *testing.T) {
+	rs := &mockRetryStore{states: map[string]RetryState{"nginx": {Attempts: 3, Failing: true}}}
+	srv := &Server{deps: Dependencies{Retries: rs, EventBus: events.New(), Log: slog.Default()}}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodDelete, "/api/containers/nginx/retry", nil)
+	r.SetPathValue("name", "nginx")
+	srv.apiClearRetry(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	if _, ok := rs.states["nginx"]; ok {
+		t.Error("retry state still present after clear")
+	}
+
+	// Clearing again is a 404.
+	w = httptest.NewRecorder()
+	srv.apiClearRetry(w, r)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("second clear status = %d, want 404", w.Code)
+	}
+}
+
+func TestApiSetRetryPolicy(t *testing.T) {
+	ss := newMockSettingsStore()
+	srv := newTestServer(ss)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/settings/retry-policy", strings.NewReader(`{"max_attempts":5,"backoff":"1h"}`))
+	srv.apiSetRetryPolicy(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	if got, _ := ss.LoadSetting("retry_max_attempts"); got != "5" {
+		t.Errorf("retry_max_attempts = %q, want 5", got)
+	}
+	if got, _ := ss.LoadSetting("retry_backoff"); got != "1h" {
+		t.Errorf("retry_backoff = %q, want 1h", got)
+	}
+
+	for _, body := range []string{`{"max_attempts":-1}`, `{"backoff":"soon"}`} {
+		w = httptest.NewRecorder()
+		r = httptest.NewRequest(http.MethodPost, "/api/settings/retry-policy", strings.NewReader(body))
+		srv.apiSetRetryPolicy(w, r)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("body %s: status = %d, want 400", body, w.Code)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/engine"
+	"github.com/Will-Luck/Docker-Sentinel/internal/registry"
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+)
+
+// rolloutGroups returns the configured rollout groups, or an empty list.
+func (s *Server) rolloutGroups() []engine.RolloutGroup {
+	groups := []engine.RolloutGroup{}
+	if s.deps.SettingsStore == nil {
+		return groups
+	}
+	raw, _ := s.deps.SettingsStore.LoadSetting("rollout_groups")
+	parsed, err := engine.ParseRolloutGroups(raw)
+	if err != nil {
+		s.deps.Log.Warn("invalid stored rollout groups", "error", err)
+	} else if parsed != nil {
+		groups = parsed
+	}
+	return groups
+}
+
+// apiListRollouts returns the rollout groups in order and every tracked
+// rollout with its current group and state. Scoped users only see rollouts
+// of images their containers run, and only their own updated containers.
+func (s *Server) apiListRollouts(w http.ResponseWriter, r *http.Request) {
+	rollouts := []store.Rollout{}
+	if s.deps.Rollouts != nil {
+		if list := s.deps.Rollouts.Rollouts(); list != nil {
+			rollouts = list
+		}
+	}
+	if requestScope(r) != nil {
+		rollouts = s.scopedRollouts(r, rollouts)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"groups": s.rolloutGroups(), "rollouts": rollouts})
+}
+
+// scopedRollouts keeps the rollouts of images run by a container in the
+// request's scope, trimming their updated lists to containers in scope.
+func (s *Server) scopedRollouts(r *http.Request, rollouts []store.Rollout) []store.Rollout {
+	allowed := s.scopeFilter(r)
+	images := make(map[string]bool)
+	if containers, err := s.deps.Docker.ListAllContainers(r.Context()); err == nil {
+		for _, c := range containers {
+			if allowed(containerName(c)) {
+				images[registry.RegistryHost(c.Image)+"/"+registry.RepoPath(c.Image)] = true
+			}
+		}
+	}
+	out := []store.Rollout{}
+	for _, ro := range rollouts {
+		if !images[ro.Image] {
+			continue
+		}
+		var updated []string
+		for _, name := range ro.Updated {
+			if allowed(name) {
+				updated = append(updated, name)
+			}
+		}
+		ro.Updated = updated
+		out = append(out, ro)
+	}
+	return out
+}
+
+// apiSaveRolloutGroups replaces the rollout groups.
+// Body: [{"name", "bake"}, ...] in update order. An empty list turns staged
+// rollouts off.
+func (s *Server) apiSaveRolloutGroups(w http.ResponseWriter, r *http.Request) {
+	if s.deps.SettingsStore == nil {
+		writeError(w, http.StatusNotImplemented, "settings store not available")
+		return
+	}
+	raw, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 64<<10))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "failed to read request body")
+		return
+	}
+	groups, err := engine.ParseRolloutGroups(string(raw))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	value := ""
+	if len(groups) > 0 {
+		data, err := json.Marshal(groups)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to encode rollout groups")
+			return
+		}
+		value = string(data)
+	}
+	if err := s.deps.SettingsStore.SaveSetting("rollout_groups", value); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to save rollout groups")
+		return
+	}
+
+	s.logEvent(r, "settings", "", "Rollout groups updated")
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok", "message": "rollout groups saved"})
+}
+
+// apiPauseRollout stops a rollout until it is resumed or promoted.
+// Body: {"key": "<image>@<target>"}
+func (s *Server) apiPauseRollout(w http.ResponseWriter, r *http.Request) {
+	s.rolloutAction(w, r, "paused", func(key string) (store.Rollout, error) {
+		return s.deps.Rollouts.PauseRollout(key)
+	})
+}
+
+// apiResumeRollout restarts a paused or halted rollout in its current group.
+func (s *Server) apiResumeRollout(w http.ResponseWriter, r *http.Request) {
+	s.rolloutAction(w, r, "resumed", func(key string) (store.Rollout, error) {
+		return s.deps.Rollouts.ResumeRollout(key)
+	})
+}
+
+// apiPromoteRollout lets the next group update now without waiting out the
+// current group's bake time.
+func (s *Server) apiPromoteRollout(w http.ResponseWriter, r *http.Request) {
+	s.rolloutAction(w, r, "promoted", func(key string) (store.Rollout, error) {
+		return s.deps.Rollouts.PromoteRollout(r.Context(), key)
+	})
+}
+
+// rolloutAction decodes the rollout key, applies fn and reports the
+// rollout's new state.
+func (s *Server) rolloutAction(w http.ResponseWriter, r *http.Request, verb string, fn func(key string) (store.Rollout, error)) {
+	if s.deps.Rollouts == nil {
+		writeError(w, http.StatusNotImplemented, "staged rollouts not available")
+		return
+	}
+	var body struct {
+		Key string `json:"key"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, 4096)).Decode(&body); err != nil || body.Key == "" {
+		writeError(w, http.StatusBadRequest, "key is required")
+		return
+	}
+
+	rollout, err := fn(body.Key)
+	switch {
+	case errors.Is(err, engine.ErrRolloutNotFound):
+		writeError(w, http.StatusNotFound, "rollout not found")
+		return
+	case errors.Is(err, engine.ErrRolloutCompleted):
+		writeError(w, http.StatusConflict, "rollout already completed")
+		return
+	case err != nil:
+		s.deps.Log.Error("rollout action failed", "rollout", body.Key, "action", verb, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to change rollout")
+		return
+	}
+
+	s.logEvent(r, "rollout", "", "Rollout "+body.Key+" "+verb)
+	writeJSON(w, http.StatusOK, rollout)
+}
@@ -0,0 +1,109 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/engine"
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+)
+
+type mockRollouts struct {
+	rollouts map[string]store.Rollout
+}
+
+func (m *mockRollouts) Rollouts() []store.Rollout {
+	var out []store.Rollout
+	for _, r := range m.rollouts {
+		out = append(out, r)
+	}
+	return out
+}
+
+func (m *mockRollouts) set(key, state string) (store.Rollout, error) {
+	r, ok := m.rollouts[key]
+	if !ok {
+		return store.Rollout{}, engine.ErrRolloutNotFound
+	}
+	if r.State == store.RolloutCompleted {
+		return r, engine.ErrRolloutCompleted
+	}
+	r.State = state
+	m.rollouts[key] = r
+	return r, nil
+}
+
+func (m *mockRollouts) PauseRollout(key string) (store.Rollout, error) {
+	return m.set(key, store.RolloutPaused)
+}
+func (m *mockRollouts) ResumeRollout(key string) (store.Rollout, error) {
+	return m.set(key, store.RolloutRunning)
+}
+func (m *mockRollouts) PromoteRollout(_ context.Context, key string) (store.Rollout, error) {
+	return m.set(key, store.RolloutRunning)
+}
+
+func TestApiRolloutActions(t *testing.T) {
+	ro := &mockRollouts{rollouts: map[string]store.Rollout{
+		"docker.io/library/nginx@1.28": {Key: "docker.io/library/nginx@1.28", Group: "canary", State: store.RolloutRunning},
+		"docker.io/library/redis@8.0":  {Key: "docker.io/library/redis@8.0", State: store.RolloutCompleted},
+	}}
+	srv := &Server{deps: Dependencies{Rollouts: ro, Log: slog.New(slog.NewTextHandler(io.Discard, nil))}}
+
+	w := httptest.NewRecorder()
+	srv.apiPauseRollout(w, httptest.NewRequest(http.MethodPost, "/api/rollouts/pause", strings.NewReader(`{"key":"docker.io/library/nginx@1.28"}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("pause status = %d: %s", w.Code, w.Body.String())
+	}
+	if got := ro.rollouts["docker.io/library/nginx@1.28"].State; got != store.RolloutPaused {
+		t.Errorf("state = %q, want paused", got)
+	}
+
+	for body, want := range map[string]int{
+		`{"key":"docker.io/library/redis@8.0"}`: http.StatusConflict,
+		`{"key":"nope"}`:                        http.StatusNotFound,
+		`{}`:                                    http.StatusBadRequest,
+	} {
+		w = httptest.NewRecorder()
+		srv.apiPromoteRollout(w, httptest.NewRequest(http.MethodPost, "/api/rollouts/promote", strings.NewReader(body)))
+		if w.Code != want {
+			t.Errorf("promote %s: status = %d, want %d", body, w.Code, want)
+		}
+	}
+}
+
+func TestApiSaveRolloutGroups(t *testing.T) {
+	ss := newMockSettingsStore()
+	srv := &Server{deps: Dependencies{SettingsStore: ss, Rollouts: &mockRollouts{}, Log: slog.New(slog.NewTextHandler(io.Discard, nil))}}
+
+	w := httptest.NewRecorder()
+	srv.apiSaveRolloutGroups(w, httptest.NewRequest(http.MethodPut, "/api/settings/rollout-groups", strings.NewReader(`[{"name":"Canary","bake":"6h"},{"name":"prod"}]`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	srv.apiListRollouts(w, httptest.NewRequest(http.MethodGet, "/api/rollouts", nil))
+	var got struct {
+		Groups   []engine.RolloutGroup `json:"groups"`
+		Rollouts []store.Rollout       `json:"rollouts"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Groups) != 2 || got.Groups[0].Name != "canary" || got.Rollouts == nil {
+		t.Errorf("list = %+v, want canary then prod and an empty rollout list", got)
+	}
+
+	w = httptest.NewRecorder()
+	srv.apiSaveRolloutGroups(w, httptest.NewRequest(http.MethodPut, "/api/settings/rollout-groups", strings.NewReader(`[{"name":"a","bake":"later"}]`)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("invalid bake status = %d, want 400", w.Code)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/Will-Luck/Docker-Sentinel/internal/auth"
+	"github.com/Will-Luck/Docker-Sentinel/internal/engine"
 	"github.com/Will-Luck/Docker-Sentinel/internal/events"
 	"github.com/Will-Luck/Docker-Sentinel/internal/registry"
 )
@@ -25,45 +26,8 @@ func (s *Server) apiServiceUpdate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Build the full target image reference (e.g. "nginx:1.29.5" not just "1.29.5").
-	var targetImage string
-	if pending, ok := s.deps.Queue.Get(name); ok {
-		if len(pending.NewerVersions) > 0 {
-			targetImage = webReplaceTag(pending.CurrentImage, pending.NewerVersions[0])
-		}
-	}
-
-	// Explicit version override from form/query — validate format to reject
-	// arbitrary strings (image refs are alphanumeric + . - _ / : @).
-	if v := r.FormValue("version"); v != "" {
-		if len(v) > 256 || !isValidImageRef(v) {
-			writeError(w, http.StatusBadRequest, "invalid version format")
-			return
-		}
-		targetImage = v
-	}
-
-	// Look up the service ID from the queue or list.
-	var serviceID string
-	if pending, ok := s.deps.Queue.Get(name); ok {
-		serviceID = pending.ContainerID
-	}
-	if serviceID == "" {
-		// Fall back to listing services.
-		services, err := s.deps.Swarm.ListServices(r.Context())
-		if err != nil {
-			writeError(w, http.StatusInternalServerError, "failed to list services")
-			return
-		}
-		for _, svc := range services {
-			if svc.Name == name {
-				serviceID = svc.ID
-				break
-			}
-		}
-	}
-	if serviceID == "" {
-		writeError(w, http.StatusNotFound, "service not found")
+	targetImage, serviceID, ok := s.serviceUpdateTarget(w, r, name)
+	if !ok {
 		return
 	}
 
@@ -110,6 +74,84 @@ func (s *Server) apiServiceUpdate(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]string{"status": "updating"})
 }
 
+// serviceUpdateTarget resolves the image a service update deploys and the
+// service's ID, writing an error response and returning false on failure.
+func (s *Server) serviceUpdateTarget(w http.ResponseWriter, r *http.Request, name string) (targetImage, serviceID string, ok bool) {
+	// Build the full target image reference (e.g. "nginx:1.29.5" not just "1.29.5").
+	if pending, ok := s.deps.Queue.Get(name); ok {
+		if len(pending.NewerVersions) > 0 {
+			targetImage = webReplaceTag(pending.CurrentImage, pending.NewerVersions[0])
+		}
+	}
+
+	// Explicit version override from form/query — validate format to reject
+	// arbitrary strings (image refs are alphanumeric + . - _ / : @).
+	if v := r.FormValue("version"); v != "" {
+		if len(v) > 256 || !isValidImageRef(v) {
+			writeError(w, http.StatusBadRequest, "invalid version format")
+			return "", "", false
+		}
+		targetImage = v
+	}
+
+	// Look up the service ID from the queue or list.
+	if pending, ok := s.deps.Queue.Get(name); ok {
+		serviceID = pending.ContainerID
+	}
+	if serviceID == "" {
+		// Fall back to listing services.
+		services, err := s.deps.Swarm.ListServices(r.Context())
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to list services")
+			return "", "", false
+		}
+		for _, svc := range services {
+			if svc.Name == name {
+				serviceID = svc.ID
+				break
+			}
+		}
+	}
+	if serviceID == "" {
+		writeError(w, http.StatusNotFound, "service not found")
+		return "", "", false
+	}
+	return targetImage, serviceID, true
+}
+
+// apiServicePlacement previews which nodes receive a service's tasks once it
+// is updated, from its constraints, placement preferences and the platforms
+// the target image is published for. Warnings flag tasks that would be left
+// pending or moved, before the update is approved.
+func (s *Server) apiServicePlacement(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if !isValidContainerName(name) {
+		writeError(w, http.StatusBadRequest, "invalid container name")
+		return
+	}
+
+	if s.deps.Swarm == nil || !s.deps.Swarm.IsSwarmMode() {
+		writeError(w, http.StatusBadRequest, "swarm mode not active")
+		return
+	}
+
+	targetImage, serviceID, ok := s.serviceUpdateTarget(w, r, name)
+	if !ok {
+		return
+	}
+
+	plan, err := s.deps.Swarm.PreviewPlacement(r.Context(), serviceID, targetImage)
+	if err != nil {
+		s.deps.Log.Warn("placement preview failed", "name", name, "error", err)
+		writeError(w, http.StatusBadGateway, "placement preview failed: "+err.Error())
+		return
+	}
+	if plan.Nodes == nil {
+		plan.Nodes = []engine.PlacementNode{}
+	}
+	writeJSON(w, http.StatusOK, plan)
+}
+
 // apiServiceRollback triggers a Swarm native rollback.
 func (s *Server) apiServiceRollback(w http.ResponseWriter, r *http.Request) {
 	name := r.PathValue("name")
@@ -222,8 +264,12 @@ func (s *Server) apiServicesList(w http.ResponseWriter, r *http.Request) {
 		pendingNames[p.ContainerName] = true
 	}
 
+	allowed := s.scopeFilter(r)
 	views := make([]serviceView, 0, len(details))
 	for _, d := range details {
+		if !allowed(d.Name) {
+			continue
+		}
 		views = append(views, s.buildServiceView(d, pendingNames, s.localHostAddr(r)))
 	}
 	writeJSON(w, http.StatusOK, views)
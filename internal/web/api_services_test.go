@@ -0,0 +1,67 @@
+package web
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/engine"
+)
+
+func TestApiServicePlacement(t *testing.T) {
+	swarm := &mockSwarmProvider{
+		swarmMode: true,
+		services:  []ServiceDetail{{ServiceSummary: ServiceSummary{ID: "svc1", Name: "web"}}},
+		placement: engine.ServicePlacement{
+			Service:  "web",
+			Nodes:    []engine.PlacementNode{{Hostname: "pi", Reason: "image has no linux/arm64 build"}},
+			Warnings: []string{"image has no linux/arm64 build on pi: tasks cannot run there"},
+		},
+	}
+	srv := &Server{deps: Dependencies{
+		Swarm: swarm,
+		Queue: &mockQueue{items: []PendingUpdate{{ContainerName: "web", CurrentImage: "nginx:1.25", NewerVersions: []string{"1.27"}}}},
+		Log:   slog.Default(),
+	}}
+
+	get := func(name, query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/services/"+name+"/placement"+query, nil)
+		r.SetPathValue("name", name)
+		srv.apiServicePlacement(w, r)
+		return w
+	}
+
+	w := get("web", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d; body: %s", w.Code, w.Body.String())
+	}
+	if swarm.previewed != "svc1=nginx:1.27" {
+		t.Errorf("previewed %q, want svc1=nginx:1.27 from the queue", swarm.previewed)
+	}
+	var plan engine.ServicePlacement
+	if err := json.Unmarshal(w.Body.Bytes(), &plan); err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Nodes) != 1 || len(plan.Warnings) != 1 {
+		t.Errorf("plan = %+v, want one node and one warning", plan)
+	}
+
+	// An explicit version overrides the queued one.
+	if w := get("web", "?version=nginx:1.26"); w.Code != http.StatusOK || swarm.previewed != "svc1=nginx:1.26" {
+		t.Errorf("version override: status = %d, previewed %q", w.Code, swarm.previewed)
+	}
+	if w := get("web", "?version=bad%20ref"); w.Code != http.StatusBadRequest {
+		t.Errorf("bad version: status = %d, want 400", w.Code)
+	}
+	if w := get("missing", ""); w.Code != http.StatusNotFound {
+		t.Errorf("unknown service: status = %d, want 404", w.Code)
+	}
+
+	swarm.swarmMode = false
+	if w := get("web", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("swarm off: status = %d, want 400", w.Code)
+	}
+}
@@ -3,12 +3,17 @@ package web
 import (
 	_ "embed"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
 	cron "github.com/robfig/cron/v3"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/docker"
+	"github.com/Will-Luck/Docker-Sentinel/internal/engine"
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
 )
 
 //go:embed grafana-dashboard.json
@@ -31,6 +36,9 @@ func (s *Server) apiSettings(w http.ResponseWriter, r *http.Request) {
 			s.deps.Log.Warn("failed to load runtime settings", "error", err)
 		} else {
 			for k, v := range dbSettings {
+				if k == store.SettingTicketing {
+					continue // holds an API token; served masked by /api/settings/ticketing
+				}
 				values[k] = v
 			}
 		}
@@ -133,6 +141,133 @@ func (s *Server) apiSetGracePeriod(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// apiSetHealthTimeout sets how long validation waits for a healthcheck or
+// probe to pass before rolling back. An empty value restores the default
+// of 60 seconds.
+func (s *Server) apiSetHealthTimeout(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Duration string `json:"duration"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if body.Duration != "" {
+		d, err := docker.ParseDurationWithDays(body.Duration)
+		if err != nil || d < 5*time.Second || d > time.Hour {
+			writeError(w, http.StatusBadRequest, "health timeout must be a duration between 5s and 1h")
+			return
+		}
+		body.Duration = d.String()
+	}
+	if s.deps.SettingsStore == nil {
+		writeError(w, http.StatusNotImplemented, "settings store not available")
+		return
+	}
+	if err := s.deps.SettingsStore.SaveSetting("health_timeout", body.Duration); err != nil {
+		s.deps.Log.Error("failed to save health_timeout", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to save setting")
+		return
+	}
+	msg := "Health check timeout reset to default"
+	if body.Duration != "" {
+		msg = "Health check timeout set to " + body.Duration
+	}
+	s.logEvent(r, "settings", "", msg)
+	writeJSON(w, http.StatusOK, map[string]string{"message": msg})
+}
+
+// apiSetBlueGreenPortRange sets the host ports blue-green candidates are
+// published on while they are validated. Body: {"port_range": "20000-20999"};
+// empty stops publishing candidate ports.
+func (s *Server) apiSetBlueGreenPortRange(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		PortRange string `json:"port_range"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	body.PortRange = strings.TrimSpace(body.PortRange)
+	if body.PortRange != "" {
+		lo, hi, err := engine.ParsePortRange(body.PortRange)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		body.PortRange = fmt.Sprintf("%d-%d", lo, hi)
+	}
+	if s.deps.SettingsStore == nil {
+		writeError(w, http.StatusNotImplemented, "settings store not available")
+		return
+	}
+	if err := s.deps.SettingsStore.SaveSetting("bluegreen_port_range", body.PortRange); err != nil {
+		s.deps.Log.Error("failed to save bluegreen_port_range", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to save setting")
+		return
+	}
+	msg := "Blue-green candidates no longer publish ports"
+	if body.PortRange != "" {
+		msg = "Blue-green candidates publish ports from " + body.PortRange
+	}
+	s.logEvent(r, "settings", "", msg)
+	writeJSON(w, http.StatusOK, map[string]string{"message": msg})
+}
+
+// apiSetExternalValidation configures the validators run after an updated
+// container passes its built-in checks.
+// Body: {"webhook_url": "https://ci.example/smoke", "command": "/plugins/smoke.sh"};
+// either field may be omitted, and an empty value clears it.
+func (s *Server) apiSetExternalValidation(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		WebhookURL *string `json:"webhook_url"`
+		Command    *string `json:"command"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if s.deps.SettingsStore == nil {
+		writeError(w, http.StatusNotImplemented, "settings store not available")
+		return
+	}
+
+	var saved []string
+	if body.WebhookURL != nil {
+		raw := strings.TrimSpace(*body.WebhookURL)
+		if err := validateSetting("validation_webhook_url", raw); err != nil {
+			writeError(w, http.StatusBadRequest, "webhook_url must be an http or https URL")
+			return
+		}
+		if err := s.deps.SettingsStore.SaveSetting("validation_webhook_url", raw); err != nil {
+			s.deps.Log.Error("failed to save validation_webhook_url", "error", err)
+			writeError(w, http.StatusInternalServerError, "failed to save setting")
+			return
+		}
+		saved = append(saved, "webhook")
+	}
+	if body.Command != nil {
+		path := strings.TrimSpace(*body.Command)
+		if err := validateSetting("validation_command", path); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err := s.deps.SettingsStore.SaveSetting("validation_command", path); err != nil {
+			s.deps.Log.Error("failed to save validation_command", "error", err)
+			writeError(w, http.StatusInternalServerError, "failed to save setting")
+			return
+		}
+		saved = append(saved, "command")
+	}
+	if len(saved) == 0 {
+		writeError(w, http.StatusBadRequest, "nothing to update")
+		return
+	}
+	msg := "External validation " + strings.Join(saved, " and ") + " saved"
+	s.logEvent(r, "settings", "", msg)
+	writeJSON(w, http.StatusOK, map[string]string{"message": msg})
+}
+
 // apiSetPause pauses or unpauses the scan scheduler.
 func (s *Server) apiSetPause(w http.ResponseWriter, r *http.Request) {
 	var body struct {
@@ -212,7 +347,8 @@ func (s *Server) apiSetLatestAutoUpdate(w http.ResponseWriter, r *http.Request)
 	})
 }
 
-// apiSetFilters sets container name filter patterns for scan exclusion.
+// apiSetFilters sets the scan exclusion rules: name globs, image:, label:
+// and "!" exceptions, one per entry. See engine.ScanFilter.
 func (s *Server) apiSetFilters(w http.ResponseWriter, r *http.Request) {
 	var body struct {
 		Patterns []string `json:"patterns"`
@@ -221,6 +357,10 @@ func (s *Server) apiSetFilters(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "invalid JSON body")
 		return
 	}
+	if _, err := engine.ParseScanFilters(body.Patterns); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	if s.deps.SettingsStore == nil {
 		writeError(w, http.StatusNotImplemented, "settings store not available")
@@ -242,6 +382,99 @@ func (s *Server) apiSetFilters(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// apiSetImageRewrites sets the image rewrite rules, one "from => to" rule
+// per entry, that redirect pulls to mirrors or pull-through caches. See
+// engine.ImageRewrite.
+func (s *Server) apiSetImageRewrites(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Rules []string `json:"rules"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	rules, err := engine.ParseImageRewrites(body.Rules)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if s.deps.SettingsStore == nil {
+		writeError(w, http.StatusNotImplemented, "settings store not available")
+		return
+	}
+
+	lines := make([]string, len(rules))
+	for i, rule := range rules {
+		lines[i] = rule.String()
+	}
+	if err := s.deps.SettingsStore.SaveSetting("image_rewrites", strings.Join(lines, "\n")); err != nil {
+		s.deps.Log.Error("failed to save image rewrites", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to save image rewrites")
+		return
+	}
+
+	s.logEvent(r, "settings", "", "Image rewrite rules updated")
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"message": "image rewrites updated",
+	})
+}
+
+// apiTestFilters reports whether a container would be excluded from scans
+// and which rule decides it. Patterns default to the saved rules; when only
+// a name is given, the image and labels of the matching local container are
+// used.
+func (s *Server) apiTestFilters(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Name     string            `json:"name"`
+		Image    string            `json:"image"`
+		Labels   map[string]string `json:"labels"`
+		Patterns *[]string         `json:"patterns"` // unsaved rules; omitted = saved rules
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if body.Name == "" && body.Image == "" {
+		writeError(w, http.StatusBadRequest, "name or image required")
+		return
+	}
+
+	var patterns []string
+	if body.Patterns != nil {
+		patterns = *body.Patterns
+	} else if s.deps.SettingsStore != nil {
+		if saved, err := s.deps.SettingsStore.LoadSetting("filters"); err == nil && saved != "" {
+			patterns = strings.Split(saved, "\n")
+		}
+	}
+	filters, err := engine.ParseScanFilters(patterns)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if body.Name != "" && body.Image == "" && body.Labels == nil {
+		if containers, err := s.deps.Docker.ListAllContainers(r.Context()); err == nil {
+			for _, c := range containers {
+				if containerName(c) == body.Name {
+					body.Image, body.Labels = c.Image, c.Labels
+					break
+				}
+			}
+		}
+	}
+
+	excluded, rule := filters.Excluded(body.Name, body.Image, body.Labels)
+	writeJSON(w, http.StatusOK, map[string]any{
+		"name":     body.Name,
+		"image":    body.Image,
+		"excluded": excluded,
+		"rule":     rule,
+	})
+}
+
 // apiSetImageCleanup toggles old image cleanup.
 func (s *Server) apiSetImageCleanup(w http.ResponseWriter, r *http.Request) {
 	var body struct {
@@ -2,6 +2,7 @@ package web
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
@@ -41,6 +42,40 @@ func (s *Server) apiSetShowStopped(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]string{"message": "show stopped containers " + label})
 }
 
+// apiSetDiscovery configures event-driven discovery of new containers.
+// Body: {"enabled": bool, "check": bool}; check runs an immediate registry
+// check for each newly discovered container.
+func (s *Server) apiSetDiscovery(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Enabled bool `json:"enabled"`
+		Check   bool `json:"check"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if s.deps.SettingsStore == nil {
+		writeError(w, http.StatusNotImplemented, "settings store not available")
+		return
+	}
+	for key, val := range map[string]bool{"container_discovery": body.Enabled, "discovery_check": body.Check} {
+		if err := s.deps.SettingsStore.SaveSetting(key, strconv.FormatBool(val)); err != nil {
+			s.deps.Log.Error("failed to save "+key, "error", err)
+			writeError(w, http.StatusInternalServerError, "failed to save setting")
+			return
+		}
+	}
+	label := "disabled"
+	if body.Enabled {
+		label = "enabled"
+		if body.Check {
+			label += " with immediate checks"
+		}
+	}
+	s.logEvent(r, "settings", "", "Container discovery "+label)
+	writeJSON(w, http.StatusOK, map[string]string{"message": "container discovery " + label})
+}
+
 // apiSetImageBackup enables or disables image retag backup before updates.
 func (s *Server) apiSetImageBackup(w http.ResponseWriter, r *http.Request) {
 	var body struct {
@@ -134,6 +169,56 @@ func (s *Server) apiSetScanConcurrency(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]string{"message": "scan concurrency set to " + val})
 }
 
+// apiSetAutoUpdateCap limits how many auto-updates one scan may start and
+// what happens to the rest: deferred to the next scan or queued for approval.
+// A max of 0 removes the cap.
+func (s *Server) apiSetAutoUpdateCap(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Max      int    `json:"max"`
+		Overflow string `json:"overflow"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if body.Max < 0 || body.Max > 1000 {
+		writeError(w, http.StatusBadRequest, "max must be between 0 and 1000")
+		return
+	}
+	if body.Overflow == "" {
+		body.Overflow = engine.CapOverflowDefer
+	}
+	if body.Overflow != engine.CapOverflowDefer && body.Overflow != engine.CapOverflowQueue {
+		writeError(w, http.StatusBadRequest, "overflow must be \"defer\" or \"queue\"")
+		return
+	}
+	if s.deps.SettingsStore == nil {
+		writeError(w, http.StatusNotImplemented, "settings store not available")
+		return
+	}
+	val := strconv.Itoa(body.Max)
+	if err := s.deps.SettingsStore.SaveSetting("max_auto_updates", val); err != nil {
+		s.deps.Log.Error("failed to save max_auto_updates", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to save setting")
+		return
+	}
+	if err := s.deps.SettingsStore.SaveSetting("auto_update_overflow", body.Overflow); err != nil {
+		s.deps.Log.Error("failed to save auto_update_overflow", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to save setting")
+		return
+	}
+	msg := "auto-update cap removed"
+	if body.Max > 0 {
+		overflow := "deferred to the next scan"
+		if body.Overflow == engine.CapOverflowQueue {
+			overflow = "queued for approval"
+		}
+		msg = fmt.Sprintf("auto-updates capped at %d per scan, overflow %s", body.Max, overflow)
+	}
+	s.logEvent(r, "settings", "", "Auto-update cap: "+msg)
+	writeJSON(w, http.StatusOK, map[string]string{"message": msg})
+}
+
 // apiSetNotifyBatchWindow configures the notification batching window.
 // When set to a non-zero duration, rapid-fire notifications during bulk updates
 // are buffered and sent as a single summary instead of N individual alerts.
@@ -237,11 +322,9 @@ func (s *Server) apiSetMaintenanceWindow(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Validate the expression if non-empty.
-	if req.Value != "" {
-		if _, err := engine.ParseWindow(req.Value); err != nil {
-			writeError(w, http.StatusBadRequest, "invalid maintenance window: "+err.Error())
-			return
-		}
+	if err := validateSetting("maintenance_window", req.Value); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
 	}
 
 	if s.deps.SettingsStore == nil {
@@ -298,6 +381,10 @@ func (s *Server) apiGetOIDCSettings(w http.ResponseWriter, _ *http.Request) {
 	if raw := load("oidc_group_mappings"); raw != "" {
 		_ = json.Unmarshal([]byte(raw), &groupMappings)
 	}
+	roleRules := []auth.RoleMappingRule{}
+	if raw := load("oidc_role_rules"); raw != "" {
+		_ = json.Unmarshal([]byte(raw), &roleRules)
+	}
 
 	writeJSON(w, http.StatusOK, map[string]any{
 		"enabled":        load("oidc_enabled") == "true",
@@ -309,9 +396,31 @@ func (s *Server) apiGetOIDCSettings(w http.ResponseWriter, _ *http.Request) {
 		"default_role":   load("oidc_default_role"),
 		"group_claim":    groupClaim,
 		"group_mappings": groupMappings,
+		"role_rules":     roleRules,
+
+		"post_logout_redirect_url": load("oidc_post_logout_redirect_url"),
+		"backchannel_logout":       load("oidc_backchannel_logout") == "true",
+		"revalidate_interval":      load("oidc_revalidate_interval"),
 	})
 }
 
+// parseOIDCRevalidateInterval parses the SSO session re-check interval.
+// Empty or "0" disables revalidation; anything shorter than a minute would
+// hammer the IdP's token endpoint.
+func parseOIDCRevalidateInterval(v string) (time.Duration, error) {
+	if v == "" || v == "0" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid revalidate interval %q", v)
+	}
+	if d < time.Minute {
+		return 0, fmt.Errorf("revalidate interval must be at least 1m")
+	}
+	return d, nil
+}
+
 // apiSaveOIDCSettings saves OIDC configuration and reinitialises the provider.
 func (s *Server) apiSaveOIDCSettings(w http.ResponseWriter, r *http.Request) {
 	if s.deps.SettingsStore == nil {
@@ -329,12 +438,25 @@ func (s *Server) apiSaveOIDCSettings(w http.ResponseWriter, r *http.Request) {
 		DefaultRole   string            `json:"default_role"`
 		GroupClaim    string            `json:"group_claim"`
 		GroupMappings map[string]string `json:"group_mappings"`
+
+		// Claim rules applied alongside group mappings on every login.
+		RoleRules []auth.RoleMappingRule `json:"role_rules"`
+
+		PostLogoutRedirectURL string `json:"post_logout_redirect_url"`
+		BackchannelLogout     bool   `json:"backchannel_logout"`
+		RevalidateInterval    string `json:"revalidate_interval"` // Go duration, "" = off
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
 
+	revalidate, err := parseOIDCRevalidateInterval(req.RevalidateInterval)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	// Validate role.
 	if req.DefaultRole != "" {
 		switch req.DefaultRole {
@@ -345,6 +467,20 @@ func (s *Server) apiSaveOIDCSettings(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
+	for group, role := range req.GroupMappings {
+		switch role {
+		case "admin", "operator", "viewer":
+		default:
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid role %q for group %q", role, group))
+			return
+		}
+	}
+	for i, rule := range req.RoleRules {
+		if err := rule.Validate(); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("role rule %d: %v", i+1, err))
+			return
+		}
+	}
 
 	enabledVal := "false"
 	if req.Enabled {
@@ -368,6 +504,12 @@ func (s *Server) apiSaveOIDCSettings(w http.ResponseWriter, r *http.Request) {
 			groupMappingsJSON = string(data)
 		}
 	}
+	roleRulesJSON := ""
+	if len(req.RoleRules) > 0 {
+		if data, err := json.Marshal(req.RoleRules); err == nil {
+			roleRulesJSON = string(data)
+		}
+	}
 	groupClaim := req.GroupClaim
 	if groupClaim == "" {
 		groupClaim = "groups"
@@ -383,6 +525,10 @@ func (s *Server) apiSaveOIDCSettings(w http.ResponseWriter, r *http.Request) {
 		{"oidc_default_role", req.DefaultRole},
 		{"oidc_group_claim", groupClaim},
 		{"oidc_group_mappings", groupMappingsJSON},
+		{"oidc_role_rules", roleRulesJSON},
+		{"oidc_post_logout_redirect_url", req.PostLogoutRedirectURL},
+		{"oidc_backchannel_logout", strconv.FormatBool(req.BackchannelLogout)},
+		{"oidc_revalidate_interval", req.RevalidateInterval},
 	}
 
 	for _, p := range pairs {
@@ -404,6 +550,11 @@ func (s *Server) apiSaveOIDCSettings(w http.ResponseWriter, r *http.Request) {
 			DefaultRole:   req.DefaultRole,
 			GroupClaim:    groupClaim,
 			GroupMappings: req.GroupMappings,
+			RoleRules:     req.RoleRules,
+
+			PostLogoutRedirectURL: req.PostLogoutRedirectURL,
+			BackchannelLogout:     req.BackchannelLogout,
+			RevalidateInterval:    revalidate,
 		}
 		provider, err := auth.NewOIDCProvider(r.Context(), oidcCfg)
 		if err != nil {
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/Will-Luck/Docker-Sentinel/internal/docker"
+	"github.com/Will-Luck/Docker-Sentinel/internal/registry"
 	"github.com/Will-Luck/Docker-Sentinel/internal/store"
 )
 
@@ -21,6 +22,9 @@ func (s *Server) apiClusterSettings(w http.ResponseWriter, _ *http.Request) {
 		"remote_policy":      "manual",
 		"auto_update_agents": "false",
 		"advertise_addr":     "",
+		"enroll_ttl":         "24h",
+		"min_agent_version":  s.deps.MinAgentVersion,
+		"outdated_agents":    "degrade",
 	}
 
 	if s.deps.SettingsStore != nil {
@@ -31,6 +35,9 @@ func (s *Server) apiClusterSettings(w http.ResponseWriter, _ *http.Request) {
 			"remote_policy":      store.SettingClusterRemotePolicy,
 			"auto_update_agents": store.SettingClusterAutoUpdateAgents,
 			"advertise_addr":     store.SettingClusterAdvertise,
+			"enroll_ttl":         store.SettingClusterEnrollTTL,
+			"min_agent_version":  store.SettingClusterMinAgentVersion,
+			"outdated_agents":    store.SettingClusterOutdatedAgents,
 		}
 		for field, dbKey := range keys {
 			if v, err := s.deps.SettingsStore.LoadSetting(dbKey); err == nil && v != "" {
@@ -53,6 +60,9 @@ func (s *Server) apiClusterSettingsSave(w http.ResponseWriter, r *http.Request)
 		RemotePolicy     string  `json:"remote_policy"`
 		AutoUpdateAgents *bool   `json:"auto_update_agents"`
 		AdvertiseAddr    *string `json:"advertise_addr"`
+		EnrollTTL        string  `json:"enroll_ttl"`
+		MinAgentVersion  *string `json:"min_agent_version"`
+		OutdatedAgents   string  `json:"outdated_agents"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid JSON")
@@ -93,6 +103,27 @@ func (s *Server) apiClusterSettingsSave(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
+	// Validate the default enrollment token lifetime against whitelist.
+	if req.EnrollTTL != "" && !enrollTTLChoices[req.EnrollTTL] {
+		writeError(w, http.StatusBadRequest, "invalid enrollment token lifetime")
+		return
+	}
+
+	// Validate the minimum agent version; empty restores the built-in default.
+	if req.MinAgentVersion != nil {
+		*req.MinAgentVersion = strings.TrimSpace(*req.MinAgentVersion)
+		if v := *req.MinAgentVersion; v != "" {
+			if _, ok := registry.ParseSemVer(v); !ok {
+				writeError(w, http.StatusBadRequest, "minimum agent version must be a version such as 2.5.0")
+				return
+			}
+		}
+	}
+	if req.OutdatedAgents != "" && req.OutdatedAgents != "degrade" && req.OutdatedAgents != "refuse" {
+		writeError(w, http.StatusBadRequest, "outdated agents must be degrade or refuse")
+		return
+	}
+
 	// Save each provided field, checking for errors.
 	if req.Enabled != nil {
 		val := "false"
@@ -140,6 +171,26 @@ func (s *Server) apiClusterSettingsSave(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
+	if req.EnrollTTL != "" {
+		if err := s.deps.SettingsStore.SaveSetting(store.SettingClusterEnrollTTL, req.EnrollTTL); err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to save setting")
+			return
+		}
+	}
+
+	if req.MinAgentVersion != nil {
+		if err := s.deps.SettingsStore.SaveSetting(store.SettingClusterMinAgentVersion, *req.MinAgentVersion); err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to save setting")
+			return
+		}
+	}
+	if req.OutdatedAgents != "" {
+		if err := s.deps.SettingsStore.SaveSetting(store.SettingClusterOutdatedAgents, req.OutdatedAgents); err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to save setting")
+			return
+		}
+	}
+
 	// Dynamic start/stop via ClusterLifecycle callback.
 	if req.Enabled != nil && s.clusterLifecycle != nil {
 		if *req.Enabled {
@@ -339,3 +390,139 @@ func (s *Server) apiSetComposeSync(w http.ResponseWriter, r *http.Request) {
 	s.logEvent(r, "settings", "", "Compose file sync "+label)
 	writeJSON(w, http.StatusOK, map[string]string{"message": "compose file sync " + label})
 }
+
+// apiSetConfigDiff enables or disables config file previews for queued
+// updates. The sentinel.config-diff label overrides it per container.
+func (s *Server) apiSetConfigDiff(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Enabled bool `json:"enabled"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if s.deps.SettingsStore == nil {
+		writeError(w, http.StatusNotImplemented, "settings store not available")
+		return
+	}
+	value := "false"
+	if body.Enabled {
+		value = "true"
+	}
+	if err := s.deps.SettingsStore.SaveSetting("config_diff", value); err != nil {
+		s.deps.Log.Error("failed to save config_diff", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to save setting")
+		return
+	}
+	label := "disabled"
+	if body.Enabled {
+		label = "enabled"
+	}
+	s.logEvent(r, "settings", "", "Config change preview "+label)
+	writeJSON(w, http.StatusOK, map[string]string{"message": "config change preview " + label})
+}
+
+// apiSetRebuildWatch configures base image watching for locally built images.
+// Body: {"enabled": true, "webhook_url": "https://ci.example/hooks/rebuild"};
+// either field may be omitted, and an empty webhook_url clears it.
+func (s *Server) apiSetRebuildWatch(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Enabled    *bool   `json:"enabled"`
+		WebhookURL *string `json:"webhook_url"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if s.deps.SettingsStore == nil {
+		writeError(w, http.StatusNotImplemented, "settings store not available")
+		return
+	}
+	if body.WebhookURL != nil {
+		url := strings.TrimSpace(*body.WebhookURL)
+		if err := validateSetting("rebuild_webhook_url", url); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err := s.deps.SettingsStore.SaveSetting("rebuild_webhook_url", url); err != nil {
+			s.deps.Log.Error("failed to save rebuild_webhook_url", "error", err)
+			writeError(w, http.StatusInternalServerError, "failed to save setting")
+			return
+		}
+		if url == "" {
+			s.logEvent(r, "settings", "", "Rebuild webhook cleared")
+		} else {
+			s.logEvent(r, "settings", "", "Rebuild webhook set")
+		}
+	}
+
+	msg := "rebuild webhook saved"
+	if body.Enabled != nil {
+		value, label := "false", "disabled"
+		if *body.Enabled {
+			value, label = "true", "enabled"
+		}
+		if err := s.deps.SettingsStore.SaveSetting("rebuild_watch", value); err != nil {
+			s.deps.Log.Error("failed to save rebuild_watch", "error", err)
+			writeError(w, http.StatusInternalServerError, "failed to save setting")
+			return
+		}
+		s.logEvent(r, "settings", "", "Base image rebuild alerts "+label)
+		msg = "base image rebuild alerts " + label
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": msg})
+}
+
+// apiSetPullChecks configures the checks run before an update pulls.
+// Body: {"registry": true, "disk_path": "/var/lib/docker"}; either field may
+// be omitted. disk_path must be mounted into Sentinel on the filesystem of
+// Docker's data root; an empty value turns the disk space check off.
+func (s *Server) apiSetPullChecks(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Registry *bool   `json:"registry"`
+		DiskPath *string `json:"disk_path"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if s.deps.SettingsStore == nil {
+		writeError(w, http.StatusNotImplemented, "settings store not available")
+		return
+	}
+
+	msg := "pull checks saved"
+	if body.DiskPath != nil {
+		path := strings.TrimSpace(*body.DiskPath)
+		if err := validateSetting("pull_check_disk_path", path); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err := s.deps.SettingsStore.SaveSetting("pull_check_disk_path", path); err != nil {
+			s.deps.Log.Error("failed to save pull_check_disk_path", "error", err)
+			writeError(w, http.StatusInternalServerError, "failed to save setting")
+			return
+		}
+		if path == "" {
+			s.logEvent(r, "settings", "", "Disk space check disabled")
+			msg = "disk space check disabled"
+		} else {
+			s.logEvent(r, "settings", "", "Disk space check path set to "+path)
+			msg = "disk space check path saved"
+		}
+	}
+	if body.Registry != nil {
+		value, label := "false", "disabled"
+		if *body.Registry {
+			value, label = "true", "enabled"
+		}
+		if err := s.deps.SettingsStore.SaveSetting("pull_check_registry", value); err != nil {
+			s.deps.Log.Error("failed to save pull_check_registry", "error", err)
+			writeError(w, http.StatusInternalServerError, "failed to save setting")
+			return
+		}
+		s.logEvent(r, "settings", "", "Registry reachability check "+label)
+		msg = "registry reachability check " + label
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": msg})
+}
@@ -3,7 +3,9 @@ package web
 import (
 	"encoding/json"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Will-Luck/Docker-Sentinel/internal/store"
@@ -74,3 +76,47 @@ func (s *Server) apiRetrySettingsSave(w http.ResponseWriter, r *http.Request) {
 	s.logEvent(r, "settings", "", "Notification retry settings updated")
 	writeJSON(w, http.StatusOK, map[string]string{"status": "saved"})
 }
+
+// apiSetExternalURL sets the URL users reach Sentinel at, used for links
+// back to the web UI in notifications. It is not resolved: the address only
+// has to work from the user's browser. An empty URL leaves links out.
+func (s *Server) apiSetExternalURL(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		URL string `json:"url"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+	base := strings.TrimRight(strings.TrimSpace(req.URL), "/")
+	if base != "" {
+		u, err := url.Parse(base)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			writeError(w, http.StatusBadRequest, "external URL must be an http(s) URL such as https://sentinel.example.com")
+			return
+		}
+		if u.RawQuery != "" || u.Fragment != "" {
+			writeError(w, http.StatusBadRequest, "external URL must not have a query or fragment")
+			return
+		}
+	}
+	if s.deps.SettingsStore == nil {
+		writeError(w, http.StatusNotImplemented, "settings store not available")
+		return
+	}
+	if err := s.deps.SettingsStore.SaveSetting("external_url", base); err != nil {
+		s.deps.Log.Error("failed to save external_url", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to save setting")
+		return
+	}
+	if s.deps.NotifyReconfigurer != nil {
+		s.deps.NotifyReconfigurer.SetExternalURL(base)
+	}
+
+	msg := "External URL cleared, notifications will not link to Sentinel"
+	if base != "" {
+		msg = "External URL set to " + base
+	}
+	s.logEvent(r, "settings", "", msg)
+	writeJSON(w, http.StatusOK, map[string]string{"message": msg, "url": base})
+}
@@ -0,0 +1,61 @@
+package web
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/notify"
+)
+
+// mockNotifyReconfigurer records the settings applied to the live notifier.
+type mockNotifyReconfigurer struct {
+	externalURL string
+}
+
+func (m *mockNotifyReconfigurer) Reconfigure(...notify.Notifier) {}
+func (m *mockNotifyReconfigurer) SetBatchWindow(time.Duration)   {}
+func (m *mockNotifyReconfigurer) SetRetry(int, time.Duration)    {}
+func (m *mockNotifyReconfigurer) SetExternalURL(base string)     { m.externalURL = base }
+
+func TestApiSetExternalURL(t *testing.T) {
+	ss := newMockSettingsStore()
+	rc := &mockNotifyReconfigurer{}
+	s := &Server{deps: Dependencies{SettingsStore: ss, NotifyReconfigurer: rc, Log: slog.New(slog.NewTextHandler(io.Discard, nil))}}
+
+	tests := []struct {
+		body   string
+		status int
+		want   string
+	}{
+		{`{"url":"ftp://sentinel.example.com"}`, http.StatusBadRequest, ""},
+		{`{"url":"sentinel.example.com"}`, http.StatusBadRequest, ""},
+		{`{"url":"https://sentinel.example.com/?x=1"}`, http.StatusBadRequest, ""},
+		{`{"url":" https://sentinel.example.com/ "}`, http.StatusOK, "https://sentinel.example.com"},
+		{`{"url":"http://nas.lan:8080/sentinel"}`, http.StatusOK, "http://nas.lan:8080/sentinel"},
+		{`{"url":""}`, http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		ss.data["external_url"] = "unchanged"
+		rc.externalURL = "unchanged"
+		w := httptest.NewRecorder()
+		s.apiSetExternalURL(w, httptest.NewRequest(http.MethodPost, "/api/settings/external-url", strings.NewReader(tt.body)))
+		if w.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.body, w.Code, tt.status)
+			continue
+		}
+		if tt.status != http.StatusOK {
+			if ss.data["external_url"] != "unchanged" || rc.externalURL != "unchanged" {
+				t.Errorf("%s: rejected URL was applied", tt.body)
+			}
+			continue
+		}
+		if ss.data["external_url"] != tt.want || rc.externalURL != tt.want {
+			t.Errorf("%s: saved %q, applied %q, want %q", tt.body, ss.data["external_url"], rc.externalURL, tt.want)
+		}
+	}
+}
@@ -2,8 +2,14 @@ package web
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
 
+	"github.com/Will-Luck/Docker-Sentinel/internal/docker"
 	"github.com/Will-Luck/Docker-Sentinel/internal/scanner"
 	"github.com/Will-Luck/Docker-Sentinel/internal/store"
 	"github.com/Will-Luck/Docker-Sentinel/internal/verify"
@@ -12,16 +18,18 @@ import (
 // apiScannerSettings returns the current scanner (Trivy) configuration.
 func (s *Server) apiScannerSettings(w http.ResponseWriter, _ *http.Request) {
 	result := map[string]string{
-		"mode":       string(scanner.ScanDisabled),
-		"threshold":  string(scanner.SeverityHigh),
-		"trivy_path": "trivy",
+		"mode":         string(scanner.ScanDisabled),
+		"threshold":    string(scanner.SeverityHigh),
+		"trivy_path":   "trivy",
+		"trivy_server": "",
 	}
 
 	if s.deps.SettingsStore != nil {
 		keys := map[string]string{
-			"mode":       store.SettingScannerMode,
-			"threshold":  store.SettingScannerThreshold,
-			"trivy_path": store.SettingTrivyPath,
+			"mode":         store.SettingScannerMode,
+			"threshold":    store.SettingScannerThreshold,
+			"trivy_path":   store.SettingTrivyPath,
+			"trivy_server": store.SettingTrivyServer,
 		}
 		for field, dbKey := range keys {
 			if v, err := s.deps.SettingsStore.LoadSetting(dbKey); err == nil && v != "" {
@@ -36,9 +44,10 @@ func (s *Server) apiScannerSettings(w http.ResponseWriter, _ *http.Request) {
 // apiScannerSettingsSave persists scanner configuration changes.
 func (s *Server) apiScannerSettingsSave(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		Mode      string `json:"mode"`
-		Threshold string `json:"threshold"`
-		TrivyPath string `json:"trivy_path"`
+		Mode        string  `json:"mode"`
+		Threshold   string  `json:"threshold"`
+		TrivyPath   string  `json:"trivy_path"`
+		TrivyServer *string `json:"trivy_server"` // nil = unchanged, "" = scan locally
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid JSON")
@@ -88,6 +97,22 @@ func (s *Server) apiScannerSettingsSave(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
+	// Trivy server URL for client/server mode; empty clears it.
+	if req.TrivyServer != nil {
+		server := strings.TrimSpace(*req.TrivyServer)
+		if server != "" {
+			u, err := url.Parse(server)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				writeError(w, http.StatusBadRequest, "trivy server must be an http or https URL")
+				return
+			}
+		}
+		if err := s.deps.SettingsStore.SaveSetting(store.SettingTrivyServer, server); err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to save setting")
+			return
+		}
+	}
+
 	s.logEvent(r, "settings", "", "Scanner settings updated")
 	writeJSON(w, http.StatusOK, map[string]string{"status": "saved"})
 }
@@ -99,6 +124,9 @@ func (s *Server) apiVerifierSettings(w http.ResponseWriter, _ *http.Request) {
 		"cosign_path": "cosign",
 		"keyless":     "false",
 		"key_path":    "",
+		"identity":    "",
+		"issuer":      "",
+		"cache_ttl":   verify.DefaultCacheTTL.String(),
 	}
 
 	if s.deps.SettingsStore != nil {
@@ -107,6 +135,9 @@ func (s *Server) apiVerifierSettings(w http.ResponseWriter, _ *http.Request) {
 			"cosign_path": store.SettingCosignPath,
 			"keyless":     store.SettingCosignKeyless,
 			"key_path":    store.SettingCosignKeyPath,
+			"identity":    store.SettingCosignIdentity,
+			"issuer":      store.SettingCosignIssuer,
+			"cache_ttl":   store.SettingVerifyCacheTTL,
 		}
 		for field, dbKey := range keys {
 			if v, err := s.deps.SettingsStore.LoadSetting(dbKey); err == nil && v != "" {
@@ -121,10 +152,13 @@ func (s *Server) apiVerifierSettings(w http.ResponseWriter, _ *http.Request) {
 // apiVerifierSettingsSave persists verifier configuration changes.
 func (s *Server) apiVerifierSettingsSave(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		Mode       string `json:"mode"`
-		CosignPath string `json:"cosign_path"`
-		Keyless    *bool  `json:"keyless"`
-		KeyPath    string `json:"key_path"`
+		Mode       string  `json:"mode"`
+		CosignPath string  `json:"cosign_path"`
+		Keyless    *bool   `json:"keyless"`
+		KeyPath    string  `json:"key_path"`
+		Identity   *string `json:"identity"`  // keyless certificate identity regexp; "" = any
+		Issuer     *string `json:"issuer"`    // keyless OIDC issuer regexp; "" = any
+		CacheTTL   *string `json:"cache_ttl"` // how long a verified digest is trusted; "0" = never cache
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid JSON")
@@ -184,6 +218,164 @@ func (s *Server) apiVerifierSettingsSave(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
+	// Keyless identity restrictions are regular expressions matched by cosign.
+	for _, f := range []struct {
+		value *string
+		key   string
+		label string
+	}{
+		{req.Identity, store.SettingCosignIdentity, "identity"},
+		{req.Issuer, store.SettingCosignIssuer, "issuer"},
+	} {
+		if f.value == nil {
+			continue
+		}
+		pattern := strings.TrimSpace(*f.value)
+		if _, err := regexp.Compile(pattern); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid "+f.label+" pattern: "+err.Error())
+			return
+		}
+		if err := s.deps.SettingsStore.SaveSetting(f.key, pattern); err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to save setting")
+			return
+		}
+	}
+
+	if req.CacheTTL != nil {
+		ttl := strings.TrimSpace(*req.CacheTTL)
+		if ttl != "0" {
+			if d, err := docker.ParseDurationWithDays(ttl); err != nil || d < time.Minute {
+				writeError(w, http.StatusBadRequest, "cache TTL must be 0 (disabled) or a duration of at least 1m, e.g. 24h or 7d")
+				return
+			}
+		}
+		if err := s.deps.SettingsStore.SaveSetting(store.SettingVerifyCacheTTL, ttl); err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to save setting")
+			return
+		}
+	}
+
 	s.logEvent(r, "settings", "", "Verifier settings updated")
 	writeJSON(w, http.StatusOK, map[string]string{"status": "saved"})
 }
+
+// validKeyName limits trusted key names to something safe in a URL path.
+var validKeyName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]{0,63}$`)
+
+// apiListTrustedKeys returns the cosign public keys signatures are checked
+// against.
+func (s *Server) apiListTrustedKeys(w http.ResponseWriter, _ *http.Request) {
+	if s.deps.TrustedKeys == nil {
+		writeError(w, http.StatusNotImplemented, "trusted keys not available")
+		return
+	}
+	keys, err := s.deps.TrustedKeys.ListTrustedKeys()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to load trusted keys")
+		return
+	}
+	if keys == nil {
+		keys = []TrustedKey{}
+	}
+	writeJSON(w, http.StatusOK, keys)
+}
+
+// apiAddTrustedKey adds or replaces a named cosign public key.
+func (s *Server) apiAddTrustedKey(w http.ResponseWriter, r *http.Request) {
+	if s.deps.TrustedKeys == nil {
+		writeError(w, http.StatusNotImplemented, "trusted keys not available")
+		return
+	}
+	var req struct {
+		Name string `json:"name"`
+		PEM  string `json:"pem"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if !validKeyName.MatchString(req.Name) {
+		writeError(w, http.StatusBadRequest, "key name must be 1-64 letters, digits, dots, dashes or underscores")
+		return
+	}
+	fingerprint, err := verify.Fingerprint(req.PEM)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid public key: "+err.Error())
+		return
+	}
+	key := TrustedKey{
+		Name:        req.Name,
+		PEM:         strings.TrimSpace(req.PEM) + "\n",
+		Fingerprint: fingerprint,
+		AddedAt:     time.Now().UTC(),
+	}
+	if err := s.deps.TrustedKeys.SaveTrustedKey(key); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to save trusted key")
+		return
+	}
+	s.logEvent(r, "settings", "", "Trusted signing key added: "+req.Name+" ("+fingerprint+")")
+	writeJSON(w, http.StatusOK, key)
+}
+
+// apiDeleteTrustedKey removes a trusted key by name.
+func (s *Server) apiDeleteTrustedKey(w http.ResponseWriter, r *http.Request) {
+	if s.deps.TrustedKeys == nil {
+		writeError(w, http.StatusNotImplemented, "trusted keys not available")
+		return
+	}
+	name := r.PathValue("name")
+	if !validKeyName.MatchString(name) {
+		writeError(w, http.StatusBadRequest, "invalid key name")
+		return
+	}
+	if err := s.deps.TrustedKeys.DeleteTrustedKey(name); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to delete trusted key")
+		return
+	}
+	s.logEvent(r, "settings", "", "Trusted signing key removed: "+name)
+	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+// apiVerifyCacheStats reports how many signature verification results are
+// cached and how often they have been reused since start.
+func (s *Server) apiVerifyCacheStats(w http.ResponseWriter, _ *http.Request) {
+	if s.deps.VerifyCache == nil {
+		writeError(w, http.StatusNotImplemented, "verification cache not available")
+		return
+	}
+	st, enabled, err := s.deps.VerifyCache.CacheStats()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to read verification cache")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"enabled": enabled && st.TTL > 0,
+		"entries": st.Entries,
+		"expired": st.Expired,
+		"stale":   st.Stale,
+		"hits":    st.Hits,
+		"misses":  st.Misses,
+		"ttl":     st.TTL.String(),
+	})
+}
+
+// apiVerifyCacheInvalidate drops the cached result for ?digest=, or every
+// cached result when no digest is given, so the next update re-verifies.
+func (s *Server) apiVerifyCacheInvalidate(w http.ResponseWriter, r *http.Request) {
+	if s.deps.VerifyCache == nil {
+		writeError(w, http.StatusNotImplemented, "verification cache not available")
+		return
+	}
+	digest := strings.TrimSpace(r.URL.Query().Get("digest"))
+	removed, err := s.deps.VerifyCache.InvalidateCache(digest)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to clear verification cache")
+		return
+	}
+	if digest == "" {
+		s.logEvent(r, "settings", "", fmt.Sprintf("Signature verification cache cleared (%d entries)", removed))
+	} else {
+		s.logEvent(r, "settings", "", "Signature verification cache entry removed: "+digest)
+	}
+	writeJSON(w, http.StatusOK, map[string]int{"removed": removed})
+}
@@ -0,0 +1,124 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/engine"
+)
+
+// apiListStackWindows returns every per-stack maintenance window override,
+// keyed by Compose project. Scoped users only see their own stacks.
+func (s *Server) apiListStackWindows(w http.ResponseWriter, r *http.Request) {
+	if s.deps.StackWindows == nil {
+		writeJSON(w, http.StatusOK, map[string]string{})
+		return
+	}
+	windows, err := s.deps.StackWindows.ListStackWindows()
+	if err != nil {
+		s.deps.Log.Error("failed to list stack windows", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to list stack windows")
+		return
+	}
+	if requestScope(r) != nil {
+		for project := range windows {
+			if !s.stackInScope(r, project) {
+				delete(windows, project)
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, windows)
+}
+
+// apiGetStackWindow returns a stack's maintenance window override alongside
+// the global window it replaces. Containers with a sentinel.window label
+// follow their label instead of either.
+func (s *Server) apiGetStackWindow(w http.ResponseWriter, r *http.Request) {
+	project := r.PathValue("project")
+	if !isValidContainerName(project) {
+		writeError(w, http.StatusBadRequest, "invalid project name")
+		return
+	}
+
+	global := ""
+	if s.deps.SettingsStore != nil {
+		global, _ = s.deps.SettingsStore.LoadSetting("maintenance_window")
+	}
+	override := ""
+	if s.deps.StackWindows != nil {
+		override, _ = s.deps.StackWindows.GetStackWindow(project)
+	}
+	effective := global
+	if override != "" {
+		effective = override
+	}
+	writeJSON(w, http.StatusOK, map[string]string{
+		"project":   project,
+		"override":  override,
+		"global":    global,
+		"effective": effective,
+	})
+}
+
+// apiSetStackWindow stores a stack's maintenance window override.
+// Body: {"window": "Sun 03:00-Sun 05:00"}, or {"window": "any"} to let the
+// stack update at any time.
+func (s *Server) apiSetStackWindow(w http.ResponseWriter, r *http.Request) {
+	project := r.PathValue("project")
+	if !isValidContainerName(project) {
+		writeError(w, http.StatusBadRequest, "invalid project name")
+		return
+	}
+	if s.deps.StackWindows == nil {
+		writeError(w, http.StatusNotImplemented, "stack window store not available")
+		return
+	}
+
+	var body struct {
+		Window string `json:"window"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	expr := strings.TrimSpace(body.Window)
+	if expr == "" {
+		writeError(w, http.StatusBadRequest, "window is required; use DELETE to clear")
+		return
+	}
+	if _, err := engine.ParseWindowOverride(expr); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid maintenance window: "+err.Error())
+		return
+	}
+
+	if err := s.deps.StackWindows.SetStackWindow(project, expr); err != nil {
+		s.deps.Log.Error("failed to save stack window", "project", project, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to save stack window")
+		return
+	}
+
+	s.logEvent(r, "window_set", project, "Stack maintenance window set to "+expr)
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok", "project": project, "window": expr})
+}
+
+// apiDeleteStackWindow clears a stack's override, returning its containers
+// to the global maintenance window.
+func (s *Server) apiDeleteStackWindow(w http.ResponseWriter, r *http.Request) {
+	project := r.PathValue("project")
+	if !isValidContainerName(project) {
+		writeError(w, http.StatusBadRequest, "invalid project name")
+		return
+	}
+	if s.deps.StackWindows == nil {
+		writeError(w, http.StatusNotImplemented, "stack window store not available")
+		return
+	}
+	if err := s.deps.StackWindows.DeleteStackWindow(project); err != nil {
+		s.deps.Log.Error("failed to delete stack window", "project", project, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to delete stack window")
+		return
+	}
+	s.logEvent(r, "window_delete", project, "Stack maintenance window override cleared")
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok", "project": project})
+}
@@ -0,0 +1,97 @@
+package web
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type mockStackWindowStore struct {
+	entries map[string]string
+}
+
+func (m *mockStackWindowStore) GetStackWindow(project string) (string, bool) {
+	expr, ok := m.entries[project]
+	return expr, ok
+}
+
+func (m *mockStackWindowStore) SetStackWindow(project, expr string) error {
+	m.entries[project] = expr
+	return nil
+}
+
+func (m *mockStackWindowStore) DeleteStackWindow(project string) error {
+	delete(m.entries, project)
+	return nil
+}
+
+func (m *mockStackWindowStore) ListStackWindows() (map[string]string, error) {
+	return m.entries, nil
+}
+
+func TestApiStackWindow(t *testing.T) {
+	ws := &mockStackWindowStore{entries: map[string]string{}}
+	settings := newMockSettingsStore()
+	_ = settings.SaveSetting("maintenance_window", "02:00-04:00")
+	srv := &Server{deps: Dependencies{StackWindows: ws, SettingsStore: settings, Log: slog.Default()}}
+
+	set := func(body string) int {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/stacks/db/window", strings.NewReader(body))
+		r.SetPathValue("project", "db")
+		srv.apiSetStackWindow(w, r)
+		return w.Code
+	}
+	if code := set(`{"window":"25:00-26:00"}`); code != http.StatusBadRequest {
+		t.Errorf("invalid window: status = %d, want 400", code)
+	}
+	if code := set(`{"window":""}`); code != http.StatusBadRequest {
+		t.Errorf("empty: status = %d, want 400", code)
+	}
+	if code := set(`{"window":"ANY"}`); code != http.StatusOK {
+		t.Errorf("any: status = %d, want 200", code)
+	}
+	if code := set(`{"window":" Sun 03:00-Sun 05:00 "}`); code != http.StatusOK {
+		t.Errorf("valid: status = %d, want 200", code)
+	}
+	if got := ws.entries["db"]; got != "Sun 03:00-Sun 05:00" {
+		t.Errorf("stored window = %q", got)
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api/stacks/db/window", nil)
+	r.SetPathValue("project", "db")
+	srv.apiGetStackWindow(w, r)
+	var resp map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp["override"] != "Sun 03:00-Sun 05:00" || resp["global"] != "02:00-04:00" || resp["effective"] != "Sun 03:00-Sun 05:00" {
+		t.Errorf("GET response = %v", resp)
+	}
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodDelete, "/api/stacks/db/window", nil)
+	r.SetPathValue("project", "db")
+	srv.apiDeleteStackWindow(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("DELETE status = %d", w.Code)
+	}
+	if _, ok := ws.entries["db"]; ok {
+		t.Error("override still stored after DELETE")
+	}
+}
+
+func TestApiStackWindowNoStore(t *testing.T) {
+	srv := &Server{deps: Dependencies{Log: slog.Default()}}
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/stacks/db/window", strings.NewReader(`{"window":"any"}`))
+	r.SetPathValue("project", "db")
+	srv.apiSetStackWindow(w, r)
+	if w.Code != http.StatusNotImplemented {
+		t.Errorf("status = %d, want 501", w.Code)
+	}
+}
@@ -0,0 +1,81 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/engine"
+	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+)
+
+// apiUpdateStack updates every running container of a Compose project as one
+// unit: all images are pulled first, members are recreated in dependency
+// order, and the whole stack is rolled back if any member fails.
+func (s *Server) apiUpdateStack(w http.ResponseWriter, r *http.Request) {
+	project := r.PathValue("project")
+	if !isValidContainerName(project) {
+		writeError(w, http.StatusBadRequest, "invalid project name")
+		return
+	}
+	if s.deps.Stacks == nil {
+		writeError(w, http.StatusNotImplemented, "stack updates not available")
+		return
+	}
+
+	containers, err := s.deps.Docker.ListAllContainers(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list containers")
+		return
+	}
+	var members []string
+	for _, c := range containers {
+		if c.State != "running" || c.Labels["com.docker.compose.project"] != project || c.Labels["sentinel.self"] == "true" {
+			continue
+		}
+		members = append(members, containerName(c))
+	}
+	if len(members) == 0 {
+		writeError(w, http.StatusNotFound, "no running containers in stack: "+project)
+		return
+	}
+
+	// Detached context since r.Context() dies with the response.
+	go func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				s.deps.Log.Error("panic in stack update goroutine", "project", project, "panic", rec)
+			}
+		}()
+		res, err := s.deps.Stacks.UpdateStack(context.Background(), project)
+		msg := "stack " + project + " updated"
+		switch {
+		case errors.Is(err, engine.ErrUpdateInProgress):
+			s.deps.Log.Warn("stack update skipped, member already updating", "project", project, "error", err)
+			msg = "stack " + project + ": " + err.Error()
+		case err != nil:
+			s.deps.Log.Error("stack update failed", "project", project, "error", err)
+			msg = "stack " + project + " update failed: " + err.Error()
+			if len(res.RolledBack) > 0 {
+				msg += " (rolled back " + strings.Join(res.RolledBack, ", ") + ")"
+			}
+		}
+		s.deps.EventBus.Publish(events.SSEEvent{
+			Type:          events.EventContainerUpdate,
+			ContainerName: res.Failed,
+			Message:       msg,
+			Timestamp:     time.Now(),
+		})
+	}()
+
+	s.logEvent(r, "update", project, "Stack update triggered ("+strings.Join(members, ", ")+")")
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"status":  "started",
+		"project": project,
+		"members": members,
+		"message": "update started for stack " + project,
+	})
+}
@@ -0,0 +1,92 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+)
+
+type mockStackUpdater struct {
+	called chan string
+}
+
+func (m *mockStackUpdater) UpdateStack(_ context.Context, project string) (StackResult, error) {
+	m.called <- project
+	return StackResult{Project: project}, nil
+}
+
+func newStackTestServer(stacks StackUpdater) *Server {
+	return &Server{
+		deps: Dependencies{
+			Docker: &mockContainerLister{containers: []ContainerSummary{
+				{State: "running", Names: []string{"/shop-app-1"}, Labels: map[string]string{"com.docker.compose.project": "shop"}},
+				{State: "running", Names: []string{"/shop-db-1"}, Labels: map[string]string{"com.docker.compose.project": "shop"}},
+				{State: "running", Names: []string{"/sentinel"}, Labels: map[string]string{"com.docker.compose.project": "infra", "sentinel.self": "true"}},
+			}},
+			Stacks:   stacks,
+			EventBus: events.New(),
+			Log:      slog.New(slog.NewTextHandler(io.Discard, nil)),
+		},
+	}
+}
+
+func stackRequest(srv *Server, project string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/stacks/"+project+"/update", nil)
+	r.SetPathValue("project", project)
+	srv.apiUpdateStack(w, r)
+	return w
+}
+
+func TestApiUpdateStack(t *testing.T) {
+	stacks := &mockStackUpdater{called: make(chan string, 1)}
+	w := stackRequest(newStackTestServer(stacks), "shop")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Members []string `json:"members"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Members) != 2 {
+		t.Errorf("members = %v, want 2", resp.Members)
+	}
+	select {
+	case got := <-stacks.called:
+		if got != "shop" {
+			t.Errorf("UpdateStack project = %q, want shop", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("UpdateStack not called")
+	}
+}
+
+func TestApiUpdateStackRejects(t *testing.T) {
+	srv := newStackTestServer(&mockStackUpdater{called: make(chan string, 1)})
+	tests := []struct {
+		project string
+		want    int
+	}{
+		{"nope", http.StatusNotFound},
+		{"infra", http.StatusNotFound}, // only member is Sentinel itself
+		{"bad$name", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if w := stackRequest(srv, tt.project); w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.project, w.Code, tt.want)
+		}
+	}
+
+	if w := stackRequest(newStackTestServer(nil), "shop"); w.Code != http.StatusNotImplemented {
+		t.Errorf("nil Stacks: status = %d, want 501", w.Code)
+	}
+}
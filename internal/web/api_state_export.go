@@ -0,0 +1,281 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sort"
+	"time"
+)
+
+// StateExport carries the operational state worth keeping when moving
+// Sentinel to a new server: pending approvals, ignored versions and
+// notification state. Unlike a database backup it can be merged into an
+// instance that already has state of its own.
+type StateExport struct {
+	Version         string                  `json:"version"`
+	ExportedAt      string                  `json:"exported_at"`
+	Queue           []PendingUpdate         `json:"queue"`
+	IgnoredVersions map[string][]string     `json:"ignored_versions"`
+	NotifyStates    map[string]*NotifyState `json:"notify_states"`
+	NotifyPrefs     map[string]*NotifyPref  `json:"notify_prefs"`
+}
+
+// StateImportResult summarises a state import. Conflicts lists entries that
+// existed on both sides, as "section: key".
+type StateImportResult struct {
+	Message         string   `json:"message"`
+	Queue           int      `json:"queue_imported"`
+	IgnoredVersions int      `json:"ignored_versions_imported"`
+	NotifyStates    int      `json:"notify_states_imported"`
+	NotifyPrefs     int      `json:"notify_prefs_imported"`
+	Conflict        string   `json:"conflict"`
+	Conflicts       []string `json:"conflicts,omitempty"`
+	Warnings        []string `json:"warnings,omitempty"`
+}
+
+const stateExportVersion = "1"
+
+// Import conflict modes: keep the local entry, or overwrite it.
+const (
+	conflictSkip    = "skip"
+	conflictReplace = "replace"
+)
+
+// apiStateExport sends the queue, ignored versions and notification state
+// as a downloadable JSON file.
+func (s *Server) apiStateExport(w http.ResponseWriter, r *http.Request) {
+	if !unscopedOnly(w, r) {
+		return
+	}
+	export := StateExport{
+		Version:         stateExportVersion,
+		ExportedAt:      time.Now().UTC().Format(time.RFC3339),
+		Queue:           []PendingUpdate{},
+		IgnoredVersions: map[string][]string{},
+		NotifyStates:    map[string]*NotifyState{},
+		NotifyPrefs:     map[string]*NotifyPref{},
+	}
+
+	if s.deps.Queue != nil {
+		if items := s.deps.Queue.List(); items != nil {
+			export.Queue = items
+		}
+	}
+	if s.deps.IgnoredVersions != nil {
+		ignored, err := s.deps.IgnoredVersions.AllIgnoredVersions()
+		if err != nil {
+			s.deps.Log.Error("state export: failed to load ignored versions", "error", err)
+			writeError(w, http.StatusInternalServerError, "failed to load ignored versions")
+			return
+		}
+		export.IgnoredVersions = ignored
+	}
+	if s.deps.NotifyState != nil {
+		states, err := s.deps.NotifyState.AllNotifyStates()
+		if err != nil {
+			s.deps.Log.Error("state export: failed to load notification states", "error", err)
+			writeError(w, http.StatusInternalServerError, "failed to load notification states")
+			return
+		}
+		prefs, err := s.deps.NotifyState.AllNotifyPrefs()
+		if err != nil {
+			s.deps.Log.Error("state export: failed to load notification preferences", "error", err)
+			writeError(w, http.StatusInternalServerError, "failed to load notification preferences")
+			return
+		}
+		if states != nil {
+			export.NotifyStates = states
+		}
+		if prefs != nil {
+			export.NotifyPrefs = prefs
+		}
+	}
+
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to serialise state")
+		return
+	}
+	filename := fmt.Sprintf("sentinel-state-%s.json", time.Now().UTC().Format("20060102"))
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
+
+// apiStateImport merges a state export into this instance. ?conflict=skip
+// (default) keeps local entries that also exist in the import;
+// ?conflict=replace overwrites them. Ignored versions are always merged.
+// Local queue entries get this host's container IDs, looked up by name.
+func (s *Server) apiStateImport(w http.ResponseWriter, r *http.Request) {
+	if !unscopedOnly(w, r) {
+		return
+	}
+	mode := r.URL.Query().Get("conflict")
+	if mode == "" {
+		mode = conflictSkip
+	}
+	if mode != conflictSkip && mode != conflictReplace {
+		writeError(w, http.StatusBadRequest, "conflict must be skip or replace")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 5<<20)
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "failed to read request body (max 5 MB)")
+		return
+	}
+	var imported StateExport
+	if err := json.Unmarshal(body, &imported); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+	if imported.Version == "" {
+		writeError(w, http.StatusBadRequest, "missing 'version' field — not a valid Sentinel state export")
+		return
+	}
+	if imported.Version != stateExportVersion {
+		writeError(w, http.StatusBadRequest, "unsupported state version: "+imported.Version+" (this instance supports version 1)")
+		return
+	}
+
+	result := StateImportResult{Conflict: mode}
+	replace := mode == conflictReplace
+	var changes auditChanges
+
+	// --- Queue ---
+	if s.deps.Queue != nil && len(imported.Queue) > 0 {
+		ids := s.localContainerIDs(r)
+		for _, item := range imported.Queue {
+			if !isValidContainerName(item.ContainerName) {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("invalid queue entry %q rejected", item.ContainerName))
+				continue
+			}
+			key := item.Key()
+			var old any
+			if existing, exists := s.deps.Queue.Get(key); exists {
+				old = existing
+				result.Conflicts = append(result.Conflicts, "queue: "+key)
+				if !replace {
+					continue
+				}
+			}
+			if item.HostID == "" && item.Type != "service" && ids != nil {
+				if id, ok := ids[item.ContainerName]; ok {
+					item.ContainerID = id
+				} else {
+					result.Warnings = append(result.Warnings, item.ContainerName+" is not running here yet; approving it will fail until the next scan refreshes it")
+				}
+			}
+			s.deps.Queue.Add(item)
+			result.Queue++
+			changes.entry("queue", key, old, item)
+		}
+	}
+
+	// --- Ignored versions ---
+	if s.deps.IgnoredVersions != nil && len(imported.IgnoredVersions) > 0 {
+		for name, versions := range imported.IgnoredVersions {
+			existing, _ := s.deps.IgnoredVersions.GetIgnoredVersions(name)
+			merged := slices.Clone(existing)
+			for _, v := range versions {
+				if v == "" || slices.Contains(merged, v) {
+					continue
+				}
+				if err := s.deps.IgnoredVersions.AddIgnoredVersion(name, v); err != nil {
+					s.deps.Log.Warn("state import: failed to save ignored version", "name", name, "version", v, "error", err)
+					continue
+				}
+				merged = append(merged, v)
+				result.IgnoredVersions++
+			}
+			if len(merged) > len(existing) {
+				changes.entry("ignored_versions", name, existing, merged)
+			}
+		}
+	}
+
+	// --- Notification state and preferences ---
+	if s.deps.NotifyState != nil && (len(imported.NotifyStates) > 0 || len(imported.NotifyPrefs) > 0) {
+		states, _ := s.deps.NotifyState.AllNotifyStates()
+		for name, state := range imported.NotifyStates {
+			if state == nil {
+				continue
+			}
+			old, exists := states[name]
+			if exists {
+				result.Conflicts = append(result.Conflicts, "notify_state: "+name)
+				if !replace {
+					continue
+				}
+			}
+			if err := s.deps.NotifyState.SetNotifyState(name, state); err != nil {
+				s.deps.Log.Warn("state import: failed to save notification state", "name", name, "error", err)
+				continue
+			}
+			result.NotifyStates++
+			changes.entry("notify_states", name, old, state)
+		}
+
+		prefs, _ := s.deps.NotifyState.AllNotifyPrefs()
+		for name, pref := range imported.NotifyPrefs {
+			if pref == nil {
+				continue
+			}
+			switch pref.Mode {
+			case "default", "every_scan", "digest_only", "muted":
+			default:
+				result.Warnings = append(result.Warnings, fmt.Sprintf("invalid notification mode %q for %s rejected", pref.Mode, name))
+				continue
+			}
+			old, exists := prefs[name]
+			if exists {
+				result.Conflicts = append(result.Conflicts, "notify_pref: "+name)
+				if !replace {
+					continue
+				}
+			}
+			if err := s.deps.NotifyState.SetNotifyPref(name, pref); err != nil {
+				s.deps.Log.Warn("state import: failed to save notification preference", "name", name, "error", err)
+				continue
+			}
+			result.NotifyPrefs++
+			changes.entry("notify_prefs", name, old, pref)
+		}
+	}
+	sort.Strings(result.Conflicts)
+
+	result.Message = fmt.Sprintf("Imported %d queued updates, %d ignored versions, %d notification states, %d notification preferences",
+		result.Queue, result.IgnoredVersions, result.NotifyStates, result.NotifyPrefs)
+	if n := len(result.Conflicts); n > 0 {
+		verb := "kept local"
+		if replace {
+			verb = "replaced"
+		}
+		result.Message += fmt.Sprintf(" (%d conflicts %s)", n, verb)
+	}
+	s.logEvent(r, "state_import", "", result.Message)
+	changes.record(r)
+	writeJSON(w, http.StatusOK, result)
+}
+
+// localContainerIDs maps local container names to their IDs, or returns nil
+// when Docker cannot be listed.
+func (s *Server) localContainerIDs(r *http.Request) map[string]string {
+	if s.deps.Docker == nil {
+		return nil
+	}
+	containers, err := s.deps.Docker.ListAllContainers(r.Context())
+	if err != nil {
+		return nil
+	}
+	ids := make(map[string]string, len(containers))
+	for _, c := range containers {
+		ids[containerName(c)] = c.ID
+	}
+	return ids
+}
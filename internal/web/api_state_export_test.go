@@ -0,0 +1,136 @@
+package web
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type mockIgnoredVersions struct {
+	versions map[string][]string
+}
+
+func (m *mockIgnoredVersions) AddIgnoredVersion(name, version string) error {
+	m.versions[name] = append(m.versions[name], version)
+	return nil
+}
+func (m *mockIgnoredVersions) GetIgnoredVersions(name string) ([]string, error) {
+	return m.versions[name], nil
+}
+func (m *mockIgnoredVersions) AllIgnoredVersions() (map[string][]string, error) {
+	return m.versions, nil
+}
+func (m *mockIgnoredVersions) ClearIgnoredVersions(name string) error {
+	delete(m.versions, name)
+	return nil
+}
+
+func newStateTestServer(q *mockQueue, iv *mockIgnoredVersions, ns *mockNotifyState) *Server {
+	return &Server{deps: Dependencies{
+		Queue:           q,
+		IgnoredVersions: iv,
+		NotifyState:     ns,
+		Docker: &mockContainerLister{containers: []ContainerSummary{
+			{ID: "new-id", Names: []string{"/nginx"}},
+		}},
+		Log: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}}
+}
+
+func TestApiStateExportImportRoundTrip(t *testing.T) {
+	src := newStateTestServer(
+		&mockQueue{items: []PendingUpdate{{ContainerID: "old-id", ContainerName: "nginx", NewerVersions: []string{"1.27"}, DetectedAt: time.Now()}}},
+		&mockIgnoredVersions{versions: map[string][]string{"redis": {"8.0"}}},
+		&mockNotifyState{prefs: map[string]*NotifyPref{"redis": {Mode: "muted"}}},
+	)
+	w := httptest.NewRecorder()
+	src.apiStateExport(w, httptest.NewRequest(http.MethodGet, "/api/state/export", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("export status = %d; body: %s", w.Code, w.Body.String())
+	}
+
+	q := &mockQueue{}
+	iv := &mockIgnoredVersions{versions: map[string][]string{}}
+	ns := &mockNotifyState{prefs: map[string]*NotifyPref{}}
+	dst := newStateTestServer(q, iv, ns)
+	w2 := httptest.NewRecorder()
+	dst.apiStateImport(w2, httptest.NewRequest(http.MethodPost, "/api/state/import", strings.NewReader(w.Body.String())))
+	if w2.Code != http.StatusOK {
+		t.Fatalf("import status = %d; body: %s", w2.Code, w2.Body.String())
+	}
+
+	if len(q.items) != 1 || q.items[0].ContainerID != "new-id" {
+		t.Errorf("queue = %+v, want nginx remapped to new-id", q.items)
+	}
+	if got := iv.versions["redis"]; len(got) != 1 || got[0] != "8.0" {
+		t.Errorf("ignored versions = %v, want [8.0]", got)
+	}
+	if ns.prefs["redis"] == nil || ns.prefs["redis"].Mode != "muted" {
+		t.Errorf("notify prefs = %v, want redis muted", ns.prefs)
+	}
+}
+
+func TestApiStateImportConflicts(t *testing.T) {
+	body := `{"version":"1",
+		"queue":[{"container_name":"nginx","current_image":"nginx:1.26"}],
+		"ignored_versions":{"nginx":["1.27","1.28"]},
+		"notify_prefs":{"nginx":{"mode":"digest_only"}}}`
+
+	for _, tt := range []struct {
+		mode     string
+		wantMode string
+	}{
+		{"", "muted"},
+		{"replace", "digest_only"},
+	} {
+		t.Run("conflict="+tt.mode, func(t *testing.T) {
+			q := &mockQueue{items: []PendingUpdate{{ContainerName: "nginx", CurrentImage: "nginx:1.25"}}}
+			iv := &mockIgnoredVersions{versions: map[string][]string{"nginx": {"1.27"}}}
+			ns := &mockNotifyState{prefs: map[string]*NotifyPref{"nginx": {Mode: "muted"}}}
+			srv := newStateTestServer(q, iv, ns)
+
+			w := httptest.NewRecorder()
+			srv.apiStateImport(w, httptest.NewRequest(http.MethodPost, "/api/state/import?conflict="+tt.mode, strings.NewReader(body)))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d; body: %s", w.Code, w.Body.String())
+			}
+			var result StateImportResult
+			_ = json.Unmarshal(w.Body.Bytes(), &result)
+
+			if len(result.Conflicts) != 2 {
+				t.Errorf("conflicts = %v, want queue and notify_pref", result.Conflicts)
+			}
+			if ns.prefs["nginx"].Mode != tt.wantMode {
+				t.Errorf("mode = %q, want %q", ns.prefs["nginx"].Mode, tt.wantMode)
+			}
+			// Ignored versions merge whatever the conflict mode.
+			if got := iv.versions["nginx"]; len(got) != 2 {
+				t.Errorf("ignored versions = %v, want 1.27 and 1.28", got)
+			}
+			if tt.mode == "" && (len(q.items) != 1 || q.items[0].CurrentImage != "nginx:1.25") {
+				t.Errorf("queue = %+v, want the local entry kept", q.items)
+			}
+		})
+	}
+}
+
+func TestApiStateImportInvalid(t *testing.T) {
+	srv := newStateTestServer(&mockQueue{}, &mockIgnoredVersions{versions: map[string][]string{}}, &mockNotifyState{prefs: map[string]*NotifyPref{}})
+	for name, tt := range map[string]struct{ query, body string }{
+		"bad mode":    {"?conflict=merge", `{"version":"1"}`},
+		"no version":  {"", `{"queue":[]}`},
+		"new version": {"", `{"version":"9"}`},
+		"not json":    {"", `queue`},
+	} {
+		w := httptest.NewRecorder()
+		srv.apiStateImport(w, httptest.NewRequest(http.MethodPost, "/api/state/import"+tt.query, strings.NewReader(tt.body)))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", name, w.Code)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+	"github.com/Will-Luck/Docker-Sentinel/internal/ticket"
+)
+
+// loadTicketConfig reads the stored ticketing settings.
+func (s *Server) loadTicketConfig() ticket.Config {
+	var c ticket.Config
+	if s.deps.SettingsStore == nil {
+		return c
+	}
+	if raw, _ := s.deps.SettingsStore.LoadSetting(store.SettingTicketing); raw != "" {
+		_ = json.Unmarshal([]byte(raw), &c)
+	}
+	return c
+}
+
+// apiGetTicketing returns the ticketing settings with the token withheld.
+func (s *Server) apiGetTicketing(w http.ResponseWriter, _ *http.Request) {
+	c := s.loadTicketConfig()
+	tokenSet := c.Token != ""
+	c.Token = ""
+	writeJSON(w, http.StatusOK, map[string]any{
+		"config":    c,
+		"token_set": tokenSet,
+	})
+}
+
+// apiSetTicketing configures the ticketing integration. An empty token keeps
+// the stored one, so the UI never has to read it back. An empty provider
+// disables the integration.
+func (s *Server) apiSetTicketing(w http.ResponseWriter, r *http.Request) {
+	var req ticket.Config
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if s.deps.SettingsStore == nil {
+		writeError(w, http.StatusNotImplemented, "settings store not available")
+		return
+	}
+	if req.Token == "" {
+		req.Token = s.loadTicketConfig().Token
+	}
+	if err := ticket.ValidateConfig(req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to encode settings")
+		return
+	}
+	if err := s.deps.SettingsStore.SaveSetting(store.SettingTicketing, string(data)); err != nil {
+		s.deps.Log.Error("failed to save ticketing settings", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to save setting")
+		return
+	}
+
+	msg := "Ticketing disabled"
+	if req.Provider != "" {
+		msg = "Ticketing set to " + req.Provider
+	}
+	s.logEvent(r, "settings", "", msg)
+	writeJSON(w, http.StatusOK, map[string]string{"message": "ticketing updated"})
+}
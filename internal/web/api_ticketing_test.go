@@ -0,0 +1,59 @@
+package web
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+)
+
+func TestApiSetTicketing_KeepsStoredToken(t *testing.T) {
+	ss := newMockSettingsStore()
+	srv := newTestServer(ss)
+	srv.deps.Log = slog.Default()
+
+	put := func(body string) int {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPut, "/api/settings/ticketing", strings.NewReader(body))
+		srv.apiSetTicketing(w, r)
+		return w.Code
+	}
+	if code := put(`{"provider":"github","repo":"o/r","token":"secret"}`); code != http.StatusOK {
+		t.Fatalf("first save status = %d", code)
+	}
+	if code := put(`{"provider":"github","repo":"o/other","stacks":["prod"]}`); code != http.StatusOK {
+		t.Fatalf("second save status = %d", code)
+	}
+	if !strings.Contains(ss.data[store.SettingTicketing], `"token":"secret"`) {
+		t.Errorf("stored token lost: %s", ss.data[store.SettingTicketing])
+	}
+
+	w := httptest.NewRecorder()
+	srv.apiGetTicketing(w, httptest.NewRequest(http.MethodGet, "/api/settings/ticketing", nil))
+	var resp struct {
+		Config   map[string]any `json:"config"`
+		TokenSet bool           `json:"token_set"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if !resp.TokenSet || resp.Config["token"] != nil || resp.Config["repo"] != "o/other" {
+		t.Errorf("GET = %+v", resp)
+	}
+}
+
+func TestApiSetTicketing_Invalid(t *testing.T) {
+	srv := newTestServer(newMockSettingsStore())
+	srv.deps.Log = slog.Default()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPut, "/api/settings/ticketing", strings.NewReader(`{"provider":"jira","token":"t"}`))
+	srv.apiSetTicketing(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", w.Code)
+	}
+}
@@ -0,0 +1,148 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/auth"
+	"github.com/Will-Luck/Docker-Sentinel/internal/docker"
+)
+
+const (
+	// timelineDefaultLimit and timelineMaxLimit bound ?limit= on /api/timeline.
+	timelineDefaultLimit = 100
+	timelineMaxLimit     = 500
+)
+
+// timelineSourcePerms maps each timeline source to the permission needed to
+// see it, so the timeline never shows more than the separate views would.
+var timelineSourcePerms = []struct {
+	source string
+	perm   auth.Permission
+}{
+	{"log", auth.PermLogsView},
+	{"history", auth.PermHistoryView},
+	{"cluster", auth.PermClusterView},
+}
+
+// parseTimelineSince accepts an RFC 3339 timestamp or a duration before now
+// such as "12h" or "7d".
+func parseTimelineSince(v string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	d, err := docker.ParseDurationWithDays(v)
+	if err != nil || d <= 0 {
+		return time.Time{}, errors.New("since must be an RFC 3339 time or a duration such as 12h or 7d")
+	}
+	return now.Add(-d), nil
+}
+
+// apiTimeline returns the activity log, update history and cluster host
+// events merged into one timeline, newest first.
+//
+// Query parameters, all optional:
+//   - since: RFC 3339 time or duration ("12h", "7d") bounding the oldest entry
+//   - type: comma-separated sources (log, history, cluster) or entry types
+//     (e.g. "failed,rollback,disconnected")
+//   - container, host: exact container name or cluster host ID
+//   - before: the next_cursor of the previous page
+//   - limit: page size, default 100, at most 500
+func (s *Server) apiTimeline(w http.ResponseWriter, r *http.Request) {
+	if s.deps.Timeline == nil {
+		writeError(w, http.StatusNotImplemented, "timeline not available")
+		return
+	}
+	params := r.URL.Query()
+	q := TimelineQuery{
+		Before:    params.Get("before"),
+		Container: params.Get("container"),
+		HostID:    params.Get("host"),
+		Limit:     timelineDefaultLimit,
+	}
+	if v := params.Get("since"); v != "" {
+		since, err := parseTimelineSince(v, time.Now())
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		q.Since = since
+	}
+	if v := params.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > timelineMaxLimit {
+			writeError(w, http.StatusBadRequest, "limit must be between 1 and "+strconv.Itoa(timelineMaxLimit))
+			return
+		}
+		q.Limit = n
+	}
+	for _, t := range strings.Split(params.Get("type"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			q.Types = append(q.Types, t)
+		}
+	}
+
+	// Only read the sources the caller may see. The route itself requires
+	// history.view, so at least one source always remains.
+	if rc := auth.GetRequestContext(r.Context()); rc != nil {
+		for _, sp := range timelineSourcePerms {
+			if rc.HasPermission(sp.perm) {
+				q.Sources = append(q.Sources, sp.source)
+			}
+		}
+	}
+
+	entries, next, err := s.scopedTimeline(r, q)
+	if errors.Is(err, ErrInvalidTimelineCursor) {
+		writeError(w, http.StatusBadRequest, "invalid before cursor")
+		return
+	}
+	if err != nil {
+		s.deps.Log.Error("failed to list timeline", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to list timeline")
+		return
+	}
+	if entries == nil {
+		entries = []TimelineEntry{}
+	}
+
+	// Host events only record the host ID; name the host when it is known.
+	if s.deps.Cluster != nil && s.deps.Cluster.Enabled() {
+		names := make(map[string]string)
+		for i := range entries {
+			e := &entries[i]
+			if e.HostID == "" || e.HostName != "" {
+				continue
+			}
+			name, ok := names[e.HostID]
+			if !ok {
+				if h, found := s.deps.Cluster.GetHost(e.HostID); found {
+					name = h.Name
+				}
+				names[e.HostID] = name
+			}
+			e.HostName = name
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"entries":     entries,
+		"next_cursor": next,
+	})
+}
+
+// handleTimeline renders the activity timeline page. Entries are loaded
+// from /api/timeline by the page script.
+func (s *Server) handleTimeline(w http.ResponseWriter, r *http.Request) {
+	data := pageData{
+		Page:       "timeline",
+		QueueCount: len(s.scopedQueue(r)),
+	}
+	s.withAuth(r, &data)
+	s.withCluster(&data)
+	s.withPortainer(&data)
+	s.renderTemplate(w, "timeline.html", data)
+}
@@ -0,0 +1,138 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/auth"
+)
+
+// mockTimelineStore records the last query and returns canned entries.
+type mockTimelineStore struct {
+	entries []TimelineEntry
+	next    string
+	err     error
+	got     TimelineQuery
+}
+
+func (m *mockTimelineStore) ListTimeline(q TimelineQuery) ([]TimelineEntry, string, error) {
+	m.got = q
+	return m.entries, m.next, m.err
+}
+
+func TestApiTimeline_ParsesQuery(t *testing.T) {
+	ts := &mockTimelineStore{
+		entries: []TimelineEntry{
+			{Source: "cluster", Type: "disconnected", HostID: "h1", Message: "lost", Cursor: "c1"},
+			{Source: "history", Type: "failed", Container: "web", Message: "failed: nginx:1.27", Failed: true, Cursor: "c2"},
+		},
+		next: "c2",
+	}
+	cc := NewClusterController()
+	cc.SetProvider(&mockClusterProvider{hosts: []ClusterHost{{ID: "h1", Name: "pi"}}})
+	srv := &Server{deps: Dependencies{Timeline: ts, Cluster: cc}}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api/timeline?since=12h&type=failed,+cluster&container=web&host=h1&before=abc&limit=50", nil)
+	srv.apiTimeline(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	q := ts.got
+	if q.Container != "web" || q.HostID != "h1" || q.Before != "abc" || q.Limit != 50 {
+		t.Errorf("query = %+v", q)
+	}
+	if !slices.Equal(q.Types, []string{"failed", "cluster"}) {
+		t.Errorf("Types = %q", q.Types)
+	}
+	if age := time.Since(q.Since); age < 12*time.Hour-time.Minute || age > 12*time.Hour+time.Minute {
+		t.Errorf("Since = %v, want about 12h ago", q.Since)
+	}
+
+	var resp struct {
+		Entries    []TimelineEntry `json:"entries"`
+		NextCursor string          `json:"next_cursor"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.NextCursor != "c2" || len(resp.Entries) != 2 {
+		t.Fatalf("resp = %+v", resp)
+	}
+	if resp.Entries[0].HostName != "pi" {
+		t.Errorf("HostName = %q, want the cluster host name", resp.Entries[0].HostName)
+	}
+}
+
+func TestApiTimeline_RestrictsSourcesToPermissions(t *testing.T) {
+	ts := &mockTimelineStore{}
+	srv := &Server{deps: Dependencies{Timeline: ts}}
+
+	r := httptest.NewRequest(http.MethodGet, "/api/timeline", nil)
+	rc := &auth.RequestContext{Permissions: []auth.Permission{auth.PermHistoryView}}
+	r = r.WithContext(context.WithValue(r.Context(), auth.ContextKey, rc))
+	w := httptest.NewRecorder()
+	srv.apiTimeline(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	if !slices.Equal(ts.got.Sources, []string{"history"}) {
+		t.Errorf("Sources = %q, want only history", ts.got.Sources)
+	}
+	if ts.got.Limit != timelineDefaultLimit {
+		t.Errorf("Limit = %d, want default %d", ts.got.Limit, timelineDefaultLimit)
+	}
+	if body := w.Body.String(); body != "{\"entries\":[],\"next_cursor\":\"\"}\n" {
+		t.Errorf("body = %s", body)
+	}
+}
+
+func TestApiTimeline_BadRequests(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		err  error
+	}{
+		{"bad since", "/api/timeline?since=yesterday", nil},
+		{"limit too large", "/api/timeline?limit=501", nil},
+		{"foreign cursor", "/api/timeline?before=zzz", ErrInvalidTimelineCursor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &Server{deps: Dependencies{Timeline: &mockTimelineStore{err: tt.err}}}
+			w := httptest.NewRecorder()
+			srv.apiTimeline(w, httptest.NewRequest(http.MethodGet, tt.url, nil))
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", w.Code)
+			}
+		})
+	}
+}
+
+func TestParseTimelineSince(t *testing.T) {
+	now := time.Date(2026, 3, 10, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"2026-03-09T22:00:00Z", time.Date(2026, 3, 9, 22, 0, 0, 0, time.UTC)},
+		{"10h", time.Date(2026, 3, 9, 22, 0, 0, 0, time.UTC)},
+		{"2d", time.Date(2026, 3, 8, 8, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := parseTimelineSince(tt.in, now)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("parseTimelineSince(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := parseTimelineSince("-1h", now); err == nil {
+		t.Error("negative duration accepted")
+	}
+}
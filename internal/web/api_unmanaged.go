@@ -0,0 +1,165 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+)
+
+// unmanagedSet returns all unmanaged entries, or an empty map when the
+// store is not wired. Keys are "name" for local and "hostID::name" for
+// remote containers.
+func (s *Server) unmanagedSet() map[string]UnmanagedEntry {
+	if s.deps.Unmanaged == nil {
+		return map[string]UnmanagedEntry{}
+	}
+	return s.deps.Unmanaged.AllUnmanaged()
+}
+
+// hostScopedKey builds the store key for a container, honouring ?host= for
+// remote containers the same way policy overrides do. Shared by the
+// per-container overrides (unmanaged, validation).
+func hostScopedKey(r *http.Request, name string) string {
+	if hostID := r.URL.Query().Get("host"); hostID != "" {
+		return hostID + "::" + name
+	}
+	return name
+}
+
+// apiListUnmanaged returns every unmanaged container in the user's scope
+// for the hidden-items view.
+func (s *Server) apiListUnmanaged(w http.ResponseWriter, r *http.Request) {
+	type item struct {
+		Name   string    `json:"name"`
+		HostID string    `json:"host_id,omitempty"`
+		Since  time.Time `json:"since"`
+		Hidden bool      `json:"hidden"`
+	}
+	entries := s.unmanagedSet()
+	allowed := s.scopeFilter(r)
+	out := make([]item, 0, len(entries))
+	for key, e := range entries {
+		if !allowed(key) {
+			continue
+		}
+		it := item{Name: key, Since: e.Since, Hidden: e.Hidden}
+		if i := strings.Index(key, "::"); i >= 0 {
+			it.HostID, it.Name = key[:i], key[i+2:]
+		}
+		out = append(out, it)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].HostID != out[j].HostID {
+			return out[i].HostID < out[j].HostID
+		}
+		return out[i].Name < out[j].Name
+	})
+	writeJSON(w, http.StatusOK, out)
+}
+
+// apiSetUnmanaged marks a container as unmanaged. Body: {"hidden": bool}
+// (optional; hidden also removes it from the dashboard).
+func (s *Server) apiSetUnmanaged(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if !isValidContainerName(name) {
+		writeError(w, http.StatusBadRequest, "invalid container name")
+		return
+	}
+	if s.deps.Unmanaged == nil {
+		writeError(w, http.StatusNotImplemented, "unmanaged store not available")
+		return
+	}
+
+	var body struct {
+		Hidden bool `json:"hidden"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+
+	if s.isProtectedContainer(r.Context(), name) {
+		writeError(w, http.StatusForbidden, "cannot unmanage sentinel itself")
+		return
+	}
+
+	key := hostScopedKey(r, name)
+	var old any
+	entry := UnmanagedEntry{Since: time.Now(), Hidden: body.Hidden}
+	if e, ok := s.unmanagedSet()[key]; ok {
+		old = e
+		// Changing visibility keeps the time it was first unmanaged.
+		entry.Since = e.Since
+	}
+	if err := s.deps.Unmanaged.SetUnmanaged(key, entry); err != nil {
+		s.deps.Log.Error("failed to mark container unmanaged", "name", name, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to mark container unmanaged")
+		return
+	}
+	auditState(r, old, entry)
+	// Drop any pending update so it doesn't linger in the queue.
+	s.deps.Queue.Remove(key)
+
+	msg := "Container marked unmanaged"
+	if body.Hidden {
+		msg += " and hidden from dashboard"
+	}
+	s.logEvent(r, "unmanaged_set", name, msg)
+	s.deps.EventBus.Publish(events.SSEEvent{
+		Type:          events.EventPolicyChange,
+		ContainerName: name,
+		Message:       msg,
+		Timestamp:     time.Now(),
+	})
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"status": "ok",
+		"name":   name,
+		"hidden": body.Hidden,
+	})
+}
+
+// apiDeleteUnmanaged returns a container to normal management.
+func (s *Server) apiDeleteUnmanaged(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if !isValidContainerName(name) {
+		writeError(w, http.StatusBadRequest, "invalid container name")
+		return
+	}
+	if s.deps.Unmanaged == nil {
+		writeError(w, http.StatusNotImplemented, "unmanaged store not available")
+		return
+	}
+
+	key := hostScopedKey(r, name)
+	old, ok := s.unmanagedSet()[key]
+	if !ok {
+		writeError(w, http.StatusNotFound, name+" is not unmanaged")
+		return
+	}
+	if err := s.deps.Unmanaged.DeleteUnmanaged(key); err != nil {
+		s.deps.Log.Error("failed to restore managed container", "name", name, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to restore container")
+		return
+	}
+	auditState(r, old, nil)
+
+	s.logEvent(r, "unmanaged_delete", name, "Container returned to management")
+	s.deps.EventBus.Publish(events.SSEEvent{
+		Type:          events.EventPolicyChange,
+		ContainerName: name,
+		Message:       name + " returned to management",
+		Timestamp:     time.Now(),
+	})
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"status": "ok",
+		"name":   name,
+	})
+}
@@ -0,0 +1,194 @@
+package web
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+)
+
+// ---------------------------------------------------------------------------
+// Mock: UnmanagedStore
+// ---------------------------------------------------------------------------
+
+type mockUnmanagedStore struct {
+	entries map[string]UnmanagedEntry
+}
+
+func newMockUnmanagedStore() *mockUnmanagedStore {
+	return &mockUnmanagedStore{entries: make(map[string]UnmanagedEntry)}
+}
+
+func (m *mockUnmanagedStore) SetUnmanaged(key string, e UnmanagedEntry) error {
+	m.entries[key] = e
+	return nil
+}
+
+func (m *mockUnmanagedStore) DeleteUnmanaged(key string) error {
+	delete(m.entries, key)
+	return nil
+}
+
+func (m *mockUnmanagedStore) AllUnmanaged() map[string]UnmanagedEntry {
+	out := make(map[string]UnmanagedEntry, len(m.entries))
+	for k, v := range m.entries {
+		out[k] = v
+	}
+	return out
+}
+
+func newUnmanagedTestServer(store *mockUnmanagedStore, containers []ContainerSummary) *Server {
+	return &Server{
+		deps: Dependencies{
+			Docker:    &mockContainerLister{containers: containers},
+			Unmanaged: store,
+			Queue:     &mockUpdateQueue{},
+			EventBus:  events.New(),
+			Log:       slog.Default(),
+		},
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Tests
+// ---------------------------------------------------------------------------
+
+func TestApiSetUnmanaged_Hidden(t *testing.T) {
+	ms := newMockUnmanagedStore()
+	srv := newUnmanagedTestServer(ms, nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPut, "/api/containers/nginx/unmanaged?host=h1", strings.NewReader(`{"hidden":true}`))
+	r.SetPathValue("name", "nginx")
+	srv.apiSetUnmanaged(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	e, ok := ms.entries["h1::nginx"]
+	if !ok {
+		t.Fatal("expected h1::nginx to be unmanaged")
+	}
+	if !e.Hidden {
+		t.Error("Hidden = false, want true")
+	}
+}
+
+func TestApiSetUnmanaged_KeepsSince(t *testing.T) {
+	ms := newMockUnmanagedStore()
+	since := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	ms.entries["nginx"] = UnmanagedEntry{Since: since, Hidden: true}
+	srv := newUnmanagedTestServer(ms, nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPut, "/api/containers/nginx/unmanaged", strings.NewReader(`{"hidden":false}`))
+	r.SetPathValue("name", "nginx")
+	srv.apiSetUnmanaged(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	e := ms.entries["nginx"]
+	if e.Hidden {
+		t.Error("Hidden = true, want false after unhiding")
+	}
+	if !e.Since.Equal(since) {
+		t.Errorf("Since = %v, want the original %v", e.Since, since)
+	}
+}
+
+func TestApiSetUnmanaged_EmptyBody(t *testing.T) {
+	ms := newMockUnmanagedStore()
+	srv := newUnmanagedTestServer(ms, nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPut, "/api/containers/nginx/unmanaged", nil)
+	r.SetPathValue("name", "nginx")
+	srv.apiSetUnmanaged(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	if e, ok := ms.entries["nginx"]; !ok || e.Hidden {
+		t.Errorf("entry = %+v (ok=%v), want visible unmanaged entry", e, ok)
+	}
+}
+
+func TestApiSetUnmanaged_SelfProtected(t *testing.T) {
+	ms := newMockUnmanagedStore()
+	srv := newUnmanagedTestServer(ms, []ContainerSummary{
+		{Names: []string{"/sentinel"}, Labels: map[string]string{"sentinel.self": "true"}},
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPut, "/api/containers/sentinel/unmanaged", nil)
+	r.SetPathValue("name", "sentinel")
+	srv.apiSetUnmanaged(w, r)
+
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want 403", w.Code)
+	}
+	if len(ms.entries) != 0 {
+		t.Errorf("expected no entries, got %v", ms.entries)
+	}
+}
+
+func TestApiDeleteUnmanaged(t *testing.T) {
+	ms := newMockUnmanagedStore()
+	ms.entries["nginx"] = UnmanagedEntry{Since: time.Now()}
+	srv := newUnmanagedTestServer(ms, nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodDelete, "/api/containers/nginx/unmanaged", nil)
+	r.SetPathValue("name", "nginx")
+	srv.apiDeleteUnmanaged(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	if _, ok := ms.entries["nginx"]; ok {
+		t.Error("nginx still unmanaged")
+	}
+
+	// Second delete is a 404.
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodDelete, "/api/containers/nginx/unmanaged", nil)
+	r.SetPathValue("name", "nginx")
+	srv.apiDeleteUnmanaged(w, r)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
+
+func TestApiListUnmanaged_SplitsHost(t *testing.T) {
+	ms := newMockUnmanagedStore()
+	ms.entries["nginx"] = UnmanagedEntry{}
+	ms.entries["h1::redis"] = UnmanagedEntry{Hidden: true}
+	srv := newUnmanagedTestServer(ms, nil)
+
+	w := httptest.NewRecorder()
+	srv.apiListUnmanaged(w, httptest.NewRequest(http.MethodGet, "/api/unmanaged", nil))
+
+	var got []struct {
+		Name   string `json:"name"`
+		HostID string `json:"host_id"`
+		Hidden bool   `json:"hidden"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("len = %d, want 2", len(got))
+	}
+	if got[0].Name != "nginx" || got[0].HostID != "" {
+		t.Errorf("got[0] = %+v, want local nginx", got[0])
+	}
+	if got[1].Name != "redis" || got[1].HostID != "h1" || !got[1].Hidden {
+		t.Errorf("got[1] = %+v, want hidden h1/redis", got[1])
+	}
+}
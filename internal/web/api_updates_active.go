@@ -0,0 +1,120 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/docker"
+	"github.com/Will-Luck/Docker-Sentinel/internal/engine"
+	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+)
+
+// apiActiveUpdates lists the updates in progress: local ones with their
+// current phase and whether they are stuck, plus remote updates still
+// waiting on a cluster agent. Scoped users only see their own containers.
+func (s *Server) apiActiveUpdates(w http.ResponseWriter, r *http.Request) {
+	active := []engine.ActiveUpdate{}
+	if s.deps.UpdateLocks != nil {
+		active = append(active, s.deps.UpdateLocks.ActiveUpdates()...)
+	}
+	if s.deps.Cluster != nil && s.deps.Cluster.Enabled() {
+		for _, a := range s.deps.Cluster.RemoteUpdates() {
+			if h, ok := s.deps.Cluster.GetHost(a.HostID); ok {
+				a.HostName = h.Name
+			}
+			active = append(active, a)
+		}
+	}
+	if requestScope(r) != nil {
+		allowed := s.scopeFilter(r)
+		visible := active[:0]
+		for _, a := range active {
+			if allowed(containerKey(a.HostID, a.Name)) {
+				visible = append(visible, a)
+			}
+		}
+		active = visible
+	}
+	sort.SliceStable(active, func(i, j int) bool { return active[i].StartedAt.Before(active[j].StartedAt) })
+	writeJSON(w, http.StatusOK, active)
+}
+
+// apiForceUnlockUpdate releases the lock of a stuck local update so it can
+// be retried. The engine refuses updates that are not yet stuck and reports
+// anything the interrupted update left behind.
+func (s *Server) apiForceUnlockUpdate(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if !isValidContainerName(name) {
+		writeError(w, http.StatusBadRequest, "invalid container name")
+		return
+	}
+	if s.deps.UpdateLocks == nil {
+		writeError(w, http.StatusNotImplemented, "update locks not available")
+		return
+	}
+
+	res, err := s.deps.UpdateLocks.ForceUnlock(r.Context(), name)
+	switch {
+	case errors.Is(err, engine.ErrNotUpdating):
+		writeError(w, http.StatusNotFound, name+" has no update in progress")
+		return
+	case errors.Is(err, engine.ErrUpdateNotStuck):
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		s.deps.Log.Error("force unlock failed", "name", name, "error", err)
+		writeError(w, http.StatusInternalServerError, "force unlock failed")
+		return
+	}
+
+	msg := "Stuck update lock released (phase " + res.Phase + ")"
+	if len(res.Warnings) > 0 {
+		msg += ": " + strings.Join(res.Warnings, "; ")
+	}
+	s.logEvent(r, "force_unlock", name, msg)
+	s.deps.EventBus.Publish(events.SSEEvent{
+		Type:          events.EventContainerUpdate,
+		ContainerName: name,
+		Message:       "update lock released",
+		Timestamp:     time.Now(),
+	})
+	writeJSON(w, http.StatusOK, res)
+}
+
+// apiSetStuckUpdateThreshold sets how long an update may stay in one phase
+// before it is reported as stuck and can be force-unlocked. An empty value
+// restores the default of 30 minutes.
+func (s *Server) apiSetStuckUpdateThreshold(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Duration string `json:"duration"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+	if req.Duration != "" {
+		if d, err := docker.ParseDurationWithDays(req.Duration); err != nil || d < time.Minute {
+			writeError(w, http.StatusBadRequest, "threshold must be a duration of at least 1m")
+			return
+		}
+	}
+	if s.deps.SettingsStore == nil {
+		writeError(w, http.StatusNotImplemented, "settings store not available")
+		return
+	}
+	if err := s.deps.SettingsStore.SaveSetting("stuck_update_threshold", req.Duration); err != nil {
+		s.deps.Log.Error("failed to save stuck_update_threshold", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to save setting")
+		return
+	}
+	msg := "Stuck update threshold reset to default"
+	if req.Duration != "" {
+		msg = "Stuck update threshold set to " + req.Duration
+	}
+	s.logEvent(r, "settings", "", msg)
+	writeJSON(w, http.StatusOK, map[string]string{"message": msg})
+}
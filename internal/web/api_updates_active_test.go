@@ -0,0 +1,129 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/engine"
+	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+)
+
+// mockUpdateLocks returns canned active updates and a canned ForceUnlock
+// outcome.
+type mockUpdateLocks struct {
+	active   []engine.ActiveUpdate
+	res      engine.ForceUnlockResult
+	err      error
+	unlocked string
+}
+
+func (m *mockUpdateLocks) ActiveUpdates() []engine.ActiveUpdate { return m.active }
+
+func (m *mockUpdateLocks) ForceUnlock(_ context.Context, name string) (engine.ForceUnlockResult, error) {
+	m.unlocked = name
+	return m.res, m.err
+}
+
+func TestApiActiveUpdates(t *testing.T) {
+	now := time.Now()
+	locks := &mockUpdateLocks{active: []engine.ActiveUpdate{
+		{Name: "web", Phase: "pull", StartedAt: now.Add(-time.Minute), PhaseSince: now},
+		{Name: "db", Phase: "stop", StartedAt: now.Add(-time.Hour), PhaseSince: now.Add(-time.Hour), Stuck: true},
+	}}
+	srv := &Server{deps: Dependencies{UpdateLocks: locks}}
+
+	w := httptest.NewRecorder()
+	srv.apiActiveUpdates(w, httptest.NewRequest(http.MethodGet, "/api/updates/active", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	var got []engine.ActiveUpdate
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(got) != 2 || got[0].Name != "db" || !got[0].Stuck || got[1].Phase != "pull" {
+		t.Errorf("active = %+v, want db (stuck) before web", got)
+	}
+
+	// Without the dependency the list is empty rather than null.
+	w = httptest.NewRecorder()
+	(&Server{}).apiActiveUpdates(w, httptest.NewRequest(http.MethodGet, "/api/updates/active", nil))
+	if body := strings.TrimSpace(w.Body.String()); body != "[]" {
+		t.Errorf("body = %s, want []", body)
+	}
+}
+
+func TestApiForceUnlockUpdate(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"released", nil, http.StatusOK},
+		{"not updating", engine.ErrNotUpdating, http.StatusNotFound},
+		{"not stuck", fmt.Errorf("%w: in pull phase", engine.ErrUpdateNotStuck), http.StatusConflict},
+		{"other error", fmt.Errorf("boom"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			locks := &mockUpdateLocks{
+				res: engine.ForceUnlockResult{Name: "web", Phase: "pull", Warnings: []string{"container is exited"}},
+				err: tt.err,
+			}
+			srv := &Server{deps: Dependencies{
+				UpdateLocks: locks,
+				EventBus:    events.New(),
+				Log:         slog.New(slog.NewTextHandler(io.Discard, nil)),
+			}}
+			r := httptest.NewRequest(http.MethodPost, "/api/updates/web/force-unlock", nil)
+			r.SetPathValue("name", "web")
+			w := httptest.NewRecorder()
+			srv.apiForceUnlockUpdate(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if locks.unlocked != "web" {
+				t.Errorf("ForceUnlock called with %q", locks.unlocked)
+			}
+			if tt.err == nil && !strings.Contains(w.Body.String(), "container is exited") {
+				t.Errorf("body = %s, want the warnings", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestApiSetStuckUpdateThreshold(t *testing.T) {
+	tests := []struct {
+		body       string
+		wantStatus int
+		wantValue  string
+	}{
+		{`{"duration":"45m"}`, http.StatusOK, "45m"},
+		{`{"duration":"2d"}`, http.StatusOK, "2d"},
+		{`{"duration":""}`, http.StatusOK, ""},
+		{`{"duration":"30s"}`, http.StatusBadRequest, "unchanged"},
+		{`{"duration":"soon"}`, http.StatusBadRequest, "unchanged"},
+	}
+	for _, tt := range tests {
+		ss := newMockSettingsStore()
+		ss.data["stuck_update_threshold"] = "unchanged"
+		srv := newTestServer(ss)
+		w := httptest.NewRecorder()
+		srv.apiSetStuckUpdateThreshold(w, httptest.NewRequest(http.MethodPost, "/api/settings/stuck-update-threshold", strings.NewReader(tt.body)))
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: status = %d, want %d", tt.body, w.Code, tt.wantStatus)
+		}
+		if got := ss.data["stuck_update_threshold"]; got != tt.wantValue {
+			t.Errorf("%s: setting = %q, want %q", tt.body, got, tt.wantValue)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/docker"
+	"github.com/Will-Luck/Docker-Sentinel/internal/engine"
+)
+
+// validationResponse is the JSON form of a validation override.
+type validationResponse struct {
+	GracePeriod   string `json:"grace_period,omitempty"`
+	Strategy      string `json:"strategy,omitempty"`
+	ProbeURL      string `json:"probe_url,omitempty"`
+	HealthTimeout string `json:"health_timeout,omitempty"`
+	ProbeStatus   int    `json:"probe_status,omitempty"`
+	ProbeBody     string `json:"probe_body,omitempty"`
+}
+
+func toValidationResponse(o ValidationOverride) validationResponse {
+	r := validationResponse{Strategy: o.Strategy, ProbeURL: o.ProbeURL, ProbeStatus: o.ProbeStatus, ProbeBody: o.ProbeBody}
+	if o.GracePeriod > 0 {
+		r.GracePeriod = o.GracePeriod.String()
+	}
+	if o.HealthTimeout > 0 {
+		r.HealthTimeout = o.HealthTimeout.String()
+	}
+	return r
+}
+
+// apiGetValidation returns a container's validation override alongside the
+// label values it overrides. Empty fields fall back to the global settings.
+func (s *Server) apiGetValidation(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if !isValidContainerName(name) {
+		writeError(w, http.StatusBadRequest, "invalid container name")
+		return
+	}
+
+	resp := map[string]any{"name": name, "override": nil}
+	if s.deps.Validation != nil {
+		if o, ok := s.deps.Validation.GetValidationOverride(hostScopedKey(r, name)); ok {
+			resp["override"] = toValidationResponse(o)
+		}
+	}
+
+	if containers, err := s.deps.Docker.ListAllContainers(r.Context()); err == nil {
+		for _, c := range containers {
+			if containerName(c) != name {
+				continue
+			}
+			labels := validationResponse{
+				Strategy:    docker.ContainerValidation(c.Labels),
+				ProbeURL:    docker.ContainerProbe(c.Labels),
+				ProbeStatus: docker.ContainerProbeStatus(c.Labels),
+				ProbeBody:   docker.ContainerProbeBody(c.Labels),
+			}
+			if g := docker.ContainerGracePeriod(c.Labels); g > 0 {
+				labels.GracePeriod = g.String()
+			}
+			if t := docker.ContainerHealthTimeout(c.Labels); t > 0 {
+				labels.HealthTimeout = t.String()
+			}
+			resp["labels"] = labels
+			break
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// apiSetValidation stores a container's validation override.
+// Body: {"grace_period": "45s", "strategy": "probe", "probe_url": "http://:8080/healthz",
+// "probe_status": 200, "probe_body": "ok", "health_timeout": "5m"}
+// A probe URL without a strategy selects the "probe" strategy.
+func (s *Server) apiSetValidation(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if !isValidContainerName(name) {
+		writeError(w, http.StatusBadRequest, "invalid container name")
+		return
+	}
+	if s.deps.Validation == nil {
+		writeError(w, http.StatusNotImplemented, "validation store not available")
+		return
+	}
+
+	var body validationResponse
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+
+	var o ValidationOverride
+	if body.GracePeriod != "" {
+		d, err := docker.ParseDurationWithDays(body.GracePeriod)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid grace_period duration")
+			return
+		}
+		if d <= 0 || d > time.Hour {
+			writeError(w, http.StatusBadRequest, "grace_period must be between 1s and 1h")
+			return
+		}
+		o.GracePeriod = d
+	}
+	if body.HealthTimeout != "" {
+		d, err := docker.ParseDurationWithDays(body.HealthTimeout)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid health_timeout duration")
+			return
+		}
+		if d <= 0 || d > time.Hour {
+			writeError(w, http.StatusBadRequest, "health_timeout must be between 1s and 1h")
+			return
+		}
+		o.HealthTimeout = d
+	}
+	if !engine.ValidStrategy(body.Strategy) {
+		writeError(w, http.StatusBadRequest, "strategy must be state, healthcheck or probe")
+		return
+	}
+	o.Strategy = body.Strategy
+	if body.ProbeURL != "" {
+		if err := engine.ValidateProbeURL(body.ProbeURL); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		o.ProbeURL = body.ProbeURL
+		if o.Strategy == engine.ValidateAuto {
+			o.Strategy = engine.ValidateProbe
+		}
+	}
+	if body.ProbeStatus != 0 && (body.ProbeStatus < 100 || body.ProbeStatus > 599) {
+		writeError(w, http.StatusBadRequest, "probe_status must be an HTTP status code between 100 and 599")
+		return
+	}
+	o.ProbeStatus = body.ProbeStatus
+	o.ProbeBody = body.ProbeBody
+	if o == (ValidationOverride{}) {
+		writeError(w, http.StatusBadRequest, "no override fields set; use DELETE to clear")
+		return
+	}
+
+	if err := s.deps.Validation.SetValidationOverride(hostScopedKey(r, name), o); err != nil {
+		s.deps.Log.Error("failed to save validation override", "name", name, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to save validation override")
+		return
+	}
+
+	s.logEvent(r, "validation_set", name, "Validation override updated")
+	writeJSON(w, http.StatusOK, map[string]any{
+		"status":   "ok",
+		"name":     name,
+		"override": toValidationResponse(o),
+	})
+}
+
+// apiDeleteValidation clears a container's validation override.
+func (s *Server) apiDeleteValidation(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if !isValidContainerName(name) {
+		writeError(w, http.StatusBadRequest, "invalid container name")
+		return
+	}
+	if s.deps.Validation == nil {
+		writeError(w, http.StatusNotImplemented, "validation store not available")
+		return
+	}
+	if err := s.deps.Validation.DeleteValidationOverride(hostScopedKey(r, name)); err != nil {
+		s.deps.Log.Error("failed to delete validation override", "name", name, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to delete validation override")
+		return
+	}
+	s.logEvent(r, "validation_delete", name, "Validation override cleared")
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok", "name": name})
+}
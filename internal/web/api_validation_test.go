@@ -0,0 +1,159 @@
+package web
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type mockValidationStore struct {
+	overrides map[string]ValidationOverride
+}
+
+func (m *mockValidationStore) GetValidationOverride(key string) (ValidationOverride, bool) {
+	o, ok := m.overrides[key]
+	return o, ok
+}
+
+func (m *mockValidationStore) SetValidationOverride(key string, o ValidationOverride) error {
+	m.overrides[key] = o
+	return nil
+}
+
+func (m *mockValidationStore) DeleteValidationOverride(key string) error {
+	delete(m.overrides, key)
+	return nil
+}
+
+func newValidationTestServer(vs *mockValidationStore) *Server {
+	return &Server{
+		deps: Dependencies{
+			Docker: &mockContainerLister{containers: []ContainerSummary{
+				{Names: []string{"/nginx"}, Labels: map[string]string{"sentinel.validate": "healthcheck"}},
+			}},
+			Validation: vs,
+			Log:        slog.Default(),
+		},
+	}
+}
+
+func TestApiSetValidation(t *testing.T) {
+	vs := &mockValidationStore{overrides: map[string]ValidationOverride{}}
+	srv := newValidationTestServer(vs)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPut, "/api/containers/nginx/validation?host=h1",
+		strings.NewReader(`{"grace_period":"45s","strategy":"probe","probe_url":"http://:8080/healthz","health_timeout":"5m"}`))
+	r.SetPathValue("name", "nginx")
+	srv.apiSetValidation(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	o, ok := vs.overrides["h1::nginx"]
+	if !ok {
+		t.Fatal("expected override stored under h1::nginx")
+	}
+	if o.GracePeriod != 45*time.Second || o.Strategy != "probe" || o.ProbeURL != "http://:8080/healthz" || o.HealthTimeout != 5*time.Minute {
+		t.Errorf("override = %+v", o)
+	}
+}
+
+func TestApiSetValidation_ProbeExpectations(t *testing.T) {
+	vs := &mockValidationStore{overrides: map[string]ValidationOverride{}}
+	srv := newValidationTestServer(vs)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPut, "/api/containers/nginx/validation",
+		strings.NewReader(`{"probe_url":"http://:8080/","probe_status":200,"probe_body":"Welcome"}`))
+	r.SetPathValue("name", "nginx")
+	srv.apiSetValidation(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	o := vs.overrides["nginx"]
+	// A probe URL alone selects the probe strategy.
+	if o.Strategy != "probe" || o.ProbeStatus != 200 || o.ProbeBody != "Welcome" {
+		t.Errorf("override = %+v, want probe strategy expecting 200 and Welcome", o)
+	}
+}
+
+func TestApiSetValidation_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"bad strategy", `{"strategy":"ping"}`},
+		{"bad grace", `{"grace_period":"soon"}`},
+		{"grace too long", `{"grace_period":"2h"}`},
+		{"bad probe url", `{"probe_url":"ftp://x/"}`},
+		{"bad health timeout", `{"health_timeout":"forever"}`},
+		{"health timeout too long", `{"health_timeout":"3h"}`},
+		{"bad probe status", `{"probe_url":"http://:80/","probe_status":999}`},
+		{"empty", `{}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vs := &mockValidationStore{overrides: map[string]ValidationOverride{}}
+			srv := newValidationTestServer(vs)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPut, "/api/containers/nginx/validation", strings.NewReader(tt.body))
+			r.SetPathValue("name", "nginx")
+			srv.apiSetValidation(w, r)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400; body: %s", w.Code, w.Body.String())
+			}
+			if len(vs.overrides) != 0 {
+				t.Errorf("expected nothing stored, got %v", vs.overrides)
+			}
+		})
+	}
+}
+
+func TestApiGetAndDeleteValidation(t *testing.T) {
+	vs := &mockValidationStore{overrides: map[string]ValidationOverride{
+		"nginx": {GracePeriod: 30 * time.Second},
+	}}
+	srv := newValidationTestServer(vs)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api/containers/nginx/validation", nil)
+	r.SetPathValue("name", "nginx")
+	srv.apiGetValidation(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	var resp struct {
+		Override *validationResponse `json:"override"`
+		Labels   validationResponse  `json:"labels"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Override == nil || resp.Override.GracePeriod != "30s" {
+		t.Errorf("override = %+v, want grace_period 30s", resp.Override)
+	}
+	if resp.Labels.Strategy != "healthcheck" {
+		t.Errorf("labels.strategy = %q, want healthcheck", resp.Labels.Strategy)
+	}
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodDelete, "/api/containers/nginx/validation", nil)
+	r.SetPathValue("name", "nginx")
+	srv.apiDeleteValidation(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("delete status = %d, want 200", w.Code)
+	}
+	if _, ok := vs.overrides["nginx"]; ok {
+		t.Error("override still present after delete")
+	}
+}
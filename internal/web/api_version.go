@@ -0,0 +1,135 @@
+package web
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/auth"
+	"github.com/Will-Luck/Docker-Sentinel/internal/registry"
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+)
+
+// newBootID returns a random identifier for this server process.
+func newBootID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// versionResponse is the version handshake. The frontend compares boot_id
+// across SSE reconnects to detect a backend restart, and uses self_update to
+// report the version change after a self-update. Clients gate features on
+// api_version; cluster admins use agents to spot agents that are too old.
+type versionResponse struct {
+	Version         string          `json:"version"`
+	Channel         string          `json:"channel"`              // "stable", "prerelease" or "dev"
+	Commit          string          `json:"commit,omitempty"`     // omitted when "unknown"
+	BuildDate       string          `json:"build_date,omitempty"` // omitted when "unknown"
+	GoVersion       string          `json:"go_version"`
+	APIVersion      string          `json:"api_version"` // e.g. "v1", the /api/v1 schema
+	MinAgentVersion string          `json:"min_agent_version,omitempty"`
+	Agents          []agentCompat   `json:"agents,omitempty"` // only for users who can view the cluster
+	BootID          string          `json:"boot_id"`
+	StartedAt       time.Time       `json:"started_at"`
+	SelfUpdate      *SelfUpdateInfo `json:"self_update,omitempty"`
+}
+
+// agentCompat is one row of the agent compatibility matrix.
+type agentCompat struct {
+	HostID     string `json:"host_id"`
+	HostName   string `json:"host_name"`
+	Version    string `json:"version,omitempty"`
+	Connected  bool   `json:"connected"`
+	Compatible string `json:"compatible"` // "yes", "no", or "unknown" for dev builds and unreported versions
+}
+
+// releaseVersion strips the " (commit)" suffix of a formatted version.
+func releaseVersion(v string) string {
+	v, _, _ = strings.Cut(strings.TrimSpace(v), " (")
+	return v
+}
+
+// updateChannel names the release channel of a version: "dev" for local
+// builds, "prerelease" for versions with a suffix such as -rc1.
+func updateChannel(version string) string {
+	sv, ok := registry.ParseSemVer(releaseVersion(version))
+	switch {
+	case !ok:
+		return "dev"
+	case sv.Pre != "":
+		return "prerelease"
+	default:
+		return "stable"
+	}
+}
+
+// agentCompatible reports whether an agent version meets minimum: "yes",
+// "no", or "unknown" when either cannot be parsed.
+func agentCompatible(version, minimum string) string {
+	av, ok := registry.ParseSemVer(releaseVersion(version))
+	if !ok {
+		return "unknown"
+	}
+	mv, ok := registry.ParseSemVer(minimum)
+	if !ok {
+		return "unknown"
+	}
+	if av.LessThan(mv) {
+		return "no"
+	}
+	return "yes"
+}
+
+// apiVersion returns build metadata and the restart handshake.
+func (s *Server) apiVersion(w http.ResponseWriter, r *http.Request) {
+	resp := versionResponse{
+		Version:         s.deps.Version,
+		Channel:         updateChannel(s.deps.Version),
+		GoVersion:       runtime.Version(),
+		APIVersion:      strings.TrimPrefix(apiVersionPrefix, "/api/"),
+		MinAgentVersion: s.minAgentVersion(),
+		BootID:          s.bootID,
+		StartedAt:       s.startTime,
+		SelfUpdate:      s.deps.SelfUpdated,
+	}
+	if s.deps.Commit != "unknown" {
+		resp.Commit = s.deps.Commit
+	}
+	if s.deps.BuildDate != "unknown" {
+		resp.BuildDate = s.deps.BuildDate
+	}
+	if s.deps.Cluster != nil && s.deps.Cluster.Enabled() && canViewCluster(r) {
+		for _, h := range s.deps.Cluster.AllHosts() {
+			resp.Agents = append(resp.Agents, agentCompat{
+				HostID:     h.ID,
+				HostName:   h.Name,
+				Version:    h.AgentVersion,
+				Connected:  h.Connected,
+				Compatible: agentCompatible(h.AgentVersion, resp.MinAgentVersion),
+			})
+		}
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// minAgentVersion returns the configured minimum agent version, or the
+// built-in default.
+func (s *Server) minAgentVersion() string {
+	if s.deps.SettingsStore != nil {
+		if v, err := s.deps.SettingsStore.LoadSetting(store.SettingClusterMinAgentVersion); err == nil && v != "" {
+			return v
+		}
+	}
+	return s.deps.MinAgentVersion
+}
+
+// canViewCluster reports whether the request may see cluster hosts.
+func canViewCluster(r *http.Request) bool {
+	rc := auth.GetRequestContext(r.Context())
+	return rc != nil && rc.HasPermission(auth.PermClusterView)
+}
@@ -0,0 +1,85 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestApiVersion(t *testing.T) {
+	srv := &Server{
+		deps: Dependencies{
+			Version:   "2.10.2 (abc1234)",
+			Commit:    "abc1234",
+			BuildDate: "unknown",
+			SelfUpdated: &SelfUpdateInfo{
+				FromVersion: "2.9.1",
+				ToVersion:   "2.10.2",
+				CompletedAt: time.Now(),
+			},
+		},
+		startTime: time.Now(),
+		bootID:    newBootID(),
+	}
+
+	w := httptest.NewRecorder()
+	srv.apiVersion(w, httptest.NewRequest(http.MethodGet, "/api/version", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	var resp versionResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.BootID == "" || resp.BootID != srv.bootID {
+		t.Errorf("boot_id = %q, want %q", resp.BootID, srv.bootID)
+	}
+	if resp.Commit != "abc1234" || resp.BuildDate != "" {
+		t.Errorf("commit = %q, build_date = %q", resp.Commit, resp.BuildDate)
+	}
+	if resp.SelfUpdate == nil || resp.SelfUpdate.FromVersion != "2.9.1" || resp.SelfUpdate.ToVersion != "2.10.2" {
+		t.Errorf("self_update = %+v", resp.SelfUpdate)
+	}
+	if resp.Channel != "stable" || resp.APIVersion != "v1" {
+		t.Errorf("channel = %q, api_version = %q", resp.Channel, resp.APIVersion)
+	}
+	if resp.Agents != nil {
+		t.Errorf("agents = %+v, want none without a cluster", resp.Agents)
+	}
+}
+
+func TestUpdateChannel(t *testing.T) {
+	tests := map[string]string{
+		"2.10.2 (abc1234)": "stable",
+		"v2.11.0-rc1":      "prerelease",
+		"dev":              "dev",
+		"dev (abc1234)":    "dev",
+	}
+	for version, want := range tests {
+		if got := updateChannel(version); got != want {
+			t.Errorf("updateChannel(%q) = %q, want %q", version, got, want)
+		}
+	}
+}
+
+func TestAgentCompatible(t *testing.T) {
+	tests := []struct {
+		version, minimum, want string
+	}{
+		{"2.5.0", "2.5.0", "yes"},
+		{"v2.10.1 (abc1234)", "2.5.0", "yes"},
+		{"2.4.9", "2.5.0", "no"},
+		{"2.5.0-rc1", "2.5.0", "no"},
+		{"dev", "2.5.0", "unknown"},
+		{"", "2.5.0", "unknown"},
+		{"2.5.0", "", "unknown"},
+	}
+	for _, tt := range tests {
+		if got := agentCompatible(tt.version, tt.minimum); got != tt.want {
+			t.Errorf("agentCompatible(%q, %q) = %q, want %q", tt.version, tt.minimum, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// apiVersionPrefix is the root of the versioned API. Every /api/ route is
+// registered under it; the unversioned path stays as an alias so existing
+// automations and the dashboard keep working.
+const apiVersionPrefix = "/api/v1"
+
+// legacyAPIDeprecated is when the unversioned /api/ paths were deprecated
+// in favour of /api/v1. No sunset date is set for them yet.
+var legacyAPIDeprecated = time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+
+// apiDeprecation describes a deprecated route. It is sent as the
+// Deprecation (RFC 9745), Sunset (RFC 8594) and Link rel="successor-version"
+// headers so clients can detect it without parsing the body.
+type apiDeprecation struct {
+	Since     time.Time
+	Sunset    time.Time // zero = no removal date yet
+	Successor string    // path of the replacement route, if any
+}
+
+// Deprecate marks the route registered with pattern (its /api/v1 form, e.g.
+// "GET /api/v1/queue") as deprecated. Call it before the route is
+// registered; both the versioned path and its unversioned alias then carry
+// the deprecation headers and the OpenAPI document flags the operation.
+func (m *routeMux) Deprecate(pattern string, dep apiDeprecation) {
+	if m.deprecated == nil {
+		m.deprecated = make(map[string]apiDeprecation)
+	}
+	m.deprecated[pattern] = dep
+}
+
+// register records spec and serves h for it. An /api/ route is served at
+// its /api/v1 path, with the unversioned path as a deprecated alias.
+func (m *routeMux) register(spec routeSpec, h http.Handler) {
+	rest, isAPI := strings.CutPrefix(spec.path, "/api/")
+	if !isAPI || strings.HasPrefix(spec.path, apiVersionPrefix+"/") {
+		m.routes = append(m.routes, spec)
+		m.ServeMux.Handle(routePattern(spec.method, spec.path), h)
+		return
+	}
+
+	legacyPath := spec.path
+	spec.path = apiVersionPrefix + "/" + rest
+	pattern := routePattern(spec.method, spec.path)
+
+	versioned, legacy := h, apiDeprecation{Since: legacyAPIDeprecated}
+	if dep, ok := m.deprecated[pattern]; ok {
+		spec.deprecated = true
+		versioned, legacy = withDeprecation(h, dep), dep
+	}
+	m.routes = append(m.routes, spec)
+	m.ServeMux.Handle(pattern, versioned)
+	m.ServeMux.Handle(routePattern(spec.method, legacyPath), withLegacyAPI(h, legacy))
+}
+
+func routePattern(method, path string) string {
+	if method == "" {
+		return path
+	}
+	return method + " " + path
+}
+
+// withDeprecation sets the deprecation headers for dep on every response.
+func withDeprecation(next http.Handler, dep apiDeprecation) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		setDeprecationHeaders(w, dep, dep.Successor)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// withLegacyAPI serves an unversioned /api/ path, pointing clients at the
+// same request under /api/v1. A successor already set by the versioned
+// route's own deprecation takes precedence.
+func withLegacyAPI(next http.Handler, dep apiDeprecation) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		successor := dep.Successor
+		if successor == "" {
+			successor = versionedAPIPath(r.URL.Path)
+		}
+		setDeprecationHeaders(w, dep, successor)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func setDeprecationHeaders(w http.ResponseWriter, dep apiDeprecation, successor string) {
+	h := w.Header()
+	h.Set("Deprecation", "@"+strconv.FormatInt(dep.Since.Unix(), 10))
+	if !dep.Sunset.IsZero() {
+		h.Set("Sunset", dep.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if successor != "" {
+		h.Add("Link", "<"+successor+">; rel=\"successor-version\"")
+	}
+}
+
+// versionedAPIPath returns the /api/v1 form of an unversioned /api/ path.
+func versionedAPIPath(path string) string {
+	if rest, ok := strings.CutPrefix(path, "/api/"); ok && !strings.HasPrefix(path, apiVersionPrefix+"/") {
+		return apiVersionPrefix + "/" + rest
+	}
+	return path
+}
+
+// unversionedAPIPath strips the version segment, so path checks written
+// against "/api/..." hold for both forms of a route.
+func unversionedAPIPath(path string) string {
+	if rest, ok := strings.CutPrefix(path, apiVersionPrefix+"/"); ok {
+		return "/api/" + rest
+	}
+	return path
+}
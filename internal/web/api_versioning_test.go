@@ -0,0 +1,85 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRouteMuxVersioning(t *testing.T) {
+	m := newRouteMux()
+	ok := func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusNoContent) }
+	sunset := time.Date(2027, 4, 1, 0, 0, 0, 0, time.UTC)
+	m.Deprecate("GET /api/v1/old", apiDeprecation{Since: legacyAPIDeprecated, Sunset: sunset, Successor: "/api/v1/new"})
+	m.HandleFunc("GET /api/containers/{name}", ok)
+	m.HandleFunc("GET /api/old", ok)
+	m.HandleFunc("GET /healthz", ok)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		m.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("GET %s: status = %d", path, w.Code)
+		}
+		return w
+	}
+	wantDeprecation := "@1792195200" // 2026-10-17T00:00:00Z
+
+	// The versioned path is canonical and carries no deprecation.
+	if w := get("/api/v1/containers/web"); w.Header().Get("Deprecation") != "" {
+		t.Errorf("v1 Deprecation = %q, want none", w.Header().Get("Deprecation"))
+	}
+
+	// The unversioned alias points at its /api/v1 successor.
+	w := get("/api/containers/web")
+	if got := w.Header().Get("Deprecation"); got != wantDeprecation {
+		t.Errorf("legacy Deprecation = %q, want %q", got, wantDeprecation)
+	}
+	if got := w.Header().Get("Link"); got != `</api/v1/containers/web>; rel="successor-version"` {
+		t.Errorf("legacy Link = %q", got)
+	}
+	if w.Header().Get("Sunset") != "" {
+		t.Error("legacy alias has a Sunset without a removal date")
+	}
+
+	// A deprecated route announces its sunset and successor on both paths.
+	for _, path := range []string{"/api/v1/old", "/api/old"} {
+		w := get(path)
+		if got := w.Header().Get("Sunset"); got != "Thu, 01 Apr 2027 00:00:00 GMT" {
+			t.Errorf("%s Sunset = %q", path, got)
+		}
+		if got := w.Header().Values("Link"); len(got) != 1 || got[0] != `</api/v1/new>; rel="successor-version"` {
+			t.Errorf("%s Link = %q", path, got)
+		}
+	}
+
+	// Non-API routes are not versioned.
+	if w := get("/healthz"); w.Header().Get("Deprecation") != "" {
+		t.Error("non-API route marked deprecated")
+	}
+
+	var paths []string
+	for _, r := range m.routes {
+		paths = append(paths, r.path)
+		if r.path == "/api/v1/old" && !r.deprecated {
+			t.Error("deprecated route not flagged for the OpenAPI document")
+		}
+	}
+	if len(paths) != 3 || paths[0] != "/api/v1/containers/{name}" || paths[2] != "/healthz" {
+		t.Errorf("recorded paths = %v", paths)
+	}
+}
+
+func TestUnversionedAPIPath(t *testing.T) {
+	for in, want := range map[string]string{
+		"/api/v1/auth/me": "/api/auth/me",
+		"/api/auth/me":    "/api/auth/me",
+		"/api/v1x/foo":    "/api/v1x/foo",
+		"/healthz":        "/healthz",
+	} {
+		if got := unversionedAPIPath(in); got != want {
+			t.Errorf("unversionedAPIPath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package web
+
+import "net/http"
+
+// versionSet returns the resolved versions keyed like policy overrides, or
+// an empty map when the store is not wired.
+func (s *Server) versionSet() map[string]VersionInfo {
+	if s.deps.Versions == nil {
+		return map[string]VersionInfo{}
+	}
+	return s.deps.Versions.AllVersionInfo()
+}
+
+// apiListVersions returns the current and candidate version of every
+// monitored container in the user's scope, as resolved by the most recent
+// scan.
+func (s *Server) apiListVersions(w http.ResponseWriter, r *http.Request) {
+	versions := s.versionSet()
+	if requestScope(r) != nil {
+		allowed := s.scopeFilter(r)
+		for key := range versions {
+			if !allowed(key) {
+				delete(versions, key)
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, versions)
+}
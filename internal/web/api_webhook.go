@@ -6,8 +6,13 @@ import (
 	"crypto/subtle"
 	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+	"github.com/Will-Luck/Docker-Sentinel/internal/registry"
 
 	"github.com/Will-Luck/Docker-Sentinel/internal/store"
 	"github.com/Will-Luck/Docker-Sentinel/internal/webhook"
@@ -98,6 +103,136 @@ func (s *Server) apiWebhook(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// apiRegistryWebhook handles push notifications from Docker Hub, Harbor and
+// GHCR. Unlike apiWebhook it does not trigger a full scan: only containers
+// running the pushed repository are checked, in the background, and queued
+// when an update is found. Registries cannot send custom headers, so the
+// token in the URL authenticates the request.
+func (s *Server) apiRegistryWebhook(w http.ResponseWriter, r *http.Request) {
+	if s.deps.SettingsStore == nil {
+		writeError(w, http.StatusServiceUnavailable, "settings store not available")
+		return
+	}
+
+	enabled, _ := s.deps.SettingsStore.LoadSetting(store.SettingWebhookEnabled)
+	if enabled != "true" {
+		writeError(w, http.StatusForbidden, "webhooks are disabled")
+		return
+	}
+
+	token := r.PathValue("token")
+	storedToken, _ := s.deps.SettingsStore.LoadSetting(store.SettingRegistryWebhookToken)
+	if storedToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(storedToken)) != 1 {
+		writeError(w, http.StatusUnauthorized, "invalid webhook token")
+		return
+	}
+
+	if s.deps.RegistryChecker == nil {
+		writeError(w, http.StatusNotImplemented, "registry checker not available")
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "failed to read request body")
+		return
+	}
+	payload, err := webhook.Parse(body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if payload.Image == "" {
+		writeError(w, http.StatusBadRequest, "payload does not name an image")
+		return
+	}
+
+	all, err := s.bulkCheckTargets(r.Context(), bulkCheckRequest{})
+	if err != nil {
+		s.deps.Log.Error("failed to list containers for registry webhook", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to list containers")
+		return
+	}
+	var targets []bulkCheckTarget
+	names := []string{}
+	for _, t := range all {
+		if payload.Matches(t.Image) {
+			targets = append(targets, t)
+			names = append(names, t.Name)
+		}
+	}
+
+	ref := payload.Image
+	if payload.Tag != "" {
+		ref += ":" + payload.Tag
+	}
+	s.deps.Log.Info("registry webhook received",
+		"source", payload.Source,
+		"image", payload.Image,
+		"tag", payload.Tag,
+		"matched", len(targets),
+	)
+	if len(targets) == 0 {
+		s.logEvent(nil, "webhook", payload.Image, "Registry push from "+payload.Source+": "+ref+" (no matching containers)")
+		writeJSON(w, http.StatusOK, map[string]any{
+			"status":     "no_match",
+			"image":      payload.Image,
+			"tag":        payload.Tag,
+			"source":     payload.Source,
+			"containers": names,
+		})
+		return
+	}
+	s.logEvent(nil, "webhook", payload.Image, fmt.Sprintf("Registry push from %s: %s, checking %d containers", payload.Source, ref, len(targets)))
+
+	// Registries time out slow receivers, so the checks run after replying.
+	go s.checkPushedTargets(context.Background(), targets)
+
+	writeJSON(w, http.StatusAccepted, map[string]any{
+		"status":     "accepted",
+		"image":      payload.Image,
+		"tag":        payload.Tag,
+		"source":     payload.Source,
+		"containers": names,
+	})
+}
+
+// checkPushedTargets checks containers named by a registry push, checking
+// each image once, and queues those with an update.
+func (s *Server) checkPushedTargets(ctx context.Context, targets []bulkCheckTarget) {
+	type checked struct {
+		available       bool
+		newer           []string
+		resolvedCurrent string
+		resolvedTarget  string
+		err             error
+	}
+	cache := make(map[string]checked)
+	updates := 0
+	for _, t := range targets {
+		c, ok := cache[t.Image]
+		if !ok {
+			c.available, c.newer, c.resolvedCurrent, c.resolvedTarget, c.err = s.deps.RegistryChecker.CheckForUpdate(ctx, t.Image)
+			cache[t.Image] = c
+		}
+		item := s.applyBulkCheck(t, registry.RegistryHost(t.Image), c.available, c.newer, c.resolvedCurrent, c.resolvedTarget, c.err)
+		switch item.Status {
+		case "failed":
+			s.deps.Log.Warn("registry webhook check failed", "name", t.Name, "host", t.HostID, "error", item.Error)
+		case "update_available":
+			updates++
+			s.deps.Log.Info("update found via registry webhook", "name", t.Name, "host", t.HostID)
+		}
+	}
+	if updates > 0 {
+		s.deps.EventBus.Publish(events.SSEEvent{
+			Type:      events.EventQueueChange,
+			Message:   fmt.Sprintf("%d updates found by registry webhook", updates),
+			Timestamp: time.Now(),
+		})
+	}
+}
+
 // apiSetWebhookEnabled toggles the webhook endpoint on or off.
 func (s *Server) apiSetWebhookEnabled(w http.ResponseWriter, r *http.Request) {
 	var body struct {
@@ -124,18 +259,21 @@ func (s *Server) apiSetWebhookEnabled(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// If enabling and no secret exists yet, auto-generate one.
+	// If enabling and no secret or registry token exists yet, auto-generate them.
 	if body.Enabled {
-		existing, _ := s.deps.SettingsStore.LoadSetting(store.SettingWebhookSecret)
-		if existing == "" {
+		for _, key := range []string{store.SettingWebhookSecret, store.SettingRegistryWebhookToken} {
+			existing, _ := s.deps.SettingsStore.LoadSetting(key)
+			if existing != "" {
+				continue
+			}
 			secret, err := generateWebhookSecret()
 			if err != nil {
-				s.deps.Log.Error("failed to generate webhook secret", "error", err)
+				s.deps.Log.Error("failed to generate webhook secret", "key", key, "error", err)
 				writeError(w, http.StatusInternalServerError, "failed to generate webhook secret")
 				return
 			}
-			if err := s.deps.SettingsStore.SaveSetting(store.SettingWebhookSecret, secret); err != nil {
-				s.deps.Log.Error("failed to save webhook secret", "error", err)
+			if err := s.deps.SettingsStore.SaveSetting(key, secret); err != nil {
+				s.deps.Log.Error("failed to save webhook secret", "key", key, "error", err)
 				writeError(w, http.StatusInternalServerError, "failed to save webhook secret")
 				return
 			}
@@ -178,6 +316,34 @@ func (s *Server) apiGenerateWebhookSecret(w http.ResponseWriter, r *http.Request
 	})
 }
 
+// apiGenerateRegistryWebhookToken creates a new registry webhook token,
+// invalidating URLs configured with the old one.
+func (s *Server) apiGenerateRegistryWebhookToken(w http.ResponseWriter, r *http.Request) {
+	if s.deps.SettingsStore == nil {
+		writeError(w, http.StatusNotImplemented, "settings store not available")
+		return
+	}
+
+	token, err := generateWebhookSecret()
+	if err != nil {
+		s.deps.Log.Error("failed to generate registry webhook token", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to generate token")
+		return
+	}
+
+	if err := s.deps.SettingsStore.SaveSetting(store.SettingRegistryWebhookToken, token); err != nil {
+		s.deps.Log.Error("failed to save registry webhook token", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to save token")
+		return
+	}
+
+	s.logEvent(r, "settings", "", "Registry webhook token regenerated")
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"token": token,
+	})
+}
+
 // apiGetWebhookInfo returns the current webhook configuration for the settings page.
 func (s *Server) apiGetWebhookInfo(w http.ResponseWriter, _ *http.Request) {
 	if s.deps.SettingsStore == nil {
@@ -187,19 +353,24 @@ func (s *Server) apiGetWebhookInfo(w http.ResponseWriter, _ *http.Request) {
 
 	enabled, _ := s.deps.SettingsStore.LoadSetting(store.SettingWebhookEnabled)
 	secret, _ := s.deps.SettingsStore.LoadSetting(store.SettingWebhookSecret)
+	token, _ := s.deps.SettingsStore.LoadSetting(store.SettingRegistryWebhookToken)
 
-	// Mask secret for display — full secret is only shown once at generation time.
-	masked := secret
-	if len(secret) > 12 {
-		masked = secret[:8] + "****" + secret[len(secret)-4:]
-	}
-
+	// Mask secrets for display — full values are only shown once at generation time.
 	writeJSON(w, http.StatusOK, map[string]string{
-		"enabled": enabled,
-		"secret":  masked,
+		"enabled":        enabled,
+		"secret":         maskWebhookSecret(secret),
+		"registry_token": maskWebhookSecret(token),
 	})
 }
 
+// maskWebhookSecret keeps the first 8 and last 4 characters of a secret.
+func maskWebhookSecret(secret string) string {
+	if len(secret) > 12 {
+		return secret[:8] + "****" + secret[len(secret)-4:]
+	}
+	return secret
+}
+
 // generateWebhookSecret produces a cryptographically random 32-byte hex string.
 func generateWebhookSecret() (string, error) {
 	b := make([]byte, 32)
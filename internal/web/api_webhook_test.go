@@ -0,0 +1,100 @@
+package web
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+)
+
+func TestApiRegistryWebhook(t *testing.T) {
+	ss := newMockSettingsStore()
+	ss.data[store.SettingWebhookEnabled] = "true"
+	ss.data[store.SettingRegistryWebhookToken] = "tok123"
+	checker := &mockBulkChecker{
+		calls:   map[string]int{},
+		updates: map[string][]string{"nginx:1.25": {"1.27"}},
+	}
+	queue := &recordingQueue{}
+	srv := &Server{deps: Dependencies{
+		SettingsStore: ss,
+		Docker: &mockContainerLister{containers: []ContainerSummary{
+			{ID: "1", Names: []string{"/web-a"}, Image: "nginx:1.25"},
+			{ID: "2", Names: []string{"/web-b"}, Image: "docker.io/library/nginx:1.25"},
+			{ID: "3", Names: []string{"/app"}, Image: "ghcr.io/org/app:v1"},
+		}},
+		RegistryChecker: checker,
+		Queue:           queue,
+		EventBus:        events.New(),
+		Log:             slog.Default(),
+	}}
+
+	post := func(token, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/webhooks/registry/"+token, strings.NewReader(body))
+		r.SetPathValue("token", token)
+		srv.apiRegistryWebhook(w, r)
+		return w
+	}
+	hubPush := `{"push_data": {"tag": "1.27"}, "repository": {"repo_name": "library/nginx"}}`
+
+	if w := post("wrong", hubPush); w.Code != http.StatusUnauthorized {
+		t.Errorf("wrong token: status = %d, want 401", w.Code)
+	}
+	if w := post("tok123", `{"foo": 1}`); w.Code != http.StatusBadRequest {
+		t.Errorf("no image: status = %d, want 400", w.Code)
+	}
+
+	w := post("tok123", `{"image": "quay.io/x/other:1"}`)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "no_match") {
+		t.Errorf("no match: status = %d, body %s", w.Code, w.Body.String())
+	}
+
+	w = post("tok123", hubPush)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202; body: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Source     string   `json:"source"`
+		Containers []string `json:"containers"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Source != "dockerhub" || len(resp.Containers) != 2 {
+		t.Errorf("response = %+v, want dockerhub with web-a and web-b", resp)
+	}
+
+	// Checks run in the background.
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		queue.mu.Lock()
+		n := len(queue.added)
+		queue.mu.Unlock()
+		if n == 1 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+	if len(queue.added) != 1 || queue.added[0].ContainerName != "web-a" {
+		t.Errorf("queued = %+v, want only web-a", queue.added)
+	}
+	checker.mu.Lock()
+	defer checker.mu.Unlock()
+	if checker.calls["ghcr.io/org/app:v1"] != 0 {
+		t.Error("unrelated container was checked")
+	}
+
+	ss.data[store.SettingWebhookEnabled] = "false"
+	if w := post("tok123", hubPush); w.Code != http.StatusForbidden {
+		t.Errorf("disabled: status = %d, want 403", w.Code)
+	}
+}
@@ -3,7 +3,11 @@ package web
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/engine"
 )
 
 // ClusterController is a thread-safe proxy for a ClusterProvider.
@@ -13,6 +17,13 @@ import (
 type ClusterController struct {
 	mu       sync.RWMutex
 	provider ClusterProvider
+	remote   sync.Map // "hostID::name" → *remoteUpdate, updates awaiting their agent
+}
+
+// remoteUpdate records when a remote container update was dispatched.
+type remoteUpdate struct {
+	hostID, name string
+	startedAt    time.Time
 }
 
 // NewClusterController returns a ClusterController with no active provider.
@@ -70,13 +81,46 @@ func (c *ClusterController) ConnectedHosts() []string {
 
 // GenerateEnrollToken creates a new one-time enrollment token.
 // Returns an error when clustering is disabled.
-func (c *ClusterController) GenerateEnrollToken() (string, string, error) {
+func (c *ClusterController) GenerateEnrollToken(ttl time.Duration, createdBy string) (string, string, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	if c.provider == nil {
 		return "", "", fmt.Errorf("cluster not enabled")
 	}
-	return c.provider.GenerateEnrollToken()
+	return c.provider.GenerateEnrollToken(ttl, createdBy)
+}
+
+// ListEnrollTokens returns all enrollment tokens, newest first.
+// Returns an error when clustering is disabled.
+func (c *ClusterController) ListEnrollTokens() ([]EnrollToken, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.provider == nil {
+		return nil, fmt.Errorf("cluster not enabled")
+	}
+	return c.provider.ListEnrollTokens()
+}
+
+// RevokeEnrollToken deletes an unused enrollment token.
+// Returns an error when clustering is disabled.
+func (c *ClusterController) RevokeEnrollToken(id string) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.provider == nil {
+		return fmt.Errorf("cluster not enabled")
+	}
+	return c.provider.RevokeEnrollToken(id)
+}
+
+// HostEvents returns a host's timeline events, newest first.
+// Returns an error when clustering is disabled.
+func (c *ClusterController) HostEvents(id string, limit int) ([]ClusterHostEvent, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.provider == nil {
+		return nil, fmt.Errorf("cluster not enabled")
+	}
+	return c.provider.HostEvents(id, limit)
 }
 
 // RemoveHost removes a host from the cluster.
@@ -125,15 +169,37 @@ func (c *ClusterController) AllHostContainers() []RemoteContainer {
 
 // UpdateRemoteContainer dispatches a container update to a remote agent.
 // Returns an error when clustering is disabled.
-func (c *ClusterController) UpdateRemoteContainer(ctx context.Context, hostID, containerName, targetImage, targetDigest string) error {
+func (c *ClusterController) UpdateRemoteContainer(ctx context.Context, hostID, containerName, targetImage, targetDigest string) ([]HookRun, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	if c.provider == nil {
-		return fmt.Errorf("cluster not enabled")
+		return nil, fmt.Errorf("cluster not enabled")
 	}
+	key, ru := hostID+"::"+containerName, &remoteUpdate{hostID: hostID, name: containerName, startedAt: time.Now()}
+	c.remote.Store(key, ru)
+	defer c.remote.CompareAndDelete(key, ru)
 	return c.provider.UpdateRemoteContainer(ctx, hostID, containerName, targetImage, targetDigest)
 }
 
+// RemoteUpdates lists remote container updates still waiting on their
+// agent, oldest first.
+func (c *ClusterController) RemoteUpdates() []engine.ActiveUpdate {
+	var out []engine.ActiveUpdate
+	c.remote.Range(func(_, v any) bool {
+		ru := v.(*remoteUpdate)
+		out = append(out, engine.ActiveUpdate{
+			Name:       ru.name,
+			Phase:      "remote",
+			StartedAt:  ru.startedAt,
+			PhaseSince: ru.startedAt,
+			HostID:     ru.hostID,
+		})
+		return true
+	})
+	sort.Slice(out, func(i, j int) bool { return out[i].StartedAt.Before(out[j].StartedAt) })
+	return out
+}
+
 // RemoteContainerAction dispatches a lifecycle action to a container on a remote agent.
 // Returns an error when clustering is disabled.
 func (c *ClusterController) RemoteContainerAction(ctx context.Context, hostID, containerName, action string) error {
@@ -12,6 +12,15 @@ import (
 type mockClusterProvider struct {
 	hosts     []ClusterHost
 	connected []string
+
+	tokens    []EnrollToken
+	revokeErr error
+	genTTL    time.Duration
+	genBy     string
+
+	events    []ClusterHostEvent
+	eventsID  string
+	eventsMax int
 }
 
 func (m *mockClusterProvider) AllHosts() []ClusterHost {
@@ -31,10 +40,24 @@ func (m *mockClusterProvider) ConnectedHosts() []string {
 	return m.connected
 }
 
-func (m *mockClusterProvider) GenerateEnrollToken() (string, string, error) {
+func (m *mockClusterProvider) GenerateEnrollToken(ttl time.Duration, createdBy string) (string, string, error) {
+	m.genTTL, m.genBy = ttl, createdBy
 	return "tok-abc", "id-123", nil
 }
 
+func (m *mockClusterProvider) ListEnrollTokens() ([]EnrollToken, error) {
+	return m.tokens, nil
+}
+
+func (m *mockClusterProvider) RevokeEnrollToken(id string) error {
+	return m.revokeErr
+}
+
+func (m *mockClusterProvider) HostEvents(id string, limit int) ([]ClusterHostEvent, error) {
+	m.eventsID, m.eventsMax = id, limit
+	return m.events, nil
+}
+
 func (m *mockClusterProvider) RemoveHost(id string) error {
 	return nil
 }
@@ -47,8 +70,8 @@ func (m *mockClusterProvider) PauseHost(id string) error {
 	return nil
 }
 
-func (m *mockClusterProvider) UpdateRemoteContainer(_ context.Context, hostID, containerName, targetImage, targetDigest string) error {
-	return nil
+func (m *mockClusterProvider) UpdateRemoteContainer(_ context.Context, hostID, containerName, targetImage, targetDigest string) ([]HookRun, error) {
+	return nil, nil
 }
 
 func (m *mockClusterProvider) RemoteContainerAction(_ context.Context, _, _, _ string) error {
@@ -90,7 +113,7 @@ func TestEnabledReturnsFalseWithNoProvider(t *testing.T) {
 	if ids := cc.ConnectedHosts(); ids != nil {
 		t.Errorf("ConnectedHosts() = %v, want nil", ids)
 	}
-	if _, _, err := cc.GenerateEnrollToken(); err == nil {
+	if _, _, err := cc.GenerateEnrollToken(time.Hour, ""); err == nil {
 		t.Error("GenerateEnrollToken() should return error when disabled")
 	}
 	if err := cc.RemoveHost("any"); err == nil {
@@ -102,7 +125,7 @@ func TestEnabledReturnsFalseWithNoProvider(t *testing.T) {
 	if err := cc.PauseHost("any"); err == nil {
 		t.Error("PauseHost() should return error when disabled")
 	}
-	if err := cc.UpdateRemoteContainer(context.Background(), "h", "c", "img", "dig"); err == nil {
+	if _, err := cc.UpdateRemoteContainer(context.Background(), "h", "c", "img", "dig"); err == nil {
 		t.Error("UpdateRemoteContainer() should return error when disabled")
 	}
 	if err := cc.RollbackRemoteContainer(context.Background(), "h", "c"); err == nil {
@@ -154,7 +177,7 @@ func TestSetProviderEnablesAndDelegates(t *testing.T) {
 	}
 
 	// GenerateEnrollToken delegates.
-	tok, id, err := cc.GenerateEnrollToken()
+	tok, id, err := cc.GenerateEnrollToken(time.Hour, "")
 	if err != nil {
 		t.Fatalf("GenerateEnrollToken() error: %v", err)
 	}
@@ -172,7 +195,7 @@ func TestSetProviderEnablesAndDelegates(t *testing.T) {
 	if err := cc.PauseHost("host-1"); err != nil {
 		t.Errorf("PauseHost() error: %v", err)
 	}
-	if err := cc.UpdateRemoteContainer(context.Background(), "host-1", "nginx", "nginx:latest", "sha256:abc"); err != nil {
+	if _, err := cc.UpdateRemoteContainer(context.Background(), "host-1", "nginx", "nginx:latest", "sha256:abc"); err != nil {
 		t.Errorf("UpdateRemoteContainer() error: %v", err)
 	}
 	if err := cc.RollbackRemoteContainer(context.Background(), "host-1", "nginx"); err != nil {
@@ -201,7 +224,7 @@ func TestSetProviderNilDisablesAgain(t *testing.T) {
 	if hosts := cc.AllHosts(); hosts != nil {
 		t.Errorf("AllHosts() = %v after disable, want nil", hosts)
 	}
-	if _, _, err := cc.GenerateEnrollToken(); err == nil {
+	if _, _, err := cc.GenerateEnrollToken(time.Hour, ""); err == nil {
 		t.Error("GenerateEnrollToken() should error after disable")
 	}
 }
@@ -238,9 +261,9 @@ func TestConcurrentAccess(t *testing.T) {
 					cc.GetHost("h1")
 					cc.ConnectedHosts()
 					// Error return is expected when disabled; just ensure no panic.
-					_, _, _ = cc.GenerateEnrollToken()
+					_, _, _ = cc.GenerateEnrollToken(time.Hour, "")
 					_ = cc.RemoveHost("h1")
-					_ = cc.UpdateRemoteContainer(context.Background(), "h1", "c", "i", "d")
+					_, _ = cc.UpdateRemoteContainer(context.Background(), "h1", "c", "i", "d")
 					_ = cc.RollbackRemoteContainer(context.Background(), "h1", "c")
 				}
 			}
@@ -259,12 +282,13 @@ func TestDisabledMethodsReturnConsistentErrors(t *testing.T) {
 		name string
 		fn   func() error
 	}{
-		{"GenerateEnrollToken", func() error { _, _, err := cc.GenerateEnrollToken(); return err }},
+		{"GenerateEnrollToken", func() error { _, _, err := cc.GenerateEnrollToken(time.Hour, ""); return err }},
 		{"RemoveHost", func() error { return cc.RemoveHost("x") }},
 		{"RevokeHost", func() error { return cc.RevokeHost("x") }},
 		{"PauseHost", func() error { return cc.PauseHost("x") }},
 		{"UpdateRemoteContainer", func() error {
-			return cc.UpdateRemoteContainer(context.Background(), "h", "c", "i", "d")
+			_, err := cc.UpdateRemoteContainer(context.Background(), "h", "c", "i", "d")
+			return err
 		}},
 		{"RollbackRemoteContainer", func() error {
 			return cc.RollbackRemoteContainer(context.Background(), "h", "c")
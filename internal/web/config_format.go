@@ -0,0 +1,205 @@
+package web
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigEncryption describes how the secrets in an export were encrypted.
+// Check is a known value sealed with the same key, so a wrong passphrase is
+// caught before anything is imported.
+type ConfigEncryption struct {
+	KDF   string `json:"kdf"`
+	Salt  string `json:"salt"`
+	Check string `json:"check"`
+}
+
+// encryptedPrefix marks a secret sealed with the export passphrase.
+const encryptedPrefix = "enc:v1:"
+
+// configCheckValue is sealed into ConfigEncryption.Check.
+const configCheckValue = "docker-sentinel"
+
+// errWrongPassphrase is returned when an encrypted export cannot be opened.
+var errWrongPassphrase = errors.New("wrong passphrase")
+
+// secretBox seals and opens export secrets with a passphrase-derived key.
+type secretBox struct {
+	aead cipher.AEAD
+}
+
+// newSecretBox derives an AES-256-GCM key from passphrase and salt.
+func newSecretBox(passphrase string, salt []byte) (*secretBox, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &secretBox{aead: aead}, nil
+}
+
+// newExportEncryption creates a box with a fresh salt and the header that
+// lets an import derive the same key.
+func newExportEncryption(passphrase string) (*secretBox, *ConfigEncryption, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, nil, err
+	}
+	box, err := newSecretBox(passphrase, salt)
+	if err != nil {
+		return nil, nil, err
+	}
+	return box, &ConfigEncryption{
+		KDF:   "scrypt",
+		Salt:  base64.StdEncoding.EncodeToString(salt),
+		Check: box.seal(configCheckValue),
+	}, nil
+}
+
+// openImportEncryption rebuilds the box for an encrypted export and checks
+// the passphrase against the header.
+func openImportEncryption(passphrase string, enc *ConfigEncryption) (*secretBox, error) {
+	if enc.KDF != "scrypt" {
+		return nil, fmt.Errorf("unsupported key derivation %q", enc.KDF)
+	}
+	salt, err := base64.StdEncoding.DecodeString(enc.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid salt: %w", err)
+	}
+	box, err := newSecretBox(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	if v, err := box.open(enc.Check); err != nil || v != configCheckValue {
+		return nil, errWrongPassphrase
+	}
+	return box, nil
+}
+
+// seal encrypts a value and returns it with encryptedPrefix.
+func (b *secretBox) seal(plain string) string {
+	nonce := make([]byte, b.aead.NonceSize())
+	_, _ = rand.Read(nonce)
+	sealed := b.aead.Seal(nonce, nonce, []byte(plain), nil)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed)
+}
+
+// open decrypts a value produced by seal.
+func (b *secretBox) open(v string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(v, encryptedPrefix))
+	if err != nil {
+		return "", err
+	}
+	n := b.aead.NonceSize()
+	if len(raw) < n {
+		return "", errWrongPassphrase
+	}
+	plain, err := b.aead.Open(nil, raw[:n], raw[n:], nil)
+	if err != nil {
+		return "", errWrongPassphrase
+	}
+	return string(plain), nil
+}
+
+// isEncrypted reports whether a value was sealed with an export passphrase.
+func isEncrypted(v string) bool {
+	return strings.HasPrefix(v, encryptedPrefix)
+}
+
+// encryptedSettings returns the string held in a channel's settings when the
+// whole object was sealed, or "" when the settings are plain JSON.
+func encryptedSettings(raw json.RawMessage) string {
+	var v string
+	if json.Unmarshal(raw, &v) == nil && isEncrypted(v) {
+		return v
+	}
+	return ""
+}
+
+// marshalConfigYAML renders an export as YAML with the same field names and
+// order as the JSON form. The JSON is parsed as YAML (a superset) and its
+// flow styling dropped, so nested channel settings stay readable maps.
+func marshalConfigYAML(export ConfigExport) ([]byte, error) {
+	data, err := json.Marshal(export)
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	blockStyle(&doc)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// blockStyle clears the styling of n and its children, letting the encoder
+// choose block layout and quote only scalars that need it.
+func blockStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		blockStyle(c)
+	}
+}
+
+// unmarshalConfig decodes a JSON or YAML export. Hand-written YAML may leave
+// setting values unquoted, so scalar settings are converted to strings.
+func unmarshalConfig(data []byte) (ConfigExport, error) {
+	var export ConfigExport
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		if err := json.Unmarshal(data, &export); err != nil {
+			return export, fmt.Errorf("invalid JSON: %w", err)
+		}
+		return export, nil
+	}
+
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return export, fmt.Errorf("invalid YAML: %w", err)
+	}
+	if settings, ok := doc["settings"].(map[string]any); ok {
+		for k, v := range settings {
+			switch v.(type) {
+			case string, map[string]any, []any:
+			case nil:
+				settings[k] = ""
+			default:
+				settings[k] = fmt.Sprint(v)
+			}
+		}
+	}
+	asJSON, err := json.Marshal(doc)
+	if err != nil {
+		return export, fmt.Errorf("invalid YAML: %w", err)
+	}
+	if err := json.Unmarshal(asJSON, &export); err != nil {
+		return export, fmt.Errorf("invalid config: %w", err)
+	}
+	return export, nil
+}
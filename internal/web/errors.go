@@ -0,0 +1,164 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+)
+
+// ErrorCode is a stable, machine-readable identifier for an API error.
+// Clients should branch on the code, not the message: messages are for
+// humans and may be reworded or translated. The catalogue is documented in
+// docs/API-ERRORS.md; adding a code means adding it to errorCatalogue too.
+type ErrorCode string
+
+// Generic codes, derived from the HTTP status when a handler does not pick
+// a more specific one.
+const (
+	CodeBadRequest       ErrorCode = "bad_request"
+	CodeUnauthorized     ErrorCode = "unauthorized"
+	CodeForbidden        ErrorCode = "forbidden"
+	CodeNotFound         ErrorCode = "not_found"
+	CodeConflict         ErrorCode = "conflict"
+	CodePayloadTooLarge  ErrorCode = "payload_too_large"
+	CodeRateLimited      ErrorCode = "rate_limited"
+	CodeInternal         ErrorCode = "internal_error"
+	CodeNotConfigured    ErrorCode = "not_configured"
+	CodeUpstreamError    ErrorCode = "upstream_error"
+	CodeUnavailable      ErrorCode = "unavailable"
+	CodeUpstreamTimeout  ErrorCode = "upstream_timeout"
+	CodeMethodNotAllowed ErrorCode = "method_not_allowed"
+)
+
+// Specific codes.
+const (
+	CodeInvalidBody          ErrorCode = "invalid_body"
+	CodeInvalidContainerName ErrorCode = "invalid_container_name"
+	CodeContainerNotFound    ErrorCode = "container_not_found"
+	CodeUpdateBlocked        ErrorCode = "update_blocked"
+	CodeSetupComplete        ErrorCode = "setup_complete"
+	CodeAccountLocked        ErrorCode = "account_locked"
+	CodeInvalidCredentials   ErrorCode = "invalid_credentials"
+	CodeClusterDisabled      ErrorCode = "cluster_disabled"
+
+	// Returned by the auth middleware before a handler runs.
+	CodeInvalidToken ErrorCode = "invalid_token"
+	CodeCSRFFailed   ErrorCode = "csrf_failed"
+)
+
+// errorCatalogue describes every code the API can return.
+var errorCatalogue = map[ErrorCode]string{
+	CodeBadRequest:       "The request was malformed or a parameter was invalid.",
+	CodeUnauthorized:     "Authentication is required or the credentials are invalid.",
+	CodeForbidden:        "The caller lacks the permission for this action.",
+	CodeNotFound:         "The requested resource does not exist.",
+	CodeConflict:         "The request conflicts with the current state.",
+	CodePayloadTooLarge:  "The request body is too large.",
+	CodeRateLimited:      "Too many requests; retry after the Retry-After interval.",
+	CodeInternal:         "Sentinel failed to complete the request.",
+	CodeNotConfigured:    "The feature is not configured or not available in this instance.",
+	CodeUpstreamError:    "A registry, Docker host or other upstream service returned an error.",
+	CodeUnavailable:      "The service is temporarily unavailable.",
+	CodeUpstreamTimeout:  "An upstream service did not respond in time.",
+	CodeMethodNotAllowed: "The HTTP method is not supported for this endpoint.",
+
+	CodeInvalidBody:          "The request body is not valid JSON or has the wrong shape.",
+	CodeInvalidContainerName: "The container name is missing or contains invalid characters.",
+	CodeContainerNotFound:    "No container with that name exists on the target host.",
+	CodeUpdateBlocked:        "The Docker connection lacks a permission the update needs; see details.reason.",
+	CodeSetupComplete:        "First-run setup has already been completed.",
+	CodeAccountLocked:        "The account is temporarily locked after failed logins.",
+	CodeInvalidCredentials:   "The username, password or second factor is wrong.",
+	CodeClusterDisabled:      "Multi-host cluster mode is not enabled.",
+	CodeInvalidToken:         "The API bearer token is invalid or expired.",
+	CodeCSRFFailed:           "The CSRF token is missing or does not match the cookie.",
+}
+
+// messageCodes maps the messages handlers share to a specific code, so
+// common failures get precise codes without touching every call site.
+// Messages of the form "container not found: web" match on the part
+// before the colon.
+var messageCodes = map[string]ErrorCode{
+	"invalid JSON body":             CodeInvalidBody,
+	"invalid JSON":                  CodeInvalidBody,
+	"invalid request body":          CodeInvalidBody,
+	"invalid container name":        CodeInvalidContainerName,
+	"container name required":       CodeInvalidContainerName,
+	"container not found":           CodeContainerNotFound,
+	"setup already complete":        CodeSetupComplete,
+	"cluster not enabled":           CodeClusterDisabled,
+	"Account is temporarily locked": CodeAccountLocked,
+	"Invalid username or password":  CodeInvalidCredentials,
+}
+
+// APIError is the JSON body of every API error response. Error duplicates
+// Message for clients written before structured errors.
+type APIError struct {
+	Error     string    `json:"error"`
+	Code      ErrorCode `json:"code"`
+	Message   string    `json:"message"`
+	Details   any       `json:"details,omitempty"`
+	Retryable bool      `json:"retryable"`
+}
+
+// codeForStatus returns the generic code for an HTTP status.
+func codeForStatus(status int) ErrorCode {
+	switch status {
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusRequestEntityTooLarge:
+		return CodePayloadTooLarge
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusNotImplemented:
+		return CodeNotConfigured
+	case http.StatusBadGateway:
+		return CodeUpstreamError
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	case http.StatusGatewayTimeout:
+		return CodeUpstreamTimeout
+	}
+	if status >= 500 {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
+
+// retryableStatus reports whether repeating the same request later may
+// succeed: rate limits and server-side failures, except features that are
+// simply not configured.
+func retryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || (status >= 500 && status != http.StatusNotImplemented)
+}
+
+// newAPIError builds an error body, picking a specific code from the message
+// when one is registered and falling back to the status code.
+func newAPIError(status int, msg string) APIError {
+	code, ok := messageCodes[msg]
+	if !ok {
+		if prefix, _, found := strings.Cut(msg, ":"); found {
+			code, ok = messageCodes[prefix]
+		}
+	}
+	if !ok {
+		code = codeForStatus(status)
+	}
+	return APIError{Error: msg, Code: code, Message: msg, Retryable: retryableStatus(status)}
+}
+
+// writeErrorCode writes an error response with an explicit code and optional
+// details for clients to act on.
+func writeErrorCode(w http.ResponseWriter, status int, code ErrorCode, msg string, details any) {
+	e := newAPIError(status, msg)
+	e.Code = code
+	e.Details = details
+	writeJSON(w, status, e)
+}
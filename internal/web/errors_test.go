@@ -0,0 +1,81 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestWriteErrorStructured(t *testing.T) {
+	tests := []struct {
+		status    int
+		msg       string
+		code      ErrorCode
+		retryable bool
+	}{
+		{http.StatusBadRequest, "invalid container name", CodeInvalidContainerName, false},
+		{http.StatusNotFound, "container not found: web", CodeContainerNotFound, false},
+		{http.StatusBadRequest, "schedule must be a cron expression", CodeBadRequest, false},
+		{http.StatusNotImplemented, "reports not available", CodeNotConfigured, false},
+		{http.StatusInternalServerError, "failed to save setting", CodeInternal, true},
+		{http.StatusTooManyRequests, "too many requests, try again later", CodeRateLimited, true},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		writeError(w, tt.status, tt.msg)
+
+		var got APIError
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+			t.Fatalf("%s: decode: %v", tt.msg, err)
+		}
+		if got.Code != tt.code || got.Retryable != tt.retryable {
+			t.Errorf("%s: code = %q retryable = %v, want %q %v", tt.msg, got.Code, got.Retryable, tt.code, tt.retryable)
+		}
+		if got.Error != tt.msg || got.Message != tt.msg {
+			t.Errorf("%s: error/message = %q/%q", tt.msg, got.Error, got.Message)
+		}
+	}
+}
+
+func TestWriteErrorCodeDetails(t *testing.T) {
+	w := httptest.NewRecorder()
+	writeErrorCode(w, http.StatusConflict, CodeUpdateBlocked, "cannot pull", map[string]string{"reason": "cannot pull"})
+
+	var got struct {
+		Code    ErrorCode         `json:"code"`
+		Details map[string]string `json:"details"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Code != CodeUpdateBlocked || got.Details["reason"] != "cannot pull" {
+		t.Errorf("got %+v", got)
+	}
+}
+
+// Every code must be documented, and every message shortcut must map to a
+// catalogued code.
+func TestErrorCatalogueDocumented(t *testing.T) {
+	doc, err := os.ReadFile("../../docs/API-ERRORS.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for code := range errorCatalogue {
+		if !strings.Contains(string(doc), "`"+string(code)+"`") {
+			t.Errorf("code %q missing from docs/API-ERRORS.md", code)
+		}
+	}
+	for msg, code := range messageCodes {
+		if _, ok := errorCatalogue[code]; !ok {
+			t.Errorf("message %q maps to uncatalogued code %q", msg, code)
+		}
+	}
+	for _, status := range []int{400, 401, 403, 404, 405, 409, 413, 429, 500, 501, 502, 503, 504} {
+		if _, ok := errorCatalogue[codeForStatus(status)]; !ok {
+			t.Errorf("status %d maps to uncatalogued code %q", status, codeForStatus(status))
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package web
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// ExporterDeps holds dependencies for the exporter web server.
+type ExporterDeps struct {
+	RateTracker RateLimitProvider
+	Gatherer    prometheus.Gatherer // metrics served at /metrics
+	Log         *slog.Logger
+	Version     string
+}
+
+// ExporterServer serves registry rate limits in exporter mode: Prometheus
+// metrics and the same JSON as the full server's /api/ratelimits. It has no
+// UI and no authentication, like the /metrics endpoint of the full server.
+type ExporterServer struct {
+	deps   ExporterDeps
+	mux    *http.ServeMux
+	server *http.Server
+}
+
+// NewExporterServer creates an ExporterServer ready to serve.
+func NewExporterServer(deps ExporterDeps) *ExporterServer {
+	es := &ExporterServer{
+		deps: deps,
+		mux:  http.NewServeMux(),
+	}
+	es.registerRoutes()
+	return es
+}
+
+func (es *ExporterServer) registerRoutes() {
+	es.mux.Handle("GET /metrics", promhttp.HandlerFor(es.deps.Gatherer, promhttp.HandlerOpts{}))
+	es.mux.HandleFunc("GET /api/v1/ratelimits", es.apiRateLimits)
+	es.mux.HandleFunc("GET /api/ratelimits", es.apiRateLimits)
+	es.mux.HandleFunc("GET /healthz", es.apiHealth)
+}
+
+func (es *ExporterServer) apiRateLimits(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, rateLimitsResponse(es.deps.RateTracker))
+}
+
+func (es *ExporterServer) apiHealth(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok", "version": es.deps.Version})
+}
+
+// ServeHTTP lets the exporter server be used directly as a handler.
+func (es *ExporterServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	es.mux.ServeHTTP(w, r)
+}
+
+// ListenAndServe starts the exporter HTTP server on addr.
+func (es *ExporterServer) ListenAndServe(addr string) error {
+	es.server = &http.Server{
+		Addr:         addr,
+		Handler:      es.mux,
+		ReadTimeout:  30 * time.Second,
+		WriteTimeout: 30 * time.Second,
+		IdleTimeout:  120 * time.Second,
+	}
+	es.deps.Log.Info("exporter web server listening", "addr", addr)
+	return es.server.ListenAndServe()
+}
+
+// Shutdown gracefully stops the exporter web server.
+func (es *ExporterServer) Shutdown(ctx context.Context) error {
+	if es.server == nil {
+		return nil
+	}
+	return es.server.Shutdown(ctx)
+}
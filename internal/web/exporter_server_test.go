@@ -0,0 +1,64 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type fakeRateTracker struct{ statuses []RateLimitStatus }
+
+func (f *fakeRateTracker) Status() []RateLimitStatus { return f.statuses }
+func (f *fakeRateTracker) OverallHealth() string     { return "low" }
+func (f *fakeRateTracker) ProbeAndRecord(context.Context, string, RegistryCredential) error {
+	return nil
+}
+
+func TestExporterServer(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "sentinel_test_gauge",
+		Help: "Test gauge.",
+	}, func() float64 { return 7 }))
+	es := NewExporterServer(ExporterDeps{
+		RateTracker: &fakeRateTracker{statuses: []RateLimitStatus{{Registry: "docker.io", Limit: 100, Remaining: 12, HasLimits: true}}},
+		Gatherer:    reg,
+		Version:     "test",
+	})
+
+	for _, path := range []string{"/api/v1/ratelimits", "/api/ratelimits"} {
+		w := httptest.NewRecorder()
+		es.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d", path, w.Code)
+		}
+		var resp struct {
+			Health     string            `json:"health"`
+			Registries []RateLimitStatus `json:"registries"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("%s: decode: %v", path, err)
+		}
+		if resp.Health != "low" || len(resp.Registries) != 1 || resp.Registries[0].Remaining != 12 {
+			t.Errorf("%s: resp = %+v", path, resp)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	es.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if !strings.Contains(w.Body.String(), "sentinel_test_gauge 7") {
+		t.Errorf("/metrics missing gathered metric:\n%s", w.Body.String())
+	}
+
+	// Nothing of the full server is exposed.
+	w = httptest.NewRecorder()
+	es.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/containers", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("/api/containers status = %d, want 404", w.Code)
+	}
+}
@@ -17,7 +17,7 @@ import (
 
 // handleQueue renders the pending update queue page.
 func (s *Server) handleQueue(w http.ResponseWriter, r *http.Request) {
-	items := s.deps.Queue.List()
+	items := s.scopedQueue(r)
 	if items == nil {
 		items = []PendingUpdate{}
 	}
@@ -25,7 +25,11 @@ func (s *Server) handleQueue(w http.ResponseWriter, r *http.Request) {
 	sources := s.loadReleaseSources()
 	releaseNotes := make(map[string]releaseNote)
 	selfKeys := make(map[string]bool)
+	blocked := make(map[string]string)
+	stale := make(map[string]bool)
+	staleAfter, now := s.queueStaleAfter(), time.Now()
 	for _, item := range items {
+		stale[item.Key()] = isStale(item, staleAfter, now)
 		if len(item.NewerVersions) > 0 {
 			ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 			info := registry.FetchReleaseNotesWithSources(ctx, item.CurrentImage, item.NewerVersions[0], sources)
@@ -38,6 +42,11 @@ func (s *Server) handleQueue(w http.ResponseWriter, r *http.Request) {
 		if s.isProtectedContainer(r.Context(), item.ContainerName) {
 			selfKeys[item.Key()] = true
 		}
+		if item.HostID == "" && item.Type != "service" {
+			if reason := s.updateBlocked(r.Context(), item.ContainerName, s.getContainerLabels(r.Context(), item.ContainerName)); reason != "" {
+				blocked[item.Key()] = reason
+			}
+		}
 	}
 
 	data := pageData{
@@ -45,6 +54,8 @@ func (s *Server) handleQueue(w http.ResponseWriter, r *http.Request) {
 		Queue:             items,
 		QueueReleaseNotes: releaseNotes,
 		QueueSelfKeys:     selfKeys,
+		QueueBlocked:      blocked,
+		QueueStale:        stale,
 		QueueCount:        len(items),
 	}
 	s.withAuth(r, &data)
@@ -56,7 +67,7 @@ func (s *Server) handleQueue(w http.ResponseWriter, r *http.Request) {
 
 // handleHistory renders the update history page.
 func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
-	records, err := s.deps.Store.ListHistory(50, "")
+	records, err := s.scopedHistory(r, 50, "")
 	if err != nil {
 		s.deps.Log.Error("failed to list history", "error", err)
 		http.Error(w, "failed to load history", http.StatusInternalServerError)
@@ -75,7 +86,7 @@ func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
 	data := pageData{
 		Page:       "history",
 		History:    records,
-		QueueCount: len(s.deps.Queue.List()),
+		QueueCount: len(s.scopedQueue(r)),
 		NextCursor: nextCursor,
 	}
 	s.withAuth(r, &data)
@@ -90,7 +101,7 @@ func (s *Server) handleSettings(w http.ResponseWriter, r *http.Request) {
 	data := pageData{
 		Page:       "settings",
 		Settings:   s.deps.Config.Values(),
-		QueueCount: len(s.deps.Queue.List()),
+		QueueCount: len(s.scopedQueue(r)),
 	}
 	s.withAuth(r, &data)
 	s.withCluster(&data)
@@ -111,6 +122,9 @@ func (s *Server) handleLogs(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
+	if requestScope(r) != nil {
+		logs = s.scopedLogs(r, logs)
+	}
 	if logs == nil {
 		logs = []LogEntry{}
 	}
@@ -118,7 +132,7 @@ func (s *Server) handleLogs(w http.ResponseWriter, r *http.Request) {
 	data := pageData{
 		Page:       "logs",
 		Logs:       logs,
-		QueueCount: len(s.deps.Queue.List()),
+		QueueCount: len(s.scopedQueue(r)),
 	}
 	s.withAuth(r, &data)
 	s.withCluster(&data)
@@ -138,6 +152,9 @@ func (s *Server) apiLogs(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, "failed to list logs")
 		return
 	}
+	if requestScope(r) != nil {
+		logs = s.scopedLogs(r, logs)
+	}
 	if logs == nil {
 		logs = []LogEntry{}
 	}
@@ -231,6 +248,9 @@ func (s *Server) handleContainerRow(w http.ResponseWriter, r *http.Request) {
 				HostAddress:     s.localHostAddr(r),
 			}
 			v.PortURLs = s.resolvePortURLs(n, s.localHostAddr(r), "", c.Ports)
+			if v.HasUpdate {
+				v.UpdateBlocked = s.updateBlocked(r.Context(), n, c.Labels)
+			}
 			targetView = &v
 		}
 	}
@@ -391,8 +411,17 @@ func (s *Server) handleDashboardStats(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	total, running := len(containers), 0
+	unmanaged := s.unmanagedSet()
+	scope := requestScope(r)
+	total, running := 0, 0
 	for _, c := range containers {
+		if _, ok := unmanaged[containerName(c)]; ok {
+			continue
+		}
+		if !scope.Matches(containerName(c), c.Labels) {
+			continue
+		}
+		total++
 		if c.State == "running" {
 			running++
 		}
@@ -402,13 +431,23 @@ func (s *Server) handleDashboardStats(w http.ResponseWriter, r *http.Request) {
 	if s.deps.Swarm != nil && s.deps.Swarm.IsSwarmMode() {
 		services, err := s.deps.Swarm.ListServices(r.Context())
 		if err == nil {
-			total += len(services)
+			for _, svc := range services {
+				if scope.Matches(svc.Name, svc.Labels) {
+					total++
+				}
+			}
 		}
 	}
 
 	// Include remote cluster containers.
 	if s.deps.Cluster != nil && s.deps.Cluster.Enabled() {
 		for _, rc := range s.deps.Cluster.AllHostContainers() {
+			if _, ok := unmanaged[rc.HostID+"::"+rc.Name]; ok {
+				continue
+			}
+			if !scope.Matches(rc.Name, rc.Labels) {
+				continue
+			}
 			total++
 			if rc.State == "running" {
 				running++
@@ -419,7 +458,54 @@ func (s *Server) handleDashboardStats(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]any{
 		"total":   total,
 		"running": running,
-		"pending": len(s.deps.Queue.List()),
+		"pending": len(s.scopedQueue(r)),
+	})
+}
+
+// handleFailureStats returns update failure counts per failure class and the
+// average duration of each update stage, to show where updates go wrong.
+func (s *Server) handleFailureStats(w http.ResponseWriter, r *http.Request) {
+	records, err := s.deps.Store.ListAllHistory()
+	if err != nil {
+		s.deps.Log.Error("failed to list history for failure stats", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to load history")
+		return
+	}
+	allowed := s.scopedHistoryFilter(r)
+
+	classes := make(map[string]int)
+	stageTotals := make(map[string]time.Duration)
+	stageCounts := make(map[string]int)
+	failures := 0
+	for _, rec := range records {
+		if !allowed(rec) {
+			continue
+		}
+		if rec.FailureClass != "" {
+			classes[rec.FailureClass]++
+			failures++
+		}
+		// Rollback records repeat the stages of the failed attempt; count each
+		// attempt once.
+		if rec.Outcome == "rollback" {
+			continue
+		}
+		for stage, d := range rec.Stages {
+			stageTotals[stage] += d
+			stageCounts[stage]++
+		}
+	}
+
+	stageAvgMs := make(map[string]int64, len(stageTotals))
+	for stage, total := range stageTotals {
+		stageAvgMs[stage] = (total / time.Duration(stageCounts[stage])).Milliseconds()
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"failures":     failures,
+		"classes":      classes,
+		"stage_avg_ms": stageAvgMs,
+		"stage_counts": stageCounts,
 	})
 }
 
@@ -464,11 +550,12 @@ func (s *Server) handleCluster(w http.ResponseWriter, r *http.Request) {
 
 	data := pageData{
 		Page:                  "cluster",
-		QueueCount:            len(s.deps.Queue.List()),
+		QueueCount:            len(s.scopedQueue(r)),
 		ClusterHosts:          hosts,
 		ClusterConnectedCount: connectedCount,
 		ClusterContainerCount: containerCount,
 		ServerVersion:         s.deps.Version,
+		MinAgentVersion:       s.minAgentVersion(),
 		ClusterPort:           s.deps.ClusterPort,
 		ImageTag:              imageTag,
 	}
@@ -586,9 +673,11 @@ func effectiveScopeLabel(containerScope docker.SemverScope, globalScope string)
 		return "minor"
 	case docker.ScopeMajor:
 		return "major"
-	default:
-		return "relaxed"
 	}
+	if scope.IsConstraint() {
+		return string(scope)
+	}
+	return "relaxed"
 }
 
 // withAuthDetail populates auth context fields on containerDetailData from the request.
@@ -863,6 +952,7 @@ func (s *Server) handleContainerDetail(w http.ResponseWriter, r *http.Request) {
 			DigestOnly:      localHasUpdate && localNewest == "",
 			Severity:        localSeverity,
 			IsSelf:          found.Labels["sentinel.self"] == "true",
+			UpdateBlocked:   s.updateBlocked(r.Context(), name, found.Labels),
 			Registry:        registry.RegistryHost(found.Image),
 			Ports:           found.Ports,
 			HostAddress:     s.localHostAddr(r),
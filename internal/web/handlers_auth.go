@@ -117,7 +117,7 @@ func (s *Server) apiLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	auth.SetSessionCookie(w, session.Token, session.ExpiresAt, s.deps.Auth.CookieSecure)
+	s.deps.Auth.Cookies().SetSession(w, session.Token, session.ExpiresAt)
 
 	s.logEvent(r, "auth", "", "User "+user.Username+" logged in from "+ip)
 
@@ -237,6 +237,7 @@ func (s *Server) apiSetup(w http.ResponseWriter, r *http.Request) {
 		UserID:    user.ID,
 		IP:        clientIP(r),
 		UserAgent: r.UserAgent(),
+		Source:    auth.SessionSourceSetup,
 		CreatedAt: time.Now().UTC(),
 		ExpiresAt: time.Now().UTC().Add(s.deps.Auth.SessionExpiry),
 	}
@@ -244,7 +245,7 @@ func (s *Server) apiSetup(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, "failed to create session")
 		return
 	}
-	auth.SetSessionCookie(w, sessionToken, session.ExpiresAt, s.deps.Auth.CookieSecure)
+	s.deps.Auth.Cookies().SetSession(w, sessionToken, session.ExpiresAt)
 
 	s.logEvent(r, "auth", "", "Initial admin user "+username+" created via setup wizard")
 
@@ -255,15 +256,24 @@ func (s *Server) apiSetup(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// handleLogout processes logout.
+// handleLogout processes logout. SSO sessions are also ended at the IdP
+// (RP-initiated logout) when it advertises an end_session_endpoint.
 func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	redirect := "/login"
 	if token := auth.GetSessionToken(r); token != "" {
+		if session, err := s.deps.Auth.Sessions.GetSession(token); err == nil && session != nil && session.IsOIDC() {
+			if provider := s.getOIDCProvider(); provider != nil {
+				if u := provider.LogoutURL(session.OIDCIDToken); u != "" {
+					redirect = u
+				}
+			}
+		}
 		if err := s.deps.Auth.Logout(token); err != nil {
 			s.deps.Log.Debug("failed to clear session on logout", "error", err)
 		}
 	}
-	auth.ClearSessionCookie(w, s.deps.Auth.CookieSecure)
-	http.Redirect(w, r, "/login", http.StatusSeeOther)
+	s.deps.Auth.Cookies().ClearSession(w)
+	http.Redirect(w, r, redirect, http.StatusSeeOther)
 }
 
 // handleAccount renders the My Account page.
@@ -293,7 +303,7 @@ func (s *Server) handleAccount(w http.ResponseWriter, r *http.Request) {
 		"Passkeys":         passkeys,
 		"WebAuthnEnabled":  s.webauthn != nil,
 		"CurrentToken":     currentToken,
-		"QueueCount":       len(s.deps.Queue.List()),
+		"QueueCount":       len(s.scopedQueue(r)),
 		"PortainerEnabled": s.isPortainerEnabled(),
 		"ClusterEnabled":   s.deps.Cluster != nil && s.deps.Cluster.Enabled(),
 	})
@@ -356,6 +366,7 @@ func (s *Server) apiChangePassword(w http.ResponseWriter, r *http.Request) {
 		UserID:    rc.User.ID,
 		IP:        clientIP(r),
 		UserAgent: r.UserAgent(),
+		Source:    auth.SessionSourcePassword,
 		CreatedAt: time.Now().UTC(),
 		ExpiresAt: time.Now().UTC().Add(s.deps.Auth.SessionExpiry),
 	}
@@ -363,7 +374,7 @@ func (s *Server) apiChangePassword(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, "failed to create session")
 		return
 	}
-	auth.SetSessionCookie(w, newToken, newSession.ExpiresAt, s.deps.Auth.CookieSecure)
+	s.deps.Auth.Cookies().SetSession(w, newToken, newSession.ExpiresAt)
 
 	s.logEvent(r, "auth", "", "User "+rc.User.Username+" changed their password")
 	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
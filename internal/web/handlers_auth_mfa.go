@@ -2,6 +2,7 @@ package web
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 
 	"github.com/Will-Luck/Docker-Sentinel/internal/auth"
@@ -38,7 +39,7 @@ func (s *Server) apiLoginTOTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	auth.SetSessionCookie(w, session.Token, session.ExpiresAt, s.deps.Auth.CookieSecure)
+	s.deps.Auth.Cookies().SetSession(w, session.Token, session.ExpiresAt)
 
 	// Look up user for logging.
 	user, _ := s.deps.Auth.Users.GetUser(session.UserID)
@@ -104,11 +105,18 @@ func (s *Server) apiTOTPConfirm(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Sessions that never passed 2FA end; this one just proved a code.
+	revoked, err := s.deps.Auth.RevokeOtherSessions(rc.User.ID, auth.GetSessionToken(r))
+	if err != nil {
+		s.deps.Log.Warn("failed to revoke sessions after enabling 2FA", "error", err)
+	}
+
 	s.logEvent(r, "auth", "", "User "+rc.User.Username+" enabled 2FA")
 
 	writeJSON(w, http.StatusOK, map[string]any{
-		"status":         "ok",
-		"recovery_codes": codes,
+		"status":           "ok",
+		"recovery_codes":   codes,
+		"sessions_revoked": revoked,
 	})
 }
 
@@ -318,7 +326,7 @@ func (s *Server) apiOIDCCallback(w http.ResponseWriter, r *http.Request) {
 	session, err := s.deps.Auth.LoginWithOIDC(
 		r.Context(), userInfo,
 		provider.AutoCreate(), provider.DefaultRole(),
-		provider.GroupMappings(),
+		provider.RoleMapping(),
 		ip, r.UserAgent(),
 	)
 	if err != nil {
@@ -330,7 +338,7 @@ func (s *Server) apiOIDCCallback(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Set session cookie (same pattern as apiLogin).
-	auth.SetSessionCookie(w, session.Token, session.ExpiresAt, s.deps.Auth.CookieSecure)
+	s.deps.Auth.Cookies().SetSession(w, session.Token, session.ExpiresAt)
 
 	s.logEvent(r, "auth", "", "User "+userInfo.Username+" logged in via OIDC from "+ip)
 
@@ -338,6 +346,43 @@ func (s *Server) apiOIDCCallback(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/", http.StatusFound)
 }
 
+// apiOIDCBackchannelLogout receives logout tokens pushed by the IdP
+// (OpenID Connect Back-Channel Logout 1.0) and ends the matching SSO
+// sessions. It is called server-to-server, so there is no session or CSRF
+// token; the signed logout token is the authentication.
+func (s *Server) apiOIDCBackchannelLogout(w http.ResponseWriter, r *http.Request) {
+	provider := s.getOIDCProvider()
+	if provider == nil || !provider.BackchannelLogout() {
+		http.Error(w, "back-channel logout not enabled", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+
+	r.Body = http.MaxBytesReader(w, r.Body, 64<<10)
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request")
+		return
+	}
+	subject, sid, err := provider.VerifyLogoutToken(r.Context(), r.PostFormValue("logout_token"))
+	if err != nil {
+		s.deps.Log.Warn("OIDC back-channel logout rejected", "error", err)
+		writeError(w, http.StatusBadRequest, "invalid_request")
+		return
+	}
+
+	ended, err := s.deps.Auth.LogoutOIDCSessions(subject, sid)
+	if err != nil {
+		// The spec asks for 400 on any failure so the IdP can retry.
+		s.deps.Log.Error("OIDC back-channel logout failed", "error", err)
+		writeError(w, http.StatusBadRequest, "logout_failed")
+		return
+	}
+	if ended > 0 {
+		s.logEvent(r, "auth", "", fmt.Sprintf("IdP back-channel logout ended %d SSO session(s)", ended))
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
 // apiOIDCAvailable returns whether OIDC is configured (for login page JS).
 func (s *Server) apiOIDCAvailable(w http.ResponseWriter, _ *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]bool{
@@ -12,6 +12,7 @@ import (
 
 	"github.com/Will-Luck/Docker-Sentinel/internal/auth"
 	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+	"github.com/pquerna/otp/totp"
 )
 
 // ---------------------------------------------------------------------------
@@ -177,6 +178,41 @@ func TestApiTOTPConfirm_InvalidCode(t *testing.T) {
 	}
 }
 
+func TestApiTOTPConfirm_RevokesOtherSessions(t *testing.T) {
+	srv := newMFATestServer()
+	user := createTestUser(srv.deps.Auth, "admin", "Str0ngP@ssword!")
+	createSession(srv.deps.Auth, user.ID, "other-device")
+	createSession(srv.deps.Auth, user.ID, "current-session")
+
+	w := httptest.NewRecorder()
+	srv.apiTOTPSetup(w, reqWithAuthContext(httptest.NewRequest(http.MethodPost, "/api/auth/totp/setup", nil), &user))
+	if w.Code != http.StatusOK {
+		t.Fatalf("setup: status = %d, want %d", w.Code, http.StatusOK)
+	}
+	freshUser, _ := srv.deps.Auth.Users.GetUser(user.ID)
+	code, err := totp.GenerateCode(freshUser.TOTPSecret, time.Now())
+	if err != nil {
+		t.Fatalf("GenerateCode: %v", err)
+	}
+
+	w = httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/auth/totp/confirm", strings.NewReader(`{"code":"`+code+`"}`))
+	r.AddCookie(&http.Cookie{Name: auth.SessionCookieName, Value: "current-session"})
+	srv.apiTOTPConfirm(w, reqWithAuthContext(r, freshUser))
+	if w.Code != http.StatusOK {
+		t.Fatalf("confirm: status = %d; body: %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		SessionsRevoked int `json:"sessions_revoked"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	remaining, _ := srv.deps.Auth.Sessions.ListSessionsForUser(user.ID)
+	if resp.SessionsRevoked != 1 || len(remaining) != 1 || remaining[0].Token != "current-session" {
+		t.Errorf("revoked %d, remaining %+v; want only the current session kept", resp.SessionsRevoked, remaining)
+	}
+}
+
 func TestApiTOTPConfirm_EmptyCode(t *testing.T) {
 	srv := newMFATestServer()
 	user := createTestUser(srv.deps.Auth, "admin", "Str0ngP@ssword!")
@@ -8,7 +8,14 @@ import (
 	"github.com/Will-Luck/Docker-Sentinel/internal/auth"
 )
 
-// apiListSessions returns the current user's active sessions.
+// sessionView is a session as listed to its owner.
+type sessionView struct {
+	auth.Session
+	Current bool `json:"current"` // the session making the request
+}
+
+// apiListSessions returns the current user's active sessions with the
+// device (IP, user agent) and sign-in method of each.
 func (s *Server) apiListSessions(w http.ResponseWriter, r *http.Request) {
 	rc := auth.GetRequestContext(r.Context())
 	if rc == nil || rc.User == nil {
@@ -21,7 +28,15 @@ func (s *Server) apiListSessions(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, "failed to list sessions")
 		return
 	}
-	writeJSON(w, http.StatusOK, sessions)
+	currentToken := auth.GetSessionToken(r)
+	out := make([]sessionView, len(sessions))
+	for i, sess := range sessions {
+		// IdP tokens stay server-side.
+		sess.OIDCIDToken = ""
+		sess.OIDCRefreshToken = ""
+		out[i] = sessionView{Session: sess, Current: sess.Token == currentToken}
+	}
+	writeJSON(w, http.StatusOK, out)
 }
 
 // apiRevokeSession revokes a specific session.
@@ -61,17 +76,35 @@ func (s *Server) apiRevokeAllSessions(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	currentToken := auth.GetSessionToken(r)
-	sessions, _ := s.deps.Auth.Sessions.ListSessionsForUser(rc.User.ID)
-	for _, sess := range sessions {
-		if sess.Token != currentToken {
-			if err := s.deps.Auth.Sessions.DeleteSession(sess.Token); err != nil {
-				s.deps.Log.Debug("failed to delete revoked session", "error", err)
-			}
-		}
+	revoked, err := s.deps.Auth.RevokeOtherSessions(rc.User.ID, auth.GetSessionToken(r))
+	if err != nil {
+		s.deps.Log.Debug("failed to delete revoked session", "error", err)
 	}
 
-	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	writeJSON(w, http.StatusOK, map[string]any{"status": "ok", "revoked": revoked})
+}
+
+// tokenAuditState is an API token as the audit log records it, without
+// its hash.
+func tokenAuditState(t auth.APIToken) map[string]any {
+	return map[string]any{
+		"id":          t.ID,
+		"name":        t.Name,
+		"user_id":     t.UserID,
+		"permissions": t.Permissions,
+		"expires_at":  t.ExpiresAt,
+	}
+}
+
+// userAuditState is a user as the audit log records it, without
+// credentials.
+func userAuditState(u auth.User) map[string]any {
+	return map[string]any{
+		"id":              u.ID,
+		"username":        u.Username,
+		"role_id":         u.RoleID,
+		"container_scope": u.ContainerScope,
+	}
 }
 
 // apiCreateToken creates a new API bearer token.
@@ -113,6 +146,7 @@ func (s *Server) apiCreateToken(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, "failed to create token")
 		return
 	}
+	auditState(r, nil, tokenAuditState(apiToken))
 
 	s.logEvent(r, "auth", "", "API token "+body.Name+" created by "+rc.User.Username)
 
@@ -139,14 +173,14 @@ func (s *Server) apiDeleteToken(w http.ResponseWriter, r *http.Request) {
 
 	// Users can only delete their own tokens (admin can delete any via user management).
 	tokens, _ := s.deps.Auth.Tokens.ListAPITokensForUser(rc.User.ID)
-	found := false
-	for _, t := range tokens {
-		if t.ID == id {
-			found = true
+	var found *auth.APIToken
+	for i := range tokens {
+		if tokens[i].ID == id {
+			found = &tokens[i]
 			break
 		}
 	}
-	if !found {
+	if found == nil {
 		writeError(w, http.StatusNotFound, "token not found")
 		return
 	}
@@ -155,6 +189,7 @@ func (s *Server) apiDeleteToken(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, "failed to delete token")
 		return
 	}
+	auditState(r, tokenAuditState(*found), nil)
 
 	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
 }
@@ -169,11 +204,12 @@ func (s *Server) apiListUsers(w http.ResponseWriter, r *http.Request) {
 
 	// Strip password hashes from response.
 	type safeUser struct {
-		ID        string    `json:"id"`
-		Username  string    `json:"username"`
-		RoleID    string    `json:"role_id"`
-		CreatedAt time.Time `json:"created_at"`
-		Locked    bool      `json:"locked"`
+		ID        string               `json:"id"`
+		Username  string               `json:"username"`
+		RoleID    string               `json:"role_id"`
+		CreatedAt time.Time            `json:"created_at"`
+		Locked    bool                 `json:"locked"`
+		Scope     *auth.ContainerScope `json:"container_scope,omitempty"`
 	}
 	result := make([]safeUser, len(users))
 	for i, u := range users {
@@ -183,6 +219,7 @@ func (s *Server) apiListUsers(w http.ResponseWriter, r *http.Request) {
 			RoleID:    u.RoleID,
 			CreatedAt: u.CreatedAt,
 			Locked:    u.Locked,
+			Scope:     u.ContainerScope,
 		}
 	}
 
@@ -192,9 +229,10 @@ func (s *Server) apiListUsers(w http.ResponseWriter, r *http.Request) {
 // apiCreateUser creates a new user (admin only).
 func (s *Server) apiCreateUser(w http.ResponseWriter, r *http.Request) {
 	var body struct {
-		Username string `json:"username"`
-		Password string `json:"password"`
-		RoleID   string `json:"role_id"`
+		Username string               `json:"username"`
+		Password string               `json:"password"`
+		RoleID   string               `json:"role_id"`
+		Scope    *auth.ContainerScope `json:"container_scope"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid request body")
@@ -215,6 +253,14 @@ func (s *Server) apiCreateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := body.Scope.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if body.Scope.Unrestricted() {
+		body.Scope = nil
+	}
+
 	if err := auth.ValidatePassword(body.Password); err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
@@ -233,18 +279,20 @@ func (s *Server) apiCreateUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	user := auth.User{
-		ID:           userID,
-		Username:     body.Username,
-		PasswordHash: hash,
-		RoleID:       body.RoleID,
-		CreatedAt:    time.Now().UTC(),
-		UpdatedAt:    time.Now().UTC(),
+		ID:             userID,
+		Username:       body.Username,
+		PasswordHash:   hash,
+		RoleID:         body.RoleID,
+		ContainerScope: body.Scope,
+		CreatedAt:      time.Now().UTC(),
+		UpdatedAt:      time.Now().UTC(),
 	}
 
 	if err := s.deps.Auth.Users.CreateUser(user); err != nil {
 		writeError(w, http.StatusConflict, "username already exists")
 		return
 	}
+	auditState(r, nil, userAuditState(user))
 
 	rc := auth.GetRequestContext(r.Context())
 	if rc != nil && rc.User != nil {
@@ -254,6 +302,44 @@ func (s *Server) apiCreateUser(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusCreated, map[string]string{"id": userID, "username": body.Username})
 }
 
+// apiSetUserScope replaces the containers a user may see (admin only). An
+// empty scope lifts the restriction.
+func (s *Server) apiSetUserScope(w http.ResponseWriter, r *http.Request) {
+	var scope auth.ContainerScope
+	if err := json.NewDecoder(r.Body).Decode(&scope); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := scope.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := s.deps.Auth.Users.GetUser(r.PathValue("id"))
+	if err != nil || user == nil {
+		writeError(w, http.StatusNotFound, "user not found")
+		return
+	}
+	auditState(r, user.ContainerScope, &scope)
+
+	user.ContainerScope = &scope
+	if scope.Unrestricted() {
+		user.ContainerScope = nil
+	}
+	user.UpdatedAt = time.Now().UTC()
+	if err := s.deps.Auth.Users.UpdateUser(*user); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to update user")
+		return
+	}
+
+	msg := "Container scope of " + user.Username + " cleared"
+	if user.ContainerScope != nil {
+		msg = "Container scope of " + user.Username + " updated"
+	}
+	s.logEvent(r, "auth", "", msg)
+	writeJSON(w, http.StatusOK, map[string]any{"status": "ok", "container_scope": user.ContainerScope})
+}
+
 // apiDeleteUser deletes a user (admin only).
 func (s *Server) apiDeleteUser(w http.ResponseWriter, r *http.Request) {
 	rc := auth.GetRequestContext(r.Context())
@@ -284,6 +370,7 @@ func (s *Server) apiDeleteUser(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, "failed to delete user")
 		return
 	}
+	auditState(r, userAuditState(*target), nil)
 
 	s.logEvent(r, "auth", "", "User "+target.Username+" deleted by "+rc.User.Username)
 	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
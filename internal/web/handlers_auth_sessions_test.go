@@ -5,6 +5,7 @@ import (
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -76,6 +77,64 @@ func TestApiListSessions_Success(t *testing.T) {
 	}
 }
 
+func TestApiListSessions_HidesOIDCTokens(t *testing.T) {
+	srv := newSessionTestServer()
+	user := createTestUser(srv.deps.Auth, "admin", "Str0ngP@ssword!")
+	_ = srv.deps.Auth.Sessions.CreateSession(auth.Session{
+		Token:            "sso-session",
+		UserID:           user.ID,
+		ExpiresAt:        time.Now().Add(time.Hour),
+		OIDCSubject:      "sub-1",
+		OIDCIDToken:      "id-token",
+		OIDCRefreshToken: "refresh-token",
+	})
+
+	w := httptest.NewRecorder()
+	r := reqWithAuthContext(httptest.NewRequest(http.MethodGet, "/api/auth/sessions", nil), &user)
+	srv.apiListSessions(w, r)
+
+	if strings.Contains(w.Body.String(), "id-token") || strings.Contains(w.Body.String(), "refresh-token") {
+		t.Errorf("IdP tokens leaked in session list: %s", w.Body.String())
+	}
+}
+
+func TestApiListSessions_MarksCurrent(t *testing.T) {
+	srv := newSessionTestServer()
+	user := createTestUser(srv.deps.Auth, "admin", "Str0ngP@ssword!")
+	createSession(srv.deps.Auth, user.ID, "other-session")
+	current := createSession(srv.deps.Auth, user.ID, "current-session")
+	current.Source = auth.SessionSourcePasskey
+	_ = srv.deps.Auth.Sessions.CreateSession(current)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api/auth/sessions", nil)
+	r.AddCookie(&http.Cookie{Name: auth.SessionCookieName, Value: "current-session"})
+	srv.apiListSessions(w, reqWithAuthContext(r, &user))
+
+	var sessions []struct {
+		Token     string `json:"token"`
+		IP        string `json:"ip"`
+		UserAgent string `json:"user_agent"`
+		Source    string `json:"source"`
+		Current   bool   `json:"current"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &sessions); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	for _, sess := range sessions {
+		if sess.IP == "" || sess.UserAgent == "" {
+			t.Errorf("session %s missing device details: %+v", sess.Token, sess)
+		}
+		wantCurrent := sess.Token == "current-session"
+		if sess.Current != wantCurrent {
+			t.Errorf("session %s current = %v, want %v", sess.Token, sess.Current, wantCurrent)
+		}
+		if wantCurrent && sess.Source != auth.SessionSourcePasskey {
+			t.Errorf("source = %q, want %q", sess.Source, auth.SessionSourcePasskey)
+		}
+	}
+}
+
 func TestApiListSessions_NoAuth(t *testing.T) {
 	srv := newSessionTestServer()
 
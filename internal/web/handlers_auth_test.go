@@ -40,6 +40,8 @@ func (m *mockUpdateQueue) Get(_ string) (PendingUpdate, bool)     { return Pendi
 func (m *mockUpdateQueue) Add(_ PendingUpdate)                    {}
 func (m *mockUpdateQueue) Approve(_ string) (PendingUpdate, bool) { return PendingUpdate{}, false }
 func (m *mockUpdateQueue) Remove(_ string)                        {}
+func (m *mockUpdateQueue) Reorder(_ []string) error               { return nil }
+func (m *mockUpdateQueue) SetComment(_, _ string) bool            { return false }
 
 // newAuthTestService creates an auth.Service with in-memory stores and a
 // pre-created admin user. Returns the service and the admin's password hash.
@@ -214,6 +216,14 @@ func (m *webMockSessionStore) ListSessionsForUser(userID string) ([]auth.Session
 	return result, nil
 }
 
+func (m *webMockSessionStore) ListSessions() ([]auth.Session, error) {
+	result := make([]auth.Session, 0, len(m.sessions))
+	for _, s := range m.sessions {
+		result = append(result, s)
+	}
+	return result, nil
+}
+
 func (m *webMockSessionStore) DeleteExpiredSessions() (int, error) {
 	now := time.Now()
 	count := 0
@@ -477,6 +487,21 @@ func TestHandleLogout_NoSession(t *testing.T) {
 	}
 }
 
+func TestApiOIDCBackchannelLogout_NotConfigured(t *testing.T) {
+	srv := newAuthTestServer()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/auth/oidc/backchannel-logout",
+		strings.NewReader("logout_token=x"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	srv.apiOIDCBackchannelLogout(w, r)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Rate limiting test (via the web-level rateLimiter wrapper)
 // ---------------------------------------------------------------------------
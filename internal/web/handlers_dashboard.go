@@ -29,6 +29,8 @@ type pageData struct {
 	Queue             []PendingUpdate
 	QueueReleaseNotes map[string]releaseNote // keyed by queue key
 	QueueSelfKeys     map[string]bool        // queue keys that are self-protected (sentinel.self=true)
+	QueueBlocked      map[string]string      // queue key → why approval would be refused (missing Docker permission)
+	QueueStale        map[string]bool        // queue keys waiting longer than queue_stale_after
 	History           []UpdateRecord
 	Settings          map[string]string
 	Logs              []LogEntry
@@ -40,7 +42,8 @@ type pageData struct {
 	TotalContainers   int
 	RunningContainers int
 	PendingUpdates    int
-	QueueCount        int // sidebar badge: number of items in queue
+	UpdatesBlocked    string // why local updates would be refused (missing Docker permissions), or ""
+	QueueCount        int    // sidebar badge: number of items in queue
 
 	// Per-tab stats for the dashboard tab navigation.
 	TabStats []tabStats
@@ -58,6 +61,7 @@ type pageData struct {
 	ClusterConnectedCount int
 	ClusterContainerCount int
 	ServerVersion         string
+	MinAgentVersion       string // oldest supported agent release, for outdated badges
 	ClusterPort           string // gRPC port for enrollment snippets
 	ImageTag              string // stripped version tag for GHCR image snippets
 
@@ -113,6 +117,7 @@ type containerView struct {
 	DigestOnly      bool   // true when update is digest-only (same tag, newer build)
 	Severity        string // "major", "minor", "patch", "build", or "" (no update)
 	IsSelf          bool
+	UpdateBlocked   string // why an update would be refused pre-flight (missing Docker permission), or ""
 	Stack           string // com.docker.compose.project label, or "" for standalone
 	Registry        string // Registry host (e.g. "docker.io", "ghcr.io", "lscr.io")
 	IsService       bool   // true for Swarm services
@@ -127,6 +132,7 @@ type containerView struct {
 // stackGroup groups containers by their Docker Compose project name.
 type stackGroup struct {
 	Name         string
+	Project      string // Compose project, "" for standalone containers
 	Containers   []containerView
 	HasPending   bool // true if any container in the group has an update available
 	RunningCount int
@@ -176,21 +182,7 @@ func classifySeverity(current, newest string) string {
 	if newest == "" {
 		return ""
 	}
-	cur, okC := registry.ParseSemVer(current)
-	nw, okN := registry.ParseSemVer(newest)
-	if !okC || !okN {
-		return ""
-	}
-	if nw.Major != cur.Major {
-		return "major"
-	}
-	if nw.Minor != cur.Minor {
-		return "minor"
-	}
-	if nw.Patch != cur.Patch {
-		return "patch"
-	}
-	return "build"
+	return registry.ClassifyChange(current, newest)
 }
 
 // buildServiceView constructs a serviceView from a ServiceDetail, resolving
@@ -389,6 +381,9 @@ func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	unmanaged := s.unmanagedSet()
+	scope := requestScope(r)
+
 	views := make([]containerView, 0, len(containers))
 	for _, c := range containers {
 		// Filter out Swarm task containers — they'll appear in the Swarm Services section.
@@ -396,6 +391,16 @@ func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
 			continue
 		}
 
+		// Users with a container scope only see their own containers.
+		if !scope.Matches(containerName(c), c.Labels) {
+			continue
+		}
+
+		// Unmanaged containers the user chose to hide are listed in the hidden-items view only.
+		if e, ok := unmanaged[containerName(c)]; ok && e.Hidden {
+			continue
+		}
+
 		// Exclude stopped containers if the user disabled them in settings.
 		if !showStopped && c.State != "running" {
 			continue
@@ -468,6 +473,9 @@ func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
 			HostAddress:     s.localHostAddr(r),
 		})
 		views[len(views)-1].PortURLs = s.resolvePortURLs(name, s.localHostAddr(r), "", c.Ports)
+		if pendingNames[name] {
+			views[len(views)-1].UpdateBlocked = s.updateBlocked(r.Context(), name, c.Labels)
+		}
 	}
 
 	// Build Swarm Services section if available.
@@ -478,6 +486,9 @@ func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
 			s.deps.Log.Warn("failed to list service details", "error", svcErr)
 		}
 		for _, d := range details {
+			if !scope.Matches(d.Name, d.Labels) {
+				continue
+			}
 			svcViews = append(svcViews, s.buildServiceView(d, pendingNames, s.localHostAddr(r)))
 		}
 	}
@@ -574,6 +585,7 @@ func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
 		}
 		group := stackGroup{
 			Name:       name,
+			Project:    key,
 			Containers: stackMap[key],
 		}
 		for _, c := range group.Containers {
@@ -625,7 +637,8 @@ func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
 		TotalContainers:   len(views) + len(svcViews),
 		RunningContainers: running,
 		PendingUpdates:    pending,
-		QueueCount:        len(s.deps.Queue.List()),
+		UpdatesBlocked:    s.localUpdatesBlocked(r.Context()),
+		QueueCount:        len(s.scopedQueue(r)),
 		ColumnVisible:     columnVisible,
 		ColCount:          colCount,
 		ColumnConfig:      template.JS(colConfigJSON), //nolint:gosec // server-controlled JSON from json.Marshal
@@ -666,6 +679,12 @@ func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
 				if _, isTask := rc.Labels["com.docker.swarm.task"]; isTask {
 					continue
 				}
+				if !scope.Matches(rc.Name, rc.Labels) {
+					continue
+				}
+				if e, ok := unmanaged[rc.HostID+"::"+rc.Name]; ok && e.Hidden {
+					continue
+				}
 
 				tag := registry.ExtractTag(rc.Image)
 				if tag == "" {
@@ -874,6 +893,7 @@ func (s *Server) appendPortainerHostGroups(ctx context.Context, data *pageData)
 	if s.deps.PortainerInstances == nil || s.deps.Portainer == nil {
 		return
 	}
+	scope := contextScope(ctx)
 	instances, err := s.deps.PortainerInstances.ListPortainerInstances()
 	if err != nil || len(instances) == 0 {
 		return
@@ -917,6 +937,9 @@ func (s *Server) appendPortainerHostGroups(ctx context.Context, data *pageData)
 			var views []containerView
 
 			for _, c := range containers {
+				if !scope.Matches(c.Name, c.Labels) {
+					continue
+				}
 				tag := registry.ExtractTag(c.Image)
 				if tag == "" {
 					if idx := strings.LastIndex(c.Image, "/"); idx >= 0 {
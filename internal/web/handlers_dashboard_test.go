@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/Will-Luck/Docker-Sentinel/internal/events"
 )
@@ -18,6 +19,7 @@ import (
 
 type mockHistoryStore struct {
 	maintenance map[string]bool
+	records     []UpdateRecord
 }
 
 func newMockHistoryStore() *mockHistoryStore {
@@ -28,7 +30,19 @@ func (m *mockHistoryStore) ListHistory(_ int, _ string) ([]UpdateRecord, error)
 	return nil, nil
 }
 
-func (m *mockHistoryStore) ListAllHistory() ([]UpdateRecord, error) { return nil, nil }
+func (m *mockHistoryStore) ListAllHistory() ([]UpdateRecord, error) { return m.records, nil }
+
+func (m *mockHistoryStore) ForEachHistory(from, to time.Time, fn func(UpdateRecord) error) error {
+	for _, r := range m.records {
+		if (!from.IsZero() && r.Timestamp.Before(from)) || (!to.IsZero() && !r.Timestamp.Before(to)) {
+			continue
+		}
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
 func (m *mockHistoryStore) ListHistoryByContainer(_ string, _ int) ([]UpdateRecord, error) {
 	return nil, nil
@@ -133,6 +147,35 @@ func TestApiContainers_HappyPath(t *testing.T) {
 	}
 }
 
+// mockVersionStore implements VersionStore for testing.
+type mockVersionStore map[string]VersionInfo
+
+func (m mockVersionStore) AllVersionInfo() map[string]VersionInfo { return m }
+
+func TestApiContainers_IncludesVersions(t *testing.T) {
+	docker := &mockContainerLister{
+		containers: []ContainerSummary{
+			{ID: "c1", Names: []string{"/app"}, Image: "app:latest", State: "running"},
+		},
+	}
+	srv := newDashboardTestServer(docker, newMockHistoryStore(), nil, nil, nil, nil)
+	srv.deps.Versions = mockVersionStore{"app": {Tag: "latest", Current: "2.1.0", Candidate: "2.2.0"}}
+
+	w := httptest.NewRecorder()
+	srv.apiContainers(w, httptest.NewRequest(http.MethodGet, "/api/containers", nil))
+
+	var result []map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(result) != 1 {
+		t.Fatalf("got %d containers, want 1", len(result))
+	}
+	if result[0]["current_version"] != "2.1.0" || result[0]["candidate_version"] != "2.2.0" {
+		t.Errorf("versions = %v → %v, want 2.1.0 → 2.2.0", result[0]["current_version"], result[0]["candidate_version"])
+	}
+}
+
 func TestApiContainers_FiltersSwarmTasks(t *testing.T) {
 	docker := &mockContainerLister{
 		containers: []ContainerSummary{
@@ -392,3 +435,45 @@ func TestApiContainerDetail_DockerError(t *testing.T) {
 		t.Fatalf("status = %d, want %d", w.Code, http.StatusInternalServerError)
 	}
 }
+
+// ---------------------------------------------------------------------------
+// handleFailureStats tests
+// ---------------------------------------------------------------------------
+
+func TestHandleFailureStats(t *testing.T) {
+	hist := newMockHistoryStore()
+	hist.records = []UpdateRecord{
+		{ContainerName: "a", Outcome: "success", Stages: map[string]time.Duration{"pull": 2 * time.Second}},
+		{ContainerName: "b", Outcome: "failed", FailureClass: "pull_error", Stages: map[string]time.Duration{"pull": 4 * time.Second}},
+		{ContainerName: "c", Outcome: "rollback", FailureClass: "health_failed", Stages: map[string]time.Duration{"pull": time.Hour}},
+		{ContainerName: "d", Outcome: "rate_limited", FailureClass: "rate_limited"},
+	}
+	srv := newDashboardTestServer(&mockContainerLister{}, hist, nil, nil, nil, nil)
+
+	w := httptest.NewRecorder()
+	srv.handleFailureStats(w, httptest.NewRequest(http.MethodGet, "/api/stats/failures", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+
+	var resp struct {
+		Failures   int              `json:"failures"`
+		Classes    map[string]int   `json:"classes"`
+		StageAvgMs map[string]int64 `json:"stage_avg_ms"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Failures != 3 {
+		t.Errorf("failures = %d, want 3", resp.Failures)
+	}
+	for _, class := range []string{"pull_error", "health_failed", "rate_limited"} {
+		if resp.Classes[class] != 1 {
+			t.Errorf("classes[%s] = %d, want 1", class, resp.Classes[class])
+		}
+	}
+	// Rollback records are excluded from stage averages.
+	if resp.StageAvgMs["pull"] != 3000 {
+		t.Errorf("stage_avg_ms[pull] = %d, want 3000", resp.StageAvgMs["pull"])
+	}
+}
@@ -6,7 +6,7 @@ import "net/http"
 func (s *Server) handleImages(w http.ResponseWriter, r *http.Request) {
 	data := pageData{
 		Page:       "images",
-		QueueCount: len(s.deps.Queue.List()),
+		QueueCount: len(s.scopedQueue(r)),
 	}
 	s.withAuth(r, &data)
 	s.withCluster(&data)
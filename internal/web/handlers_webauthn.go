@@ -330,7 +330,7 @@ func (s *Server) apiPasskeyLoginFinish(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	auth.SetSessionCookie(w, session.Token, session.ExpiresAt, s.deps.Auth.CookieSecure)
+	s.deps.Auth.Cookies().SetSession(w, session.Token, session.ExpiresAt)
 
 	s.logEvent(r, "auth", "", "User "+user.Username+" logged in via passkey from "+clientIP(r))
 
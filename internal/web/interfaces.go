@@ -2,16 +2,30 @@ package web
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"io"
+	"log/slog"
 	"time"
 
+	"github.com/Will-Luck/Docker-Sentinel/internal/docker"
+	"github.com/Will-Luck/Docker-Sentinel/internal/engine"
+	"github.com/Will-Luck/Docker-Sentinel/internal/logging"
 	"github.com/Will-Luck/Docker-Sentinel/internal/notify"
+	"github.com/Will-Luck/Docker-Sentinel/internal/registry"
+	"github.com/Will-Luck/Docker-Sentinel/internal/report"
+	"github.com/Will-Luck/Docker-Sentinel/internal/scanner"
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+	"github.com/Will-Luck/Docker-Sentinel/internal/verify"
 )
 
 // HistoryStore reads/writes update history and maintenance state.
 type HistoryStore interface {
 	ListHistory(limit int, before string) ([]UpdateRecord, error)
 	ListAllHistory() ([]UpdateRecord, error)
+	// ForEachHistory calls fn for each record with a timestamp in [from, to),
+	// newest first, without loading the whole history; zero bounds are open.
+	ForEachHistory(from, to time.Time, fn func(UpdateRecord) error) error
 	ListHistoryByContainer(name string, limit int) ([]UpdateRecord, error)
 	GetMaintenance(name string) (bool, error)
 	RecordUpdate(rec UpdateRecord) error
@@ -63,6 +77,14 @@ type EventLogger interface {
 	ListLogs(limit int) ([]LogEntry, error)
 }
 
+// SelfUpdateInfo describes the self-update that started this instance.
+type SelfUpdateInfo struct {
+	FromVersion string    `json:"from_version"`
+	ToVersion   string    `json:"to_version"`
+	StartedAt   time.Time `json:"started_at"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
 // SelfUpdater triggers self-update via an ephemeral helper container.
 type SelfUpdater interface {
 	Update(ctx context.Context, targetImage string) error
@@ -79,13 +101,53 @@ type NotifierReconfigurer interface {
 	Reconfigure(notifiers ...notify.Notifier)
 	SetBatchWindow(d time.Duration)
 	SetRetry(maxRetries int, backoff time.Duration)
+	SetExternalURL(base string)
 }
 
 // BackupManager provides backup creation, listing, and download.
 type BackupManager interface {
 	CreateBackup(ctx context.Context) (*BackupInfo, error)
 	List() ([]BackupInfo, error)
+	// InProgress reports whether a hot backup is running. Mutating API
+	// requests are rejected with 503 while it is.
+	InProgress() bool
 	FilePath(filename string) (string, error)
+	// WriteSnapshot streams a consistent copy of the database to w.
+	WriteSnapshot(w io.Writer) (int64, error)
+	// Config returns the scheduled backup settings in effect.
+	Config() BackupConfig
+	// Configure applies scheduled backup settings without a restart.
+	Configure(cfg BackupConfig) error
+}
+
+// BackupConfig holds the scheduled backup settings.
+type BackupConfig struct {
+	Schedule  string `json:"schedule"`  // cron expression; empty disables scheduled backups
+	Dir       string `json:"dir"`       // directory scheduled and manual backups are written to
+	Retention int    `json:"retention"` // local backups kept; 0 = unlimited
+}
+
+// DatabaseRestorer replaces the live database with a backup file.
+type DatabaseRestorer interface {
+	// ValidateBackup checks the file is a consistent Sentinel database.
+	ValidateBackup(path string) error
+	// Restore validates the file and swaps it in for the live database.
+	Restore(path string) error
+}
+
+// DatabaseMaintainer prunes old entries and compacts the database file.
+type DatabaseMaintainer interface {
+	// ApplyRetention prunes history, logs and snapshots past the configured retention.
+	ApplyRetention() (RetentionReport, error)
+	// Compact rewrites the database file and returns its size before and after.
+	Compact() (before, after int64, err error)
+}
+
+// RetentionReport counts the entries removed by a retention run.
+type RetentionReport struct {
+	History   int `json:"history"`
+	Logs      int `json:"logs"`
+	Snapshots int `json:"snapshots"`
 }
 
 // BackupInfo describes a backup file.
@@ -109,6 +171,7 @@ type NotifyStateStore interface {
 	DeleteNotifyPref(name string) error
 	AllNotifyPrefs() (map[string]*NotifyPref, error)
 	AllNotifyStates() (map[string]*NotifyState, error)
+	SetNotifyState(name string, state *NotifyState) error
 	ClearNotifyState(name string) error
 }
 
@@ -116,6 +179,7 @@ type NotifyStateStore interface {
 type IgnoredVersionStore interface {
 	AddIgnoredVersion(containerName, version string) error
 	GetIgnoredVersions(containerName string) ([]string, error)
+	AllIgnoredVersions() (map[string][]string, error)
 	ClearIgnoredVersions(containerName string) error
 }
 
@@ -183,6 +247,15 @@ type DigestController interface {
 	SetDigestConfig()
 	TriggerDigest(ctx context.Context)
 	LastRunTime() time.Time
+	PreviewDigest(ctx context.Context, channelID string) ([]string, string, error)
+	SendSummary(ctx context.Context, channelID string, failures, offline []string) (string, error)
+}
+
+// ReportService builds and sends the periodic summary report.
+type ReportService interface {
+	Build(period time.Duration) (*report.Report, error)
+	Send(ctx context.Context) error
+	Reschedule()
 }
 
 // SchedulerController controls the scheduler's poll interval and scan triggers.
@@ -202,17 +275,26 @@ type ClusterProvider interface {
 	GetHost(id string) (ClusterHost, bool)
 	// ConnectedHosts returns the IDs of currently connected agents.
 	ConnectedHosts() []string
-	// GenerateEnrollToken creates a new one-time enrollment token.
+	// GenerateEnrollToken creates a new one-time enrollment token valid for
+	// ttl, recording createdBy as its creator.
 	// Returns the plaintext token (shown to admin once) and the token ID.
-	GenerateEnrollToken() (token string, id string, err error)
+	GenerateEnrollToken(ttl time.Duration, createdBy string) (token string, id string, err error)
+	// ListEnrollTokens returns all enrollment tokens, newest first.
+	ListEnrollTokens() ([]EnrollToken, error)
+	// RevokeEnrollToken deletes an unused enrollment token. Returns
+	// ErrEnrollTokenNotFound or ErrEnrollTokenUsed when it cannot.
+	RevokeEnrollToken(id string) error
 	// RemoveHost removes a host from the cluster.
 	RemoveHost(id string) error
 	// RevokeHost revokes a host's certificate and removes it.
 	RevokeHost(id string) error
 	// PauseHost sets a host to paused state (no new updates).
 	PauseHost(id string) error
-	// UpdateRemoteContainer dispatches a container update to a remote agent.
-	UpdateRemoteContainer(ctx context.Context, hostID, containerName, targetImage, targetDigest string) error
+	// HostEvents returns up to limit timeline events for a host, newest first.
+	HostEvents(id string, limit int) ([]ClusterHostEvent, error)
+	// UpdateRemoteContainer dispatches a container update to a remote agent
+	// and returns the lifecycle hooks the agent ran around it.
+	UpdateRemoteContainer(ctx context.Context, hostID, containerName, targetImage, targetDigest string) ([]HookRun, error)
 	// RemoteContainerAction dispatches a lifecycle action to a container on a remote agent.
 	RemoteContainerAction(ctx context.Context, hostID, containerName, action string) error
 	// RemoteContainerLogs fetches the last N lines of logs from a remote container.
@@ -224,6 +306,37 @@ type ClusterProvider interface {
 	AllHostContainers() []RemoteContainer
 }
 
+// Enrollment token errors returned by ClusterProvider.RevokeEnrollToken.
+var (
+	ErrEnrollTokenNotFound = errors.New("enrollment token not found")
+	ErrEnrollTokenUsed     = errors.New("enrollment token already used")
+)
+
+// EnrollToken describes an enrollment token for the web layer. The token
+// value itself is never listed; it is shown once when generated.
+type EnrollToken struct {
+	ID         string    `json:"id"`
+	CreatedBy  string    `json:"created_by,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Expired    bool      `json:"expired"`
+	Used       bool      `json:"used"`
+	UsedAt     time.Time `json:"used_at,omitempty"`
+	UsedByID   string    `json:"used_by_id,omitempty"`   // host ID the token enrolled
+	UsedByName string    `json:"used_by_name,omitempty"` // host name the agent enrolled with
+}
+
+// ClusterHostEvent is one entry in a host's activity timeline: a
+// connection change, a command sent to the agent, its result, a certificate
+// renewal or an agent version change.
+type ClusterHostEvent struct {
+	Timestamp time.Time `json:"timestamp"`
+	Type      string    `json:"type"`
+	Container string    `json:"container,omitempty"`
+	Message   string    `json:"message"`
+	Failed    bool      `json:"failed,omitempty"`
+}
+
 // RemoteContainer represents a container on a remote host.
 type RemoteContainer struct {
 	Name     string            `json:"name"`
@@ -244,12 +357,15 @@ type ClusterHost struct {
 	Connected     bool      `json:"connected"`
 	EnrolledAt    time.Time `json:"enrolled_at"`
 	LastSeen      time.Time `json:"last_seen"`
+	LastReport    time.Time `json:"last_report,omitempty"` // when the agent last sent its container list
 	AgentVersion  string    `json:"agent_version,omitempty"`
 	Containers    int       `json:"containers"` // count of known containers
 	DisconnectAt  time.Time `json:"disconnect_at,omitempty"`
 	DisconnectErr string    `json:"disconnect_err,omitempty"`
 	DisconnectCat string    `json:"disconnect_cat,omitempty"`
 	EngineID      string    `json:"engine_id,omitempty"` // Docker Engine ID for source dedup
+	Outdated      bool      `json:"outdated,omitempty"`  // agent older than the minimum supported version
+	Slow          bool      `json:"slow,omitempty"`      // most recent commands were slow or timed out
 }
 
 // SwarmProvider provides Swarm service operations for the dashboard.
@@ -261,6 +377,9 @@ type SwarmProvider interface {
 	UpdateService(ctx context.Context, id, name, targetImage string) error
 	RollbackService(ctx context.Context, id, name string) error
 	ScaleService(ctx context.Context, name string, replicas uint64) error
+	// PreviewPlacement predicts the nodes a service's tasks run on once it
+	// is updated to targetImage ("" keeps the current image).
+	PreviewPlacement(ctx context.Context, id, targetImage string) (engine.ServicePlacement, error)
 }
 
 // ServiceSummary is a minimal Swarm service info struct for the web layer.
@@ -294,6 +413,113 @@ type ServiceDetail struct {
 	UpdateStatus string
 }
 
+// UnmanagedStore persists containers excluded from all Sentinel management.
+type UnmanagedStore interface {
+	SetUnmanaged(key string, entry UnmanagedEntry) error
+	DeleteUnmanaged(key string) error
+	AllUnmanaged() map[string]UnmanagedEntry
+}
+
+// UnmanagedEntry mirrors store.UnmanagedEntry.
+type UnmanagedEntry struct {
+	Since  time.Time `json:"since"`
+	Hidden bool      `json:"hidden"` // also hidden from the dashboard
+}
+
+// ValidationStore persists per-container grace period and validation
+// strategy overrides.
+type ValidationStore interface {
+	GetValidationOverride(key string) (ValidationOverride, bool)
+	SetValidationOverride(key string, o ValidationOverride) error
+	DeleteValidationOverride(key string) error
+}
+
+// ValidationOverride mirrors store.ValidationOverride.
+type ValidationOverride struct {
+	GracePeriod   time.Duration
+	Strategy      string
+	ProbeURL      string
+	HealthTimeout time.Duration
+	ProbeStatus   int
+	ProbeBody     string
+}
+
+// PreconditionStore persists per-container wait-for condition overrides.
+type PreconditionStore interface {
+	GetPreconditions(key string) (Preconditions, bool)
+	SetPreconditions(key string, p Preconditions) error
+	DeletePreconditions(key string) error
+}
+
+// Preconditions mirrors store.Preconditions.
+type Preconditions struct {
+	Conditions []string
+	Action     string
+}
+
+// ScheduleStore persists per-container cron schedule overrides.
+type ScheduleStore interface {
+	GetContainerSchedule(key string) (string, bool)
+	SetContainerSchedule(key, expr string) error
+	DeleteContainerSchedule(key string) error
+}
+
+// StackWindowStore persists per-stack maintenance window overrides, keyed by
+// Compose project.
+type StackWindowStore interface {
+	GetStackWindow(project string) (string, bool)
+	SetStackWindow(project, expr string) error
+	DeleteStackWindow(project string) error
+	ListStackWindows() (map[string]string, error)
+}
+
+// PinStore persists timed pins and resolves the image digest a container is
+// pinned on.
+type PinStore interface {
+	GetTimedPin(key string) (TimedPin, bool)
+	SetTimedPin(key string, p TimedPin) error
+	DeleteTimedPin(key string) error
+	ImageDigest(ctx context.Context, imageRef string) (string, error)
+}
+
+// TimedPin mirrors store.TimedPin.
+type TimedPin struct {
+	PinnedAt       time.Time `json:"pinned_at"`
+	Until          time.Time `json:"until"`
+	PreviousPolicy string    `json:"previous_policy,omitempty"`
+	Digest         string    `json:"digest,omitempty"`
+	Reminded       bool      `json:"reminded,omitempty"`
+}
+
+// RetryStore reads and clears per-container failed auto-update state.
+type RetryStore interface {
+	AllRetryStates() map[string]RetryState
+	DeleteRetryState(name string) error
+}
+
+// RetryState mirrors store.RetryState.
+type RetryState struct {
+	Attempts    int       `json:"attempts"`
+	LastError   string    `json:"last_error,omitempty"`
+	LastFailure time.Time `json:"last_failure"`
+	NextAttempt time.Time `json:"next_attempt,omitempty"`
+	Failing     bool      `json:"failing"`
+}
+
+// VersionStore reads the human-readable versions resolved during scans.
+type VersionStore interface {
+	AllVersionInfo() map[string]VersionInfo
+}
+
+// VersionInfo mirrors store.VersionInfo.
+type VersionInfo struct {
+	Tag       string    `json:"tag"`
+	Current   string    `json:"current,omitempty"`
+	Candidate string    `json:"candidate,omitempty"`
+	Source    string    `json:"source,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
 // ReleaseSourceStore reads and writes configurable release note sources.
 type ReleaseSourceStore interface {
 	GetReleaseSources() ([]ReleaseSource, error)
@@ -309,6 +535,7 @@ type ReleaseSource struct {
 // HookStore reads and writes lifecycle hook configurations.
 type HookStore interface {
 	ListHooks(containerName string) ([]HookEntry, error)
+	ListAllHooks() ([]HookEntry, error)
 	SaveHook(hook HookEntry) error
 	DeleteHook(containerName, phase string) error
 }
@@ -335,6 +562,12 @@ type ClusterLifecycle interface {
 	Stop()
 }
 
+// ApprovalReceiver listens for update approvals from outside the dashboard,
+// such as Telegram buttons, and is reloaded when notification channels change.
+type ApprovalReceiver interface {
+	Reload(channels []notify.Channel)
+}
+
 // PortainerProvider provides multi-instance Portainer access for the web layer.
 type PortainerProvider interface {
 	TestConnection(ctx context.Context, instanceID string) error
@@ -474,7 +707,9 @@ type LogEntry struct {
 
 // NotifyPref mirrors store.NotifyPref.
 type NotifyPref struct {
-	Mode string `json:"mode"`
+	Mode     string   `json:"mode"`
+	Channels []string `json:"channels,omitempty"`
+	Source   string   `json:"source,omitempty"` // "label" when synced from sentinel.notify / sentinel.channels
 }
 
 // NotifyState mirrors store.NotifyState.
@@ -482,6 +717,7 @@ type NotifyState struct {
 	LastDigest   string    `json:"last_digest"`
 	LastNotified time.Time `json:"last_notified"`
 	FirstSeen    time.Time `json:"first_seen"`
+	SnoozedUntil time.Time `json:"snoozed_until,omitzero"`
 }
 
 // UpdateRecord mirrors store.UpdateRecord to avoid importing store.
@@ -492,12 +728,35 @@ type UpdateRecord struct {
 	OldDigest     string        `json:"old_digest"`
 	NewImage      string        `json:"new_image"`
 	NewDigest     string        `json:"new_digest"`
-	Outcome       string        `json:"outcome"` // "success", "failed", "rollback", "identical", "partial", "rate_limited", "check_failed", "dry_run", "pull_only", "scan_summary"
+	Outcome       string        `json:"outcome"` // "success", "failed", "rollback", "identical", "partial", "rate_limited", "check_failed", "dry_run", "pull_only", "deferred", "skipped", "rejected", "ignored", "scan_summary"
 	Duration      time.Duration `json:"duration"`
 	Error         string        `json:"error,omitempty"`
 	Type          string        `json:"type,omitempty"`      // "container" (default) or "service"
 	HostID        string        `json:"host_id,omitempty"`   // cluster host ID (empty = local)
 	HostName      string        `json:"host_name,omitempty"` // cluster host name (empty = local)
+
+	FailureClass string                   `json:"failure_class,omitempty"` // structured failure cause (e.g. "pull_error")
+	Stages       map[string]time.Duration `json:"stages,omitempty"`        // per-phase timings
+
+	Preconditions []PreconditionResult `json:"preconditions,omitempty"`  // wait-for results checked before the update
+	Hooks         []HookRun            `json:"hooks,omitempty"`          // lifecycle hooks a remote agent ran
+	Comment       string               `json:"comment,omitempty"`        // operator's note from the approval queue
+	ResourceDrift []string             `json:"resource_drift,omitempty"` // resource limits not preserved by the update
+}
+
+// PreconditionResult mirrors store.PreconditionResult.
+type PreconditionResult struct {
+	Condition string `json:"condition"`
+	OK        bool   `json:"ok"`
+	Detail    string `json:"detail,omitempty"`
+}
+
+// HookRun mirrors store.HookRun.
+type HookRun struct {
+	Phase    string `json:"phase"`
+	ExitCode int    `json:"exit_code"`
+	Output   string `json:"output,omitempty"`
+	Error    string `json:"error,omitempty"`
 }
 
 // SnapshotEntry represents a snapshot with a parsed image reference for display.
@@ -513,6 +772,8 @@ type UpdateQueue interface {
 	Add(update PendingUpdate)                  // Adds or replaces a pending update.
 	Approve(name string) (PendingUpdate, bool) // Returns the update and removes it from the queue.
 	Remove(name string)
+	Reorder(keys []string) error          // Moves keys to the front in the given order.
+	SetComment(name, comment string) bool // Annotates a pending update; false if not queued.
 }
 
 // PendingUpdate mirrors engine.PendingUpdate.
@@ -529,6 +790,18 @@ type PendingUpdate struct {
 	Type                   string    `json:"type,omitempty"`    // "container" (default) or "service"
 	HostID                 string    `json:"host_id,omitempty"` // cluster host ID (empty = local)
 	HostName               string    `json:"host_name,omitempty"`
+	Order                  int       `json:"order"` // execution position; lower runs first
+	// Newer versions classified as major, minor, patch or build changes.
+	Candidates []registry.VersionCandidate `json:"candidates,omitempty"`
+	// Version picked when approving; empty means the newest. Not queued.
+	TargetVersion string `json:"target_version,omitempty"`
+	// Set when the pre-update vulnerability scan held the update back.
+	ScanReport    *scanner.ScanResult `json:"scan_report,omitempty"`
+	ScanThreshold scanner.Severity    `json:"scan_threshold,omitempty"`
+	// Default config files that change upstream under mounted paths.
+	ConfigDiff *engine.ConfigDiff `json:"config_diff,omitempty"`
+	// Operator's note, copied to the history record and activity log.
+	Comment string `json:"comment,omitempty"`
 }
 
 // Key returns the queue map key. Remote containers use "hostID::name" to
@@ -567,12 +840,13 @@ type PortMapping struct {
 
 // ContainerSummary is a minimal container info struct.
 type ContainerSummary struct {
-	ID     string
-	Names  []string
-	Image  string
-	Labels map[string]string
-	State  string
-	Ports  []PortMapping
+	ID          string
+	Names       []string
+	Image       string
+	Labels      map[string]string
+	State       string
+	Ports       []PortMapping
+	NetworkMode string // HostConfig.NetworkMode, e.g. "container:<id>"
 }
 
 // ContainerInspect has just what the dashboard needs.
@@ -595,6 +869,202 @@ type ContainerUpdater interface {
 	SelfUpdateQueued() bool
 }
 
+// CapabilityChecker reports which Docker operations the local daemon
+// connection permits, so update actions can be refused before they start.
+type CapabilityChecker interface {
+	Capabilities(ctx context.Context) docker.Capabilities
+	RefreshCapabilities(ctx context.Context) docker.Capabilities
+	UpdateBlocked(ctx context.Context, name string, labels map[string]string) string
+}
+
+// UpdateLocks exposes the engine's in-flight update locks.
+type UpdateLocks interface {
+	ActiveUpdates() []engine.ActiveUpdate
+	ForceUnlock(ctx context.Context, name string) (engine.ForceUnlockResult, error)
+}
+
+// RolloutController reports and steers staged rollouts.
+type RolloutController interface {
+	Rollouts() []store.Rollout
+	PauseRollout(key string) (store.Rollout, error)
+	ResumeRollout(key string) (store.Rollout, error)
+	PromoteRollout(ctx context.Context, key string) (store.Rollout, error)
+}
+
+// ComposeSyncer previews and writes image tags back into compose files.
+type ComposeSyncer interface {
+	PreviewComposeSync(ctx context.Context, name, image string) (engine.ComposeEdit, error)
+	SyncComposeFile(ctx context.Context, name, image string) (engine.ComposeEdit, error)
+}
+
+// ComposePathStore persists where each Compose project's file is mounted
+// inside Sentinel's container.
+type ComposePathStore interface {
+	ListComposePaths() (map[string]string, error)
+	SetComposePath(project, path string) error
+	DeleteComposePath(project string) error
+}
+
+// TrustedKeyStore persists the cosign public keys image signatures are
+// verified against.
+type TrustedKeyStore interface {
+	ListTrustedKeys() ([]TrustedKey, error)
+	SaveTrustedKey(key TrustedKey) error
+	DeleteTrustedKey(name string) error
+}
+
+// VerifyCache reports on and clears cached signature verification results.
+type VerifyCache interface {
+	CacheStats() (st verify.CacheStats, enabled bool, err error)
+	InvalidateCache(digest string) (int, error) // "" clears every entry
+}
+
+// TrustedKey mirrors store.TrustedKey.
+type TrustedKey struct {
+	Name        string    `json:"name"`
+	PEM         string    `json:"pem"`
+	Fingerprint string    `json:"fingerprint"`
+	AddedAt     time.Time `json:"added_at"`
+}
+
+// DeployTokenStore persists the tokens CI pipelines use to redeploy
+// containers through the deploy hook, and the idempotency keys they sent.
+type DeployTokenStore interface {
+	ListDeployTokens() ([]DeployToken, error)
+	SaveDeployToken(token DeployToken) error
+	DeleteDeployToken(id string) error
+	GetDeployRequest(tokenID, key string) (DeployRequest, bool, error)
+	// ClaimDeployRequest atomically records req unless its key is taken,
+	// returning the request holding the key and whether req was recorded.
+	ClaimDeployRequest(req DeployRequest) (DeployRequest, bool, error)
+	// ReleaseDeployRequest forgets a key whose deploy failed, so a retry
+	// deploys again.
+	ReleaseDeployRequest(tokenID, key string) error
+}
+
+// DeployToken mirrors store.DeployToken. The hash is never sent to clients.
+type DeployToken struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	TokenHash  string    `json:"-"`
+	Containers []string  `json:"containers"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+}
+
+// DeployRequest mirrors store.DeployRequest.
+type DeployRequest struct {
+	TokenID     string
+	Key         string
+	Container   string
+	TargetImage string
+	CreatedAt   time.Time
+}
+
+// TimelineStore reads the merged activity timeline: the activity log,
+// update history and cluster host events in one keyset-paginated list.
+type TimelineStore interface {
+	// ListTimeline returns entries newest first and the cursor of the next
+	// page ("" when there are no more). A malformed q.Before returns
+	// ErrInvalidTimelineCursor.
+	ListTimeline(q TimelineQuery) ([]TimelineEntry, string, error)
+}
+
+// ErrInvalidTimelineCursor is returned by TimelineStore for a cursor it did
+// not issue.
+var ErrInvalidTimelineCursor = errors.New("invalid timeline cursor")
+
+// TimelineQuery mirrors store.TimelineQuery.
+type TimelineQuery struct {
+	Since     time.Time
+	Before    string
+	Sources   []string
+	Types     []string
+	Container string
+	HostID    string
+	Limit     int
+}
+
+// TimelineEntry mirrors store.TimelineEntry.
+type TimelineEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Source    string    `json:"source"` // "log", "history" or "cluster"
+	Type      string    `json:"type"`   // log type, update outcome or host event type
+	Container string    `json:"container,omitempty"`
+	HostID    string    `json:"host_id,omitempty"`
+	HostName  string    `json:"host_name,omitempty"`
+	User      string    `json:"user,omitempty"`
+	Message   string    `json:"message"`
+	Failed    bool      `json:"failed,omitempty"`
+	Cursor    string    `json:"cursor"`
+}
+
+// AuditStore persists the audit log of mutating API requests.
+type AuditStore interface {
+	AppendAudit(e AuditEntry) error
+	// ListAudit returns entries newest first and the cursor of the next
+	// page ("" when there are no more). A malformed q.Before returns
+	// ErrInvalidAuditCursor.
+	ListAudit(q AuditQuery) ([]AuditEntry, string, error)
+	PruneAudit(cutoff time.Time) (int, error)
+}
+
+// ErrInvalidAuditCursor is returned by AuditStore for a cursor it did not
+// issue.
+var ErrInvalidAuditCursor = errors.New("invalid audit cursor")
+
+// AuditEntry mirrors store.AuditEntry.
+type AuditEntry struct {
+	Timestamp time.Time       `json:"timestamp"`
+	Actor     string          `json:"actor,omitempty"`
+	TokenName string          `json:"token_name,omitempty"`
+	IP        string          `json:"ip"`
+	Method    string          `json:"method"`
+	Path      string          `json:"path"`
+	Status    int             `json:"status"`
+	Container string          `json:"container,omitempty"`
+	Request   json.RawMessage `json:"request,omitempty"`
+	Before    json.RawMessage `json:"before,omitempty"`
+	After     json.RawMessage `json:"after,omitempty"`
+	Cursor    string          `json:"cursor,omitempty"`
+}
+
+// AuditQuery mirrors store.AuditQuery.
+type AuditQuery struct {
+	Actor     string
+	Method    string
+	Path      string
+	Container string
+	Since     time.Time
+	Failed    bool
+	Before    string
+	Limit     int
+}
+
+// ImageDateProvider reports when images were built and pushed, so an update
+// to a fresh release can be told apart from an old tag that was never pulled.
+type ImageDateProvider interface {
+	// LocalImageDates inspects the image a container is running.
+	LocalImageDates(ctx context.Context, containerID string) (registry.ImageDates, error)
+	// RemoteImageDates looks an image reference up in its registry.
+	RemoteImageDates(ctx context.Context, imageRef string) (registry.ImageDates, error)
+}
+
+// StackUpdater updates every container of a Compose project as one unit.
+type StackUpdater interface {
+	UpdateStack(ctx context.Context, project string) (StackResult, error)
+}
+
+// StackResult reports the outcome of a stack update for the web layer.
+type StackResult struct {
+	Project    string   `json:"project"`
+	Order      []string `json:"order"`
+	Updated    []string `json:"updated,omitempty"`
+	Unchanged  []string `json:"unchanged,omitempty"`
+	RolledBack []string `json:"rolled_back,omitempty"`
+	Failed     string   `json:"failed,omitempty"`
+}
+
 // ContainerRestarter restarts a container by ID.
 type ContainerRestarter interface {
 	RestartContainer(ctx context.Context, id string) error
@@ -605,6 +1075,18 @@ type ContainerStopper interface {
 	StopContainer(ctx context.Context, id string) error
 }
 
+// ContainerPauser pauses and unpauses a container by ID.
+type ContainerPauser interface {
+	PauseContainer(ctx context.Context, id string) error
+	UnpauseContainer(ctx context.Context, id string) error
+}
+
+// ContainerKiller sends a signal to a container by ID. An empty signal
+// sends SIGKILL.
+type ContainerKiller interface {
+	KillContainer(ctx context.Context, id, signal string) error
+}
+
 // ContainerStarter starts a container by ID.
 type ContainerStarter interface {
 	StartContainer(ctx context.Context, id string) error
@@ -632,6 +1114,36 @@ type ImagePruneReport struct {
 	SpaceReclaimed int64 `json:"space_reclaimed"`
 }
 
+// ResourceManager lists Docker networks and volumes and removes orphans.
+type ResourceManager interface {
+	ListNetworks(ctx context.Context) ([]NetworkInfo, error)
+	ListVolumes(ctx context.Context) ([]VolumeInfo, error)
+	RemoveNetwork(ctx context.Context, id string) error
+	RemoveVolume(ctx context.Context, name string) error
+}
+
+// NetworkInfo represents a Docker network for the web layer.
+type NetworkInfo struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Driver     string   `json:"driver"`
+	Scope      string   `json:"scope"`
+	Internal   bool     `json:"internal"`
+	Builtin    bool     `json:"builtin"`
+	Containers []string `json:"containers"`
+	Orphan     bool     `json:"orphan"`
+}
+
+// VolumeInfo represents a Docker volume for the web layer.
+type VolumeInfo struct {
+	Name       string   `json:"name"`
+	Driver     string   `json:"driver"`
+	Mountpoint string   `json:"mountpoint"`
+	CreatedAt  string   `json:"created_at,omitempty"`
+	Containers []string `json:"containers"`
+	Orphan     bool     `json:"orphan"`
+}
+
 // VersionScopeUpdater updates the global version scope at runtime.
 type VersionScopeUpdater interface {
 	SetDefaultScope(scope string)
@@ -657,3 +1169,9 @@ type ConfigWriter interface {
 	SetScanConcurrency(n int)
 	SetMaintenanceWindow(s string)
 }
+
+// LogLevelController reports and overrides per-component log levels.
+type LogLevelController interface {
+	State() logging.LevelState
+	Override(levels map[string]slog.Level, d time.Duration)
+}
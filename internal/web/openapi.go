@@ -0,0 +1,312 @@
+package web
+
+import (
+	"net/http"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/auth"
+)
+
+// routeMux is the server's ServeMux, recording each registration so the
+// OpenAPI document is built from the routes actually served and cannot
+// drift from them. API routes are served under /api/v1 with the
+// unversioned path kept as a deprecated alias (see api_versioning.go).
+type routeMux struct {
+	*http.ServeMux
+	routes     []routeSpec
+	deprecated map[string]apiDeprecation // keyed by versioned pattern
+}
+
+// routeSpec is the metadata recorded for one registered route.
+type routeSpec struct {
+	method     string
+	path       string
+	handler    string          // handler method name, e.g. "apiServiceUpdate"; "" for closures
+	authed     bool            // behind the auth middleware
+	perm       auth.Permission // required permission; "" when any signed-in user may call it
+	deprecated bool
+}
+
+func newRouteMux() *routeMux {
+	return &routeMux{ServeMux: http.NewServeMux()}
+}
+
+// guardedHandler is what the perm and authed helpers in registerRoutes
+// return: the wrapped handler plus the access rule it enforces.
+type guardedHandler struct {
+	http.Handler
+	name string
+	perm auth.Permission
+}
+
+func (m *routeMux) Handle(pattern string, h http.Handler) {
+	spec := newRouteSpec(pattern)
+	switch g := h.(type) {
+	case guardedHandler:
+		spec.handler, spec.authed, spec.perm = g.name, true, g.perm
+	case http.HandlerFunc:
+		spec.handler = handlerName(g)
+	}
+	m.register(spec, h)
+}
+
+func (m *routeMux) HandleFunc(pattern string, f func(http.ResponseWriter, *http.Request)) {
+	spec := newRouteSpec(pattern)
+	spec.handler = handlerName(f)
+	m.register(spec, http.HandlerFunc(f))
+}
+
+func newRouteSpec(pattern string) routeSpec {
+	method, path, ok := strings.Cut(pattern, " ")
+	if !ok {
+		method, path = "", pattern
+	}
+	return routeSpec{method: method, path: path}
+}
+
+// handlerName returns the method name of a handler such as s.apiVersion,
+// or "" for closures and wrapped handlers.
+func handlerName(f any) string {
+	fn := runtime.FuncForPC(reflect.ValueOf(f).Pointer())
+	if fn == nil {
+		return ""
+	}
+	name := strings.TrimSuffix(fn.Name(), "-fm")
+	name = name[strings.LastIndex(name, ".")+1:]
+	if strings.HasPrefix(name, "func") {
+		return ""
+	}
+	return name
+}
+
+// openAPIPath converts a ServeMux path to an OpenAPI path template and
+// returns its parameter names. "{rest...}" becomes "{rest}", "{$}" is
+// dropped and a subtree pattern such as "/static/" gains a "{path}"
+// parameter.
+func openAPIPath(path string) (string, []string) {
+	path = strings.TrimSuffix(path, "{$}")
+	if strings.HasSuffix(path, "/") && path != "/" {
+		path += "{path}"
+	}
+	var params []string
+	segs := strings.Split(path, "/")
+	for i, seg := range segs {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			name := strings.TrimSuffix(strings.TrimSuffix(seg[1:len(seg)-1], "..."), "$")
+			segs[i] = "{" + name + "}"
+			params = append(params, name)
+		}
+	}
+	return strings.Join(segs, "/"), params
+}
+
+// operationID names an operation after its handler ("apiServiceUpdate"
+// becomes "serviceUpdate", "apiTOTPSetup" becomes "totpSetup"), or after
+// its method and path for closures.
+func operationID(r routeSpec) string {
+	words := handlerWords(r.handler)
+	if len(words) == 0 {
+		var b strings.Builder
+		b.WriteString(strings.ToLower(r.method))
+		for _, part := range strings.FieldsFunc(r.path, func(c rune) bool {
+			return !unicode.IsLetter(c) && !unicode.IsDigit(c)
+		}) {
+			b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+		return b.String()
+	}
+	words[0] = strings.ToLower(words[0])
+	return strings.Join(words, "")
+}
+
+// operationSummary turns a handler name into words: "apiServiceUpdate"
+// becomes "Service update" and "apiSyncNPM" becomes "Sync NPM".
+func operationSummary(handler string) string {
+	words := handlerWords(handler)
+	for i, w := range words {
+		if len(w) > 1 && strings.ToUpper(w) == w {
+			continue // acronym
+		}
+		if i == 0 {
+			words[i] = strings.ToUpper(w[:1]) + w[1:]
+		} else {
+			words[i] = strings.ToLower(w)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// handlerWords splits a handler name into its camel-case words, dropping
+// the "api" or "handle" prefix and keeping acronyms such as "TOTP" whole.
+func handlerWords(handler string) []string {
+	name := strings.TrimPrefix(strings.TrimPrefix(handler, "api"), "handle")
+	if name == "" {
+		return nil
+	}
+	var words []string
+	start := 0
+	runes := []rune(name)
+	for i := 1; i < len(runes); i++ {
+		prev, c := runes[i-1], runes[i]
+		nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if unicode.IsUpper(c) && (unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower)) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	return append(words, string(runes[start:]))
+}
+
+// operationTag groups an operation by the first path segment after /api/v1/,
+// or the first segment for dashboard pages and other top-level routes.
+func operationTag(path string) string {
+	rest, ok := strings.CutPrefix(path, apiVersionPrefix+"/")
+	if !ok {
+		rest = strings.TrimPrefix(path, "/api/")
+	}
+	seg, _, _ := strings.Cut(strings.TrimPrefix(rest, "/"), "/")
+	if seg == "" || strings.HasPrefix(seg, "{") {
+		return "pages"
+	}
+	return seg
+}
+
+// openAPIDocument builds an OpenAPI 3 document for the registered routes.
+// Request and response bodies are not described beyond the shared error
+// shape; the document exists so clients can be generated against the full
+// set of paths, parameters and auth requirements.
+func (s *Server) openAPIDocument() map[string]any {
+	codes := make([]string, 0, len(errorCatalogue))
+	for code := range errorCatalogue {
+		codes = append(codes, string(code))
+	}
+	sort.Strings(codes)
+
+	errorResponse := map[string]any{
+		"description": "Error",
+		"content": map[string]any{
+			"application/json": map[string]any{
+				"schema": map[string]any{"$ref": "#/components/schemas/Error"},
+			},
+		},
+	}
+	signedIn := []map[string][]string{{"bearerAuth": {}}, {"cookieAuth": {}}}
+
+	paths := make(map[string]map[string]any)
+	usedIDs := make(map[string]bool)
+	for _, r := range s.mux.routes {
+		path, params := openAPIPath(r.path)
+		id := operationID(r)
+		if usedIDs[id] {
+			// The same handler serves several routes; name the repeats
+			// after their method and path instead.
+			id = operationID(routeSpec{method: r.method, path: r.path})
+		}
+		usedIDs[id] = true
+
+		op := map[string]any{
+			"operationId": id,
+			"summary":     operationSummary(r.handler),
+			"tags":        []string{operationTag(r.path)},
+			"responses": map[string]any{
+				"200":     map[string]any{"description": "Success"},
+				"default": errorResponse,
+			},
+		}
+		if r.handler == "" {
+			op["summary"] = r.method + " " + path
+		}
+		if r.deprecated {
+			op["deprecated"] = true
+		}
+		if len(params) > 0 {
+			var ps []map[string]any
+			for _, p := range params {
+				ps = append(ps, map[string]any{
+					"name":     p,
+					"in":       "path",
+					"required": true,
+					"schema":   map[string]string{"type": "string"},
+				})
+			}
+			op["parameters"] = ps
+		}
+		if r.authed {
+			op["security"] = signedIn
+			if r.perm != "" {
+				op["x-sentinel-permission"] = string(r.perm)
+				op["description"] = "Requires the " + string(r.perm) + " permission."
+			}
+		} else {
+			// Public, or authenticated by the handler itself (webhook
+			// secrets, deploy tokens, login flows).
+			op["security"] = []map[string][]string{}
+		}
+
+		methods := []string{strings.ToLower(r.method)}
+		if r.method == "" {
+			methods = []string{"get", "post", "put", "patch", "delete"}
+		}
+		if paths[path] == nil {
+			paths[path] = make(map[string]any)
+		}
+		for _, m := range methods {
+			paths[path][m] = op
+		}
+	}
+
+	version := s.deps.Version
+	if version == "" {
+		version = "dev"
+	}
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":       "Docker-Sentinel API",
+			"version":     version,
+			"description": "Generated from the routes registered by the Sentinel web server. Every " + apiVersionPrefix + "/ path is also served without the version segment as a deprecated alias; those responses carry Deprecation and Link headers naming the versioned path. Browser sessions must echo the " + auth.CSRFCookieName + " cookie in the " + auth.CSRFHeaderName + " header on state-changing requests; bearer tokens need no CSRF header.",
+		},
+		"security": signedIn,
+		"paths":    paths,
+		"components": map[string]any{
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{
+					"type":        "http",
+					"scheme":      "bearer",
+					"description": "API token (stk_...) created under Account.",
+				},
+				"cookieAuth": map[string]any{
+					"type": "apiKey",
+					"in":   "cookie",
+					"name": auth.SessionCookieName,
+				},
+			},
+			"schemas": map[string]any{
+				"Error": map[string]any{
+					"type":     "object",
+					"required": []string{"error", "code", "message", "retryable"},
+					"properties": map[string]any{
+						"error":     map[string]string{"type": "string"},
+						"code":      map[string]any{"type": "string", "enum": codes},
+						"message":   map[string]string{"type": "string"},
+						"details":   map[string]any{},
+						"retryable": map[string]string{"type": "boolean"},
+					},
+				},
+			},
+		},
+	}
+}
+
+// apiOpenAPI serves the OpenAPI document. It is built on first request,
+// after every route has been registered.
+func (s *Server) apiOpenAPI(w http.ResponseWriter, _ *http.Request) {
+	s.openAPIOnce.Do(func() {
+		s.openAPIDoc = s.openAPIDocument()
+	})
+	writeJSON(w, http.StatusOK, s.openAPIDoc)
+}
@@ -0,0 +1,99 @@
+package web
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOpenAPIDocument(t *testing.T) {
+	srv := NewServer(Dependencies{Log: slog.Default(), Version: "v9.9.9"})
+
+	w := httptest.NewRecorder()
+	srv.apiOpenAPI(w, httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	type operation struct {
+		OperationID string                `json:"operationId"`
+		Security    []map[string][]string `json:"security"`
+		Permission  string                `json:"x-sentinel-permission"`
+		Parameters  []struct {
+			Name string `json:"name"`
+			In   string `json:"in"`
+		} `json:"parameters"`
+	}
+	var doc struct {
+		OpenAPI string `json:"openapi"`
+		Info    struct {
+			Version string `json:"version"`
+		} `json:"info"`
+		Paths      map[string]map[string]operation `json:"paths"`
+		Components struct {
+			SecuritySchemes map[string]any `json:"securitySchemes"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.OpenAPI != "3.0.3" || doc.Info.Version != "v9.9.9" {
+		t.Errorf("openapi = %q, version = %q", doc.OpenAPI, doc.Info.Version)
+	}
+	if doc.Components.SecuritySchemes["bearerAuth"] == nil || doc.Components.SecuritySchemes["cookieAuth"] == nil {
+		t.Errorf("security schemes = %v", doc.Components.SecuritySchemes)
+	}
+
+	// Every registered route is documented, under a unique operation ID.
+	ids := map[string]string{}
+	for _, r := range srv.mux.routes {
+		path, _ := openAPIPath(r.path)
+		op, ok := doc.Paths[path][strings.ToLower(r.method)]
+		if !ok {
+			t.Errorf("%s %s missing from the document", r.method, r.path)
+			continue
+		}
+		if prev, dup := ids[op.OperationID]; dup && prev != r.method+" "+path {
+			t.Errorf("operationId %q used by %s and %s %s", op.OperationID, prev, r.method, path)
+		}
+		ids[op.OperationID] = r.method + " " + path
+	}
+
+	placement := doc.Paths["/api/v1/services/{name}/placement"]["get"]
+	if placement.OperationID != "servicePlacement" || placement.Permission != "containers.view" {
+		t.Errorf("placement operation = %+v", placement)
+	}
+	if len(placement.Parameters) != 1 || placement.Parameters[0].Name != "name" || placement.Parameters[0].In != "path" {
+		t.Errorf("placement parameters = %+v", placement.Parameters)
+	}
+	if len(placement.Security) != 2 {
+		t.Errorf("placement security = %v, want bearer or cookie", placement.Security)
+	}
+	if op := doc.Paths["/healthz"]["get"]; op.Security == nil || len(op.Security) != 0 {
+		t.Errorf("healthz security = %v, want explicitly empty", op.Security)
+	}
+	if _, ok := doc.Paths["/api/v1/openapi.json"]["get"]; !ok {
+		t.Error("document does not describe itself")
+	}
+}
+
+func TestOpenAPIPath(t *testing.T) {
+	tests := []struct {
+		in, want string
+		params   int
+	}{
+		{"/api/containers/{name}/update", "/api/containers/{name}/update", 1},
+		{"/api/hosts/{host}/containers/{name}", "/api/hosts/{host}/containers/{name}", 2},
+		{"/files/{path...}", "/files/{path}", 1},
+		{"/{$}", "/", 0},
+		{"/static/", "/static/{path}", 1},
+	}
+	for _, tt := range tests {
+		got, params := openAPIPath(tt.in)
+		if got != tt.want || len(params) != tt.params {
+			t.Errorf("openAPIPath(%q) = %q, %v; want %q with %d params", tt.in, got, params, tt.want, tt.params)
+		}
+	}
+}
@@ -1,9 +1,14 @@
 package web
 
 import (
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/auth"
 )
 
 // rateLimiter tracks request counts per IP within a sliding window.
@@ -82,3 +87,115 @@ func rateLimit(rl *rateLimiter, next http.HandlerFunc) http.HandlerFunc {
 		next(w, r)
 	}
 }
+
+// apiLimiter is a token-bucket limiter keyed by API token or client IP.
+// Unlike rateLimiter it allows short bursts and refills continuously, which
+// suits dashboards that fire a handful of requests on page load while still
+// stopping scripts that poll in a tight loop.
+type apiLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	rate    float64 // tokens per second
+	burst   float64
+	now     func() time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// newAPILimiter allows perMinute requests per minute per client with bursts
+// of up to burst requests. A burst below 1 defaults to perMinute.
+func newAPILimiter(perMinute, burst int) *apiLimiter {
+	if burst < 1 {
+		burst = perMinute
+	}
+	return &apiLimiter{
+		buckets: make(map[string]*tokenBucket),
+		rate:    float64(perMinute) / 60,
+		burst:   float64(burst),
+		now:     time.Now,
+	}
+}
+
+// take consumes one token for key. It returns whether the request is
+// allowed, the whole tokens left, and how long until the next token when
+// the bucket is empty.
+func (l *apiLimiter) take(key string) (ok bool, remaining int, retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	b, found := l.buckets[key]
+	if !found {
+		b = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens += now.Sub(b.last).Seconds() * l.rate
+	if b.tokens > l.burst {
+		b.tokens = l.burst
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+		return false, 0, wait
+	}
+	b.tokens--
+	return true, int(b.tokens), 0
+}
+
+// cleanup drops buckets that have refilled completely; they carry no state
+// a fresh bucket wouldn't.
+func (l *apiLimiter) cleanup() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// apiRateLimitKey identifies the caller. Requests carrying a valid API token
+// are limited per token, everything else per client IP. The bearer value is
+// checked first so a client can't mint itself a fresh bucket by sending a
+// new made-up token with each request.
+func (s *Server) apiRateLimitKey(r *http.Request) string {
+	if bearer := auth.ExtractBearerToken(r.Header.Get("Authorization")); bearer != "" && s.deps.Auth != nil {
+		if tok := s.deps.Auth.LookupBearerToken(bearer); tok != nil {
+			return "token:" + tok.ID
+		}
+	}
+	return "ip:" + clientIP(r)
+}
+
+// apiRateLimit wraps /api/ routes with the token-bucket limiter. Responses
+// carry X-RateLimit-Limit and X-RateLimit-Remaining; rejected requests get
+// 429 with Retry-After.
+func (s *Server) apiRateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l := s.apiLimiter
+		if l == nil || !strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ok, remaining, retryAfter := l.take(s.apiRateLimitKey(r))
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(int(l.burst)))
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		if !ok {
+			secs := int(math.Ceil(retryAfter.Seconds()))
+			if secs < 1 {
+				secs = 1
+			}
+			w.Header().Set("Retry-After", strconv.Itoa(secs))
+			writeErrorCode(w, http.StatusTooManyRequests, CodeRateLimited, "API rate limit exceeded, try again later",
+				map[string]int{"retry_after_seconds": secs})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,103 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAPILimiterBurstAndRefill(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := newAPILimiter(60, 3) // one token per second, burst of 3
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if ok, _, _ := l.take("ip:1.2.3.4"); !ok {
+			t.Fatalf("request %d rejected within burst", i+1)
+		}
+	}
+	ok, remaining, retry := l.take("ip:1.2.3.4")
+	if ok {
+		t.Fatal("request beyond burst allowed")
+	}
+	if remaining != 0 || retry <= 0 || retry > time.Second {
+		t.Errorf("remaining=%d retry=%s, want 0 and (0,1s]", remaining, retry)
+	}
+
+	// Other clients have their own bucket.
+	if ok, _, _ := l.take("ip:5.6.7.8"); !ok {
+		t.Error("separate client was rate limited")
+	}
+
+	now = now.Add(time.Second)
+	if ok, _, _ := l.take("ip:1.2.3.4"); !ok {
+		t.Error("request rejected after refill")
+	}
+}
+
+func TestAPIRateLimitMiddleware(t *testing.T) {
+	svc, rawToken := newFeedAuthService()
+	srv := &Server{apiLimiter: newAPILimiter(60, 1), deps: Dependencies{Auth: svc}}
+	h := srv.apiRateLimit(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	do := func(path, token string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.RemoteAddr = "10.0.0.1:1234"
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := do("/api/containers", ""); w.Code != http.StatusOK || w.Header().Get("X-RateLimit-Limit") != "1" {
+		t.Fatalf("first request: code=%d limit=%q", w.Code, w.Header().Get("X-RateLimit-Limit"))
+	}
+	w := do("/api/containers", "")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("second request: code=%d, want 429", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("missing Retry-After on 429")
+	}
+
+	// Made-up tokens share the IP's bucket rather than each getting a new one.
+	for _, fake := range []string{"abc", "def"} {
+		if w := do("/api/containers", fake); w.Code != http.StatusTooManyRequests {
+			t.Errorf("unknown token %q: code=%d, want 429", fake, w.Code)
+		}
+	}
+	// A valid token from the same IP is counted separately.
+	if w := do("/api/containers", rawToken); w.Code != http.StatusOK {
+		t.Errorf("token request: code=%d, want 200", w.Code)
+	}
+	if n := len(srv.apiLimiter.buckets); n != 2 {
+		t.Errorf("buckets = %d, want 2 (one IP, one token)", n)
+	}
+	// Non-API paths are never limited.
+	if w := do("/static/app.js", ""); w.Code != http.StatusOK {
+		t.Errorf("static request: code=%d, want 200", w.Code)
+	}
+}
+
+func TestServerShutdownStopsCleanupLoops(t *testing.T) {
+	srv := &Server{done: make(chan struct{})}
+	stopped := make(chan struct{})
+	go func() {
+		srv.every(time.Hour, func() {})
+		close(stopped)
+	}()
+	if err := srv.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("cleanup loop still running after Shutdown")
+	}
+}
@@ -0,0 +1,319 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/auth"
+)
+
+// scopedRoutePrefixes are the routes whose {name}, {container} or {key}
+// path value names a container, local or (with ?host= or a hostID::
+// prefix) remote.
+var scopedRoutePrefixes = []string{
+	"/container/",
+	"/api/containers/",
+	"/api/update/",
+	"/api/check/",
+	"/api/updates/",
+	"/api/hooks/",
+	"/api/deps/",
+	"/api/approve/",
+	"/api/ignore/",
+	"/api/reject/",
+	"/api/queue/",
+	"/service/",
+	"/api/services/",
+}
+
+// requestScope returns the container scope of the request's user, or nil
+// when they may see every container.
+func requestScope(r *http.Request) *auth.ContainerScope {
+	return contextScope(r.Context())
+}
+
+// contextScope is requestScope for code that only has the request context.
+func contextScope(ctx context.Context) *auth.ContainerScope {
+	rc := auth.GetRequestContext(ctx)
+	if rc == nil || rc.Scope.Unrestricted() {
+		return nil
+	}
+	return rc.Scope
+}
+
+// splitContainerKey splits a "hostID::name" key; local keys have no host.
+func splitContainerKey(key string) (hostID, name string) {
+	if h, n, ok := strings.Cut(key, "::"); ok {
+		return h, n
+	}
+	return "", key
+}
+
+// containerKey joins a host ID and container name into a scope key; local
+// containers have no host.
+func containerKey(hostID, name string) string {
+	if hostID == "" {
+		return name
+	}
+	return hostID + "::" + name
+}
+
+// scopeLabels returns the labels of the container a key refers to, or nil
+// when it cannot be found.
+func (s *Server) scopeLabels(ctx context.Context, key string) map[string]string {
+	hostID, name := splitContainerKey(key)
+	if hostID == "" {
+		return s.getContainerLabels(ctx, name)
+	}
+	if s.deps.Cluster != nil && s.deps.Cluster.Enabled() {
+		for _, rc := range s.deps.Cluster.AllHostContainers() {
+			if rc.HostID == hostID && rc.Name == name {
+				return rc.Labels
+			}
+		}
+	}
+	return nil
+}
+
+// inScope reports whether the request's user may see the container a key
+// refers to.
+func (s *Server) inScope(r *http.Request, key string) bool {
+	scope := requestScope(r)
+	if scope == nil {
+		return true
+	}
+	_, name := splitContainerKey(key)
+	return scope.Matches(name, s.scopeLabels(r.Context(), key))
+}
+
+// scopeFilter returns a predicate reporting whether the request's user may
+// see a container, by key. Labels are looked up once, so it suits
+// filtering lists.
+func (s *Server) scopeFilter(r *http.Request) func(key string) bool {
+	scope := requestScope(r)
+	if scope == nil {
+		return func(string) bool { return true }
+	}
+	ctx := r.Context()
+	labels := make(map[string]map[string]string)
+	if containers, err := s.deps.Docker.ListAllContainers(ctx); err == nil {
+		for _, c := range containers {
+			labels[containerName(c)] = c.Labels
+		}
+	}
+	if s.deps.Swarm != nil && s.deps.Swarm.IsSwarmMode() {
+		if services, err := s.deps.Swarm.ListServiceDetail(ctx); err == nil {
+			for _, svc := range services {
+				labels[svc.Name] = svc.Labels
+			}
+		}
+	}
+	if s.deps.Cluster != nil && s.deps.Cluster.Enabled() {
+		for _, rc := range s.deps.Cluster.AllHostContainers() {
+			labels[rc.HostID+"::"+rc.Name] = rc.Labels
+		}
+	}
+	return func(key string) bool {
+		_, name := splitContainerKey(key)
+		return scope.Matches(name, labels[key])
+	}
+}
+
+// scopeContainers answers 404 for requests naming a container outside the
+// user's scope, as though it did not exist. Stack updates are allowed only
+// when every container in the stack is in scope.
+func (s *Server) scopeContainers(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requestScope(r) == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		path := unversionedAPIPath(r.URL.Path)
+		if project := r.PathValue("project"); project != "" && strings.HasPrefix(path, "/api/stacks/") {
+			if !s.stackInScope(r, project) {
+				writeError(w, http.StatusNotFound, "stack not found: "+project)
+				return
+			}
+		}
+		for _, prefix := range scopedRoutePrefixes {
+			if !strings.HasPrefix(path, prefix) {
+				continue
+			}
+			key := r.PathValue("key")
+			if key == "" {
+				name := r.PathValue("name")
+				if name == "" {
+					name = r.PathValue("container")
+				}
+				if name == "" {
+					break
+				}
+				key = hostScopedKey(r, name)
+			}
+			if !s.inScope(r, key) {
+				_, name := splitContainerKey(key)
+				if strings.HasPrefix(path, "/api/") {
+					writeError(w, http.StatusNotFound, "container not found: "+name)
+				} else {
+					http.NotFound(w, r)
+				}
+				return
+			}
+			break
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// stackInScope reports whether every local container of a Compose project
+// is in the request's scope.
+func (s *Server) stackInScope(r *http.Request, project string) bool {
+	scope := requestScope(r)
+	if scope == nil || scope.AllowsStack(project) {
+		return true
+	}
+	containers, err := s.deps.Docker.ListAllContainers(r.Context())
+	if err != nil {
+		return false
+	}
+	found := false
+	for _, c := range containers {
+		if c.Labels["com.docker.compose.project"] != project {
+			continue
+		}
+		if !scope.Matches(containerName(c), c.Labels) {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+// scopedQueue returns the pending updates the request's user may see.
+func (s *Server) scopedQueue(r *http.Request) []PendingUpdate {
+	items := s.deps.Queue.List()
+	if requestScope(r) == nil {
+		return items
+	}
+	allowed := s.scopeFilter(r)
+	var out []PendingUpdate
+	for _, item := range items {
+		if allowed(item.Key()) {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// scopedHistory lists update history the request's user may see, paging
+// through the store until limit records are collected.
+func (s *Server) scopedHistory(r *http.Request, limit int, before string) ([]UpdateRecord, error) {
+	if requestScope(r) == nil {
+		return s.deps.Store.ListHistory(limit, before)
+	}
+	allowed := s.scopeFilter(r)
+	var out []UpdateRecord
+	for len(out) < limit {
+		page, err := s.deps.Store.ListHistory(limit, before)
+		if err != nil {
+			return nil, err
+		}
+		for _, rec := range page {
+			if allowed(containerKey(rec.HostID, rec.ContainerName)) && len(out) < limit {
+				out = append(out, rec)
+			}
+		}
+		if len(page) < limit {
+			break
+		}
+		before = page[len(page)-1].Timestamp.UTC().Format(time.RFC3339Nano)
+	}
+	return out, nil
+}
+
+// scopedHistoryFilter returns a predicate reporting whether the request's
+// user may see a history record, for readers that walk the whole store.
+func (s *Server) scopedHistoryFilter(r *http.Request) func(UpdateRecord) bool {
+	if requestScope(r) == nil {
+		return func(UpdateRecord) bool { return true }
+	}
+	allowed := s.scopeFilter(r)
+	return func(rec UpdateRecord) bool {
+		return allowed(containerKey(rec.HostID, rec.ContainerName))
+	}
+}
+
+// scopedTimeline lists timeline entries the request's user may see, paging
+// through the store until q.Limit entries are collected. Scoped users only
+// see entries about containers in their scope; entries naming no container
+// (settings changes, host events) are left out.
+func (s *Server) scopedTimeline(r *http.Request, q TimelineQuery) ([]TimelineEntry, string, error) {
+	if requestScope(r) == nil {
+		return s.deps.Timeline.ListTimeline(q)
+	}
+	allowed := s.scopeFilter(r)
+	if q.Container != "" && !allowed(containerKey(q.HostID, q.Container)) {
+		return nil, "", nil
+	}
+	var out []TimelineEntry
+	for {
+		page, next, err := s.deps.Timeline.ListTimeline(q)
+		if err != nil {
+			return nil, "", err
+		}
+		for i, e := range page {
+			if e.Container == "" || !allowed(containerKey(e.HostID, e.Container)) {
+				continue
+			}
+			out = append(out, e)
+			if len(out) == q.Limit {
+				if i < len(page)-1 || next != "" {
+					return out, e.Cursor, nil
+				}
+				return out, "", nil
+			}
+		}
+		if next == "" {
+			return out, "", nil
+		}
+		q.Before = next
+	}
+}
+
+// unscopedOnly answers 403 and returns false when the request's user has a
+// container scope. Whole-instance exports, imports, backups and restores
+// cover every container, so only unrestricted users may use them.
+func unscopedOnly(w http.ResponseWriter, r *http.Request) bool {
+	if requestScope(r) == nil {
+		return true
+	}
+	writeError(w, http.StatusForbidden, "not available to users with a container scope")
+	return false
+}
+
+// scopedLogs keeps the activity log entries about containers in the
+// request's scope. Entries naming no container are left out, as in the
+// timeline.
+func (s *Server) scopedLogs(r *http.Request, logs []LogEntry) []LogEntry {
+	allowed := s.scopeFilter(r)
+	var out []LogEntry
+	for _, e := range logs {
+		if e.Container != "" && allowed(e.Container) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// filterNames returns the container names allowed reports as visible.
+func filterNames(names []string, allowed func(string) bool) []string {
+	out := []string{}
+	for _, name := range names {
+		if allowed(name) {
+			out = append(out, name)
+		}
+	}
+	return out
+}
@@ -0,0 +1,446 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"runtime/debug"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/auth"
+	"github.com/Will-Luck/Docker-Sentinel/internal/engine"
+	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+	"github.com/Will-Luck/Docker-Sentinel/internal/metrics"
+	"github.com/Will-Luck/Docker-Sentinel/internal/report"
+	"github.com/Will-Luck/Docker-Sentinel/internal/store"
+)
+
+// scopeTestServer has three local containers: web and api in the "shop"
+// stack, and an unrelated postgres.
+func scopeTestServer() *Server {
+	shop := map[string]string{"com.docker.compose.project": "shop"}
+	return &Server{deps: Dependencies{
+		Docker: &mockContainerLister{containers: []ContainerSummary{
+			{ID: "1", Names: []string{"/web"}, Labels: shop},
+			{ID: "2", Names: []string{"/api"}, Labels: shop},
+			{ID: "3", Names: []string{"/postgres"}},
+		}},
+		Queue: &mockQueue{items: []PendingUpdate{
+			{ContainerName: "web"},
+			{ContainerName: "postgres"},
+		}},
+		Store: newMockHistoryStore(),
+		Log:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}}
+}
+
+// withScope attaches a user with the given container scope to a request.
+func withScope(r *http.Request, scope *auth.ContainerScope) *http.Request {
+	rc := &auth.RequestContext{User: &auth.User{Username: "dev"}, Scope: scope}
+	return r.WithContext(context.WithValue(r.Context(), auth.ContextKey, rc))
+}
+
+func TestScopeContainers(t *testing.T) {
+	s := scopeTestServer()
+	scope := &auth.ContainerScope{Stacks: []string{"shop"}}
+
+	mux := http.NewServeMux()
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	mux.Handle("GET /api/containers/{name}", s.scopeContainers(ok))
+	mux.Handle("POST /api/stacks/{project}/update", s.scopeContainers(ok))
+	mux.Handle("GET /container/{name}", s.scopeContainers(ok))
+	mux.Handle("GET /api/services/{name}/detail", s.scopeContainers(ok))
+	mux.Handle("GET /service/{name}", s.scopeContainers(ok))
+	mux.Handle("PUT /api/queue/{key}/comment", s.scopeContainers(ok))
+	mux.Handle("GET /api/queue/count", s.scopeContainers(ok))
+
+	tests := []struct {
+		url    string
+		scope  *auth.ContainerScope
+		method string
+		want   int
+	}{
+		{"/api/containers/web", scope, http.MethodGet, http.StatusOK},
+		{"/api/containers/postgres", scope, http.MethodGet, http.StatusNotFound},
+		{"/api/containers/postgres", nil, http.MethodGet, http.StatusOK},
+		{"/container/postgres", scope, http.MethodGet, http.StatusNotFound},
+		{"/api/services/web/detail", scope, http.MethodGet, http.StatusOK},
+		{"/api/services/billing/detail", scope, http.MethodGet, http.StatusNotFound},
+		{"/service/billing", scope, http.MethodGet, http.StatusNotFound},
+		{"/api/queue/web/comment", scope, http.MethodPut, http.StatusOK},
+		{"/api/queue/postgres/comment", scope, http.MethodPut, http.StatusNotFound},
+		{"/api/queue/count", scope, http.MethodGet, http.StatusOK},
+		{"/api/stacks/shop/update", scope, http.MethodPost, http.StatusOK},
+		{"/api/stacks/other/update", scope, http.MethodPost, http.StatusNotFound},
+		{"/api/stacks/shop/update", &auth.ContainerScope{Names: []string{"web"}}, http.MethodPost, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, withScope(httptest.NewRequest(tt.method, tt.url, nil), tt.scope))
+		if w.Code != tt.want {
+			t.Errorf("%s %s (scope %+v): status = %d, want %d", tt.method, tt.url, tt.scope, w.Code, tt.want)
+		}
+	}
+}
+
+func TestScopedListings(t *testing.T) {
+	s := scopeTestServer()
+	scope := &auth.ContainerScope{Names: []string{"w*"}}
+
+	queue := s.scopedQueue(withScope(httptest.NewRequest(http.MethodGet, "/api/queue", nil), scope))
+	if len(queue) != 1 || queue[0].ContainerName != "web" {
+		t.Errorf("scoped queue = %+v, want only web", queue)
+	}
+	if all := s.scopedQueue(httptest.NewRequest(http.MethodGet, "/api/queue", nil)); len(all) != 2 {
+		t.Errorf("unscoped queue has %d items, want 2", len(all))
+	}
+
+	w := httptest.NewRecorder()
+	s.apiContainers(w, withScope(httptest.NewRequest(http.MethodGet, "/api/containers", nil), scope))
+	var containers []struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &containers); err != nil {
+		t.Fatalf("decode: %v (body %s)", err, w.Body.String())
+	}
+	if len(containers) != 1 || containers[0].Name != "web" {
+		t.Errorf("containers = %+v, want only web", containers)
+	}
+}
+
+func TestApiSetUserScope(t *testing.T) {
+	users := &feedUserStore{users: map[string]*auth.User{"u1": {ID: "u1", Username: "dev", RoleID: auth.RoleOperatorID}}}
+	s := &Server{deps: Dependencies{
+		Auth:     auth.NewService(auth.ServiceConfig{Users: users, Log: slog.New(slog.NewTextHandler(io.Discard, nil))}),
+		EventLog: &mockEventLogger{},
+		Log:      slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}}
+	set := func(id, body string) int {
+		r := httptest.NewRequest(http.MethodPut, "/api/auth/users/"+id+"/scope", strings.NewReader(body))
+		r.SetPathValue("id", id)
+		w := httptest.NewRecorder()
+		s.apiSetUserScope(w, r)
+		return w.Code
+	}
+
+	if code := set("u1", `{"stacks":["shop"],"names":["web-*"]}`); code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+	if sc := users.users["u1"].ContainerScope; sc == nil || len(sc.Stacks) != 1 || sc.Names[0] != "web-*" {
+		t.Errorf("scope = %+v", sc)
+	}
+
+	if code := set("u1", `{"names":["web-["]}`); code != http.StatusBadRequest {
+		t.Errorf("invalid glob: status = %d, want 400", code)
+	}
+	if code := set("nobody", `{}`); code != http.StatusNotFound {
+		t.Errorf("unknown user: status = %d, want 404", code)
+	}
+
+	if code := set("u1", `{}`); code != http.StatusOK || users.users["u1"].ContainerScope != nil {
+		t.Errorf("clearing: status = %d, scope = %+v", code, users.users["u1"].ContainerScope)
+	}
+}
+
+// TestScopedHistoryReaders checks that readers walking the whole history
+// only return records of containers in the user's scope.
+func TestScopedHistoryReaders(t *testing.T) {
+	s := scopeTestServer()
+	scope := &auth.ContainerScope{Stacks: []string{"shop"}}
+	records := []UpdateRecord{
+		{ContainerName: "web", Outcome: "success", Timestamp: time.Now()},
+		{ContainerName: "postgres", Outcome: "failed", FailureClass: "health", Timestamp: time.Now()},
+	}
+	hs := newMockHistoryStore()
+	hs.records = records
+	s.deps.Store = hs
+
+	get := func(h http.HandlerFunc, url string) string {
+		w := httptest.NewRecorder()
+		h(w, withScope(httptest.NewRequest(http.MethodGet, url, nil), scope))
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s: status = %d, body = %s", url, w.Code, w.Body.String())
+		}
+		return w.Body.String()
+	}
+
+	for _, format := range []string{"json", "csv"} {
+		body := get(s.apiHistoryExport, "/api/history/export?format="+format)
+		if !strings.Contains(body, "web") || strings.Contains(body, "postgres") {
+			t.Errorf("%s export = %s, want only web", format, body)
+		}
+	}
+
+	var stats struct {
+		Failures int `json:"failures"`
+	}
+	if err := json.Unmarshal([]byte(get(s.handleFailureStats, "/api/stats/failures")), &stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats.Failures != 0 {
+		t.Errorf("failures = %d, want 0 (postgres is out of scope)", stats.Failures)
+	}
+
+	s.deps.Timeline = &mockTimelineStore{entries: []TimelineEntry{
+		{Source: "history", Container: "web", Cursor: "c1"},
+		{Source: "history", Container: "postgres", Cursor: "c2"},
+		{Source: "cluster", HostID: "h1", Cursor: "c3"},
+	}}
+	var tl struct {
+		Entries []TimelineEntry `json:"entries"`
+	}
+	if err := json.Unmarshal([]byte(get(s.apiTimeline, "/api/timeline")), &tl); err != nil {
+		t.Fatal(err)
+	}
+	if len(tl.Entries) != 1 || tl.Entries[0].Container != "web" {
+		t.Errorf("timeline = %+v, want only web", tl.Entries)
+	}
+	if body := get(s.apiTimeline, "/api/timeline?container=postgres"); strings.Contains(body, "postgres") {
+		t.Errorf("timeline for out-of-scope container = %s", body)
+	}
+}
+
+func TestHistoryFeedScope(t *testing.T) {
+	const rawToken = "scoped-feed-token"
+	users := &feedUserStore{users: map[string]*auth.User{"u1": {
+		ID: "u1", Username: "dev", RoleID: auth.RoleOperatorID,
+		ContainerScope: &auth.ContainerScope{Names: []string{"web"}},
+	}}}
+	tokens := &feedTokenStore{tokens: map[string]*auth.APIToken{}}
+	_ = tokens.CreateAPIToken(auth.APIToken{ID: "t1", TokenHash: auth.HashToken(rawToken), UserID: "u1"})
+	svc := auth.NewService(auth.ServiceConfig{
+		Users:    users,
+		Sessions: &feedSessionStore{},
+		Roles:    &feedRoleStore{},
+		Tokens:   tokens,
+		Settings: &feedSettingsReader{},
+		Log:      slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+
+	s := scopeTestServer()
+	s.deps.Auth = svc
+	s.deps.Store = &feedHistoryStore{records: []UpdateRecord{
+		{ContainerName: "web", Timestamp: time.Now()},
+		{ContainerName: "postgres", Timestamp: time.Now()},
+	}}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api/history/feed", nil)
+	r.Header.Set("Authorization", "Bearer "+rawToken)
+	s.apiHistoryFeed(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if body := w.Body.String(); !strings.Contains(body, "Updated web") || strings.Contains(body, "postgres") {
+		t.Errorf("feed = %s, want only web", body)
+	}
+}
+
+func TestApiQueueCommentScope(t *testing.T) {
+	s := scopeTestServer()
+	scope := &auth.ContainerScope{Stacks: []string{"shop"}}
+
+	for _, tt := range []struct {
+		key  string
+		want int
+	}{
+		{"web", http.StatusOK},
+		{"postgres", http.StatusNotFound},
+	} {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPut, "/api/queue/"+tt.key+"/comment", strings.NewReader(`{"comment":"hold"}`))
+		r.SetPathValue("key", tt.key)
+		s.apiQueueComment(w, withScope(r, scope))
+		if w.Code != tt.want {
+			t.Errorf("comment on %s: status = %d, want %d", tt.key, w.Code, tt.want)
+		}
+	}
+	if item, _ := s.deps.Queue.Get("postgres"); item.Comment != "" {
+		t.Errorf("out-of-scope entry got comment %q", item.Comment)
+	}
+}
+
+// scopePortainer serves one Portainer endpoint with the given containers.
+type scopePortainer struct {
+	PortainerProvider
+	containers []PortainerContainerInfo
+}
+
+func (p *scopePortainer) Endpoints(context.Context, string) ([]PortainerEndpoint, error) {
+	return []PortainerEndpoint{{ID: 1, Name: "edge"}}, nil
+}
+
+func (p *scopePortainer) AllEndpoints(ctx context.Context, id string) ([]PortainerEndpoint, error) {
+	return p.Endpoints(ctx, id)
+}
+
+func (p *scopePortainer) EndpointContainers(context.Context, string, int) ([]PortainerContainerInfo, error) {
+	return p.containers, nil
+}
+
+func (p *scopePortainer) EndpointEngineID(context.Context, string, int) (string, error) {
+	return "", nil
+}
+
+// scopeImages lists canned images.
+type scopeImages struct {
+	ImageManager
+	images []ImageInfo
+}
+
+func (m *scopeImages) ListImages(context.Context) ([]ImageInfo, error) { return m.images, nil }
+
+// TestScopedGETRoutes walks every authenticated GET route as a user scoped
+// to the "shop" stack and checks that none of them mention secretdb, a
+// container outside it.
+func TestScopedGETRoutes(t *testing.T) {
+	const rawToken = "scoped-token"
+	users := newWebMockUserStore()
+	users.users["u1"] = auth.User{
+		ID: "u1", Username: "dev", RoleID: auth.RoleAdminID,
+		ContainerScope: &auth.ContainerScope{Stacks: []string{"shop"}},
+	}
+	tokens := newWebMockAPITokenStore()
+	tokens.tokens["t1"] = auth.APIToken{ID: "t1", TokenHash: auth.HashToken(rawToken), UserID: "u1"}
+	enabled := true
+	authSvc := auth.NewService(auth.ServiceConfig{
+		Users:          users,
+		Sessions:       newWebMockSessionStore(),
+		Roles:          newWebMockRoleStore(),
+		Tokens:         tokens,
+		Settings:       newWebMockSettingsReader(),
+		Log:            slog.New(slog.NewTextHandler(io.Discard, nil)),
+		SessionExpiry:  time.Hour,
+		AuthEnabledEnv: &enabled,
+	})
+
+	shop := map[string]string{"com.docker.compose.project": "shop"}
+	secret := map[string]string{"com.docker.compose.project": "vault"}
+	history := newMockHistoryStore()
+	history.records = []UpdateRecord{
+		{ContainerName: "web", Outcome: "success", Timestamp: time.Now()},
+		{ContainerName: "secretdb", Outcome: "failed", Timestamp: time.Now()},
+	}
+	cc := NewClusterController()
+	cc.SetProvider(&mockClusterProviderWithContainers{
+		hosts:     []ClusterHost{{ID: "h1", Name: "remote"}},
+		connected: []string{"h1"},
+		containers: []RemoteContainer{
+			{Name: "web", HostID: "h1", HostName: "remote", Labels: shop},
+			{Name: "secretdb", HostID: "h1", HostName: "remote", Labels: secret},
+		},
+	})
+	unmanaged := newMockUnmanagedStore()
+	unmanaged.entries["secretdb"] = UnmanagedEntry{Hidden: true}
+	srv := NewServer(Dependencies{
+		Auth: authSvc,
+		Docker: &mockContainerLister{containers: []ContainerSummary{
+			{ID: "1", Names: []string{"/web"}, Image: "nginx:1", Labels: shop},
+			{ID: "2", Names: []string{"/secretdb"}, Image: "postgres:16", Labels: secret},
+		}},
+		Queue: &mockQueue{items: []PendingUpdate{
+			{ContainerName: "web"},
+			{ContainerName: "secretdb"},
+		}},
+		Store:    history,
+		EventBus: events.New(),
+		EventLog: &mockEventLogger{entries: []LogEntry{
+			{Type: "update", Container: "web", Message: "web updated"},
+			{Type: "update", Container: "secretdb", Message: "secretdb updated"},
+		}},
+		Timeline: &mockTimelineStore{entries: []TimelineEntry{
+			{Source: "log", Container: "web", Message: "web updated"},
+			{Source: "log", Container: "secretdb", Message: "secretdb updated"},
+		}},
+		Config:        &mockConfigReader{defaultPolicy: "manual"},
+		Policy:        newMockPolicyStore(),
+		SettingsStore: newMockSettingsStore(),
+		Retries: &mockRetryStore{states: map[string]RetryState{
+			"secretdb": {Attempts: 2, Failing: true},
+		}},
+		Cluster:   cc,
+		Unmanaged: unmanaged,
+		Versions: mockVersionStore{
+			"web":      {Current: "1"},
+			"secretdb": {Current: "16"},
+		},
+		UpdateLocks: &mockUpdateLocks{active: []engine.ActiveUpdate{
+			{Name: "web", Phase: "pull"},
+			{Name: "secretdb", Phase: "pull"},
+		}},
+		Rollouts: &mockRollouts{rollouts: map[string]store.Rollout{
+			"docker.io/library/postgres@17": {Key: "docker.io/library/postgres@17", Image: "docker.io/library/postgres", Updated: []string{"secretdb"}},
+		}},
+		StackWindows: &mockStackWindowStore{entries: map[string]string{"vault": "secretdb-window"}},
+		Resources: &mockResourceManager{
+			networks: []NetworkInfo{{Name: "shared", Containers: []string{"web", "secretdb"}}},
+			volumes:  []VolumeInfo{{Name: "data", Containers: []string{"secretdb"}}},
+		},
+		Swarm: &mockSwarmProvider{swarmMode: true, services: []ServiceDetail{
+			{ServiceSummary: ServiceSummary{ID: "s1", Name: "web", Labels: shop}},
+			{ServiceSummary: ServiceSummary{ID: "s2", Name: "secretdb", Labels: secret}},
+		}},
+		Portainer: &scopePortainer{containers: []PortainerContainerInfo{
+			{ID: "p1", Name: "web", Labels: shop, EndpointID: 1},
+			{ID: "p2", Name: "secretdb", Labels: secret, EndpointID: 1},
+		}},
+		Digest: &mockDigestController{preview: map[string][]string{"": {"web", "secretdb"}}},
+		Reports: &mockReportService{applied: []report.Entry{
+			{Container: "web", Outcome: "success"},
+			{Container: "secretdb", Outcome: "success"},
+		}},
+		Audit: &mockAuditStore{entries: []AuditEntry{
+			{Method: "POST", Path: "/api/containers/web/policy", Container: "web"},
+			{Method: "POST", Path: "/api/containers/secretdb/policy", Container: "secretdb"},
+		}},
+		NotifyState: &mockNotifyState{prefs: map[string]*NotifyPref{
+			"web":      {Mode: "default"},
+			"secretdb": {Mode: "muted"},
+		}},
+		ImageManager: &scopeImages{images: []ImageInfo{
+			{ID: "i1", RepoTags: []string{"nginx:1"}, InUse: true},
+			{ID: "i2", RepoTags: []string{"postgres:16"}, InUse: true},
+		}},
+		Log: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+
+	params := strings.NewReplacer("{name}", "web", "{container}", "web", "{key}", "web",
+		"{project}", "shop", "{id}", "h1", "{filename}", "backup.db", "{$}", "")
+	for _, route := range srv.mux.routes {
+		// Event streams stay open; the SSE filter has its own test.
+		if route.method != http.MethodGet || !route.authed ||
+			strings.HasSuffix(route.path, "/stream") || unversionedAPIPath(route.path) == "/api/events" {
+			continue
+		}
+		path := route.path
+		if strings.HasPrefix(unversionedAPIPath(path), "/api/portainer/endpoints/") {
+			path = strings.Replace(path, "{id}", "1", 1) // numeric endpoint IDs
+		}
+		path = params.Replace(path)
+		t.Run(path, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, path, nil)
+			r.Header.Set("Authorization", "Bearer "+rawToken)
+			w := httptest.NewRecorder()
+			func() {
+				defer func() {
+					if p := recover(); p != nil {
+						t.Fatalf("handler panicked: %v\n%s", p, debug.Stack())
+					}
+				}()
+				srv.mux.ServeHTTP(w, r)
+			}()
+			if strings.Contains(w.Body.String(), "secretdb") || strings.Contains(w.Body.String(), "postgres:16") {
+				t.Errorf("response mentions an out-of-scope container:\n%s", w.Body.String())
+			}
+			// secretdb's failed update is the only one in history.
+			if unversionedAPIPath(path) == "/api/alerts" && strings.Contains(w.Body.String(), metrics.AlertUpdateFailed) {
+				t.Errorf("alerts count an out-of-scope failure:\n%s", w.Body.String())
+			}
+		})
+	}
+}
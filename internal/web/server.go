@@ -9,8 +9,10 @@ import (
 	"log/slog"
 	"net/http"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-webauthn/webauthn/webauthn"
@@ -38,6 +40,8 @@ type Dependencies struct {
 	Restarter           ContainerRestarter
 	Stopper             ContainerStopper
 	Starter             ContainerStarter
+	Pauser              ContainerPauser
+	Killer              ContainerKiller
 	LogViewer           ContainerLogViewer
 	LogStreamer         ContainerLogStreamer
 	Registry            RegistryVersionChecker
@@ -60,7 +64,30 @@ type Dependencies struct {
 	AboutStore          AboutStore
 	HookStore           HookStore
 	ReleaseSources      ReleaseSourceStore
+	Unmanaged           UnmanagedStore                                       // nil-safe: containers excluded from scans, stats and the dashboard
+	Versions            VersionStore                                         // nil-safe: resolved current/candidate versions per container
+	Validation          ValidationStore                                      // nil-safe: per-container grace period / validation overrides
+	Preconditions       PreconditionStore                                    // nil-safe: per-container wait-for condition overrides
+	Schedules           ScheduleStore                                        // nil-safe: per-container cron schedule overrides
+	StackWindows        StackWindowStore                                     // nil-safe: per-stack maintenance window overrides
+	Pins                PinStore                                             // nil-safe: timed pins that revert on expiry
+	Reports             ReportService                                        // nil-safe: weekly summary report
+	Retries             RetryStore                                           // nil-safe: failed auto-update backoff state
 	ImageManager        ImageManager                                         // nil when not available
+	Resources           ResourceManager                                      // nil-safe: network and volume inventory
+	Stacks              StackUpdater                                         // nil-safe: whole-stack Compose updates
+	Capabilities        CapabilityChecker                                    // nil-safe: pre-flight Docker permission probes
+	ComposeSync         ComposeSyncer                                        // nil-safe: compose file preview/write
+	ComposePaths        ComposePathStore                                     // nil-safe: per-stack compose file mount paths
+	TrustedKeys         TrustedKeyStore                                      // nil-safe: cosign public keys for signature verification
+	VerifyCache         VerifyCache                                          // nil-safe: cached signature verification results
+	DeployTokens        DeployTokenStore                                     // nil-safe: CI deploy hook tokens
+	Timeline            TimelineStore                                        // nil-safe: merged activity timeline
+	UpdateLocks         UpdateLocks                                          // nil-safe: in-flight updates and force unlock
+	Rollouts            RolloutController                                    // nil-safe: staged rollout groups
+	LogLevels           LogLevelController                                   // nil-safe: runtime per-component log levels
+	Audit               AuditStore                                           // nil-safe: audit log of mutating API requests
+	ImageDates          ImageDateProvider                                    // nil-safe: image build and registry push dates
 	Swarm               SwarmProvider                                        // nil when not in Swarm mode
 	Cluster             *ClusterController                                   // thread-safe proxy; always non-nil, use .Enabled() to check
 	Portainer           PortainerProvider                                    // nil when Portainer not configured; set by PortainerInitFunc on first successful test
@@ -69,23 +96,29 @@ type Dependencies struct {
 	NPM                 NPMProvider                                          // nil when NPM not configured; set by NPMInitFunc on first successful test
 	NPMInitFunc         func(ctx context.Context) (NPMProvider, error)       // creates NPM provider from saved settings
 	Backup              BackupManager                                        // nil when backup not configured
+	Restorer            DatabaseRestorer                                     // nil when restore is unavailable
+	Maintainer          DatabaseMaintainer                                   // nil when retention pruning and compaction are unavailable
 	PortConfigs         PortConfigStore                                      // nil when store not available
 	VersionScope        VersionScopeUpdater                                  // nil-safe: updates checker's default scope at runtime
 	MetricsEnabled      bool
 	Auth                *auth.Service
-	Version             string // formatted version string, e.g. "v2.0.1 (abc1234)"
-	ClusterPort         string // gRPC listen port, e.g. "9443"
-	Commit              string // short git commit hash, e.g. "abc1234" or "unknown"
+	Version             string          // formatted version string, e.g. "v2.0.1 (abc1234)"
+	ClusterPort         string          // gRPC listen port, e.g. "9443"
+	Commit              string          // short git commit hash, e.g. "abc1234" or "unknown"
+	BuildDate           string          // RFC 3339 build timestamp or "unknown"
+	SelfUpdated         *SelfUpdateInfo // nil unless this instance replaced the previous one via self-update
+	MinAgentVersion     string          // oldest cluster agent release this server supports
 	Log                 *slog.Logger
 }
 
 // Server is the web dashboard HTTP server.
 type Server struct {
 	deps                 Dependencies
-	mux                  *http.ServeMux
+	mux                  *routeMux
 	tmpl                 *template.Template
 	server               *http.Server
 	startTime            time.Time          // when the server was created
+	bootID               string             // random per-process ID; a change tells the frontend the backend restarted
 	setupDeadline        time.Time          // setup page closes after this; zero = no window
 	webauthn             *webauthn.WebAuthn // nil when WebAuthn is not configured
 	oidcProvider         *auth.OIDCProvider // nil when OIDC is not configured
@@ -93,11 +126,20 @@ type Server struct {
 	tlsCert              string             // path to TLS certificate PEM (empty = plain HTTP)
 	tlsKey               string             // path to TLS private key PEM
 	clusterLifecycle     ClusterLifecycle   // nil until wired by main; enables dynamic start/stop
+	approvalReceiver     ApprovalReceiver   // nil until wired by main; Telegram approve/reject buttons
 	scanGate             chan struct{}      // closed on first dashboard load to unblock the scheduler
 	scanGateOnce         sync.Once
 	pendingRemoteUpdates sync.Map // key: "hostID::name" → struct{}
 	hostAddress          string   // SENTINEL_HOST override for port links; empty = use request host
 	authLimiter          *rateLimiter
+	apiLimiter           *apiLimiter   // nil = API rate limiting disabled
+	done                 chan struct{} // closed on Shutdown; stops background cleanup loops
+	doneOnce             sync.Once
+	sse                  sseTracker
+	sseHeartbeat         time.Duration // 0 = sseHeartbeatInterval
+	openAPIOnce          sync.Once
+	openAPIDoc           map[string]any // built from the registered routes on first request
+	auditPrunedAt        atomic.Int64   // unix seconds of the last audit retention pass
 }
 
 func (s *Server) markRemoteUpdating(hostID, name string) {
@@ -134,6 +176,32 @@ func (s *Server) SetTLS(cert, key string) {
 	s.tlsKey = key
 }
 
+// SetAPIRateLimit enables per-token/per-IP rate limiting on /api/ routes,
+// allowing perMinute requests per minute with bursts of up to burst.
+// perMinute <= 0 leaves limiting disabled. Call before ListenAndServe.
+func (s *Server) SetAPIRateLimit(perMinute, burst int) {
+	if perMinute <= 0 {
+		return
+	}
+	l := newAPILimiter(perMinute, burst)
+	s.apiLimiter = l
+	go s.every(5*time.Minute, l.cleanup)
+}
+
+// every runs fn at each interval until the server shuts down.
+func (s *Server) every(interval time.Duration, fn func()) {
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-tick.C:
+			fn()
+		}
+	}
+}
+
 // SetScanGate sets the channel the server closes on the first dashboard load,
 // unblocking the scheduler's initial scan after fresh setup.
 func (s *Server) SetScanGate(ch chan struct{}) {
@@ -151,6 +219,12 @@ func (s *Server) SetClusterLifecycle(cl ClusterLifecycle) {
 	s.clusterLifecycle = cl
 }
 
+// SetApprovalReceiver wires the receiver for chat approval buttons. It is
+// reloaded whenever notification channels are saved.
+func (s *Server) SetApprovalReceiver(r ApprovalReceiver) {
+	s.approvalReceiver = r
+}
+
 // SetWebAuthn configures WebAuthn support. When wa is nil, passkey routes return 404.
 func (s *Server) SetWebAuthn(wa *webauthn.WebAuthn) {
 	s.webauthn = wa
@@ -163,6 +237,11 @@ func (s *Server) SetOIDCProvider(p *auth.OIDCProvider) {
 	s.oidcProvider = p
 }
 
+// OIDCProvider returns the current OIDC provider, or nil when SSO is off.
+func (s *Server) OIDCProvider() *auth.OIDCProvider {
+	return s.getOIDCProvider()
+}
+
 // getOIDCProvider returns the current OIDC provider (thread-safe).
 func (s *Server) getOIDCProvider() *auth.OIDCProvider {
 	s.oidcMu.RLock()
@@ -179,20 +258,16 @@ func NewServer(deps Dependencies) *Server {
 	}
 	s := &Server{
 		deps:        deps,
-		mux:         http.NewServeMux(),
+		mux:         newRouteMux(),
 		startTime:   time.Now(),
+		bootID:      newBootID(),
 		hostAddress: hostAddr,
 		authLimiter: newRateLimiter(10, time.Minute),
+		done:        make(chan struct{}),
 	}
 
 	// Periodically clean up stale rate-limiter entries to prevent memory growth.
-	go func() {
-		tick := time.NewTicker(5 * time.Minute)
-		defer tick.Stop()
-		for range tick.C {
-			s.authLimiter.cleanup()
-		}
-	}()
+	go s.every(5*time.Minute, s.authLimiter.cleanup)
 
 	s.parseTemplates()
 	s.registerRoutes()
@@ -201,11 +276,13 @@ func NewServer(deps Dependencies) *Server {
 
 // ListenAndServe starts the HTTP server on the given address.
 func (s *Server) ListenAndServe(addr string) error {
-	handler := http.Handler(s.mux)
+	handler := s.auditRequests(s.mux)
 	// Wrap with setup redirect when auth is configured.
 	if s.deps.Auth != nil {
-		handler = s.setupRedirectHandler(s.mux)
+		handler = s.setupRedirectHandler(handler)
 	}
+	handler = s.readOnlyDuringBackup(handler)
+	handler = s.apiRateLimit(handler)
 	handler = securityHeaders(handler)
 	s.server = &http.Server{
 		Addr:         addr,
@@ -222,6 +299,23 @@ func (s *Server) ListenAndServe(addr string) error {
 	return s.server.ListenAndServe()
 }
 
+// readOnlyDuringBackup rejects mutating API requests with 503 while a hot
+// backup holds its snapshot transaction. BoltDB can't grow the file under an
+// open reader, so writes would otherwise stall and surface as 500s. Auth
+// endpoints stay writable so sessions keep working.
+func (s *Server) readOnlyDuringBackup(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.deps.Backup != nil && s.deps.Backup.InProgress() &&
+			r.Method != http.MethodGet && r.Method != http.MethodHead && r.Method != http.MethodOptions &&
+			strings.HasPrefix(r.URL.Path, "/api/") && !strings.HasPrefix(unversionedAPIPath(r.URL.Path), "/api/auth/") {
+			w.Header().Set("Retry-After", "5")
+			writeError(w, http.StatusServiceUnavailable, "read-only while a backup is running, retry shortly")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 // setupRedirectHandler redirects all non-setup requests to /setup when first-run is needed.
 func (s *Server) setupRedirectHandler(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -239,6 +333,9 @@ func (s *Server) setupRedirectHandler(next http.Handler) http.Handler {
 
 // Shutdown gracefully shuts down the HTTP server.
 func (s *Server) Shutdown(ctx context.Context) error {
+	if s.done != nil {
+		s.doneOnce.Do(func() { close(s.done) })
+	}
 	if s.server == nil {
 		return nil
 	}
@@ -276,13 +373,14 @@ func (s *Server) parseTemplates() {
 func (s *Server) registerRoutes() {
 	// Middleware helpers — wraps handlers with auth + CSRF + permission check.
 	authMw := auth.AuthMiddleware(s.deps.Auth)
-	csrfMw := auth.CSRFMiddleware
+	originMw := auth.OriginMiddleware(s.deps.Auth)
+	csrfMw := func(h http.Handler) http.Handler { return originMw(auth.CSRFMiddleware(h)) }
 
 	perm := func(p auth.Permission, h http.HandlerFunc) http.Handler {
-		return authMw(csrfMw(auth.RequirePermission(p)(h)))
+		return guardedHandler{authMw(auditActor(csrfMw(auth.RequirePermission(p)(s.scopeContainers(h))))), handlerName(h), p}
 	}
 	authed := func(h http.HandlerFunc) http.Handler {
-		return authMw(csrfMw(h))
+		return guardedHandler{authMw(auditActor(csrfMw(h))), handlerName(h), ""}
 	}
 
 	// --- Public routes (no auth required) ---
@@ -311,6 +409,10 @@ func (s *Server) registerRoutes() {
 	s.mux.HandleFunc("GET /api/auth/oidc/login", s.apiOIDCLogin)
 	s.mux.HandleFunc("GET /api/auth/oidc/callback", s.apiOIDCCallback)
 	s.mux.HandleFunc("GET /api/auth/oidc/available", s.apiOIDCAvailable)
+	s.mux.HandleFunc("GET /api/auth/diagnostics", s.apiAuthDiagnostics)
+	// Back-channel logout is IdP-to-Sentinel; the signed logout token
+	// replaces session and CSRF checks.
+	s.mux.HandleFunc("POST /api/auth/oidc/backchannel-logout", s.apiOIDCBackchannelLogout)
 	s.mux.HandleFunc("GET /healthz", s.apiHealthz)
 	s.mux.HandleFunc("GET /readyz", s.apiReadyz)
 	s.mux.HandleFunc("GET /api/history/feed", s.apiHistoryFeed)
@@ -333,6 +435,8 @@ func (s *Server) registerRoutes() {
 	s.mux.Handle("POST /api/auth/tokens", authed(s.apiCreateToken))
 	s.mux.Handle("DELETE /api/auth/tokens/{id}", authed(s.apiDeleteToken))
 	s.mux.Handle("GET /api/auth/me", authed(s.apiGetMe))
+	s.mux.Handle("GET /api/version", authed(s.apiVersion))
+	s.mux.Handle("GET /api/openapi.json", authed(s.apiOpenAPI))
 	s.mux.Handle("POST /api/auth/passkeys/register/begin", authed(s.apiPasskeyRegisterBegin))
 	s.mux.Handle("POST /api/auth/passkeys/register/finish", authed(s.apiPasskeyRegisterFinish))
 	s.mux.Handle("GET /api/auth/passkeys", authed(s.apiListPasskeys))
@@ -351,23 +455,44 @@ func (s *Server) registerRoutes() {
 	s.mux.Handle("GET /api/containers", perm(auth.PermContainersView, s.apiContainers))
 	s.mux.Handle("GET /api/containers/{name}", perm(auth.PermContainersView, s.apiContainerDetail))
 	s.mux.Handle("GET /api/containers/{name}/versions", perm(auth.PermContainersView, s.apiContainerVersions))
+	s.mux.Handle("GET /api/containers/{name}/validation", perm(auth.PermContainersView, s.apiGetValidation))
+	s.mux.Handle("GET /api/containers/{name}/preconditions", perm(auth.PermContainersView, s.apiGetPreconditions))
+	s.mux.Handle("GET /api/containers/{name}/schedule", perm(auth.PermContainersView, s.apiGetContainerSchedule))
+	s.mux.Handle("GET /api/stacks/windows", perm(auth.PermContainersView, s.apiListStackWindows))
+	s.mux.Handle("GET /api/stacks/{project}/window", perm(auth.PermContainersView, s.apiGetStackWindow))
+	s.mux.Handle("GET /api/containers/{name}/compose-sync", perm(auth.PermContainersView, s.apiPreviewComposeSync))
+	s.mux.Handle("GET /api/containers/{name}/pin", perm(auth.PermContainersView, s.apiGetPin))
 	s.mux.Handle("GET /api/containers/{name}/tags", perm(auth.PermContainersView, s.apiContainerAllTags))
+	s.mux.Handle("GET /api/containers/{name}/release", perm(auth.PermContainersView, s.apiContainerRelease))
+	s.mux.Handle("GET /api/containers/{name}/image-dates", perm(auth.PermContainersView, s.apiContainerImageDates))
 	s.mux.Handle("GET /api/containers/{name}/row", perm(auth.PermContainersView, s.handleContainerRow))
 	s.mux.Handle("GET /api/containers/{name}/logs", perm(auth.PermContainersView, s.apiContainerLogs))
 	s.mux.Handle("GET /api/containers/{name}/logs/stream", perm(auth.PermContainersView, s.apiContainerLogStream))
 	s.mux.Handle("GET /api/stats", perm(auth.PermContainersView, s.handleDashboardStats))
+	s.mux.Handle("GET /api/unmanaged", perm(auth.PermContainersView, s.apiListUnmanaged))
+	s.mux.Handle("GET /api/versions", perm(auth.PermContainersView, s.apiListVersions))
+	s.mux.Handle("GET /api/retries", perm(auth.PermContainersView, s.apiListRetries))
+	s.mux.Handle("GET /api/rollouts", perm(auth.PermContainersView, s.apiListRollouts))
+	s.mux.Handle("GET /api/updates/active", perm(auth.PermContainersView, s.apiActiveUpdates))
+	s.mux.Handle("GET /api/stats/failures", perm(auth.PermHistoryView, s.handleFailureStats))
 	s.mux.Handle("GET /api/events", perm(auth.PermContainersView, s.apiSSE))
+	s.mux.Handle("GET /api/events/stats", perm(auth.PermSettingsView, s.apiSSEStats))
 	s.mux.Handle("GET /api/queue", perm(auth.PermContainersView, s.apiQueue))
 	s.mux.Handle("GET /api/queue/count", perm(auth.PermContainersView, s.apiQueueCount))
 	s.mux.Handle("GET /api/queue/export", perm(auth.PermContainersView, s.apiQueueExport))
+	s.mux.Handle("PATCH /api/queue/order", perm(auth.PermContainersApprove, s.apiQueueReorder))
+	s.mux.Handle("PUT /api/queue/{key}/comment", perm(auth.PermContainersApprove, s.apiQueueComment))
+	s.mux.Handle("POST /api/queue/approve-all", perm(auth.PermContainersApprove, s.apiApproveAll))
 	s.mux.Handle("GET /api/last-scan", perm(auth.PermContainersView, s.apiLastScan))
 
 	// containers.update
 	s.mux.Handle("POST /api/update/{name}", perm(auth.PermContainersUpdate, s.apiUpdate))
+	s.mux.Handle("POST /api/check/bulk", perm(auth.PermContainersUpdate, s.apiBulkCheck))
 	s.mux.Handle("POST /api/check/{name}", perm(auth.PermContainersUpdate, s.apiCheck))
 	s.mux.Handle("POST /api/scan", perm(auth.PermContainersUpdate, s.apiTriggerScan))
 	s.mux.Handle("POST /api/containers/{name}/switch-ghcr", perm(auth.PermContainersUpdate, s.apiSwitchToGHCR))
 	s.mux.Handle("POST /api/containers/{name}/update-to-version", perm(auth.PermContainersUpdate, s.apiUpdateToVersion))
+	s.mux.Handle("POST /api/stacks/{project}/update", perm(auth.PermContainersUpdate, s.apiUpdateStack))
 
 	// containers.approve — {key} is the queue key: plain name for local, "hostID::name" for remote.
 	s.mux.Handle("POST /api/approve/{key}", perm(auth.PermContainersApprove, s.apiApprove))
@@ -381,28 +506,56 @@ func (s *Server) registerRoutes() {
 	// services are treated as a container-equivalent resource.
 	s.mux.Handle("GET /api/services", perm(auth.PermContainersView, s.apiServicesList))
 	s.mux.Handle("GET /api/services/{name}/detail", perm(auth.PermContainersView, s.apiServiceDetail))
+	s.mux.Handle("GET /api/services/{name}/placement", perm(auth.PermContainersView, s.apiServicePlacement))
 	s.mux.Handle("POST /api/services/{name}/update", perm(auth.PermContainersUpdate, s.apiServiceUpdate))
 	s.mux.Handle("POST /api/services/{name}/rollback", perm(auth.PermContainersRollback, s.apiServiceRollback))
 	s.mux.Handle("POST /api/services/{name}/scale", perm(auth.PermContainersManage, s.apiServiceScale))
 
 	s.mux.Handle("GET /service/{name}", perm(auth.PermContainersView, s.handleServiceDetail))
 
-	// Cluster management (requires settings.modify permission).
+	// Cluster management (cluster.view to see hosts, cluster.manage to change them).
 	// Routes are registered unconditionally — the ClusterController returns
 	// 503 when no provider is active, so the API surface is always consistent.
-	s.mux.Handle("GET /cluster", perm(auth.PermSettingsModify, s.handleCluster))
-	s.mux.Handle("GET /api/cluster/hosts", perm(auth.PermSettingsModify, s.handleClusterHosts))
-	s.mux.Handle("POST /api/cluster/enroll-token", perm(auth.PermSettingsModify, s.handleGenerateEnrollToken))
-	s.mux.Handle("DELETE /api/cluster/hosts/{id}", perm(auth.PermSettingsModify, s.handleRemoveHost))
-	s.mux.Handle("POST /api/cluster/hosts/{id}/revoke", perm(auth.PermSettingsModify, s.handleRevokeHost))
-	s.mux.Handle("POST /api/cluster/hosts/{id}/pause", perm(auth.PermSettingsModify, s.handlePauseHost))
+	s.mux.Handle("GET /cluster", perm(auth.PermClusterView, s.handleCluster))
+	s.mux.Handle("GET /api/cluster/hosts", perm(auth.PermClusterView, s.handleClusterHosts))
+	s.mux.Handle("GET /api/cluster/containers", perm(auth.PermClusterView, s.apiClusterContainers))
+	s.mux.Handle("POST /api/cluster/enroll-token", perm(auth.PermClusterManage, s.handleGenerateEnrollToken))
+	s.mux.Handle("GET /api/cluster/enroll-tokens", perm(auth.PermClusterManage, s.handleListEnrollTokens))
+	s.mux.Handle("DELETE /api/cluster/enroll-tokens/{id}", perm(auth.PermClusterManage, s.handleRevokeEnrollToken))
+	s.mux.Handle("GET /api/cluster/hosts/{id}/events", perm(auth.PermClusterView, s.handleClusterHostEvents))
+	s.mux.Handle("DELETE /api/cluster/hosts/{id}", perm(auth.PermClusterManage, s.handleRemoveHost))
+	s.mux.Handle("POST /api/cluster/hosts/{id}/revoke", perm(auth.PermClusterManage, s.handleRevokeHost))
+	s.mux.Handle("POST /api/cluster/hosts/{id}/pause", perm(auth.PermClusterManage, s.handlePauseHost))
+
+	// system.selfupdate
+	s.mux.Handle("POST /api/self-update", perm(auth.PermSystemSelfUpdate, s.apiSelfUpdate))
 
 	// containers.manage
 	s.mux.Handle("POST /api/containers/{name}/restart", perm(auth.PermContainersManage, s.apiRestart))
 	s.mux.Handle("POST /api/containers/{name}/stop", perm(auth.PermContainersManage, s.apiStop))
 	s.mux.Handle("POST /api/containers/{name}/start", perm(auth.PermContainersManage, s.apiStart))
+	s.mux.Handle("POST /api/containers/{name}/pause", perm(auth.PermContainersManage, s.apiPause))
+	s.mux.Handle("POST /api/containers/{name}/unpause", perm(auth.PermContainersManage, s.apiUnpause))
+	s.mux.Handle("POST /api/containers/{name}/kill", perm(auth.PermContainersManage, s.apiKill))
 	s.mux.Handle("POST /api/containers/{name}/policy", perm(auth.PermContainersManage, s.apiChangePolicy))
 	s.mux.Handle("DELETE /api/containers/{name}/policy", perm(auth.PermContainersManage, s.apiDeletePolicy))
+	s.mux.Handle("PUT /api/containers/{name}/unmanaged", perm(auth.PermContainersManage, s.apiSetUnmanaged))
+	s.mux.Handle("DELETE /api/containers/{name}/unmanaged", perm(auth.PermContainersManage, s.apiDeleteUnmanaged))
+	s.mux.Handle("PUT /api/containers/{name}/validation", perm(auth.PermContainersManage, s.apiSetValidation))
+	s.mux.Handle("DELETE /api/containers/{name}/validation", perm(auth.PermContainersManage, s.apiDeleteValidation))
+	s.mux.Handle("PUT /api/containers/{name}/preconditions", perm(auth.PermContainersManage, s.apiSetPreconditions))
+	s.mux.Handle("DELETE /api/containers/{name}/preconditions", perm(auth.PermContainersManage, s.apiDeletePreconditions))
+	s.mux.Handle("POST /api/containers/{name}/schedule", perm(auth.PermContainersManage, s.apiSetContainerSchedule))
+	s.mux.Handle("POST /api/containers/{name}/compose-sync", perm(auth.PermContainersManage, s.apiComposeSync))
+	s.mux.Handle("DELETE /api/containers/{name}/schedule", perm(auth.PermContainersManage, s.apiDeleteContainerSchedule))
+	s.mux.Handle("POST /api/stacks/{project}/window", perm(auth.PermContainersManage, s.apiSetStackWindow))
+	s.mux.Handle("DELETE /api/stacks/{project}/window", perm(auth.PermContainersManage, s.apiDeleteStackWindow))
+	s.mux.Handle("PUT /api/containers/{name}/pin", perm(auth.PermContainersManage, s.apiSetPin))
+	s.mux.Handle("DELETE /api/containers/{name}/pin", perm(auth.PermContainersManage, s.apiDeletePin))
+	s.mux.Handle("DELETE /api/containers/{name}/retry", perm(auth.PermContainersManage, s.apiClearRetry))
+	s.mux.Handle("POST /api/rollouts/pause", perm(auth.PermContainersApprove, s.apiPauseRollout))
+	s.mux.Handle("POST /api/rollouts/resume", perm(auth.PermContainersApprove, s.apiResumeRollout))
+	s.mux.Handle("POST /api/rollouts/promote", perm(auth.PermContainersApprove, s.apiPromoteRollout))
 	s.mux.Handle("POST /api/bulk/policy", perm(auth.PermContainersManage, s.apiBulkPolicy))
 
 	// settings.view
@@ -411,6 +564,7 @@ func (s *Server) registerRoutes() {
 	s.mux.Handle("GET /api/settings/notifications", perm(auth.PermSettingsView, s.apiGetNotifications))
 	s.mux.Handle("GET /api/settings/notifications/event-types", perm(auth.PermSettingsView, s.apiNotificationEventTypes))
 	s.mux.Handle("GET /api/settings/notifications/templates", perm(auth.PermSettingsView, s.apiGetNotifyTemplates))
+	s.mux.Handle("GET /api/settings/notifications/{id}/template", perm(auth.PermSettingsView, s.apiGetChannelTemplates))
 	s.mux.Handle("GET /api/settings/registries", perm(auth.PermSettingsView, s.apiGetRegistryCredentials))
 	s.mux.Handle("GET /api/release-sources", perm(auth.PermSettingsView, s.apiGetReleaseSources))
 	s.mux.Handle("GET /api/ratelimits", perm(auth.PermContainersView, s.apiGetRateLimits))
@@ -419,11 +573,14 @@ func (s *Server) registerRoutes() {
 	s.mux.Handle("GET /api/containers/{name}/ghcr", perm(auth.PermContainersView, s.apiGetContainerGHCR))
 	s.mux.Handle("GET /api/hooks/{container}", perm(auth.PermSettingsView, s.apiGetHooks))
 	s.mux.Handle("GET /api/deps", perm(auth.PermContainersView, s.apiGetDeps))
+	s.mux.Handle("GET /api/deps/graph", perm(auth.PermContainersView, s.apiGetDepsGraph))
 	s.mux.Handle("GET /api/deps/{container}", perm(auth.PermContainersView, s.apiGetContainerDeps))
 
 	// Notification prefs & digest (read)
 	s.mux.Handle("GET /api/containers/{name}/notify-pref", perm(auth.PermSettingsView, s.apiGetNotifyPref))
 	s.mux.Handle("GET /api/settings/digest", perm(auth.PermSettingsView, s.apiGetDigestSettings))
+	s.mux.Handle("GET /api/settings/digest/channels", perm(auth.PermSettingsView, s.apiGetDigestChannels))
+	s.mux.Handle("GET /api/digest/preview", perm(auth.PermSettingsView, s.apiPreviewDigest))
 	s.mux.Handle("GET /api/settings/container-notify-prefs", perm(auth.PermSettingsView, s.apiGetAllNotifyPrefs))
 	s.mux.Handle("GET /api/digest/banner", perm(auth.PermContainersView, s.apiGetDigestBanner))
 
@@ -431,18 +588,24 @@ func (s *Server) registerRoutes() {
 	s.mux.Handle("POST /api/settings/poll-interval", perm(auth.PermSettingsModify, s.apiSetPollInterval))
 	s.mux.Handle("POST /api/settings/default-policy", perm(auth.PermSettingsModify, s.apiSetDefaultPolicy))
 	s.mux.Handle("POST /api/settings/grace-period", perm(auth.PermSettingsModify, s.apiSetGracePeriod))
+	s.mux.Handle("POST /api/settings/health-timeout", perm(auth.PermSettingsModify, s.apiSetHealthTimeout))
+	s.mux.Handle("POST /api/settings/external-validation", perm(auth.PermSettingsModify, s.apiSetExternalValidation))
+	s.mux.Handle("POST /api/settings/bluegreen-port-range", perm(auth.PermSettingsModify, s.apiSetBlueGreenPortRange))
 	s.mux.Handle("POST /api/settings/pause", perm(auth.PermSettingsModify, s.apiSetPause))
 	s.mux.Handle("POST /api/settings/latest-auto-update", perm(auth.PermSettingsModify, s.apiSetLatestAutoUpdate))
 	s.mux.Handle("POST /api/settings/filters", perm(auth.PermSettingsModify, s.apiSetFilters))
+	s.mux.Handle("POST /api/settings/filters/test", perm(auth.PermSettingsView, s.apiTestFilters))
+	s.mux.Handle("POST /api/settings/image-rewrites", perm(auth.PermSettingsModify, s.apiSetImageRewrites))
 	s.mux.Handle("POST /api/settings/stack-order", perm(auth.PermSettingsModify, s.apiSaveStackOrder))
 	s.mux.Handle("POST /api/settings/dashboard-columns", perm(auth.PermSettingsModify, s.apiSetDashboardColumns))
 	s.mux.Handle("PUT /api/settings/notifications", perm(auth.PermSettingsModify, s.apiSaveNotifications))
 	s.mux.Handle("POST /api/settings/notifications/test", perm(auth.PermSettingsModify, s.apiTestNotification))
 	s.mux.Handle("PUT /api/settings/registries", perm(auth.PermSettingsModify, s.apiSaveRegistryCredentials))
 	s.mux.Handle("PUT /api/release-sources", perm(auth.PermSettingsModify, s.apiSetReleaseSources))
+	s.mux.Handle("POST /api/release-sources", perm(auth.PermSettingsModify, s.apiAddReleaseSource))
+	s.mux.Handle("DELETE /api/release-sources", perm(auth.PermSettingsModify, s.apiDeleteReleaseSource))
 	s.mux.Handle("POST /api/settings/registries/test", perm(auth.PermSettingsModify, s.apiTestRegistryCredential))
 	s.mux.Handle("DELETE /api/settings/registries/{id}", perm(auth.PermSettingsModify, s.apiDeleteRegistryCredential))
-	s.mux.Handle("POST /api/self-update", perm(auth.PermSettingsModify, s.apiSelfUpdate))
 	s.mux.Handle("POST /api/settings/image-cleanup", perm(auth.PermSettingsModify, s.apiSetImageCleanup))
 	s.mux.Handle("POST /api/settings/schedule", perm(auth.PermSettingsModify, s.apiSetSchedule))
 	s.mux.Handle("POST /api/settings/hooks-enabled", perm(auth.PermSettingsModify, s.apiSetHooksEnabled))
@@ -453,30 +616,66 @@ func (s *Server) registerRoutes() {
 	s.mux.Handle("POST /api/settings/dry-run", perm(auth.PermSettingsModify, s.apiSetDryRun))
 	s.mux.Handle("POST /api/settings/pull-only", perm(auth.PermSettingsModify, s.apiSetPullOnly))
 	s.mux.Handle("POST /api/settings/update-delay", perm(auth.PermSettingsModify, s.apiSetUpdateDelay))
+	s.mux.Handle("POST /api/settings/stuck-update-threshold", perm(auth.PermSettingsModify, s.apiSetStuckUpdateThreshold))
+	s.mux.Handle("POST /api/updates/{name}/force-unlock", perm(auth.PermSettingsModify, s.apiForceUnlockUpdate))
+	s.mux.Handle("POST /api/settings/retry-policy", perm(auth.PermSettingsModify, s.apiSetRetryPolicy))
+	s.mux.Handle("POST /api/settings/audit-retention", perm(auth.PermUsersManage, s.apiSetAuditRetention))
+	s.mux.Handle("POST /api/settings/event-sink", perm(auth.PermSettingsModify, s.apiSetEventSink))
+	s.mux.Handle("GET /api/settings/ticketing", perm(auth.PermSettingsView, s.apiGetTicketing))
+	s.mux.Handle("GET /api/settings/report", perm(auth.PermSettingsView, s.apiGetReportSettings))
+	s.mux.Handle("POST /api/settings/report", perm(auth.PermSettingsModify, s.apiSaveReportSettings))
+	s.mux.Handle("POST /api/reports/send", perm(auth.PermSettingsModify, s.apiSendReport))
+	s.mux.Handle("PUT /api/settings/ticketing", perm(auth.PermSettingsModify, s.apiSetTicketing))
 	s.mux.Handle("POST /api/settings/general", perm(auth.PermSettingsModify, s.apiSaveGeneralSetting))
 	s.mux.Handle("POST /api/settings/switch-role", perm(auth.PermSettingsModify, s.apiSwitchRole))
 	s.mux.Handle("POST /api/settings/ha-discovery", perm(auth.PermSettingsModify, s.apiSetHADiscovery))
 	s.mux.Handle("POST /api/settings/compose-sync", perm(auth.PermSettingsModify, s.apiSetComposeSync))
+	s.mux.Handle("POST /api/settings/config-diff", perm(auth.PermSettingsModify, s.apiSetConfigDiff))
+	s.mux.Handle("POST /api/settings/rebuild-watch", perm(auth.PermSettingsModify, s.apiSetRebuildWatch))
+	s.mux.Handle("POST /api/settings/pull-checks", perm(auth.PermSettingsModify, s.apiSetPullChecks))
+	s.mux.Handle("GET /api/compose/paths", perm(auth.PermSettingsView, s.apiListComposePaths))
+	s.mux.Handle("POST /api/compose/paths", perm(auth.PermSettingsModify, s.apiSetComposePath))
+	s.mux.Handle("DELETE /api/compose/paths/{project}", perm(auth.PermSettingsModify, s.apiDeleteComposePath))
 	s.mux.Handle("POST /api/settings/image-backup", perm(auth.PermSettingsModify, s.apiSetImageBackup))
 	s.mux.Handle("POST /api/settings/show-stopped", perm(auth.PermSettingsModify, s.apiSetShowStopped))
+	s.mux.Handle("POST /api/settings/discovery", perm(auth.PermSettingsModify, s.apiSetDiscovery))
 	s.mux.Handle("POST /api/settings/remove-volumes", perm(auth.PermSettingsModify, s.apiSetRemoveVolumes))
 	s.mux.Handle("POST /api/settings/scan-concurrency", perm(auth.PermSettingsModify, s.apiSetScanConcurrency))
+	s.mux.Handle("POST /api/settings/auto-update-cap", perm(auth.PermSettingsModify, s.apiSetAutoUpdateCap))
 	s.mux.Handle("POST /api/settings/notify-batch-window", perm(auth.PermSettingsModify, s.apiSetNotifyBatchWindow))
+	s.mux.Handle("POST /api/settings/external-url", perm(auth.PermSettingsModify, s.apiSetExternalURL))
 	s.mux.Handle("POST /api/settings/maintenance-window", perm(auth.PermSettingsModify, s.apiSetMaintenanceWindow))
+	s.mux.Handle("GET /api/settings/logging", perm(auth.PermSettingsView, s.apiGetLogLevels))
+	s.mux.Handle("PUT /api/settings/logging", perm(auth.PermSettingsModify, s.apiSetLogLevels))
 	s.mux.Handle("POST /api/settings/docker-tls", perm(auth.PermSettingsModify, s.apiSetDockerTLS))
 	s.mux.Handle("POST /api/settings/docker-tls-test", perm(auth.PermSettingsModify, s.apiTestDockerTLS))
 	s.mux.Handle("POST /api/backup/trigger", perm(auth.PermSettingsModify, s.apiBackupTrigger))
 	s.mux.Handle("GET /api/backup/list", perm(auth.PermSettingsModify, s.apiBackupList))
 	s.mux.Handle("GET /api/backup/download/{filename}", perm(auth.PermSettingsModify, s.apiBackupDownload))
+	s.mux.Handle("POST /api/admin/backup", perm(auth.PermSettingsModify, s.apiAdminBackup))
+	s.mux.Handle("GET /api/admin/backup/settings", perm(auth.PermSettingsModify, s.apiGetBackupSettings))
+	s.mux.Handle("POST /api/admin/backup/settings", perm(auth.PermSettingsModify, s.apiSaveBackupSettings))
+	s.mux.Handle("POST /api/admin/restore", perm(auth.PermUsersManage, s.apiAdminRestore))
+	s.mux.Handle("POST /api/admin/compact", perm(auth.PermSettingsModify, s.apiAdminCompact))
+	s.mux.Handle("POST /api/settings/retention", perm(auth.PermSettingsModify, s.apiSetRetention))
 	s.mux.Handle("GET /api/config/export", perm(auth.PermSettingsModify, s.apiConfigExport))
 	s.mux.Handle("POST /api/config/import", perm(auth.PermSettingsModify, s.apiConfigImport))
+	s.mux.Handle("GET /api/state/export", perm(auth.PermSettingsModify, s.apiStateExport))
+	s.mux.Handle("POST /api/state/import", perm(auth.PermSettingsModify, s.apiStateImport))
 	s.mux.Handle("GET /api/grafana-dashboard", perm(auth.PermSettingsModify, s.apiGrafanaDashboard))
+	s.mux.Handle("GET /api/alerts/rules", perm(auth.PermSettingsModify, s.apiAlertRules))
+	s.mux.Handle("GET /api/alerts", perm(auth.PermSettingsView, s.apiAlerts))
 
 	// Scanner & verifier settings
 	s.mux.Handle("GET /api/settings/scanner", perm(auth.PermSettingsView, s.apiScannerSettings))
 	s.mux.Handle("POST /api/settings/scanner", perm(auth.PermSettingsModify, s.apiScannerSettingsSave))
 	s.mux.Handle("GET /api/settings/verifier", perm(auth.PermSettingsView, s.apiVerifierSettings))
 	s.mux.Handle("POST /api/settings/verifier", perm(auth.PermSettingsModify, s.apiVerifierSettingsSave))
+	s.mux.Handle("GET /api/settings/verifier/keys", perm(auth.PermSettingsView, s.apiListTrustedKeys))
+	s.mux.Handle("POST /api/settings/verifier/keys", perm(auth.PermSettingsModify, s.apiAddTrustedKey))
+	s.mux.Handle("DELETE /api/settings/verifier/keys/{name}", perm(auth.PermSettingsModify, s.apiDeleteTrustedKey))
+	s.mux.Handle("GET /api/verify/cache", perm(auth.PermSettingsView, s.apiVerifyCacheStats))
+	s.mux.Handle("DELETE /api/verify/cache", perm(auth.PermSettingsModify, s.apiVerifyCacheInvalidate))
 
 	// Notification retry settings
 	s.mux.Handle("GET /api/settings/notifications/retry", perm(auth.PermSettingsView, s.apiRetrySettings))
@@ -484,8 +683,8 @@ func (s *Server) registerRoutes() {
 
 	// Cluster settings — always available so the admin can enable/configure cluster
 	// even when the cluster server is not yet running.
-	s.mux.Handle("GET /api/settings/cluster", perm(auth.PermSettingsModify, s.apiClusterSettings))
-	s.mux.Handle("POST /api/settings/cluster", perm(auth.PermSettingsModify, s.apiClusterSettingsSave))
+	s.mux.Handle("GET /api/settings/cluster", perm(auth.PermClusterView, s.apiClusterSettings))
+	s.mux.Handle("POST /api/settings/cluster", perm(auth.PermClusterManage, s.apiClusterSettingsSave))
 
 	// Portainer multi-instance.
 	s.mux.Handle("GET /portainer", perm(auth.PermSettingsModify, s.handlePortainer))
@@ -519,13 +718,19 @@ func (s *Server) registerRoutes() {
 	s.mux.Handle("POST /api/containers/{name}/notify-pref", perm(auth.PermSettingsModify, s.apiSetNotifyPref))
 	s.mux.Handle("DELETE /api/notify-states", perm(auth.PermSettingsModify, s.apiClearAllNotifyStates))
 	s.mux.Handle("POST /api/settings/digest", perm(auth.PermSettingsModify, s.apiSaveDigestSettings))
+	s.mux.Handle("PUT /api/settings/digest/channels", perm(auth.PermSettingsModify, s.apiSaveDigestChannels))
+	s.mux.Handle("PUT /api/settings/rollout-groups", perm(auth.PermSettingsModify, s.apiSaveRolloutGroups))
 	s.mux.Handle("POST /api/digest/trigger", perm(auth.PermSettingsModify, s.apiTriggerDigest))
+	s.mux.Handle("POST /api/digest/send-summary", perm(auth.PermSettingsModify, s.apiSendSummary))
 	s.mux.Handle("POST /api/digest/banner/dismiss", perm(auth.PermContainersView, s.apiDismissDigestBanner))
 
 	// Notification templates (write)
 	s.mux.Handle("PUT /api/settings/notifications/templates", perm(auth.PermSettingsModify, s.apiSaveNotifyTemplate))
 	s.mux.Handle("DELETE /api/settings/notifications/templates/{type}", perm(auth.PermSettingsModify, s.apiDeleteNotifyTemplate))
 	s.mux.Handle("POST /api/settings/notifications/templates/preview", perm(auth.PermSettingsModify, s.apiPreviewNotifyTemplate))
+	s.mux.Handle("PUT /api/settings/notifications/{id}/template", perm(auth.PermSettingsModify, s.apiSaveChannelTemplate))
+	s.mux.Handle("DELETE /api/settings/notifications/{id}/template/{type}", perm(auth.PermSettingsModify, s.apiDeleteChannelTemplate))
+	s.mux.Handle("POST /api/settings/notifications/{id}/template/preview", perm(auth.PermSettingsModify, s.apiPreviewChannelTemplate))
 
 	// OIDC settings (admin-only, requires settings.modify)
 	s.mux.Handle("GET /api/settings/oidc", perm(auth.PermSettingsModify, s.apiGetOIDCSettings))
@@ -535,15 +740,22 @@ func (s *Server) registerRoutes() {
 	s.mux.Handle("GET /api/auth/users", perm(auth.PermUsersManage, s.apiListUsers))
 	s.mux.Handle("POST /api/auth/users", perm(auth.PermUsersManage, s.apiCreateUser))
 	s.mux.Handle("DELETE /api/auth/users/{id}", perm(auth.PermUsersManage, s.apiDeleteUser))
+	s.mux.Handle("PUT /api/auth/users/{id}/scope", perm(auth.PermUsersManage, s.apiSetUserScope))
 	s.mux.Handle("POST /api/auth/settings", perm(auth.PermUsersManage, s.apiAuthSettings))
 
-	// Webhook endpoint — uses its own secret-based auth, no session/CSRF required.
+	// Webhook endpoints — use their own secret-based auth, no session/CSRF required.
 	// Always registered; returns 403 when disabled so the route is discoverable.
 	s.mux.HandleFunc("POST /api/webhook", s.apiWebhook)
+	s.mux.HandleFunc("POST /api/webhooks/registry/{token}", s.apiRegistryWebhook)
+	s.mux.HandleFunc("POST /api/hooks/deploy/{container}", s.apiDeployHook)
 
 	// Webhook settings (admin-managed via the settings page).
 	s.mux.Handle("POST /api/settings/webhook-enabled", perm(auth.PermSettingsModify, s.apiSetWebhookEnabled))
 	s.mux.Handle("POST /api/settings/webhook-secret", perm(auth.PermSettingsModify, s.apiGenerateWebhookSecret))
+	s.mux.Handle("POST /api/settings/registry-webhook-token", perm(auth.PermSettingsModify, s.apiGenerateRegistryWebhookToken))
+	s.mux.Handle("GET /api/settings/deploy-tokens", perm(auth.PermSettingsView, s.apiListDeployTokens))
+	s.mux.Handle("POST /api/settings/deploy-tokens", perm(auth.PermSettingsModify, s.apiCreateDeployToken))
+	s.mux.Handle("DELETE /api/settings/deploy-tokens/{id}", perm(auth.PermSettingsModify, s.apiDeleteDeployToken))
 	s.mux.Handle("GET /api/settings/webhook-info", perm(auth.PermSettingsView, s.apiGetWebhookInfo))
 
 	// logs.view
@@ -554,12 +766,26 @@ func (s *Server) registerRoutes() {
 	s.mux.Handle("GET /history", perm(auth.PermHistoryView, s.handleHistory))
 	s.mux.Handle("GET /api/history", perm(auth.PermHistoryView, s.apiHistory))
 	s.mux.Handle("GET /api/history/export", perm(auth.PermHistoryView, s.apiHistoryExport))
+	s.mux.Handle("GET /api/reports", perm(auth.PermHistoryView, s.apiReport))
+	s.mux.Handle("GET /timeline", perm(auth.PermHistoryView, s.handleTimeline))
+	s.mux.Handle("GET /api/timeline", perm(auth.PermHistoryView, s.apiTimeline))
+
+	// Audit log (admin only: it shows every user's actions)
+	s.mux.Handle("GET /api/audit", perm(auth.PermUsersManage, s.apiAudit))
+
+	// Docker permission probes (socket proxy pre-flight)
+	s.mux.Handle("GET /api/capabilities", perm(auth.PermContainersView, s.apiCapabilities))
+	s.mux.Handle("POST /api/capabilities/refresh", perm(auth.PermSettingsModify, s.apiRefreshCapabilities))
 
 	// Images management
 	s.mux.Handle("GET /images", perm(auth.PermContainersView, s.handleImages))
 	s.mux.Handle("GET /api/images", perm(auth.PermContainersView, s.apiListImages))
 	s.mux.Handle("POST /api/images/prune", perm(auth.PermContainersManage, s.apiPruneImages))
 	s.mux.Handle("DELETE /api/images/{id}", perm(auth.PermContainersManage, s.apiRemoveImage))
+	s.mux.Handle("GET /api/networks", perm(auth.PermContainersView, s.apiListNetworks))
+	s.mux.Handle("DELETE /api/networks/{id}", perm(auth.PermContainersManage, s.apiRemoveNetwork))
+	s.mux.Handle("GET /api/volumes", perm(auth.PermContainersView, s.apiListVolumes))
+	s.mux.Handle("DELETE /api/volumes/{name}", perm(auth.PermContainersManage, s.apiRemoveVolume))
 }
 
 func (s *Server) serveCSS(w http.ResponseWriter, r *http.Request) {
@@ -665,20 +891,33 @@ func (s *Server) handleClusterHosts(w http.ResponseWriter, _ *http.Request) {
 	writeJSON(w, http.StatusOK, hosts)
 }
 
-func (s *Server) handleGenerateEnrollToken(w http.ResponseWriter, _ *http.Request) {
+// handleClusterHostEvents returns a host's activity timeline, newest first.
+// ?limit= caps the count (default 100, max 500).
+func (s *Server) handleClusterHostEvents(w http.ResponseWriter, r *http.Request) {
 	if !s.deps.Cluster.Enabled() {
 		writeError(w, http.StatusServiceUnavailable, "cluster not enabled")
 		return
 	}
-	token, id, err := s.deps.Cluster.GenerateEnrollToken()
+	id := r.PathValue("id")
+	if _, ok := s.deps.Cluster.GetHost(id); !ok {
+		writeError(w, http.StatusNotFound, "host not found")
+		return
+	}
+	limit := 100
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 && n <= 500 {
+			limit = n
+		}
+	}
+	evts, err := s.deps.Cluster.HostEvents(id, limit)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	writeJSON(w, http.StatusOK, map[string]string{
-		"token": token,
-		"id":    id,
-	})
+	if evts == nil {
+		evts = []ClusterHostEvent{}
+	}
+	writeJSON(w, http.StatusOK, evts)
 }
 
 func (s *Server) handleRemoveHost(w http.ResponseWriter, r *http.Request) {
@@ -727,9 +966,10 @@ func writeJSON(w http.ResponseWriter, status int, v any) {
 	_ = json.NewEncoder(w).Encode(v)
 }
 
-// writeError writes a JSON error response.
+// writeError writes a structured JSON error response (see APIError); the
+// code is derived from the message or status.
 func writeError(w http.ResponseWriter, status int, msg string) {
-	writeJSON(w, status, map[string]string{"error": msg})
+	writeJSON(w, status, newAPIError(status, msg))
 }
 
 // Template helper functions.
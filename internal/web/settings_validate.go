@@ -0,0 +1,77 @@
+package web
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/engine"
+	"github.com/Will-Luck/Docker-Sentinel/internal/lifecycle"
+	"github.com/robfig/cron/v3"
+)
+
+// validateSetting applies the checks a setting's dedicated handler enforces
+// to a raw value, so settings that arrive another way (config import) are
+// held to the same rules. Keys without extra rules pass; an empty value
+// always passes, as it clears the setting.
+func validateSetting(key, value string) error {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	switch key {
+	case "validation_command":
+		return validateExecutable(value)
+	case "validation_webhook_url", "rebuild_webhook_url":
+		return validateHTTPURL(value)
+	case "event_sink_url":
+		return lifecycle.ValidateConfig(lifecycle.Config{URL: value})
+	case "event_sink_format":
+		return lifecycle.ValidateConfig(lifecycle.Config{Format: value})
+	case "maintenance_window":
+		if _, err := engine.ParseWindow(value); err != nil {
+			return fmt.Errorf("invalid maintenance window: %w", err)
+		}
+	case "pull_check_disk_path":
+		if !filepath.IsAbs(value) {
+			return fmt.Errorf("disk path must be absolute")
+		}
+		if _, err := os.Stat(value); err != nil {
+			return fmt.Errorf("disk path is not visible to Sentinel: %w", err)
+		}
+	case "backup_dir":
+		if !filepath.IsAbs(value) {
+			return fmt.Errorf("backup directory must be an absolute path")
+		}
+	case "backup_schedule":
+		if _, err := cron.ParseStandard(value); err != nil {
+			return fmt.Errorf("invalid cron expression: %w", err)
+		}
+	}
+	return nil
+}
+
+// validateHTTPURL requires an absolute http or https URL.
+func validateHTTPURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%q must be an http or https URL", raw)
+	}
+	return nil
+}
+
+// validateExecutable requires an absolute path to an executable file inside
+// Sentinel's container. Relative names are refused so nothing is resolved
+// through PATH.
+func validateExecutable(path string) error {
+	if !filepath.IsAbs(path) {
+		return fmt.Errorf("command must be an absolute path")
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() || info.Mode()&0o111 == 0 {
+		return fmt.Errorf("command is not an executable file inside Sentinel's container")
+	}
+	return nil
+}
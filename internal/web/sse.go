@@ -4,28 +4,185 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/auth"
+)
+
+const (
+	// sseHeartbeatInterval is how often a comment line is written to the
+	// stream. Reverse proxies close connections that stay idle for too long
+	// (nginx's proxy_read_timeout defaults to 60s), so this stays well below.
+	sseHeartbeatInterval = 15 * time.Second
+
+	// sseWriteTimeout bounds each write. A client that cannot take a write
+	// in this time, because it is gone or a proxy stopped reading, is stale
+	// and its stream is closed.
+	sseWriteTimeout = 10 * time.Second
 )
 
+// sseConn describes one open event stream, for /api/events/stats.
+type sseConn struct {
+	ID          uint64    `json:"id"`
+	RemoteAddr  string    `json:"remote_addr"`
+	User        string    `json:"user,omitempty"`
+	UserAgent   string    `json:"user_agent,omitempty"`
+	Proxy       string    `json:"proxy,omitempty"` // forwarding headers seen, e.g. "X-Forwarded-For"
+	ConnectedAt time.Time `json:"connected_at"`
+	LastWrite   time.Time `json:"last_write"`
+	Events      uint64    `json:"events_sent"`
+	Heartbeats  uint64    `json:"heartbeats_sent"`
+}
+
+// sseTracker keeps the open event streams and lifetime counters. The zero
+// value is ready to use.
+type sseTracker struct {
+	mu    sync.Mutex
+	conns map[uint64]*sseConn
+	next  uint64
+	total uint64 // streams opened since start
+	stale uint64 // streams closed because a write failed or timed out
+}
+
+func (t *sseTracker) add(c *sseConn) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.conns == nil {
+		t.conns = make(map[uint64]*sseConn)
+	}
+	t.next++
+	t.total++
+	c.ID = t.next
+	t.conns[c.ID] = c
+}
+
+func (t *sseTracker) remove(id uint64, stale bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.conns, id)
+	if stale {
+		t.stale++
+	}
+}
+
+// wrote records a successful write: an event, or a heartbeat.
+func (t *sseTracker) wrote(id uint64, at time.Time, heartbeat bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	c, ok := t.conns[id]
+	if !ok {
+		return
+	}
+	c.LastWrite = at
+	if heartbeat {
+		c.Heartbeats++
+	} else {
+		c.Events++
+	}
+}
+
+// snapshot returns copies of the open streams, oldest first, and the counters.
+func (t *sseTracker) snapshot() (conns []sseConn, total, stale uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	conns = make([]sseConn, 0, len(t.conns))
+	for _, c := range t.conns {
+		conns = append(conns, *c)
+	}
+	sort.Slice(conns, func(i, j int) bool { return conns[i].ID < conns[j].ID })
+	return conns, t.total, t.stale
+}
+
+// proxyHeaders names the forwarding headers on r, which show that the stream
+// passes through a reverse proxy that may buffer it.
+func proxyHeaders(r *http.Request) string {
+	var seen string
+	for _, h := range []string{"X-Forwarded-For", "X-Real-Ip", "Forwarded", "Via"} {
+		if r.Header.Get(h) == "" {
+			continue
+		}
+		if seen != "" {
+			seen += ", "
+		}
+		seen += h
+	}
+	return seen
+}
+
 // apiSSE streams server-sent events to the client. The connection stays open
-// until the client disconnects or the server shuts down.
+// until the client disconnects, a write goes stale or the server shuts down.
+// Idle streams get a comment line every heartbeat so proxies keep them open.
+// Scoped users only get events about containers in their scope; events
+// naming no container reach them without a message, which could describe
+// other containers.
 func (s *Server) apiSSE(w http.ResponseWriter, r *http.Request) {
-	flusher, ok := w.(http.Flusher)
-	if !ok {
+	if _, ok := w.(http.Flusher); !ok {
 		writeError(w, http.StatusInternalServerError, "streaming not supported")
 		return
 	}
+	rc := http.NewResponseController(w)
 
 	w.Header().Set("Content-Type", "text/event-stream")
-	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Cache-Control", "no-cache, no-transform")
 	w.Header().Set("Connection", "keep-alive")
 	w.Header().Set("X-Accel-Buffering", "no")
 
 	ch, cancel := s.deps.EventBus.Subscribe()
 	defer cancel()
 
+	conn := &sseConn{
+		RemoteAddr:  clientIP(r),
+		UserAgent:   r.UserAgent(),
+		Proxy:       proxyHeaders(r),
+		ConnectedAt: time.Now(),
+	}
+	if rctx := auth.GetRequestContext(r.Context()); rctx != nil && rctx.User != nil {
+		conn.User = rctx.User.Username
+	}
+	s.sse.add(conn)
+	stale := false
+	defer func() { s.sse.remove(conn.ID, stale) }()
+
+	// write sends one frame. A failed or timed-out write marks the stream
+	// stale; the deadline is best effort, as not every writer supports it.
+	write := func(heartbeat bool, format string, args ...any) bool {
+		_ = rc.SetWriteDeadline(time.Now().Add(sseWriteTimeout))
+		_, err := fmt.Fprintf(w, format, args...)
+		if err == nil {
+			err = rc.Flush()
+		}
+		if err != nil {
+			s.deps.Log.Debug("SSE client stale, closing stream", "remote", conn.RemoteAddr, "error", err)
+			stale = true
+			return false
+		}
+		s.sse.wrote(conn.ID, time.Now(), heartbeat)
+		return true
+	}
+
 	// Send an initial connected event so the client knows the stream is live.
-	fmt.Fprint(w, "event: connected\ndata: {}\n\n")
-	flusher.Flush()
+	// The boot ID lets it notice that the backend restarted in between. The
+	// retry field sets the browser's reconnect delay.
+	if !write(false, "retry: 3000\nevent: connected\ndata: {\"boot_id\":%q}\n\n", s.bootID) {
+		return
+	}
+
+	interval := s.sseHeartbeat
+	if interval <= 0 {
+		interval = sseHeartbeatInterval
+	}
+	heartbeat := time.NewTicker(interval)
+	defer heartbeat.Stop()
+
+	// The scope filter snapshots container labels, so it is rebuilt every
+	// heartbeat to pick up containers created since.
+	scoped := requestScope(r) != nil
+	var allowed func(string) bool
+	if scoped {
+		allowed = s.scopeFilter(r)
+	}
 
 	for {
 		select {
@@ -33,16 +190,55 @@ func (s *Server) apiSSE(w http.ResponseWriter, r *http.Request) {
 			if !ok {
 				return
 			}
+			if scoped {
+				if evt.ContainerName == "" {
+					evt.Message = ""
+				} else if !allowed(containerKey(evt.HostID, evt.ContainerName)) {
+					continue
+				}
+			}
 			data, err := json.Marshal(evt)
 			if err != nil {
 				s.deps.Log.Warn("failed to marshal SSE event", "error", err)
 				continue
 			}
-			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", evt.Type, data)
-			flusher.Flush()
+			if !write(false, "event: %s\ndata: %s\n\n", evt.Type, data) {
+				return
+			}
+
+		case now := <-heartbeat.C:
+			if !write(true, ": heartbeat %d\n\n", now.Unix()) {
+				return
+			}
+			if scoped {
+				allowed = s.scopeFilter(r)
+			}
 
 		case <-r.Context().Done():
 			return
 		}
 	}
 }
+
+// apiSSEStats reports the open event streams and how streams have ended,
+// to diagnose proxies that buffer or cut them off.
+func (s *Server) apiSSEStats(w http.ResponseWriter, _ *http.Request) {
+	conns, total, stale := s.sse.snapshot()
+	interval := s.sseHeartbeat
+	if interval <= 0 {
+		interval = sseHeartbeatInterval
+	}
+	var dropped uint64
+	if s.deps.EventBus != nil {
+		dropped = s.deps.EventBus.Dropped()
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"active":             len(conns),
+		"total_connections":  total,
+		"stale_disconnects":  stale,
+		"dropped_events":     dropped,
+		"heartbeat_interval": interval.String(),
+		"write_timeout":      sseWriteTimeout.String(),
+		"connections":        conns,
+	})
+}
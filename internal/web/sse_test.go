@@ -0,0 +1,130 @@
+package web
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/auth"
+	"github.com/Will-Luck/Docker-Sentinel/internal/events"
+)
+
+func TestApiSSEHeartbeatAndStats(t *testing.T) {
+	bus := events.New()
+	srv := &Server{
+		deps:         Dependencies{EventBus: bus, Log: slog.New(slog.NewTextHandler(io.Discard, nil))},
+		bootID:       "boot-1",
+		sseHeartbeat: 10 * time.Millisecond,
+	}
+	ts := httptest.NewServer(http.HandlerFunc(srv.apiSSE))
+	defer ts.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+	req.Header.Set("X-Forwarded-For", "203.0.113.9")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resp.Header.Get("X-Accel-Buffering"); got != "no" {
+		t.Errorf("X-Accel-Buffering = %q, want no", got)
+	}
+	lines := bufio.NewScanner(resp.Body)
+	readUntil := func(prefix string) string {
+		t.Helper()
+		for lines.Scan() {
+			if strings.HasPrefix(lines.Text(), prefix) {
+				return lines.Text()
+			}
+		}
+		t.Fatalf("stream ended before a line starting %q", prefix)
+		return ""
+	}
+
+	if got := readUntil("data:"); got != `data: {"boot_id":"boot-1"}` {
+		t.Errorf("connected data = %q", got)
+	}
+	readUntil(": heartbeat ")
+
+	bus.Publish(events.SSEEvent{Type: events.EventQueueChange, ContainerName: "web"})
+	if got := readUntil("event:"); got != "event: queue_change" {
+		t.Errorf("event line = %q, want queue_change", got)
+	}
+	readUntil("data:")
+
+	w := httptest.NewRecorder()
+	srv.apiSSEStats(w, httptest.NewRequest(http.MethodGet, "/api/events/stats", nil))
+	var stats struct {
+		Active      int       `json:"active"`
+		Total       uint64    `json:"total_connections"`
+		Connections []sseConn `json:"connections"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats.Active != 1 || stats.Total != 1 || len(stats.Connections) != 1 {
+		t.Fatalf("stats = %+v, want one open stream", stats)
+	}
+	c := stats.Connections[0]
+	if c.Events != 2 || c.Heartbeats < 1 || c.Proxy != "X-Forwarded-For" || c.LastWrite.IsZero() {
+		t.Errorf("connection = %+v, want 2 events, heartbeats and a proxy header", c)
+	}
+
+	// Closing the client ends the stream and drops it from the stats.
+	resp.Body.Close()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		conns, _, _ := srv.sse.snapshot()
+		if len(conns) == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("stream still tracked after the client closed: %+v", conns)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestApiSSEScope(t *testing.T) {
+	srv := scopeTestServer()
+	srv.deps.EventBus = events.New()
+	scope := &auth.ContainerScope{Stacks: []string{"shop"}}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		srv.apiSSE(w, withScope(r, scope))
+	}))
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	lines := bufio.NewScanner(resp.Body)
+	nextData := func() string {
+		t.Helper()
+		for lines.Scan() {
+			if strings.HasPrefix(lines.Text(), "data:") {
+				return lines.Text()
+			}
+		}
+		t.Fatal("stream ended")
+		return ""
+	}
+	nextData() // connected
+
+	srv.deps.EventBus.Publish(events.SSEEvent{Type: events.EventContainerUpdate, ContainerName: "postgres", Message: "postgres updated"})
+	srv.deps.EventBus.Publish(events.SSEEvent{Type: events.EventContainerUpdate, ContainerName: "web", Message: "web updated"})
+	srv.deps.EventBus.Publish(events.SSEEvent{Type: events.EventScanComplete, Message: "postgres has an update"})
+
+	if got := nextData(); !strings.Contains(got, `"container_name":"web"`) {
+		t.Errorf("first event = %s, want web's update", got)
+	}
+	if got := nextData(); !strings.Contains(got, `"scan_complete"`) || strings.Contains(got, "postgres") {
+		t.Errorf("second event = %s, want scan_complete without its message", got)
+	}
+}
@@ -363,6 +363,31 @@
       showToast("Network error \u2014 could not resume scanning", "error");
     });
   }
+  function refreshCapabilities(event) {
+    var btn = event && event.target ? event.target.closest(".btn") : null;
+    if (btn) btn.disabled = true;
+    fetch("/api/capabilities/refresh", { method: "POST" }).then(function(resp) {
+      return resp.json().then(function(data) {
+        return { ok: resp.ok, data };
+      });
+    }).then(function(result) {
+      if (!result.ok) {
+        showToast(result.data.error || "Failed to re-check permissions", "error");
+        if (btn) btn.disabled = false;
+        return;
+      }
+      if (result.data.update_blocked) {
+        showToast(result.data.update_blocked, "warning");
+        if (btn) btn.disabled = false;
+        return;
+      }
+      showToast("Docker permissions OK", "success");
+      window.location.reload();
+    }).catch(function() {
+      showToast("Network error \u2014 could not re-check permissions", "error");
+      if (btn) btn.disabled = false;
+    });
+  }
   function checkPauseState() {
     var banner = document.getElementById("pause-banner");
     if (!banner) return;
@@ -1345,6 +1370,25 @@
       btn
     );
   }
+  function killContainer(btn) {
+    var name = window._containerName || (typeof _containerName !== "undefined" ? _containerName : "");
+    var hostId = window._containerHostId || (typeof _containerHostId !== "undefined" ? _containerHostId : "");
+    if (!name) return;
+    var select = document.getElementById("kill-signal");
+    var signal = select ? select.value : "SIGKILL";
+    var endpoint = "/api/containers/" + encodeURIComponent(name) + "/kill";
+    if (hostId) endpoint += "?host=" + encodeURIComponent(hostId);
+    showConfirm("Kill " + name, "<p>Send <strong>" + escapeHTML(signal) + "</strong> to " + escapeHTML(name) + "?</p>").then(function(confirmed) {
+      if (!confirmed) return;
+      apiPost(
+        endpoint,
+        { signal },
+        signal + " sent",
+        "Failed to kill " + name,
+        btn
+      );
+    });
+  }
   function togglePorts(el, e) {
     e.stopPropagation();
     el.closest(".cell-ports").classList.toggle("expanded");
@@ -1434,6 +1478,61 @@
     document.body.appendChild(overlay);
     _dashboardShortcutsVisible = true;
   }
+  var _unmanagedItems = [];
+  function _unmanagedURL(item) {
+    var url = "/api/containers/" + encodeURIComponent(item.name) + "/unmanaged";
+    if (item.host_id) url += "?host=" + encodeURIComponent(item.host_id);
+    return url;
+  }
+  function loadUnmanaged() {
+    var section = document.getElementById("unmanaged-section");
+    if (!section) return;
+    fetch("/api/unmanaged", { credentials: "same-origin" }).then(function(res) {
+      return res.ok ? res.json() : [];
+    }).then(function(items) {
+      _unmanagedItems = items || [];
+      var html = "";
+      for (var i = 0; i < _unmanagedItems.length; i++) {
+        var item = _unmanagedItems[i];
+        html += '<tr data-index="' + i + '"><td class="mono">' + escapeHTML(item.name) + "</td><td>" + (item.host_id ? escapeHTML(item.host_id) : "local") + "</td><td>" + escapeHTML(new Date(item.since).toLocaleString()) + "</td><td>" + (item.hidden ? '<span class="badge badge-muted">hidden</span>' : '<span class="badge badge-info">shown</span>') + '</td><td class="unmanaged-actions">' + (item.hidden ? '<button class="btn btn-sm" data-unmanaged-action="unhide">Unhide</button>' : "") + '<button class="btn btn-sm btn-success" data-unmanaged-action="manage">Manage</button></td></tr>';
+      }
+      document.getElementById("unmanaged-body").innerHTML = html;
+      document.getElementById("unmanaged-count").textContent = _unmanagedItems.length;
+      section.style.display = _unmanagedItems.length ? "" : "none";
+    }).catch(function() {
+    });
+  }
+  function unmanagedAction(item, action, btn) {
+    var opts = {
+      triggerEl: btn,
+      errorMsg: "Failed to update " + item.name,
+      onSuccess: function() {
+        window.location.reload();
+      }
+    };
+    if (action === "unhide") {
+      opts.method = "PUT";
+      opts.body = { hidden: false };
+      opts.successMsg = item.name + " shown on the dashboard";
+    } else {
+      opts.method = "DELETE";
+      opts.successMsg = item.name + " returned to management";
+    }
+    apiFetch(_unmanagedURL(item), opts).catch(function() {
+    });
+  }
+  function initUnmanagedSection() {
+    var section = document.getElementById("unmanaged-section");
+    if (!section) return;
+    section.addEventListener("click", function(e) {
+      var btn = e.target.closest("[data-unmanaged-action]");
+      if (!btn) return;
+      var row = btn.closest("tr");
+      var item = _unmanagedItems[parseInt(row.getAttribute("data-index"), 10)];
+      if (item) unmanagedAction(item, btn.getAttribute("data-unmanaged-action"), btn);
+    });
+    loadUnmanaged();
+  }
 
   // internal/web/static/src/js/queue.js
   function _updateQueueBadge() {
@@ -1498,11 +1597,37 @@
     var chevron = row ? row.querySelector(".queue-expand") : null;
     if (chevron) chevron.textContent = visible ? "\u25B8" : "\u25BE";
   }
+  function queueCommentBody(key) {
+    var input = document.querySelector('.queue-comment-input[data-queue-key="' + CSS.escape(key) + '"]');
+    var comment = input ? input.value.trim() : "";
+    return comment ? { comment: comment } : null;
+  }
+  function queueApproveBody(key) {
+    var body = queueCommentBody(key);
+    var select = document.querySelector('.queue-version-select[data-queue-key="' + CSS.escape(key) + '"]');
+    if (select && select.selectedIndex > 0) {
+      body = body || {};
+      body.version = select.value;
+    }
+    return body;
+  }
+  function saveQueueComment(key, event) {
+    var btn = event && event.target ? event.target.closest(".btn") : null;
+    var body = queueCommentBody(key) || { comment: "" };
+    apiFetch("/api/queue/" + encodeURIComponent(key) + "/comment", {
+      method: "PUT",
+      body: body,
+      triggerEl: btn,
+      successMsg: body.comment ? "Comment saved for " + key : "Comment cleared for " + key,
+      errorMsg: "Failed to save comment"
+    }).catch(function() {
+    });
+  }
   function approveUpdate(key, event) {
     var btn = event && event.target ? event.target.closest(".btn") : null;
     apiPost2(
       "/api/approve/" + encodeURIComponent(key),
-      null,
+      queueApproveBody(key),
       "Approved update for " + key,
       "Failed to approve",
       btn,
@@ -1515,7 +1640,7 @@
     var btn = event && event.target ? event.target.closest(".btn") : null;
     apiPost2(
       "/api/ignore/" + encodeURIComponent(key),
-      null,
+      queueCommentBody(key),
       "Version ignored for " + key,
       "Failed to ignore version",
       btn,
@@ -1528,7 +1653,7 @@
     var btn = event && event.target ? event.target.closest(".btn") : null;
     apiPost2(
       "/api/reject/" + encodeURIComponent(key),
-      null,
+      queueCommentBody(key),
       "Rejected update for " + key,
       "Failed to reject",
       btn,
@@ -1538,18 +1663,9 @@
     );
   }
   var _bulkInProgress = false;
-  function bulkQueueAction(apiPath, actionLabel, triggerBtn, skipSelf) {
-    var allRows = document.querySelectorAll(".table-wrap tbody tr.container-row[data-queue-key]");
-    if (!allRows.length) return;
-    var rows = [];
-    for (var s = 0; s < allRows.length; s++) {
-      if (skipSelf && allRows[s].getAttribute("data-self") === "true") continue;
-      rows.push(allRows[s]);
-    }
-    if (!rows.length) {
-      showToast("No eligible containers for " + actionLabel + " (Sentinel skipped)", "info");
-      return;
-    }
+  function bulkQueueAction(apiPath, actionLabel, triggerBtn) {
+    var rows = document.querySelectorAll(".table-wrap tbody tr.container-row[data-queue-key]");
+    if (!rows.length) return;
     _bulkInProgress = true;
     var headerBtns = document.querySelectorAll(".queue-header .btn");
     for (var i = 0; i < headerBtns.length; i++) headerBtns[i].disabled = true;
@@ -1638,7 +1754,38 @@
   }
   function approveAll(event) {
     var btn = event && event.target ? event.target.closest(".btn") : null;
-    bulkQueueAction("approve", "approved", btn, true);
+    var rows = document.querySelectorAll(".table-wrap tbody tr.container-row[data-queue-key]");
+    if (!rows.length) return;
+    var keys = [];
+    for (var i = 0; i < rows.length; i++) {
+      if (rows[i].getAttribute("data-self") === "true") continue;
+      var key = rows[i].getAttribute("data-queue-key");
+      if (key) keys.push(key);
+    }
+    if (!keys.length) {
+      showToast("No eligible containers for approval (Sentinel skipped)", "info");
+      return;
+    }
+    _bulkInProgress = true;
+    apiFetch("/api/queue/approve-all", {
+      method: "POST",
+      body: { keys: keys },
+      triggerEl: btn,
+      errorMsg: "Failed to approve updates",
+      onSuccess: function(data) {
+        var order = data.order || [];
+        showToast("Approved " + order.length + " updates \u2014 running one at a time in queue order", "success");
+        for (var j = 0; j < order.length; j++) {
+          var row = document.querySelector('tr[data-queue-key="' + CSS.escape(order[j]) + '"]');
+          if (row) removeQueueRow(row.querySelector(".btn") || { closest: function() {
+            return row;
+          } });
+        }
+      }
+    }).catch(function() {
+    }).then(function() {
+      _bulkInProgress = false;
+    });
   }
   function ignoreAll(event) {
     var btn = event && event.target ? event.target.closest(".btn") : null;
@@ -1673,6 +1820,47 @@
       "Failed to trigger update"
     );
   }
+  function triggerStackUpdate(project, event) {
+    var btn = event && event.target ? event.target.closest(".btn") : null;
+    showConfirm(
+      "Update Stack",
+      "<p>Update every container in <strong>" + escapeHTML(project) + "</strong> as one unit?</p><p>All images are pulled first, then containers are recreated in dependency order. If any container fails, the whole stack is rolled back.</p>",
+      { confirmLabel: "Update Stack" }
+    ).then(function(confirmed) {
+      if (!confirmed) return;
+      apiFetch("/api/stacks/" + encodeURIComponent(project) + "/update", {
+        method: "POST",
+        triggerEl: btn,
+        successMsg: "Stack update started for " + project,
+        errorMsg: "Failed to start stack update"
+      });
+    });
+  }
+  function triggerComposeSync(name, event) {
+    var btn = event && event.target ? event.target.closest(".btn") : null;
+    var url = "/api/containers/" + encodeURIComponent(name) + "/compose-sync";
+    apiFetch(url, { triggerEl: btn, errorMsg: "Failed to preview compose file" }).then(function(data) {
+      if (!data || !data.edit) return;
+      if (!data.changed) {
+        showToast("Compose file unchanged \u2014 " + (data.edit.reason || "nothing to update"), "info");
+        return;
+      }
+      showConfirm(
+        "Sync Compose File",
+        "<p>Write the running image tag into <code>" + escapeHTML(data.edit.path) + '</code>? A <code>.bak</code> copy is kept.</p><pre class="mono" style="overflow-x:auto">' + escapeHTML(data.edit.diff) + "</pre>",
+        { confirmLabel: "Write File" }
+      ).then(function(confirmed) {
+        if (!confirmed) return;
+        apiFetch(url, {
+          method: "POST",
+          triggerEl: btn,
+          successMsg: "Compose file updated for " + name,
+          errorMsg: "Failed to write compose file"
+        });
+      });
+    }).catch(function() {
+    });
+  }
   function triggerCheck(name, event, hostId) {
     var btn = event && event.target ? event.target.closest(".btn") : null;
     var url = "/api/check/" + encodeURIComponent(name);
@@ -1903,6 +2091,108 @@
   function getBulkInProgress() {
     return _bulkInProgress;
   }
+  var _dragRow = null;
+  function _moveQueueRow(row, before) {
+    var accordion = row.nextElementSibling;
+    var tbody = row.parentNode;
+    tbody.insertBefore(row, before);
+    if (accordion && accordion.classList.contains("accordion-panel")) {
+      tbody.insertBefore(accordion, before);
+    }
+  }
+  function _queueRowOrder() {
+    var rows = _getQueueRows();
+    var keys = [];
+    for (var i = 0; i < rows.length; i++) {
+      var key = rows[i].getAttribute("data-queue-key");
+      if (key) keys.push(key);
+    }
+    return keys;
+  }
+  function saveQueueOrder(previous) {
+    var keys = _queueRowOrder();
+    apiFetch("/api/queue/order", {
+      method: "PATCH",
+      body: { keys: keys },
+      errorMsg: "Failed to save queue order",
+      onError: function() {
+        var tbody = document.querySelector(".queue-table tbody");
+        if (!tbody) return;
+        for (var i = 0; i < previous.length; i++) {
+          var row = tbody.querySelector('tr[data-queue-key="' + CSS.escape(previous[i]) + '"]');
+          if (row) _moveQueueRow(row, null);
+        }
+      }
+    }).catch(function() {
+    });
+  }
+  function moveQueueRow(row, delta) {
+    var rows = Array.prototype.slice.call(_getQueueRows());
+    var idx = rows.indexOf(row);
+    var target = idx + delta;
+    if (idx < 0 || target < 0 || target >= rows.length) return false;
+    var previous = _queueRowOrder();
+    if (delta < 0) {
+      _moveQueueRow(row, rows[target]);
+    } else {
+      var after = rows[target].nextElementSibling;
+      if (after && after.classList.contains("accordion-panel")) after = after.nextElementSibling;
+      _moveQueueRow(row, after);
+    }
+    saveQueueOrder(previous);
+    return true;
+  }
+  function _clearQueueDragClasses() {
+    var all = document.querySelectorAll(".queue-table .drag-over-above, .queue-table .drag-over-below");
+    for (var i = 0; i < all.length; i++) {
+      all[i].classList.remove("drag-over-above", "drag-over-below");
+    }
+  }
+  function initQueueReorder() {
+    var table = document.querySelector(".queue-table");
+    if (!table) return;
+    table.addEventListener("dragstart", function(e) {
+      if (!e.target.closest || !e.target.closest(".queue-drag-handle")) return;
+      _dragRow = e.target.closest("tr.container-row");
+      if (!_dragRow) return;
+      _dragRow.classList.add("dragging");
+      e.dataTransfer.effectAllowed = "move";
+      e.dataTransfer.setData("text/plain", _dragRow.getAttribute("data-queue-key"));
+      e.dataTransfer.setDragImage(_dragRow, 0, 0);
+    });
+    table.addEventListener("dragover", function(e) {
+      var row = e.target.closest("tr.container-row");
+      if (!row || !_dragRow || row === _dragRow) return;
+      e.preventDefault();
+      e.dataTransfer.dropEffect = "move";
+      var rect = row.getBoundingClientRect();
+      _clearQueueDragClasses();
+      row.classList.add(e.clientY < rect.top + rect.height / 2 ? "drag-over-above" : "drag-over-below");
+    });
+    table.addEventListener("drop", function(e) {
+      var row = e.target.closest("tr.container-row");
+      if (!row || !_dragRow || row === _dragRow) return;
+      e.preventDefault();
+      var previous = _queueRowOrder();
+      var rect = row.getBoundingClientRect();
+      if (e.clientY < rect.top + rect.height / 2) {
+        _moveQueueRow(_dragRow, row);
+      } else {
+        var after = row.nextElementSibling;
+        if (after && after.classList.contains("accordion-panel")) after = after.nextElementSibling;
+        _moveQueueRow(_dragRow, after);
+      }
+      _clearQueueDragClasses();
+      _dragRow.classList.remove("dragging");
+      _dragRow = null;
+      saveQueueOrder(previous);
+    });
+    table.addEventListener("dragend", function() {
+      if (_dragRow) _dragRow.classList.remove("dragging");
+      _dragRow = null;
+      _clearQueueDragClasses();
+    });
+  }
   var _kbFocusIndex = -1;
   var _kbHandler = null;
   var _shortcutsOverlayVisible = false;
@@ -1935,6 +2225,7 @@
     var shortcuts = [
       ["j", "Next row"],
       ["k", "Previous row"],
+      ["J / K", "Move focused row down / up"],
       ["Enter / Space", "Toggle accordion"],
       ["a", "Approve focused"],
       ["r", "Reject focused"],
@@ -2014,7 +2305,17 @@
       case " ":
         if (_kbFocusIndex >= 0 && _kbFocusIndex < rows.length) {
           e.preventDefault();
-          toggleQueueAccordion(_kbFocusIndex);
+          var expand = rows[_kbFocusIndex].querySelector(".queue-expand");
+          if (expand) expand.click();
+        }
+        break;
+      case "J":
+      case "K":
+        if (_kbFocusIndex >= 0 && _kbFocusIndex < rows.length) {
+          e.preventDefault();
+          var delta = e.key === "J" ? 1 : -1;
+          if (moveQueueRow(rows[_kbFocusIndex], delta)) _kbFocusIndex += delta;
+          _applyKbFocus();
         }
         break;
       case "a":
@@ -2071,6 +2372,86 @@
       focused[i].classList.remove("kb-focused");
     }
   }
+  function _formatElapsed(since) {
+    var secs = Math.max(0, Math.floor((Date.now() - new Date(since).getTime()) / 1000));
+    if (secs < 60) return secs + "s";
+    if (secs < 3600) return Math.floor(secs / 60) + "m";
+    return Math.floor(secs / 3600) + "h " + Math.floor((secs % 3600) / 60) + "m";
+  }
+  function loadActiveUpdates() {
+    var card = document.getElementById("active-updates-card");
+    var tbody = document.getElementById("active-updates-tbody");
+    if (!card || !tbody) return;
+    fetch("/api/updates/active")
+      .then(function (resp) { return resp.ok ? resp.json() : []; })
+      .then(function (active) {
+        while (tbody.firstChild) tbody.removeChild(tbody.firstChild);
+        card.style.display = active.length ? "" : "none";
+        for (var i = 0; i < active.length; i++) {
+          var a = active[i];
+          var row = document.createElement("tr");
+          var nameCell = document.createElement("td");
+          nameCell.className = "mono";
+          nameCell.textContent = a.name;
+          if (a.host_id) {
+            var host = document.createElement("span");
+            host.className = "host-badge";
+            host.textContent = a.host_name || a.host_id;
+            nameCell.appendChild(host);
+          }
+          row.appendChild(nameCell);
+          var phaseCell = document.createElement("td");
+          var badge = document.createElement("span");
+          badge.className = "badge " + (a.stuck ? "badge-error" : "badge-info");
+          badge.textContent = a.stuck ? a.phase + " (stuck)" : a.phase;
+          phaseCell.appendChild(badge);
+          row.appendChild(phaseCell);
+          var timeCell = document.createElement("td");
+          timeCell.title = "Started " + new Date(a.started_at).toLocaleString();
+          timeCell.textContent = _formatElapsed(a.phase_since) + " in phase, " + _formatElapsed(a.started_at) + " total";
+          row.appendChild(timeCell);
+          var actionCell = document.createElement("td");
+          if (a.stuck && !a.host_id) {
+            var btn = document.createElement("button");
+            btn.className = "btn btn-sm btn-error";
+            btn.textContent = "Force unlock";
+            btn.setAttribute("data-name", a.name);
+            btn.addEventListener("click", function (e) {
+              forceUnlockUpdate(e.currentTarget.getAttribute("data-name"), e);
+            });
+            actionCell.appendChild(btn);
+          }
+          row.appendChild(actionCell);
+          tbody.appendChild(row);
+        }
+      })
+      .catch(function () {});
+  }
+  function forceUnlockUpdate(name, event) {
+    var btn = event && event.currentTarget ? event.currentTarget : null;
+    showConfirm(
+      "Force unlock " + name + "?",
+      "<p>The stuck update of <strong>" + escapeHTML(name) + "</strong> will be abandoned so it can be retried. " +
+      "Sentinel checks the container afterwards and tells you if it needs a rollback.</p>",
+      { confirmLabel: "Force unlock", danger: true }
+    ).then(function (confirmed) {
+      if (!confirmed) return;
+      apiFetch("/api/updates/" + encodeURIComponent(name) + "/force-unlock", {
+        method: "POST",
+        triggerEl: btn,
+        errorMsg: "Failed to force unlock " + name,
+        onSuccess: function (data) {
+          var warnings = data.warnings || [];
+          if (warnings.length) {
+            showToast(name + ": " + warnings.join("; "), "warning");
+          } else {
+            showToast("Update lock released for " + name, "success");
+          }
+          loadActiveUpdates();
+        }
+      });
+    });
+  }
 
   // internal/web/static/src/js/swarm.js
   function isSafeURL(url) {
@@ -2089,6 +2470,55 @@
   }
   function triggerSvcUpdate(name, event) {
     var btn = event && event.target ? event.target.closest(".badge-action") || event.target.closest(".btn") : null;
+    fetch("/api/services/" + encodeURIComponent(name) + "/placement").then(function(r) {
+      return r.json().then(function(data) {
+        return { ok: r.ok, data };
+      });
+    }).then(function(result) {
+      if (!result.ok) {
+        startSvcUpdate(name, btn);
+        return;
+      }
+      showConfirm("Update " + name, placementPreviewHTML(result.data), {
+        danger: result.data.warnings && result.data.warnings.length > 0,
+        confirmLabel: "Update"
+      }).then(function(confirmed) {
+        if (confirmed) startSvcUpdate(name, btn);
+      });
+    }).catch(function() {
+      startSvcUpdate(name, btn);
+    });
+  }
+  function placementPreviewHTML(plan) {
+    var html = "<p>Update <strong>" + escapeHTML(plan.service) + "</strong> to <strong>" + escapeHTML(plan.image) + "</strong>?</p>";
+    if (plan.image_platforms && plan.image_platforms.length > 0) {
+      html += '<div class="confirm-muted-row">Image platforms: ' + plan.image_platforms.map(escapeHTML).join(", ") + "</div>";
+    }
+    if (plan.constraints && plan.constraints.length > 0) {
+      html += '<div class="confirm-muted-row">Constraints: ' + plan.constraints.map(escapeHTML).join(", ") + "</div>";
+    }
+    if (plan.warnings && plan.warnings.length > 0) {
+      html += '<div class="confirm-section-label">Warnings (' + plan.warnings.length + ")</div>";
+      for (var w = 0; w < plan.warnings.length; w++) {
+        html += '<div class="confirm-change-row"><span class="badge badge-warning">' + escapeHTML(plan.warnings[w]) + "</span></div>";
+      }
+    }
+    var nodes = plan.nodes || [];
+    html += '<div class="confirm-section-label">Nodes (' + nodes.length + ")</div>";
+    for (var i = 0; i < nodes.length; i++) {
+      var n = nodes[i];
+      if (n.eligible) {
+        html += '<div class="confirm-change-row"><span class="confirm-change-name">' + escapeHTML(n.hostname) + '</span> <span class="badge badge-muted">' + escapeHTML(n.platform) + "</span> " + n.current_tasks + ' \u2192 <span class="badge badge-info">' + n.planned_tasks + " task" + (n.planned_tasks !== 1 ? "s" : "") + "</span></div>";
+      } else {
+        html += '<div class="confirm-muted-row">' + escapeHTML(n.hostname) + " (" + escapeHTML(n.platform) + ") \u2014 " + escapeHTML(n.reason) + "</div>";
+      }
+    }
+    if (plan.mode === "replicated") {
+      html += '<div class="confirm-muted-row">Planned task counts are an estimate; the scheduler also weighs node resources.</div>';
+    }
+    return html;
+  }
+  function startSvcUpdate(name, btn) {
     if (btn) {
       btn.classList.add("loading");
       btn.disabled = true;
@@ -2511,18 +2941,61 @@
       }
     }
   }
+  var _sseBootID = null;
+  var _backendRetryDelay = 2e3;
+  function waitForBackend() {
+    setConnectionStatus(false);
+    fetch("/api/version", { credentials: "same-origin", cache: "no-store" }).then(function(res) {
+      if (res.status === 401) {
+        window.location.href = "/login";
+        return;
+      }
+      if (!res.ok) throw new Error("HTTP " + res.status);
+      window.location.reload();
+    }).catch(function() {
+      _backendRetryDelay = Math.min(_backendRetryDelay * 2, 3e4);
+      setTimeout(waitForBackend, _backendRetryDelay);
+    });
+  }
+  function showSelfUpdateNotice() {
+    fetch("/api/version", { credentials: "same-origin", cache: "no-store" }).then(function(res) {
+      return res.ok ? res.json() : null;
+    }).then(function(data) {
+      if (!data || !data.self_update) return;
+      if (localStorage.getItem("sentinel-update-seen") === data.boot_id) return;
+      localStorage.setItem("sentinel-update-seen", data.boot_id);
+      var done = new Date(data.self_update.completed_at).getTime();
+      if (!done || Date.now() - done > 24 * 60 * 60 * 1e3) return;
+      var from = data.self_update.from_version || "unknown";
+      var to = data.self_update.to_version || data.version;
+      showToast("Sentinel updated from " + from + " \u2192 " + to, "success");
+    }).catch(function() {
+    });
+  }
   function initSSE() {
     if (typeof EventSource === "undefined") return;
+    showSelfUpdateNotice();
     var es = new EventSource("/api/events");
     window.sseSource = es;
     var _sseHasConnected = false;
     var _sseCatchUpTimer = null;
-    es.addEventListener("connected", function() {
+    es.addEventListener("connected", function(e) {
       if (localStorage.getItem("sentinel-self-updating")) {
         localStorage.removeItem("sentinel-self-updating");
         window.location.reload();
         return;
       }
+      var bootID = null;
+      try {
+        bootID = JSON.parse(e.data).boot_id || null;
+      } catch (_) {
+      }
+      if (_sseBootID && bootID && bootID !== _sseBootID) {
+        window.location.reload();
+        return;
+      }
+      if (bootID) _sseBootID = bootID;
+      _backendRetryDelay = 2e3;
       var wasReconnect = _sseHasConnected;
       _sseHasConnected = true;
       setConnectionStatus(true);
@@ -2787,6 +3260,10 @@
     };
     es.onerror = function() {
       setConnectionStatus(false);
+      if (es.readyState === EventSource.CLOSED) {
+        es.close();
+        setTimeout(waitForBackend, _backendRetryDelay);
+      }
     };
   }
   function loadGHCRAlternatives() {
@@ -3073,6 +3550,10 @@
         var filters = settings["filters"] || "";
         filtersArea.value = filters;
       }
+      var rewritesArea = document.getElementById("image-rewrites");
+      if (rewritesArea) {
+        rewritesArea.value = settings["image_rewrites"] || "";
+      }
       var imageCleanupToggle = document.getElementById("image-cleanup-toggle");
       if (imageCleanupToggle) {
         var imageCleanup = settings["image_cleanup"] === "true";
@@ -3117,6 +3598,30 @@
       if (updateDelayInput) {
         updateDelayInput.value = settings["update_delay"] || "";
       }
+      var healthTimeoutInput = document.getElementById("health-timeout");
+      if (healthTimeoutInput) {
+        healthTimeoutInput.value = settings["health_timeout"] || "";
+      }
+      var blueGreenPortsInput = document.getElementById("bluegreen-port-range");
+      if (blueGreenPortsInput) {
+        blueGreenPortsInput.value = settings["bluegreen_port_range"] || "";
+      }
+      var validationWebhookInput = document.getElementById("validation-webhook-input");
+      if (validationWebhookInput) {
+        validationWebhookInput.value = settings["validation_webhook_url"] || "";
+      }
+      var validationCommandInput = document.getElementById("validation-command-input");
+      if (validationCommandInput) {
+        validationCommandInput.value = settings["validation_command"] || "";
+      }
+      var stuckThresholdInput = document.getElementById("stuck-update-threshold");
+      if (stuckThresholdInput) {
+        stuckThresholdInput.value = settings["stuck_update_threshold"] || "";
+      }
+      var auditRetentionInput = document.getElementById("audit-retention");
+      if (auditRetentionInput) {
+        auditRetentionInput.value = settings["audit_retention"] || "";
+      }
       var maintenanceWindowInput = document.getElementById("maintenance-window");
       if (maintenanceWindowInput) {
         maintenanceWindowInput.value = settings["maintenance_window"] || "";
@@ -3127,6 +3632,40 @@
         composeSyncToggle.checked = composeSync;
         updateToggleText("compose-sync-text", composeSync);
       }
+      var configDiffToggle = document.getElementById("config-diff-toggle");
+      if (configDiffToggle) {
+        var configDiff = settings["config_diff"] === "true";
+        configDiffToggle.checked = configDiff;
+        updateToggleText("config-diff-text", configDiff);
+      }
+      var rebuildWatchToggle = document.getElementById("rebuild-watch-toggle");
+      if (rebuildWatchToggle) {
+        var rebuildWatch = settings["rebuild_watch"] === "true";
+        rebuildWatchToggle.checked = rebuildWatch;
+        updateToggleText("rebuild-watch-text", rebuildWatch);
+      }
+      var rebuildWebhookInput = document.getElementById("rebuild-webhook-input");
+      if (rebuildWebhookInput) {
+        rebuildWebhookInput.value = settings["rebuild_webhook_url"] || "";
+      }
+      var pullCheckRegistryToggle = document.getElementById("pull-check-registry-toggle");
+      if (pullCheckRegistryToggle) {
+        var pullCheckRegistry = settings["pull_check_registry"] !== "false";
+        pullCheckRegistryToggle.checked = pullCheckRegistry;
+        updateToggleText("pull-check-registry-text", pullCheckRegistry);
+      }
+      var pullCheckDiskInput = document.getElementById("pull-check-disk-input");
+      if (pullCheckDiskInput) {
+        pullCheckDiskInput.value = settings["pull_check_disk_path"] || "";
+      }
+      ["history", "logs", "snapshots"].forEach(function(kind) {
+        var days = document.getElementById("retention-" + kind + "-days");
+        var max = document.getElementById("retention-" + kind + "-max");
+        if (days) days.value = settings["retention_" + kind + "_days"] || "";
+        if (max) max.value = settings["retention_" + kind + "_max"] || "";
+      });
+      var externalURLInput = document.getElementById("external-url-input");
+      if (externalURLInput) externalURLInput.value = settings["external_url"] || "";
       var imageBackupToggle = document.getElementById("image-backup-toggle");
       if (imageBackupToggle) {
         var imageBackup = settings["image_backup"] === "true";
@@ -3152,6 +3691,17 @@
           scanConcInput.value = sc;
         }
       }
+      var maxAutoInput = document.getElementById("max-auto-updates-input");
+      if (maxAutoInput && settings["max_auto_updates"]) {
+        var ma = parseInt(settings["max_auto_updates"], 10);
+        if (!isNaN(ma) && ma >= 0) {
+          maxAutoInput.value = ma;
+        }
+      }
+      var overflowSelect = document.getElementById("auto-update-overflow");
+      if (overflowSelect && settings["auto_update_overflow"]) {
+        overflowSelect.value = settings["auto_update_overflow"];
+      }
       var haToggle = document.getElementById("ha-discovery-toggle");
       if (haToggle) {
         var haEnabled = settings["ha_discovery_enabled"] === "true";
@@ -3167,6 +3717,9 @@
     });
     loadScannerSettings();
     loadVerifierSettings();
+    loadTrustedKeys();
+    loadVerifyCacheStats();
+    loadDeployTokens();
     loadRetrySettings();
     var settingsTabContainer = document.getElementById("settings-tabs");
     var tabBtns = settingsTabContainer ? settingsTabContainer.querySelectorAll(".tab-btn") : [];
@@ -3220,7 +3773,9 @@
     if (window.loadAboutInfo) window.loadAboutInfo();
     if (window.loadClusterSettings) window.loadClusterSettings();
     loadWebhookSettings();
+    loadComposePaths();
     loadDashboardColumns();
+    loadBackupSettings();
   }
   function clearAccordionState() {
     var keys = [];
@@ -3525,6 +4080,79 @@
       showToast("Network error -- could not save filters", "error");
     });
   }
+  function saveImageRewrites() {
+    var textarea = document.getElementById("image-rewrites");
+    if (!textarea) return;
+    var lines = textarea.value.split("\n");
+    var rules = [];
+    for (var i = 0; i < lines.length; i++) {
+      var trimmed = lines[i].replace(/^\s+|\s+$/g, "");
+      if (trimmed !== "") {
+        rules.push(trimmed);
+      }
+    }
+    fetch("/api/settings/image-rewrites", {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({ rules })
+    }).then(function(resp) {
+      return resp.json().then(function(data) {
+        return { ok: resp.ok, data };
+      });
+    }).then(function(result) {
+      if (result.ok) {
+        showToast(result.data.message || "Image rewrites saved", "success");
+      } else {
+        showToast(result.data.error || "Failed to save image rewrites", "error");
+      }
+    }).catch(function() {
+      showToast("Network error -- could not save image rewrites", "error");
+    });
+  }
+  function testFilters() {
+    var textarea = document.getElementById("container-filters");
+    var nameInput = document.getElementById("filter-test-name");
+    var imageInput = document.getElementById("filter-test-image");
+    var resultEl = document.getElementById("filter-test-result");
+    if (!textarea || !nameInput || !imageInput || !resultEl) return;
+    var name = nameInput.value.trim();
+    var image = imageInput.value.trim();
+    if (!name && !image) {
+      showToast("Enter a container name or image to test", "info");
+      return;
+    }
+    var patterns = [];
+    var lines = textarea.value.split("\n");
+    for (var i = 0; i < lines.length; i++) {
+      var trimmed = lines[i].trim();
+      if (trimmed !== "") patterns.push(trimmed);
+    }
+    fetch("/api/settings/filters/test", {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({ name, image, patterns })
+    }).then(function(resp) {
+      return resp.json().then(function(data) {
+        return { ok: resp.ok, data };
+      });
+    }).then(function(result) {
+      if (!result.ok) {
+        resultEl.textContent = result.data.error || "Filter test failed";
+        return;
+      }
+      var d = result.data;
+      var subject = (d.name || d.image) + (d.name && d.image ? " (" + d.image + ")" : "");
+      if (d.excluded) {
+        resultEl.textContent = subject + " is excluded by " + d.rule;
+      } else if (d.rule) {
+        resultEl.textContent = subject + " is scanned: re-included by " + d.rule;
+      } else {
+        resultEl.textContent = subject + " is scanned: no rule matches";
+      }
+    }).catch(function() {
+      showToast("Network error -- could not test filters", "error");
+    });
+  }
   function updateToggleText(textId, enabled) {
     var text = document.getElementById(textId);
     if (text) {
@@ -3619,9 +4247,9 @@
       showToast("Network error -- could not update setting", "error");
     });
   }
-  function setImageBackup(enabled) {
-    updateToggleText("image-backup-text", enabled);
-    fetch("/api/settings/image-backup", { method: "POST", headers: { "Content-Type": "application/json" }, body: JSON.stringify({ enabled }) }).then(function(r) {
+  function setConfigDiff(enabled) {
+    updateToggleText("config-diff-text", enabled);
+    fetch("/api/settings/config-diff", { method: "POST", headers: { "Content-Type": "application/json" }, body: JSON.stringify({ enabled }) }).then(function(r) {
       return r.json();
     }).then(function(data) {
       showToast(data.message || "Setting updated", "success");
@@ -3629,46 +4257,165 @@
       showToast("Network error -- could not update setting", "error");
     });
   }
-  function setShowStopped(enabled) {
-    updateToggleText("show-stopped-text", enabled);
-    fetch("/api/settings/show-stopped", { method: "POST", headers: { "Content-Type": "application/json" }, body: JSON.stringify({ enabled }) }).then(function(r) {
+  function setRebuildWatch(enabled) {
+    updateToggleText("rebuild-watch-text", enabled);
+    fetch("/api/settings/rebuild-watch", { method: "POST", headers: { "Content-Type": "application/json" }, body: JSON.stringify({ enabled }) }).then(function(r) {
       return r.json();
     }).then(function(data) {
-      showToast(data.message || "Setting updated", "success");
+      showToast(data.message || data.error || "Setting updated", data.error ? "error" : "success");
     }).catch(function() {
       showToast("Network error -- could not update setting", "error");
     });
   }
-  function setRemoveVolumes(enabled) {
-    updateToggleText("remove-volumes-text", enabled);
-    fetch("/api/settings/remove-volumes", { method: "POST", headers: { "Content-Type": "application/json" }, body: JSON.stringify({ enabled }) }).then(function(r) {
+  function saveRebuildWebhook() {
+    var input = document.getElementById("rebuild-webhook-input");
+    if (!input) return;
+    fetch("/api/settings/rebuild-watch", { method: "POST", headers: { "Content-Type": "application/json" }, body: JSON.stringify({ webhook_url: input.value.trim() }) }).then(function(r) {
       return r.json();
     }).then(function(data) {
-      showToast(data.message || "Setting updated", "success");
+      showToast(data.message || data.error || "Setting updated", data.error ? "error" : "success");
     }).catch(function() {
       showToast("Network error -- could not update setting", "error");
     });
   }
-  function setScanConcurrency() {
-    var input = document.getElementById("scan-concurrency-input");
-    var n = parseInt(input ? input.value : "1", 10);
-    if (isNaN(n) || n < 1 || n > 20) {
-      showToast("Concurrency must be between 1 and 20", "error");
-      return;
-    }
-    fetch("/api/settings/scan-concurrency", { method: "POST", headers: { "Content-Type": "application/json" }, body: JSON.stringify({ concurrency: n }) }).then(function(r) {
+  function setPullCheckRegistry(enabled) {
+    updateToggleText("pull-check-registry-text", enabled);
+    fetch("/api/settings/pull-checks", { method: "POST", headers: { "Content-Type": "application/json" }, body: JSON.stringify({ registry: enabled }) }).then(function(r) {
       return r.json();
     }).then(function(data) {
-      showToast(data.message || "Setting updated", "success");
+      showToast(data.message || data.error || "Setting updated", data.error ? "error" : "success");
     }).catch(function() {
       showToast("Network error -- could not update setting", "error");
     });
   }
-  function setHADiscovery(enabled) {
-    updateToggleText("ha-discovery-text", enabled);
-    var prefix = (document.getElementById("ha-discovery-prefix") || {}).value || "";
-    fetch("/api/settings/ha-discovery", { method: "POST", headers: { "Content-Type": "application/json" }, body: JSON.stringify({ enabled, prefix }) }).then(function(r) {
-      if (r.status === 204) {
+  function savePullCheckDiskPath() {
+    var input = document.getElementById("pull-check-disk-input");
+    if (!input) return;
+    fetch("/api/settings/pull-checks", { method: "POST", headers: { "Content-Type": "application/json" }, body: JSON.stringify({ disk_path: input.value.trim() }) }).then(function(r) {
+      return r.json();
+    }).then(function(data) {
+      showToast(data.message || data.error || "Setting updated", data.error ? "error" : "success");
+    }).catch(function() {
+      showToast("Network error -- could not update setting", "error");
+    });
+  }
+  function loadComposePaths() {
+    var list = document.getElementById("compose-paths-list");
+    if (!list) return;
+    fetch("/api/compose/paths").then(function(r) {
+      return r.json();
+    }).then(function(paths) {
+      var projects = Object.keys(paths || {}).sort();
+      if (projects.length === 0) {
+        list.innerHTML = '<div class="setting-desc">No overrides \u2014 using host paths from Compose labels.</div>';
+        return;
+      }
+      list.innerHTML = projects.map(function(project) {
+        return '<div style="display:flex; gap:var(--sp-2); align-items:center; margin-bottom:var(--sp-1)"><code>' + escapeHTML(project) + "</code> \u2192 <code>" + escapeHTML(paths[project]) + '</code><button class="btn btn-sm" data-project="' + escapeHTML(project) + `" onclick="deleteComposePath(this.getAttribute('data-project'))">Remove</button></div>`;
+      }).join("");
+    }).catch(function() {
+    });
+  }
+  function saveComposePath() {
+    var project = document.getElementById("compose-path-project");
+    var path = document.getElementById("compose-path-path");
+    if (!project || !path) return;
+    fetch("/api/compose/paths", {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({ project: project.value.trim(), path: path.value.trim() })
+    }).then(function(resp) {
+      return resp.json().then(function(data) {
+        return { ok: resp.ok, data };
+      });
+    }).then(function(result) {
+      if (result.ok) {
+        showToast("Compose path saved for " + result.data.project, "success");
+        project.value = "";
+        path.value = "";
+        loadComposePaths();
+      } else {
+        showToast(result.data.error || "Failed to save compose path", "error");
+      }
+    }).catch(function() {
+      showToast("Network error -- could not save compose path", "error");
+    });
+  }
+  function deleteComposePath(project) {
+    fetch("/api/compose/paths/" + encodeURIComponent(project), { method: "DELETE" }).then(function(resp) {
+      if (!resp.ok) throw new Error("delete failed");
+      showToast("Compose path removed for " + project, "success");
+      loadComposePaths();
+    }).catch(function() {
+      showToast("Could not remove compose path", "error");
+    });
+  }
+  function setImageBackup(enabled) {
+    updateToggleText("image-backup-text", enabled);
+    fetch("/api/settings/image-backup", { method: "POST", headers: { "Content-Type": "application/json" }, body: JSON.stringify({ enabled }) }).then(function(r) {
+      return r.json();
+    }).then(function(data) {
+      showToast(data.message || "Setting updated", "success");
+    }).catch(function() {
+      showToast("Network error -- could not update setting", "error");
+    });
+  }
+  function setShowStopped(enabled) {
+    updateToggleText("show-stopped-text", enabled);
+    fetch("/api/settings/show-stopped", { method: "POST", headers: { "Content-Type": "application/json" }, body: JSON.stringify({ enabled }) }).then(function(r) {
+      return r.json();
+    }).then(function(data) {
+      showToast(data.message || "Setting updated", "success");
+    }).catch(function() {
+      showToast("Network error -- could not update setting", "error");
+    });
+  }
+  function setRemoveVolumes(enabled) {
+    updateToggleText("remove-volumes-text", enabled);
+    fetch("/api/settings/remove-volumes", { method: "POST", headers: { "Content-Type": "application/json" }, body: JSON.stringify({ enabled }) }).then(function(r) {
+      return r.json();
+    }).then(function(data) {
+      showToast(data.message || "Setting updated", "success");
+    }).catch(function() {
+      showToast("Network error -- could not update setting", "error");
+    });
+  }
+  function setScanConcurrency() {
+    var input = document.getElementById("scan-concurrency-input");
+    var n = parseInt(input ? input.value : "1", 10);
+    if (isNaN(n) || n < 1 || n > 20) {
+      showToast("Concurrency must be between 1 and 20", "error");
+      return;
+    }
+    fetch("/api/settings/scan-concurrency", { method: "POST", headers: { "Content-Type": "application/json" }, body: JSON.stringify({ concurrency: n }) }).then(function(r) {
+      return r.json();
+    }).then(function(data) {
+      showToast(data.message || "Setting updated", "success");
+    }).catch(function() {
+      showToast("Network error -- could not update setting", "error");
+    });
+  }
+  function setAutoUpdateCap() {
+    var input = document.getElementById("max-auto-updates-input");
+    var n = parseInt(input ? input.value : "0", 10);
+    if (isNaN(n) || n < 0 || n > 1e3) {
+      showToast("Max auto-updates must be between 0 and 1000", "error");
+      return;
+    }
+    var overflow = (document.getElementById("auto-update-overflow") || {}).value || "defer";
+    fetch("/api/settings/auto-update-cap", { method: "POST", headers: { "Content-Type": "application/json" }, body: JSON.stringify({ max: n, overflow }) }).then(function(r) {
+      return r.json();
+    }).then(function(data) {
+      showToast(data.message || data.error || "Setting updated", data.error ? "error" : "success");
+    }).catch(function() {
+      showToast("Network error -- could not update setting", "error");
+    });
+  }
+  function setHADiscovery(enabled) {
+    updateToggleText("ha-discovery-text", enabled);
+    var prefix = (document.getElementById("ha-discovery-prefix") || {}).value || "";
+    fetch("/api/settings/ha-discovery", { method: "POST", headers: { "Content-Type": "application/json" }, body: JSON.stringify({ enabled, prefix }) }).then(function(r) {
+      if (r.status === 204) {
         showToast("HA discovery " + (enabled ? "enabled" : "disabled"), "success");
         return;
       }
@@ -3712,6 +4459,87 @@
       showToast("Network error -- could not save update delay", "error");
     });
   }
+  function setHealthTimeout() {
+    var input = document.getElementById("health-timeout");
+    if (!input) return;
+    fetch("/api/settings/health-timeout", { method: "POST", headers: { "Content-Type": "application/json" }, body: JSON.stringify({ duration: input.value.trim() }) }).then(function(resp) {
+      return resp.json().then(function(data) {
+        return { ok: resp.ok, data };
+      });
+    }).then(function(result) {
+      if (result.ok) {
+        showToast(result.data.message || "Health check timeout saved", "success");
+      } else {
+        showToast(result.data.error || "Failed to save health check timeout", "error");
+      }
+    }).catch(function() {
+      showToast("Network error -- could not save health check timeout", "error");
+    });
+  }
+  function setBlueGreenPortRange() {
+    var input = document.getElementById("bluegreen-port-range");
+    if (!input) return;
+    fetch("/api/settings/bluegreen-port-range", { method: "POST", headers: { "Content-Type": "application/json" }, body: JSON.stringify({ port_range: input.value.trim() }) }).then(function(resp) {
+      return resp.json().then(function(data) {
+        return { ok: resp.ok, data };
+      });
+    }).then(function(result) {
+      if (result.ok) {
+        showToast(result.data.message || "Blue-green port range saved", "success");
+      } else {
+        showToast(result.data.error || "Failed to save blue-green port range", "error");
+      }
+    }).catch(function() {
+      showToast("Network error -- could not save blue-green port range", "error");
+    });
+  }
+  function saveExternalValidation(field, inputId) {
+    var input = document.getElementById(inputId);
+    if (!input) return;
+    var body = {};
+    body[field] = input.value.trim();
+    fetch("/api/settings/external-validation", { method: "POST", headers: { "Content-Type": "application/json" }, body: JSON.stringify(body) }).then(function(r) {
+      return r.json();
+    }).then(function(data) {
+      showToast(data.message || data.error || "Setting updated", data.error ? "error" : "success");
+    }).catch(function() {
+      showToast("Network error -- could not update setting", "error");
+    });
+  }
+  function setStuckUpdateThreshold() {
+    var input = document.getElementById("stuck-update-threshold");
+    if (!input) return;
+    fetch("/api/settings/stuck-update-threshold", { method: "POST", headers: { "Content-Type": "application/json" }, body: JSON.stringify({ duration: input.value.trim() }) }).then(function(resp) {
+      return resp.json().then(function(data) {
+        return { ok: resp.ok, data };
+      });
+    }).then(function(result) {
+      if (result.ok) {
+        showToast(result.data.message || "Stuck update threshold saved", "success");
+      } else {
+        showToast(result.data.error || "Failed to save stuck update threshold", "error");
+      }
+    }).catch(function() {
+      showToast("Network error -- could not save stuck update threshold", "error");
+    });
+  }
+  function setAuditRetention() {
+    var input = document.getElementById("audit-retention");
+    if (!input) return;
+    fetch("/api/settings/audit-retention", { method: "POST", headers: { "Content-Type": "application/json" }, body: JSON.stringify({ duration: input.value.trim() }) }).then(function(resp) {
+      return resp.json().then(function(data) {
+        return { ok: resp.ok, data };
+      });
+    }).then(function(result) {
+      if (result.ok) {
+        showToast(result.data.message || "Audit retention saved", "success");
+      } else {
+        showToast(result.data.error || "Failed to save audit retention", "error");
+      }
+    }).catch(function() {
+      showToast("Network error -- could not save audit retention", "error");
+    });
+  }
   function toggleCollapsible(headerEl) {
     var expanded = headerEl.getAttribute("aria-expanded") === "true";
     headerEl.setAttribute("aria-expanded", expanded ? "false" : "true");
@@ -3781,11 +4609,11 @@
       var enabled = data.enabled === "true";
       toggle.checked = enabled;
       updateToggleText("webhook-enabled-text", enabled);
-      showWebhookConfig(enabled, data.secret || "");
+      showWebhookConfig(enabled, data.secret || "", data.registry_token || "");
     }).catch(function() {
     });
   }
-  function showWebhookConfig(enabled, secret) {
+  function showWebhookConfig(enabled, secret, registryToken) {
     var configDiv = document.getElementById("webhook-config");
     if (!configDiv) return;
     configDiv.style.display = enabled ? "" : "none";
@@ -3801,6 +4629,16 @@
         hint.style.display = secret && secret.indexOf("****") !== -1 ? "" : "none";
       }
     }
+    setRegistryWebhookURL(registryToken);
+  }
+  function setRegistryWebhookURL(token) {
+    var input = document.getElementById("registry-webhook-url");
+    if (!input) return;
+    input.value = token ? window.location.origin + "/api/webhooks/registry/" + token : "";
+    var hint = document.getElementById("registry-webhook-hint");
+    if (hint) {
+      hint.style.display = token && token.indexOf("****") !== -1 ? "" : "none";
+    }
   }
   function setWebhookEnabled(enabled) {
     updateToggleText("webhook-enabled-text", enabled);
@@ -3852,6 +4690,43 @@
       });
     });
   }
+  function regenerateRegistryWebhookToken() {
+    showConfirm(
+      "Regenerate Registry Webhook URL",
+      "<p>Registries configured with the current URL will stop triggering checks. Continue?</p>",
+      { danger: true, confirmLabel: "Regenerate" }
+    ).then(function(confirmed) {
+      if (!confirmed) return;
+      fetch("/api/settings/registry-webhook-token", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" }
+      }).then(function(resp) {
+        return resp.json().then(function(data) {
+          return { ok: resp.ok, data };
+        });
+      }).then(function(result) {
+        if (result.ok) {
+          setRegistryWebhookURL(result.data.token || "");
+          showToast("Registry webhook URL regenerated \u2014 copy it now, it won't be shown again", "success");
+        } else {
+          showToast(result.data.error || "Failed to regenerate URL", "error");
+        }
+      }).catch(function() {
+        showToast("Network error -- could not regenerate URL", "error");
+      });
+    });
+  }
+  function copyRegistryWebhookURL() {
+    var input = document.getElementById("registry-webhook-url");
+    if (!input || !input.value) return;
+    navigator.clipboard.writeText(input.value).then(function() {
+      showToast("Registry webhook URL copied", "success");
+    }).catch(function() {
+      input.select();
+      document.execCommand("copy");
+      showToast("Registry webhook URL copied", "success");
+    });
+  }
   function copyWebhookURL() {
     var input = document.getElementById("webhook-url");
     if (!input || !input.value) return;
@@ -3898,14 +4773,20 @@
   }
   function exportConfig() {
     var includeSecrets = document.getElementById("export-secrets-toggle");
-    var qs = includeSecrets && includeSecrets.checked ? "?secrets=true" : "";
-    fetch("/api/config/export" + qs).then(function(r) {
+    var formatEl = document.getElementById("export-format");
+    var passEl = document.getElementById("export-passphrase");
+    var format = formatEl ? formatEl.value : "json";
+    var qs = "?format=" + encodeURIComponent(format);
+    if (includeSecrets && includeSecrets.checked) qs += "&secrets=true";
+    var headers = {};
+    if (passEl && passEl.value) headers["X-Config-Passphrase"] = passEl.value;
+    fetch("/api/config/export" + qs, { headers: headers }).then(function(r) {
       if (!r.ok) throw new Error("Export failed");
       return r.blob();
     }).then(function(blob) {
       var a = document.createElement("a");
       a.href = URL.createObjectURL(blob);
-      a.download = "sentinel-config-" + (/* @__PURE__ */ new Date()).toISOString().slice(0, 10) + ".json";
+      a.download = "sentinel-config-" + (/* @__PURE__ */ new Date()).toISOString().slice(0, 10) + "." + format;
       a.click();
       URL.revokeObjectURL(a.href);
       showToast("Configuration exported", "success");
@@ -3926,11 +4807,14 @@
     ).then(function(confirmed) {
       if (!confirmed) return;
       var file = fileInput.files[0];
+      var passEl = document.getElementById("import-passphrase");
+      var headers = { "Content-Type": /\.ya?ml$/i.test(file.name) ? "application/yaml" : "application/json" };
+      if (passEl && passEl.value) headers["X-Config-Passphrase"] = passEl.value;
       var reader = new FileReader();
       reader.onload = function(e) {
         fetch("/api/config/import", {
           method: "POST",
-          headers: { "Content-Type": "application/json" },
+          headers: headers,
           body: e.target.result
         }).then(function(r) {
           return r.json();
@@ -3950,6 +4834,199 @@
       reader.readAsText(file);
     });
   }
+  function exportQueueState() {
+    fetch("/api/state/export").then(function(r) {
+      if (!r.ok) throw new Error("Export failed");
+      return r.blob();
+    }).then(function(blob) {
+      var a = document.createElement("a");
+      a.href = URL.createObjectURL(blob);
+      a.download = "sentinel-state-" + (/* @__PURE__ */ new Date()).toISOString().slice(0, 10) + ".json";
+      a.click();
+      URL.revokeObjectURL(a.href);
+      showToast("Queue state exported", "success");
+    }).catch(function() {
+      showToast("Export failed", "error");
+    });
+  }
+  function importQueueState() {
+    var fileInput = document.getElementById("state-import-file");
+    if (!fileInput || !fileInput.files.length) {
+      showToast("Select a file first", "error");
+      return;
+    }
+    var conflictEl = document.getElementById("state-import-conflict");
+    var conflict = conflictEl ? conflictEl.value : "skip";
+    var reader = new FileReader();
+    reader.onload = function(e) {
+      fetch("/api/state/import?conflict=" + encodeURIComponent(conflict), {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: e.target.result
+      }).then(function(r) {
+        return r.json();
+      }).then(function(data) {
+        if (data.error) {
+          showToast(data.error, "error");
+          return;
+        }
+        var warnings = data.warnings || [];
+        showToast(data.message || "Queue state imported", warnings.length ? "warning" : "success");
+      }).catch(function() {
+        showToast("Import failed", "error");
+      });
+    };
+    reader.readAsText(fileInput.files[0]);
+  }
+  function downloadDatabaseBackup(btn) {
+    if (btn) btn.disabled = true;
+    fetch("/api/admin/backup", { method: "POST" }).then(function(r) {
+      if (!r.ok) throw new Error("Backup failed");
+      return r.blob();
+    }).then(function(blob) {
+      var a = document.createElement("a");
+      a.href = URL.createObjectURL(blob);
+      a.download = "sentinel-" + (/* @__PURE__ */ new Date()).toISOString().slice(0, 10) + ".db";
+      a.click();
+      URL.revokeObjectURL(a.href);
+      showToast("Database backup downloaded", "success");
+    }).catch(function() {
+      showToast("Database backup failed", "error");
+    }).then(function() {
+      if (btn) btn.disabled = false;
+    });
+  }
+  function loadBackupSettings() {
+    fetch("/api/admin/backup/settings").then(function(r) {
+      if (!r.ok) throw new Error("unavailable");
+      return r.json();
+    }).then(function(cfg) {
+      var schedule = document.getElementById("backup-schedule");
+      var dir = document.getElementById("backup-dir");
+      var retention = document.getElementById("backup-retention");
+      if (schedule) schedule.value = cfg.schedule || "";
+      if (dir) dir.value = cfg.dir || "";
+      if (retention) retention.value = cfg.retention;
+    }).catch(function() {
+    });
+  }
+  function saveBackupSettings(btn) {
+    var schedule = document.getElementById("backup-schedule");
+    var dir = document.getElementById("backup-dir");
+    var retention = document.getElementById("backup-retention");
+    if (!schedule || !dir || !retention) return;
+    if (btn) btn.disabled = true;
+    fetch("/api/admin/backup/settings", {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({
+        schedule: schedule.value.trim(),
+        dir: dir.value.trim(),
+        retention: parseInt(retention.value, 10) || 0
+      })
+    }).then(function(resp) {
+      return resp.json().then(function(data) {
+        return { ok: resp.ok, data };
+      });
+    }).then(function(result) {
+      if (result.ok) {
+        showToast("Backup settings saved", "success");
+      } else {
+        showToast(result.data.error || "Failed to save backup settings", "error");
+      }
+    }).catch(function() {
+      showToast("Network error -- could not save backup settings", "error");
+    }).then(function() {
+      if (btn) btn.disabled = false;
+    });
+  }
+  function saveRetention(btn) {
+    var body = {};
+    ["history", "logs", "snapshots"].forEach(function(kind) {
+      var days = document.getElementById("retention-" + kind + "-days");
+      var max = document.getElementById("retention-" + kind + "-max");
+      if (!days || !max) return;
+      body[kind] = {
+        days: parseInt(days.value, 10) || 0,
+        max_records: parseInt(max.value, 10) || 0
+      };
+    });
+    if (btn) btn.disabled = true;
+    fetch("/api/settings/retention", {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify(body)
+    }).then(function(resp) {
+      return resp.json().then(function(data) {
+        return { ok: resp.ok, data };
+      });
+    }).then(function(result) {
+      if (result.ok) {
+        showToast(result.data.message || "Retention saved", "success");
+      } else {
+        showToast(result.data.error || "Failed to save retention", "error");
+      }
+    }).catch(function() {
+      showToast("Network error -- could not save retention", "error");
+    }).then(function() {
+      if (btn) btn.disabled = false;
+    });
+  }
+  function compactDatabase(btn) {
+    if (btn) btn.disabled = true;
+    fetch("/api/admin/compact", { method: "POST" }).then(function(resp) {
+      return resp.json().then(function(data) {
+        return { ok: resp.ok, data };
+      });
+    }).then(function(result) {
+      if (result.ok) {
+        showToast(result.data.message || "Database compacted", "success");
+      } else {
+        showToast(result.data.error || "Compaction failed", "error");
+      }
+    }).catch(function() {
+      showToast("Network error -- could not compact database", "error");
+    }).then(function() {
+      if (btn) btn.disabled = false;
+    });
+  }
+  function restoreDatabase(btn) {
+    var fileInput = document.getElementById("db-restore-file");
+    if (!fileInput || !fileInput.files.length) {
+      showToast("Select a database file first", "error");
+      return;
+    }
+    showConfirm(
+      "Restore Database",
+      "<p>This replaces <strong>all</strong> data &mdash; policies, history, users and cluster enrolments &mdash; with the backup. You may be signed out. Continue?</p>",
+      { confirmLabel: "Restore", danger: true }
+    ).then(function(confirmed) {
+      if (!confirmed) return;
+      if (btn) btn.disabled = true;
+      fetch("/api/admin/restore", {
+        method: "POST",
+        headers: { "Content-Type": "application/octet-stream" },
+        body: fileInput.files[0]
+      }).then(function(resp) {
+        return resp.json().then(function(data) {
+          return { ok: resp.ok, data };
+        });
+      }).then(function(result) {
+        if (!result.ok) {
+          showToast(result.data.error || "Restore failed", "error");
+          return;
+        }
+        showToast(result.data.message || "Database restored", "success");
+        setTimeout(function() {
+          location.reload();
+        }, 2e3);
+      }).catch(function() {
+        showToast("Network error -- could not restore database", "error");
+      }).then(function() {
+        if (btn) btn.disabled = false;
+      });
+    });
+  }
   function loadDashboardColumns() {
     fetch("/api/settings").then(function(r) {
       return r.json();
@@ -4010,10 +5087,12 @@
       var modeEl = document.getElementById("scanner-mode");
       var threshEl = document.getElementById("scanner-threshold");
       var pathEl = document.getElementById("trivy-path");
+      var serverEl = document.getElementById("trivy-server");
       var preview = document.getElementById("scanner-preview");
       if (modeEl) modeEl.value = data.mode || "disabled";
       if (threshEl) threshEl.value = data.threshold || "HIGH";
       if (pathEl) pathEl.value = data.trivy_path || "trivy";
+      if (serverEl) serverEl.value = data.trivy_server || "";
       if (preview) {
         if (data.mode === "disabled" || !data.mode) {
           preview.textContent = "Disabled";
@@ -4028,10 +5107,12 @@
     var mode = document.getElementById("scanner-mode");
     var threshold = document.getElementById("scanner-threshold");
     var trivyPath = document.getElementById("trivy-path");
+    var trivyServer = document.getElementById("trivy-server");
     var body = {};
     if (mode) body.mode = mode.value;
     if (threshold) body.threshold = threshold.value;
     if (trivyPath && trivyPath.value) body.trivy_path = trivyPath.value;
+    if (trivyServer) body.trivy_server = trivyServer.value.trim();
     fetch("/api/settings/scanner", {
       method: "POST",
       headers: { "Content-Type": "application/json" },
@@ -4056,6 +5137,9 @@
       var pathEl = document.getElementById("cosign-path");
       var keylessEl = document.getElementById("cosign-keyless");
       var keyPathEl = document.getElementById("cosign-key-path");
+      var identityEl = document.getElementById("cosign-identity");
+      var issuerEl = document.getElementById("cosign-issuer");
+      var cacheTTLEl = document.getElementById("verify-cache-ttl");
       var preview = document.getElementById("verifier-preview");
       if (modeEl) modeEl.value = data.mode || "disabled";
       if (pathEl) pathEl.value = data.cosign_path || "cosign";
@@ -4065,6 +5149,9 @@
         if (text) text.textContent = data.keyless === "true" ? "On" : "Off";
       }
       if (keyPathEl) keyPathEl.value = data.key_path || "";
+      if (identityEl) identityEl.value = data.identity || "";
+      if (issuerEl) issuerEl.value = data.issuer || "";
+      if (cacheTTLEl) cacheTTLEl.value = data.cache_ttl || "";
       if (preview) {
         if (data.mode === "disabled" || !data.mode) {
           preview.textContent = "Disabled";
@@ -4081,11 +5168,17 @@
     var cosignPath = document.getElementById("cosign-path");
     var keyless = document.getElementById("cosign-keyless");
     var keyPath = document.getElementById("cosign-key-path");
+    var identity = document.getElementById("cosign-identity");
+    var issuer = document.getElementById("cosign-issuer");
+    var cacheTTL = document.getElementById("verify-cache-ttl");
     var body = {};
     if (mode) body.mode = mode.value;
     if (cosignPath && cosignPath.value) body.cosign_path = cosignPath.value;
     if (keyless) body.keyless = keyless.checked;
     if (keyPath) body.key_path = keyPath.value;
+    if (identity) body.identity = identity.value.trim();
+    if (issuer) body.issuer = issuer.value.trim();
+    if (cacheTTL && cacheTTL.value.trim()) body.cache_ttl = cacheTTL.value.trim();
     fetch("/api/settings/verifier", {
       method: "POST",
       headers: { "Content-Type": "application/json" },
@@ -4098,10 +5191,153 @@
     }).then(function() {
       showToast("Verifier settings saved", "success");
       loadVerifierSettings();
+      loadVerifyCacheStats();
     }).catch(function(err) {
       showToast("Failed: " + err.message, "error");
     });
   }
+  function loadTrustedKeys() {
+    var list = document.getElementById("trusted-keys-list");
+    if (!list) return;
+    fetch("/api/settings/verifier/keys").then(function(r) {
+      return r.json();
+    }).then(function(keys) {
+      if (!Array.isArray(keys) || keys.length === 0) {
+        list.innerHTML = '<div class="setting-desc">No trusted keys.</div>';
+        return;
+      }
+      list.innerHTML = keys.map(function(key) {
+        return '<div style="display:flex; gap:var(--sp-2); align-items:center; margin-bottom:var(--sp-1)"><code>' + escapeHTML(key.name) + '</code><span class="setting-desc" title="' + escapeHTML(key.fingerprint) + '">' + escapeHTML(key.fingerprint.slice(0, 23)) + '\u2026</span><button class="btn btn-sm" data-key="' + escapeHTML(key.name) + `" onclick="deleteTrustedKey(this.getAttribute('data-key'))">Remove</button></div>`;
+      }).join("");
+    }).catch(function() {
+    });
+  }
+  function addTrustedKey() {
+    var name = document.getElementById("trusted-key-name");
+    var pem = document.getElementById("trusted-key-pem");
+    if (!name || !pem) return;
+    fetch("/api/settings/verifier/keys", {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({ name: name.value.trim(), pem: pem.value })
+    }).then(function(resp) {
+      return resp.json().then(function(data) {
+        return { ok: resp.ok, data };
+      });
+    }).then(function(result) {
+      if (result.ok) {
+        showToast("Trusted key added: " + result.data.name, "success");
+        name.value = "";
+        pem.value = "";
+        loadTrustedKeys();
+      } else {
+        showToast(result.data.error || "Failed to add trusted key", "error");
+      }
+    }).catch(function() {
+      showToast("Network error -- could not add trusted key", "error");
+    });
+  }
+  function deleteTrustedKey(name) {
+    fetch("/api/settings/verifier/keys/" + encodeURIComponent(name), { method: "DELETE" }).then(function(resp) {
+      if (!resp.ok) throw new Error("delete failed");
+      showToast("Trusted key removed: " + name, "success");
+      loadTrustedKeys();
+    }).catch(function() {
+      showToast("Could not remove trusted key", "error");
+    });
+  }
+  function loadVerifyCacheStats() {
+    var el = document.getElementById("verify-cache-stats");
+    if (!el) return;
+    fetch("/api/verify/cache").then(function(r) {
+      return r.json();
+    }).then(function(data) {
+      if (data.error) {
+        el.textContent = "Not available.";
+        return;
+      }
+      var text = data.entries + " cached digest" + (data.entries === 1 ? "" : "s");
+      if (data.stale) text += " (" + data.stale + " from an older key set)";
+      text += ". " + data.hits + " reused, " + data.misses + " verified since start.";
+      if (!data.enabled) text += " Caching is off.";
+      el.textContent = text;
+    }).catch(function() {
+      el.textContent = "Not available.";
+    });
+  }
+  function clearVerifyCache() {
+    fetch("/api/verify/cache", { method: "DELETE" }).then(function(resp) {
+      return resp.json().then(function(data) {
+        return { ok: resp.ok, data };
+      });
+    }).then(function(result) {
+      if (result.ok) {
+        showToast("Verification cache cleared (" + result.data.removed + " entries)", "success");
+        loadVerifyCacheStats();
+      } else {
+        showToast(result.data.error || "Could not clear verification cache", "error");
+      }
+    }).catch(function() {
+      showToast("Network error -- could not clear verification cache", "error");
+    });
+  }
+  function loadDeployTokens() {
+    var list = document.getElementById("deploy-tokens-list");
+    if (!list) return;
+    fetch("/api/settings/deploy-tokens").then(function(r) {
+      return r.json();
+    }).then(function(tokens) {
+      if (!Array.isArray(tokens) || tokens.length === 0) {
+        list.innerHTML = '<div class="setting-desc">No deploy tokens.</div>';
+        return;
+      }
+      list.innerHTML = tokens.map(function(tok) {
+        var used = tok.last_used_at && tok.last_used_at.indexOf("0001-") !== 0 ? "used " + new Date(tok.last_used_at).toLocaleString() : "never used";
+        return '<div style="display:flex; gap:var(--sp-2); align-items:center; margin-bottom:var(--sp-1)"><code>' + escapeHTML(tok.name) + '</code><span class="setting-desc">' + escapeHTML((tok.containers || []).join(", ")) + " &middot; " + escapeHTML(used) + '</span><button class="btn btn-sm" data-id="' + escapeHTML(tok.id) + '" data-name="' + escapeHTML(tok.name) + `" onclick="deleteDeployToken(this.getAttribute('data-id'), this.getAttribute('data-name'))">Revoke</button></div>`;
+      }).join("");
+    }).catch(function() {
+    });
+  }
+  function createDeployToken() {
+    var name = document.getElementById("deploy-token-name");
+    var containers = document.getElementById("deploy-token-containers");
+    if (!name || !containers) return;
+    fetch("/api/settings/deploy-tokens", {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({ name: name.value.trim(), containers: containers.value.split(",") })
+    }).then(function(resp) {
+      return resp.json().then(function(data) {
+        return { ok: resp.ok, data };
+      });
+    }).then(function(result) {
+      if (result.ok) {
+        var out = document.getElementById("deploy-token-new");
+        if (out) {
+          out.value = result.data.token || "";
+          out.style.display = "";
+          out.select();
+        }
+        showToast("Deploy token created \u2014 copy it now, it won't be shown again", "success");
+        name.value = "";
+        containers.value = "";
+        loadDeployTokens();
+      } else {
+        showToast(result.data.error || "Failed to create deploy token", "error");
+      }
+    }).catch(function() {
+      showToast("Network error -- could not create deploy token", "error");
+    });
+  }
+  function deleteDeployToken(id, name) {
+    fetch("/api/settings/deploy-tokens/" + encodeURIComponent(id), { method: "DELETE" }).then(function(resp) {
+      if (!resp.ok) throw new Error("delete failed");
+      showToast("Deploy token revoked: " + name, "success");
+      loadDeployTokens();
+    }).catch(function() {
+      showToast("Could not revoke deploy token", "error");
+    });
+  }
   function loadRetrySettings() {
     fetch("/api/settings/notifications/retry").then(function(r) {
       return r.json();
@@ -4165,10 +5401,13 @@
       _updateToggleText("cluster-enabled-text", enabled);
       document.getElementById("cluster-port").value = s.port || "9443";
       document.getElementById("cluster-grace").value = s.grace_period || "30m";
+      document.getElementById("cluster-enroll-ttl").value = s.enroll_ttl || "24h";
       document.getElementById("cluster-policy").value = s.remote_policy || "manual";
       var autoUpdate = s.auto_update_agents === "true";
       document.getElementById("cluster-auto-update").checked = autoUpdate;
       _updateToggleText("cluster-auto-update-text", autoUpdate);
+      document.getElementById("cluster-min-agent-version").value = s.min_agent_version || "";
+      document.getElementById("cluster-outdated-agents").value = s.outdated_agents || "degrade";
       toggleClusterFields(enabled);
     }).catch(function(err) {
       console.error("Failed to load cluster settings:", err);
@@ -4215,8 +5454,11 @@
         enabled,
         port: document.getElementById("cluster-port").value,
         grace_period: document.getElementById("cluster-grace").value,
+        enroll_ttl: document.getElementById("cluster-enroll-ttl").value,
         remote_policy: document.getElementById("cluster-policy").value,
-        auto_update_agents: autoUpdateEl.checked
+        auto_update_agents: autoUpdateEl.checked,
+        min_agent_version: document.getElementById("cluster-min-agent-version").value,
+        outdated_agents: document.getElementById("cluster-outdated-agents").value
       })
     }).then(function(resp) {
       return resp.json().then(function(data) {
@@ -4241,7 +5483,12 @@
     { key: "update_failed", label: "Update Failed" },
     { key: "rollback_succeeded", label: "Rollback Succeeded" },
     { key: "rollback_failed", label: "Rollback Failed" },
-    { key: "container_state", label: "State Change" }
+    { key: "container_state", label: "State Change" },
+  { key: "pin_expiring", label: "Pin Expiring" },
+  { key: "pin_expired", label: "Pin Expired" },
+  { key: "rebuild_needed", label: "Rebuild Needed" },
+  { key: "queue_stale", label: "Stale Approval" },
+  { key: "report", label: "Weekly Report" }
   ];
   var LEGACY_EVENT_KEYS = {
     "update_complete": "update_succeeded",
@@ -4276,7 +5523,8 @@
     ],
     telegram: [
       { key: "bot_token", label: "Bot Token", type: "password", placeholder: "123456:ABC-DEF..." },
-      { key: "chat_id", label: "Chat ID", type: "text", placeholder: "-1001234567890" }
+      { key: "chat_id", label: "Chat ID", type: "text", placeholder: "-1001234567890" },
+      { key: "actions", label: "Approve Buttons", type: "text", placeholder: "true to approve or reject updates from the chat" }
     ],
     pushover: [
       { key: "app_token", label: "App Token", type: "password", placeholder: "Application token" },
@@ -4293,8 +5541,9 @@
     ],
     apprise: [
       { key: "url", label: "Apprise API URL", type: "text", placeholder: "http://apprise:8000" },
-      { key: "tag", label: "Config Tag", type: "text", placeholder: "Tag for persistent config (optional)" },
-      { key: "urls", label: "Apprise URLs", type: "text", placeholder: "Apprise URL(s) for stateless mode (optional)" }
+      { key: "tag", label: "Config Key", type: "text", placeholder: "Key of a persistent config (optional)" },
+      { key: "urls", label: "Apprise URLs", type: "text", placeholder: "Apprise URL(s) for stateless mode (optional)" },
+      { key: "tags", label: "Notify Tags", type: "text", placeholder: "Tags to notify, e.g. sentinel or all (optional)" }
     ],
     mqtt: [
       { key: "broker", label: "Broker URL", type: "text", placeholder: "tcp://mqtt:1883" },
@@ -4303,6 +5552,11 @@
       { key: "username", label: "Username", type: "text", placeholder: "Username (optional)" },
       { key: "password", label: "Password", type: "password", placeholder: "Password (optional)" },
       { key: "qos", label: "QoS", type: "text", placeholder: "0, 1, or 2 (default: 0)" }
+    ],
+    matrix: [
+      { key: "homeserver", label: "Homeserver URL", type: "text", placeholder: "https://matrix.example.org" },
+      { key: "access_token", label: "Access Token", type: "password", placeholder: "Token of the posting account" },
+      { key: "room_id", label: "Room ID", type: "text", placeholder: "!abcdef:example.org" }
     ]
   };
   var notificationChannels = [];
@@ -4322,6 +5576,7 @@
     });
   }
   function renderChannels() {
+    renderTemplateChannelOptions();
     var container = document.getElementById("channel-list");
     if (!container) return;
     while (container.firstChild) container.removeChild(container.firstChild);
@@ -4427,6 +5682,9 @@
       row.appendChild(input);
       fieldsDiv.appendChild(row);
     }
+    if (ch.type === "group") {
+      buildGroupFields(fieldsDiv, ch, settings);
+    }
     card.appendChild(fieldsDiv);
     var enabledEvents = ch.events;
     var allEnabled = !enabledEvents || enabledEvents.length === 0;
@@ -4469,6 +5727,63 @@
     card.appendChild(pillsWrap);
     return card;
   }
+  function buildGroupFields(fieldsDiv, ch, settings) {
+    var members = settings.members || [];
+    var memberRow = document.createElement("div");
+    memberRow.className = "channel-field";
+    var memberLabel = document.createElement("div");
+    memberLabel.className = "channel-field-label";
+    memberLabel.textContent = "Members";
+    memberRow.appendChild(memberLabel);
+    var memberList = document.createElement("div");
+    memberList.style.display = "flex";
+    memberList.style.flexWrap = "wrap";
+    memberList.style.gap = "var(--sp-3)";
+    var candidates = 0;
+    for (var i = 0; i < notificationChannels.length; i++) {
+      var other = notificationChannels[i];
+      if (other.type === "group" || other.id === ch.id) continue;
+      candidates++;
+      var lbl = document.createElement("label");
+      lbl.style.display = "flex";
+      lbl.style.alignItems = "center";
+      lbl.style.gap = "6px";
+      lbl.style.fontSize = "0.8125rem";
+      var cb = document.createElement("input");
+      cb.type = "checkbox";
+      cb.setAttribute("data-member", other.id);
+      cb.checked = members.indexOf(other.id) !== -1;
+      lbl.appendChild(cb);
+      lbl.appendChild(document.createTextNode(other.name || other.type));
+      memberList.appendChild(lbl);
+    }
+    if (candidates === 0) {
+      memberList.textContent = "Add other channels first, then select them here.";
+      memberList.style.color = "var(--fg-muted)";
+      memberList.style.fontSize = "0.8125rem";
+    }
+    memberRow.appendChild(memberList);
+    fieldsDiv.appendChild(memberRow);
+    var policyRow = document.createElement("div");
+    policyRow.className = "channel-field";
+    var policyLabel = document.createElement("div");
+    policyLabel.className = "channel-field-label";
+    policyLabel.textContent = "Delivery";
+    policyRow.appendChild(policyLabel);
+    var policy = document.createElement("select");
+    policy.className = "setting-select";
+    policy.setAttribute("data-setting", "policy");
+    var opts = [["any", "Delivered if any member succeeds"], ["all", "Only if every member succeeds"]];
+    for (var o = 0; o < opts.length; o++) {
+      var opt = document.createElement("option");
+      opt.value = opts[o][0];
+      opt.textContent = opts[o][1];
+      policy.appendChild(opt);
+    }
+    policy.value = settings.policy === "all" ? "all" : "any";
+    policyRow.appendChild(policy);
+    fieldsDiv.appendChild(policyRow);
+  }
   function addChannel() {
     var select = document.getElementById("channel-type-select");
     if (!select || !select.value) return;
@@ -4492,8 +5807,17 @@
   }
   function deleteChannel(index) {
     if (index < 0 || index >= notificationChannels.length) return;
-    var name = notificationChannels[index].name || notificationChannels[index].type;
+    collectChannelsFromDOM();
+    var removed = notificationChannels[index];
+    var name = removed.name || removed.type;
     notificationChannels.splice(index, 1);
+    for (var i = 0; i < notificationChannels.length; i++) {
+      if (notificationChannels[i].type !== "group") continue;
+      var gs = {};
+      try { gs = JSON.parse(notificationChannels[i].settings || "{}"); } catch(e) { gs = {}; }
+      gs.members = (gs.members || []).filter(function(id) { return id !== removed.id; });
+      notificationChannels[i].settings = JSON.stringify(gs);
+    }
     renderChannels();
     showToast("Removed " + name + " \u2014 save to apply", "info");
   }
@@ -4531,6 +5855,13 @@
           settings[key] = val;
         }
       }
+      if (notificationChannels[idx].type === "group") {
+        var memberBoxes = cards[i].querySelectorAll("[data-member]");
+        settings.members = [];
+        for (var mb = 0; mb < memberBoxes.length; mb++) {
+          if (memberBoxes[mb].checked) settings.members.push(memberBoxes[mb].getAttribute("data-member"));
+        }
+      }
       notificationChannels[idx].settings = JSON.stringify(settings);
       var pills = cards[i].querySelectorAll(".event-pill");
       var events = [];
@@ -4656,6 +5987,17 @@
       if (el) el.value = data.digest_time || "09:00";
       el = document.getElementById("digest-interval");
       if (el) el.value = data.digest_interval || "24h";
+      el = document.getElementById("queue-stale-after");
+      if (el) {
+        var staleAfter = data.queue_stale_after || "";
+        if (staleAfter && !el.querySelector('option[value="' + staleAfter + '"]')) {
+          var opt = document.createElement("option");
+          opt.value = staleAfter;
+          opt.textContent = "After " + staleAfter;
+          el.appendChild(opt);
+        }
+        el.value = staleAfter;
+      }
     }).catch(function() {
     });
   }
@@ -4669,6 +6011,8 @@
     if (el && el.value) body.digest_time = el.value;
     el = document.getElementById("digest-interval");
     if (el && el.value) body.digest_interval = el.value;
+    el = document.getElementById("queue-stale-after");
+    if (el) body.queue_stale_after = el.value;
     fetch("/api/settings/digest", {
       method: "POST",
       credentials: "same-origin",
@@ -4682,6 +6026,29 @@
       showToast("Failed to save settings", "error");
     });
   }
+  function saveExternalURL() {
+    var input = document.getElementById("external-url-input");
+    if (!input) return;
+    fetch("/api/settings/external-url", {
+      method: "POST",
+      credentials: "same-origin",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({ url: input.value.trim() })
+    }).then(function(res) {
+      return res.json().then(function(data) {
+        return { ok: res.ok, data };
+      });
+    }).then(function(result) {
+      if (!result.ok) {
+        showToast(result.data.error || "Failed to save external URL", "error");
+        return;
+      }
+      input.value = result.data.url || "";
+      showToast(result.data.message || "External URL saved", "success");
+    }).catch(function() {
+      showToast("Network error -- could not save external URL", "error");
+    });
+  }
   function triggerDigest() {
     fetch("/api/digest/trigger", {
       method: "POST",
@@ -4921,8 +6288,32 @@
     });
   }
   var _notifyTemplates = {};
+  function templateURL(suffix) {
+    var sel = document.getElementById("template-channel");
+    var id = sel ? sel.value : "";
+    if (!id) return "/api/settings/notifications/templates" + suffix;
+    return "/api/settings/notifications/" + encodeURIComponent(id) + "/template" + suffix;
+  }
+  function renderTemplateChannelOptions() {
+    var sel = document.getElementById("template-channel");
+    if (!sel) return;
+    var current = sel.value;
+    while (sel.options.length > 1) sel.remove(1);
+    for (var i = 0; i < notificationChannels.length; i++) {
+      var ch = notificationChannels[i];
+      var opt = document.createElement("option");
+      opt.value = ch.id;
+      opt.textContent = ch.name || ch.type;
+      sel.appendChild(opt);
+    }
+    sel.value = current;
+    if (sel.value !== current) {
+      sel.value = "";
+      loadNotifyTemplates();
+    }
+  }
   function loadNotifyTemplates() {
-    fetch("/api/settings/notifications/templates", { credentials: "same-origin" }).then(function(r) {
+    fetch(templateURL(""), { credentials: "same-origin" }).then(function(r) {
       return r.json();
     }).then(function(data) {
       _notifyTemplates = data && data.templates ? data.templates : {};
@@ -4946,7 +6337,7 @@
     if (!sel || !textarea) return;
     var eventType = sel.value;
     var tmpl = textarea.value;
-    fetch("/api/settings/notifications/templates", {
+    fetch(templateURL(""), {
       method: "PUT",
       credentials: "same-origin",
       headers: { "Content-Type": "application/json" },
@@ -4971,7 +6362,7 @@
     var textarea = document.getElementById("template-body");
     if (!sel) return;
     var eventType = sel.value;
-    fetch("/api/settings/notifications/templates/" + encodeURIComponent(eventType), {
+    fetch(templateURL("/" + encodeURIComponent(eventType)), {
       method: "DELETE",
       credentials: "same-origin"
     }).then(function(resp) {
@@ -4998,11 +6389,12 @@
     if (!sel || !textarea) return;
     var eventType = sel.value;
     var tmpl = textarea.value;
-    if (!tmpl) {
+    var channelSel = document.getElementById("template-channel");
+    if (!tmpl && !(channelSel && channelSel.value)) {
       showToast("Enter a template to preview", "info");
       return;
     }
-    fetch("/api/settings/notifications/templates/preview", {
+    fetch(templateURL("/preview"), {
       method: "POST",
       credentials: "same-origin",
       headers: { "Content-Type": "application/json" },
@@ -6074,6 +7466,197 @@
     });
   }
 
+  var _networks = [];
+  var _volumes = [];
+
+  async function loadResources() {
+    try {
+      var responses = await Promise.all([fetch("/api/networks"), fetch("/api/volumes")]);
+      if (responses[0].ok) _networks = (await responses[0].json()).networks || [];
+      if (responses[1].ok) _volumes = (await responses[1].json()).volumes || [];
+      renderNetworksTable();
+      renderVolumesTable();
+    } catch (err) {
+      console.error("Failed to load networks and volumes:", err);
+    }
+  }
+
+  function resourceCell(row, text, className) {
+    var cell = document.createElement("td");
+    if (className) cell.className = className;
+    cell.textContent = text;
+    row.appendChild(cell);
+    return cell;
+  }
+
+  function attachedCell(row, containers) {
+    var cell = document.createElement("td");
+    if (containers && containers.length > 0) {
+      cell.textContent = containers.join(", ");
+    } else {
+      var none = document.createElement("span");
+      none.className = "text-muted";
+      none.textContent = "None";
+      cell.appendChild(none);
+    }
+    row.appendChild(cell);
+  }
+
+  function statusCell(row, item) {
+    var cell = document.createElement("td");
+    var badge = document.createElement("span");
+    if (item.builtin) {
+      badge.className = "badge badge-muted";
+      badge.textContent = "Built-in";
+    } else if (item.orphan) {
+      badge.className = "badge badge-error";
+      badge.textContent = "Orphan";
+    } else {
+      badge.className = "badge badge-success";
+      badge.textContent = "In Use";
+    }
+    cell.appendChild(badge);
+    row.appendChild(cell);
+  }
+
+  function removeCell(row, item, onRemove) {
+    var cell = document.createElement("td");
+    var btn = document.createElement("button");
+    btn.className = "btn btn-sm btn-danger";
+    btn.textContent = "Remove";
+    if (!item.orphan) {
+      btn.disabled = true;
+      btn.title = item.builtin ? "Built-in networks cannot be removed" : "Cannot remove: in use by a container";
+    } else {
+      btn.addEventListener("click", function() { onRemove(btn); });
+    }
+    cell.appendChild(btn);
+    row.appendChild(cell);
+  }
+
+  function renderResourceRows(tbodyId, summaryId, noun, items, cols, buildRow) {
+    var tbody = document.getElementById(tbodyId);
+    if (!tbody) return;
+    while (tbody.firstChild) tbody.removeChild(tbody.firstChild);
+
+    var orphans = items.filter(function(item) { return item.orphan; }).length;
+    var summaryEl = document.getElementById(summaryId);
+    if (summaryEl) summaryEl.textContent = items.length + " " + noun + " (" + orphans + " orphaned)";
+
+    if (items.length === 0) {
+      var emptyRow = document.createElement("tr");
+      var emptyCell = resourceCell(emptyRow, "No " + noun + " found");
+      emptyCell.colSpan = cols;
+      emptyCell.style.textAlign = "center";
+      emptyCell.style.color = "var(--text-secondary)";
+      tbody.appendChild(emptyRow);
+      return;
+    }
+
+    // Orphans first so they are easy to spot, then by name.
+    var sorted = items.slice().sort(function(a, b) {
+      if (a.orphan !== b.orphan) return a.orphan ? -1 : 1;
+      return a.name < b.name ? -1 : a.name > b.name ? 1 : 0;
+    });
+    for (var i = 0; i < sorted.length; i++) {
+      tbody.appendChild(buildRow(sorted[i]));
+    }
+  }
+
+  function renderNetworksTable() {
+    renderResourceRows("networks-tbody", "networks-summary", "networks", _networks, 6, function(net) {
+      var row = document.createElement("tr");
+      var nameCell = resourceCell(row, net.name);
+      nameCell.title = net.id;
+      resourceCell(row, net.driver + (net.internal ? " (internal)" : ""));
+      resourceCell(row, net.scope);
+      attachedCell(row, net.containers);
+      statusCell(row, net);
+      removeCell(row, net, function(btn) { removeNetwork(net.id, net.name, btn); });
+      return row;
+    });
+  }
+
+  function renderVolumesTable() {
+    renderResourceRows("volumes-tbody", "volumes-summary", "volumes", _volumes, 5, function(vol) {
+      var row = document.createElement("tr");
+      var nameCell = resourceCell(row, vol.name.length > 32 ? vol.name.substring(0, 12) + '\u2026' : vol.name);
+      nameCell.title = vol.mountpoint || vol.name;
+      resourceCell(row, vol.driver);
+      attachedCell(row, vol.containers);
+      statusCell(row, vol);
+      removeCell(row, vol, function(btn) { removeVolume(vol.name, btn); });
+      return row;
+    });
+  }
+
+  function removeNetwork(id, name, btn) {
+    showConfirm(
+      "Remove Network",
+      "<p>Remove network <strong>" + escapeHTML(name) + "</strong>? No containers are attached to it.</p>",
+      { danger: true, confirmLabel: "Remove" }
+    ).then(function(confirmed) {
+      if (!confirmed) return;
+      apiFetch("/api/networks/" + encodeURIComponent(id), {
+        method: "DELETE",
+        triggerEl: btn,
+        successMsg: "Network removed",
+        errorMsg: "Remove failed",
+        onSuccess: function() { loadResources(); }
+      });
+    });
+  }
+
+  function removeVolume(name, btn) {
+    showConfirm(
+      "Remove Volume",
+      "<p>Remove volume <strong>" + escapeHTML(name) + "</strong>? Its data will be deleted and cannot be recovered.</p>",
+      { danger: true, confirmLabel: "Remove" }
+    ).then(function(confirmed) {
+      if (!confirmed) return;
+      apiFetch("/api/volumes/" + encodeURIComponent(name), {
+        method: "DELETE",
+        triggerEl: btn,
+        successMsg: "Volume removed",
+        errorMsg: "Remove failed",
+        onSuccess: function() { loadResources(); }
+      });
+    });
+  }
+
+  // removeOrphans removes every orphaned network or volume after one confirmation.
+  function removeOrphans(kind) {
+    var items = (kind === "volumes" ? _volumes : _networks).filter(function(item) { return item.orphan; });
+    if (items.length === 0) {
+      showToast("No orphaned " + kind, "info");
+      return;
+    }
+    var noun = items.length === 1 ? kind.slice(0, -1) : kind;
+    var warning = kind === "volumes" ? " Their data will be deleted and cannot be recovered." : "";
+    showConfirm(
+      "Remove Orphaned " + (kind === "volumes" ? "Volumes" : "Networks"),
+      "<p>Remove " + items.length + " orphaned " + noun + "?" + warning + "</p>",
+      { danger: true, confirmLabel: "Remove" }
+    ).then(async function(confirmed) {
+      if (!confirmed) return;
+      var removed = 0;
+      var failed = 0;
+      for (var i = 0; i < items.length; i++) {
+        var key = kind === "volumes" ? items[i].name : items[i].id;
+        try {
+          var resp = await fetch("/api/" + kind + "/" + encodeURIComponent(key), { method: "DELETE" });
+          if (resp.ok) { removed++; } else { failed++; }
+        } catch (_) { failed++; }
+      }
+      if (failed > 0) {
+        showToast("Removed " + removed + ", failed " + failed, "warning");
+      } else {
+        showToast("Removed " + removed + " " + noun, "success");
+      }
+      loadResources();
+    });
+  }
+
   // internal/web/static/src/js/logs.js
   var _allLogs = [];
   var _currentType = "all";
@@ -6090,6 +7673,9 @@
       "restart",
       "start",
       "stop",
+      "pause",
+      "unpause",
+      "kill",
       "scale",
       "scan",
       "webhook",
@@ -6099,7 +7685,7 @@
     ],
     policy: ["policy_set", "policy_delete", "bulk_policy", "notify_pref", "notify_states_cleared"],
     auth: ["auth"],
-    settings: ["settings", "cluster-settings", "config-import", "digest", "hooks"]
+    settings: ["settings", "cluster-settings", "cluster", "config-import", "digest", "hooks"]
   };
   var TYPE_BADGE = {
     policy_set: "badge-info",
@@ -6111,6 +7697,9 @@
     start: "badge-success",
     stop: "badge-error",
     restart: "badge-warning",
+    pause: "badge-warning",
+    unpause: "badge-success",
+    kill: "badge-error",
     auth: "badge-info",
     settings: "badge-muted",
     scan: "badge-info",
@@ -6264,6 +7853,138 @@
     return date.toLocaleDateString();
   }
 
+  // internal/web/static/src/js/timeline.js
+  var _timelineEntries = [];
+  var _timelineNext = "";
+  var _timelineSource = "all";
+  var TIMELINE_SOURCE_BADGE = {
+    log: { cls: "badge-muted", label: "Activity" },
+    history: { cls: "badge-info", label: "Update" },
+    cluster: { cls: "badge-warning", label: "Cluster" }
+  };
+  var TIMELINE_TYPE_BADGE = {
+    success: "badge-success",
+    rollback_success: "badge-warning",
+    connected: "badge-success",
+    disconnected: "badge-warning",
+    approve: "badge-success",
+    reject: "badge-error"
+  };
+  function timelineQuery() {
+    var params = new URLSearchParams();
+    var since = document.getElementById("timeline-since");
+    if (since && since.value) params.set("since", since.value);
+    var container = document.getElementById("timeline-container");
+    if (container && container.value.trim()) params.set("container", container.value.trim());
+    var host = document.getElementById("timeline-host");
+    if (host && host.value.trim()) params.set("host", host.value.trim());
+    if (_timelineSource !== "all") params.set("type", _timelineSource);
+    return params;
+  }
+  async function loadTimeline() {
+    _timelineEntries = [];
+    _timelineNext = "";
+    await fetchTimeline(timelineQuery());
+  }
+  async function loadMoreTimeline() {
+    if (!_timelineNext) return;
+    var params = timelineQuery();
+    params.set("before", _timelineNext);
+    await fetchTimeline(params);
+  }
+  function filterTimeline(source) {
+    _timelineSource = source;
+    var pills = document.querySelectorAll(".timeline-source-pill");
+    for (var i = 0; i < pills.length; i++) {
+      pills[i].classList.toggle("active", pills[i].getAttribute("data-source") === source);
+    }
+    loadTimeline();
+  }
+  async function fetchTimeline(params) {
+    try {
+      var resp = await fetch("/api/timeline?" + params.toString());
+      if (!resp.ok) throw new Error("HTTP " + resp.status);
+      var data = await resp.json();
+      var entries = Array.isArray(data.entries) ? data.entries : [];
+      _timelineEntries = _timelineEntries.concat(entries);
+      _timelineNext = data.next_cursor || "";
+      renderTimeline();
+    } catch (err) {
+      console.error("Failed to load timeline:", err);
+      if (window.showToast) window.showToast("Failed to load timeline", "error");
+    }
+  }
+  function renderTimeline() {
+    var tbody = document.getElementById("timeline-tbody");
+    if (!tbody) return;
+    while (tbody.firstChild) tbody.removeChild(tbody.firstChild);
+    var summary = document.getElementById("timeline-summary");
+    if (summary) {
+      summary.textContent = _timelineEntries.length + (_timelineNext ? "+" : "") + " events";
+    }
+    var more = document.getElementById("timeline-more");
+    if (more) more.style.display = _timelineNext ? "" : "none";
+    if (_timelineEntries.length === 0) {
+      var tr = document.createElement("tr");
+      var td = document.createElement("td");
+      td.colSpan = 5;
+      var emptyDiv = document.createElement("div");
+      emptyDiv.className = "empty-state";
+      var msg = document.createElement("p");
+      msg.textContent = "Nothing happened in this period.";
+      emptyDiv.appendChild(msg);
+      td.appendChild(emptyDiv);
+      tr.appendChild(td);
+      tbody.appendChild(tr);
+      return;
+    }
+    for (var i = 0; i < _timelineEntries.length; i++) {
+      var e = _timelineEntries[i];
+      var row = document.createElement("tr");
+      var timeCell = document.createElement("td");
+      var ts = new Date(e.timestamp);
+      timeCell.title = ts.toISOString();
+      timeCell.textContent = ts.toLocaleString();
+      row.appendChild(timeCell);
+      var sourceCell = document.createElement("td");
+      var src = TIMELINE_SOURCE_BADGE[e.source] || { cls: "badge-muted", label: e.source };
+      var srcBadge = document.createElement("span");
+      srcBadge.className = "badge " + src.cls;
+      srcBadge.textContent = src.label;
+      sourceCell.appendChild(srcBadge);
+      row.appendChild(sourceCell);
+      var typeCell = document.createElement("td");
+      var typeBadge = document.createElement("span");
+      typeBadge.className = "badge " + (e.failed ? "badge-error" : (TIMELINE_TYPE_BADGE[e.type] || "badge-muted"));
+      typeBadge.textContent = e.type;
+      typeCell.appendChild(typeBadge);
+      row.appendChild(typeCell);
+      var containerCell = document.createElement("td");
+      containerCell.className = "mono";
+      if (e.container) {
+        var link = document.createElement("a");
+        link.href = "/container/" + encodeURIComponent(e.container) + (e.host_id ? "?host=" + encodeURIComponent(e.host_id) : "");
+        link.textContent = e.container;
+        containerCell.appendChild(link);
+      } else {
+        containerCell.textContent = "-";
+      }
+      if (e.host_id) {
+        var host = document.createElement("span");
+        host.className = "host-badge";
+        host.title = "Host: " + e.host_id;
+        host.textContent = e.host_name || e.host_id;
+        containerCell.appendChild(host);
+      }
+      row.appendChild(containerCell);
+      var msgCell = document.createElement("td");
+      msgCell.title = e.message;
+      msgCell.textContent = e.user ? e.user + ": " + e.message : e.message;
+      row.appendChild(msgCell);
+      tbody.appendChild(row);
+    }
+  }
+
   // internal/web/static/src/js/main.js
   setUpdateStatsFn(updateStats2);
   window._dashboardSelectedContainers = selectedContainers;
@@ -6277,6 +7998,7 @@
   window.apiFetch = apiFetch;
   window.activateFilter = activateFilter;
   window.resumeScanning = resumeScanning;
+  window.refreshCapabilities = refreshCapabilities;
   window.expandAllStacks = expandAllStacks;
   window.collapseAllStacks = collapseAllStacks;
   window.toggleManageMode = function() {
@@ -6302,16 +8024,20 @@
   window.fetchContainerLogs = fetchContainerLogs;
   window.toggleLogStream = toggleLogStream;
   window.containerAction = containerAction;
+  window.killContainer = killContainer;
   window.bulkContainerAction = bulkContainerAction;
   window.toggleDashboardShortcutsHelp = toggleDashboardShortcutsHelp;
   window.toggleQueueAccordion = toggleQueueAccordion;
   window.approveUpdate = approveUpdate;
   window.ignoreUpdate = ignoreUpdate;
   window.rejectUpdate = rejectUpdate;
+  window.saveQueueComment = saveQueueComment;
   window.approveAll = approveAll;
   window.ignoreAll = ignoreAll;
   window.rejectAll = rejectAll;
   window.triggerUpdate = triggerUpdate;
+  window.triggerStackUpdate = triggerStackUpdate;
+  window.triggerComposeSync = triggerComposeSync;
   window.triggerCheck = triggerCheck;
   window.triggerRollback = triggerRollback;
   window.changePolicy = changePolicy;
@@ -6322,6 +8048,8 @@
   window.initQueueKeyboard = initQueueKeyboard;
   window.cleanupQueueKeyboard = cleanupQueueKeyboard;
   window.toggleShortcutsHelp = toggleShortcutsHelp;
+  window.loadActiveUpdates = loadActiveUpdates;
+  window.forceUnlockUpdate = forceUnlockUpdate;
   window.toggleSvc = toggleSvc;
   window.triggerSvcUpdate = triggerSvcUpdate;
   window.changeSvcPolicy = changeSvcPolicy;
@@ -6344,6 +8072,8 @@
   window.setLatestAutoUpdate = setLatestAutoUpdate;
   window.setPauseState = setPauseState;
   window.saveFilters = saveFilters;
+  window.saveImageRewrites = saveImageRewrites;
+  window.testFilters = testFilters;
   window.setImageCleanup = setImageCleanup;
   window.saveCronSchedule = saveCronSchedule;
   window.setDependencyAware = setDependencyAware;
@@ -6352,11 +8082,26 @@
   window.setDryRun = setDryRun;
   window.setPullOnly = setPullOnly;
   window.setUpdateDelay = setUpdateDelay;
+  window.setHealthTimeout = setHealthTimeout;
+  window.setBlueGreenPortRange = setBlueGreenPortRange;
+  window.saveExternalValidation = saveExternalValidation;
+  window.setStuckUpdateThreshold = setStuckUpdateThreshold;
+  window.setAuditRetention = setAuditRetention;
   window.setComposeSync = setComposeSync;
+  window.setConfigDiff = setConfigDiff;
+  window.setRebuildWatch = setRebuildWatch;
+  window.saveRebuildWebhook = saveRebuildWebhook;
+  window.setPullCheckRegistry = setPullCheckRegistry;
+  window.savePullCheckDiskPath = savePullCheckDiskPath;
+  window.saveRetention = saveRetention;
+  window.compactDatabase = compactDatabase;
+  window.saveComposePath = saveComposePath;
+  window.deleteComposePath = deleteComposePath;
   window.setImageBackup = setImageBackup;
   window.setShowStopped = setShowStopped;
   window.setRemoveVolumes = setRemoveVolumes;
   window.setScanConcurrency = setScanConcurrency;
+  window.setAutoUpdateCap = setAutoUpdateCap;
   window.setHADiscovery = setHADiscovery;
   window.saveHADiscoveryPrefix = saveHADiscoveryPrefix;
   window.updateToggleText = updateToggleText;
@@ -6367,14 +8112,26 @@
   window.regenerateWebhookSecret = regenerateWebhookSecret;
   window.copyWebhookURL = copyWebhookURL;
   window.copyWebhookSecret = copyWebhookSecret;
+  window.regenerateRegistryWebhookToken = regenerateRegistryWebhookToken;
+  window.copyRegistryWebhookURL = copyRegistryWebhookURL;
   window.saveMaintenanceWindow = saveMaintenanceWindow;
   window.exportConfig = exportConfig;
   window.importConfig = importConfig;
+  window.exportQueueState = exportQueueState;
+  window.importQueueState = importQueueState;
+  window.downloadDatabaseBackup = downloadDatabaseBackup;
+  window.saveBackupSettings = saveBackupSettings;
+  window.restoreDatabase = restoreDatabase;
   window.saveDashboardColumns = saveDashboardColumns;
   window.loadScannerSettings = loadScannerSettings;
   window.saveScannerSettings = saveScannerSettings;
   window.loadVerifierSettings = loadVerifierSettings;
   window.saveVerifierSettings = saveVerifierSettings;
+  window.addTrustedKey = addTrustedKey;
+  window.deleteTrustedKey = deleteTrustedKey;
+  window.clearVerifyCache = clearVerifyCache;
+  window.createDeployToken = createDeployToken;
+  window.deleteDeployToken = deleteDeployToken;
   window.loadRetrySettings = loadRetrySettings;
   window.saveRetrySettings = saveRetrySettings;
   window.loadDashboardColumns = loadDashboardColumns;
@@ -6387,6 +8144,7 @@
   window.testNotification = testNotification;
   window.onNotifyModeChange = onNotifyModeChange;
   window.saveDigestSettings = saveDigestSettings;
+  window.saveExternalURL = saveExternalURL;
   window.triggerDigest = triggerDigest;
   window.saveNotifyPref = setContainerNotifyPref;
   window.loadNotificationChannels = loadNotificationChannels;
@@ -6413,9 +8171,14 @@
   window.toggleImageSelect = toggleImageSelect;
   window.toggleImageSelectAll = toggleSelectAll;
   window.removeSelectedImages = removeSelectedImages;
+  window.loadResources = loadResources;
+  window.removeOrphans = removeOrphans;
   window.loadActivityLogs = loadActivityLogs;
   window.filterLogs = filterLogs;
   window.exportLogs = exportLogs;
+  window.loadTimeline = loadTimeline;
+  window.loadMoreTimeline = loadMoreTimeline;
+  window.filterTimeline = filterTimeline;
   (function initHamburger() {
     var btn = document.querySelector(".nav-hamburger");
     var links = document.querySelector(".nav-links");
@@ -6465,7 +8228,10 @@
     initAccordionPersistence();
     openAccordionFromHash();
     initQueueKeyboard();
+    initQueueReorder();
+    loadActiveUpdates();
     initDashboardKeyboard();
+    initUnmanagedSection();
     (function initHealthDot() {
       var navStatus = document.querySelector(".nav-status");
       if (!navStatus) return;
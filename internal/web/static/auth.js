@@ -299,7 +299,21 @@ function loadUsers() {
                 tdStatus.appendChild(statusBadge);
                 tr.appendChild(tdStatus);
 
+                var tdScope = document.createElement("td");
+                tdScope.textContent = formatContainerScope(u.container_scope) || "All containers";
+                tr.appendChild(tdScope);
+
                 var tdActions = document.createElement("td");
+                var scopeBtn = document.createElement("button");
+                scopeBtn.className = "btn";
+                scopeBtn.textContent = "Scope";
+                scopeBtn.setAttribute("data-user-id", u.id);
+                scopeBtn.setAttribute("data-scope", formatContainerScope(u.container_scope));
+                scopeBtn.addEventListener("click", function() {
+                    editUserScope(this.getAttribute("data-user-id"), this.getAttribute("data-scope"));
+                });
+                tdActions.appendChild(scopeBtn);
+                tdActions.appendChild(document.createTextNode(" "));
                 var delBtn = document.createElement("button");
                 delBtn.className = "btn btn-error";
                 delBtn.textContent = "Delete";
@@ -351,6 +365,65 @@ function createUser() {
         });
 }
 
+// Container scopes are edited as comma-separated selectors: a bare value is
+// a name glob, "stack:" a Compose project and "label:" a key or key=value.
+function formatContainerScope(scope) {
+    if (!scope) return "";
+    var parts = [];
+    (scope.names || []).forEach(function(n) { parts.push(n); });
+    (scope.stacks || []).forEach(function(p) { parts.push("stack:" + p); });
+    (scope.labels || []).forEach(function(l) { parts.push("label:" + l); });
+    return parts.join(", ");
+}
+
+function parseContainerScope(text) {
+    var scope = { names: [], stacks: [], labels: [] };
+    text.split(",").forEach(function(part) {
+        part = part.trim();
+        if (!part) return;
+        if (part.indexOf("stack:") === 0) {
+            scope.stacks.push(part.slice(6).trim());
+        } else if (part.indexOf("label:") === 0) {
+            scope.labels.push(part.slice(6).trim());
+        } else {
+            scope.names.push(part);
+        }
+    });
+    return scope;
+}
+
+function editUserScope(id, current) {
+    var text = window.prompt(
+        "Containers this user may see and update.\n\n" +
+        "Comma-separated: name globs (shop-*), stack:<project>, label:<key> or label:<key>=<value>. " +
+        "Leave empty for all containers.",
+        current || ""
+    );
+    if (text === null) return;
+
+    authFetch("/api/auth/users/" + encodeURIComponent(id) + "/scope", {
+        method: "PUT",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify(parseContainerScope(text))
+    })
+        .then(function(resp) {
+            return resp.json().then(function(data) { return { ok: resp.ok, data: data }; });
+        })
+        .then(function(result) {
+            if (result.ok) {
+                showToast("Container scope updated", "success");
+                loadUsers();
+            } else {
+                showToast(result.data.error || "Failed to update scope", "error");
+            }
+        })
+        .catch(function(err) {
+            if (err.message !== "Unauthorized") {
+                showToast("Network error", "error");
+            }
+        });
+}
+
 function deleteUser(id) {
     authFetch("/api/auth/users/" + encodeURIComponent(id), {
         method: "DELETE"
@@ -623,7 +696,9 @@ function confirmTOTPSetup() {
             return;
         }
 
-        showToast("Two-factor authentication enabled", "success");
+        var revoked = result.data.sessions_revoked || 0;
+        showToast("Two-factor authentication enabled" +
+            (revoked ? "; signed out " + revoked + " other session" + (revoked === 1 ? "" : "s") : ""), "success");
 
         // Show recovery codes.
         var setupDiv = document.getElementById("totp-setup-flow");
@@ -695,6 +770,9 @@ function copyRecoveryCodes() {
    9. OIDC / SSO Settings
    ------------------------------------------------------------ */
 
+// Group claim as loaded, resent on save so it is not reset to the default.
+var oidcGroupClaim = "";
+
 function loadOIDCSettings() {
     var toggle = document.getElementById("oidc-enabled");
     if (!toggle) return; // Not on settings page or not admin.
@@ -728,6 +806,33 @@ function loadOIDCSettings() {
                     }
                 }
             }
+
+            oidcGroupClaim = data.group_claim || "";
+            var roleRules = document.getElementById("oidc-role-rules");
+            if (roleRules) roleRules.value = formatOIDCRoleRules(data.group_mappings, data.role_rules);
+
+            var postLogout = document.getElementById("oidc-post-logout-redirect-url");
+            var backchannel = document.getElementById("oidc-backchannel-logout");
+            var backchannelURL = document.getElementById("oidc-backchannel-url");
+            var revalidate = document.getElementById("oidc-revalidate-interval");
+
+            if (postLogout) postLogout.value = data.post_logout_redirect_url || "";
+            if (backchannel) {
+                backchannel.checked = !!data.backchannel_logout;
+                updateToggleTextLocal("oidc-backchannel-logout-text", !!data.backchannel_logout);
+            }
+            if (backchannelURL) backchannelURL.textContent = window.location.origin + "/api/auth/oidc/backchannel-logout";
+            if (revalidate) {
+                var interval = data.revalidate_interval || "";
+                // Keep custom values set via config import selectable.
+                if (interval && !revalidate.querySelector('option[value="' + interval + '"]')) {
+                    var opt = document.createElement("option");
+                    opt.value = interval;
+                    opt.textContent = "Every " + interval;
+                    revalidate.appendChild(opt);
+                }
+                revalidate.value = interval;
+            }
         })
         .catch(function() { /* ignore -- settings not available */ });
 }
@@ -740,6 +845,14 @@ function saveOIDCSettings() {
     var redirectUrl = (document.getElementById("oidc-redirect-url") || {}).value || "";
     var autoCreate = (document.getElementById("oidc-auto-create") || {}).checked || false;
     var defaultRole = (document.getElementById("oidc-default-role") || {}).value || "viewer";
+    var postLogoutRedirectUrl = (document.getElementById("oidc-post-logout-redirect-url") || {}).value || "";
+    var backchannelLogout = (document.getElementById("oidc-backchannel-logout") || {}).checked || false;
+    var revalidateInterval = (document.getElementById("oidc-revalidate-interval") || {}).value || "";
+    var mapping = parseOIDCRoleRules((document.getElementById("oidc-role-rules") || {}).value || "");
+    if (mapping.error) {
+        showToast(mapping.error, "error");
+        return;
+    }
 
     // Auto-detect redirect URL if empty and enabled.
     if (enabled && !redirectUrl) {
@@ -756,7 +869,13 @@ function saveOIDCSettings() {
             client_secret: clientSecret,
             redirect_url: redirectUrl,
             auto_create: autoCreate,
-            default_role: defaultRole
+            default_role: defaultRole,
+            group_claim: oidcGroupClaim,
+            group_mappings: mapping.groups,
+            role_rules: mapping.rules,
+            post_logout_redirect_url: postLogoutRedirectUrl,
+            backchannel_logout: backchannelLogout,
+            revalidate_interval: revalidateInterval
         })
     })
     .then(function(resp) {
@@ -780,6 +899,51 @@ function saveOIDCSettings() {
     });
 }
 
+// formatOIDCRoleRules renders group mappings and claim rules as the
+// "match => role" lines edited in the role mapping textarea.
+function formatOIDCRoleRules(groups, rules) {
+    var lines = [];
+    Object.keys(groups || {}).sort().forEach(function(group) {
+        lines.push(group + " => " + groups[group]);
+    });
+    (rules || []).forEach(function(rule) {
+        var op = rule.match === "regex" ? " ~ " : " = ";
+        lines.push((rule.claim || oidcGroupClaim || "groups") + op + rule.value + " => " + rule.role);
+    });
+    return lines.join("\n");
+}
+
+// parseOIDCRoleRules parses the role mapping textarea. Bare names become
+// group mappings; "claim = value" and "claim ~ regex" become claim rules.
+function parseOIDCRoleRules(text) {
+    var groups = {};
+    var rules = [];
+    var lines = text.split("\n");
+    for (var i = 0; i < lines.length; i++) {
+        var line = lines[i].trim();
+        if (!line) continue;
+        var arrow = line.lastIndexOf("=>");
+        if (arrow < 0) {
+            return { error: "Role mapping line " + (i + 1) + ": expected match => role" };
+        }
+        var match = line.slice(0, arrow).trim();
+        var role = line.slice(arrow + 2).trim();
+        if (!match || !role) {
+            return { error: "Role mapping line " + (i + 1) + ": expected match => role" };
+        }
+        var re = match.indexOf(" ~ ");
+        var eq = match.indexOf(" = ");
+        if (re >= 0 && (eq < 0 || re < eq)) {
+            rules.push({ claim: match.slice(0, re).trim(), match: "regex", value: match.slice(re + 3).trim(), role: role });
+        } else if (eq >= 0) {
+            rules.push({ claim: match.slice(0, eq).trim(), match: "exact", value: match.slice(eq + 3).trim(), role: role });
+        } else {
+            groups[match] = role;
+        }
+    }
+    return { groups: groups, rules: rules };
+}
+
 // Local helper — avoids dependency on app.js updateToggleText which may not be loaded.
 function updateToggleTextLocal(textId, enabled) {
     var text = document.getElementById(textId);
@@ -789,7 +953,52 @@ function updateToggleTextLocal(textId, enabled) {
 }
 
 /* ------------------------------------------------------------
-   10. Init
+   10. Sign-in diagnostics
+   ------------------------------------------------------------ */
+
+// Shows why sign-in may not stick on this address. Errors are always shown;
+// warnings too when a sign-in a moment ago landed back on this page.
+function loadLoginDiagnostics() {
+    var box = document.getElementById("login-diagnostics");
+    if (!box) return;
+    var since = parseInt(sessionStorage.getItem("login_redirected") || "0", 10);
+    sessionStorage.removeItem("login_redirected");
+    var looped = since > 0 && Date.now() - since < 30000;
+
+    fetch("/api/auth/diagnostics?origin=" + encodeURIComponent(window.location.origin))
+        .then(function(resp) { return resp.ok ? resp.json() : null; })
+        .then(function(data) {
+            if (!data) return;
+            var shown = [];
+            var checks = data.checks || [];
+            for (var i = 0; i < checks.length; i++) {
+                if (checks[i].status === "error" || (looped && checks[i].status === "warn")) {
+                    shown.push(checks[i].message);
+                }
+            }
+            if (!shown.length && !looped) return;
+
+            while (box.firstChild) box.removeChild(box.firstChild);
+            var title = document.createElement("strong");
+            title.textContent = looped ? "You signed in but were sent back here." : "Signing in may not work on this address.";
+            box.appendChild(title);
+            if (!shown.length) {
+                shown.push("The browser did not keep the session cookie. Check that cookies are allowed for this site.");
+            }
+            var list = document.createElement("ul");
+            for (var j = 0; j < shown.length; j++) {
+                var li = document.createElement("li");
+                li.textContent = shown[j];
+                list.appendChild(li);
+            }
+            box.appendChild(list);
+            box.style.display = "";
+        })
+        .catch(function() { /* diagnostics are best-effort */ });
+}
+
+/* ------------------------------------------------------------
+   11. Init
    ------------------------------------------------------------ */
 
 document.addEventListener("DOMContentLoaded", function() {
@@ -797,6 +1006,7 @@ document.addEventListener("DOMContentLoaded", function() {
     loadUsers();
     loadTOTPStatus();
     loadOIDCSettings();
+    loadLoginDiagnostics();
 
     // Intercept login form to handle suggest_passkey and TOTP responses.
     var loginForm = document.getElementById("login-form");
@@ -827,6 +1037,7 @@ document.addEventListener("DOMContentLoaded", function() {
                     if (result.data.suggest_passkey) {
                         sessionStorage.setItem("suggest_passkey", "1");
                     }
+                    sessionStorage.setItem("login_redirected", String(Date.now()));
                     window.location.href = result.data.redirect || "/";
                 } else {
                     var errDiv = document.querySelector(".login-error");
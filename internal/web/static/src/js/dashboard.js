@@ -4,7 +4,7 @@
    manage mode, drag reorder
    ============================================================ */
 
-import { showToast, escapeHTML, showConfirm } from "./utils.js";
+import { showToast, escapeHTML, showConfirm, apiFetch } from "./utils.js";
 
 /* ------------------------------------------------------------
    0. Column Visibility
@@ -189,6 +189,35 @@ function resumeScanning() {
         });
 }
 
+function refreshCapabilities(event) {
+    var btn = event && event.target ? event.target.closest(".btn") : null;
+    if (btn) btn.disabled = true;
+    fetch("/api/capabilities/refresh", { method: "POST" })
+        .then(function(resp) {
+            return resp.json().then(function(data) {
+                return { ok: resp.ok, data: data };
+            });
+        })
+        .then(function(result) {
+            if (!result.ok) {
+                showToast(result.data.error || "Failed to re-check permissions", "error");
+                if (btn) btn.disabled = false;
+                return;
+            }
+            if (result.data.update_blocked) {
+                showToast(result.data.update_blocked, "warning");
+                if (btn) btn.disabled = false;
+                return;
+            }
+            showToast("Docker permissions OK", "success");
+            window.location.reload();
+        })
+        .catch(function() {
+            showToast("Network error — could not re-check permissions", "error");
+            if (btn) btn.disabled = false;
+        });
+}
+
 function checkPauseState() {
     var banner = document.getElementById("pause-banner");
     if (!banner) return;
@@ -1388,6 +1417,26 @@ function containerAction(action, btn) {
     );
 }
 
+function killContainer(btn) {
+    var name = window._containerName || (typeof _containerName !== 'undefined' ? _containerName : '');
+    var hostId = window._containerHostId || (typeof _containerHostId !== 'undefined' ? _containerHostId : '');
+    if (!name) return;
+    var select = document.getElementById('kill-signal');
+    var signal = select ? select.value : 'SIGKILL';
+
+    var endpoint = '/api/containers/' + encodeURIComponent(name) + '/kill';
+    if (hostId) endpoint += '?host=' + encodeURIComponent(hostId);
+
+    showConfirm('Kill ' + name, '<p>Send <strong>' + escapeHTML(signal) + '</strong> to ' + escapeHTML(name) + '?</p>').then(function(confirmed) {
+        if (!confirmed) return;
+        apiPost(endpoint, { signal: signal },
+            signal + ' sent',
+            'Failed to kill ' + name,
+            btn
+        );
+    });
+}
+
 function togglePorts(el, e) {
     e.stopPropagation();
     el.closest('.cell-ports').classList.toggle('expanded');
@@ -1498,6 +1547,82 @@ function toggleDashboardShortcutsHelp() {
     _dashboardShortcutsVisible = true;
 }
 
+/* ------------------------------------------------------------
+   12. Unmanaged Containers
+   ------------------------------------------------------------ */
+
+// Containers Sentinel has been told to leave alone. Hidden ones are left
+// out of the dashboard table, so this section is the way back for them.
+var _unmanagedItems = [];
+
+function _unmanagedURL(item) {
+    var url = "/api/containers/" + encodeURIComponent(item.name) + "/unmanaged";
+    if (item.host_id) url += "?host=" + encodeURIComponent(item.host_id);
+    return url;
+}
+
+function loadUnmanaged() {
+    var section = document.getElementById("unmanaged-section");
+    if (!section) return;
+    fetch("/api/unmanaged", { credentials: "same-origin" })
+        .then(function (res) { return res.ok ? res.json() : []; })
+        .then(function (items) {
+            _unmanagedItems = items || [];
+            var html = "";
+            for (var i = 0; i < _unmanagedItems.length; i++) {
+                var item = _unmanagedItems[i];
+                html += '<tr data-index="' + i + '">' +
+                    '<td class="mono">' + escapeHTML(item.name) + '</td>' +
+                    '<td>' + (item.host_id ? escapeHTML(item.host_id) : "local") + '</td>' +
+                    '<td>' + escapeHTML(new Date(item.since).toLocaleString()) + '</td>' +
+                    '<td>' + (item.hidden
+                        ? '<span class="badge badge-muted">hidden</span>'
+                        : '<span class="badge badge-info">shown</span>') + '</td>' +
+                    '<td class="unmanaged-actions">' +
+                    (item.hidden ? '<button class="btn btn-sm" data-unmanaged-action="unhide">Unhide</button>' : '') +
+                    '<button class="btn btn-sm btn-success" data-unmanaged-action="manage">Manage</button>' +
+                    '</td></tr>';
+            }
+            document.getElementById("unmanaged-body").innerHTML = html;
+            document.getElementById("unmanaged-count").textContent = _unmanagedItems.length;
+            section.style.display = _unmanagedItems.length ? "" : "none";
+        })
+        .catch(function () {});
+}
+
+// Unhide keeps the container unmanaged but lists it on the dashboard again;
+// Manage returns it to normal update checks. Both change the dashboard
+// table, so the page reloads on success.
+function unmanagedAction(item, action, btn) {
+    var opts = {
+        triggerEl: btn,
+        errorMsg: "Failed to update " + item.name,
+        onSuccess: function () { window.location.reload(); }
+    };
+    if (action === "unhide") {
+        opts.method = "PUT";
+        opts.body = { hidden: false };
+        opts.successMsg = item.name + " shown on the dashboard";
+    } else {
+        opts.method = "DELETE";
+        opts.successMsg = item.name + " returned to management";
+    }
+    apiFetch(_unmanagedURL(item), opts).catch(function () {});
+}
+
+function initUnmanagedSection() {
+    var section = document.getElementById("unmanaged-section");
+    if (!section) return;
+    section.addEventListener("click", function (e) {
+        var btn = e.target.closest("[data-unmanaged-action]");
+        if (!btn) return;
+        var row = btn.closest("tr");
+        var item = _unmanagedItems[parseInt(row.getAttribute("data-index"), 10)];
+        if (item) unmanagedAction(item, btn.getAttribute("data-unmanaged-action"), btn);
+    });
+    loadUnmanaged();
+}
+
 export {
     togglePorts,
     initPortLinks,
@@ -1508,6 +1633,7 @@ export {
     openAccordionFromHash,
     initPauseBanner,
     resumeScanning,
+    refreshCapabilities,
     checkPauseState,
     refreshLastScan,
     onRowClick,
@@ -1531,7 +1657,9 @@ export {
     fetchContainerLogs,
     toggleLogStream,
     containerAction,
+    killContainer,
     bulkContainerAction,
     initDashboardKeyboard,
-    toggleDashboardShortcutsHelp
+    toggleDashboardShortcutsHelp,
+    initUnmanagedSection
 };
@@ -2,7 +2,7 @@
    Docker-Sentinel — Images management page
    ============================================================ */
 
-import { showToast, showConfirm, apiFetch } from "./utils.js";
+import { showToast, showConfirm, apiFetch, escapeHTML } from "./utils.js";
 
 // Module state: cached image data and current filter/sort.
 var _allImages = [];
@@ -385,3 +385,196 @@ export function removeImage(id) {
         });
     });
 }
+
+// ---- Networks and volumes ----
+
+var _networks = [];
+var _volumes = [];
+
+export async function loadResources() {
+    try {
+        var responses = await Promise.all([fetch('/api/networks'), fetch('/api/volumes')]);
+        if (responses[0].ok) _networks = (await responses[0].json()).networks || [];
+        if (responses[1].ok) _volumes = (await responses[1].json()).volumes || [];
+        renderNetworksTable();
+        renderVolumesTable();
+    } catch (err) {
+        console.error('Failed to load networks and volumes:', err);
+    }
+}
+
+function resourceCell(row, text, className) {
+    var cell = document.createElement('td');
+    if (className) cell.className = className;
+    cell.textContent = text;
+    row.appendChild(cell);
+    return cell;
+}
+
+function attachedCell(row, containers) {
+    var cell = document.createElement('td');
+    if (containers && containers.length > 0) {
+        cell.textContent = containers.join(', ');
+    } else {
+        var none = document.createElement('span');
+        none.className = 'text-muted';
+        none.textContent = 'None';
+        cell.appendChild(none);
+    }
+    row.appendChild(cell);
+}
+
+function statusCell(row, item) {
+    var cell = document.createElement('td');
+    var badge = document.createElement('span');
+    if (item.builtin) {
+        badge.className = 'badge badge-muted';
+        badge.textContent = 'Built-in';
+    } else if (item.orphan) {
+        badge.className = 'badge badge-error';
+        badge.textContent = 'Orphan';
+    } else {
+        badge.className = 'badge badge-success';
+        badge.textContent = 'In Use';
+    }
+    cell.appendChild(badge);
+    row.appendChild(cell);
+}
+
+function removeCell(row, item, onRemove) {
+    var cell = document.createElement('td');
+    var btn = document.createElement('button');
+    btn.className = 'btn btn-sm btn-danger';
+    btn.textContent = 'Remove';
+    if (!item.orphan) {
+        btn.disabled = true;
+        btn.title = item.builtin ? 'Built-in networks cannot be removed' : 'Cannot remove: in use by a container';
+    } else {
+        btn.addEventListener('click', function() { onRemove(btn); });
+    }
+    cell.appendChild(btn);
+    row.appendChild(cell);
+}
+
+function renderResourceRows(tbodyId, summaryId, noun, items, cols, buildRow) {
+    var tbody = document.getElementById(tbodyId);
+    if (!tbody) return;
+    while (tbody.firstChild) tbody.removeChild(tbody.firstChild);
+
+    var orphans = items.filter(function(item) { return item.orphan; }).length;
+    var summaryEl = document.getElementById(summaryId);
+    if (summaryEl) summaryEl.textContent = items.length + ' ' + noun + ' (' + orphans + ' orphaned)';
+
+    if (items.length === 0) {
+        var emptyRow = document.createElement('tr');
+        var emptyCell = resourceCell(emptyRow, 'No ' + noun + ' found');
+        emptyCell.colSpan = cols;
+        emptyCell.style.textAlign = 'center';
+        emptyCell.style.color = 'var(--text-secondary)';
+        tbody.appendChild(emptyRow);
+        return;
+    }
+
+    // Orphans first so they are easy to spot, then by name.
+    var sorted = items.slice().sort(function(a, b) {
+        if (a.orphan !== b.orphan) return a.orphan ? -1 : 1;
+        return a.name < b.name ? -1 : a.name > b.name ? 1 : 0;
+    });
+    for (var i = 0; i < sorted.length; i++) {
+        tbody.appendChild(buildRow(sorted[i]));
+    }
+}
+
+function renderNetworksTable() {
+    renderResourceRows('networks-tbody', 'networks-summary', 'networks', _networks, 6, function(net) {
+        var row = document.createElement('tr');
+        var nameCell = resourceCell(row, net.name);
+        nameCell.title = net.id;
+        resourceCell(row, net.driver + (net.internal ? ' (internal)' : ''));
+        resourceCell(row, net.scope);
+        attachedCell(row, net.containers);
+        statusCell(row, net);
+        removeCell(row, net, function(btn) { removeNetwork(net.id, net.name, btn); });
+        return row;
+    });
+}
+
+function renderVolumesTable() {
+    renderResourceRows('volumes-tbody', 'volumes-summary', 'volumes', _volumes, 5, function(vol) {
+        var row = document.createElement('tr');
+        var nameCell = resourceCell(row, vol.name.length > 32 ? vol.name.substring(0, 12) + '…' : vol.name);
+        nameCell.title = vol.mountpoint || vol.name;
+        resourceCell(row, vol.driver);
+        attachedCell(row, vol.containers);
+        statusCell(row, vol);
+        removeCell(row, vol, function(btn) { removeVolume(vol.name, btn); });
+        return row;
+    });
+}
+
+function removeNetwork(id, name, btn) {
+    showConfirm(
+        "Remove Network",
+        "<p>Remove network <strong>" + escapeHTML(name) + "</strong>? No containers are attached to it.</p>",
+        { danger: true, confirmLabel: "Remove" }
+    ).then(function(confirmed) {
+        if (!confirmed) return;
+        apiFetch('/api/networks/' + encodeURIComponent(id), {
+            method: 'DELETE',
+            triggerEl: btn,
+            successMsg: 'Network removed',
+            errorMsg: 'Remove failed',
+            onSuccess: function() { loadResources(); }
+        });
+    });
+}
+
+function removeVolume(name, btn) {
+    showConfirm(
+        "Remove Volume",
+        "<p>Remove volume <strong>" + escapeHTML(name) + "</strong>? Its data will be deleted and cannot be recovered.</p>",
+        { danger: true, confirmLabel: "Remove" }
+    ).then(function(confirmed) {
+        if (!confirmed) return;
+        apiFetch('/api/volumes/' + encodeURIComponent(name), {
+            method: 'DELETE',
+            triggerEl: btn,
+            successMsg: 'Volume removed',
+            errorMsg: 'Remove failed',
+            onSuccess: function() { loadResources(); }
+        });
+    });
+}
+
+// removeOrphans removes every orphaned network or volume after one confirmation.
+export function removeOrphans(kind) {
+    var items = (kind === 'volumes' ? _volumes : _networks).filter(function(item) { return item.orphan; });
+    if (items.length === 0) {
+        showToast('No orphaned ' + kind, 'info');
+        return;
+    }
+    var noun = items.length === 1 ? kind.slice(0, -1) : kind;
+    var warning = kind === 'volumes' ? ' Their data will be deleted and cannot be recovered.' : '';
+    showConfirm(
+        "Remove Orphaned " + (kind === 'volumes' ? "Volumes" : "Networks"),
+        "<p>Remove " + items.length + " orphaned " + noun + "?" + warning + "</p>",
+        { danger: true, confirmLabel: "Remove" }
+    ).then(async function(confirmed) {
+        if (!confirmed) return;
+        var removed = 0;
+        var failed = 0;
+        for (var i = 0; i < items.length; i++) {
+            var key = kind === 'volumes' ? items[i].name : items[i].id;
+            try {
+                var resp = await fetch('/api/' + kind + '/' + encodeURIComponent(key), { method: 'DELETE' });
+                if (resp.ok) { removed++; } else { failed++; }
+            } catch (_) { failed++; }
+        }
+        if (failed > 0) {
+            showToast('Removed ' + removed + ', failed ' + failed, 'warning');
+        } else {
+            showToast('Removed ' + removed + ' ' + noun, 'success');
+        }
+        loadResources();
+    });
+}
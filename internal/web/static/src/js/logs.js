@@ -9,10 +9,10 @@ var _currentType = 'all'; // all | update | policy | auth | settings
 var TYPE_GROUPS = {
     update:   ['update', 'rollback', 'approve', 'reject', 'ignore', 'check',
                'self_update', 'update_to_version', 'restart', 'start', 'stop',
-               'scale', 'scan', 'webhook', 'ghcr_switch', 'image_prune', 'image_remove'],
+               'pause', 'unpause', 'kill', 'scale', 'scan', 'webhook', 'ghcr_switch', 'image_prune', 'image_remove'],
     policy:   ['policy_set', 'policy_delete', 'bulk_policy', 'notify_pref', 'notify_states_cleared'],
     auth:     ['auth'],
-    settings: ['settings', 'cluster-settings', 'config-import', 'digest', 'hooks']
+    settings: ['settings', 'cluster-settings', 'cluster', 'config-import', 'digest', 'hooks']
 };
 
 // Badge colour per type.
@@ -26,6 +26,9 @@ var TYPE_BADGE = {
     start:         'badge-success',
     stop:          'badge-error',
     restart:       'badge-warning',
+    pause:         'badge-warning',
+    unpause:       'badge-success',
+    kill:          'badge-error',
     auth:          'badge-info',
     settings:      'badge-muted',
     scan:          'badge-info',
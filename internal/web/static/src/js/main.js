@@ -24,6 +24,7 @@ import {
     openAccordionFromHash,
     initPauseBanner,
     resumeScanning,
+    refreshCapabilities,
     checkPauseState,
     refreshLastScan,
     onRowClick,
@@ -47,9 +48,11 @@ import {
     fetchContainerLogs,
     toggleLogStream,
     containerAction,
+    killContainer,
     bulkContainerAction,
     initDashboardKeyboard,
-    toggleDashboardShortcutsHelp
+    toggleDashboardShortcutsHelp,
+    initUnmanagedSection
 } from "./dashboard.js";
 
 import {
@@ -57,11 +60,14 @@ import {
     approveUpdate,
     ignoreUpdate,
     rejectUpdate,
+    saveQueueComment,
     getBulkInProgress,
     approveAll,
     ignoreAll,
     rejectAll,
     triggerUpdate,
+    triggerStackUpdate,
+    triggerComposeSync,
     triggerCheck,
     triggerRollback,
     changePolicy,
@@ -71,9 +77,12 @@ import {
     loadAllTags,
     updateToVersion,
     applyBulkPolicy,
+    initQueueReorder,
     initQueueKeyboard,
     cleanupQueueKeyboard,
-    toggleShortcutsHelp
+    toggleShortcutsHelp,
+    loadActiveUpdates,
+    forceUnlockUpdate
 } from "./queue.js";
 
 import {
@@ -117,6 +126,8 @@ import {
     setLatestAutoUpdate,
     setPauseState,
     saveFilters,
+    testFilters,
+    saveImageRewrites,
     setImageCleanup,
     saveCronSchedule,
     setDependencyAware,
@@ -125,11 +136,26 @@ import {
     setDryRun,
     setPullOnly,
     setUpdateDelay,
+    setHealthTimeout,
+    setBlueGreenPortRange,
+    saveExternalValidation,
+    setStuckUpdateThreshold,
+    setAuditRetention,
     setComposeSync,
+    setConfigDiff,
+    setRebuildWatch,
+    saveRebuildWebhook,
+    setPullCheckRegistry,
+    savePullCheckDiskPath,
+    saveRetention,
+    compactDatabase,
+    saveComposePath,
+    deleteComposePath,
     setImageBackup,
     setShowStopped,
     setRemoveVolumes,
     setScanConcurrency,
+    setAutoUpdateCap,
     setHADiscovery,
     saveHADiscoveryPrefix,
     updateToggleText,
@@ -140,15 +166,27 @@ import {
     regenerateWebhookSecret,
     copyWebhookURL,
     copyWebhookSecret,
+    regenerateRegistryWebhookToken,
+    copyRegistryWebhookURL,
     saveMaintenanceWindow,
     exportConfig,
     importConfig,
+    exportQueueState,
+    importQueueState,
+    downloadDatabaseBackup,
+    saveBackupSettings,
+    restoreDatabase,
     loadDashboardColumns,
     saveDashboardColumns,
     loadScannerSettings,
     saveScannerSettings,
     loadVerifierSettings,
     saveVerifierSettings,
+    addTrustedKey,
+    deleteTrustedKey,
+    clearVerifyCache,
+    createDeployToken,
+    deleteDeployToken,
     loadRetrySettings,
     saveRetrySettings,
     toggleAdvanced
@@ -168,6 +206,7 @@ import {
     onNotifyModeChange,
     loadDigestSettings,
     saveDigestSettings,
+    saveExternalURL,
     triggerDigest,
     loadContainerNotifyPrefs,
     setContainerNotifyPref,
@@ -202,7 +241,9 @@ import {
     toggleManageMode as toggleImageManageMode,
     toggleImageSelect,
     toggleSelectAll as toggleImageSelectAll,
-    removeSelectedImages
+    removeSelectedImages,
+    loadResources,
+    removeOrphans
 } from "./images.js";
 
 import {
@@ -211,6 +252,12 @@ import {
     exportLogs
 } from "./logs.js";
 
+import {
+    loadTimeline,
+    loadMoreTimeline,
+    filterTimeline
+} from "./timeline.js";
+
 // Wire updateStats into dashboard module (avoids circular import).
 setUpdateStatsFn(updateStats);
 
@@ -237,6 +284,7 @@ window.apiFetch = apiFetch;
 // Dashboard
 window.activateFilter = activateFilter;
 window.resumeScanning = resumeScanning;
+window.refreshCapabilities = refreshCapabilities;
 window.expandAllStacks = expandAllStacks;
 window.collapseAllStacks = collapseAllStacks;
 window.toggleManageMode = function() {
@@ -262,6 +310,7 @@ window.refreshLastScan = refreshLastScan;
 window.fetchContainerLogs = fetchContainerLogs;
 window.toggleLogStream = toggleLogStream;
 window.containerAction = containerAction;
+window.killContainer = killContainer;
 window.bulkContainerAction = bulkContainerAction;
 window.toggleDashboardShortcutsHelp = toggleDashboardShortcutsHelp;
 
@@ -270,10 +319,13 @@ window.toggleQueueAccordion = toggleQueueAccordion;
 window.approveUpdate = approveUpdate;
 window.ignoreUpdate = ignoreUpdate;
 window.rejectUpdate = rejectUpdate;
+window.saveQueueComment = saveQueueComment;
 window.approveAll = approveAll;
 window.ignoreAll = ignoreAll;
 window.rejectAll = rejectAll;
 window.triggerUpdate = triggerUpdate;
+window.triggerStackUpdate = triggerStackUpdate;
+window.triggerComposeSync = triggerComposeSync;
 window.triggerCheck = triggerCheck;
 window.triggerRollback = triggerRollback;
 window.changePolicy = changePolicy;
@@ -284,6 +336,8 @@ window.switchToGHCR = switchToGHCR;
 window.initQueueKeyboard = initQueueKeyboard;
 window.cleanupQueueKeyboard = cleanupQueueKeyboard;
 window.toggleShortcutsHelp = toggleShortcutsHelp;
+window.loadActiveUpdates = loadActiveUpdates;
+window.forceUnlockUpdate = forceUnlockUpdate;
 
 // Swarm
 window.toggleSvc = toggleSvc;
@@ -312,6 +366,8 @@ window.applyCustomGracePeriod = applyCustomGracePeriod;
 window.setLatestAutoUpdate = setLatestAutoUpdate;
 window.setPauseState = setPauseState;
 window.saveFilters = saveFilters;
+window.saveImageRewrites = saveImageRewrites;
+window.testFilters = testFilters;
 window.setImageCleanup = setImageCleanup;
 window.saveCronSchedule = saveCronSchedule;
 window.setDependencyAware = setDependencyAware;
@@ -320,11 +376,26 @@ window.setHooksWriteLabels = setHooksWriteLabels;
 window.setDryRun = setDryRun;
 window.setPullOnly = setPullOnly;
 window.setUpdateDelay = setUpdateDelay;
+window.setHealthTimeout = setHealthTimeout;
+window.setBlueGreenPortRange = setBlueGreenPortRange;
+window.saveExternalValidation = saveExternalValidation;
+window.setStuckUpdateThreshold = setStuckUpdateThreshold;
+window.setAuditRetention = setAuditRetention;
 window.setComposeSync = setComposeSync;
+window.setConfigDiff = setConfigDiff;
+window.setRebuildWatch = setRebuildWatch;
+window.saveRebuildWebhook = saveRebuildWebhook;
+window.setPullCheckRegistry = setPullCheckRegistry;
+window.savePullCheckDiskPath = savePullCheckDiskPath;
+window.saveRetention = saveRetention;
+window.compactDatabase = compactDatabase;
+window.saveComposePath = saveComposePath;
+window.deleteComposePath = deleteComposePath;
 window.setImageBackup = setImageBackup;
 window.setShowStopped = setShowStopped;
 window.setRemoveVolumes = setRemoveVolumes;
 window.setScanConcurrency = setScanConcurrency;
+window.setAutoUpdateCap = setAutoUpdateCap;
 window.setHADiscovery = setHADiscovery;
 window.saveHADiscoveryPrefix = saveHADiscoveryPrefix;
 window.updateToggleText = updateToggleText;
@@ -335,14 +406,26 @@ window.setWebhookEnabled = setWebhookEnabled;
 window.regenerateWebhookSecret = regenerateWebhookSecret;
 window.copyWebhookURL = copyWebhookURL;
 window.copyWebhookSecret = copyWebhookSecret;
+window.regenerateRegistryWebhookToken = regenerateRegistryWebhookToken;
+window.copyRegistryWebhookURL = copyRegistryWebhookURL;
 window.saveMaintenanceWindow = saveMaintenanceWindow;
 window.exportConfig = exportConfig;
 window.importConfig = importConfig;
+window.exportQueueState = exportQueueState;
+window.importQueueState = importQueueState;
+window.downloadDatabaseBackup = downloadDatabaseBackup;
+window.saveBackupSettings = saveBackupSettings;
+window.restoreDatabase = restoreDatabase;
 window.saveDashboardColumns = saveDashboardColumns;
 window.loadScannerSettings = loadScannerSettings;
 window.saveScannerSettings = saveScannerSettings;
 window.loadVerifierSettings = loadVerifierSettings;
 window.saveVerifierSettings = saveVerifierSettings;
+window.addTrustedKey = addTrustedKey;
+window.deleteTrustedKey = deleteTrustedKey;
+window.clearVerifyCache = clearVerifyCache;
+window.createDeployToken = createDeployToken;
+window.deleteDeployToken = deleteDeployToken;
 window.loadRetrySettings = loadRetrySettings;
 window.saveRetrySettings = saveRetrySettings;
 window.loadDashboardColumns = loadDashboardColumns;
@@ -359,6 +442,7 @@ window.saveNotificationChannels = saveNotificationChannels;
 window.testNotification = testNotification;
 window.onNotifyModeChange = onNotifyModeChange;
 window.saveDigestSettings = saveDigestSettings;
+window.saveExternalURL = saveExternalURL;
 window.triggerDigest = triggerDigest;
 window.saveNotifyPref = setContainerNotifyPref;
 window.loadNotificationChannels = loadNotificationChannels;
@@ -391,12 +475,19 @@ window.toggleImageManageMode = toggleImageManageMode;
 window.toggleImageSelect = toggleImageSelect;
 window.toggleImageSelectAll = toggleImageSelectAll;
 window.removeSelectedImages = removeSelectedImages;
+window.loadResources = loadResources;
+window.removeOrphans = removeOrphans;
 
 // Activity Logs
 window.loadActivityLogs = loadActivityLogs;
 window.filterLogs = filterLogs;
 window.exportLogs = exportLogs;
 
+// Timeline
+window.loadTimeline = loadTimeline;
+window.loadMoreTimeline = loadMoreTimeline;
+window.filterTimeline = filterTimeline;
+
 /* ------------------------------------------------------------
    Hamburger menu toggle
    ------------------------------------------------------------ */
@@ -466,7 +557,10 @@ document.addEventListener("DOMContentLoaded", function () {
     initAccordionPersistence();
     openAccordionFromHash();
     initQueueKeyboard();
+    initQueueReorder();
+    loadActiveUpdates();
     initDashboardKeyboard();
+    initUnmanagedSection();
 
     // Health indicator in nav — fetches /api/readyz on load.
     (function initHealthDot() {
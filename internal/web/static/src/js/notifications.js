@@ -13,7 +13,12 @@ var EVENT_TYPES = [
     { key: "update_failed", label: "Update Failed" },
     { key: "rollback_succeeded", label: "Rollback Succeeded" },
     { key: "rollback_failed", label: "Rollback Failed" },
-    { key: "container_state", label: "State Change" }
+    { key: "container_state", label: "State Change" },
+    { key: "pin_expiring", label: "Pin Expiring" },
+    { key: "pin_expired", label: "Pin Expired" },
+    { key: "rebuild_needed", label: "Rebuild Needed" },
+    { key: "queue_stale", label: "Stale Approval" },
+    { key: "report", label: "Weekly Report" }
 ];
 
 // Map legacy event keys (from older saved configs in BoltDB) to current constants.
@@ -52,7 +57,8 @@ var PROVIDER_FIELDS = {
     ],
     telegram: [
         { key: "bot_token", label: "Bot Token", type: "password", placeholder: "123456:ABC-DEF..." },
-        { key: "chat_id", label: "Chat ID", type: "text", placeholder: "-1001234567890" }
+        { key: "chat_id", label: "Chat ID", type: "text", placeholder: "-1001234567890" },
+        { key: "actions", label: "Approve Buttons", type: "text", placeholder: "true to approve or reject updates from the chat" }
     ],
     pushover: [
         { key: "app_token", label: "App Token", type: "password", placeholder: "Application token" },
@@ -69,8 +75,9 @@ var PROVIDER_FIELDS = {
     ],
     apprise: [
         { key: "url", label: "Apprise API URL", type: "text", placeholder: "http://apprise:8000" },
-        { key: "tag", label: "Config Tag", type: "text", placeholder: "Tag for persistent config (optional)" },
-        { key: "urls", label: "Apprise URLs", type: "text", placeholder: "Apprise URL(s) for stateless mode (optional)" }
+        { key: "tag", label: "Config Key", type: "text", placeholder: "Key of a persistent config (optional)" },
+        { key: "urls", label: "Apprise URLs", type: "text", placeholder: "Apprise URL(s) for stateless mode (optional)" },
+        { key: "tags", label: "Notify Tags", type: "text", placeholder: "Tags to notify, e.g. sentinel or all (optional)" }
     ],
     mqtt: [
         { key: "broker", label: "Broker URL", type: "text", placeholder: "tcp://mqtt:1883" },
@@ -80,6 +87,11 @@ var PROVIDER_FIELDS = {
         { key: "password", label: "Password", type: "password", placeholder: "Password (optional)" },
         { key: "qos", label: "QoS", type: "text", placeholder: "0, 1, or 2 (default: 0)" }
     ],
+    matrix: [
+        { key: "homeserver", label: "Homeserver URL", type: "text", placeholder: "https://matrix.example.org" },
+        { key: "access_token", label: "Access Token", type: "password", placeholder: "Token of the posting account" },
+        { key: "room_id", label: "Room ID", type: "text", placeholder: "!abcdef:example.org" }
+    ],
 };
 
 var notificationChannels = [];
@@ -102,6 +114,7 @@ function loadNotificationChannels() {
 }
 
 function renderChannels() {
+    renderTemplateChannelOptions();
     var container = document.getElementById("channel-list");
     if (!container) return;
 
@@ -223,6 +236,10 @@ function buildChannelCard(index) {
         fieldsDiv.appendChild(row);
     }
 
+    if (ch.type === "group") {
+        buildGroupFields(fieldsDiv, ch, settings);
+    }
+
     card.appendChild(fieldsDiv);
 
     // Event filter pills.
@@ -278,6 +295,70 @@ function buildChannelCard(index) {
     return card;
 }
 
+// Group channels list the other channels as member checkboxes plus a
+// failure policy. Groups cannot contain other groups.
+function buildGroupFields(fieldsDiv, ch, settings) {
+    var members = settings.members || [];
+
+    var memberRow = document.createElement("div");
+    memberRow.className = "channel-field";
+    var memberLabel = document.createElement("div");
+    memberLabel.className = "channel-field-label";
+    memberLabel.textContent = "Members";
+    memberRow.appendChild(memberLabel);
+
+    var memberList = document.createElement("div");
+    memberList.style.display = "flex";
+    memberList.style.flexWrap = "wrap";
+    memberList.style.gap = "var(--sp-3)";
+    var candidates = 0;
+    for (var i = 0; i < notificationChannels.length; i++) {
+        var other = notificationChannels[i];
+        if (other.type === "group" || other.id === ch.id) continue;
+        candidates++;
+        var lbl = document.createElement("label");
+        lbl.style.display = "flex";
+        lbl.style.alignItems = "center";
+        lbl.style.gap = "6px";
+        lbl.style.fontSize = "0.8125rem";
+        var cb = document.createElement("input");
+        cb.type = "checkbox";
+        cb.setAttribute("data-member", other.id);
+        cb.checked = members.indexOf(other.id) !== -1;
+        lbl.appendChild(cb);
+        lbl.appendChild(document.createTextNode(other.name || other.type));
+        memberList.appendChild(lbl);
+    }
+    if (candidates === 0) {
+        memberList.textContent = "Add other channels first, then select them here.";
+        memberList.style.color = "var(--fg-muted)";
+        memberList.style.fontSize = "0.8125rem";
+    }
+    memberRow.appendChild(memberList);
+    fieldsDiv.appendChild(memberRow);
+
+    var policyRow = document.createElement("div");
+    policyRow.className = "channel-field";
+    var policyLabel = document.createElement("div");
+    policyLabel.className = "channel-field-label";
+    policyLabel.textContent = "Delivery";
+    policyRow.appendChild(policyLabel);
+
+    var policy = document.createElement("select");
+    policy.className = "setting-select";
+    policy.setAttribute("data-setting", "policy");
+    var opts = [["any", "Delivered if any member succeeds"], ["all", "Only if every member succeeds"]];
+    for (var o = 0; o < opts.length; o++) {
+        var opt = document.createElement("option");
+        opt.value = opts[o][0];
+        opt.textContent = opts[o][1];
+        policy.appendChild(opt);
+    }
+    policy.value = settings.policy === "all" ? "all" : "any";
+    policyRow.appendChild(policy);
+    fieldsDiv.appendChild(policyRow);
+}
+
 function addChannel() {
     var select = document.getElementById("channel-type-select");
     if (!select || !select.value) return;
@@ -306,8 +387,18 @@ function addChannel() {
 
 function deleteChannel(index) {
     if (index < 0 || index >= notificationChannels.length) return;
-    var name = notificationChannels[index].name || notificationChannels[index].type;
+    collectChannelsFromDOM();
+    var removed = notificationChannels[index];
+    var name = removed.name || removed.type;
     notificationChannels.splice(index, 1);
+    // Drop the channel from any group that listed it.
+    for (var i = 0; i < notificationChannels.length; i++) {
+        if (notificationChannels[i].type !== "group") continue;
+        var gs = {};
+        try { gs = JSON.parse(notificationChannels[i].settings || "{}"); } catch(e) { gs = {}; }
+        gs.members = (gs.members || []).filter(function(id) { return id !== removed.id; });
+        notificationChannels[i].settings = JSON.stringify(gs);
+    }
     renderChannels();
     showToast("Removed " + name + " — save to apply", "info");
 }
@@ -343,6 +434,14 @@ function collectChannelsFromDOM() {
             }
         }
 
+        if (notificationChannels[idx].type === "group") {
+            var memberBoxes = cards[i].querySelectorAll("[data-member]");
+            settings.members = [];
+            for (var mb = 0; mb < memberBoxes.length; mb++) {
+                if (memberBoxes[mb].checked) settings.members.push(memberBoxes[mb].getAttribute("data-member"));
+            }
+        }
+
         notificationChannels[idx].settings = JSON.stringify(settings);
 
         // Collect event pill states.
@@ -482,6 +581,18 @@ function loadDigestSettings() {
             if (el) el.value = data.digest_time || "09:00";
             el = document.getElementById("digest-interval");
             if (el) el.value = data.digest_interval || "24h";
+            el = document.getElementById("queue-stale-after");
+            if (el) {
+                var staleAfter = data.queue_stale_after || "";
+                // Keep custom values set via config import selectable.
+                if (staleAfter && !el.querySelector('option[value="' + staleAfter + '"]')) {
+                    var opt = document.createElement("option");
+                    opt.value = staleAfter;
+                    opt.textContent = "After " + staleAfter;
+                    el.appendChild(opt);
+                }
+                el.value = staleAfter;
+            }
         })
         .catch(function () {});
 }
@@ -496,6 +607,8 @@ function saveDigestSettings() {
     if (el && el.value) body.digest_time = el.value;
     el = document.getElementById("digest-interval");
     if (el && el.value) body.digest_interval = el.value;
+    el = document.getElementById("queue-stale-after");
+    if (el) body.queue_stale_after = el.value;
 
     fetch("/api/settings/digest", {
         method: "POST",
@@ -510,6 +623,29 @@ function saveDigestSettings() {
     .catch(function () { showToast("Failed to save settings", "error"); });
 }
 
+function saveExternalURL() {
+    var input = document.getElementById("external-url-input");
+    if (!input) return;
+    fetch("/api/settings/external-url", {
+        method: "POST",
+        credentials: "same-origin",
+        headers: {"Content-Type": "application/json"},
+        body: JSON.stringify({ url: input.value.trim() })
+    })
+    .then(function (res) {
+        return res.json().then(function (data) { return { ok: res.ok, data: data }; });
+    })
+    .then(function (result) {
+        if (!result.ok) {
+            showToast(result.data.error || "Failed to save external URL", "error");
+            return;
+        }
+        input.value = result.data.url || "";
+        showToast(result.data.message || "External URL saved", "success");
+    })
+    .catch(function () { showToast("Network error -- could not save external URL", "error"); });
+}
+
 function triggerDigest() {
     fetch("/api/digest/trigger", {
         method: "POST",
@@ -789,8 +925,38 @@ function setContainerNotifyPref(name, mode) {
 
 var _notifyTemplates = {};
 
+// templateURL returns the template API path for the channel selected in the
+// editor, or for the templates that apply to every channel.
+function templateURL(suffix) {
+    var sel = document.getElementById("template-channel");
+    var id = sel ? sel.value : "";
+    if (!id) return "/api/settings/notifications/templates" + suffix;
+    return "/api/settings/notifications/" + encodeURIComponent(id) + "/template" + suffix;
+}
+
+// renderTemplateChannelOptions lists the channels in the template editor's
+// channel picker, keeping the current selection while it still exists.
+function renderTemplateChannelOptions() {
+    var sel = document.getElementById("template-channel");
+    if (!sel) return;
+    var current = sel.value;
+    while (sel.options.length > 1) sel.remove(1);
+    for (var i = 0; i < notificationChannels.length; i++) {
+        var ch = notificationChannels[i];
+        var opt = document.createElement("option");
+        opt.value = ch.id;
+        opt.textContent = ch.name || ch.type;
+        sel.appendChild(opt);
+    }
+    sel.value = current;
+    if (sel.value !== current) {
+        sel.value = "";
+        loadNotifyTemplates();
+    }
+}
+
 function loadNotifyTemplates() {
-    fetch("/api/settings/notifications/templates", {credentials: "same-origin"})
+    fetch(templateURL(""), {credentials: "same-origin"})
         .then(function(r) { return r.json(); })
         .then(function(data) {
             _notifyTemplates = (data && data.templates) ? data.templates : {};
@@ -821,7 +987,7 @@ function saveNotifyTemplate() {
     var eventType = sel.value;
     var tmpl = textarea.value;
 
-    fetch("/api/settings/notifications/templates", {
+    fetch(templateURL(""), {
         method: "PUT",
         credentials: "same-origin",
         headers: {"Content-Type": "application/json"},
@@ -850,7 +1016,7 @@ function deleteNotifyTemplate() {
 
     var eventType = sel.value;
 
-    fetch("/api/settings/notifications/templates/" + encodeURIComponent(eventType), {
+    fetch(templateURL("/" + encodeURIComponent(eventType)), {
         method: "DELETE",
         credentials: "same-origin"
     })
@@ -881,12 +1047,14 @@ function previewNotifyTemplate() {
     var eventType = sel.value;
     var tmpl = textarea.value;
 
-    if (!tmpl) {
+    // A channel preview without a template shows what the channel sends now.
+    var channelSel = document.getElementById("template-channel");
+    if (!tmpl && !(channelSel && channelSel.value)) {
         showToast("Enter a template to preview", "info");
         return;
     }
 
-    fetch("/api/settings/notifications/templates/preview", {
+    fetch(templateURL("/preview"), {
         method: "POST",
         credentials: "same-origin",
         headers: {"Content-Type": "application/json"},
@@ -921,6 +1089,7 @@ export {
     onNotifyModeChange,
     loadDigestSettings,
     saveDigestSettings,
+    saveExternalURL,
     triggerDigest,
     loadContainerNotifyPrefs,
     setContainerNotifyPref,
@@ -77,11 +77,43 @@ function toggleQueueAccordion(index) {
     if (chevron) chevron.textContent = visible ? "\u25B8" : "\u25BE";
 }
 
+// Comment typed into a queue entry's accordion, sent with the action so it
+// lands on the history record and in the activity log.
+function queueCommentBody(key) {
+    var input = document.querySelector('.queue-comment-input[data-queue-key="' + CSS.escape(key) + '"]');
+    var comment = input ? input.value.trim() : "";
+    return comment ? { comment: comment } : null;
+}
+
+// queueApproveBody adds the version picked in the accordion, when it is
+// not the newest (the server default).
+function queueApproveBody(key) {
+    var body = queueCommentBody(key);
+    var select = document.querySelector('.queue-version-select[data-queue-key="' + CSS.escape(key) + '"]');
+    if (select && select.selectedIndex > 0) {
+        body = body || {};
+        body.version = select.value;
+    }
+    return body;
+}
+
+function saveQueueComment(key, event) {
+    var btn = event && event.target ? event.target.closest(".btn") : null;
+    var body = queueCommentBody(key) || { comment: "" };
+    apiFetch("/api/queue/" + encodeURIComponent(key) + "/comment", {
+        method: "PUT",
+        body: body,
+        triggerEl: btn,
+        successMsg: body.comment ? "Comment saved for " + key : "Comment cleared for " + key,
+        errorMsg: "Failed to save comment"
+    }).catch(function() { /* toast already shown */ });
+}
+
 function approveUpdate(key, event) {
     var btn = event && event.target ? event.target.closest(".btn") : null;
     apiPost(
         "/api/approve/" + encodeURIComponent(key),
-        null,
+        queueApproveBody(key),
         "Approved update for " + key,
         "Failed to approve",
         btn,
@@ -93,7 +125,7 @@ function ignoreUpdate(key, event) {
     var btn = event && event.target ? event.target.closest(".btn") : null;
     apiPost(
         "/api/ignore/" + encodeURIComponent(key),
-        null,
+        queueCommentBody(key),
         "Version ignored for " + key,
         "Failed to ignore version",
         btn,
@@ -105,7 +137,7 @@ function rejectUpdate(key, event) {
     var btn = event && event.target ? event.target.closest(".btn") : null;
     apiPost(
         "/api/reject/" + encodeURIComponent(key),
-        null,
+        queueCommentBody(key),
         "Rejected update for " + key,
         "Failed to reject",
         btn,
@@ -116,20 +148,9 @@ function rejectUpdate(key, event) {
 // Staggered per-row API calls — rows fade out on success, re-enable on failure.
 var _bulkInProgress = false;
 
-function bulkQueueAction(apiPath, actionLabel, triggerBtn, skipSelf) {
-    var allRows = document.querySelectorAll(".table-wrap tbody tr.container-row[data-queue-key]");
-    if (!allRows.length) return;
-
-    // When skipSelf is true (e.g. "Approve All"), exclude self-protected rows.
-    var rows = [];
-    for (var s = 0; s < allRows.length; s++) {
-        if (skipSelf && allRows[s].getAttribute("data-self") === "true") continue;
-        rows.push(allRows[s]);
-    }
-    if (!rows.length) {
-        showToast("No eligible containers for " + actionLabel + " (Sentinel skipped)", "info");
-        return;
-    }
+function bulkQueueAction(apiPath, actionLabel, triggerBtn) {
+    var rows = document.querySelectorAll(".table-wrap tbody tr.container-row[data-queue-key]");
+    if (!rows.length) return;
 
     _bulkInProgress = true;
 
@@ -223,9 +244,43 @@ function bulkQueueAction(apiPath, actionLabel, triggerBtn, skipSelf) {
     }
 }
 
+// Approve All hands the visible rows to the server in one request. The
+// server runs them one at a time in queue order and stops at the first
+// failure, so put a database above the services that depend on it.
 function approveAll(event) {
     var btn = event && event.target ? event.target.closest(".btn") : null;
-    bulkQueueAction("approve", "approved", btn, true);
+    var rows = document.querySelectorAll(".table-wrap tbody tr.container-row[data-queue-key]");
+    if (!rows.length) return;
+
+    // Sentinel's own entry is skipped; it updates via "Update Sentinel".
+    var keys = [];
+    for (var i = 0; i < rows.length; i++) {
+        if (rows[i].getAttribute("data-self") === "true") continue;
+        var key = rows[i].getAttribute("data-queue-key");
+        if (key) keys.push(key);
+    }
+    if (!keys.length) {
+        showToast("No eligible containers for approval (Sentinel skipped)", "info");
+        return;
+    }
+
+    _bulkInProgress = true;
+    apiFetch("/api/queue/approve-all", {
+        method: "POST",
+        body: { keys: keys },
+        triggerEl: btn,
+        errorMsg: "Failed to approve updates",
+        onSuccess: function (data) {
+            var order = data.order || [];
+            showToast("Approved " + order.length + " updates \u2014 running one at a time in queue order", "success");
+            for (var j = 0; j < order.length; j++) {
+                var row = document.querySelector('tr[data-queue-key="' + CSS.escape(order[j]) + '"]');
+                if (row) removeQueueRow(row.querySelector(".btn") || { closest: function () { return row; } });
+            }
+        }
+    }).catch(function () { /* toast already shown */ }).then(function () {
+        _bulkInProgress = false;
+    });
 }
 
 function ignoreAll(event) {
@@ -264,6 +319,51 @@ function triggerUpdate(name, event, hostId) {
     );
 }
 
+function triggerStackUpdate(project, event) {
+    var btn = event && event.target ? event.target.closest(".btn") : null;
+    showConfirm(
+        "Update Stack",
+        "<p>Update every container in <strong>" + escapeHTML(project) + "</strong> as one unit?</p>" +
+        "<p>All images are pulled first, then containers are recreated in dependency order. " +
+        "If any container fails, the whole stack is rolled back.</p>",
+        { confirmLabel: "Update Stack" }
+    ).then(function(confirmed) {
+        if (!confirmed) return;
+        apiFetch("/api/stacks/" + encodeURIComponent(project) + "/update", {
+            method: "POST",
+            triggerEl: btn,
+            successMsg: "Stack update started for " + project,
+            errorMsg: "Failed to start stack update"
+        });
+    });
+}
+
+function triggerComposeSync(name, event) {
+    var btn = event && event.target ? event.target.closest(".btn") : null;
+    var url = "/api/containers/" + encodeURIComponent(name) + "/compose-sync";
+    apiFetch(url, { triggerEl: btn, errorMsg: "Failed to preview compose file" }).then(function(data) {
+        if (!data || !data.edit) return;
+        if (!data.changed) {
+            showToast("Compose file unchanged \u2014 " + (data.edit.reason || "nothing to update"), "info");
+            return;
+        }
+        showConfirm(
+            "Sync Compose File",
+            "<p>Write the running image tag into <code>" + escapeHTML(data.edit.path) + "</code>? A <code>.bak</code> copy is kept.</p>" +
+            '<pre class="mono" style="overflow-x:auto">' + escapeHTML(data.edit.diff) + "</pre>",
+            { confirmLabel: "Write File" }
+        ).then(function(confirmed) {
+            if (!confirmed) return;
+            apiFetch(url, {
+                method: "POST",
+                triggerEl: btn,
+                successMsg: "Compose file updated for " + name,
+                errorMsg: "Failed to write compose file"
+            });
+        });
+    }).catch(function() { /* toast already shown */ });
+}
+
 function triggerCheck(name, event, hostId) {
     var btn = event && event.target ? event.target.closest(".btn") : null;
     var url = "/api/check/" + encodeURIComponent(name);
@@ -525,6 +625,128 @@ function applyBulkPolicy() {
 function getBulkInProgress() { return _bulkInProgress; }
 
 
+/* ============================================================
+   Queue Ordering — drag rows (or Shift+J/K) to set approval order
+   ============================================================ */
+
+var _dragRow = null;
+
+// Each queue row is followed by its accordion row; both move together.
+function _moveQueueRow(row, before) {
+    var accordion = row.nextElementSibling;
+    var tbody = row.parentNode;
+    tbody.insertBefore(row, before);
+    if (accordion && accordion.classList.contains("accordion-panel")) {
+        tbody.insertBefore(accordion, before);
+    }
+}
+
+function _queueRowOrder() {
+    var rows = _getQueueRows();
+    var keys = [];
+    for (var i = 0; i < rows.length; i++) {
+        var key = rows[i].getAttribute("data-queue-key");
+        if (key) keys.push(key);
+    }
+    return keys;
+}
+
+// saveQueueOrder persists the on-screen order. On failure the rows are put
+// back as they were so the page never shows an order the server rejected.
+function saveQueueOrder(previous) {
+    var keys = _queueRowOrder();
+    apiFetch("/api/queue/order", {
+        method: "PATCH",
+        body: { keys: keys },
+        errorMsg: "Failed to save queue order",
+        onError: function () {
+            var tbody = document.querySelector(".queue-table tbody");
+            if (!tbody) return;
+            for (var i = 0; i < previous.length; i++) {
+                var row = tbody.querySelector('tr[data-queue-key="' + CSS.escape(previous[i]) + '"]');
+                if (row) _moveQueueRow(row, null);
+            }
+        }
+    }).catch(function () { /* toast already shown */ });
+}
+
+// moveQueueRow shifts a row one place up (-1) or down (+1) and saves.
+function moveQueueRow(row, delta) {
+    var rows = Array.prototype.slice.call(_getQueueRows());
+    var idx = rows.indexOf(row);
+    var target = idx + delta;
+    if (idx < 0 || target < 0 || target >= rows.length) return false;
+    var previous = _queueRowOrder();
+    if (delta < 0) {
+        _moveQueueRow(row, rows[target]);
+    } else {
+        var after = rows[target].nextElementSibling;
+        if (after && after.classList.contains("accordion-panel")) after = after.nextElementSibling;
+        _moveQueueRow(row, after);
+    }
+    saveQueueOrder(previous);
+    return true;
+}
+
+function _clearQueueDragClasses() {
+    var all = document.querySelectorAll(".queue-table .drag-over-above, .queue-table .drag-over-below");
+    for (var i = 0; i < all.length; i++) {
+        all[i].classList.remove("drag-over-above", "drag-over-below");
+    }
+}
+
+function initQueueReorder() {
+    var table = document.querySelector(".queue-table");
+    if (!table) return;
+
+    table.addEventListener("dragstart", function (e) {
+        // Only the handle is draggable; the rest of the row stays clickable.
+        if (!e.target.closest || !e.target.closest(".queue-drag-handle")) return;
+        _dragRow = e.target.closest("tr.container-row");
+        if (!_dragRow) return;
+        _dragRow.classList.add("dragging");
+        e.dataTransfer.effectAllowed = "move";
+        e.dataTransfer.setData("text/plain", _dragRow.getAttribute("data-queue-key"));
+        e.dataTransfer.setDragImage(_dragRow, 0, 0);
+    });
+
+    table.addEventListener("dragover", function (e) {
+        var row = e.target.closest("tr.container-row");
+        if (!row || !_dragRow || row === _dragRow) return;
+        e.preventDefault();
+        e.dataTransfer.dropEffect = "move";
+        var rect = row.getBoundingClientRect();
+        _clearQueueDragClasses();
+        row.classList.add(e.clientY < rect.top + rect.height / 2 ? "drag-over-above" : "drag-over-below");
+    });
+
+    table.addEventListener("drop", function (e) {
+        var row = e.target.closest("tr.container-row");
+        if (!row || !_dragRow || row === _dragRow) return;
+        e.preventDefault();
+        var previous = _queueRowOrder();
+        var rect = row.getBoundingClientRect();
+        if (e.clientY < rect.top + rect.height / 2) {
+            _moveQueueRow(_dragRow, row);
+        } else {
+            var after = row.nextElementSibling;
+            if (after && after.classList.contains("accordion-panel")) after = after.nextElementSibling;
+            _moveQueueRow(_dragRow, after);
+        }
+        _clearQueueDragClasses();
+        _dragRow.classList.remove("dragging");
+        _dragRow = null;
+        saveQueueOrder(previous);
+    });
+
+    table.addEventListener("dragend", function () {
+        if (_dragRow) _dragRow.classList.remove("dragging");
+        _dragRow = null;
+        _clearQueueDragClasses();
+    });
+}
+
+
 /* ============================================================
    Keyboard Shortcuts — Queue page navigation and actions
    ============================================================ */
@@ -571,6 +793,7 @@ function _createShortcutsOverlay() {
     var shortcuts = [
         ["j", "Next row"],
         ["k", "Previous row"],
+        ["J / K", "Move focused row down / up"],
         ["Enter / Space", "Toggle accordion"],
         ["a", "Approve focused"],
         ["r", "Reject focused"],
@@ -671,7 +894,20 @@ function _onQueueKeydown(e) {
         case " ":
             if (_kbFocusIndex >= 0 && _kbFocusIndex < rows.length) {
                 e.preventDefault();
-                toggleQueueAccordion(_kbFocusIndex);
+                // The chevron knows its accordion; the row index may have
+                // changed since render if the queue was reordered.
+                var expand = rows[_kbFocusIndex].querySelector(".queue-expand");
+                if (expand) expand.click();
+            }
+            break;
+
+        case "J":
+        case "K":
+            if (_kbFocusIndex >= 0 && _kbFocusIndex < rows.length) {
+                e.preventDefault();
+                var delta = e.key === "J" ? 1 : -1;
+                if (moveQueueRow(rows[_kbFocusIndex], delta)) _kbFocusIndex += delta;
+                _applyKbFocus();
             }
             break;
 
@@ -739,6 +975,98 @@ function cleanupQueueKeyboard() {
     }
 }
 
+/* ------------------------------------------------------------
+   Updates in progress — stuck detection and force unlock
+   ------------------------------------------------------------ */
+
+function _formatElapsed(since) {
+    var secs = Math.max(0, Math.floor((Date.now() - new Date(since).getTime()) / 1000));
+    if (secs < 60) return secs + "s";
+    if (secs < 3600) return Math.floor(secs / 60) + "m";
+    return Math.floor(secs / 3600) + "h " + Math.floor((secs % 3600) / 60) + "m";
+}
+
+function loadActiveUpdates() {
+    var card = document.getElementById("active-updates-card");
+    var tbody = document.getElementById("active-updates-tbody");
+    if (!card || !tbody) return;
+    fetch("/api/updates/active")
+        .then(function (resp) { return resp.ok ? resp.json() : []; })
+        .then(function (active) {
+            while (tbody.firstChild) tbody.removeChild(tbody.firstChild);
+            card.style.display = active.length ? "" : "none";
+            for (var i = 0; i < active.length; i++) {
+                var a = active[i];
+                var row = document.createElement("tr");
+
+                var nameCell = document.createElement("td");
+                nameCell.className = "mono";
+                nameCell.textContent = a.name;
+                if (a.host_id) {
+                    var host = document.createElement("span");
+                    host.className = "host-badge";
+                    host.textContent = a.host_name || a.host_id;
+                    nameCell.appendChild(host);
+                }
+                row.appendChild(nameCell);
+
+                var phaseCell = document.createElement("td");
+                var badge = document.createElement("span");
+                badge.className = "badge " + (a.stuck ? "badge-error" : "badge-info");
+                badge.textContent = a.stuck ? a.phase + " (stuck)" : a.phase;
+                phaseCell.appendChild(badge);
+                row.appendChild(phaseCell);
+
+                var timeCell = document.createElement("td");
+                timeCell.title = "Started " + new Date(a.started_at).toLocaleString();
+                timeCell.textContent = _formatElapsed(a.phase_since) + " in phase, " + _formatElapsed(a.started_at) + " total";
+                row.appendChild(timeCell);
+
+                var actionCell = document.createElement("td");
+                if (a.stuck && !a.host_id) {
+                    var btn = document.createElement("button");
+                    btn.className = "btn btn-sm btn-error";
+                    btn.textContent = "Force unlock";
+                    btn.setAttribute("data-name", a.name);
+                    btn.addEventListener("click", function (e) {
+                        forceUnlockUpdate(e.currentTarget.getAttribute("data-name"), e);
+                    });
+                    actionCell.appendChild(btn);
+                }
+                row.appendChild(actionCell);
+
+                tbody.appendChild(row);
+            }
+        })
+        .catch(function () {});
+}
+
+function forceUnlockUpdate(name, event) {
+    var btn = event && event.currentTarget ? event.currentTarget : null;
+    showConfirm(
+        "Force unlock " + name + "?",
+        "<p>The stuck update of <strong>" + escapeHTML(name) + "</strong> will be abandoned so it can be retried. " +
+        "Sentinel checks the container afterwards and tells you if it needs a rollback.</p>",
+        { confirmLabel: "Force unlock", danger: true }
+    ).then(function (confirmed) {
+        if (!confirmed) return;
+        apiFetch("/api/updates/" + encodeURIComponent(name) + "/force-unlock", {
+            method: "POST",
+            triggerEl: btn,
+            errorMsg: "Failed to force unlock " + name,
+            onSuccess: function (data) {
+                var warnings = data.warnings || [];
+                if (warnings.length) {
+                    showToast(name + ": " + warnings.join("; "), "warning");
+                } else {
+                    showToast("Update lock released for " + name, "success");
+                }
+                loadActiveUpdates();
+            }
+        });
+    });
+}
+
 
 export {
     removeQueueRow,
@@ -746,12 +1074,16 @@ export {
     approveUpdate,
     ignoreUpdate,
     rejectUpdate,
+    saveQueueComment,
     getBulkInProgress,
     bulkQueueAction,
     approveAll,
     ignoreAll,
     rejectAll,
+    initQueueReorder,
     triggerUpdate,
+    triggerStackUpdate,
+    triggerComposeSync,
     triggerCheck,
     triggerRollback,
     changePolicy,
@@ -763,5 +1095,7 @@ export {
     applyBulkPolicy,
     initQueueKeyboard,
     cleanupQueueKeyboard,
-    toggleShortcutsHelp
+    toggleShortcutsHelp,
+    loadActiveUpdates,
+    forceUnlockUpdate
 };
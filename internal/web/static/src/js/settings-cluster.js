@@ -26,10 +26,13 @@ function loadClusterSettings() {
             _updateToggleText("cluster-enabled-text", enabled);
             document.getElementById("cluster-port").value = s.port || "9443";
             document.getElementById("cluster-grace").value = s.grace_period || "30m";
+            document.getElementById("cluster-enroll-ttl").value = s.enroll_ttl || "24h";
             document.getElementById("cluster-policy").value = s.remote_policy || "manual";
             var autoUpdate = s.auto_update_agents === "true";
             document.getElementById("cluster-auto-update").checked = autoUpdate;
             _updateToggleText("cluster-auto-update-text", autoUpdate);
+            document.getElementById("cluster-min-agent-version").value = s.min_agent_version || "";
+            document.getElementById("cluster-outdated-agents").value = s.outdated_agents || "degrade";
             toggleClusterFields(enabled);
         })
         .catch(function(err) {
@@ -80,8 +83,11 @@ function saveClusterSettings() {
             enabled: enabled,
             port: document.getElementById("cluster-port").value,
             grace_period: document.getElementById("cluster-grace").value,
+            enroll_ttl: document.getElementById("cluster-enroll-ttl").value,
             remote_policy: document.getElementById("cluster-policy").value,
-            auto_update_agents: autoUpdateEl.checked
+            auto_update_agents: autoUpdateEl.checked,
+            min_agent_version: document.getElementById("cluster-min-agent-version").value,
+            outdated_agents: document.getElementById("cluster-outdated-agents").value
         })
     })
         .then(function(resp) {
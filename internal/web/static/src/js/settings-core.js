@@ -3,7 +3,7 @@
    2a. Settings Helpers
    ============================================================ */
 
-import { showToast, showConfirm } from "./utils.js";
+import { showToast, showConfirm, escapeHTML } from "./utils.js";
 
 /* ------------------------------------------------------------
    Show Advanced toggle
@@ -146,6 +146,12 @@ function initSettingsPage() {
                 filtersArea.value = filters;
             }
 
+            // Image rewrite rules.
+            var rewritesArea = document.getElementById("image-rewrites");
+            if (rewritesArea) {
+                rewritesArea.value = settings["image_rewrites"] || "";
+            }
+
             // Image cleanup toggle.
             var imageCleanupToggle = document.getElementById("image-cleanup-toggle");
             if (imageCleanupToggle) {
@@ -206,6 +212,40 @@ function initSettingsPage() {
                 updateDelayInput.value = settings["update_delay"] || "";
             }
 
+            // Health check timeout.
+            var healthTimeoutInput = document.getElementById("health-timeout");
+            if (healthTimeoutInput) {
+                healthTimeoutInput.value = settings["health_timeout"] || "";
+            }
+
+            // Blue-green port range.
+            var blueGreenPortsInput = document.getElementById("bluegreen-port-range");
+            if (blueGreenPortsInput) {
+                blueGreenPortsInput.value = settings["bluegreen_port_range"] || "";
+            }
+
+            // External validation.
+            var validationWebhookInput = document.getElementById("validation-webhook-input");
+            if (validationWebhookInput) {
+                validationWebhookInput.value = settings["validation_webhook_url"] || "";
+            }
+            var validationCommandInput = document.getElementById("validation-command-input");
+            if (validationCommandInput) {
+                validationCommandInput.value = settings["validation_command"] || "";
+            }
+
+            // Stuck update threshold.
+            var stuckThresholdInput = document.getElementById("stuck-update-threshold");
+            if (stuckThresholdInput) {
+                stuckThresholdInput.value = settings["stuck_update_threshold"] || "";
+            }
+
+            // Audit log retention.
+            var auditRetentionInput = document.getElementById("audit-retention");
+            if (auditRetentionInput) {
+                auditRetentionInput.value = settings["audit_retention"] || "";
+            }
+
             // Maintenance window.
             var maintenanceWindowInput = document.getElementById("maintenance-window");
             if (maintenanceWindowInput) {
@@ -220,6 +260,50 @@ function initSettingsPage() {
                 updateToggleText("compose-sync-text", composeSync);
             }
 
+            // Config change preview toggle.
+            var configDiffToggle = document.getElementById("config-diff-toggle");
+            if (configDiffToggle) {
+                var configDiff = settings["config_diff"] === "true";
+                configDiffToggle.checked = configDiff;
+                updateToggleText("config-diff-text", configDiff);
+            }
+
+            // Base image rebuild alerts.
+            var rebuildWatchToggle = document.getElementById("rebuild-watch-toggle");
+            if (rebuildWatchToggle) {
+                var rebuildWatch = settings["rebuild_watch"] === "true";
+                rebuildWatchToggle.checked = rebuildWatch;
+                updateToggleText("rebuild-watch-text", rebuildWatch);
+            }
+            var rebuildWebhookInput = document.getElementById("rebuild-webhook-input");
+            if (rebuildWebhookInput) {
+                rebuildWebhookInput.value = settings["rebuild_webhook_url"] || "";
+            }
+
+            // Pre-pull checks.
+            var pullCheckRegistryToggle = document.getElementById("pull-check-registry-toggle");
+            if (pullCheckRegistryToggle) {
+                var pullCheckRegistry = settings["pull_check_registry"] !== "false";
+                pullCheckRegistryToggle.checked = pullCheckRegistry;
+                updateToggleText("pull-check-registry-text", pullCheckRegistry);
+            }
+            var pullCheckDiskInput = document.getElementById("pull-check-disk-input");
+            if (pullCheckDiskInput) {
+                pullCheckDiskInput.value = settings["pull_check_disk_path"] || "";
+            }
+
+            // Data retention.
+            ["history", "logs", "snapshots"].forEach(function(kind) {
+                var days = document.getElementById("retention-" + kind + "-days");
+                var max = document.getElementById("retention-" + kind + "-max");
+                if (days) days.value = settings["retention_" + kind + "_days"] || "";
+                if (max) max.value = settings["retention_" + kind + "_max"] || "";
+            });
+
+            // External URL for notification links.
+            var externalURLInput = document.getElementById("external-url-input");
+            if (externalURLInput) externalURLInput.value = settings["external_url"] || "";
+
             // Image backup toggle.
             var imageBackupToggle = document.getElementById("image-backup-toggle");
             if (imageBackupToggle) {
@@ -251,6 +335,17 @@ function initSettingsPage() {
                 if (!isNaN(sc) && sc >= 1) { scanConcInput.value = sc; }
             }
 
+            // Auto-update cap.
+            var maxAutoInput = document.getElementById("max-auto-updates-input");
+            if (maxAutoInput && settings["max_auto_updates"]) {
+                var ma = parseInt(settings["max_auto_updates"], 10);
+                if (!isNaN(ma) && ma >= 0) { maxAutoInput.value = ma; }
+            }
+            var overflowSelect = document.getElementById("auto-update-overflow");
+            if (overflowSelect && settings["auto_update_overflow"]) {
+                overflowSelect.value = settings["auto_update_overflow"];
+            }
+
             // HA discovery toggle.
             var haToggle = document.getElementById("ha-discovery-toggle");
             if (haToggle) {
@@ -270,6 +365,9 @@ function initSettingsPage() {
     // Load scanner, verifier, and retry settings into their accordion sections.
     loadScannerSettings();
     loadVerifierSettings();
+    loadTrustedKeys();
+    loadVerifyCacheStats();
+    loadDeployTokens();
     loadRetrySettings();
 
     // Tab navigation (settings page only -- other pages handle their own tabs).
@@ -346,8 +444,14 @@ function initSettingsPage() {
     // Load webhook settings.
     loadWebhookSettings();
 
+    // Load per-stack compose file paths.
+    loadComposePaths();
+
     // Load dashboard column preferences.
     loadDashboardColumns();
+
+    // Load scheduled database backup settings.
+    loadBackupSettings();
 }
 
 // Local helper — clearAccordionState is also in dashboard.js but duplicated
@@ -709,6 +813,96 @@ function saveFilters() {
         });
 }
 
+// saveImageRewrites saves the pull rewrite rules, one "from => to" per line.
+function saveImageRewrites() {
+    var textarea = document.getElementById("image-rewrites");
+    if (!textarea) return;
+
+    var lines = textarea.value.split("\n");
+    var rules = [];
+    for (var i = 0; i < lines.length; i++) {
+        var trimmed = lines[i].replace(/^\s+|\s+$/g, "");
+        if (trimmed !== "") {
+            rules.push(trimmed);
+        }
+    }
+
+    fetch("/api/settings/image-rewrites", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ rules: rules })
+    })
+        .then(function(resp) {
+            return resp.json().then(function(data) {
+                return { ok: resp.ok, data: data };
+            });
+        })
+        .then(function(result) {
+            if (result.ok) {
+                showToast(result.data.message || "Image rewrites saved", "success");
+            } else {
+                showToast(result.data.error || "Failed to save image rewrites", "error");
+            }
+        })
+        .catch(function() {
+            showToast("Network error -- could not save image rewrites", "error");
+        });
+}
+
+// testFilters checks a name and/or image against the rules as typed, before
+// they are saved. With only a name, the server uses that container's image
+// and labels.
+function testFilters() {
+    var textarea = document.getElementById("container-filters");
+    var nameInput = document.getElementById("filter-test-name");
+    var imageInput = document.getElementById("filter-test-image");
+    var resultEl = document.getElementById("filter-test-result");
+    if (!textarea || !nameInput || !imageInput || !resultEl) return;
+
+    var name = nameInput.value.trim();
+    var image = imageInput.value.trim();
+    if (!name && !image) {
+        showToast("Enter a container name or image to test", "info");
+        return;
+    }
+
+    var patterns = [];
+    var lines = textarea.value.split("\n");
+    for (var i = 0; i < lines.length; i++) {
+        var trimmed = lines[i].trim();
+        if (trimmed !== "") patterns.push(trimmed);
+    }
+
+    fetch("/api/settings/filters/test", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ name: name, image: image, patterns: patterns })
+    })
+        .then(function(resp) {
+            return resp.json().then(function(data) {
+                return { ok: resp.ok, data: data };
+            });
+        })
+        .then(function(result) {
+            if (!result.ok) {
+                resultEl.textContent = result.data.error || "Filter test failed";
+                return;
+            }
+            var d = result.data;
+            var subject = (d.name || d.image) + (d.name && d.image ? " (" + d.image + ")" : "");
+            if (d.excluded) {
+                resultEl.textContent = subject + " is excluded by " + d.rule;
+            } else if (d.rule) {
+                resultEl.textContent = subject + " is scanned: re-included by " + d.rule;
+            } else {
+                resultEl.textContent = subject + " is scanned: no rule matches";
+            }
+        })
+        .catch(function() {
+            showToast("Network error -- could not test filters", "error");
+        });
+}
+
 function updateToggleText(textId, enabled) {
     var text = document.getElementById(textId);
     if (text) {
@@ -785,6 +979,113 @@ function setComposeSync(enabled) {
         .catch(function() { showToast("Network error -- could not update setting", "error"); });
 }
 
+function setConfigDiff(enabled) {
+    updateToggleText("config-diff-text", enabled);
+    fetch("/api/settings/config-diff", { method: "POST", headers: { "Content-Type": "application/json" }, body: JSON.stringify({ enabled: enabled }) })
+        .then(function(r) { return r.json(); })
+        .then(function(data) { showToast(data.message || "Setting updated", "success"); })
+        .catch(function() { showToast("Network error -- could not update setting", "error"); });
+}
+
+function setRebuildWatch(enabled) {
+    updateToggleText("rebuild-watch-text", enabled);
+    fetch("/api/settings/rebuild-watch", { method: "POST", headers: { "Content-Type": "application/json" }, body: JSON.stringify({ enabled: enabled }) })
+        .then(function(r) { return r.json(); })
+        .then(function(data) { showToast(data.message || data.error || "Setting updated", data.error ? "error" : "success"); })
+        .catch(function() { showToast("Network error -- could not update setting", "error"); });
+}
+
+function saveRebuildWebhook() {
+    var input = document.getElementById("rebuild-webhook-input");
+    if (!input) return;
+    fetch("/api/settings/rebuild-watch", { method: "POST", headers: { "Content-Type": "application/json" }, body: JSON.stringify({ webhook_url: input.value.trim() }) })
+        .then(function(r) { return r.json(); })
+        .then(function(data) { showToast(data.message || data.error || "Setting updated", data.error ? "error" : "success"); })
+        .catch(function() { showToast("Network error -- could not update setting", "error"); });
+}
+
+function setPullCheckRegistry(enabled) {
+    updateToggleText("pull-check-registry-text", enabled);
+    fetch("/api/settings/pull-checks", { method: "POST", headers: { "Content-Type": "application/json" }, body: JSON.stringify({ registry: enabled }) })
+        .then(function(r) { return r.json(); })
+        .then(function(data) { showToast(data.message || data.error || "Setting updated", data.error ? "error" : "success"); })
+        .catch(function() { showToast("Network error -- could not update setting", "error"); });
+}
+
+function savePullCheckDiskPath() {
+    var input = document.getElementById("pull-check-disk-input");
+    if (!input) return;
+    fetch("/api/settings/pull-checks", { method: "POST", headers: { "Content-Type": "application/json" }, body: JSON.stringify({ disk_path: input.value.trim() }) })
+        .then(function(r) { return r.json(); })
+        .then(function(data) { showToast(data.message || data.error || "Setting updated", data.error ? "error" : "success"); })
+        .catch(function() { showToast("Network error -- could not update setting", "error"); });
+}
+
+function loadComposePaths() {
+    var list = document.getElementById("compose-paths-list");
+    if (!list) return;
+
+    fetch("/api/compose/paths")
+        .then(function(r) { return r.json(); })
+        .then(function(paths) {
+            var projects = Object.keys(paths || {}).sort();
+            if (projects.length === 0) {
+                list.innerHTML = '<div class="setting-desc">No overrides — using host paths from Compose labels.</div>';
+                return;
+            }
+            // Safe: project names and paths are escaped via escapeHTML.
+            list.innerHTML = projects.map(function(project) {
+                return '<div style="display:flex; gap:var(--sp-2); align-items:center; margin-bottom:var(--sp-1)">' +
+                    '<code>' + escapeHTML(project) + '</code> → <code>' + escapeHTML(paths[project]) + '</code>' +
+                    '<button class="btn btn-sm" data-project="' + escapeHTML(project) + '" onclick="deleteComposePath(this.getAttribute(\'data-project\'))">Remove</button>' +
+                    '</div>';
+            }).join("");
+        })
+        .catch(function() { /* ignore -- section stays empty */ });
+}
+
+function saveComposePath() {
+    var project = document.getElementById("compose-path-project");
+    var path = document.getElementById("compose-path-path");
+    if (!project || !path) return;
+
+    fetch("/api/compose/paths", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ project: project.value.trim(), path: path.value.trim() })
+    })
+        .then(function(resp) {
+            return resp.json().then(function(data) {
+                return { ok: resp.ok, data: data };
+            });
+        })
+        .then(function(result) {
+            if (result.ok) {
+                showToast("Compose path saved for " + result.data.project, "success");
+                project.value = "";
+                path.value = "";
+                loadComposePaths();
+            } else {
+                showToast(result.data.error || "Failed to save compose path", "error");
+            }
+        })
+        .catch(function() {
+            showToast("Network error -- could not save compose path", "error");
+        });
+}
+
+function deleteComposePath(project) {
+    fetch("/api/compose/paths/" + encodeURIComponent(project), { method: "DELETE" })
+        .then(function(resp) {
+            if (!resp.ok) throw new Error("delete failed");
+            showToast("Compose path removed for " + project, "success");
+            loadComposePaths();
+        })
+        .catch(function() {
+            showToast("Could not remove compose path", "error");
+        });
+}
+
 function setImageBackup(enabled) {
     updateToggleText("image-backup-text", enabled);
     fetch("/api/settings/image-backup", { method: "POST", headers: { "Content-Type": "application/json" }, body: JSON.stringify({ enabled: enabled }) })
@@ -822,6 +1123,20 @@ function setScanConcurrency() {
         .catch(function() { showToast("Network error -- could not update setting", "error"); });
 }
 
+function setAutoUpdateCap() {
+    var input = document.getElementById("max-auto-updates-input");
+    var n = parseInt(input ? input.value : "0", 10);
+    if (isNaN(n) || n < 0 || n > 1000) {
+        showToast("Max auto-updates must be between 0 and 1000", "error");
+        return;
+    }
+    var overflow = (document.getElementById("auto-update-overflow") || {}).value || "defer";
+    fetch("/api/settings/auto-update-cap", { method: "POST", headers: { "Content-Type": "application/json" }, body: JSON.stringify({ max: n, overflow: overflow }) })
+        .then(function(r) { return r.json(); })
+        .then(function(data) { showToast(data.message || data.error || "Setting updated", data.error ? "error" : "success"); })
+        .catch(function() { showToast("Network error -- could not update setting", "error"); });
+}
+
 function setHADiscovery(enabled) {
     updateToggleText("ha-discovery-text", enabled);
     var prefix = (document.getElementById("ha-discovery-prefix") || {}).value || "";
@@ -857,6 +1172,65 @@ function setUpdateDelay() {
         .catch(function() { showToast("Network error -- could not save update delay", "error"); });
 }
 
+function setHealthTimeout() {
+    var input = document.getElementById("health-timeout");
+    if (!input) return;
+    fetch("/api/settings/health-timeout", { method: "POST", headers: { "Content-Type": "application/json" }, body: JSON.stringify({ duration: input.value.trim() }) })
+        .then(function(resp) { return resp.json().then(function(data) { return { ok: resp.ok, data: data }; }); })
+        .then(function(result) {
+            if (result.ok) { showToast(result.data.message || "Health check timeout saved", "success"); }
+            else { showToast(result.data.error || "Failed to save health check timeout", "error"); }
+        })
+        .catch(function() { showToast("Network error -- could not save health check timeout", "error"); });
+}
+
+function setBlueGreenPortRange() {
+    var input = document.getElementById("bluegreen-port-range");
+    if (!input) return;
+    fetch("/api/settings/bluegreen-port-range", { method: "POST", headers: { "Content-Type": "application/json" }, body: JSON.stringify({ port_range: input.value.trim() }) })
+        .then(function(resp) { return resp.json().then(function(data) { return { ok: resp.ok, data: data }; }); })
+        .then(function(result) {
+            if (result.ok) { showToast(result.data.message || "Blue-green port range saved", "success"); }
+            else { showToast(result.data.error || "Failed to save blue-green port range", "error"); }
+        })
+        .catch(function() { showToast("Network error -- could not save blue-green port range", "error"); });
+}
+
+function saveExternalValidation(field, inputId) {
+    var input = document.getElementById(inputId);
+    if (!input) return;
+    var body = {};
+    body[field] = input.value.trim();
+    fetch("/api/settings/external-validation", { method: "POST", headers: { "Content-Type": "application/json" }, body: JSON.stringify(body) })
+        .then(function(r) { return r.json(); })
+        .then(function(data) { showToast(data.message || data.error || "Setting updated", data.error ? "error" : "success"); })
+        .catch(function() { showToast("Network error -- could not update setting", "error"); });
+}
+
+function setStuckUpdateThreshold() {
+    var input = document.getElementById("stuck-update-threshold");
+    if (!input) return;
+    fetch("/api/settings/stuck-update-threshold", { method: "POST", headers: { "Content-Type": "application/json" }, body: JSON.stringify({ duration: input.value.trim() }) })
+        .then(function(resp) { return resp.json().then(function(data) { return { ok: resp.ok, data: data }; }); })
+        .then(function(result) {
+            if (result.ok) { showToast(result.data.message || "Stuck update threshold saved", "success"); }
+            else { showToast(result.data.error || "Failed to save stuck update threshold", "error"); }
+        })
+        .catch(function() { showToast("Network error -- could not save stuck update threshold", "error"); });
+}
+
+function setAuditRetention() {
+    var input = document.getElementById("audit-retention");
+    if (!input) return;
+    fetch("/api/settings/audit-retention", { method: "POST", headers: { "Content-Type": "application/json" }, body: JSON.stringify({ duration: input.value.trim() }) })
+        .then(function(resp) { return resp.json().then(function(data) { return { ok: resp.ok, data: data }; }); })
+        .then(function(result) {
+            if (result.ok) { showToast(result.data.message || "Audit retention saved", "success"); }
+            else { showToast(result.data.error || "Failed to save audit retention", "error"); }
+        })
+        .catch(function() { showToast("Network error -- could not save audit retention", "error"); });
+}
+
 function toggleCollapsible(headerEl) {
     var expanded = headerEl.getAttribute("aria-expanded") === "true";
     headerEl.setAttribute("aria-expanded", expanded ? "false" : "true");
@@ -943,12 +1317,12 @@ function loadWebhookSettings() {
             var enabled = data.enabled === "true";
             toggle.checked = enabled;
             updateToggleText("webhook-enabled-text", enabled);
-            showWebhookConfig(enabled, data.secret || "");
+            showWebhookConfig(enabled, data.secret || "", data.registry_token || "");
         })
         .catch(function() { /* ignore -- falls back to defaults */ });
 }
 
-function showWebhookConfig(enabled, secret) {
+function showWebhookConfig(enabled, secret, registryToken) {
     var configDiv = document.getElementById("webhook-config");
     if (!configDiv) return;
     configDiv.style.display = enabled ? "" : "none";
@@ -967,6 +1341,20 @@ function showWebhookConfig(enabled, secret) {
             hint.style.display = (secret && secret.indexOf("****") !== -1) ? "" : "none";
         }
     }
+
+    setRegistryWebhookURL(registryToken);
+}
+
+// setRegistryWebhookURL shows the push URL for a registry webhook token.
+function setRegistryWebhookURL(token) {
+    var input = document.getElementById("registry-webhook-url");
+    if (!input) return;
+    input.value = token ? window.location.origin + "/api/webhooks/registry/" + token : "";
+    // A masked token (contains ****) gives an unusable URL, so show a hint.
+    var hint = document.getElementById("registry-webhook-hint");
+    if (hint) {
+        hint.style.display = (token && token.indexOf("****") !== -1) ? "" : "none";
+    }
 }
 
 function setWebhookEnabled(enabled) {
@@ -1030,6 +1418,49 @@ function regenerateWebhookSecret() {
     });
 }
 
+function regenerateRegistryWebhookToken() {
+    showConfirm(
+        "Regenerate Registry Webhook URL",
+        "<p>Registries configured with the current URL will stop triggering checks. Continue?</p>",
+        { danger: true, confirmLabel: "Regenerate" }
+    ).then(function(confirmed) {
+        if (!confirmed) return;
+
+        fetch("/api/settings/registry-webhook-token", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" }
+        })
+            .then(function(resp) {
+                return resp.json().then(function(data) {
+                    return { ok: resp.ok, data: data };
+                });
+            })
+            .then(function(result) {
+                if (result.ok) {
+                    setRegistryWebhookURL(result.data.token || "");
+                    showToast("Registry webhook URL regenerated — copy it now, it won't be shown again", "success");
+                } else {
+                    showToast(result.data.error || "Failed to regenerate URL", "error");
+                }
+            })
+            .catch(function() {
+                showToast("Network error -- could not regenerate URL", "error");
+            });
+    });
+}
+
+function copyRegistryWebhookURL() {
+    var input = document.getElementById("registry-webhook-url");
+    if (!input || !input.value) return;
+    navigator.clipboard.writeText(input.value).then(function() {
+        showToast("Registry webhook URL copied", "success");
+    }).catch(function() {
+        input.select();
+        document.execCommand("copy");
+        showToast("Registry webhook URL copied", "success");
+    });
+}
+
 function copyWebhookURL() {
     var input = document.getElementById("webhook-url");
     if (!input || !input.value) return;
@@ -1090,8 +1521,14 @@ function saveMaintenanceWindow() {
 
 function exportConfig() {
     var includeSecrets = document.getElementById("export-secrets-toggle");
-    var qs = (includeSecrets && includeSecrets.checked) ? "?secrets=true" : "";
-    fetch("/api/config/export" + qs)
+    var formatEl = document.getElementById("export-format");
+    var passEl = document.getElementById("export-passphrase");
+    var format = formatEl ? formatEl.value : "json";
+    var qs = "?format=" + encodeURIComponent(format);
+    if (includeSecrets && includeSecrets.checked) qs += "&secrets=true";
+    var headers = {};
+    if (passEl && passEl.value) headers["X-Config-Passphrase"] = passEl.value;
+    fetch("/api/config/export" + qs, { headers: headers })
         .then(function(r) {
             if (!r.ok) throw new Error("Export failed");
             return r.blob();
@@ -1099,7 +1536,7 @@ function exportConfig() {
         .then(function(blob) {
             var a = document.createElement("a");
             a.href = URL.createObjectURL(blob);
-            a.download = "sentinel-config-" + new Date().toISOString().slice(0, 10) + ".json";
+            a.download = "sentinel-config-" + new Date().toISOString().slice(0, 10) + "." + format;
             a.click();
             URL.revokeObjectURL(a.href);
             showToast("Configuration exported", "success");
@@ -1124,11 +1561,14 @@ function importConfig() {
         if (!confirmed) return;
 
         var file = fileInput.files[0];
+        var passEl = document.getElementById("import-passphrase");
+        var headers = { "Content-Type": /\.ya?ml$/i.test(file.name) ? "application/yaml" : "application/json" };
+        if (passEl && passEl.value) headers["X-Config-Passphrase"] = passEl.value;
         var reader = new FileReader();
         reader.onload = function(e) {
             fetch("/api/config/import", {
                 method: "POST",
-                headers: { "Content-Type": "application/json" },
+                headers: headers,
                 body: e.target.result
             })
                 .then(function(r) { return r.json(); })
@@ -1148,6 +1588,232 @@ function importConfig() {
     });
 }
 
+function exportQueueState() {
+    fetch("/api/state/export")
+        .then(function(r) {
+            if (!r.ok) throw new Error("Export failed");
+            return r.blob();
+        })
+        .then(function(blob) {
+            var a = document.createElement("a");
+            a.href = URL.createObjectURL(blob);
+            a.download = "sentinel-state-" + new Date().toISOString().slice(0, 10) + ".json";
+            a.click();
+            URL.revokeObjectURL(a.href);
+            showToast("Queue state exported", "success");
+        })
+        .catch(function() {
+            showToast("Export failed", "error");
+        });
+}
+
+function importQueueState() {
+    var fileInput = document.getElementById("state-import-file");
+    if (!fileInput || !fileInput.files.length) {
+        showToast("Select a file first", "error");
+        return;
+    }
+    var conflictEl = document.getElementById("state-import-conflict");
+    var conflict = conflictEl ? conflictEl.value : "skip";
+    var reader = new FileReader();
+    reader.onload = function(e) {
+        fetch("/api/state/import?conflict=" + encodeURIComponent(conflict), {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: e.target.result
+        })
+            .then(function(r) { return r.json(); })
+            .then(function(data) {
+                if (data.error) {
+                    showToast(data.error, "error");
+                    return;
+                }
+                var warnings = data.warnings || [];
+                showToast(data.message || "Queue state imported", warnings.length ? "warning" : "success");
+            })
+            .catch(function() {
+                showToast("Import failed", "error");
+            });
+    };
+    reader.readAsText(fileInput.files[0]);
+}
+
+function downloadDatabaseBackup(btn) {
+    if (btn) btn.disabled = true;
+    fetch("/api/admin/backup", { method: "POST" })
+        .then(function(r) {
+            if (!r.ok) throw new Error("Backup failed");
+            return r.blob();
+        })
+        .then(function(blob) {
+            var a = document.createElement("a");
+            a.href = URL.createObjectURL(blob);
+            a.download = "sentinel-" + new Date().toISOString().slice(0, 10) + ".db";
+            a.click();
+            URL.revokeObjectURL(a.href);
+            showToast("Database backup downloaded", "success");
+        })
+        .catch(function() {
+            showToast("Database backup failed", "error");
+        })
+        .then(function() {
+            if (btn) btn.disabled = false;
+        });
+}
+
+function loadBackupSettings() {
+    fetch("/api/admin/backup/settings")
+        .then(function(r) {
+            if (!r.ok) throw new Error("unavailable");
+            return r.json();
+        })
+        .then(function(cfg) {
+            var schedule = document.getElementById("backup-schedule");
+            var dir = document.getElementById("backup-dir");
+            var retention = document.getElementById("backup-retention");
+            if (schedule) schedule.value = cfg.schedule || "";
+            if (dir) dir.value = cfg.dir || "";
+            if (retention) retention.value = cfg.retention;
+        })
+        .catch(function() {});
+}
+
+function saveBackupSettings(btn) {
+    var schedule = document.getElementById("backup-schedule");
+    var dir = document.getElementById("backup-dir");
+    var retention = document.getElementById("backup-retention");
+    if (!schedule || !dir || !retention) return;
+    if (btn) btn.disabled = true;
+    fetch("/api/admin/backup/settings", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({
+            schedule: schedule.value.trim(),
+            dir: dir.value.trim(),
+            retention: parseInt(retention.value, 10) || 0
+        })
+    })
+        .then(function(resp) {
+            return resp.json().then(function(data) {
+                return { ok: resp.ok, data: data };
+            });
+        })
+        .then(function(result) {
+            if (result.ok) {
+                showToast("Backup settings saved", "success");
+            } else {
+                showToast(result.data.error || "Failed to save backup settings", "error");
+            }
+        })
+        .catch(function() {
+            showToast("Network error -- could not save backup settings", "error");
+        })
+        .then(function() {
+            if (btn) btn.disabled = false;
+        });
+}
+
+function saveRetention(btn) {
+    var body = {};
+    ["history", "logs", "snapshots"].forEach(function(kind) {
+        var days = document.getElementById("retention-" + kind + "-days");
+        var max = document.getElementById("retention-" + kind + "-max");
+        if (!days || !max) return;
+        body[kind] = {
+            days: parseInt(days.value, 10) || 0,
+            max_records: parseInt(max.value, 10) || 0
+        };
+    });
+    if (btn) btn.disabled = true;
+    fetch("/api/settings/retention", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify(body)
+    })
+        .then(function(resp) {
+            return resp.json().then(function(data) {
+                return { ok: resp.ok, data: data };
+            });
+        })
+        .then(function(result) {
+            if (result.ok) {
+                showToast(result.data.message || "Retention saved", "success");
+            } else {
+                showToast(result.data.error || "Failed to save retention", "error");
+            }
+        })
+        .catch(function() {
+            showToast("Network error -- could not save retention", "error");
+        })
+        .then(function() {
+            if (btn) btn.disabled = false;
+        });
+}
+
+function compactDatabase(btn) {
+    if (btn) btn.disabled = true;
+    fetch("/api/admin/compact", { method: "POST" })
+        .then(function(resp) {
+            return resp.json().then(function(data) {
+                return { ok: resp.ok, data: data };
+            });
+        })
+        .then(function(result) {
+            if (result.ok) {
+                showToast(result.data.message || "Database compacted", "success");
+            } else {
+                showToast(result.data.error || "Compaction failed", "error");
+            }
+        })
+        .catch(function() {
+            showToast("Network error -- could not compact database", "error");
+        })
+        .then(function() {
+            if (btn) btn.disabled = false;
+        });
+}
+
+function restoreDatabase(btn) {
+    var fileInput = document.getElementById("db-restore-file");
+    if (!fileInput || !fileInput.files.length) {
+        showToast("Select a database file first", "error");
+        return;
+    }
+
+    showConfirm(
+        "Restore Database",
+        "<p>This replaces <strong>all</strong> data &mdash; policies, history, users and cluster enrolments &mdash; with the backup. You may be signed out. Continue?</p>",
+        { confirmLabel: "Restore", danger: true }
+    ).then(function(confirmed) {
+        if (!confirmed) return;
+        if (btn) btn.disabled = true;
+        fetch("/api/admin/restore", {
+            method: "POST",
+            headers: { "Content-Type": "application/octet-stream" },
+            body: fileInput.files[0]
+        })
+            .then(function(resp) {
+                return resp.json().then(function(data) {
+                    return { ok: resp.ok, data: data };
+                });
+            })
+            .then(function(result) {
+                if (!result.ok) {
+                    showToast(result.data.error || "Restore failed", "error");
+                    return;
+                }
+                showToast(result.data.message || "Database restored", "success");
+                setTimeout(function() { location.reload(); }, 2000);
+            })
+            .catch(function() {
+                showToast("Network error -- could not restore database", "error");
+            })
+            .then(function() {
+                if (btn) btn.disabled = false;
+            });
+    });
+}
+
 // --- Dashboard Column Config ---
 
 function loadDashboardColumns() {
@@ -1218,11 +1884,13 @@ function loadScannerSettings() {
             var modeEl = document.getElementById("scanner-mode");
             var threshEl = document.getElementById("scanner-threshold");
             var pathEl = document.getElementById("trivy-path");
+            var serverEl = document.getElementById("trivy-server");
             var preview = document.getElementById("scanner-preview");
 
             if (modeEl) modeEl.value = data.mode || "disabled";
             if (threshEl) threshEl.value = data.threshold || "HIGH";
             if (pathEl) pathEl.value = data.trivy_path || "trivy";
+            if (serverEl) serverEl.value = data.trivy_server || "";
             if (preview) {
                 if (data.mode === "disabled" || !data.mode) {
                     preview.textContent = "Disabled";
@@ -1238,11 +1906,13 @@ function saveScannerSettings() {
     var mode = document.getElementById("scanner-mode");
     var threshold = document.getElementById("scanner-threshold");
     var trivyPath = document.getElementById("trivy-path");
+    var trivyServer = document.getElementById("trivy-server");
 
     var body = {};
     if (mode) body.mode = mode.value;
     if (threshold) body.threshold = threshold.value;
     if (trivyPath && trivyPath.value) body.trivy_path = trivyPath.value;
+    if (trivyServer) body.trivy_server = trivyServer.value.trim();
 
     fetch("/api/settings/scanner", {
         method: "POST",
@@ -1268,6 +1938,9 @@ function loadVerifierSettings() {
             var pathEl = document.getElementById("cosign-path");
             var keylessEl = document.getElementById("cosign-keyless");
             var keyPathEl = document.getElementById("cosign-key-path");
+            var identityEl = document.getElementById("cosign-identity");
+            var issuerEl = document.getElementById("cosign-issuer");
+            var cacheTTLEl = document.getElementById("verify-cache-ttl");
             var preview = document.getElementById("verifier-preview");
 
             if (modeEl) modeEl.value = data.mode || "disabled";
@@ -1278,6 +1951,9 @@ function loadVerifierSettings() {
                 if (text) text.textContent = data.keyless === "true" ? "On" : "Off";
             }
             if (keyPathEl) keyPathEl.value = data.key_path || "";
+            if (identityEl) identityEl.value = data.identity || "";
+            if (issuerEl) issuerEl.value = data.issuer || "";
+            if (cacheTTLEl) cacheTTLEl.value = data.cache_ttl || "";
             if (preview) {
                 if (data.mode === "disabled" || !data.mode) {
                     preview.textContent = "Disabled";
@@ -1295,12 +1971,18 @@ function saveVerifierSettings() {
     var cosignPath = document.getElementById("cosign-path");
     var keyless = document.getElementById("cosign-keyless");
     var keyPath = document.getElementById("cosign-key-path");
+    var identity = document.getElementById("cosign-identity");
+    var issuer = document.getElementById("cosign-issuer");
+    var cacheTTL = document.getElementById("verify-cache-ttl");
 
     var body = {};
     if (mode) body.mode = mode.value;
     if (cosignPath && cosignPath.value) body.cosign_path = cosignPath.value;
     if (keyless) body.keyless = keyless.checked;
     if (keyPath) body.key_path = keyPath.value;
+    if (identity) body.identity = identity.value.trim();
+    if (issuer) body.issuer = issuer.value.trim();
+    if (cacheTTL && cacheTTL.value.trim()) body.cache_ttl = cacheTTL.value.trim();
 
     fetch("/api/settings/verifier", {
         method: "POST",
@@ -1314,10 +1996,190 @@ function saveVerifierSettings() {
     .then(function() {
         showToast("Verifier settings saved", "success");
         loadVerifierSettings();
+        loadVerifyCacheStats();
     })
     .catch(function(err) { showToast("Failed: " + err.message, "error"); });
 }
 
+function loadTrustedKeys() {
+    var list = document.getElementById("trusted-keys-list");
+    if (!list) return;
+
+    fetch("/api/settings/verifier/keys")
+        .then(function(r) { return r.json(); })
+        .then(function(keys) {
+            if (!Array.isArray(keys) || keys.length === 0) {
+                list.innerHTML = '<div class="setting-desc">No trusted keys.</div>';
+                return;
+            }
+            // Safe: names and fingerprints are escaped via escapeHTML.
+            list.innerHTML = keys.map(function(key) {
+                return '<div style="display:flex; gap:var(--sp-2); align-items:center; margin-bottom:var(--sp-1)">' +
+                    '<code>' + escapeHTML(key.name) + '</code>' +
+                    '<span class="setting-desc" title="' + escapeHTML(key.fingerprint) + '">' + escapeHTML(key.fingerprint.slice(0, 23)) + '…</span>' +
+                    '<button class="btn btn-sm" data-key="' + escapeHTML(key.name) + '" onclick="deleteTrustedKey(this.getAttribute(\'data-key\'))">Remove</button>' +
+                    '</div>';
+            }).join("");
+        })
+        .catch(function() { /* ignore -- section stays empty */ });
+}
+
+function addTrustedKey() {
+    var name = document.getElementById("trusted-key-name");
+    var pem = document.getElementById("trusted-key-pem");
+    if (!name || !pem) return;
+
+    fetch("/api/settings/verifier/keys", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ name: name.value.trim(), pem: pem.value })
+    })
+        .then(function(resp) {
+            return resp.json().then(function(data) {
+                return { ok: resp.ok, data: data };
+            });
+        })
+        .then(function(result) {
+            if (result.ok) {
+                showToast("Trusted key added: " + result.data.name, "success");
+                name.value = "";
+                pem.value = "";
+                loadTrustedKeys();
+            } else {
+                showToast(result.data.error || "Failed to add trusted key", "error");
+            }
+        })
+        .catch(function() {
+            showToast("Network error -- could not add trusted key", "error");
+        });
+}
+
+function deleteTrustedKey(name) {
+    fetch("/api/settings/verifier/keys/" + encodeURIComponent(name), { method: "DELETE" })
+        .then(function(resp) {
+            if (!resp.ok) throw new Error("delete failed");
+            showToast("Trusted key removed: " + name, "success");
+            loadTrustedKeys();
+        })
+        .catch(function() {
+            showToast("Could not remove trusted key", "error");
+        });
+}
+
+function loadVerifyCacheStats() {
+    var el = document.getElementById("verify-cache-stats");
+    if (!el) return;
+
+    fetch("/api/verify/cache")
+        .then(function(r) { return r.json(); })
+        .then(function(data) {
+            if (data.error) {
+                el.textContent = "Not available.";
+                return;
+            }
+            var text = data.entries + " cached digest" + (data.entries === 1 ? "" : "s");
+            if (data.stale) text += " (" + data.stale + " from an older key set)";
+            text += ". " + data.hits + " reused, " + data.misses + " verified since start.";
+            if (!data.enabled) text += " Caching is off.";
+            el.textContent = text;
+        })
+        .catch(function() { el.textContent = "Not available."; });
+}
+
+function clearVerifyCache() {
+    fetch("/api/verify/cache", { method: "DELETE" })
+        .then(function(resp) {
+            return resp.json().then(function(data) {
+                return { ok: resp.ok, data: data };
+            });
+        })
+        .then(function(result) {
+            if (result.ok) {
+                showToast("Verification cache cleared (" + result.data.removed + " entries)", "success");
+                loadVerifyCacheStats();
+            } else {
+                showToast(result.data.error || "Could not clear verification cache", "error");
+            }
+        })
+        .catch(function() {
+            showToast("Network error -- could not clear verification cache", "error");
+        });
+}
+
+function loadDeployTokens() {
+    var list = document.getElementById("deploy-tokens-list");
+    if (!list) return;
+
+    fetch("/api/settings/deploy-tokens")
+        .then(function(r) { return r.json(); })
+        .then(function(tokens) {
+            if (!Array.isArray(tokens) || tokens.length === 0) {
+                list.innerHTML = '<div class="setting-desc">No deploy tokens.</div>';
+                return;
+            }
+            // Safe: names and scopes are escaped via escapeHTML.
+            list.innerHTML = tokens.map(function(tok) {
+                var used = tok.last_used_at && tok.last_used_at.indexOf("0001-") !== 0
+                    ? "used " + new Date(tok.last_used_at).toLocaleString()
+                    : "never used";
+                return '<div style="display:flex; gap:var(--sp-2); align-items:center; margin-bottom:var(--sp-1)">' +
+                    '<code>' + escapeHTML(tok.name) + '</code>' +
+                    '<span class="setting-desc">' + escapeHTML((tok.containers || []).join(", ")) + ' &middot; ' + escapeHTML(used) + '</span>' +
+                    '<button class="btn btn-sm" data-id="' + escapeHTML(tok.id) + '" data-name="' + escapeHTML(tok.name) + '" onclick="deleteDeployToken(this.getAttribute(\'data-id\'), this.getAttribute(\'data-name\'))">Revoke</button>' +
+                    '</div>';
+            }).join("");
+        })
+        .catch(function() { /* ignore -- section stays empty */ });
+}
+
+function createDeployToken() {
+    var name = document.getElementById("deploy-token-name");
+    var containers = document.getElementById("deploy-token-containers");
+    if (!name || !containers) return;
+
+    fetch("/api/settings/deploy-tokens", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ name: name.value.trim(), containers: containers.value.split(",") })
+    })
+        .then(function(resp) {
+            return resp.json().then(function(data) {
+                return { ok: resp.ok, data: data };
+            });
+        })
+        .then(function(result) {
+            if (result.ok) {
+                var out = document.getElementById("deploy-token-new");
+                if (out) {
+                    out.value = result.data.token || "";
+                    out.style.display = "";
+                    out.select();
+                }
+                showToast("Deploy token created — copy it now, it won't be shown again", "success");
+                name.value = "";
+                containers.value = "";
+                loadDeployTokens();
+            } else {
+                showToast(result.data.error || "Failed to create deploy token", "error");
+            }
+        })
+        .catch(function() {
+            showToast("Network error -- could not create deploy token", "error");
+        });
+}
+
+function deleteDeployToken(id, name) {
+    fetch("/api/settings/deploy-tokens/" + encodeURIComponent(id), { method: "DELETE" })
+        .then(function(resp) {
+            if (!resp.ok) throw new Error("delete failed");
+            showToast("Deploy token revoked: " + name, "success");
+            loadDeployTokens();
+        })
+        .catch(function() {
+            showToast("Could not revoke deploy token", "error");
+        });
+}
+
 function loadRetrySettings() {
     fetch("/api/settings/notifications/retry")
         .then(function(r) { return r.json(); })
@@ -1378,6 +2240,8 @@ export {
     setLatestAutoUpdate,
     setPauseState,
     saveFilters,
+    testFilters,
+    saveImageRewrites,
     setImageCleanup,
     saveCronSchedule,
     setDependencyAware,
@@ -1386,11 +2250,27 @@ export {
     setDryRun,
     setPullOnly,
     setUpdateDelay,
+    setHealthTimeout,
+    setBlueGreenPortRange,
+    saveExternalValidation,
+    setStuckUpdateThreshold,
+    setAuditRetention,
     setComposeSync,
+    setConfigDiff,
+    setRebuildWatch,
+    saveRebuildWebhook,
+    setPullCheckRegistry,
+    savePullCheckDiskPath,
+    saveRetention,
+    compactDatabase,
+    loadComposePaths,
+    saveComposePath,
+    deleteComposePath,
     setImageBackup,
     setShowStopped,
     setRemoveVolumes,
     setScanConcurrency,
+    setAutoUpdateCap,
     setHADiscovery,
     saveHADiscoveryPrefix,
     updateToggleText,
@@ -1401,16 +2281,32 @@ export {
     regenerateWebhookSecret,
     copyWebhookURL,
     copyWebhookSecret,
+    regenerateRegistryWebhookToken,
+    copyRegistryWebhookURL,
     saveMaintenanceWindow,
     updateScanPreviews,
     exportConfig,
     importConfig,
+    exportQueueState,
+    importQueueState,
+    downloadDatabaseBackup,
+    loadBackupSettings,
+    saveBackupSettings,
+    restoreDatabase,
     loadDashboardColumns,
     saveDashboardColumns,
     loadScannerSettings,
     saveScannerSettings,
     loadVerifierSettings,
     saveVerifierSettings,
+    loadTrustedKeys,
+    addTrustedKey,
+    deleteTrustedKey,
+    loadVerifyCacheStats,
+    clearVerifyCache,
+    loadDeployTokens,
+    createDeployToken,
+    deleteDeployToken,
     loadRetrySettings,
     saveRetrySettings,
     toggleAdvanced
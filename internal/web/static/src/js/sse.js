@@ -210,9 +210,58 @@ function setConnectionStatus(connected) {
     }
 }
 
+/* ------------------------------------------------------------
+   Restart handshake — detect a backend restart (e.g. after a
+   self-update) and recover instead of stranding the page.
+   ------------------------------------------------------------ */
+
+// Boot ID of the backend this page last connected to (see /api/version).
+var _sseBootID = null;
+var _backendRetryDelay = 2000;
+
+// Poll /api/version until the backend answers, then reload so the page
+// picks up the new build's assets. Used once the browser has given up on
+// the event stream, which happens when a reverse proxy answers 502 while
+// Sentinel restarts. A 401 means the session did not survive: log in again.
+function waitForBackend() {
+    setConnectionStatus(false);
+    fetch("/api/version", {credentials: "same-origin", cache: "no-store"})
+        .then(function (res) {
+            if (res.status === 401) {
+                window.location.href = "/login";
+                return;
+            }
+            if (!res.ok) throw new Error("HTTP " + res.status);
+            window.location.reload();
+        })
+        .catch(function () {
+            _backendRetryDelay = Math.min(_backendRetryDelay * 2, 30000);
+            setTimeout(waitForBackend, _backendRetryDelay);
+        });
+}
+
+// Show "updated from X → Y" once per boot after a self-update.
+function showSelfUpdateNotice() {
+    fetch("/api/version", {credentials: "same-origin", cache: "no-store"})
+        .then(function (res) { return res.ok ? res.json() : null; })
+        .then(function (data) {
+            if (!data || !data.self_update) return;
+            if (localStorage.getItem("sentinel-update-seen") === data.boot_id) return;
+            localStorage.setItem("sentinel-update-seen", data.boot_id);
+            var done = new Date(data.self_update.completed_at).getTime();
+            if (!done || Date.now() - done > 24 * 60 * 60 * 1000) return;
+            var from = data.self_update.from_version || "unknown";
+            var to = data.self_update.to_version || data.version;
+            showToast("Sentinel updated from " + from + " \u2192 " + to, "success");
+        })
+        .catch(function () {});
+}
+
 function initSSE() {
     if (typeof EventSource === "undefined") return;
 
+    showSelfUpdateNotice();
+
     var es = new EventSource("/api/events");
     // Expose for page-specific inline scripts (cluster.html, portainer.html)
     // so they can add listeners without opening a duplicate SSE connection.
@@ -220,12 +269,23 @@ function initSSE() {
     var _sseHasConnected = false;
     var _sseCatchUpTimer = null;
 
-    es.addEventListener("connected", function () {
+    es.addEventListener("connected", function (e) {
         if (localStorage.getItem("sentinel-self-updating")) {
             localStorage.removeItem("sentinel-self-updating");
             window.location.reload();
             return;
         }
+        // A different boot ID means the backend restarted while we were
+        // disconnected (self-update, container restart). Reload to load the
+        // new build's assets; a plain network flap keeps the same boot ID.
+        var bootID = null;
+        try { bootID = JSON.parse(e.data).boot_id || null; } catch (_) {}
+        if (_sseBootID && bootID && bootID !== _sseBootID) {
+            window.location.reload();
+            return;
+        }
+        if (bootID) _sseBootID = bootID;
+        _backendRetryDelay = 2000;
         // Previously: reconnect → window.location.reload(). On a flapping
         // network the dashboard would reload-loop, losing expanded host
         // groups, scroll position, selection, open modals, and unsaved
@@ -524,6 +584,11 @@ function initSSE() {
 
     es.onerror = function () {
         setConnectionStatus(false);
+        // CLOSED means the browser will not retry on its own.
+        if (es.readyState === EventSource.CLOSED) {
+            es.close();
+            setTimeout(waitForBackend, _backendRetryDelay);
+        }
     };
 }
 
@@ -2,7 +2,7 @@
    7b. Swarm Service Toggle & Actions
    ============================================================ */
 
-import { showToast, escapeHTML, apiPost } from "./utils.js";
+import { showToast, escapeHTML, apiPost, showConfirm } from "./utils.js";
 
 // isSafeURL validates that a URL string starts with http:// or https://.
 function isSafeURL(url) {
@@ -27,6 +27,62 @@ function toggleSvc(headerRow) {
 
 function triggerSvcUpdate(name, event) {
     var btn = event && event.target ? event.target.closest(".badge-action") || event.target.closest(".btn") : null;
+    // Preview task placement first so nodes that cannot run the new image
+    // are seen before approving, not discovered as pending tasks after.
+    fetch("/api/services/" + encodeURIComponent(name) + "/placement")
+        .then(function(r) {
+            return r.json().then(function(data) { return { ok: r.ok, data: data }; });
+        })
+        .then(function(result) {
+            if (!result.ok) {
+                startSvcUpdate(name, btn);
+                return;
+            }
+            showConfirm("Update " + name, placementPreviewHTML(result.data), {
+                danger: result.data.warnings && result.data.warnings.length > 0,
+                confirmLabel: "Update"
+            }).then(function(confirmed) {
+                if (confirmed) startSvcUpdate(name, btn);
+            });
+        })
+        .catch(function() {
+            startSvcUpdate(name, btn);
+        });
+}
+
+// placementPreviewHTML renders a placement preview for the confirm modal.
+function placementPreviewHTML(plan) {
+    var html = "<p>Update <strong>" + escapeHTML(plan.service) + "</strong> to <strong>" + escapeHTML(plan.image) + "</strong>?</p>";
+    if (plan.image_platforms && plan.image_platforms.length > 0) {
+        html += '<div class="confirm-muted-row">Image platforms: ' + plan.image_platforms.map(escapeHTML).join(", ") + "</div>";
+    }
+    if (plan.constraints && plan.constraints.length > 0) {
+        html += '<div class="confirm-muted-row">Constraints: ' + plan.constraints.map(escapeHTML).join(", ") + "</div>";
+    }
+    if (plan.warnings && plan.warnings.length > 0) {
+        html += '<div class="confirm-section-label">Warnings (' + plan.warnings.length + ")</div>";
+        for (var w = 0; w < plan.warnings.length; w++) {
+            html += '<div class="confirm-change-row"><span class="badge badge-warning">' + escapeHTML(plan.warnings[w]) + "</span></div>";
+        }
+    }
+    var nodes = plan.nodes || [];
+    html += '<div class="confirm-section-label">Nodes (' + nodes.length + ")</div>";
+    for (var i = 0; i < nodes.length; i++) {
+        var n = nodes[i];
+        if (n.eligible) {
+            html += '<div class="confirm-change-row"><span class="confirm-change-name">' + escapeHTML(n.hostname) + '</span> <span class="badge badge-muted">' + escapeHTML(n.platform) + '</span> ' +
+                n.current_tasks + ' \u2192 <span class="badge badge-info">' + n.planned_tasks + " task" + (n.planned_tasks !== 1 ? "s" : "") + "</span></div>";
+        } else {
+            html += '<div class="confirm-muted-row">' + escapeHTML(n.hostname) + " (" + escapeHTML(n.platform) + ") \u2014 " + escapeHTML(n.reason) + "</div>";
+        }
+    }
+    if (plan.mode === "replicated") {
+        html += '<div class="confirm-muted-row">Planned task counts are an estimate; the scheduler also weighs node resources.</div>';
+    }
+    return html;
+}
+
+function startSvcUpdate(name, btn) {
     if (btn) {
         btn.classList.add("loading");
         btn.disabled = true;
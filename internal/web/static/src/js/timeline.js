@@ -0,0 +1,158 @@
+/* ============================================================
+   Docker-Sentinel — Timeline page (client-side)
+   ============================================================ */
+
+var _timelineEntries = [];
+var _timelineNext = '';
+var _timelineSource = 'all'; // all | log | history | cluster
+
+// Badge per source.
+var TIMELINE_SOURCE_BADGE = {
+    log:     { cls: 'badge-muted', label: 'Activity' },
+    history: { cls: 'badge-info', label: 'Update' },
+    cluster: { cls: 'badge-warning', label: 'Cluster' }
+};
+
+// Badge colour per entry type; failures are always shown in red.
+var TIMELINE_TYPE_BADGE = {
+    success:          'badge-success',
+    rollback_success: 'badge-warning',
+    connected:        'badge-success',
+    disconnected:     'badge-warning',
+    approve:          'badge-success',
+    reject:           'badge-error'
+};
+
+function timelineQuery() {
+    var params = new URLSearchParams();
+    var since = document.getElementById('timeline-since');
+    if (since && since.value) params.set('since', since.value);
+    var container = document.getElementById('timeline-container');
+    if (container && container.value.trim()) params.set('container', container.value.trim());
+    var host = document.getElementById('timeline-host');
+    if (host && host.value.trim()) params.set('host', host.value.trim());
+    if (_timelineSource !== 'all') params.set('type', _timelineSource);
+    return params;
+}
+
+export async function loadTimeline() {
+    _timelineEntries = [];
+    _timelineNext = '';
+    await fetchTimeline(timelineQuery());
+}
+
+export async function loadMoreTimeline() {
+    if (!_timelineNext) return;
+    var params = timelineQuery();
+    params.set('before', _timelineNext);
+    await fetchTimeline(params);
+}
+
+export function filterTimeline(source) {
+    _timelineSource = source;
+    var pills = document.querySelectorAll('.timeline-source-pill');
+    for (var i = 0; i < pills.length; i++) {
+        pills[i].classList.toggle('active', pills[i].getAttribute('data-source') === source);
+    }
+    loadTimeline();
+}
+
+async function fetchTimeline(params) {
+    try {
+        var resp = await fetch('/api/timeline?' + params.toString());
+        if (!resp.ok) throw new Error('HTTP ' + resp.status);
+        var data = await resp.json();
+        var entries = Array.isArray(data.entries) ? data.entries : [];
+        _timelineEntries = _timelineEntries.concat(entries);
+        _timelineNext = data.next_cursor || '';
+        renderTimeline();
+    } catch (err) {
+        console.error('Failed to load timeline:', err);
+        if (window.showToast) window.showToast('Failed to load timeline', 'error');
+    }
+}
+
+function renderTimeline() {
+    var tbody = document.getElementById('timeline-tbody');
+    if (!tbody) return;
+    while (tbody.firstChild) tbody.removeChild(tbody.firstChild);
+
+    var summary = document.getElementById('timeline-summary');
+    if (summary) {
+        summary.textContent = _timelineEntries.length + (_timelineNext ? '+' : '') + ' events';
+    }
+    var more = document.getElementById('timeline-more');
+    if (more) more.style.display = _timelineNext ? '' : 'none';
+
+    if (_timelineEntries.length === 0) {
+        var tr = document.createElement('tr');
+        var td = document.createElement('td');
+        td.colSpan = 5;
+        var emptyDiv = document.createElement('div');
+        emptyDiv.className = 'empty-state';
+        var msg = document.createElement('p');
+        msg.textContent = 'Nothing happened in this period.';
+        emptyDiv.appendChild(msg);
+        td.appendChild(emptyDiv);
+        tr.appendChild(td);
+        tbody.appendChild(tr);
+        return;
+    }
+
+    for (var i = 0; i < _timelineEntries.length; i++) {
+        var e = _timelineEntries[i];
+        var row = document.createElement('tr');
+
+        // Time
+        var timeCell = document.createElement('td');
+        var ts = new Date(e.timestamp);
+        timeCell.title = ts.toISOString();
+        timeCell.textContent = ts.toLocaleString();
+        row.appendChild(timeCell);
+
+        // Source
+        var sourceCell = document.createElement('td');
+        var src = TIMELINE_SOURCE_BADGE[e.source] || { cls: 'badge-muted', label: e.source };
+        var srcBadge = document.createElement('span');
+        srcBadge.className = 'badge ' + src.cls;
+        srcBadge.textContent = src.label;
+        sourceCell.appendChild(srcBadge);
+        row.appendChild(sourceCell);
+
+        // Type
+        var typeCell = document.createElement('td');
+        var typeBadge = document.createElement('span');
+        typeBadge.className = 'badge ' + (e.failed ? 'badge-error' : (TIMELINE_TYPE_BADGE[e.type] || 'badge-muted'));
+        typeBadge.textContent = e.type;
+        typeCell.appendChild(typeBadge);
+        row.appendChild(typeCell);
+
+        // Container and host
+        var containerCell = document.createElement('td');
+        containerCell.className = 'mono';
+        if (e.container) {
+            var link = document.createElement('a');
+            link.href = '/container/' + encodeURIComponent(e.container) + (e.host_id ? '?host=' + encodeURIComponent(e.host_id) : '');
+            link.textContent = e.container;
+            containerCell.appendChild(link);
+        } else {
+            containerCell.textContent = '-';
+        }
+        if (e.host_id) {
+            var host = document.createElement('span');
+            host.className = 'host-badge';
+            host.title = 'Host: ' + e.host_id;
+            host.textContent = e.host_name || e.host_id;
+            containerCell.appendChild(host);
+        }
+        row.appendChild(containerCell);
+
+        // Message
+        var msgCell = document.createElement('td');
+        msgCell.title = e.message;
+        msgCell.textContent = e.user ? e.user + ': ' + e.message : e.message;
+        row.appendChild(msgCell);
+
+        tbody.appendChild(row);
+    }
+}
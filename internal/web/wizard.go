@@ -217,6 +217,7 @@ func (ws *WizardServer) apiSetup(w http.ResponseWriter, r *http.Request) {
 		UserID:    user.ID,
 		IP:        wizardClientIP(r),
 		UserAgent: r.UserAgent(),
+		Source:    auth.SessionSourceSetup,
 		CreatedAt: time.Now().UTC(),
 		ExpiresAt: time.Now().UTC().Add(ws.deps.Auth.SessionExpiry),
 	}
@@ -224,7 +225,7 @@ func (ws *WizardServer) apiSetup(w http.ResponseWriter, r *http.Request) {
 		writeWizardError(w, http.StatusInternalServerError, "failed to create session")
 		return
 	}
-	auth.SetSessionCookie(w, sessionToken, session.ExpiresAt, ws.deps.Auth.CookieSecure)
+	ws.deps.Auth.Cookies().SetSession(w, sessionToken, session.ExpiresAt)
 
 	ws.deps.Log.Info("wizard setup complete", "role", req.Role, "user", req.Username)
 
@@ -321,7 +322,7 @@ func writeWizardJSON(w http.ResponseWriter, status int, v any) {
 }
 
 func writeWizardError(w http.ResponseWriter, status int, msg string) {
-	writeWizardJSON(w, status, map[string]string{"error": msg})
+	writeWizardJSON(w, status, newAPIError(status, msg))
 }
 
 func wizardClientIP(r *http.Request) string {
@@ -1,18 +1,20 @@
-// Package webhook parses inbound webhook payloads from Docker Hub, GHCR,
-// and generic CI/CD pipelines into a normalised Payload struct.
+// Package webhook parses inbound webhook payloads from Docker Hub, Harbor,
+// GHCR, and generic CI/CD pipelines into a normalised Payload struct.
 package webhook
 
 import (
 	"encoding/json"
 	"errors"
 	"strings"
+
+	"github.com/Will-Luck/Docker-Sentinel/internal/registry"
 )
 
 // Payload represents a parsed webhook payload.
 type Payload struct {
 	Image    string // e.g. "nginx", "ghcr.io/user/repo"
 	Tag      string // e.g. "latest", "v1.2.3"
-	Source   string // "dockerhub", "ghcr", "generic", "unknown"
+	Source   string // "dockerhub", "harbor", "ghcr", "generic", "unknown"
 	RawEvent string // original event type if available
 }
 
@@ -20,7 +22,7 @@ type Payload struct {
 var ErrEmptyBody = errors.New("empty request body")
 
 // Parse attempts to detect and parse a webhook payload from various sources.
-// It tries Docker Hub format, GHCR format, and generic format in order.
+// It tries Docker Hub, Harbor, GHCR, and generic formats in order.
 // If the body is valid JSON but doesn't match any known format, a Payload
 // with Source "unknown" is returned (no error).
 func Parse(body []byte) (*Payload, error) {
@@ -41,6 +43,13 @@ func Parse(body []byte) (*Payload, error) {
 		}
 	}
 
+	// Try Harbor — presence of "event_data" key.
+	if _, ok := raw["event_data"]; ok {
+		if p, err := parseHarbor(body); err == nil {
+			return p, nil
+		}
+	}
+
 	// Try GHCR (GitHub package event) — presence of "package" key.
 	if _, ok := raw["package"]; ok {
 		if p, err := parseGHCR(body); err == nil {
@@ -95,6 +104,65 @@ func parseDockerHub(body []byte) (*Payload, error) {
 	}, nil
 }
 
+// parseHarbor handles Harbor webhook payloads (PUSH_ARTIFACT and friends).
+// The image comes from the first resource URL, which includes the Harbor
+// host, falling back to the repository's full name.
+//
+//	{
+//	    "type": "PUSH_ARTIFACT",
+//	    "event_data": {
+//	        "resources": [{"tag": "v1.2.3", "resource_url": "harbor.example.com/library/app:v1.2.3"}],
+//	        "repository": {"name": "app", "namespace": "library", "repo_full_name": "library/app"}
+//	    }
+//	}
+func parseHarbor(body []byte) (*Payload, error) {
+	var hb struct {
+		Type      string `json:"type"`
+		EventData struct {
+			Resources []struct {
+				Tag         string `json:"tag"`
+				ResourceURL string `json:"resource_url"`
+			} `json:"resources"`
+			Repository struct {
+				RepoFullName string `json:"repo_full_name"`
+			} `json:"repository"`
+		} `json:"event_data"`
+	}
+	if err := json.Unmarshal(body, &hb); err != nil {
+		return nil, err
+	}
+
+	var image, tag string
+	if len(hb.EventData.Resources) > 0 {
+		res := hb.EventData.Resources[0]
+		tag = res.Tag
+		image = res.ResourceURL
+		// Strip the digest or tag; a colon before the last slash is a port.
+		if idx := strings.Index(image, "@"); idx >= 0 {
+			image = image[:idx]
+		}
+		if idx := strings.LastIndex(image, ":"); idx > strings.LastIndex(image, "/") {
+			if tag == "" {
+				tag = image[idx+1:]
+			}
+			image = image[:idx]
+		}
+	}
+	if image == "" {
+		image = hb.EventData.Repository.RepoFullName
+	}
+	if image == "" {
+		return nil, errors.New("harbor: missing repository")
+	}
+
+	return &Payload{
+		Image:    image,
+		Tag:      tag,
+		Source:   "harbor",
+		RawEvent: hb.Type,
+	}, nil
+}
+
 // parseGHCR handles GitHub Container Registry webhook payloads (package event).
 //
 //	{
@@ -208,3 +276,16 @@ func parseGeneric(body []byte) (*Payload, error) {
 		Source: "generic",
 	}, nil
 }
+
+// Matches reports whether imageRef names the repository this payload is
+// about. Registry host and repository path are compared after normalisation,
+// so "nginx:1.25" matches a Docker Hub push of "library/nginx". Tags are not
+// compared: a push of any tag can be an update for a container that follows
+// semver tags.
+func (p *Payload) Matches(imageRef string) bool {
+	if p == nil || p.Image == "" || imageRef == "" {
+		return false
+	}
+	return strings.EqualFold(registry.RegistryHost(p.Image), registry.RegistryHost(imageRef)) &&
+		strings.EqualFold(registry.RepoPath(p.Image), registry.RepoPath(imageRef))
+}
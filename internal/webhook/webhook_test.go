@@ -361,3 +361,72 @@ func TestParse_GHCR_NoTag(t *testing.T) {
 		t.Errorf("tag = %q, want empty", p.Tag)
 	}
 }
+
+func TestParse_Harbor(t *testing.T) {
+	body := []byte(`{
+		"type": "PUSH_ARTIFACT",
+		"occur_at": 1700000000,
+		"operator": "admin",
+		"event_data": {
+			"resources": [{
+				"digest": "sha256:abc",
+				"tag": "v1.2.3",
+				"resource_url": "harbor.example.com:8443/library/app:v1.2.3"
+			}],
+			"repository": {"name": "app", "namespace": "library", "repo_full_name": "library/app"}
+		}
+	}`)
+
+	p, err := Parse(body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Source != "harbor" {
+		t.Errorf("source = %q, want %q", p.Source, "harbor")
+	}
+	if p.Image != "harbor.example.com:8443/library/app" {
+		t.Errorf("image = %q, want %q", p.Image, "harbor.example.com:8443/library/app")
+	}
+	if p.Tag != "v1.2.3" {
+		t.Errorf("tag = %q, want %q", p.Tag, "v1.2.3")
+	}
+	if p.RawEvent != "PUSH_ARTIFACT" {
+		t.Errorf("rawEvent = %q, want %q", p.RawEvent, "PUSH_ARTIFACT")
+	}
+}
+
+func TestParse_Harbor_RepoFallback(t *testing.T) {
+	body := []byte(`{
+		"type": "PUSH_ARTIFACT",
+		"event_data": {"repository": {"repo_full_name": "team/api"}}
+	}`)
+
+	p, err := Parse(body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Source != "harbor" || p.Image != "team/api" {
+		t.Errorf("got source=%q image=%q, want harbor team/api", p.Source, p.Image)
+	}
+}
+
+func TestPayloadMatches(t *testing.T) {
+	tests := []struct {
+		payload Payload
+		image   string
+		want    bool
+	}{
+		{Payload{Image: "library/nginx"}, "nginx:1.25", true},
+		{Payload{Image: "library/nginx"}, "docker.io/library/nginx:latest", true},
+		{Payload{Image: "library/nginx"}, "ghcr.io/library/nginx:latest", false},
+		{Payload{Image: "ghcr.io/Org/App"}, "ghcr.io/org/app:v2", true},
+		{Payload{Image: "ghcr.io/org/app"}, "ghcr.io/org/app-worker:v2", false},
+		{Payload{Image: "harbor.example.com:8443/library/app"}, "harbor.example.com:8443/library/app@sha256:abc", true},
+		{Payload{}, "nginx", false},
+	}
+	for _, tt := range tests {
+		if got := tt.payload.Matches(tt.image); got != tt.want {
+			t.Errorf("Payload{%q}.Matches(%q) = %v, want %v", tt.payload.Image, tt.image, got, tt.want)
+		}
+	}
+}